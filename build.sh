@@ -12,6 +12,12 @@ go build -ldflags="-s -w" -o lxc-go-cli .
 echo "Building for Linux AMD64..."
 GOOS=linux GOARCH=amd64 CGO_ENABLED=1 go build -ldflags="-s -w" -o lxc-go-cli .
 
+# Remote clients only need the lxc client, so no cgo
+echo "Building for macOS and Windows..."
+GOOS=darwin GOARCH=arm64 CGO_ENABLED=0 go build -ldflags="-s -w" -o lxc-go-cli-darwin-arm64 .
+GOOS=darwin GOARCH=amd64 CGO_ENABLED=0 go build -ldflags="-s -w" -o lxc-go-cli-darwin-amd64 .
+GOOS=windows GOARCH=amd64 CGO_ENABLED=0 go build -ldflags="-s -w" -o lxc-go-cli-windows-amd64.exe .
+
 echo "Build complete!"
 echo "Available binaries:"
 ls -la lxc-go-cli* 
\ No newline at end of file
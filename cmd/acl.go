@@ -0,0 +1,203 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aclTimeout     time.Duration
+	aclIngress     []string
+	aclEgress      []string
+	aclDescription string
+	aclNIC         string
+)
+
+// aclCmd represents the acl command
+var aclCmd = &cobra.Command{
+	Use:   "acl <create|attach|list>",
+	Short: "Manage LXD network ACLs",
+	Long: `Manage LXD network ACLs so ingress and egress rules are enforced by LXD
+rather than by host firewall scripts.
+
+Rules are written as space-separated key=value pairs using LXD's rule fields
+(action, source, destination, protocol, source_port, destination_port,
+icmp_type, icmp_code, description, state). Once an ACL is attached to a NIC,
+traffic not matched by any rule is rejected.
+
+Network ACLs on bridged NICs need LXD 4.20 or later. On older servers use
+'lxc-go-cli net policy' for host-level rules instead.
+
+Examples:
+  lxc-go-cli acl create web --ingress "action=allow protocol=tcp destination_port=80,443" --egress "action=allow"
+  lxc-go-cli acl attach web mycontainer
+  lxc-go-cli acl list`,
+}
+
+// aclCreateCmd represents the acl create subcommand
+var aclCreateCmd = &cobra.Command{
+	Use:   "create <acl-name>",
+	Short: "Create a network ACL with ingress and egress rules",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), aclTimeout)
+		defer cancel()
+		return createACL(ctx, &DefaultACLManager{}, args[0], aclDescription, aclIngress, aclEgress)
+	},
+}
+
+// aclAttachCmd represents the acl attach subcommand
+var aclAttachCmd = &cobra.Command{
+	Use:   "attach <acl-name> <container-name>",
+	Short: "Attach a network ACL to a container NIC",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), aclTimeout)
+		defer cancel()
+		return attachACL(ctx, &DefaultACLManager{}, args[0], args[1], aclNIC)
+	},
+}
+
+// aclListCmd represents the acl list subcommand
+var aclListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List network ACLs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), aclTimeout)
+		defer cancel()
+		return listACLs(ctx, &DefaultACLManager{}, cmd.OutOrStdout())
+	},
+}
+
+// ACLManager interface for dependency injection
+type ACLManager interface {
+	MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error)
+	ContainerExists(ctx context.Context, name string) bool
+	CreateNetworkACL(ctx context.Context, acl helpers.NetworkACL) error
+	AttachNetworkACL(ctx context.Context, containerName, nic, aclName string) error
+	ListNetworkACLs(ctx context.Context) ([]helpers.NetworkACL, error)
+}
+
+// DefaultACLManager implements ACLManager using helpers
+type DefaultACLManager struct{}
+
+func (d *DefaultACLManager) MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error) {
+	return helpers.MissingAPIExtensions(ctx, required...)
+}
+
+func (d *DefaultACLManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultACLManager) CreateNetworkACL(ctx context.Context, acl helpers.NetworkACL) error {
+	return helpers.CreateNetworkACL(ctx, acl)
+}
+
+func (d *DefaultACLManager) AttachNetworkACL(ctx context.Context, containerName, nic, aclName string) error {
+	return helpers.AttachNetworkACL(ctx, containerName, nic, aclName)
+}
+
+func (d *DefaultACLManager) ListNetworkACLs(ctx context.Context) ([]helpers.NetworkACL, error) {
+	return helpers.ListNetworkACLs(ctx)
+}
+
+// checkACLSupport fails with a fallback hint when the server cannot manage ACLs
+func checkACLSupport(ctx context.Context, manager ACLManager) error {
+	missing, err := manager.MissingAPIExtensions(ctx, helpers.NetworkACLExtensions...)
+	if err != nil {
+		return fmt.Errorf("failed to check network ACL support: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("this LXD server does not support network ACLs on bridged NICs (missing API extensions: %s); "+
+			"upgrade to LXD 4.20 or later, or use 'lxc-go-cli net policy' for host-level rules", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// createACL validates the rules and creates the ACL
+func createACL(ctx context.Context, manager ACLManager, name, description string, ingress, egress []string) error {
+	if name == "" {
+		return fmt.Errorf("ACL name is required")
+	}
+
+	acl := helpers.NetworkACL{Name: name, Description: description}
+	for _, spec := range ingress {
+		rule, err := helpers.ParseNetworkACLRule(spec)
+		if err != nil {
+			return helpers.UsageErrorf("invalid ingress rule: %w", err)
+		}
+		acl.Ingress = append(acl.Ingress, rule)
+	}
+	for _, spec := range egress {
+		rule, err := helpers.ParseNetworkACLRule(spec)
+		if err != nil {
+			return helpers.UsageErrorf("invalid egress rule: %w", err)
+		}
+		acl.Egress = append(acl.Egress, rule)
+	}
+
+	if err := checkACLSupport(ctx, manager); err != nil {
+		return err
+	}
+
+	if err := manager.CreateNetworkACL(ctx, acl); err != nil {
+		return err
+	}
+
+	logger.Info("Created network ACL '%s' with %d ingress and %d egress rule(s)", name, len(acl.Ingress), len(acl.Egress))
+	return nil
+}
+
+// attachACL attaches an existing ACL to a container NIC
+func attachACL(ctx context.Context, manager ACLManager, aclName, containerName, nic string) error {
+	if err := checkACLSupport(ctx, manager); err != nil {
+		return err
+	}
+
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	if err := manager.AttachNetworkACL(ctx, containerName, nic, aclName); err != nil {
+		return fmt.Errorf("failed to attach ACL '%s' to container '%s': %w", aclName, containerName, err)
+	}
+
+	logger.Info("Attached network ACL '%s' to %s of container '%s'", aclName, nic, containerName)
+	return nil
+}
+
+// listACLs prints all network ACLs
+func listACLs(ctx context.Context, manager ACLManager, out io.Writer) error {
+	if err := checkACLSupport(ctx, manager); err != nil {
+		return err
+	}
+
+	acls, err := manager.ListNetworkACLs(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, helpers.FormatNetworkACLs(acls))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(aclCmd)
+	aclCmd.AddCommand(aclCreateCmd, aclAttachCmd, aclListCmd)
+
+	aclCmd.PersistentFlags().DurationVarP(&aclTimeout, "timeout", "t", 30*time.Second, "Timeout for ACL operations")
+	aclCreateCmd.Flags().StringArrayVar(&aclIngress, "ingress", nil, "Ingress rule as key=value pairs (repeatable)")
+	aclCreateCmd.Flags().StringArrayVar(&aclEgress, "egress", nil, "Egress rule as key=value pairs (repeatable)")
+	aclCreateCmd.Flags().StringVar(&aclDescription, "description", "", "ACL description")
+	aclAttachCmd.Flags().StringVar(&aclNIC, "nic", "eth0", "Container NIC to attach the ACL to")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockACLManager for testing acl commands
+type MockACLManager struct {
+	Missing            []string
+	ExistingContainers map[string]bool
+	ACLs               []helpers.NetworkACL
+	Created            []helpers.NetworkACL
+	Calls              map[string]int
+}
+
+func (m *MockACLManager) MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error) {
+	return m.Missing, nil
+}
+
+func (m *MockACLManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockACLManager) CreateNetworkACL(ctx context.Context, acl helpers.NetworkACL) error {
+	m.trackCall("CreateNetworkACL")
+	m.Created = append(m.Created, acl)
+	return nil
+}
+
+func (m *MockACLManager) AttachNetworkACL(ctx context.Context, containerName, nic, aclName string) error {
+	m.trackCall("AttachNetworkACL")
+	return nil
+}
+
+func (m *MockACLManager) ListNetworkACLs(ctx context.Context) ([]helpers.NetworkACL, error) {
+	return m.ACLs, nil
+}
+
+func (m *MockACLManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func TestACLCommand(t *testing.T) {
+	subcommands := map[string]bool{}
+	for _, sub := range aclCmd.Commands() {
+		subcommands[sub.Name()] = true
+	}
+	for _, name := range []string{"create", "attach", "list"} {
+		if !subcommands[name] {
+			t.Errorf("acl should have '%s' subcommand", name)
+		}
+	}
+	if flag := aclAttachCmd.Flags().Lookup("nic"); flag == nil || flag.DefValue != "eth0" {
+		t.Error("nic flag should exist with default eth0")
+	}
+}
+
+func TestCreateACL(t *testing.T) {
+	manager := &MockACLManager{}
+	err := createACL(context.Background(), manager, "web", "web servers",
+		[]string{"action=allow protocol=tcp destination_port=80"}, []string{"action=allow"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Created) != 1 || len(manager.Created[0].Ingress) != 1 || len(manager.Created[0].Egress) != 1 {
+		t.Errorf("unexpected created ACLs: %+v", manager.Created)
+	}
+
+	manager = &MockACLManager{}
+	err = createACL(context.Background(), manager, "web", "", []string{"protocol=tcp"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid ingress rule") {
+		t.Errorf("expected invalid ingress rule error, got %v", err)
+	}
+	if manager.Calls["CreateNetworkACL"] != 0 {
+		t.Error("ACL should not be created when a rule is invalid")
+	}
+}
+
+func TestACLUnsupportedServer(t *testing.T) {
+	manager := &MockACLManager{
+		Missing:            []string{"network_bridge_acl"},
+		ExistingContainers: map[string]bool{"app": true},
+	}
+
+	checks := map[string]error{
+		"create": createACL(context.Background(), manager, "web", "", nil, nil),
+		"attach": attachACL(context.Background(), manager, "web", "app", "eth0"),
+		"list":   listACLs(context.Background(), manager, &bytes.Buffer{}),
+	}
+	for name, err := range checks {
+		if err == nil || !strings.Contains(err.Error(), "net policy") || !strings.Contains(err.Error(), "network_bridge_acl") {
+			t.Errorf("%s: expected fallback message, got %v", name, err)
+		}
+	}
+	if len(manager.Calls) != 0 {
+		t.Errorf("no ACL operations should run on an unsupported server, got %v", manager.Calls)
+	}
+}
+
+func TestAttachACL(t *testing.T) {
+	manager := &MockACLManager{ExistingContainers: map[string]bool{"app": true}}
+	if err := attachACL(context.Background(), manager, "web", "app", "eth0"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Calls["AttachNetworkACL"] != 1 {
+		t.Error("expected ACL to be attached")
+	}
+
+	err := attachACL(context.Background(), manager, "web", "ghost", "eth0")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+}
+
+func TestListACLs(t *testing.T) {
+	manager := &MockACLManager{ACLs: []helpers.NetworkACL{{Name: "web", UsedBy: []string{"/1.0/instances/app"}}}}
+	var out bytes.Buffer
+	if err := listACLs(context.Background(), manager, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "web") || !strings.Contains(out.String(), "app") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
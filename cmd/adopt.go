@@ -0,0 +1,243 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	adoptInstallDocker bool
+	adoptTimeout       time.Duration
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <container-name>",
+	Short: "Bring an existing container under lxc-go-cli management",
+	Long: `Bring an existing, manually created container under lxc-go-cli management.
+
+The container is inspected and:
+  - missing Docker security settings (nesting, syscall interception) are applied
+  - with --install-docker, Docker and the 'app' user are installed if missing
+  - existing TCP/UDP proxy devices are registered so 'port list' shows them
+  - it is tagged with user.lxc-go-cli.managed=true and the tool version
+
+The container is restarted when security settings or Docker were changed.
+--install-docker requires the container to be running.
+
+Examples:
+  lxc-go-cli adopt legacy-web
+  lxc-go-cli adopt legacy-web --install-docker`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), adoptTimeout)
+		defer cancel()
+
+		return adoptContainer(ctx, &DefaultAdoptManager{}, args[0], adoptInstallDocker)
+	},
+}
+
+// AdoptManager interface for dependency injection
+type AdoptManager interface {
+	AppUserManager
+	ContainerExists(name string) bool
+	GetContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+	GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+	SetConfigValue(ctx context.Context, containerName, key, value string) error
+	RestartContainer(ctx context.Context, name string) error
+}
+
+// DefaultAdoptManager implements AdoptManager using helpers
+type DefaultAdoptManager struct{}
+
+func (d *DefaultAdoptManager) ContainerExists(name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultAdoptManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultAdoptManager) GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetExpandedContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultAdoptManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultAdoptManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	return helpers.RunInContainer(ctx, containerName, args...)
+}
+
+func (d *DefaultAdoptManager) SetUserPassword(containerName, username, password string) error {
+	return helpers.SetUserPassword(containerName, username, password)
+}
+
+func (d *DefaultAdoptManager) StoreContainerPassword(containerName, password string) error {
+	return helpers.StoreContainerPassword(containerName, password)
+}
+
+func (d *DefaultAdoptManager) RestartContainer(ctx context.Context, name string) error {
+	return helpers.RestartContainer(ctx, name)
+}
+
+// adoptedContainer is the part of `lxc config show` output adopt inspects
+type adoptedContainer struct {
+	Config  map[string]string            `yaml:"config"`
+	Devices map[string]map[string]string `yaml:"devices"`
+}
+
+// parseAdoptedContainer parses `lxc config show` output
+func parseAdoptedContainer(yamlData []byte) (*adoptedContainer, error) {
+	var container adoptedContainer
+	if err := yaml.Unmarshal(yamlData, &container); err != nil {
+		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+	if container.Config == nil {
+		container.Config = map[string]string{}
+	}
+	return &container, nil
+}
+
+// adoptContainer applies this tool's setup to an existing container and tags it as managed
+func adoptContainer(ctx context.Context, manager AdoptManager, name string, installDocker bool) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(name) {
+		return containerNotFound(name)
+	}
+
+	localData, err := manager.GetContainerConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+	local, err := parseAdoptedContainer(localData)
+	if err != nil {
+		return err
+	}
+	expandedData, err := manager.GetExpandedContainerConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+	expanded, err := parseAdoptedContainer(expandedData)
+	if err != nil {
+		return err
+	}
+
+	if expanded.Config[helpers.ManagedMarkerKey] == "true" {
+		logger.Info("Container '%s' is already managed by lxc-go-cli", name)
+		return nil
+	}
+
+	logger.Info("Adopting container '%s'...", name)
+	restart := false
+
+	// Apply missing security settings in a stable order
+	missing := helpers.MissingSecurityConfig(expanded.Config)
+	keys := make([]string, 0, len(missing))
+	for key := range missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		logger.Info("Setting %s=%s", key, missing[key])
+		if err := manager.SetConfigValue(ctx, name, key, missing[key]); err != nil {
+			return fmt.Errorf("failed to configure container security: %w", err)
+		}
+		restart = true
+	}
+
+	// --timeout bounds the configuration changes; the Docker install and the
+	// restart take as long as they take
+	if installDocker {
+		installed, err := installMissingDocker(context.WithoutCancel(ctx), manager, name)
+		if err != nil {
+			return err
+		}
+		restart = restart || installed
+	}
+
+	// Register existing proxy devices so port list recognizes them
+	ports := helpers.AdoptableProxyDevices(local.Config, local.Devices)
+	if len(ports) > 0 {
+		registered := append(helpers.SplitConfigList(local.Config[helpers.AdoptedPortsKey]), ports...)
+		if err := manager.SetConfigValue(ctx, name, helpers.AdoptedPortsKey, strings.Join(registered, ",")); err != nil {
+			return fmt.Errorf("failed to register proxy devices: %w", err)
+		}
+		logger.Info("Registered %d existing proxy device(s): %s", len(ports), strings.Join(ports, ", "))
+	}
+
+	// The marker goes last so a failed adopt can simply be retried
+	marker := helpers.ManagedMarkerConfig(version, time.Time{})
+	for _, key := range []string{helpers.ManagedVersionKey, helpers.ManagedMarkerKey} {
+		if err := manager.SetConfigValue(ctx, name, key, marker[key]); err != nil {
+			return fmt.Errorf("failed to tag container as managed: %w", err)
+		}
+	}
+
+	if restart {
+		logger.Info("Restarting container to apply all settings...")
+		if err := manager.RestartContainer(context.WithoutCancel(ctx), name); err != nil {
+			return fmt.Errorf("failed to restart container: %w", err)
+		}
+	}
+
+	logger.Info("Container '%s' is now managed by lxc-go-cli", name)
+	return nil
+}
+
+// installMissingDocker installs Docker and the 'app' user when they are absent,
+// reporting whether anything was installed
+func installMissingDocker(ctx context.Context, manager AdoptManager, name string) (bool, error) {
+	installed := false
+
+	if err := manager.RunInContainer(ctx, name, "sh", "-c", "command -v docker"); err != nil {
+		logger.Info("Docker not found. Installing Docker and Docker Compose V2...")
+		if err := manager.RunInContainer(ctx, name, "apt-get", "update"); err != nil {
+			return false, fmt.Errorf("failed to update package index: %w", err)
+		}
+		if err := helpers.InstallDockerInContainer(ctx, manager, name); err != nil {
+			return false, fmt.Errorf("failed to install Docker: %w", err)
+		}
+		installed = true
+	} else {
+		logger.Info("Docker is already installed")
+	}
+
+	if err := manager.RunInContainer(ctx, name, "id", "app"); err != nil {
+		logger.Info("Creating 'app' user...")
+		if err := setupAppUser(ctx, manager, name, ""); err != nil {
+			return installed, err
+		}
+		installed = true
+	} else if installed {
+		logger.Info("Adding existing 'app' user to docker group...")
+		if err := manager.RunInContainer(ctx, name, "usermod", "-aG", "docker", "app"); err != nil {
+			return installed, fmt.Errorf("failed to add 'app' user to docker group: %w", err)
+		}
+	} else {
+		logger.Info("'app' user already exists")
+	}
+
+	return installed, nil
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().BoolVar(&adoptInstallDocker, "install-docker", false, "Install Docker and the 'app' user if missing")
+	adoptCmd.Flags().DurationVarP(&adoptTimeout, "timeout", "t", 30*time.Second, "Timeout for reading and updating container configuration")
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockAdoptManager for testing adopt command
+type MockAdoptManager struct {
+	ExistingContainers map[string]bool
+	LocalConfig        string
+	ExpandedConfig     string
+	MissingCommands    map[string]bool
+	SetValues          map[string]string
+	Commands           []string
+	Calls              map[string]int
+}
+
+func (m *MockAdoptManager) ContainerExists(name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockAdoptManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return []byte(m.LocalConfig), nil
+}
+
+func (m *MockAdoptManager) GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return []byte(m.ExpandedConfig), nil
+}
+
+func (m *MockAdoptManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	if m.SetValues == nil {
+		m.SetValues = make(map[string]string)
+	}
+	m.SetValues[key] = value
+	return nil
+}
+
+func (m *MockAdoptManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	command := strings.Join(args, " ")
+	m.Commands = append(m.Commands, command)
+	if m.MissingCommands[command] {
+		return fmt.Errorf("exit status 1")
+	}
+	return nil
+}
+
+func (m *MockAdoptManager) SetUserPassword(containerName, username, password string) error {
+	m.trackCall("SetUserPassword")
+	return nil
+}
+
+func (m *MockAdoptManager) StoreContainerPassword(containerName, password string) error {
+	m.trackCall("StoreContainerPassword")
+	return nil
+}
+
+func (m *MockAdoptManager) RestartContainer(ctx context.Context, name string) error {
+	m.trackCall("RestartContainer")
+	return nil
+}
+
+func (m *MockAdoptManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func (m *MockAdoptManager) ran(prefix string) bool {
+	for _, command := range m.Commands {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+const adoptLocalConfig = `config:
+  security.nesting: "true"
+devices:
+  web:
+    type: proxy
+    listen: tcp:0.0.0.0:8080
+    connect: tcp:127.0.0.1:80
+  socket:
+    type: proxy
+    listen: unix:/run/app.sock
+    connect: unix:/run/app.sock
+  legacy-8443-443-tcp:
+    type: proxy
+    listen: tcp:0.0.0.0:8443
+    connect: tcp:0.0.0.0:443
+`
+
+func TestAdoptContainer(t *testing.T) {
+	manager := &MockAdoptManager{
+		ExistingContainers: map[string]bool{"legacy": true},
+		LocalConfig:        adoptLocalConfig,
+		ExpandedConfig:     adoptLocalConfig,
+	}
+
+	if err := adoptContainer(context.Background(), manager, "legacy", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if manager.SetValues[helpers.ManagedMarkerKey] != "true" {
+		t.Error("container should be tagged as managed")
+	}
+	if _, ok := manager.SetValues["security.nesting"]; ok {
+		t.Error("existing security.nesting should not be set again")
+	}
+	if manager.SetValues["security.syscalls.intercept.mknod"] != "true" {
+		t.Error("missing mknod interception should be applied")
+	}
+	if ports := manager.SetValues[helpers.AdoptedPortsKey]; ports != "legacy-8443-443-tcp,web" {
+		t.Errorf("expected TCP proxy devices to be registered, got '%s'", ports)
+	}
+	if manager.Calls["RestartContainer"] != 1 {
+		t.Error("container should be restarted after security changes")
+	}
+	if len(manager.Commands) != 0 {
+		t.Errorf("nothing should run in the container without --install-docker, got %v", manager.Commands)
+	}
+}
+
+func TestAdoptContainerInstallDocker(t *testing.T) {
+	config := `config:
+  security.nesting: "true"
+  security.syscalls.intercept.mknod: "true"
+  security.syscalls.intercept.setxattr: "true"
+`
+	manager := &MockAdoptManager{
+		ExistingContainers: map[string]bool{"legacy": true},
+		LocalConfig:        config,
+		ExpandedConfig:     config,
+		MissingCommands:    map[string]bool{"sh -c command -v docker": true, "id app": true},
+	}
+
+	if err := adoptContainer(context.Background(), manager, "legacy", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !manager.ran("apt-get install -y sudo docker-ce") {
+		t.Errorf("expected Docker to be installed, got %v", manager.Commands)
+	}
+	if !manager.ran("useradd -m -s /bin/bash app") {
+		t.Errorf("expected app user to be created, got %v", manager.Commands)
+	}
+	if manager.Calls["RestartContainer"] != 1 {
+		t.Error("container should be restarted after installing Docker")
+	}
+}
+
+func TestAdoptContainerExistingDockerUser(t *testing.T) {
+	config := "config:\n  security.nesting: \"true\"\n  security.syscalls.intercept.mknod: \"true\"\n  security.syscalls.intercept.setxattr: \"true\"\n"
+	manager := &MockAdoptManager{
+		ExistingContainers: map[string]bool{"legacy": true},
+		LocalConfig:        config,
+		ExpandedConfig:     config,
+	}
+
+	if err := adoptContainer(context.Background(), manager, "legacy", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.ran("apt-get") || manager.ran("useradd") {
+		t.Errorf("nothing should be installed, got %v", manager.Commands)
+	}
+	if manager.Calls["RestartContainer"] != 0 {
+		t.Error("container should not be restarted when nothing changed")
+	}
+}
+
+func TestAdoptContainerErrors(t *testing.T) {
+	manager := &MockAdoptManager{ExistingContainers: map[string]bool{}}
+	err := adoptContainer(context.Background(), manager, "ghost", false)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+
+	managed := "config:\n  user.lxc-go-cli.managed: \"true\"\n"
+	manager = &MockAdoptManager{
+		ExistingContainers: map[string]bool{"web": true},
+		LocalConfig:        managed,
+		ExpandedConfig:     managed,
+	}
+	if err := adoptContainer(context.Background(), manager, "web", false); err != nil {
+		t.Fatalf("expected no error for managed container, got %v", err)
+	}
+	if len(manager.SetValues) != 0 {
+		t.Errorf("managed container should not be modified, got %v", manager.SetValues)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// aliasAnnotation marks commands registered from aliases; its value is the
+// command line the alias runs
+const aliasAnnotation = "lxc-go-cli/alias"
+
+// reservedCommandNames are added by cobra while executing, so they are not
+// yet in the command tree when aliases are set or registered
+var reservedCommandNames = []string{"help", "completion"}
+
+// aliasCmd represents the alias command
+var aliasCmd = &cobra.Command{
+	Use:   "alias <set|list|remove>",
+	Short: "Define shortcuts for common command lines",
+	Long: `Define shortcuts for command lines you run often. An alias becomes a
+command of its own: it runs its command line with any extra arguments
+appended, and is listed in the help.
+
+Aliases are saved in this tool's config file. The command line is quoted as in
+a shell and must start with a command of this tool; aliases cannot replace
+built-in commands.
+
+Available subcommands:
+  set     - Define or replace an alias
+  list    - List aliases and the command lines they run
+  remove  - Delete an alias
+
+Examples:
+  lxc-go-cli alias set weblogs "logs web --docker app --follow"
+  lxc-go-cli weblogs
+  lxc-go-cli alias set webps "exec web -- docker ps"
+  lxc-go-cli alias remove weblogs`,
+}
+
+// aliasSetCmd represents the alias set subcommand
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <command-line>",
+	Short: "Define or replace an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setAlias(&DefaultSettingsManager{}, rootCmd, args[0], args[1])
+	},
+}
+
+// aliasListCmd represents the alias list subcommand
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases and the command lines they run",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listAliases(&DefaultSettingsManager{}, cmd.OutOrStdout())
+	},
+}
+
+// aliasRemoveCmd represents the alias remove subcommand
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Delete an alias",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return removeAlias(&DefaultSettingsManager{}, args[0])
+	},
+}
+
+// SettingsManager loads and saves this tool's config file
+type SettingsManager interface {
+	LoadSettings() (*helpers.Settings, error)
+	SaveSettings(settings *helpers.Settings) error
+}
+
+// DefaultSettingsManager implements SettingsManager using helpers
+type DefaultSettingsManager struct{}
+
+func (d *DefaultSettingsManager) LoadSettings() (*helpers.Settings, error) {
+	return helpers.LoadSettings()
+}
+
+func (d *DefaultSettingsManager) SaveSettings(settings *helpers.Settings) error {
+	return helpers.SaveSettings(settings)
+}
+
+// builtinCommand returns the built-in top-level command called name, or nil
+func builtinCommand(root *cobra.Command, name string) *cobra.Command {
+	for _, cmd := range root.Commands() {
+		if _, isAlias := cmd.Annotations[aliasAnnotation]; isAlias {
+			continue
+		}
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// isBuiltinCommand reports whether name is taken by a built-in command
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	for _, reserved := range reservedCommandNames {
+		if name == reserved {
+			return true
+		}
+	}
+	return builtinCommand(root, name) != nil
+}
+
+// parseAlias splits an alias command line and checks it runs a built-in command
+func parseAlias(root *cobra.Command, line string) ([]string, error) {
+	words, err := helpers.SplitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("the command line of an alias cannot be empty")
+	}
+	if !isBuiltinCommand(root, words[0]) {
+		return nil, fmt.Errorf("'%s' is not a command of lxc-go-cli; an alias must start with one, e.g. 'logs'", words[0])
+	}
+	return words, nil
+}
+
+// setAlias saves an alias after checking its name and command line
+func setAlias(manager SettingsManager, root *cobra.Command, name, line string) error {
+	if err := helpers.ValidateAliasName(name); err != nil {
+		return err
+	}
+	if isBuiltinCommand(root, name) {
+		return fmt.Errorf("'%s' is a built-in command and cannot be an alias", name)
+	}
+	if _, err := parseAlias(root, line); err != nil {
+		return err
+	}
+
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	previous, replaced := settings.Aliases[name]
+	if settings.Aliases == nil {
+		settings.Aliases = make(map[string]string)
+	}
+	settings.Aliases[name] = line
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+
+	if replaced && previous != line {
+		logger.Info("Alias '%s' replaced (was '%s')", name, previous)
+	}
+	logger.Info("'lxc-go-cli %s' now runs 'lxc-go-cli %s'", name, line)
+	return nil
+}
+
+// listAliases prints the aliases sorted by name
+func listAliases(manager SettingsManager, out io.Writer) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if len(settings.Aliases) == 0 {
+		fmt.Fprintln(out, "No aliases defined")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCOMMAND")
+	for _, name := range sortedAliasNames(settings.Aliases) {
+		fmt.Fprintf(w, "%s\t%s\n", name, settings.Aliases[name])
+	}
+	return w.Flush()
+}
+
+// removeAlias deletes an alias
+func removeAlias(manager SettingsManager, name string) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if _, exists := settings.Aliases[name]; !exists {
+		return helpers.NotFoundErrorf("alias '%s' does not exist", name)
+	}
+
+	delete(settings.Aliases, name)
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+	logger.Info("Alias '%s' removed", name)
+	return nil
+}
+
+// sortedAliasNames returns the alias names in order
+func sortedAliasNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registerAliases adds a command for each saved alias. Aliases that clash
+// with built-in commands or no longer parse, e.g. after editing the config
+// by hand, are skipped.
+func registerAliases(root *cobra.Command, aliases map[string]string) {
+	for _, name := range sortedAliasNames(aliases) {
+		line := aliases[name]
+		if err := helpers.ValidateAliasName(name); err != nil {
+			logger.Debug("Skipping alias '%s': %v", name, err)
+			continue
+		}
+		if isBuiltinCommand(root, name) {
+			logger.Debug("Skipping alias '%s': a built-in command has that name", name)
+			continue
+		}
+		words, err := parseAlias(root, line)
+		if err != nil {
+			logger.Debug("Skipping alias '%s': %v", name, err)
+			continue
+		}
+		root.AddCommand(aliasCommand(root, name, line, words))
+	}
+}
+
+// aliasCommand returns the command that runs an alias: the root command is
+// executed again with the alias's words followed by the given arguments
+func aliasCommand(root *cobra.Command, name, line string, words []string) *cobra.Command {
+	return &cobra.Command{
+		Use:   name + " [args...]",
+		Short: fmt.Sprintf("Alias for '%s'", line),
+		// Flags belong to the expanded command
+		DisableFlagParsing: true,
+		// The expanded command reports its own errors and usage
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		Annotations: map[string]string{
+			aliasAnnotation:        line,
+			skipLXCCheckAnnotation: "true",
+		},
+		// The expanded command applies the driver, context and checks itself
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expanded := append(append([]string(nil), words...), args...)
+			logger.Debug("Alias '%s' runs: %s", name, strings.Join(expanded, " "))
+			root.SetArgs(expanded)
+			return root.Execute()
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+	// Aliases only touch this tool's config file
+	skipLXCCheck(aliasCmd)
+}
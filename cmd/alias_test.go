@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+// MockSettingsManager keeps settings in memory for testing
+type MockSettingsManager struct {
+	Settings helpers.Settings
+	Saved    bool
+}
+
+func (m *MockSettingsManager) LoadSettings() (*helpers.Settings, error) {
+	settings := m.Settings
+	return &settings, nil
+}
+
+func (m *MockSettingsManager) SaveSettings(settings *helpers.Settings) error {
+	m.Settings = *settings
+	m.Saved = true
+	return nil
+}
+
+// newAliasTestRoot returns a command tree whose 'logs' command records its arguments
+func newAliasTestRoot(got *[]string) *cobra.Command {
+	root := &cobra.Command{Use: "lxc-go-cli", SilenceErrors: true, SilenceUsage: true}
+	var follow bool
+	logs := &cobra.Command{
+		Use:     "logs",
+		Aliases: []string{"log"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			*got = append(args, "follow="+map[bool]string{true: "yes", false: "no"}[follow])
+			return nil
+		},
+	}
+	logs.Flags().BoolVar(&follow, "follow", false, "")
+	root.AddCommand(logs)
+	return root
+}
+
+func TestSetAlias(t *testing.T) {
+	var got []string
+	root := newAliasTestRoot(&got)
+	manager := &MockSettingsManager{}
+
+	if err := setAlias(manager, root, "weblogs", "logs web --follow"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Settings.Aliases["weblogs"] != "logs web --follow" {
+		t.Errorf("expected the alias to be saved, got %v", manager.Settings.Aliases)
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"logs", "logs web", "built-in command"},
+		{"log", "logs web", "built-in command"},
+		{"help", "logs web", "built-in command"},
+		{"web logs", "logs web", "invalid alias name"},
+		{"x", "weblogs --follow", "not a command of lxc-go-cli"},
+		{"x", "", "cannot be empty"},
+		{"x", "logs 'web", "unterminated"},
+	}
+	for _, tt := range tests {
+		manager := &MockSettingsManager{}
+		if err := setAlias(manager, root, tt.name, tt.line); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s=%q: expected %q, got %v", tt.name, tt.line, tt.want, err)
+		}
+		if manager.Saved {
+			t.Errorf("%s=%q: expected nothing to be saved", tt.name, tt.line)
+		}
+	}
+}
+
+func TestListAndRemoveAliases(t *testing.T) {
+	manager := &MockSettingsManager{}
+	var out bytes.Buffer
+	if err := listAliases(manager, &out); err != nil || !strings.Contains(out.String(), "No aliases defined") {
+		t.Errorf("unexpected output %q (%v)", out.String(), err)
+	}
+
+	manager.Settings.Aliases = map[string]string{"webps": "exec web -- docker ps", "weblogs": "logs web --follow"}
+	out.Reset()
+	if err := listAliases(manager, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "weblogs") || !strings.HasPrefix(lines[2], "webps") {
+		t.Errorf("expected aliases sorted by name, got:\n%s", out.String())
+	}
+
+	if err := removeAlias(manager, "weblogs"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, exists := manager.Settings.Aliases["weblogs"]; exists {
+		t.Error("expected the alias to be removed")
+	}
+	if err := removeAlias(manager, "weblogs"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing alias error, got %v", err)
+	}
+}
+
+func TestRegisterAliases(t *testing.T) {
+	var got []string
+	root := newAliasTestRoot(&got)
+	registerAliases(root, map[string]string{
+		"weblogs": "logs web --follow",
+		"logs":    "logs db",
+		"broken":  "logs 'web",
+		"loop":    "loop",
+	})
+
+	names := []string{}
+	for _, cmd := range root.Commands() {
+		names = append(names, cmd.Name())
+	}
+	if strings.Join(names, ",") != "logs,weblogs" {
+		t.Errorf("expected only the valid alias to be registered, got %v", names)
+	}
+
+	root.SetArgs([]string{"weblogs", "--since", "1h"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "unknown flag: --since") {
+		t.Errorf("expected the expanded command to parse the extra flags, got %v", err)
+	}
+
+	root.SetArgs([]string{"weblogs", "extra"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(got, " ") != "web extra follow=yes" {
+		t.Errorf("expected the alias to expand with extra arguments appended, got %v", got)
+	}
+}
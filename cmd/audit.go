@@ -0,0 +1,233 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	auditTimeout time.Duration
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit <security>",
+	Short: "Audit container configuration",
+	Long: `Audit container configuration against recommended settings.
+
+Available subcommands:
+  security - Check the security profile of a Docker-in-LXC container
+
+Examples:
+  lxc-go-cli audit security mycontainer`,
+}
+
+// auditSecurityCmd represents the audit security subcommand
+var auditSecurityCmd = &cobra.Command{
+	Use:   "security <container-name>",
+	Short: "Check the security profile of a container",
+	Long: `Check the security profile of a Docker-in-LXC container.
+
+Checks performed:
+  - Nesting and syscall interception needed by Docker are enabled
+  - The container is not privileged unless it needs to be (GPU access)
+  - Whether the app password is stored in instance metadata
+  - The container has an isolated uid/gid map
+
+Settings inherited from profiles are taken into account. Each problem is
+reported with the command that fixes it. The command exits with an error if
+any check fails.
+
+Examples:
+  lxc-go-cli audit security mycontainer`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+		defer cancel()
+
+		manager := &DefaultAuditManager{}
+		return auditSecurity(ctx, manager, args[0], cmd.OutOrStdout())
+	},
+}
+
+// AuditManager interface for dependency injection
+type AuditManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+}
+
+// DefaultAuditManager implements AuditManager using helpers
+type DefaultAuditManager struct{}
+
+func (d *DefaultAuditManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultAuditManager) GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetExpandedContainerConfig(ctx, containerName)
+}
+
+// auditConfig is the parsed expanded configuration a security audit looks at
+type auditConfig struct {
+	Config  map[string]string            `yaml:"config"`
+	Devices map[string]map[string]string `yaml:"devices"`
+}
+
+// hasDeviceType reports whether the container has a device of the given type
+func (c *auditConfig) hasDeviceType(deviceType string) bool {
+	for _, device := range c.Devices {
+		if device["type"] == deviceType {
+			return true
+		}
+	}
+	return false
+}
+
+// securityCheck inspects one aspect of a container's configuration
+type securityCheck func(containerName string, config *auditConfig) CheckResult
+
+// securityChecks lists the checks run by audit security, in order
+var securityChecks = []securityCheck{
+	checkNesting,
+	checkSyscallIntercept("mknod"),
+	checkSyscallIntercept("setxattr"),
+	checkPrivileged,
+	checkPasswordMetadata,
+	checkIdmapIsolation,
+}
+
+// auditSecurity runs all security checks against a container and prints a report
+func auditSecurity(ctx context.Context, manager AuditManager, containerName string, out io.Writer) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	data, err := manager.GetExpandedContainerConfig(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container configuration: %w", err)
+	}
+
+	var config auditConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+
+	results := make([]CheckResult, 0, len(securityChecks))
+	for _, check := range securityChecks {
+		results = append(results, check(containerName, &config))
+	}
+
+	fmt.Fprintf(out, "Security audit for container '%s':\n", containerName)
+	fmt.Fprint(out, formatCheckResults(results, render.StyleFor(out)))
+
+	if failed := countFailedChecks(results); failed > 0 {
+		return i18n.Errorf("checks.failed", failed)
+	}
+	return nil
+}
+
+// checkNesting verifies that nesting is enabled, which Docker requires
+func checkNesting(containerName string, config *auditConfig) CheckResult {
+	if config.Config["security.nesting"] == "true" {
+		return CheckResult{Name: "nesting", Status: CheckPass, Message: "security.nesting is enabled"}
+	}
+	return CheckResult{
+		Name:        "nesting",
+		Status:      CheckFail,
+		Message:     "security.nesting is disabled; Docker cannot run",
+		Remediation: fmt.Sprintf("lxc config set %s security.nesting true && lxc restart %s", containerName, containerName),
+	}
+}
+
+// checkSyscallIntercept verifies that a syscall used by Docker image layers is intercepted
+func checkSyscallIntercept(syscall string) securityCheck {
+	key := "security.syscalls.intercept." + syscall
+	return func(containerName string, config *auditConfig) CheckResult {
+		name := fmt.Sprintf("%s interception", syscall)
+		if config.Config[key] == "true" {
+			return CheckResult{Name: name, Status: CheckPass, Message: fmt.Sprintf("%s is enabled", key)}
+		}
+		return CheckResult{
+			Name:        name,
+			Status:      CheckWarn,
+			Message:     fmt.Sprintf("%s is disabled; some images may fail to unpack", key),
+			Remediation: fmt.Sprintf("lxc config set %s %s true && lxc restart %s", containerName, key, containerName),
+		}
+	}
+}
+
+// checkPrivileged flags privileged containers that do not need to be
+func checkPrivileged(containerName string, config *auditConfig) CheckResult {
+	if config.Config["security.privileged"] != "true" {
+		return CheckResult{Name: "privileged", Status: CheckPass, Message: "container is unprivileged"}
+	}
+	if config.hasDeviceType("gpu") {
+		return CheckResult{
+			Name:        "privileged",
+			Status:      CheckWarn,
+			Message:     "container is privileged for GPU access; root in the container is root on the host",
+			Remediation: fmt.Sprintf("disable GPU access when no longer needed: lxc-go-cli gpu %s disable", containerName),
+		}
+	}
+	return CheckResult{
+		Name:        "privileged",
+		Status:      CheckFail,
+		Message:     "container is privileged without a GPU device; root in the container is root on the host",
+		Remediation: fmt.Sprintf("lxc config unset %s security.privileged && lxc restart %s", containerName, containerName),
+	}
+}
+
+// checkPasswordMetadata flags passwords stored in instance metadata, which is only
+// base64 encoded and readable by anyone with access to LXD
+func checkPasswordMetadata(containerName string, config *auditConfig) CheckResult {
+	if _, ok := config.Config["user.app-password"]; !ok {
+		return CheckResult{Name: "password metadata", Status: CheckPass, Message: "no password stored in instance metadata"}
+	}
+	return CheckResult{
+		Name:        "password metadata",
+		Status:      CheckWarn,
+		Message:     "app password is stored base64-encoded in user.app-password, readable by every member of the 'lxd' group",
+		Remediation: fmt.Sprintf("save the password elsewhere (lxc-go-cli password %s), then: lxc config unset %s user.app-password", containerName, containerName),
+	}
+}
+
+// checkIdmapIsolation checks whether the container has its own uid/gid range
+func checkIdmapIsolation(containerName string, config *auditConfig) CheckResult {
+	if config.Config["security.privileged"] == "true" {
+		return CheckResult{
+			Name:    "idmap isolation",
+			Status:  CheckWarn,
+			Message: "not applicable: privileged containers are not uid/gid mapped",
+		}
+	}
+	if config.Config["security.idmap.isolated"] == "true" {
+		return CheckResult{Name: "idmap isolation", Status: CheckPass, Message: "container has an isolated uid/gid map"}
+	}
+	return CheckResult{
+		Name:        "idmap isolation",
+		Status:      CheckWarn,
+		Message:     "container shares its uid/gid map with other unprivileged containers",
+		Remediation: fmt.Sprintf("lxc config set %s security.idmap.isolated true && lxc restart %s", containerName, containerName),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditSecurityCmd)
+
+	auditSecurityCmd.Flags().DurationVarP(&auditTimeout, "timeout", "t", 30*time.Second, "Timeout for the audit operation")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchDisk     bool
+	benchNetwork  bool
+	benchPath     string
+	benchSizeMB   int
+	benchDuration time.Duration
+	benchInstall  bool
+	benchOutput   string
+	benchTimeout  time.Duration
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench <container-name>",
+	Short: "Run quick disk and network benchmarks inside a container",
+	Long: `Run quick micro-benchmarks inside a container, e.g. to compare storage
+drivers and pools or to check the overhead of NIC passthrough.
+
+  disk     fio measures sequential throughput and 4k random IOPS with direct
+           I/O on a test file in --path; without fio, dd measures sequential
+           throughput only. The test file is removed afterwards.
+  network  iperf3 measures TCP throughput both ways between the container and
+           an iperf3 server started on the host at the container's gateway
+           for the duration of the test. Needs iperf3 on the host and a
+           local LXD server.
+
+Both run by default; --disk or --network runs only one. --install adds fio
+and iperf3 to the container with apt first.
+
+Examples:
+  lxc-go-cli bench web
+  lxc-go-cli bench web --install
+  lxc-go-cli bench web --disk --path /var/lib/docker --size-mb 1024
+  lxc-go-cli bench web --network --duration 10s --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), benchTimeout)
+		defer cancel()
+
+		opts := BenchOptions{
+			Disk:     benchDisk || !benchNetwork,
+			Network:  benchNetwork || !benchDisk,
+			Path:     benchPath,
+			SizeMB:   benchSizeMB,
+			Duration: benchDuration,
+			Install:  benchInstall,
+			Output:   benchOutput,
+		}
+		return runBench(ctx, &DefaultBenchManager{}, args[0], opts, cmd.OutOrStdout())
+	},
+}
+
+// BenchOptions selects and sizes the benchmarks
+type BenchOptions struct {
+	Disk     bool
+	Network  bool
+	Path     string
+	SizeMB   int
+	Duration time.Duration
+	// Install adds the benchmark tools to the container first
+	Install bool
+	// Output is text or json
+	Output string
+}
+
+// BenchReport is the result of a bench run; a benchmark that failed has
+// its error instead of a result
+type BenchReport struct {
+	Container    string                      `json:"container"`
+	Disk         *helpers.DiskBenchResult    `json:"disk,omitempty"`
+	DiskError    string                      `json:"disk_error,omitempty"`
+	Network      *helpers.NetworkBenchResult `json:"network,omitempty"`
+	NetworkError string                      `json:"network_error,omitempty"`
+}
+
+// BenchManager interface for dependency injection
+type BenchManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	InstallBenchTools(ctx context.Context, containerName string) error
+	BenchDisk(ctx context.Context, containerName, dir string, sizeMB int, runtime time.Duration) (*helpers.DiskBenchResult, error)
+	BenchNetwork(ctx context.Context, containerName string, duration time.Duration) (*helpers.NetworkBenchResult, error)
+}
+
+// DefaultBenchManager implements BenchManager using helpers
+type DefaultBenchManager struct{}
+
+func (d *DefaultBenchManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultBenchManager) InstallBenchTools(ctx context.Context, containerName string) error {
+	return helpers.InstallBenchTools(ctx, containerName)
+}
+
+func (d *DefaultBenchManager) BenchDisk(ctx context.Context, containerName, dir string, sizeMB int, runtime time.Duration) (*helpers.DiskBenchResult, error) {
+	return helpers.BenchDisk(ctx, containerName, dir, sizeMB, runtime)
+}
+
+func (d *DefaultBenchManager) BenchNetwork(ctx context.Context, containerName string, duration time.Duration) (*helpers.NetworkBenchResult, error) {
+	return helpers.BenchNetwork(ctx, containerName, duration)
+}
+
+// runBench runs the selected benchmarks and prints their results. One
+// failing benchmark does not stop the other; the command fails afterwards.
+func runBench(ctx context.Context, manager BenchManager, containerName string, opts BenchOptions, out io.Writer) error {
+	output := strings.ToLower(opts.Output)
+	if output != "text" && output != "json" {
+		return helpers.UsageErrorf("invalid output format '%s': must be text or json", opts.Output)
+	}
+	if opts.SizeMB < 1 {
+		return helpers.UsageErrorf("--size-mb must be at least 1")
+	}
+	if opts.Duration < time.Second {
+		return helpers.UsageErrorf("--duration must be at least 1s")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	if opts.Install {
+		logger.Info("Installing %s in container '%s'...", strings.Join(helpers.BenchTools, " and "), containerName)
+		if err := manager.InstallBenchTools(ctx, containerName); err != nil {
+			return err
+		}
+	}
+
+	report := BenchReport{Container: containerName}
+	failed := 0
+	if opts.Disk {
+		result, err := manager.BenchDisk(ctx, containerName, opts.Path, opts.SizeMB, opts.Duration)
+		if err != nil {
+			logger.Error("Disk benchmark failed: %v", err)
+			report.DiskError = err.Error()
+			failed++
+		}
+		report.Disk = result
+	}
+	if opts.Network {
+		result, err := manager.BenchNetwork(ctx, containerName, opts.Duration)
+		if err != nil {
+			logger.Error("Network benchmark failed: %v", err)
+			report.NetworkError = err.Error()
+			failed++
+		}
+		report.Network = result
+	}
+
+	if output == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode benchmark results: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		fmt.Fprint(out, formatBenchReport(report))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d benchmark(s) failed", failed)
+	}
+	return nil
+}
+
+// formatBenchReport renders benchmark results for people
+func formatBenchReport(report BenchReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Benchmark of '%s'\n", report.Container)
+	if disk := report.Disk; disk != nil {
+		fmt.Fprintf(&sb, "Disk (%s, %s):\n", disk.Tool, disk.Path)
+		fmt.Fprintf(&sb, "  Sequential write:   %.1f MB/s\n", disk.SeqWriteMBps)
+		fmt.Fprintf(&sb, "  Sequential read:    %.1f MB/s\n", disk.SeqReadMBps)
+		if disk.Tool == "fio" {
+			fmt.Fprintf(&sb, "  Random read (4k):   %.0f IOPS\n", disk.RandReadIOPS)
+			fmt.Fprintf(&sb, "  Random write (4k):  %.0f IOPS\n", disk.RandWriteIOPS)
+		}
+	} else if report.DiskError != "" {
+		fmt.Fprintf(&sb, "Disk: failed: %s\n", report.DiskError)
+	}
+	if network := report.Network; network != nil {
+		fmt.Fprintf(&sb, "Network (iperf3, host %s):\n", network.Server)
+		fmt.Fprintf(&sb, "  Container -> host:  %.1f Mbit/s\n", network.UploadMbps)
+		fmt.Fprintf(&sb, "  Host -> container:  %.1f Mbit/s\n", network.DownloadMbps)
+		fmt.Fprintf(&sb, "  Retransmits:        %d\n", network.Retransmits)
+	} else if report.NetworkError != "" {
+		fmt.Fprintf(&sb, "Network: failed: %s\n", report.NetworkError)
+	}
+	return sb.String()
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().BoolVar(&benchDisk, "disk", false, "Only run the disk benchmark")
+	benchCmd.Flags().BoolVar(&benchNetwork, "network", false, "Only run the network benchmark")
+	benchCmd.Flags().StringVar(&benchPath, "path", helpers.DefaultBenchDir, "Directory in the container the disk benchmark writes to")
+	benchCmd.Flags().IntVar(&benchSizeMB, "size-mb", 256, "Size of the disk benchmark's test file in MB")
+	benchCmd.Flags().DurationVar(&benchDuration, "duration", 5*time.Second, "How long each fio job and iperf3 direction runs")
+	benchCmd.Flags().BoolVar(&benchInstall, "install", false, "Install fio and iperf3 in the container with apt first")
+	benchCmd.Flags().StringVarP(&benchOutput, "output", "o", "text", "Output format (text, json)")
+	benchCmd.Flags().DurationVarP(&benchTimeout, "timeout", "t", 10*time.Minute, "Timeout for the benchmarks")
+}
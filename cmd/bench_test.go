@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockBenchManager for testing the bench command
+type MockBenchManager struct {
+	Installed    bool
+	DiskError    error
+	NetworkError error
+	DiskCalls    int
+	NetworkCalls int
+	DiskPath     string
+}
+
+func (m *MockBenchManager) ContainerExists(ctx context.Context, name string) bool {
+	return name == "web"
+}
+
+func (m *MockBenchManager) InstallBenchTools(ctx context.Context, containerName string) error {
+	m.Installed = true
+	return nil
+}
+
+func (m *MockBenchManager) BenchDisk(ctx context.Context, containerName, dir string, sizeMB int, runtime time.Duration) (*helpers.DiskBenchResult, error) {
+	m.DiskCalls++
+	m.DiskPath = dir
+	if m.DiskError != nil {
+		return nil, m.DiskError
+	}
+	return &helpers.DiskBenchResult{Tool: "fio", Path: dir, SeqWriteMBps: 512.34, SeqReadMBps: 1024, RandReadIOPS: 20000, RandWriteIOPS: 9000}, nil
+}
+
+func (m *MockBenchManager) BenchNetwork(ctx context.Context, containerName string, duration time.Duration) (*helpers.NetworkBenchResult, error) {
+	m.NetworkCalls++
+	if m.NetworkError != nil {
+		return nil, m.NetworkError
+	}
+	return &helpers.NetworkBenchResult{Server: "10.10.0.1:40000", UploadMbps: 9400, DownloadMbps: 9100, Retransmits: 2}, nil
+}
+
+func defaultBenchOptions() BenchOptions {
+	return BenchOptions{Disk: true, Network: true, Path: "/var/tmp", SizeMB: 256, Duration: 5 * time.Second, Output: "text"}
+}
+
+func TestRunBench(t *testing.T) {
+	manager := &MockBenchManager{}
+	var out bytes.Buffer
+	if err := runBench(context.Background(), manager, "web", defaultBenchOptions(), &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{"Disk (fio, /var/tmp):", "Sequential write:   512.3 MB/s", "Random read (4k):   20000 IOPS",
+		"Network (iperf3, host 10.10.0.1:40000):", "Host -> container:  9100.0 Mbit/s", "Retransmits:        2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+	if manager.Installed {
+		t.Error("expected no install without --install")
+	}
+}
+
+func TestRunBenchSelectionAndFailures(t *testing.T) {
+	manager := &MockBenchManager{NetworkError: fmt.Errorf("iperf3 is not installed on the host")}
+	opts := defaultBenchOptions()
+	opts.Install = true
+	opts.Output = "json"
+
+	var out bytes.Buffer
+	err := runBench(context.Background(), manager, "web", opts, &out)
+	if err == nil || !strings.Contains(err.Error(), "1 benchmark(s) failed") {
+		t.Errorf("expected a failed benchmark, got %v", err)
+	}
+	var report BenchReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out.String(), err)
+	}
+	if report.Disk == nil || report.Network != nil || !strings.Contains(report.NetworkError, "not installed on the host") || !manager.Installed {
+		t.Errorf("expected the disk result and the network error, got %+v", report)
+	}
+
+	manager = &MockBenchManager{}
+	opts = defaultBenchOptions()
+	opts.Network = false
+	if err := runBench(context.Background(), manager, "web", opts, &out); err != nil || manager.NetworkCalls != 0 || manager.DiskCalls != 1 {
+		t.Errorf("expected only the disk benchmark, got %v (%+v)", err, manager)
+	}
+}
+
+func TestRunBenchValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*BenchOptions)
+		target  string
+		wantErr string
+	}{
+		{"missing container", func(o *BenchOptions) {}, "db", "does not exist"},
+		{"bad output", func(o *BenchOptions) { o.Output = "yaml" }, "web", "invalid output format"},
+		{"zero size", func(o *BenchOptions) { o.SizeMB = 0 }, "web", "--size-mb"},
+		{"short duration", func(o *BenchOptions) { o.Duration = time.Millisecond }, "web", "--duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaultBenchOptions()
+			tt.modify(&opts)
+			err := runBench(context.Background(), &MockBenchManager{}, tt.target, opts, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBenchCommandFlags(t *testing.T) {
+	for _, name := range []string{"disk", "network", "path", "size-mb", "duration", "install", "output", "timeout"} {
+		if benchCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+	var manager BenchManager = &DefaultBenchManager{}
+	_ = manager
+}
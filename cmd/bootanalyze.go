@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootAnalyzeTop     int
+	bootAnalyzeOutput  string
+	bootAnalyzeTimeout time.Duration
+)
+
+// bootAnalyzeCmd represents the boot-analyze command
+var bootAnalyzeCmd = &cobra.Command{
+	Use:   "boot-analyze <container-name>",
+	Short: "Report which services slow down a container's startup",
+	Long: `Report how the last start of a running container went and which services
+slowed it down.
+
+The report splits the time from LXD starting the container to systemd
+reaching its default target into:
+
+  LXD start to systemd   from LXD starting the container to systemd starting
+                         in it, e.g. storage, idmap shifting and devices
+  systemd to boot done   from systemd starting to the boot finishing
+
+followed by the slowest units from systemd-analyze blame and the chain of
+units the boot waited for from systemd-analyze critical-chain. Needs systemd
+248 or later in the container, e.g. Ubuntu 22.04 or Debian 12.
+
+Examples:
+  lxc-go-cli boot-analyze web
+  lxc-go-cli boot-analyze web --top 20
+  lxc-go-cli boot-analyze web --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), bootAnalyzeTimeout)
+		defer cancel()
+		return bootAnalyze(ctx, &DefaultBootAnalyzeManager{}, args[0], bootAnalyzeTop, bootAnalyzeOutput, cmd.OutOrStdout())
+	},
+}
+
+// BootAnalyzeManager interface for dependency injection
+type BootAnalyzeManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	AnalyzeBoot(ctx context.Context, containerName string) (*helpers.BootReport, error)
+}
+
+// DefaultBootAnalyzeManager implements BootAnalyzeManager using helpers
+type DefaultBootAnalyzeManager struct{}
+
+func (d *DefaultBootAnalyzeManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultBootAnalyzeManager) AnalyzeBoot(ctx context.Context, containerName string) (*helpers.BootReport, error) {
+	return helpers.AnalyzeBoot(ctx, containerName)
+}
+
+// bootAnalyze prints how the last start of a container went, with the top
+// slowest units
+func bootAnalyze(ctx context.Context, manager BootAnalyzeManager, containerName string, top int, output string, out io.Writer) error {
+	output = strings.ToLower(output)
+	if output != "text" && output != "json" {
+		return helpers.UsageErrorf("invalid output format '%s': must be text or json", output)
+	}
+	if top < 1 {
+		return helpers.UsageErrorf("--top must be at least 1")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	report, err := manager.AnalyzeBoot(ctx, containerName)
+	if err != nil {
+		return err
+	}
+	if len(report.Units) > top {
+		report.Units = report.Units[:top]
+	}
+
+	if output == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode boot report: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	fmt.Fprint(out, formatBootReport(*report))
+	return nil
+}
+
+// formatBootReport renders a boot report for people
+func formatBootReport(report helpers.BootReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Boot of '%s', started %s\n", report.Container, report.Started.Local().Format(time.DateTime))
+	fmt.Fprintf(&sb, "  LXD start to systemd:  %s\n", formatBootDuration(report.InitDelay()))
+	if report.Finished.IsZero() {
+		fmt.Fprintf(&sb, "  systemd to boot done:  still booting\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "  systemd to boot done:  %s\n", formatBootDuration(report.SystemdDuration()))
+	fmt.Fprintf(&sb, "  Total:                 %s\n", formatBootDuration(report.Total()))
+
+	if len(report.Units) > 0 {
+		fmt.Fprintf(&sb, "\nSlowest units:\n")
+		for _, unit := range report.Units {
+			fmt.Fprintf(&sb, "  %9s  %s\n", formatBootDuration(time.Duration(unit.Seconds*float64(time.Second))), unit.Unit)
+		}
+	}
+	if len(report.CriticalChain) > 0 {
+		fmt.Fprintf(&sb, "\nCritical chain:\n")
+		for _, line := range report.CriticalChain {
+			fmt.Fprintf(&sb, "  %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+// formatBootDuration rounds a duration to milliseconds, e.g. "1.234s"
+func formatBootDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+func init() {
+	rootCmd.AddCommand(bootAnalyzeCmd)
+
+	bootAnalyzeCmd.Flags().IntVar(&bootAnalyzeTop, "top", 10, "Number of slowest units to show")
+	bootAnalyzeCmd.Flags().StringVarP(&bootAnalyzeOutput, "output", "o", "text", "Output format (text, json)")
+	bootAnalyzeCmd.Flags().DurationVarP(&bootAnalyzeTimeout, "timeout", "t", 2*time.Minute, "Timeout for the analysis")
+}
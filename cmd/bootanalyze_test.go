@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockBootAnalyzeManager for testing the boot-analyze command
+type MockBootAnalyzeManager struct {
+	Report *helpers.BootReport
+	Error  error
+}
+
+func (m *MockBootAnalyzeManager) ContainerExists(ctx context.Context, name string) bool {
+	return name == "web"
+}
+
+func (m *MockBootAnalyzeManager) AnalyzeBoot(ctx context.Context, containerName string) (*helpers.BootReport, error) {
+	return m.Report, m.Error
+}
+
+func testBootReport() *helpers.BootReport {
+	started := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &helpers.BootReport{
+		Container:   "web",
+		Started:     started,
+		InitStarted: started.Add(1250 * time.Millisecond),
+		Finished:    started.Add(8750 * time.Millisecond),
+		Units: []helpers.BootUnit{
+			{Unit: "cloud-init.service", Seconds: 4.1},
+			{Unit: "docker.service", Seconds: 2.345},
+			{Unit: "systemd-journald.service", Seconds: 0.1},
+		},
+		CriticalChain: []string{"multi-user.target @7.5s", "└─docker.service @3.2s +4.2s"},
+	}
+}
+
+func TestBootAnalyze(t *testing.T) {
+	var out bytes.Buffer
+	err := bootAnalyze(context.Background(), &MockBootAnalyzeManager{Report: testBootReport()}, "web", 2, "text", &out)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"LXD start to systemd:  1.25s",
+		"systemd to boot done:  7.5s",
+		"Total:                 8.75s",
+		"     4.1s  cloud-init.service",
+		"   2.345s  docker.service",
+		"└─docker.service @3.2s +4.2s",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "systemd-journald.service") {
+		t.Errorf("expected --top 2 to leave out the third unit:\n%s", out.String())
+	}
+}
+
+func TestBootAnalyzeStillBooting(t *testing.T) {
+	report := testBootReport()
+	report.Finished = time.Time{}
+	report.Units, report.CriticalChain = nil, nil
+	var out bytes.Buffer
+	if err := bootAnalyze(context.Background(), &MockBootAnalyzeManager{Report: report}, "web", 10, "text", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "still booting") || strings.Contains(out.String(), "Total") {
+		t.Errorf("expected an unfinished boot, got:\n%s", out.String())
+	}
+}
+
+func TestBootAnalyzeJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := bootAnalyze(context.Background(), &MockBootAnalyzeManager{Report: testBootReport()}, "web", 1, "json", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var report helpers.BootReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out.String())
+	}
+	if len(report.Units) != 1 || report.Units[0].Unit != "cloud-init.service" {
+		t.Errorf("expected only the slowest unit, got %+v", report.Units)
+	}
+}
+
+func TestBootAnalyzeErrors(t *testing.T) {
+	manager := &MockBootAnalyzeManager{Report: testBootReport()}
+	var out bytes.Buffer
+	if err := bootAnalyze(context.Background(), manager, "web", 10, "yaml", &out); !errors.Is(err, helpers.ErrUsage) {
+		t.Errorf("expected a usage error for the output format, got %v", err)
+	}
+	if err := bootAnalyze(context.Background(), manager, "web", 0, "text", &out); !errors.Is(err, helpers.ErrUsage) {
+		t.Errorf("expected a usage error for --top 0, got %v", err)
+	}
+	if err := bootAnalyze(context.Background(), manager, "db", 10, "text", &out); !errors.Is(err, helpers.ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	manager.Error = errors.New("container is stopped; boot times are only available while it runs")
+	if err := bootAnalyze(context.Background(), manager, "web", 10, "text", &out); err == nil || !strings.Contains(err.Error(), "stopped") {
+		t.Errorf("expected the analysis error, got %v", err)
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	certDomain      string
+	certWebroot     string
+	certEmail       string
+	certInContainer bool
+	certStaging     bool
+	certInstallDir  string
+	certReload      string
+	certNoRenew     bool
+	certTimeout     time.Duration
+	certListTable   tableFlags
+)
+
+// certCmd represents the cert command
+var certCmd = &cobra.Command{
+	Use:   "cert <issue|renew|list>",
+	Short: "Issue Let's Encrypt certificates for containers",
+	Long: `Issue Let's Encrypt certificates for web servers in containers with the
+lego ACME client, install them in the container and renew them automatically.
+
+Available subcommands:
+  issue  - Issue a certificate and install it in a container
+  renew  - Renew a certificate if it expires within 30 days
+  list   - List issued certificates and when they expire
+
+Examples:
+  lxc-go-cli cert issue web --domain app.example.com --webroot /var/www/html --email ops@example.com
+  lxc-go-cli cert list`,
+}
+
+// certIssueCmd represents the cert issue subcommand
+var certIssueCmd = &cobra.Command{
+	Use:   "issue <container-name>",
+	Short: "Issue a certificate and install it in a container",
+	Long: `Issue a certificate for a domain with an HTTP challenge answered by the
+container's web server from --webroot, which must be the directory it serves
+the domain from. The domain must already resolve to this host with port 80
+forwarded to the container (see 'port add' and 'expose').
+
+By default lego runs on the host: the challenge files are shared into the
+webroot with a read-only disk device, and the account and certificates are
+kept under this tool's config dir. With --in-container lego is installed and
+run inside the container instead, which also works against remote servers.
+
+The certificate and key are installed as <install-dir>/<domain>.crt and .key;
+point the web server at them and pass --reload to have it pick up renewals.
+A daily cron entry renews the certificate 30 days before it expires.
+
+Examples:
+  lxc-go-cli cert issue web --domain app.example.com --webroot /var/www/html --email ops@example.com
+  lxc-go-cli cert issue web --domain app.example.com --webroot /var/www/html --email ops@example.com \
+    --reload "systemctl reload nginx"
+  lxc-go-cli cert issue web --domain app.example.com --webroot /srv/www --email ops@example.com --in-container --staging`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), certTimeout)
+		defer cancel()
+
+		mode := helpers.ACMEModeHost
+		if certInContainer {
+			mode = helpers.ACMEModeContainer
+		}
+		record := &helpers.CertRecord{
+			Container:  args[0],
+			Domain:     certDomain,
+			Email:      certEmail,
+			Webroot:    certWebroot,
+			Mode:       mode,
+			Staging:    certStaging,
+			InstallDir: certInstallDir,
+			Reload:     certReload,
+		}
+		return issueCertificate(ctx, &DefaultCertManager{}, record, !certNoRenew, cmd.OutOrStdout())
+	},
+}
+
+// certRenewCmd represents the cert renew subcommand
+var certRenewCmd = &cobra.Command{
+	Use:   "renew <domain>",
+	Short: "Renew a certificate if it expires within 30 days",
+	Long: `Renew a certificate issued with 'cert issue' if it expires within 30
+days and install the new one in its container. The renewal cron entry runs
+this daily, so there is rarely a need to run it by hand.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), certTimeout)
+		defer cancel()
+
+		return renewCertificate(ctx, &DefaultCertManager{}, args[0])
+	},
+}
+
+// certListCmd represents the cert list subcommand
+var certListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List issued certificates and when they expire",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		return listCertificates(&DefaultCertManager{}, out, certListTable.options(out), time.Now())
+	},
+}
+
+// CertManager interface for dependency injection
+type CertManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	IssueCertificate(ctx context.Context, record *helpers.CertRecord) error
+	RenewCertificate(ctx context.Context, record *helpers.CertRecord) error
+	ScheduleRenewal(ctx context.Context, record *helpers.CertRecord, entry string) error
+	LoadCertRecord(domain string) (*helpers.CertRecord, error)
+	SaveCertRecord(record *helpers.CertRecord) error
+	ListCertRecords() ([]helpers.CertRecord, error)
+}
+
+// DefaultCertManager implements CertManager using helpers
+type DefaultCertManager struct{}
+
+func (d *DefaultCertManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultCertManager) IssueCertificate(ctx context.Context, record *helpers.CertRecord) error {
+	return helpers.IssueCertificate(ctx, record)
+}
+
+func (d *DefaultCertManager) RenewCertificate(ctx context.Context, record *helpers.CertRecord) error {
+	return helpers.RenewCertificate(ctx, record)
+}
+
+func (d *DefaultCertManager) ScheduleRenewal(ctx context.Context, record *helpers.CertRecord, entry string) error {
+	return helpers.ScheduleRenewal(ctx, record, entry)
+}
+
+func (d *DefaultCertManager) LoadCertRecord(domain string) (*helpers.CertRecord, error) {
+	return helpers.LoadCertRecord(domain)
+}
+
+func (d *DefaultCertManager) SaveCertRecord(record *helpers.CertRecord) error {
+	return helpers.SaveCertRecord(record)
+}
+
+func (d *DefaultCertManager) ListCertRecords() ([]helpers.CertRecord, error) {
+	return helpers.ListCertRecords()
+}
+
+// validateCertRecord checks the options of cert issue
+func validateCertRecord(record *helpers.CertRecord) error {
+	if record.Container == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if err := helpers.ValidateDomain(record.Domain); err != nil {
+		return fmt.Errorf("--domain: %w", err)
+	}
+	if record.Email == "" {
+		return helpers.UsageErrorf("--email is required: Let's Encrypt sends expiry warnings to it")
+	}
+	if record.Webroot == "" {
+		return helpers.UsageErrorf("--webroot is required")
+	}
+	if !path.IsAbs(record.Webroot) {
+		return helpers.UsageErrorf("--webroot must be an absolute path in the container, got '%s'", record.Webroot)
+	}
+	if record.InstallDir == "" {
+		record.InstallDir = helpers.DefaultCertInstallDir
+	}
+	if !path.IsAbs(record.InstallDir) {
+		return helpers.UsageErrorf("--install-dir must be an absolute path in the container, got '%s'", record.InstallDir)
+	}
+	return nil
+}
+
+// issueCertificate issues a certificate, records it and schedules its renewal
+func issueCertificate(ctx context.Context, manager CertManager, record *helpers.CertRecord, renew bool, out io.Writer) error {
+	if err := validateCertRecord(record); err != nil {
+		return err
+	}
+	if !manager.ContainerExists(ctx, record.Container) {
+		return containerNotFound(record.Container)
+	}
+
+	if err := manager.IssueCertificate(ctx, record); err != nil {
+		return err
+	}
+	if err := manager.SaveCertRecord(record); err != nil {
+		return err
+	}
+
+	renewal := "disabled (--no-renew)"
+	if renew {
+		renewal = scheduleCertRenewal(ctx, manager, record)
+	}
+
+	fmt.Fprintf(out, "Certificate for %s issued", record.Domain)
+	if record.Staging {
+		fmt.Fprint(out, " by the staging server (not trusted by browsers)")
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  Certificate: %s\n", record.CertFile())
+	fmt.Fprintf(out, "  Key:         %s\n", record.KeyFile())
+	if !record.Expires.IsZero() {
+		fmt.Fprintf(out, "  Expires:     %s\n", record.Expires.Format("2006-01-02"))
+	}
+	fmt.Fprintf(out, "  Renewal:     %s\n", renewal)
+	return nil
+}
+
+// scheduleCertRenewal installs the renewal cron entry and describes it; a
+// failure only warns, since the certificate is already in place
+func scheduleCertRenewal(ctx context.Context, manager CertManager, record *helpers.CertRecord) string {
+	var entry, where string
+	if record.Mode == helpers.ACMEModeContainer {
+		entry, where = helpers.ContainerRenewalCronEntry(record), "cron in container '"+record.Container+"'"
+	} else {
+		executable, err := os.Executable()
+		if err != nil {
+			executable = "lxc-go-cli"
+		}
+		username := "root"
+		if current, err := user.Current(); err == nil {
+			username = current.Username
+		}
+		entry, where = helpers.HostRenewalCronEntry(record, username, executable), "cron on the host"
+	}
+
+	if err := manager.ScheduleRenewal(ctx, record, entry); err != nil {
+		logger.Warn("Could not install the renewal cron entry: %v", err)
+		logger.Warn("Renew it with 'lxc-go-cli cert renew %s' or install this cron entry yourself:\n%s", record.Domain, entry)
+		return "not scheduled, see the warnings above"
+	}
+	return "daily via " + where
+}
+
+// renewCertificate renews a recorded certificate and updates its state
+func renewCertificate(ctx context.Context, manager CertManager, domain string) error {
+	record, err := manager.LoadCertRecord(domain)
+	if err != nil {
+		return err
+	}
+	if err := manager.RenewCertificate(ctx, record); err != nil {
+		return err
+	}
+	if err := manager.SaveCertRecord(record); err != nil {
+		return err
+	}
+	if !record.Expires.IsZero() {
+		logger.Info("Certificate for '%s' is valid until %s", domain, record.Expires.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// listCertificates prints the recorded certificates with their expiry
+func listCertificates(manager CertManager, out io.Writer, opts render.Options, now time.Time) error {
+	records, err := manager.ListCertRecords()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(out, "No certificates issued yet (see 'lxc-go-cli cert issue --help')")
+		return nil
+	}
+
+	table := render.NewTable("DOMAIN", "CONTAINER", "MODE", "EXPIRES", "DAYS LEFT", "CERTIFICATE")
+	for _, record := range records {
+		expires, daysLeft := "-", "-"
+		if !record.Expires.IsZero() {
+			expires = record.Expires.Format("2006-01-02")
+			daysLeft = strconv.Itoa(int(record.Expires.Sub(now).Hours() / 24))
+		}
+		mode := record.Mode
+		if record.Staging {
+			mode += " (staging)"
+		}
+		table.AddRow(record.Domain, record.Container, mode, expires, daysLeft, record.CertFile())
+	}
+	table.Style("days-left", certDaysLeftColor)
+	return table.Render(out, opts)
+}
+
+// certDaysLeftColor warns about certificates that renewal has not kept fresh
+func certDaysLeftColor(days string) render.Color {
+	n, err := strconv.Atoi(days)
+	if err != nil {
+		return render.NoColor
+	}
+	switch {
+	case n < 7:
+		return render.Red
+	case n < 30:
+		return render.Yellow
+	}
+	return render.Green
+}
+
+func init() {
+	rootCmd.AddCommand(certCmd)
+
+	certCmd.AddCommand(certIssueCmd)
+	certCmd.AddCommand(certRenewCmd)
+	certCmd.AddCommand(certListCmd)
+
+	certIssueCmd.Flags().StringVar(&certDomain, "domain", "", "Domain to issue the certificate for (required)")
+	certIssueCmd.Flags().StringVar(&certWebroot, "webroot", "", "Directory the container's web server serves the domain from (required)")
+	certIssueCmd.Flags().StringVar(&certEmail, "email", "", "Contact email for the Let's Encrypt account (required)")
+	certIssueCmd.Flags().BoolVar(&certInContainer, "in-container", false, "Run the ACME client inside the container instead of on the host")
+	certIssueCmd.Flags().BoolVar(&certStaging, "staging", false, "Use the Let's Encrypt staging server, e.g. to test the setup")
+	certIssueCmd.Flags().StringVar(&certInstallDir, "install-dir", helpers.DefaultCertInstallDir, "Directory in the container to install the certificate and key in")
+	certIssueCmd.Flags().StringVar(&certReload, "reload", "", "Command run in the container after each install, e.g. 'systemctl reload nginx'")
+	certIssueCmd.Flags().BoolVar(&certNoRenew, "no-renew", false, "Do not install the renewal cron entry")
+	certIssueCmd.MarkFlagRequired("domain")
+	certIssueCmd.MarkFlagRequired("webroot")
+	certIssueCmd.MarkFlagRequired("email")
+
+	for _, sub := range []*cobra.Command{certIssueCmd, certRenewCmd} {
+		sub.Flags().DurationVarP(&certTimeout, "timeout", "t", 5*time.Minute, "Timeout for the certificate operation")
+	}
+	addTableFlags(certListCmd, &certListTable)
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockCertManager for testing cert command
+type MockCertManager struct {
+	Existing    map[string]bool
+	Records     map[string]*helpers.CertRecord
+	Issued      []string
+	Renewed     []string
+	CronEntries []string
+	ScheduleErr error
+	Expires     time.Time
+}
+
+func (m *MockCertManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.Existing[name]
+}
+
+func (m *MockCertManager) IssueCertificate(ctx context.Context, record *helpers.CertRecord) error {
+	m.Issued = append(m.Issued, record.Domain)
+	record.Expires = m.Expires
+	return nil
+}
+
+func (m *MockCertManager) RenewCertificate(ctx context.Context, record *helpers.CertRecord) error {
+	m.Renewed = append(m.Renewed, record.Domain)
+	record.Expires = m.Expires
+	return nil
+}
+
+func (m *MockCertManager) ScheduleRenewal(ctx context.Context, record *helpers.CertRecord, entry string) error {
+	if m.ScheduleErr != nil {
+		return m.ScheduleErr
+	}
+	m.CronEntries = append(m.CronEntries, entry)
+	return nil
+}
+
+func (m *MockCertManager) LoadCertRecord(domain string) (*helpers.CertRecord, error) {
+	if record, ok := m.Records[domain]; ok {
+		return record, nil
+	}
+	return nil, fmt.Errorf("no certificate was issued for '%s'", domain)
+}
+
+func (m *MockCertManager) SaveCertRecord(record *helpers.CertRecord) error {
+	if m.Records == nil {
+		m.Records = make(map[string]*helpers.CertRecord)
+	}
+	m.Records[record.Domain] = record
+	return nil
+}
+
+func (m *MockCertManager) ListCertRecords() ([]helpers.CertRecord, error) {
+	var records []helpers.CertRecord
+	for _, record := range m.Records {
+		records = append(records, *record)
+	}
+	return records, nil
+}
+
+func newCertRecord() *helpers.CertRecord {
+	return &helpers.CertRecord{Container: "web", Domain: "app.example.com", Email: "ops@example.com", Webroot: "/var/www/html", Mode: helpers.ACMEModeHost}
+}
+
+func TestValidateCertRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*helpers.CertRecord)
+		error  string
+	}{
+		{"valid", func(r *helpers.CertRecord) {}, ""},
+		{"bad domain", func(r *helpers.CertRecord) { r.Domain = "app example" }, "--domain"},
+		{"no email", func(r *helpers.CertRecord) { r.Email = "" }, "--email"},
+		{"relative webroot", func(r *helpers.CertRecord) { r.Webroot = "www" }, "absolute"},
+		{"relative install dir", func(r *helpers.CertRecord) { r.InstallDir = "certs" }, "--install-dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := newCertRecord()
+			tt.modify(record)
+			err := validateCertRecord(record)
+			if tt.error == "" {
+				if err != nil || record.InstallDir != helpers.DefaultCertInstallDir {
+					t.Errorf("expected a valid record with the default install dir, got %v, %q", err, record.InstallDir)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestIssueCertificate(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(90 * 24 * time.Hour)
+
+	t.Run("host", func(t *testing.T) {
+		manager := &MockCertManager{Existing: map[string]bool{"web": true}, Expires: expires}
+		var out bytes.Buffer
+		if err := issueCertificate(ctx, manager, newCertRecord(), true, &out); err != nil {
+			t.Fatal(err)
+		}
+		if manager.Records["app.example.com"] == nil {
+			t.Error("expected the certificate to be recorded")
+		}
+		if len(manager.CronEntries) != 1 || !strings.Contains(manager.CronEntries[0], "cert renew app.example.com") {
+			t.Errorf("expected a host renewal entry, got %v", manager.CronEntries)
+		}
+		for _, want := range []string{"/etc/ssl/lxc-go-cli/app.example.com.crt", expires.Format("2006-01-02"), "daily via cron on the host"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("expected %q in the summary:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("container", func(t *testing.T) {
+		manager := &MockCertManager{Existing: map[string]bool{"web": true}}
+		record := newCertRecord()
+		record.Mode = helpers.ACMEModeContainer
+		var out bytes.Buffer
+		if err := issueCertificate(ctx, manager, record, true, &out); err != nil {
+			t.Fatal(err)
+		}
+		if len(manager.CronEntries) != 1 || !strings.Contains(manager.CronEntries[0], " root lego ") {
+			t.Errorf("expected a container renewal entry, got %v", manager.CronEntries)
+		}
+	})
+
+	t.Run("renewal failure only warns", func(t *testing.T) {
+		manager := &MockCertManager{Existing: map[string]bool{"web": true}, ScheduleErr: fmt.Errorf("permission denied")}
+		var out bytes.Buffer
+		if err := issueCertificate(ctx, manager, newCertRecord(), true, &out); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "not scheduled") {
+			t.Errorf("expected the summary to report the missing renewal:\n%s", out.String())
+		}
+	})
+
+	t.Run("no renew", func(t *testing.T) {
+		manager := &MockCertManager{Existing: map[string]bool{"web": true}}
+		if err := issueCertificate(ctx, manager, newCertRecord(), false, &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(manager.CronEntries) != 0 {
+			t.Errorf("expected no cron entry, got %v", manager.CronEntries)
+		}
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		manager := &MockCertManager{}
+		if err := issueCertificate(ctx, manager, newCertRecord(), true, &bytes.Buffer{}); err == nil || len(manager.Issued) != 0 {
+			t.Errorf("expected a missing container to fail before issuing, got %v", err)
+		}
+	})
+}
+
+func TestRenewAndListCertificates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := newCertRecord()
+	record.Expires = now.Add(10 * 24 * time.Hour)
+	manager := &MockCertManager{Records: map[string]*helpers.CertRecord{record.Domain: record}, Expires: now.Add(90 * 24 * time.Hour)}
+
+	var out bytes.Buffer
+	if err := listCertificates(manager, &out, render.Options{}, now); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "app.example.com") || !strings.Contains(out.String(), "10") {
+		t.Errorf("unexpected list:\n%s", out.String())
+	}
+	if certDaysLeftColor("10") != render.Yellow || certDaysLeftColor("3") != render.Red || certDaysLeftColor("60") != render.Green {
+		t.Error("unexpected days left colors")
+	}
+
+	if err := renewCertificate(context.Background(), manager, "app.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !manager.Records[record.Domain].Expires.Equal(now.Add(90 * 24 * time.Hour)) {
+		t.Error("expected the renewed expiry to be saved")
+	}
+	if err := renewCertificate(context.Background(), manager, "other.example.com"); err == nil {
+		t.Error("expected an unknown domain to fail")
+	}
+
+	out.Reset()
+	if err := listCertificates(&MockCertManager{}, &out, render.Options{}, now); err != nil || !strings.Contains(out.String(), "No certificates") {
+		t.Errorf("expected an empty notice, got %v:\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clusterTimeout time.Duration
+	clusterTable   tableFlags
+)
+
+// clusterCmd represents the cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster <list>",
+	Short: "Show the members of an LXD cluster",
+	Long: `Show the members of an LXD cluster, e.g. a multi-node homelab.
+
+'create --target <member>' launches a container on a specific member, and
+'list' and 'info' show the member each container runs on.
+
+Available subcommands:
+  list  - List members with their status and capacity
+
+Examples:
+  lxc-go-cli cluster list
+  lxc-go-cli create --name web --target member3`,
+}
+
+// clusterListCmd represents the cluster list subcommand
+var clusterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List members with their status and capacity",
+	Long: `List the members of the cluster with their roles and status, their
+processors and memory, and how many instances of the current project run on
+each.
+
+Examples:
+  lxc-go-cli cluster list
+  lxc-go-cli cluster list --columns name,status,instances`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+		defer cancel()
+
+		out := cmd.OutOrStdout()
+		return listClusterMembers(ctx, &DefaultClusterManager{}, out, clusterTable.options(out))
+	},
+}
+
+// ClusterManager interface for dependency injection
+type ClusterManager interface {
+	IsClustered(ctx context.Context) (bool, error)
+	ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error)
+	GetMemberCapacity(ctx context.Context, member string) (*helpers.MemberCapacity, error)
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+}
+
+// DefaultClusterManager implements ClusterManager using helpers
+type DefaultClusterManager struct{}
+
+func (d *DefaultClusterManager) IsClustered(ctx context.Context) (bool, error) {
+	return helpers.IsClustered(ctx)
+}
+
+func (d *DefaultClusterManager) ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error) {
+	return helpers.ListClusterMembers(ctx)
+}
+
+func (d *DefaultClusterManager) GetMemberCapacity(ctx context.Context, member string) (*helpers.MemberCapacity, error) {
+	return helpers.GetMemberCapacity(ctx, member)
+}
+
+func (d *DefaultClusterManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+// listClusterMembers prints the cluster members with their capacity and
+// instance counts
+func listClusterMembers(ctx context.Context, manager ClusterManager, out io.Writer, opts render.Options) error {
+	clustered, err := manager.IsClustered(ctx)
+	if err != nil {
+		return err
+	}
+	if !clustered {
+		fmt.Fprintln(out, "This LXD server is not clustered")
+		return nil
+	}
+
+	members, err := manager.ListClusterMembers(ctx)
+	if err != nil {
+		return err
+	}
+
+	instances := make(map[string]int)
+	if states, err := manager.ListContainers(ctx); err != nil {
+		logger.Warn("Could not count instances per member: %v", err)
+	} else {
+		for _, state := range states {
+			instances[state.Location]++
+		}
+	}
+
+	table := render.NewTable("NAME", "URL", "ROLES", "ARCH", "STATUS", "CPUS", "MEMORY", "INSTANCES")
+	for _, member := range members {
+		cpus, memory := "-", "-"
+		// Offline members cannot report their resources
+		if member.Status == helpers.MemberStatusOnline {
+			if capacity, err := manager.GetMemberCapacity(ctx, member.Name); err != nil {
+				logger.Debug("Could not get the capacity of member '%s': %v", member.Name, err)
+			} else {
+				cpus = strconv.Itoa(capacity.CPUs)
+				memory = helpers.FormatBytes(capacity.MemoryUsed) + "/" + helpers.FormatBytes(capacity.MemoryTotal)
+			}
+		}
+		table.AddRow(
+			member.Name,
+			valueOrDash(member.URL),
+			valueOrDash(strings.Join(member.Roles, ",")),
+			valueOrDash(member.Architecture),
+			member.Status,
+			cpus,
+			memory,
+			strconv.Itoa(instances[member.Name]),
+		)
+	}
+	table.Style("status", memberStatusColor)
+	return table.Render(out, opts)
+}
+
+// memberStatusColor colors online members green and all others red
+func memberStatusColor(status string) render.Color {
+	if status == helpers.MemberStatusOnline {
+		return render.Green
+	}
+	return render.Red
+}
+
+func init() {
+	rootCmd.AddCommand(clusterCmd)
+
+	clusterCmd.AddCommand(clusterListCmd)
+
+	clusterListCmd.Flags().DurationVarP(&clusterTimeout, "timeout", "t", 30*time.Second, "Timeout for the cluster operation")
+	addTableFlags(clusterListCmd, &clusterTable)
+}
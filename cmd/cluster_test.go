@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockClusterManager for testing cluster command
+type MockClusterManager struct {
+	Clustered bool
+	Members   []helpers.ClusterMember
+	States    []helpers.ContainerState
+	Queried   []string
+}
+
+func (m *MockClusterManager) IsClustered(ctx context.Context) (bool, error) {
+	return m.Clustered, nil
+}
+
+func (m *MockClusterManager) ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error) {
+	return m.Members, nil
+}
+
+func (m *MockClusterManager) GetMemberCapacity(ctx context.Context, member string) (*helpers.MemberCapacity, error) {
+	m.Queried = append(m.Queried, member)
+	if member == "member2" {
+		return nil, fmt.Errorf("resources unavailable")
+	}
+	return &helpers.MemberCapacity{CPUs: 8, MemoryTotal: 16 << 30, MemoryUsed: 4 << 30}, nil
+}
+
+func (m *MockClusterManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func TestClusterCommand(t *testing.T) {
+	if _, _, err := clusterCmd.Find([]string{"list"}); err != nil {
+		t.Errorf("expected subcommand 'list': %v", err)
+	}
+	if createCmd.Flags().Lookup("target") == nil {
+		t.Error("expected create to have a --target flag")
+	}
+}
+
+func TestListClusterMembers(t *testing.T) {
+	manager := &MockClusterManager{
+		Clustered: true,
+		Members: []helpers.ClusterMember{
+			{Name: "member1", URL: "https://10.0.0.1:8443", Roles: []string{"database-leader", "database"}, Architecture: "x86_64", Status: helpers.MemberStatusOnline},
+			{Name: "member2", URL: "https://10.0.0.2:8443", Status: helpers.MemberStatusOnline},
+			{Name: "member3", URL: "https://10.0.0.3:8443", Status: "Offline"},
+		},
+		States: []helpers.ContainerState{{Name: "web", Location: "member1"}, {Name: "db", Location: "member1"}, {Name: "ci", Location: "member3"}},
+	}
+
+	var out bytes.Buffer
+	if err := listClusterMembers(context.Background(), manager, &out, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and three members, got:\n%s", out.String())
+	}
+	for line, expected := range map[int][]string{
+		1: {"member1", "database-leader,database", "x86_64", "Online", "8", "4.0GiB/16.0GiB", "2"},
+		2: {"member2", "-"},
+		3: {"member3", "Offline", "1"},
+	} {
+		for _, want := range expected {
+			if !strings.Contains(lines[line], want) {
+				t.Errorf("expected %q in %q", want, lines[line])
+			}
+		}
+	}
+	if strings.Join(manager.Queried, ",") != "member1,member2" {
+		t.Errorf("only online members should be asked for their capacity, asked %v", manager.Queried)
+	}
+
+	out.Reset()
+	if err := listClusterMembers(context.Background(), &MockClusterManager{}, &out, render.Options{}); err != nil || !strings.Contains(out.String(), "not clustered") {
+		t.Errorf("expected a standalone notice, got %q, %v", out.String(), err)
+	}
+}
+
+func TestListShowsLocationOnCluster(t *testing.T) {
+	manager := newMockListManager()
+	var out bytes.Buffer
+	if err := listContainers(context.Background(), manager, false, nil, &out, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "LOCATION") {
+		t.Errorf("a standalone server should not get a location column, got:\n%s", out.String())
+	}
+
+	manager.States[0].Location = "member3"
+	out.Reset()
+	if err := listContainers(context.Background(), manager, false, nil, &out, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "LOCATION") || !strings.Contains(out.String(), "member3") {
+		t.Errorf("expected a location column, got:\n%s", out.String())
+	}
+
+	info := formatContainerInfo(&manager.States[0], render.Style{})
+	if !strings.Contains(info, "Location:") || !strings.Contains(info, "member3") {
+		t.Errorf("expected info to show the location, got:\n%s", info)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	composeTimeout    time.Duration
+	composeProjectDir string
+	composeEnvFile    string
+	composeSecrets    bool
+)
+
+// composeCmd represents the compose command
+var composeCmd = &cobra.Command{
+	Use:   "compose <up>",
+	Short: "Run Docker Compose in LXC containers",
+	Long: `Run Docker Compose as the 'app' user inside a container.
+
+Available subcommands:
+  up - Create and start the compose project in the background
+
+Examples:
+  lxc-go-cli compose up mycontainer --env-file .env`,
+}
+
+// composeUpCmd represents the compose up subcommand
+var composeUpCmd = &cobra.Command{
+	Use:   "up <container-name> [-- <docker compose up options>...]",
+	Short: "Start a compose project as the app user",
+	Long: `Run 'docker compose up -d' as the 'app' user in the project directory
+inside a container. Options after '--' are passed on to 'docker compose up'.
+
+--env-file loads variables from a .env file on the host for compose to
+interpolate, like 'docker compose --env-file'. They are passed on stdin, never
+on a command line, into a root-only file on the container's /run tmpfs that is
+deleted as soon as the command has read it; nothing is left in the project
+directory.
+
+--secrets passes the container's secrets (see 'secret set') the same way,
+after decrypting them on the host; they override env file variables of the
+same name.
+
+Examples:
+  lxc-go-cli compose up mycontainer
+  lxc-go-cli compose up mycontainer --env-file .env.production
+  lxc-go-cli compose up mycontainer --secrets
+  lxc-go-cli compose up mycontainer --project-dir /home/app/shop -- --build --wait`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 && dash != 1 {
+			return fmt.Errorf("expected a single container name before '--', got %d", dash)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		ctx, timeoutCancel := context.WithTimeout(ctx, composeTimeout)
+		defer timeoutCancel()
+
+		// A failing compose run is reported through the exit code, not usage help
+		cmd.SilenceUsage = true
+		manager := &DefaultComposeManager{}
+		streams := execStreams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+		return composeUp(ctx, manager, args[0], composeProjectDir, composeEnvFile, composeSecrets, args[1:], streams)
+	},
+}
+
+// ComposeManager interface for dependency injection
+type ComposeManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error)
+	RemoveFile(ctx context.Context, containerName, path string) error
+	LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error)
+	RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error
+}
+
+// DefaultComposeManager implements ComposeManager using helpers
+type DefaultComposeManager struct{}
+
+func (d *DefaultComposeManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultComposeManager) PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error) {
+	return helpers.PushEnv(ctx, containerName, vars)
+}
+
+func (d *DefaultComposeManager) RemoveFile(ctx context.Context, containerName, path string) error {
+	return helpers.RemoveContainerFile(ctx, containerName, path)
+}
+
+func (d *DefaultComposeManager) LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error) {
+	return helpers.LoadSecrets(ctx, containerName)
+}
+
+func (d *DefaultComposeManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	cmdArgs := append([]string{"exec", containerName, "-T", "--"}, args...)
+	logger.Debug("Executing: lxc %s", strings.Join(cmdArgs, " "))
+	return helpers.Runner().RunStreaming(ctx, helpers.Streams{Stdin: streams.Stdin, Stdout: streams.Stdout, Stderr: streams.Stderr}, "lxc", cmdArgs...)
+}
+
+// composeUpCommand builds the command that starts the project as app; su
+// clears the environment, so env file variables are kept with -w
+func composeUpCommand(projectDir string, options []string, envPath string, envKeys []string) []string {
+	script := "cd " + helpers.ShellQuote(projectDir) + " && exec docker compose up -d"
+	for _, option := range options {
+		script += " " + helpers.ShellQuote(option)
+	}
+
+	if envPath == "" {
+		return []string{"su", "-", "app", "-c", script}
+	}
+	return helpers.EnvCommand(envPath, "su", "-w", strings.Join(envKeys, ","), "-", "app", "-c", script)
+}
+
+// composeUp starts a compose project in a container, passing on the exit code
+func composeUp(ctx context.Context, manager ComposeManager, containerName, projectDir, envFile string, withSecrets bool, options []string, streams execStreams) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !path.IsAbs(projectDir) {
+		return fmt.Errorf("project directory '%s' must be an absolute path inside the container", projectDir)
+	}
+
+	var vars []helpers.EnvVar
+	if envFile != "" {
+		var err error
+		if vars, err = helpers.LoadEnvFile(envFile); err != nil {
+			return err
+		}
+	}
+
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	if withSecrets {
+		secrets, err := manager.LoadSecrets(ctx, containerName)
+		if err != nil {
+			return err
+		}
+		if len(secrets) == 0 {
+			logger.Warn("Container '%s' has no secrets to pass (see 'lxc-go-cli secret set')", containerName)
+		}
+		vars = mergeEnvVars(vars, secrets)
+	}
+
+	var envPath string
+	if len(vars) > 0 {
+		var err error
+		if envPath, err = manager.PushEnv(ctx, containerName, vars); err != nil {
+			return err
+		}
+		defer func() {
+			// Normally already deleted by the command itself
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := manager.RemoveFile(cleanupCtx, containerName, envPath); err != nil {
+				logger.Warn("Failed to remove %s from container '%s': %v", envPath, containerName, err)
+			}
+		}()
+	}
+
+	logger.Info("Starting compose project in %s in container '%s'...", projectDir, containerName)
+	err := manager.RunNonInteractive(ctx, containerName, streams, composeUpCommand(projectDir, options, envPath, helpers.EnvKeys(vars))...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return helpers.TimeoutErrorf("docker compose up timed out in container '%s'", containerName)
+	}
+	if code := helpers.ExitCodeFromError(err); code > 0 {
+		return &ExitError{Code: code, Err: fmt.Errorf("docker compose up exited with status %d in container '%s'", code, containerName)}
+	}
+	return fmt.Errorf("failed to run docker compose up in container '%s': %w", containerName, err)
+}
+
+// mergeEnvVars adds overrides to vars, replacing variables of the same name
+func mergeEnvVars(vars, overrides []helpers.EnvVar) []helpers.EnvVar {
+	index := make(map[string]int, len(vars))
+	for i, v := range vars {
+		index[v.Key] = i
+	}
+	for _, v := range overrides {
+		if i, ok := index[v.Key]; ok {
+			logger.Debug("Secret '%s' overrides the env file variable", v.Key)
+			vars[i].Value = v.Value
+			continue
+		}
+		index[v.Key] = len(vars)
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+func init() {
+	rootCmd.AddCommand(composeCmd)
+	composeCmd.AddCommand(composeUpCmd)
+
+	composeUpCmd.Flags().StringVar(&composeProjectDir, "project-dir", "/home/app", "Docker Compose project directory inside the container")
+	composeUpCmd.Flags().StringVar(&composeEnvFile, "env-file", "", "Load variables from a .env file on the host for compose to interpolate")
+	composeUpCmd.Flags().BoolVar(&composeSecrets, "secrets", false, "Pass the container's stored secrets to compose like --env-file variables")
+	composeUpCmd.Flags().DurationVarP(&composeTimeout, "timeout", "t", 10*time.Minute, "Timeout for docker compose up")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockComposeManager implements ComposeManager for testing
+type MockComposeManager struct {
+	ExistingContainers map[string]bool
+	PushedEnv          []helpers.EnvVar
+	PushError          error
+	RemovedFiles       []string
+	Secrets            []helpers.EnvVar
+	RunArgs            []string
+	RunError           error
+}
+
+func (m *MockComposeManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockComposeManager) PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error) {
+	if m.PushError != nil {
+		return "", m.PushError
+	}
+	m.PushedEnv = vars
+	return "/run/lxc-go-cli-env.abc123", nil
+}
+
+func (m *MockComposeManager) RemoveFile(ctx context.Context, containerName, path string) error {
+	m.RemovedFiles = append(m.RemovedFiles, containerName+":"+path)
+	return nil
+}
+
+func (m *MockComposeManager) LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error) {
+	return m.Secrets, nil
+}
+
+func (m *MockComposeManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	m.RunArgs = args
+	return m.RunError
+}
+
+func TestComposeCommand(t *testing.T) {
+	if composeCmd.Use != "compose <up>" {
+		t.Errorf("expected Use to be 'compose <up>', got %s", composeCmd.Use)
+	}
+	for _, name := range []string{"project-dir", "env-file", "timeout"} {
+		if composeUpCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected compose up to have a --%s flag", name)
+		}
+	}
+	if flag := composeUpCmd.Flags().Lookup("project-dir"); flag.DefValue != "/home/app" {
+		t.Errorf("expected the project directory to default to /home/app, got %s", flag.DefValue)
+	}
+}
+
+func TestComposeUpCommand(t *testing.T) {
+	got := composeUpCommand("/home/app/my shop", []string{"--build", "it's"}, "", nil)
+	want := []string{"su", "-", "app", "-c", `cd '/home/app/my shop' && exec docker compose up -d '--build' 'it'\''s'`}
+	if strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	got = composeUpCommand("/home/app", nil, "/run/lxc-go-cli-env.abc123", []string{"A", "B"})
+	want = helpers.EnvCommand("/run/lxc-go-cli-env.abc123", "su", "-w", "A,B", "-", "app", "-c", "cd '/home/app' && exec docker compose up -d")
+	if strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestComposeUp(t *testing.T) {
+	manager := &MockComposeManager{ExistingContainers: map[string]bool{"web": true}}
+	ctx := context.Background()
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("DB_PASSWORD=s3cret\nTAG=1.2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := composeUp(ctx, manager, "web", "/home/app", envFile, false, []string{"--wait"}, execStreams{}); err != nil {
+		t.Fatalf("composeUp failed: %v", err)
+	}
+	if len(manager.PushedEnv) != 2 || manager.PushedEnv[0].Value != "s3cret" {
+		t.Errorf("expected the env file to be pushed, got %v", manager.PushedEnv)
+	}
+	want := composeUpCommand("/home/app", []string{"--wait"}, "/run/lxc-go-cli-env.abc123", []string{"DB_PASSWORD", "TAG"})
+	if strings.Join(manager.RunArgs, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("expected %q, got %q", want, manager.RunArgs)
+	}
+	if strings.Contains(strings.Join(manager.RunArgs, " "), "s3cret") {
+		t.Errorf("variable values must not appear on the command line: %q", manager.RunArgs)
+	}
+	if len(manager.RemovedFiles) != 1 || manager.RemovedFiles[0] != "web:/run/lxc-go-cli-env.abc123" {
+		t.Errorf("expected the env file to be removed, got %v", manager.RemovedFiles)
+	}
+
+	// Without an env file nothing is pushed
+	manager = &MockComposeManager{ExistingContainers: map[string]bool{"web": true}}
+	if err := composeUp(ctx, manager, "web", "/home/app", "", false, nil, execStreams{}); err != nil {
+		t.Fatalf("composeUp failed: %v", err)
+	}
+	if manager.PushedEnv != nil || len(manager.RemovedFiles) != 0 || manager.RunArgs[0] != "su" {
+		t.Errorf("expected a plain su command, got %q", manager.RunArgs)
+	}
+
+	// Secrets are pushed with the env file and override it
+	manager = &MockComposeManager{
+		ExistingContainers: map[string]bool{"web": true},
+		Secrets:            []helpers.EnvVar{{Key: "DB_PASSWORD", Value: "from-secret"}, {Key: "API_KEY", Value: "k3y"}},
+	}
+	if err := composeUp(ctx, manager, "web", "/home/app", envFile, true, nil, execStreams{}); err != nil {
+		t.Fatalf("composeUp failed: %v", err)
+	}
+	if len(manager.PushedEnv) != 3 || manager.PushedEnv[0].Value != "from-secret" || manager.PushedEnv[2].Key != "API_KEY" {
+		t.Errorf("expected the secrets to be merged into the env file, got %v", manager.PushedEnv)
+	}
+	if strings.Contains(strings.Join(manager.RunArgs, " "), "k3y") {
+		t.Errorf("secret values must not appear on the command line: %q", manager.RunArgs)
+	}
+}
+
+func TestComposeUpErrors(t *testing.T) {
+	manager := &MockComposeManager{ExistingContainers: map[string]bool{"web": true}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		container  string
+		projectDir string
+		envFile    string
+		want       string
+	}{
+		{"no container", "", "/home/app", "", "container name is required"},
+		{"relative project dir", "web", "shop", "", "must be an absolute path"},
+		{"missing env file", "web", "/home/app", filepath.Join(t.TempDir(), "missing.env"), "env file"},
+		{"missing container", "missing", "/home/app", "", "does not exist"},
+	}
+	for _, tt := range tests {
+		err := composeUp(ctx, manager, tt.container, tt.projectDir, tt.envFile, false, nil, execStreams{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	manager.RunError = exec.Command("sh", "-c", "exit 17").Run()
+	err := composeUp(ctx, manager, "web", "/home/app", "", false, nil, execStreams{})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 17 {
+		t.Errorf("expected exit code 17 to be preserved, got %v", err)
+	}
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manager.PushError = errors.New("failed to copy environment")
+	if err := composeUp(ctx, manager, "web", "/home/app", envFile, false, nil, execStreams{}); err == nil || !strings.Contains(err.Error(), "failed to copy environment") {
+		t.Errorf("expected the push error, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	configTimeout time.Duration
+	configFull    bool
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config <show>",
+	Short: "Inspect container configuration",
+	Long: `Inspect the configuration of a container.
+
+Available subcommands:
+  show - Show the configuration managed by this tool
+
+Examples:
+  lxc-go-cli config show mycontainer
+  lxc-go-cli config show mycontainer --full`,
+}
+
+// configShowCmd represents the config show subcommand
+var configShowCmd = &cobra.Command{
+	Use:   "show <container-name>",
+	Short: "Show the configuration this tool manages for a container",
+	Long: `Show the parts of a container's configuration that this tool manages:
+security settings, user.* metadata and proxy, gpu and disk devices.
+
+Use --full to show the complete 'lxc config show' output instead. Secret values
+such as the stored app password are always hidden; use 'password' to reveal it.
+
+Examples:
+  lxc-go-cli config show mycontainer
+  lxc-go-cli config show mycontainer --full`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), configTimeout)
+		defer cancel()
+
+		manager := &DefaultConfigManager{}
+		return showContainerConfig(ctx, manager, args[0], configFull, cmd.OutOrStdout())
+	},
+}
+
+// ConfigManager interface for dependency injection
+type ConfigManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+}
+
+// DefaultConfigManager implements ConfigManager using helpers
+type DefaultConfigManager struct{}
+
+func (d *DefaultConfigManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultConfigManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetContainerConfig(ctx, containerName)
+}
+
+// showContainerConfig prints the tool-scoped or full configuration of a container
+func showContainerConfig(ctx context.Context, manager ConfigManager, containerName string, full bool, out io.Writer) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	configData, err := manager.GetContainerConfig(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container configuration: %w", err)
+	}
+
+	var data []byte
+	if full {
+		data, err = helpers.MaskConfigSecrets(configData)
+	} else {
+		var managed *helpers.ManagedConfig
+		managed, err = helpers.FilterManagedConfig(containerName, configData)
+		if err == nil {
+			data, err = yaml.Marshal(managed)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, string(data))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+
+	configShowCmd.Flags().DurationVarP(&configTimeout, "timeout", "t", 30*time.Second, "Timeout for the config operation")
+	configShowCmd.Flags().BoolVar(&configFull, "full", false, "Show the complete container configuration")
+}
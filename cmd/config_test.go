@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const sampleConfigShow = `architecture: x86_64
+config:
+  image.os: Ubuntu
+  security.nesting: "true"
+  security.syscalls.intercept.mknod: "true"
+  user.app-password: c2VjcmV0
+  volatile.eth0.hwaddr: 00:16:3e:aa:bb:cc
+devices:
+  root:
+    path: /
+    pool: btrfs-pool
+    type: disk
+  web-8080-80-tcp:
+    connect: tcp:0.0.0.0:80
+    listen: tcp:0.0.0.0:8080
+    type: proxy
+  eth1:
+    network: lxdbr0
+    type: nic
+ephemeral: false
+profiles:
+- default
+`
+
+// MockConfigManager for testing config command
+type MockConfigManager struct {
+	ExistingContainers map[string]bool
+	ConfigData         []byte
+	ConfigError        error
+}
+
+func (m *MockConfigManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockConfigManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	if m.ConfigError != nil {
+		return nil, m.ConfigError
+	}
+	return m.ConfigData, nil
+}
+
+func TestConfigCommand(t *testing.T) {
+	if configShowCmd.Use != "show <container-name>" {
+		t.Errorf("unexpected Use for config show: '%s'", configShowCmd.Use)
+	}
+	if configShowCmd.Flags().Lookup("full") == nil {
+		t.Error("full flag should exist")
+	}
+}
+
+func TestShowContainerConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		containerName  string
+		full           bool
+		configError    error
+		expectedError  string
+		expectedOutput []string
+		hiddenOutput   []string
+	}{
+		{name: "empty name", expectedError: "container name is required"},
+		{name: "missing container", containerName: "missing", expectedError: "container 'missing' does not exist"},
+		{
+			name:           "managed keys only",
+			containerName:  "web",
+			expectedOutput: []string{"name: web", "security.nesting", "user.app-password: <hidden>", "web-8080-80-tcp", "root:"},
+			hiddenOutput:   []string{"volatile.eth0.hwaddr", "image.os", "eth1", "c2VjcmV0", "architecture"},
+		},
+		{
+			name:           "full config",
+			containerName:  "web",
+			full:           true,
+			expectedOutput: []string{"architecture: x86_64", "volatile.eth0.hwaddr", "eth1", "user.app-password: <hidden>"},
+			hiddenOutput:   []string{"c2VjcmV0"},
+		},
+		{
+			name:          "config fails",
+			containerName: "web",
+			configError:   fmt.Errorf("lxc failed"),
+			expectedError: "failed to get container configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockConfigManager{
+				ExistingContainers: map[string]bool{"web": true},
+				ConfigData:         []byte(sampleConfigShow),
+				ConfigError:        tt.configError,
+			}
+
+			var out bytes.Buffer
+			err := showContainerConfig(context.Background(), manager, tt.containerName, tt.full, &out)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for _, expected := range tt.expectedOutput {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+				}
+			}
+			for _, hidden := range tt.hiddenOutput {
+				if strings.Contains(out.String(), hidden) {
+					t.Errorf("output should not contain '%s', got:\n%s", hidden, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultConfigManager(t *testing.T) {
+	// Test that DefaultConfigManager implements ConfigManager interface
+	var manager ConfigManager = &DefaultConfigManager{}
+	_ = manager
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// assumeYes answers yes to every confirmation, set by --yes or assume_yes
+// in the config file
+var assumeYes bool
+
+// errAborted is returned when a confirmation is declined
+var errAborted = i18n.Errorf("confirm.aborted")
+
+// Where confirmations are asked and answered; tests replace them
+var (
+	confirmIn       io.Reader = os.Stdin
+	confirmOut      io.Writer = os.Stderr
+	stdinIsTerminal           = func() bool {
+		info, err := os.Stdin.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+)
+
+// confirm asks the question with the given message ID before a destructive
+// action and returns errAborted unless the answer is yes, in English or the
+// language of messages. Nothing is asked with --yes, or when stdin is not a
+// terminal, so scripts and pipelines keep working unattended.
+func confirm(id string, args ...any) error {
+	question := i18n.T(id, args...)
+	if assumeYes {
+		logger.Debug("%s yes (--yes)", question)
+		return nil
+	}
+	if !stdinIsTerminal() {
+		logger.Debug("%s yes (stdin is not a terminal)", question)
+		return nil
+	}
+
+	fmt.Fprint(confirmOut, i18n.T("confirm.prompt", question))
+	answer, err := bufio.NewReader(confirmIn).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(confirmOut)
+		return errAborted
+	}
+	if slices.Contains(strings.Split(i18n.T("confirm.yes"), ","), strings.ToLower(strings.TrimSpace(answer))) {
+		return nil
+	}
+	return errAborted
+}
+
+// applyAssumeYes turns confirmations off when the config file's assume_yes
+// is set; --yes turns them off either way
+func applyAssumeYes(cmd *cobra.Command) error {
+	if assumeYes || !usesSettings(cmd) {
+		return nil
+	}
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return err
+	}
+	assumeYes = settings.AssumeYes
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// useConfirmAnswer makes confirmations read answer from a terminal
+func useConfirmAnswer(t *testing.T, answer string) *bytes.Buffer {
+	t.Helper()
+	previousIn, previousOut, previousTerminal, previousYes := confirmIn, confirmOut, stdinIsTerminal, assumeYes
+	t.Cleanup(func() {
+		confirmIn, confirmOut, stdinIsTerminal, assumeYes = previousIn, previousOut, previousTerminal, previousYes
+	})
+	var prompt bytes.Buffer
+	confirmIn = strings.NewReader(answer)
+	confirmOut = &prompt
+	stdinIsTerminal = func() bool { return true }
+	assumeYes = false
+	return &prompt
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		answer string
+		ok     bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{" yes \n", true},
+		{"\n", false},
+		{"n\n", false},
+		{"yep\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		prompt := useConfirmAnswer(t, tt.answer)
+		err := confirm("Delete container '%s'?", "web")
+		if tt.ok && err != nil {
+			t.Errorf("answer %q: expected yes, got %v", tt.answer, err)
+		}
+		if !tt.ok && !errors.Is(err, errAborted) {
+			t.Errorf("answer %q: expected abort, got %v", tt.answer, err)
+		}
+		if !strings.HasPrefix(prompt.String(), "Delete container 'web'? [y/N] ") {
+			t.Errorf("unexpected prompt %q", prompt.String())
+		}
+	}
+}
+
+func TestConfirmSkipsPrompt(t *testing.T) {
+	prompt := useConfirmAnswer(t, "n\n")
+	assumeYes = true
+	if err := confirm("Delete?"); err != nil || prompt.Len() != 0 {
+		t.Errorf("expected --yes to skip the prompt, got %v, %q", err, prompt.String())
+	}
+
+	assumeYes = false
+	stdinIsTerminal = func() bool { return false }
+	if err := confirm("Delete?"); err != nil || prompt.Len() != 0 {
+		t.Errorf("expected no prompt without a terminal, got %v, %q", err, prompt.String())
+	}
+}
+
+func TestDeleteContainerDeclined(t *testing.T) {
+	useConfirmAnswer(t, "n\n")
+	manager := newMockListManager()
+	if err := deleteContainer(context.Background(), manager, "legacy", false, false); !errors.Is(err, errAborted) {
+		t.Fatalf("expected abort, got %v", err)
+	}
+	if len(manager.Deleted) != 0 {
+		t.Errorf("expected nothing deleted, got %v", manager.Deleted)
+	}
+}
+
+func TestApplyAssumeYes(t *testing.T) {
+	originalDir := helpers.SettingsDir
+	helpers.SettingsDir = t.TempDir()
+	t.Cleanup(func() { helpers.SettingsDir = originalDir })
+	useConfirmAnswer(t, "")
+
+	if err := applyAssumeYes(deleteCmd); err != nil || assumeYes {
+		t.Errorf("expected prompts by default, got %v, %v", assumeYes, err)
+	}
+	if err := helpers.SaveSettings(&helpers.Settings{AssumeYes: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyAssumeYes(deleteCmd); err != nil || !assumeYes {
+		t.Errorf("expected assume_yes from the config, got %v, %v", assumeYes, err)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var contextTimeout time.Duration
+
+// activeContext describes the context this invocation targets, e.g.
+// "mylab (from LXC_GO_CLI_REMOTE)"; empty when using the lxc default remote
+var activeContext string
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:   "context <use|show|unset>",
+	Short: "Choose which LXD server subsequent commands target",
+	Long: `Choose which remote LXD server subsequent commands target.
+
+The context is saved in this tool's config file and applies to every later
+command, without changing the default remote of the lxc client itself. The
+` + helpers.ContextEnvVar + ` environment variable overrides it for a single
+shell or CI job. Remotes are added with 'lxc-go-cli remote add'.
+
+Available subcommands:
+  use    - Target a remote in subsequent commands
+  show   - Show the active context and where it comes from
+  unset  - Go back to the lxc client's default remote
+
+Examples:
+  lxc-go-cli context use mylab
+  ` + helpers.ContextEnvVar + `=staging lxc-go-cli list
+  lxc-go-cli context unset`,
+}
+
+// contextUseCmd represents the context use subcommand
+var contextUseCmd = &cobra.Command{
+	Use:   "use <remote>",
+	Short: "Target a remote in subsequent commands",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+		defer cancel()
+
+		return useContext(ctx, &DefaultContextManager{}, args[0])
+	},
+}
+
+// contextShowCmd represents the context show subcommand
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the active context and where it comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showContext(&DefaultContextManager{}, cmd.OutOrStdout())
+	},
+}
+
+// contextUnsetCmd represents the context unset subcommand
+var contextUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Go back to the lxc client's default remote",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unsetContext(&DefaultContextManager{})
+	},
+}
+
+// ContextManager interface for dependency injection
+type ContextManager interface {
+	ListRemotes(ctx context.Context) ([]helpers.Remote, error)
+	LoadSettings() (*helpers.Settings, error)
+	SaveSettings(settings *helpers.Settings) error
+}
+
+// DefaultContextManager implements ContextManager using helpers
+type DefaultContextManager struct{}
+
+func (d *DefaultContextManager) ListRemotes(ctx context.Context) ([]helpers.Remote, error) {
+	return helpers.ListRemotes(ctx)
+}
+
+func (d *DefaultContextManager) LoadSettings() (*helpers.Settings, error) {
+	return helpers.LoadSettings()
+}
+
+func (d *DefaultContextManager) SaveSettings(settings *helpers.Settings) error {
+	return helpers.SaveSettings(settings)
+}
+
+// useContext saves a remote as the context for subsequent commands
+func useContext(ctx context.Context, manager ContextManager, remote string) error {
+	found, err := findRemote(ctx, manager, remote)
+	if err != nil {
+		return err
+	}
+	if found.Protocol == "simplestreams" {
+		return fmt.Errorf("remote '%s' is an image server and cannot run containers", remote)
+	}
+
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Context = remote
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+
+	logger.Info("Context set to '%s' (%s)", remote, valueOrDash(found.Addr))
+	if envRemote, source := helpers.ResolveContext(nil); source == helpers.ContextSourceEnv && envRemote != remote {
+		logger.Warn("%s=%s overrides the saved context in this shell", helpers.ContextEnvVar, envRemote)
+	}
+	return nil
+}
+
+// showContext prints the active context and its source
+func showContext(manager ContextManager, out io.Writer) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	remote, source := helpers.ResolveContext(settings)
+	if remote == "" {
+		fmt.Fprintln(out, "No context set; commands use the lxc client's default remote")
+		return nil
+	}
+	fmt.Fprintln(out, contextLabel(remote, source))
+	return nil
+}
+
+// unsetContext removes the saved context
+func unsetContext(manager ContextManager) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings.Context == "" {
+		logger.Info("No context is set")
+		return nil
+	}
+
+	settings.Context = ""
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+	logger.Info("Context unset; commands use the lxc client's default remote")
+	return nil
+}
+
+// contextLabel renders a context with its source, e.g. "mylab (from config)"
+func contextLabel(remote, source string) string {
+	if source == helpers.ContextSourceEnv {
+		return fmt.Sprintf("%s (from %s)", remote, helpers.ContextEnvVar)
+	}
+	return fmt.Sprintf("%s (from %s)", remote, source)
+}
+
+// printActiveContext writes the active context and project above command
+// output, if either is set
+func printActiveContext(out io.Writer) {
+	if activeContext == "" && activeProject == "" {
+		return
+	}
+	if activeContext != "" {
+		fmt.Fprintf(out, "Context: %s\n", activeContext)
+	}
+	if activeProject != "" {
+		fmt.Fprintf(out, "Project: %s\n", activeProject)
+	}
+	fmt.Fprintln(out)
+}
+
+// contextFreeCommands manage remotes and contexts themselves, or never talk to
+// LXD, so they run against the user's own lxc configuration
+var contextFreeCommands = map[string]bool{
+	"remote":     true,
+	"context":    true,
+	"alias":      true,
+	"version":    true,
+	"completion": true,
+	"help":       true,
+}
+
+// usesSettings reports whether a command is affected by this tool's config
+// file; the root command and contextFreeCommands are not
+func usesSettings(cmd *cobra.Command) bool {
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	return top.HasParent() && !contextFreeCommands[top.Name()]
+}
+
+// applyContext points lxc at the active context before a command runs
+func applyContext(cmd *cobra.Command) error {
+	if !usesSettings(cmd) {
+		return nil
+	}
+
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return err
+	}
+	remote, source := helpers.ResolveContext(settings)
+	if remote == "" {
+		return nil
+	}
+	if err := helpers.ActivateContext(remote); err != nil {
+		return err
+	}
+	activeContext = contextLabel(remote, source)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+
+	contextCmd.AddCommand(contextUseCmd)
+	contextCmd.AddCommand(contextShowCmd)
+	contextCmd.AddCommand(contextUnsetCmd)
+	// Let users inspect or drop a context whose remote is unreachable
+	skipLXCCheck(contextShowCmd)
+	skipLXCCheck(contextUnsetCmd)
+
+	contextUseCmd.Flags().DurationVarP(&contextTimeout, "timeout", "t", 30*time.Second, "Timeout for looking up the remote")
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// MockContextManager for testing context command
+type MockContextManager struct {
+	Remotes  []helpers.Remote
+	Settings helpers.Settings
+	SaveErr  error
+	Saved    bool
+}
+
+func (m *MockContextManager) ListRemotes(ctx context.Context) ([]helpers.Remote, error) {
+	return m.Remotes, nil
+}
+
+func (m *MockContextManager) LoadSettings() (*helpers.Settings, error) {
+	settings := m.Settings
+	return &settings, nil
+}
+
+func (m *MockContextManager) SaveSettings(settings *helpers.Settings) error {
+	if m.SaveErr != nil {
+		return m.SaveErr
+	}
+	m.Settings = *settings
+	m.Saved = true
+	return nil
+}
+
+func newMockContextManager() *MockContextManager {
+	return &MockContextManager{Remotes: newMockRemoteManager().Remotes}
+}
+
+func TestUseContext(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockContextManager()
+	if err := useContext(ctx, manager, "mylab"); err != nil {
+		t.Fatalf("useContext failed: %v", err)
+	}
+	if manager.Settings.Context != "mylab" {
+		t.Errorf("Expected context mylab to be saved, got %q", manager.Settings.Context)
+	}
+
+	for name, want := range map[string]string{"missing": "does not exist", "images": "image server"} {
+		manager := newMockContextManager()
+		if err := useContext(ctx, manager, name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("useContext(%s): expected error containing %q, got %v", name, want, err)
+		}
+		if manager.Saved {
+			t.Errorf("useContext(%s) should not save", name)
+		}
+	}
+
+	manager = newMockContextManager()
+	manager.SaveErr = fmt.Errorf("read-only file system")
+	if err := useContext(ctx, manager, "mylab"); err == nil {
+		t.Error("Expected save error")
+	}
+}
+
+func TestShowAndUnsetContext(t *testing.T) {
+	t.Setenv(helpers.ContextEnvVar, "")
+
+	manager := newMockContextManager()
+	var out bytes.Buffer
+	if err := showContext(manager, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No context set") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	manager.Settings.Context = "mylab"
+	out.Reset()
+	_ = showContext(manager, &out)
+	if strings.TrimSpace(out.String()) != "mylab (from config)" {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	t.Setenv(helpers.ContextEnvVar, "staging")
+	out.Reset()
+	_ = showContext(manager, &out)
+	if strings.TrimSpace(out.String()) != "staging (from "+helpers.ContextEnvVar+")" {
+		t.Errorf("Expected environment override, got %q", out.String())
+	}
+
+	if err := unsetContext(manager); err != nil {
+		t.Fatal(err)
+	}
+	if manager.Settings.Context != "" {
+		t.Error("Expected context to be cleared")
+	}
+	manager.Saved = false
+	if err := unsetContext(manager); err != nil || manager.Saved {
+		t.Errorf("Unsetting twice should be a no-op, got %v", err)
+	}
+}
+
+func TestApplyContextSkipsRemoteCommands(t *testing.T) {
+	t.Setenv(helpers.ContextEnvVar, "does-not-exist")
+	activeContext = ""
+
+	for _, cmd := range []*cobra.Command{remoteAddCmd, contextUseCmd, versionCmd, rootCmd} {
+		if err := applyContext(cmd); err != nil {
+			t.Errorf("%s should not apply the context: %v", cmd.CommandPath(), err)
+		}
+	}
+	if activeContext != "" {
+		t.Errorf("No context should be active, got %q", activeContext)
+	}
+
+	// Other commands fail early when the context's remote is unknown
+	t.Setenv("LXD_CONF", t.TempDir())
+	t.Setenv("LXC_GO_CLI_LXD_CONF", "")
+	if err := applyContext(listCmd); err == nil || !strings.Contains(err.Error(), "does-not-exist") {
+		t.Errorf("Expected unknown remote error, got %v", err)
+	}
+}
+
+func TestListShowsActiveContext(t *testing.T) {
+	activeContext = "mylab (from config)"
+	defer func() { activeContext = "" }()
+
+	manager := newMockListManager()
+	var out bytes.Buffer
+	if err := listContainers(context.Background(), manager, false, nil, &out, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Context: mylab (from config)\n") {
+		t.Errorf("Expected context header, got:\n%s", out.String())
+	}
+}
@@ -4,7 +4,15 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/deji/lxc-go-cli/internal/helpers"
 	"github.com/deji/lxc-go-cli/internal/logger"
@@ -12,21 +20,304 @@ import (
 )
 
 var (
-	containerName string
-	imageName     string
-	storageSize   string
+	containerName       string
+	imageName           string
+	storageSize         string
+	storagePool         string
+	createMaxDuration   time.Duration
+	createStepTimeout   map[string]string
+	createResume        bool
+	createNoProvision   bool
+	createOutput        string
+	createTimezone      string
+	createLocale        string
+	createPackages      []string
+	createPkgFile       string
+	createDockerStore   DockerStorageOptions
+	createEphemeral     bool
+	createVM            bool
+	createTarget        string
+	createParallel      int
+	createPasswordStdin bool
+	createTimings       string
+	createPreset        string
+	createIPv6          string
+	createOnFailure     string
 )
 
+// createSummaryIPWait bounds how long create waits for the container's
+// address before printing the summary without it
+var createSummaryIPWait = 30 * time.Second
+
+// createIPv6Wait bounds how long the ipv6 step waits for the container's
+// IPv6 addresses to match --ipv6
+var createIPv6Wait = 30 * time.Second
+
+// defaultStepParallel is how many independent create steps run at once
+const defaultStepParallel = 4
+
+// CreateOptions holds the settings for creating a container
+type CreateOptions struct {
+	Name        string
+	Image       string
+	Size        string
+	StoragePool string
+	// MaxDuration bounds the whole create; zero means no total budget
+	MaxDuration time.Duration
+	// StepTimeouts overrides defaultStepTimeouts for individual steps
+	StepTimeouts map[string]time.Duration
+	// Resume continues an interrupted create of an existing container
+	Resume bool
+	// OnFailure is what happens to the container when a step fails: one of
+	// OnFailureKeep, OnFailureDestroy or OnFailureSnapshot; empty means
+	// destroy, or keep with --keep-partial
+	OnFailure string
+	// NoProvision only launches and secures the container; 'provision' does the rest later
+	NoProvision bool
+	// Output is the summary format, text or json
+	Output string
+	// Out receives the summary; nil prints none
+	Out io.Writer
+	// Locale is the requested time zone and locale; empty fields fall back
+	// to HostLocale
+	Locale     LocaleOptions
+	HostLocale LocaleOptions
+	// Packages are extra apt packages installed after Docker
+	Packages []string
+	// DockerStorage chooses where and how Docker stores images
+	DockerStorage DockerStorageOptions
+	// Ephemeral launches a container that is deleted when it stops
+	Ephemeral bool
+	// VM launches a virtual machine instead of a container
+	VM bool
+	// Target is the cluster member to launch on; empty lets LXD place it
+	Target string
+	// Parallel bounds how many independent steps run at once; zero or one
+	// runs them in order
+	Parallel int
+	// AppPassword is the 'app' user password; empty generates one
+	AppPassword string
+	// Timings records how long each step takes; nil records nothing
+	Timings *Timings
+	// Preset names the size preset Limits came from, if any
+	Preset string
+	// Limits are the CPU, memory and root disk set at launch
+	Limits helpers.SizePreset
+	// IPv6 is how the container gets its IPv6 address; the zero value keeps
+	// the network's defaults and checks nothing
+	IPv6 helpers.IPv6Config
+}
+
+// DockerStorageOptions is how Docker stores its images and containers
+type DockerStorageOptions struct {
+	// Driver is a Docker storage driver, or auto (the default) to pick one
+	// from the filesystem under /var/lib/docker
+	Driver string
+	// VolumePool, when set, holds a custom volume mounted at /var/lib/docker
+	VolumePool string
+}
+
+// driver returns the requested storage driver, auto when none was given
+func (o DockerStorageOptions) driver() string {
+	if o.Driver == "" {
+		return helpers.DockerStorageAuto
+	}
+	return o.Driver
+}
+
+// validate checks the storage driver and that the volume pool exists and is
+// not Btrfs, which would defeat the point of a separate volume
+func (o DockerStorageOptions) validate(manager ContainerManager) error {
+	if err := helpers.ValidateDockerStorageDriver(o.driver()); err != nil {
+		return err
+	}
+	if o.VolumePool == "" {
+		return nil
+	}
+	pool, err := manager.GetStoragePool(o.VolumePool)
+	if err != nil {
+		return fmt.Errorf("failed to use storage pool '%s' for Docker: %w", o.VolumePool, err)
+	}
+	if pool.Driver == "btrfs" {
+		return fmt.Errorf("storage pool '%s' uses Btrfs; --docker-volume-pool needs a pool with an ext4 or xfs filesystem, such as an lvm pool", o.VolumePool)
+	}
+	return nil
+}
+
+// LocaleOptions is a container's time zone and locale; empty fields keep the
+// image defaults (UTC and POSIX)
+type LocaleOptions struct {
+	Timezone string
+	Locale   string
+}
+
+// withDefaults fills empty fields from defaults
+func (o LocaleOptions) withDefaults(defaults LocaleOptions) LocaleOptions {
+	if o.Timezone == "" {
+		o.Timezone = defaults.Timezone
+	}
+	if o.Locale == "" {
+		o.Locale = defaults.Locale
+	}
+	return o
+}
+
+// validate checks the time zone and locale names
+func (o LocaleOptions) validate() error {
+	if o.Timezone != "" {
+		if err := helpers.ValidateTimezone(o.Timezone); err != nil {
+			return err
+		}
+	}
+	if o.Locale != "" {
+		if err := helpers.ValidateLocale(o.Locale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePackages merges the --package values with the names listed in
+// --packages-file, dropping duplicates
+func resolvePackages(packages []string, file string) ([]string, error) {
+	if file != "" {
+		listed, err := helpers.LoadPackagesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(append([]string(nil), packages...), listed...)
+	}
+
+	seen := make(map[string]bool, len(packages))
+	var unique []string
+	for _, name := range packages {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique, nil
+}
+
+// hostLocaleOptions returns the host's time zone and locale, which containers
+// inherit unless --timezone or --locale is given
+func hostLocaleOptions() LocaleOptions {
+	return LocaleOptions{Timezone: helpers.HostTimezone(), Locale: helpers.HostLocale()}
+}
+
+// CreateSummary describes a created container for people and scripts
+type CreateSummary struct {
+	Name        string   `json:"name"`
+	Image       string   `json:"image"`
+	Size        string   `json:"size"`
+	StoragePool string   `json:"storage_pool"`
+	IPv4        string   `json:"ipv4"`
+	Provisioned bool     `json:"provisioned"`
+	Ephemeral   bool     `json:"ephemeral"`
+	VM          bool     `json:"vm"`
+	Location    string   `json:"location,omitempty"`
+	User        string   `json:"user,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	NextSteps   []string `json:"next_steps"`
+}
+
+// Create steps, in order. The names are used by --step-timeout.
+const (
+	stepImage         = "image"
+	stepLaunch        = "launch"
+	stepIPv6          = "ipv6"
+	stepSecurity      = "security"
+	stepAptUpdate     = "apt-update"
+	stepLocale        = "locale"
+	stepDockerStorage = "docker-storage"
+	stepDockerInstall = "docker-install"
+	stepPackages      = "packages"
+	stepAppUser       = "app-user"
+	stepRestart       = "restart"
+)
+
+// defaultStepTimeouts are generous limits that only catch hung steps; launch
+// still downloads the image when image could not check for it
+var defaultStepTimeouts = map[string]time.Duration{
+	stepImage:         15 * time.Minute,
+	stepLaunch:        15 * time.Minute,
+	stepIPv6:          2 * time.Minute,
+	stepSecurity:      1 * time.Minute,
+	stepAptUpdate:     5 * time.Minute,
+	stepLocale:        5 * time.Minute,
+	stepDockerStorage: 5 * time.Minute,
+	stepDockerInstall: 20 * time.Minute,
+	stepPackages:      15 * time.Minute,
+	stepAppUser:       2 * time.Minute,
+	stepRestart:       3 * time.Minute,
+}
+
+// stepTimeout returns the timeout for a create step
+func (o CreateOptions) stepTimeout(step string) time.Duration {
+	if timeout, ok := o.StepTimeouts[step]; ok {
+		return timeout
+	}
+	return defaultStepTimeouts[step]
+}
+
+// parseStepTimeouts parses --step-timeout values such as docker-install=30m
+func parseStepTimeouts(values map[string]string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(values))
+	for step, value := range values {
+		if _, known := defaultStepTimeouts[step]; !known {
+			return nil, helpers.UsageErrorf("unknown create step '%s' (valid steps: %s)", step, strings.Join(append([]string{stepImage}, createStepNames()...), ", "))
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, helpers.UsageErrorf("invalid timeout '%s' for step '%s'", value, step)
+		}
+		timeouts[step] = timeout
+	}
+	return timeouts, nil
+}
+
+// createStepNames lists the create steps in execution order
+func createStepNames() []string {
+	return []string{stepLaunch, stepSecurity, stepAptUpdate, stepLocale, stepDockerStorage, stepDockerInstall, stepPackages, stepAppUser, stepRestart}
+}
+
+// launchStepNames are the steps create runs with --no-provision
+func launchStepNames() []string {
+	return []string{stepLaunch, stepSecurity}
+}
+
 // ContainerManager interface for dependency injection
 type ContainerManager interface {
 	GetOrCreateBtrfsPool() (string, error)
+	GetStoragePool(name string) (*helpers.StoragePool, error)
+	GetBtrfsUsage(pool string) (*helpers.BtrfsUsage, error)
 	ContainerExists(name string) bool
-	CreateContainer(name, distro, release, arch, storagePool string) error
+	LaunchInstance(name, distro, release, arch, storagePool string, opts helpers.LaunchOptions) error
+	DeleteContainer(name string) error
+	CreateSnapshot(containerName, snapshotName string) error
+	PublishSnapshot(ctx context.Context, containerName, snapshotName, alias string) error
+	RecordAuditEvent(event helpers.AuditEvent) error
+	IsClustered(ctx context.Context) (bool, error)
+	ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error)
+	GetInstanceType(name string) (string, error)
+	WaitForAgent(ctx context.Context, name string) error
 	ConfigureContainerSecurity(containerName string) error
-	RunInContainer(containerName string, args ...string) error
-	RestartContainer(name string) error
+	RunInContainer(ctx context.Context, containerName string, args ...string) error
+	RestartContainer(ctx context.Context, name string) error
 	StoreContainerPassword(containerName, password string) error
 	SetUserPassword(containerName, username, password string) error
+	SetConfigValue(containerName, key, value string) error
+	GetConfigValue(containerName, key string) (string, error)
+	StartContainer(name string) error
+	GetContainerPassword(containerName string) (string, error)
+	GetContainerIPv4(name string) (string, error)
+	GetContainerIPv6Addresses(name string) ([]string, error)
+	DisableIPv6(ctx context.Context, name string) error
+	PlanSubIDFixes() ([]helpers.IdmapFix, error)
+	AttachDockerVolume(containerName, pool string) error
+	FindLocalImage(ctx context.Context, image string) (*helpers.LocalImage, error)
+	DownloadImage(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error)
+	Notify(ctx context.Context, event helpers.NotificationEvent)
 }
 
 // DefaultContainerManager implements ContainerManager using helpers
@@ -36,24 +327,64 @@ func (d *DefaultContainerManager) GetOrCreateBtrfsPool() (string, error) {
 	return helpers.GetOrCreateBtrfsPool()
 }
 
+func (d *DefaultContainerManager) GetStoragePool(name string) (*helpers.StoragePool, error) {
+	return helpers.GetStoragePool(name)
+}
+
+func (d *DefaultContainerManager) GetBtrfsUsage(pool string) (*helpers.BtrfsUsage, error) {
+	return helpers.GetBtrfsUsage(pool)
+}
+
 func (d *DefaultContainerManager) ContainerExists(name string) bool {
 	return helpers.ContainerExists(name)
 }
 
-func (d *DefaultContainerManager) CreateContainer(name, distro, release, arch, storagePool string) error {
-	return helpers.CreateContainer(name, distro, release, arch, storagePool)
+func (d *DefaultContainerManager) LaunchInstance(name, distro, release, arch, storagePool string, opts helpers.LaunchOptions) error {
+	return helpers.LaunchInstance(name, distro, release, arch, storagePool, opts)
+}
+
+func (d *DefaultContainerManager) DeleteContainer(name string) error {
+	return helpers.DeleteContainer(name, true)
+}
+
+func (d *DefaultContainerManager) CreateSnapshot(containerName, snapshotName string) error {
+	return helpers.CreateSnapshot(containerName, snapshotName)
+}
+
+func (d *DefaultContainerManager) PublishSnapshot(ctx context.Context, containerName, snapshotName, alias string) error {
+	return helpers.PublishSnapshot(ctx, containerName, snapshotName, alias)
+}
+
+func (d *DefaultContainerManager) RecordAuditEvent(event helpers.AuditEvent) error {
+	return helpers.AppendAuditEvent(event)
+}
+
+func (d *DefaultContainerManager) IsClustered(ctx context.Context) (bool, error) {
+	return helpers.IsClustered(ctx)
+}
+
+func (d *DefaultContainerManager) ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error) {
+	return helpers.ListClusterMembers(ctx)
+}
+
+func (d *DefaultContainerManager) GetInstanceType(name string) (string, error) {
+	return helpers.GetInstanceType(context.Background(), name)
+}
+
+func (d *DefaultContainerManager) WaitForAgent(ctx context.Context, name string) error {
+	return helpers.WaitForAgent(ctx, name)
 }
 
 func (d *DefaultContainerManager) ConfigureContainerSecurity(containerName string) error {
 	return helpers.ConfigureContainerSecurity(containerName)
 }
 
-func (d *DefaultContainerManager) RunInContainer(containerName string, args ...string) error {
-	return helpers.RunInContainer(containerName, args...)
+func (d *DefaultContainerManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	return helpers.RunInContainer(ctx, containerName, args...)
 }
 
-func (d *DefaultContainerManager) RestartContainer(name string) error {
-	return helpers.RestartContainer(name)
+func (d *DefaultContainerManager) RestartContainer(ctx context.Context, name string) error {
+	return helpers.RestartContainer(ctx, name)
 }
 
 func (d *DefaultContainerManager) StoreContainerPassword(containerName, password string) error {
@@ -64,73 +395,868 @@ func (d *DefaultContainerManager) SetUserPassword(containerName, username, passw
 	return helpers.SetUserPassword(containerName, username, password)
 }
 
-// createContainer creates a container with the given parameters
+func (d *DefaultContainerManager) SetConfigValue(containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultContainerManager) GetConfigValue(containerName, key string) (string, error) {
+	return helpers.GetConfigValue(containerName, key)
+}
+
+func (d *DefaultContainerManager) StartContainer(name string) error {
+	return helpers.StartContainer(name)
+}
+
+func (d *DefaultContainerManager) GetContainerPassword(containerName string) (string, error) {
+	return helpers.GetContainerPassword(containerName)
+}
+
+func (d *DefaultContainerManager) GetContainerIPv4(name string) (string, error) {
+	return helpers.GetContainerIPv4(name)
+}
+
+func (d *DefaultContainerManager) GetContainerIPv6Addresses(name string) ([]string, error) {
+	return helpers.GetContainerIPv6Addresses(name)
+}
+
+func (d *DefaultContainerManager) DisableIPv6(ctx context.Context, name string) error {
+	return helpers.DisableContainerIPv6(ctx, name)
+}
+
+func (d *DefaultContainerManager) PlanSubIDFixes() ([]helpers.IdmapFix, error) {
+	// The ranges that matter are on the LXD host, not this client
+	if err := helpers.RequireLocalServer(context.Background(), "subordinate id checks"); err != nil {
+		return nil, err
+	}
+	return helpers.PlanSubIDFixes()
+}
+
+func (d *DefaultContainerManager) AttachDockerVolume(containerName, pool string) error {
+	return helpers.AttachDockerVolume(containerName, pool)
+}
+
+func (d *DefaultContainerManager) FindLocalImage(ctx context.Context, image string) (*helpers.LocalImage, error) {
+	return helpers.FindLocalImage(ctx, image)
+}
+
+func (d *DefaultContainerManager) DownloadImage(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error) {
+	return helpers.DownloadImage(ctx, image, onProgress)
+}
+
+func (d *DefaultContainerManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	helpers.Notify(ctx, event)
+}
+
 func createContainer(manager ContainerManager, name, image, size string) error {
+	return createContainerWithOptions(manager, CreateOptions{Name: name, Image: image, Size: size})
+}
+
+// createContainerWithOptions creates a container using the given options
+func createContainerWithOptions(manager ContainerManager, opts CreateOptions) error {
+	name, image, size := opts.Name, opts.Image, opts.Size
 	if name == "" {
 		return fmt.Errorf("container name is required (use --name)")
 	}
+	if name == helpers.AutoContainerName {
+		if opts.Resume {
+			return helpers.UsageErrorf("--resume needs the name of the container to continue (use --name)")
+		}
+		generated, err := helpers.GeneratePetName(manager.ContainerExists)
+		if err != nil {
+			return err
+		}
+		name, opts.Name = generated, generated
+		logger.Info("Generated container name '%s'", name)
+	}
+	if err := helpers.ValidateContainerName(name); err != nil {
+		return err
+	}
 	if image == "" {
 		image = "ubuntu:24.04"
 	}
 	if size == "" {
 		size = "10G"
 	}
+	output := strings.ToLower(opts.Output)
+	if output != "" && output != "text" && output != "json" {
+		return helpers.UsageErrorf("invalid output format '%s': must be 'text' or 'json'", opts.Output)
+	}
+	locale := opts.Locale.withDefaults(opts.HostLocale)
+	if err := locale.validate(); err != nil {
+		return err
+	}
+	if err := helpers.ValidatePackages(opts.Packages); err != nil {
+		return err
+	}
+	if err := opts.DockerStorage.validate(manager); err != nil {
+		return err
+	}
+	if opts.VM && opts.Ephemeral {
+		return helpers.UsageErrorf("--vm cannot be combined with --ephemeral")
+	}
+	if opts.VM && opts.DockerStorage.VolumePool != "" {
+		return helpers.UsageErrorf("--docker-volume-pool is for containers; a VM keeps Docker's data on its own disk")
+	}
+	if opts.Target != "" {
+		if err := checkLaunchTarget(manager, opts.Target); err != nil {
+			return err
+		}
+	}
+	if opts.NoProvision && (opts.Locale != (LocaleOptions{}) || len(opts.Packages) > 0 || opts.DockerStorage != (DockerStorageOptions{})) {
+		logger.Warn("--timezone, --locale, --package and the Docker storage flags are applied by 'provision'; pass them to it instead")
+	}
 
 	logger.Info("Creating container '%s' with image '%s' and storage size '%s'...", name, image, size)
 
-	// Get or create a Btrfs storage pool without changing system default
-	logger.Info("Checking for Btrfs storage pool...")
-	storagePool, err := manager.GetOrCreateBtrfsPool()
+	storagePool, err := resolveStoragePool(manager, opts.StoragePool)
 	if err != nil {
-		return fmt.Errorf("failed to get or create Btrfs storage pool: %w", err)
+		return err
 	}
-	logger.Info("Using Btrfs storage pool: '%s'", storagePool)
+	warnIfMetadataLow(manager, storagePool)
+	warnIfSubIDsMissing(manager)
 
 	// Check if container already exists
+	var completed []string
 	if manager.ContainerExists(name) {
-		return fmt.Errorf("container '%s' already exists", name)
+		if !opts.Resume {
+			return helpers.ConflictErrorf("container '%s' already exists (use --resume to continue an interrupted create)", name)
+		}
+		completed, err = resumableSteps(manager, name)
+		if err != nil {
+			return err
+		}
+		if opts.NoProvision && containsAll(completed, launchStepNames()) {
+			logger.Info("Container '%s' is already launched and secured", name)
+			return writeCreateSummary(manager, opts, image, size, storagePool, containsAll(completed, createStepNames()))
+		}
+		if containsAll(completed, createStepNames()) {
+			logger.Info("Container '%s' is already fully provisioned", name)
+			return writeCreateSummary(manager, opts, image, size, storagePool, true)
+		}
 	}
 
 	// Parse image string
 	distro, release, arch := helpers.ParseImageString(image)
 
-	// Create the container using LXC CLI
-	logger.Info("Creating container with image %s:%s:%s using storage pool '%s'...", distro, release, arch, storagePool)
-	if err := manager.CreateContainer(name, distro, release, arch, storagePool); err != nil {
-		return fmt.Errorf("failed to create container: %w", err)
+	// A failed create deals with the container it launched as the policy
+	// says; a resumed one launched it in an earlier run and is kept for
+	// another --resume
+	policy := opts.OnFailure
+	if policy == "" {
+		policy = OnFailureDestroy
+		if keepPartial {
+			policy = OnFailureKeep
+		}
+	}
+	undo := &UndoStack{Keep: policy == OnFailureKeep}
+	outcome := "failed before the container was launched"
+	if len(completed) > 0 {
+		outcome = "kept for another --resume (launched by an earlier run)"
+	}
+	steps := []Step{
+		{Name: stepLaunch, Run: func(ctx context.Context) error {
+			// Create the container using LXC CLI
+			logger.Info("Creating container with image %s:%s:%s using storage pool '%s'...", distro, release, arch, storagePool)
+			if opts.Ephemeral {
+				logger.Info("The container is ephemeral and will be deleted when it stops")
+			}
+			if opts.VM {
+				logger.Info("Launching a virtual machine instead of a container")
+			}
+			if opts.Target != "" {
+				logger.Info("Placing it on cluster member '%s'", opts.Target)
+			}
+			if opts.Preset != "" {
+				logger.Info("Sizing it with preset '%s': %s", opts.Preset, opts.Limits)
+			}
+			if opts.IPv6.Address != "" {
+				logger.Info("Assigning it IPv6 address %s", opts.IPv6.Address)
+			}
+			launch := helpers.LaunchOptions{
+				Ephemeral: opts.Ephemeral,
+				VM:        opts.VM,
+				Target:    opts.Target,
+				Config:    opts.Limits.Config(),
+				RootSize:  opts.Limits.Disk,
+				IPv6:      opts.IPv6,
+			}
+			if err := manager.LaunchInstance(name, distro, release, arch, storagePool, launch); err != nil {
+				return fmt.Errorf("failed to create container: %w", err)
+			}
+			outcome = fmt.Sprintf("kept for debugging (on-failure %s)", policy)
+			undo.Push(fmt.Sprintf("delete container '%s'", name), func(ctx context.Context) error {
+				image, err := discardFailedContainer(ctx, manager, name, policy, time.Now())
+				switch {
+				case err != nil:
+					outcome = fmt.Sprintf("kept after failing to discard it (on-failure %s): %v", policy, err)
+				case image != "":
+					outcome = fmt.Sprintf("saved as image '%s' and destroyed (on-failure %s)", image, policy)
+				default:
+					outcome = fmt.Sprintf("destroyed (on-failure %s)", policy)
+				}
+				return err
+			})
+
+			// Tag the container so list, delete and bulk operations know it is ours
+			markContainerManaged(manager, name, helpers.ManagedMarkerConfig(version, time.Now()))
+			return waitForAgent(ctx, manager, name)
+		}},
+		securityStep(manager, name),
+	}
+	if opts.IPv6.Mode != "" {
+		steps = append(steps, ipv6Step(manager, name, opts.IPv6))
+	}
+	if !opts.NoProvision {
+		steps = append(steps, provisionSteps(manager, name, len(completed) > 0, false, locale, opts.Packages, opts.DockerStorage, opts.AppPassword)...)
+	}
+	for i := range steps {
+		steps[i].Timeout = opts.stepTimeout(steps[i].Name)
+	}
+	steps = trackProvisionSteps(manager, name, steps, completed)
+	// Not recorded like the others: there is no container to record it on yet.
+	// VM images are separate from container images and launch downloads them.
+	if !containsAll(completed, []string{stepLaunch}) && !opts.VM {
+		pull := imageStep(manager, image)
+		pull.Timeout = opts.stepTimeout(stepImage)
+		steps = append([]Step{pull}, steps...)
+	}
+
+	if len(completed) > 0 {
+		logger.Info("Resuming create of '%s'; completed steps: %s", name, strings.Join(completed, ", "))
+		// The container may have been stopped since the interrupted run
+		if err := manager.StartContainer(name); err != nil {
+			logger.Debug("Start before resume returned: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), opts.stepTimeout(stepLaunch))
+		err := waitForAgent(ctx, manager, name)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	runner := NewStepRunner(opts.MaxDuration)
+	runner.Parallel = opts.Parallel
+	runner.Timings = opts.Timings
+	if err := runner.Run(context.Background(), steps...); err != nil {
+		undo.Rollback(err)
+		event := helpers.AuditEvent{Action: auditActionCreateFailed, Container: name, Reason: outcome, Error: err.Error()}
+		if auditErr := manager.RecordAuditEvent(event); auditErr != nil {
+			logger.Warn("Failed to record the failed create in the audit log: %v", auditErr)
+		}
+		if manager.ContainerExists(name) {
+			logger.Info("Run 'lxc-go-cli create --name %s --resume' to continue from the failed step", name)
+		}
+		manager.Notify(context.Background(), helpers.NotificationEvent{
+			Event:     helpers.EventProvisionFailed,
+			Container: name,
+			Message:   fmt.Sprintf("Creating container '%s' failed", name),
+			Error:     err.Error(),
+		})
+		return err
+	}
+
+	message := fmt.Sprintf("Container '%s' created and provisioned", name)
+	if opts.NoProvision {
+		logger.Info("Container '%s' created and secured. Run 'lxc-go-cli provision %s' to install Docker and the 'app' user", name, name)
+		message = fmt.Sprintf("Container '%s' created and secured", name)
+	} else {
+		logger.Info("Container setup complete!")
+	}
+	manager.Notify(context.Background(), helpers.NotificationEvent{Event: helpers.EventCreateComplete, Container: name, Message: message})
+	return writeCreateSummary(manager, opts, image, size, storagePool, !opts.NoProvision)
+}
+
+// Policies for a container whose create fails, chosen with --on-failure
+const (
+	// OnFailureKeep leaves the container for debugging and --resume
+	OnFailureKeep = "keep"
+	// OnFailureDestroy deletes the container
+	OnFailureDestroy = "destroy"
+	// OnFailureSnapshot saves the container as an image, then deletes it
+	OnFailureSnapshot = "snapshot"
+)
+
+// auditActionCreateFailed records in the audit log what a failed create did
+// with its container
+const auditActionCreateFailed = "create-failed"
+
+// resolveOnFailure picks the on-failure policy: --on-failure, then
+// --keep-partial, then the config file, then destroy
+func resolveOnFailure(flag string, flagSet bool, settings *helpers.Settings) (string, error) {
+	policy := flag
+	switch {
+	case flagSet:
+	case keepPartial:
+		policy = OnFailureKeep
+	case settings != nil && settings.Create.OnFailure != "":
+		policy = settings.Create.OnFailure
+	}
+	switch policy {
+	case OnFailureKeep, OnFailureDestroy, OnFailureSnapshot:
+		return policy, nil
+	}
+	return "", helpers.UsageErrorf("invalid on-failure policy '%s': must be %s, %s or %s", policy, OnFailureKeep, OnFailureDestroy, OnFailureSnapshot)
+}
+
+// failedImagePublishTimeout bounds publishing a failed container as an image.
+// Publishing compresses the whole root filesystem, which takes far longer
+// than the undo timeout for multi-GB containers.
+const failedImagePublishTimeout = 30 * time.Minute
+
+// discardFailedContainer deletes a container whose create failed. With the
+// snapshot policy it first publishes a snapshot of it as an image, returned,
+// and keeps the container if that fails.
+func discardFailedContainer(ctx context.Context, manager ContainerManager, name, policy string, now time.Time) (string, error) {
+	image := ""
+	if policy == OnFailureSnapshot {
+		snapshot := helpers.SnapshotName("failed", now)
+		image = "failed-" + name + "-" + now.Format("20060102-150405")
+		if err := manager.CreateSnapshot(name, snapshot); err != nil {
+			return "", err
+		}
+		logger.Info("Publishing container '%s' as image '%s'; this can take a while for large containers", name, image)
+		publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failedImagePublishTimeout)
+		err := manager.PublishSnapshot(publishCtx, name, snapshot, image)
+		cancel()
+		if err != nil {
+			return "", err
+		}
+		logger.Info("Saved container '%s' as image '%s'; inspect it with 'lxc launch %s <name>'", name, image, image)
+	}
+	return image, manager.DeleteContainer(name)
+}
+
+// imageStep makes sure the image is in the server's image store before the
+// launch, downloading it as a step of its own so its progress can be followed
+func imageStep(manager ContainerManager, image string) Step {
+	return Step{Name: stepImage, Run: func(ctx context.Context) error {
+		local, err := manager.FindLocalImage(ctx, image)
+		if err != nil {
+			// launch downloads the image itself, only without progress
+			logger.Warn("Could not check whether image '%s' is cached: %v", image, err)
+			return nil
+		}
+		if local != nil {
+			logger.Info("Image '%s' is cached (%s)", image, helpers.ShortFingerprint(local.Fingerprint))
+			return nil
+		}
+
+		logger.Info("Downloading image '%s'...", image)
+		logged := ""
+		fingerprint, err := manager.DownloadImage(ctx, image, func(p helpers.ImageProgress) {
+			progress.Emit(ProgressEvent{Event: EventDownload, Step: stepImage, Stage: p.Stage, Percent: &p.Percent, Bytes: p.Processed})
+			// Log every tenth of each stage rather than every poll
+			if mark := fmt.Sprintf("%s/%d", p.Stage, p.Percent/10); mark != logged {
+				logged = mark
+				logger.Info("Downloading image '%s': %s", image, p)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to download image '%s': %w", image, err)
+		}
+		logger.Info("Image '%s' downloaded (%s)", image, helpers.ShortFingerprint(fingerprint))
+		return nil
+	}}
+}
+
+// writeCreateSummary prints what was created, including the address and the
+// 'app' password, so scripts need not scrape log lines
+func writeCreateSummary(manager ContainerManager, opts CreateOptions, image, size, storagePool string, provisioned bool) error {
+	if opts.Out == nil {
+		return nil
+	}
+
+	summary := CreateSummary{
+		Name:        opts.Name,
+		Image:       image,
+		Size:        size,
+		StoragePool: storagePool,
+		IPv4:        waitForContainerIPv4(manager, opts.Name, createSummaryIPWait),
+		Provisioned: provisioned,
+		Ephemeral:   opts.Ephemeral,
+		VM:          opts.VM,
+		Location:    opts.Target,
+	}
+	if provisioned {
+		summary.User = "app"
+		// A supplied password is not stored, and the caller already has it
+		if opts.AppPassword == "" {
+			password, err := manager.GetContainerPassword(opts.Name)
+			if err != nil {
+				logger.Warn("Could not read the stored 'app' password: %v", err)
+			}
+			summary.Password = password
+		}
+		summary.NextSteps = []string{
+			fmt.Sprintf("lxc-go-cli exec %s", opts.Name),
+			fmt.Sprintf("lxc-go-cli port add %s <host-port> <container-port>", opts.Name),
+		}
+	} else {
+		summary.NextSteps = []string{fmt.Sprintf("lxc-go-cli provision %s", opts.Name)}
+	}
+
+	if strings.ToLower(opts.Output) == "json" {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode create summary: %w", err)
+		}
+		fmt.Fprintln(opts.Out, string(data))
+		return nil
+	}
+	fmt.Fprint(opts.Out, formatCreateSummary(summary))
+	return nil
+}
+
+// formatCreateSummary renders the summary as aligned text
+func formatCreateSummary(summary CreateSummary) string {
+	var sb strings.Builder
+	sb.WriteString("\nContainer summary:\n")
+	fmt.Fprintf(&sb, "  Name:          %s\n", summary.Name)
+	fmt.Fprintf(&sb, "  Image:         %s\n", summary.Image)
+	fmt.Fprintf(&sb, "  Size:          %s\n", summary.Size)
+	fmt.Fprintf(&sb, "  Storage pool:  %s\n", summary.StoragePool)
+	fmt.Fprintf(&sb, "  IPv4:          %s\n", valueOrDash(summary.IPv4))
+	if summary.Ephemeral {
+		sb.WriteString("  Ephemeral:     yes (deleted when stopped)\n")
+	}
+	if summary.VM {
+		sb.WriteString("  Type:          virtual machine\n")
+	}
+	if summary.Location != "" {
+		fmt.Fprintf(&sb, "  Location:      %s\n", summary.Location)
+	}
+	if summary.Provisioned {
+		fmt.Fprintf(&sb, "  User:          %s\n", summary.User)
+		fmt.Fprintf(&sb, "  Password:      %s\n", valueOrDash(summary.Password))
+	}
+	sb.WriteString("\nNext steps:\n")
+	for _, step := range summary.NextSteps {
+		fmt.Fprintf(&sb, "  %s\n", step)
+	}
+	return sb.String()
+}
+
+// waitForContainerIPv4 polls for the container's address, which DHCP may
+// only assign a few seconds after a restart; "" if none arrives in time
+func waitForContainerIPv4(manager ContainerManager, name string, timeout time.Duration) string {
+	deadline := time.Now().Add(timeout)
+	for {
+		ip, err := manager.GetContainerIPv4(name)
+		if err != nil {
+			logger.Debug("Could not read the address of '%s': %v", name, err)
+			return ""
+		}
+		if ip != "" || time.Now().After(deadline) {
+			return ip
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// ipv6Step turns IPv6 off for --ipv6 disabled, then waits until the
+// container's IPv6 addresses are what --ipv6 asked for
+func ipv6Step(manager ContainerManager, name string, config helpers.IPv6Config) Step {
+	return Step{Name: stepIPv6, After: []string{stepLaunch}, Run: func(ctx context.Context) error {
+		if config.Mode == helpers.IPv6Disabled {
+			logger.Info("Disabling IPv6 in container '%s'...", name)
+			if err := manager.DisableIPv6(ctx, name); err != nil {
+				return err
+			}
+		}
+
+		logger.Info("Checking the IPv6 address of container '%s' (%s)...", name, config)
+		addresses, err := waitForIPv6(ctx, manager, name, config, createIPv6Wait)
+		if err != nil {
+			return fmt.Errorf("IPv6 check failed for container '%s': %w", name, err)
+		}
+		if len(addresses) > 0 {
+			logger.Info("Container '%s' has IPv6 address %s", name, strings.Join(addresses, ", "))
+		} else {
+			logger.Info("Container '%s' has no IPv6 address", name)
+		}
+		return nil
+	}}
+}
+
+// waitForIPv6 polls the container's global IPv6 addresses until they match
+// config; router advertisements can take a few seconds after the launch
+func waitForIPv6(ctx context.Context, manager ContainerManager, name string, config helpers.IPv6Config, timeout time.Duration) ([]string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		addresses, err := manager.GetContainerIPv6Addresses(name)
+		if err != nil {
+			return nil, err
+		}
+		err = config.CheckIPv6Addresses(addresses)
+		if err == nil || time.Now().After(deadline) {
+			return addresses, err
+		}
+		select {
+		case <-ctx.Done():
+			return addresses, err
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// securityStep applies the security settings Docker needs
+func securityStep(manager ContainerManager, name string) Step {
+	return Step{Name: stepSecurity, After: []string{stepLaunch}, Run: func(ctx context.Context) error {
+		if isVirtualMachine(manager, name) {
+			logger.Info("Skipping container security settings: Docker runs on the VM's own kernel")
+			return nil
+		}
+		// Configure security settings for Docker
+		logger.Info("Configuring container security settings for Docker...")
+		if err := manager.ConfigureContainerSecurity(name); err != nil {
+			return fmt.Errorf("failed to configure container security: %w", err)
+		}
+		return nil
+	}}
+}
+
+// provisionSteps returns the steps that install Docker and the 'app' user in a
+// launched and secured container. existing marks a container that may already
+// have the 'app' user, such as a resumed one; with skipInstalledDocker a
+// container that already has Docker, such as an adopted one, keeps it and
+// its storage configuration. A non-empty appPassword is set for the 'app'
+// user instead of a generated one.
+//
+// apt allows one install at a time, so the steps using it form a chain.
+// Security settings are applied from the host alongside that chain, and the
+// 'app' user, which needs the docker group and may need groups or a shell
+// from the extra packages, is set up after them, once apt is done.
+func provisionSteps(manager ContainerManager, name string, existing, skipInstalledDocker bool, locale LocaleOptions, packages []string, storage DockerStorageOptions, appPassword string) []Step {
+	return []Step{
+		{Name: stepAptUpdate, After: []string{stepLaunch}, Run: func(ctx context.Context) error {
+			logger.Info("Setting up Docker, Docker Compose, and app user...")
+			// Update package index
+			logger.Debug("Updating package index...")
+			if err := manager.RunInContainer(ctx, name, "apt-get", "update"); err != nil {
+				return fmt.Errorf("failed to update package index: %w", err)
+			}
+			return nil
+		}},
+		{Name: stepLocale, After: []string{stepAptUpdate}, Run: func(ctx context.Context) error {
+			return configureLocale(ctx, manager, name, locale)
+		}},
+		{Name: stepDockerStorage, After: []string{stepLocale}, Run: func(ctx context.Context) error {
+			if skipInstalledDocker && manager.RunInContainer(ctx, name, "sh", "-c", "command -v docker") == nil {
+				logger.Info("Docker is already installed; keeping its storage configuration")
+				return nil
+			}
+			return configureDockerStorage(ctx, manager, name, storage)
+		}},
+		{Name: stepDockerInstall, After: []string{stepSecurity, stepDockerStorage}, Run: func(ctx context.Context) error {
+			if skipInstalledDocker && manager.RunInContainer(ctx, name, "sh", "-c", "command -v docker") == nil {
+				logger.Info("Docker is already installed")
+				return nil
+			}
+			// Install Docker and Docker Compose V2
+			logger.Debug("Installing Docker and Docker Compose V2...")
+			if err := helpers.InstallDockerInContainer(ctx, manager, name); err != nil {
+				return fmt.Errorf("failed to install Docker: %w", err)
+			}
+			return nil
+		}},
+		{Name: stepPackages, After: []string{stepDockerInstall}, Run: func(ctx context.Context) error {
+			if len(packages) == 0 {
+				return nil
+			}
+			logger.Info("Installing packages: %s...", strings.Join(packages, " "))
+			if err := manager.RunInContainer(ctx, name, helpers.PackageInstallCommand(packages)...); err != nil {
+				return fmt.Errorf("failed to install packages: %w", err)
+			}
+			return nil
+		}},
+		{Name: stepAppUser, After: []string{stepDockerInstall, stepPackages}, Run: func(ctx context.Context) error {
+			// A resumed step may have created the user before it was interrupted
+			if existing && manager.RunInContainer(ctx, name, "id", "app") == nil {
+				logger.Info("'app' user already exists, configuring it...")
+				return configureAppUser(ctx, manager, name, appPassword)
+			}
+			return setupAppUser(ctx, manager, name, appPassword)
+		}},
+		{Name: stepRestart, After: []string{stepPackages, stepAppUser}, Run: func(ctx context.Context) error {
+			// Restart container to ensure all settings take effect
+			logger.Info("Restarting container to apply all settings...")
+			if err := manager.RestartContainer(ctx, name); err != nil {
+				return fmt.Errorf("failed to restart container: %w", err)
+			}
+			return waitForAgent(ctx, manager, name)
+		}},
+	}
+}
+
+// checkLaunchTarget makes sure --target names an online member of a cluster
+func checkLaunchTarget(manager ContainerManager, target string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clustered, err := manager.IsClustered(ctx)
+	if err != nil {
+		return err
+	}
+	if !clustered {
+		return fmt.Errorf("--target needs a clustered LXD server, but this one is standalone")
+	}
+	members, err := manager.ListClusterMembers(ctx)
+	if err != nil {
+		return err
+	}
+	return helpers.CheckTargetMember(members, target)
+}
+
+// isVirtualMachine reports whether an instance is a VM; when its type cannot
+// be read it is treated as a container, as all instances used to be
+func isVirtualMachine(manager ContainerManager, name string) bool {
+	instanceType, err := manager.GetInstanceType(name)
+	if err != nil {
+		logger.Debug("Could not get the type of '%s': %v", name, err)
+		return false
+	}
+	return instanceType == helpers.InstanceTypeVM
+}
+
+// waitForAgent waits until commands can run in a VM, whose agent starts well
+// after the VM itself; containers are ready as soon as they start
+func waitForAgent(ctx context.Context, manager ContainerManager, name string) error {
+	if !isVirtualMachine(manager, name) {
+		return nil
+	}
+	logger.Info("Waiting for the agent of VM '%s'...", name)
+	if err := manager.WaitForAgent(ctx, name); err != nil {
+		return fmt.Errorf("VM '%s' is not ready for commands: %w", name, err)
+	}
+	return nil
+}
+
+// configureLocale sets the container's time zone and locale
+func configureLocale(ctx context.Context, manager ContainerManager, name string, locale LocaleOptions) error {
+	if locale.Timezone != "" {
+		logger.Info("Setting time zone to %s...", locale.Timezone)
+		if err := manager.RunInContainer(ctx, name, helpers.TimezoneCommand(locale.Timezone)...); err != nil {
+			return fmt.Errorf("failed to set time zone '%s': %w", locale.Timezone, err)
+		}
+	}
+	if locale.Locale != "" {
+		logger.Info("Setting locale to %s...", locale.Locale)
+		if err := manager.RunInContainer(ctx, name, helpers.LocaleCommand(locale.Locale)...); err != nil {
+			return fmt.Errorf("failed to set locale '%s': %w", locale.Locale, err)
+		}
+	}
+	return nil
+}
+
+// configureDockerStorage mounts the Docker volume and picks Docker's storage
+// driver before Docker is installed. On Btrfs Docker would choose its btrfs
+// driver, which creates a subvolume per layer and misbehaves when nested, so
+// auto uses fuse-overlayfs there and overlay2 on a separate volume.
+func configureDockerStorage(ctx context.Context, manager ContainerManager, name string, storage DockerStorageOptions) error {
+	if storage.VolumePool != "" {
+		logger.Info("Mounting a volume from storage pool '%s' at %s...", storage.VolumePool, helpers.DockerDataDir)
+		if err := manager.AttachDockerVolume(name, storage.VolumePool); err != nil {
+			return fmt.Errorf("failed to attach Docker volume: %w", err)
+		}
+	}
+
+	driver := storage.driver()
+	if driver == helpers.DockerStorageAuto {
+		switch {
+		case storage.VolumePool != "":
+			driver = helpers.DockerStorageOverlay2
+		case manager.RunInContainer(ctx, name, helpers.BtrfsDataDirCommand()...) == nil:
+			logger.Info("Container filesystem is Btrfs; using the fuse-overlayfs storage driver for Docker")
+			driver = helpers.DockerStorageFuseOverlayfs
+		default:
+			// Docker picks overlay2 by itself on other filesystems
+			logger.Debug("Leaving Docker's storage driver at its default")
+			return nil
+		}
+	}
+
+	if driver == helpers.DockerStorageFuseOverlayfs {
+		if err := manager.RunInContainer(ctx, name, helpers.PackageInstallCommand([]string{"fuse-overlayfs"})...); err != nil {
+			return fmt.Errorf("failed to install fuse-overlayfs: %w", err)
+		}
+	}
+
+	logger.Info("Configuring Docker to use the %s storage driver...", driver)
+	if err := manager.RunInContainer(ctx, name, helpers.DockerStorageDriverCommand(driver)...); err != nil {
+		return fmt.Errorf("failed to configure Docker storage driver: %w", err)
+	}
+	return nil
+}
+
+// containsAll reports whether every wanted value is in values
+func containsAll(values, wanted []string) bool {
+	present := make(map[string]bool, len(values))
+	for _, value := range values {
+		present[value] = true
+	}
+	for _, value := range wanted {
+		if !present[value] {
+			return false
+		}
+	}
+	return true
+}
+
+// resumableSteps returns the steps already completed for an existing container
+func resumableSteps(manager ContainerManager, name string) ([]string, error) {
+	value, err := manager.GetConfigValue(name, helpers.ProvisionStepsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provisioning state: %w", err)
 	}
+	completed := helpers.SplitConfigList(value)
+	if len(completed) == 0 {
+		return nil, fmt.Errorf("container '%s' has no provisioning state to resume; it was not created by an interrupted 'create'", name)
+	}
+	return completed, nil
+}
 
-	// Configure security settings for Docker
-	logger.Info("Configuring container security settings for Docker...")
-	if err := manager.ConfigureContainerSecurity(name); err != nil {
-		return fmt.Errorf("failed to configure container security: %w", err)
+// trackProvisionSteps drops completed steps and records each remaining step
+// in the container's metadata once it succeeds
+func trackProvisionSteps(manager ContainerManager, name string, steps []Step, completed []string) []Step {
+	done := make(map[string]bool, len(completed))
+	for _, step := range completed {
+		done[step] = true
 	}
+	recorded := append([]string(nil), completed...)
+	// Independent steps may finish at the same time
+	var mu sync.Mutex
 
-	logger.Info("Container created and started. Setting up Docker, Docker Compose, and app user...")
+	var remaining []Step
+	for _, step := range steps {
+		if done[step.Name] {
+			logger.Debug("Skipping completed step '%s'", step.Name)
+			continue
+		}
+		run, stepName := step.Run, step.Name
+		step.Run = func(ctx context.Context) error {
+			if err := run(ctx); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			recorded = append(recorded, stepName)
+			if err := manager.SetConfigValue(name, helpers.ProvisionStepsKey, strings.Join(recorded, ",")); err != nil {
+				logger.Warn("Failed to record completed step '%s': %v", stepName, err)
+			}
+			return nil
+		}
+		remaining = append(remaining, step)
+	}
+	return remaining
+}
 
-	// Update package index
-	logger.Debug("Updating package index...")
-	if err := manager.RunInContainer(name, "apt-get", "update"); err != nil {
-		return fmt.Errorf("failed to update package index: %w", err)
+// markContainerManaged writes the managed marker keys in a stable order.
+// Failures are logged rather than failing the whole create.
+func markContainerManaged(manager ContainerManager, name string, marker map[string]string) {
+	keys := make([]string, 0, len(marker))
+	for key := range marker {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	// Install Docker and Docker Compose V2
-	logger.Debug("Installing Docker and Docker Compose V2...")
-	if err := helpers.InstallDockerInContainer(manager, name); err != nil {
-		return fmt.Errorf("failed to install Docker: %w", err)
+	for _, key := range keys {
+		if err := manager.SetConfigValue(name, key, marker[key]); err != nil {
+			logger.Warn("Failed to set %s on container '%s': %v", key, name, err)
+		}
 	}
+}
+
+// AppUserManager is the subset of container operations needed to set up the 'app' user
+type AppUserManager interface {
+	RunInContainer(ctx context.Context, containerName string, args ...string) error
+	SetUserPassword(containerName, username, password string) error
+	StoreContainerPassword(containerName, password string) error
+}
 
-	// Generate secure password for 'app' user
-	password := helpers.GenerateSecurePassword()
-	logger.Info("Generated secure password for 'app' user: %s", password)
-	logger.Info("IMPORTANT: Save this password - you'll need it for sudo access in the container!")
+// maxAppPassword bounds the 'app' password read by --password-stdin
+const maxAppPassword = 4096
+
+// resolveCreatePreset looks up the size preset for create; an explicit
+// --size replaces the preset's disk size
+func resolveCreatePreset(name, size string, sizeChanged bool) (helpers.SizePreset, error) {
+	if name == "" {
+		return helpers.SizePreset{}, nil
+	}
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return helpers.SizePreset{}, err
+	}
+	preset, err := helpers.ResolveSizePreset(settings, name)
+	if err != nil {
+		return helpers.SizePreset{}, err
+	}
+	if sizeChanged {
+		if err := helpers.ValidateStorageSize(size); err != nil {
+			return helpers.SizePreset{}, err
+		}
+		preset.Disk = size
+	}
+	return preset, nil
+}
 
-	// Create 'app' user and add to docker and sudo groups
+// resolveAppPassword returns the 'app' password supplied on stdin or in
+// AppPasswordEnvVar, or empty to generate one
+func resolveAppPassword(fromStdin bool, stdin io.Reader) (string, error) {
+	if fromStdin {
+		data, err := io.ReadAll(io.LimitReader(stdin, maxAppPassword+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to read password from stdin: %w", err)
+		}
+		if len(data) > maxAppPassword {
+			return "", fmt.Errorf("password on stdin is longer than %d bytes", maxAppPassword)
+		}
+		// Drop the newline left by echo or a file
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("no password on stdin")
+		}
+		if err := helpers.ValidateSuppliedPassword(password); err != nil {
+			return "", helpers.UsageErrorf("invalid password on stdin: %w", err)
+		}
+		return password, nil
+	}
+
+	password, ok := os.LookupEnv(helpers.AppPasswordEnvVar)
+	if !ok {
+		return "", nil
+	}
+	if err := helpers.ValidateSuppliedPassword(password); err != nil {
+		return "", helpers.UsageErrorf("invalid password in $%s: %w", helpers.AppPasswordEnvVar, err)
+	}
+	return password, nil
+}
+
+// setupAppUser creates the 'app' user with the given or a generated password
+// and docker and sudo access
+func setupAppUser(ctx context.Context, manager AppUserManager, name, password string) error {
 	logger.Debug("Creating 'app' user...")
-	if err := manager.RunInContainer(name, "useradd", "-m", "-s", "/bin/bash", "app"); err != nil {
+	if err := manager.RunInContainer(ctx, name, "useradd", "-m", "-s", "/bin/bash", "app"); err != nil {
 		return fmt.Errorf("failed to create 'app' user: %w", err)
 	}
 
+	return configureAppUser(ctx, manager, name, password)
+}
+
+// configureAppUser gives an existing 'app' user a password and docker and
+// sudo access. An empty password generates one, which is logged and stored
+// in the container metadata; a supplied one is neither, as its owner (such
+// as a vault) already keeps it.
+func configureAppUser(ctx context.Context, manager AppUserManager, name, password string) error {
+	supplied := password != ""
+	if supplied {
+		logger.Info("Using the supplied password for 'app' user")
+	} else {
+		password = helpers.GenerateSecurePassword()
+		logger.Info("Generated secure password for 'app' user: %s", password)
+		logger.Info("IMPORTANT: Save this password - you'll need it for sudo access in the container!")
+	}
+
 	// Set password for 'app' user
 	logger.Debug("Setting password for 'app' user...")
 	if err := manager.SetUserPassword(name, "app", password); err != nil {
@@ -138,10 +1264,14 @@ func createContainer(manager ContainerManager, name, image, size string) error {
 	}
 
 	logger.Debug("Adding 'app' user to docker and sudo groups...")
-	if err := manager.RunInContainer(name, "usermod", "-aG", "docker,sudo", "app"); err != nil {
+	if err := manager.RunInContainer(ctx, name, "usermod", "-aG", "docker,sudo", "app"); err != nil {
 		return fmt.Errorf("failed to add 'app' user to docker and sudo groups: %w", err)
 	}
 
+	if supplied {
+		return nil
+	}
+
 	// Store password in container metadata for later retrieval
 	logger.Debug("Storing password in container metadata...")
 	if err := manager.StoreContainerPassword(name, password); err != nil {
@@ -149,14 +1279,63 @@ func createContainer(manager ContainerManager, name, image, size string) error {
 		// Don't fail the entire operation if password storage fails
 	}
 
-	// Restart container to ensure all settings take effect
-	logger.Info("Restarting container to apply all settings...")
-	if err := manager.RestartContainer(name); err != nil {
-		return fmt.Errorf("failed to restart container: %w", err)
+	return nil
+}
+
+// resolveStoragePool returns the explicitly requested pool, or the first Btrfs pool found
+func resolveStoragePool(manager ContainerManager, requested string) (string, error) {
+	if requested != "" {
+		logger.Info("Checking storage pool '%s'...", requested)
+		pool, err := manager.GetStoragePool(requested)
+		if err != nil {
+			return "", fmt.Errorf("failed to use storage pool '%s': %w", requested, err)
+		}
+		if pool.Driver != "btrfs" {
+			logger.Warn("Storage pool '%s' uses the '%s' driver; this tool is designed for Btrfs", pool.Name, pool.Driver)
+		}
+		logger.Info("Using storage pool: '%s'", pool.Name)
+		return pool.Name, nil
 	}
 
-	logger.Info("Container setup complete!")
-	return nil
+	// Get or create a Btrfs storage pool without changing system default
+	logger.Info("Checking for Btrfs storage pool...")
+	storagePool, err := manager.GetOrCreateBtrfsPool()
+	if err != nil {
+		return "", fmt.Errorf("failed to get or create Btrfs storage pool: %w", err)
+	}
+	logger.Info("Using Btrfs storage pool: '%s'", storagePool)
+	return storagePool, nil
+}
+
+// warnIfMetadataLow warns when the pool's Btrfs metadata is close to exhaustion.
+// Usage can only be read as root, so failures are not treated as errors.
+func warnIfMetadataLow(manager ContainerManager, pool string) {
+	usage, err := manager.GetBtrfsUsage(pool)
+	if err != nil {
+		logger.Debug("Could not check Btrfs metadata usage for pool '%s': %v", pool, err)
+		return
+	}
+	if usage.MetadataNearlyExhausted() {
+		logger.Warn("Btrfs metadata in storage pool '%s' is %.1f%% used; containers may fail with 'No space left on device'", pool, usage.MetadataPercent())
+		logger.Warn("Run 'lxc-go-cli storage maintain %s --balance' to reclaim space", pool)
+	}
+}
+
+// warnIfSubIDsMissing warns when root or the LXD daemon user lack the
+// subordinate ids Docker needs; the files may be unreadable, so failures are
+// not treated as errors
+func warnIfSubIDsMissing(manager ContainerManager) {
+	fixes, err := manager.PlanSubIDFixes()
+	if err != nil {
+		logger.Debug("Could not check subordinate id ranges: %v", err)
+		return
+	}
+	for _, fix := range fixes {
+		logger.Warn("%s", fix.Reason)
+	}
+	if len(fixes) > 0 {
+		logger.Warn("Run 'sudo lxc-go-cli doctor --fix-idmap' to add the missing ranges")
+	}
 }
 
 // createCmd represents the create command
@@ -165,11 +1344,178 @@ var createCmd = &cobra.Command{
 	Short: "Create an LXC container ready for Docker use",
 	Long: `Creates an LXC container, installs Docker and Docker Compose V2 from Docker's official repository, and sets up a non-root 'app' user with docker and sudo access.
 
+Each step (image, launch, security, apt-update, locale, docker-storage,
+docker-install, packages, app-user, restart) has its own timeout so a hung download or apt run fails with
+a clear error instead of blocking forever. Override them with --step-timeout
+and bound the whole run with --max-duration.
+
+The image step checks whether the image is already in the server's image
+store and, if not, downloads it with progress before the launch. See
+'lxc-go-cli images' to list and prune downloaded images.
+
+Names must be 1 to 63 letters, digits and hyphens, starting with a letter and
+not ending with a hyphen; invalid names are rejected before anything is
+created. Without --name, or with --name auto, a name such as brave-otter is
+generated that no existing container uses.
+
+A create that fails deletes the container it launched, so no half-provisioned
+container is left behind. --on-failure chooses otherwise: keep leaves it for
+debugging (as does --keep-partial), and snapshot saves it as an image named
+failed-<name>-<time> before deleting it, to be launched and inspected later
+(publishing may take up to 30 minutes). The default can be set as
+create.on_failure in the config file. Every failed create, including a failed
+--resume, is recorded in the audit log with what was done. Completed steps are recorded on a kept
+container, so the create can be continued with --resume rather than starting
+from scratch, as can an interrupted one.
+
+Independent steps run at the same time, up to --parallel of them: the
+security settings are applied while apt runs. --parallel 1 runs every step
+in order.
+
+When done, a summary with the address, the 'app' password and next steps is
+printed; --output json prints it as JSON for scripts (logs go to stderr).
+The time spent in each step is printed to stderr afterwards, also when
+create fails; --timings json prints it as one JSON line to track provisioning
+performance across versions, --timings none leaves it out.
+
+With --no-provision only the launch and security steps run; install Docker
+and the 'app' user later with 'lxc-go-cli provision <container>'.
+
+The container inherits the host's time zone and locale instead of UTC and
+POSIX; choose others with --timezone and --locale.
+
+Before launching, /etc/subuid and /etc/subgid are checked for ranges big
+enough for Docker; 'lxc-go-cli doctor --fix-idmap' adds missing ones.
+
+Docker's btrfs storage driver misbehaves in nested containers, so when
+/var/lib/docker is on Btrfs Docker is set up with fuse-overlayfs instead.
+--docker-volume-pool mounts a volume from a non-Btrfs pool (e.g. lvm, which
+formats it ext4) at /var/lib/docker and uses overlay2 on it;
+--docker-storage-driver picks the driver explicitly. The volume, named
+<name>-docker, is deleted with the container; create refuses to reuse one
+left behind.
+
+With --ephemeral the container is launched with 'lxc launch --ephemeral' for
+throwaway jobs such as CI: provisioning runs as usual, but LXD deletes the
+container as soon as it stops.
+
+With --vm a virtual machine is launched instead of a container, for
+workloads that need their own kernel. Commands run through the VM's agent,
+which create waits for after each start; the container security settings are
+skipped and Docker uses overlay2 on the VM's disk. 'gpu' passes a whole GPU
+through to a VM and 'port' forwards to it in NAT mode.
+
+On a cluster, --target launches on a specific member, which must be online;
+without it LXD places the container. 'cluster list' shows the members.
+
+The 'app' password is generated unless one is supplied, for setups where
+passwords are issued centrally (e.g. by a vault): --password-stdin reads it
+from stdin, or it is taken from $LXC_GO_CLI_APP_PASSWORD. A supplied password
+is not logged, stored in the container metadata or printed in the summary.
+
+Extra apt packages given with --package or listed in --packages-file (one or
+more per line, # starts a comment) are installed in one apt run after Docker.
+
+--preset sizes the container at launch with a named preset of CPUs, memory
+limit and root disk size: small, medium and large are built in, and the
+config file can change them or add more ('limits presets' lists them). An
+explicit --size replaces the preset's disk size.
+
+--ipv6 sets how the container gets IPv6 on its NIC, and an extra ipv6 step
+checks the result once it has launched: auto expects an address from the
+network by SLAAC or DHCPv6, disabled turns IPv6 off inside the container, and
+static:<address> reserves a fixed address, which needs DHCPv6 on the network
+(ipv6.dhcp.stateful=true). Without --ipv6 the network's defaults apply.
+
 Example:
-  lxc-go-cli create --name mycontainer --image ubuntu:24.04 --size 10G`,
+  lxc-go-cli create --name mycontainer --image ubuntu:24.04 --size 10G
+  lxc-go-cli create --name mycontainer --storage-pool fast
+  lxc-go-cli create --image ubuntu:22.04
+  lxc-go-cli create --name mycontainer --max-duration 30m --step-timeout docker-install=25m
+  lxc-go-cli create --name mycontainer --resume
+  lxc-go-cli create --name mycontainer --no-provision
+  lxc-go-cli create --name mycontainer --output json | jq -r .password
+  lxc-go-cli create --name mycontainer --timezone Europe/London --locale en_GB.UTF-8
+  lxc-go-cli create --name mycontainer --package git --package htop
+  lxc-go-cli create --name mycontainer --docker-volume-pool lvm
+  lxc-go-cli create --name ci-job --ephemeral
+  lxc-go-cli create --name myvm --vm
+  lxc-go-cli create --name mycontainer --target member3
+  lxc-go-cli create --name mycontainer --preset medium
+  lxc-go-cli create --name mycontainer --ipv6 static:fd42:1:2:3::10
+  vault kv get -field=password secret/web | lxc-go-cli create --name web --password-stdin`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		stepTimeouts, err := parseStepTimeouts(createStepTimeout)
+		if err != nil {
+			return err
+		}
+		packages, err := resolvePackages(createPackages, createPkgFile)
+		if err != nil {
+			return err
+		}
+		if createParallel < 1 {
+			return helpers.UsageErrorf("--parallel must be at least 1")
+		}
+		if err := validateTimingsFormat(createTimings); err != nil {
+			return err
+		}
+		settings, err := helpers.LoadSettings()
+		if err != nil {
+			return err
+		}
+		onFailure, err := resolveOnFailure(createOnFailure, cmd.Flags().Changed("on-failure"), settings)
+		if err != nil {
+			return err
+		}
+
+		appPassword, err := resolveAppPassword(createPasswordStdin, cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		size := storageSize
+		limits, err := resolveCreatePreset(createPreset, storageSize, cmd.Flags().Changed("size"))
+		if err != nil {
+			return err
+		}
+		ipv6, err := helpers.ParseIPv6Config(createIPv6)
+		if err != nil {
+			return err
+		}
+		if limits.Disk != "" {
+			size = limits.Disk
+		}
+
+		timings := newTimings("create", createTimings)
 		manager := &DefaultContainerManager{}
-		return createContainer(manager, containerName, imageName, storageSize)
+		err = createContainerWithOptions(manager, CreateOptions{
+			Name:          containerName,
+			Image:         imageName,
+			Size:          size,
+			StoragePool:   storagePool,
+			MaxDuration:   createMaxDuration,
+			StepTimeouts:  stepTimeouts,
+			Resume:        createResume,
+			OnFailure:     onFailure,
+			NoProvision:   createNoProvision,
+			Output:        createOutput,
+			Out:           cmd.OutOrStdout(),
+			Locale:        LocaleOptions{Timezone: createTimezone, Locale: createLocale},
+			HostLocale:    hostLocaleOptions(),
+			Packages:      packages,
+			DockerStorage: createDockerStore,
+			Ephemeral:     createEphemeral,
+			VM:            createVM,
+			Target:        createTarget,
+			Parallel:      createParallel,
+			AppPassword:   appPassword,
+			Timings:       timings,
+			Preset:        createPreset,
+			IPv6:          ipv6,
+			Limits:        limits,
+		})
+		// Also after a failure, to show where the time went
+		printTimings(cmd.ErrOrStderr(), timings, createTimings)
+		return err
 	},
 }
 
@@ -184,8 +1530,28 @@ func init() {
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
-	createCmd.Flags().StringVarP(&containerName, "name", "n", "", "Container name (required)")
+	createCmd.Flags().StringVarP(&containerName, "name", "n", helpers.AutoContainerName, "Container name; 'auto' generates one such as brave-otter")
 	createCmd.Flags().StringVarP(&imageName, "image", "i", "ubuntu:24.04", "Container image (default: ubuntu:24.04)")
 	createCmd.Flags().StringVarP(&storageSize, "size", "s", "10G", "Storage size (default: 10G)")
-	createCmd.MarkFlagRequired("name")
+	createCmd.Flags().StringVar(&storagePool, "storage-pool", "", "Storage pool to use (default: first Btrfs pool found, created if missing)")
+	createCmd.Flags().DurationVar(&createMaxDuration, "max-duration", 0, "Total time budget for create (default: no limit)")
+	createCmd.Flags().StringToStringVar(&createStepTimeout, "step-timeout", nil, "Per-step timeout override, e.g. docker-install=30m (repeatable)")
+	createCmd.Flags().BoolVar(&createResume, "resume", false, "Continue an interrupted create, skipping completed steps")
+	createCmd.Flags().StringVar(&createOnFailure, "on-failure", OnFailureDestroy, "What to do with the container if a step fails: keep, destroy, or snapshot (save it as an image, then destroy it)")
+	createCmd.Flags().BoolVar(&createNoProvision, "no-provision", false, "Only launch and secure the container; run 'provision' later")
+	createCmd.Flags().StringVarP(&createOutput, "output", "o", "text", "Summary format (text, json)")
+	createCmd.Flags().StringVar(&createTimezone, "timezone", "", "Container time zone, e.g. Europe/London (default: the host's)")
+	createCmd.Flags().StringVar(&createLocale, "locale", "", "Container locale, e.g. en_GB.UTF-8 (default: the host's)")
+	createCmd.Flags().StringArrayVar(&createPackages, "package", nil, "Extra apt package to install after Docker (repeatable)")
+	createCmd.Flags().StringVar(&createPkgFile, "packages-file", "", "File listing extra apt packages to install after Docker")
+	createCmd.Flags().StringVar(&createDockerStore.Driver, "docker-storage-driver", helpers.DockerStorageAuto, "Docker storage driver ("+strings.Join(helpers.DockerStorageDrivers, ", ")+")")
+	createCmd.Flags().StringVar(&createDockerStore.VolumePool, "docker-volume-pool", "", "Storage pool for a volume mounted at /var/lib/docker (must not be Btrfs)")
+	createCmd.Flags().BoolVar(&createEphemeral, "ephemeral", false, "Launch an ephemeral container that is deleted when it stops")
+	createCmd.Flags().BoolVar(&createVM, "vm", false, "Launch a virtual machine instead of a container")
+	createCmd.Flags().StringVar(&createTarget, "target", "", "Cluster member to launch on (default: chosen by LXD)")
+	createCmd.Flags().IntVar(&createParallel, "parallel", defaultStepParallel, "Maximum number of independent steps to run at once")
+	createCmd.Flags().StringVar(&createTimings, "timings", TimingsText, "Time spent per step, printed to stderr at the end: text, json or none")
+	createCmd.Flags().StringVar(&createPreset, "preset", "", "Size preset setting CPU, memory and disk, e.g. small, medium or large (see 'limits presets')")
+	createCmd.Flags().StringVar(&createIPv6, "ipv6", "", "IPv6 for the container's NIC: auto, disabled or static:<address> (default: the network's)")
+	createCmd.Flags().BoolVar(&createPasswordStdin, "password-stdin", false, "Read the 'app' user password from stdin instead of generating one")
 }
@@ -2,21 +2,58 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
 )
 
 // MockContainerManager for testing
 type MockContainerManager struct {
-	GetOrCreateBtrfsPoolFunc       func() (string, error)
-	ContainerExistsFunc            func(name string) bool
-	CreateContainerFunc            func(name, distro, release, arch, storagePool string) error
+	GetOrCreateBtrfsPoolFunc     func() (string, error)
+	GetStoragePoolFunc           func(name string) (*helpers.StoragePool, error)
+	GetBtrfsUsageFunc            func(pool string) (*helpers.BtrfsUsage, error)
+	ContainerExistsFunc          func(name string) bool
+	CreateContainerFunc          func(name, distro, release, arch, storagePool string) error
+	CreateEphemeralContainerFunc func(name, distro, release, arch, storagePool string) error
+	CreateVirtualMachineFunc     func(name, distro, release, arch, storagePool string) error
+	IsClusteredFunc              func(ctx context.Context) (bool, error)
+	ListClusterMembersFunc       func(ctx context.Context) ([]helpers.ClusterMember, error)
+	// Launched records the options of every launch
+	Launched                       []helpers.LaunchOptions
+	GetInstanceTypeFunc            func(name string) (string, error)
+	WaitForAgentFunc               func(ctx context.Context, name string) error
 	ConfigureContainerSecurityFunc func(containerName string) error
-	RunInContainerFunc             func(containerName string, args ...string) error
-	RestartContainerFunc           func(name string) error
+	RunInContainerFunc             func(ctx context.Context, containerName string, args ...string) error
+	RestartContainerFunc           func(ctx context.Context, name string) error
 	StoreContainerPasswordFunc     func(containerName, password string) error
 	SetUserPasswordFunc            func(containerName, username, password string) error
+	SetConfigValueFunc             func(containerName, key, value string) error
+	GetConfigValueFunc             func(containerName, key string) (string, error)
+	StartContainerFunc             func(name string) error
+	GetContainerPasswordFunc       func(containerName string) (string, error)
+	GetContainerIPv4Func           func(name string) (string, error)
+	GetContainerIPv6Func           func(name string) ([]string, error)
+	DisableIPv6Func                func(ctx context.Context, name string) error
+	PlanSubIDFixesFunc             func() ([]helpers.IdmapFix, error)
+	AttachDockerVolumeFunc         func(containerName, pool string) error
+	FindLocalImageFunc             func(ctx context.Context, image string) (*helpers.LocalImage, error)
+	DownloadImageFunc              func(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error)
+	NotifyFunc                     func(event helpers.NotificationEvent)
+	PublishSnapshotFunc            func(ctx context.Context, containerName, snapshotName, alias string) error
+	// Deleted records the containers deleted, e.g. by a failed create
+	Deleted     []string
+	Snapshots   []string
+	Published   []string
+	AuditEvents []helpers.AuditEvent
 }
 
 func (m *MockContainerManager) GetOrCreateBtrfsPool() (string, error) {
@@ -26,6 +63,20 @@ func (m *MockContainerManager) GetOrCreateBtrfsPool() (string, error) {
 	return "", fmt.Errorf("GetOrCreateBtrfsPool not mocked")
 }
 
+func (m *MockContainerManager) GetStoragePool(name string) (*helpers.StoragePool, error) {
+	if m.GetStoragePoolFunc != nil {
+		return m.GetStoragePoolFunc(name)
+	}
+	return nil, fmt.Errorf("storage pool '%s' does not exist", name)
+}
+
+func (m *MockContainerManager) GetBtrfsUsage(pool string) (*helpers.BtrfsUsage, error) {
+	if m.GetBtrfsUsageFunc != nil {
+		return m.GetBtrfsUsageFunc(pool)
+	}
+	return nil, fmt.Errorf("GetBtrfsUsage not mocked")
+}
+
 func (m *MockContainerManager) ContainerExists(name string) bool {
 	if m.ContainerExistsFunc != nil {
 		return m.ContainerExistsFunc(name)
@@ -33,13 +84,80 @@ func (m *MockContainerManager) ContainerExists(name string) bool {
 	return false
 }
 
-func (m *MockContainerManager) CreateContainer(name, distro, release, arch, storagePool string) error {
+func (m *MockContainerManager) DeleteContainer(name string) error {
+	m.Deleted = append(m.Deleted, name)
+	return nil
+}
+
+func (m *MockContainerManager) CreateSnapshot(containerName, snapshotName string) error {
+	m.Snapshots = append(m.Snapshots, containerName+"/"+snapshotName)
+	return nil
+}
+
+func (m *MockContainerManager) PublishSnapshot(ctx context.Context, containerName, snapshotName, alias string) error {
+	if m.PublishSnapshotFunc != nil {
+		if err := m.PublishSnapshotFunc(ctx, containerName, snapshotName, alias); err != nil {
+			return err
+		}
+	}
+	m.Published = append(m.Published, alias)
+	return nil
+}
+
+func (m *MockContainerManager) RecordAuditEvent(event helpers.AuditEvent) error {
+	m.AuditEvents = append(m.AuditEvents, event)
+	return nil
+}
+
+// LaunchInstance dispatches to the create function for the kind of instance
+func (m *MockContainerManager) LaunchInstance(name, distro, release, arch, storagePool string, opts helpers.LaunchOptions) error {
+	m.Launched = append(m.Launched, opts)
+	switch {
+	case opts.Ephemeral:
+		if m.CreateEphemeralContainerFunc != nil {
+			return m.CreateEphemeralContainerFunc(name, distro, release, arch, storagePool)
+		}
+		return fmt.Errorf("CreateEphemeralContainer not mocked")
+	case opts.VM:
+		if m.CreateVirtualMachineFunc != nil {
+			return m.CreateVirtualMachineFunc(name, distro, release, arch, storagePool)
+		}
+		return fmt.Errorf("CreateVirtualMachine not mocked")
+	}
 	if m.CreateContainerFunc != nil {
 		return m.CreateContainerFunc(name, distro, release, arch, storagePool)
 	}
 	return fmt.Errorf("CreateContainer not mocked")
 }
 
+func (m *MockContainerManager) IsClustered(ctx context.Context) (bool, error) {
+	if m.IsClusteredFunc != nil {
+		return m.IsClusteredFunc(ctx)
+	}
+	return false, nil
+}
+
+func (m *MockContainerManager) ListClusterMembers(ctx context.Context) ([]helpers.ClusterMember, error) {
+	if m.ListClusterMembersFunc != nil {
+		return m.ListClusterMembersFunc(ctx)
+	}
+	return nil, fmt.Errorf("ListClusterMembers not mocked")
+}
+
+func (m *MockContainerManager) GetInstanceType(name string) (string, error) {
+	if m.GetInstanceTypeFunc != nil {
+		return m.GetInstanceTypeFunc(name)
+	}
+	return helpers.InstanceTypeContainer, nil
+}
+
+func (m *MockContainerManager) WaitForAgent(ctx context.Context, name string) error {
+	if m.WaitForAgentFunc != nil {
+		return m.WaitForAgentFunc(ctx, name)
+	}
+	return nil
+}
+
 func (m *MockContainerManager) ConfigureContainerSecurity(containerName string) error {
 	if m.ConfigureContainerSecurityFunc != nil {
 		return m.ConfigureContainerSecurityFunc(containerName)
@@ -47,16 +165,16 @@ func (m *MockContainerManager) ConfigureContainerSecurity(containerName string)
 	return fmt.Errorf("ConfigureContainerSecurity not mocked")
 }
 
-func (m *MockContainerManager) RunInContainer(containerName string, args ...string) error {
+func (m *MockContainerManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
 	if m.RunInContainerFunc != nil {
-		return m.RunInContainerFunc(containerName, args...)
+		return m.RunInContainerFunc(ctx, containerName, args...)
 	}
 	return fmt.Errorf("RunInContainer not mocked")
 }
 
-func (m *MockContainerManager) RestartContainer(name string) error {
+func (m *MockContainerManager) RestartContainer(ctx context.Context, name string) error {
 	if m.RestartContainerFunc != nil {
-		return m.RestartContainerFunc(name)
+		return m.RestartContainerFunc(ctx, name)
 	}
 	return fmt.Errorf("RestartContainer not mocked")
 }
@@ -75,6 +193,90 @@ func (m *MockContainerManager) SetUserPassword(containerName, username, password
 	return nil // Default to success for password setting
 }
 
+func (m *MockContainerManager) SetConfigValue(containerName, key, value string) error {
+	if m.SetConfigValueFunc != nil {
+		return m.SetConfigValueFunc(containerName, key, value)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) GetConfigValue(containerName, key string) (string, error) {
+	if m.GetConfigValueFunc != nil {
+		return m.GetConfigValueFunc(containerName, key)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) StartContainer(name string) error {
+	if m.StartContainerFunc != nil {
+		return m.StartContainerFunc(name)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) GetContainerPassword(containerName string) (string, error) {
+	if m.GetContainerPasswordFunc != nil {
+		return m.GetContainerPasswordFunc(containerName)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) GetContainerIPv4(name string) (string, error) {
+	if m.GetContainerIPv4Func != nil {
+		return m.GetContainerIPv4Func(name)
+	}
+	return "", nil
+}
+
+func (m *MockContainerManager) GetContainerIPv6Addresses(name string) ([]string, error) {
+	if m.GetContainerIPv6Func != nil {
+		return m.GetContainerIPv6Func(name)
+	}
+	return nil, nil
+}
+
+func (m *MockContainerManager) DisableIPv6(ctx context.Context, name string) error {
+	if m.DisableIPv6Func != nil {
+		return m.DisableIPv6Func(ctx, name)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) PlanSubIDFixes() ([]helpers.IdmapFix, error) {
+	if m.PlanSubIDFixesFunc != nil {
+		return m.PlanSubIDFixesFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockContainerManager) AttachDockerVolume(containerName, pool string) error {
+	if m.AttachDockerVolumeFunc != nil {
+		return m.AttachDockerVolumeFunc(containerName, pool)
+	}
+	return nil
+}
+
+func (m *MockContainerManager) FindLocalImage(ctx context.Context, image string) (*helpers.LocalImage, error) {
+	if m.FindLocalImageFunc != nil {
+		return m.FindLocalImageFunc(ctx, image)
+	}
+	// Default to an image that is already cached
+	return &helpers.LocalImage{Fingerprint: "a0b1c2d3e4f5a6b7"}, nil
+}
+
+func (m *MockContainerManager) DownloadImage(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error) {
+	if m.DownloadImageFunc != nil {
+		return m.DownloadImageFunc(ctx, image, onProgress)
+	}
+	return "", fmt.Errorf("DownloadImage not mocked")
+}
+
+func (m *MockContainerManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	if m.NotifyFunc != nil {
+		m.NotifyFunc(event)
+	}
+}
+
 func TestCreateCommand(t *testing.T) {
 	// Test create command creation
 	if createCmd == nil {
@@ -141,6 +343,16 @@ func TestCreateContainerValidation(t *testing.T) {
 			containerName: "",
 			expectedError: "container name is required",
 		},
+		{
+			name:          "invalid character",
+			containerName: "my_app",
+			expectedError: "contains '_'; use only letters, digits and '-' (try 'my-app')",
+		},
+		{
+			name:          "leading digit",
+			containerName: "1web",
+			expectedError: "must not start with a digit",
+		},
 		{
 			name:          "valid container name",
 			containerName: "test-container",
@@ -163,10 +375,10 @@ func TestCreateContainerValidation(t *testing.T) {
 				ConfigureContainerSecurityFunc: func(containerName string) error {
 					return nil
 				},
-				RunInContainerFunc: func(containerName string, args ...string) error {
+				RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 					return nil
 				},
-				RestartContainerFunc: func(name string) error {
+				RestartContainerFunc: func(ctx context.Context, name string) error {
 					return nil
 				},
 			}
@@ -199,10 +411,10 @@ func TestCreateContainerDefaultValues(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			return nil
 		},
-		RestartContainerFunc: func(name string) error {
+		RestartContainerFunc: func(ctx context.Context, name string) error {
 			return nil
 		},
 	}
@@ -310,7 +522,7 @@ func TestCreateContainerPackageUpdateError(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			if len(args) > 0 && args[0] == "apt-get" && len(args) > 1 && args[1] == "update" {
 				return fmt.Errorf("package update failed")
 			}
@@ -341,7 +553,7 @@ func TestCreateContainerDockerInstallError(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			if len(args) > 0 && args[0] == "apt-get" && len(args) > 1 && args[1] == "install" {
 				return fmt.Errorf("docker installation failed")
 			}
@@ -372,7 +584,7 @@ func TestCreateContainerUserCreationError(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			if len(args) > 0 && args[0] == "useradd" {
 				return fmt.Errorf("user creation failed")
 			}
@@ -403,7 +615,7 @@ func TestCreateContainerUserGroupError(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			if len(args) > 0 && args[0] == "usermod" {
 				return fmt.Errorf("user group modification failed")
 			}
@@ -434,13 +646,15 @@ func TestCreateContainerRestartError(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			return nil
 		},
-		RestartContainerFunc: func(name string) error {
+		RestartContainerFunc: func(ctx context.Context, name string) error {
 			return fmt.Errorf("restart failed")
 		},
 	}
+	var events []helpers.NotificationEvent
+	manager.NotifyFunc = func(event helpers.NotificationEvent) { events = append(events, event) }
 
 	err := createContainer(manager, "test-container", "ubuntu:24.04", "10G")
 	if err == nil {
@@ -449,6 +663,128 @@ func TestCreateContainerRestartError(t *testing.T) {
 	if !contains(err.Error(), "failed to restart container") {
 		t.Errorf("expected error about container restart, got '%s'", err.Error())
 	}
+	if len(events) != 1 || events[0].Event != helpers.EventProvisionFailed || !contains(events[0].Error, "failed to restart container") {
+		t.Errorf("expected a provision-failed notification, got %+v", events)
+	}
+	if len(manager.Deleted) != 1 || manager.Deleted[0] != "test-container" {
+		t.Errorf("expected the half-provisioned container to be deleted, got %v", manager.Deleted)
+	}
+
+	// --keep-partial keeps it for a later --resume
+	manager.Deleted = nil
+	keepPartial = true
+	defer func() { keepPartial = false }()
+	if err := createContainer(manager, "test-container", "ubuntu:24.04", "10G"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(manager.Deleted) != 0 {
+		t.Errorf("expected the container to be kept, got %v", manager.Deleted)
+	}
+}
+
+func TestCreateContainerOnFailure(t *testing.T) {
+	newFailingManager := func() *MockContainerManager {
+		return &MockContainerManager{
+			GetOrCreateBtrfsPoolFunc:       func() (string, error) { return "test-pool", nil },
+			CreateContainerFunc:            func(name, distro, release, arch, storagePool string) error { return nil },
+			ConfigureContainerSecurityFunc: func(containerName string) error { return nil },
+			RunInContainerFunc:             func(ctx context.Context, containerName string, args ...string) error { return nil },
+			RestartContainerFunc:           func(ctx context.Context, name string) error { return fmt.Errorf("restart failed") },
+		}
+	}
+
+	tests := []struct {
+		policy      string
+		publishErr  error
+		deleted     bool
+		published   bool
+		auditReason string
+	}{
+		{OnFailureKeep, nil, false, false, "kept for debugging (on-failure keep)"},
+		{OnFailureDestroy, nil, true, false, "destroyed (on-failure destroy)"},
+		{OnFailureSnapshot, nil, true, true, "saved as image 'failed-web-"},
+		{OnFailureSnapshot, fmt.Errorf("publish failed"), false, false, "kept after failing to discard it (on-failure snapshot)"},
+	}
+	for _, tt := range tests {
+		manager := newFailingManager()
+		manager.PublishSnapshotFunc = func(ctx context.Context, containerName, snapshotName, alias string) error {
+			// Publishing outlives the undo timeout
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < undoTimeout {
+				t.Errorf("%s: expected publishing to get longer than the undo timeout, got %v", tt.policy, deadline)
+			}
+			return tt.publishErr
+		}
+
+		err := createContainerWithOptions(manager, CreateOptions{Name: "web", OnFailure: tt.policy})
+		if err == nil || !contains(err.Error(), "failed to restart container") {
+			t.Fatalf("%s: expected the restart error, got %v", tt.policy, err)
+		}
+		if deleted := len(manager.Deleted) == 1; deleted != tt.deleted {
+			t.Errorf("%s: expected deleted %v, got %v", tt.policy, tt.deleted, manager.Deleted)
+		}
+		if published := len(manager.Published) == 1; published != tt.published {
+			t.Errorf("%s: expected published %v, got %v", tt.policy, tt.published, manager.Published)
+		}
+		if tt.published && (len(manager.Snapshots) != 1 || !strings.HasPrefix(manager.Snapshots[0], "web/failed-")) {
+			t.Errorf("%s: expected a snapshot before publishing, got %v", tt.policy, manager.Snapshots)
+		}
+		if len(manager.AuditEvents) != 1 {
+			t.Fatalf("%s: expected one audit event, got %+v", tt.policy, manager.AuditEvents)
+		}
+		event := manager.AuditEvents[0]
+		if event.Action != auditActionCreateFailed || event.Container != "web" || !strings.HasPrefix(event.Reason, tt.auditReason) || !contains(event.Error, "failed to restart container") {
+			t.Errorf("%s: unexpected audit event %+v", tt.policy, event)
+		}
+	}
+}
+
+func TestResolveOnFailure(t *testing.T) {
+	settings := &helpers.Settings{Create: helpers.CreateSettings{OnFailure: OnFailureSnapshot}}
+
+	tests := []struct {
+		flag     string
+		flagSet  bool
+		keep     bool
+		settings *helpers.Settings
+		expected string
+	}{
+		{OnFailureDestroy, false, false, nil, OnFailureDestroy},
+		{OnFailureDestroy, false, false, settings, OnFailureSnapshot},
+		{OnFailureDestroy, false, true, settings, OnFailureKeep},
+		{OnFailureDestroy, true, true, settings, OnFailureDestroy},
+	}
+	for _, tt := range tests {
+		keepPartial = tt.keep
+		policy, err := resolveOnFailure(tt.flag, tt.flagSet, tt.settings)
+		if err != nil || policy != tt.expected {
+			t.Errorf("%+v: expected %s, got %s (%v)", tt, tt.expected, policy, err)
+		}
+	}
+	keepPartial = false
+
+	_, err := resolveOnFailure("archive", true, nil)
+	if !errors.Is(err, helpers.ErrUsage) {
+		t.Errorf("expected a usage error for an unknown policy, got %v", err)
+	}
+}
+
+func TestCreateContainerResumeFailureKeepsContainer(t *testing.T) {
+	var commands []string
+	manager := newResumeManager("launch,security", &commands, map[string]string{})
+	manager.RestartContainerFunc = func(ctx context.Context, name string) error {
+		return fmt.Errorf("restart failed")
+	}
+
+	if err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", Resume: true}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(manager.Deleted) != 0 {
+		t.Errorf("a container launched by an earlier run should be kept, got %v", manager.Deleted)
+	}
+	if len(manager.AuditEvents) != 1 || manager.AuditEvents[0].Action != auditActionCreateFailed ||
+		!strings.HasPrefix(manager.AuditEvents[0].Reason, "kept for another --resume") {
+		t.Errorf("expected the failed resume in the audit log, got %+v", manager.AuditEvents)
+	}
 }
 
 func TestCreateContainerSuccess(t *testing.T) {
@@ -465,18 +801,733 @@ func TestCreateContainerSuccess(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			return nil
 		},
-		RestartContainerFunc: func(name string) error {
+		RestartContainerFunc: func(ctx context.Context, name string) error {
 			return nil
 		},
 	}
+	var events []helpers.NotificationEvent
+	manager.NotifyFunc = func(event helpers.NotificationEvent) { events = append(events, event) }
 
 	err := createContainer(manager, "test-container", "ubuntu:24.04", "10G")
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
+	if len(events) != 1 || events[0].Event != helpers.EventCreateComplete || events[0].Container != "test-container" {
+		t.Errorf("expected a create-complete notification, got %+v", events)
+	}
+}
+
+func TestCreateContainerSetsManagedMarker(t *testing.T) {
+	config := make(map[string]string)
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) {
+			return "test-pool", nil
+		},
+		CreateContainerFunc: func(name, distro, release, arch, storagePool string) error {
+			return nil
+		},
+		ConfigureContainerSecurityFunc: func(containerName string) error {
+			return nil
+		},
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
+			return nil
+		},
+		RestartContainerFunc: func(ctx context.Context, name string) error {
+			return nil
+		},
+		SetConfigValueFunc: func(containerName, key, value string) error {
+			config[key] = value
+			return nil
+		},
+	}
+
+	if err := createContainer(manager, "test-container", "ubuntu:24.04", "10G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if config[helpers.ManagedMarkerKey] != "true" {
+		t.Error("expected managed marker to be set")
+	}
+	if config[helpers.ManagedVersionKey] != version {
+		t.Errorf("expected version '%s', got '%s'", version, config[helpers.ManagedVersionKey])
+	}
+	if _, err := time.Parse(time.RFC3339, config[helpers.ManagedCreatedKey]); err != nil {
+		t.Errorf("expected RFC 3339 creation timestamp, got '%s'", config[helpers.ManagedCreatedKey])
+	}
+}
+
+func TestCreateContainerStepTimeout(t *testing.T) {
+	stopped := make(chan error, 1)
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) {
+			return "test-pool", nil
+		},
+		CreateContainerFunc: func(name, distro, release, arch, storagePool string) error {
+			return nil
+		},
+		ConfigureContainerSecurityFunc: func(containerName string) error {
+			return nil
+		},
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
+			if args[0] == "apt-get" && args[1] == "update" {
+				<-ctx.Done()
+				stopped <- ctx.Err()
+				return ctx.Err()
+			}
+			return nil
+		},
+	}
+
+	err := createContainerWithOptions(manager, CreateOptions{
+		Name:         "test-container",
+		StepTimeouts: map[string]time.Duration{stepAptUpdate: 20 * time.Millisecond},
+	})
+	if err == nil || !contains(err.Error(), "step 'apt-update' timed out") {
+		t.Errorf("expected apt-update timeout, got %v", err)
+	}
+
+	// The command must be cancelled, not left running once the CLI gives up
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the command's context to time out, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the timed-out command to be cancelled")
+	}
+}
+
+// newResumeManager returns a mock for an existing container with the given completed steps
+func newResumeManager(completed string, commands *[]string, config map[string]string) *MockContainerManager {
+	config[helpers.ProvisionStepsKey] = completed
+	return &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) {
+			return "test-pool", nil
+		},
+		ContainerExistsFunc: func(name string) bool {
+			return true
+		},
+		CreateContainerFunc: func(name, distro, release, arch, storagePool string) error {
+			return fmt.Errorf("container should not be launched again")
+		},
+		ConfigureContainerSecurityFunc: func(containerName string) error {
+			*commands = append(*commands, "configure-security")
+			return nil
+		},
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
+			*commands = append(*commands, strings.Join(args, " "))
+			return nil
+		},
+		RestartContainerFunc: func(ctx context.Context, name string) error {
+			*commands = append(*commands, "restart")
+			return nil
+		},
+		GetConfigValueFunc: func(containerName, key string) (string, error) {
+			return config[key], nil
+		},
+		SetConfigValueFunc: func(containerName, key, value string) error {
+			config[key] = value
+			return nil
+		},
+	}
+}
+
+func TestCreateContainerResume(t *testing.T) {
+	var commands []string
+	config := map[string]string{}
+	manager := newResumeManager("launch,security,apt-update", &commands, config)
+
+	err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", Resume: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	joined := strings.Join(commands, "\n")
+	if contains(joined, "configure-security") || commands[0] == "apt-get update" {
+		t.Errorf("completed steps should be skipped, got:\n%s", joined)
+	}
+	if !contains(joined, "docker-compose-plugin") || !contains(joined, "restart") {
+		t.Errorf("remaining steps should run, got:\n%s", joined)
+	}
+	// The app user already exists, so it is configured rather than created
+	if contains(joined, "useradd") {
+		t.Errorf("existing app user should not be created again, got:\n%s", joined)
+	}
+	if config[helpers.ProvisionStepsKey] != strings.Join(createStepNames(), ",") {
+		t.Errorf("expected all steps to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+}
+
+func TestCreateContainerResumeErrors(t *testing.T) {
+	var commands []string
+
+	manager := newResumeManager("", &commands, map[string]string{})
+	err := createContainerWithOptions(manager, CreateOptions{Name: "test-container"})
+	if err == nil || !contains(err.Error(), "use --resume") {
+		t.Errorf("expected hint to use --resume, got %v", err)
+	}
+
+	err = createContainerWithOptions(manager, CreateOptions{Name: "test-container", Resume: true})
+	if err == nil || !contains(err.Error(), "no provisioning state") {
+		t.Errorf("expected missing state error, got %v", err)
+	}
+
+	manager = newResumeManager(strings.Join(createStepNames(), ","), &commands, map[string]string{})
+	if err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", Resume: true}); err != nil {
+		t.Errorf("expected fully provisioned container to succeed, got %v", err)
+	}
+	if len(commands) != 0 {
+		t.Errorf("nothing should run for a fully provisioned container, got %v", commands)
+	}
+}
+
+func TestCreateContainerRecordsSteps(t *testing.T) {
+	config := make(map[string]string)
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) {
+			return "test-pool", nil
+		},
+		CreateContainerFunc: func(name, distro, release, arch, storagePool string) error {
+			return nil
+		},
+		ConfigureContainerSecurityFunc: func(containerName string) error {
+			return nil
+		},
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
+			if args[0] == "useradd" {
+				return fmt.Errorf("useradd failed")
+			}
+			return nil
+		},
+		SetConfigValueFunc: func(containerName, key, value string) error {
+			config[key] = value
+			return nil
+		},
+	}
+
+	if err := createContainer(manager, "test-container", "ubuntu:24.04", "10G"); err == nil {
+		t.Fatal("expected app user step to fail")
+	}
+	if config[helpers.ProvisionStepsKey] != "launch,security,apt-update,locale,docker-storage,docker-install,packages" {
+		t.Errorf("expected steps up to docker-install to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+}
+
+func TestCreateContainerNoProvision(t *testing.T) {
+	var commands []string
+	config := make(map[string]string)
+	manager := newResumeManager("", &commands, config)
+	manager.ContainerExistsFunc = func(name string) bool { return false }
+	manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error {
+		commands = append(commands, "launch")
+		return nil
+	}
+
+	err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", NoProvision: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(commands, ",") != "launch,configure-security" {
+		t.Errorf("expected only launch and security, got %v", commands)
+	}
+	if config[helpers.ProvisionStepsKey] != "launch,security" {
+		t.Errorf("expected launch steps to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+}
+
+func TestCreateContainerLocale(t *testing.T) {
+	var commands []string
+	config := make(map[string]string)
+	manager := newResumeManager("", &commands, config)
+	manager.ContainerExistsFunc = func(name string) bool { return false }
+	manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error { return nil }
+
+	err := createContainerWithOptions(manager, CreateOptions{
+		Name:       "test-container",
+		Locale:     LocaleOptions{Timezone: "Europe/London"},
+		HostLocale: LocaleOptions{Timezone: "America/New_York", Locale: "en_US.UTF-8"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The flag wins over the host's time zone; the host's locale is inherited
+	timezone := strings.Join(helpers.TimezoneCommand("Europe/London"), " ")
+	locale := strings.Join(helpers.LocaleCommand("en_US.UTF-8"), " ")
+	if len(commands) < 4 || commands[1] != "apt-get update" || commands[2] != timezone || commands[3] != locale {
+		t.Errorf("expected time zone and locale after apt-get update, got:\n%s", strings.Join(commands, "\n"))
+	}
+
+	commands = nil
+	err = createContainerWithOptions(manager, CreateOptions{Name: "test-container", Locale: LocaleOptions{Timezone: "Europe/London; rm -rf /"}})
+	if err == nil || !contains(err.Error(), "invalid time zone") {
+		t.Errorf("expected invalid time zone error, got %v", err)
+	}
+	if len(commands) != 0 {
+		t.Errorf("nothing should run with an invalid time zone, got %v", commands)
+	}
+
+	manager.RunInContainerFunc = func(ctx context.Context, containerName string, args ...string) error {
+		if args[len(args)-1] == "en_GB.UTF-8" {
+			return fmt.Errorf("exit status 1")
+		}
+		return nil
+	}
+	err = createContainerWithOptions(manager, CreateOptions{Name: "test-container", Locale: LocaleOptions{Locale: "en_GB.UTF-8"}})
+	if err == nil || !contains(err.Error(), "failed to set locale 'en_GB.UTF-8'") {
+		t.Errorf("expected locale error, got %v", err)
+	}
+}
+
+func TestCreateContainerPackages(t *testing.T) {
+	var commands []string
+	manager := newResumeManager("", &commands, map[string]string{})
+	manager.ContainerExistsFunc = func(name string) bool { return false }
+	manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error { return nil }
+
+	err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", Packages: []string{"git", "htop"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	install := "env DEBIAN_FRONTEND=noninteractive apt-get install -y git htop"
+	docker, packages := -1, -1
+	for i, command := range commands {
+		if contains(command, "docker-compose-plugin") {
+			docker = i
+		}
+		if command == install {
+			packages = i
+		}
+	}
+	if docker < 0 || packages < docker {
+		t.Errorf("expected packages to be installed once after Docker, got:\n%s", strings.Join(commands, "\n"))
+	}
+
+	err = createContainerWithOptions(manager, CreateOptions{Name: "test-container", Packages: []string{"git; reboot"}})
+	if err == nil || !contains(err.Error(), "invalid package name") {
+		t.Errorf("expected invalid package error, got %v", err)
+	}
+}
+
+func TestResolvePackages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packages.txt")
+	if err := os.WriteFile(path, []byte("# tools\njq curl\n\ngit # again\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	packages, err := resolvePackages([]string{"git", "htop"}, path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(packages, " ") != "git htop jq curl" {
+		t.Errorf("unexpected packages: %v", packages)
+	}
+
+	if _, err := resolvePackages(nil, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestCreateContainerSuppliedPassword(t *testing.T) {
+	var commands []string
+	manager := newResumeManager("", &commands, map[string]string{})
+	manager.ContainerExistsFunc = func(name string) bool { return false }
+	manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error { return nil }
+	var setPassword string
+	manager.SetUserPasswordFunc = func(containerName, username, password string) error {
+		setPassword = password
+		return nil
+	}
+	stored := false
+	manager.StoreContainerPasswordFunc = func(containerName, password string) error {
+		stored = true
+		return nil
+	}
+
+	var out bytes.Buffer
+	err := createContainerWithOptions(manager, CreateOptions{Name: "web", AppPassword: "from-vault", Output: "json", Out: &out})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if setPassword != "from-vault" {
+		t.Errorf("expected the supplied password to be set, got %q", setPassword)
+	}
+	if stored {
+		t.Error("expected a supplied password not to be stored in the metadata")
+	}
+	var summary CreateSummary
+	json.Unmarshal(out.Bytes(), &summary)
+	if summary.Password != "" {
+		t.Errorf("expected no password in the summary, got %q", summary.Password)
+	}
+}
+
+func TestResolveAppPassword(t *testing.T) {
+	t.Setenv(helpers.AppPasswordEnvVar, "from-env")
+
+	password, err := resolveAppPassword(true, strings.NewReader("from-stdin\n"))
+	if err != nil || password != "from-stdin" {
+		t.Errorf("expected the stdin password, got %q, %v", password, err)
+	}
+	password, err = resolveAppPassword(false, strings.NewReader("ignored"))
+	if err != nil || password != "from-env" {
+		t.Errorf("expected the env password, got %q, %v", password, err)
+	}
+
+	if _, err := resolveAppPassword(true, strings.NewReader("\n")); err == nil || !contains(err.Error(), "no password on stdin") {
+		t.Errorf("expected empty stdin error, got %v", err)
+	}
+	if _, err := resolveAppPassword(true, strings.NewReader("two\nlines\n")); err == nil || !contains(err.Error(), "newline") {
+		t.Errorf("expected newline error, got %v", err)
+	}
+	if _, err := resolveAppPassword(true, strings.NewReader(strings.Repeat("x", maxAppPassword+1))); err == nil {
+		t.Error("expected error for an overlong password")
+	}
+
+	t.Setenv(helpers.AppPasswordEnvVar, "")
+	if _, err := resolveAppPassword(false, nil); err == nil || !contains(err.Error(), helpers.AppPasswordEnvVar) {
+		t.Errorf("expected empty env error, got %v", err)
+	}
+	os.Unsetenv(helpers.AppPasswordEnvVar)
+	if password, err := resolveAppPassword(false, nil); err != nil || password != "" {
+		t.Errorf("expected a generated password by default, got %q, %v", password, err)
+	}
+}
+
+func TestCreateContainerSummary(t *testing.T) {
+	var commands []string
+	config := make(map[string]string)
+	newManager := func() *MockContainerManager {
+		manager := newResumeManager("", &commands, config)
+		manager.ContainerExistsFunc = func(name string) bool { return false }
+		manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error { return nil }
+		manager.RunInContainerFunc = func(ctx context.Context, containerName string, args ...string) error { return nil }
+		manager.GetContainerPasswordFunc = func(containerName string) (string, error) { return "s3cret-pass", nil }
+		manager.GetContainerIPv4Func = func(name string) (string, error) { return "10.0.0.5", nil }
+		return manager
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", Output: "json", Out: &out})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var summary CreateSummary
+		if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+			t.Fatalf("expected JSON summary, got %q: %v", out.String(), err)
+		}
+		if summary.Name != "web" || summary.IPv4 != "10.0.0.5" || summary.Password != "s3cret-pass" ||
+			summary.StoragePool != "test-pool" || summary.Image != "ubuntu:24.04" || summary.User != "app" || !summary.Provisioned {
+			t.Errorf("unexpected summary: %+v", summary)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, want := range []string{"IPv4:          10.0.0.5", "Password:      s3cret-pass", "lxc-go-cli port add web"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("expected summary to contain %q, got:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("no provision", func(t *testing.T) {
+		var out bytes.Buffer
+		manager := newManager()
+		manager.GetContainerIPv4Func = func(name string) (string, error) { return "", fmt.Errorf("not found") }
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", NoProvision: true, Output: "json", Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var summary CreateSummary
+		json.Unmarshal(out.Bytes(), &summary)
+		if summary.Provisioned || summary.Password != "" || summary.IPv4 != "" {
+			t.Errorf("expected unprovisioned summary without password, got %+v", summary)
+		}
+		if len(summary.NextSteps) != 1 || summary.NextSteps[0] != "lxc-go-cli provision web" {
+			t.Errorf("expected provision hint, got %v", summary.NextSteps)
+		}
+	})
+
+	t.Run("ephemeral", func(t *testing.T) {
+		var out bytes.Buffer
+		launched := false
+		manager := newManager()
+		manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error {
+			t.Error("an ephemeral container should not be launched as a regular one")
+			return nil
+		}
+		manager.CreateEphemeralContainerFunc = func(name, distro, release, arch, storagePool string) error {
+			launched = true
+			return nil
+		}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", Ephemeral: true, Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !launched {
+			t.Error("expected an ephemeral launch")
+		}
+		if !strings.Contains(out.String(), "Ephemeral:     yes") {
+			t.Errorf("expected summary to mark the container ephemeral, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("vm", func(t *testing.T) {
+		var out bytes.Buffer
+		launched, agentWaits := false, 0
+		manager := newManager()
+		manager.CreateVirtualMachineFunc = func(name, distro, release, arch, storagePool string) error {
+			launched = true
+			return nil
+		}
+		manager.GetInstanceTypeFunc = func(name string) (string, error) {
+			return helpers.InstanceTypeVM, nil
+		}
+		manager.WaitForAgentFunc = func(ctx context.Context, name string) error {
+			agentWaits++
+			return nil
+		}
+		manager.ConfigureContainerSecurityFunc = func(containerName string) error {
+			t.Error("container security settings should not be applied to a VM")
+			return nil
+		}
+		manager.FindLocalImageFunc = func(ctx context.Context, image string) (*helpers.LocalImage, error) {
+			t.Error("the image step should not run for a VM")
+			return nil, nil
+		}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", VM: true, Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !launched {
+			t.Error("expected a VM launch")
+		}
+		// Once after the launch and once after the final restart
+		if agentWaits != 2 {
+			t.Errorf("expected to wait for the agent twice, waited %d times", agentWaits)
+		}
+		if !strings.Contains(out.String(), "Type:          virtual machine") {
+			t.Errorf("expected summary to mark the VM, got:\n%s", out.String())
+		}
+
+		if err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", VM: true, Ephemeral: true}); exitCode(err) != ExitUsage || !strings.Contains(err.Error(), "--ephemeral") {
+			t.Errorf("expected --vm with --ephemeral to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("target", func(t *testing.T) {
+		var out bytes.Buffer
+		manager := newManager()
+		manager.IsClusteredFunc = func(ctx context.Context) (bool, error) { return true, nil }
+		manager.ListClusterMembersFunc = func(ctx context.Context) ([]helpers.ClusterMember, error) {
+			return []helpers.ClusterMember{
+				{Name: "member1", Status: helpers.MemberStatusOnline},
+				{Name: "member3", Status: helpers.MemberStatusOnline},
+				{Name: "member4", Status: "Offline", Message: "No heartbeat for 2m"},
+			}, nil
+		}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", Target: "member3", Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Launched) != 1 || manager.Launched[0].Target != "member3" {
+			t.Errorf("expected a launch on member3, got %+v", manager.Launched)
+		}
+		if !strings.Contains(out.String(), "Location:      member3") {
+			t.Errorf("expected summary to show the member, got:\n%s", out.String())
+		}
+
+		for target, want := range map[string]string{"member9": "does not exist", "member4": "offline"} {
+			manager := newManager()
+			manager.IsClusteredFunc = func(ctx context.Context) (bool, error) { return true, nil }
+			manager.ListClusterMembersFunc = func(ctx context.Context) ([]helpers.ClusterMember, error) {
+				return []helpers.ClusterMember{{Name: "member4", Status: "Offline"}}, nil
+			}
+			if err := createContainerWithOptions(manager, CreateOptions{Name: "web", Target: target}); err == nil || !strings.Contains(err.Error(), want) || len(manager.Launched) > 0 {
+				t.Errorf("--target %s: expected error containing %q before launching, got %v", target, want, err)
+			}
+		}
+		if err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", Target: "member3"}); err == nil || !strings.Contains(err.Error(), "standalone") {
+			t.Errorf("expected --target to be rejected on a standalone server, got %v", err)
+		}
+	})
+
+	t.Run("preset", func(t *testing.T) {
+		manager := newManager()
+		limits := helpers.SizePreset{CPU: "2", Memory: "4GiB", Disk: "20GiB"}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", Preset: "medium", Limits: limits}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Launched) != 1 {
+			t.Fatalf("expected one launch, got %+v", manager.Launched)
+		}
+		launch := manager.Launched[0]
+		if launch.Config[helpers.LimitCPUKey] != "2" || launch.Config[helpers.LimitMemoryKey] != "4GiB" || launch.RootSize != "20GiB" {
+			t.Errorf("expected the preset's limits at launch, got %+v", launch)
+		}
+	})
+
+	t.Run("ipv6", func(t *testing.T) {
+		oldWait := createIPv6Wait
+		createIPv6Wait = 0
+		defer func() { createIPv6Wait = oldWait }()
+
+		manager := newManager()
+		manager.GetContainerIPv6Func = func(name string) ([]string, error) { return []string{"fd42:1:2:3::10"}, nil }
+		static := helpers.IPv6Config{Mode: helpers.IPv6Static, Address: "fd42:1:2:3::10"}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", IPv6: static}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Launched) != 1 || manager.Launched[0].IPv6 != static {
+			t.Errorf("expected the static address at launch, got %+v", manager.Launched)
+		}
+
+		disabled := false
+		manager = newManager()
+		manager.DisableIPv6Func = func(ctx context.Context, name string) error {
+			disabled = true
+			return nil
+		}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: "web", IPv6: helpers.IPv6Config{Mode: helpers.IPv6Disabled}}); err != nil || !disabled {
+			t.Errorf("expected IPv6 to be disabled, got %v (disabled: %v)", err, disabled)
+		}
+
+		err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", IPv6: helpers.IPv6Config{Mode: helpers.IPv6Auto}})
+		if err == nil || !strings.Contains(err.Error(), "no global IPv6 address") {
+			t.Errorf("expected a missing address to fail the ipv6 step, got %v", err)
+		}
+	})
+
+	t.Run("auto name", func(t *testing.T) {
+		var out bytes.Buffer
+		var checked, launched []string
+		manager := newManager()
+		manager.ContainerExistsFunc = func(name string) bool {
+			checked = append(checked, name)
+			// The first generated name is taken
+			return len(checked) == 1
+		}
+		manager.CreateContainerFunc = func(name, distro, release, arch, storagePool string) error {
+			launched = append(launched, name)
+			return nil
+		}
+		if err := createContainerWithOptions(manager, CreateOptions{Name: helpers.AutoContainerName, Output: "json", Out: &out}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var summary CreateSummary
+		json.Unmarshal(out.Bytes(), &summary)
+		if len(checked) < 2 || len(launched) != 1 || launched[0] != checked[1] || summary.Name != launched[0] {
+			t.Errorf("expected the unused generated name to be launched and reported, checked %v, launched %v, summary %q", checked, launched, summary.Name)
+		}
+
+		err := createContainerWithOptions(newManager(), CreateOptions{Name: helpers.AutoContainerName, Resume: true})
+		if err == nil || !strings.Contains(err.Error(), "--resume needs the name") {
+			t.Errorf("expected resume to need a name, got %v", err)
+		}
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		err := createContainerWithOptions(newManager(), CreateOptions{Name: "web", Output: "yaml"})
+		if err == nil || !strings.Contains(err.Error(), "invalid output format") {
+			t.Errorf("expected invalid output error, got %v", err)
+		}
+	})
+}
+
+func TestWaitForContainerIPv4(t *testing.T) {
+	calls := 0
+	manager := &MockContainerManager{GetContainerIPv4Func: func(name string) (string, error) {
+		calls++
+		return "", nil
+	}}
+	if ip := waitForContainerIPv4(manager, "web", 0); ip != "" || calls != 1 {
+		t.Errorf("expected a single attempt without a wait budget, got %q after %d calls", ip, calls)
+	}
+}
+
+func TestCreateContainerExplicitStoragePool(t *testing.T) {
+	var usedPool string
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) {
+			t.Error("GetOrCreateBtrfsPool should not be called when a pool is given")
+			return "", fmt.Errorf("unexpected call")
+		},
+		GetStoragePoolFunc: func(name string) (*helpers.StoragePool, error) {
+			if name != "fast" {
+				return nil, fmt.Errorf("storage pool '%s' does not exist", name)
+			}
+			return &helpers.StoragePool{Name: "fast", Driver: "btrfs"}, nil
+		},
+		CreateContainerFunc: func(name, distro, release, arch, storagePool string) error {
+			usedPool = storagePool
+			return nil
+		},
+		ConfigureContainerSecurityFunc: func(containerName string) error { return nil },
+		RunInContainerFunc:             func(ctx context.Context, containerName string, args ...string) error { return nil },
+		RestartContainerFunc:           func(ctx context.Context, name string) error { return nil },
+	}
+
+	err := createContainerWithOptions(manager, CreateOptions{Name: "test-container", StoragePool: "fast"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if usedPool != "fast" {
+		t.Errorf("expected container to be created in pool 'fast', got '%s'", usedPool)
+	}
+
+	err = createContainerWithOptions(manager, CreateOptions{Name: "test-container", StoragePool: "missing"})
+	if err == nil || !contains(err.Error(), "failed to use storage pool 'missing'") {
+		t.Errorf("expected missing pool error, got %v", err)
+	}
+}
+
+func TestCreateContainerWarnsOnLowMetadata(t *testing.T) {
+	th := logger.NewTestHelper()
+	defer th.Cleanup()
+	th.SetLevel(logger.WARN)
+
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) { return "test-pool", nil },
+		GetBtrfsUsageFunc: func(pool string) (*helpers.BtrfsUsage, error) {
+			return &helpers.BtrfsUsage{MetadataSize: 100, MetadataUsed: 99}, nil
+		},
+		CreateContainerFunc:            func(name, distro, release, arch, storagePool string) error { return nil },
+		ConfigureContainerSecurityFunc: func(containerName string) error { return nil },
+		RunInContainerFunc:             func(ctx context.Context, containerName string, args ...string) error { return nil },
+		RestartContainerFunc:           func(ctx context.Context, name string) error { return nil },
+	}
+
+	if err := createContainer(manager, "test-container", "ubuntu:24.04", "10G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	th.AssertContainsLog(t, logger.WARN, "storage maintain test-pool --balance")
+}
+
+func TestCreateContainerWarnsOnMissingSubIDs(t *testing.T) {
+	th := logger.NewTestHelper()
+	defer th.Cleanup()
+	th.SetLevel(logger.WARN)
+
+	manager := &MockContainerManager{
+		GetOrCreateBtrfsPoolFunc: func() (string, error) { return "test-pool", nil },
+		PlanSubIDFixesFunc: func() ([]helpers.IdmapFix, error) {
+			return []helpers.IdmapFix{{File: "/etc/subuid", Line: "root:1000000:1000000000", Reason: "root has no subordinate ids"}}, nil
+		},
+		CreateContainerFunc:            func(name, distro, release, arch, storagePool string) error { return nil },
+		ConfigureContainerSecurityFunc: func(containerName string) error { return nil },
+		RunInContainerFunc:             func(ctx context.Context, containerName string, args ...string) error { return nil },
+		RestartContainerFunc:           func(ctx context.Context, name string) error { return nil },
+	}
+
+	if err := createContainer(manager, "test-container", "ubuntu:24.04", "10G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	th.AssertContainsLog(t, logger.WARN, "root has no subordinate ids")
+	th.AssertContainsLog(t, logger.WARN, "doctor --fix-idmap")
 }
 
 func TestCreateCommandFlags(t *testing.T) {
@@ -501,6 +1552,21 @@ func TestCreateCommandFlags(t *testing.T) {
 	if sizeFlag.DefValue != "10G" {
 		t.Errorf("expected size flag default to be '10G', got '%s'", sizeFlag.DefValue)
 	}
+
+	if createCmd.Flags().Lookup("storage-pool") == nil {
+		t.Error("storage-pool flag should exist")
+	}
+
+	outputFlag := createCmd.Flags().Lookup("output")
+	if outputFlag == nil || outputFlag.DefValue != "text" {
+		t.Error("output flag should exist and default to text")
+	}
+	if createCmd.Flags().Lookup("password-stdin") == nil {
+		t.Error("password-stdin flag should exist")
+	}
+	if createCmd.Flags().Lookup("ipv6") == nil {
+		t.Error("ipv6 flag should exist")
+	}
 }
 
 func TestDefaultContainerManager(t *testing.T) {
@@ -526,15 +1592,99 @@ func TestDefaultContainerManagerMethods(t *testing.T) {
 	exists := manager.ContainerExists("test-container")
 	t.Logf("ContainerExists returned: %v", exists)
 
-	err = manager.CreateContainer("test", "ubuntu", "24.04", "amd64", "default")
-	t.Logf("CreateContainer returned: %v", err)
+	err = manager.LaunchInstance("test", "ubuntu", "24.04", "amd64", "default", helpers.LaunchOptions{})
+	t.Logf("LaunchInstance returned: %v", err)
 
 	err = manager.ConfigureContainerSecurity("test")
 	t.Logf("ConfigureContainerSecurity returned: %v", err)
 
-	err = manager.RunInContainer("test", "echo", "hello")
+	err = manager.RunInContainer(context.Background(), "test", "echo", "hello")
 	t.Logf("RunInContainer returned: %v", err)
 
-	err = manager.RestartContainer("test")
+	err = manager.RestartContainer(context.Background(), "test")
 	t.Logf("RestartContainer returned: %v", err)
 }
+
+func TestConfigureDockerStorage(t *testing.T) {
+	tests := []struct {
+		name         string
+		storage      DockerStorageOptions
+		btrfs        bool
+		expectAttach bool
+		expected     string
+	}{
+		{name: "auto on btrfs uses fuse-overlayfs", btrfs: true, expected: "fuse-overlayfs"},
+		{name: "auto elsewhere keeps the default"},
+		{name: "auto with a volume uses overlay2", btrfs: true, storage: DockerStorageOptions{VolumePool: "lvm"}, expectAttach: true, expected: "overlay2"},
+		{name: "explicit driver", btrfs: true, storage: DockerStorageOptions{Driver: "vfs"}, expected: "vfs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []string
+			var attached string
+			manager := &MockContainerManager{
+				RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
+					command := strings.Join(args, " ")
+					commands = append(commands, command)
+					if strings.Contains(command, "stat -f") && !tt.btrfs {
+						return fmt.Errorf("exit status 1")
+					}
+					return nil
+				},
+				AttachDockerVolumeFunc: func(containerName, pool string) error {
+					attached = pool
+					return nil
+				},
+			}
+
+			if err := configureDockerStorage(context.Background(), manager, "web", tt.storage); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if (attached != "") != tt.expectAttach {
+				t.Errorf("unexpected volume attach to pool '%s'", attached)
+			}
+
+			all := strings.Join(commands, "\n")
+			if tt.expected == "" {
+				if strings.Contains(all, "daemon.json") {
+					t.Errorf("expected Docker's default driver to be kept, got:\n%s", all)
+				}
+				return
+			}
+			if !strings.Contains(all, `"storage-driver": "`+tt.expected+`"`) {
+				t.Errorf("expected %s to be configured, got:\n%s", tt.expected, all)
+			}
+			if installed := strings.Contains(all, "apt-get install -y fuse-overlayfs"); installed != (tt.expected == "fuse-overlayfs") {
+				t.Errorf("unexpected fuse-overlayfs install state %v:\n%s", installed, all)
+			}
+		})
+	}
+}
+
+func TestDockerStorageOptionsValidate(t *testing.T) {
+	manager := &MockContainerManager{
+		GetStoragePoolFunc: func(name string) (*helpers.StoragePool, error) {
+			switch name {
+			case "lvm":
+				return &helpers.StoragePool{Name: name, Driver: "lvm"}, nil
+			case "fast":
+				return &helpers.StoragePool{Name: name, Driver: "btrfs"}, nil
+			}
+			return nil, fmt.Errorf("storage pool not found")
+		},
+	}
+
+	if err := (DockerStorageOptions{VolumePool: "lvm"}).validate(manager); err != nil {
+		t.Errorf("expected lvm pool to be accepted, got %v", err)
+	}
+	if err := (DockerStorageOptions{VolumePool: "fast"}).validate(manager); err == nil || !contains(err.Error(), "uses Btrfs") {
+		t.Errorf("expected Btrfs pool to be rejected, got %v", err)
+	}
+	if err := (DockerStorageOptions{VolumePool: "missing"}).validate(manager); err == nil {
+		t.Error("expected missing pool to be rejected")
+	}
+	if err := (DockerStorageOptions{Driver: "aufs"}).validate(manager); err == nil || !contains(err.Error(), "invalid Docker storage driver") {
+		t.Errorf("expected invalid driver error, got %v", err)
+	}
+}
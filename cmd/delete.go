@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteForce     bool
+	deleteUnmanaged bool
+	deletePurge     bool
+	deleteTimeout   time.Duration
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete <container-name>",
+	Short: "Delete a container managed by lxc-go-cli",
+	Long: `Delete a container created or adopted by lxc-go-cli.
+
+The container is moved to the trash rather than destroyed: it is stopped,
+kept from starting at boot and renamed to trash-<time>-<name>, freeing its
+name. 'restore-from-trash <name>' undoes the deletion and 'trash empty'
+destroys containers trashed more than a week ago. Ephemeral containers, and
+containers already in the trash, are deleted right away.
+
+--purge deletes the container immediately without the trash; on a terminal
+this has to be confirmed, and --yes skips the question. Destroying a
+container also removes its Docker volume, expose sites, certificates,
+renewal and snapshot cron entries, stored secrets, Vault secret and exposed
+firewall ports. Those set up under the name of a trashed container are kept
+when a new container has taken that name.
+
+Containers not managed by this tool are refused unless --unmanaged is given,
+so unrelated containers on the host cannot be removed by accident. Running
+containers are only deleted with --force.
+
+Examples:
+  lxc-go-cli delete mycontainer
+  lxc-go-cli restore-from-trash mycontainer
+  lxc-go-cli delete mycontainer --force --purge --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+		defer cancel()
+
+		if deletePurge {
+			return deleteContainer(ctx, &DefaultDeleteManager{}, args[0], deleteForce, deleteUnmanaged)
+		}
+		return trashContainer(ctx, &DefaultTrashManager{}, args[0], deleteForce, deleteUnmanaged, time.Now())
+	},
+}
+
+// DeleteManager interface for dependency injection
+type DeleteManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	DeleteContainer(ctx context.Context, name string, force bool) error
+}
+
+// DefaultDeleteManager implements DeleteManager using helpers
+type DefaultDeleteManager struct{}
+
+func (d *DefaultDeleteManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+func (d *DefaultDeleteManager) DeleteContainer(ctx context.Context, name string, force bool) error {
+	return helpers.DeleteContainer(name, force)
+}
+
+// findContainer returns the state of the named container
+func findContainer(ctx context.Context, manager ListManager, name string) (*helpers.ContainerState, error) {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range states {
+		if states[i].Name == name {
+			return &states[i], nil
+		}
+	}
+	return nil, containerNotFound(name)
+}
+
+// deletableContainer returns the state of a container after checking it is
+// managed and, unless force is set, stopped
+func deletableContainer(ctx context.Context, manager ListManager, name string, force, includeUnmanaged bool) (*helpers.ContainerState, error) {
+	if name == "" {
+		return nil, helpers.WithKind(i18n.Errorf("container.name_required"), helpers.ErrUsage)
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !includeUnmanaged && !helpers.IsManagedContainer(state.Config) {
+		return nil, helpers.WithKind(i18n.Errorf("delete.not_managed", name), helpers.ErrConflict)
+	}
+	if state.Status == "Running" && !force {
+		return nil, helpers.WithKind(i18n.Errorf("delete.running", name), helpers.ErrConflict)
+	}
+	return state, nil
+}
+
+// deleteContainer deletes a container after checking it is managed and stopped
+func deleteContainer(ctx context.Context, manager DeleteManager, name string, force, includeUnmanaged bool) error {
+	if _, err := deletableContainer(ctx, manager, name, force, includeUnmanaged); err != nil {
+		return err
+	}
+	if err := confirm("delete.confirm", name); err != nil {
+		return err
+	}
+
+	logger.Info("%s", i18n.T("delete.deleting", name))
+	if err := manager.DeleteContainer(ctx, name, force); err != nil {
+		return i18n.Errorf("delete.failed", name, err)
+	}
+
+	logger.Info("%s", i18n.T("delete.deleted", name))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Stop and delete a running container")
+	deleteCmd.Flags().BoolVar(&deleteUnmanaged, "unmanaged", false, "Allow deleting containers not managed by lxc-go-cli")
+	deleteCmd.Flags().BoolVar(&deletePurge, "purge", false, "Delete the container immediately instead of moving it to the trash")
+	deleteCmd.Flags().DurationVarP(&deleteTimeout, "timeout", "t", 2*time.Minute, "Timeout for the delete operation")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDeleteContainer(t *testing.T) {
+	tests := []struct {
+		name          string
+		container     string
+		force         bool
+		unmanaged     bool
+		errorContains string
+		exitCode      int
+	}{
+		{name: "stopped managed container", container: "legacy"},
+		{name: "running without force", container: "web", errorContains: "use --force", exitCode: ExitConflict},
+		{name: "running with force", container: "web", force: true},
+		{name: "unmanaged refused", container: "unrelated", force: true, errorContains: "not managed by lxc-go-cli", exitCode: ExitConflict},
+		{name: "unmanaged allowed", container: "unrelated", force: true, unmanaged: true},
+		{name: "missing container", container: "ghost", errorContains: "does not exist", exitCode: ExitNotFound},
+		{name: "empty name", errorContains: "name is required", exitCode: ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newMockListManager()
+			err := deleteContainer(context.Background(), manager, tt.container, tt.force, tt.unmanaged)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing '%s', got %v", tt.errorContains, err)
+				}
+				if got := exitCode(err); got != tt.exitCode {
+					t.Errorf("expected exit code %d, got %d", tt.exitCode, got)
+				}
+				if len(manager.Deleted) != 0 {
+					t.Error("nothing should be deleted on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(manager.Deleted) != 1 || manager.Deleted[0] != tt.container {
+				t.Errorf("expected '%s' to be deleted, got %v", tt.container, manager.Deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deprovisionRemoveUser   bool
+	deprovisionRemoveDocker bool
+)
+
+// deprovisionCmd represents the deprovision command
+var deprovisionCmd = &cobra.Command{
+	Use:   "deprovision <container-name>",
+	Short: "Remove Docker and the app user from a container without deleting it",
+	Long: `Undo the provisioning done by 'create', leaving the container itself in place.
+
+  --remove-user     deletes the 'app' user, its home directory and the stored password
+  --remove-docker   purges the Docker packages, images, volumes and Docker's apt repository
+
+Without either flag both are removed. The container must be running.
+The provisioning record is updated so 'create --resume' can provision it again.
+
+Examples:
+  lxc-go-cli deprovision mycontainer
+  lxc-go-cli deprovision mycontainer --remove-user`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removeUser, removeDocker := deprovisionRemoveUser, deprovisionRemoveDocker
+		if !removeUser && !removeDocker {
+			removeUser, removeDocker = true, true
+		}
+		return deprovisionContainer(context.Background(), &DefaultDeprovisionManager{}, args[0], removeUser, removeDocker)
+	},
+}
+
+// DeprovisionManager interface for dependency injection
+type DeprovisionManager interface {
+	ContainerExists(name string) bool
+	RunInContainer(ctx context.Context, containerName string, args ...string) error
+	GetConfigValue(containerName, key string) (string, error)
+	SetConfigValue(containerName, key, value string) error
+	UnsetConfigValue(containerName, key string) error
+}
+
+// DefaultDeprovisionManager implements DeprovisionManager using helpers
+type DefaultDeprovisionManager struct{}
+
+func (d *DefaultDeprovisionManager) ContainerExists(name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultDeprovisionManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	return helpers.RunInContainer(ctx, containerName, args...)
+}
+
+func (d *DefaultDeprovisionManager) GetConfigValue(containerName, key string) (string, error) {
+	return helpers.GetConfigValue(containerName, key)
+}
+
+func (d *DefaultDeprovisionManager) SetConfigValue(containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultDeprovisionManager) UnsetConfigValue(containerName, key string) error {
+	return helpers.UnsetConfigValue(containerName, key)
+}
+
+// deprovisionContainer removes the app user and/or Docker from a container
+func deprovisionContainer(ctx context.Context, manager DeprovisionManager, name string, removeUser, removeDocker bool) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(name) {
+		return containerNotFound(name)
+	}
+	var removing []string
+	if removeUser {
+		removing = append(removing, "the 'app' user and its home directory")
+	}
+	if removeDocker {
+		removing = append(removing, "Docker with its images and volumes")
+	}
+	if err := confirm("deprovision.confirm", strings.Join(removing, " and "), name); err != nil {
+		return err
+	}
+
+	var undone []string
+
+	// The user goes first so its processes are not left holding Docker resources
+	if removeUser {
+		if err := removeAppUser(ctx, manager, name); err != nil {
+			return err
+		}
+		undone = append(undone, stepAppUser)
+	}
+
+	if removeDocker {
+		if err := manager.RunInContainer(ctx, name, "sh", "-c", "command -v docker"); err != nil {
+			logger.Info("Docker is not installed")
+		} else {
+			logger.Info("Removing Docker and Docker Compose V2...")
+			if err := helpers.UninstallDockerFromContainer(ctx, manager, name); err != nil {
+				return err
+			}
+		}
+		undone = append(undone, stepDockerInstall)
+	}
+
+	if err := forgetProvisionSteps(manager, name, undone); err != nil {
+		logger.Warn("Failed to update provisioning record: %v", err)
+	}
+
+	logger.Info("Container '%s' deprovisioned", name)
+	return nil
+}
+
+// removeAppUser deletes the 'app' user and its stored password
+func removeAppUser(ctx context.Context, manager DeprovisionManager, name string) error {
+	if err := manager.RunInContainer(ctx, name, "id", "app"); err != nil {
+		logger.Info("'app' user does not exist")
+	} else {
+		logger.Info("Removing 'app' user and its home directory...")
+		// Kill leftover sessions first; userdel refuses to remove a logged-in user
+		_ = manager.RunInContainer(ctx, name, "pkill", "-KILL", "-u", "app")
+		if err := manager.RunInContainer(ctx, name, "userdel", "-r", "app"); err != nil {
+			return fmt.Errorf("failed to remove 'app' user: %w", err)
+		}
+	}
+
+	// Unsetting a key that is not set is an error, so check first
+	password, err := manager.GetConfigValue(name, "user.app-password")
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		logger.Info("Removing stored password...")
+		if err := manager.UnsetConfigValue(name, "user.app-password"); err != nil {
+			return fmt.Errorf("failed to remove stored password: %w", err)
+		}
+	}
+	return nil
+}
+
+// forgetProvisionSteps drops undone steps from the provisioning record
+func forgetProvisionSteps(manager DeprovisionManager, name string, undone []string) error {
+	value, err := manager.GetConfigValue(name, helpers.ProvisionStepsKey)
+	if err != nil || value == "" {
+		return err
+	}
+
+	removed := make(map[string]bool, len(undone))
+	for _, step := range undone {
+		removed[step] = true
+	}
+	var remaining []string
+	for _, step := range helpers.SplitConfigList(value) {
+		if !removed[step] {
+			remaining = append(remaining, step)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return manager.UnsetConfigValue(name, helpers.ProvisionStepsKey)
+	}
+	return manager.SetConfigValue(name, helpers.ProvisionStepsKey, strings.Join(remaining, ","))
+}
+
+func init() {
+	rootCmd.AddCommand(deprovisionCmd)
+
+	deprovisionCmd.Flags().BoolVar(&deprovisionRemoveUser, "remove-user", false, "Remove the 'app' user and its stored password")
+	deprovisionCmd.Flags().BoolVar(&deprovisionRemoveDocker, "remove-docker", false, "Remove Docker, its data and its apt repository")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockDeprovisionManager for testing deprovision command
+type MockDeprovisionManager struct {
+	ExistingContainers map[string]bool
+	MissingCommands    map[string]bool
+	Config             map[string]string
+	Commands           []string
+}
+
+func (m *MockDeprovisionManager) ContainerExists(name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockDeprovisionManager) RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	command := strings.Join(args, " ")
+	m.Commands = append(m.Commands, command)
+	if m.MissingCommands[command] {
+		return fmt.Errorf("exit status 1")
+	}
+	return nil
+}
+
+func (m *MockDeprovisionManager) GetConfigValue(containerName, key string) (string, error) {
+	return m.Config[key], nil
+}
+
+func (m *MockDeprovisionManager) SetConfigValue(containerName, key, value string) error {
+	m.Config[key] = value
+	return nil
+}
+
+func (m *MockDeprovisionManager) UnsetConfigValue(containerName, key string) error {
+	if _, ok := m.Config[key]; !ok {
+		return fmt.Errorf("key '%s' is not set", key)
+	}
+	delete(m.Config, key)
+	return nil
+}
+
+func (m *MockDeprovisionManager) ran(prefix string) bool {
+	for _, command := range m.Commands {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func newMockDeprovisionManager() *MockDeprovisionManager {
+	return &MockDeprovisionManager{
+		ExistingContainers: map[string]bool{"test-container": true},
+		Config: map[string]string{
+			"user.app-password":       "c2VjcmV0",
+			helpers.ProvisionStepsKey: strings.Join(createStepNames(), ","),
+		},
+	}
+}
+
+func TestDeprovisionContainer(t *testing.T) {
+	manager := newMockDeprovisionManager()
+
+	if err := deprovisionContainer(context.Background(), manager, "test-container", true, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !manager.ran("userdel -r app") {
+		t.Error("app user should be removed")
+	}
+	if !manager.ran("apt-get purge -y docker-ce") {
+		t.Error("Docker packages should be purged")
+	}
+	if _, ok := manager.Config["user.app-password"]; ok {
+		t.Error("stored password should be removed")
+	}
+	if steps := manager.Config[helpers.ProvisionStepsKey]; steps != "launch,security,apt-update,locale,docker-storage,packages,restart" {
+		t.Errorf("expected undone steps to be dropped from the record, got '%s'", steps)
+	}
+}
+
+func TestDeprovisionContainerUserOnly(t *testing.T) {
+	manager := newMockDeprovisionManager()
+
+	if err := deprovisionContainer(context.Background(), manager, "test-container", true, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if manager.ran("apt-get purge") {
+		t.Error("Docker should be kept when only the user is removed")
+	}
+	if steps := manager.Config[helpers.ProvisionStepsKey]; strings.Contains(steps, stepAppUser) || !strings.Contains(steps, stepDockerInstall) {
+		t.Errorf("only the app-user step should be dropped, got '%s'", steps)
+	}
+}
+
+func TestDeprovisionContainerNothingInstalled(t *testing.T) {
+	manager := &MockDeprovisionManager{
+		ExistingContainers: map[string]bool{"test-container": true},
+		MissingCommands:    map[string]bool{"id app": true, "sh -c command -v docker": true},
+		Config:             map[string]string{},
+	}
+
+	if err := deprovisionContainer(context.Background(), manager, "test-container", true, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if manager.ran("userdel") || manager.ran("apt-get purge") {
+		t.Errorf("nothing should be removed, got %v", manager.Commands)
+	}
+}
+
+func TestDeprovisionContainerErrors(t *testing.T) {
+	manager := newMockDeprovisionManager()
+
+	if err := deprovisionContainer(context.Background(), manager, "", true, true); err == nil {
+		t.Error("expected error for empty container name")
+	}
+	if err := deprovisionContainer(context.Background(), manager, "missing", true, true); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+
+	manager.MissingCommands = map[string]bool{"userdel -r app": true}
+	err := deprovisionContainer(context.Background(), manager, "test-container", true, true)
+	if err == nil || !strings.Contains(err.Error(), "failed to remove 'app' user") {
+		t.Errorf("expected userdel failure, got %v", err)
+	}
+	if manager.ran("apt-get purge") {
+		t.Error("Docker should not be removed after the user removal failed")
+	}
+}
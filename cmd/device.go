@@ -0,0 +1,244 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deviceTimeout    time.Duration
+	deviceAttachName string
+	deviceVendorID   string
+	deviceProductID  string
+	devicePath       string
+	deviceListTable  tableFlags
+)
+
+// deviceCmd represents the device command
+var deviceCmd = &cobra.Command{
+	Use:   "device <attach|list|detach>",
+	Short: "Pass USB and serial devices into a container",
+	Long: `Pass host USB devices and serial or other character devices into a
+container, e.g. a Zigbee stick for Home Assistant or a microcontroller to flash.
+
+USB devices are matched by vendor and product ID as shown by lsusb; LXD
+passes matching devices in whenever they are plugged in. Serial devices are
+given by path and hotplugged too: the device may be missing when attached and
+appears in the container once it shows up on the host. Devices are opened with
+mode 0666 so the 'app' user can use them.
+
+Available subcommands:
+  attach  - Pass a USB or serial device into a container
+  list    - List the USB and character devices of a container
+  detach  - Remove a device from a container
+
+Examples:
+  lxc-go-cli device attach mycontainer usb --vendorid 1d6b --productid 0002
+  lxc-go-cli device attach mycontainer serial --path /dev/ttyUSB0
+  lxc-go-cli device list mycontainer
+  lxc-go-cli device detach mycontainer usb-1d6b-0002`,
+}
+
+// deviceAttachCmd represents the device attach subcommand
+var deviceAttachCmd = &cobra.Command{
+	Use:   "attach <container-name> <usb|serial>",
+	Short: "Pass a USB or serial device into a container",
+	Long: `Pass a USB or serial device into a container.
+
+For usb, --vendorid is required and --productid narrows the match to one
+product. For serial, --path names the device node, e.g. /dev/ttyUSB0 or
+/dev/ttyACM0. The device is named after the IDs or the node unless --name is
+given.
+
+Examples:
+  lxc-go-cli device attach mycontainer usb --vendorid 1d6b --productid 0002
+  lxc-go-cli device attach mycontainer serial --path /dev/ttyACM0 --name printer`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device, err := newPassthroughDevice(args[1], deviceAttachName, deviceVendorID, deviceProductID, devicePath)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), deviceTimeout)
+		defer cancel()
+
+		return attachDevice(ctx, &DefaultDeviceManager{}, args[0], device)
+	},
+}
+
+// deviceListCmd represents the device list subcommand
+var deviceListCmd = &cobra.Command{
+	Use:   "list [container-name]",
+	Short: "List the USB and character devices of a container",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := containerArg(args)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), deviceTimeout)
+		defer cancel()
+
+		out := cmd.OutOrStdout()
+		return listDevices(ctx, &DefaultDeviceManager{}, name, out, deviceListTable.options(out))
+	},
+}
+
+// deviceDetachCmd represents the device detach subcommand
+var deviceDetachCmd = &cobra.Command{
+	Use:   "detach <container-name> <device-name>",
+	Short: "Remove a USB or character device from a container",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), deviceTimeout)
+		defer cancel()
+
+		return detachDevice(ctx, &DefaultDeviceManager{}, args[0], args[1])
+	},
+}
+
+// DeviceManager interface for dependency injection
+type DeviceManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetContainerDevices(ctx context.Context, containerName string) (map[string]map[string]string, error)
+	AddDevice(ctx context.Context, containerName string, device *helpers.PassthroughDevice) error
+	RemoveDevice(ctx context.Context, containerName, deviceName string) error
+}
+
+// DefaultDeviceManager implements DeviceManager using helpers
+type DefaultDeviceManager struct{}
+
+func (d *DefaultDeviceManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultDeviceManager) GetContainerDevices(ctx context.Context, containerName string) (map[string]map[string]string, error) {
+	return helpers.GetContainerDevices(containerName)
+}
+
+func (d *DefaultDeviceManager) AddDevice(ctx context.Context, containerName string, device *helpers.PassthroughDevice) error {
+	return helpers.AddContainerDevice(containerName, device.Name, device.Type, device.Properties)
+}
+
+func (d *DefaultDeviceManager) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	return helpers.RemoveContainerDevice(containerName, deviceName)
+}
+
+// newPassthroughDevice builds the device to attach from the kind and flags
+func newPassthroughDevice(kind, name, vendorID, productID, path string) (*helpers.PassthroughDevice, error) {
+	switch strings.ToLower(kind) {
+	case helpers.DeviceKindUSB:
+		if path != "" {
+			return nil, helpers.UsageErrorf("--path is for serial devices; match USB devices with --vendorid and --productid")
+		}
+		if vendorID == "" {
+			return nil, helpers.UsageErrorf("--vendorid is required for usb devices (see lsusb)")
+		}
+		return helpers.NewUSBDevice(name, vendorID, productID)
+	case helpers.DeviceKindSerial:
+		if vendorID != "" || productID != "" {
+			return nil, helpers.UsageErrorf("--vendorid and --productid are for usb devices; give serial devices with --path")
+		}
+		if path == "" {
+			return nil, helpers.UsageErrorf("--path is required for serial devices, e.g. /dev/ttyUSB0")
+		}
+		return helpers.NewSerialDevice(name, path)
+	default:
+		return nil, helpers.UsageErrorf("invalid device kind '%s': must be 'usb' or 'serial'", kind)
+	}
+}
+
+// attachDevice adds a device to a container unless the name is taken
+func attachDevice(ctx context.Context, manager DeviceManager, containerName string, device *helpers.PassthroughDevice) error {
+	devices, err := containerDevices(ctx, manager, containerName)
+	if err != nil {
+		return err
+	}
+	if _, exists := devices[device.Name]; exists {
+		return fmt.Errorf("container '%s' already has a device named '%s' (choose another with --name)", containerName, device.Name)
+	}
+
+	if err := manager.AddDevice(ctx, containerName, device); err != nil {
+		return err
+	}
+	logger.Info("Attached %s to container '%s' as '%s'", device.Describe(), containerName, device.Name)
+	return nil
+}
+
+// listDevices prints a container's USB and character devices
+func listDevices(ctx context.Context, manager DeviceManager, containerName string, out io.Writer, table render.Options) error {
+	devices, err := containerDevices(ctx, manager, containerName)
+	if err != nil {
+		return err
+	}
+
+	passthrough := helpers.PassthroughDevices(devices)
+	if len(passthrough) == 0 {
+		fmt.Fprintf(out, "No USB or character devices attached to container '%s'\n", containerName)
+		return nil
+	}
+
+	t := render.NewTable("NAME", "TYPE", "DEVICE", "MODE")
+	for _, device := range passthrough {
+		t.AddRow(device.Name, device.Type, device.Describe(), valueOrDash(device.Properties["mode"]))
+	}
+	return t.Render(out, table)
+}
+
+// detachDevice removes a USB or character device; other devices, such as
+// proxies and disks, have their own commands
+func detachDevice(ctx context.Context, manager DeviceManager, containerName, deviceName string) error {
+	devices, err := containerDevices(ctx, manager, containerName)
+	if err != nil {
+		return err
+	}
+	props, exists := devices[deviceName]
+	if !exists {
+		return helpers.NotFoundErrorf("container '%s' has no device named '%s' (see 'lxc-go-cli device list %s')", containerName, deviceName, containerName)
+	}
+	if !helpers.IsPassthroughType(props["type"]) {
+		return fmt.Errorf("device '%s' is a %s device, not a USB or character device", deviceName, props["type"])
+	}
+
+	if err := manager.RemoveDevice(ctx, containerName, deviceName); err != nil {
+		return err
+	}
+	logger.Info("Detached '%s' from container '%s'", deviceName, containerName)
+	return nil
+}
+
+// containerDevices checks the container exists and returns its devices
+func containerDevices(ctx context.Context, manager DeviceManager, containerName string) (map[string]map[string]string, error) {
+	if !manager.ContainerExists(ctx, containerName) {
+		return nil, containerNotFound(containerName)
+	}
+	return manager.GetContainerDevices(ctx, containerName)
+}
+
+func init() {
+	rootCmd.AddCommand(deviceCmd)
+
+	deviceCmd.AddCommand(deviceAttachCmd)
+	deviceCmd.AddCommand(deviceListCmd)
+	deviceCmd.AddCommand(deviceDetachCmd)
+
+	deviceCmd.PersistentFlags().DurationVarP(&deviceTimeout, "timeout", "t", 30*time.Second, "Timeout for device operations")
+	deviceAttachCmd.Flags().StringVar(&deviceAttachName, "name", "", "Device name (default: derived from the IDs or path)")
+	deviceAttachCmd.Flags().StringVar(&deviceVendorID, "vendorid", "", "USB vendor ID, e.g. 1d6b")
+	deviceAttachCmd.Flags().StringVar(&deviceProductID, "productid", "", "USB product ID, e.g. 0002")
+	deviceAttachCmd.Flags().StringVar(&devicePath, "path", "", "Serial device node, e.g. /dev/ttyUSB0")
+	addTableFlags(deviceListCmd, &deviceListTable)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockDeviceManager keeps a container's devices in memory for testing
+type MockDeviceManager struct {
+	Devices map[string]map[string]string
+	Added   []*helpers.PassthroughDevice
+	Removed []string
+}
+
+func newMockDeviceManager() *MockDeviceManager {
+	return &MockDeviceManager{Devices: map[string]map[string]string{
+		"root":          {"type": "disk", "path": "/"},
+		"usb-1d6b-0002": {"type": "usb", "vendorid": "1d6b", "productid": "0002", "mode": "0666"},
+	}}
+}
+
+func (m *MockDeviceManager) ContainerExists(ctx context.Context, name string) bool {
+	return name == "web"
+}
+
+func (m *MockDeviceManager) GetContainerDevices(ctx context.Context, containerName string) (map[string]map[string]string, error) {
+	return m.Devices, nil
+}
+
+func (m *MockDeviceManager) AddDevice(ctx context.Context, containerName string, device *helpers.PassthroughDevice) error {
+	m.Added = append(m.Added, device)
+	return nil
+}
+
+func (m *MockDeviceManager) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	m.Removed = append(m.Removed, deviceName)
+	return nil
+}
+
+func TestNewPassthroughDevice(t *testing.T) {
+	if device, err := newPassthroughDevice("USB", "", "1d6b", "0003", ""); err != nil || device.Name != "usb-1d6b-0003" {
+		t.Errorf("unexpected usb device %+v (%v)", device, err)
+	}
+	if device, err := newPassthroughDevice("serial", "", "", "", "/dev/ttyACM0"); err != nil || device.Name != "serial-ttyACM0" {
+		t.Errorf("unexpected serial device %+v (%v)", device, err)
+	}
+
+	tests := []struct {
+		kind, vendor, product, path, want string
+	}{
+		{"usb", "", "", "", "--vendorid is required"},
+		{"usb", "1d6b", "", "/dev/ttyUSB0", "--path is for serial devices"},
+		{"serial", "", "", "", "--path is required"},
+		{"serial", "1d6b", "", "/dev/ttyUSB0", "are for usb devices"},
+		{"pci", "", "", "", "invalid device kind 'pci'"},
+	}
+	for _, tt := range tests {
+		if _, err := newPassthroughDevice(tt.kind, "", tt.vendor, tt.product, tt.path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: expected %q, got %v", tt, tt.want, err)
+		}
+	}
+}
+
+func TestAttachDevice(t *testing.T) {
+	ctx := context.Background()
+	manager := newMockDeviceManager()
+
+	device, _ := helpers.NewSerialDevice("", "/dev/ttyUSB0")
+	if err := attachDevice(ctx, manager, "web", device); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Added) != 1 || manager.Added[0].Name != "serial-ttyUSB0" {
+		t.Errorf("expected the serial device to be added, got %+v", manager.Added)
+	}
+
+	taken, _ := helpers.NewUSBDevice("", "1d6b", "0002")
+	if err := attachDevice(ctx, manager, "web", taken); err == nil || !strings.Contains(err.Error(), "already has a device named 'usb-1d6b-0002'") {
+		t.Errorf("expected a name clash error, got %v", err)
+	}
+	if err := attachDevice(ctx, manager, "missing", device); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing container error, got %v", err)
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	var out bytes.Buffer
+	if err := listDevices(context.Background(), newMockDeviceManager(), "web", &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "NAME           TYPE  DEVICE                     MODE\n" +
+		"usb-1d6b-0002  usb   vendor 1d6b, product 0002  0666\n"
+	if out.String() != want {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, out.String())
+	}
+
+	out.Reset()
+	empty := &MockDeviceManager{Devices: map[string]map[string]string{"root": {"type": "disk"}}}
+	if err := listDevices(context.Background(), empty, "web", &out, render.Options{}); err != nil || !strings.Contains(out.String(), "No USB or character devices") {
+		t.Errorf("expected an empty message, got %v and %q", err, out.String())
+	}
+}
+
+func TestDetachDevice(t *testing.T) {
+	ctx := context.Background()
+	manager := newMockDeviceManager()
+
+	if err := detachDevice(ctx, manager, "web", "usb-1d6b-0002"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Removed) != 1 {
+		t.Errorf("expected the device to be removed, got %v", manager.Removed)
+	}
+
+	if err := detachDevice(ctx, manager, "web", "root"); err == nil || !strings.Contains(err.Error(), "is a disk device") {
+		t.Errorf("expected other device types to be refused, got %v", err)
+	}
+	if err := detachDevice(ctx, manager, "web", "dongle"); err == nil || !strings.Contains(err.Error(), "has no device named 'dongle'") {
+		t.Errorf("expected a missing device error, got %v", err)
+	}
+	if len(manager.Removed) != 1 {
+		t.Errorf("expected nothing else to be removed, got %v", manager.Removed)
+	}
+}
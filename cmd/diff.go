@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var diffTimeout time.Duration
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <container-a> <container-b>",
+	Short: "Compare the configuration, devices and Docker versions of two containers",
+	Long: `Compare two containers and print their differences as a unified-diff-style
+report: lines starting with '-' are only in the first container, lines starting
+with '+' only in the second.
+
+Compared are:
+  config        LXC config keys, including those inherited from profiles
+  devices       devices with their properties
+  docker        installed Docker package versions (running containers only)
+  provisioning  metadata this tool keeps, such as the completed provisioning steps
+
+Device names that start with the container name, such as port forwarding
+devices, are shown as <name>-... so they compare equal. Volatile keys, creation
+times and secrets are not compared.
+
+Like diff, the command exits with status 1 when the containers differ.
+
+Examples:
+  lxc-go-cli diff web-staging web-prod`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), diffTimeout)
+		defer cancel()
+
+		out := cmd.OutOrStdout()
+		return diffContainers(ctx, &DefaultDiffManager{}, args[0], args[1], out, render.StyleFor(out))
+	},
+}
+
+// DiffManager interface for dependency injection
+type DiffManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetExpandedConfig(ctx context.Context, containerName string) ([]byte, error)
+	GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error)
+}
+
+// DefaultDiffManager implements DiffManager using helpers
+type DefaultDiffManager struct{}
+
+func (d *DefaultDiffManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultDiffManager) GetExpandedConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetExpandedContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultDiffManager) GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error) {
+	return helpers.GetPackageVersions(ctx, containerName, packages...)
+}
+
+// diffContainers prints the differences between two containers and returns
+// an exit status 1 error when there are any
+func diffContainers(ctx context.Context, manager DiffManager, nameA, nameB string, out io.Writer, style render.Style) error {
+	if nameA == nameB {
+		return fmt.Errorf("cannot compare container '%s' with itself", nameA)
+	}
+	factsA, err := containerFacts(ctx, manager, nameA)
+	if err != nil {
+		return err
+	}
+	factsB, err := containerFacts(ctx, manager, nameB)
+	if err != nil {
+		return err
+	}
+
+	// Without versions from both sides every package would show as a difference
+	versionsA, errA := manager.GetPackageVersions(ctx, nameA, helpers.DockerPackages...)
+	versionsB, errB := manager.GetPackageVersions(ctx, nameB, helpers.DockerPackages...)
+	if errA == nil && errB == nil {
+		factsA[helpers.DiffSectionDocker] = versionsA
+		factsB[helpers.DiffSectionDocker] = versionsB
+	} else {
+		logger.Warn("Docker versions are not compared; both containers must be running")
+		logger.Debug("Package queries returned: %v, %v", errA, errB)
+	}
+
+	diffs := helpers.DiffContainerFacts(factsA, factsB)
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "Containers '%s' and '%s' have the same configuration\n", nameA, nameB)
+		return nil
+	}
+
+	fmt.Fprint(out, formatContainerDiff(nameA, nameB, diffs, style))
+	return &ExitError{Code: 1, Err: fmt.Errorf("containers '%s' and '%s' differ in %d setting(s)", nameA, nameB, len(diffs))}
+}
+
+// containerFacts checks a container exists and collects its comparable settings
+func containerFacts(ctx context.Context, manager DiffManager, name string) (helpers.ContainerFacts, error) {
+	if !manager.ContainerExists(ctx, name) {
+		return nil, containerNotFound(name)
+	}
+	config, err := manager.GetExpandedConfig(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ContainerFactsFromConfig(name, config)
+}
+
+// formatContainerDiff renders differences like a unified diff, with one
+// hunk per section
+func formatContainerDiff(nameA, nameB string, diffs []helpers.FactDiff, style render.Style) string {
+	report := fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB)
+	section := ""
+	for _, diff := range diffs {
+		if diff.Section != section {
+			section = diff.Section
+			report += style.Paint(render.Dim, fmt.Sprintf("@@ %s @@", section)) + "\n"
+		}
+		if diff.InA {
+			report += style.Paint(render.Red, fmt.Sprintf("-%s: %s", diff.Key, diff.A)) + "\n"
+		}
+		if diff.InB {
+			report += style.Paint(render.Green, fmt.Sprintf("+%s: %s", diff.Key, diff.B)) + "\n"
+		}
+	}
+	return report
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().DurationVarP(&diffTimeout, "timeout", "t", 30*time.Second, "Timeout for reading the containers")
+}
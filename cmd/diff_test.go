@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockDiffManager for testing the diff command
+type MockDiffManager struct {
+	Configs  map[string]string
+	Versions map[string]map[string]string
+}
+
+func (m *MockDiffManager) ContainerExists(ctx context.Context, name string) bool {
+	_, ok := m.Configs[name]
+	return ok
+}
+
+func (m *MockDiffManager) GetExpandedConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return []byte(m.Configs[containerName]), nil
+}
+
+func (m *MockDiffManager) GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error) {
+	versions, ok := m.Versions[containerName]
+	if !ok {
+		return nil, fmt.Errorf("container is not running")
+	}
+	return versions, nil
+}
+
+func newMockDiffManager() *MockDiffManager {
+	return &MockDiffManager{
+		Configs: map[string]string{
+			"staging": "config:\n  limits.memory: 2GiB\n  user.lxc-go-cli.version: 1.2.0\ndevices:\n  staging-8080-80-tcp:\n    type: proxy\n    listen: tcp:0.0.0.0:8080\n",
+			"prod":    "config:\n  limits.memory: 4GiB\n  user.lxc-go-cli.version: 1.2.0\ndevices:\n  prod-8080-80-tcp:\n    type: proxy\n    listen: tcp:0.0.0.0:8080\n  gpu:\n    type: gpu\n",
+			"prod2":   "config:\n  limits.memory: 4GiB\n  user.lxc-go-cli.version: 1.2.0\ndevices:\n  prod2-8080-80-tcp:\n    type: proxy\n    listen: tcp:0.0.0.0:8080\n  gpu:\n    type: gpu\n",
+		},
+		Versions: map[string]map[string]string{
+			"staging": {"docker-ce": "5:27.0.1", "containerd.io": "1.7.19"},
+			"prod":    {"docker-ce": "5:27.1.0", "containerd.io": "1.7.19"},
+			"prod2":   {"docker-ce": "5:27.1.0", "containerd.io": "1.7.19"},
+		},
+	}
+}
+
+func TestDiffContainers(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+
+	err := diffContainers(ctx, newMockDiffManager(), "staging", "prod", &out, render.Style{})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || !strings.Contains(err.Error(), "differ in 3 setting(s)") {
+		t.Fatalf("expected exit status 1 for 3 differences, got %v", err)
+	}
+	want := "--- staging\n+++ prod\n" +
+		"@@ config @@\n" +
+		"-limits.memory: 2GiB\n" +
+		"+limits.memory: 4GiB\n" +
+		"@@ devices @@\n" +
+		"+gpu.type: gpu\n" +
+		"@@ docker @@\n" +
+		"-docker-ce: 5:27.0.1\n" +
+		"+docker-ce: 5:27.1.0\n"
+	if out.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	if err := diffContainers(ctx, newMockDiffManager(), "prod", "prod2", &out, render.Style{}); err != nil || !strings.Contains(out.String(), "have the same configuration") {
+		t.Errorf("expected identical containers, got %v and %q", err, out.String())
+	}
+
+	manager := newMockDiffManager()
+	delete(manager.Versions, "prod")
+	out.Reset()
+	if err := diffContainers(ctx, manager, "staging", "prod", &out, render.Style{}); err == nil || strings.Contains(out.String(), "docker") {
+		t.Errorf("expected Docker versions to be skipped for a stopped container, got %v and:\n%s", err, out.String())
+	}
+
+	if err := diffContainers(ctx, manager, "staging", "missing", &out, render.Style{}); err == nil || !strings.Contains(err.Error(), "'missing' does not exist") {
+		t.Errorf("expected a missing container error, got %v", err)
+	}
+	if err := diffContainers(ctx, manager, "prod", "prod", &out, render.Style{}); err == nil {
+		t.Error("expected an error comparing a container with itself")
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dnsTimeout  time.Duration
+	dnsNetwork  string
+	dnsResolver string
+)
+
+// dnsResolverAuto picks the host resolver that is running
+const dnsResolverAuto = "auto"
+
+// dnsCmd represents the dns command
+var dnsCmd = &cobra.Command{
+	Use:   "dns <setup|teardown>",
+	Short: "Resolve <container>.lxd names from the host",
+	Long: `LXD's DNS server on a bridge knows every container on it as
+<container>.<domain>, the domain being the network's dns.domain (lxd by
+default), but the host does not ask it.
+
+  setup      points the host's resolver at the bridge's DNS server for the
+             domain: systemd-resolved through a systemd unit that reapplies
+             the setting whenever the bridge comes up, or dnsmasq through a
+             server= rule in /etc/dnsmasq.d
+  teardown   removes that configuration again
+
+--resolver picks systemd-resolved or dnsmasq; by default the one running is
+used. Both need root and a local LXD server. 'lxc-go-cli doctor' checks that a
+running container resolves once setup is done.
+
+Examples:
+  sudo lxc-go-cli dns setup
+  sudo lxc-go-cli dns setup --network lxdbr1 --resolver dnsmasq
+  sudo lxc-go-cli dns teardown`,
+}
+
+// dnsSetupCmd represents the dns setup subcommand
+var dnsSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Make the host resolve a bridge's container names",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+		defer cancel()
+
+		if err := helpers.RequireLocalServer(ctx, "dns setup"); err != nil {
+			return err
+		}
+		return setupDNS(ctx, &DefaultDNSManager{}, dnsNetwork, dnsResolver)
+	},
+}
+
+// dnsTeardownCmd represents the dns teardown subcommand
+var dnsTeardownCmd = &cobra.Command{
+	Use:   "teardown",
+	Short: "Remove what 'dns setup' configured",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+		defer cancel()
+
+		if err := helpers.RequireLocalServer(ctx, "dns teardown"); err != nil {
+			return err
+		}
+		return teardownDNS(ctx, &DefaultDNSManager{}, dnsNetwork)
+	},
+}
+
+// DNSManager interface for dependency injection
+type DNSManager interface {
+	ListManager
+	GetBridgeDNS(ctx context.Context, network string) (helpers.BridgeDNS, error)
+	DetectDNSResolver(ctx context.Context) (string, error)
+	SetupBridgeDNS(ctx context.Context, bridge helpers.BridgeDNS, resolver string) error
+	TeardownBridgeDNS(ctx context.Context, network string) (bool, error)
+	CheckBridgeDNS(ctx context.Context, network string, states []helpers.ContainerState) helpers.DNSCheck
+}
+
+// DefaultDNSManager implements DNSManager using helpers
+type DefaultDNSManager struct {
+	DefaultListManager
+}
+
+func (d *DefaultDNSManager) GetBridgeDNS(ctx context.Context, network string) (helpers.BridgeDNS, error) {
+	return helpers.GetBridgeDNS(ctx, network)
+}
+
+func (d *DefaultDNSManager) DetectDNSResolver(ctx context.Context) (string, error) {
+	return helpers.DetectDNSResolver(ctx)
+}
+
+func (d *DefaultDNSManager) SetupBridgeDNS(ctx context.Context, bridge helpers.BridgeDNS, resolver string) error {
+	return helpers.SetupBridgeDNS(ctx, bridge, resolver)
+}
+
+func (d *DefaultDNSManager) TeardownBridgeDNS(ctx context.Context, network string) (bool, error) {
+	return helpers.TeardownBridgeDNS(ctx, network)
+}
+
+func (d *DefaultDNSManager) CheckBridgeDNS(ctx context.Context, network string, states []helpers.ContainerState) helpers.DNSCheck {
+	return helpers.CheckBridgeDNS(ctx, network, states)
+}
+
+// setupDNS configures the host resolver for a bridge, then tries to resolve
+// one of its containers
+func setupDNS(ctx context.Context, manager DNSManager, network, resolver string) error {
+	switch resolver {
+	case dnsResolverAuto, helpers.DNSResolverResolved, helpers.DNSResolverDnsmasq:
+	default:
+		return helpers.UsageErrorf("invalid resolver '%s': must be %s, %s or %s", resolver, dnsResolverAuto, helpers.DNSResolverResolved, helpers.DNSResolverDnsmasq)
+	}
+
+	bridge, err := manager.GetBridgeDNS(ctx, network)
+	if err != nil {
+		return err
+	}
+	if resolver == dnsResolverAuto {
+		if resolver, err = manager.DetectDNSResolver(ctx); err != nil {
+			return fmt.Errorf("%w; pick one with --resolver", err)
+		}
+	}
+
+	logger.Info("Forwarding .%s to %s on %s with %s...", bridge.Domain, bridge.Address, network, resolver)
+	if err := manager.SetupBridgeDNS(ctx, bridge, resolver); err != nil {
+		return err
+	}
+
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		logger.Warn("Could not list containers to test resolution: %v", err)
+		return nil
+	}
+	check := manager.CheckBridgeDNS(ctx, network, states)
+	switch {
+	case check.OK:
+		logger.Info("Verified: %s", check.Message)
+	case check.Warning:
+		logger.Info("Not verified: %s; 'lxc-go-cli doctor' checks it later", check.Message)
+	default:
+		logger.Warn("%s; it can take a few seconds to apply, check again with 'lxc-go-cli doctor'", check.Message)
+	}
+	return nil
+}
+
+// teardownDNS removes the resolver configuration of a bridge
+func teardownDNS(ctx context.Context, manager DNSManager, network string) error {
+	removed, err := manager.TeardownBridgeDNS(ctx, network)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		logger.Info("Nothing to remove: 'dns setup' was not run for %s", network)
+		return nil
+	}
+	logger.Info("Removed the DNS configuration for %s; its container names no longer resolve from the host", network)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dnsCmd)
+	dnsCmd.AddCommand(dnsSetupCmd)
+	dnsCmd.AddCommand(dnsTeardownCmd)
+
+	dnsCmd.PersistentFlags().DurationVarP(&dnsTimeout, "timeout", "t", time.Minute, "Timeout for the dns operation")
+	dnsCmd.PersistentFlags().StringVar(&dnsNetwork, "network", "lxdbr0", "Bridge network whose container names to resolve")
+	dnsSetupCmd.Flags().StringVar(&dnsResolver, "resolver", dnsResolverAuto, "Host resolver to configure: auto, systemd-resolved or dnsmasq")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockDNSManager for testing dns setup and teardown
+type MockDNSManager struct {
+	*MockListManager
+	Bridge        helpers.BridgeDNS
+	BridgeError   error
+	Resolver      string
+	ResolverError error
+	SetupWith     string
+	SetupError    error
+	Removed       bool
+	Check         helpers.DNSCheck
+}
+
+func newMockDNSManager() *MockDNSManager {
+	return &MockDNSManager{
+		MockListManager: newMockListManager(),
+		Bridge:          helpers.BridgeDNS{Network: "lxdbr0", Address: "10.0.0.1", Domain: "lxd"},
+		Resolver:        helpers.DNSResolverResolved,
+		Check:           helpers.DNSCheck{Network: "lxdbr0", OK: true, Message: "web.lxd resolves to 10.0.0.2"},
+	}
+}
+
+func (m *MockDNSManager) GetBridgeDNS(ctx context.Context, network string) (helpers.BridgeDNS, error) {
+	return m.Bridge, m.BridgeError
+}
+
+func (m *MockDNSManager) DetectDNSResolver(ctx context.Context) (string, error) {
+	return m.Resolver, m.ResolverError
+}
+
+func (m *MockDNSManager) SetupBridgeDNS(ctx context.Context, bridge helpers.BridgeDNS, resolver string) error {
+	m.SetupWith = resolver
+	return m.SetupError
+}
+
+func (m *MockDNSManager) TeardownBridgeDNS(ctx context.Context, network string) (bool, error) {
+	return m.Removed, nil
+}
+
+func (m *MockDNSManager) CheckBridgeDNS(ctx context.Context, network string, states []helpers.ContainerState) helpers.DNSCheck {
+	return m.Check
+}
+
+func TestSetupDNS(t *testing.T) {
+	manager := newMockDNSManager()
+	if err := setupDNS(context.Background(), manager, "lxdbr0", dnsResolverAuto); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.SetupWith != helpers.DNSResolverResolved {
+		t.Errorf("expected the detected resolver to be configured, got %q", manager.SetupWith)
+	}
+
+	manager = newMockDNSManager()
+	manager.ResolverError = errors.New("neither systemd-resolved nor dnsmasq is running on the host")
+	if err := setupDNS(context.Background(), manager, "lxdbr0", helpers.DNSResolverDnsmasq); err != nil || manager.SetupWith != helpers.DNSResolverDnsmasq {
+		t.Errorf("expected an explicit resolver to skip detection, got %v (%q)", err, manager.SetupWith)
+	}
+	err := setupDNS(context.Background(), manager, "lxdbr0", dnsResolverAuto)
+	if err == nil || !strings.Contains(err.Error(), "--resolver") {
+		t.Errorf("expected a hint to pick a resolver, got %v", err)
+	}
+
+	if err := setupDNS(context.Background(), newMockDNSManager(), "lxdbr0", "bind"); err == nil || !strings.Contains(err.Error(), "invalid resolver") {
+		t.Errorf("expected an unknown resolver to be rejected, got %v", err)
+	}
+
+	manager = newMockDNSManager()
+	manager.BridgeError = errors.New("network 'lxdbr0' has DNS turned off")
+	if err := setupDNS(context.Background(), manager, "lxdbr0", dnsResolverAuto); err == nil || manager.SetupWith != "" {
+		t.Errorf("expected nothing to be configured without bridge DNS, got %v", err)
+	}
+}
+
+func TestTeardownDNS(t *testing.T) {
+	manager := newMockDNSManager()
+	if err := teardownDNS(context.Background(), manager, "lxdbr0"); err != nil {
+		t.Errorf("expected nothing to remove to succeed, got %v", err)
+	}
+	manager.Removed = true
+	if err := teardownDNS(context.Background(), manager, "lxdbr0"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestDNSCommand(t *testing.T) {
+	if dnsSetupCmd.Flags().Lookup("resolver") == nil || dnsCmd.PersistentFlags().Lookup("network") == nil {
+		t.Error("resolver and network flags should exist")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dockerTimeout       time.Duration
+	dockerRegistry      string
+	dockerUsername      string
+	dockerPasswordStdin bool
+)
+
+// maxRegistryPassword bounds how much of stdin is read as a password or token
+const maxRegistryPassword = 64 * 1024
+
+// dockerCmd represents the docker command
+var dockerCmd = &cobra.Command{
+	Use:   "docker <login>",
+	Short: "Manage Docker inside LXC containers",
+	Long: `Manage the Docker installation inside a container.
+
+Available subcommands:
+  login - Log the 'app' user in to a container registry
+
+Examples:
+  echo "$GHCR_TOKEN" | lxc-go-cli docker login mycontainer --registry ghcr.io --username me --password-stdin`,
+}
+
+// dockerLoginCmd represents the docker login subcommand
+var dockerLoginCmd = &cobra.Command{
+	Use:   "login <container-name> --username <user> --password-stdin",
+	Short: "Log the app user in to a container registry",
+	Long: `Run 'docker login' as the 'app' user inside a container, so compose deploys
+can pull private images.
+
+The password or token is read from stdin and handed to docker on its stdin; it
+never appears in a command line, the process list or the logs. The registry
+defaults to Docker Hub. Docker stores the credentials in
+/home/app/.docker/config.json inside the container.
+
+Examples:
+  echo "$GHCR_TOKEN" | lxc-go-cli docker login mycontainer --registry ghcr.io --username me --password-stdin
+  lxc-go-cli docker login mycontainer --username me --password-stdin < token.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !dockerPasswordStdin {
+			return helpers.UsageErrorf("--password-stdin is required: pipe the password or token in so it stays off the command line")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), dockerTimeout)
+		defer cancel()
+
+		manager := &DefaultDockerLoginManager{}
+		return dockerLogin(ctx, manager, args[0], dockerRegistry, dockerUsername, cmd.InOrStdin())
+	},
+}
+
+// DockerLoginManager interface for dependency injection
+type DockerLoginManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	DockerLogin(ctx context.Context, containerName, registry, username string, password io.Reader) error
+}
+
+// DefaultDockerLoginManager implements DockerLoginManager using helpers
+type DefaultDockerLoginManager struct{}
+
+func (d *DefaultDockerLoginManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultDockerLoginManager) DockerLogin(ctx context.Context, containerName, registry, username string, password io.Reader) error {
+	return helpers.DockerLogin(ctx, containerName, registry, username, password)
+}
+
+// dockerLogin reads the password from stdin and logs the app user in
+func dockerLogin(ctx context.Context, manager DockerLoginManager, containerName, registry, username string, stdin io.Reader) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if err := helpers.ValidateRegistryLogin(registry, username); err != nil {
+		return err
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(stdin, maxRegistryPassword+1))
+	if err != nil {
+		return fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	if len(data) > maxRegistryPassword {
+		return fmt.Errorf("password on stdin is longer than %d bytes", maxRegistryPassword)
+	}
+	// Like docker, drop the newline left by echo or a file
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return fmt.Errorf("no password on stdin")
+	}
+
+	target := registry
+	if target == "" {
+		target = "Docker Hub"
+	}
+	logger.Info("Logging 'app' in to %s as '%s' in container '%s'...", target, username, containerName)
+	if err := manager.DockerLogin(ctx, containerName, registry, username, strings.NewReader(password+"\n")); err != nil {
+		return err
+	}
+
+	logger.Info("Logged in to %s", target)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dockerCmd)
+	dockerCmd.AddCommand(dockerLoginCmd)
+
+	dockerLoginCmd.Flags().StringVar(&dockerRegistry, "registry", "", "Registry to log in to (default: Docker Hub)")
+	dockerLoginCmd.Flags().StringVarP(&dockerUsername, "username", "u", "", "Registry user name (required)")
+	dockerLoginCmd.Flags().BoolVar(&dockerPasswordStdin, "password-stdin", false, "Read the password or token from stdin")
+	dockerLoginCmd.Flags().DurationVarP(&dockerTimeout, "timeout", "t", 60*time.Second, "Timeout for the login")
+	dockerLoginCmd.MarkFlagRequired("username")
+}
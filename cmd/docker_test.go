@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// MockDockerLoginManager for testing docker login
+type MockDockerLoginManager struct {
+	Existing map[string]bool
+	LoginErr error
+	Registry string
+	Username string
+	Password string
+	Calls    int
+}
+
+func (m *MockDockerLoginManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.Existing[name]
+}
+
+func (m *MockDockerLoginManager) DockerLogin(ctx context.Context, containerName, registry, username string, password io.Reader) error {
+	m.Calls++
+	data, err := io.ReadAll(password)
+	if err != nil {
+		return err
+	}
+	m.Registry, m.Username, m.Password = registry, username, string(data)
+	return m.LoginErr
+}
+
+func TestDockerLogin(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockDockerLoginManager{Existing: map[string]bool{"web": true}}
+
+	if err := dockerLogin(ctx, manager, "web", "ghcr.io", "octocat", strings.NewReader("ghp_secret\n")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Registry != "ghcr.io" || manager.Username != "octocat" || manager.Password != "ghp_secret\n" {
+		t.Errorf("unexpected login: %+v", manager)
+	}
+
+	tests := []struct {
+		name      string
+		container string
+		registry  string
+		username  string
+		stdin     string
+		expected  string
+	}{
+		{"missing container", "db", "", "octocat", "secret", "does not exist"},
+		{"empty password", "web", "", "octocat", "\n", "no password"},
+		{"no username", "web", "", "", "secret", "username is required"},
+		{"bad username", "web", "", "octo'cat", "secret", "invalid username"},
+		{"bad registry", "web", "https://ghcr.io", "octocat", "secret", "invalid registry"},
+		{"too long", "web", "", "octocat", strings.Repeat("x", maxRegistryPassword+1), "longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockDockerLoginManager{Existing: map[string]bool{"web": true}}
+			err := dockerLogin(ctx, manager, tt.container, tt.registry, tt.username, strings.NewReader(tt.stdin))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+			if manager.Calls != 0 {
+				t.Error("docker login should not run")
+			}
+		})
+	}
+
+	manager.LoginErr = fmt.Errorf("docker login failed: exit status 1 (output: unauthorized)")
+	if err := dockerLogin(ctx, manager, "web", "", "octocat", strings.NewReader("wrong")); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("expected login error, got %v", err)
+	}
+}
+
+func TestDockerLoginCommand(t *testing.T) {
+	if dockerLoginCmd.Flags().Lookup("password-stdin") == nil || dockerLoginCmd.Flags().Lookup("registry") == nil {
+		t.Error("expected --password-stdin and --registry flags")
+	}
+	// There is deliberately no way to pass the password as an argument
+	if dockerLoginCmd.Flags().Lookup("password") != nil {
+		t.Error("docker login must not accept --password")
+	}
+}
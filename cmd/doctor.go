@@ -0,0 +1,519 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorTimeout  time.Duration
+	doctorFixIdmap bool
+	doctorDeep     bool
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [container-name]",
+	Short: "Check the host and a container for common problems",
+	Long: `Check the host for common problems that prevent containers from working.
+
+Checks performed:
+  - The lxc client is installed and can reach the LXD daemon
+  - Btrfs storage pools have enough metadata space left
+  - root and the LXD daemon user have /etc/subuid and /etc/subgid ranges big
+    enough for Docker, and containers sharing a host folder have a raw.idmap
+  - With the Vault password store, Vault is reachable, unsealed and accepts
+    the token
+  - After 'dns setup', a running container's <container>.lxd name resolves
+    from the host
+
+Given a container, it also checks that the container has the settings Docker
+needs and that the Docker daemon in it answers. --deep runs commands in the
+container to find the problems that commonly break Docker inside LXD:
+  - cgroup v2 with the cpu, memory and pids controllers delegated, and the
+    container may create cgroups
+  - overlay filesystems can be mounted, and Docker does not use vfs
+  - iptables, and nftables when installed, work
+  - the container, and a container started by Docker in it, resolve names;
+    this runs the ` + helpers.DockerProbeImage + ` image
+  - Docker's and containerd's recent logs show no known failure
+
+Each problem is reported with a suggested fix. The command exits with an error
+if any check fails.
+
+With --fix-idmap the missing subordinate id ranges are appended to
+/etc/subuid and /etc/subgid and raw.idmap is set on containers sharing a host
+folder, mapping the folder's owner to the 'app' user. New ranges avoid the
+ids of other users. The changes are listed and, on a terminal, confirmed
+before they are made (--yes skips the question). Run it as root, then restart
+the daemon and the affected containers. The LXD snap allocates container ids
+itself, so with it only raw.idmap is checked.
+
+Example:
+  lxc-go-cli doctor
+  lxc-go-cli doctor web --deep
+  sudo lxc-go-cli doctor --fix-idmap`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		defer cancel()
+
+		containerName := ""
+		if len(args) > 0 {
+			containerName = resolveContainerName(args[0])
+		} else if doctorDeep {
+			return helpers.UsageErrorf("--deep needs a container name")
+		}
+
+		manager := &DefaultDoctorManager{}
+		if doctorFixIdmap {
+			if err := fixIdmap(ctx, manager, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		}
+		return runDoctor(ctx, manager, containerName, doctorDeep, cmd.OutOrStdout())
+	},
+}
+
+// CheckStatus is the outcome of a single doctor check
+type CheckStatus int
+
+const (
+	CheckPass CheckStatus = iota
+	CheckWarn
+	CheckFail
+)
+
+// String returns the string representation of CheckStatus
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckPass:
+		return "PASS"
+	case CheckWarn:
+		return "WARN"
+	case CheckFail:
+		return "FAIL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CheckResult is the result of a single doctor check
+type CheckResult struct {
+	Name        string
+	Status      CheckStatus
+	Message     string
+	Remediation string
+}
+
+// DoctorManager interface for dependency injection
+type DoctorManager interface {
+	CheckLXCAvailable(ctx context.Context) error
+	ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error)
+	GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error)
+	PlanIdmapFixes(ctx context.Context) ([]helpers.IdmapFix, error)
+	ApplyIdmapFixes(ctx context.Context, fixes []helpers.IdmapFix) error
+	CheckPasswordStore(ctx context.Context) (string, error)
+	GetContainerStatus(ctx context.Context, name string) (string, error)
+	CheckDocker(ctx context.Context, name string, deep bool) []helpers.DockerCheck
+	CheckBridgeDNS(ctx context.Context) []helpers.DNSCheck
+}
+
+// DefaultDoctorManager implements DoctorManager using helpers
+type DefaultDoctorManager struct{}
+
+func (d *DefaultDoctorManager) CheckLXCAvailable(ctx context.Context) error {
+	return helpers.CheckLXCAvailable()
+}
+
+func (d *DefaultDoctorManager) ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error) {
+	return helpers.ListStoragePools()
+}
+
+func (d *DefaultDoctorManager) GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error) {
+	return helpers.GetBtrfsUsage(pool)
+}
+
+func (d *DefaultDoctorManager) PlanIdmapFixes(ctx context.Context) ([]helpers.IdmapFix, error) {
+	// /etc/subuid and host folder owners describe this machine, not a remote server
+	if err := helpers.RequireLocalServer(ctx, "idmap checks"); err != nil {
+		return nil, err
+	}
+	return helpers.PlanIdmapFixes(ctx)
+}
+
+func (d *DefaultDoctorManager) ApplyIdmapFixes(ctx context.Context, fixes []helpers.IdmapFix) error {
+	return helpers.ApplyIdmapFixes(fixes)
+}
+
+// CheckPasswordStore checks the configured password store and returns its name
+func (d *DefaultDoctorManager) CheckPasswordStore(ctx context.Context) (string, error) {
+	store := helpers.ActivePasswordStore()
+	return store.Name(), store.Check(ctx)
+}
+
+func (d *DefaultDoctorManager) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	return helpers.GetContainerStatus(name)
+}
+
+// CheckDocker checks Docker in a container, with deep running the probes
+// that exec into it
+func (d *DefaultDoctorManager) CheckDocker(ctx context.Context, name string, deep bool) []helpers.DockerCheck {
+	checks := helpers.CheckDockerConfig(ctx, name)
+	if deep {
+		checks = append(checks, helpers.DeepCheckDocker(ctx, name)...)
+	}
+	return checks
+}
+
+// CheckBridgeDNS checks every network 'dns setup' configured on this host
+func (d *DefaultDoctorManager) CheckBridgeDNS(ctx context.Context) []helpers.DNSCheck {
+	configured := helpers.ConfiguredBridgeDNS()
+	if len(configured) == 0 {
+		return nil
+	}
+	networks := make([]string, 0, len(configured))
+	for network := range configured {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+
+	states, err := helpers.ListContainers()
+	if err != nil {
+		return []helpers.DNSCheck{{Network: strings.Join(networks, ", "), Warning: true, Message: fmt.Sprintf("could not list containers: %v", err)}}
+	}
+	checks := make([]helpers.DNSCheck, 0, len(networks))
+	for _, network := range networks {
+		checks = append(checks, helpers.CheckBridgeDNS(ctx, network, states))
+	}
+	return checks
+}
+
+// doctorCheck runs one group of checks and returns their results
+type doctorCheck func(ctx context.Context, manager DoctorManager) []CheckResult
+
+// doctorChecks lists the checks run by the doctor command, in order
+var doctorChecks = []doctorCheck{
+	checkLXCClient,
+	checkBtrfsMetadata,
+	checkIdmap,
+	checkPasswordStore,
+	checkBridgeDNS,
+}
+
+// runDoctor runs all checks, and those of a container if one is given, and
+// prints a report
+func runDoctor(ctx context.Context, manager DoctorManager, containerName string, deep bool, out io.Writer) error {
+	checks := doctorChecks
+	if containerName != "" {
+		checks = append(checks[:len(checks):len(checks)], containerDockerCheck(containerName, deep))
+	}
+
+	var results []CheckResult
+	for _, check := range checks {
+		results = append(results, check(ctx, manager)...)
+	}
+
+	fmt.Fprint(out, formatCheckResults(results, render.StyleFor(out)))
+
+	if failed := countFailedChecks(results); failed > 0 {
+		return i18n.Errorf("checks.failed", failed)
+	}
+	return nil
+}
+
+// checkLXCClient verifies the lxc client can talk to LXD
+func checkLXCClient(ctx context.Context, manager DoctorManager) []CheckResult {
+	if err := manager.CheckLXCAvailable(ctx); err != nil {
+		remediation := "install LXD (sudo snap install lxd && sudo lxd init) and make sure your user is in the 'lxd' group"
+		var unavailable *helpers.LXCUnavailableError
+		if errors.As(err, &unavailable) {
+			remediation = unavailable.Hint
+		}
+		return []CheckResult{{
+			Name:        "lxc client",
+			Status:      CheckFail,
+			Message:     err.Error(),
+			Remediation: remediation,
+		}}
+	}
+	return []CheckResult{{Name: "lxc client", Status: CheckPass, Message: "lxc client can reach the LXD daemon"}}
+}
+
+// checkBtrfsMetadata warns about Btrfs pools that are close to running out of metadata space
+func checkBtrfsMetadata(ctx context.Context, manager DoctorManager) []CheckResult {
+	pools, err := manager.ListStoragePools(ctx)
+	if err != nil {
+		return []CheckResult{{Name: "storage pools", Status: CheckWarn, Message: fmt.Sprintf("could not list storage pools: %v", err)}}
+	}
+
+	var results []CheckResult
+	for _, pool := range pools {
+		if pool.Driver != "btrfs" {
+			continue
+		}
+		name := fmt.Sprintf("btrfs metadata (%s)", pool.Name)
+
+		usage, err := manager.GetBtrfsUsage(ctx, pool.Name)
+		if err != nil {
+			results = append(results, CheckResult{
+				Name:        name,
+				Status:      CheckWarn,
+				Message:     fmt.Sprintf("could not read usage: %v", err),
+				Remediation: "run 'lxc-go-cli doctor' as root, or with --sudo, to inspect Btrfs pools",
+			})
+			continue
+		}
+
+		if usage.MetadataNearlyExhausted() {
+			results = append(results, CheckResult{
+				Name:        name,
+				Status:      CheckWarn,
+				Message:     fmt.Sprintf("metadata is %.1f%% used with %s unallocated", usage.MetadataPercent(), helpers.FormatBytes(usage.DeviceUnallocated)),
+				Remediation: fmt.Sprintf("lxc-go-cli storage maintain %s --balance", pool.Name),
+			})
+			continue
+		}
+
+		results = append(results, CheckResult{
+			Name:    name,
+			Status:  CheckPass,
+			Message: fmt.Sprintf("metadata is %.1f%% used", usage.MetadataPercent()),
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckResult{Name: "btrfs metadata", Status: CheckPass, Message: "no Btrfs storage pools found"})
+	}
+	return results
+}
+
+// checkIdmap warns about missing subordinate id ranges and host folder
+// mounts without a raw.idmap, which break Docker and file ownership
+func checkIdmap(ctx context.Context, manager DoctorManager) []CheckResult {
+	fixes, err := manager.PlanIdmapFixes(ctx)
+	if err != nil {
+		return []CheckResult{{Name: "idmap", Status: CheckWarn, Message: fmt.Sprintf("could not check id mappings: %v", err)}}
+	}
+	if len(fixes) == 0 {
+		return []CheckResult{{Name: "idmap", Status: CheckPass, Message: "subordinate ids and host folder mappings are configured"}}
+	}
+
+	var results []CheckResult
+	for _, fix := range fixes {
+		name := "idmap"
+		if fix.Container != "" {
+			name = fmt.Sprintf("idmap (%s)", fix.Container)
+		}
+		results = append(results, CheckResult{
+			Name:        name,
+			Status:      CheckWarn,
+			Message:     fix.Reason,
+			Remediation: "sudo lxc-go-cli doctor --fix-idmap",
+		})
+	}
+	return results
+}
+
+// checkPasswordStore verifies that the backend 'app' passwords are kept in
+// can be used, so create does not fail to store one
+func checkPasswordStore(ctx context.Context, manager DoctorManager) []CheckResult {
+	backend, err := manager.CheckPasswordStore(ctx)
+	if err != nil {
+		return []CheckResult{{
+			Name:        "password store",
+			Status:      CheckFail,
+			Message:     fmt.Sprintf("%s: %v", backend, err),
+			Remediation: fmt.Sprintf("set %s and %s, or fix password_store in %s", helpers.VaultAddrEnvVar, helpers.VaultTokenEnvVar, helpers.SettingsPath()),
+		}}
+	}
+	if backend == helpers.PasswordStoreVault {
+		return []CheckResult{{Name: "password store", Status: CheckPass, Message: "Vault is reachable and the token is valid"}}
+	}
+	return []CheckResult{{Name: "password store", Status: CheckPass, Message: "passwords are stored in container metadata"}}
+}
+
+// checkBridgeDNS verifies that container names resolve from the host on
+// the networks 'dns setup' configured; it reports nothing without them
+func checkBridgeDNS(ctx context.Context, manager DoctorManager) []CheckResult {
+	var results []CheckResult
+	for _, check := range manager.CheckBridgeDNS(ctx) {
+		result := CheckResult{
+			Name:        fmt.Sprintf("dns (%s)", check.Network),
+			Status:      CheckFail,
+			Message:     check.Message,
+			Remediation: check.Fix,
+		}
+		switch {
+		case check.OK:
+			result.Status = CheckPass
+		case check.Warning:
+			result.Status = CheckWarn
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// containerDockerCheck returns the check of Docker in a container
+func containerDockerCheck(containerName string, deep bool) doctorCheck {
+	return func(ctx context.Context, manager DoctorManager) []CheckResult {
+		status, err := manager.GetContainerStatus(ctx, containerName)
+		if err != nil {
+			return []CheckResult{{Name: containerName, Status: CheckFail, Message: containerNotFound(containerName).Error()}}
+		}
+		if status != "Running" {
+			return []CheckResult{{
+				Name:        containerName,
+				Status:      CheckFail,
+				Message:     fmt.Sprintf("container is %s; Docker can only be checked in a running container", strings.ToLower(status)),
+				Remediation: fmt.Sprintf("lxc start %s", containerName),
+			}}
+		}
+
+		var results []CheckResult
+		for _, check := range manager.CheckDocker(ctx, containerName, deep) {
+			result := CheckResult{
+				Name:        fmt.Sprintf("%s (%s)", check.Name, containerName),
+				Status:      CheckFail,
+				Message:     check.Message,
+				Remediation: check.Fix,
+			}
+			switch {
+			case check.OK:
+				result.Status = CheckPass
+			case check.Warning:
+				result.Status = CheckWarn
+			}
+			results = append(results, result)
+		}
+		return results
+	}
+}
+
+// fixIdmap explains the planned id mapping changes, then makes them
+func fixIdmap(ctx context.Context, manager DoctorManager, out io.Writer) error {
+	fixes, err := manager.PlanIdmapFixes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check id mappings: %w", err)
+	}
+	if len(fixes) == 0 {
+		fmt.Fprintln(out, "Id mappings are already configured; nothing to change.")
+		return nil
+	}
+
+	fmt.Fprintln(out, "The following id mapping changes will be made:")
+	restartDaemon := false
+	var containers []string
+	for _, fix := range fixes {
+		fmt.Fprintf(out, "  - %s\n", fix)
+		if fix.Container != "" {
+			containers = append(containers, fix.Container)
+		} else {
+			restartDaemon = true
+		}
+	}
+	if err := confirm("doctor.fix_idmap_confirm", len(fixes)); err != nil {
+		return err
+	}
+
+	if err := manager.ApplyIdmapFixes(ctx, fixes); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Id mappings updated.")
+	if restartDaemon {
+		restart := "sudo snap restart lxd (or sudo systemctl restart lxd)"
+		if helpers.CurrentDriver() == helpers.DriverIncus {
+			restart = "sudo systemctl restart incus"
+		}
+		fmt.Fprintf(out, "Restart the daemon to use the new ranges: %s\n", restart)
+	}
+	for _, name := range containers {
+		fmt.Fprintf(out, "Restart '%s' to apply its raw.idmap: %s restart %s\n", name, helpers.ClientBinary(), name)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// countFailedChecks returns the number of failed checks
+func countFailedChecks(results []CheckResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Status == CheckFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// mark returns the symbol and color that stand for the status on terminals
+func (s CheckStatus) mark() (string, render.Color) {
+	switch s {
+	case CheckPass:
+		return render.SymbolPass, render.Green
+	case CheckWarn:
+		return render.SymbolWarn, render.Yellow
+	default:
+		return render.SymbolFail, render.Red
+	}
+}
+
+// checkMark returns a check mark or cross followed by a space on terminals,
+// and nothing in plain output
+func checkMark(style render.Style, ok bool) string {
+	if !style.Symbols {
+		return ""
+	}
+	status := CheckPass
+	if !ok {
+		status = CheckFail
+	}
+	symbol, color := status.mark()
+	return style.Paint(color, symbol) + " "
+}
+
+// formatCheckResults formats check results for display: "[PASS]" and the
+// like in plain output, colored check marks and crosses on terminals
+func formatCheckResults(results []CheckResult, style render.Style) string {
+	var result strings.Builder
+
+	for _, check := range results {
+		label, indent := fmt.Sprintf("[%s]", check.Status), "       "
+		if style.Symbols {
+			symbol, color := check.Status.mark()
+			label, indent = style.Paint(color, symbol), "  "
+		}
+		result.WriteString(fmt.Sprintf("%s %s: %s\n", label, check.Name, check.Message))
+		if check.Status != CheckPass && check.Remediation != "" {
+			// Line up multi-line remediations under the first line
+			remediation := strings.ReplaceAll(check.Remediation, "\n", "\n"+indent+"     ")
+			result.WriteString(fmt.Sprintf("%sfix: %s\n", indent, remediation))
+		}
+	}
+
+	return result.String()
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	// doctor reports a missing lxc itself, alongside its other checks
+	skipLXCCheck(doctorCmd)
+
+	doctorCmd.Flags().DurationVarP(&doctorTimeout, "timeout", "t", 60*time.Second, "Timeout for the checks")
+	doctorCmd.Flags().BoolVar(&doctorFixIdmap, "fix-idmap", false, "Add missing /etc/subuid and /etc/subgid ranges and raw.idmap settings")
+	doctorCmd.Flags().BoolVar(&doctorDeep, "deep", false, "Run commands in the container to find what breaks Docker in it")
+}
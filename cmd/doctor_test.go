@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockDoctorManager for testing doctor command
+type MockDoctorManager struct {
+	LXCError   error
+	Pools      []helpers.StoragePool
+	PoolsError error
+	Usage      map[string]*helpers.BtrfsUsage
+	UsageError error
+	IdmapFixes []helpers.IdmapFix
+	IdmapError error
+	Applied    []helpers.IdmapFix
+	StoreName  string
+	StoreError error
+
+	Status       string
+	StatusError  error
+	DockerChecks []helpers.DockerCheck
+	DeepChecked  bool
+	DNSChecks    []helpers.DNSCheck
+}
+
+func (m *MockDoctorManager) CheckLXCAvailable(ctx context.Context) error {
+	return m.LXCError
+}
+
+func (m *MockDoctorManager) ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error) {
+	if m.PoolsError != nil {
+		return nil, m.PoolsError
+	}
+	return m.Pools, nil
+}
+
+func (m *MockDoctorManager) GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error) {
+	if m.UsageError != nil {
+		return nil, m.UsageError
+	}
+	if usage, ok := m.Usage[pool]; ok {
+		return usage, nil
+	}
+	return &helpers.BtrfsUsage{}, nil
+}
+
+func (m *MockDoctorManager) PlanIdmapFixes(ctx context.Context) ([]helpers.IdmapFix, error) {
+	if m.IdmapError != nil {
+		return nil, m.IdmapError
+	}
+	return m.IdmapFixes, nil
+}
+
+func (m *MockDoctorManager) ApplyIdmapFixes(ctx context.Context, fixes []helpers.IdmapFix) error {
+	m.Applied = append(m.Applied, fixes...)
+	return nil
+}
+
+func (m *MockDoctorManager) CheckPasswordStore(ctx context.Context) (string, error) {
+	if m.StoreName == "" {
+		return helpers.PasswordStoreMetadata, m.StoreError
+	}
+	return m.StoreName, m.StoreError
+}
+
+func (m *MockDoctorManager) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	return m.Status, m.StatusError
+}
+
+func (m *MockDoctorManager) CheckDocker(ctx context.Context, name string, deep bool) []helpers.DockerCheck {
+	m.DeepChecked = deep
+	return m.DockerChecks
+}
+
+func (m *MockDoctorManager) CheckBridgeDNS(ctx context.Context) []helpers.DNSCheck {
+	return m.DNSChecks
+}
+
+func TestDoctorCommand(t *testing.T) {
+	if doctorCmd == nil {
+		t.Fatal("doctorCmd should not be nil")
+	}
+	if doctorCmd.Use != "doctor [container-name]" {
+		t.Errorf("expected Use to be 'doctor [container-name]', got '%s'", doctorCmd.Use)
+	}
+	if doctorCmd.Flags().Lookup("timeout") == nil {
+		t.Error("timeout flag should exist")
+	}
+	if doctorCmd.Flags().Lookup("fix-idmap") == nil {
+		t.Error("fix-idmap flag should exist")
+	}
+	if doctorCmd.Flags().Lookup("deep") == nil {
+		t.Error("deep flag should exist")
+	}
+}
+
+func TestCheckStatusString(t *testing.T) {
+	if CheckPass.String() != "PASS" || CheckWarn.String() != "WARN" || CheckFail.String() != "FAIL" {
+		t.Error("unexpected check status strings")
+	}
+	if CheckStatus(99).String() != "UNKNOWN" {
+		t.Error("expected UNKNOWN for invalid status")
+	}
+}
+
+func TestFormatCheckResults(t *testing.T) {
+	results := []CheckResult{
+		{Name: "lxc", Status: CheckPass, Message: "lxc 5.21"},
+		{Name: "idmap", Status: CheckFail, Message: "no ranges", Remediation: "step one\nstep two"},
+	}
+
+	plain := "[PASS] lxc: lxc 5.21\n" +
+		"[FAIL] idmap: no ranges\n" +
+		"       fix: step one\n" +
+		"            step two\n"
+	if got := formatCheckResults(results, render.Style{}); got != plain {
+		t.Errorf("expected:\n%q\ngot:\n%q", plain, got)
+	}
+
+	symbols := "✔ lxc: lxc 5.21\n" +
+		"✖ idmap: no ranges\n" +
+		"  fix: step one\n" +
+		"       step two\n"
+	if got := formatCheckResults(results, render.Style{Symbols: true}); got != symbols {
+		t.Errorf("expected:\n%q\ngot:\n%q", symbols, got)
+	}
+
+	colored := formatCheckResults(results, render.Style{Symbols: true, Color: true})
+	if !strings.Contains(colored, "\x1b[32m✔\x1b[0m lxc") || !strings.Contains(colored, "\x1b[31m✖\x1b[0m idmap") {
+		t.Errorf("expected colored marks, got %q", colored)
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	tests := []struct {
+		name           string
+		manager        *MockDoctorManager
+		container      string
+		deep           bool
+		expectedError  string
+		expectedOutput []string
+	}{
+		{
+			name:           "all checks pass",
+			manager:        &MockDoctorManager{Pools: []helpers.StoragePool{{Name: "fast", Driver: "btrfs"}}},
+			expectedOutput: []string{"[PASS] lxc client", "[PASS] btrfs metadata (fast)"},
+		},
+		{
+			name:           "lxc missing fails",
+			manager:        &MockDoctorManager{LXCError: fmt.Errorf("lxc client not found in PATH")},
+			expectedError:  "1 check(s) failed",
+			expectedOutput: []string{"[FAIL] lxc client: lxc client not found in PATH", "fix: install LXD"},
+		},
+		{
+			name: "metadata nearly exhausted warns",
+			manager: &MockDoctorManager{
+				Pools: []helpers.StoragePool{{Name: "fast", Driver: "btrfs"}, {Name: "default", Driver: "dir"}},
+				Usage: map[string]*helpers.BtrfsUsage{"fast": {MetadataSize: 100, MetadataUsed: 96}},
+			},
+			expectedOutput: []string{"[WARN] btrfs metadata (fast): metadata is 96.0% used", "storage maintain fast --balance"},
+		},
+		{
+			name:           "unreadable usage warns",
+			manager:        &MockDoctorManager{Pools: []helpers.StoragePool{{Name: "fast", Driver: "btrfs"}}, UsageError: fmt.Errorf("permission denied")},
+			expectedOutput: []string{"[WARN] btrfs metadata (fast): could not read usage"},
+		},
+		{
+			name:           "no btrfs pools",
+			manager:        &MockDoctorManager{},
+			expectedOutput: []string{"no Btrfs storage pools found", "[PASS] idmap"},
+		},
+		{
+			name: "missing idmap warns",
+			manager: &MockDoctorManager{IdmapFixes: []helpers.IdmapFix{
+				{File: "/etc/subuid", Line: "root:1000000:1000000000", Reason: "root has no subordinate ids"},
+				{Container: "web", RawIdmap: "both 1000 1000", Reason: "/srv/web is shared"},
+			}},
+			expectedOutput: []string{"[WARN] idmap: root has no subordinate ids", "[WARN] idmap (web): /srv/web is shared", "doctor --fix-idmap"},
+		},
+		{
+			name:           "unreadable idmap warns",
+			manager:        &MockDoctorManager{IdmapError: fmt.Errorf("permission denied")},
+			expectedOutput: []string{"[WARN] idmap: could not check id mappings"},
+		},
+		{
+			name:           "vault password store passes",
+			manager:        &MockDoctorManager{StoreName: helpers.PasswordStoreVault},
+			expectedOutput: []string{"[PASS] password store: Vault is reachable"},
+		},
+		{
+			name:           "sealed vault fails",
+			manager:        &MockDoctorManager{StoreName: helpers.PasswordStoreVault, StoreError: fmt.Errorf("Vault at https://vault:8200 is sealed")},
+			expectedError:  "1 check(s) failed",
+			expectedOutput: []string{"[FAIL] password store: vault: Vault at https://vault:8200 is sealed", "VAULT_TOKEN"},
+		},
+		{
+			name: "bridge dns checks",
+			manager: &MockDoctorManager{DNSChecks: []helpers.DNSCheck{
+				{Network: "lxdbr0", OK: true, Message: "web.lxd resolves to 10.0.0.2"},
+				{Network: "lxdbr1", Message: "db.lxd does not resolve from the host", Fix: "lxc-go-cli dns setup --network lxdbr1"},
+			}},
+			expectedError: "1 check(s) failed",
+			expectedOutput: []string{
+				"[PASS] dns (lxdbr0): web.lxd resolves to 10.0.0.2",
+				"[FAIL] dns (lxdbr1): db.lxd does not resolve", "fix: lxc-go-cli dns setup --network lxdbr1",
+			},
+		},
+		{
+			name: "deep container checks",
+			manager: &MockDoctorManager{Status: "Running", DockerChecks: []helpers.DockerCheck{
+				{Name: "docker daemon", OK: true, Message: "Docker 27.3.1 is running"},
+				{Name: "overlayfs", Message: "overlay mounts are not allowed", Fix: "upgrade the host kernel"},
+				{Name: "dns", Warning: true, Message: "could not pull busybox:stable"},
+			}},
+			container:     "web",
+			deep:          true,
+			expectedError: "1 check(s) failed",
+			expectedOutput: []string{
+				"[PASS] docker daemon (web): Docker 27.3.1 is running",
+				"[FAIL] overlayfs (web): overlay mounts are not allowed", "fix: upgrade the host kernel",
+				"[WARN] dns (web): could not pull",
+			},
+		},
+		{
+			name:           "stopped container fails",
+			manager:        &MockDoctorManager{Status: "Stopped"},
+			container:      "web",
+			expectedError:  "1 check(s) failed",
+			expectedOutput: []string{"[FAIL] web: container is stopped", "fix: lxc start web"},
+		},
+		{
+			name:           "missing container fails",
+			manager:        &MockDoctorManager{StatusError: fmt.Errorf("not found")},
+			container:      "web",
+			expectedError:  "1 check(s) failed",
+			expectedOutput: []string{"[FAIL] web: container 'web' does not exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runDoctor(context.Background(), tt.manager, tt.container, tt.deep, &out)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			for _, expected := range tt.expectedOutput {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+				}
+			}
+			if tt.manager.DeepChecked != tt.deep {
+				t.Errorf("expected deep checks to be %v", tt.deep)
+			}
+		})
+	}
+}
+
+func TestDefaultDoctorManager(t *testing.T) {
+	// Test that DefaultDoctorManager implements DoctorManager interface
+	var manager DoctorManager = &DefaultDoctorManager{}
+	_ = manager
+}
+
+func TestFixIdmap(t *testing.T) {
+	fixes := []helpers.IdmapFix{
+		{File: "/etc/subuid", Line: "root:1000:1", Reason: "raw.idmap can only map host id 1000 if root may use it"},
+		{Container: "web", RawIdmap: "both 1000 1000", Reason: "/srv/web is shared"},
+	}
+	manager := &MockDoctorManager{IdmapFixes: fixes}
+
+	var out bytes.Buffer
+	if err := fixIdmap(context.Background(), manager, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Applied) != 2 {
+		t.Errorf("expected both fixes to be applied, got %v", manager.Applied)
+	}
+	for _, expected := range []string{"append 'root:1000:1' to /etc/subuid", "set raw.idmap on 'web'", "Restart the daemon", "restart web"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+		}
+	}
+
+	// Declining the confirmation changes nothing
+	useConfirmAnswer(t, "n\n")
+	manager = &MockDoctorManager{IdmapFixes: fixes}
+	if err := fixIdmap(context.Background(), manager, &bytes.Buffer{}); !errors.Is(err, errAborted) || len(manager.Applied) != 0 {
+		t.Errorf("expected an aborted fix with nothing applied, got %v and %v", err, manager.Applied)
+	}
+
+	manager = &MockDoctorManager{}
+	out.Reset()
+	if err := fixIdmap(context.Background(), manager, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Applied) != 0 || !strings.Contains(out.String(), "nothing to change") {
+		t.Errorf("expected nothing to be applied, got %v and output %q", manager.Applied, out.String())
+	}
+}
@@ -5,9 +5,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/deji/lxc-go-cli/internal/helpers"
@@ -16,36 +21,278 @@ import (
 )
 
 var (
-	execTimeout time.Duration
+	execTimeout  time.Duration
+	execAll      bool
+	execSelector string
+	execParallel int
+	execNoTTY    bool
+	execCapture  bool
+	execAgent    bool
+	execEnvFile  string
+	execUser     string
+	execRoot     bool
+	execLogin    bool
+	execRecord   string
 )
 
 // execCmd represents the exec command
 var execCmd = &cobra.Command{
-	Use:   "exec <container-name>",
+	Use:   "exec [container-name]",
 	Short: "Execute an interactive shell in an LXC container as app user",
 	Long: `Execute an interactive shell in an LXC container as the 'app' user.
 This command runs 'lxc exec <container-name> -- su - app' to provide
 an interactive shell session in the specified container as the app user with proper environment and group memberships.
 
-Example:
-  lxc-go-cli exec mycontainer`,
-	Args: cobra.ExactArgs(1),
+Without a container name the current container chosen with 'use' is used.
+
+Shells and commands run as the 'app' user, or as the user set with exec.user
+in the config file. --user picks another user and --root runs as root, e.g. for
+package upgrades. Shells are always login shells; commands start in the
+user's home directory and get the user's environment but do not source its
+profile unless --login is given (exec.login in the config file makes that the
+default).
+
+When a command is given after '--', it is run instead of a shell. Pass a
+comma-separated list of containers, or --all for every running managed
+container, to run the command on each of them concurrently. --selector narrows
+--all to the containers whose labels match (see 'label'). Output lines are
+prefixed with the container name and a per-container exit code summary is
+printed at the end. In this mode --timeout only applies when set explicitly.
+
+For CI pipelines and scripts where stdin is not a terminal:
+  --no-tty   runs the shell without allocating a terminal, reading commands from stdin
+  --capture  runs the command on a single container without a terminal or output
+             prefixes, keeping stdout and stderr separate and exiting with the
+             command's exit code
+
+--forward-agent makes the host's SSH agent (SSH_AUTH_SOCK) usable in the
+session, so git over SSH works without copying keys into the container. A
+temporary proxy device exposes the agent socket inside the container for the
+app user and is removed when the session ends. It works with a single
+container on a local LXD server.
+
+--env-file loads variables from a .env file on the host into the session of a
+single container. They are passed on stdin, never on a command line, into a
+root-only file on the container's /run tmpfs that is deleted as soon as the
+session has read it.
+
+--record saves the session of a single container, a shell or a command, as an
+asciinema v2 recording to play back with 'asciinema play' or share, e.g. to
+document a setup procedure or show a problem. It records what is printed, not
+what is typed, although typed characters that are echoed (not passwords)
+appear in the output. The session always gets a terminal.
+
+Examples:
+  lxc-go-cli exec mycontainer
+  lxc-go-cli exec web1,web2,web3 -- apt-get update
+  lxc-go-cli exec --all --parallel 4 --root -- apt-get upgrade -y
+  lxc-go-cli exec mycontainer --login -- docker compose ps
+  lxc-go-cli exec --all --selector env=prod -- docker compose pull
+  echo 'docker ps' | lxc-go-cli exec mycontainer --no-tty
+  lxc-go-cli exec mycontainer --capture -- docker compose ps --format json > ps.json
+  lxc-go-cli exec mycontainer --forward-agent -- git clone git@github.com:me/app.git
+  lxc-go-cli exec mycontainer --env-file .env -- ./migrate.sh
+  lxc-go-cli exec mycontainer --record setup.cast`,
+	Args: validateExecArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		containerName := args[0]
+		manager := &DefaultContainerExecManager{}
+
+		dash := cmd.ArgsLenAtDash()
+		if !execAll && (len(args) == 0 || dash == 0) {
+			name, err := containerArg(nil)
+			if err != nil {
+				return err
+			}
+			args = append([]string{name}, args...)
+			if dash == 0 {
+				dash = 1
+			}
+		} else if !execAll && !strings.Contains(args[0], ",") {
+			args[0] = resolveContainerName(args[0])
+		}
+
+		settings, err := helpers.LoadSettings()
+		if err != nil {
+			return err
+		}
+		identity, err := resolveExecIdentity(settings, execUser, execRoot, execLogin, cmd.Flags().Changed("login"))
+		if err != nil {
+			return err
+		}
 
-		// Create context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+		var session execSession
+		if execAgent || execEnvFile != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+			defer cancel()
+
+			session, err = startExecSession(ctx, manager, args[0], identity.User, execAgent, execEnvFile)
+			defer session.stop(manager, args[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		if execRecord != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+			defer cancel()
+
+			command := session.shell(identity.User)
+			if dash >= 0 {
+				command = session.command(identity, args[dash:])
+			}
+			width, height := helpers.TerminalSize(ctx)
+			header := helpers.CastHeader{
+				Width:  width,
+				Height: height,
+				Title:  "lxc-go-cli exec " + args[0],
+				Env:    map[string]string{"TERM": os.Getenv("TERM")},
+			}
+			// A failing command is reported through the exit code, not usage help
+			cmd.SilenceUsage = true
+			return execRecorded(ctx, manager, args[0], command, execRecord, header, os.Stdout)
+		}
+
+		if dash < 0 && !execNoTTY {
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+			defer cancel()
+
+			if session.active() || identity.User != helpers.DefaultExecUser {
+				return execInteractiveCommand(ctx, manager, args[0], identity.User, session.shell(identity.User))
+			}
+			return execContainer(ctx, manager, args[0])
+		}
+
+		// Long-running fleet commands such as upgrades should not be cut off by the
+		// interactive default, so only honour an explicitly requested timeout
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 		defer cancel()
+		if cmd.Flags().Changed("timeout") {
+			var timeoutCancel context.CancelFunc
+			ctx, timeoutCancel = context.WithTimeout(ctx, execTimeout)
+			defer timeoutCancel()
+		}
 
-		manager := &DefaultContainerExecManager{}
-		return execContainer(ctx, manager, containerName)
+		streams := execStreams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+		if dash < 0 {
+			return execNonInteractive(ctx, manager, args[0], session.shell(identity.User), streams)
+		}
+		command := session.command(identity, args[dash:])
+		if execCapture || session.active() {
+			// A failing command is reported through the exit code, not usage help
+			cmd.SilenceUsage = true
+			return execNonInteractive(ctx, manager, args[0], command, streams)
+		}
+
+		var targets []string
+		if !execAll {
+			targets = splitContainerList(args[0])
+		}
+		selector, err := helpers.ParseLabelSelector(execSelector)
+		if err != nil {
+			return err
+		}
+		return broadcastExec(ctx, manager, targets, selector, command, execParallel, os.Stdout)
 	},
 }
 
+// validateExecArgs accepts `exec <name>`, `exec <names> -- <command...>` and
+// `exec --all -- <command...>`; the name may be omitted to use the current container
+func validateExecArgs(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if execSelector != "" && !execAll {
+		return helpers.UsageErrorf("--selector requires --all")
+	}
+	if execRoot && execUser != "" {
+		return helpers.UsageErrorf("--root cannot be combined with --user")
+	}
+	for flag, set := range map[string]bool{"--forward-agent": execAgent, "--env-file": execEnvFile != ""} {
+		if !set {
+			continue
+		}
+		if execAll {
+			return fmt.Errorf("%s cannot be combined with --all", flag)
+		}
+		if len(args) > 0 && len(splitContainerList(args[0])) > 1 {
+			return fmt.Errorf("%s runs on a single container", flag)
+		}
+	}
+	if execRecord != "" {
+		if execAll || execCapture || execNoTTY {
+			return helpers.UsageErrorf("--record needs a terminal session and cannot be combined with --all, --capture or --no-tty")
+		}
+		if len(args) > 0 && len(splitContainerList(args[0])) > 1 {
+			return helpers.UsageErrorf("--record runs on a single container")
+		}
+	}
+	if execCapture {
+		if execAll {
+			return helpers.UsageErrorf("--capture cannot be combined with --all")
+		}
+		if dash < 0 {
+			return helpers.UsageErrorf("--capture requires a command after '--'")
+		}
+		if dash == 1 && len(splitContainerList(args[0])) > 1 {
+			return helpers.UsageErrorf("--capture runs on a single container")
+		}
+	}
+	if dash < 0 {
+		if execAll {
+			return helpers.UsageErrorf("--all requires a command after '--'")
+		}
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+
+	if dash == len(args) {
+		return fmt.Errorf("no command provided after '--'")
+	}
+	if execAll {
+		if dash != 0 {
+			return fmt.Errorf("container names cannot be combined with --all")
+		}
+		return nil
+	}
+	if dash > 1 {
+		return fmt.Errorf("expected a single container name or comma-separated list before '--', got %d", dash)
+	}
+	return nil
+}
+
+// splitContainerList splits a comma-separated container list, dropping blanks and duplicates
+func splitContainerList(list string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
 // ContainerExecManager interface for dependency injection
 type ContainerExecManager interface {
 	ContainerExists(ctx context.Context, name string) bool
 	ExecInteractiveShell(ctx context.Context, containerName string) error
+	RunCommand(ctx context.Context, containerName string, w io.Writer, args ...string) error
+	ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error)
+	RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error
+	ExecInteractive(ctx context.Context, containerName string, args ...string) error
+	ExecRecorded(ctx context.Context, containerName string, out io.Writer, args ...string) error
+	ForwardSSHAgent(ctx context.Context, containerName, user string) (*helpers.AgentForward, error)
+	RemoveDevice(ctx context.Context, containerName, deviceName string) error
+	PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error)
+	RemoveFile(ctx context.Context, containerName, path string) error
+}
+
+// execStreams connects a non-interactive command to the caller's input and output
+type execStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 // DefaultContainerExecManager implements ContainerExecManager using helpers
@@ -57,17 +304,238 @@ func (d *DefaultContainerExecManager) ContainerExists(ctx context.Context, name
 
 func (d *DefaultContainerExecManager) ExecInteractiveShell(ctx context.Context, containerName string) error {
 	// Use lxc exec with su to properly load user environment and groups
-	cmd := exec.Command("lxc", "exec", containerName, "--", "su", "-", "app")
+	logger.Debug("Executing: lxc exec %s -- su - app", containerName)
 
-	// Connect stdin, stdout, stderr for interactive session
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Connect stdin, stdout, stderr for interactive session; the session lasts
+	// until the user exits, so the command timeout does not apply to it
+	streams := helpers.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	return helpers.Runner().RunStreaming(context.WithoutCancel(ctx), streams, "lxc", "exec", containerName, "--", "su", "-", "app")
+}
 
-	logger.Debug("Executing: lxc exec %s -- su - app", containerName)
+func (d *DefaultContainerExecManager) RunCommand(ctx context.Context, containerName string, w io.Writer, args ...string) error {
+	return helpers.StreamContainerCommand(ctx, containerName, "", w, args...)
+}
+
+func (d *DefaultContainerExecManager) ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error) {
+	return helpers.ListManagedContainersMatching(selector)
+}
+
+func (d *DefaultContainerExecManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	// -T keeps lxc from allocating a terminal so stdout and stderr stay separate
+	cmdArgs := append([]string{"exec", containerName, "-T", "--"}, args...)
+	logger.Debug("Executing: lxc %s", strings.Join(cmdArgs, " "))
+	return helpers.Runner().RunStreaming(ctx, helpers.Streams{Stdin: streams.Stdin, Stdout: streams.Stdout, Stderr: streams.Stderr}, "lxc", cmdArgs...)
+}
+
+func (d *DefaultContainerExecManager) ExecInteractive(ctx context.Context, containerName string, args ...string) error {
+	cmdArgs := append([]string{"exec", containerName, "--"}, args...)
+	logger.Debug("Executing: lxc %s", strings.Join(cmdArgs, " "))
+
+	streams := helpers.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	return helpers.Runner().RunStreaming(context.WithoutCancel(ctx), streams, "lxc", cmdArgs...)
+}
+
+func (d *DefaultContainerExecManager) ExecRecorded(ctx context.Context, containerName string, out io.Writer, args ...string) error {
+	// Output goes through the recording rather than to the terminal, so lxc
+	// has to be told to allocate one
+	cmdArgs := append([]string{"exec", containerName, "-t", "--"}, args...)
+	logger.Debug("Executing: lxc %s", strings.Join(cmdArgs, " "))
+
+	streams := helpers.Streams{Stdin: os.Stdin, Stdout: out, Stderr: out}
+	return helpers.Runner().RunStreaming(context.WithoutCancel(ctx), streams, "lxc", cmdArgs...)
+}
+
+func (d *DefaultContainerExecManager) ForwardSSHAgent(ctx context.Context, containerName, user string) (*helpers.AgentForward, error) {
+	// The agent socket lives on this machine, which a remote server cannot reach
+	if err := helpers.RequireLocalServer(ctx, "SSH agent forwarding"); err != nil {
+		return nil, err
+	}
+	socket, err := helpers.HostSSHAgentSocket()
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ForwardSSHAgent(ctx, containerName, socket, user)
+}
+
+func (d *DefaultContainerExecManager) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	return helpers.RemoveContainerDevice(containerName, deviceName)
+}
+
+func (d *DefaultContainerExecManager) PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error) {
+	return helpers.PushEnv(ctx, containerName, vars)
+}
+
+func (d *DefaultContainerExecManager) RemoveFile(ctx context.Context, containerName, path string) error {
+	return helpers.RemoveContainerFile(ctx, containerName, path)
+}
+
+// execSession is what a single-container session gets on top of its command:
+// a forwarded SSH agent and variables from an env file
+type execSession struct {
+	agent   *helpers.AgentForward
+	envFile string
+	envKeys []string
+}
+
+// active reports whether the session changes the command at all
+func (s execSession) active() bool {
+	return s.agent != nil || s.envFile != ""
+}
+
+// wrap prefixes a command so it sees the session's agent and variables
+func (s execSession) wrap(command []string) []string {
+	if s.agent != nil {
+		command = helpers.AgentCommand(s.agent, command...)
+	}
+	if s.envFile != "" {
+		command = helpers.EnvCommand(s.envFile, command...)
+	}
+	return command
+}
+
+// keepEnv lists the session's variables, which a login shell must be told to keep
+func (s execSession) keepEnv() []string {
+	keep := append([]string(nil), s.envKeys...)
+	if s.agent != nil {
+		keep = append(keep, helpers.SSHAuthSockEnv)
+	}
+	return keep
+}
+
+// shell returns the login shell for user with the session's variables
+func (s execSession) shell(user string) []string {
+	return s.wrap(helpers.UserShell(user, s.keepEnv()))
+}
+
+// command wraps a command so it runs as identity with the session's variables
+func (s execSession) command(identity execIdentity, command []string) []string {
+	return s.wrap(helpers.UserCommand(identity.User, identity.Login, s.keepEnv(), command...))
+}
+
+// execIdentity is who a shell or command runs as inside the container
+type execIdentity struct {
+	User string
+	// Login runs commands in a login shell that sources the user's profile
+	Login bool
+}
+
+// resolveExecIdentity applies --root, --user and --login over the config file
+func resolveExecIdentity(settings *helpers.Settings, user string, root, login, loginSet bool) (execIdentity, error) {
+	if root {
+		user = "root"
+	}
+	user, err := helpers.ResolveExecUser(user, settings)
+	if err != nil {
+		return execIdentity{}, err
+	}
+	if !loginSet && settings != nil {
+		login = settings.Exec.Login
+	}
+	return execIdentity{User: user, Login: login}, nil
+}
+
+// startExecSession forwards the SSH agent and copies env file variables into
+// a container. stop must be called even on error to undo what was set up.
+func startExecSession(ctx context.Context, manager ContainerExecManager, containerName, user string, forwardAgent bool, envFile string) (execSession, error) {
+	var session execSession
+
+	// Read the file first so a typo does not leave a device behind
+	var vars []helpers.EnvVar
+	if envFile != "" {
+		var err error
+		if vars, err = helpers.LoadEnvFile(envFile); err != nil {
+			return session, err
+		}
+	}
 
-	// Run the interactive command
-	return cmd.Run()
+	if !manager.ContainerExists(ctx, containerName) {
+		return session, containerNotFound(containerName)
+	}
+
+	if forwardAgent {
+		agent, err := manager.ForwardSSHAgent(ctx, containerName, user)
+		if err != nil {
+			return session, err
+		}
+		logger.Debug("Forwarding the SSH agent to %s in container '%s'", agent.Socket, containerName)
+		session.agent = agent
+	}
+
+	if len(vars) > 0 {
+		path, err := manager.PushEnv(ctx, containerName, vars)
+		if err != nil {
+			return session, err
+		}
+		session.envFile = path
+		session.envKeys = helpers.EnvKeys(vars)
+	}
+	return session, nil
+}
+
+// stop removes the agent device and any env file the command did not get to
+// delete; failures are only logged since the session itself already finished
+func (s execSession) stop(manager ContainerExecManager, containerName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if s.agent != nil {
+		if err := manager.RemoveDevice(ctx, containerName, s.agent.Device); err != nil {
+			logger.Warn("Failed to remove SSH agent device '%s' from container '%s': %v", s.agent.Device, containerName, err)
+		}
+	}
+	if s.envFile != "" {
+		if err := manager.RemoveFile(ctx, containerName, s.envFile); err != nil {
+			logger.Warn("Failed to remove %s from container '%s': %v", s.envFile, containerName, err)
+		}
+	}
+}
+
+// execInteractiveCommand runs a shell command for user in the container with a terminal
+func execInteractiveCommand(ctx context.Context, manager ContainerExecManager, containerName, user string, command []string) error {
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	logger.Info("Executing interactive shell in container '%s' as %s user...", containerName, user)
+	if err := manager.ExecInteractive(ctx, containerName, command...); err != nil {
+		return fmt.Errorf("failed to execute interactive shell in container '%s': %w", containerName, err)
+	}
+	return nil
+}
+
+// execRecorded runs a shell or command in the container with a terminal,
+// showing its output on out and recording it to path as an asciinema v2 cast
+func execRecorded(ctx context.Context, manager ContainerExecManager, containerName string, command []string, path string, header helpers.CastHeader, out io.Writer) error {
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	// Recordings may show secrets, so only the user can read them
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+	defer file.Close()
+	recorder, err := helpers.NewCastRecorder(file, header, time.Now())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Recording the session in container '%s' to %s...", containerName, path)
+	runErr := manager.ExecRecorded(ctx, containerName, io.MultiWriter(out, recorder), command...)
+	if err := recorder.Close(); err != nil {
+		logger.Warn("The recording in %s is incomplete: %v", path, err)
+	} else {
+		logger.Info("Recorded the session to %s; play it with 'asciinema play %s'", path, path)
+	}
+
+	if runErr == nil {
+		return nil
+	}
+	if code := helpers.ExitCodeFromError(runErr); code > 0 {
+		return &ExitError{Code: code, Err: fmt.Errorf("session exited with status %d in container '%s'", code, containerName)}
+	}
+	return fmt.Errorf("failed to execute recorded session in container '%s': %w", containerName, runErr)
 }
 
 // execContainer executes a shell in the container as app user
@@ -78,7 +546,7 @@ func execContainer(ctx context.Context, manager ContainerExecManager, containerN
 
 	// Check if container exists
 	if !manager.ContainerExists(ctx, containerName) {
-		return fmt.Errorf("container '%s' does not exist", containerName)
+		return containerNotFound(containerName)
 	}
 
 	logger.Info("Executing interactive shell in container '%s' as app user...", containerName)
@@ -92,9 +560,144 @@ func execContainer(ctx context.Context, manager ContainerExecManager, containerN
 	return nil
 }
 
+// execNonInteractive runs a command without a terminal, passing on its exit code
+func execNonInteractive(ctx context.Context, manager ContainerExecManager, containerName string, command []string, streams execStreams) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	err := manager.RunNonInteractive(ctx, containerName, streams, command...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return helpers.TimeoutErrorf("command timed out in container '%s'", containerName)
+	}
+	if code := helpers.ExitCodeFromError(err); code > 0 {
+		return &ExitError{Code: code, Err: fmt.Errorf("command exited with status %d in container '%s'", code, containerName)}
+	}
+	return fmt.Errorf("failed to run command in container '%s': %w", containerName, err)
+}
+
+// execResult records the outcome of a broadcast command on one container
+type execResult struct {
+	Container string
+	ExitCode  int
+	Err       error
+}
+
+// broadcastExec runs a command on several containers concurrently; an empty
+// target list means every running managed container matching the selector
+func broadcastExec(ctx context.Context, manager ContainerExecManager, targets []string, selector helpers.LabelSelector, command []string, parallel int, out io.Writer) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+	if parallel < 1 {
+		return helpers.UsageErrorf("--parallel must be at least 1")
+	}
+
+	if len(targets) == 0 {
+		names, err := manager.ListManagedContainers(ctx, selector)
+		if err != nil {
+			return fmt.Errorf("failed to list managed containers: %w", err)
+		}
+		if len(names) == 0 && len(selector) > 0 {
+			return fmt.Errorf("no running managed containers match selector '%s'", selector)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no running managed containers found")
+		}
+		targets = names
+	} else {
+		for _, name := range targets {
+			if !manager.ContainerExists(ctx, name) {
+				return containerNotFound(name)
+			}
+		}
+	}
+
+	logger.Info("Running '%s' on %d container(s)...", strings.Join(command, " "), len(targets))
+
+	results := make([]execResult, len(targets))
+	sem := make(chan struct{}, parallel)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, name := range targets {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			pw := helpers.NewPrefixWriter(out, fmt.Sprintf("[%s] ", name), &mu)
+			err := manager.RunCommand(ctx, name, pw, command...)
+			if flushErr := pw.Flush(); flushErr != nil {
+				logger.Debug("Failed to flush output for '%s': %v", name, flushErr)
+			}
+			results[i] = execResult{Container: name, ExitCode: helpers.ExitCodeFromError(err), Err: err}
+		}(i, name)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprint(out, formatExecResults(results))
+
+	// Interrupted commands are not reported as failures by the runner
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("exec interrupted")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d container(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// formatExecResults renders the per-container exit code summary
+func formatExecResults(results []execResult) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER\tEXIT\tRESULT")
+	for _, result := range results {
+		status := "ok"
+		exitCode := fmt.Sprintf("%d", result.ExitCode)
+		if result.Err != nil {
+			status = "failed"
+			if result.ExitCode < 0 {
+				exitCode = "-"
+				status = fmt.Sprintf("failed: %v", result.Err)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Container, exitCode, status)
+	}
+	w.Flush()
+	return sb.String()
+}
+
 func init() {
 	rootCmd.AddCommand(execCmd)
 
 	// Add timeout flag
 	execCmd.Flags().DurationVarP(&execTimeout, "timeout", "t", 30*time.Second, "Timeout for the exec operation")
+	execCmd.Flags().BoolVar(&execAll, "all", false, "Run the command on every running managed container")
+	execCmd.Flags().StringVar(&execSelector, "selector", "", "Only run on containers whose labels match, e.g. env=prod,team=core (with --all)")
+	execCmd.Flags().IntVar(&execParallel, "parallel", 10, "Maximum number of containers to run the command on at once")
+	execCmd.Flags().BoolVar(&execNoTTY, "no-tty", false, "Do not allocate a terminal; read the shell's commands from stdin")
+	execCmd.Flags().BoolVar(&execCapture, "capture", false, "Run the command on one container with separate stdout/stderr and its exit code")
+	execCmd.Flags().BoolVar(&execAgent, "forward-agent", false, "Make the host's SSH agent available in the session")
+	execCmd.Flags().StringVarP(&execUser, "user", "u", "", "User to run the shell or command as (default app, or exec.user from the config file)")
+	execCmd.Flags().BoolVar(&execRoot, "root", false, "Run the shell or command as root")
+	execCmd.Flags().BoolVar(&execLogin, "login", false, "Run the command in a login shell that sources the user's profile")
+	execCmd.Flags().StringVar(&execRecord, "record", "", "Record the session of a single container to this file in asciinema v2 format")
+	execCmd.Flags().StringVar(&execEnvFile, "env-file", "", "Load variables from a .env file on the host into the session")
 }
@@ -1,10 +1,21 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
 )
 
 // MockContainerExecManager for testing exec command
@@ -13,7 +24,19 @@ type MockContainerExecManager struct {
 	ExecInteractiveShellFunc func(ctx context.Context, containerName string) error
 	ExistingContainers       map[string]bool
 	ExecShellError           error
+	RunCommandFunc           func(ctx context.Context, containerName string, w io.Writer, args ...string) error
+	ManagedContainers        []string
+	ListManagedError         error
+	Labels                   map[string]map[string]string
+	RunNonInteractiveFunc    func(ctx context.Context, containerName string, streams execStreams, args ...string) error
+	ExecInteractiveArgs      []string
+	ExecRecordedFunc         func(out io.Writer) error
+	AgentError               error
+	RemovedDevices           []string
+	PushedEnv                []helpers.EnvVar
+	RemovedFiles             []string
 	Calls                    map[string]int
+	mu                       sync.Mutex
 }
 
 func (m *MockContainerExecManager) ContainerExists(ctx context.Context, name string) bool {
@@ -38,7 +61,81 @@ func (m *MockContainerExecManager) ExecInteractiveShell(ctx context.Context, con
 	return nil
 }
 
+func (m *MockContainerExecManager) RunCommand(ctx context.Context, containerName string, w io.Writer, args ...string) error {
+	m.trackCall("RunCommand")
+	if m.RunCommandFunc != nil {
+		return m.RunCommandFunc(ctx, containerName, w, args...)
+	}
+	fmt.Fprintf(w, "ran %s\n", strings.Join(args, " "))
+	return nil
+}
+
+func (m *MockContainerExecManager) ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error) {
+	m.trackCall("ListManagedContainers")
+	if m.ListManagedError != nil {
+		return nil, m.ListManagedError
+	}
+	var names []string
+	for _, name := range m.ManagedContainers {
+		if selector.Matches(m.Labels[name]) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (m *MockContainerExecManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	m.trackCall("RunNonInteractive")
+	if m.RunNonInteractiveFunc != nil {
+		return m.RunNonInteractiveFunc(ctx, containerName, streams, args...)
+	}
+	return nil
+}
+
+func (m *MockContainerExecManager) ExecInteractive(ctx context.Context, containerName string, args ...string) error {
+	m.trackCall("ExecInteractive")
+	m.ExecInteractiveArgs = args
+	return m.ExecShellError
+}
+
+func (m *MockContainerExecManager) ExecRecorded(ctx context.Context, containerName string, out io.Writer, args ...string) error {
+	m.trackCall("ExecRecorded")
+	m.ExecInteractiveArgs = args
+	if m.ExecRecordedFunc != nil {
+		return m.ExecRecordedFunc(out)
+	}
+	return m.ExecShellError
+}
+
+func (m *MockContainerExecManager) ForwardSSHAgent(ctx context.Context, containerName, user string) (*helpers.AgentForward, error) {
+	m.trackCall("ForwardSSHAgent")
+	if m.AgentError != nil {
+		return nil, m.AgentError
+	}
+	return &helpers.AgentForward{Device: "ssh-agent-42", Socket: "/tmp/lxc-go-cli-ssh-agent-42.sock"}, nil
+}
+
+func (m *MockContainerExecManager) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	m.trackCall("RemoveDevice")
+	m.RemovedDevices = append(m.RemovedDevices, containerName+"/"+deviceName)
+	return nil
+}
+
+func (m *MockContainerExecManager) PushEnv(ctx context.Context, containerName string, vars []helpers.EnvVar) (string, error) {
+	m.trackCall("PushEnv")
+	m.PushedEnv = vars
+	return "/run/lxc-go-cli-env.abc123", nil
+}
+
+func (m *MockContainerExecManager) RemoveFile(ctx context.Context, containerName, path string) error {
+	m.trackCall("RemoveFile")
+	m.RemovedFiles = append(m.RemovedFiles, containerName+":"+path)
+	return nil
+}
+
 func (m *MockContainerExecManager) trackCall(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.Calls == nil {
 		m.Calls = make(map[string]int)
 	}
@@ -46,6 +143,8 @@ func (m *MockContainerExecManager) trackCall(method string) {
 }
 
 func (m *MockContainerExecManager) GetCallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.Calls == nil {
 		return 0
 	}
@@ -59,8 +158,8 @@ func TestExecCommand(t *testing.T) {
 	}
 
 	// Test exec command properties
-	if execCmd.Use != "exec <container-name>" {
-		t.Errorf("expected Use to be 'exec <container-name>', got '%s'", execCmd.Use)
+	if execCmd.Use != "exec [container-name]" {
+		t.Errorf("expected Use to be 'exec [container-name]', got '%s'", execCmd.Use)
 	}
 
 	if execCmd.Short == "" {
@@ -73,15 +172,15 @@ func TestExecCommand(t *testing.T) {
 }
 
 func TestExecCommandArgs(t *testing.T) {
-	// Test that the command expects exactly 1 argument
+	// Test that the command expects at most 1 argument
 	if execCmd.Args == nil {
 		t.Error("execCmd should have Args validation")
 	}
 
-	// Test with no args (should fail)
+	// Test with no args (should pass, using the current container)
 	err := execCmd.Args(execCmd, []string{})
-	if err == nil {
-		t.Error("should fail with no arguments")
+	if err != nil {
+		t.Errorf("should pass with no arguments: %v", err)
 	}
 
 	// Test with one arg (should pass)
@@ -324,3 +423,529 @@ func TestExecContainerWithMockValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestExecBroadcastFlags(t *testing.T) {
+	if execCmd.Flags().Lookup("all") == nil {
+		t.Error("all flag should exist")
+	}
+	parallelFlag := execCmd.Flags().Lookup("parallel")
+	if parallelFlag == nil || parallelFlag.DefValue != "10" {
+		t.Error("parallel flag should exist and default to 10")
+	}
+}
+
+func TestSplitContainerList(t *testing.T) {
+	names := splitContainerList("web1, web2,,web1,web3")
+	expected := []string{"web1", "web2", "web3"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestBroadcastExec(t *testing.T) {
+	// exitError produces a real *exec.ExitError so exit codes can be reported
+	exitError := func(code int) error {
+		return fmt.Errorf("command failed: %w", exec.Command("sh", "-c", fmt.Sprintf("exit %d", code)).Run())
+	}
+
+	tests := []struct {
+		name           string
+		targets        []string
+		managed        []string
+		selector       string
+		listError      error
+		parallel       int
+		runCommand     func(ctx context.Context, containerName string, w io.Writer, args ...string) error
+		expectedError  string
+		expectedOutput []string
+		missingOutput  []string
+	}{
+		{
+			name:           "all containers succeed",
+			targets:        []string{"web1", "web2"},
+			parallel:       10,
+			expectedOutput: []string{"[web1] ran apt-get update", "[web2] ran apt-get update", "CONTAINER", "web1       0     ok"},
+		},
+		{
+			name:     "one container fails",
+			targets:  []string{"web1", "web2"},
+			parallel: 1,
+			runCommand: func(ctx context.Context, containerName string, w io.Writer, args ...string) error {
+				if containerName == "web2" {
+					fmt.Fprint(w, "E: Could not get lock")
+					return exitError(100)
+				}
+				return nil
+			},
+			expectedError:  "1 of 2 container(s) failed",
+			expectedOutput: []string{"[web2] E: Could not get lock", "web2       100   failed"},
+		},
+		{
+			name:          "unknown container",
+			targets:       []string{"web1", "missing"},
+			parallel:      10,
+			expectedError: "container 'missing' does not exist",
+		},
+		{
+			name:           "all managed containers",
+			managed:        []string{"web1", "web2"},
+			parallel:       2,
+			expectedOutput: []string{"[web1] ran apt-get update", "[web2] ran apt-get update"},
+		},
+		{
+			name:           "managed containers matching selector",
+			managed:        []string{"web1", "web2"},
+			selector:       "env=prod",
+			parallel:       2,
+			expectedOutput: []string{"[web1] ran apt-get update"},
+			missingOutput:  []string{"web2"},
+		},
+		{
+			name:          "no containers match selector",
+			managed:       []string{"web1", "web2"},
+			selector:      "env=staging",
+			parallel:      2,
+			expectedError: "no running managed containers match selector 'env=staging'",
+		},
+		{
+			name:          "no managed containers",
+			parallel:      10,
+			expectedError: "no running managed containers found",
+		},
+		{
+			name:          "listing fails",
+			listError:     fmt.Errorf("lxc failed"),
+			parallel:      10,
+			expectedError: "failed to list managed containers",
+		},
+		{
+			name:          "invalid parallelism",
+			targets:       []string{"web1"},
+			parallel:      0,
+			expectedError: "--parallel must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockContainerExecManager{
+				ExistingContainers: map[string]bool{"web1": true, "web2": true},
+				ManagedContainers:  tt.managed,
+				ListManagedError:   tt.listError,
+				RunCommandFunc:     tt.runCommand,
+				Labels:             map[string]map[string]string{"web1": {"env": "prod"}, "web2": {"env": "dev"}},
+			}
+			selector, err := helpers.ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("invalid selector: %v", err)
+			}
+
+			var out bytes.Buffer
+			err = broadcastExec(context.Background(), manager, tt.targets, selector, []string{"apt-get", "update"}, tt.parallel, &out)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			for _, expected := range tt.expectedOutput {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+				}
+			}
+			for _, missing := range tt.missingOutput {
+				if strings.Contains(out.String(), missing) {
+					t.Errorf("expected output not to contain '%s', got:\n%s", missing, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExecNonInteractive(t *testing.T) {
+	manager := &MockContainerExecManager{
+		ExistingContainers: map[string]bool{"web1": true},
+		RunNonInteractiveFunc: func(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+			fmt.Fprintln(streams.Stdout, "out")
+			fmt.Fprintln(streams.Stderr, "err")
+			if args[0] == "false" {
+				return exec.Command("sh", "-c", "exit 3").Run()
+			}
+			return nil
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	streams := execStreams{Stdout: &stdout, Stderr: &stderr}
+	if err := execNonInteractive(context.Background(), manager, "web1", []string{"true"}, streams); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("expected separate stdout and stderr, got %q and %q", stdout.String(), stderr.String())
+	}
+
+	err := execNonInteractive(context.Background(), manager, "web1", []string{"false"}, streams)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Errorf("expected exit code 3 to be preserved, got %v", err)
+	}
+
+	err = execNonInteractive(context.Background(), manager, "missing", []string{"true"}, streams)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+}
+
+func TestValidateExecArgsCapture(t *testing.T) {
+	defer func() { execCapture, execAll = false, false }()
+
+	tests := []struct {
+		name          string
+		args          []string
+		all           bool
+		expectedError string
+	}{
+		{name: "single container", args: []string{"web1", "--", "ls"}},
+		{name: "no command", args: []string{"web1"}, expectedError: "--capture requires a command"},
+		{name: "several containers", args: []string{"web1,web2", "--", "ls"}, expectedError: "single container"},
+		{name: "with all", args: []string{"--", "ls"}, all: true, expectedError: "cannot be combined with --all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCapture, execAll = true, tt.all
+			cmd := &cobra.Command{Use: "exec"}
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := validateExecArgs(cmd, cmd.Flags().Args())
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateExecArgsForwardAgent(t *testing.T) {
+	defer func() { execAgent, execAll = false, false }()
+
+	tests := []struct {
+		name          string
+		args          []string
+		all           bool
+		expectedError string
+	}{
+		{name: "shell", args: []string{"web1"}},
+		{name: "command", args: []string{"web1", "--", "git", "pull"}},
+		{name: "several containers", args: []string{"web1,web2", "--", "ls"}, expectedError: "single container"},
+		{name: "with all", args: []string{"--", "ls"}, all: true, expectedError: "cannot be combined with --all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execAgent, execAll = true, tt.all
+			cmd := &cobra.Command{Use: "exec"}
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := validateExecArgs(cmd, cmd.Flags().Args())
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateExecArgsEnvFile(t *testing.T) {
+	defer func() { execEnvFile, execAll = "", false }()
+	execEnvFile = ".env"
+
+	cmd := &cobra.Command{Use: "exec"}
+	if err := cmd.ParseFlags([]string{"web1,web2", "--", "ls"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateExecArgs(cmd, cmd.Flags().Args()); err == nil || !strings.Contains(err.Error(), "--env-file runs on a single container") {
+		t.Errorf("expected a single container error, got %v", err)
+	}
+
+	execAll = true
+	cmd = &cobra.Command{Use: "exec"}
+	if err := cmd.ParseFlags([]string{"--", "ls"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateExecArgs(cmd, cmd.Flags().Args()); err == nil || !strings.Contains(err.Error(), "--env-file cannot be combined with --all") {
+		t.Errorf("expected an --all error, got %v", err)
+	}
+}
+
+func TestValidateExecArgsSelector(t *testing.T) {
+	defer func() { execSelector, execAll = "", false }()
+	execSelector = "env=prod"
+
+	cmd := &cobra.Command{Use: "exec"}
+	if err := cmd.ParseFlags([]string{"web1", "--", "ls"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateExecArgs(cmd, cmd.Flags().Args()); err == nil || !strings.Contains(err.Error(), "--selector requires --all") {
+		t.Errorf("expected a --selector requires --all error, got %v", err)
+	}
+
+	execAll = true
+	cmd = &cobra.Command{Use: "exec"}
+	if err := cmd.ParseFlags([]string{"--", "ls"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateExecArgs(cmd, cmd.Flags().Args()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestExecSession(t *testing.T) {
+	manager := &MockContainerExecManager{ExistingContainers: map[string]bool{"web": true}}
+	ctx := context.Background()
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("TOKEN=s3cret\nexport REGION='eu west'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := startExecSession(ctx, manager, "web", "app", true, envFile)
+	if err != nil {
+		t.Fatalf("startExecSession failed: %v", err)
+	}
+	if len(manager.PushedEnv) != 2 || manager.PushedEnv[0] != (helpers.EnvVar{Key: "TOKEN", Value: "s3cret"}) {
+		t.Errorf("expected the env file variables to be pushed, got %v", manager.PushedEnv)
+	}
+
+	shell := session.shell("app")
+	want := strings.Join(helpers.EnvCommand("/run/lxc-go-cli-env.abc123",
+		"env", "SSH_AUTH_SOCK=/tmp/lxc-go-cli-ssh-agent-42.sock", "su", "-w", "TOKEN,REGION,SSH_AUTH_SOCK", "-", "app"), " ")
+	if strings.Join(shell, " ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(shell, " "))
+	}
+	for _, arg := range shell {
+		if strings.Contains(arg, "s3cret") {
+			t.Fatalf("variable values must not appear on the command line: %q", shell)
+		}
+	}
+
+	if err := execInteractiveCommand(ctx, manager, "web", "app", shell); err != nil {
+		t.Fatalf("execInteractiveCommand failed: %v", err)
+	}
+	if strings.Join(manager.ExecInteractiveArgs, " ") != want {
+		t.Errorf("expected the session shell to run, got %q", manager.ExecInteractiveArgs)
+	}
+
+	session.stop(manager, "web")
+	if len(manager.RemovedDevices) != 1 || manager.RemovedDevices[0] != "web/ssh-agent-42" {
+		t.Errorf("expected the agent device to be removed, got %v", manager.RemovedDevices)
+	}
+	if len(manager.RemovedFiles) != 1 || manager.RemovedFiles[0] != "web:/run/lxc-go-cli-env.abc123" {
+		t.Errorf("expected the env file to be removed, got %v", manager.RemovedFiles)
+	}
+
+	// Without extras the session leaves commands alone
+	var plain execSession
+	if plain.active() || strings.Join(plain.shell("app"), " ") != "su - app" || strings.Join(plain.wrap([]string{"ls"}), " ") != "ls" {
+		t.Errorf("expected an inactive session to change nothing, got %q", plain.shell("app"))
+	}
+
+	// Commands keep the session's variables when run in a login shell
+	command := session.command(execIdentity{User: "app", Login: true}, []string{"echo", "it's"})
+	want = strings.Join(helpers.EnvCommand("/run/lxc-go-cli-env.abc123",
+		"env", "SSH_AUTH_SOCK=/tmp/lxc-go-cli-ssh-agent-42.sock", "su", "-w", "TOKEN,REGION,SSH_AUTH_SOCK", "-", "app", "-c", `'echo' 'it'\''s'`), " ")
+	if strings.Join(command, " ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(command, " "))
+	}
+}
+
+func TestExecSessionCommand(t *testing.T) {
+	var plain execSession
+	tests := []struct {
+		identity execIdentity
+		want     string
+	}{
+		{execIdentity{User: "app"}, `runuser -u app -- sh -c cd ~app && exec "$@" sh docker ps`},
+		{execIdentity{User: "root"}, "docker ps"},
+		{execIdentity{User: "app", Login: true}, "su - app -c 'docker' 'ps'"},
+		{execIdentity{User: "root", Login: true}, "su - root -c 'docker' 'ps'"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(plain.command(tt.identity, []string{"docker", "ps"}), " "); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.identity, tt.want, got)
+		}
+	}
+	if got := strings.Join(plain.shell("deploy"), " "); got != "su - deploy" {
+		t.Errorf("expected a login shell for deploy, got %q", got)
+	}
+}
+
+func TestResolveExecIdentity(t *testing.T) {
+	configured := &helpers.Settings{Exec: helpers.ExecSettings{User: "deploy", Login: true}}
+	tests := []struct {
+		name          string
+		settings      *helpers.Settings
+		user          string
+		root          bool
+		login         bool
+		loginSet      bool
+		expected      execIdentity
+		expectedError string
+	}{
+		{name: "default app user", settings: &helpers.Settings{}, expected: execIdentity{User: "app"}},
+		{name: "configured default", settings: configured, expected: execIdentity{User: "deploy", Login: true}},
+		{name: "user flag wins", settings: configured, user: "ci", expected: execIdentity{User: "ci", Login: true}},
+		{name: "root", settings: configured, root: true, expected: execIdentity{User: "root", Login: true}},
+		{name: "login flag wins", settings: configured, loginSet: true, expected: execIdentity{User: "deploy"}},
+		{name: "login flag", settings: &helpers.Settings{}, login: true, loginSet: true, expected: execIdentity{User: "app", Login: true}},
+		{name: "invalid user", settings: &helpers.Settings{}, user: "app;rm", expectedError: "invalid user name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := resolveExecIdentity(tt.settings, tt.user, tt.root, tt.login, tt.loginSet)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil || identity != tt.expected {
+				t.Errorf("expected %+v, got %+v (%v)", tt.expected, identity, err)
+			}
+		})
+	}
+}
+
+func TestValidateExecArgsUser(t *testing.T) {
+	defer func() { execRoot, execUser = false, "" }()
+	execRoot, execUser = true, "deploy"
+
+	cmd := &cobra.Command{Use: "exec"}
+	if err := validateExecArgs(cmd, []string{"web1"}); err == nil || !strings.Contains(err.Error(), "--root cannot be combined with --user") {
+		t.Errorf("expected a --root and --user error, got %v", err)
+	}
+}
+
+func TestExecSessionErrors(t *testing.T) {
+	manager := &MockContainerExecManager{ExistingContainers: map[string]bool{"web": true}}
+	ctx := context.Background()
+
+	if _, err := startExecSession(ctx, manager, "missing", "app", true, ""); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+	if _, err := startExecSession(ctx, manager, "web", "app", true, filepath.Join(t.TempDir(), "missing.env")); err == nil || !strings.Contains(err.Error(), "env file") {
+		t.Errorf("expected env file error, got %v", err)
+	}
+	if manager.GetCallCount("ForwardSSHAgent") != 0 {
+		t.Error("expected nothing to be set up when the env file cannot be read")
+	}
+
+	manager.AgentError = errors.New("no SSH agent: SSH_AUTH_SOCK is not set")
+	if _, err := startExecSession(ctx, manager, "web", "app", true, ""); err == nil || !strings.Contains(err.Error(), "SSH_AUTH_SOCK") {
+		t.Errorf("expected the agent error, got %v", err)
+	}
+}
+
+func TestValidateExecArgsRecord(t *testing.T) {
+	defer func() { execRecord, execAll, execNoTTY = "", false, false }()
+
+	tests := []struct {
+		name          string
+		args          []string
+		all           bool
+		noTTY         bool
+		expectedError string
+	}{
+		{name: "shell", args: []string{"web1"}},
+		{name: "command", args: []string{"web1", "--", "./setup.sh"}},
+		{name: "several containers", args: []string{"web1,web2", "--", "ls"}, expectedError: "single container"},
+		{name: "with all", args: []string{"--", "ls"}, all: true, expectedError: "cannot be combined with --all"},
+		{name: "without a terminal", args: []string{"web1"}, noTTY: true, expectedError: "needs a terminal session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execRecord, execAll, execNoTTY = "session.cast", tt.all, tt.noTTY
+			cmd := &cobra.Command{Use: "exec"}
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := validateExecArgs(cmd, cmd.Flags().Args())
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestExecRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	manager := &MockContainerExecManager{
+		ExistingContainers: map[string]bool{"web1": true},
+		ExecRecordedFunc: func(out io.Writer) error {
+			fmt.Fprint(out, "$ ls\r\n")
+			return nil
+		},
+	}
+
+	var out bytes.Buffer
+	header := helpers.CastHeader{Width: 120, Height: 40}
+	if err := execRecorded(context.Background(), manager, "web1", []string{"su", "-", "app"}, path, header, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.String() != "$ ls\r\n" {
+		t.Errorf("expected the session on the terminal too, got %q", out.String())
+	}
+	if strings.Join(manager.ExecInteractiveArgs, " ") != "su - app" {
+		t.Errorf("unexpected command %v", manager.ExecInteractiveArgs)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"version":2,"width":120,"height":40,`) || !strings.HasSuffix(lines[1], `,"o","$ ls\r\n"]`) {
+		t.Errorf("unexpected recording:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the recording to be private, got %v", info.Mode())
+	}
+}
+
+func TestExecRecordedExitCode(t *testing.T) {
+	manager := &MockContainerExecManager{
+		ExistingContainers: map[string]bool{"web1": true},
+		ExecRecordedFunc: func(out io.Writer) error {
+			return exec.Command("sh", "-c", "exit 3").Run()
+		},
+	}
+	err := execRecorded(context.Background(), manager, "web1", []string{"false"}, filepath.Join(t.TempDir(), "s.cast"), helpers.CastHeader{}, io.Discard)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Errorf("expected the session's exit code, got %v", err)
+	}
+
+	err = execRecorded(context.Background(), manager, "missing", nil, filepath.Join(t.TempDir(), "s.cast"), helpers.CastHeader{}, io.Discard)
+	if !errors.Is(err, helpers.ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes scripts can rely on; they are documented in the README and do
+// not change between releases. Commands that run a command in a container,
+// such as exec, run and compose up, exit with that command's status instead.
+const (
+	ExitSuccess = 0
+	// ExitFailure is any failure not covered by a more specific code
+	ExitFailure = 1
+	// ExitUsage is a command invoked wrongly: an unknown command or flag, a
+	// wrong number of arguments or an invalid value
+	ExitUsage = 2
+	// ExitNotFound is a container, snapshot, pool or other object that does
+	// not exist
+	ExitNotFound = 3
+	// ExitConflict is an object that already exists or a resource in use
+	ExitConflict = 4
+	// ExitTimeout is an operation that ran out of time, e.g. past --timeout
+	ExitTimeout = 5
+	// ExitEnvironment is a missing prerequisite: the lxc client, root
+	// privileges, a local LXD server or a host tool
+	ExitEnvironment = 6
+)
+
+// cobraUsageErrors are the beginnings of the errors cobra returns for a
+// command line it cannot parse before any of our code runs
+var cobraUsageErrors = []string{"unknown command ", "required flag(s) "}
+
+// exitCode maps the error a command returned to its exit code. An ExitError
+// keeps its code; other errors are classified by kind.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Code > 0 {
+		return exitErr.Code
+	}
+	switch {
+	case errors.Is(err, helpers.ErrUsage):
+		return ExitUsage
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.Is(err, helpers.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, helpers.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, helpers.ErrEnvironment):
+		return ExitEnvironment
+	}
+	for _, prefix := range cobraUsageErrors {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return ExitUsage
+		}
+	}
+	return ExitFailure
+}
+
+// markUsageErrors makes flag parsing and argument count errors of root and
+// all its subcommands usage errors
+func markUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return helpers.WithKind(err, helpers.ErrUsage)
+	})
+	var mark func(c *cobra.Command)
+	mark = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(cmd *cobra.Command, a []string) error {
+				return helpers.WithKind(args(cmd, a), helpers.ErrUsage)
+			}
+		}
+		for _, sub := range c.Commands() {
+			mark(sub)
+		}
+	}
+	mark(root)
+}
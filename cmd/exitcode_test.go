@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, ExitSuccess},
+		{"plain failure", errors.New("boom"), ExitFailure},
+		{"explicit code", &ExitError{Code: 42, Err: errors.New("exited with 42")}, 42},
+		{"usage", helpers.UsageErrorf("invalid size '%s'", "x"), ExitUsage},
+		{"unknown command", errors.New(`unknown command "lsit" for "lxc-go-cli"`), ExitUsage},
+		{"not found", containerNotFoundExact("web"), ExitNotFound},
+		{"wrapped not found", fmt.Errorf("rollback: %w", helpers.NotFoundErrorf("snapshot 'x' does not exist")), ExitNotFound},
+		{"conflict", helpers.FormatPortConflictError("8080", "tcp"), ExitConflict},
+		{"timeout", helpers.TimeoutErrorf("step 'x' timed out"), ExitTimeout},
+		{"context deadline", fmt.Errorf("lxc exec failed: %w", context.DeadlineExceeded), ExitTimeout},
+		{"lxc unavailable", &helpers.LXCUnavailableError{Code: helpers.LXCErrNotFound}, ExitEnvironment},
+		{"environment", helpers.WithKind(errors.New("needs root"), helpers.ErrEnvironment), ExitEnvironment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.expected {
+				t.Errorf("expected exit code %d, got %d for %v", tt.expected, got, tt.err)
+			}
+		})
+	}
+}
+
+// containerNotFoundExact returns the not found error without listing
+// containers for suggestions
+func containerNotFoundExact(name string) error {
+	previous := exactNames
+	exactNames = true
+	defer func() { exactNames = previous }()
+	return containerNotFound(name)
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "lxc-go-cli", SilenceErrors: true, SilenceUsage: true}
+		root.AddCommand(&cobra.Command{
+			Use:  "delete <container-name>",
+			Args: cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return helpers.NotFoundErrorf("container '%s' does not exist", args[0])
+			},
+		})
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		markUsageErrors(root)
+		return root
+	}
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"delete"}, ExitUsage},
+		{[]string{"delete", "a", "b"}, ExitUsage},
+		{[]string{"delete", "web", "--bogus"}, ExitUsage},
+		{[]string{"dleete", "web"}, ExitUsage},
+		{[]string{"delete", "web"}, ExitNotFound},
+	}
+	for _, tt := range tests {
+		root := newRoot()
+		root.SetArgs(tt.args)
+		if got := exitCode(root.Execute()); got != tt.expected {
+			t.Errorf("%v: expected exit code %d, got %d", tt.args, tt.expected, got)
+		}
+	}
+}
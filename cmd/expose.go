@@ -0,0 +1,366 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	exposeVia       int
+	exposeDomain    string
+	exposeTLS       string
+	exposeACMEEmail string
+	exposeForce     bool
+	exposeTimeout   time.Duration
+)
+
+// exposeCmd represents the expose command
+var exposeCmd = &cobra.Command{
+	Use:   "expose <container-name> <container-port>",
+	Short: "Publish a container's web service on the host in one step",
+	Long: `Publish a service running in a container in one step:
+
+  1. forward a host port (--via, defaults to the container port) to the
+     container port with a proxy device, as 'port add' does
+  2. open the port in the host firewall, if ufw or firewalld is active
+  3. with --domain, write a Caddy site that serves the domain from the
+     forwarded port, and open 80 and 443 instead; the forward then only
+     listens on 127.0.0.1, so clients cannot bypass Caddy and its TLS
+  4. with --tls self-signed, generate a certificate for the domain; with
+     --tls acme, Caddy obtains one from Let's Encrypt
+
+The Caddy sites and certificates are written to /etc/caddy/lxc-go-cli, with
+keys readable by the caddy group, so --domain needs root. Load the sites by
+adding an import line to your Caddyfile, as printed after the first run.
+Running expose again for the same port and domain updates the site in place.
+
+Examples:
+  lxc-go-cli expose web 80                      # host :80 -> web:80
+  lxc-go-cli expose web 80 --via 8080           # host :8080 -> web:80
+  lxc-go-cli expose web 80 --via 8080 --domain app.local --tls self-signed
+  lxc-go-cli expose web 3000 --via 8081 --domain app.example.com --tls acme --acme-email ops@example.com`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerPort, err := strconv.Atoi(args[1])
+		if err != nil {
+			return helpers.UsageErrorf("invalid container port '%s': must be a number", args[1])
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exposeTimeout)
+		defer cancel()
+
+		opts := ExposeOptions{
+			ContainerPort: containerPort,
+			Via:           exposeVia,
+			Domain:        exposeDomain,
+			TLS:           exposeTLS,
+			ACMEEmail:     exposeACMEEmail,
+			Force:         exposeForce,
+		}
+		return exposeContainer(ctx, &DefaultExposeManager{}, args[0], opts, cmd.OutOrStdout())
+	},
+}
+
+// ExposeOptions holds the settings of an expose run
+type ExposeOptions struct {
+	ContainerPort int
+	// Via is the host port; 0 means the container port
+	Via       int
+	Domain    string
+	TLS       string
+	ACMEEmail string
+	// Force skips the host port availability check
+	Force bool
+}
+
+// ExposeManager interface for dependency injection
+type ExposeManager interface {
+	ContainerPortManager
+	DetectFirewall(ctx context.Context) (string, error)
+	OpenFirewallPort(ctx context.Context, firewall string, port int, protocol, comment string) error
+	EnsureSelfSignedCert(domain string) (string, string, error)
+	WriteProxySite(site helpers.ProxySite) (string, error)
+}
+
+// DefaultExposeManager implements ExposeManager using helpers
+type DefaultExposeManager struct {
+	DefaultContainerPortManager
+}
+
+func (d *DefaultExposeManager) DetectFirewall(ctx context.Context) (string, error) {
+	return helpers.DetectFirewall(ctx)
+}
+
+func (d *DefaultExposeManager) OpenFirewallPort(ctx context.Context, firewall string, port int, protocol, comment string) error {
+	return helpers.OpenFirewallPort(ctx, firewall, port, protocol, comment)
+}
+
+func (d *DefaultExposeManager) EnsureSelfSignedCert(domain string) (string, string, error) {
+	return helpers.EnsureSelfSignedCert(domain)
+}
+
+func (d *DefaultExposeManager) WriteProxySite(site helpers.ProxySite) (string, error) {
+	return helpers.WriteProxySite(site)
+}
+
+// validateExposeOptions checks the options and fills in defaults
+func validateExposeOptions(opts *ExposeOptions) error {
+	if opts.ContainerPort < 1 || opts.ContainerPort > 65535 {
+		return helpers.UsageErrorf("invalid container port '%d': must be between 1 and 65535", opts.ContainerPort)
+	}
+	if opts.Via == 0 {
+		opts.Via = opts.ContainerPort
+	}
+	if opts.Via < 1 || opts.Via > 65535 {
+		return helpers.UsageErrorf("invalid --via port '%d': must be between 1 and 65535", opts.Via)
+	}
+
+	if opts.Domain == "" {
+		if opts.TLS != "" && opts.TLS != helpers.TLSNone {
+			return helpers.UsageErrorf("--tls requires --domain")
+		}
+		if opts.ACMEEmail != "" {
+			return helpers.UsageErrorf("--acme-email requires --domain and --tls acme")
+		}
+		return nil
+	}
+
+	if err := helpers.ValidateDomain(opts.Domain); err != nil {
+		return err
+	}
+	if opts.TLS == "" {
+		opts.TLS = helpers.TLSNone
+	}
+	if err := helpers.ValidateTLSMode(opts.TLS); err != nil {
+		return err
+	}
+	if opts.ACMEEmail != "" && opts.TLS != helpers.TLSACME {
+		return helpers.UsageErrorf("--acme-email requires --tls acme")
+	}
+	// The proxy itself needs these ports
+	if opts.Via == 80 || opts.Via == 443 {
+		return helpers.UsageErrorf("--via %d is taken by the reverse proxy when --domain is set; pick another host port", opts.Via)
+	}
+	return nil
+}
+
+// exposePublicPorts returns the host ports clients connect to
+func exposePublicPorts(opts ExposeOptions) []int {
+	if opts.Domain == "" {
+		return []int{opts.Via}
+	}
+	if opts.TLS == helpers.TLSNone {
+		return []int{80}
+	}
+	// Port 80 redirects to HTTPS and answers ACME HTTP challenges
+	return []int{80, 443}
+}
+
+// exposeURL returns the address the service is reachable at
+func exposeURL(opts ExposeOptions) string {
+	switch {
+	case opts.Domain == "":
+		return fmt.Sprintf("http://<host>:%d", opts.Via)
+	case opts.TLS == helpers.TLSNone:
+		return "http://" + opts.Domain
+	}
+	return "https://" + opts.Domain
+}
+
+// exposeListenIP returns the host address the forward listens on: behind the
+// reverse proxy only Caddy may connect to it
+func exposeListenIP(opts ExposeOptions) string {
+	if opts.Domain != "" {
+		return "127.0.0.1"
+	}
+	return "0.0.0.0"
+}
+
+// findPortForward returns the proxy device that already forwards the host
+// port to the container port over TCP, and the host address it listens on
+func findPortForward(ctx context.Context, manager ContainerPortManager, containerName string, hostPort, containerPort int) (deviceName, listenIP string, found bool) {
+	configData, err := manager.GetContainerConfig(ctx, containerName)
+	if err != nil {
+		logger.Debug("Could not read the config of '%s': %v", containerName, err)
+		return "", "", false
+	}
+	var config ContainerConfig
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		logger.Debug("Could not parse the config of '%s': %v", containerName, err)
+		return "", "", false
+	}
+	for name, device := range config.Devices {
+		if device.Type != "proxy" || device.Bind == "container" {
+			continue
+		}
+		protocol, ip, port, ok := parseProxyEndpoint(device.Listen)
+		_, _, connectPort, connectOK := parseProxyEndpoint(device.Connect)
+		if ok && connectOK && protocol == "tcp" && port == strconv.Itoa(hostPort) && connectPort == strconv.Itoa(containerPort) {
+			return name, ip, true
+		}
+	}
+	return "", "", false
+}
+
+// exposeContainer forwards a port, opens the firewall and sets up the
+// reverse proxy site of a container service
+func exposeContainer(ctx context.Context, manager ExposeManager, containerName string, opts ExposeOptions, out io.Writer) error {
+	if err := validateExposeOptions(&opts); err != nil {
+		return err
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	// 1. Port forward, kept when an earlier run created it on the same address
+	via, containerPort := strconv.Itoa(opts.Via), strconv.Itoa(opts.ContainerPort)
+	listenIP := exposeListenIP(opts)
+	existing, existingIP, found := findPortForward(ctx, manager, containerName, opts.Via, opts.ContainerPort)
+	if found && existingIP == listenIP {
+		logger.Info("Host port %s already forwards to %s:%s", via, containerName, containerPort)
+	} else {
+		if found {
+			// A public forward would let clients skip the proxy, so it is replaced
+			logger.Info("Moving the forward of host port %s from %s to %s", via, existingIP, listenIP)
+			if err := manager.RunLXCCommand(ctx, "lxc", "config", "device", "remove", containerName, existing); err != nil {
+				return fmt.Errorf("failed to remove the forward of host port %s: %w", via, err)
+			}
+		}
+		deviceName := portDeviceName(containerName, via, containerPort, "tcp")
+		if err := addPortProxyDevice(ctx, manager, containerName, deviceName, listenIP, via, containerPort, "tcp", opts.Force); err != nil {
+			return err
+		}
+	}
+
+	// 2. Reverse proxy site and certificate
+	var sitePath string
+	if opts.Domain != "" {
+		site := helpers.ProxySite{
+			Container: containerName,
+			Domain:    opts.Domain,
+			Upstream:  opts.Via,
+			TLS:       opts.TLS,
+			ACMEEmail: opts.ACMEEmail,
+		}
+		if opts.TLS == helpers.TLSSelfSigned {
+			certFile, keyFile, err := manager.EnsureSelfSignedCert(opts.Domain)
+			if err != nil {
+				return err
+			}
+			site.CertFile, site.KeyFile = certFile, keyFile
+		}
+		path, err := manager.WriteProxySite(site)
+		if err != nil {
+			return err
+		}
+		sitePath = path
+		logger.Info("Wrote reverse proxy config %s", path)
+	}
+
+	// 3. Host firewall; a failure here leaves the forward in place, so it
+	// only warns and prints what to run instead
+	ports := exposePublicPorts(opts)
+	firewall, err := manager.DetectFirewall(ctx)
+	if err != nil {
+		logger.Warn("Could not detect the host firewall: %v", err)
+	}
+	comment := "lxc-go-cli expose " + containerName
+	firewallStatus := "no active firewall found"
+	if firewall != "" {
+		firewallStatus = "opened in " + firewall
+		var opened []string
+		for _, port := range ports {
+			if err := manager.OpenFirewallPort(ctx, firewall, port, "tcp", comment); err != nil {
+				logger.Warn("Could not open %d/tcp in %s: %v", port, firewall, err)
+				for _, argv := range helpers.FirewallOpenCommands(firewall, port, "tcp", comment) {
+					logger.Warn("Run it yourself with: sudo %s", strings.Join(argv, " "))
+				}
+				firewallStatus = "not opened, see the warnings above"
+				continue
+			}
+			opened = append(opened, fmt.Sprintf("%d/tcp", port))
+		}
+		recordExposedPorts(ctx, manager, containerName, opened)
+	} else if err == nil {
+		logger.Info("No active ufw or firewalld found; leaving the host firewall alone")
+	}
+
+	printExposeSummary(out, containerName, opts, ports, firewallStatus, sitePath)
+	return nil
+}
+
+// recordExposedPorts adds the firewall ports opened for a container to its
+// config, so deleting the container closes them again
+func recordExposedPorts(ctx context.Context, manager ContainerPortManager, containerName string, opened []string) {
+	if len(opened) == 0 {
+		return
+	}
+	var config ContainerConfig
+	if configData, err := manager.GetContainerConfig(ctx, containerName); err == nil {
+		yaml.Unmarshal(configData, &config)
+	}
+	recorded := helpers.SplitConfigList(config.Config[helpers.ExposedPortsKey])
+	changed := false
+	for _, rule := range opened {
+		if !slices.Contains(recorded, rule) {
+			recorded = append(recorded, rule)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := manager.RunLXCCommand(ctx, "lxc", "config", "set", containerName, helpers.ExposedPortsKey+"="+strings.Join(recorded, ",")); err != nil {
+		logger.Warn("Could not record the opened ports of '%s'; close them yourself after deleting it: %v", containerName, err)
+	}
+}
+
+// printExposeSummary prints what expose set up and how to finish it
+func printExposeSummary(out io.Writer, containerName string, opts ExposeOptions, ports []int, firewallStatus, sitePath string) {
+	portList := make([]string, len(ports))
+	for i, port := range ports {
+		portList[i] = fmt.Sprintf("%d/tcp", port)
+	}
+
+	fmt.Fprintf(out, "Exposed %s:%d\n", containerName, opts.ContainerPort)
+	fmt.Fprintf(out, "  Forward:  host %s:%d -> %s:%d\n", exposeListenIP(opts), opts.Via, containerName, opts.ContainerPort)
+	fmt.Fprintf(out, "  Firewall: %s (%s)\n", strings.Join(portList, ", "), firewallStatus)
+	if sitePath != "" {
+		fmt.Fprintf(out, "  Proxy:    %s (tls: %s)\n", sitePath, opts.TLS)
+	}
+	fmt.Fprintf(out, "  URL:      %s\n", exposeURL(opts))
+
+	if sitePath != "" {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "To serve the site, add this line to your Caddyfile and reload Caddy:")
+		fmt.Fprintf(out, "  import %s\n", filepath.Join(filepath.Dir(sitePath), "*.caddy"))
+		if opts.TLS == helpers.TLSSelfSigned {
+			fmt.Fprintln(out, "Browsers will warn about the self-signed certificate until it is trusted.")
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exposeCmd)
+
+	exposeCmd.Flags().IntVar(&exposeVia, "via", 0, "Host port to forward (default: the container port)")
+	exposeCmd.Flags().StringVar(&exposeDomain, "domain", "", "Domain to serve through the reverse proxy")
+	exposeCmd.Flags().StringVar(&exposeTLS, "tls", "", "TLS for the domain: none, self-signed or acme (default none)")
+	exposeCmd.Flags().StringVar(&exposeACMEEmail, "acme-email", "", "Contact email for the ACME account (with --tls acme)")
+	exposeCmd.Flags().BoolVarP(&exposeForce, "force", "f", false, "Skip the host port availability check")
+	exposeCmd.Flags().DurationVarP(&exposeTimeout, "timeout", "t", 60*time.Second, "Timeout for the expose operation")
+}
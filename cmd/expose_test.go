@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockExposeManager for testing expose command
+type MockExposeManager struct {
+	MockContainerPortManager
+	Firewall    string
+	FirewallErr error
+	Opened      []int
+	Sites       []helpers.ProxySite
+	Certs       []string
+	// Commands are the lxc commands run, joined with spaces
+	Commands []string
+}
+
+func (m *MockExposeManager) DetectFirewall(ctx context.Context) (string, error) {
+	return m.Firewall, nil
+}
+
+func (m *MockExposeManager) OpenFirewallPort(ctx context.Context, firewall string, port int, protocol, comment string) error {
+	if m.FirewallErr != nil {
+		return m.FirewallErr
+	}
+	m.Opened = append(m.Opened, port)
+	return nil
+}
+
+func (m *MockExposeManager) EnsureSelfSignedCert(domain string) (string, string, error) {
+	m.Certs = append(m.Certs, domain)
+	return "/certs/" + domain + ".crt", "/certs/" + domain + ".key", nil
+}
+
+func (m *MockExposeManager) WriteProxySite(site helpers.ProxySite) (string, error) {
+	m.Sites = append(m.Sites, site)
+	return "/config/expose/" + site.Domain + ".caddy", nil
+}
+
+func newMockExposeManager() *MockExposeManager {
+	m := &MockExposeManager{
+		MockContainerPortManager: MockContainerPortManager{ExistingContainers: map[string]bool{"web": true}},
+		Firewall:                 helpers.FirewallUFW,
+	}
+	m.RunLXCCommandFunc = func(ctx context.Context, args ...string) error {
+		m.Commands = append(m.Commands, strings.Join(args, " "))
+		return nil
+	}
+	return m
+}
+
+func TestValidateExposeOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  ExposeOptions
+		error string
+	}{
+		{"via defaults to container port", ExposeOptions{ContainerPort: 80}, ""},
+		{"invalid container port", ExposeOptions{ContainerPort: 70000}, "container port"},
+		{"invalid via", ExposeOptions{ContainerPort: 80, Via: -1}, "--via"},
+		{"tls without domain", ExposeOptions{ContainerPort: 80, TLS: helpers.TLSSelfSigned}, "--tls requires --domain"},
+		{"invalid domain", ExposeOptions{ContainerPort: 80, Via: 8080, Domain: "app local"}, "invalid domain"},
+		{"invalid tls", ExposeOptions{ContainerPort: 80, Via: 8080, Domain: "app.local", TLS: "letsencrypt"}, "invalid TLS mode"},
+		{"email without acme", ExposeOptions{ContainerPort: 80, Via: 8080, Domain: "app.local", ACMEEmail: "ops@example.com"}, "--tls acme"},
+		{"via clashes with proxy", ExposeOptions{ContainerPort: 80, Domain: "app.local"}, "taken by the reverse proxy"},
+		{"acme", ExposeOptions{ContainerPort: 80, Via: 8080, Domain: "app.example.com", TLS: helpers.TLSACME, ACMEEmail: "ops@example.com"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := validateExposeOptions(&opts)
+			if tt.error == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if opts.Via == 0 {
+					t.Error("expected --via to default to the container port")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestExposeContainer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("port only", func(t *testing.T) {
+		manager := newMockExposeManager()
+		var out bytes.Buffer
+		if err := exposeContainer(ctx, manager, "web", ExposeOptions{ContainerPort: 80, Via: 18080, Force: true}, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"lxc config device add web web-18080-80-tcp proxy connect=tcp:0.0.0.0:80 listen=tcp:0.0.0.0:18080",
+			"lxc config set web " + helpers.ExposedPortsKey + "=18080/tcp",
+		}
+		if fmt.Sprint(manager.Commands) != fmt.Sprint(want) {
+			t.Errorf("expected the proxy device and the opened port recorded, ran %q", manager.Commands)
+		}
+		if fmt.Sprint(manager.Opened) != "[18080]" || len(manager.Sites) != 0 {
+			t.Errorf("expected only 18080 opened and no site, got %v, %v", manager.Opened, manager.Sites)
+		}
+		if !strings.Contains(out.String(), "http://<host>:18080") {
+			t.Errorf("expected the URL in the summary:\n%s", out.String())
+		}
+	})
+
+	t.Run("domain with self-signed tls", func(t *testing.T) {
+		manager := newMockExposeManager()
+		var out bytes.Buffer
+		opts := ExposeOptions{ContainerPort: 80, Via: 18080, Domain: "app.local", TLS: helpers.TLSSelfSigned, Force: true}
+		if err := exposeContainer(ctx, manager, "web", opts, &out); err != nil {
+			t.Fatal(err)
+		}
+		if len(manager.Commands) == 0 || manager.Commands[0] != "lxc config device add web web-18080-80-tcp proxy connect=tcp:0.0.0.0:80 listen=tcp:127.0.0.1:18080" {
+			t.Errorf("expected the forward to listen on loopback only, ran %q", manager.Commands)
+		}
+		if fmt.Sprint(manager.Certs) != "[app.local]" || len(manager.Sites) != 1 {
+			t.Fatalf("expected a certificate and a site, got %v, %v", manager.Certs, manager.Sites)
+		}
+		if site := manager.Sites[0]; site.Upstream != 18080 || site.CertFile != "/certs/app.local.crt" {
+			t.Errorf("unexpected site %+v", site)
+		}
+		if fmt.Sprint(manager.Opened) != "[80 443]" {
+			t.Errorf("expected 80 and 443 opened, got %v", manager.Opened)
+		}
+		for _, want := range []string{"https://app.local", "import /config/expose/*.caddy", "self-signed"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("expected %q in the summary:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("existing forward is kept", func(t *testing.T) {
+		manager := newMockExposeManager()
+		manager.ContainerConfigs = map[string][]byte{"web": []byte(`devices:
+  web-18080-80-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:80
+    listen: tcp:0.0.0.0:18080
+config:
+  ` + helpers.ExposedPortsKey + `: 18080/tcp
+`)}
+		if err := exposeContainer(ctx, manager, "web", ExposeOptions{ContainerPort: 80, Via: 18080}, &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(manager.Commands) != 0 {
+			t.Errorf("expected no new proxy device nor recorded port, ran %q", manager.Commands)
+		}
+	})
+
+	t.Run("public forward moves behind the proxy", func(t *testing.T) {
+		manager := newMockExposeManager()
+		manager.ContainerConfigs = map[string][]byte{"web": []byte(`devices:
+  web-18080-80-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:80
+    listen: tcp:0.0.0.0:18080
+`)}
+		opts := ExposeOptions{ContainerPort: 80, Via: 18080, Domain: "app.local", Force: true}
+		if err := exposeContainer(ctx, manager, "web", opts, &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"lxc config device remove web web-18080-80-tcp",
+			"lxc config device add web web-18080-80-tcp proxy connect=tcp:0.0.0.0:80 listen=tcp:127.0.0.1:18080",
+			"lxc config set web " + helpers.ExposedPortsKey + "=80/tcp",
+		}
+		if fmt.Sprint(manager.Commands) != fmt.Sprint(want) {
+			t.Errorf("expected the forward to be moved to loopback, ran %q", manager.Commands)
+		}
+	})
+
+	t.Run("firewall failure only warns", func(t *testing.T) {
+		manager := newMockExposeManager()
+		manager.FirewallErr = fmt.Errorf("ufw failed")
+		var out bytes.Buffer
+		if err := exposeContainer(ctx, manager, "web", ExposeOptions{ContainerPort: 80, Via: 18080, Force: true}, &out); err != nil {
+			t.Fatalf("expected a firewall failure not to fail expose, got %v", err)
+		}
+		if !strings.Contains(out.String(), "not opened") {
+			t.Errorf("expected the summary to report the firewall failure:\n%s", out.String())
+		}
+	})
+
+	t.Run("no firewall", func(t *testing.T) {
+		manager := newMockExposeManager()
+		manager.Firewall = ""
+		var out bytes.Buffer
+		if err := exposeContainer(ctx, manager, "web", ExposeOptions{ContainerPort: 80, Via: 18080, Force: true}, &out); err != nil {
+			t.Fatal(err)
+		}
+		if len(manager.Opened) != 0 || !strings.Contains(out.String(), "no active firewall") {
+			t.Errorf("expected no firewall changes, got %v:\n%s", manager.Opened, out.String())
+		}
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		manager := newMockExposeManager()
+		if err := exposeContainer(ctx, manager, "db", ExposeOptions{ContainerPort: 80}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("expected a missing container error, got %v", err)
+		}
+	})
+}
@@ -15,7 +15,9 @@ import (
 )
 
 var (
-	gpuTimeout time.Duration
+	gpuTimeout       time.Duration
+	gpuNoSnapshot    bool
+	gpuSkipHostCheck bool
 )
 
 // gpuCmd represents the gpu command
@@ -29,11 +31,22 @@ This command enables or disables GPU access by:
 - Setting/unsetting privileged mode (required for GPU access)
 - Restarting the container to apply changes
 
+A virtual machine gets the whole GPU passed through (gputype=physical) and
+does not need privileged mode.
+
 Actions:
   enable  - Enable GPU access (adds GPU device and sets privileged mode)
   disable - Disable GPU access (removes GPU device and unsets privileged mode)  
   status  - Show current GPU configuration
 
+Before enabling, the host is checked for a GPU a container can use (render
+nodes under /dev/dri or a working nvidia-smi); lspci is consulted to explain
+what is missing. Use --skip-host-check to enable GPU access anyway. The status
+action lists the GPUs found on the host.
+
+Enabling GPU access takes an automatic pre-gpu-enable snapshot first so the
+change can be reverted with 'lxc-go-cli rollback'. Use --no-snapshot to skip it.
+
 Examples:
   lxc-go-cli gpu mycontainer enable   # Enable GPU access
   lxc-go-cli gpu mycontainer disable  # Disable GPU access
@@ -48,7 +61,7 @@ Examples:
 		defer cancel()
 
 		manager := &DefaultGPUManager{}
-		return handleGPUAction(ctx, manager, containerName, action)
+		return handleGPUAction(ctx, manager, containerName, action, !gpuNoSnapshot, gpuSkipHostCheck)
 	},
 }
 
@@ -59,6 +72,8 @@ type GPUManager interface {
 	EnableGPU(ctx context.Context, containerName string) error
 	DisableGPU(ctx context.Context, containerName string) error
 	RestartContainer(ctx context.Context, name string) error
+	CreateSnapshot(ctx context.Context, containerName, snapshotName string) error
+	DetectHostGPUs(ctx context.Context) ([]helpers.HostGPU, error)
 }
 
 // DefaultGPUManager implements GPUManager using helpers
@@ -81,7 +96,19 @@ func (d *DefaultGPUManager) DisableGPU(ctx context.Context, containerName string
 }
 
 func (d *DefaultGPUManager) RestartContainer(ctx context.Context, name string) error {
-	return helpers.RestartContainer(name)
+	return helpers.RestartContainer(ctx, name)
+}
+
+func (d *DefaultGPUManager) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	return helpers.CreateSnapshot(containerName, snapshotName)
+}
+
+func (d *DefaultGPUManager) DetectHostGPUs(ctx context.Context) ([]helpers.HostGPU, error) {
+	// lspci and /dev/dri describe this machine, not a remote server
+	if err := helpers.RequireLocalServer(ctx, "GPU detection"); err != nil {
+		return nil, err
+	}
+	return helpers.DetectHostGPUs(ctx), nil
 }
 
 // validateGPUArgs validates the arguments for GPU operations
@@ -101,11 +128,13 @@ func validateGPUArgs(containerName, action string) error {
 		}
 	}
 
-	return fmt.Errorf("invalid action '%s': must be 'enable', 'disable', or 'status'", action)
+	return helpers.UsageErrorf("invalid action '%s': must be 'enable', 'disable', or 'status'", action)
 }
 
-// handleGPUAction handles the GPU action for a container
-func handleGPUAction(ctx context.Context, manager GPUManager, containerName, action string) error {
+// handleGPUAction handles the GPU action for a container; snapshot controls whether
+// enabling takes a pre-operation snapshot and skipHostCheck whether it first
+// looks for a usable GPU on the host
+func handleGPUAction(ctx context.Context, manager GPUManager, containerName, action string, snapshot, skipHostCheck bool) error {
 	// Validate arguments
 	if err := validateGPUArgs(containerName, action); err != nil {
 		return err
@@ -113,12 +142,12 @@ func handleGPUAction(ctx context.Context, manager GPUManager, containerName, act
 
 	// Check if container exists
 	if !manager.ContainerExists(ctx, containerName) {
-		return fmt.Errorf("container '%s' does not exist", containerName)
+		return containerNotFound(containerName)
 	}
 
 	switch action {
 	case "enable":
-		return handleGPUEnable(ctx, manager, containerName)
+		return handleGPUEnable(ctx, manager, containerName, snapshot, skipHostCheck)
 	case "disable":
 		return handleGPUDisable(ctx, manager, containerName)
 	case "status":
@@ -129,7 +158,19 @@ func handleGPUAction(ctx context.Context, manager GPUManager, containerName, act
 }
 
 // handleGPUEnable enables GPU access for a container
-func handleGPUEnable(ctx context.Context, manager GPUManager, containerName string) error {
+func handleGPUEnable(ctx context.Context, manager GPUManager, containerName string, snapshot, skipHostCheck bool) error {
+	if !skipHostCheck {
+		if err := checkHostGPUs(ctx, manager); err != nil {
+			return err
+		}
+	}
+
+	if snapshot {
+		if _, err := snapshotBeforeOperation(ctx, manager, containerName, "gpu-enable"); err != nil {
+			return err
+		}
+	}
+
 	logger.Info("Enabling GPU access for container '%s'...", containerName)
 
 	// Enable GPU
@@ -147,6 +188,20 @@ func handleGPUEnable(ctx context.Context, manager GPUManager, containerName stri
 	return nil
 }
 
+// checkHostGPUs fails when the host has no GPU a container could use, so that
+// enabling does not leave a gpu device that cannot start
+func checkHostGPUs(ctx context.Context, manager GPUManager) error {
+	gpus, err := manager.DetectHostGPUs(ctx)
+	if err != nil {
+		logger.Warn("Skipping the host GPU check: %v", err)
+		return nil
+	}
+	if err := helpers.CheckHostGPUs(gpus); err != nil {
+		return fmt.Errorf("%w; use --skip-host-check to enable GPU access anyway", err)
+	}
+	return nil
+}
+
 // handleGPUDisable disables GPU access for a container
 func handleGPUDisable(ctx context.Context, manager GPUManager, containerName string) error {
 	logger.Info("Disabling GPU access for container '%s'...", containerName)
@@ -177,6 +232,14 @@ func handleGPUStatus(ctx context.Context, manager GPUManager, containerName stri
 
 	// Format and display status
 	fmt.Print(helpers.FormatGPUStatus(status))
+
+	gpus, err := manager.DetectHostGPUs(ctx)
+	if err != nil {
+		logger.Debug("Could not detect host GPUs: %v", err)
+		fmt.Printf("Host GPUs:\n  unknown (%v)\n", err)
+		return nil
+	}
+	fmt.Print(helpers.FormatHostGPUs(gpus))
 	return nil
 }
 
@@ -185,5 +248,7 @@ func init() {
 
 	// Add timeout flag
 	gpuCmd.Flags().DurationVarP(&gpuTimeout, "timeout", "t", 60*time.Second, "Timeout for GPU operations")
+	gpuCmd.Flags().BoolVar(&gpuNoSnapshot, "no-snapshot", false, "Skip the snapshot taken before enabling GPU access")
+	gpuCmd.Flags().BoolVar(&gpuSkipHostCheck, "skip-host-check", false, "Enable GPU access even if no usable GPU is found on the host")
 }
 
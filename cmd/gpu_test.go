@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +25,10 @@ type MockGPUManager struct {
 	DisableError       error
 	StatusError        error
 	RestartError       error
+	SnapshotError      error
+	Snapshots          []string
+	HostGPUs           []helpers.HostGPU
+	HostGPUError       error
 }
 
 func NewMockGPUManager() *MockGPUManager {
@@ -31,6 +36,7 @@ func NewMockGPUManager() *MockGPUManager {
 		ExistingContainers: make(map[string]bool),
 		GPUStates:          make(map[string]*helpers.GPUStatus),
 		Calls:              make(map[string]int),
+		HostGPUs:           []helpers.HostGPU{{Source: helpers.GPUSourceRender, Description: "/dev/dri/renderD128"}},
 	}
 }
 
@@ -88,6 +94,20 @@ func (m *MockGPUManager) RestartContainer(ctx context.Context, name string) erro
 	return m.RestartError
 }
 
+func (m *MockGPUManager) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	m.trackCall("CreateSnapshot")
+	if m.SnapshotError != nil {
+		return m.SnapshotError
+	}
+	m.Snapshots = append(m.Snapshots, snapshotName)
+	return nil
+}
+
+func (m *MockGPUManager) DetectHostGPUs(ctx context.Context) ([]helpers.HostGPU, error) {
+	m.trackCall("DetectHostGPUs")
+	return m.HostGPUs, m.HostGPUError
+}
+
 func (m *MockGPUManager) trackCall(method string) {
 	if m.Calls == nil {
 		m.Calls = make(map[string]int)
@@ -280,7 +300,7 @@ func TestHandleGPUAction(t *testing.T) {
 			manager := NewMockGPUManager()
 			manager.ExistingContainers["test-container"] = tt.containerExists
 
-			err := handleGPUAction(ctx, manager, tt.containerName, tt.action)
+			err := handleGPUAction(ctx, manager, tt.containerName, tt.action, false, false)
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -333,7 +353,7 @@ func TestHandleGPUEnable(t *testing.T) {
 			manager.EnableError = tt.enableError
 			manager.RestartError = tt.restartError
 
-			err := handleGPUEnable(ctx, manager, "test-container")
+			err := handleGPUEnable(ctx, manager, "test-container", false, false)
 
 			if tt.expectedErr != "" {
 				if err == nil {
@@ -516,7 +536,7 @@ func TestGPUWithContext(t *testing.T) {
 
 	// Test with background context
 	ctx := context.Background()
-	err := handleGPUAction(ctx, manager, "test-container", "status")
+	err := handleGPUAction(ctx, manager, "test-container", "status", false, false)
 	if err != nil {
 		t.Errorf("should succeed with background context: %v", err)
 	}
@@ -526,7 +546,7 @@ func TestGPUWithContext(t *testing.T) {
 	cancel() // Cancel immediately
 
 	// The function should still work since our mock doesn't respect context cancellation
-	err = handleGPUAction(ctx, manager, "test-container", "status")
+	err = handleGPUAction(ctx, manager, "test-container", "status", false, false)
 	if err != nil {
 		t.Errorf("should work with cancelled context in mock: %v", err)
 	}
@@ -538,7 +558,7 @@ func TestGPUWithContext(t *testing.T) {
 	// Wait for timeout
 	time.Sleep(2 * time.Millisecond)
 
-	err = handleGPUAction(ctx, manager, "test-container", "status")
+	err = handleGPUAction(ctx, manager, "test-container", "status", false, false)
 	if err != nil {
 		t.Errorf("should work with expired timeout in mock: %v", err)
 	}
@@ -550,7 +570,7 @@ func TestGPUIdempotentOperations(t *testing.T) {
 	manager.ExistingContainers["test-container"] = true
 
 	// Test enabling GPU multiple times
-	err := handleGPUEnable(ctx, manager, "test-container")
+	err := handleGPUEnable(ctx, manager, "test-container", false, false)
 	if err != nil {
 		t.Errorf("first enable should succeed: %v", err)
 	}
@@ -558,7 +578,7 @@ func TestGPUIdempotentOperations(t *testing.T) {
 	// Reset call counts for second test
 	manager.Calls = make(map[string]int)
 
-	err = handleGPUEnable(ctx, manager, "test-container")
+	err = handleGPUEnable(ctx, manager, "test-container", false, false)
 	if err != nil {
 		t.Errorf("second enable should succeed (idempotent): %v", err)
 	}
@@ -584,19 +604,71 @@ func TestGPUActionCaseHandling(t *testing.T) {
 	manager.ExistingContainers["test-container"] = true
 
 	// Test that action is case-sensitive in current implementation
-	err := handleGPUAction(ctx, manager, "test-container", "ENABLE")
+	err := handleGPUAction(ctx, manager, "test-container", "ENABLE", false, false)
 	if err == nil {
 		t.Error("should fail with uppercase action (case sensitive)")
 	}
 
-	err = handleGPUAction(ctx, manager, "test-container", "Enable")
+	err = handleGPUAction(ctx, manager, "test-container", "Enable", false, false)
 	if err == nil {
 		t.Error("should fail with mixed case action (case sensitive)")
 	}
 
 	// But lowercase should work
-	err = handleGPUAction(ctx, manager, "test-container", "enable")
+	err = handleGPUAction(ctx, manager, "test-container", "enable", false, false)
 	if err != nil {
 		t.Errorf("should succeed with lowercase action: %v", err)
 	}
 }
+
+func TestHandleGPUEnableSnapshot(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMockGPUManager()
+
+	if err := handleGPUEnable(ctx, manager, "test-container", true, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Snapshots) != 1 || !strings.HasPrefix(manager.Snapshots[0], "pre-gpu-enable-") {
+		t.Errorf("expected a pre-gpu-enable snapshot, got %v", manager.Snapshots)
+	}
+
+	// A failed snapshot must stop the change from being made
+	manager = NewMockGPUManager()
+	manager.SnapshotError = fmt.Errorf("quota exceeded")
+	err := handleGPUEnable(ctx, manager, "test-container", true, false)
+	if err == nil || !contains(err.Error(), "failed to snapshot container 'test-container' before gpu-enable") {
+		t.Errorf("expected snapshot error, got %v", err)
+	}
+	if manager.GetCallCount("EnableGPU") != 0 {
+		t.Error("EnableGPU should not be called when the snapshot fails")
+	}
+}
+
+func TestHandleGPUEnableHostCheck(t *testing.T) {
+	ctx := context.Background()
+
+	manager := NewMockGPUManager()
+	manager.HostGPUs = nil
+	err := handleGPUEnable(ctx, manager, "test-container", false, false)
+	if err == nil || !contains(err.Error(), "no GPU found") || !contains(err.Error(), "--skip-host-check") {
+		t.Errorf("expected host check error, got %v", err)
+	}
+	if manager.GetCallCount("EnableGPU") != 0 {
+		t.Error("EnableGPU should not be called without a host GPU")
+	}
+
+	// The escape hatch skips detection entirely
+	if err := handleGPUEnable(ctx, manager, "test-container", false, true); err != nil {
+		t.Errorf("expected --skip-host-check to enable anyway, got %v", err)
+	}
+	if manager.GetCallCount("DetectHostGPUs") != 1 {
+		t.Errorf("expected detection to be skipped, got %d calls", manager.GetCallCount("DetectHostGPUs"))
+	}
+
+	// When the host cannot be inspected, e.g. on a remote server, enabling goes ahead
+	manager = NewMockGPUManager()
+	manager.HostGPUError = fmt.Errorf("GPU detection needs to run on the LXD host")
+	if err := handleGPUEnable(ctx, manager, "test-container", false, false); err != nil {
+		t.Errorf("expected enable to proceed, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostsTimeout time.Duration
+	hostsHost    bool
+	hostsDryRun  bool
+)
+
+// hostsCmd represents the hosts command
+var hostsCmd = &cobra.Command{
+	Use:   "hosts <sync>",
+	Short: "Manage /etc/hosts entries for managed containers",
+	Long: `Let managed containers reach each other by name without relying on the
+DNS of the LXD bridge.
+
+  sync    writes the names and addresses of all managed containers into the
+          /etc/hosts of every running managed container, and of the host
+          with --host
+
+Examples:
+  lxc-go-cli hosts sync
+  sudo lxc-go-cli hosts sync --host
+  lxc-go-cli hosts sync --dry-run`,
+}
+
+// hostsSyncCmd represents the hosts sync subcommand
+var hostsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Write all managed containers' names and addresses into /etc/hosts",
+	Long: `Write a line per address, IPv4 and IPv6, of every managed container into
+the /etc/hosts of each running managed container, so they can reach each
+other by name. With --host the host's /etc/hosts is updated too, which needs
+root and a local LXD server.
+
+The entries go in a block between '# BEGIN lxc-go-cli managed containers'
+and '# END lxc-go-cli managed containers' lines that is replaced on every
+sync; the rest of the file is left alone. Stopped containers have no
+address and are left out, so run sync again after containers are created,
+started or deleted.
+
+Examples:
+  lxc-go-cli hosts sync
+  sudo lxc-go-cli hosts sync --host
+  lxc-go-cli hosts sync --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), hostsTimeout)
+		defer cancel()
+
+		if hostsHost {
+			if err := helpers.RequireLocalServer(ctx, "hosts sync --host"); err != nil {
+				return err
+			}
+		}
+		return syncHosts(ctx, &DefaultHostsManager{}, hostsHost, hostsDryRun, cmd.OutOrStdout())
+	},
+}
+
+// HostsManager interface for dependency injection
+type HostsManager interface {
+	ListManager
+	ReadContainerHosts(ctx context.Context, name string) (string, error)
+	WriteContainerHosts(ctx context.Context, name, content string) error
+	ReadHostHosts() (string, error)
+	WriteHostHosts(content string) error
+}
+
+// DefaultHostsManager implements HostsManager using helpers
+type DefaultHostsManager struct {
+	DefaultListManager
+}
+
+func (d *DefaultHostsManager) ReadContainerHosts(ctx context.Context, name string) (string, error) {
+	return helpers.ReadContainerHosts(ctx, name)
+}
+
+func (d *DefaultHostsManager) WriteContainerHosts(ctx context.Context, name, content string) error {
+	return helpers.WriteContainerHosts(ctx, name, content)
+}
+
+func (d *DefaultHostsManager) ReadHostHosts() (string, error) {
+	return helpers.ReadHostHosts()
+}
+
+func (d *DefaultHostsManager) WriteHostHosts(content string) error {
+	return helpers.WriteHostHosts(content)
+}
+
+// syncHosts writes the managed containers' entries into the hosts file of
+// every running managed container, and of the host with host. Containers
+// that fail are reported and the others still updated.
+func syncHosts(ctx context.Context, manager HostsManager, host, dryRun bool, out io.Writer) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	entries := helpers.HostsEntries(states)
+	block := helpers.HostsBlock(entries)
+	var targets []string
+	for _, state := range helpers.WithoutTrash(states) {
+		if helpers.IsManagedContainer(state.Config) && state.Status == "Running" {
+			targets = append(targets, state.Name)
+		}
+	}
+
+	if dryRun {
+		fmt.Fprint(out, block)
+		logger.Info("Would update /etc/hosts in %d container(s)", len(targets))
+		if host {
+			logger.Info("Would update %s on the host", helpers.HostHostsFile)
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		logger.Warn("No managed container has an address; clearing the entries instead")
+	}
+
+	updated, failed := 0, 0
+	for _, name := range targets {
+		changed, err := syncHostsFile(func() (string, error) { return manager.ReadContainerHosts(ctx, name) },
+			func(content string) error { return manager.WriteContainerHosts(ctx, name, content) }, block)
+		switch {
+		case err != nil:
+			logger.Error("Container '%s': %v", name, err)
+			failed++
+		case changed:
+			logger.Info("Updated /etc/hosts in container '%s'", name)
+			updated++
+		default:
+			logger.Debug("/etc/hosts in container '%s' is up to date", name)
+		}
+	}
+	if host {
+		changed, err := syncHostsFile(manager.ReadHostHosts, manager.WriteHostHosts, block)
+		switch {
+		case err != nil:
+			logger.Error("Host: %v", err)
+			failed++
+		case changed:
+			logger.Info("Updated %s on the host", helpers.HostHostsFile)
+		}
+	}
+
+	logger.Info("Synced %d entries to %d container(s): %d updated, %d up to date", len(entries), len(targets), updated, len(targets)-updated-failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d hosts file(s)", failed)
+	}
+	return nil
+}
+
+// syncHostsFile replaces the managed block of one hosts file, writing it
+// only when it changes
+func syncHostsFile(read func() (string, error), write func(string) error, block string) (bool, error) {
+	content, err := read()
+	if err != nil {
+		return false, err
+	}
+	synced := helpers.ReplaceHostsBlock(content, block)
+	if synced == content {
+		return false, nil
+	}
+	return true, write(synced)
+}
+
+func init() {
+	rootCmd.AddCommand(hostsCmd)
+	hostsCmd.AddCommand(hostsSyncCmd)
+
+	hostsCmd.PersistentFlags().DurationVarP(&hostsTimeout, "timeout", "t", 2*time.Minute, "Timeout for the hosts operation")
+	hostsSyncCmd.Flags().BoolVar(&hostsHost, "host", false, "Also update the host's /etc/hosts (needs root)")
+	hostsSyncCmd.Flags().BoolVar(&hostsDryRun, "dry-run", false, "Print the entries without writing any file")
+}
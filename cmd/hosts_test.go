@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockHostsManager for testing hosts sync
+type MockHostsManager struct {
+	*MockListManager
+	Files     map[string]string
+	Written   map[string]string
+	ReadError map[string]error
+	Host      string
+	HostWrote bool
+}
+
+func newMockHostsManager() *MockHostsManager {
+	list := &MockListManager{States: []helpers.ContainerState{
+		{Name: "web", Status: "Running", IPv4: "10.0.0.2", IPv6Addresses: []string{"fd42::2"}, Config: map[string]string{helpers.ManagedMarkerKey: "true"}},
+		{Name: "db", Status: "Running", IPv4: "10.0.0.3", Config: map[string]string{helpers.ManagedMarkerKey: "true"}},
+		{Name: "old", Status: "Stopped", Config: map[string]string{helpers.ManagedMarkerKey: "true"}},
+		{Name: "unrelated", Status: "Running", IPv4: "10.0.0.9", Config: map[string]string{}},
+	}}
+	return &MockHostsManager{
+		MockListManager: list,
+		Files: map[string]string{
+			"web": "127.0.0.1 localhost\n",
+			"db":  "127.0.0.1 localhost\n",
+		},
+		Written:   map[string]string{},
+		ReadError: map[string]error{},
+		Host:      "127.0.0.1 localhost\n",
+	}
+}
+
+func (m *MockHostsManager) ReadContainerHosts(ctx context.Context, name string) (string, error) {
+	if err := m.ReadError[name]; err != nil {
+		return "", err
+	}
+	return m.Files[name], nil
+}
+
+func (m *MockHostsManager) WriteContainerHosts(ctx context.Context, name, content string) error {
+	m.Written[name] = content
+	m.Files[name] = content
+	return nil
+}
+
+func (m *MockHostsManager) ReadHostHosts() (string, error) {
+	return m.Host, nil
+}
+
+func (m *MockHostsManager) WriteHostHosts(content string) error {
+	m.Host = content
+	m.HostWrote = true
+	return nil
+}
+
+func TestSyncHosts(t *testing.T) {
+	manager := newMockHostsManager()
+	var out bytes.Buffer
+	if err := syncHosts(context.Background(), manager, true, false, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Written) != 2 || !manager.HostWrote {
+		t.Fatalf("expected both running containers and the host to be updated, got %v (host: %v)", manager.Written, manager.HostWrote)
+	}
+	web := manager.Written["web"]
+	for _, want := range []string{"127.0.0.1 localhost\n", "10.0.0.3  db\n", "10.0.0.2  web\n", "fd42::2   web\n", helpers.HostsBlockEnd} {
+		if !strings.Contains(web, want) {
+			t.Errorf("expected %q in the hosts file, got:\n%s", want, web)
+		}
+	}
+	if strings.Contains(web, "unrelated") || strings.Contains(web, "old") {
+		t.Errorf("expected only managed containers with an address, got:\n%s", web)
+	}
+	if manager.Host != web {
+		t.Errorf("expected the host to get the same entries, got:\n%s", manager.Host)
+	}
+
+	// A second sync changes nothing
+	manager.Written = map[string]string{}
+	manager.HostWrote = false
+	if err := syncHosts(context.Background(), manager, true, false, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Written) != 0 || manager.HostWrote {
+		t.Errorf("expected unchanged files to be left alone, got %v (host: %v)", manager.Written, manager.HostWrote)
+	}
+}
+
+func TestSyncHostsDryRun(t *testing.T) {
+	manager := newMockHostsManager()
+	var out bytes.Buffer
+	if err := syncHosts(context.Background(), manager, true, true, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Written) != 0 || manager.HostWrote {
+		t.Errorf("expected a dry run to write nothing, got %v", manager.Written)
+	}
+	if !strings.Contains(out.String(), "10.0.0.2  web") {
+		t.Errorf("expected the entries to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestSyncHostsContinuesAfterFailure(t *testing.T) {
+	manager := newMockHostsManager()
+	manager.ReadError["db"] = errors.New("container is busy")
+	err := syncHosts(context.Background(), manager, false, false, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "failed to update 1 hosts file") {
+		t.Errorf("expected the failure to be reported, got %v", err)
+	}
+	if _, ok := manager.Written["web"]; !ok || manager.HostWrote {
+		t.Errorf("expected the other container, and not the host, to be updated, got %v", manager.Written)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imagesTimeout   time.Duration
+	imagesUnusedFor time.Duration
+	imagesDryRun    bool
+	imagesTable     tableFlags
+)
+
+// imagesCmd represents the images command
+var imagesCmd = &cobra.Command{
+	Use:   "images <list|prune>",
+	Short: "List and prune the images cached by the server",
+	Long: `List and prune the images in the server's image store.
+
+'create' checks the store before launching and downloads a missing image as a
+step of its own, reporting its progress. Downloaded images stay in the store so
+later containers start without downloading them again; 'images prune' removes
+the ones no container has been created from in a while.
+
+Examples:
+  lxc-go-cli images list
+  lxc-go-cli images prune --unused-for 168h --dry-run`,
+}
+
+// imagesListCmd represents the images list subcommand
+var imagesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the images in the server's image store",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), imagesTimeout)
+		defer cancel()
+
+		out := cmd.OutOrStdout()
+		return listImages(ctx, &DefaultImagesManager{}, out, imagesTable.options(out))
+	},
+}
+
+// imagesPruneCmd represents the images prune subcommand
+var imagesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete images not used for a while",
+	Long: `Delete images no container has been created from within --unused-for.
+
+Images with a local alias were imported or named on purpose and are kept.
+Existing containers do not need the image they were created from.
+
+Examples:
+  lxc-go-cli images prune
+  lxc-go-cli images prune --unused-for 168h --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), imagesTimeout)
+		defer cancel()
+
+		return pruneImages(ctx, &DefaultImagesManager{}, time.Now().Add(-imagesUnusedFor), imagesDryRun)
+	},
+}
+
+// ImagesManager interface for dependency injection
+type ImagesManager interface {
+	ListImages(ctx context.Context) ([]helpers.LocalImage, error)
+	DeleteImage(ctx context.Context, fingerprint string) error
+}
+
+// DefaultImagesManager implements ImagesManager using helpers
+type DefaultImagesManager struct{}
+
+func (d *DefaultImagesManager) ListImages(ctx context.Context) ([]helpers.LocalImage, error) {
+	return helpers.ListImages(ctx)
+}
+
+func (d *DefaultImagesManager) DeleteImage(ctx context.Context, fingerprint string) error {
+	return helpers.DeleteImage(ctx, fingerprint)
+}
+
+// listImages prints the images in the store, newest first
+func listImages(ctx context.Context, manager ImagesManager, out io.Writer, opts render.Options) error {
+	images, err := manager.ListImages(ctx)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		fmt.Fprintln(out, "No images found")
+		return nil
+	}
+
+	table := render.NewTable("FINGERPRINT", "SOURCE", "DESCRIPTION", "ARCH", "SIZE", "CACHED", "LAST USED")
+	for _, image := range images {
+		cached := "no"
+		if image.Cached {
+			cached = "yes"
+		}
+		table.AddRow(
+			helpers.ShortFingerprint(image.Fingerprint),
+			valueOrDash(imageSource(image)),
+			valueOrDash(image.Description),
+			valueOrDash(image.Architecture),
+			helpers.FormatBytes(image.Size),
+			cached,
+			formatImageTime(image.LastUsedAt),
+		)
+	}
+	return table.Render(out, opts)
+}
+
+// imageSource names an image by its local aliases, else by where it came from
+func imageSource(image helpers.LocalImage) string {
+	if len(image.Aliases) > 0 {
+		return strings.Join(image.Aliases, ",")
+	}
+	if image.Source.Alias == "" {
+		return ""
+	}
+	return image.Source.Alias + " (" + image.Source.Server + ")"
+}
+
+// formatImageTime renders an image timestamp, "never" for the zero time
+func formatImageTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// pruneImages deletes images not used since cutoff
+func pruneImages(ctx context.Context, manager ImagesManager, cutoff time.Time, dryRun bool) error {
+	images, err := manager.ListImages(ctx)
+	if err != nil {
+		return err
+	}
+
+	prune := helpers.ImagesToPrune(images, cutoff)
+	if len(prune) == 0 {
+		logger.Info("Nothing to prune: every image was used since %s", formatImageTime(cutoff))
+		return nil
+	}
+	if !dryRun {
+		if err := confirm("images.prune_confirm", len(prune), formatImageTime(cutoff)); err != nil {
+			return err
+		}
+	}
+
+	var freed int64
+	for _, image := range prune {
+		name := helpers.ShortFingerprint(image.Fingerprint)
+		if source := imageSource(image); source != "" {
+			name += " " + source
+		}
+		if dryRun {
+			logger.Info("Would delete image %s (%s)", name, helpers.FormatBytes(image.Size))
+			continue
+		}
+		logger.Info("Deleting image %s...", name)
+		if err := manager.DeleteImage(ctx, image.Fingerprint); err != nil {
+			return err
+		}
+		freed += image.Size
+	}
+
+	if !dryRun {
+		logger.Info("Pruned %d image(s), freeing %s", len(prune), helpers.FormatBytes(freed))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+	imagesCmd.AddCommand(imagesListCmd)
+	imagesCmd.AddCommand(imagesPruneCmd)
+
+	imagesCmd.PersistentFlags().DurationVarP(&imagesTimeout, "timeout", "t", 2*time.Minute, "Timeout for the image operation")
+	imagesPruneCmd.Flags().DurationVar(&imagesUnusedFor, "unused-for", 30*24*time.Hour, "Delete images not used for this long")
+	imagesPruneCmd.Flags().BoolVar(&imagesDryRun, "dry-run", false, "Only show the images that would be deleted")
+	addTableFlags(imagesListCmd, &imagesTable)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockImagesManager for testing images command
+type MockImagesManager struct {
+	Images  []helpers.LocalImage
+	Deleted []string
+}
+
+func (m *MockImagesManager) ListImages(ctx context.Context) ([]helpers.LocalImage, error) {
+	return m.Images, nil
+}
+
+func (m *MockImagesManager) DeleteImage(ctx context.Context, fingerprint string) error {
+	m.Deleted = append(m.Deleted, fingerprint)
+	return nil
+}
+
+func newMockImagesManager(now time.Time) *MockImagesManager {
+	return &MockImagesManager{Images: []helpers.LocalImage{
+		{
+			Fingerprint:  "8f1d3a0b7c2e4f5a6b7c",
+			Description:  "ubuntu 24.04 LTS amd64",
+			Architecture: "x86_64",
+			Size:         250 * 1024 * 1024,
+			Cached:       true,
+			LastUsedAt:   now.Add(-90 * 24 * time.Hour),
+			Source:       helpers.ImageSource{Server: "https://cloud-images.ubuntu.com/releases", Alias: "24.04"},
+		},
+		{Fingerprint: "1234567890abcdef", Aliases: []string{"golden"}, Size: 1024, UploadedAt: now.Add(-365 * 24 * time.Hour)},
+	}}
+}
+
+func TestImagesCommand(t *testing.T) {
+	if imagesCmd.Use != "images <list|prune>" {
+		t.Errorf("unexpected Use: %s", imagesCmd.Use)
+	}
+	for _, name := range []string{"list", "prune"} {
+		if _, _, err := imagesCmd.Find([]string{name}); err != nil {
+			t.Errorf("expected subcommand '%s': %v", name, err)
+		}
+	}
+	if flag := imagesPruneCmd.Flags().Lookup("unused-for"); flag == nil || flag.DefValue != "720h0m0s" {
+		t.Errorf("expected --unused-for to default to 30 days, got %v", flag)
+	}
+}
+
+func TestListImages(t *testing.T) {
+	manager := newMockImagesManager(time.Now())
+
+	var out bytes.Buffer
+	if err := listImages(context.Background(), manager, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, expected := range []string{"FINGERPRINT", "8f1d3a0b7c2e", "24.04 (https://cloud-images.ubuntu.com/releases)", "250.0MiB", "golden", "never"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := listImages(context.Background(), &MockImagesManager{}, &out, render.Options{}); err != nil || out.String() != "No images found\n" {
+		t.Errorf("expected no images message, got %q, %v", out.String(), err)
+	}
+}
+
+func TestPruneImages(t *testing.T) {
+	now := time.Now()
+	manager := newMockImagesManager(now)
+	cutoff := now.Add(-30 * 24 * time.Hour)
+
+	if err := pruneImages(context.Background(), manager, cutoff, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 0 {
+		t.Errorf("dry run should delete nothing, deleted %v", manager.Deleted)
+	}
+
+	if err := pruneImages(context.Background(), manager, cutoff, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.Deleted, ",") != "8f1d3a0b7c2e4f5a6b7c" {
+		t.Errorf("expected only the stale unaliased image to be deleted, got %v", manager.Deleted)
+	}
+}
+
+func TestImageStep(t *testing.T) {
+	var out bytes.Buffer
+	progress = NewProgressReporter(&out)
+	t.Cleanup(func() { progress = nil })
+
+	manager := &MockContainerManager{
+		FindLocalImageFunc: func(ctx context.Context, image string) (*helpers.LocalImage, error) {
+			return nil, nil
+		},
+		DownloadImageFunc: func(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error) {
+			if image != "ubuntu:24.04" {
+				t.Errorf("unexpected image %s", image)
+			}
+			onProgress(helpers.ImageProgress{Stage: "rootfs", Percent: 40, Processed: 4096})
+			return "8f1d3a0b7c2e4f5a", nil
+		},
+	}
+	if err := imageStep(manager, "ubuntu:24.04").Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	events := decodeProgress(t, out.String())
+	if len(events) != 1 || events[0].Event != EventDownload || events[0].Stage != "rootfs" || *events[0].Percent != 40 || events[0].Bytes != 4096 {
+		t.Errorf("expected a download progress event, got %+v", events)
+	}
+
+	manager.DownloadImageFunc = func(ctx context.Context, image string, onProgress func(helpers.ImageProgress)) (string, error) {
+		return "", fmt.Errorf("no such alias")
+	}
+	if err := imageStep(manager, "ubuntu:24.04").Run(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to download image") {
+		t.Errorf("expected a download error, got %v", err)
+	}
+
+	// Launch downloads the image itself when the store cannot be checked
+	manager.FindLocalImageFunc = func(ctx context.Context, image string) (*helpers.LocalImage, error) {
+		return nil, fmt.Errorf("query failed")
+	}
+	if err := imageStep(manager, "ubuntu:24.04").Run(context.Background()); err != nil {
+		t.Errorf("expected a failed check to be ignored, got %v", err)
+	}
+}
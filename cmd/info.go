@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var infoTimeout time.Duration
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   "info [container-name]",
+	Short: "Show status, addresses and port forwarding of a container",
+	Long: `Show a summary of a container: whether it is a container or a virtual
+machine, its status (including whether it is paused or ephemeral), addresses,
+memory and disk usage, management marker and port forwarding rules. On a
+cluster it also shows the member the container runs on.
+Without a name the current container chosen with 'use' is shown.
+
+Examples:
+  lxc-go-cli info mycontainer`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := containerArg(args)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+		defer cancel()
+
+		return showContainerInfo(ctx, &DefaultListManager{}, name, cmd.OutOrStdout())
+	},
+}
+
+// showContainerInfo prints a summary of a single container
+func showContainerInfo(ctx context.Context, manager ListManager, name string, out io.Writer) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+
+	printActiveContext(out)
+	fmt.Fprint(out, formatContainerInfo(state, render.StyleFor(out)))
+	if state.Status == helpers.StatusFrozen {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, frozenNotice(name))
+	}
+	return nil
+}
+
+// formatContainerInfo renders a container's details followed by its port mappings
+func formatContainerInfo(state *helpers.ContainerState, style render.Style) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	status := statusLabel(*state)
+	if state.Status == helpers.StatusFrozen {
+		status += " (paused)"
+	}
+	memory := "-"
+	if state.MemoryUsage > 0 {
+		memory = helpers.FormatBytes(state.MemoryUsage)
+	}
+
+	devices := make(map[string]Device, len(state.Devices))
+	for deviceName, device := range state.Devices {
+		devices[deviceName] = Device{Type: device["type"], Connect: device["connect"], Listen: device["listen"], Bind: device["bind"]}
+	}
+	mappings := portMappingsFromDevices(state.Config, devices, state.Name)
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].DeviceName < mappings[j].DeviceName })
+
+	fmt.Fprintf(w, "Name:\t%s\n", state.Name)
+	fmt.Fprintf(w, "Type:\t%s\n", valueOrDash(state.Type))
+	fmt.Fprintf(w, "Status:\t%s\n", style.Paint(statusColor(status), status))
+	if state.Location != "" {
+		fmt.Fprintf(w, "Location:\t%s\n", state.Location)
+	}
+	fmt.Fprintf(w, "IPv4:\t%s\n", valueOrDash(state.IPv4))
+	fmt.Fprintf(w, "IPv6:\t%s\n", valueOrDash(state.IPv6))
+	fmt.Fprintf(w, "Memory:\t%s\n", memory)
+	fmt.Fprintf(w, "Disk:\t%s\n", diskUsageLabel(state))
+	if limits := rateLimitLabel(state.Devices["root"], helpers.LimitReadKey, helpers.LimitWriteKey); limits != "" {
+		fmt.Fprintf(w, "Disk IO limit:\t%s\n", limits)
+	}
+	if limits := rateLimitLabel(state.Devices[helpers.PrimaryNIC(state.Devices)], helpers.LimitEgressKey, helpers.LimitIngressKey); limits != "" {
+		fmt.Fprintf(w, "Network limit:\t%s\n", limits)
+	}
+	fmt.Fprintf(w, "Managed:\t%s\n", managedLabel(state.Config))
+	fmt.Fprintf(w, "Created:\t%s\n", valueOrDash(state.Config[helpers.ManagedCreatedKey]))
+	fmt.Fprintf(w, "Ports:\t%d\n", len(mappings))
+	w.Flush()
+
+	if len(mappings) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(formatPortMappings(mappings))
+	}
+	return sb.String()
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().DurationVarP(&infoTimeout, "timeout", "t", 30*time.Second, "Timeout for the info operation")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inventoryTimeout time.Duration
+	inventoryFormat  string
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Export managed containers as an Ansible or Terraform inventory",
+	Long: `Export an inventory of managed containers with their IP addresses, user,
+forwarded ports and a command that prints the app user's password.
+
+Passwords are never written to the inventory. The Ansible format resolves them
+at run time through a pipe lookup of 'lxc-go-cli password <container>'.
+
+Formats:
+  json       - A list of containers (default)
+  ansible    - Ansible dynamic inventory JSON; all containers are in the 'lxc_go_cli' group
+  terraform  - A flat string map for Terraform's external data source,
+               keyed "<container>.<attribute>"
+
+Examples:
+  lxc-go-cli inventory
+  lxc-go-cli inventory --format ansible > inventory.json
+
+  # Ansible dynamic inventory script
+  printf '#!/bin/sh\nexec lxc-go-cli inventory --format ansible\n' > lxc.sh && chmod +x lxc.sh
+  ansible -i lxc.sh lxc_go_cli -m ping
+
+  # Terraform
+  data "external" "containers" {
+    program = ["lxc-go-cli", "inventory", "--format", "terraform"]
+  }`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), inventoryTimeout)
+		defer cancel()
+
+		manager := &DefaultInventoryManager{}
+		return exportInventory(ctx, manager, strings.ToLower(inventoryFormat), cmd.OutOrStdout())
+	},
+}
+
+// InventoryManager interface for dependency injection
+type InventoryManager interface {
+	ListInventory(ctx context.Context) ([]helpers.InventoryHost, error)
+}
+
+// DefaultInventoryManager implements InventoryManager using helpers
+type DefaultInventoryManager struct{}
+
+func (d *DefaultInventoryManager) ListInventory(ctx context.Context) ([]helpers.InventoryHost, error) {
+	return helpers.ListInventory()
+}
+
+// exportInventory writes the managed container inventory in the given format
+func exportInventory(ctx context.Context, manager InventoryManager, format string, out io.Writer) error {
+	// Validate the format before querying LXD
+	if _, err := helpers.FormatInventory(nil, format); err != nil {
+		return err
+	}
+
+	hosts, err := manager.ListInventory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	data, err := helpers.FormatInventory(hosts, format)
+	if err != nil {
+		return fmt.Errorf("failed to render inventory: %w", err)
+	}
+
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().DurationVarP(&inventoryTimeout, "timeout", "t", 30*time.Second, "Timeout for the inventory operation")
+	inventoryCmd.Flags().StringVarP(&inventoryFormat, "format", "f", "json", "Output format (json, ansible, terraform)")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockInventoryManager for testing inventory command
+type MockInventoryManager struct {
+	Hosts     []helpers.InventoryHost
+	ListError error
+	Calls     int
+}
+
+func (m *MockInventoryManager) ListInventory(ctx context.Context) ([]helpers.InventoryHost, error) {
+	m.Calls++
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.Hosts, nil
+}
+
+func TestInventoryCommand(t *testing.T) {
+	if inventoryCmd == nil {
+		t.Fatal("inventoryCmd should not be nil")
+	}
+	formatFlag := inventoryCmd.Flags().Lookup("format")
+	if formatFlag == nil || formatFlag.DefValue != "json" {
+		t.Error("format flag should exist and default to json")
+	}
+}
+
+func TestExportInventory(t *testing.T) {
+	hosts := []helpers.InventoryHost{
+		{Name: "web", Status: "Running", IPv4: "10.0.0.5", User: "app", PasswordCommand: "lxc-go-cli password web",
+			Ports: []helpers.InventoryPort{{Protocol: "tcp", HostPort: 8080, ContainerPort: 80}}},
+	}
+
+	tests := []struct {
+		name          string
+		format        string
+		listError     error
+		expectedError string
+		expectedCalls int
+	}{
+		{name: "json", format: "json", expectedCalls: 1},
+		{name: "ansible", format: "ansible", expectedCalls: 1},
+		{name: "terraform", format: "terraform", expectedCalls: 1},
+		{name: "invalid format", format: "csv", expectedError: "invalid format 'csv'"},
+		{name: "list fails", format: "json", listError: fmt.Errorf("lxc failed"), expectedError: "failed to list containers", expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockInventoryManager{Hosts: hosts, ListError: tt.listError}
+
+			var out bytes.Buffer
+			err := exportInventory(context.Background(), manager, tt.format, &out)
+
+			if manager.Calls != tt.expectedCalls {
+				t.Errorf("expected %d calls to ListInventory, got %d", tt.expectedCalls, manager.Calls)
+			}
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !json.Valid(out.Bytes()) {
+				t.Errorf("expected valid JSON output, got %s", out.String())
+			}
+			if contains(out.String(), "c2VjcmV0") {
+				t.Error("inventory must not contain passwords")
+			}
+		})
+	}
+}
+
+func TestDefaultInventoryManager(t *testing.T) {
+	// Test that DefaultInventoryManager implements InventoryManager interface
+	var manager InventoryManager = &DefaultInventoryManager{}
+	_ = manager
+}
@@ -0,0 +1,196 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kvmTimeout       time.Duration
+	kvmSkipHostCheck bool
+)
+
+// kvmCmd represents the kvm command
+var kvmCmd = &cobra.Command{
+	Use:   "kvm <enable|disable|status>",
+	Short: "Pass KVM into a container for nested virtualization",
+	Long: `Let a container run QEMU-based workloads, such as testcontainers that start
+VMs or Firecracker, by passing the host's /dev/kvm into it. /dev/vhost-net and
+/dev/vhost-vsock are passed too when the host has them.
+
+The devices are hotplugged, so the container keeps running, and opened with
+mode 0666 so the 'app' user can use them. Unlike GPU access no privileged mode
+is needed.
+
+Before enabling, the host is checked for /dev/kvm; when it is missing the CPU
+flags tell whether a kernel module needs loading or virtualization is disabled
+in the firmware. Use --skip-host-check to enable KVM anyway.
+
+Available subcommands:
+  enable   - Pass /dev/kvm and the vhost devices into a container
+  disable  - Remove them again
+  status   - Show the container's KVM devices and the host's support
+
+Examples:
+  lxc-go-cli kvm enable mycontainer
+  lxc-go-cli kvm status mycontainer
+  lxc-go-cli kvm disable mycontainer`,
+}
+
+// kvmEnableCmd represents the kvm enable subcommand
+var kvmEnableCmd = &cobra.Command{
+	Use:   "enable [container-name]",
+	Short: "Pass /dev/kvm and the vhost devices into a container",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKVMAction(args, func(ctx context.Context, manager KVMManager, name string) error {
+			return enableKVM(ctx, manager, name, kvmSkipHostCheck)
+		})
+	},
+}
+
+// kvmDisableCmd represents the kvm disable subcommand
+var kvmDisableCmd = &cobra.Command{
+	Use:   "disable [container-name]",
+	Short: "Remove the KVM devices from a container",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKVMAction(args, disableKVM)
+	},
+}
+
+// kvmStatusCmd represents the kvm status subcommand
+var kvmStatusCmd = &cobra.Command{
+	Use:   "status [container-name]",
+	Short: "Show a container's KVM devices and the host's support",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKVMAction(args, func(ctx context.Context, manager KVMManager, name string) error {
+			return showKVMStatus(ctx, manager, name, cmd.OutOrStdout())
+		})
+	},
+}
+
+// KVMManager interface for dependency injection
+type KVMManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetKVMStatus(ctx context.Context, containerName string) (*helpers.KVMStatus, error)
+	EnableKVM(ctx context.Context, containerName string, devices []helpers.KVMDevice) error
+	DisableKVM(ctx context.Context, containerName string) error
+	DetectHostKVM(ctx context.Context) (*helpers.KVMHost, error)
+}
+
+// DefaultKVMManager implements KVMManager using helpers
+type DefaultKVMManager struct{}
+
+func (d *DefaultKVMManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultKVMManager) GetKVMStatus(ctx context.Context, containerName string) (*helpers.KVMStatus, error) {
+	return helpers.GetContainerKVMStatus(containerName)
+}
+
+func (d *DefaultKVMManager) EnableKVM(ctx context.Context, containerName string, devices []helpers.KVMDevice) error {
+	return helpers.EnableContainerKVM(containerName, devices)
+}
+
+func (d *DefaultKVMManager) DisableKVM(ctx context.Context, containerName string) error {
+	return helpers.DisableContainerKVM(containerName)
+}
+
+func (d *DefaultKVMManager) DetectHostKVM(ctx context.Context) (*helpers.KVMHost, error) {
+	// /dev/kvm and /proc/cpuinfo describe this machine, not a remote server
+	if err := helpers.RequireLocalServer(ctx, "KVM detection"); err != nil {
+		return nil, err
+	}
+	return helpers.DetectHostKVM(), nil
+}
+
+// runKVMAction resolves the container and runs a kvm subcommand against it
+func runKVMAction(args []string, action func(ctx context.Context, manager KVMManager, name string) error) error {
+	name, err := containerArg(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kvmTimeout)
+	defer cancel()
+
+	manager := &DefaultKVMManager{}
+	if !manager.ContainerExists(ctx, name) {
+		return containerNotFound(name)
+	}
+	return action(ctx, manager, name)
+}
+
+// enableKVM passes the KVM devices into a container. Without the host check,
+// or when the host cannot be inspected, every device is passed.
+func enableKVM(ctx context.Context, manager KVMManager, containerName string, skipHostCheck bool) error {
+	devices := helpers.KVMDevices
+	if !skipHostCheck {
+		host, err := manager.DetectHostKVM(ctx)
+		if err != nil {
+			logger.Warn("Skipping the host KVM check: %v", err)
+		} else {
+			if err := helpers.CheckHostKVM(host); err != nil {
+				return fmt.Errorf("%w; use --skip-host-check to enable KVM anyway", err)
+			}
+			devices = host.AvailableDevices()
+		}
+	}
+
+	logger.Info("Enabling KVM for container '%s'...", containerName)
+	if err := manager.EnableKVM(ctx, containerName, devices); err != nil {
+		return fmt.Errorf("failed to enable KVM: %w", err)
+	}
+	logger.Info("KVM enabled for container '%s'; QEMU can use /dev/kvm now", containerName)
+	return nil
+}
+
+// disableKVM removes the KVM devices from a container
+func disableKVM(ctx context.Context, manager KVMManager, containerName string) error {
+	logger.Info("Disabling KVM for container '%s'...", containerName)
+	if err := manager.DisableKVM(ctx, containerName); err != nil {
+		return fmt.Errorf("failed to disable KVM: %w", err)
+	}
+	logger.Info("KVM disabled for container '%s'", containerName)
+	return nil
+}
+
+// showKVMStatus prints a container's KVM devices and, when it can be
+// inspected, the host's support
+func showKVMStatus(ctx context.Context, manager KVMManager, containerName string, out io.Writer) error {
+	status, err := manager.GetKVMStatus(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get KVM status: %w", err)
+	}
+
+	host, err := manager.DetectHostKVM(ctx)
+	if err != nil {
+		logger.Debug("Could not inspect the host: %v", err)
+		host = nil
+	}
+	fmt.Fprint(out, helpers.FormatKVMStatus(status, host))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(kvmCmd)
+
+	kvmCmd.AddCommand(kvmEnableCmd)
+	kvmCmd.AddCommand(kvmDisableCmd)
+	kvmCmd.AddCommand(kvmStatusCmd)
+
+	kvmCmd.PersistentFlags().DurationVarP(&kvmTimeout, "timeout", "t", 60*time.Second, "Timeout for KVM operations")
+	kvmEnableCmd.Flags().BoolVar(&kvmSkipHostCheck, "skip-host-check", false, "Enable KVM even if the host has no usable /dev/kvm")
+}
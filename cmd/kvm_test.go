@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockKVMManager for testing the kvm command
+type MockKVMManager struct {
+	Status       *helpers.KVMStatus
+	Host         *helpers.KVMHost
+	HostError    error
+	EnableError  error
+	Enabled      []helpers.KVMDevice
+	EnableCalled bool
+	Disabled     bool
+}
+
+func (m *MockKVMManager) ContainerExists(ctx context.Context, name string) bool {
+	return true
+}
+
+func (m *MockKVMManager) GetKVMStatus(ctx context.Context, containerName string) (*helpers.KVMStatus, error) {
+	return m.Status, nil
+}
+
+func (m *MockKVMManager) EnableKVM(ctx context.Context, containerName string, devices []helpers.KVMDevice) error {
+	m.EnableCalled = true
+	m.Enabled = devices
+	return m.EnableError
+}
+
+func (m *MockKVMManager) DisableKVM(ctx context.Context, containerName string) error {
+	m.Disabled = true
+	return nil
+}
+
+func (m *MockKVMManager) DetectHostKVM(ctx context.Context) (*helpers.KVMHost, error) {
+	return m.Host, m.HostError
+}
+
+func TestEnableKVM(t *testing.T) {
+	ctx := context.Background()
+	kvmOnly := &helpers.KVMHost{Devices: map[string]bool{"/dev/kvm": true}, CPUExtension: "vmx"}
+
+	t.Run("passes the devices the host has", func(t *testing.T) {
+		manager := &MockKVMManager{Host: kvmOnly}
+		if err := enableKVM(ctx, manager, "web", false); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Enabled) != 1 || manager.Enabled[0].Path != "/dev/kvm" {
+			t.Errorf("expected only /dev/kvm, got %+v", manager.Enabled)
+		}
+	})
+
+	t.Run("host without kvm", func(t *testing.T) {
+		manager := &MockKVMManager{Host: &helpers.KVMHost{Devices: map[string]bool{}, CPUExtension: "vmx"}}
+		err := enableKVM(ctx, manager, "web", false)
+		if err == nil || !strings.Contains(err.Error(), "modprobe kvm_intel") || !strings.Contains(err.Error(), "--skip-host-check") {
+			t.Errorf("expected a host check error, got %v", err)
+		}
+		if manager.EnableCalled {
+			t.Error("nothing should be enabled when the host check fails")
+		}
+	})
+
+	t.Run("skip host check", func(t *testing.T) {
+		manager := &MockKVMManager{HostError: fmt.Errorf("should not be called")}
+		if err := enableKVM(ctx, manager, "web", true); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Enabled) != len(helpers.KVMDevices) {
+			t.Errorf("expected every device, got %+v", manager.Enabled)
+		}
+	})
+
+	t.Run("remote server", func(t *testing.T) {
+		manager := &MockKVMManager{HostError: fmt.Errorf("KVM detection needs a local server")}
+		if err := enableKVM(ctx, manager, "web", false); err != nil || len(manager.Enabled) != len(helpers.KVMDevices) {
+			t.Errorf("expected every device when the host cannot be inspected, got %v and %+v", err, manager.Enabled)
+		}
+	})
+
+	t.Run("enable fails", func(t *testing.T) {
+		manager := &MockKVMManager{Host: kvmOnly, EnableError: fmt.Errorf("lxc failed")}
+		if err := enableKVM(ctx, manager, "web", false); err == nil || !strings.Contains(err.Error(), "failed to enable KVM") {
+			t.Errorf("expected an enable error, got %v", err)
+		}
+	})
+}
+
+func TestDisableKVM(t *testing.T) {
+	manager := &MockKVMManager{}
+	if err := disableKVM(context.Background(), manager, "web"); err != nil || !manager.Disabled {
+		t.Errorf("expected the devices to be removed, got %v", err)
+	}
+}
+
+func TestShowKVMStatus(t *testing.T) {
+	manager := &MockKVMManager{
+		Status: &helpers.KVMStatus{Devices: map[string]bool{"kvm": true}},
+		Host:   &helpers.KVMHost{Devices: map[string]bool{"/dev/kvm": true}, CPUExtension: "svm"},
+	}
+	var out bytes.Buffer
+	if err := showKVMStatus(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "KVM Status: enabled") || !strings.Contains(out.String(), "CPU virtualization: svm") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	manager.HostError = fmt.Errorf("remote")
+	out.Reset()
+	if err := showKVMStatus(context.Background(), manager, "web", &out); err != nil || strings.Contains(out.String(), "Host:") {
+		t.Errorf("expected the host section to be left out, got %v and:\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	labelTimeout  time.Duration
+	labelSelector string
+	labelTable    tableFlags
+)
+
+// labelCmd represents the label command
+var labelCmd = &cobra.Command{
+	Use:   "label <set|remove|list>",
+	Short: "Label containers so fleet commands can target groups of them",
+	Long: `Attach key=value labels to containers, e.g. env=prod or team=core, and pick
+containers by label with --selector on list, exec --all and update --all.
+
+Labels are stored in the container config as user.label.<key>, so they travel
+with the container and are visible to other LXD tools. Keys and values use up
+to 63 letters, digits, '.', '_' and '-'.
+
+A selector is a comma-separated list of requirements that must all hold:
+  env=prod     the label env is prod (env==prod also works)
+  env!=prod    the label env is not prod, or not set
+  canary       the label canary is set
+  !canary      the label canary is not set
+
+Available subcommands:
+  set     - Set labels on a container
+  remove  - Remove labels from a container
+  list    - Show the labels of one or all managed containers
+
+Examples:
+  lxc-go-cli label set web1 env=prod team=core
+  lxc-go-cli label list --selector env=prod
+  lxc-go-cli exec --all --selector env=prod,team=core -- docker compose pull
+  lxc-go-cli update --all --selector '!canary'`,
+}
+
+// labelSetCmd represents the label set subcommand
+var labelSetCmd = &cobra.Command{
+	Use:   "set <container-name> <key=value>...",
+	Short: "Set labels on a container",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), labelTimeout)
+		defer cancel()
+
+		return setContainerLabels(ctx, &DefaultLabelManager{}, args[0], args[1:])
+	},
+}
+
+// labelRemoveCmd represents the label remove subcommand
+var labelRemoveCmd = &cobra.Command{
+	Use:   "remove <container-name> <key>...",
+	Short: "Remove labels from a container",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), labelTimeout)
+		defer cancel()
+
+		return removeContainerLabels(ctx, &DefaultLabelManager{}, args[0], args[1:])
+	},
+}
+
+// labelListCmd represents the label list subcommand
+var labelListCmd = &cobra.Command{
+	Use:   "list [container-name]",
+	Short: "Show the labels of one or all managed containers",
+	Long: `Show the labels of a container, or of every managed container when no name
+is given. --selector only shows containers whose labels match.
+
+Examples:
+  lxc-go-cli label list web1
+  lxc-go-cli label list --selector team=core`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), labelTimeout)
+		defer cancel()
+
+		selector, err := helpers.ParseLabelSelector(labelSelector)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return listContainerLabels(ctx, &DefaultLabelManager{}, name, selector, out, labelTable.options(out))
+	},
+}
+
+// LabelManager interface for dependency injection
+type LabelManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	SetConfigValue(ctx context.Context, containerName, key, value string) error
+	UnsetConfigValue(ctx context.Context, containerName, key string) error
+}
+
+// DefaultLabelManager implements LabelManager using helpers
+type DefaultLabelManager struct{}
+
+func (d *DefaultLabelManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+func (d *DefaultLabelManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultLabelManager) UnsetConfigValue(ctx context.Context, containerName, key string) error {
+	return helpers.UnsetConfigValue(containerName, key)
+}
+
+// setContainerLabels validates all labels before setting any of them
+func setContainerLabels(ctx context.Context, manager LabelManager, name string, assignments []string) error {
+	labels, err := helpers.ParseLabels(assignments)
+	if err != nil {
+		return err
+	}
+	if _, err := findContainer(ctx, manager, name); err != nil {
+		return err
+	}
+
+	for _, key := range helpers.LabelKeys(labels) {
+		if err := manager.SetConfigValue(ctx, name, helpers.LabelConfigPrefix+key, labels[key]); err != nil {
+			return fmt.Errorf("failed to set label '%s' on container '%s': %w", key, name, err)
+		}
+	}
+	logger.Info("Labels of container '%s' set: %s", name, helpers.FormatLabels(labels))
+	return nil
+}
+
+// removeContainerLabels removes labels; keys that are not set are reported
+// but do not fail the command
+func removeContainerLabels(ctx context.Context, manager LabelManager, name string, keys []string) error {
+	for _, key := range keys {
+		if err := helpers.ValidateLabelKey(key); err != nil {
+			return err
+		}
+	}
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+
+	current := helpers.ContainerLabels(state.Config)
+	for _, key := range keys {
+		if _, ok := current[key]; !ok {
+			logger.Warn("Container '%s' has no label '%s'", name, key)
+			continue
+		}
+		if err := manager.UnsetConfigValue(ctx, name, helpers.LabelConfigPrefix+key); err != nil {
+			return fmt.Errorf("failed to remove label '%s' from container '%s': %w", key, name, err)
+		}
+		logger.Info("Label '%s' removed from container '%s'", key, name)
+	}
+	return nil
+}
+
+// listContainerLabels prints the labels of one container, or of every
+// managed container matching the selector
+func listContainerLabels(ctx context.Context, manager LabelManager, name string, selector helpers.LabelSelector, out io.Writer, opts render.Options) error {
+	if name != "" {
+		state, err := findContainer(ctx, manager, name)
+		if err != nil {
+			return err
+		}
+		labels := helpers.ContainerLabels(state.Config)
+		if len(labels) == 0 {
+			fmt.Fprintf(out, "Container '%s' has no labels\n", name)
+			return nil
+		}
+		table := render.NewTable("KEY", "VALUE")
+		for _, key := range helpers.LabelKeys(labels) {
+			table.AddRow(key, labels[key])
+		}
+		return table.Render(out, opts)
+	}
+
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	states = helpers.FilterContainersBySelector(helpers.FilterManagedContainers(states, false), selector)
+	if len(states) == 0 && len(selector) > 0 {
+		fmt.Fprintf(out, "No containers match selector '%s'\n", selector)
+		return nil
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(out, "No managed containers found")
+		return nil
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	table := render.NewTable("NAME", "LABELS")
+	for _, state := range states {
+		table.AddRow(state.Name, valueOrDash(helpers.FormatLabels(helpers.ContainerLabels(state.Config))))
+	}
+	return table.Render(out, opts)
+}
+
+func init() {
+	rootCmd.AddCommand(labelCmd)
+	labelCmd.AddCommand(labelSetCmd)
+	labelCmd.AddCommand(labelRemoveCmd)
+	labelCmd.AddCommand(labelListCmd)
+
+	labelCmd.PersistentFlags().DurationVarP(&labelTimeout, "timeout", "t", 30*time.Second, "Timeout for the label operation")
+	labelListCmd.Flags().StringVar(&labelSelector, "selector", "", "Only show containers whose labels match, e.g. env=prod")
+	addTableFlags(labelListCmd, &labelTable)
+}
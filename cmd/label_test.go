@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockLabelManager for testing label command; config changes are applied to States
+type MockLabelManager struct {
+	States []helpers.ContainerState
+	Set    []string
+	Unset  []string
+}
+
+func (m *MockLabelManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockLabelManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	m.Set = append(m.Set, containerName+"/"+key+"="+value)
+	for i := range m.States {
+		if m.States[i].Name == containerName {
+			m.States[i].Config[key] = value
+		}
+	}
+	return nil
+}
+
+func (m *MockLabelManager) UnsetConfigValue(ctx context.Context, containerName, key string) error {
+	m.Unset = append(m.Unset, containerName+"/"+key)
+	for i := range m.States {
+		if m.States[i].Name == containerName {
+			delete(m.States[i].Config, key)
+		}
+	}
+	return nil
+}
+
+func newMockLabelManager() *MockLabelManager {
+	return &MockLabelManager{States: []helpers.ContainerState{
+		{Name: "web1", Status: "Running", Config: map[string]string{helpers.ManagedMarkerKey: "true", "user.label.env": "prod"}},
+		{Name: "web2", Status: "Running", Config: map[string]string{helpers.ManagedMarkerKey: "true"}},
+		{Name: "other", Status: "Running", Config: map[string]string{"user.label.env": "prod"}},
+	}}
+}
+
+func TestLabelCommand(t *testing.T) {
+	if labelCmd.Use != "label <set|remove|list>" {
+		t.Errorf("unexpected Use: %s", labelCmd.Use)
+	}
+	for _, name := range []string{"set", "remove", "list"} {
+		if _, _, err := labelCmd.Find([]string{name}); err != nil {
+			t.Errorf("expected subcommand '%s': %v", name, err)
+		}
+	}
+	if labelListCmd.Flags().Lookup("selector") == nil {
+		t.Error("label list should have a selector flag")
+	}
+}
+
+func TestSetContainerLabels(t *testing.T) {
+	manager := newMockLabelManager()
+	ctx := context.Background()
+
+	if err := setContainerLabels(ctx, manager, "web2", []string{"team=core", "env=dev"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []string{"web2/user.label.env=dev", "web2/user.label.team=core"}
+	if strings.Join(manager.Set, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %v, got %v", want, manager.Set)
+	}
+
+	manager.Set = nil
+	if err := setContainerLabels(ctx, manager, "web2", []string{"team=core", "bad"}); err == nil || !strings.Contains(err.Error(), "expected key=value") {
+		t.Errorf("expected an invalid label error, got %v", err)
+	}
+	if len(manager.Set) != 0 {
+		t.Errorf("no label should be set when one is invalid, got %v", manager.Set)
+	}
+
+	if err := setContainerLabels(ctx, manager, "missing", []string{"env=prod"}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing container error, got %v", err)
+	}
+}
+
+func TestRemoveContainerLabels(t *testing.T) {
+	manager := newMockLabelManager()
+
+	if err := removeContainerLabels(context.Background(), manager, "web1", []string{"env", "team"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.Unset, " ") != "web1/user.label.env" {
+		t.Errorf("expected only the set label to be removed, got %v", manager.Unset)
+	}
+
+	if err := removeContainerLabels(context.Background(), manager, "web1", []string{"bad key"}); err == nil {
+		t.Error("expected an invalid key error")
+	}
+}
+
+func TestListContainerLabels(t *testing.T) {
+	manager := newMockLabelManager()
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	if err := listContainerLabels(ctx, manager, "", nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, expected := range []string{"NAME", "LABELS", "web1", "env=prod", "web2"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "other") {
+		t.Error("unmanaged containers should be left out")
+	}
+
+	selector, _ := helpers.ParseLabelSelector("!env")
+	out.Reset()
+	if err := listContainerLabels(ctx, manager, "", selector, &out, render.Options{Columns: []string{"name"}, NoHeader: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.String() != "web2\n" {
+		t.Errorf("expected only web2 without an env label, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := listContainerLabels(ctx, manager, "web1", nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "KEY") || !strings.Contains(out.String(), "prod") {
+		t.Errorf("expected the labels of web1, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := listContainerLabels(ctx, manager, "web2", nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "has no labels") {
+		t.Errorf("expected a no labels message, got %s", out.String())
+	}
+}
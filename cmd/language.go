@@ -0,0 +1,42 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// applyLanguage shows messages in the configured language, or else the one
+// of the locale, and translates the short help of commands that have a
+// translation. Log levels, error codes and machine-readable output stay the
+// same in every language.
+func applyLanguage(root *cobra.Command, configured string) {
+	lang := i18n.DetectLanguage(configured)
+	if configured != "" && i18n.ParseLanguage(configured) != lang {
+		logger.Debug("No messages in language '%s'; using %s", configured, lang)
+	}
+	if err := i18n.SetLanguage(lang); err != nil {
+		logger.Debug("%v", err)
+		return
+	}
+	localizeCommands(root)
+}
+
+// localizeCommands replaces the short help of parent's subcommands, at any
+// depth, with the translation of cmd.<command path>.short where there is one,
+// e.g. cmd.port.add.short for 'port add'
+func localizeCommands(parent *cobra.Command) {
+	for _, c := range parent.Commands() {
+		// The command path starts with the name of the root command
+		path := strings.SplitN(c.CommandPath(), " ", 2)[1]
+		if short, ok := i18n.Lookup("cmd." + strings.ReplaceAll(path, " ", ".") + ".short"); ok {
+			c.Short = short
+		}
+		localizeCommands(c)
+	}
+}
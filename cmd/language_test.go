@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// useLanguage shows messages in lang for one test
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	original := i18n.Language()
+	if err := i18n.SetLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { i18n.SetLanguage(original) })
+}
+
+func TestApplyLanguage(t *testing.T) {
+	original := i18n.Language()
+	t.Cleanup(func() { i18n.SetLanguage(original) })
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+
+	root := &cobra.Command{Use: "lxc-go-cli"}
+	list := &cobra.Command{Use: "list", Short: "List containers managed by lxc-go-cli"}
+	other := &cobra.Command{Use: "other", Short: "Not translated"}
+	root.AddCommand(list, other)
+
+	applyLanguage(root, "")
+	if i18n.Language() != "es" {
+		t.Fatalf("expected Spanish from LANG, got %s", i18n.Language())
+	}
+	if list.Short != "Listar los contenedores gestionados por lxc-go-cli" {
+		t.Errorf("expected a translated short help, got %q", list.Short)
+	}
+	if other.Short != "Not translated" {
+		t.Errorf("expected commands without a translation to keep theirs, got %q", other.Short)
+	}
+
+	applyLanguage(root, "en")
+	if i18n.Language() != "en" {
+		t.Errorf("expected the configured language to win over LANG, got %s", i18n.Language())
+	}
+}
+
+func TestTranslatedCommandsExist(t *testing.T) {
+	useLanguage(t, "es")
+	for _, c := range rootCmd.Commands() {
+		if c.Hidden || c.Name() == "help" || c.Name() == "completion" {
+			continue
+		}
+		if _, ok := i18n.Lookup("cmd." + c.Name() + ".short"); !ok {
+			t.Errorf("command %s has no Spanish short help", c.Name())
+		}
+	}
+}
+
+func TestSpanishMessages(t *testing.T) {
+	useLanguage(t, "es")
+
+	prompt := useConfirmAnswer(t, "sí\n")
+	if err := confirm("delete.confirm", "web"); err != nil {
+		t.Errorf("expected 'sí' to confirm, got %v", err)
+	}
+	if prompt.String() != "¿Eliminar el contenedor 'web' y todos sus datos? [s/N] " {
+		t.Errorf("unexpected prompt %q", prompt.String())
+	}
+
+	useConfirmAnswer(t, "no\n")
+	err := confirm("delete.confirm", "web")
+	if !errors.Is(err, errAborted) || err.Error() != "cancelado" {
+		t.Errorf("expected a translated abort, got %v", err)
+	}
+
+	if got := joinAlternatives([]string{"a", "b", "c"}); got != "'a', 'b' o 'c'" {
+		t.Errorf("unexpected alternatives %q", got)
+	}
+
+	_, err = deletableContainer(context.Background(), newMockListManager(), "", false, false)
+	if err == nil || i18n.CodeOf(err) != "container.name_required" || !strings.Contains(err.Error(), "hace falta") {
+		t.Errorf("expected a translated error with a stable code, got %v (%s)", err, i18n.CodeOf(err))
+	}
+}
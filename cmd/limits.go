@@ -0,0 +1,484 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	limitsTimeout time.Duration
+	limitsOptions LimitOptions
+	limitsPreset  string
+)
+
+// limitsCmd represents the limits command
+var limitsCmd = &cobra.Command{
+	Use:   "limits <set|apply|show|presets>",
+	Short: "Pin a container to CPUs and limit its memory, disk IO and network",
+	Long: `Control the CPU, memory, disk IO and network limits of a container.
+
+Available subcommands:
+  set      - Pin CPUs, set memory behaviour and rate limit disk and network
+  apply    - Size a container with a preset of CPUs, memory and disk
+  show     - Show a container's limits
+  presets  - List the size presets
+
+Examples:
+  lxc-go-cli limits set mycontainer --cpu-pin 0,1
+  lxc-go-cli limits apply mycontainer --preset large
+  lxc-go-cli limits set mycontainer --memory-swap false --memory-enforce hard
+  lxc-go-cli limits set mycontainer --disk-read 50MB --disk-write 50MB --net-egress 100Mbit
+  lxc-go-cli limits show mycontainer`,
+}
+
+// limitsSetCmd represents the limits set subcommand
+var limitsSetCmd = &cobra.Command{
+	Use:   "set <container-name>",
+	Short: "Pin CPUs, set memory behaviour and rate limit disk and network",
+	Long: `Set CPU pinning, memory limit behaviour and IO limits of a container.
+
+--cpu-pin takes a list of host CPUs such as 0,1 or 0-3,8 and sets limits.cpu
+to pin the container to them. The CPUs are checked against /proc/cpuinfo of
+the host.
+
+--memory-swap false keeps the container's memory out of swap, and
+--memory-enforce chooses how limits.memory is enforced: hard never lets the
+container exceed it, soft lets it use spare host memory and only reclaims it
+under pressure. Both only apply to containers, not virtual machines.
+
+--disk-read and --disk-write limit the root disk in bytes per second (50MB,
+20MiB) or operations per second (1000iops); --disk-priority (0 to 10) sets
+the container's share of disk time when containers compete for it.
+--net-egress and --net-ingress limit the main network device in bits per
+second (100Mbit, 1Gbit). Devices inherited from a profile are overridden on
+the container only.
+
+The host checks are skipped for a remote server, whose CPUs are not known here.
+
+Examples:
+  lxc-go-cli limits set mycontainer --cpu-pin 0,1
+  lxc-go-cli limits set mycontainer --cpu-pin 0-3 --memory-enforce soft
+  lxc-go-cli limits set mycontainer --memory-swap false --memory-enforce hard
+  lxc-go-cli limits set mycontainer --disk-read 50MB --disk-write 50MB --net-egress 100Mbit`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), limitsTimeout)
+		defer cancel()
+
+		return setContainerLimits(ctx, &DefaultLimitsManager{}, args[0], limitsOptions)
+	},
+}
+
+// limitsApplyCmd represents the limits apply subcommand
+var limitsApplyCmd = &cobra.Command{
+	Use:   "apply <container-name> --preset <name>",
+	Short: "Size a container with a preset of CPUs, memory and disk",
+	Long: `Set a container's CPU count (limits.cpu), memory limit (limits.memory) and
+root disk size from a named preset, so containers are sized the same way
+across a team.
+
+small (1 CPU, 1GiB, 10GiB), medium (2 CPUs, 4GiB, 20GiB) and large (4 CPUs,
+8GiB, 50GiB) are built in. Presets in the config file replace built-in ones
+of the same name or add new ones; fields left out are not changed:
+
+  presets:
+    large:
+      cpu: 8
+      memory: 16GiB
+      disk: 100GiB
+    ci:
+      cpu: 2
+      memory: 2GiB
+
+A CPU count replaces CPUs pinned with 'limits set --cpu-pin'. 'create
+--preset' applies a preset to a new container.
+
+Examples:
+  lxc-go-cli limits apply mycontainer --preset large
+  lxc-go-cli limits presets`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), limitsTimeout)
+		defer cancel()
+
+		settings, err := helpers.LoadSettings()
+		if err != nil {
+			return err
+		}
+		preset, err := helpers.ResolveSizePreset(settings, limitsPreset)
+		if err != nil {
+			return err
+		}
+		return applyLimitsPreset(ctx, &DefaultLimitsManager{}, args[0], limitsPreset, preset)
+	},
+}
+
+// limitsPresetsCmd represents the limits presets subcommand
+var limitsPresetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "List the size presets for 'limits apply' and 'create --preset'",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := helpers.LoadSettings()
+		if err != nil {
+			return err
+		}
+		return listSizePresets(settings, cmd.OutOrStdout())
+	},
+}
+
+// limitsShowCmd represents the limits show subcommand
+var limitsShowCmd = &cobra.Command{
+	Use:   "show <container-name>",
+	Short: "Show a container's CPU and memory limits",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), limitsTimeout)
+		defer cancel()
+
+		return showContainerLimits(ctx, &DefaultLimitsManager{}, args[0], cmd.OutOrStdout())
+	},
+}
+
+// LimitOptions are the limits to set; empty fields are left unchanged
+type LimitOptions struct {
+	// CPUPin is a list of host CPUs such as "0,1" or "0-3"
+	CPUPin string
+	// MemorySwap is "true" or "false"
+	MemorySwap string
+	// MemoryEnforce is "hard" or "soft"
+	MemoryEnforce string
+	// DiskRead and DiskWrite limit the root disk, e.g. "50MB" or "1000iops"
+	DiskRead  string
+	DiskWrite string
+	// DiskPriority is the share of disk time, 0 to 10
+	DiskPriority string
+	// NetEgress and NetIngress limit the main NIC, e.g. "100Mbit"
+	NetEgress  string
+	NetIngress string
+}
+
+// LimitsManager interface for dependency injection
+type LimitsManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	SetConfigValue(ctx context.Context, containerName, key, value string) error
+	HostTopology(ctx context.Context) (*helpers.HostTopology, error)
+	GetContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+	SetDeviceLimits(ctx context.Context, containerName, device string, limits []string, local bool) error
+}
+
+// DefaultLimitsManager implements LimitsManager using helpers
+type DefaultLimitsManager struct{}
+
+func (d *DefaultLimitsManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+func (d *DefaultLimitsManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultLimitsManager) HostTopology(ctx context.Context) (*helpers.HostTopology, error) {
+	// /proc describes this machine, not the server's
+	if err := helpers.RequireLocalServer(ctx, "checking limits against the host"); err != nil {
+		return nil, err
+	}
+	return helpers.DetectHostTopology()
+}
+
+func (d *DefaultLimitsManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultLimitsManager) SetDeviceLimits(ctx context.Context, containerName, device string, limits []string, local bool) error {
+	return helpers.SetDeviceLimits(ctx, containerName, device, limits, local)
+}
+
+// setContainerLimits validates opts against the host and sets them on a container
+func setContainerLimits(ctx context.Context, manager LimitsManager, name string, opts LimitOptions) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if opts == (LimitOptions{}) {
+		return fmt.Errorf("nothing to set: use --cpu-pin, --memory-swap, --memory-enforce, --disk-read, --disk-write, --disk-priority, --net-egress or --net-ingress")
+	}
+
+	var cpus []int
+	if opts.CPUPin != "" {
+		var err error
+		if cpus, err = helpers.ParseCPUSet(opts.CPUPin); err != nil {
+			return err
+		}
+	}
+	swap := true
+	if opts.MemorySwap != "" {
+		var err error
+		if swap, err = strconv.ParseBool(opts.MemorySwap); err != nil {
+			return helpers.UsageErrorf("invalid --memory-swap '%s': must be 'true' or 'false'", opts.MemorySwap)
+		}
+	}
+	enforce := strings.ToLower(opts.MemoryEnforce)
+	if enforce != "" {
+		if err := helpers.ValidateMemoryEnforce(enforce); err != nil {
+			return err
+		}
+	}
+	if opts.DiskPriority != "" {
+		if err := helpers.ValidateDiskPriority(opts.DiskPriority); err != nil {
+			return err
+		}
+	}
+	var diskLimits, nicLimits []string
+	for _, limit := range []struct{ key, value string }{{helpers.LimitReadKey, opts.DiskRead}, {helpers.LimitWriteKey, opts.DiskWrite}} {
+		if limit.value == "" {
+			continue
+		}
+		if err := helpers.ValidateDiskRate(limit.value); err != nil {
+			return err
+		}
+		diskLimits = append(diskLimits, limit.key+"="+limit.value)
+	}
+	for _, limit := range []struct{ key, value string }{{helpers.LimitEgressKey, opts.NetEgress}, {helpers.LimitIngressKey, opts.NetIngress}} {
+		if limit.value == "" {
+			continue
+		}
+		if err := helpers.ValidateNetworkRate(limit.value); err != nil {
+			return err
+		}
+		nicLimits = append(nicLimits, limit.key+"="+limit.value)
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	if state.Type == helpers.InstanceTypeVM && (opts.MemorySwap != "" || enforce != "") {
+		return fmt.Errorf("'%s' is a VM; --memory-swap and --memory-enforce only apply to containers", name)
+	}
+
+	if len(cpus) > 0 || opts.MemorySwap != "" {
+		host, err := manager.HostTopology(ctx)
+		if err != nil {
+			logger.Warn("Skipping the host checks: %v", err)
+		} else {
+			if len(cpus) > 0 {
+				if err := helpers.CheckCPUPin(cpus, host); err != nil {
+					return err
+				}
+			}
+			if opts.MemorySwap != "" && swap && host.SwapBytes == 0 {
+				logger.Warn("The host has no active swap, so swap stays unused until some is enabled")
+			}
+		}
+	}
+	if enforce != "" && state.Config[helpers.LimitMemoryKey] == "" {
+		logger.Warn("'%s' has no limits.memory, so --memory-enforce has no effect until one is set", name)
+	}
+
+	var settings [][2]string
+	if len(cpus) > 0 {
+		settings = append(settings, [2]string{helpers.LimitCPUKey, helpers.FormatCPUPin(cpus)})
+	}
+	if opts.MemorySwap != "" {
+		settings = append(settings, [2]string{helpers.LimitMemorySwapKey, strconv.FormatBool(swap)})
+	}
+	if enforce != "" {
+		settings = append(settings, [2]string{helpers.LimitMemoryEnforceKey, enforce})
+	}
+	if opts.DiskPriority != "" {
+		settings = append(settings, [2]string{helpers.LimitDiskPriorityKey, opts.DiskPriority})
+	}
+	for _, setting := range settings {
+		if err := manager.SetConfigValue(ctx, name, setting[0], setting[1]); err != nil {
+			return err
+		}
+		logger.Info("Set %s=%s on '%s'", setting[0], setting[1], name)
+	}
+
+	if len(diskLimits) > 0 {
+		if _, ok := state.Devices["root"]; !ok {
+			return fmt.Errorf("container '%s' has no root disk device", name)
+		}
+		if err := setDeviceLimits(ctx, manager, name, "root", diskLimits); err != nil {
+			return err
+		}
+	}
+	if len(nicLimits) > 0 {
+		nic := helpers.PrimaryNIC(state.Devices)
+		if nic == "" {
+			return fmt.Errorf("container '%s' has no network device", name)
+		}
+		if err := setDeviceLimits(ctx, manager, name, nic, nicLimits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDeviceLimits sets limits on a device of the container, overriding it
+// when it comes from a profile
+func setDeviceLimits(ctx context.Context, manager LimitsManager, name, device string, limits []string) error {
+	// Only a device defined on the container itself can be changed with device set
+	configData, err := manager.GetContainerConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+	local, err := parseAdoptedContainer(configData)
+	if err != nil {
+		return err
+	}
+	_, isLocal := local.Devices[device]
+
+	if err := manager.SetDeviceLimits(ctx, name, device, limits, isLocal); err != nil {
+		return err
+	}
+	logger.Info("Set %s on device '%s' of '%s'", strings.Join(limits, " "), device, name)
+	return nil
+}
+
+// applyLimitsPreset sets a preset's CPU count, memory limit and root disk
+// size on a container
+func applyLimitsPreset(ctx context.Context, manager LimitsManager, name, presetName string, preset helpers.SizePreset) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	if preset.Disk != "" {
+		if _, ok := state.Devices["root"]; !ok {
+			return fmt.Errorf("container '%s' has no root disk device", name)
+		}
+	}
+	if pinned := state.Config[helpers.LimitCPUKey]; preset.CPU != "" && strings.ContainsAny(pinned, ",-") {
+		logger.Warn("'%s' was pinned to CPUs %s; the preset sets a count of %s instead", name, pinned, preset.CPU)
+	}
+
+	logger.Info("Applying preset '%s' to '%s': %s", presetName, name, preset)
+	config := preset.Config()
+	for _, key := range []string{helpers.LimitCPUKey, helpers.LimitMemoryKey} {
+		value, ok := config[key]
+		if !ok {
+			continue
+		}
+		if err := manager.SetConfigValue(ctx, name, key, value); err != nil {
+			return err
+		}
+		logger.Info("Set %s=%s on '%s'", key, value, name)
+	}
+	if preset.Disk != "" {
+		if err := setDeviceLimits(ctx, manager, name, "root", []string{"size=" + preset.Disk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listSizePresets prints the built-in and configured size presets
+func listSizePresets(settings *helpers.Settings, out io.Writer) error {
+	presets := helpers.SizePresets(settings)
+	table := render.NewTable("NAME", "CPU", "MEMORY", "DISK", "SOURCE")
+	for _, name := range helpers.SizePresetNames(presets) {
+		preset := presets[name]
+		source := "built-in"
+		if _, ok := settings.Presets[name]; ok {
+			source = "config"
+		}
+		table.AddRow(name, valueOrDash(preset.CPU), valueOrDash(preset.Memory), valueOrDash(preset.Disk), source)
+	}
+	return table.Render(out, render.Options{})
+}
+
+// showContainerLimits prints a container's CPU and memory limits
+func showContainerLimits(ctx context.Context, manager LimitsManager, name string, out io.Writer) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Container:       %s\n", state.Name)
+	fmt.Fprintf(out, "CPU:             %s\n", cpuLimitLabel(state.Config[helpers.LimitCPUKey]))
+	fmt.Fprintf(out, "Memory:          %s\n", valueOrDefault(state.Config[helpers.LimitMemoryKey], "no limit"))
+	fmt.Fprintf(out, "Memory swap:     %s\n", valueOrDefault(state.Config[helpers.LimitMemorySwapKey], "true (default)"))
+	fmt.Fprintf(out, "Memory enforce:  %s\n", valueOrDefault(state.Config[helpers.LimitMemoryEnforceKey], helpers.MemoryEnforceHard+" (default)"))
+	fmt.Fprintf(out, "Disk priority:   %s\n", valueOrDefault(state.Config[helpers.LimitDiskPriorityKey], "5 (default)"))
+	fmt.Fprintf(out, "Disk IO:         %s\n", valueOrDefault(rateLimitLabel(state.Devices["root"], helpers.LimitReadKey, helpers.LimitWriteKey), "no limit"))
+	fmt.Fprintf(out, "Network:         %s\n", valueOrDefault(rateLimitLabel(state.Devices[helpers.PrimaryNIC(state.Devices)], helpers.LimitEgressKey, helpers.LimitIngressKey), "no limit"))
+	return nil
+}
+
+// rateLimitLabel describes the rate limits set on a device, e.g.
+// "read 50MB/s, write 50MB/s"; "" when none of keys are set
+func rateLimitLabel(device map[string]string, keys ...string) string {
+	var parts []string
+	for _, key := range keys {
+		value := device[key]
+		if value == "" {
+			continue
+		}
+		if !strings.HasSuffix(value, "iops") {
+			value += "/s"
+		}
+		parts = append(parts, strings.TrimPrefix(key, "limits.")+" "+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cpuLimitLabel tells a CPU count in limits.cpu from a list of pinned CPUs
+func cpuLimitLabel(value string) string {
+	switch {
+	case value == "":
+		return "no limit"
+	case strings.ContainsAny(value, ",-"):
+		return value + " (pinned)"
+	default:
+		return value + " CPUs"
+	}
+}
+
+// valueOrDefault returns value, or fallback when it is empty
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(limitsCmd)
+
+	limitsCmd.AddCommand(limitsSetCmd)
+	limitsCmd.AddCommand(limitsApplyCmd)
+	limitsCmd.AddCommand(limitsShowCmd)
+	limitsCmd.AddCommand(limitsPresetsCmd)
+	skipLXCCheck(limitsPresetsCmd)
+
+	limitsCmd.PersistentFlags().DurationVarP(&limitsTimeout, "timeout", "t", 30*time.Second, "Timeout for the limits operation")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.CPUPin, "cpu-pin", "", "Host CPUs to pin the container to, e.g. 0,1 or 0-3")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.MemorySwap, "memory-swap", "", "Whether the container's memory may be swapped (true, false)")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.MemoryEnforce, "memory-enforce", "", "How limits.memory is enforced (hard, soft)")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.DiskRead, "disk-read", "", "Root disk read limit, e.g. 50MB or 1000iops")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.DiskWrite, "disk-write", "", "Root disk write limit, e.g. 50MB or 1000iops")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.DiskPriority, "disk-priority", "", "Share of disk time when containers compete, 0 to 10")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.NetEgress, "net-egress", "", "Outgoing network limit, e.g. 100Mbit")
+	limitsSetCmd.Flags().StringVar(&limitsOptions.NetIngress, "net-ingress", "", "Incoming network limit, e.g. 100Mbit")
+	limitsApplyCmd.Flags().StringVar(&limitsPreset, "preset", "", "Size preset to apply, e.g. small, medium or large")
+	limitsApplyCmd.MarkFlagRequired("preset")
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockLimitsManager for testing limits command
+type MockLimitsManager struct {
+	States       []helpers.ContainerState
+	Host         *helpers.HostTopology
+	HostErr      error
+	LocalConfig  string
+	SetCommands  []string
+	DeviceLimits []string
+}
+
+func (m *MockLimitsManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockLimitsManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	m.SetCommands = append(m.SetCommands, key+"="+value)
+	return nil
+}
+
+func (m *MockLimitsManager) HostTopology(ctx context.Context) (*helpers.HostTopology, error) {
+	return m.Host, m.HostErr
+}
+
+func (m *MockLimitsManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return []byte(m.LocalConfig), nil
+}
+
+func (m *MockLimitsManager) SetDeviceLimits(ctx context.Context, containerName, device string, limits []string, local bool) error {
+	action := "override"
+	if local {
+		action = "set"
+	}
+	m.DeviceLimits = append(m.DeviceLimits, fmt.Sprintf("%s %s %s", action, device, strings.Join(limits, " ")))
+	return nil
+}
+
+func newMockLimitsManager() *MockLimitsManager {
+	return &MockLimitsManager{
+		States: []helpers.ContainerState{
+			{
+				Name:   "web",
+				Type:   helpers.InstanceTypeContainer,
+				Config: map[string]string{"limits.memory": "2GiB"},
+				Devices: map[string]map[string]string{
+					"root": {"type": "disk", "path": "/", "pool": "default"},
+					"eth0": {"type": "nic", "network": "lxdbr0"},
+				},
+			},
+			{Name: "vm", Type: helpers.InstanceTypeVM, Config: map[string]string{}},
+		},
+		Host:        &helpers.HostTopology{CPUs: []int{0, 1, 2, 3}, SwapBytes: 1 << 30},
+		LocalConfig: "devices:\n  root:\n    type: disk\n    path: /\n    pool: default\n",
+	}
+}
+
+func TestSetContainerLimits(t *testing.T) {
+	ctx := context.Background()
+	manager := newMockLimitsManager()
+
+	err := setContainerLimits(ctx, manager, "web", LimitOptions{CPUPin: "0,1", MemorySwap: "false", MemoryEnforce: "Soft"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "limits.cpu=0,1 limits.memory.swap=false limits.memory.enforce=soft"
+	if got := strings.Join(manager.SetCommands, " "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// A single CPU must not be mistaken for a CPU count
+	manager.SetCommands = nil
+	if err := setContainerLimits(ctx, manager, "web", LimitOptions{CPUPin: "2"}); err != nil || manager.SetCommands[0] != "limits.cpu=2-2" {
+		t.Errorf("expected a pinning range, got %v, %v", manager.SetCommands, err)
+	}
+
+	manager.SetCommands = nil
+	tests := []struct {
+		name string
+		opts LimitOptions
+		err  string
+	}{
+		{name: "web", opts: LimitOptions{}, err: "nothing to set"},
+		{name: "web", opts: LimitOptions{CPUPin: "2-6"}, err: "no CPU 4, 5, 6"},
+		{name: "web", opts: LimitOptions{MemorySwap: "maybe"}, err: "invalid --memory-swap"},
+		{name: "web", opts: LimitOptions{MemoryEnforce: "strict"}, err: "invalid memory enforcement"},
+		{name: "vm", opts: LimitOptions{MemorySwap: "false"}, err: "only apply to containers"},
+		{name: "web", opts: LimitOptions{DiskRead: "fast"}, err: "invalid disk rate"},
+		{name: "web", opts: LimitOptions{NetEgress: "100MB"}, err: "invalid network rate"},
+		{name: "web", opts: LimitOptions{DiskPriority: "11"}, err: "invalid disk priority"},
+		{name: "vm", opts: LimitOptions{NetIngress: "1Gbit"}, err: "no network device"},
+		{name: "missing", opts: LimitOptions{CPUPin: "0"}, err: "does not exist"},
+	}
+	for _, tt := range tests {
+		if err := setContainerLimits(ctx, manager, tt.name, tt.opts); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s %+v: expected error containing %q, got %v", tt.name, tt.opts, tt.err, err)
+		}
+	}
+	if len(manager.SetCommands) != 0 {
+		t.Errorf("invalid limits should set nothing, set %v", manager.SetCommands)
+	}
+
+	// Without the host's topology the pinning is passed on unchecked
+	manager.HostErr = fmt.Errorf("remote server")
+	if err := setContainerLimits(ctx, manager, "vm", LimitOptions{CPUPin: "8-9"}); err != nil || manager.SetCommands[0] != "limits.cpu=8,9" {
+		t.Errorf("expected the host check to be skipped, got %v, %v", manager.SetCommands, err)
+	}
+}
+
+func TestSetContainerIOLimits(t *testing.T) {
+	manager := newMockLimitsManager()
+
+	opts := LimitOptions{DiskRead: "50MB", DiskWrite: "1000iops", DiskPriority: "8", NetEgress: "100Mbit"}
+	if err := setContainerLimits(context.Background(), manager, "web", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.SetCommands, " ") != "limits.disk.priority=8" {
+		t.Errorf("expected the disk priority on the instance, got %v", manager.SetCommands)
+	}
+	// The root disk is the container's own; eth0 comes from a profile
+	expected := []string{"set root limits.read=50MB limits.write=1000iops", "override eth0 limits.egress=100Mbit"}
+	if strings.Join(manager.DeviceLimits, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("expected %v, got %v", expected, manager.DeviceLimits)
+	}
+}
+
+func TestShowContainerLimits(t *testing.T) {
+	manager := newMockLimitsManager()
+	manager.States[0].Config[helpers.LimitCPUKey] = "0-1"
+	manager.States[0].Config[helpers.LimitMemorySwapKey] = "false"
+	manager.States[0].Devices["root"][helpers.LimitReadKey] = "50MB"
+	manager.States[0].Devices["root"][helpers.LimitWriteKey] = "1000iops"
+
+	var out bytes.Buffer
+	if err := showContainerLimits(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, expected := range []string{"0-1 (pinned)", "Memory:          2GiB", "Memory swap:     false", "hard (default)", "read 50MB/s, write 1000iops", "Network:         no limit"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	if label := cpuLimitLabel("4"); label != "4 CPUs" {
+		t.Errorf("expected a CPU count, got %q", label)
+	}
+}
+
+func TestApplyLimitsPreset(t *testing.T) {
+	manager := newMockLimitsManager()
+	preset := helpers.SizePreset{CPU: "4", Memory: "8GiB", Disk: "50GiB"}
+	if err := applyLimitsPreset(context.Background(), manager, "web", "large", preset); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.SetCommands, " ") != "limits.cpu=4 limits.memory=8GiB" {
+		t.Errorf("unexpected config changes %v", manager.SetCommands)
+	}
+	if len(manager.DeviceLimits) != 1 || manager.DeviceLimits[0] != "set root size=50GiB" {
+		t.Errorf("expected the root disk to be resized, got %v", manager.DeviceLimits)
+	}
+
+	manager = newMockLimitsManager()
+	if err := applyLimitsPreset(context.Background(), manager, "web", "ci", helpers.SizePreset{Memory: "2GiB"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.SetCommands, " ") != "limits.memory=2GiB" || len(manager.DeviceLimits) != 0 {
+		t.Errorf("expected only the memory limit, got %v %v", manager.SetCommands, manager.DeviceLimits)
+	}
+
+	if err := applyLimitsPreset(context.Background(), manager, "ghost", "large", preset); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+}
+
+func TestListSizePresets(t *testing.T) {
+	settings := &helpers.Settings{Presets: map[string]helpers.SizePreset{
+		"large": {CPU: "8", Memory: "16GiB"},
+		"ci":    {CPU: "2"},
+	}}
+	var out bytes.Buffer
+	if err := listSizePresets(settings, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "ci") {
+		t.Fatalf("expected four presets sorted by name, got:\n%s", out.String())
+	}
+	for _, want := range []string{"large   8    16GiB   -", "small   1    1GiB    10GiB  built-in"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
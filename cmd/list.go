@@ -0,0 +1,183 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listUnmanaged bool
+	listSelector  string
+	listTimeout   time.Duration
+	listTable     tableFlags
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List containers managed by lxc-go-cli",
+	Long: `List containers created or adopted by lxc-go-cli with their status, address
+and the tool version that manages them. Ephemeral containers, which are
+deleted when they stop, are marked in the status column. On a cluster a
+location column shows the member each container runs on.
+
+Containers not managed by this tool are hidden unless --unmanaged is given.
+Deleted containers in the trash are hidden too; see 'trash list'.
+--selector only shows containers whose labels match (see 'label').
+
+Pick and order columns with --columns and leave out the header with
+--no-header, e.g. to feed names to other commands. On a terminal the status
+is colored unless NO_COLOR is set.
+
+Examples:
+  lxc-go-cli list
+  lxc-go-cli list --unmanaged
+  lxc-go-cli list --selector env=prod,team=core
+  lxc-go-cli list --columns name,ipv4 --no-header`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+
+		selector, err := helpers.ParseLabelSelector(listSelector)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		return listContainers(ctx, &DefaultListManager{}, listUnmanaged, selector, out, listTable.options(out))
+	},
+}
+
+// ListManager interface for dependency injection
+type ListManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+}
+
+// DefaultListManager implements ListManager using helpers
+type DefaultListManager struct{}
+
+func (d *DefaultListManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+// listContainers prints managed containers, or every container with
+// includeUnmanaged, keeping those whose labels match the selector
+func listContainers(ctx context.Context, manager ListManager, includeUnmanaged bool, selector helpers.LabelSelector, out io.Writer, table render.Options) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	warnPendingMigrations(states)
+
+	states = helpers.FilterContainersBySelector(helpers.FilterManagedContainers(helpers.WithoutTrash(states), includeUnmanaged), selector)
+	if len(states) == 0 {
+		if len(selector) > 0 {
+			fmt.Fprintf(out, "No containers match selector '%s'\n", selector)
+		} else if includeUnmanaged {
+			fmt.Fprintln(out, "No containers found")
+		} else {
+			fmt.Fprintln(out, "No managed containers found (use --unmanaged to show all containers)")
+		}
+		return nil
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	var rendered strings.Builder
+	if err := containerListTable(states).Render(&rendered, table); err != nil {
+		return err
+	}
+	if !table.NoHeader {
+		printActiveContext(out)
+	}
+	fmt.Fprint(out, rendered.String())
+	return nil
+}
+
+// containerListTable lays out containers with their addresses, their
+// management marker and, on a cluster, the member each one runs on
+func containerListTable(states []helpers.ContainerState) *render.Table {
+	clustered := false
+	for _, state := range states {
+		if state.Location != "" {
+			clustered = true
+			break
+		}
+	}
+
+	headers := []string{"NAME", "STATUS", "IPV4", "IPV6", "MANAGED", "CREATED"}
+	if clustered {
+		headers = []string{"NAME", "STATUS", "IPV4", "IPV6", "LOCATION", "MANAGED", "CREATED"}
+	}
+	table := render.NewTable(headers...)
+	for _, state := range states {
+		row := []string{state.Name, statusLabel(state), valueOrDash(state.IPv4), valueOrDash(state.IPv6)}
+		if clustered {
+			row = append(row, valueOrDash(state.Location))
+		}
+		table.AddRow(append(row, managedLabel(state.Config), valueOrDash(state.Config[helpers.ManagedCreatedKey]))...)
+	}
+	table.Style("status", statusColor)
+	return table
+}
+
+// statusColor colors running containers green, stopped ones red and frozen
+// ones yellow
+func statusColor(label string) render.Color {
+	switch {
+	case strings.HasPrefix(label, "Running"):
+		return render.Green
+	case strings.HasPrefix(label, "Stopped"):
+		return render.Red
+	case strings.HasPrefix(label, helpers.StatusFrozen):
+		return render.Yellow
+	}
+	return render.NoColor
+}
+
+// statusLabel shows the container status, marking ephemeral containers
+func statusLabel(state helpers.ContainerState) string {
+	if state.Ephemeral {
+		return state.Status + " (ephemeral)"
+	}
+	return state.Status
+}
+
+// managedLabel shows the managing tool version, "yes" for containers created
+// before the marker existed and "no" for unmanaged containers
+func managedLabel(config map[string]string) string {
+	if !helpers.IsManagedContainer(config) {
+		return "no"
+	}
+	if version := config[helpers.ManagedVersionKey]; version != "" {
+		return version
+	}
+	return "yes"
+}
+
+// valueOrDash renders empty values as "-"
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().BoolVar(&listUnmanaged, "unmanaged", false, "Also show containers not managed by lxc-go-cli")
+	listCmd.Flags().StringVar(&listSelector, "selector", "", "Only show containers whose labels match, e.g. env=prod,team=core")
+	listCmd.Flags().DurationVarP(&listTimeout, "timeout", "t", 30*time.Second, "Timeout for the list operation")
+	addTableFlags(listCmd, &listTable)
+}
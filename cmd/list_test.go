@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockListManager for testing list and delete commands
+type MockListManager struct {
+	States  []helpers.ContainerState
+	Deleted []string
+}
+
+func (m *MockListManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockListManager) DeleteContainer(ctx context.Context, name string, force bool) error {
+	m.Deleted = append(m.Deleted, name)
+	return nil
+}
+
+func newMockListManager() *MockListManager {
+	return &MockListManager{States: []helpers.ContainerState{
+		{Name: "web", Status: "Running", IPv4: "10.0.0.2", IPv6: "fd42::2", Config: map[string]string{
+			helpers.ManagedMarkerKey:  "true",
+			helpers.ManagedVersionKey: "1.abc123",
+			helpers.ManagedCreatedKey: "2025-01-01T12:00:00Z",
+		}},
+		{Name: "legacy", Status: "Stopped", Config: map[string]string{"user.app-password": "c2VjcmV0"}},
+		{Name: "ci", Status: "Running", Ephemeral: true, Config: map[string]string{helpers.ManagedMarkerKey: "true"}},
+		{Name: "unrelated", Status: "Running", Config: map[string]string{}},
+	}}
+}
+
+func TestListContainers(t *testing.T) {
+	var out bytes.Buffer
+	if err := listContainers(context.Background(), newMockListManager(), false, nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output := out.String()
+	for _, expected := range []string{"legacy", "web", "1.abc123", "2025-01-01T12:00:00Z", "10.0.0.2", "IPV6", "fd42::2", "Running (ephemeral)"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "unrelated") {
+		t.Error("unmanaged containers should be hidden by default")
+	}
+	if strings.Index(output, "legacy") > strings.Index(output, "web") {
+		t.Error("containers should be sorted by name")
+	}
+
+	out.Reset()
+	if err := listContainers(context.Background(), newMockListManager(), true, nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "unrelated") {
+		t.Error("--unmanaged should include unmanaged containers")
+	}
+
+	out.Reset()
+	empty := &MockListManager{States: []helpers.ContainerState{{Name: "unrelated", Config: map[string]string{}}}}
+	if err := listContainers(context.Background(), empty, false, nil, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "--unmanaged") {
+		t.Errorf("expected hint about --unmanaged, got %s", out.String())
+	}
+}
+
+func TestListContainersSelector(t *testing.T) {
+	manager := newMockListManager()
+	manager.States[0].Config[helpers.LabelConfigPrefix+"env"] = "prod"
+	manager.States[2].Config[helpers.LabelConfigPrefix+"env"] = "dev"
+
+	selector, err := helpers.ParseLabelSelector("env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := listContainers(context.Background(), manager, false, selector, &out, render.Options{Columns: []string{"name"}, NoHeader: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.String() != "web\n" {
+		t.Errorf("expected only 'web' to match, got %q", out.String())
+	}
+
+	selector, _ = helpers.ParseLabelSelector("team=core")
+	out.Reset()
+	if err := listContainers(context.Background(), manager, false, selector, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "No containers match selector 'team=core'") {
+		t.Errorf("expected no match message, got %s", out.String())
+	}
+}
+
+func TestManagedLabel(t *testing.T) {
+	tests := []struct {
+		config   map[string]string
+		expected string
+	}{
+		{config: map[string]string{helpers.ManagedMarkerKey: "true", helpers.ManagedVersionKey: "1.abc"}, expected: "1.abc"},
+		{config: map[string]string{"user.app-password": "x"}, expected: "yes"},
+		{config: map[string]string{}, expected: "no"},
+		{config: map[string]string{helpers.ManagedMarkerKey: "false"}, expected: "no"},
+	}
+	for _, tt := range tests {
+		if label := managedLabel(tt.config); label != tt.expected {
+			t.Errorf("expected '%s', got '%s' for %v", tt.expected, label, tt.config)
+		}
+	}
+}
+
+func TestListContainersColumns(t *testing.T) {
+	var out bytes.Buffer
+	table := render.Options{Columns: []string{"name", "ipv4"}, NoHeader: true}
+	if err := listContainers(context.Background(), newMockListManager(), false, nil, &out, table); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.String() != "ci      -\nlegacy  -\nweb     10.0.0.2\n" {
+		t.Errorf("expected only the selected columns without a header, got:\n%q", out.String())
+	}
+
+	out.Reset()
+	err := listContainers(context.Background(), newMockListManager(), false, nil, &out, render.Options{Columns: []string{"size"}})
+	if err == nil || !strings.Contains(err.Error(), "unknown column 'size'") || out.Len() != 0 {
+		t.Errorf("expected an unknown column error before any output, got %v and %q", err, out.String())
+	}
+}
+
+func TestStatusColor(t *testing.T) {
+	for label, want := range map[string]render.Color{
+		"Running (ephemeral)": render.Green,
+		"Stopped":             render.Red,
+		"Frozen":              render.Yellow,
+		"Error":               render.NoColor,
+	} {
+		if got := statusColor(label); got != want {
+			t.Errorf("%s: expected color %q, got %q", label, want, got)
+		}
+	}
+}
@@ -50,8 +50,10 @@ func TestLogLevelInitialization(t *testing.T) {
 			// Set the flag value
 			logLevel = tt.flagValue
 
-			// Call the PersistentPreRun function directly
-			rootCmd.PersistentPreRun(rootCmd, []string{})
+			// Call the PersistentPreRunE function directly
+			if err := rootCmd.PersistentPreRunE(rootCmd, []string{}); err != nil {
+				t.Fatalf("PersistentPreRunE failed: %v", err)
+			}
 
 			// Check that the level was set correctly
 			if logger.GetLevel() != tt.expectedLevel {
@@ -80,10 +82,10 @@ func TestLoggingInCommands(t *testing.T) {
 		ConfigureContainerSecurityFunc: func(containerName string) error {
 			return nil
 		},
-		RunInContainerFunc: func(containerName string, args ...string) error {
+		RunInContainerFunc: func(ctx context.Context, containerName string, args ...string) error {
 			return nil
 		},
-		RestartContainerFunc: func(name string) error {
+		RestartContainerFunc: func(ctx context.Context, name string) error {
 			return nil
 		},
 	}
@@ -0,0 +1,167 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsDockerServices []string
+	logsIncludeJournal bool
+	logsFollow         bool
+	logsSince          time.Duration
+	logsProjectDir     string
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs [container-name]",
+	Short: "Show journald or Docker Compose logs from an LXC container",
+	Long: `Show logs from an LXC container.
+
+By default the container's systemd journal is shown. With --docker, the logs of
+the given Docker Compose service are shown instead. --docker may be repeated to
+show several services at once, and --journal adds the journal back in. When more
+than one source is shown, every line is prefixed with the name of its source.
+Without a name the current container chosen with 'use' is used.
+
+Examples:
+  lxc-go-cli logs mycontainer                        # Container journal
+  lxc-go-cli logs mycontainer --since 1h             # Journal entries from the last hour
+  lxc-go-cli logs mycontainer --docker web --follow  # Follow the 'web' compose service
+  lxc-go-cli logs mycontainer --docker web --docker db --journal`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerName, err := containerArg(args)
+		if err != nil {
+			return err
+		}
+
+		// Stop streaming cleanly on Ctrl-C
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		opts := helpers.LogOptions{
+			Follow:     logsFollow,
+			Since:      logsSince,
+			ProjectDir: logsProjectDir,
+		}
+
+		manager := &DefaultLogsManager{}
+		return showLogs(ctx, manager, containerName, logSources(logsDockerServices, logsIncludeJournal), opts, cmd.OutOrStdout())
+	},
+}
+
+// LogSource identifies where a stream of log lines comes from
+type LogSource struct {
+	// Name is used as the line prefix when several sources are multiplexed
+	Name string
+	// Service is the Docker Compose service name; empty means the journal
+	Service string
+}
+
+// IsDocker returns true if the source is a Docker Compose service
+func (s LogSource) IsDocker() bool {
+	return s.Service != ""
+}
+
+// LogsManager interface for dependency injection
+type LogsManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	StreamLogs(ctx context.Context, containerName string, source LogSource, opts helpers.LogOptions, w io.Writer) error
+}
+
+// DefaultLogsManager implements LogsManager using helpers
+type DefaultLogsManager struct{}
+
+func (d *DefaultLogsManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultLogsManager) StreamLogs(ctx context.Context, containerName string, source LogSource, opts helpers.LogOptions, w io.Writer) error {
+	if source.IsDocker() {
+		return helpers.StreamContainerCommand(ctx, containerName, opts.ProjectDir, w, helpers.BuildDockerLogsCommand(source.Service, opts)...)
+	}
+	return helpers.StreamContainerCommand(ctx, containerName, "", w, helpers.BuildJournalCommand(opts)...)
+}
+
+// logSources builds the list of log sources from the command flags
+func logSources(dockerServices []string, includeJournal bool) []LogSource {
+	var sources []LogSource
+	if len(dockerServices) == 0 || includeJournal {
+		sources = append(sources, LogSource{Name: "journal"})
+	}
+	for _, service := range dockerServices {
+		sources = append(sources, LogSource{Name: service, Service: service})
+	}
+	return sources
+}
+
+// showLogs streams logs from every source concurrently, prefixing lines when there is more than one
+func showLogs(ctx context.Context, manager LogsManager, containerName string, sources []LogSource, opts helpers.LogOptions, out io.Writer) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("at least one log source is required")
+	}
+
+	// Check if container exists
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	logger.Debug("Showing logs for container '%s' from %d source(s)", containerName, len(sources))
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+
+	for i, source := range sources {
+		prefix := ""
+		if len(sources) > 1 {
+			prefix = fmt.Sprintf("[%s] ", source.Name)
+		}
+		writer := helpers.NewPrefixWriter(out, prefix, &mu)
+
+		wg.Add(1)
+		go func(i int, source LogSource) {
+			defer wg.Done()
+			errs[i] = manager.StreamLogs(ctx, containerName, source, opts, writer)
+			writer.Flush()
+		}(i, source)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to read %s logs from container '%s': %w", sources[i].Name, containerName, err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringArrayVar(&logsDockerServices, "docker", nil, "Show Docker Compose logs for the given service (repeatable)")
+	logsCmd.Flags().BoolVar(&logsIncludeJournal, "journal", false, "Include the container journal when --docker is used")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only show logs newer than a relative duration (e.g. 1h, 30m)")
+	logsCmd.Flags().StringVar(&logsProjectDir, "project-dir", "/home/app", "Docker Compose project directory inside the container")
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockLogsManager for testing logs command
+type MockLogsManager struct {
+	ExistingContainers map[string]bool
+	SourceOutput       map[string]string
+	StreamError        error
+	Calls              map[string]int
+}
+
+func (m *MockLogsManager) ContainerExists(ctx context.Context, name string) bool {
+	m.trackCall("ContainerExists")
+	return m.ExistingContainers[name]
+}
+
+func (m *MockLogsManager) StreamLogs(ctx context.Context, containerName string, source LogSource, opts helpers.LogOptions, w io.Writer) error {
+	m.trackCall("StreamLogs")
+	if m.StreamError != nil {
+		return m.StreamError
+	}
+	_, err := io.WriteString(w, m.SourceOutput[source.Name])
+	return err
+}
+
+func (m *MockLogsManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func (m *MockLogsManager) GetCallCount(method string) int {
+	if m.Calls == nil {
+		return 0
+	}
+	return m.Calls[method]
+}
+
+func TestLogsCommand(t *testing.T) {
+	if logsCmd == nil {
+		t.Fatal("logsCmd should not be nil")
+	}
+
+	if logsCmd.Use != "logs [container-name]" {
+		t.Errorf("expected Use to be 'logs [container-name]', got '%s'", logsCmd.Use)
+	}
+
+	for _, name := range []string{"docker", "journal", "follow", "since", "project-dir"} {
+		if logsCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+}
+
+func TestLogSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []string
+		journal  bool
+		expected []string
+	}{
+		{name: "journal by default", expected: []string{"journal"}},
+		{name: "docker only", services: []string{"web"}, expected: []string{"web"}},
+		{name: "docker and journal", services: []string{"web", "db"}, journal: true, expected: []string{"journal", "web", "db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := logSources(tt.services, tt.journal)
+			if len(sources) != len(tt.expected) {
+				t.Fatalf("expected %d sources, got %d", len(tt.expected), len(sources))
+			}
+			for i, name := range tt.expected {
+				if sources[i].Name != name {
+					t.Errorf("expected source[%d] to be '%s', got '%s'", i, name, sources[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestShowLogs(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerName string
+		sources       []LogSource
+		streamError   error
+		expectedError string
+		expectedLines []string
+	}{
+		{
+			name:          "empty container name",
+			containerName: "",
+			sources:       logSources(nil, false),
+			expectedError: "container name is required",
+		},
+		{
+			name:          "container does not exist",
+			containerName: "missing",
+			sources:       logSources(nil, false),
+			expectedError: "container 'missing' does not exist",
+		},
+		{
+			name:          "single source is not prefixed",
+			containerName: "test-container",
+			sources:       logSources(nil, false),
+			expectedLines: []string{"boot ok"},
+		},
+		{
+			name:          "multiple sources are prefixed",
+			containerName: "test-container",
+			sources:       logSources([]string{"web"}, true),
+			expectedLines: []string{"[journal] boot ok", "[web] listening"},
+		},
+		{
+			name:          "stream fails",
+			containerName: "test-container",
+			sources:       logSources([]string{"web"}, false),
+			streamError:   fmt.Errorf("exec failed"),
+			expectedError: "failed to read web logs from container 'test-container'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockLogsManager{
+				ExistingContainers: map[string]bool{"test-container": true},
+				SourceOutput: map[string]string{
+					"journal": "boot ok\n",
+					"web":     "listening",
+				},
+				StreamError: tt.streamError,
+			}
+
+			var out bytes.Buffer
+			err := showLogs(context.Background(), manager, tt.containerName, tt.sources, helpers.LogOptions{}, &out)
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("expected error containing '%s', got nil", tt.expectedError)
+				} else if !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			for _, line := range tt.expectedLines {
+				if !strings.Contains(out.String(), line+"\n") {
+					t.Errorf("expected output to contain line '%s', got '%s'", line, out.String())
+				}
+			}
+			if manager.GetCallCount("StreamLogs") != len(tt.sources) {
+				t.Errorf("expected %d calls to StreamLogs, got %d", len(tt.sources), manager.GetCallCount("StreamLogs"))
+			}
+		})
+	}
+}
+
+func TestDefaultLogsManager(t *testing.T) {
+	// Test that DefaultLogsManager implements LogsManager interface
+	var manager LogsManager = &DefaultLogsManager{}
+	_ = manager
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateTimeout time.Duration
+	migrateDryRun  bool
+)
+
+// migrateStateCmd represents the migrate-state command
+var migrateStateCmd = &cobra.Command{
+	Use:   "migrate-state [container-name]",
+	Short: "Upgrade the metadata of managed containers to the current format",
+	Long: `Upgrade the config keys and device names this tool keeps on managed
+containers when they are in a format an older release wrote, or that went
+stale, such as port forwarding devices still named after the name a
+container had before 'restore-from-trash --as' restored it under another.
+
+'list' warns when containers need migrating. Migrating is safe to repeat:
+containers already in the current format are left alone. Without a container
+name every managed container is migrated; trashed containers are migrated
+once restored. Renaming a port forwarding device removes and re-adds it, so
+the forwarding stops for a moment.
+
+Examples:
+  lxc-go-cli migrate-state --dry-run
+  lxc-go-cli migrate-state web1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+		defer cancel()
+
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return migrateState(ctx, &DefaultMigrateManager{}, name, migrateDryRun)
+	},
+}
+
+// MigrateManager interface for dependency injection
+type MigrateManager interface {
+	ListManager
+	ApplyStateChange(ctx context.Context, containerName string, change helpers.StateChange) error
+}
+
+// DefaultMigrateManager implements MigrateManager using helpers
+type DefaultMigrateManager struct {
+	DefaultListManager
+}
+
+func (d *DefaultMigrateManager) ApplyStateChange(ctx context.Context, containerName string, change helpers.StateChange) error {
+	return helpers.ApplyStateChange(ctx, containerName, change)
+}
+
+// migrateState applies the pending migrations of one container, or of every
+// managed container when name is empty
+func migrateState(ctx context.Context, manager MigrateManager, name string, dryRun bool) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		var found []helpers.ContainerState
+		for _, state := range states {
+			if state.Name == name {
+				found = append(found, state)
+			}
+		}
+		if len(found) == 0 {
+			return containerNotFound(name)
+		}
+		states = found
+	}
+
+	pending := helpers.PendingMigrations(states)
+	if len(pending) == 0 {
+		logger.Info("Nothing to migrate: container metadata is in the current format")
+		return nil
+	}
+
+	changed := 0
+	for _, migration := range pending {
+		logger.Info("Container '%s': %s (%s)", migration.Container, migration.Migration.Description, migration.Migration.ID)
+		for _, change := range migration.Changes {
+			if dryRun {
+				logger.Info("  Would %s", change)
+				continue
+			}
+			logger.Info("  %s", change)
+			if err := manager.ApplyStateChange(ctx, migration.Container, change); err != nil {
+				return err
+			}
+			changed++
+		}
+	}
+
+	if !dryRun {
+		logger.Info("Migrated %d container(s) with %d change(s)", migratedContainers(pending), changed)
+	}
+	return nil
+}
+
+// migratedContainers counts the containers among pending migrations
+func migratedContainers(pending []helpers.PendingMigration) int {
+	containers := make(map[string]bool)
+	for _, migration := range pending {
+		containers[migration.Container] = true
+	}
+	return len(containers)
+}
+
+// warnPendingMigrations points at migrate-state when containers still use a
+// deprecated metadata format
+func warnPendingMigrations(states []helpers.ContainerState) {
+	pending := helpers.PendingMigrations(states)
+	if len(pending) == 0 {
+		return
+	}
+	logger.Warn("%d container(s) use a deprecated metadata format; run 'lxc-go-cli migrate-state --dry-run' to see the changes", migratedContainers(pending))
+}
+
+func init() {
+	rootCmd.AddCommand(migrateStateCmd)
+
+	migrateStateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Only show the changes")
+	migrateStateCmd.Flags().DurationVarP(&migrateTimeout, "timeout", "t", 2*time.Minute, "Timeout for the migration")
+}
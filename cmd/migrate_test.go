@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockMigrateManager is a mock implementation of MigrateManager
+type MockMigrateManager struct {
+	MockListManager
+	Applied []string
+}
+
+func (m *MockMigrateManager) ApplyStateChange(ctx context.Context, containerName string, change helpers.StateChange) error {
+	m.Applied = append(m.Applied, containerName+": "+change.String())
+	return nil
+}
+
+func newMockMigrateManager() *MockMigrateManager {
+	return &MockMigrateManager{MockListManager: MockListManager{States: []helpers.ContainerState{
+		{Name: "web", Config: map[string]string{"user.app-password": "c2VjcmV0"}},
+		{Name: "api", Config: map[string]string{helpers.ManagedMarkerKey: "true", helpers.PreviousNamesKey: "old"}, Devices: map[string]map[string]string{
+			"old-8080-80-tcp": {"type": "proxy"},
+		}},
+	}}}
+}
+
+func TestMigrateState(t *testing.T) {
+	manager := newMockMigrateManager()
+	if err := migrateState(context.Background(), manager, "", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Applied) != 0 {
+		t.Errorf("dry run should change nothing, applied %v", manager.Applied)
+	}
+
+	if err := migrateState(context.Background(), manager, "", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"api: rename device 'old-8080-80-tcp' to 'api-8080-80-tcp'",
+		"web: set " + helpers.ManagedMarkerKey + "=true",
+	}
+	if len(manager.Applied) != len(expected) || manager.Applied[0] != expected[0] || manager.Applied[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, manager.Applied)
+	}
+}
+
+func TestMigrateStateOneContainer(t *testing.T) {
+	manager := newMockMigrateManager()
+	if err := migrateState(context.Background(), manager, "web", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Applied) != 1 || manager.Applied[0] != "web: set "+helpers.ManagedMarkerKey+"=true" {
+		t.Errorf("expected only web to be migrated, got %v", manager.Applied)
+	}
+
+	previous := exactNames
+	exactNames = true
+	defer func() { exactNames = previous }()
+	err := migrateState(context.Background(), manager, "missing", false)
+	if !errors.Is(err, helpers.ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
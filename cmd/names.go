@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// exactNames turns off matching mistyped container names, set by --exact
+var exactNames bool
+
+// containerNames lists the existing containers for name matching; tests
+// replace it
+var containerNames = func() ([]string, error) {
+	states, err := helpers.ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(states))
+	for _, state := range states {
+		names = append(names, state.Name)
+	}
+	return names, nil
+}
+
+// resolveContainerName maps a name that differs from an existing container
+// only in case, hyphens or underscores to that container, e.g. webapi to
+// web-api. Any other name, or every name with --exact, is returned as is.
+func resolveContainerName(name string) string {
+	if exactNames || name == "" {
+		return name
+	}
+	names, err := containerNames()
+	if err != nil {
+		logger.Debug("Could not list containers to match '%s': %v", name, err)
+		return name
+	}
+	match, ok := helpers.MatchContainerName(name, names)
+	if !ok || match == name {
+		return name
+	}
+	logger.Info("%s", i18n.T("container.name_matched", match, name))
+	return match
+}
+
+// containerNotFound is the error for a container that does not exist, with
+// close names as a hint unless --exact is set
+func containerNotFound(name string) error {
+	if exactNames {
+		return helpers.WithKind(i18n.Errorf("container.not_found", name), helpers.ErrNotFound)
+	}
+	names, err := containerNames()
+	if err != nil {
+		logger.Debug("Could not list containers to suggest names for '%s': %v", name, err)
+		return helpers.WithKind(i18n.Errorf("container.not_found", name), helpers.ErrNotFound)
+	}
+	similar := helpers.SimilarContainerNames(name, names)
+	if len(similar) == 0 {
+		return helpers.WithKind(i18n.Errorf("container.not_found", name), helpers.ErrNotFound)
+	}
+	return helpers.WithKind(i18n.Errorf("container.not_found_similar", name, joinAlternatives(similar)), helpers.ErrNotFound)
+}
+
+// joinAlternatives quotes names and joins them as 'a', 'b' or 'c', with "or"
+// in the language of messages
+func joinAlternatives(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " " + i18n.T("list.or") + " " + quoted[len(quoted)-1]
+}
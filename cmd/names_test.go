@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Keep tests from listing the containers of a real LXD server
+	containerNames = func() ([]string, error) { return nil, nil }
+	// Never wait for an answer to a confirmation
+	stdinIsTerminal = func() bool { return false }
+	os.Exit(m.Run())
+}
+
+// useContainerNames makes name matching see the given containers
+func useContainerNames(t *testing.T, names ...string) {
+	t.Helper()
+	previous, previousExact := containerNames, exactNames
+	t.Cleanup(func() { containerNames, exactNames = previous, previousExact })
+	containerNames = func() ([]string, error) { return names, nil }
+}
+
+func TestResolveContainerName(t *testing.T) {
+	useContainerNames(t, "web-api", "db", "worker-1", "worker_1")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"db", "db"},
+		{"webapi", "web-api"},
+		{"Web_API", "web-api"},
+		{"worker1", "worker1"},
+		{"missing", "missing"},
+	}
+	for _, tt := range tests {
+		if got := resolveContainerName(tt.name); got != tt.want {
+			t.Errorf("resolveContainerName(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+
+	exactNames = true
+	if got := resolveContainerName("webapi"); got != "webapi" {
+		t.Errorf("expected --exact to keep the name, got %q", got)
+	}
+}
+
+func TestResolveContainerNameListFailure(t *testing.T) {
+	useContainerNames(t)
+	containerNames = func() ([]string, error) { return nil, fmt.Errorf("lxc not found") }
+	if got := resolveContainerName("webapi"); got != "webapi" {
+		t.Errorf("expected the name unchanged, got %q", got)
+	}
+	if err := containerNotFound("webapi"); err.Error() != "container 'webapi' does not exist" {
+		t.Errorf("expected a plain error, got %v", err)
+	}
+}
+
+func TestContainerNotFound(t *testing.T) {
+	useContainerNames(t, "web-api", "web-app", "db")
+
+	if err := containerNotFound("webapi"); !strings.HasSuffix(err.Error(), "does not exist; did you mean 'web-api' or 'web-app'?") {
+		t.Errorf("expected suggestions, got %v", err)
+	}
+	if err := containerNotFound("dv"); !strings.HasSuffix(err.Error(), "did you mean 'db'?") {
+		t.Errorf("expected a single suggestion, got %v", err)
+	}
+	if err := containerNotFound("mysql"); err.Error() != "container 'mysql' does not exist" {
+		t.Errorf("expected no suggestions, got %v", err)
+	}
+
+	exactNames = true
+	if err := containerNotFound("webapi"); err.Error() != "container 'webapi' does not exist" {
+		t.Errorf("expected --exact to skip suggestions, got %v", err)
+	}
+}
+
+func TestContainerArgMatchesNames(t *testing.T) {
+	useContainerNames(t, "web-api")
+	if name, err := containerArg([]string{"WebAPI"}); err != nil || name != "web-api" {
+		t.Errorf("expected the matched container, got %q (%v)", name, err)
+	}
+}
+
+func TestJoinAlternatives(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"a"}, "'a'"},
+		{[]string{"a", "b"}, "'a' or 'b'"},
+		{[]string{"a", "b", "c"}, "'a', 'b' or 'c'"},
+	}
+	for _, tt := range tests {
+		if got := joinAlternatives(tt.names); got != tt.want {
+			t.Errorf("joinAlternatives(%v) = %q; want %q", tt.names, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	netTimeout        time.Duration
+	netPolicyPort     int
+	netPolicyProtocol string
+)
+
+// netCmd represents the net command
+var netCmd = &cobra.Command{
+	Use:   "net <policy>",
+	Short: "Manage container networking",
+	Long: `Manage container networking.
+
+Available subcommands:
+  policy - Allow or deny traffic between containers
+
+Examples:
+  lxc-go-cli net policy deny any db
+  lxc-go-cli net policy allow app db --port 5432`,
+}
+
+// netPolicyCmd represents the net policy subcommand
+var netPolicyCmd = &cobra.Command{
+	Use:   "policy <allow|deny|list|remove>",
+	Short: "Allow or deny traffic between containers",
+	Long: `Allow or deny traffic between containers on the same bridge.
+
+Rules are kept in a dedicated nftables table (bridge lxc_go_cli) and match
+containers by the MAC address of eth0. Allow rules always take precedence over
+deny rules, so a container can be isolated with a broad deny and then opened to
+specific peers. Use 'any' as the source or destination to match every container.
+
+These commands modify the host firewall and must be run as root, or with --sudo
+to run nft through sudo. Rules are not persisted across host reboots.
+
+Examples:
+  # Only the app container may reach the database, and only on 5432
+  lxc-go-cli net policy deny any db
+  lxc-go-cli net policy allow app db --port 5432
+
+  lxc-go-cli net policy list
+  lxc-go-cli net policy remove any db`,
+}
+
+// netPolicyAllowCmd represents the net policy allow subcommand
+var netPolicyAllowCmd = &cobra.Command{
+	Use:   "allow <src-container> <dst-container>",
+	Short: "Allow traffic from one container to another",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetPolicyCommand(func(ctx context.Context, manager NetPolicyManager) error {
+			return addNetPolicy(ctx, manager, newNetPolicyRule("allow", args))
+		})
+	},
+}
+
+// netPolicyDenyCmd represents the net policy deny subcommand
+var netPolicyDenyCmd = &cobra.Command{
+	Use:   "deny <src-container> <dst-container>",
+	Short: "Deny traffic from one container to another",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetPolicyCommand(func(ctx context.Context, manager NetPolicyManager) error {
+			return addNetPolicy(ctx, manager, newNetPolicyRule("deny", args))
+		})
+	},
+}
+
+// netPolicyListCmd represents the net policy list subcommand
+var netPolicyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List network policy rules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetPolicyCommand(func(ctx context.Context, manager NetPolicyManager) error {
+			return listNetPolicies(ctx, manager, cmd.OutOrStdout())
+		})
+	},
+}
+
+// netPolicyRemoveCmd represents the net policy remove subcommand
+var netPolicyRemoveCmd = &cobra.Command{
+	Use:   "remove <src-container> <dst-container>",
+	Short: "Remove network policy rules between two containers",
+	Long: `Remove network policy rules between two containers. Without --port every rule
+between the two containers is removed; with --port only rules for that port.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetPolicyCommand(func(ctx context.Context, manager NetPolicyManager) error {
+			return removeNetPolicy(ctx, manager, args[0], args[1], netPolicyPort)
+		})
+	},
+}
+
+// runNetPolicyCommand runs a policy operation with the default manager and timeout
+func runNetPolicyCommand(run func(ctx context.Context, manager NetPolicyManager) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), netTimeout)
+	defer cancel()
+	return run(ctx, &DefaultNetPolicyManager{})
+}
+
+// newNetPolicyRule builds a rule from command arguments and flags
+func newNetPolicyRule(action string, args []string) helpers.NetPolicyRule {
+	rule := helpers.NetPolicyRule{Action: action, Source: args[0], Destination: args[1], Port: netPolicyPort}
+	if netPolicyPort > 0 {
+		rule.Protocol = strings.ToLower(netPolicyProtocol)
+	}
+	return rule
+}
+
+// NetPolicyManager interface for dependency injection
+type NetPolicyManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	GetContainerMAC(ctx context.Context, containerName string) (string, error)
+	EnsurePolicyTable(ctx context.Context) error
+	AddPolicyRule(ctx context.Context, rule helpers.NetPolicyRule, sourceMAC, destinationMAC string) error
+	ListPolicyRules(ctx context.Context) ([]helpers.NetPolicyRule, error)
+	DeletePolicyRule(ctx context.Context, handle int) error
+}
+
+// DefaultNetPolicyManager implements NetPolicyManager using helpers
+type DefaultNetPolicyManager struct{}
+
+func (d *DefaultNetPolicyManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultNetPolicyManager) GetContainerMAC(ctx context.Context, containerName string) (string, error) {
+	return helpers.GetContainerMAC(containerName)
+}
+
+func (d *DefaultNetPolicyManager) EnsurePolicyTable(ctx context.Context) error {
+	return helpers.EnsurePolicyTable(ctx)
+}
+
+func (d *DefaultNetPolicyManager) AddPolicyRule(ctx context.Context, rule helpers.NetPolicyRule, sourceMAC, destinationMAC string) error {
+	return helpers.AddPolicyRule(ctx, rule, sourceMAC, destinationMAC)
+}
+
+func (d *DefaultNetPolicyManager) ListPolicyRules(ctx context.Context) ([]helpers.NetPolicyRule, error) {
+	return helpers.ListPolicyRules(ctx)
+}
+
+func (d *DefaultNetPolicyManager) DeletePolicyRule(ctx context.Context, handle int) error {
+	return helpers.DeletePolicyRule(ctx, handle)
+}
+
+// resolvePolicyMAC returns the MAC address to match for a container, empty for 'any'
+func resolvePolicyMAC(ctx context.Context, manager NetPolicyManager, containerName string) (string, error) {
+	if containerName == helpers.PolicyAnyContainer {
+		return "", nil
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return "", containerNotFound(containerName)
+	}
+	mac, err := manager.GetContainerMAC(ctx, containerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get MAC address of container '%s': %w", containerName, err)
+	}
+	return mac, nil
+}
+
+// addNetPolicy installs an allow or deny rule between two containers
+func addNetPolicy(ctx context.Context, manager NetPolicyManager, rule helpers.NetPolicyRule) error {
+	if err := helpers.ValidateNetPolicyRule(rule); err != nil {
+		return err
+	}
+
+	sourceMAC, err := resolvePolicyMAC(ctx, manager, rule.Source)
+	if err != nil {
+		return err
+	}
+	destinationMAC, err := resolvePolicyMAC(ctx, manager, rule.Destination)
+	if err != nil {
+		return err
+	}
+
+	existing, err := manager.ListPolicyRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, installed := range existing {
+		if installed.Matches(rule) {
+			logger.Info("Rule already present: %s", rule)
+			return nil
+		}
+	}
+
+	if err := manager.EnsurePolicyTable(ctx); err != nil {
+		return fmt.Errorf("failed to create network policy table: %w", err)
+	}
+	if err := manager.AddPolicyRule(ctx, rule, sourceMAC, destinationMAC); err != nil {
+		return fmt.Errorf("failed to add network policy rule: %w", err)
+	}
+
+	logger.Info("Added network policy rule: %s", rule)
+	return nil
+}
+
+// listNetPolicies prints the installed rules
+func listNetPolicies(ctx context.Context, manager NetPolicyManager, out io.Writer) error {
+	rules, err := manager.ListPolicyRules(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, helpers.FormatPolicyRules(rules))
+	return nil
+}
+
+// removeNetPolicy deletes rules between two containers, optionally limited to one port
+func removeNetPolicy(ctx context.Context, manager NetPolicyManager, source, destination string, port int) error {
+	rules, err := manager.ListPolicyRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, rule := range rules {
+		if rule.Source != source || rule.Destination != destination {
+			continue
+		}
+		if port > 0 && rule.Port != port {
+			continue
+		}
+		if err := manager.DeletePolicyRule(ctx, rule.Handle); err != nil {
+			return fmt.Errorf("failed to remove rule '%s': %w", rule, err)
+		}
+		logger.Info("Removed network policy rule: %s", rule)
+		removed++
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("no network policy rules found from '%s' to '%s'", source, destination)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(netCmd)
+	netCmd.AddCommand(netPolicyCmd)
+	netPolicyCmd.AddCommand(netPolicyAllowCmd, netPolicyDenyCmd, netPolicyListCmd, netPolicyRemoveCmd)
+
+	netPolicyCmd.PersistentFlags().DurationVarP(&netTimeout, "timeout", "t", 30*time.Second, "Timeout for network policy operations")
+	for _, cmd := range []*cobra.Command{netPolicyAllowCmd, netPolicyDenyCmd, netPolicyRemoveCmd} {
+		cmd.Flags().IntVar(&netPolicyPort, "port", 0, "Destination port to match (default: all traffic)")
+	}
+	for _, cmd := range []*cobra.Command{netPolicyAllowCmd, netPolicyDenyCmd} {
+		cmd.Flags().StringVar(&netPolicyProtocol, "protocol", "tcp", "Protocol for --port (tcp, udp)")
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockNetPolicyManager for testing net policy commands
+type MockNetPolicyManager struct {
+	ExistingContainers map[string]bool
+	MACs               map[string]string
+	Rules              []helpers.NetPolicyRule
+	AddError           error
+	Added              []helpers.NetPolicyRule
+	Deleted            []int
+	Calls              map[string]int
+}
+
+func (m *MockNetPolicyManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockNetPolicyManager) GetContainerMAC(ctx context.Context, containerName string) (string, error) {
+	mac, ok := m.MACs[containerName]
+	if !ok {
+		return "", fmt.Errorf("no MAC")
+	}
+	return mac, nil
+}
+
+func (m *MockNetPolicyManager) EnsurePolicyTable(ctx context.Context) error {
+	m.trackCall("EnsurePolicyTable")
+	return nil
+}
+
+func (m *MockNetPolicyManager) AddPolicyRule(ctx context.Context, rule helpers.NetPolicyRule, sourceMAC, destinationMAC string) error {
+	m.trackCall("AddPolicyRule")
+	if m.AddError != nil {
+		return m.AddError
+	}
+	m.Added = append(m.Added, rule)
+	return nil
+}
+
+func (m *MockNetPolicyManager) ListPolicyRules(ctx context.Context) ([]helpers.NetPolicyRule, error) {
+	return m.Rules, nil
+}
+
+func (m *MockNetPolicyManager) DeletePolicyRule(ctx context.Context, handle int) error {
+	m.trackCall("DeletePolicyRule")
+	m.Deleted = append(m.Deleted, handle)
+	return nil
+}
+
+func (m *MockNetPolicyManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func newMockNetPolicyManager() *MockNetPolicyManager {
+	return &MockNetPolicyManager{
+		ExistingContainers: map[string]bool{"app": true, "db": true, "nomac": true},
+		MACs:               map[string]string{"app": "00:16:3e:00:00:01", "db": "00:16:3e:00:00:02"},
+	}
+}
+
+func TestNetPolicyCommand(t *testing.T) {
+	subcommands := map[string]bool{}
+	for _, sub := range netPolicyCmd.Commands() {
+		subcommands[sub.Name()] = true
+	}
+	for _, name := range []string{"allow", "deny", "list", "remove"} {
+		if !subcommands[name] {
+			t.Errorf("net policy should have '%s' subcommand", name)
+		}
+	}
+	if netPolicyAllowCmd.Flags().Lookup("port") == nil {
+		t.Error("port flag should exist on allow")
+	}
+	if netPolicyDenyCmd.Flags().Lookup("protocol") == nil {
+		t.Error("protocol flag should exist on deny")
+	}
+}
+
+func TestAddNetPolicy(t *testing.T) {
+	allowApp := helpers.NetPolicyRule{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp"}
+
+	tests := []struct {
+		name          string
+		rule          helpers.NetPolicyRule
+		existing      []helpers.NetPolicyRule
+		addError      error
+		expectError   bool
+		errorContains string
+		expectAdded   bool
+	}{
+		{name: "allow with port", rule: allowApp, expectAdded: true},
+		{name: "deny from any", rule: helpers.NetPolicyRule{Action: "deny", Source: "any", Destination: "db"}, expectAdded: true},
+		{name: "already present", rule: allowApp, existing: []helpers.NetPolicyRule{allowApp}},
+		{
+			name:          "invalid rule",
+			rule:          helpers.NetPolicyRule{Action: "deny", Source: "db", Destination: "db"},
+			expectError:   true,
+			errorContains: "must differ",
+		},
+		{
+			name:          "missing container",
+			rule:          helpers.NetPolicyRule{Action: "deny", Source: "ghost", Destination: "db"},
+			expectError:   true,
+			errorContains: "container 'ghost' does not exist",
+		},
+		{
+			name:          "missing MAC",
+			rule:          helpers.NetPolicyRule{Action: "deny", Source: "nomac", Destination: "db"},
+			expectError:   true,
+			errorContains: "failed to get MAC address",
+		},
+		{
+			name:          "nft failure",
+			rule:          allowApp,
+			addError:      fmt.Errorf("permission denied"),
+			expectError:   true,
+			errorContains: "failed to add network policy rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newMockNetPolicyManager()
+			manager.Rules = tt.existing
+			manager.AddError = tt.addError
+
+			err := addNetPolicy(context.Background(), manager, tt.rule)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("expected error containing '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if added := len(manager.Added) == 1; added != tt.expectAdded {
+				t.Errorf("expected rule added=%v, got %v", tt.expectAdded, manager.Added)
+			}
+			if tt.expectAdded && manager.Calls["EnsurePolicyTable"] != 1 {
+				t.Error("expected policy table to be ensured before adding")
+			}
+		})
+	}
+}
+
+func TestRemoveNetPolicy(t *testing.T) {
+	rules := []helpers.NetPolicyRule{
+		{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp", Handle: 4},
+		{Action: "deny", Source: "app", Destination: "db", Handle: 5},
+		{Action: "deny", Source: "any", Destination: "db", Handle: 3},
+	}
+
+	manager := newMockNetPolicyManager()
+	manager.Rules = rules
+	if err := removeNetPolicy(context.Background(), manager, "app", "db", 5432); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 1 || manager.Deleted[0] != 4 {
+		t.Errorf("expected only handle 4 to be deleted, got %v", manager.Deleted)
+	}
+
+	manager = newMockNetPolicyManager()
+	manager.Rules = rules
+	if err := removeNetPolicy(context.Background(), manager, "app", "db", 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 2 {
+		t.Errorf("expected both app -> db rules to be deleted, got %v", manager.Deleted)
+	}
+
+	err := removeNetPolicy(context.Background(), manager, "db", "app", 0)
+	if err == nil || !strings.Contains(err.Error(), "no network policy rules found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestListNetPolicies(t *testing.T) {
+	manager := newMockNetPolicyManager()
+	manager.Rules = []helpers.NetPolicyRule{{Action: "deny", Source: "any", Destination: "db"}}
+
+	var out bytes.Buffer
+	if err := listNetPolicies(context.Background(), manager, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "deny") || !strings.Contains(out.String(), "db") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
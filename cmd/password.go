@@ -6,6 +6,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/deji/lxc-go-cli/internal/helpers"
@@ -15,6 +16,14 @@ import (
 
 var (
 	passwordTimeout time.Duration
+
+	generateLength           int
+	generateSymbols          bool
+	generateExcludeAmbiguous bool
+	generateWords            int
+	generateSeparator        string
+	generateWordlist         string
+	generateCount            int
 )
 
 // passwordCmd represents the password command
@@ -27,8 +36,19 @@ This command retrieves the password that was generated during container creation
 and stored in the container's metadata. The password is needed for sudo access
 within the container.
 
+With "password_store: {backend: vault}" in the config file, passwords are kept
+in a HashiCorp Vault kv-v2 engine instead, at <mount>/<path>/<container>
+(secret/lxc-go-cli/<container> by default), using VAULT_ADDR and VAULT_TOKEN.
+Passwords of containers created before the switch are still read from their
+metadata. 'lxc-go-cli doctor' checks that Vault is reachable.
+
+Generated passwords follow the policy under "password" in the config file
+(length, symbols, exclude_ambiguous, words, separator, wordlist); see
+'lxc-go-cli password generate' to try it out.
+
 Example:
-  lxc-go-cli password mycontainer`,
+  lxc-go-cli password mycontainer
+  lxc-go-cli password generate --length 24 --symbols`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		containerName := args[0]
@@ -42,6 +62,28 @@ Example:
 	},
 }
 
+// passwordGenerateCmd represents the password generate subcommand
+var passwordGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a password with the configured or given policy",
+	Long: `Print a newly generated password, without touching any container.
+
+The policy comes from the "password" section of the config file; flags
+override it. --words switches to a diceware-style passphrase drawn from the
+built-in wordlist or --wordlist.
+
+Examples:
+  lxc-go-cli password generate
+  lxc-go-cli password generate --length 24 --symbols --exclude-ambiguous
+  lxc-go-cli password generate --words 6 --separator .
+  DB_PASSWORD=$(lxc-go-cli password generate)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy := passwordPolicyFromFlags(cmd, helpers.ActivePasswordPolicy())
+		return generatePasswords(policy, generateCount, cmd.OutOrStdout())
+	},
+}
+
 // PasswordManager interface for dependency injection
 type PasswordManager interface {
 	ContainerExists(ctx context.Context, name string) bool
@@ -68,7 +110,7 @@ func retrievePassword(ctx context.Context, manager PasswordManager, containerNam
 
 	// Check if container exists
 	if !manager.ContainerExists(ctx, containerName) {
-		return fmt.Errorf("container '%s' does not exist", containerName)
+		return containerNotFound(containerName)
 	}
 
 	logger.Debug("Retrieving password for container '%s'", containerName)
@@ -84,8 +126,83 @@ func retrievePassword(ctx context.Context, manager PasswordManager, containerNam
 	return nil
 }
 
+// passwordPolicyFromFlags applies the flags that were set on top of policy
+func passwordPolicyFromFlags(cmd *cobra.Command, policy helpers.PasswordPolicy) helpers.PasswordPolicy {
+	flags := cmd.Flags()
+	if flags.Changed("length") {
+		policy.Length = generateLength
+		// An explicit length asks for a character password
+		if !flags.Changed("words") {
+			policy.Words = 0
+		}
+	}
+	if flags.Changed("symbols") {
+		policy.Symbols = generateSymbols
+	}
+	if flags.Changed("exclude-ambiguous") {
+		policy.ExcludeAmbiguous = generateExcludeAmbiguous
+	}
+	if flags.Changed("words") {
+		policy.Words = generateWords
+	}
+	if flags.Changed("separator") {
+		policy.Separator = generateSeparator
+	}
+	if flags.Changed("wordlist") {
+		policy.Wordlist = generateWordlist
+	}
+	return policy
+}
+
+// generatePasswords prints count passwords, one per line
+func generatePasswords(policy helpers.PasswordPolicy, count int, out io.Writer) error {
+	if count < 1 {
+		return helpers.UsageErrorf("count must be at least 1")
+	}
+	for i := 0; i < count; i++ {
+		password, bits, err := helpers.GeneratePassword(policy)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			logger.Debug("Generating %s (about %.0f bits of entropy)", policy.Describe(), bits)
+		}
+		fmt.Fprintln(out, password)
+	}
+	return nil
+}
+
+// applyPasswordPolicy makes generated passwords follow the config file's
+// policy and stores them in its password store
+func applyPasswordPolicy(cmd *cobra.Command) error {
+	if !usesSettings(cmd) {
+		return nil
+	}
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if err := helpers.SetPasswordPolicy(settings.Password); err != nil {
+		return fmt.Errorf("invalid password policy in %s: %v", helpers.SettingsPath(), err)
+	}
+	if err := helpers.SetPasswordStore(settings.PasswordStore); err != nil {
+		return fmt.Errorf("invalid password store in %s: %v", helpers.SettingsPath(), err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(passwordCmd)
+	passwordCmd.AddCommand(passwordGenerateCmd)
+	skipLXCCheck(passwordGenerateCmd)
+
+	passwordGenerateCmd.Flags().IntVar(&generateLength, "length", helpers.DefaultPasswordLength, "Number of characters")
+	passwordGenerateCmd.Flags().BoolVar(&generateSymbols, "symbols", false, "Include symbols")
+	passwordGenerateCmd.Flags().BoolVar(&generateExcludeAmbiguous, "exclude-ambiguous", false, "Leave out easily confused characters such as 0/O and 1/l/I")
+	passwordGenerateCmd.Flags().IntVar(&generateWords, "words", 0, "Generate a passphrase of this many words instead")
+	passwordGenerateCmd.Flags().StringVar(&generateSeparator, "separator", "-", "Separator between passphrase words")
+	passwordGenerateCmd.Flags().StringVar(&generateWordlist, "wordlist", "", "Wordlist file for passphrases (default: built-in list)")
+	passwordGenerateCmd.Flags().IntVarP(&generateCount, "count", "c", 1, "Number of passwords to generate")
 
 	// Add timeout flag
 	passwordCmd.Flags().DurationVarP(&passwordTimeout, "timeout", "t", 10*time.Second, "Timeout for password retrieval operation")
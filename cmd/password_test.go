@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // MockPasswordManager for testing password command
@@ -332,3 +339,98 @@ func TestPasswordValidation(t *testing.T) {
 	}
 }
 
+func TestGeneratePasswords(t *testing.T) {
+	var out bytes.Buffer
+	if err := generatePasswords(helpers.PasswordPolicy{Length: 20}, 3, &out); err != nil {
+		t.Fatalf("generatePasswords failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 passwords, got %q", out.String())
+	}
+	for _, line := range lines {
+		if len(line) != 20 {
+			t.Errorf("expected 20 characters, got %q", line)
+		}
+	}
+
+	if err := generatePasswords(helpers.PasswordPolicy{}, 0, &out); err == nil {
+		t.Error("expected error for zero count")
+	}
+	if err := generatePasswords(helpers.PasswordPolicy{Length: 3}, 1, &out); err == nil {
+		t.Error("expected error for a too short password")
+	}
+}
+
+func TestPasswordPolicyFromFlags(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().AddFlagSet(passwordGenerateCmd.Flags())
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			passwordGenerateCmd.Flags().VisitAll(func(f *pflag.Flag) {
+				f.Value.Set(f.DefValue)
+				f.Changed = false
+			})
+		})
+		return cmd
+	}
+	config := helpers.PasswordPolicy{Words: 6, Separator: "."}
+
+	// Unset flags keep the configured policy
+	if policy := passwordPolicyFromFlags(newCmd(), config); policy != config {
+		t.Errorf("expected configured policy, got %+v", policy)
+	}
+
+	// An explicit length switches to a character password
+	policy := passwordPolicyFromFlags(newCmd("--length", "30", "--symbols"), config)
+	if policy.Words != 0 || policy.Length != 30 || !policy.Symbols {
+		t.Errorf("expected a 30 character password with symbols, got %+v", policy)
+	}
+
+	policy = passwordPolicyFromFlags(newCmd("--words", "8"), helpers.PasswordPolicy{Symbols: true})
+	if policy.Words != 8 || !policy.Symbols {
+		t.Errorf("expected 8 words on top of config, got %+v", policy)
+	}
+}
+
+func TestApplyPasswordPolicy(t *testing.T) {
+	originalDir := helpers.SettingsDir
+	helpers.SettingsDir = t.TempDir()
+	t.Cleanup(func() {
+		helpers.SettingsDir = originalDir
+		helpers.SetPasswordPolicy(helpers.PasswordPolicy{})
+	})
+
+	os.WriteFile(helpers.SettingsPath(), []byte("password:\n  length: 30\n"), 0644)
+	if err := applyPasswordPolicy(createCmd); err != nil {
+		t.Fatalf("applyPasswordPolicy failed: %v", err)
+	}
+	if helpers.ActivePasswordPolicy().Length != 30 {
+		t.Errorf("expected configured length, got %+v", helpers.ActivePasswordPolicy())
+	}
+
+	os.WriteFile(helpers.SettingsPath(), []byte("password:\n  length: 2\n"), 0644)
+	if err := applyPasswordPolicy(createCmd); err == nil || !strings.Contains(err.Error(), "invalid password policy") {
+		t.Errorf("expected invalid policy error, got %v", err)
+	}
+	os.WriteFile(helpers.SettingsPath(), []byte("password_store:\n  backend: vault\n  vault:\n    mount: kv\n"), 0644)
+	if err := applyPasswordPolicy(createCmd); err != nil {
+		t.Fatalf("applyPasswordPolicy failed: %v", err)
+	}
+	t.Cleanup(func() { helpers.SetPasswordStore(helpers.PasswordStoreSettings{}) })
+	if store, ok := helpers.ActivePasswordStore().(*helpers.VaultPasswordStore); !ok || store.SecretPath("web") != "kv/lxc-go-cli/web" {
+		t.Errorf("expected the vault store, got %#v", helpers.ActivePasswordStore())
+	}
+
+	os.WriteFile(helpers.SettingsPath(), []byte("password_store:\n  backend: keychain\n"), 0644)
+	if err := applyPasswordPolicy(createCmd); err == nil || !strings.Contains(err.Error(), "invalid password store") {
+		t.Errorf("expected invalid store error, got %v", err)
+	}
+	// Commands that never generate passwords ignore the config
+	if err := applyPasswordPolicy(versionCmd); err != nil {
+		t.Errorf("version should not read the password policy: %v", err)
+	}
+}
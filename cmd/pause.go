@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:   "pause <container-name>",
+	Short: "Freeze a running container",
+	Long: `Freeze all processes of a running container with lxc pause.
+
+A frozen container keeps its memory but uses no CPU, which is useful for
+shelving resource-hungry dev environments without losing their state.
+Forwarded ports stop responding until the container is resumed.
+
+Examples:
+  lxc-go-cli pause mycontainer
+  lxc-go-cli resume mycontainer`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pauseContainer(&DefaultPauseManager{}, args[0])
+	},
+}
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume <container-name>",
+	Short: "Unfreeze a paused container",
+	Long: `Unfreeze a container previously frozen with 'lxc-go-cli pause'.
+
+To continue an interrupted create instead, use 'lxc-go-cli create --resume'.
+
+Examples:
+  lxc-go-cli resume mycontainer`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return resumeContainer(&DefaultPauseManager{}, args[0])
+	},
+}
+
+// PauseManager interface for dependency injection
+type PauseManager interface {
+	ContainerExists(name string) bool
+	GetContainerStatus(name string) (string, error)
+	FreezeContainer(name string) error
+	UnfreezeContainer(name string) error
+}
+
+// DefaultPauseManager implements PauseManager using helpers
+type DefaultPauseManager struct{}
+
+func (d *DefaultPauseManager) ContainerExists(name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultPauseManager) GetContainerStatus(name string) (string, error) {
+	return helpers.GetContainerStatus(name)
+}
+
+func (d *DefaultPauseManager) FreezeContainer(name string) error {
+	return helpers.FreezeContainer(name)
+}
+
+func (d *DefaultPauseManager) UnfreezeContainer(name string) error {
+	return helpers.UnfreezeContainer(name)
+}
+
+// containerStatus checks the container exists and returns its status
+func containerStatus(manager PauseManager, name string) (string, error) {
+	if name == "" {
+		return "", helpers.WithKind(i18n.Errorf("container.name_required"), helpers.ErrUsage)
+	}
+	if !manager.ContainerExists(name) {
+		return "", containerNotFound(name)
+	}
+	return manager.GetContainerStatus(name)
+}
+
+// pauseContainer freezes a running container
+func pauseContainer(manager PauseManager, name string) error {
+	status, err := containerStatus(manager, name)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case helpers.StatusFrozen:
+		logger.Info("%s", i18n.T("pause.already_paused", name))
+		return nil
+	case "Running":
+	default:
+		return helpers.WithKind(i18n.Errorf("pause.not_running", name, status), helpers.ErrConflict)
+	}
+
+	if err := manager.FreezeContainer(name); err != nil {
+		return i18n.Errorf("pause.failed", err)
+	}
+	logger.Info("%s", i18n.T("pause.paused", name, name))
+	return nil
+}
+
+// resumeContainer unfreezes a paused container
+func resumeContainer(manager PauseManager, name string) error {
+	status, err := containerStatus(manager, name)
+	if err != nil {
+		return err
+	}
+
+	if status != helpers.StatusFrozen {
+		return helpers.WithKind(i18n.Errorf("resume.not_paused", name, status), helpers.ErrConflict)
+	}
+
+	if err := manager.UnfreezeContainer(name); err != nil {
+		return i18n.Errorf("resume.failed", err)
+	}
+	logger.Info("%s", i18n.T("resume.resumed", name))
+	return nil
+}
+
+// frozenNotice is shown by commands whose output is misleading for a paused container
+func frozenNotice(name string) string {
+	return fmt.Sprintf("Container '%s' is paused; its processes and forwarded ports will not respond until 'lxc-go-cli resume %s'", name, name)
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockPauseManager for testing pause and resume commands
+type MockPauseManager struct {
+	Statuses  map[string]string
+	FreezeErr error
+	Calls     map[string]int
+}
+
+func (m *MockPauseManager) ContainerExists(name string) bool {
+	_, ok := m.Statuses[name]
+	return ok
+}
+
+func (m *MockPauseManager) GetContainerStatus(name string) (string, error) {
+	return m.Statuses[name], nil
+}
+
+func (m *MockPauseManager) FreezeContainer(name string) error {
+	m.trackCall("FreezeContainer")
+	if m.FreezeErr != nil {
+		return m.FreezeErr
+	}
+	m.Statuses[name] = helpers.StatusFrozen
+	return nil
+}
+
+func (m *MockPauseManager) UnfreezeContainer(name string) error {
+	m.trackCall("UnfreezeContainer")
+	m.Statuses[name] = "Running"
+	return nil
+}
+
+func (m *MockPauseManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func TestPauseAndResumeContainer(t *testing.T) {
+	manager := &MockPauseManager{Statuses: map[string]string{"web": "Running"}}
+
+	if err := pauseContainer(manager, "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Statuses["web"] != helpers.StatusFrozen {
+		t.Error("container should be frozen")
+	}
+
+	// Pausing again is a no-op
+	if err := pauseContainer(manager, "web"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if manager.Calls["FreezeContainer"] != 1 {
+		t.Errorf("expected 1 freeze, got %d", manager.Calls["FreezeContainer"])
+	}
+
+	if err := resumeContainer(manager, "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Statuses["web"] != "Running" {
+		t.Error("container should be running again")
+	}
+}
+
+func TestPauseContainerErrors(t *testing.T) {
+	manager := &MockPauseManager{Statuses: map[string]string{"stopped": "Stopped", "web": "Running"}}
+
+	tests := []struct {
+		name          string
+		run           func() error
+		expectedError string
+		exitCode      int
+	}{
+		{"empty name", func() error { return pauseContainer(manager, "") }, "container name is required", ExitUsage},
+		{"missing container", func() error { return pauseContainer(manager, "missing") }, "does not exist", ExitNotFound},
+		{"stopped container", func() error { return pauseContainer(manager, "stopped") }, "only running containers can be paused", ExitConflict},
+		{"resume running container", func() error { return resumeContainer(manager, "web") }, "not paused", ExitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+			if got := exitCode(err); got != tt.exitCode {
+				t.Errorf("expected exit code %d, got %d", tt.exitCode, got)
+			}
+		})
+	}
+
+	manager.FreezeErr = fmt.Errorf("lxc pause failed")
+	if err := pauseContainer(manager, "web"); err == nil || !strings.Contains(err.Error(), "failed to pause container") {
+		t.Errorf("expected freeze failure, got %v", err)
+	}
+}
+
+func TestShowContainerInfo(t *testing.T) {
+	manager := &MockListManager{States: []helpers.ContainerState{
+		{
+			Name:        "web",
+			Status:      helpers.StatusFrozen,
+			IPv4:        "10.0.0.2",
+			MemoryUsage: 512 * 1024 * 1024,
+			Config:      map[string]string{helpers.ManagedMarkerKey: "true", helpers.ManagedVersionKey: "1.abc123"},
+			Devices: map[string]map[string]string{
+				"web-8080-80-tcp": {"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:0.0.0.0:80"},
+				"root":            {"type": "disk", "path": "/"},
+			},
+		},
+	}}
+
+	var out bytes.Buffer
+	if err := showContainerInfo(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output := out.String()
+	for _, expected := range []string{"Frozen (paused)", "10.0.0.2", "512.0MiB", "1.abc123", "web-8080-80-tcp", "lxc-go-cli resume web"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, output)
+		}
+	}
+
+	if err := showContainerInfo(context.Background(), manager, "missing", &out); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
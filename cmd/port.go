@@ -6,37 +6,66 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"net"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/deji/lxc-go-cli/internal/helpers"
 	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
 
 var (
-	portTimeout time.Duration
-	forcePort   bool
+	portTimeout      time.Duration
+	forcePort        bool
+	reversePort      bool
+	portApplyFile    string
+	portImportFile   string
+	portValidateFile string
+	portDryRun       bool
+	portListTable    tableFlags
+	portListCheck    bool
+	portStatsTable   tableFlags
+	portInterface    string
 )
 
+// portProbeTimeout bounds each reachability probe of port list --check
+const portProbeTimeout = 2 * time.Second
+
 // portCmd represents the port command
 var portCmd = &cobra.Command{
-	Use:   "port <add|list>",
+	Use:   "port <add|list|apply|export|import|validate|schema|check|stats>",
 	Short: "Manage port forwarding for LXC containers",
 	Long: `Manage port forwarding between host and container using LXC proxy devices.
 
 Available subcommands:
-  add   - Add port forwarding rule
-  list  - List existing port forwarding rules
+  add      - Add port forwarding rule
+  list     - List existing port forwarding rules
+  apply    - Reconcile port forwarding rules with a YAML file
+  export   - Print port forwarding rules and devices as a YAML file
+  import   - Add the port forwarding rules and devices of a YAML file
+  validate - Check a YAML file without touching any container
+  schema   - Print the JSON schema of the YAML file
+  check    - Report whether a host port is free and who uses it
+  stats    - Show connections and bytes through each forwarded port
 
 Examples:
   lxc-go-cli port add mycontainer 8080 80        # Add TCP port forwarding
   lxc-go-cli port add mycontainer 5432 5432 udp  # Add UDP port forwarding
-  lxc-go-cli port list mycontainer               # List all port mappings`,
+  lxc-go-cli port list mycontainer               # List all port mappings
+  lxc-go-cli port apply mycontainer -f ports.yaml # Declare all mappings at once
+  lxc-go-cli port export mycontainer > ports.yaml # Save mappings for git
+  lxc-go-cli port validate -f ports.yaml         # Lint the file, e.g. in CI
+  lxc-go-cli port check 8080                     # Find out what holds port 8080
+  lxc-go-cli port stats mycontainer              # Find the busiest forwarded port`,
 }
 
 // portAddCmd represents the port add subcommand
@@ -48,18 +77,40 @@ This command creates a proxy device that forwards traffic from the host port
 to the container port using the specified protocol.
 
 The protocol parameter is optional and defaults to 'tcp'.
-When 'both' is specified, both TCP and UDP forwarding rules are created.
+When 'both' is specified, both TCP and UDP forwarding rules are created; if
+one fails the other is removed again unless --keep-partial is given.
+
+With --reverse the direction is turned around and the ports are given as
+<container-port> <host-port>: the proxy listens on 127.0.0.1 inside the
+container and forwards to 127.0.0.1 on the host, so an app in the container
+can reach a service that only listens on the host, such as a database.
+
+With --interface the proxy listens only on the addresses of that host
+network interface instead of on all addresses, e.g. on the public side of a
+multi-homed host. An interface with several addresses gets one device per
+address, named <container>-<host-port>-<container-port>-<protocol>-<address>.
+The addresses are read when the rule is added: add it again after they
+change.
+
+Virtual machines only support proxies in NAT mode, which forward to a fixed
+address: the VM's current address is pinned on its eth0 device first.
+--reverse is not available for VMs.
 
 Examples:
   lxc-go-cli port add mycontainer 8080 80        # defaults to tcp
   lxc-go-cli port add mycontainer 8080 80 tcp    # explicit tcp
   lxc-go-cli port add mycontainer 5432 5432 udp  # udp only
-  lxc-go-cli port add mycontainer 3000 3000 both # both tcp and udp`,
+  lxc-go-cli port add mycontainer 3000 3000 both # both tcp and udp
+  lxc-go-cli port add mycontainer 443 443 --interface eth1 # only on eth1's addresses
+  lxc-go-cli port add --reverse mycontainer 5432 5432 # host Postgres on localhost:5432 in the container`,
 	Args: cobra.RangeArgs(3, 4),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		containerName := args[0]
 		hostPort := args[1]
 		containerPort := args[2]
+		if reversePort {
+			hostPort, containerPort = args[2], args[1]
+		}
 
 		// Protocol is optional, defaults to "tcp"
 		protocol := "tcp"
@@ -72,30 +123,286 @@ Examples:
 		defer cancel()
 
 		manager := &DefaultContainerPortManager{}
+		if reversePort {
+			if portInterface != "" {
+				return helpers.UsageErrorf("--interface cannot be used with --reverse, which listens inside the container")
+			}
+			return configureReversePortForwarding(ctx, manager, containerName, containerPort, hostPort, protocol)
+		}
+		if portInterface != "" {
+			// The interface and its addresses are those of this machine
+			if err := helpers.RequireLocalServer(ctx, "port add --interface"); err != nil {
+				return err
+			}
+			return configureInterfacePortForwarding(ctx, manager, containerName, hostPort, containerPort, protocol, portInterface, forcePort)
+		}
 		return configurePortForwarding(ctx, manager, containerName, hostPort, containerPort, protocol, forcePort)
 	},
 }
 
 // portListCmd represents the port list subcommand
 var portListCmd = &cobra.Command{
-	Use:   "list <container-name>",
+	Use:   "list [container-name]",
 	Short: "List port forwarding rules for an LXC container",
 	Long: `List all existing port forwarding rules for the specified container.
 This command shows all proxy devices configured for port forwarding,
 displaying the protocol, host port, container port, and device name.
+Without a name the current container chosen with 'use' is listed.
+
+With --check two live columns are added: LISTENING says whether the host
+port is bound, and REACHABLE whether a TCP connection through it reaches a
+service in the container. The checks take a moment, so they are opt-in; UDP
+and reverse mappings show '-'.
+
+Pick and order columns with --columns (e.g. host-port,container-port) and
+leave out the headings with --no-header.
 
 Examples:
-  lxc-go-cli port list mycontainer  # List all port mappings for mycontainer`,
-	Args: cobra.ExactArgs(1),
+  lxc-go-cli port list mycontainer  # List all port mappings for mycontainer
+  lxc-go-cli port list mycontainer --check
+  lxc-go-cli port list mycontainer --columns host-port,container-port --no-header`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		containerName := args[0]
+		containerName, err := containerArg(args)
+		if err != nil {
+			return err
+		}
 
 		// Create context with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
 		defer cancel()
 
 		manager := &DefaultContainerPortManager{}
-		return listPortForwarding(ctx, manager, containerName)
+		var prober PortProbeManager
+		if portListCheck {
+			prober = &DefaultPortProbeManager{}
+		}
+		return listPortForwarding(ctx, manager, containerName, portListTable.options(cmd.OutOrStdout()), prober)
+	},
+}
+
+// portApplyCmd represents the port apply subcommand
+var portApplyCmd = &cobra.Command{
+	Use:   "apply <container-name> -f <file>",
+	Short: "Reconcile port forwarding rules with a YAML file",
+	Long: `Make a container's port forwarding match a YAML file: missing rules are
+added, rules this tool created that are not in the file are removed, and rules
+whose listen address changed are replaced. The differences are printed.
+
+Only rules created by 'port add' or 'port apply' are managed; devices added by
+other tools and rules registered by 'adopt' are left alone.
+
+Devices listed under 'devices' are added when missing and their settings
+updated when changed; devices are never removed.
+
+File format:
+  ports:
+    - host: 8080          # host port
+      container: 80       # container port
+      protocol: tcp       # tcp, udp or both (default tcp)
+      listen: 127.0.0.1   # host address to listen on (default 0.0.0.0)
+  devices:                # other devices, by name (optional)
+    data:
+      type: disk          # LXD device type (required)
+      source: /srv/data   # any other LXD device settings
+      path: /data
+
+Examples:
+  lxc-go-cli port apply mycontainer -f ports.yaml
+  lxc-go-cli port apply mycontainer -f ports.yaml --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := loadPortSpec(portApplyFile, cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
+		defer cancel()
+
+		manager := &DefaultContainerPortManager{}
+		return applyPortSpec(ctx, manager, args[0], spec, portApplyOptions{DryRun: portDryRun, Force: forcePort}, cmd.OutOrStdout())
+	},
+}
+
+// portExportCmd represents the port export subcommand
+var portExportCmd = &cobra.Command{
+	Use:   "export <container-name>",
+	Short: "Print port forwarding rules and devices as a YAML file",
+	Long: `Print the port forwarding rules this tool created on a container in the
+format read by 'port import' and 'port apply', so the setup can be kept in git
+and replayed onto a rebuilt container.
+
+TCP and UDP rules for the same ports are merged into 'protocol: both'. The
+container's other devices, such as disks, GPUs, USB devices and proxy devices
+created by other tools, are listed under 'devices' with their LXD settings.
+Reverse mappings and devices inherited from profiles are not exported.
+
+Examples:
+  lxc-go-cli port export mycontainer > ports.yaml
+  lxc-go-cli port export old-web | lxc-go-cli port import new-web -f -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
+		defer cancel()
+
+		return exportPortSpec(ctx, &DefaultContainerPortManager{}, args[0], cmd.OutOrStdout())
+	},
+}
+
+// portImportCmd represents the port import subcommand
+var portImportCmd = &cobra.Command{
+	Use:   "import <container-name> -f <file>",
+	Short: "Add the port forwarding rules and devices of a YAML file",
+	Long: `Add the port forwarding rules of a YAML file, as written by 'port export',
+to a container. Rules already present are kept, rules whose listen address
+changed are replaced, and rules that are not in the file are left alone; use
+'port apply' to also remove those. Use '-f -' to read the file from stdin.
+
+Devices in the file are added when missing and their settings updated when
+changed. A device cannot change its type, and no device is removed.
+
+Examples:
+  lxc-go-cli port import mycontainer -f ports.yaml
+  lxc-go-cli port import mycontainer -f ports.yaml --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := loadPortSpec(portImportFile, cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
+		defer cancel()
+
+		manager := &DefaultContainerPortManager{}
+		return applyPortSpec(ctx, manager, args[0], spec, portApplyOptions{DryRun: portDryRun, Force: forcePort, KeepUnlisted: true}, cmd.OutOrStdout())
+	},
+}
+
+// portValidateCmd represents the port validate subcommand
+var portValidateCmd = &cobra.Command{
+	Use:   "validate -f <file>",
+	Short: "Check a port forwarding YAML file without touching any container",
+	Long: `Check a file for 'port apply' and 'port import' and report every problem
+with its line and column, so CI can lint the file before it is applied. No
+container or LXD server is needed. Use '-f -' to read the file from stdin.
+
+The command exits with status 1 when the file has problems.
+
+Examples:
+  lxc-go-cli port validate -f ports.yaml
+  lxc-go-cli port schema > ports.schema.json   # for editors and other linters`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := portValidateFile
+		var data []byte
+		var err error
+		if name == "-" {
+			name = "<stdin>"
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read ports file: %w", err)
+		}
+		// Problems are reported in the output, not with usage help
+		cmd.SilenceUsage = true
+		return validatePortSpecFile(name, data, cmd.OutOrStdout())
+	},
+}
+
+// portSchemaCmd represents the port schema subcommand
+var portSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON schema of the port forwarding YAML file",
+	Long: `Print a JSON schema, generated from the file format itself, describing the
+file read by 'port apply', 'port import' and 'port validate'. Editors with YAML
+language support can use it for completion and inline errors, e.g. with a
+comment at the top of the file:
+
+  # yaml-language-server: $schema=ports.schema.json
+
+Examples:
+  lxc-go-cli port schema > ports.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := helpers.JSONSchema(PortSpec{}, "lxc-go-cli port forwarding")
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(schema)
+		return err
+	},
+}
+
+// portCheckCmd represents the port check subcommand
+var portCheckCmd = &cobra.Command{
+	Use:   "check <host-port> [tcp|udp|both]",
+	Short: "Report whether a host port is free",
+	Long: `Report whether a host port is free, which process listens on it and which
+container's proxy device claims it. Proxy devices of stopped containers claim
+their port too: the port is taken again when the container starts.
+
+Process names of other users are only shown when run as root.
+The command exits with status 1 when the port is in use or claimed.
+
+Examples:
+  lxc-go-cli port check 8080       # defaults to tcp
+  lxc-go-cli port check 5353 udp
+  lxc-go-cli port check 3000 both`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		protocol := "tcp"
+		if len(args) > 1 {
+			protocol = args[1]
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
+		defer cancel()
+
+		// A busy port is reported through the exit code, not usage help
+		cmd.SilenceUsage = true
+		manager := &DefaultPortCheckManager{}
+		return checkPort(ctx, manager, args[0], protocol, cmd.OutOrStdout())
+	},
+}
+
+// portStatsCmd represents the port stats subcommand
+var portStatsCmd = &cobra.Command{
+	Use:   "stats [container-name]",
+	Short: "Show the traffic through a container's forwarded ports",
+	Long: `Show the open connections and the bytes received from and sent to clients
+through each forwarded host port of a container, busiest first, to find the
+service that saturates the host's network.
+
+TCP ports are read from the sockets of LXD's proxy process with ss. UDP ports
+and NAT proxy devices (used for VMs) are read from conntrack, which needs root
+and the conntrack package; byte counts also need conntrack accounting
+(` + helpers.ConntrackAccountingHint + `). The numbers cover the connections
+open now, not all traffic since the port was forwarded. Reverse mappings are
+not shown. The counters are read on this machine, so the LXD server must be
+local.
+
+Examples:
+  lxc-go-cli port stats mycontainer
+  sudo lxc-go-cli port stats myvm
+  lxc-go-cli port stats mycontainer --columns host-port,connections,bytes-out`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containerName, err := containerArg(args)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), portTimeout)
+		defer cancel()
+
+		if err := helpers.RequireLocalServer(ctx, "port stats"); err != nil {
+			return err
+		}
+		return showPortStats(ctx, &DefaultPortStatsManager{}, containerName, portStatsTable.options(cmd.OutOrStdout()), cmd.OutOrStdout())
 	},
 }
 
@@ -104,6 +411,10 @@ type ContainerPortManager interface {
 	ContainerExists(ctx context.Context, name string) bool
 	RunLXCCommand(ctx context.Context, args ...string) error
 	GetContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+	GetContainerStatus(ctx context.Context, name string) (string, error)
+	GetInstanceType(ctx context.Context, name string) (string, error)
+	PinInstanceAddress(ctx context.Context, name string) (string, error)
+	InterfaceAddresses(name string) ([]string, error)
 }
 
 // DefaultContainerPortManager implements ContainerPortManager using helpers
@@ -124,19 +435,42 @@ func (d *DefaultContainerPortManager) RunLXCCommand(ctx context.Context, args ..
 }
 
 func (d *DefaultContainerPortManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
-	if containerName == "" {
-		return nil, fmt.Errorf("container name is required")
-	}
+	return helpers.GetContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultContainerPortManager) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	return helpers.GetContainerStatus(name)
+}
+
+func (d *DefaultContainerPortManager) GetInstanceType(ctx context.Context, name string) (string, error) {
+	return helpers.GetInstanceType(ctx, name)
+}
+
+func (d *DefaultContainerPortManager) PinInstanceAddress(ctx context.Context, name string) (string, error) {
+	return helpers.PinInstanceAddress(ctx, name)
+}
+
+func (d *DefaultContainerPortManager) InterfaceAddresses(name string) ([]string, error) {
+	return helpers.InterfaceAddresses(name)
+}
 
-	// Execute lxc config show command using exec.CommandContext
-	cmd := exec.CommandContext(ctx, "lxc", "config", "show", containerName)
-	output, err := cmd.CombinedOutput()
+// proxyConnectSettings returns the proxy device settings that connect to
+// containerPort in the instance. A container is reached by the proxy process
+// inside it; a VM only supports NAT-mode proxies to its pinned address.
+func proxyConnectSettings(ctx context.Context, manager ContainerPortManager, containerName, protocol, containerPort string) ([]string, error) {
+	instanceType, err := manager.GetInstanceType(ctx, containerName)
 	if err != nil {
-		logger.Debug("Failed to get container config: %s", string(output))
-		return nil, fmt.Errorf("failed to get container config: %w (output: %s)", err, string(output))
+		logger.Debug("Could not get the type of '%s', assuming a container: %v", containerName, err)
+	}
+	if instanceType != helpers.InstanceTypeVM {
+		return []string{fmt.Sprintf("connect=%s:0.0.0.0:%s", protocol, containerPort)}, nil
 	}
 
-	return output, nil
+	address, err := manager.PinInstanceAddress(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"nat=true", fmt.Sprintf("connect=%s:%s:%s", protocol, address, containerPort)}, nil
 }
 
 // validatePortForwardingArgs validates the arguments for port forwarding
@@ -151,10 +485,10 @@ func validatePortForwardingArgs(containerName, hostPort, containerPort, protocol
 	}
 	hostPortNum, err := strconv.Atoi(hostPort)
 	if err != nil {
-		return fmt.Errorf("invalid host port '%s': must be a number", hostPort)
+		return helpers.UsageErrorf("invalid host port '%s': must be a number", hostPort)
 	}
 	if hostPortNum < 1 || hostPortNum > 65535 {
-		return fmt.Errorf("invalid host port '%s': must be between 1 and 65535", hostPort)
+		return helpers.UsageErrorf("invalid host port '%s': must be between 1 and 65535", hostPort)
 	}
 
 	// Validate container port
@@ -163,10 +497,10 @@ func validatePortForwardingArgs(containerName, hostPort, containerPort, protocol
 	}
 	containerPortNum, err := strconv.Atoi(containerPort)
 	if err != nil {
-		return fmt.Errorf("invalid container port '%s': must be a number", containerPort)
+		return helpers.UsageErrorf("invalid container port '%s': must be a number", containerPort)
 	}
 	if containerPortNum < 1 || containerPortNum > 65535 {
-		return fmt.Errorf("invalid container port '%s': must be between 1 and 65535", containerPort)
+		return helpers.UsageErrorf("invalid container port '%s': must be between 1 and 65535", containerPort)
 	}
 
 	// Validate protocol - empty defaults to tcp
@@ -175,7 +509,7 @@ func validatePortForwardingArgs(containerName, hostPort, containerPort, protocol
 	}
 	protocol = strings.ToLower(protocol)
 	if protocol != "tcp" && protocol != "udp" && protocol != "both" {
-		return fmt.Errorf("invalid protocol '%s': must be 'tcp', 'udp', or 'both'", protocol)
+		return helpers.UsageErrorf("invalid protocol '%s': must be 'tcp', 'udp', or 'both'", protocol)
 	}
 
 	return nil
@@ -190,7 +524,7 @@ func configurePortForwarding(ctx context.Context, manager ContainerPortManager,
 
 	// Check if container exists
 	if !manager.ContainerExists(ctx, containerName) {
-		return fmt.Errorf("container '%s' does not exist", containerName)
+		return containerNotFound(containerName)
 	}
 
 	// Handle empty protocol (default to tcp)
@@ -206,11 +540,16 @@ func configurePortForwarding(ctx context.Context, manager ContainerPortManager,
 	case "udp":
 		return configurePortForwardingForProtocol(ctx, manager, containerName, hostPort, containerPort, "udp", force)
 	case "both":
-		// Configure both TCP and UDP
-		if err := configurePortForwardingForProtocol(ctx, manager, containerName, hostPort, containerPort, "tcp", force); err != nil {
-			return err
+		// Configure both TCP and UDP, removing the TCP device if UDP fails
+		undo := NewUndoStack()
+		for _, proto := range []string{"tcp", "udp"} {
+			if err := configurePortForwardingForProtocol(ctx, manager, containerName, hostPort, containerPort, proto, force); err != nil {
+				undo.Rollback(err)
+				return err
+			}
+			pushRemovePortDevice(undo, manager, containerName, portDeviceName(containerName, hostPort, containerPort, proto))
 		}
-		return configurePortForwardingForProtocol(ctx, manager, containerName, hostPort, containerPort, "udp", force)
+		return nil
 	default:
 		return fmt.Errorf("unsupported protocol: %s", protocol)
 	}
@@ -218,41 +557,167 @@ func configurePortForwarding(ctx context.Context, manager ContainerPortManager,
 
 // configurePortForwardingForProtocol configures port forwarding for a specific protocol
 func configurePortForwardingForProtocol(ctx context.Context, manager ContainerPortManager, containerName, hostPort, containerPort, protocol string, force bool) error {
+	deviceName := portDeviceName(containerName, hostPort, containerPort, protocol)
+	return addPortProxyDevice(ctx, manager, containerName, deviceName, "0.0.0.0", hostPort, containerPort, protocol, force)
+}
+
+// portDeviceName returns the name of the proxy device forwarding hostPort
+func portDeviceName(containerName, hostPort, containerPort, protocol string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", containerName, hostPort, containerPort, protocol)
+}
+
+// portAddressDeviceName returns the name of one of several proxy devices
+// forwarding the same host port, told apart by the address they listen on,
+// e.g. web-443-443-tcp-192-0-2-10
+func portAddressDeviceName(containerName, hostPort, containerPort, protocol, listenIP string) string {
+	suffix := strings.NewReplacer(".", "-", ":", "-").Replace(strings.ToLower(listenIP))
+	return portDeviceName(containerName, hostPort, containerPort, protocol) + "-" + suffix
+}
+
+// configureInterfacePortForwarding forwards a host port on the addresses of
+// one host network interface, with a device per address and protocol
+func configureInterfacePortForwarding(ctx context.Context, manager ContainerPortManager, containerName, hostPort, containerPort, protocol, iface string, force bool) error {
+	if err := validatePortForwardingArgs(containerName, hostPort, containerPort, protocol); err != nil {
+		return err
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+	addresses, err := manager.InterfaceAddresses(iface)
+	if err != nil {
+		return err
+	}
+	logger.Info("Interface '%s' has address(es) %s", iface, strings.Join(addresses, ", "))
+
+	undo := NewUndoStack()
+	for _, proto := range portSpecProtocols(protocol) {
+		for _, address := range addresses {
+			deviceName := portDeviceName(containerName, hostPort, containerPort, proto)
+			if len(addresses) > 1 {
+				deviceName = portAddressDeviceName(containerName, hostPort, containerPort, proto, address)
+			}
+			if err := addPortProxyDevice(ctx, manager, containerName, deviceName, address, hostPort, containerPort, proto, force); err != nil {
+				undo.Rollback(err)
+				return err
+			}
+			pushRemovePortDevice(undo, manager, containerName, deviceName)
+		}
+	}
+	return nil
+}
+
+// pushRemovePortDevice records removing a proxy device that was just added
+func pushRemovePortDevice(undo *UndoStack, manager ContainerPortManager, containerName, deviceName string) {
+	undo.Push(fmt.Sprintf("remove device '%s' from container '%s'", deviceName, containerName), func(ctx context.Context) error {
+		return manager.RunLXCCommand(ctx, "lxc", "config", "device", "remove", containerName, deviceName)
+	})
+}
+
+// addPortProxyDevice adds a proxy device forwarding hostPort on listenIP to
+// containerPort in the instance, checking the host port first unless forced
+func addPortProxyDevice(ctx context.Context, manager ContainerPortManager, containerName, deviceName, listenIP, hostPort, containerPort, protocol string, force bool) error {
 	// Check port availability unless forced
 	if !force {
 		hostPortNum, err := strconv.Atoi(hostPort)
 		if err != nil {
-			return fmt.Errorf("invalid host port '%s': %w", hostPort, err)
+			return helpers.UsageErrorf("invalid host port '%s': %w", hostPort, err)
 		}
 
-		if !helpers.IsPortAvailable(hostPortNum, protocol) {
+		ip := listenIP
+		if ip == "0.0.0.0" {
+			ip = ""
+		}
+		if !helpers.IsPortAvailableOn(ip, hostPortNum, protocol) {
 			return helpers.FormatPortConflictError(hostPort, protocol)
 		}
 	}
 
-	deviceName := fmt.Sprintf("%s-%s-%s-%s", containerName, hostPort, containerPort, protocol)
-	connectAddr := fmt.Sprintf("%s:0.0.0.0:%s", protocol, containerPort) // Container side
-	listenAddr := fmt.Sprintf("%s:0.0.0.0:%s", protocol, hostPort)       // Host side
+	listenAddr := fmt.Sprintf("%s:%s", protocol, net.JoinHostPort(listenIP, hostPort)) // Host side
+
+	// Container side
+	connect, err := proxyConnectSettings(ctx, manager, containerName, protocol, containerPort)
+	if err != nil {
+		return fmt.Errorf("failed to configure %s port forwarding to '%s': %w", protocol, containerName, err)
+	}
 
-	logger.Info("Configuring %s port forwarding: %s:%s -> %s:%s",
-		strings.ToUpper(protocol), "0.0.0.0", hostPort, containerName, containerPort)
+	logger.Info("Configuring %s port forwarding: %s -> %s:%s",
+		strings.ToUpper(protocol), net.JoinHostPort(listenIP, hostPort), containerName, containerPort)
 
 	// Use lxc config device add to create the proxy device
-	err := manager.RunLXCCommand(ctx, "lxc", "config", "device", "add", containerName, deviceName, "proxy",
-		fmt.Sprintf("connect=%s", connectAddr), fmt.Sprintf("listen=%s", listenAddr))
+	args := append([]string{"lxc", "config", "device", "add", containerName, deviceName, "proxy"}, connect...)
+	err = manager.RunLXCCommand(ctx, append(args, fmt.Sprintf("listen=%s", listenAddr))...)
 	if err != nil {
-		return fmt.Errorf("failed to configure %s port forwarding %s:%s -> %s:%s: %w",
-			protocol, "0.0.0.0", hostPort, containerName, containerPort, err)
+		return fmt.Errorf("failed to configure %s port forwarding %s -> %s:%s: %w",
+			protocol, net.JoinHostPort(listenIP, hostPort), containerName, containerPort, err)
+	}
+
+	logger.Info("Successfully configured %s port forwarding %s -> %s:%s",
+		strings.ToUpper(protocol), net.JoinHostPort(listenIP, hostPort), containerName, containerPort)
+
+	return nil
+}
+
+// configureReversePortForwarding lets processes in a container reach a host
+// service: the proxy binds inside the container and connects on the host
+func configureReversePortForwarding(ctx context.Context, manager ContainerPortManager, containerName, containerPort, hostPort, protocol string) error {
+	if err := validatePortForwardingArgs(containerName, hostPort, containerPort, protocol); err != nil {
+		return err
+	}
+
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+	if instanceType, err := manager.GetInstanceType(ctx, containerName); err == nil && instanceType == helpers.InstanceTypeVM {
+		return fmt.Errorf("reverse port forwarding needs a proxy bound inside the instance, which VMs do not support; '%s' is a VM", containerName)
+	}
+
+	protocols := []string{strings.ToLower(protocol)}
+	switch protocols[0] {
+	case "":
+		protocols = []string{"tcp"}
+	case "both":
+		protocols = []string{"tcp", "udp"}
 	}
 
-	logger.Info("Successfully configured %s port forwarding %s:%s -> %s:%s",
-		strings.ToUpper(protocol), "0.0.0.0", hostPort, containerName, containerPort)
+	hostPortNum, _ := strconv.Atoi(hostPort)
+	undo := NewUndoStack()
+	for _, proto := range protocols {
+		if helpers.IsPortAvailable(hostPortNum, proto) {
+			logger.Warn("Nothing is listening on host port %s/%s yet; connections from '%s' will fail until a service does",
+				hostPort, proto, containerName)
+		}
+
+		deviceName := reversePortDeviceName(containerName, containerPort, hostPort, proto)
+		listenAddr := fmt.Sprintf("%s:127.0.0.1:%s", proto, containerPort) // Container side
+		connectAddr := fmt.Sprintf("%s:127.0.0.1:%s", proto, hostPort)     // Host side
+
+		logger.Info("Configuring reverse %s port forwarding: %s:127.0.0.1:%s -> host:127.0.0.1:%s",
+			strings.ToUpper(proto), containerName, containerPort, hostPort)
+
+		err := manager.RunLXCCommand(ctx, "lxc", "config", "device", "add", containerName, deviceName, "proxy",
+			"bind=container", fmt.Sprintf("listen=%s", listenAddr), fmt.Sprintf("connect=%s", connectAddr))
+		if err != nil {
+			err = fmt.Errorf("failed to configure reverse %s port forwarding %s:%s -> host:%s: %w",
+				proto, containerName, containerPort, hostPort, err)
+			undo.Rollback(err)
+			return err
+		}
+		pushRemovePortDevice(undo, manager, containerName, deviceName)
+	}
 
+	logger.Info("Successfully configured reverse port forwarding %s:%s -> host:%s", containerName, containerPort, hostPort)
 	return nil
 }
 
+// reversePortDeviceName names a reverse proxy device
+// {containerName}-rev-{containerPort}-{hostPort}-{protocol}
+func reversePortDeviceName(containerName, containerPort, hostPort, protocol string) string {
+	return fmt.Sprintf("%s-rev-%s-%s-%s", containerName, containerPort, hostPort, protocol)
+}
+
 // ContainerConfig represents the structure of lxc config show output
 type ContainerConfig struct {
+	Config  map[string]string `yaml:"config"`
 	Devices map[string]Device `yaml:"devices"`
 }
 
@@ -261,6 +726,8 @@ type Device struct {
 	Type    string `yaml:"type"`
 	Connect string `yaml:"connect,omitempty"`
 	Listen  string `yaml:"listen,omitempty"`
+	Bind    string `yaml:"bind,omitempty"`
+	Nat     string `yaml:"nat,omitempty"`
 }
 
 // PortMapping represents a port forwarding configuration
@@ -271,17 +738,107 @@ type PortMapping struct {
 	ContainerPort string
 	HostIP        string
 	ContainerIP   string
+	Reverse       bool
+	// NAT devices forward packets in the kernel instead of a proxy process
+	NAT bool
+}
+
+// PortProbeManager checks whether forwarded ports are live, for port list --check
+type PortProbeManager interface {
+	IsPortAvailable(port int, protocol string) bool
+	ProbePort(hostPort int, protocol string, timeout time.Duration) error
+}
+
+// DefaultPortProbeManager implements PortProbeManager using helpers
+type DefaultPortProbeManager struct{}
+
+func (d *DefaultPortProbeManager) IsPortAvailable(port int, protocol string) bool {
+	return helpers.IsPortAvailable(port, protocol)
+}
+
+func (d *DefaultPortProbeManager) ProbePort(hostPort int, protocol string, timeout time.Duration) error {
+	return helpers.ValidatePortMapping(hostPort, protocol, timeout)
+}
+
+// portProbe is the live state of a mapping: "yes", "no" or "-" when it does
+// not apply
+type portProbe struct {
+	Listening string
+	Reachable string
+}
+
+// probePortMappings checks all mappings at once, as each probe can take up to
+// portProbeTimeout
+func probePortMappings(prober PortProbeManager, mappings []PortMapping) []portProbe {
+	probes := make([]portProbe, len(mappings))
+	var wg sync.WaitGroup
+	for i, mapping := range mappings {
+		wg.Add(1)
+		go func(i int, mapping PortMapping) {
+			defer wg.Done()
+			probes[i] = probePortMapping(prober, mapping)
+		}(i, mapping)
+	}
+	wg.Wait()
+	return probes
+}
+
+// probePortMapping checks whether the host port of a mapping is bound and,
+// for TCP, whether a connection through it succeeds
+func probePortMapping(prober PortProbeManager, mapping PortMapping) portProbe {
+	port, err := strconv.Atoi(mapping.HostPort)
+	// Reverse mappings listen inside the container, not on the host
+	if err != nil || mapping.Reverse {
+		return portProbe{Listening: "-", Reachable: "-"}
+	}
+
+	listening := !prober.IsPortAvailable(port, mapping.Protocol)
+	probe := portProbe{Listening: yesNo(listening), Reachable: "-"}
+	if !strings.EqualFold(mapping.Protocol, "tcp") {
+		return probe
+	}
+	if !listening {
+		probe.Reachable = "no"
+		return probe
+	}
+	if err := prober.ProbePort(port, mapping.Protocol, portProbeTimeout); err != nil {
+		logger.Debug("Probe of %s failed: %v", mapping.DeviceName, err)
+		probe.Reachable = "no"
+	} else {
+		probe.Reachable = "yes"
+	}
+	return probe
+}
+
+// yesNo renders a boolean as "yes" or "no"
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
 }
 
-// listPortForwarding lists all port forwarding rules for a container
-func listPortForwarding(ctx context.Context, manager ContainerPortManager, containerName string) error {
+// yesNoColor colors "yes" green and "no" red
+func yesNoColor(value string) render.Color {
+	switch value {
+	case "yes":
+		return render.Green
+	case "no":
+		return render.Red
+	}
+	return render.NoColor
+}
+
+// listPortForwarding lists all port forwarding rules for a container; with a
+// prober the live state of each mapping is shown too
+func listPortForwarding(ctx context.Context, manager ContainerPortManager, containerName string, table render.Options, prober PortProbeManager) error {
 	if containerName == "" {
 		return fmt.Errorf("container name is required")
 	}
 
 	// Check if container exists
 	if !manager.ContainerExists(ctx, containerName) {
-		return fmt.Errorf("container '%s' does not exist", containerName)
+		return containerNotFound(containerName)
 	}
 
 	// Get container configuration
@@ -296,14 +853,33 @@ func listPortForwarding(ctx context.Context, manager ContainerPortManager, conta
 		return fmt.Errorf("failed to parse port mappings: %w", err)
 	}
 
+	// Rules on a paused container exist but nothing answers on them
+	if status, err := manager.GetContainerStatus(ctx, containerName); err != nil {
+		logger.Debug("Could not get status of container '%s': %v", containerName, err)
+	} else if status == helpers.StatusFrozen {
+		fmt.Println(frozenNotice(containerName))
+	}
+
 	// Display results
 	if len(mappings) == 0 {
 		fmt.Printf("No port forwarding rules found for container '%s'\n", containerName)
 		return nil
 	}
 
-	fmt.Printf("Port mappings for container '%s':\n", containerName)
-	fmt.Print(formatPortMappings(mappings))
+	var probes []portProbe
+	if prober != nil {
+		probes = probePortMappings(prober, mappings)
+	}
+
+	// Render first, so an unknown --columns name fails before anything is printed
+	var rendered strings.Builder
+	if err := portMappingTable(mappings, probes).Render(&rendered, table); err != nil {
+		return err
+	}
+	if !table.NoHeader {
+		fmt.Printf("Port mappings for container '%s':\n", containerName)
+	}
+	fmt.Print(rendered.String())
 	return nil
 }
 
@@ -314,26 +890,64 @@ func parsePortMappingsFromConfig(yamlData []byte, containerName string) ([]PortM
 		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
 	}
 
+	return portMappingsFromDevices(config.Config, config.Devices, containerName), nil
+}
+
+// portMappingsFromDevices extracts port mappings from a container's config and devices
+func portMappingsFromDevices(config map[string]string, devices map[string]Device, containerName string) []PortMapping {
+	// Devices registered by adopt keep their original names
+	adopted := make(map[string]bool)
+	for _, name := range helpers.SplitConfigList(config[helpers.AdoptedPortsKey]) {
+		adopted[name] = true
+	}
+
 	var mappings []PortMapping
-	for deviceName, device := range config.Devices {
-		// Only process proxy devices that match our naming convention
-		if device.Type == "proxy" && isPortDevice(deviceName, containerName) {
-			mapping, err := parsePortMapping(deviceName, device)
-			if err != nil {
-				logger.Debug("Failed to parse port mapping for device '%s': %v", deviceName, err)
-				continue
-			}
-			mappings = append(mappings, *mapping)
+	for deviceName, device := range devices {
+		if device.Type != "proxy" {
+			continue
+		}
+
+		var mapping *PortMapping
+		var err error
+		switch {
+		// Only process proxy devices that match our naming convention or were adopted
+		case isPortDevice(deviceName, containerName):
+			mapping, err = parsePortMapping(deviceName, device)
+		case isReversePortDevice(deviceName, containerName) && device.Bind == "container":
+			mapping, err = parseReversePortMapping(deviceName, device)
+		case adopted[deviceName]:
+			mapping, err = parseAdoptedPortMapping(deviceName, device)
+		default:
+			continue
 		}
+		if err != nil {
+			logger.Debug("Failed to parse port mapping for device '%s': %v", deviceName, err)
+			continue
+		}
+		mapping.NAT = device.Nat == "true"
+		mappings = append(mappings, *mapping)
 	}
 
-	return mappings, nil
+	return mappings
 }
 
 // isPortDevice checks if a device name matches our port forwarding naming convention
 func isPortDevice(deviceName, containerName string) bool {
-	// Expected pattern: {containerName}-{hostPort}-{containerPort}-{protocol}
-	pattern := fmt.Sprintf(`^%s-\d+-\d+-(tcp|udp)$`, regexp.QuoteMeta(containerName))
+	// Expected pattern: {containerName}-{hostPort}-{containerPort}-{protocol},
+	// followed by the listen address when a port has several
+	pattern := fmt.Sprintf(`^%s-\d+-\d+-(tcp|udp)(-[0-9a-f-]+)?$`, regexp.QuoteMeta(containerName))
+	matched, err := regexp.MatchString(pattern, deviceName)
+	if err != nil {
+		logger.Debug("Failed to match device name pattern: %v", err)
+		return false
+	}
+	return matched
+}
+
+// isReversePortDevice checks if a device name matches our reverse forwarding naming convention
+func isReversePortDevice(deviceName, containerName string) bool {
+	// Expected pattern: {containerName}-rev-{containerPort}-{hostPort}-{protocol}
+	pattern := fmt.Sprintf(`^%s-rev-\d+-\d+-(tcp|udp)$`, regexp.QuoteMeta(containerName))
 	matched, err := regexp.MatchString(pattern, deviceName)
 	if err != nil {
 		logger.Debug("Failed to match device name pattern: %v", err)
@@ -342,18 +956,18 @@ func isPortDevice(deviceName, containerName string) bool {
 	return matched
 }
 
+// portDeviceSuffix matches the end of a port device name:
+// -{hostPort}-{containerPort}-{protocol}, then the listen address if any
+var portDeviceSuffix = regexp.MustCompile(`-(\d+)-(\d+)-(tcp|udp)(-[0-9a-f-]+)?$`)
+
 // parsePortMapping extracts port mapping information from device configuration
 func parsePortMapping(deviceName string, device Device) (*PortMapping, error) {
 	// Extract protocol, host port, container port from device name
-	// Expected format: {containerName}-{hostPort}-{containerPort}-{protocol}
-	parts := strings.Split(deviceName, "-")
-	if len(parts) < 4 {
+	match := portDeviceSuffix.FindStringSubmatch(deviceName)
+	if match == nil {
 		return nil, fmt.Errorf("invalid device name format: %s", deviceName)
 	}
-
-	protocol := parts[len(parts)-1]
-	containerPort := parts[len(parts)-2]
-	hostPort := parts[len(parts)-3]
+	hostPort, containerPort, protocol := match[1], match[2], match[3]
 
 	// Parse connect and listen addresses
 	hostIP, containerIP := "0.0.0.0", "0.0.0.0"
@@ -366,12 +980,9 @@ func parsePortMapping(deviceName string, device Device) (*PortMapping, error) {
 		}
 	}
 
-	// Parse listen field (format: tcp:IP:PORT or udp:IP:PORT)
-	if device.Listen != "" {
-		listenParts := strings.Split(device.Listen, ":")
-		if len(listenParts) == 3 {
-			containerIP = listenParts[1]
-		}
+	// Parse listen field (format: tcp:IP:PORT, or tcp:[IP]:PORT for IPv6)
+	if _, ip, _, ok := parseProxyEndpoint(device.Listen); ok {
+		containerIP = ip
 	}
 
 	return &PortMapping{
@@ -384,31 +995,1048 @@ func parsePortMapping(deviceName string, device Device) (*PortMapping, error) {
 	}, nil
 }
 
-// formatPortMappings formats port mappings for display
-func formatPortMappings(mappings []PortMapping) string {
-	if len(mappings) == 0 {
-		return ""
+// parseAdoptedPortMapping extracts port mapping information from the listen and
+// connect addresses of a device whose name does not follow our convention
+func parseAdoptedPortMapping(deviceName string, device Device) (*PortMapping, error) {
+	protocol, hostIP, hostPort, ok := parseProxyEndpoint(device.Listen)
+	if !ok {
+		return nil, fmt.Errorf("invalid listen address: %s", device.Listen)
+	}
+	_, containerIP, containerPort, ok := parseProxyEndpoint(device.Connect)
+	if !ok {
+		return nil, fmt.Errorf("invalid connect address: %s", device.Connect)
 	}
 
-	var result strings.Builder
-
-	// Table header
-	result.WriteString("PROTOCOL  HOST PORT  CONTAINER PORT  HOST IP      CONTAINER IP  DEVICE NAME\n")
-	result.WriteString("--------  ---------  --------------  -----------  ------------  -----------\n")
+	return &PortMapping{
+		DeviceName:    deviceName,
+		Protocol:      strings.ToUpper(protocol),
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+		HostIP:        hostIP,
+		ContainerIP:   containerIP,
+	}, nil
+}
 
-	// Table rows
-	for _, mapping := range mappings {
-		result.WriteString(fmt.Sprintf("%-8s  %-9s  %-14s  %-11s  %-12s  %s\n",
-			mapping.Protocol,
-			mapping.HostPort,
-			mapping.ContainerPort,
-			mapping.HostIP,
-			mapping.ContainerIP,
-			mapping.DeviceName,
-		))
+// parseReversePortMapping extracts port mapping information from a reverse
+// device, which listens inside the container and connects on the host
+func parseReversePortMapping(deviceName string, device Device) (*PortMapping, error) {
+	protocol, containerIP, containerPort, ok := parseProxyEndpoint(device.Listen)
+	if !ok {
+		return nil, fmt.Errorf("invalid listen address: %s", device.Listen)
+	}
+	_, hostIP, hostPort, ok := parseProxyEndpoint(device.Connect)
+	if !ok {
+		return nil, fmt.Errorf("invalid connect address: %s", device.Connect)
 	}
 
-	return result.String()
+	return &PortMapping{
+		DeviceName:    deviceName,
+		Protocol:      strings.ToUpper(protocol),
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+		HostIP:        hostIP,
+		ContainerIP:   containerIP,
+		Reverse:       true,
+	}, nil
+}
+
+// parseProxyEndpoint splits a proxy address such as "tcp:0.0.0.0:8080" or "tcp:[::]:8080"
+func parseProxyEndpoint(address string) (protocol, ip, port string, ok bool) {
+	first := strings.Index(address, ":")
+	last := strings.LastIndex(address, ":")
+	if first < 0 || last == first {
+		return "", "", "", false
+	}
+	protocol, ip, port = address[:first], address[first+1:last], address[last+1:]
+	if protocol != "tcp" && protocol != "udp" {
+		return "", "", "", false
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", "", "", false
+	}
+	return protocol, strings.Trim(ip, "[]"), port, true
+}
+
+// formatPortMappings formats port mappings for display
+func formatPortMappings(mappings []PortMapping) string {
+	if len(mappings) == 0 {
+		return ""
+	}
+	return portMappingTable(mappings, nil).String()
+}
+
+// portMappingTable lays out port mappings as a table, with LISTENING and
+// REACHABLE columns when probes are given
+func portMappingTable(mappings []PortMapping, probes []portProbe) *render.Table {
+	headers := []string{"PROTOCOL", "HOST PORT", "CONTAINER PORT", "HOST IP", "CONTAINER IP", "DEVICE NAME"}
+	if probes != nil {
+		headers = append(headers, "LISTENING", "REACHABLE")
+	}
+	table := render.NewTable(headers...)
+	for i, mapping := range mappings {
+		deviceName := mapping.DeviceName
+		if mapping.Reverse {
+			deviceName += " (container -> host)"
+		}
+		row := []string{mapping.Protocol, mapping.HostPort, mapping.ContainerPort, mapping.HostIP, mapping.ContainerIP, deviceName}
+		if probes != nil {
+			row = append(row, probes[i].Listening, probes[i].Reachable)
+		}
+		table.AddRow(row...)
+	}
+	table.Style("listening", yesNoColor)
+	table.Style("reachable", yesNoColor)
+	return table
+}
+
+// PortSpec is the desired port forwarding of a container, as read by port apply
+type PortSpec struct {
+	Ports []PortSpecEntry `yaml:"ports"`
+	// Devices are the container's other devices by name, with their LXD settings
+	Devices map[string]map[string]string `yaml:"devices,omitempty" desc:"Other devices by name, with their LXD settings such as type, source and path"`
+}
+
+// PortSpecEntry is one declared mapping
+type PortSpecEntry struct {
+	Host      int    `yaml:"host" desc:"Host port" min:"1" max:"65535"`
+	Container int    `yaml:"container" desc:"Container port" min:"1" max:"65535"`
+	Protocol  string `yaml:"protocol,omitempty" desc:"Protocol to forward (default tcp)" enum:"tcp,udp,both"`
+	Listen    string `yaml:"listen,omitempty" desc:"Host address to listen on (default 0.0.0.0)"`
+}
+
+// portRule is a single-protocol proxy device as port apply compares them
+type portRule struct {
+	DeviceName    string
+	Protocol      string
+	HostPort      string
+	ContainerPort string
+	ListenIP      string
+}
+
+func (r portRule) String() string {
+	return fmt.Sprintf("%s %s -> %s", r.Protocol, net.JoinHostPort(r.ListenIP, r.HostPort), r.ContainerPort)
+}
+
+// portApplyOptions controls how port apply changes the container
+type portApplyOptions struct {
+	// DryRun only prints the differences
+	DryRun bool
+	// Force skips the host port availability check
+	Force bool
+	// KeepUnlisted leaves rules that are not in the spec in place, as port import does
+	KeepUnlisted bool
+}
+
+// loadPortSpec reads and validates a ports file; "-" reads it from stdin
+func loadPortSpec(path string, stdin io.Reader) (*PortSpec, error) {
+	if path == "" {
+		return nil, fmt.Errorf("a ports file is required (use -f)")
+	}
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ports file: %w", err)
+	}
+	return parsePortSpec(data)
+}
+
+// parsePortSpec parses and validates the contents of a port apply file
+func parsePortSpec(data []byte) (*PortSpec, error) {
+	var spec PortSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse ports file: %w", err)
+	}
+	if problems := portSpecProblems(&spec); len(problems) > 0 {
+		return nil, fmt.Errorf("ports[%d]: %w", problems[0].Entry, problems[0].Err)
+	}
+	if problems := specDeviceProblems(&spec); len(problems) > 0 {
+		return nil, fmt.Errorf("devices.%s: %w", problems[0].Device, problems[0].Err)
+	}
+	return &spec, nil
+}
+
+// portSpecProblem is a problem with one field of a ports file entry
+type portSpecProblem struct {
+	Entry int
+	Field string
+	Err   error
+}
+
+// portSpecProblems checks every field of every entry, in file order
+func portSpecProblems(spec *PortSpec) []portSpecProblem {
+	var problems []portSpecProblem
+	for i, entry := range spec.Ports {
+		if entry.Host < 1 || entry.Host > 65535 {
+			problems = append(problems, portSpecProblem{i, "host", helpers.UsageErrorf("invalid host port '%d': must be between 1 and 65535", entry.Host)})
+		}
+		if entry.Container < 1 || entry.Container > 65535 {
+			problems = append(problems, portSpecProblem{i, "container", helpers.UsageErrorf("invalid container port '%d': must be between 1 and 65535", entry.Container)})
+		}
+		if err := validatePortForwardingArgs("-", "1", "1", entry.Protocol); err != nil {
+			problems = append(problems, portSpecProblem{i, "protocol", err})
+		}
+		if entry.Listen != "" && net.ParseIP(entry.Listen) == nil {
+			problems = append(problems, portSpecProblem{i, "listen", helpers.UsageErrorf("invalid listen address '%s'", entry.Listen)})
+		}
+	}
+	return problems
+}
+
+// specDeviceProblem is a problem with a device of a ports file
+type specDeviceProblem struct {
+	Device string
+	Err    error
+}
+
+// specDeviceProblems checks that every device has a type, in name order
+func specDeviceProblems(spec *PortSpec) []specDeviceProblem {
+	var problems []specDeviceProblem
+	for _, name := range sortedDeviceNames(spec.Devices) {
+		if spec.Devices[name]["type"] == "" {
+			problems = append(problems, specDeviceProblem{name, helpers.UsageErrorf("type is required")})
+		}
+	}
+	return problems
+}
+
+// sortedDeviceNames returns the names of devices in order
+func sortedDeviceNames(devices map[string]map[string]string) []string {
+	names := make([]string, 0, len(devices))
+	for name := range devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// portSpecDuplicates reports host ports declared more than once for a
+// protocol; a port may only be repeated on different specific addresses
+func portSpecDuplicates(spec *PortSpec) []portSpecProblem {
+	var problems []portSpecProblem
+	listens := make(map[string][]string)
+	for i, entry := range spec.Ports {
+		listen := listenOrDefault(entry.Listen)
+		for _, protocol := range portSpecProtocols(entry.Protocol) {
+			key := fmt.Sprintf("%d/%s", entry.Host, protocol)
+			if listenConflicts(listens[key], listen) {
+				problems = append(problems, portSpecProblem{i, "host", fmt.Errorf("host port %s is declared more than once", key)})
+				break
+			}
+			listens[key] = append(listens[key], listen)
+		}
+	}
+	return problems
+}
+
+// listenOrDefault returns the listen address of a spec entry, all IPv4
+// addresses by default
+func listenOrDefault(listen string) string {
+	if listen == "" {
+		return "0.0.0.0"
+	}
+	return listen
+}
+
+// listenConflicts reports whether a host port cannot listen on listen as
+// well as on the addresses it already has: the same address twice, or any
+// address next to all of them
+func listenConflicts(existing []string, listen string) bool {
+	for _, have := range existing {
+		if have == listen || net.ParseIP(have).IsUnspecified() || net.ParseIP(listen).IsUnspecified() {
+			return true
+		}
+	}
+	return false
+}
+
+// portSpecProtocols expands the protocol of a spec entry, defaulting to tcp
+func portSpecProtocols(protocol string) []string {
+	switch protocol = strings.ToLower(protocol); protocol {
+	case "":
+		return []string{"tcp"}
+	case "both":
+		return []string{"tcp", "udp"}
+	default:
+		return []string{protocol}
+	}
+}
+
+// yamlLineError matches the "line N: message" parts of yaml.v2 errors
+var yamlLineError = regexp.MustCompile(`line (\d+): (.+)`)
+
+// yamlUnknownField matches yaml.v2's error for keys the struct does not have
+var yamlUnknownField = regexp.MustCompile(`^field (\S+) not found`)
+
+// specProblem is a problem found in a YAML file at a 1-based line and
+// column; zero means the position is unknown
+type specProblem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p specProblem) format(name string) string {
+	switch {
+	case p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", name, p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", name, p.Line, p.Message)
+	default:
+		return fmt.Sprintf("%s: %s", name, p.Message)
+	}
+}
+
+// validatePortSpecFile prints every problem in a ports file as
+// file:line:column: message and returns an exit status 1 error if there are any
+func validatePortSpecFile(name string, data []byte, out io.Writer) error {
+	lines := strings.Split(string(data), "\n")
+	var problems []specProblem
+
+	var spec PortSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		matches := yamlLineError.FindAllStringSubmatch(err.Error(), -1)
+		if len(matches) == 0 {
+			problems = append(problems, specProblem{Message: strings.TrimPrefix(err.Error(), "yaml: ")})
+		}
+		for _, match := range matches {
+			problem := specProblem{Message: match[2]}
+			problem.Line, _ = strconv.Atoi(match[1])
+			if field := yamlUnknownField.FindStringSubmatch(match[2]); field != nil && problem.Line <= len(lines) {
+				problem.Column = strings.Index(lines[problem.Line-1], field[1]) + 1
+				problem.Message = fmt.Sprintf("unknown field '%s'", field[1])
+			}
+			problems = append(problems, problem)
+		}
+	} else {
+		positions := portSpecPositions(lines)
+		for _, found := range append(portSpecProblems(&spec), portSpecDuplicates(&spec)...) {
+			path := fmt.Sprintf("ports[%d]", found.Entry)
+			problem, ok := positions[path+"."+found.Field]
+			if !ok {
+				problem = positions[path]
+			}
+			problem.Message = fmt.Sprintf("%s.%s: %v", path, found.Field, found.Err)
+			problems = append(problems, problem)
+		}
+		for _, found := range specDeviceProblems(&spec) {
+			path := "devices." + found.Device
+			problem := positions[path]
+			problem.Message = fmt.Sprintf("%s: %v", path, found.Err)
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) == 0 && len(spec.Devices) > 0 {
+		fmt.Fprintf(out, "%s: OK (%d mapping(s), %d device(s))\n", name, len(spec.Ports), len(spec.Devices))
+		return nil
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(out, "%s: OK (%d mapping(s))\n", name, len(spec.Ports))
+		return nil
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem.format(name))
+	}
+	return &ExitError{Code: 1, Err: fmt.Errorf("%s has %d problem(s)", name, len(problems))}
+}
+
+// yamlKey matches the keys on a line of block or flow style YAML
+var yamlKey = regexp.MustCompile(`(?:^|[\s{,-])([A-Za-z_][\w-]*)\s*:`)
+
+// portSpecPositions finds where each entry of a ports file and its keys
+// start, keyed like ports[1].host, and where each device starts, keyed like
+// devices.gpu0. Only the top-level ports list and devices map are scanned.
+func portSpecPositions(lines []string) map[string]specProblem {
+	positions := make(map[string]specProblem)
+	inPorts, inDevices := false, false
+	entry, deviceIndent := -1, 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 && !strings.HasPrefix(trimmed, "-") {
+			inPorts = strings.HasPrefix(trimmed, "ports:")
+			inDevices = strings.HasPrefix(trimmed, "devices:")
+			deviceIndent = 0
+			continue
+		}
+		if inDevices {
+			// Device names are the keys of the first indentation level
+			if deviceIndent == 0 {
+				deviceIndent = indent
+			}
+			if match := yamlKey.FindStringSubmatchIndex(line); indent == deviceIndent && match != nil {
+				positions["devices."+line[match[2]:match[3]]] = specProblem{Line: i + 1, Column: match[2] + 1}
+			}
+			continue
+		}
+		if !inPorts {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			entry++
+			positions[fmt.Sprintf("ports[%d]", entry)] = specProblem{Line: i + 1, Column: indent + 1}
+		}
+		if entry < 0 {
+			continue
+		}
+		for _, match := range yamlKey.FindAllStringSubmatchIndex(line, -1) {
+			key := fmt.Sprintf("ports[%d].%s", entry, line[match[2]:match[3]])
+			if _, ok := positions[key]; !ok {
+				positions[key] = specProblem{Line: i + 1, Column: match[2] + 1}
+			}
+		}
+	}
+	return positions
+}
+
+// desiredPortRules expands a spec into one rule per protocol, rejecting
+// host ports declared twice for the same protocol. A host port declared on
+// several addresses gets a device per address.
+func desiredPortRules(containerName string, spec *PortSpec) ([]portRule, error) {
+	listens := make(map[string][]string)
+	var rules []portRule
+	for _, entry := range spec.Ports {
+		protocols := portSpecProtocols(entry.Protocol)
+		listen := listenOrDefault(entry.Listen)
+
+		for _, protocol := range protocols {
+			rule := portRule{
+				Protocol:      protocol,
+				HostPort:      strconv.Itoa(entry.Host),
+				ContainerPort: strconv.Itoa(entry.Container),
+				ListenIP:      listen,
+			}
+			key := protocol + "/" + rule.HostPort
+			if listenConflicts(listens[key], listen) {
+				return nil, fmt.Errorf("host port %s/%s is declared more than once", rule.HostPort, protocol)
+			}
+			listens[key] = append(listens[key], listen)
+			rules = append(rules, rule)
+		}
+	}
+
+	for i, rule := range rules {
+		rules[i].DeviceName = portDeviceName(containerName, rule.HostPort, rule.ContainerPort, rule.Protocol)
+		if len(listens[rule.Protocol+"/"+rule.HostPort]) > 1 {
+			rules[i].DeviceName = portAddressDeviceName(containerName, rule.HostPort, rule.ContainerPort, rule.Protocol, rule.ListenIP)
+		}
+	}
+	return rules, nil
+}
+
+// currentPortRules returns the proxy devices this tool created on the container
+func currentPortRules(configData []byte, containerName string) ([]portRule, error) {
+	var config ContainerConfig
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+
+	var rules []portRule
+	for deviceName, device := range config.Devices {
+		if device.Type != "proxy" || !isPortDevice(deviceName, containerName) {
+			continue
+		}
+		protocol, listenIP, hostPort, ok := parseProxyEndpoint(device.Listen)
+		if !ok {
+			logger.Debug("Skipping device '%s' with unexpected listen address '%s'", deviceName, device.Listen)
+			continue
+		}
+		_, _, containerPort, ok := parseProxyEndpoint(device.Connect)
+		if !ok {
+			logger.Debug("Skipping device '%s' with unexpected connect address '%s'", deviceName, device.Connect)
+			continue
+		}
+		rules = append(rules, portRule{
+			DeviceName:    deviceName,
+			Protocol:      protocol,
+			HostPort:      hostPort,
+			ContainerPort: containerPort,
+			ListenIP:      listenIP,
+		})
+	}
+	return rules, nil
+}
+
+// diffPortRules compares current and desired rules by device name; a rule
+// whose addresses changed is removed and added again
+func diffPortRules(current, desired []portRule) (add, remove, unchanged []portRule) {
+	existing := make(map[string]portRule, len(current))
+	for _, rule := range current {
+		existing[rule.DeviceName] = rule
+	}
+	wanted := make(map[string]bool, len(desired))
+
+	for _, rule := range desired {
+		wanted[rule.DeviceName] = true
+		have, ok := existing[rule.DeviceName]
+		switch {
+		case !ok:
+			add = append(add, rule)
+		case have != rule:
+			remove = append(remove, have)
+			add = append(add, rule)
+		default:
+			unchanged = append(unchanged, rule)
+		}
+	}
+	for _, rule := range current {
+		if !wanted[rule.DeviceName] {
+			remove = append(remove, rule)
+		}
+	}
+
+	byDevice := func(rules []portRule) {
+		sort.Slice(rules, func(i, j int) bool { return rules[i].DeviceName < rules[j].DeviceName })
+	}
+	byDevice(add)
+	byDevice(remove)
+	byDevice(unchanged)
+	return add, remove, unchanged
+}
+
+// applyPortSpec reconciles a container's port forwarding with spec and
+// prints the differences
+func applyPortSpec(ctx context.Context, manager ContainerPortManager, containerName string, spec *PortSpec, opts portApplyOptions, out io.Writer) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	desired, err := desiredPortRules(containerName, spec)
+	if err != nil {
+		return err
+	}
+	configData, err := manager.GetContainerConfig(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container configuration: %w", err)
+	}
+	current, err := currentPortRules(configData, containerName)
+	if err != nil {
+		return err
+	}
+
+	currentDevices, err := currentSpecDevices(configData, containerName)
+	if err != nil {
+		return err
+	}
+	deviceChanges, unchangedDevices, err := diffSpecDevices(currentDevices, spec.Devices)
+	if err != nil {
+		return err
+	}
+
+	if opts.KeepUnlisted {
+		current = listedPortRules(current, desired)
+	}
+	add, remove, unchanged := diffPortRules(current, desired)
+	for _, rule := range remove {
+		fmt.Fprintf(out, "- %s\n", rule)
+	}
+	for _, rule := range add {
+		fmt.Fprintf(out, "+ %s\n", rule)
+	}
+	for _, change := range deviceChanges {
+		fmt.Fprintf(out, "%s\n", change)
+	}
+	for _, rule := range unchanged {
+		fmt.Fprintf(out, "  %s\n", rule)
+	}
+	for _, name := range unchangedDevices {
+		fmt.Fprintf(out, "  device %s\n", name)
+	}
+	if len(add) == 0 && len(remove) == 0 && len(deviceChanges) == 0 {
+		fmt.Fprintf(out, "Port forwarding of '%s' is up to date\n", containerName)
+		return nil
+	}
+	if opts.DryRun {
+		fmt.Fprintf(out, "Dry run: %d to add, %d to remove\n", len(add)+len(deviceChanges), len(remove))
+		return nil
+	}
+	if len(remove) > 0 {
+		if err := confirm("port.apply_confirm", containerName, len(remove)); err != nil {
+			return err
+		}
+	}
+
+	// Removing first frees host ports that changed rules listen on again
+	for _, rule := range remove {
+		if err := manager.RunLXCCommand(ctx, "lxc", "config", "device", "remove", containerName, rule.DeviceName); err != nil {
+			return fmt.Errorf("failed to remove port forwarding %s: %w", rule, err)
+		}
+	}
+
+	removed := make(map[string]bool, len(remove))
+	for _, rule := range remove {
+		removed[rule.Protocol+"/"+rule.HostPort] = true
+	}
+	for _, rule := range add {
+		// A port freed by a removed rule of this container is available
+		if !opts.Force && !removed[rule.Protocol+"/"+rule.HostPort] {
+			port, _ := strconv.Atoi(rule.HostPort)
+			if !helpers.IsPortAvailable(port, rule.Protocol) {
+				return helpers.FormatPortConflictError(rule.HostPort, rule.Protocol)
+			}
+		}
+		connect, err := proxyConnectSettings(ctx, manager, containerName, rule.Protocol, rule.ContainerPort)
+		if err != nil {
+			return fmt.Errorf("failed to add port forwarding %s: %w", rule, err)
+		}
+		args := append([]string{"lxc", "config", "device", "add", containerName, rule.DeviceName, "proxy"}, connect...)
+		err = manager.RunLXCCommand(ctx, append(args, fmt.Sprintf("listen=%s:%s", rule.Protocol, net.JoinHostPort(rule.ListenIP, rule.HostPort)))...)
+		if err != nil {
+			return fmt.Errorf("failed to add port forwarding %s: %w", rule, err)
+		}
+	}
+
+	for _, change := range deviceChanges {
+		if err := manager.RunLXCCommand(ctx, change.command(containerName)...); err != nil {
+			return fmt.Errorf("failed to %s device '%s': %w", change.verb(), change.Name, err)
+		}
+	}
+
+	logger.Info("Applied port forwarding to '%s': %d added, %d removed", containerName, len(add), len(remove))
+	if len(deviceChanges) > 0 {
+		logger.Info("Added or updated %d device(s) of '%s'", len(deviceChanges), containerName)
+	}
+	return nil
+}
+
+// deviceChange is a device of a ports file that a container lacks, or has
+// with other settings
+type deviceChange struct {
+	Name string
+	Type string
+	// Settings are all settings but the type of a new device, or the
+	// changed settings of an existing one
+	Settings map[string]string
+	New      bool
+}
+
+func (c deviceChange) String() string {
+	if c.New {
+		return fmt.Sprintf("+ device %s (%s) %s", c.Name, c.Type, strings.Join(c.settingArgs(), " "))
+	}
+	return fmt.Sprintf("~ device %s %s", c.Name, strings.Join(c.settingArgs(), " "))
+}
+
+func (c deviceChange) verb() string {
+	if c.New {
+		return "add"
+	}
+	return "update"
+}
+
+// settingArgs returns the settings as key=value arguments in key order
+func (c deviceChange) settingArgs() []string {
+	keys := make([]string, 0, len(c.Settings))
+	for key := range c.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key+"="+c.Settings[key])
+	}
+	return args
+}
+
+// command returns the lxc command that makes the change
+func (c deviceChange) command(containerName string) []string {
+	if c.New {
+		return append([]string{"lxc", "config", "device", "add", containerName, c.Name, c.Type}, c.settingArgs()...)
+	}
+	return append([]string{"lxc", "config", "device", "set", containerName, c.Name}, c.settingArgs()...)
+}
+
+// currentSpecDevices returns a container's devices other than the port
+// forwarding this tool manages, as a ports file lists them
+func currentSpecDevices(configData []byte, containerName string) (map[string]map[string]string, error) {
+	var config struct {
+		Devices map[string]map[string]string `yaml:"devices"`
+	}
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+	devices := make(map[string]map[string]string)
+	for name, settings := range config.Devices {
+		if isPortDevice(name, containerName) || isReversePortDevice(name, containerName) {
+			continue
+		}
+		devices[name] = settings
+	}
+	return devices, nil
+}
+
+// diffSpecDevices compares the devices of a ports file with a container's.
+// Devices that are not in the file are left alone, and a device cannot
+// change its type.
+func diffSpecDevices(current, desired map[string]map[string]string) (changes []deviceChange, unchanged []string, err error) {
+	for _, name := range sortedDeviceNames(desired) {
+		settings := desired[name]
+		have, ok := current[name]
+		if !ok {
+			change := deviceChange{Name: name, Type: settings["type"], Settings: make(map[string]string), New: true}
+			for key, value := range settings {
+				if key != "type" {
+					change.Settings[key] = value
+				}
+			}
+			changes = append(changes, change)
+			continue
+		}
+		if have["type"] != settings["type"] {
+			return nil, nil, fmt.Errorf("device '%s' is a %s device, not %s; remove it first", name, have["type"], settings["type"])
+		}
+		changed := make(map[string]string)
+		for key, value := range settings {
+			if have[key] != value {
+				changed[key] = value
+			}
+		}
+		if len(changed) == 0 {
+			unchanged = append(unchanged, name)
+			continue
+		}
+		changes = append(changes, deviceChange{Name: name, Type: settings["type"], Settings: changed})
+	}
+	return changes, unchanged, nil
+}
+
+// listedPortRules keeps the current rules whose device is also desired, so
+// a diff only replaces changed rules and removes nothing else
+func listedPortRules(current, desired []portRule) []portRule {
+	wanted := make(map[string]bool, len(desired))
+	for _, rule := range desired {
+		wanted[rule.DeviceName] = true
+	}
+	var listed []portRule
+	for _, rule := range current {
+		if wanted[rule.DeviceName] {
+			listed = append(listed, rule)
+		}
+	}
+	return listed
+}
+
+// portSpecFromRules turns rules back into a spec, merging the TCP and UDP
+// rules of a mapping into protocol both and leaving defaults out
+func portSpecFromRules(rules []portRule) *PortSpec {
+	type mapping struct {
+		host, container int
+		listen          string
+	}
+	protocols := make(map[mapping][]string)
+	var order []mapping
+	for _, rule := range rules {
+		host, _ := strconv.Atoi(rule.HostPort)
+		container, _ := strconv.Atoi(rule.ContainerPort)
+		key := mapping{host, container, rule.ListenIP}
+		if _, ok := protocols[key]; !ok {
+			order = append(order, key)
+		}
+		protocols[key] = append(protocols[key], rule.Protocol)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].host != order[j].host {
+			return order[i].host < order[j].host
+		}
+		if order[i].container != order[j].container {
+			return order[i].container < order[j].container
+		}
+		return order[i].listen < order[j].listen
+	})
+
+	spec := &PortSpec{Ports: []PortSpecEntry{}}
+	for _, key := range order {
+		entry := PortSpecEntry{Host: key.host, Container: key.container}
+		switch {
+		case len(protocols[key]) > 1:
+			entry.Protocol = "both"
+		case protocols[key][0] != "tcp":
+			entry.Protocol = protocols[key][0]
+		}
+		if key.listen != "0.0.0.0" {
+			entry.Listen = key.listen
+		}
+		spec.Ports = append(spec.Ports, entry)
+	}
+	return spec
+}
+
+// exportPortSpec prints the port forwarding rules this tool created on a
+// container as a ports file
+func exportPortSpec(ctx context.Context, manager ContainerPortManager, containerName string, out io.Writer) error {
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+	configData, err := manager.GetContainerConfig(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container configuration: %w", err)
+	}
+	rules, err := currentPortRules(configData, containerName)
+	if err != nil {
+		return err
+	}
+
+	devices, err := currentSpecDevices(configData, containerName)
+	if err != nil {
+		return err
+	}
+
+	var config ContainerConfig
+	if err := yaml.Unmarshal(configData, &config); err == nil {
+		for deviceName := range config.Devices {
+			if isReversePortDevice(deviceName, containerName) {
+				logger.Warn("Reverse mapping '%s' is not exported; add it again with 'port add --reverse'", deviceName)
+			}
+		}
+	}
+
+	spec := portSpecFromRules(rules)
+	if len(devices) > 0 {
+		spec.Devices = devices
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode port forwarding: %w", err)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// PortCheckManager interface for dependency injection
+type PortCheckManager interface {
+	IsPortAvailable(port int, protocol string) bool
+	GetPortHolders(ctx context.Context, port int, protocol string) ([]helpers.PortHolder, error)
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+}
+
+// DefaultPortCheckManager implements PortCheckManager using helpers
+type DefaultPortCheckManager struct{}
+
+func (d *DefaultPortCheckManager) IsPortAvailable(port int, protocol string) bool {
+	return helpers.IsPortAvailable(port, protocol)
+}
+
+func (d *DefaultPortCheckManager) GetPortHolders(ctx context.Context, port int, protocol string) ([]helpers.PortHolder, error) {
+	return helpers.GetPortHolders(ctx, port, protocol)
+}
+
+func (d *DefaultPortCheckManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+// portClaim is a container proxy device listening on a host port
+type portClaim struct {
+	Container string
+	Status    string
+	Device    string
+	Listen    string
+}
+
+// findPortClaims returns the proxy devices that listen on the given host port
+func findPortClaims(states []helpers.ContainerState, port int, protocol string) []portClaim {
+	var claims []portClaim
+	for _, state := range states {
+		for name, device := range state.Devices {
+			// Reverse devices listen inside the container
+			if device["type"] != "proxy" || device["bind"] == "container" {
+				continue
+			}
+			proto, ip, listenPort, ok := parseProxyEndpoint(device["listen"])
+			if !ok || proto != protocol || listenPort != strconv.Itoa(port) {
+				continue
+			}
+			claims = append(claims, portClaim{Container: state.Name, Status: state.Status, Device: name, Listen: ip})
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Container != claims[j].Container {
+			return claims[i].Container < claims[j].Container
+		}
+		return claims[i].Device < claims[j].Device
+	})
+	return claims
+}
+
+// checkPort reports host listeners and container claims for a host port
+func checkPort(ctx context.Context, manager PortCheckManager, hostPort, protocol string, out io.Writer) error {
+	port, err := strconv.Atoi(hostPort)
+	if err != nil || port < 1 || port > 65535 {
+		return helpers.UsageErrorf("host port must be a number between 1 and 65535, got '%s'", hostPort)
+	}
+
+	var protocols []string
+	switch protocol {
+	case "tcp", "udp":
+		protocols = []string{protocol}
+	case "both":
+		protocols = []string{"tcp", "udp"}
+	default:
+		return helpers.UsageErrorf("protocol must be 'tcp', 'udp', or 'both', got '%s'", protocol)
+	}
+
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	style := render.StyleFor(out)
+	busy := false
+	for _, proto := range protocols {
+		available := manager.IsPortAvailable(port, proto)
+		claims := findPortClaims(states, port, proto)
+
+		if available {
+			fmt.Fprintf(out, "%sPort %d/%s: free on the host\n", checkMark(style, len(claims) == 0), port, proto)
+		} else {
+			fmt.Fprintf(out, "%sPort %d/%s: in use on the host\n", checkMark(style, false), port, proto)
+			holders, err := manager.GetPortHolders(ctx, port, proto)
+			if err != nil {
+				logger.Debug("Could not look up the process on port %d/%s: %v", port, proto, err)
+			}
+			for _, holder := range holders {
+				fmt.Fprintf(out, "  Process:   %s\n", holder)
+			}
+			if len(holders) == 0 {
+				fmt.Fprintf(out, "  %s\n", helpers.GetPortUsageInfo(port))
+			} else if holders[0].Process == "" {
+				fmt.Fprintln(out, "  Run as root to see which process holds the port")
+			}
+		}
+
+		for _, claim := range claims {
+			fmt.Fprintf(out, "  Container: %s (%s), device %s listening on %s\n",
+				claim.Container, strings.ToLower(claim.Status), claim.Device, claim.Listen)
+		}
+		if !available || len(claims) > 0 {
+			busy = true
+		}
+	}
+
+	if busy {
+		return &ExitError{Code: 1, Err: fmt.Errorf("port %d is in use", port)}
+	}
+	return nil
+}
+
+// PortStatsManager interface for dependency injection
+type PortStatsManager interface {
+	ContainerPortManager
+	GetPortTraffic(ctx context.Context, port int, protocol string, nat bool) (helpers.PortTraffic, error)
+}
+
+// DefaultPortStatsManager implements PortStatsManager using helpers
+type DefaultPortStatsManager struct {
+	DefaultContainerPortManager
+}
+
+func (d *DefaultPortStatsManager) GetPortTraffic(ctx context.Context, port int, protocol string, nat bool) (helpers.PortTraffic, error) {
+	return helpers.GetPortTraffic(ctx, port, protocol, nat)
+}
+
+// portStat is the traffic through one forwarded port; Err is set when it
+// could not be read
+type portStat struct {
+	Mapping PortMapping
+	Traffic helpers.PortTraffic
+	Err     error
+}
+
+// showPortStats prints the traffic through each forwarded port of a
+// container, busiest first
+func showPortStats(ctx context.Context, manager PortStatsManager, containerName string, table render.Options, out io.Writer) error {
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+	configData, err := manager.GetContainerConfig(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container configuration: %w", err)
+	}
+	mappings, err := parsePortMappingsFromConfig(configData, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to parse port mappings: %w", err)
+	}
+
+	var stats []portStat
+	for _, mapping := range mappings {
+		if mapping.Reverse {
+			continue
+		}
+		stat := portStat{Mapping: mapping}
+		port, err := strconv.Atoi(mapping.HostPort)
+		if err != nil {
+			stat.Err = helpers.UsageErrorf("invalid host port '%s'", mapping.HostPort)
+		} else {
+			stat.Traffic, stat.Err = manager.GetPortTraffic(ctx, port, mapping.Protocol, mapping.NAT)
+		}
+		stats = append(stats, stat)
+	}
+	if len(stats) == 0 {
+		fmt.Fprintf(out, "No port forwarding rules found for container '%s'\n", containerName)
+		return nil
+	}
+	sortPortStats(stats)
+
+	failed := 0
+	uncounted := false
+	t := render.NewTable("DEVICE", "PROTOCOL", "HOST PORT", "CONTAINER PORT", "CONNECTIONS", "BYTES IN", "BYTES OUT", "SOURCE")
+	for _, stat := range stats {
+		m := stat.Mapping
+		if stat.Err != nil {
+			logger.Warn("Could not read the traffic of '%s': %v", m.DeviceName, stat.Err)
+			failed++
+			t.AddRow(m.DeviceName, m.Protocol, m.HostPort, m.ContainerPort, "-", "-", "-", "-")
+			continue
+		}
+		in, out := "-", "-"
+		if stat.Traffic.Bytes {
+			in, out = helpers.FormatBytes(stat.Traffic.BytesIn), helpers.FormatBytes(stat.Traffic.BytesOut)
+		} else {
+			uncounted = true
+		}
+		t.AddRow(m.DeviceName, m.Protocol, m.HostPort, m.ContainerPort, strconv.Itoa(stat.Traffic.Connections), in, out, stat.Traffic.Source)
+	}
+	if err := t.Render(out, table); err != nil {
+		return err
+	}
+	if uncounted {
+		fmt.Fprintf(out, "conntrack does not count bytes; turn accounting on with: %s\n", helpers.ConntrackAccountingHint)
+	}
+	if failed == len(stats) {
+		return fmt.Errorf("could not read the traffic of any forwarded port")
+	}
+	return nil
+}
+
+// sortPortStats orders stats by bytes through the port, then connections,
+// busiest first
+func sortPortStats(stats []portStat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i].Traffic, stats[j].Traffic
+		if a.BytesIn+a.BytesOut != b.BytesIn+b.BytesOut {
+			return a.BytesIn+a.BytesOut > b.BytesIn+b.BytesOut
+		}
+		if a.Connections != b.Connections {
+			return a.Connections > b.Connections
+		}
+		return stats[i].Mapping.DeviceName < stats[j].Mapping.DeviceName
+	})
 }
 
 func init() {
@@ -417,11 +2045,41 @@ func init() {
 	// Add subcommands
 	portCmd.AddCommand(portAddCmd)
 	portCmd.AddCommand(portListCmd)
+	portCmd.AddCommand(portApplyCmd)
+	portCmd.AddCommand(portExportCmd)
+	portCmd.AddCommand(portImportCmd)
+	portCmd.AddCommand(portValidateCmd)
+	portCmd.AddCommand(portSchemaCmd)
+	portCmd.AddCommand(portCheckCmd)
+	portCmd.AddCommand(portStatsCmd)
 
 	// Add timeout flag to both subcommands
 	portAddCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
 	portListCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	addTableFlags(portListCmd, &portListTable)
+	portListCmd.Flags().BoolVar(&portListCheck, "check", false, "Show whether each host port is bound and reachable (slower)")
+	portApplyCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	portCheckCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	portStatsCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	addTableFlags(portStatsCmd, &portStatsTable)
 
 	// Add force flag to port add command
 	portAddCmd.Flags().BoolVarP(&forcePort, "force", "f", false, "Force port mapping creation even if port appears to be in use")
+	portAddCmd.Flags().BoolVar(&reversePort, "reverse", false, "Forward a port inside the container to a service on the host")
+	portAddCmd.Flags().StringVar(&portInterface, "interface", "", "Listen only on the addresses of this host network interface, e.g. eth1")
+
+	portApplyCmd.Flags().StringVarP(&portApplyFile, "file", "f", "", "YAML file declaring the port mappings (required)")
+	portApplyCmd.Flags().BoolVar(&portDryRun, "dry-run", false, "Only show the differences")
+	portApplyCmd.Flags().BoolVar(&forcePort, "force", false, "Add mappings even if the host port appears to be in use")
+	portApplyCmd.MarkFlagRequired("file")
+
+	portExportCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	portImportCmd.Flags().DurationVarP(&portTimeout, "timeout", "t", 30*time.Second, "Timeout for the port configuration operation")
+	portImportCmd.Flags().StringVarP(&portImportFile, "file", "f", "", "YAML file with the port mappings, or - for stdin (required)")
+	portImportCmd.Flags().BoolVar(&portDryRun, "dry-run", false, "Only show the differences")
+	portImportCmd.Flags().BoolVar(&forcePort, "force", false, "Add mappings even if the host port appears to be in use")
+	portImportCmd.MarkFlagRequired("file")
+
+	portValidateCmd.Flags().StringVarP(&portValidateFile, "file", "f", "", "YAML file to check, or - for stdin (required)")
+	portValidateCmd.MarkFlagRequired("file")
 }
@@ -1,11 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
 )
 
 // MockContainerPortManager for testing port command
@@ -13,6 +22,10 @@ type MockContainerPortManager struct {
 	ContainerExistsFunc    func(ctx context.Context, name string) bool
 	RunLXCCommandFunc      func(ctx context.Context, args ...string) error
 	GetContainerConfigFunc func(ctx context.Context, containerName string) ([]byte, error)
+	Statuses               map[string]string
+	Types                  map[string]string
+	PinnedAddresses        map[string]string
+	Interfaces             map[string][]string
 	ExistingContainers     map[string]bool
 	RunCommandError        error
 	GetConfigError         error
@@ -61,6 +74,38 @@ func (m *MockContainerPortManager) GetContainerConfig(ctx context.Context, conta
 	return []byte("devices: {}"), nil
 }
 
+func (m *MockContainerPortManager) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	m.trackCall("GetContainerStatus")
+	if status, ok := m.Statuses[name]; ok {
+		return status, nil
+	}
+	return "Running", nil
+}
+
+func (m *MockContainerPortManager) GetInstanceType(ctx context.Context, name string) (string, error) {
+	m.trackCall("GetInstanceType")
+	if instanceType, ok := m.Types[name]; ok {
+		return instanceType, nil
+	}
+	return helpers.InstanceTypeContainer, nil
+}
+
+func (m *MockContainerPortManager) PinInstanceAddress(ctx context.Context, name string) (string, error) {
+	m.trackCall("PinInstanceAddress")
+	if address, ok := m.PinnedAddresses[name]; ok {
+		return address, nil
+	}
+	return "", fmt.Errorf("VM '%s' has no IPv4 address yet", name)
+}
+
+func (m *MockContainerPortManager) InterfaceAddresses(name string) ([]string, error) {
+	m.trackCall("InterfaceAddresses")
+	if addresses, ok := m.Interfaces[name]; ok {
+		return addresses, nil
+	}
+	return nil, fmt.Errorf("unknown network interface '%s'", name)
+}
+
 func (m *MockContainerPortManager) trackCall(method string) {
 	if m.Calls == nil {
 		m.Calls = make(map[string]int)
@@ -82,8 +127,8 @@ func TestPortCommand(t *testing.T) {
 	}
 
 	// Test port command properties
-	if portCmd.Use != "port <add|list>" {
-		t.Errorf("expected 'port <add|list>', got '%s'", portCmd.Use)
+	if portCmd.Use != "port <add|list|apply|export|import|validate|schema|check|stats>" {
+		t.Errorf("expected 'port <add|list|apply|export|import|validate|schema|check|stats>', got '%s'", portCmd.Use)
 	}
 
 	if portCmd.Short == "" {
@@ -109,7 +154,7 @@ func TestPortCommand(t *testing.T) {
 	}
 
 	// Test port list command properties
-	if portListCmd.Use != "list <container-name>" {
+	if portListCmd.Use != "list [container-name]" {
 		t.Errorf("expected specific Use format for list, got '%s'", portListCmd.Use)
 	}
 }
@@ -154,15 +199,15 @@ func TestPortAddCommandArgs(t *testing.T) {
 }
 
 func TestPortListCommandArgs(t *testing.T) {
-	// Test that the port list command expects exactly 1 argument
+	// Test that the port list command expects at most 1 argument
 	if portListCmd.Args == nil {
 		t.Error("portListCmd should have Args validation")
 	}
 
-	// Test with wrong number of args
+	// Test with no args (should pass, using the current container)
 	err := portListCmd.Args(portListCmd, []string{})
-	if err == nil {
-		t.Error("should fail with no arguments")
+	if err != nil {
+		t.Errorf("should pass with no arguments: %v", err)
 	}
 
 	err = portListCmd.Args(portListCmd, []string{"container", "extra"})
@@ -489,6 +534,124 @@ func TestConfigurePortForwardingForProtocol(t *testing.T) {
 	}
 }
 
+func TestConfigurePortForwardingVM(t *testing.T) {
+	ctx := context.Background()
+	var commands [][]string
+	manager := &MockContainerPortManager{
+		ExistingContainers: map[string]bool{"vm": true},
+		Types:              map[string]string{"vm": helpers.InstanceTypeVM},
+		PinnedAddresses:    map[string]string{"vm": "10.0.0.5"},
+		RunLXCCommandFunc: func(ctx context.Context, args ...string) error {
+			commands = append(commands, args)
+			return nil
+		},
+	}
+
+	if err := configurePortForwardingForProtocol(ctx, manager, "vm", "8080", "80", "tcp", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "lxc config device add vm vm-8080-80-tcp proxy nat=true connect=tcp:10.0.0.5:80 listen=tcp:0.0.0.0:8080"
+	if len(commands) != 1 || strings.Join(commands[0], " ") != expected {
+		t.Errorf("expected a NAT proxy to the pinned address, got %v", commands)
+	}
+
+	// Without an address there is nothing to forward to
+	manager.PinnedAddresses = nil
+	if err := configurePortForwardingForProtocol(ctx, manager, "vm", "8080", "80", "tcp", true); err == nil || !strings.Contains(err.Error(), "no IPv4 address") {
+		t.Errorf("expected an address error, got %v", err)
+	}
+	if err := configureReversePortForwarding(ctx, manager, "vm", "5432", "5432", "tcp"); err == nil || !strings.Contains(err.Error(), "is a VM") {
+		t.Errorf("expected reverse forwarding to a VM to be rejected, got %v", err)
+	}
+}
+
+func TestConfigureInterfacePortForwarding(t *testing.T) {
+	ctx := context.Background()
+	var commands []string
+	manager := &MockContainerPortManager{
+		ExistingContainers: map[string]bool{"web": true},
+		Interfaces: map[string][]string{
+			"eth0": {"192.0.2.10"},
+			"eth1": {"198.51.100.7", "2001:db8::7"},
+		},
+		RunLXCCommandFunc: func(ctx context.Context, args ...string) error {
+			commands = append(commands, strings.Join(args, " "))
+			return nil
+		},
+	}
+
+	if err := configureInterfacePortForwarding(ctx, manager, "web", "443", "443", "tcp", "eth0", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := configureInterfacePortForwarding(ctx, manager, "web", "53", "53", "udp", "eth1", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{
+		"lxc config device add web web-443-443-tcp proxy connect=tcp:0.0.0.0:443 listen=tcp:192.0.2.10:443",
+		"lxc config device add web web-53-53-udp-198-51-100-7 proxy connect=udp:0.0.0.0:53 listen=udp:198.51.100.7:53",
+		"lxc config device add web web-53-53-udp-2001-db8--7 proxy connect=udp:0.0.0.0:53 listen=udp:[2001:db8::7]:53",
+	}
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(commands, "\n"))
+	}
+
+	if err := configureInterfacePortForwarding(ctx, manager, "web", "443", "443", "tcp", "wlan9", true); err == nil || !strings.Contains(err.Error(), "unknown network interface") {
+		t.Errorf("expected an unknown interface error, got %v", err)
+	}
+	if err := configureInterfacePortForwarding(ctx, manager, "db", "443", "443", "tcp", "eth0", true); err == nil {
+		t.Error("expected error for a missing container")
+	}
+}
+
+func TestPortMappingsOnSeveralAddresses(t *testing.T) {
+	config := []byte(`devices:
+  web-53-53-udp-198-51-100-7:
+    type: proxy
+    connect: udp:0.0.0.0:53
+    listen: udp:198.51.100.7:53
+  web-53-53-udp-2001-db8--7:
+    type: proxy
+    connect: udp:0.0.0.0:53
+    listen: udp:[2001:db8::7]:53
+`)
+	mappings, err := parsePortMappingsFromConfig(config, "web")
+	if err != nil || len(mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %v (%v)", mappings, err)
+	}
+	for _, mapping := range mappings {
+		if mapping.HostPort != "53" || mapping.ContainerPort != "53" || mapping.Protocol != "UDP" {
+			t.Errorf("unexpected mapping %+v", mapping)
+		}
+		if mapping.DeviceName == "web-53-53-udp-2001-db8--7" && mapping.ContainerIP != "2001:db8::7" {
+			t.Errorf("expected the IPv6 listen address, got %+v", mapping)
+		}
+	}
+
+	// Exporting and applying the same rules changes nothing
+	current, err := currentPortRules(config, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := portSpecFromRules(current)
+	if len(spec.Ports) != 2 || len(portSpecDuplicates(spec)) != 0 {
+		t.Fatalf("expected two valid entries, got %+v", spec.Ports)
+	}
+	desired, err := desiredPortRules("web", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add, remove, _ := diffPortRules(current, desired)
+	if len(add) != 0 || len(remove) != 0 {
+		t.Errorf("expected no changes, got add %v remove %v", add, remove)
+	}
+
+	// All addresses and a specific one cannot share a port
+	spec = &PortSpec{Ports: []PortSpecEntry{{Host: 53, Container: 53}, {Host: 53, Container: 53, Listen: "198.51.100.7"}}}
+	if _, err := desiredPortRules("web", spec); err == nil || len(portSpecDuplicates(spec)) != 1 {
+		t.Error("expected a port on all addresses and on one address to be rejected")
+	}
+}
+
 func TestConfigurePortForwardingBothProtocols(t *testing.T) {
 	ctx := context.Background()
 	commandHistory := make([][]string, 0)
@@ -646,16 +809,16 @@ func TestPortForwardingEdgeCases(t *testing.T) {
 
 func TestPortForwardingPartialFailure(t *testing.T) {
 	ctx := context.Background()
-	callCount := 0
+	var commands []string
 
 	manager := &MockContainerPortManager{
 		ExistingContainers: map[string]bool{
 			"test-container": true,
 		},
 		RunLXCCommandFunc: func(ctx context.Context, args ...string) error {
-			callCount++
+			commands = append(commands, strings.Join(args, " "))
 			// Fail on the second call (UDP) when protocol is "both"
-			if callCount == 2 {
+			if len(commands) == 2 {
 				return fmt.Errorf("second command failed")
 			}
 			return nil
@@ -665,12 +828,26 @@ func TestPortForwardingPartialFailure(t *testing.T) {
 	// Test that if UDP fails when protocol is "both", the whole operation fails
 	err := configurePortForwarding(ctx, manager, "test-container", "8080", "80", "both", false)
 	if err == nil {
-		t.Error("should fail when second command fails")
+		t.Fatal("should fail when second command fails")
 	}
 
 	if !contains(err.Error(), "failed to configure udp port forwarding") {
 		t.Errorf("error should indicate UDP failure: %v", err)
 	}
+	if len(commands) != 3 || commands[2] != "lxc config device remove test-container test-container-8080-80-tcp" {
+		t.Errorf("expected the TCP device to be removed again, got %v", commands)
+	}
+
+	// --keep-partial leaves the TCP device in place
+	commands = nil
+	keepPartial = true
+	defer func() { keepPartial = false }()
+	if err := configurePortForwarding(ctx, manager, "test-container", "8080", "80", "both", false); err == nil {
+		t.Fatal("should fail when second command fails")
+	}
+	if len(commands) != 2 {
+		t.Errorf("expected the TCP device to be kept, got %v", commands)
+	}
 }
 
 func TestPortCommandWithDefaultProtocol(t *testing.T) {
@@ -775,6 +952,10 @@ func TestPortAddCommandFlags(t *testing.T) {
 	if forceFlag.DefValue != "false" {
 		t.Errorf("expected force flag default to be 'false', got '%s'", forceFlag.DefValue)
 	}
+
+	if portAddCmd.Flags().Lookup("interface") == nil {
+		t.Error("interface flag should exist on portAddCmd")
+	}
 }
 
 func TestPortAvailabilityIntegration(t *testing.T) {
@@ -873,7 +1054,7 @@ func TestListPortForwarding(t *testing.T) {
 				GetConfigError: tt.configError,
 			}
 
-			err := listPortForwarding(ctx, manager, tt.containerName)
+			err := listPortForwarding(ctx, manager, tt.containerName, render.Options{}, nil)
 
 			if tt.expectedError != "" {
 				if err == nil {
@@ -888,6 +1069,24 @@ func TestListPortForwarding(t *testing.T) {
 	}
 }
 
+func TestListPortForwardingFrozen(t *testing.T) {
+	manager := &MockContainerPortManager{
+		ExistingContainers: map[string]bool{"test-container": true},
+		ContainerConfigs:   map[string][]byte{"test-container": []byte("devices: {}")},
+		Statuses:           map[string]string{"test-container": helpers.StatusFrozen},
+	}
+
+	if err := listPortForwarding(context.Background(), manager, "test-container", render.Options{}, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.GetCallCount("GetContainerStatus") != 1 {
+		t.Error("port list should check whether the container is paused")
+	}
+	if !contains(frozenNotice("test-container"), "lxc-go-cli resume test-container") {
+		t.Error("frozen notice should explain how to resume")
+	}
+}
+
 func TestParsePortMappingsFromConfig(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -929,6 +1128,26 @@ func TestParsePortMappingsFromConfig(t *testing.T) {
 			containerName: "test-container",
 			expectedCount: 2, // Only devices matching container name
 		},
+		{
+			name: "adopted devices",
+			yamlData: `config:
+  user.lxc-go-cli.ports: web,broken
+devices:
+  web:
+    type: proxy
+    listen: tcp:[::]:8080
+    connect: tcp:127.0.0.1:80
+  broken:
+    type: proxy
+    listen: unix:/run/app.sock
+    connect: unix:/run/app.sock
+  unregistered:
+    type: proxy
+    listen: tcp:0.0.0.0:9090
+    connect: tcp:127.0.0.1:90`,
+			containerName: "test-container",
+			expectedCount: 1, // Only registered devices with TCP/UDP addresses
+		},
 	}
 
 	for _, tt := range tests {
@@ -950,6 +1169,92 @@ func TestParsePortMappingsFromConfig(t *testing.T) {
 	}
 }
 
+func TestParseAdoptedPortMapping(t *testing.T) {
+	mapping, err := parseAdoptedPortMapping("web", Device{Type: "proxy", Listen: "tcp:[::]:8080", Connect: "tcp:127.0.0.1:80"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mapping.Protocol != "TCP" || mapping.HostPort != "8080" || mapping.ContainerPort != "80" ||
+		mapping.HostIP != "::" || mapping.ContainerIP != "127.0.0.1" {
+		t.Errorf("unexpected mapping: %+v", mapping)
+	}
+
+	if _, err := parseAdoptedPortMapping("sock", Device{Type: "proxy", Listen: "unix:/run/a.sock", Connect: "tcp:127.0.0.1:80"}); err == nil {
+		t.Error("expected error for unix listen address")
+	}
+}
+
+func TestConfigureReversePortForwarding(t *testing.T) {
+	ctx := context.Background()
+	mock := &MockContainerPortManager{ExistingContainers: map[string]bool{"app": true}}
+	var commands [][]string
+	mock.RunLXCCommandFunc = func(ctx context.Context, args ...string) error {
+		commands = append(commands, args)
+		return nil
+	}
+
+	if err := configureReversePortForwarding(ctx, mock, "app", "5432", "15432", "both"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("expected a tcp and a udp device, got %v", commands)
+	}
+	expected := "lxc config device add app app-rev-5432-15432-tcp proxy bind=container listen=tcp:127.0.0.1:5432 connect=tcp:127.0.0.1:15432"
+	if got := strings.Join(commands[0], " "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if err := configureReversePortForwarding(ctx, mock, "missing", "5432", "5432", "tcp"); err == nil {
+		t.Error("expected error for missing container")
+	}
+	if err := configureReversePortForwarding(ctx, mock, "app", "5432", "70000", "tcp"); err == nil {
+		t.Error("expected error for invalid host port")
+	}
+
+	mock.RunLXCCommandFunc = nil
+	mock.RunCommandError = fmt.Errorf("device already exists")
+	if err := configureReversePortForwarding(ctx, mock, "app", "5432", "5432", "tcp"); err == nil || !strings.Contains(err.Error(), "reverse") {
+		t.Errorf("expected reverse forwarding error, got %v", err)
+	}
+}
+
+func TestReversePortMappings(t *testing.T) {
+	devices := map[string]Device{
+		"app-rev-5432-15432-tcp": {Type: "proxy", Bind: "container", Listen: "tcp:127.0.0.1:5432", Connect: "tcp:127.0.0.1:15432"},
+		"app-8080-80-tcp":        {Type: "proxy", Listen: "tcp:0.0.0.0:8080", Connect: "tcp:0.0.0.0:80"},
+		// Without bind=container the device is not one of ours
+		"app-rev-1-2-tcp": {Type: "proxy", Listen: "tcp:127.0.0.1:1", Connect: "tcp:127.0.0.1:2"},
+	}
+	mappings := portMappingsFromDevices(nil, devices, "app")
+	if len(mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %+v", mappings)
+	}
+
+	var reverse *PortMapping
+	for i := range mappings {
+		if mappings[i].Reverse {
+			reverse = &mappings[i]
+		}
+	}
+	if reverse == nil || reverse.HostPort != "15432" || reverse.ContainerPort != "5432" || reverse.HostIP != "127.0.0.1" {
+		t.Fatalf("unexpected reverse mapping: %+v", reverse)
+	}
+	if !strings.Contains(formatPortMappings([]PortMapping{*reverse}), "app-rev-5432-15432-tcp (container -> host)") {
+		t.Error("expected the reverse direction to be shown")
+	}
+
+	// A reverse device does not claim a host port and is not managed by port apply
+	states := []helpers.ContainerState{{Name: "app", Devices: map[string]map[string]string{
+		"app-rev-5432-15432-tcp": {"type": "proxy", "bind": "container", "listen": "tcp:127.0.0.1:5432"},
+	}}}
+	if claims := findPortClaims(states, 5432, "tcp"); len(claims) != 0 {
+		t.Errorf("expected no claims, got %+v", claims)
+	}
+	if isPortDevice("app-rev-5432-15432-tcp", "app") {
+		t.Error("reverse devices must not match the forward naming convention")
+	}
+}
+
 func TestIsPortDevice(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -969,6 +1274,12 @@ func TestIsPortDevice(t *testing.T) {
 			containerName: "test-container",
 			expected:      true,
 		},
+		{
+			name:          "device on one of several addresses",
+			deviceName:    "test-container-8080-80-tcp-192-0-2-10",
+			containerName: "test-container",
+			expected:      true,
+		},
 		{
 			name:          "wrong container name",
 			deviceName:    "other-container-8080-80-tcp",
@@ -1120,7 +1431,7 @@ func TestFormatPortMappings(t *testing.T) {
 					ContainerIP:   "0.0.0.0",
 				},
 			},
-			expected: "PROTOCOL  HOST PORT  CONTAINER PORT  HOST IP      CONTAINER IP  DEVICE NAME",
+			expected: "PROTOCOL  HOST PORT  CONTAINER PORT  HOST IP  CONTAINER IP  DEVICE NAME\nTCP       8080       80              0.0.0.0  0.0.0.0       test-container-8080-80-tcp\n",
 		},
 		{
 			name: "multiple mappings",
@@ -1142,7 +1453,9 @@ func TestFormatPortMappings(t *testing.T) {
 					ContainerIP:   "192.168.1.1",
 				},
 			},
-			expected: "PROTOCOL  HOST PORT  CONTAINER PORT  HOST IP      CONTAINER IP  DEVICE NAME",
+			expected: "HOST IP    CONTAINER IP  DEVICE NAME\n" +
+				"TCP       8080       80              0.0.0.0    0.0.0.0       test-container-8080-80-tcp\n" +
+				"UDP       5432       5432            127.0.0.1  192.168.1.1   test-container-5432-5432-udp\n",
 		},
 	}
 
@@ -1161,3 +1474,630 @@ func TestFormatPortMappings(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePortSpec(t *testing.T) {
+	spec, err := parsePortSpec([]byte(`ports:
+  - host: 8080
+    container: 80
+  - host: 5353
+    container: 53
+    protocol: both
+    listen: 127.0.0.1
+`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rules, err := desiredPortRules("web", spec)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("expected 3 rules, got %v", rules)
+	}
+	if rules[0].DeviceName != "web-8080-80-tcp" || rules[0].ListenIP != "0.0.0.0" {
+		t.Errorf("unexpected default rule: %+v", rules[0])
+	}
+	if rules[2].DeviceName != "web-5353-53-udp" || rules[2].ListenIP != "127.0.0.1" {
+		t.Errorf("unexpected udp rule: %+v", rules[2])
+	}
+
+	invalid := map[string]string{
+		"bad port":        "ports:\n  - host: 0\n    container: 80\n",
+		"bad protocol":    "ports:\n  - host: 80\n    container: 80\n    protocol: sctp\n",
+		"bad listen":      "ports:\n  - host: 80\n    container: 80\n    listen: localhost\n",
+		"unknown field":   "ports:\n  - host: 80\n    container: 80\n    hostport: 1\n",
+		"not a port list": "ports: 8080\n",
+	}
+	for name, data := range invalid {
+		if _, err := parsePortSpec([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	spec, _ = parsePortSpec([]byte("ports:\n  - {host: 80, container: 80}\n  - {host: 80, container: 8080, protocol: both}\n"))
+	if _, err := desiredPortRules("web", spec); err == nil || !contains(err.Error(), "more than once") {
+		t.Errorf("expected duplicate host port error, got %v", err)
+	}
+}
+
+func TestApplyPortSpec(t *testing.T) {
+	current := `devices:
+  web-8080-80-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:80
+    listen: tcp:0.0.0.0:8080
+  web-9000-9000-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:9000
+    listen: tcp:0.0.0.0:9000
+  web-5432-5432-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:5432
+    listen: tcp:0.0.0.0:5432
+  custom:
+    type: proxy
+    connect: tcp:0.0.0.0:22
+    listen: tcp:0.0.0.0:2222
+`
+	spec, err := parsePortSpec([]byte(`ports:
+  - {host: 8080, container: 80}
+  - {host: 5432, container: 5432, listen: 127.0.0.1}
+  - {host: 3000, container: 3000}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newManager := func() (*MockContainerPortManager, *[][]string) {
+		var commands [][]string
+		manager := &MockContainerPortManager{
+			ExistingContainers: map[string]bool{"web": true},
+			ContainerConfigs:   map[string][]byte{"web": []byte(current)},
+			Calls:              make(map[string]int),
+		}
+		manager.RunLXCCommandFunc = func(ctx context.Context, args ...string) error {
+			commands = append(commands, args)
+			return nil
+		}
+		return manager, &commands
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		manager, commands := newManager()
+		var out strings.Builder
+		if err := applyPortSpec(context.Background(), manager, "web", spec, portApplyOptions{DryRun: true}, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(*commands) != 0 {
+			t.Errorf("dry run should not change anything, got %v", *commands)
+		}
+		expected := "- tcp 0.0.0.0:5432 -> 5432\n" +
+			"- tcp 0.0.0.0:9000 -> 9000\n" +
+			"+ tcp 0.0.0.0:3000 -> 3000\n" +
+			"+ tcp 127.0.0.1:5432 -> 5432\n" +
+			"  tcp 0.0.0.0:8080 -> 80\n" +
+			"Dry run: 2 to add, 2 to remove\n"
+		if out.String() != expected {
+			t.Errorf("unexpected diff:\n%s\nexpected:\n%s", out.String(), expected)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		manager, commands := newManager()
+		var out strings.Builder
+		if err := applyPortSpec(context.Background(), manager, "web", spec, portApplyOptions{Force: true}, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var got []string
+		for _, command := range *commands {
+			got = append(got, strings.Join(command, " "))
+		}
+		expected := []string{
+			"lxc config device remove web web-5432-5432-tcp",
+			"lxc config device remove web web-9000-9000-tcp",
+			"lxc config device add web web-3000-3000-tcp proxy connect=tcp:0.0.0.0:3000 listen=tcp:0.0.0.0:3000",
+			"lxc config device add web web-5432-5432-tcp proxy connect=tcp:0.0.0.0:5432 listen=tcp:127.0.0.1:5432",
+		}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("unexpected commands:\n%s", strings.Join(got, "\n"))
+		}
+		if contains(strings.Join(got, "\n"), "custom") {
+			t.Error("devices not created by this tool must be left alone")
+		}
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		manager, commands := newManager()
+		upToDate, _ := parsePortSpec([]byte("ports:\n  - {host: 8080, container: 80}\n  - {host: 9000, container: 9000}\n  - {host: 5432, container: 5432}\n"))
+		var out strings.Builder
+		if err := applyPortSpec(context.Background(), manager, "web", upToDate, portApplyOptions{}, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(*commands) != 0 || !contains(out.String(), "up to date") {
+			t.Errorf("expected no changes, got %v\n%s", *commands, out.String())
+		}
+	})
+
+	t.Run("import keeps unlisted rules", func(t *testing.T) {
+		manager, commands := newManager()
+		var out strings.Builder
+		if err := applyPortSpec(context.Background(), manager, "web", spec, portApplyOptions{Force: true, KeepUnlisted: true}, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var got []string
+		for _, command := range *commands {
+			got = append(got, strings.Join(command, " "))
+		}
+		expected := []string{
+			"lxc config device remove web web-5432-5432-tcp",
+			"lxc config device add web web-3000-3000-tcp proxy connect=tcp:0.0.0.0:3000 listen=tcp:0.0.0.0:3000",
+			"lxc config device add web web-5432-5432-tcp proxy connect=tcp:0.0.0.0:5432 listen=tcp:127.0.0.1:5432",
+		}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected only the changed rule to be replaced, got:\n%s", strings.Join(got, "\n"))
+		}
+	})
+
+	t.Run("devices", func(t *testing.T) {
+		manager, commands := newManager()
+		withDevices, err := parsePortSpec([]byte(`ports:
+  - {host: 8080, container: 80}
+devices:
+  custom: {type: proxy, connect: "tcp:0.0.0.0:22", listen: "tcp:0.0.0.0:2223"}
+  gpu0: {type: gpu, id: "0"}
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		if err := applyPortSpec(context.Background(), manager, "web", withDevices, portApplyOptions{Force: true, KeepUnlisted: true}, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, expected := range []string{"~ device custom listen=tcp:0.0.0.0:2223\n", "+ device gpu0 (gpu) id=0\n"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+			}
+		}
+		var got []string
+		for _, command := range *commands {
+			got = append(got, strings.Join(command, " "))
+		}
+		expected := []string{
+			"lxc config device set web custom listen=tcp:0.0.0.0:2223",
+			"lxc config device add web gpu0 gpu id=0",
+		}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("unexpected commands:\n%s", strings.Join(got, "\n"))
+		}
+
+		retyped, _ := parsePortSpec([]byte("ports: []\ndevices:\n  custom: {type: disk, source: /srv, path: /srv}\n"))
+		if err := applyPortSpec(context.Background(), manager, "web", retyped, portApplyOptions{}, io.Discard); err == nil || !strings.Contains(err.Error(), "proxy device") {
+			t.Errorf("expected an error for a device changing type, got %v", err)
+		}
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		manager, _ := newManager()
+		if err := applyPortSpec(context.Background(), manager, "db", spec, portApplyOptions{}, io.Discard); err == nil {
+			t.Error("expected error for missing container")
+		}
+	})
+}
+
+func TestExportPortSpec(t *testing.T) {
+	config := `devices:
+  web-8080-80-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:80
+    listen: tcp:0.0.0.0:8080
+  web-53-53-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:53
+    listen: tcp:0.0.0.0:53
+  web-53-53-udp:
+    type: proxy
+    connect: udp:0.0.0.0:53
+    listen: udp:0.0.0.0:53
+  web-5432-5432-tcp:
+    type: proxy
+    connect: tcp:0.0.0.0:5432
+    listen: tcp:127.0.0.1:5432
+  web-rev-5432-5432-tcp:
+    type: proxy
+    bind: container
+    connect: tcp:127.0.0.1:5432
+    listen: tcp:127.0.0.1:5432
+  custom:
+    type: proxy
+    connect: tcp:0.0.0.0:22
+    listen: tcp:0.0.0.0:2222
+  data:
+    type: disk
+    source: /srv/web-data
+    path: /data
+    readonly: "true"
+`
+	manager := &MockContainerPortManager{
+		ExistingContainers: map[string]bool{"web": true},
+		ContainerConfigs:   map[string][]byte{"web": []byte(config)},
+		Calls:              make(map[string]int),
+	}
+
+	var out bytes.Buffer
+	if err := exportPortSpec(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `ports:
+- host: 53
+  container: 53
+  protocol: both
+- host: 5432
+  container: 5432
+  listen: 127.0.0.1
+- host: 8080
+  container: 80
+devices:
+  custom:
+    connect: tcp:0.0.0.0:22
+    listen: tcp:0.0.0.0:2222
+    type: proxy
+  data:
+    path: /data
+    readonly: "true"
+    source: /srv/web-data
+    type: disk
+`
+	if out.String() != expected {
+		t.Errorf("unexpected export:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+
+	// The export is read back as the same rules and devices, even under another name
+	spec, err := parsePortSpec(out.Bytes())
+	if err != nil {
+		t.Fatalf("expected the export to parse, got %v", err)
+	}
+	rules, _ := desiredPortRules("web", spec)
+	current, _ := currentPortRules([]byte(config), "web")
+	if add, remove, _ := diffPortRules(current, rules); len(add) != 0 || len(remove) != 0 {
+		t.Errorf("expected the export to round-trip, got +%v -%v", add, remove)
+	}
+	devices, _ := currentSpecDevices([]byte(config), "web")
+	if changes, unchanged, err := diffSpecDevices(devices, spec.Devices); len(changes) != 0 || len(unchanged) != 2 || err != nil {
+		t.Errorf("expected the devices to round-trip, got %v %v (%v)", changes, unchanged, err)
+	}
+
+	out.Reset()
+	empty := &MockContainerPortManager{
+		ExistingContainers: map[string]bool{"web": true},
+		ContainerConfigs:   map[string][]byte{"web": []byte("devices: {}\n")},
+		Calls:              make(map[string]int),
+	}
+	if err := exportPortSpec(context.Background(), empty, "web", &out); err != nil || out.String() != "ports: []\n" {
+		t.Errorf("expected an empty list, got %q (%v)", out.String(), err)
+	}
+	if err := exportPortSpec(context.Background(), manager, "db", &out); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestValidatePortSpecFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "field problems with positions",
+			data: `ports:
+  - host: 8080
+    container: 80
+  - host: 70000
+    container: 80
+    protocol: sctp
+  - {host: 8080, container: 81, listen: localhost}
+`,
+			expected: []string{
+				"ports.yaml:4:5: ports[1].host: invalid host port '70000': must be between 1 and 65535",
+				"ports.yaml:6:5: ports[1].protocol: invalid protocol 'sctp'",
+				"ports.yaml:7:6: ports[2].host: host port 8080/tcp is declared more than once\nports.yaml:7:33: ports[2].listen: invalid listen address 'localhost'",
+			},
+		},
+		{
+			name:     "device without type",
+			data:     "ports: []\ndevices:\n  data:\n    source: /srv\n",
+			expected: []string{"ports.yaml:3:3: devices.data: type is required"},
+		},
+		{
+			name:     "unknown field",
+			data:     "ports:\n  - host: 80\n    container: 80\n    hostport: 1\n",
+			expected: []string{"ports.yaml:4:5: unknown field 'hostport'"},
+		},
+		{
+			name:     "wrong type",
+			data:     "ports:\n  - host: http\n    container: 80\n",
+			expected: []string{"ports.yaml:2: cannot unmarshal !!str `http` into int"},
+		},
+		{
+			name:     "syntax error",
+			data:     "ports:\n  - host: 80\n   container: 80\n",
+			expected: []string{"ports.yaml:2: did not find expected '-' indicator"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := validatePortSpecFile("ports.yaml", []byte(tt.data), &out)
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+				t.Errorf("expected exit status 1, got %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+				}
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := validatePortSpecFile("ports.yaml", []byte("ports:\n  - host: 8080\n    container: 80\n"), &out); err != nil {
+		t.Errorf("expected a valid file, got %v", err)
+	}
+	if out.String() != "ports.yaml: OK (1 mapping(s))\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestPortSchema(t *testing.T) {
+	var out bytes.Buffer
+	portSchemaCmd.SetOut(&out)
+	defer portSchemaCmd.SetOut(nil)
+	if err := portSchemaCmd.RunE(portSchemaCmd, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, expected := range []string{`"ports"`, `"host"`, `"maximum": 65535`, `"both"`, `"additionalProperties": false`} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected schema to contain %s, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestLoadPortSpec(t *testing.T) {
+	if _, err := loadPortSpec("", nil); err == nil {
+		t.Error("expected error without a file")
+	}
+	path := filepath.Join(t.TempDir(), "ports.yaml")
+	os.WriteFile(path, []byte("ports:\n  - {host: 8080, container: 80}\n"), 0644)
+	spec, err := loadPortSpec(path, nil)
+	if err != nil || len(spec.Ports) != 1 {
+		t.Errorf("expected one mapping, got %v (%v)", spec, err)
+	}
+	spec, err = loadPortSpec("-", strings.NewReader("ports:\n  - {host: 53, container: 53, protocol: udp}\n"))
+	if err != nil || len(spec.Ports) != 1 || spec.Ports[0].Protocol != "udp" {
+		t.Errorf("expected one mapping from stdin, got %v (%v)", spec, err)
+	}
+}
+
+// MockPortCheckManager for testing port check
+type MockPortCheckManager struct {
+	Busy    map[string]bool
+	Holders []helpers.PortHolder
+	States  []helpers.ContainerState
+}
+
+func (m *MockPortCheckManager) IsPortAvailable(port int, protocol string) bool {
+	return !m.Busy[protocol]
+}
+
+func (m *MockPortCheckManager) GetPortHolders(ctx context.Context, port int, protocol string) ([]helpers.PortHolder, error) {
+	return m.Holders, nil
+}
+
+func (m *MockPortCheckManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func TestCheckPort(t *testing.T) {
+	ctx := context.Background()
+
+	manager := &MockPortCheckManager{}
+	var out bytes.Buffer
+	// Bad input exits as a usage error, as it does for port add
+	for _, args := range [][2]string{{"http", "tcp"}, {"70000", "tcp"}, {"8080", "sctp"}} {
+		if err := checkPort(ctx, manager, args[0], args[1], &out); exitCode(err) != ExitUsage {
+			t.Errorf("%v: expected a usage error, got %v", args, err)
+		}
+	}
+	if err := checkPort(ctx, manager, "8080", "both", &out); err != nil {
+		t.Fatalf("expected free port, got %v", err)
+	}
+	if out.String() != "Port 8080/tcp: free on the host\nPort 8080/udp: free on the host\n" {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	manager = &MockPortCheckManager{
+		Busy:    map[string]bool{"tcp": true},
+		Holders: []helpers.PortHolder{{Process: "nginx", PID: 812, Local: "0.0.0.0:8080"}},
+		States: []helpers.ContainerState{{Name: "web", Status: "Stopped", Devices: map[string]map[string]string{
+			"web-8080-80-tcp": {"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:127.0.0.1:80"},
+			"web-8080-80-udp": {"type": "proxy", "listen": "udp:0.0.0.0:8080", "connect": "udp:127.0.0.1:80"},
+			"root":            {"type": "disk", "path": "/"},
+		}}},
+	}
+	out.Reset()
+	err := checkPort(ctx, manager, "8080", "tcp", &out)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	for _, want := range []string{"in use on the host", "Process:   nginx (pid 812) on 0.0.0.0:8080", "Container: web (stopped), device web-8080-80-tcp"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "web-8080-80-udp") {
+		t.Errorf("udp device should not match a tcp check:\n%s", out.String())
+	}
+
+	// A claim by a stopped container counts even when the host port is free
+	manager.Busy = nil
+	out.Reset()
+	if err := checkPort(ctx, manager, "8080", "udp", &out); err == nil || !strings.Contains(out.String(), "web-8080-80-udp") {
+		t.Errorf("expected udp claim, got %v:\n%s", err, out.String())
+	}
+
+	// Without a process name the generic hint is shown
+	manager = &MockPortCheckManager{Busy: map[string]bool{"tcp": true}}
+	out.Reset()
+	_ = checkPort(ctx, manager, "8080", "tcp", &out)
+	if !strings.Contains(out.String(), helpers.GetPortUsageInfo(8080)) {
+		t.Errorf("expected usage hint, got:\n%s", out.String())
+	}
+
+	for _, args := range [][2]string{{"0", "tcp"}, {"http", "tcp"}, {"8080", "sctp"}} {
+		if err := checkPort(ctx, manager, args[0], args[1], io.Discard); err == nil {
+			t.Errorf("checkPort(%s, %s) should fail", args[0], args[1])
+		}
+	}
+}
+
+// MockPortProbeManager reports bound and reachable ports from maps
+type MockPortProbeManager struct {
+	Bound     map[int]bool
+	Reachable map[int]bool
+	mu        sync.Mutex
+	Probed    []int
+}
+
+func (m *MockPortProbeManager) IsPortAvailable(port int, protocol string) bool {
+	return !m.Bound[port]
+}
+
+func (m *MockPortProbeManager) ProbePort(hostPort int, protocol string, timeout time.Duration) error {
+	m.mu.Lock()
+	m.Probed = append(m.Probed, hostPort)
+	m.mu.Unlock()
+	if !m.Reachable[hostPort] {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func TestProbePortMappings(t *testing.T) {
+	mappings := []PortMapping{
+		{DeviceName: "web-8080-80-tcp", Protocol: "TCP", HostPort: "8080"},
+		{DeviceName: "web-8081-81-tcp", Protocol: "TCP", HostPort: "8081"},
+		{DeviceName: "web-8082-82-tcp", Protocol: "TCP", HostPort: "8082"},
+		{DeviceName: "web-5353-53-udp", Protocol: "UDP", HostPort: "5353"},
+		{DeviceName: "web-rev-5432-15432-tcp", Protocol: "TCP", HostPort: "15432", Reverse: true},
+	}
+	prober := &MockPortProbeManager{
+		Bound:     map[int]bool{8080: true, 8081: true, 5353: true, 15432: true},
+		Reachable: map[int]bool{8080: true},
+	}
+
+	probes := probePortMappings(prober, mappings)
+	want := []portProbe{{"yes", "yes"}, {"yes", "no"}, {"no", "no"}, {"yes", "-"}, {"-", "-"}}
+	for i := range want {
+		if probes[i] != want[i] {
+			t.Errorf("%s: expected %+v, got %+v", mappings[i].DeviceName, want[i], probes[i])
+		}
+	}
+	// Only bound TCP ports are probed
+	if len(prober.Probed) != 2 {
+		t.Errorf("expected 2 probes, got %v", prober.Probed)
+	}
+
+	output := portMappingTable(mappings[:1], probes[:1]).String()
+	if !strings.Contains(output, "LISTENING  REACHABLE") || !strings.HasSuffix(output, "yes        yes\n") {
+		t.Errorf("expected the live columns, got:\n%s", output)
+	}
+	if strings.Contains(formatPortMappings(mappings), "LISTENING") {
+		t.Error("expected no live columns without probes")
+	}
+}
+
+// MockPortStatsManager for testing port stats
+type MockPortStatsManager struct {
+	MockContainerPortManager
+	Traffic map[int]helpers.PortTraffic
+	NAT     map[int]bool
+}
+
+func (m *MockPortStatsManager) GetPortTraffic(ctx context.Context, port int, protocol string, nat bool) (helpers.PortTraffic, error) {
+	if m.NAT == nil {
+		m.NAT = make(map[int]bool)
+	}
+	m.NAT[port] = nat
+	traffic, ok := m.Traffic[port]
+	if !ok {
+		return helpers.PortTraffic{}, fmt.Errorf("conntrack: permission denied")
+	}
+	return traffic, nil
+}
+
+func TestShowPortStats(t *testing.T) {
+	config := `devices:
+  web-8080-80-tcp:
+    type: proxy
+    listen: tcp:0.0.0.0:8080
+    connect: tcp:127.0.0.1:80
+  web-8443-443-tcp:
+    type: proxy
+    nat: "true"
+    listen: tcp:10.0.0.1:8443
+    connect: tcp:10.0.0.5:443
+  web-5353-53-udp:
+    type: proxy
+    listen: udp:0.0.0.0:5353
+    connect: udp:127.0.0.1:53
+  web-rev-5432-5432-tcp:
+    type: proxy
+    bind: container
+    listen: tcp:127.0.0.1:5432
+    connect: tcp:127.0.0.1:5432
+`
+	manager := &MockPortStatsManager{
+		MockContainerPortManager: MockContainerPortManager{
+			ExistingContainers: map[string]bool{"web": true},
+			ContainerConfigs:   map[string][]byte{"web": []byte(config)},
+		},
+		Traffic: map[int]helpers.PortTraffic{
+			8080: {Connections: 2, BytesIn: 1024, BytesOut: 2048, Bytes: true, Source: "ss"},
+			8443: {Connections: 40, BytesIn: 10 << 20, BytesOut: 500 << 20, Bytes: true, Source: "conntrack"},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := showPortStats(context.Background(), manager, "web", render.Options{}, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "web-8443-443-tcp") || !strings.HasPrefix(lines[2], "web-8080-80-tcp") || !strings.HasPrefix(lines[3], "web-5353-53-udp") {
+		t.Fatalf("expected the busiest port first and no reverse mapping, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "500.0MiB") || !strings.HasSuffix(lines[3], "-") {
+		t.Errorf("unexpected rows:\n%s", out.String())
+	}
+	if !manager.NAT[8443] || manager.NAT[8080] {
+		t.Errorf("expected only the NAT device to be read as NAT, got %v", manager.NAT)
+	}
+
+	manager.Traffic = map[int]helpers.PortTraffic{8080: {Connections: 1, Source: "conntrack"}}
+	out.Reset()
+	if err := showPortStats(context.Background(), manager, "web", render.Options{}, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), helpers.ConntrackAccountingHint) {
+		t.Errorf("expected the accounting hint, got:\n%s", out.String())
+	}
+
+	manager.Traffic = nil
+	if err := showPortStats(context.Background(), manager, "web", render.Options{}, &out); err == nil {
+		t.Error("expected error when no port could be read")
+	}
+	if err := showPortStats(context.Background(), manager, "db", render.Options{}, &out); err == nil {
+		t.Error("expected error for a missing container")
+	}
+}
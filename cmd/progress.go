@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// Progress formats accepted by --progress
+const (
+	ProgressText = "text"
+	ProgressJSON = "json"
+)
+
+// Progress event types, stable for wrappers to match on
+const (
+	EventStepStarted   = "step_started"
+	EventStepCompleted = "step_completed"
+	EventStepFailed    = "step_failed"
+	EventDownload      = "download_progress"
+	EventLog           = "log"
+	EventError         = "error"
+)
+
+var progressFormat string
+
+// ProgressEvent is one JSON line written to stderr with --progress json
+type ProgressEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	Step  string    `json:"step,omitempty"`
+	// Stage is the part of a download in progress, e.g. "rootfs"
+	Stage string `json:"stage,omitempty"`
+	// Percent is the share of steps finished when the event was emitted, or
+	// of the stage downloaded for download events
+	Percent *int `json:"percent,omitempty"`
+	// Bytes is how much of the stage has been downloaded, when known
+	Bytes      int64  `json:"bytes,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Level      string `json:"level,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Code identifies the error whatever the language of Error
+	Code string `json:"code,omitempty"`
+	// ExitCode is the exit code the command ends with after an error
+	ExitCode int `json:"exit_code,omitempty"`
+}
+
+// ProgressReporter writes progress events as JSON lines. A nil reporter
+// discards events, so callers need not check whether reporting is enabled.
+type ProgressReporter struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+// NewProgressReporter creates a ProgressReporter writing to out
+func NewProgressReporter(out io.Writer) *ProgressReporter {
+	return &ProgressReporter{out: out, now: time.Now}
+}
+
+// progress is the reporter of the running command; nil unless --progress json
+var progress *ProgressReporter
+
+// Emit writes an event, stamping it with the current time
+func (p *ProgressReporter) Emit(event ProgressEvent) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	event.Time = p.now().UTC()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(p.out, "%s\n", data)
+}
+
+// StepStarted reports that a step started with percent of the steps finished
+func (p *ProgressReporter) StepStarted(step string, percent int) {
+	p.Emit(ProgressEvent{Event: EventStepStarted, Step: step, Percent: &percent})
+}
+
+// StepFinished reports that a step completed, or failed with err
+func (p *ProgressReporter) StepFinished(step string, percent int, duration time.Duration, err error) {
+	event := ProgressEvent{Event: EventStepCompleted, Step: step, Percent: &percent, DurationMS: duration.Milliseconds()}
+	if err != nil {
+		event.Event = EventStepFailed
+		event.Error = err.Error()
+	}
+	p.Emit(event)
+}
+
+// applyProgress sets up progress reporting from --progress. In JSON mode log
+// messages and the final error become events too, so every line on stderr is
+// JSON while normal output stays on stdout.
+func applyProgress(cmd *cobra.Command) error {
+	switch strings.ToLower(progressFormat) {
+	case "", ProgressText:
+		progress = nil
+		logger.SetHandler(nil)
+	case ProgressJSON:
+		progress = NewProgressReporter(cmd.ErrOrStderr())
+		logger.SetHandler(func(level logger.LogLevel, message string) {
+			progress.Emit(ProgressEvent{Event: EventLog, Level: strings.ToLower(level.String()), Message: message})
+		})
+		// Reported as an error event by Execute instead
+		cmd.Root().SilenceErrors = true
+	default:
+		return helpers.UsageErrorf("invalid progress format '%s': must be '%s' or '%s'", progressFormat, ProgressText, ProgressJSON)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// decodeProgress parses the JSON lines written by a ProgressReporter
+func decodeProgress(t *testing.T, out string) []ProgressEvent {
+	t.Helper()
+	var events []ProgressEvent
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var event ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected a JSON line, got %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := NewProgressReporter(&out)
+	reporter.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	reporter.StepStarted("launch", 0)
+	reporter.StepFinished("launch", 50, 1500*time.Millisecond, nil)
+	reporter.StepFinished("docker-install", 50, time.Second, fmt.Errorf("apt failed"))
+
+	want := `{"time":"2025-03-01T12:00:00Z","event":"step_started","step":"launch","percent":0}
+{"time":"2025-03-01T12:00:00Z","event":"step_completed","step":"launch","percent":50,"duration_ms":1500}
+{"time":"2025-03-01T12:00:00Z","event":"step_failed","step":"docker-install","percent":50,"duration_ms":1000,"error":"apt failed"}
+`
+	if out.String() != want {
+		t.Errorf("unexpected events:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// A nil reporter discards events
+	var disabled *ProgressReporter
+	disabled.StepStarted("launch", 0)
+}
+
+func TestStepRunnerReportsProgress(t *testing.T) {
+	var out bytes.Buffer
+	runner := NewStepRunner(0)
+	runner.Progress = NewProgressReporter(&out)
+
+	step := func(name string, err error) Step {
+		return Step{Name: name, Run: func(ctx context.Context) error { return err }}
+	}
+	err := runner.Run(context.Background(), step("one", nil), step("two", nil), step("three", fmt.Errorf("boom")), step("four", nil))
+	if err == nil {
+		t.Fatal("expected the failing step's error")
+	}
+
+	var got []string
+	for _, event := range decodeProgress(t, out.String()) {
+		got = append(got, fmt.Sprintf("%s %s %d", event.Event, event.Step, *event.Percent))
+	}
+	want := []string{
+		"step_started one 0", "step_completed one 25",
+		"step_started two 25", "step_completed two 50",
+		"step_started three 50", "step_failed three 50",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected events:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestApplyProgress(t *testing.T) {
+	previous := progressFormat
+	t.Cleanup(func() {
+		progressFormat = previous
+		progress = nil
+		logger.SetHandler(nil)
+		rootCmd.SilenceErrors = false
+	})
+
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+	t.Cleanup(func() { rootCmd.SetErr(nil) })
+
+	progressFormat = "json"
+	if err := applyProgress(rootCmd); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	logger.Warn("disk almost full")
+	events := decodeProgress(t, stderr.String())
+	if len(events) != 1 || events[0].Event != EventLog || events[0].Level != "warn" || events[0].Message != "disk almost full" {
+		t.Errorf("expected log messages as events, got %+v", events)
+	}
+	if !rootCmd.SilenceErrors {
+		t.Error("errors should be reported as events, not printed")
+	}
+
+	progressFormat = "text"
+	if err := applyProgress(rootCmd); err != nil || progress != nil {
+		t.Errorf("expected progress events to be off, got %v, %v", progress, err)
+	}
+
+	progressFormat = "xml"
+	if err := applyProgress(rootCmd); err == nil {
+		t.Error("expected an invalid format error")
+	}
+}
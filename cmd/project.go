@@ -0,0 +1,261 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectDescription string
+	projectCreateUse   bool
+	projectTimeout     time.Duration
+)
+
+// activeProject describes the project this invocation targets, e.g.
+// "team-a (from config)"; empty when using the remote's own project
+var activeProject string
+
+// projectCmd represents the project command
+var projectCmd = &cobra.Command{
+	Use:   "project <create|list|use>",
+	Short: "Run commands in a separate LXD project",
+	Long: `Run commands in a separate LXD project. Projects keep instances,
+networks and storage volumes apart, so teams sharing a server can use the same
+container names without colliding.
+
+The project chosen with 'project use' is saved in this tool's config file and
+applies to every later command. The --project flag, or the ` + helpers.ProjectEnvVar + `
+environment variable, overrides it for a single command or shell.
+
+Available subcommands:
+  create  - Create a project
+  list    - List projects and show the current one
+  use     - Run subsequent commands in a project
+
+Examples:
+  lxc-go-cli project create team-a --use
+  lxc-go-cli list --project team-b
+  lxc-go-cli project use default`,
+}
+
+// projectCreateCmd represents the project create subcommand
+var projectCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a project",
+	Long: `Create a project. It shares images and profiles with the default
+project, so containers are created exactly as they are there.
+
+Examples:
+  lxc-go-cli project create team-a --description "Team A staging"
+  lxc-go-cli project create team-a --use`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), projectTimeout)
+		defer cancel()
+
+		return createProject(ctx, &DefaultProjectManager{}, args[0], projectDescription, projectCreateUse)
+	},
+}
+
+// projectListCmd represents the project list subcommand
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List projects and show the current one",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), projectTimeout)
+		defer cancel()
+
+		return listProjects(ctx, &DefaultProjectManager{}, cmd.OutOrStdout())
+	},
+}
+
+// projectUseCmd represents the project use subcommand
+var projectUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Run subsequent commands in a project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), projectTimeout)
+		defer cancel()
+
+		return useProject(ctx, &DefaultProjectManager{}, args[0])
+	},
+}
+
+// ProjectManager interface for dependency injection
+type ProjectManager interface {
+	ListProjects(ctx context.Context) ([]helpers.Project, error)
+	CreateProject(ctx context.Context, name, description string) error
+	LoadSettings() (*helpers.Settings, error)
+	SaveSettings(settings *helpers.Settings) error
+}
+
+// DefaultProjectManager implements ProjectManager using helpers
+type DefaultProjectManager struct{}
+
+func (d *DefaultProjectManager) ListProjects(ctx context.Context) ([]helpers.Project, error) {
+	return helpers.ListProjects(ctx)
+}
+
+func (d *DefaultProjectManager) CreateProject(ctx context.Context, name, description string) error {
+	return helpers.CreateProject(ctx, name, description)
+}
+
+func (d *DefaultProjectManager) LoadSettings() (*helpers.Settings, error) {
+	return helpers.LoadSettings()
+}
+
+func (d *DefaultProjectManager) SaveSettings(settings *helpers.Settings) error {
+	return helpers.SaveSettings(settings)
+}
+
+// createProject creates a project and optionally switches to it
+func createProject(ctx context.Context, manager ProjectManager, name, description string, use bool) error {
+	if err := helpers.ValidateProjectName(name); err != nil {
+		return err
+	}
+
+	projects, err := manager.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if project.Name == name {
+			return helpers.ConflictErrorf("project '%s' already exists", name)
+		}
+	}
+
+	if err := manager.CreateProject(ctx, name, description); err != nil {
+		return err
+	}
+	logger.Info("Project '%s' created", name)
+
+	if use {
+		return saveProject(manager, name)
+	}
+	logger.Info("Run 'lxc-go-cli project use %s' or pass --project %s to work in it", name, name)
+	return nil
+}
+
+// listProjects prints the projects on the server, marking the current one
+func listProjects(ctx context.Context, manager ProjectManager, out io.Writer) error {
+	projects, err := manager.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := helpers.ActiveProject()
+	if current == "" {
+		current = helpers.DefaultProject
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDESCRIPTION\tINSTANCES\tCURRENT")
+	for _, project := range projects {
+		mark := ""
+		if project.Name == current {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", project.Name, valueOrDash(project.Description), project.Instances(), mark)
+	}
+	return w.Flush()
+}
+
+// useProject saves a project as the one subsequent commands run in
+func useProject(ctx context.Context, manager ProjectManager, name string) error {
+	projects, err := manager.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, project := range projects {
+		if project.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return helpers.NotFoundErrorf("project '%s' does not exist (see 'lxc-go-cli project list')", name)
+	}
+	return saveProject(manager, name)
+}
+
+// saveProject stores the project in the config file; the default project is
+// stored as no project at all
+func saveProject(manager ProjectManager, name string) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Project = name
+	if name == helpers.DefaultProject {
+		settings.Project = ""
+	}
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+
+	logger.Info("Now working in project '%s'", name)
+	if envProject, source := helpers.ResolveProject("", nil); source == helpers.ContextSourceEnv && envProject != name {
+		logger.Warn("%s=%s overrides the saved project in this shell", helpers.ProjectEnvVar, envProject)
+	}
+	return nil
+}
+
+// projectLabel renders a project with its source, e.g. "team-a (from config)"
+func projectLabel(project, source string) string {
+	switch source {
+	case helpers.ContextSourceEnv:
+		return fmt.Sprintf("%s (from %s)", project, helpers.ProjectEnvVar)
+	case helpers.ProjectSourceFlag:
+		return fmt.Sprintf("%s (from --project)", project)
+	}
+	return fmt.Sprintf("%s (from %s)", project, source)
+}
+
+// applyProject makes lxc commands target the active project before a command runs
+func applyProject(cmd *cobra.Command) error {
+	if !usesSettings(cmd) {
+		return nil
+	}
+
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return err
+	}
+	project, source := helpers.ResolveProject(projectFlag, settings)
+	if project == "" {
+		return nil
+	}
+	if err := helpers.UseProject(project); err != nil {
+		return err
+	}
+	activeProject = projectLabel(project, source)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+
+	projectCmd.AddCommand(projectCreateCmd)
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectUseCmd)
+
+	projectCreateCmd.Flags().StringVar(&projectDescription, "description", "", "Description of the project")
+	projectCreateCmd.Flags().BoolVar(&projectCreateUse, "use", false, "Run subsequent commands in the project after creating it")
+
+	for _, sub := range []*cobra.Command{projectCreateCmd, projectListCmd, projectUseCmd} {
+		sub.Flags().DurationVarP(&projectTimeout, "timeout", "t", 30*time.Second, "Timeout for the project operation")
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockProjectManager for testing project command
+type MockProjectManager struct {
+	Projects []helpers.Project
+	Created  []string
+	Settings helpers.Settings
+	Saved    bool
+}
+
+func (m *MockProjectManager) ListProjects(ctx context.Context) ([]helpers.Project, error) {
+	return m.Projects, nil
+}
+
+func (m *MockProjectManager) CreateProject(ctx context.Context, name, description string) error {
+	m.Created = append(m.Created, name)
+	m.Projects = append(m.Projects, helpers.Project{Name: name, Description: description})
+	return nil
+}
+
+func (m *MockProjectManager) LoadSettings() (*helpers.Settings, error) {
+	settings := m.Settings
+	return &settings, nil
+}
+
+func (m *MockProjectManager) SaveSettings(settings *helpers.Settings) error {
+	m.Settings = *settings
+	m.Saved = true
+	return nil
+}
+
+func newMockProjectManager() *MockProjectManager {
+	return &MockProjectManager{Projects: []helpers.Project{
+		{Name: "default", Description: "Default LXD project", UsedBy: []string{"/1.0/instances/web", "/1.0/instances/db"}},
+		{Name: "team-a", UsedBy: []string{"/1.0/instances/web?project=team-a"}},
+	}}
+}
+
+func TestProjectCommand(t *testing.T) {
+	if projectCmd.Use != "project <create|list|use>" {
+		t.Errorf("unexpected Use: %s", projectCmd.Use)
+	}
+	for _, name := range []string{"create", "list", "use"} {
+		if _, _, err := projectCmd.Find([]string{name}); err != nil {
+			t.Errorf("expected subcommand '%s': %v", name, err)
+		}
+	}
+	if rootCmd.PersistentFlags().Lookup("project") == nil {
+		t.Error("expected a global --project flag")
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	ctx := context.Background()
+	manager := newMockProjectManager()
+
+	if err := createProject(ctx, manager, "team-b", "Team B", true); err != nil {
+		t.Fatalf("createProject failed: %v", err)
+	}
+	if strings.Join(manager.Created, ",") != "team-b" || manager.Settings.Project != "team-b" {
+		t.Errorf("expected team-b to be created and used, got %v, %q", manager.Created, manager.Settings.Project)
+	}
+
+	for name, want := range map[string]string{"team-a": "already exists", "bad name": "invalid project name"} {
+		if err := createProject(ctx, newMockProjectManager(), name, "", false); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("createProject(%s): expected error containing %q, got %v", name, want, err)
+		}
+	}
+}
+
+func TestUseProject(t *testing.T) {
+	t.Setenv(helpers.ProjectEnvVar, "")
+	ctx := context.Background()
+	manager := newMockProjectManager()
+
+	if err := useProject(ctx, manager, "team-a"); err != nil || manager.Settings.Project != "team-a" {
+		t.Fatalf("expected team-a to be saved, got %q, %v", manager.Settings.Project, err)
+	}
+	// The default project is saved as no project
+	if err := useProject(ctx, manager, "default"); err != nil || manager.Settings.Project != "" {
+		t.Errorf("expected the project to be cleared, got %q, %v", manager.Settings.Project, err)
+	}
+
+	manager.Saved = false
+	if err := useProject(ctx, manager, "missing"); err == nil || !strings.Contains(err.Error(), "does not exist") || manager.Saved {
+		t.Errorf("expected a missing project error, got %v", err)
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	var out bytes.Buffer
+	if err := listProjects(context.Background(), newMockProjectManager(), &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "Default LXD project") || !strings.Contains(lines[1], "2") || !strings.HasSuffix(lines[1], "*") {
+		t.Errorf("expected the default project to be current with two instances, got %q", lines[1])
+	}
+	if strings.HasSuffix(lines[2], "*") {
+		t.Errorf("team-a should not be current, got %q", lines[2])
+	}
+}
+
+func TestApplyProject(t *testing.T) {
+	originalDir := helpers.SettingsDir
+	helpers.SettingsDir = t.TempDir()
+	t.Setenv(helpers.ProjectEnvVar, "")
+	t.Cleanup(func() {
+		helpers.SettingsDir = originalDir
+		projectFlag = ""
+		activeProject = ""
+		helpers.UseProject("")
+	})
+
+	projectFlag = "team-a"
+	if err := applyProject(remoteListCmd); err != nil || activeProject != "" {
+		t.Errorf("remote commands should not use a project, got %q, %v", activeProject, err)
+	}
+	if err := applyProject(listCmd); err != nil {
+		t.Fatal(err)
+	}
+	if activeProject != "team-a (from --project)" || helpers.ActiveProject() != "team-a" {
+		t.Errorf("expected team-a from the flag, got %q", activeProject)
+	}
+
+	var out bytes.Buffer
+	printActiveContext(&out)
+	if out.String() != "Project: team-a (from --project)\n\n" {
+		t.Errorf("expected a project header, got %q", out.String())
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	provisionMaxDuration time.Duration
+	provisionStepTimeout map[string]string
+	provisionTimezone    string
+	provisionLocale      string
+	provisionPackages    []string
+	provisionPkgFile     string
+	provisionDockerStore DockerStorageOptions
+	provisionParallel    int
+)
+
+// ProvisionOptions holds the settings for provisioning a container
+type ProvisionOptions struct {
+	// MaxDuration bounds the whole provisioning; zero means no total budget
+	MaxDuration time.Duration
+	// StepTimeouts overrides defaultStepTimeouts for individual steps
+	StepTimeouts map[string]time.Duration
+	// Locale is the requested time zone and locale; empty fields fall back
+	// to HostLocale, except on adopted containers, which keep their own
+	Locale     LocaleOptions
+	HostLocale LocaleOptions
+	// Packages are extra apt packages installed after Docker
+	Packages []string
+	// DockerStorage chooses where and how Docker stores images
+	DockerStorage DockerStorageOptions
+	// Parallel bounds how many independent steps run at once; zero or one
+	// runs them in order
+	Parallel int
+}
+
+// provisionCmd represents the provision command
+var provisionCmd = &cobra.Command{
+	Use:   "provision <container-name>",
+	Short: "Install Docker and the 'app' user in an existing container",
+	Long: `Run the provisioning steps of 'create' on an existing container: apply the
+Docker security settings, install Docker and Docker Compose V2, set up the
+'app' user with a generated password, and restart.
+
+Use it after 'create --no-provision' or on an adopted container. Steps already
+recorded as completed are skipped, so a failed run can simply be repeated. On
+containers without provisioning state an existing Docker installation and
+'app' user are kept.
+
+The time zone and locale are set as with create: --timezone and --locale, or
+the host's settings. Containers without provisioning state keep their own
+unless the flags are given. Extra apt packages are installed with --package
+and --packages-file. Docker's storage is configured as with create, through
+--docker-storage-driver and --docker-volume-pool, unless Docker is already
+installed.
+
+Examples:
+  lxc-go-cli create --name mycontainer --no-provision
+  lxc-go-cli provision mycontainer
+  lxc-go-cli provision legacy-web --step-timeout docker-install=30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stepTimeouts, err := parseStepTimeouts(provisionStepTimeout)
+		if err != nil {
+			return err
+		}
+		packages, err := resolvePackages(provisionPackages, provisionPkgFile)
+		if err != nil {
+			return err
+		}
+		if provisionParallel < 1 {
+			return helpers.UsageErrorf("--parallel must be at least 1")
+		}
+
+		return provisionContainer(&DefaultContainerManager{}, args[0], ProvisionOptions{
+			MaxDuration:   provisionMaxDuration,
+			StepTimeouts:  stepTimeouts,
+			Locale:        LocaleOptions{Timezone: provisionTimezone, Locale: provisionLocale},
+			HostLocale:    hostLocaleOptions(),
+			Packages:      packages,
+			DockerStorage: provisionDockerStore,
+			Parallel:      provisionParallel,
+		})
+	},
+}
+
+// provisionContainer runs the remaining provisioning steps on an existing container
+func provisionContainer(manager ContainerManager, name string, opts ProvisionOptions) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(name) {
+		return containerNotFound(name)
+	}
+
+	value, err := manager.GetConfigValue(name, helpers.ProvisionStepsKey)
+	if err != nil {
+		return fmt.Errorf("failed to read provisioning state: %w", err)
+	}
+	completed := helpers.SplitConfigList(value)
+	// Containers this tool did not create, e.g. adopted ones, have no state
+	adopted := len(completed) == 0
+
+	locale := opts.Locale
+	if !adopted {
+		locale = locale.withDefaults(opts.HostLocale)
+	}
+	if err := locale.validate(); err != nil {
+		return err
+	}
+	if err := helpers.ValidatePackages(opts.Packages); err != nil {
+		return err
+	}
+	if err := opts.DockerStorage.validate(manager); err != nil {
+		return err
+	}
+
+	// The container exists, so it counts as launched; recording that keeps
+	// 'create --resume' from launching it again
+	if !containsAll(completed, []string{stepLaunch}) {
+		completed = append([]string{stepLaunch}, completed...)
+	}
+	if containsAll(completed, createStepNames()) {
+		logger.Info("Container '%s' is already fully provisioned", name)
+		return nil
+	}
+
+	// Provisioning runs commands in the container, so it must be running
+	if err := manager.StartContainer(name); err != nil {
+		logger.Debug("Start before provisioning returned: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStepTimeouts[stepLaunch])
+	err = waitForAgent(ctx, manager, name)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	timeouts := CreateOptions{StepTimeouts: opts.StepTimeouts}
+	steps := append([]Step{securityStep(manager, name)}, provisionSteps(manager, name, true, adopted, locale, opts.Packages, opts.DockerStorage, "")...)
+	for i := range steps {
+		steps[i].Timeout = timeouts.stepTimeout(steps[i].Name)
+	}
+	steps = trackProvisionSteps(manager, name, steps, completed)
+
+	logger.Info("Provisioning container '%s'...", name)
+	runner := NewStepRunner(opts.MaxDuration)
+	runner.Parallel = opts.Parallel
+	if err := runner.Run(context.Background(), steps...); err != nil {
+		logger.Info("Run 'lxc-go-cli provision %s' again to continue from the failed step", name)
+		manager.Notify(context.Background(), helpers.NotificationEvent{
+			Event:     helpers.EventProvisionFailed,
+			Container: name,
+			Message:   fmt.Sprintf("Provisioning container '%s' failed", name),
+			Error:     err.Error(),
+		})
+		return err
+	}
+
+	logger.Info("Container '%s' provisioned", name)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(provisionCmd)
+
+	provisionCmd.Flags().DurationVar(&provisionMaxDuration, "max-duration", 0, "Total time budget for provisioning (default: no limit)")
+	provisionCmd.Flags().StringToStringVar(&provisionStepTimeout, "step-timeout", nil, "Per-step timeout override, e.g. docker-install=30m (repeatable)")
+	provisionCmd.Flags().StringVar(&provisionTimezone, "timezone", "", "Container time zone, e.g. Europe/London (default: the host's)")
+	provisionCmd.Flags().StringVar(&provisionLocale, "locale", "", "Container locale, e.g. en_GB.UTF-8 (default: the host's)")
+	provisionCmd.Flags().StringArrayVar(&provisionPackages, "package", nil, "Extra apt package to install after Docker (repeatable)")
+	provisionCmd.Flags().StringVar(&provisionPkgFile, "packages-file", "", "File listing extra apt packages to install after Docker")
+	provisionCmd.Flags().StringVar(&provisionDockerStore.Driver, "docker-storage-driver", helpers.DockerStorageAuto, "Docker storage driver ("+strings.Join(helpers.DockerStorageDrivers, ", ")+")")
+	provisionCmd.Flags().IntVar(&provisionParallel, "parallel", defaultStepParallel, "Maximum number of independent steps to run at once")
+	provisionCmd.Flags().StringVar(&provisionDockerStore.VolumePool, "docker-volume-pool", "", "Storage pool for a volume mounted at /var/lib/docker (must not be Btrfs)")
+}
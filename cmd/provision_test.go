@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+func TestProvisionContainerAfterNoProvision(t *testing.T) {
+	var commands []string
+	config := map[string]string{}
+	manager := newResumeManager("launch,security", &commands, config)
+	manager.RunInContainerFunc = func(ctx context.Context, containerName string, args ...string) error {
+		commands = append(commands, strings.Join(args, " "))
+		if args[0] == "id" {
+			return fmt.Errorf("no such user")
+		}
+		return nil
+	}
+
+	if err := provisionContainer(manager, "test-container", ProvisionOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	joined := strings.Join(commands, "\n")
+	if contains(joined, "configure-security") {
+		t.Errorf("recorded security step should be skipped, got:\n%s", joined)
+	}
+	if commands[0] != "apt-get update" || !contains(joined, "docker-compose-plugin") {
+		t.Errorf("expected Docker to be installed, got:\n%s", joined)
+	}
+	if contains(joined, "command -v docker") {
+		t.Errorf("a container created by this tool should not be checked for Docker, got:\n%s", joined)
+	}
+	if !contains(joined, "useradd -m -s /bin/bash app") || commands[len(commands)-1] != "restart" {
+		t.Errorf("expected app user creation and restart, got:\n%s", joined)
+	}
+	if config[helpers.ProvisionStepsKey] != strings.Join(createStepNames(), ",") {
+		t.Errorf("expected all steps to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+}
+
+func TestProvisionAdoptedContainer(t *testing.T) {
+	var commands []string
+	config := map[string]string{}
+	manager := newResumeManager("", &commands, config)
+
+	if err := provisionContainer(manager, "legacy", ProvisionOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	joined := strings.Join(commands, "\n")
+	if commands[0] != "configure-security" {
+		t.Errorf("adopted container should get security settings first, got:\n%s", joined)
+	}
+	// Docker and the app user are already present
+	if contains(joined, "docker-compose-plugin") || contains(joined, "useradd") {
+		t.Errorf("existing Docker and app user should be kept, got:\n%s", joined)
+	}
+	if !contains(joined, "usermod -aG docker,sudo app") {
+		t.Errorf("existing app user should be configured, got:\n%s", joined)
+	}
+	// Launch is recorded so 'create --resume' does not launch again
+	if config[helpers.ProvisionStepsKey] != strings.Join(createStepNames(), ",") {
+		t.Errorf("expected all steps to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+}
+
+func TestProvisionContainerLocale(t *testing.T) {
+	host := LocaleOptions{Timezone: "Europe/Berlin", Locale: "de_DE.UTF-8"}
+
+	var commands []string
+	manager := newResumeManager("launch,security", &commands, map[string]string{})
+	if err := provisionContainer(manager, "web", ProvisionOptions{HostLocale: host}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !contains(strings.Join(commands, "\n"), strings.Join(helpers.TimezoneCommand("Europe/Berlin"), " ")) {
+		t.Errorf("expected the host's time zone, got:\n%s", strings.Join(commands, "\n"))
+	}
+
+	// Adopted containers keep their settings unless asked
+	commands = nil
+	manager = newResumeManager("", &commands, map[string]string{})
+	if err := provisionContainer(manager, "legacy", ProvisionOptions{Locale: LocaleOptions{Locale: "en_GB.UTF-8"}, HostLocale: host}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	joined := strings.Join(commands, "\n")
+	if contains(joined, "Europe/Berlin") || !contains(joined, strings.Join(helpers.LocaleCommand("en_GB.UTF-8"), " ")) {
+		t.Errorf("expected only the requested locale, got:\n%s", joined)
+	}
+
+	if err := provisionContainer(manager, "legacy", ProvisionOptions{Locale: LocaleOptions{Locale: "en GB"}}); err == nil {
+		t.Error("expected invalid locale error")
+	}
+}
+
+func TestProvisionContainerErrors(t *testing.T) {
+	var commands []string
+
+	if err := provisionContainer(newResumeManager("", &commands, map[string]string{}), "", ProvisionOptions{}); err == nil {
+		t.Error("expected error for empty name")
+	}
+
+	manager := newResumeManager("", &commands, map[string]string{})
+	manager.ContainerExistsFunc = func(name string) bool { return false }
+	if err := provisionContainer(manager, "missing", ProvisionOptions{}); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+
+	manager = newResumeManager(strings.Join(createStepNames(), ","), &commands, map[string]string{})
+	if err := provisionContainer(manager, "done", ProvisionOptions{}); err != nil {
+		t.Errorf("expected fully provisioned container to succeed, got %v", err)
+	}
+	if len(commands) != 0 {
+		t.Errorf("nothing should run for a fully provisioned container, got %v", commands)
+	}
+
+	config := map[string]string{}
+	manager = newResumeManager("launch,security", &commands, config)
+	manager.RunInContainerFunc = func(ctx context.Context, containerName string, args ...string) error {
+		if strings.Contains(strings.Join(args, " "), "docker-compose-plugin") {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	}
+	var events []helpers.NotificationEvent
+	manager.NotifyFunc = func(event helpers.NotificationEvent) { events = append(events, event) }
+	err := provisionContainer(manager, "slow", ProvisionOptions{StepTimeouts: map[string]time.Duration{stepDockerInstall: 10 * time.Millisecond}})
+	if err == nil || !contains(err.Error(), stepDockerInstall) {
+		t.Errorf("expected docker-install timeout, got %v", err)
+	}
+	if config[helpers.ProvisionStepsKey] != "launch,security,apt-update,locale,docker-storage" {
+		t.Errorf("expected progress to be recorded, got '%s'", config[helpers.ProvisionStepsKey])
+	}
+	if len(events) != 1 || events[0].Event != helpers.EventProvisionFailed || events[0].Container != "slow" {
+		t.Errorf("expected a provision-failed notification, got %+v", events)
+	}
+}
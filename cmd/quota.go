@@ -0,0 +1,216 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var quotaTimeout time.Duration
+
+// quotaCmd represents the quota command
+var quotaCmd = &cobra.Command{
+	Use:   "quota <enable|set|show>",
+	Short: "Limit how much of the storage pool a container can use",
+	Long: `Limit how much of the shared storage pool a container can use, so one
+runaway Docker build cannot fill the pool for every container.
+
+On Btrfs pools the limit is enforced with qgroup quotas, which must be enabled
+on the pool first ('quota set' does this automatically). Enabling quotas
+usually needs root.
+
+Available subcommands:
+  enable  - Enable Btrfs qgroup quotas on a storage pool
+  set     - Set a container's root disk limit
+  show    - Show a container's disk usage and limit
+
+Examples:
+  lxc-go-cli quota set mycontainer 20G
+  lxc-go-cli quota show mycontainer`,
+}
+
+// quotaEnableCmd represents the quota enable subcommand
+var quotaEnableCmd = &cobra.Command{
+	Use:   "enable <pool>",
+	Short: "Enable Btrfs qgroup quotas on a storage pool",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), quotaTimeout)
+		defer cancel()
+
+		return enablePoolQuota(ctx, &DefaultQuotaManager{}, args[0])
+	},
+}
+
+// quotaSetCmd represents the quota set subcommand
+var quotaSetCmd = &cobra.Command{
+	Use:   "set <container-name> <size>",
+	Short: "Set a container's root disk limit",
+	Long: `Set the size limit of a container's root disk, e.g. 20G.
+
+Quotas are enabled on the container's Btrfs pool first if needed. A root disk
+inherited from a profile is overridden on the container only.
+
+Examples:
+  lxc-go-cli quota set mycontainer 20G`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), quotaTimeout)
+		defer cancel()
+
+		return setContainerQuota(ctx, &DefaultQuotaManager{}, args[0], args[1])
+	},
+}
+
+// quotaShowCmd represents the quota show subcommand
+var quotaShowCmd = &cobra.Command{
+	Use:   "show <container-name>",
+	Short: "Show a container's disk usage and limit",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), quotaTimeout)
+		defer cancel()
+
+		return showContainerQuota(ctx, &DefaultQuotaManager{}, args[0], cmd.OutOrStdout())
+	},
+}
+
+// QuotaManager interface for dependency injection
+type QuotaManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	GetContainerConfig(ctx context.Context, containerName string) ([]byte, error)
+	GetStoragePool(ctx context.Context, name string) (*helpers.StoragePool, error)
+	EnableBtrfsQuota(ctx context.Context, pool string) error
+	SetRootDiskSize(ctx context.Context, containerName, size string, local bool) error
+}
+
+// DefaultQuotaManager implements QuotaManager using helpers
+type DefaultQuotaManager struct{}
+
+func (d *DefaultQuotaManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+func (d *DefaultQuotaManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return helpers.GetContainerConfig(ctx, containerName)
+}
+
+func (d *DefaultQuotaManager) GetStoragePool(ctx context.Context, name string) (*helpers.StoragePool, error) {
+	return helpers.GetStoragePool(name)
+}
+
+func (d *DefaultQuotaManager) EnableBtrfsQuota(ctx context.Context, pool string) error {
+	return helpers.EnableBtrfsQuota(pool)
+}
+
+func (d *DefaultQuotaManager) SetRootDiskSize(ctx context.Context, containerName, size string, local bool) error {
+	return helpers.SetRootDiskSize(containerName, size, local)
+}
+
+// enablePoolQuota enables qgroup quotas on a Btrfs pool
+func enablePoolQuota(ctx context.Context, manager QuotaManager, poolName string) error {
+	pool, err := manager.GetStoragePool(ctx, poolName)
+	if err != nil {
+		return err
+	}
+	if pool.Driver != "btrfs" {
+		logger.Info("Storage pool '%s' uses the '%s' driver, which enforces disk limits without qgroups", pool.Name, pool.Driver)
+		return nil
+	}
+
+	if err := manager.EnableBtrfsQuota(ctx, pool.Name); err != nil {
+		return fmt.Errorf("failed to enable quotas on storage pool '%s' (this usually needs root): %w", pool.Name, err)
+	}
+	logger.Info("Btrfs quotas enabled on storage pool '%s'", pool.Name)
+	return nil
+}
+
+// setContainerQuota limits the size of a container's root disk
+func setContainerQuota(ctx context.Context, manager QuotaManager, name, size string) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if err := helpers.ValidateStorageSize(size); err != nil {
+		return err
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	root, ok := state.Devices["root"]
+	if !ok {
+		return fmt.Errorf("container '%s' has no root disk device", name)
+	}
+	if root["pool"] != "" {
+		if err := enablePoolQuota(ctx, manager, root["pool"]); err != nil {
+			return err
+		}
+	}
+
+	// Only a root disk defined on the container itself can be changed with device set
+	configData, err := manager.GetContainerConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+	local, err := parseAdoptedContainer(configData)
+	if err != nil {
+		return err
+	}
+	_, isLocal := local.Devices["root"]
+
+	if err := manager.SetRootDiskSize(ctx, name, size, isLocal); err != nil {
+		return err
+	}
+	logger.Info("Root disk of container '%s' limited to %s", name, size)
+	return nil
+}
+
+// showContainerQuota prints a container's root disk usage and limit
+func showContainerQuota(ctx context.Context, manager QuotaManager, name string, out io.Writer) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Container:  %s\n", state.Name)
+	fmt.Fprintf(out, "Pool:       %s\n", valueOrDash(state.Devices["root"]["pool"]))
+	fmt.Fprintf(out, "Disk usage: %s\n", diskUsageLabel(state))
+	return nil
+}
+
+// diskUsageLabel renders root disk usage and limit, e.g. "3.2GiB of 20G"
+func diskUsageLabel(state *helpers.ContainerState) string {
+	usage := "-"
+	if state.DiskUsage > 0 {
+		usage = helpers.FormatBytes(state.DiskUsage)
+	}
+	if limit := state.Devices["root"]["size"]; limit != "" {
+		return fmt.Sprintf("%s of %s", usage, limit)
+	}
+	return usage + " (no limit)"
+}
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+
+	quotaCmd.AddCommand(quotaEnableCmd)
+	quotaCmd.AddCommand(quotaSetCmd)
+	quotaCmd.AddCommand(quotaShowCmd)
+
+	for _, sub := range []*cobra.Command{quotaEnableCmd, quotaSetCmd, quotaShowCmd} {
+		sub.Flags().DurationVarP(&quotaTimeout, "timeout", "t", 30*time.Second, "Timeout for the quota operation")
+	}
+}
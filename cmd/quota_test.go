@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockQuotaManager for testing quota command
+type MockQuotaManager struct {
+	States        []helpers.ContainerState
+	LocalConfig   string
+	Pools         map[string]string
+	EnableErr     error
+	QuotaPools    []string
+	SetSize       string
+	SetLocal      bool
+	SetRootCalled bool
+}
+
+func (m *MockQuotaManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockQuotaManager) GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	return []byte(m.LocalConfig), nil
+}
+
+func (m *MockQuotaManager) GetStoragePool(ctx context.Context, name string) (*helpers.StoragePool, error) {
+	driver, ok := m.Pools[name]
+	if !ok {
+		return nil, fmt.Errorf("storage pool '%s' not found", name)
+	}
+	return &helpers.StoragePool{Name: name, Driver: driver}, nil
+}
+
+func (m *MockQuotaManager) EnableBtrfsQuota(ctx context.Context, pool string) error {
+	m.QuotaPools = append(m.QuotaPools, pool)
+	return m.EnableErr
+}
+
+func (m *MockQuotaManager) SetRootDiskSize(ctx context.Context, containerName, size string, local bool) error {
+	m.SetRootCalled = true
+	m.SetSize = size
+	m.SetLocal = local
+	return nil
+}
+
+func newMockQuotaManager() *MockQuotaManager {
+	return &MockQuotaManager{
+		States: []helpers.ContainerState{{
+			Name:      "web",
+			Status:    "Running",
+			DiskUsage: 3 * 1024 * 1024 * 1024,
+			Devices:   map[string]map[string]string{"root": {"type": "disk", "path": "/", "pool": "btrfs-pool"}},
+		}},
+		LocalConfig: "config: {}\ndevices: {}\n",
+		Pools:       map[string]string{"btrfs-pool": "btrfs", "fast": "zfs"},
+	}
+}
+
+func TestSetContainerQuota(t *testing.T) {
+	manager := newMockQuotaManager()
+
+	if err := setContainerQuota(context.Background(), manager, "web", "20G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.QuotaPools) != 1 || manager.QuotaPools[0] != "btrfs-pool" {
+		t.Errorf("expected quotas to be enabled on btrfs-pool, got %v", manager.QuotaPools)
+	}
+	if manager.SetSize != "20G" || manager.SetLocal {
+		t.Errorf("expected profile root disk to be overridden with 20G, got size=%s local=%v", manager.SetSize, manager.SetLocal)
+	}
+
+	manager.LocalConfig = "devices:\n  root:\n    type: disk\n    path: /\n    pool: btrfs-pool\n"
+	if err := setContainerQuota(context.Background(), manager, "web", "20G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !manager.SetLocal {
+		t.Error("a root disk defined on the container should be set, not overridden")
+	}
+}
+
+func TestSetContainerQuotaNonBtrfsPool(t *testing.T) {
+	manager := newMockQuotaManager()
+	manager.States[0].Devices["root"]["pool"] = "fast"
+
+	if err := setContainerQuota(context.Background(), manager, "web", "20G"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.QuotaPools) != 0 {
+		t.Error("qgroups should only be enabled on Btrfs pools")
+	}
+	if !manager.SetRootCalled {
+		t.Error("the size limit should still be set")
+	}
+}
+
+func TestSetContainerQuotaErrors(t *testing.T) {
+	manager := newMockQuotaManager()
+	tests := []struct {
+		name          string
+		container     string
+		size          string
+		expectedError string
+	}{
+		{"empty name", "", "20G", "container name is required"},
+		{"invalid size", "web", "lots", "invalid size"},
+		{"missing container", "missing", "20G", "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setContainerQuota(context.Background(), manager, tt.container, tt.size)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	manager.EnableErr = fmt.Errorf("permission denied")
+	err := setContainerQuota(context.Background(), manager, "web", "20G")
+	if err == nil || !strings.Contains(err.Error(), "needs root") {
+		t.Errorf("expected quota enable failure, got %v", err)
+	}
+	if manager.SetRootCalled {
+		t.Error("the limit should not be set when quotas cannot be enabled")
+	}
+}
+
+func TestShowContainerQuota(t *testing.T) {
+	manager := newMockQuotaManager()
+
+	var out bytes.Buffer
+	if err := showContainerQuota(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "3.0GiB (no limit)") {
+		t.Errorf("expected usage without limit, got:\n%s", out.String())
+	}
+
+	manager.States[0].Devices["root"]["size"] = "20G"
+	out.Reset()
+	if err := showContainerQuota(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "3.0GiB of 20G") {
+		t.Errorf("expected usage with limit, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteAcceptCertificate bool
+	remoteToken             string
+	remoteUse               bool
+	remoteTimeout           time.Duration
+)
+
+// remoteCmd represents the remote command
+var remoteCmd = &cobra.Command{
+	Use:   "remote <add|list|use|remove|token>",
+	Short: "Operate against a remote LXD server over HTTPS",
+	Long: `Operate against a remote LXD server over HTTPS, e.g. from a Mac or
+Windows laptop with only the lxc client installed.
+
+Remotes are stored in the lxc client configuration. 'remote use' makes a remote
+the default, after which every command of this tool operates against it.
+Commands that run host tools directly (btrfs quota and usage, network policy)
+must still be run on the LXD host itself.
+
+Trust is established with a token: run 'lxc-go-cli remote token <client-name>'
+on the LXD host and pass the printed token to 'remote add --token'.
+
+Available subcommands:
+  add     - Add a remote LXD server
+  list    - List remotes and show the default
+  use     - Make a remote the default
+  remove  - Remove a remote
+  token   - Create a trust token on this server for a new client
+
+Examples:
+  lxc-go-cli remote token my-laptop                    # on the LXD host
+  lxc-go-cli remote add mylab https://host:8443 --accept-certificate --token <token> --use
+  lxc-go-cli remote use local`,
+}
+
+// remoteAddCmd represents the remote add subcommand
+var remoteAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add a remote LXD server",
+	Long: `Add a remote LXD server. The server must listen on the network
+(core.https_address) and trust this client, which it does once a trust token
+created on the server is passed with --token. Without --token lxc prompts for one.
+
+Examples:
+  lxc-go-cli remote add mylab https://host:8443 --accept-certificate --token <token>
+  lxc-go-cli remote add mylab host --use`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Adding may prompt for a token, so only the other subcommands are time limited
+		ctx := context.Background()
+		streams := helpers.Streams{Stdin: os.Stdin, Stdout: cmd.OutOrStdout(), Stderr: cmd.ErrOrStderr()}
+		return addRemote(ctx, &DefaultRemoteManager{}, args[0], args[1], helpers.RemoteAddOptions{
+			AcceptCertificate: remoteAcceptCertificate,
+			Token:             remoteToken,
+		}, remoteUse, streams)
+	},
+}
+
+// remoteListCmd represents the remote list subcommand
+var remoteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remotes and show the default",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		defer cancel()
+
+		return listRemotes(ctx, &DefaultRemoteManager{}, cmd.OutOrStdout())
+	},
+}
+
+// remoteUseCmd represents the remote use subcommand
+var remoteUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a remote the default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		defer cancel()
+
+		return useRemote(ctx, &DefaultRemoteManager{}, args[0])
+	},
+}
+
+// remoteRemoveCmd represents the remote remove subcommand
+var remoteRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a remote",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		defer cancel()
+
+		return removeRemote(ctx, &DefaultRemoteManager{}, args[0])
+	},
+}
+
+// remoteTokenCmd represents the remote token subcommand
+var remoteTokenCmd = &cobra.Command{
+	Use:   "token <client-name>",
+	Short: "Create a trust token on this server for a new client",
+	Long: `Create a trust token on the current server. Pass the token to
+'remote add --token' on the client; it can be used once.
+
+Examples:
+  lxc-go-cli remote token my-laptop`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		defer cancel()
+
+		return createRemoteToken(ctx, &DefaultRemoteManager{}, args[0], cmd.OutOrStdout())
+	},
+}
+
+// RemoteManager interface for dependency injection
+type RemoteManager interface {
+	ListRemotes(ctx context.Context) ([]helpers.Remote, error)
+	AddRemote(ctx context.Context, name, addr string, opts helpers.RemoteAddOptions, streams helpers.Streams) error
+	RemoveRemote(ctx context.Context, name string) error
+	SwitchRemote(ctx context.Context, name string) error
+	CreateTrustToken(ctx context.Context, clientName string) (string, error)
+}
+
+// DefaultRemoteManager implements RemoteManager using helpers
+type DefaultRemoteManager struct{}
+
+func (d *DefaultRemoteManager) ListRemotes(ctx context.Context) ([]helpers.Remote, error) {
+	return helpers.ListRemotes(ctx)
+}
+
+func (d *DefaultRemoteManager) AddRemote(ctx context.Context, name, addr string, opts helpers.RemoteAddOptions, streams helpers.Streams) error {
+	return helpers.AddRemote(ctx, name, addr, opts, streams)
+}
+
+func (d *DefaultRemoteManager) RemoveRemote(ctx context.Context, name string) error {
+	return helpers.RemoveRemote(ctx, name)
+}
+
+func (d *DefaultRemoteManager) SwitchRemote(ctx context.Context, name string) error {
+	return helpers.SwitchRemote(ctx, name)
+}
+
+func (d *DefaultRemoteManager) CreateTrustToken(ctx context.Context, clientName string) (string, error) {
+	return helpers.CreateTrustToken(ctx, clientName)
+}
+
+// RemoteLister is implemented by managers that can look up remotes
+type RemoteLister interface {
+	ListRemotes(ctx context.Context) ([]helpers.Remote, error)
+}
+
+// findRemote returns the named remote or an error listing the known ones
+func findRemote(ctx context.Context, manager RemoteLister, name string) (*helpers.Remote, error) {
+	remotes, err := manager.ListRemotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range remotes {
+		if remotes[i].Name == name {
+			return &remotes[i], nil
+		}
+	}
+	return nil, helpers.NotFoundErrorf("remote '%s' does not exist (see 'lxc-go-cli remote list')", name)
+}
+
+// addRemote adds and optionally switches to a remote server
+func addRemote(ctx context.Context, manager RemoteManager, name, addr string, opts helpers.RemoteAddOptions, use bool, streams helpers.Streams) error {
+	if err := helpers.ValidateRemoteName(name); err != nil {
+		return err
+	}
+	if err := helpers.ValidateRemoteURL(addr); err != nil {
+		return err
+	}
+
+	remotes, err := manager.ListRemotes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, remote := range remotes {
+		if remote.Name == name {
+			return helpers.ConflictErrorf("remote '%s' already exists (%s)", name, remote.Addr)
+		}
+	}
+
+	if err := manager.AddRemote(ctx, name, addr, opts, streams); err != nil {
+		return err
+	}
+	logger.Info("Remote '%s' added", name)
+
+	if use {
+		return useRemote(ctx, manager, name)
+	}
+	logger.Info("Run 'lxc-go-cli remote use %s' to operate against it", name)
+	return nil
+}
+
+// listRemotes prints the known remotes, marking the default
+func listRemotes(ctx context.Context, manager RemoteManager, out io.Writer) error {
+	remotes, err := manager.ListRemotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURL\tPROTOCOL\tAUTH\tDEFAULT")
+	for _, remote := range remotes {
+		// Image servers such as ubuntu: cannot run containers
+		if remote.Public && remote.Protocol == "simplestreams" {
+			continue
+		}
+		def := ""
+		if remote.Default {
+			def = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", remote.Name, remote.Addr,
+			valueOrDash(remote.Protocol), valueOrDash(remote.AuthType), def)
+	}
+	return w.Flush()
+}
+
+// useRemote makes a remote the default for every command
+func useRemote(ctx context.Context, manager RemoteManager, name string) error {
+	remote, err := findRemote(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	if remote.Protocol == "simplestreams" {
+		return fmt.Errorf("remote '%s' is an image server and cannot run containers", name)
+	}
+
+	if err := manager.SwitchRemote(ctx, name); err != nil {
+		return err
+	}
+	logger.Info("Now operating against remote '%s' (%s)", name, valueOrDash(remote.Addr))
+	return nil
+}
+
+// removeRemote removes a remote; the default remote must be switched away from first
+func removeRemote(ctx context.Context, manager RemoteManager, name string) error {
+	remote, err := findRemote(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	if remote.Static {
+		return fmt.Errorf("remote '%s' is built in and cannot be removed", name)
+	}
+	if remote.Default {
+		return fmt.Errorf("remote '%s' is the default; run 'lxc-go-cli remote use %s' first", name, helpers.LocalRemote)
+	}
+
+	if err := manager.RemoveRemote(ctx, name); err != nil {
+		return err
+	}
+	logger.Info("Remote '%s' removed", name)
+	return nil
+}
+
+// createRemoteToken prints a trust token a new client can use with remote add
+func createRemoteToken(ctx context.Context, manager RemoteManager, clientName string, out io.Writer) error {
+	if clientName == "" {
+		return fmt.Errorf("client name is required")
+	}
+
+	token, err := manager.CreateTrustToken(ctx, clientName)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, token)
+	logger.Info("On the client, run: lxc-go-cli remote add <name> https://<this-host>:8443 --accept-certificate --token <token>")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(remoteCmd)
+
+	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(remoteListCmd)
+	remoteCmd.AddCommand(remoteUseCmd)
+	remoteCmd.AddCommand(remoteRemoveCmd)
+	remoteCmd.AddCommand(remoteTokenCmd)
+
+	remoteAddCmd.Flags().BoolVar(&remoteAcceptCertificate, "accept-certificate", false, "Trust the server certificate without prompting")
+	remoteAddCmd.Flags().StringVar(&remoteToken, "token", "", "Trust token created on the server with 'remote token'")
+	remoteAddCmd.Flags().BoolVar(&remoteUse, "use", false, "Make the remote the default after adding it")
+
+	for _, sub := range []*cobra.Command{remoteListCmd, remoteUseCmd, remoteRemoveCmd, remoteTokenCmd} {
+		sub.Flags().DurationVarP(&remoteTimeout, "timeout", "t", 30*time.Second, "Timeout for the remote operation")
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockRemoteManager for testing remote command
+type MockRemoteManager struct {
+	Remotes  []helpers.Remote
+	AddErr   error
+	Added    []string
+	AddOpts  helpers.RemoteAddOptions
+	Switched string
+	Removed  string
+	Token    string
+}
+
+func (m *MockRemoteManager) ListRemotes(ctx context.Context) ([]helpers.Remote, error) {
+	return m.Remotes, nil
+}
+
+func (m *MockRemoteManager) AddRemote(ctx context.Context, name, addr string, opts helpers.RemoteAddOptions, streams helpers.Streams) error {
+	if m.AddErr != nil {
+		return m.AddErr
+	}
+	m.Added = append(m.Added, name, addr)
+	m.AddOpts = opts
+	m.Remotes = append(m.Remotes, helpers.Remote{Name: name, Addr: addr, Protocol: "lxd"})
+	return nil
+}
+
+func (m *MockRemoteManager) RemoveRemote(ctx context.Context, name string) error {
+	m.Removed = name
+	return nil
+}
+
+func (m *MockRemoteManager) SwitchRemote(ctx context.Context, name string) error {
+	m.Switched = name
+	return nil
+}
+
+func (m *MockRemoteManager) CreateTrustToken(ctx context.Context, clientName string) (string, error) {
+	if m.Token == "" {
+		return "", fmt.Errorf("not trusted")
+	}
+	return m.Token, nil
+}
+
+func newMockRemoteManager() *MockRemoteManager {
+	return &MockRemoteManager{Remotes: []helpers.Remote{
+		{Name: "images", Addr: "https://images.linuxcontainers.org", Protocol: "simplestreams", Public: true, Static: false},
+		{Name: "local", Addr: "unix://", Protocol: "lxd", AuthType: "file access", Static: true, Default: true},
+		{Name: "mylab", Addr: "https://lab:8443", Protocol: "lxd", AuthType: "tls"},
+	}}
+}
+
+func TestAddRemote(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockRemoteManager()
+	opts := helpers.RemoteAddOptions{AcceptCertificate: true, Token: "abc"}
+	if err := addRemote(ctx, manager, "work", "https://work:8443", opts, true, helpers.Streams{}); err != nil {
+		t.Fatalf("addRemote failed: %v", err)
+	}
+	if strings.Join(manager.Added, " ") != "work https://work:8443" || manager.AddOpts != opts {
+		t.Errorf("Unexpected add: %v %+v", manager.Added, manager.AddOpts)
+	}
+	if manager.Switched != "work" {
+		t.Errorf("Expected --use to switch to 'work', got %q", manager.Switched)
+	}
+
+	manager = newMockRemoteManager()
+	if err := addRemote(ctx, manager, "work", "work.example.com", helpers.RemoteAddOptions{}, false, helpers.Streams{}); err != nil {
+		t.Fatalf("addRemote with bare host failed: %v", err)
+	}
+	if manager.Switched != "" {
+		t.Error("Should not switch without --use")
+	}
+
+	errorCases := []struct {
+		name, remote, addr string
+		addErr             error
+		want               string
+	}{
+		{"existing", "mylab", "https://other:8443", nil, "already exists"},
+		{"bad name", "my:lab", "https://lab:8443", nil, "invalid remote name"},
+		{"http", "work", "http://work:8443", nil, "https"},
+		{"add fails", "work", "https://work:8443", fmt.Errorf("certificate rejected"), "certificate rejected"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := newMockRemoteManager()
+			manager.AddErr = tc.addErr
+			err := addRemote(ctx, manager, tc.remote, tc.addr, helpers.RemoteAddOptions{}, true, helpers.Streams{})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+			if manager.Switched != "" {
+				t.Error("Should not switch after a failed add")
+			}
+		})
+	}
+}
+
+func TestListRemotes(t *testing.T) {
+	var out bytes.Buffer
+	if err := listRemotes(context.Background(), newMockRemoteManager(), &out); err != nil {
+		t.Fatalf("listRemotes failed: %v", err)
+	}
+
+	output := out.String()
+	if strings.Contains(output, "images") {
+		t.Error("Public image servers should not be listed")
+	}
+	for _, want := range []string{"NAME", "mylab", "https://lab:8443", "tls"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "local") && !strings.HasSuffix(strings.TrimSpace(line), "*") {
+			t.Errorf("Expected local to be marked as default: %q", line)
+		}
+	}
+}
+
+func TestUseRemote(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockRemoteManager()
+	if err := useRemote(ctx, manager, "mylab"); err != nil {
+		t.Fatalf("useRemote failed: %v", err)
+	}
+	if manager.Switched != "mylab" {
+		t.Errorf("Expected switch to mylab, got %q", manager.Switched)
+	}
+
+	for name, want := range map[string]string{"missing": "does not exist", "images": "image server"} {
+		manager := newMockRemoteManager()
+		if err := useRemote(ctx, manager, name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("useRemote(%s): expected error containing %q, got %v", name, want, err)
+		}
+		if manager.Switched != "" {
+			t.Errorf("useRemote(%s) should not switch", name)
+		}
+	}
+}
+
+func TestRemoveRemote(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockRemoteManager()
+	if err := removeRemote(ctx, manager, "mylab"); err != nil {
+		t.Fatalf("removeRemote failed: %v", err)
+	}
+	if manager.Removed != "mylab" {
+		t.Errorf("Expected mylab to be removed, got %q", manager.Removed)
+	}
+
+	manager = newMockRemoteManager()
+	manager.Remotes[2].Default = true
+	manager.Remotes[1].Default = false
+	if err := removeRemote(ctx, manager, "mylab"); err == nil || !strings.Contains(err.Error(), "is the default") {
+		t.Errorf("Expected default remote error, got %v", err)
+	}
+	if err := removeRemote(ctx, manager, "local"); err == nil || !strings.Contains(err.Error(), "built in") {
+		t.Errorf("Expected built-in remote error, got %v", err)
+	}
+	if manager.Removed != "" {
+		t.Error("Should not remove on error")
+	}
+}
+
+func TestCreateRemoteToken(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockRemoteManager()
+	manager.Token = "eyJjbGllbnRfbmFtZSI6Im15LWxhcHRvcCJ9"
+	var out bytes.Buffer
+	if err := createRemoteToken(ctx, manager, "my-laptop", &out); err != nil {
+		t.Fatalf("createRemoteToken failed: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != manager.Token {
+		t.Errorf("Expected only the token on stdout, got %q", out.String())
+	}
+
+	if err := createRemoteToken(ctx, newMockRemoteManager(), "my-laptop", &out); err == nil {
+		t.Error("Expected error when token creation fails")
+	}
+	if err := createRemoteToken(ctx, manager, "", &out); err == nil {
+		t.Error("Expected error for empty client name")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackTimeout time.Duration
+	rollbackTo      string
+	rollbackList    bool
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <container-name>",
+	Short: "Restore a container to a snapshot",
+	Long: `Restore a container to a snapshot.
+
+Risky operations such as 'update' and 'gpu enable' take an automatic snapshot
+named pre-<operation>-<timestamp> before making changes (disable with
+--no-snapshot on those commands). Without --to, rollback restores the most
+recent automatic snapshot.
+
+Examples:
+  lxc-go-cli rollback mycontainer
+  lxc-go-cli rollback mycontainer --to pre-update-20250101-120000
+  lxc-go-cli rollback mycontainer --list`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+		defer cancel()
+
+		manager := &DefaultRollbackManager{}
+		if rollbackList {
+			return listSnapshots(ctx, manager, args[0], cmd.OutOrStdout())
+		}
+		return rollbackContainer(ctx, manager, args[0], rollbackTo)
+	},
+}
+
+// ContainerSnapshotter creates container snapshots
+type ContainerSnapshotter interface {
+	CreateSnapshot(ctx context.Context, containerName, snapshotName string) error
+}
+
+// RollbackManager interface for dependency injection
+type RollbackManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error)
+	RestoreSnapshot(ctx context.Context, containerName, snapshotName string) error
+}
+
+// DefaultRollbackManager implements RollbackManager using helpers
+type DefaultRollbackManager struct{}
+
+func (d *DefaultRollbackManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultRollbackManager) ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error) {
+	return helpers.ListSnapshots(containerName)
+}
+
+func (d *DefaultRollbackManager) RestoreSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	return helpers.RestoreSnapshot(containerName, snapshotName)
+}
+
+// snapshotBeforeOperation takes an automatic snapshot named pre-<operation>-<timestamp>
+func snapshotBeforeOperation(ctx context.Context, snapshotter ContainerSnapshotter, containerName, operation string) (string, error) {
+	snapshot := helpers.SnapshotName(helpers.AutoSnapshotPrefix+operation, time.Now())
+	logger.Info("Creating snapshot '%s' of container '%s'...", snapshot, containerName)
+	if err := snapshotter.CreateSnapshot(ctx, containerName, snapshot); err != nil {
+		return "", fmt.Errorf("failed to snapshot container '%s' before %s: %w", containerName, operation, err)
+	}
+	return snapshot, nil
+}
+
+// rollbackContainer restores a container to the named snapshot, or the latest automatic one
+func rollbackContainer(ctx context.Context, manager RollbackManager, containerName, snapshotName string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	snapshots, err := manager.ListSnapshots(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if snapshotName == "" {
+		latest := helpers.LatestAutoSnapshot(snapshots)
+		if latest == nil {
+			return fmt.Errorf("container '%s' has no automatic snapshots; use --to to pick a snapshot", containerName)
+		}
+		snapshotName = latest.Name
+	} else if !hasSnapshot(snapshots, snapshotName) {
+		return helpers.NotFoundErrorf("snapshot '%s' of container '%s' does not exist", snapshotName, containerName)
+	}
+	if err := confirm("rollback.confirm", containerName, snapshotName); err != nil {
+		return err
+	}
+
+	logger.Info("Restoring container '%s' to snapshot '%s'...", containerName, snapshotName)
+	if err := manager.RestoreSnapshot(ctx, containerName, snapshotName); err != nil {
+		return fmt.Errorf("failed to roll back container '%s': %w", containerName, err)
+	}
+
+	logger.Info("Container '%s' restored to snapshot '%s'", containerName, snapshotName)
+	return nil
+}
+
+// listSnapshots prints a container's snapshots
+func listSnapshots(ctx context.Context, manager RollbackManager, containerName string, out io.Writer) error {
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	snapshots, err := manager.ListSnapshots(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	fmt.Fprint(out, helpers.FormatSnapshots(snapshots))
+	return nil
+}
+
+func hasSnapshot(snapshots []helpers.Snapshot, name string) bool {
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().DurationVarP(&rollbackTimeout, "timeout", "t", 5*time.Minute, "Timeout for the rollback operation")
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "Snapshot to restore (default: latest automatic snapshot)")
+	rollbackCmd.Flags().BoolVar(&rollbackList, "list", false, "List snapshots instead of restoring")
+}
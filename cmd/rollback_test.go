@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockRollbackManager for testing rollback command
+type MockRollbackManager struct {
+	ExistingContainers map[string]bool
+	Snapshots          []helpers.Snapshot
+	ListError          error
+	RestoreError       error
+	Restored           string
+}
+
+func (m *MockRollbackManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockRollbackManager) ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.Snapshots, nil
+}
+
+func (m *MockRollbackManager) RestoreSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	if m.RestoreError != nil {
+		return m.RestoreError
+	}
+	m.Restored = snapshotName
+	return nil
+}
+
+func TestRollbackCommand(t *testing.T) {
+	if rollbackCmd == nil {
+		t.Fatal("rollbackCmd should not be nil")
+	}
+	if rollbackCmd.Use != "rollback <container-name>" {
+		t.Errorf("expected Use to be 'rollback <container-name>', got '%s'", rollbackCmd.Use)
+	}
+	for _, name := range []string{"to", "list", "timeout"} {
+		if rollbackCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+}
+
+func TestRollbackContainer(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := []helpers.Snapshot{
+		{Name: "pre-update-20250101-120000", CreatedAt: base},
+		{Name: "pre-gpu-enable-20250102-120000", CreatedAt: base.Add(24 * time.Hour)},
+		{Name: "manual", CreatedAt: base.Add(48 * time.Hour)},
+	}
+
+	tests := []struct {
+		name            string
+		containerName   string
+		to              string
+		snapshots       []helpers.Snapshot
+		listError       error
+		restoreError    error
+		expectedError   string
+		expectedRestore string
+	}{
+		{name: "empty container name", expectedError: "container name is required"},
+		{name: "missing container", containerName: "missing", expectedError: "container 'missing' does not exist"},
+		{
+			name:            "latest automatic snapshot",
+			containerName:   "web",
+			snapshots:       snapshots,
+			expectedRestore: "pre-gpu-enable-20250102-120000",
+		},
+		{
+			name:            "explicit snapshot",
+			containerName:   "web",
+			to:              "manual",
+			snapshots:       snapshots,
+			expectedRestore: "manual",
+		},
+		{
+			name:          "unknown snapshot",
+			containerName: "web",
+			to:            "nope",
+			snapshots:     snapshots,
+			expectedError: "snapshot 'nope' of container 'web' does not exist",
+		},
+		{
+			name:          "no automatic snapshots",
+			containerName: "web",
+			snapshots:     []helpers.Snapshot{{Name: "manual", CreatedAt: base}},
+			expectedError: "has no automatic snapshots",
+		},
+		{
+			name:          "list fails",
+			containerName: "web",
+			listError:     fmt.Errorf("lxc failed"),
+			expectedError: "failed to list snapshots",
+		},
+		{
+			name:          "restore fails",
+			containerName: "web",
+			snapshots:     snapshots,
+			restoreError:  fmt.Errorf("lxc failed"),
+			expectedError: "failed to roll back container 'web'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockRollbackManager{
+				ExistingContainers: map[string]bool{"web": true},
+				Snapshots:          tt.snapshots,
+				ListError:          tt.listError,
+				RestoreError:       tt.restoreError,
+			}
+
+			err := rollbackContainer(context.Background(), manager, tt.containerName, tt.to)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if manager.Restored != tt.expectedRestore {
+				t.Errorf("expected restore of '%s', got '%s'", tt.expectedRestore, manager.Restored)
+			}
+		})
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	manager := &MockRollbackManager{
+		ExistingContainers: map[string]bool{"web": true},
+		Snapshots:          []helpers.Snapshot{{Name: "pre-update-20250101-120000", CreatedAt: time.Now()}},
+	}
+
+	var out bytes.Buffer
+	if err := listSnapshots(context.Background(), manager, "web", &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "pre-update-20250101-120000") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	if err := listSnapshots(context.Background(), manager, "missing", &out); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestDefaultRollbackManager(t *testing.T) {
+	// Test that DefaultRollbackManager implements RollbackManager interface
+	var manager RollbackManager = &DefaultRollbackManager{}
+	_ = manager
+}
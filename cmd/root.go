@@ -4,14 +4,25 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
 	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logLevel string
+	logLevel    string
+	quietOutput bool
+	plainOutput bool
+	driverFlag  string
+	useSudo     bool
+	projectFlag string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -21,18 +32,122 @@ var rootCmd = &cobra.Command{
 	Long: `lxc-go-cli is a cli tool to create and manage containers for docker.
 	It is a wrapper around the lxc cli tool to create and manage containers with the
 	btrfs storage backend. Docker and Docker Compose V2 are installed from Docker's official repository.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Initialize logging level from flag
 		logger.SetLevelFromString(logLevel)
+		if err := applyProgress(cmd); err != nil {
+			return err
+		}
+		helpers.SetQuietOutput(quietOutput)
+		render.SetPlain(plainOutput)
+		helpers.SetSudo(useSudo)
+
+		// Talk to LXD or Incus, then target the active context and project, if any
+		if err := applyDriver(); err != nil {
+			return err
+		}
+		if err := applyContext(cmd); err != nil {
+			return err
+		}
+		if err := applyProject(cmd); err != nil {
+			return err
+		}
+		if err := checkLXC(cmd); err != nil {
+			return err
+		}
+		if err := applyAssumeYes(cmd); err != nil {
+			return err
+		}
+		return applyPasswordPolicy(cmd)
 	},
 }
 
+// applyDriver selects LXD or Incus from --driver, LXC_GO_CLI_DRIVER or the
+// installed client
+func applyDriver() error {
+	driver, err := helpers.ResolveDriver(driverFlag)
+	if err != nil {
+		return err
+	}
+	return helpers.UseDriver(driver)
+}
+
+// skipLXCCheckAnnotation marks commands, and their subcommands, that work
+// without a usable lxc client
+const skipLXCCheckAnnotation = "lxc-go-cli/skip-lxc-check"
+
+// lxcAvailable checks the lxc client before a command runs; tests replace it
+var lxcAvailable = helpers.CheckLXCAvailable
+
+// checkLXC fails early, with install instructions, when a command needs an
+// lxc client that is missing or cannot reach LXD
+func checkLXC(cmd *cobra.Command) error {
+	// Group commands such as 'port' on their own only print help
+	if !cmd.Runnable() {
+		return nil
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[skipLXCCheckAnnotation] == "true" {
+			return nil
+		}
+		// Built-in help and shell completion never touch LXD
+		if c.Name() == "help" || c.Name() == "completion" || strings.HasPrefix(c.Name(), "__complete") {
+			return nil
+		}
+	}
+
+	err := lxcAvailable()
+	if err == nil {
+		return nil
+	}
+	cmd.SilenceUsage = true
+
+	var unavailable *helpers.LXCUnavailableError
+	if errors.As(err, &unavailable) {
+		return &ExitError{Code: ExitEnvironment, Err: fmt.Errorf("%w\n\n%s", err, unavailable.Hint)}
+	}
+	return &ExitError{Code: ExitEnvironment, Err: err}
+}
+
+// skipLXCCheck marks a command as usable without lxc
+func skipLXCCheck(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[skipLXCCheckAnnotation] = "true"
+}
+
+// ExitError makes the process exit with a specific code, e.g. to pass on the
+// exit code of a command run inside a container
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	helpers.RecordFromEnv()
+	// A broken config file is reported by the commands that need it
+	configuredLanguage := ""
+	if settings, err := helpers.LoadSettings(); err == nil {
+		registerAliases(rootCmd, settings.Aliases)
+		configuredLanguage = settings.Language
+	}
+	applyLanguage(rootCmd, configuredLanguage)
+	markUsageErrors(rootCmd)
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		progress.Emit(ProgressEvent{Event: EventError, Error: err.Error(), Code: i18n.CodeOf(err), ExitCode: exitCode(err)})
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -43,6 +158,15 @@ func init() {
 
 	// Add persistent log level flag
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Set the logging level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "Hide output of commands run in containers unless they fail")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Print plain text without colors or symbols, e.g. for logs and scripts")
+	rootCmd.PersistentFlags().StringVar(&progressFormat, "progress", ProgressText, "Progress format: text, or json for step and log events as JSON lines on stderr")
+	rootCmd.PersistentFlags().BoolVar(&useSudo, "sudo", false, "Run privileged host commands (firewall, btrfs maintenance) through sudo, asking for the password once")
+	rootCmd.PersistentFlags().StringVar(&projectFlag, "project", "", "LXD project to run in (default: the one chosen with 'project use')")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to confirmation prompts of destructive commands, e.g. for automation")
+	rootCmd.PersistentFlags().BoolVar(&keepPartial, "keep-partial", false, "Leave the completed steps of a failed multi-step command, e.g. a half-provisioned container, instead of reverting them")
+	rootCmd.PersistentFlags().BoolVar(&exactNames, "exact", false, "Only accept exact container names; do not match case or suggest similar names")
+	rootCmd.PersistentFlags().StringVar(&driverFlag, "driver", "", "Container manager to use: lxd or incus (default: detected from the installed client)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/deji/lxc-go-cli/internal/helpers"
 	"github.com/spf13/cobra"
 )
 
@@ -149,6 +153,19 @@ func TestExecuteFunctionWithError(t *testing.T) {
 	}
 }
 
+func TestExitError(t *testing.T) {
+	inner := errors.New("command exited with status 3")
+	var err error = fmt.Errorf("exec: %w", &ExitError{Code: 3, Err: inner})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected ExitError with code 3, got %v", err)
+	}
+	if exitErr.Error() != inner.Error() || !errors.Is(err, inner) {
+		t.Errorf("ExitError should wrap the underlying error, got %v", exitErr)
+	}
+}
+
 // Test error type for testing
 type testError struct {
 	message string
@@ -157,3 +174,68 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.message
 }
+
+func TestCheckLXC(t *testing.T) {
+	previous := lxcAvailable
+	t.Cleanup(func() { lxcAvailable = previous })
+
+	missing := &helpers.LXCUnavailableError{Code: helpers.LXCErrNotFound, Reason: "lxc client not found in PATH", Hint: "sudo snap install lxd"}
+	calls := 0
+	lxcAvailable = func() error {
+		calls++
+		return missing
+	}
+
+	err := checkLXC(listCmd)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitEnvironment {
+		t.Fatalf("expected exit code %d, got %v", ExitEnvironment, err)
+	}
+	for _, want := range []string{helpers.LXCErrNotFound, "sudo snap install lxd"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in the error, got %q", want, err.Error())
+		}
+	}
+	if !errors.Is(err, missing) {
+		t.Error("expected the error to wrap the detection error")
+	}
+	if !listCmd.SilenceUsage {
+		t.Error("expected usage to be silenced")
+	}
+	listCmd.SilenceUsage = false
+
+	// Commands that work without lxc, and group commands that only print help, skip the check
+	for _, cmd := range []*cobra.Command{versionCmd, doctorCmd, contextShowCmd, passwordGenerateCmd, portCmd, rootCmd} {
+		if err := checkLXC(cmd); err != nil {
+			t.Errorf("%s: expected no check, got %v", cmd.CommandPath(), err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected lxc to be checked once, got %d", calls)
+	}
+
+	lxcAvailable = func() error { return nil }
+	if err := checkLXC(listCmd); err != nil {
+		t.Errorf("expected no error with lxc available, got %v", err)
+	}
+}
+
+func TestApplyDriver(t *testing.T) {
+	t.Cleanup(func() {
+		driverFlag = ""
+		helpers.UseDriver(helpers.DriverLXD)
+	})
+
+	driverFlag = "incus"
+	if err := applyDriver(); err != nil {
+		t.Fatalf("applyDriver failed: %v", err)
+	}
+	if helpers.CurrentDriver() != helpers.DriverIncus {
+		t.Errorf("expected the incus driver, got %s", helpers.CurrentDriver())
+	}
+
+	driverFlag = "podman"
+	if err := applyDriver(); err == nil {
+		t.Error("expected an invalid driver to fail")
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runName        string
+	runImage       string
+	runSize        string
+	runStoragePool string
+	runMounts      []string
+	runWorkdir     string
+	runKeep        bool
+	runTimeout     time.Duration
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run [flags] -- <command...>",
+	Short: "Run a command in a throwaway container and destroy it",
+	Long: `Create an ephemeral, fully provisioned container, run one command in it as
+the 'app' user, then destroy the container. The command's exit code becomes
+the exit code of lxc-go-cli, so run works as a CI executor.
+
+--mount shares a host directory with the container (repeatable). Ownership is
+mapped with an idmapped mount, so files written by the 'app' user belong to
+the host user. The command runs in --workdir, which defaults to the first
+mount's container path.
+
+The container is destroyed even if the command fails or is interrupted; use
+--keep to leave it running for debugging. It is ephemeral either way, so
+stopping it deletes it.
+
+Examples:
+  lxc-go-cli run --image ubuntu:24.04 --mount .:/src -- docker compose -f /src/ci.yml up --exit-code-from tests
+  lxc-go-cli run --mount .:/src --timeout 30m -- make test`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return fmt.Errorf("no command provided after '--'")
+		}
+		if dash != 0 {
+			return fmt.Errorf("unexpected arguments before '--': %s", strings.Join(args[:dash], " "))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Interrupting still destroys the container
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		if runTimeout > 0 {
+			var timeoutCancel context.CancelFunc
+			ctx, timeoutCancel = context.WithTimeout(ctx, runTimeout)
+			defer timeoutCancel()
+		}
+
+		// A failing command is reported through the exit code, not usage help
+		cmd.SilenceUsage = true
+		return runInContainer(ctx, &DefaultRunManager{}, RunOptions{
+			Name:        runName,
+			Image:       runImage,
+			Size:        runSize,
+			StoragePool: runStoragePool,
+			Mounts:      runMounts,
+			Workdir:     runWorkdir,
+			Keep:        runKeep,
+			Command:     args[cmd.ArgsLenAtDash():],
+		}, execStreams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr})
+	},
+}
+
+// RunOptions holds the settings for a run
+type RunOptions struct {
+	// Name of the container; empty generates one
+	Name        string
+	Image       string
+	Size        string
+	StoragePool string
+	// Mounts are host:container directory pairs
+	Mounts []string
+	// Workdir is where the command runs; empty means the first mount, or
+	// the 'app' user's home without mounts
+	Workdir string
+	// Keep leaves the container running after the command
+	Keep    bool
+	Command []string
+}
+
+// RunManager interface for dependency injection
+type RunManager interface {
+	ContainerManager
+	AddDevice(containerName, deviceName, deviceType string, properties map[string]string) error
+	RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error
+}
+
+// DefaultRunManager implements RunManager using helpers
+type DefaultRunManager struct {
+	DefaultContainerManager
+}
+
+func (d *DefaultRunManager) AddDevice(containerName, deviceName, deviceType string, properties map[string]string) error {
+	return helpers.AddContainerDevice(containerName, deviceName, deviceType, properties)
+}
+
+func (d *DefaultRunManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	cmdArgs := append([]string{"exec", containerName, "-T", "--"}, args...)
+	logger.Debug("Executing: lxc %s", strings.Join(cmdArgs, " "))
+	return helpers.Runner().RunStreaming(ctx, helpers.Streams{Stdin: streams.Stdin, Stdout: streams.Stdout, Stderr: streams.Stderr}, "lxc", cmdArgs...)
+}
+
+// runCommandScript builds the command that runs as app in workdir
+func runCommandScript(workdir string, command []string) []string {
+	script := "cd " + helpers.ShellQuote(workdir) + " && exec"
+	for _, arg := range command {
+		script += " " + helpers.ShellQuote(arg)
+	}
+	return []string{"su", "-", "app", "-c", script}
+}
+
+// runInContainer creates an ephemeral container, runs the command in it and
+// destroys it, passing on the command's exit code
+func runInContainer(ctx context.Context, manager RunManager, opts RunOptions, streams execStreams) error {
+	if len(opts.Command) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+
+	mounts := make([]helpers.Mount, 0, len(opts.Mounts))
+	for _, spec := range opts.Mounts {
+		mount, err := helpers.ParseMount(spec)
+		if err != nil {
+			return err
+		}
+		mounts = append(mounts, mount)
+	}
+
+	workdir := opts.Workdir
+	if workdir == "" {
+		workdir = "/home/app"
+		if len(mounts) > 0 {
+			workdir = mounts[0].Path
+		}
+	}
+
+	name := opts.Name
+	if name == "" || name == helpers.AutoContainerName {
+		name = helpers.RandomContainerName("run")
+	} else if err := helpers.ValidateContainerName(name); err != nil {
+		return err
+	}
+	// Never destroy a container run did not create
+	if manager.ContainerExists(name) {
+		return helpers.ConflictErrorf("container '%s' already exists", name)
+	}
+
+	defer func() {
+		if opts.Keep {
+			logger.Info("Keeping container '%s'; delete it with 'lxc-go-cli delete %s'", name, name)
+			return
+		}
+		destroyRunContainer(manager, name)
+	}()
+
+	if err := createContainerWithOptions(manager, CreateOptions{
+		Name:        name,
+		Image:       opts.Image,
+		Size:        opts.Size,
+		StoragePool: opts.StoragePool,
+		Ephemeral:   true,
+		Parallel:    defaultStepParallel,
+	}); err != nil {
+		return err
+	}
+
+	for i, mount := range mounts {
+		device := fmt.Sprintf("run-mount-%d", i)
+		logger.Info("Mounting %s at %s...", mount.Source, mount.Path)
+		if err := manager.AddDevice(name, device, "disk", mount.DeviceProperties()); err != nil {
+			return fmt.Errorf("failed to mount %s: %w", mount.Source, err)
+		}
+	}
+
+	logger.Info("Running '%s' in container '%s'...", strings.Join(opts.Command, " "), name)
+	err := manager.RunNonInteractive(ctx, name, streams, runCommandScript(workdir, opts.Command)...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return helpers.TimeoutErrorf("command timed out in container '%s'", name)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("run interrupted")
+	}
+	if code := helpers.ExitCodeFromError(err); code > 0 {
+		return &ExitError{Code: code, Err: fmt.Errorf("command exited with status %d in container '%s'", code, name)}
+	}
+	return fmt.Errorf("failed to run command in container '%s': %w", name, err)
+}
+
+// destroyRunContainer deletes the container if it was created; failures are
+// only logged so they do not hide the command's result
+func destroyRunContainer(manager RunManager, name string) {
+	if !manager.ContainerExists(name) {
+		return
+	}
+	logger.Info("Destroying container '%s'...", name)
+	if err := manager.DeleteContainer(name); err != nil {
+		logger.Warn("Failed to destroy container '%s': %v", name, err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&runName, "name", "n", "", "Container name (default: run-<random>)")
+	runCmd.Flags().StringVarP(&runImage, "image", "i", "ubuntu:24.04", "Container image")
+	runCmd.Flags().StringVarP(&runSize, "size", "s", "10G", "Storage size")
+	runCmd.Flags().StringVar(&runStoragePool, "storage-pool", "", "Storage pool to use (default: first Btrfs pool found, created if missing)")
+	runCmd.Flags().StringArrayVarP(&runMounts, "mount", "m", nil, "Share a host directory, as <host-dir>:<container-path> (repeatable)")
+	runCmd.Flags().StringVarP(&runWorkdir, "workdir", "w", "", "Directory to run the command in (default: the first mount)")
+	runCmd.Flags().BoolVar(&runKeep, "keep", false, "Leave the container running after the command")
+	runCmd.Flags().DurationVarP(&runTimeout, "timeout", "t", 0, "Timeout for the command (default: no limit)")
+}
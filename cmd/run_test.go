@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// MockRunManager implements RunManager for testing
+type MockRunManager struct {
+	*MockContainerManager
+	Created  bool
+	Devices  map[string]map[string]string
+	RunArgs  []string
+	RunError error
+	Deleted  []string
+}
+
+func newMockRunManager() *MockRunManager {
+	m := &MockRunManager{Devices: make(map[string]map[string]string)}
+	m.MockContainerManager = newResumeManager("", new([]string), make(map[string]string))
+	m.ContainerExistsFunc = func(name string) bool { return m.Created }
+	// Create runs independent steps concurrently, so nothing is recorded
+	m.ConfigureContainerSecurityFunc = func(containerName string) error { return nil }
+	m.RunInContainerFunc = func(ctx context.Context, containerName string, args ...string) error { return nil }
+	m.CreateEphemeralContainerFunc = func(name, distro, release, arch, storagePool string) error {
+		m.Created = true
+		return nil
+	}
+	return m
+}
+
+func (m *MockRunManager) AddDevice(containerName, deviceName, deviceType string, properties map[string]string) error {
+	m.Devices[deviceName] = properties
+	return nil
+}
+
+func (m *MockRunManager) RunNonInteractive(ctx context.Context, containerName string, streams execStreams, args ...string) error {
+	m.RunArgs = args
+	return m.RunError
+}
+
+func (m *MockRunManager) DeleteContainer(name string) error {
+	m.Deleted = append(m.Deleted, name)
+	return nil
+}
+
+func TestRunCommand(t *testing.T) {
+	for _, name := range []string{"image", "mount", "name", "workdir", "keep", "timeout"} {
+		if runCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected run to have a --%s flag", name)
+		}
+	}
+	if flag := runCmd.Flags().Lookup("image"); flag.DefValue != "ubuntu:24.04" {
+		t.Errorf("expected the image to default to ubuntu:24.04, got %s", flag.DefValue)
+	}
+}
+
+func TestRunCommandScript(t *testing.T) {
+	got := runCommandScript("/src", []string{"docker", "compose", "-f", "/src/ci.yml", "up", "--exit-code-from", "tests"})
+	want := []string{"su", "-", "app", "-c", `cd '/src' && exec 'docker' 'compose' '-f' '/src/ci.yml' 'up' '--exit-code-from' 'tests'`}
+	if strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunInContainer(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+
+	t.Run("success", func(t *testing.T) {
+		manager := newMockRunManager()
+		err := runInContainer(ctx, manager, RunOptions{Name: "ci", Mounts: []string{src + ":/src"}, Command: []string{"make", "test"}}, execStreams{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if device := manager.Devices["run-mount-0"]; device["source"] != src || device["path"] != "/src" || device["shift"] != "true" {
+			t.Errorf("unexpected mount device %v", device)
+		}
+		if len(manager.RunArgs) == 0 || !strings.HasPrefix(manager.RunArgs[len(manager.RunArgs)-1], "cd '/src' && exec") {
+			t.Errorf("expected the command to run in the first mount, got %q", manager.RunArgs)
+		}
+		if len(manager.Deleted) != 1 || manager.Deleted[0] != "ci" {
+			t.Errorf("expected the container to be destroyed, got %v", manager.Deleted)
+		}
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		manager := newMockRunManager()
+		manager.RunError = exec.Command("sh", "-c", "exit 3").Run()
+		err := runInContainer(ctx, manager, RunOptions{Command: []string{"false"}}, execStreams{})
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+			t.Errorf("expected exit code 3 to be preserved, got %v", err)
+		}
+		if len(manager.Deleted) != 1 || !strings.HasPrefix(manager.Deleted[0], "run-") {
+			t.Errorf("expected the generated container to be destroyed, got %v", manager.Deleted)
+		}
+		if !strings.HasSuffix(manager.RunArgs[len(manager.RunArgs)-1], "cd '/home/app' && exec 'false'") {
+			t.Errorf("expected the command to run in the app home, got %q", manager.RunArgs)
+		}
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		manager := newMockRunManager()
+		if err := runInContainer(ctx, manager, RunOptions{Name: "ci", Keep: true, Command: []string{"true"}}, execStreams{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Deleted) != 0 {
+			t.Errorf("expected --keep to leave the container, got %v", manager.Deleted)
+		}
+	})
+
+	t.Run("existing container", func(t *testing.T) {
+		manager := newMockRunManager()
+		manager.Created = true
+		err := runInContainer(ctx, manager, RunOptions{Name: "web", Command: []string{"true"}}, execStreams{})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("expected an existing container error, got %v", err)
+		}
+		if len(manager.Deleted) != 0 {
+			t.Errorf("expected an existing container never to be destroyed, got %v", manager.Deleted)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		manager := newMockRunManager()
+		err := runInContainer(ctx, manager, RunOptions{Name: "ci_job", Command: []string{"true"}}, execStreams{})
+		if err == nil || !strings.Contains(err.Error(), "try 'ci-job'") {
+			t.Errorf("expected an invalid name error, got %v", err)
+		}
+		if manager.Created || len(manager.Deleted) != 0 {
+			t.Error("expected nothing to be created or destroyed")
+		}
+	})
+
+	t.Run("invalid mount", func(t *testing.T) {
+		manager := newMockRunManager()
+		err := runInContainer(ctx, manager, RunOptions{Mounts: []string{src}, Command: []string{"true"}}, execStreams{})
+		if err == nil || !strings.Contains(err.Error(), "invalid mount") {
+			t.Errorf("expected an invalid mount error, got %v", err)
+		}
+		if manager.Created {
+			t.Error("expected no container to be created for an invalid mount")
+		}
+	})
+}
@@ -0,0 +1,282 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretAsEnv     bool
+	secretService   string
+	secretOwner     string
+	secretTimeout   time.Duration
+	secretListTable tableFlags
+)
+
+// maxSecretInput bounds the value read by secret set
+const maxSecretInput = 64 * 1024
+
+// secretCmd represents the secret command
+var secretCmd = &cobra.Command{
+	Use:   "secret <set|list|rm|inject>",
+	Short: "Store encrypted secrets for containers and inject them at deploy time",
+	Long: `Store credentials such as database passwords encrypted with age on the
+host, and inject them into a container's /run tmpfs only when a service needs
+them. Unlike the generated 'app' password they never live unencrypted in the
+container's config or metadata, and they are gone when the container stops.
+
+The age key is created on first use in this tool's config dir
+(secrets/identity.txt); back it up, secrets cannot be read without it.
+
+Available subcommands:
+  set     - Store a secret read from stdin
+  list    - List a container's secrets
+  rm      - Delete a secret
+  inject  - Write the secrets into the container for a service
+
+Examples:
+  echo -n "s3cret" | lxc-go-cli secret set web DB_PASSWORD -
+  lxc-go-cli secret inject web --as-env --service app
+  lxc-go-cli compose up web --secrets`,
+}
+
+// secretSetCmd represents the secret set subcommand
+var secretSetCmd = &cobra.Command{
+	Use:   "set <container-name> <NAME> -",
+	Short: "Store a secret read from stdin",
+	Long: `Encrypt a value read from stdin and store it as a secret of the
+container. The trailing '-' stands for stdin: values are never taken from the
+command line, where they would end up in the shell history and process list.
+A single trailing newline is dropped. Setting an existing secret replaces it.
+
+Examples:
+  echo -n "s3cret" | lxc-go-cli secret set web DB_PASSWORD -
+  lxc-go-cli secret set web TLS_KEY - < server.key`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[2] != "-" {
+			return fmt.Errorf("pass the value on stdin with '-': secret values are not accepted on the command line")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+		defer cancel()
+
+		return setSecret(ctx, &DefaultSecretManager{}, args[0], args[1], cmd.InOrStdin())
+	},
+}
+
+// secretListCmd represents the secret list subcommand
+var secretListCmd = &cobra.Command{
+	Use:   "list <container-name>",
+	Short: "List a container's secrets",
+	Long: `List the names of a container's secrets and when they were last set.
+Values are never shown.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		return listSecrets(&DefaultSecretManager{}, args[0], out, secretListTable.options(out))
+	},
+}
+
+// secretRmCmd represents the secret rm subcommand
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <container-name> <NAME>",
+	Short: "Delete a secret",
+	Long: `Delete a stored secret. Copies already injected into the container stay
+until it restarts or the next inject.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := (&DefaultSecretManager{}).DeleteSecret(args[0], args[1]); err != nil {
+			return err
+		}
+		logger.Info("Deleted secret '%s' of container '%s'", args[1], args[0])
+		return nil
+	},
+}
+
+// secretInjectCmd represents the secret inject subcommand
+var secretInjectCmd = &cobra.Command{
+	Use:   "inject <container-name>",
+	Short: "Write the secrets into the container for a service",
+	Long: `Decrypt a container's secrets and write them into its /run tmpfs for a
+service, readable only by --owner (the 'app' user by default):
+
+  default   one file per secret, /run/lxc-go-cli/secrets/<service>/<NAME>,
+            for compose 'secrets:' entries with 'file:'
+  --as-env  a single env file, /run/lxc-go-cli/secrets/<service>/secrets.env,
+            for a compose 'env_file:'
+
+Nothing is stored in the container's config, and /run is cleared when the
+container restarts, so run inject as a deploy step before 'compose up'.
+'compose up --secrets' passes the secrets to compose directly instead.
+
+Examples:
+  lxc-go-cli secret inject web --as-env --service app
+  lxc-go-cli secret inject web --service db --owner root`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+		defer cancel()
+
+		return injectSecrets(ctx, &DefaultSecretManager{}, args[0], secretService, secretOwner, secretAsEnv, cmd.OutOrStdout())
+	},
+}
+
+// SecretManager interface for dependency injection
+type SecretManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	SetSecret(ctx context.Context, containerName, name string, value []byte) error
+	ListSecrets(containerName string) ([]helpers.SecretInfo, error)
+	DeleteSecret(containerName, name string) error
+	LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error)
+	PushSecretFile(ctx context.Context, containerName, file, owner string, data []byte) error
+}
+
+// DefaultSecretManager implements SecretManager using helpers
+type DefaultSecretManager struct{}
+
+func (d *DefaultSecretManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultSecretManager) SetSecret(ctx context.Context, containerName, name string, value []byte) error {
+	return helpers.SetSecret(ctx, containerName, name, value)
+}
+
+func (d *DefaultSecretManager) ListSecrets(containerName string) ([]helpers.SecretInfo, error) {
+	return helpers.ListSecrets(containerName)
+}
+
+func (d *DefaultSecretManager) DeleteSecret(containerName, name string) error {
+	return helpers.DeleteSecret(containerName, name)
+}
+
+func (d *DefaultSecretManager) LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error) {
+	return helpers.LoadSecrets(ctx, containerName)
+}
+
+func (d *DefaultSecretManager) PushSecretFile(ctx context.Context, containerName, file, owner string, data []byte) error {
+	return helpers.PushSecretFile(ctx, containerName, file, owner, data)
+}
+
+// setSecret reads a secret from stdin and stores it encrypted
+func setSecret(ctx context.Context, manager SecretManager, containerName, name string, stdin io.Reader) error {
+	if err := helpers.ValidateSecretName(name); err != nil {
+		return err
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(stdin, maxSecretInput+1))
+	if err != nil {
+		return fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	if len(data) > maxSecretInput {
+		return fmt.Errorf("secret on stdin is longer than %d bytes", maxSecretInput)
+	}
+	// Drop the newline left by echo or a file, but keep any others
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		return fmt.Errorf("no secret on stdin")
+	}
+
+	if err := manager.SetSecret(ctx, containerName, name, []byte(value)); err != nil {
+		return err
+	}
+	logger.Info("Stored secret '%s' for container '%s'", name, containerName)
+	return nil
+}
+
+// listSecrets prints the names of a container's secrets
+func listSecrets(manager SecretManager, containerName string, out io.Writer, opts render.Options) error {
+	secrets, err := manager.ListSecrets(containerName)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		fmt.Fprintf(out, "Container '%s' has no secrets\n", containerName)
+		return nil
+	}
+
+	table := render.NewTable("NAME", "UPDATED")
+	for _, secret := range secrets {
+		table.AddRow(secret.Name, secret.Updated.Format("2006-01-02 15:04"))
+	}
+	return table.Render(out, opts)
+}
+
+// injectSecrets writes a container's secrets into its tmpfs for a service
+func injectSecrets(ctx context.Context, manager SecretManager, containerName, service, owner string, asEnv bool, out io.Writer) error {
+	if service == "" || strings.ContainsAny(service, "/ ") || service == "." || service == ".." {
+		return helpers.UsageErrorf("invalid service name '%s'", service)
+	}
+	if owner == "" {
+		return helpers.UsageErrorf("--owner is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	vars, err := manager.LoadSecrets(ctx, containerName)
+	if err != nil {
+		return err
+	}
+	if len(vars) == 0 {
+		return helpers.NotFoundErrorf("container '%s' has no secrets (see 'lxc-go-cli secret set')", containerName)
+	}
+
+	dir := helpers.SecretsServiceDir(service)
+	if asEnv {
+		data, err := helpers.SecretsEnvData(vars)
+		if err != nil {
+			return err
+		}
+		file := path.Join(dir, helpers.SecretsEnvFile)
+		if err := manager.PushSecretFile(ctx, containerName, file, owner, data); err != nil {
+			return err
+		}
+		logger.Info("Injected %d secret(s) into %s in container '%s'", len(vars), file, containerName)
+		fmt.Fprintf(out, "%s\n", file)
+		return nil
+	}
+
+	for _, v := range vars {
+		file := path.Join(dir, v.Key)
+		if err := manager.PushSecretFile(ctx, containerName, file, owner, []byte(v.Value)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s\n", file)
+	}
+	logger.Info("Injected %d secret(s) into %s in container '%s'", len(vars), dir, containerName)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretRmCmd)
+	secretCmd.AddCommand(secretInjectCmd)
+
+	secretInjectCmd.Flags().BoolVar(&secretAsEnv, "as-env", false, "Write a single env file instead of one file per secret")
+	secretInjectCmd.Flags().StringVar(&secretService, "service", "app", "Service the secrets are for; names the directory under "+helpers.SecretsRunDir)
+	secretInjectCmd.Flags().StringVar(&secretOwner, "owner", "app", "User in the container that may read the secrets")
+
+	for _, sub := range []*cobra.Command{secretSetCmd, secretInjectCmd} {
+		sub.Flags().DurationVarP(&secretTimeout, "timeout", "t", 60*time.Second, "Timeout for the secret operation")
+	}
+	addTableFlags(secretListCmd, &secretListTable)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockSecretManager for testing secret command
+type MockSecretManager struct {
+	Existing map[string]bool
+	Stored   map[string]string
+	Pushed   map[string]string
+	Owners   []string
+}
+
+func (m *MockSecretManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.Existing[name]
+}
+
+func (m *MockSecretManager) SetSecret(ctx context.Context, containerName, name string, value []byte) error {
+	if m.Stored == nil {
+		m.Stored = make(map[string]string)
+	}
+	m.Stored[name] = string(value)
+	return nil
+}
+
+func (m *MockSecretManager) ListSecrets(containerName string) ([]helpers.SecretInfo, error) {
+	var secrets []helpers.SecretInfo
+	for _, name := range []string{"API_KEY", "DB_PASSWORD"} {
+		if _, ok := m.Stored[name]; ok {
+			secrets = append(secrets, helpers.SecretInfo{Name: name, Updated: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)})
+		}
+	}
+	return secrets, nil
+}
+
+func (m *MockSecretManager) DeleteSecret(containerName, name string) error {
+	delete(m.Stored, name)
+	return nil
+}
+
+func (m *MockSecretManager) LoadSecrets(ctx context.Context, containerName string) ([]helpers.EnvVar, error) {
+	secrets, _ := m.ListSecrets(containerName)
+	var vars []helpers.EnvVar
+	for _, secret := range secrets {
+		vars = append(vars, helpers.EnvVar{Key: secret.Name, Value: m.Stored[secret.Name]})
+	}
+	return vars, nil
+}
+
+func (m *MockSecretManager) PushSecretFile(ctx context.Context, containerName, file, owner string, data []byte) error {
+	if m.Pushed == nil {
+		m.Pushed = make(map[string]string)
+	}
+	m.Pushed[file] = string(data)
+	m.Owners = append(m.Owners, owner)
+	return nil
+}
+
+func TestSetSecret(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockSecretManager{Existing: map[string]bool{"web": true}}
+
+	if err := setSecret(ctx, manager, "web", "DB_PASSWORD", strings.NewReader("s3cret\n")); err != nil {
+		t.Fatal(err)
+	}
+	if manager.Stored["DB_PASSWORD"] != "s3cret" {
+		t.Errorf("expected the trailing newline to be dropped, got %q", manager.Stored["DB_PASSWORD"])
+	}
+	if err := setSecret(ctx, manager, "web", "TLS_KEY", strings.NewReader("line1\nline2\n")); err != nil || manager.Stored["TLS_KEY"] != "line1\nline2" {
+		t.Errorf("expected inner newlines to be kept, got %q, %v", manager.Stored["TLS_KEY"], err)
+	}
+
+	for _, tt := range []struct {
+		container, name, stdin, want string
+	}{
+		{"web", "DB-PASSWORD", "x", "invalid secret name"},
+		{"db", "DB_PASSWORD", "x", "does not exist"},
+		{"web", "DB_PASSWORD", "\n", "no secret on stdin"},
+		{"web", "DB_PASSWORD", strings.Repeat("x", maxSecretInput+1), "longer than"},
+	} {
+		if err := setSecret(ctx, manager, tt.container, tt.name, strings.NewReader(tt.stdin)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected %q, got %v", tt.want, err)
+		}
+	}
+}
+
+func TestListSecrets(t *testing.T) {
+	manager := &MockSecretManager{Stored: map[string]string{"DB_PASSWORD": "s3cret"}}
+	var out bytes.Buffer
+	if err := listSecrets(manager, "web", &out, render.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "DB_PASSWORD") || !strings.Contains(out.String(), "2026-01-02 03:04") || strings.Contains(out.String(), "s3cret") {
+		t.Errorf("unexpected list:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := listSecrets(&MockSecretManager{}, "web", &out, render.Options{}); err != nil || !strings.Contains(out.String(), "no secrets") {
+		t.Errorf("expected an empty notice, got %v:\n%s", err, out.String())
+	}
+}
+
+func TestInjectSecrets(t *testing.T) {
+	ctx := context.Background()
+	stored := map[string]string{"DB_PASSWORD": "s3cret", "API_KEY": "k3y"}
+
+	manager := &MockSecretManager{Existing: map[string]bool{"web": true}, Stored: stored}
+	var out bytes.Buffer
+	if err := injectSecrets(ctx, manager, "web", "app", "app", true, &out); err != nil {
+		t.Fatal(err)
+	}
+	envFile := "/run/lxc-go-cli/secrets/app/secrets.env"
+	if manager.Pushed[envFile] != "API_KEY='k3y'\nDB_PASSWORD='s3cret'\n" || strings.TrimSpace(out.String()) != envFile {
+		t.Errorf("unexpected env file %v, output %q", manager.Pushed, out.String())
+	}
+
+	manager = &MockSecretManager{Existing: map[string]bool{"web": true}, Stored: stored}
+	if err := injectSecrets(ctx, manager, "web", "db", "root", false, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if manager.Pushed["/run/lxc-go-cli/secrets/db/DB_PASSWORD"] != "s3cret" || len(manager.Pushed) != 2 || manager.Owners[0] != "root" {
+		t.Errorf("expected one file per secret owned by root, got %v, %v", manager.Pushed, manager.Owners)
+	}
+
+	manager = &MockSecretManager{Existing: map[string]bool{"web": true}, Stored: map[string]string{"DB_PASSWORD": "it's"}}
+	if err := injectSecrets(ctx, manager, "web", "app", "app", true, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "without --as-env") {
+		t.Errorf("expected a quoted value to be refused in an env file, got %v", err)
+	}
+	if err := injectSecrets(ctx, manager, "web", "../etc", "app", false, &bytes.Buffer{}); err == nil {
+		t.Error("expected an invalid service name to fail")
+	}
+	if err := injectSecrets(ctx, &MockSecretManager{Existing: map[string]bool{"web": true}}, "web", "app", "app", false, &bytes.Buffer{}); err == nil {
+		t.Error("expected a container without secrets to fail")
+	}
+}
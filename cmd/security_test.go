@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockAppArmorManager for testing security apparmor command
+type MockAppArmorManager struct {
+	ExistingContainers map[string]bool
+	Config             map[string]string
+	LoadedProfiles     map[string]bool
+	LoadedError        error
+	SetError           error
+	Calls              map[string]int
+}
+
+func (m *MockAppArmorManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockAppArmorManager) GetConfigValue(ctx context.Context, containerName, key string) (string, error) {
+	return m.Config[key], nil
+}
+
+func (m *MockAppArmorManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	m.trackCall("SetConfigValue")
+	if m.SetError != nil {
+		return m.SetError
+	}
+	m.Config[key] = value
+	return nil
+}
+
+func (m *MockAppArmorManager) UnsetConfigValue(ctx context.Context, containerName, key string) error {
+	m.trackCall("UnsetConfigValue")
+	delete(m.Config, key)
+	return nil
+}
+
+func (m *MockAppArmorManager) IsAppArmorProfileLoaded(ctx context.Context, name string) (bool, error) {
+	if m.LoadedError != nil {
+		return false, m.LoadedError
+	}
+	return m.LoadedProfiles[name], nil
+}
+
+func (m *MockAppArmorManager) RestartContainer(ctx context.Context, name string) error {
+	m.trackCall("RestartContainer")
+	return nil
+}
+
+func (m *MockAppArmorManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func TestSecurityAppArmorCommand(t *testing.T) {
+	if securityAppArmorCmd.Use != "apparmor <container-name>" {
+		t.Errorf("unexpected Use for security apparmor: '%s'", securityAppArmorCmd.Use)
+	}
+	for _, name := range []string{"profile", "reset", "timeout"} {
+		if securityAppArmorCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+}
+
+func TestConfigureAppArmor(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerName string
+		profile       string
+		reset         bool
+		config        map[string]string
+		loadedError   error
+		setError      error
+		expectedError string
+		expectedRaw   string
+		expectedRules bool
+	}{
+		{name: "empty name", expectedError: "container name is required"},
+		{name: "no action", containerName: "web", expectedError: "either --profile or --reset is required"},
+		{name: "both actions", containerName: "web", profile: "generate", reset: true, expectedError: "cannot be combined"},
+		{name: "invalid profile name", containerName: "web", profile: "bad name;", expectedError: "invalid AppArmor profile name"},
+		{name: "missing container", containerName: "missing", profile: "generate", expectedError: "container 'missing' does not exist"},
+		{
+			name:          "generate rules",
+			containerName: "web",
+			profile:       "generate",
+			config:        map[string]string{"raw.lxc": "lxc.apparmor.profile=old\nlxc.cgroup.devices.allow=a\n"},
+			expectedRaw:   "lxc.cgroup.devices.allow=a",
+			expectedRules: true,
+		},
+		{
+			name:          "attach loaded profile",
+			containerName: "web",
+			profile:       "docker-web",
+			expectedRaw:   "lxc.apparmor.profile=docker-web",
+		},
+		{
+			name:          "profile not loaded",
+			containerName: "web",
+			profile:       "not-loaded",
+			expectedError: "AppArmor profile 'not-loaded' is not loaded on the host",
+		},
+		{
+			name:          "cannot verify profile still attaches",
+			containerName: "web",
+			profile:       "not-loaded",
+			loadedError:   fmt.Errorf("permission denied"),
+			expectedRaw:   "lxc.apparmor.profile=not-loaded",
+		},
+		{
+			name:          "reset",
+			containerName: "web",
+			reset:         true,
+			config:        map[string]string{"raw.lxc": "lxc.apparmor.profile=docker-web\n", "raw.apparmor": "mount,"},
+			expectedRaw:   "",
+		},
+		{
+			name:          "set fails",
+			containerName: "web",
+			profile:       "generate",
+			setError:      fmt.Errorf("lxc failed"),
+			expectedError: "failed to apply AppArmor rules",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = map[string]string{}
+			}
+			manager := &MockAppArmorManager{
+				ExistingContainers: map[string]bool{"web": true},
+				Config:             config,
+				LoadedProfiles:     map[string]bool{"docker-web": true},
+				LoadedError:        tt.loadedError,
+				SetError:           tt.setError,
+			}
+
+			err := configureAppArmor(context.Background(), manager, tt.containerName, tt.profile, tt.reset)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := strings.TrimSpace(manager.Config["raw.lxc"]); got != tt.expectedRaw {
+				t.Errorf("expected raw.lxc '%s', got '%s'", tt.expectedRaw, got)
+			}
+			if hasRules := manager.Config["raw.apparmor"] == helpers.GenerateDockerAppArmorRules(); hasRules != tt.expectedRules {
+				t.Errorf("expected generated rules %v, got raw.apparmor '%s'", tt.expectedRules, manager.Config["raw.apparmor"])
+			}
+			if manager.Calls["RestartContainer"] != 1 {
+				t.Error("container should be restarted once")
+			}
+		})
+	}
+}
+
+func TestDefaultAppArmorManager(t *testing.T) {
+	// Test that DefaultAppArmorManager implements AppArmorManager interface
+	var manager AppArmorManager = &DefaultAppArmorManager{}
+	_ = manager
+}
@@ -0,0 +1,343 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotCron         string
+	snapshotKeep         int
+	snapshotExpiry       string
+	snapshotCronFallback bool
+	snapshotDisable      bool
+	snapshotDryRun       bool
+	snapshotTimeout      time.Duration
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot <schedule|prune>",
+	Short: "Schedule container snapshots and enforce retention",
+	Long: `Schedule container snapshots and enforce how many are kept.
+
+Available subcommands:
+  schedule  - Take snapshots on a cron schedule
+  prune     - Delete scheduled snapshots beyond the retention count
+
+Only snapshots named scheduled-<timestamp> are pruned; manual snapshots and
+the automatic pre-<operation> snapshots used by rollback are never touched.
+
+Examples:
+  lxc-go-cli snapshot schedule mycontainer --cron "0 3 * * *" --keep 7
+  lxc-go-cli snapshot prune mycontainer`,
+}
+
+// snapshotScheduleCmd represents the snapshot schedule subcommand
+var snapshotScheduleCmd = &cobra.Command{
+	Use:   "schedule <container-name>",
+	Short: "Take snapshots of a container on a cron schedule",
+	Long: `Take snapshots of a container on a cron schedule.
+
+LXD takes and expires the snapshots itself by setting snapshots.schedule,
+snapshots.pattern and snapshots.expiry on the container. LXD expires snapshots
+by age, so --keep is converted to an expiry for hourly, daily and weekly
+schedules; use --expiry for other schedules. --keep is also recorded so
+'snapshot prune' can enforce it by count.
+
+If the server lacks snapshot scheduling, or with --cron-fallback, a cron entry
+in /etc/cron.d runs the snapshot and prune instead (requires root).
+
+Examples:
+  lxc-go-cli snapshot schedule mycontainer --cron "0 3 * * *" --keep 7
+  lxc-go-cli snapshot schedule mycontainer --cron "0 */6 * * *" --expiry 2d
+  lxc-go-cli snapshot schedule mycontainer --disable`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+
+		manager := &DefaultSnapshotManager{}
+		if snapshotDisable {
+			return disableSnapshotSchedule(ctx, manager, args[0])
+		}
+		return scheduleSnapshots(ctx, manager, args[0], SnapshotScheduleOptions{
+			Cron:         snapshotCron,
+			Keep:         snapshotKeep,
+			Expiry:       snapshotExpiry,
+			CronFallback: snapshotCronFallback,
+		})
+	},
+}
+
+// snapshotPruneCmd represents the snapshot prune subcommand
+var snapshotPruneCmd = &cobra.Command{
+	Use:   "prune <container-name>",
+	Short: "Delete scheduled snapshots beyond the retention count",
+	Long: `Delete the oldest scheduled snapshots so only the newest --keep remain.
+
+Without --keep, the count recorded by 'snapshot schedule --keep' is used.
+
+Examples:
+  lxc-go-cli snapshot prune mycontainer
+  lxc-go-cli snapshot prune mycontainer --keep 3 --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+
+		return pruneSnapshots(ctx, &DefaultSnapshotManager{}, args[0], snapshotKeep, snapshotDryRun)
+	},
+}
+
+// SnapshotScheduleOptions holds the settings for snapshot schedule
+type SnapshotScheduleOptions struct {
+	Cron         string
+	Keep         int
+	Expiry       string
+	CronFallback bool
+}
+
+// SnapshotManager interface for dependency injection
+type SnapshotManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error)
+	GetConfigValue(ctx context.Context, containerName, key string) (string, error)
+	SetConfigValue(ctx context.Context, containerName, key, value string) error
+	UnsetConfigValue(ctx context.Context, containerName, key string) error
+	ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error)
+	DeleteSnapshot(ctx context.Context, containerName, snapshotName string) error
+	WriteCronEntry(containerName, entry string) error
+	RemoveCronEntry(containerName string) error
+}
+
+// DefaultSnapshotManager implements SnapshotManager using helpers
+type DefaultSnapshotManager struct{}
+
+func (d *DefaultSnapshotManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultSnapshotManager) MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error) {
+	return helpers.MissingAPIExtensions(ctx, required...)
+}
+
+func (d *DefaultSnapshotManager) GetConfigValue(ctx context.Context, containerName, key string) (string, error) {
+	return helpers.GetConfigValue(containerName, key)
+}
+
+func (d *DefaultSnapshotManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	return helpers.SetConfigValue(containerName, key, value)
+}
+
+func (d *DefaultSnapshotManager) UnsetConfigValue(ctx context.Context, containerName, key string) error {
+	return helpers.UnsetConfigValue(containerName, key)
+}
+
+func (d *DefaultSnapshotManager) ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error) {
+	return helpers.ListSnapshots(containerName)
+}
+
+func (d *DefaultSnapshotManager) DeleteSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	return helpers.DeleteSnapshot(containerName, snapshotName)
+}
+
+func (d *DefaultSnapshotManager) WriteCronEntry(containerName, entry string) error {
+	return helpers.WriteSnapshotCronEntry(containerName, entry)
+}
+
+func (d *DefaultSnapshotManager) RemoveCronEntry(containerName string) error {
+	return helpers.RemoveSnapshotCronEntry(containerName)
+}
+
+// scheduleSnapshots configures LXD (or a cron entry) to snapshot a container on a schedule
+func scheduleSnapshots(ctx context.Context, manager SnapshotManager, name string, opts SnapshotScheduleOptions) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if opts.Cron == "" {
+		return helpers.UsageErrorf("--cron is required")
+	}
+	if err := helpers.ValidateCronExpression(opts.Cron); err != nil {
+		return err
+	}
+	if opts.Keep < 0 {
+		return helpers.UsageErrorf("--keep must not be negative")
+	}
+	if !manager.ContainerExists(ctx, name) {
+		return containerNotFound(name)
+	}
+
+	fallback := opts.CronFallback
+	if !fallback {
+		missing, err := manager.MissingAPIExtensions(ctx, helpers.SnapshotScheduleExtensions...)
+		if err != nil {
+			return fmt.Errorf("failed to check snapshot scheduling support: %w", err)
+		}
+		if len(missing) > 0 {
+			logger.Warn("LXD server lacks snapshot scheduling (missing API extensions: %s); using a cron entry instead", strings.Join(missing, ", "))
+			fallback = true
+		}
+	}
+
+	if opts.Keep > 0 {
+		if err := manager.SetConfigValue(ctx, name, helpers.SnapshotKeepKey, strconv.Itoa(opts.Keep)); err != nil {
+			return fmt.Errorf("failed to record retention: %w", err)
+		}
+	}
+
+	if fallback {
+		executable, err := os.Executable()
+		if err != nil {
+			executable = "lxc-go-cli"
+		}
+		if err := manager.WriteCronEntry(name, helpers.SnapshotCronEntry(opts.Cron, name, executable, opts.Keep)); err != nil {
+			return err
+		}
+		logger.Info("Installed cron entry for scheduled snapshots of '%s' (%s)", name, opts.Cron)
+		return nil
+	}
+
+	expiry := opts.Expiry
+	if expiry == "" && opts.Keep > 0 {
+		if interval := helpers.CronInterval(opts.Cron); interval > 0 {
+			expiry = helpers.SnapshotExpiry(time.Duration(opts.Keep) * interval)
+		} else {
+			logger.Warn("Cannot convert --keep to an expiry for schedule '%s'; run 'lxc-go-cli snapshot prune %s' to enforce it", opts.Cron, name)
+		}
+	}
+
+	config := [][2]string{
+		{helpers.SnapshotScheduleKey, opts.Cron},
+		{helpers.SnapshotPatternKey, helpers.ScheduledSnapshotPattern},
+	}
+	if expiry != "" {
+		config = append(config, [2]string{helpers.SnapshotExpiryKey, expiry})
+	}
+	for _, kv := range config {
+		if err := manager.SetConfigValue(ctx, name, kv[0], kv[1]); err != nil {
+			return fmt.Errorf("failed to configure snapshot schedule: %w", err)
+		}
+	}
+
+	if expiry != "" {
+		logger.Info("Container '%s' will be snapshotted on '%s'; snapshots expire after %s", name, opts.Cron, expiry)
+	} else {
+		logger.Info("Container '%s' will be snapshotted on '%s'", name, opts.Cron)
+	}
+	return nil
+}
+
+// disableSnapshotSchedule removes the LXD schedule settings and any cron entry
+func disableSnapshotSchedule(ctx context.Context, manager SnapshotManager, name string) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, name) {
+		return containerNotFound(name)
+	}
+
+	// Unsetting a key that is not set is an error, so check first
+	for _, key := range []string{helpers.SnapshotScheduleKey, helpers.SnapshotPatternKey, helpers.SnapshotExpiryKey, helpers.SnapshotKeepKey} {
+		value, err := manager.GetConfigValue(ctx, name, key)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		if err := manager.UnsetConfigValue(ctx, name, key); err != nil {
+			return fmt.Errorf("failed to disable snapshot schedule: %w", err)
+		}
+	}
+	if err := manager.RemoveCronEntry(name); err != nil {
+		return err
+	}
+
+	logger.Info("Scheduled snapshots disabled for container '%s'; existing snapshots were kept", name)
+	return nil
+}
+
+// pruneSnapshots deletes scheduled snapshots beyond the newest keep
+func pruneSnapshots(ctx context.Context, manager SnapshotManager, name string, keep int, dryRun bool) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, name) {
+		return containerNotFound(name)
+	}
+
+	if keep <= 0 {
+		value, err := manager.GetConfigValue(ctx, name, helpers.SnapshotKeepKey)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("no retention recorded for container '%s'; pass --keep", name)
+		}
+		if keep, err = strconv.Atoi(value); err != nil || keep <= 0 {
+			return fmt.Errorf("invalid retention '%s' in %s", value, helpers.SnapshotKeepKey)
+		}
+	}
+
+	snapshots, err := manager.ListSnapshots(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	prune := helpers.SnapshotsToPrune(snapshots, keep)
+	if len(prune) == 0 {
+		logger.Info("Nothing to prune: container '%s' has %d or fewer scheduled snapshots", name, keep)
+		return nil
+	}
+	if !dryRun {
+		if err := confirm("snapshot.prune_confirm", len(prune), name); err != nil {
+			return err
+		}
+	}
+
+	for _, snapshot := range prune {
+		if dryRun {
+			logger.Info("Would delete snapshot '%s'", snapshot.Name)
+			continue
+		}
+		logger.Info("Deleting snapshot '%s'...", snapshot.Name)
+		if err := manager.DeleteSnapshot(ctx, name, snapshot.Name); err != nil {
+			return err
+		}
+	}
+
+	if !dryRun {
+		logger.Info("Pruned %d snapshot(s) of container '%s', keeping the newest %d", len(prune), name, keep)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.AddCommand(snapshotScheduleCmd)
+	snapshotCmd.AddCommand(snapshotPruneCmd)
+
+	snapshotScheduleCmd.Flags().StringVar(&snapshotCron, "cron", "", "Cron schedule, e.g. \"0 3 * * *\" or @daily")
+	snapshotScheduleCmd.Flags().IntVar(&snapshotKeep, "keep", 0, "Number of scheduled snapshots to keep")
+	snapshotScheduleCmd.Flags().StringVar(&snapshotExpiry, "expiry", "", "LXD snapshot expiry, e.g. 7d or 12H (overrides the expiry derived from --keep)")
+	snapshotScheduleCmd.Flags().BoolVar(&snapshotCronFallback, "cron-fallback", false, "Use a host cron entry even if LXD supports snapshot scheduling")
+	snapshotScheduleCmd.Flags().BoolVar(&snapshotDisable, "disable", false, "Remove the snapshot schedule")
+	snapshotScheduleCmd.Flags().DurationVarP(&snapshotTimeout, "timeout", "t", 5*time.Minute, "Timeout for the snapshot operation")
+
+	snapshotPruneCmd.Flags().IntVar(&snapshotKeep, "keep", 0, "Number of scheduled snapshots to keep (default: value recorded by schedule)")
+	snapshotPruneCmd.Flags().BoolVar(&snapshotDryRun, "dry-run", false, "Show which snapshots would be deleted")
+	snapshotPruneCmd.Flags().DurationVarP(&snapshotTimeout, "timeout", "t", 5*time.Minute, "Timeout for the prune operation")
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockSnapshotManager for testing snapshot command
+type MockSnapshotManager struct {
+	ExistingContainers map[string]bool
+	MissingExtensions  []string
+	Config             map[string]string
+	Snapshots          []helpers.Snapshot
+	Deleted            []string
+	CronEntries        map[string]string
+}
+
+func newMockSnapshotManager() *MockSnapshotManager {
+	return &MockSnapshotManager{
+		ExistingContainers: map[string]bool{"web": true},
+		Config:             map[string]string{},
+		CronEntries:        map[string]string{},
+	}
+}
+
+func (m *MockSnapshotManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockSnapshotManager) MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error) {
+	return m.MissingExtensions, nil
+}
+
+func (m *MockSnapshotManager) GetConfigValue(ctx context.Context, containerName, key string) (string, error) {
+	return m.Config[key], nil
+}
+
+func (m *MockSnapshotManager) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	m.Config[key] = value
+	return nil
+}
+
+func (m *MockSnapshotManager) UnsetConfigValue(ctx context.Context, containerName, key string) error {
+	if _, ok := m.Config[key]; !ok {
+		return fmt.Errorf("key '%s' is not set", key)
+	}
+	delete(m.Config, key)
+	return nil
+}
+
+func (m *MockSnapshotManager) ListSnapshots(ctx context.Context, containerName string) ([]helpers.Snapshot, error) {
+	return m.Snapshots, nil
+}
+
+func (m *MockSnapshotManager) DeleteSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	m.Deleted = append(m.Deleted, snapshotName)
+	return nil
+}
+
+func (m *MockSnapshotManager) WriteCronEntry(containerName, entry string) error {
+	m.CronEntries[containerName] = entry
+	return nil
+}
+
+func (m *MockSnapshotManager) RemoveCronEntry(containerName string) error {
+	delete(m.CronEntries, containerName)
+	return nil
+}
+
+func TestScheduleSnapshots(t *testing.T) {
+	manager := newMockSnapshotManager()
+
+	err := scheduleSnapshots(context.Background(), manager, "web", SnapshotScheduleOptions{Cron: "0 3 * * *", Keep: 7})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		helpers.SnapshotScheduleKey: "0 3 * * *",
+		helpers.SnapshotPatternKey:  helpers.ScheduledSnapshotPattern,
+		helpers.SnapshotExpiryKey:   "7d",
+		helpers.SnapshotKeepKey:     "7",
+	}
+	for key, value := range expected {
+		if manager.Config[key] != value {
+			t.Errorf("expected %s=%s, got '%s'", key, value, manager.Config[key])
+		}
+	}
+	if len(manager.CronEntries) != 0 {
+		t.Error("no cron entry expected when LXD supports scheduling")
+	}
+}
+
+func TestScheduleSnapshotsExplicitExpiry(t *testing.T) {
+	manager := newMockSnapshotManager()
+
+	err := scheduleSnapshots(context.Background(), manager, "web", SnapshotScheduleOptions{Cron: "0 */6 * * *", Keep: 4, Expiry: "1d"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Config[helpers.SnapshotExpiryKey] != "1d" {
+		t.Errorf("expected explicit expiry, got '%s'", manager.Config[helpers.SnapshotExpiryKey])
+	}
+}
+
+func TestScheduleSnapshotsCronFallback(t *testing.T) {
+	manager := newMockSnapshotManager()
+	manager.MissingExtensions = []string{"snapshot_expiry"}
+
+	err := scheduleSnapshots(context.Background(), manager, "web", SnapshotScheduleOptions{Cron: "@daily", Keep: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := manager.Config[helpers.SnapshotScheduleKey]; ok {
+		t.Error("LXD schedule should not be set when falling back to cron")
+	}
+	if entry := manager.CronEntries["web"]; !strings.Contains(entry, "snapshot prune web --keep 3") {
+		t.Errorf("expected cron entry with prune, got:\n%s", entry)
+	}
+}
+
+func TestScheduleSnapshotsErrors(t *testing.T) {
+	manager := newMockSnapshotManager()
+	tests := []struct {
+		name          string
+		container     string
+		opts          SnapshotScheduleOptions
+		expectedError string
+	}{
+		{"missing cron", "web", SnapshotScheduleOptions{}, "--cron is required"},
+		{"invalid cron", "web", SnapshotScheduleOptions{Cron: "daily"}, "invalid cron expression"},
+		{"negative keep", "web", SnapshotScheduleOptions{Cron: "@daily", Keep: -1}, "must not be negative"},
+		{"missing container", "missing", SnapshotScheduleOptions{Cron: "@daily"}, "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scheduleSnapshots(context.Background(), manager, tt.container, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDisableSnapshotSchedule(t *testing.T) {
+	manager := newMockSnapshotManager()
+	manager.Config[helpers.SnapshotScheduleKey] = "@daily"
+	manager.Config[helpers.SnapshotKeepKey] = "7"
+	manager.CronEntries["web"] = "entry"
+
+	if err := disableSnapshotSchedule(context.Background(), manager, "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Config) != 0 {
+		t.Errorf("expected schedule keys to be removed, got %v", manager.Config)
+	}
+	if len(manager.CronEntries) != 0 {
+		t.Error("expected cron entry to be removed")
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	base := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	manager := newMockSnapshotManager()
+	manager.Config[helpers.SnapshotKeepKey] = "2"
+	manager.Snapshots = []helpers.Snapshot{
+		{Name: "scheduled-20250101-030000", CreatedAt: base},
+		{Name: "pre-update-20250101-120000", CreatedAt: base.Add(9 * time.Hour)},
+		{Name: "scheduled-20250102-030000", CreatedAt: base.Add(24 * time.Hour)},
+		{Name: "scheduled-20250103-030000", CreatedAt: base.Add(48 * time.Hour)},
+	}
+
+	if err := pruneSnapshots(context.Background(), manager, "web", 0, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 0 {
+		t.Error("dry run should not delete snapshots")
+	}
+
+	if err := pruneSnapshots(context.Background(), manager, "web", 0, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 1 || manager.Deleted[0] != "scheduled-20250101-030000" {
+		t.Errorf("expected the oldest scheduled snapshot to be deleted, got %v", manager.Deleted)
+	}
+
+	manager.Deleted = nil
+	if err := pruneSnapshots(context.Background(), manager, "web", 1, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Deleted) != 2 {
+		t.Errorf("explicit --keep should override the recorded retention, got %v", manager.Deleted)
+	}
+}
+
+func TestPruneSnapshotsWithoutRetention(t *testing.T) {
+	manager := newMockSnapshotManager()
+
+	err := pruneSnapshots(context.Background(), manager, "web", 0, false)
+	if err == nil || !strings.Contains(err.Error(), "pass --keep") {
+		t.Errorf("expected missing retention error, got %v", err)
+	}
+}
+
+func TestPruneSnapshotsInvalidRetention(t *testing.T) {
+	manager := newMockSnapshotManager()
+	manager.Config[helpers.SnapshotKeepKey] = "many"
+
+	// Malformed metadata on the container is not a usage error
+	err := pruneSnapshots(context.Background(), manager, "web", 0, false)
+	if err == nil || !strings.Contains(err.Error(), "invalid retention") || exitCode(err) != ExitFailure {
+		t.Errorf("expected a plain invalid retention error, got %v (exit code %d)", err, exitCode(err))
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Step is one named unit of work with its own timeout
+type Step struct {
+	Name    string
+	Timeout time.Duration
+	// After names the steps that must finish before this one. Nil means the
+	// step listed before it; names not in the list count as finished, so
+	// steps completed by an earlier run can be dropped.
+	After []string
+	Run   func(ctx context.Context) error
+}
+
+// StepRunner runs steps, enforcing each step's timeout and an optional total
+// budget across all steps.
+//
+// With Parallel above one, steps whose dependencies have finished run together
+// in waves of at most Parallel steps. Each wave is announced and its failures
+// reported in list order, so the progress log does not depend on which step
+// happens to finish first.
+//
+// A step's context is cancelled when it times out, which kills the commands
+// the step runs under it, so none are left holding the dpkg lock or racing
+// the cleanup of a failed create. The runner returns at the timeout even if
+// a step ignores its context, so a hung step cannot block the CLI forever.
+type StepRunner struct {
+	MaxDuration time.Duration
+	// Parallel bounds how many steps run at once; below two steps run one
+	// at a time in list order
+	Parallel int
+	// Progress receives step events; nil discards them
+	Progress *ProgressReporter
+	// Timings records how long each step took; nil records nothing
+	Timings  *Timings
+	deadline time.Time
+
+	mu       sync.Mutex
+	total    int
+	finished int
+}
+
+// NewStepRunner creates a StepRunner reporting to the command's progress
+// events; a zero maxDuration means no total budget
+func NewStepRunner(maxDuration time.Duration) *StepRunner {
+	return &StepRunner{MaxDuration: maxDuration, Progress: progress}
+}
+
+// Run executes the steps and stops at the first failure
+func (r *StepRunner) Run(ctx context.Context, steps ...Step) error {
+	waves, err := stepWaves(steps)
+	if err != nil {
+		return err
+	}
+	if r.MaxDuration > 0 && r.deadline.IsZero() {
+		r.deadline = time.Now().Add(r.MaxDuration)
+	}
+	r.mu.Lock()
+	r.total, r.finished = len(steps), 0
+	r.mu.Unlock()
+
+	if r.Parallel < 2 {
+		for i, step := range steps {
+			logger.Debug("Step %d/%d: %s", i+1, len(steps), step.Name)
+			if err := r.runStep(ctx, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, wave := range waves {
+		if err := r.runWave(ctx, wave); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runWave runs independent steps concurrently, at most r.Parallel at a time.
+// Steps not yet started when one fails are skipped; the first failure in
+// list order is returned.
+func (r *StepRunner) runWave(ctx context.Context, wave []Step) error {
+	if len(wave) == 1 {
+		logger.Debug("Step: %s", wave[0].Name)
+		return r.runStep(ctx, wave[0])
+	}
+
+	names := make([]string, len(wave))
+	for i, step := range wave {
+		names[i] = step.Name
+	}
+	logger.Debug("Running steps in parallel: %s", strings.Join(names, ", "))
+
+	errs := make([]error, len(wave))
+	slots := make(chan struct{}, r.Parallel)
+	stop := make(chan struct{})
+	var failed sync.Once
+	var wg sync.WaitGroup
+launch:
+	for i, step := range wave {
+		select {
+		case slots <- struct{}{}:
+		case <-stop:
+			break launch
+		}
+		// A slot and a failure may be ready together; never start after a failure
+		select {
+		case <-stop:
+			break launch
+		default:
+		}
+
+		wg.Add(1)
+		go func(i int, step Step) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if errs[i] = r.runStep(ctx, step); errs[i] != nil {
+				failed.Do(func() { close(stop) })
+			}
+		}(i, step)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepWaves groups steps into waves whose dependencies all finish in earlier
+// waves, keeping list order within each wave. Dependencies must be listed
+// before the steps that need them.
+func stepWaves(steps []Step) ([][]Step, error) {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if _, dup := index[step.Name]; dup {
+			return nil, fmt.Errorf("duplicate step '%s'", step.Name)
+		}
+		index[step.Name] = i
+	}
+
+	levels := make([]int, len(steps))
+	var waves [][]Step
+	for i, step := range steps {
+		after := step.After
+		if after == nil && i > 0 {
+			after = []string{steps[i-1].Name}
+		}
+		for _, dep := range after {
+			j, listed := index[dep]
+			if !listed {
+				continue
+			}
+			if j >= i {
+				return nil, fmt.Errorf("step '%s' must be listed after '%s', which it depends on", step.Name, dep)
+			}
+			if levels[j]+1 > levels[i] {
+				levels[i] = levels[j] + 1
+			}
+		}
+		for len(waves) <= levels[i] {
+			waves = append(waves, nil)
+		}
+		waves[levels[i]] = append(waves[levels[i]], step)
+	}
+	return waves, nil
+}
+
+// runStep runs a single step, reporting its start and outcome
+func (r *StepRunner) runStep(ctx context.Context, step Step) error {
+	r.Progress.StepStarted(step.Name, r.percent(false))
+	start := time.Now()
+	err := r.runStepWithTimeout(ctx, step)
+	r.Progress.StepFinished(step.Name, r.percent(err == nil), time.Since(start), err)
+	r.Timings.Record("", step.Name, time.Since(start), err)
+	return err
+}
+
+// percent returns the share of steps finished, counting one more first if finished
+func (r *StepRunner) percent(finished bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if finished {
+		r.finished++
+	}
+	if r.total == 0 {
+		return 0
+	}
+	return r.finished * 100 / r.total
+}
+
+// runStepWithTimeout runs a single step under its timeout and the remaining budget
+func (r *StepRunner) runStepWithTimeout(ctx context.Context, step Step) error {
+	timeout := step.Timeout
+	budgetLimited := false
+	if !r.deadline.IsZero() {
+		remaining := time.Until(r.deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("step '%s' not started: total time budget of %s exhausted", step.Name, r.MaxDuration)
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+			budgetLimited = true
+		}
+	}
+
+	stepCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		stepCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		done <- step.Run(stepCtx)
+	}()
+
+	select {
+	case err := <-done:
+		logger.Debug("Step '%s' finished in %s", step.Name, time.Since(start).Round(time.Millisecond))
+		return err
+	case <-stepCtx.Done():
+		if !errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("step '%s' cancelled: %w", step.Name, stepCtx.Err())
+		}
+		if budgetLimited {
+			return helpers.TimeoutErrorf("step '%s' timed out: total time budget of %s exceeded", step.Name, r.MaxDuration)
+		}
+		return helpers.TimeoutErrorf("step '%s' timed out after %s", step.Name, timeout)
+	}
+}
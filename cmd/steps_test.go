@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStepRunnerRunsInOrder(t *testing.T) {
+	var order []string
+	step := func(name string, err error) Step {
+		return Step{Name: name, Timeout: time.Second, Run: func(ctx context.Context) error {
+			order = append(order, name)
+			return err
+		}}
+	}
+
+	err := NewStepRunner(0).Run(context.Background(), step("one", nil), step("two", fmt.Errorf("boom")), step("three", nil))
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("expected step error to be returned unchanged, got %v", err)
+	}
+	if strings.Join(order, ",") != "one,two" {
+		t.Errorf("expected to stop after the failing step, got %v", order)
+	}
+}
+
+func TestStepRunnerStepTimeout(t *testing.T) {
+	// A step that ignores its context must not block the runner
+	hung := make(chan struct{})
+	defer close(hung)
+
+	err := NewStepRunner(0).Run(context.Background(), Step{
+		Name:    "apt-update",
+		Timeout: 20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-hung
+			return nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "step 'apt-update' timed out after 20ms") {
+		t.Errorf("expected step timeout error, got %v", err)
+	}
+}
+
+func TestStepRunnerBudget(t *testing.T) {
+	runner := NewStepRunner(30 * time.Millisecond)
+	err := runner.Run(context.Background(), Step{
+		Name:    "docker-install",
+		Timeout: time.Minute,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "step 'docker-install' timed out: total time budget of 30ms exceeded") {
+		t.Errorf("expected budget error, got %v", err)
+	}
+
+	err = runner.Run(context.Background(), Step{Name: "restart", Run: func(ctx context.Context) error { return nil }})
+	if err == nil || !strings.Contains(err.Error(), "step 'restart' not started") {
+		t.Errorf("expected exhausted budget error, got %v", err)
+	}
+}
+
+func TestParseStepTimeouts(t *testing.T) {
+	timeouts, err := parseStepTimeouts(map[string]string{"docker-install": "30m", "apt-update": "90s"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if timeouts["docker-install"] != 30*time.Minute || timeouts["apt-update"] != 90*time.Second {
+		t.Errorf("unexpected timeouts: %v", timeouts)
+	}
+
+	for _, values := range []map[string]string{{"download": "5m"}, {"restart": "soon"}, {"restart": "-1m"}} {
+		if _, err := parseStepTimeouts(values); exitCode(err) != ExitUsage {
+			t.Errorf("expected a usage error for %v, got %v", values, err)
+		}
+	}
+}
+
+func TestStepRunnerParallel(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+
+	// b and c each wait for the other to start, so they must run together
+	started := map[string]chan struct{}{"b": make(chan struct{}), "c": make(chan struct{})}
+	together := func(name, other string) Step {
+		return Step{Name: name, After: []string{"a"}, Timeout: time.Second, Run: func(ctx context.Context) error {
+			close(started[name])
+			select {
+			case <-started[other]:
+			case <-ctx.Done():
+				return fmt.Errorf("%s ran alone", name)
+			}
+			record(name)
+			return nil
+		}}
+	}
+	step := func(name string, after ...string) Step {
+		return Step{Name: name, After: after, Run: func(ctx context.Context) error {
+			record(name)
+			return nil
+		}}
+	}
+
+	runner := NewStepRunner(0)
+	runner.Parallel = 2
+	if err := runner.Run(context.Background(), step("a"), together("b", "c"), together("c", "b"), step("d", "b", "c")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(order) != 4 || order[0] != "a" || order[3] != "d" {
+		t.Errorf("expected a first and d last, got %v", order)
+	}
+}
+
+func TestStepRunnerParallelFailure(t *testing.T) {
+	var ran []string
+	var mu sync.Mutex
+	step := func(name string, err error, after ...string) Step {
+		return Step{Name: name, After: after, Run: func(ctx context.Context) error {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			return err
+		}}
+	}
+
+	runner := NewStepRunner(0)
+	runner.Parallel = 4
+	err := runner.Run(context.Background(),
+		step("a", nil), step("b", fmt.Errorf("b failed"), "a"), step("c", fmt.Errorf("c failed"), "a"), step("d", nil, "b"))
+	if err == nil || err.Error() != "b failed" {
+		t.Errorf("expected the first failure in list order, got %v", err)
+	}
+	for _, name := range ran {
+		if name == "d" {
+			t.Error("expected steps after a failed wave not to run")
+		}
+	}
+}
+
+func TestStepWaves(t *testing.T) {
+	steps := provisionSteps(&MockContainerManager{}, "web", false, false, LocaleOptions{}, nil, DockerStorageOptions{}, "")
+	steps = append([]Step{{Name: stepLaunch}, securityStep(&MockContainerManager{}, "web")}, steps...)
+
+	waves, err := stepWaves(steps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var got []string
+	for _, wave := range waves {
+		var names []string
+		for _, step := range wave {
+			names = append(names, step.Name)
+		}
+		got = append(got, strings.Join(names, "+"))
+	}
+	want := "launch,security+apt-update,locale,docker-storage,docker-install,packages,app-user,restart"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected waves %s, got %s", want, strings.Join(got, ","))
+	}
+
+	// Steps without After follow the one before them; completed steps that
+	// were dropped from the list count as finished
+	waves, err = stepWaves([]Step{{Name: "b", After: []string{"a"}}, {Name: "c"}})
+	if err != nil || len(waves) != 2 {
+		t.Errorf("expected two sequential waves, got %d (%v)", len(waves), err)
+	}
+
+	if _, err := stepWaves([]Step{{Name: "a", After: []string{"b"}}, {Name: "b"}}); err == nil {
+		t.Error("expected an error for a dependency listed later")
+	}
+	if _, err := stepWaves([]Step{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected an error for duplicate steps")
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storageTimeout   time.Duration
+	storageDriver    string
+	storagePoolSize  string
+	storageSource    string
+	storageBalance   bool
+	storageScrub     bool
+	storageListTable tableFlags
+)
+
+// storageCmd represents the storage command
+var storageCmd = &cobra.Command{
+	Use:   "storage <create|list|maintain>",
+	Short: "Manage LXD storage pools",
+	Long: `Manage LXD storage pools used by containers.
+
+Available subcommands:
+  create   - Create a storage pool with an explicit driver, size and source
+  list     - List existing storage pools
+  maintain - Report Btrfs usage and run balance/scrub maintenance
+
+Examples:
+  lxc-go-cli storage create fast --size 50G
+  lxc-go-cli storage create disk2 --driver btrfs --source /dev/sdb
+  lxc-go-cli storage list
+  lxc-go-cli storage maintain fast --balance`,
+}
+
+// storageCreateCmd represents the storage create subcommand
+var storageCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a storage pool",
+	Long: `Create an LXD storage pool.
+
+By default a loop-backed Btrfs pool is created with LXD's default size. Use
+--size to set the size of the loop file, or --source to build the pool on a
+block device or existing directory instead.
+
+Examples:
+  lxc-go-cli storage create fast --size 50G
+  lxc-go-cli storage create disk2 --driver btrfs --source /dev/sdb`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		opts := helpers.StoragePoolOptions{
+			Name:   args[0],
+			Driver: strings.ToLower(storageDriver),
+			Size:   storagePoolSize,
+			Source: storageSource,
+		}
+
+		manager := &DefaultStorageManager{}
+		return createStoragePool(ctx, manager, opts)
+	},
+}
+
+// storageListCmd represents the storage list subcommand
+var storageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List storage pools",
+	Long: `List all LXD storage pools with their driver, size, source and number of users.
+
+Pick and order columns with --columns and leave out the header with --no-header.
+
+Examples:
+  lxc-go-cli storage list
+  lxc-go-cli storage list --columns name,used-by`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		manager := &DefaultStorageManager{}
+		out := cmd.OutOrStdout()
+		return listStoragePools(ctx, manager, out, storageListTable.options(out))
+	},
+}
+
+// storageMaintainCmd represents the storage maintain subcommand
+var storageMaintainCmd = &cobra.Command{
+	Use:   "maintain <pool>",
+	Short: "Run Btrfs maintenance on a storage pool",
+	Long: `Run Btrfs maintenance on a storage pool.
+
+Always prints a usage report (including metadata usage) and the scrub status.
+Btrfs can fail with "No space left on device" when metadata fills up even though
+data space is still free; --balance compacts partially used chunks so metadata
+can grow again.
+
+Options:
+  --balance  Run a filtered balance (chunks under 50% usage)
+  --scrub    Run a scrub and wait for it to complete
+
+This command usually needs to be run as root; with --sudo the btrfs commands
+are run through sudo instead.
+
+Examples:
+  lxc-go-cli storage maintain btrfs-pool
+  lxc-go-cli storage maintain btrfs-pool --sudo
+  lxc-go-cli storage maintain btrfs-pool --balance --scrub`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		manager := &DefaultStorageManager{}
+		return maintainStoragePool(ctx, manager, args[0], storageBalance, storageScrub, cmd.OutOrStdout())
+	},
+}
+
+// StorageManager interface for dependency injection
+type StorageManager interface {
+	ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error)
+	CreateStoragePool(ctx context.Context, opts helpers.StoragePoolOptions) error
+	GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error)
+	GetBtrfsScrubStatus(ctx context.Context, pool string) (string, error)
+	StartBtrfsScrub(ctx context.Context, pool string) (string, error)
+	BalanceBtrfsPool(ctx context.Context, pool string) (string, error)
+}
+
+// DefaultStorageManager implements StorageManager using helpers
+type DefaultStorageManager struct{}
+
+func (d *DefaultStorageManager) ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error) {
+	return helpers.ListStoragePools()
+}
+
+func (d *DefaultStorageManager) CreateStoragePool(ctx context.Context, opts helpers.StoragePoolOptions) error {
+	return helpers.CreateStoragePool(opts)
+}
+
+func (d *DefaultStorageManager) GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error) {
+	return helpers.GetBtrfsUsage(pool)
+}
+
+func (d *DefaultStorageManager) GetBtrfsScrubStatus(ctx context.Context, pool string) (string, error) {
+	return helpers.GetBtrfsScrubStatus(pool)
+}
+
+func (d *DefaultStorageManager) StartBtrfsScrub(ctx context.Context, pool string) (string, error) {
+	return helpers.StartBtrfsScrub(pool)
+}
+
+func (d *DefaultStorageManager) BalanceBtrfsPool(ctx context.Context, pool string) (string, error) {
+	return helpers.BalanceBtrfsPool(pool)
+}
+
+// createStoragePool validates options and creates a storage pool
+func createStoragePool(ctx context.Context, manager StorageManager, opts helpers.StoragePoolOptions) error {
+	if err := helpers.ValidateStoragePoolOptions(opts); err != nil {
+		return err
+	}
+
+	pools, err := manager.ListStoragePools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list storage pools: %w", err)
+	}
+	for _, pool := range pools {
+		if pool.Name == opts.Name {
+			return helpers.ConflictErrorf("storage pool '%s' already exists", opts.Name)
+		}
+	}
+
+	logger.Info("Creating %s storage pool '%s'...", opts.Driver, opts.Name)
+	if err := manager.CreateStoragePool(ctx, opts); err != nil {
+		return fmt.Errorf("failed to create storage pool: %w", err)
+	}
+
+	logger.Info("Storage pool '%s' created successfully", opts.Name)
+	return nil
+}
+
+// listStoragePools displays all storage pools
+func listStoragePools(ctx context.Context, manager StorageManager, out io.Writer, table render.Options) error {
+	pools, err := manager.ListStoragePools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list storage pools: %w", err)
+	}
+
+	if len(pools) == 0 {
+		fmt.Fprint(out, helpers.FormatStoragePools(pools))
+		return nil
+	}
+	return helpers.StoragePoolTable(pools).Render(out, table)
+}
+
+// maintainStoragePool reports usage and runs the requested Btrfs maintenance on a pool
+func maintainStoragePool(ctx context.Context, manager StorageManager, poolName string, balance, scrub bool, out io.Writer) error {
+	if poolName == "" {
+		return fmt.Errorf("storage pool name is required")
+	}
+
+	pools, err := manager.ListStoragePools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list storage pools: %w", err)
+	}
+
+	var pool *helpers.StoragePool
+	for i := range pools {
+		if pools[i].Name == poolName {
+			pool = &pools[i]
+			break
+		}
+	}
+	if pool == nil {
+		return helpers.NotFoundErrorf("storage pool '%s' does not exist", poolName)
+	}
+	if pool.Driver != "btrfs" {
+		return fmt.Errorf("storage pool '%s' uses the '%s' driver; maintenance is only supported for btrfs", poolName, pool.Driver)
+	}
+
+	if balance {
+		logger.Info("Balancing storage pool '%s' (this may take a while)...", poolName)
+		output, err := manager.BalanceBtrfsPool(ctx, poolName)
+		if err != nil {
+			return fmt.Errorf("failed to balance storage pool: %w", err)
+		}
+		logger.Debug("Balance output: %s", output)
+		logger.Info("Balance of storage pool '%s' completed", poolName)
+	}
+
+	if scrub {
+		logger.Info("Scrubbing storage pool '%s' (this may take a while)...", poolName)
+		output, err := manager.StartBtrfsScrub(ctx, poolName)
+		if err != nil {
+			return fmt.Errorf("failed to scrub storage pool: %w", err)
+		}
+		logger.Debug("Scrub output: %s", output)
+		logger.Info("Scrub of storage pool '%s' completed", poolName)
+	}
+
+	usage, err := manager.GetBtrfsUsage(ctx, poolName)
+	if err != nil {
+		return fmt.Errorf("failed to get usage for storage pool: %w", err)
+	}
+	fmt.Fprint(out, helpers.FormatBtrfsUsage(poolName, usage))
+
+	status, err := manager.GetBtrfsScrubStatus(ctx, poolName)
+	if err != nil {
+		logger.Warn("Failed to get scrub status for storage pool '%s': %v", poolName, err)
+		return nil
+	}
+	fmt.Fprintf(out, "\nScrub status:\n%s", status)
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+
+	// Add subcommands
+	storageCmd.AddCommand(storageCreateCmd)
+	storageCmd.AddCommand(storageListCmd)
+	storageCmd.AddCommand(storageMaintainCmd)
+
+	// Add timeout flag to both subcommands
+	storageCreateCmd.Flags().DurationVarP(&storageTimeout, "timeout", "t", 2*time.Minute, "Timeout for the storage operation")
+	storageListCmd.Flags().DurationVarP(&storageTimeout, "timeout", "t", 30*time.Second, "Timeout for the storage operation")
+	addTableFlags(storageListCmd, &storageListTable)
+	storageMaintainCmd.Flags().DurationVarP(&storageTimeout, "timeout", "t", 2*time.Hour, "Timeout for the storage operation")
+
+	storageCreateCmd.Flags().StringVar(&storageDriver, "driver", "btrfs", "Storage driver (btrfs, zfs, lvm, dir)")
+	storageCreateCmd.Flags().StringVar(&storagePoolSize, "size", "", "Size of the loop-backed pool (e.g. 50G)")
+	storageCreateCmd.Flags().StringVar(&storageSource, "source", "", "Block device or directory to create the pool on")
+
+	storageMaintainCmd.Flags().BoolVar(&storageBalance, "balance", false, "Run a filtered balance to reclaim partially used chunks")
+	storageMaintainCmd.Flags().BoolVar(&storageScrub, "scrub", false, "Run a scrub and wait for it to complete")
+}
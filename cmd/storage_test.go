@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// MockStorageManager for testing storage command
+type MockStorageManager struct {
+	Pools       []helpers.StoragePool
+	ListError   error
+	CreateError error
+	Created     []helpers.StoragePoolOptions
+	Usage       *helpers.BtrfsUsage
+	UsageError  error
+	ScrubError  error
+	Calls       map[string]int
+}
+
+func (m *MockStorageManager) ListStoragePools(ctx context.Context) ([]helpers.StoragePool, error) {
+	m.trackCall("ListStoragePools")
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.Pools, nil
+}
+
+func (m *MockStorageManager) CreateStoragePool(ctx context.Context, opts helpers.StoragePoolOptions) error {
+	m.trackCall("CreateStoragePool")
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	m.Created = append(m.Created, opts)
+	return nil
+}
+
+func (m *MockStorageManager) GetBtrfsUsage(ctx context.Context, pool string) (*helpers.BtrfsUsage, error) {
+	m.trackCall("GetBtrfsUsage")
+	if m.UsageError != nil {
+		return nil, m.UsageError
+	}
+	if m.Usage != nil {
+		return m.Usage, nil
+	}
+	return &helpers.BtrfsUsage{}, nil
+}
+
+func (m *MockStorageManager) GetBtrfsScrubStatus(ctx context.Context, pool string) (string, error) {
+	m.trackCall("GetBtrfsScrubStatus")
+	return "no stats available\n", nil
+}
+
+func (m *MockStorageManager) StartBtrfsScrub(ctx context.Context, pool string) (string, error) {
+	m.trackCall("StartBtrfsScrub")
+	if m.ScrubError != nil {
+		return "", m.ScrubError
+	}
+	return "scrub done", nil
+}
+
+func (m *MockStorageManager) BalanceBtrfsPool(ctx context.Context, pool string) (string, error) {
+	m.trackCall("BalanceBtrfsPool")
+	return "Done, had to relocate 1 out of 5 chunks", nil
+}
+
+func (m *MockStorageManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func (m *MockStorageManager) GetCallCount(method string) int {
+	if m.Calls == nil {
+		return 0
+	}
+	return m.Calls[method]
+}
+
+func TestStorageCommand(t *testing.T) {
+	if storageCmd == nil {
+		t.Fatal("storageCmd should not be nil")
+	}
+	if storageCreateCmd.Use != "create <name>" {
+		t.Errorf("unexpected Use for storage create: '%s'", storageCreateCmd.Use)
+	}
+	if storageListCmd.Use != "list" {
+		t.Errorf("unexpected Use for storage list: '%s'", storageListCmd.Use)
+	}
+
+	driverFlag := storageCreateCmd.Flags().Lookup("driver")
+	if driverFlag == nil || driverFlag.DefValue != "btrfs" {
+		t.Error("driver flag should exist and default to btrfs")
+	}
+	for _, name := range []string{"size", "source", "timeout"} {
+		if storageCreateCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+}
+
+func TestCreateStoragePool(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          helpers.StoragePoolOptions
+		listError     error
+		createError   error
+		expectedError string
+	}{
+		{
+			name:          "missing name",
+			opts:          helpers.StoragePoolOptions{Driver: "btrfs"},
+			expectedError: "storage pool name is required",
+		},
+		{
+			name:          "invalid driver",
+			opts:          helpers.StoragePoolOptions{Name: "p", Driver: "ext4"},
+			expectedError: "invalid driver 'ext4'",
+		},
+		{
+			name:          "invalid size",
+			opts:          helpers.StoragePoolOptions{Name: "p", Driver: "btrfs", Size: "lots"},
+			expectedError: "invalid size 'lots'",
+		},
+		{
+			name:          "pool already exists",
+			opts:          helpers.StoragePoolOptions{Name: "existing", Driver: "btrfs"},
+			expectedError: "storage pool 'existing' already exists",
+		},
+		{
+			name:          "list fails",
+			opts:          helpers.StoragePoolOptions{Name: "p", Driver: "btrfs"},
+			listError:     fmt.Errorf("lxc failed"),
+			expectedError: "failed to list storage pools",
+		},
+		{
+			name:          "create fails",
+			opts:          helpers.StoragePoolOptions{Name: "p", Driver: "btrfs"},
+			createError:   fmt.Errorf("lxc failed"),
+			expectedError: "failed to create storage pool",
+		},
+		{
+			name: "successful create",
+			opts: helpers.StoragePoolOptions{Name: "fast", Driver: "btrfs", Size: "50G"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockStorageManager{
+				Pools:       []helpers.StoragePool{{Name: "existing", Driver: "btrfs"}},
+				ListError:   tt.listError,
+				CreateError: tt.createError,
+			}
+
+			err := createStoragePool(context.Background(), manager, tt.opts)
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("expected error containing '%s', got nil", tt.expectedError)
+				} else if !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(manager.Created) != 1 || manager.Created[0].Size != "50G" {
+				t.Errorf("expected pool to be created with size 50G, got %+v", manager.Created)
+			}
+		})
+	}
+}
+
+func TestListStoragePools(t *testing.T) {
+	manager := &MockStorageManager{
+		Pools: []helpers.StoragePool{
+			{Name: "fast", Driver: "btrfs", Config: map[string]string{"size": "50GiB"}, UsedBy: []string{"/1.0/instances/web"}},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := listStoragePools(context.Background(), manager, &out, render.Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "fast") || !strings.Contains(out.String(), "50GiB") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	manager.ListError = fmt.Errorf("lxc failed")
+	if err := listStoragePools(context.Background(), manager, &out, render.Options{}); err == nil {
+		t.Error("expected error when listing fails")
+	}
+}
+
+func TestMaintainStoragePool(t *testing.T) {
+	tests := []struct {
+		name          string
+		pool          string
+		balance       bool
+		scrub         bool
+		usageError    error
+		scrubError    error
+		expectedError string
+		expectedCalls map[string]int
+	}{
+		{name: "empty pool name", pool: "", expectedError: "storage pool name is required"},
+		{name: "missing pool", pool: "missing", expectedError: "storage pool 'missing' does not exist"},
+		{name: "non-btrfs pool", pool: "default", expectedError: "only supported for btrfs"},
+		{
+			name:          "report only",
+			pool:          "fast",
+			expectedCalls: map[string]int{"GetBtrfsUsage": 1, "GetBtrfsScrubStatus": 1, "BalanceBtrfsPool": 0, "StartBtrfsScrub": 0},
+		},
+		{
+			name:          "balance and scrub",
+			pool:          "fast",
+			balance:       true,
+			scrub:         true,
+			expectedCalls: map[string]int{"BalanceBtrfsPool": 1, "StartBtrfsScrub": 1},
+		},
+		{name: "usage fails", pool: "fast", usageError: fmt.Errorf("permission denied"), expectedError: "failed to get usage"},
+		{name: "scrub fails", pool: "fast", scrub: true, scrubError: fmt.Errorf("busy"), expectedError: "failed to scrub"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockStorageManager{
+				Pools: []helpers.StoragePool{
+					{Name: "fast", Driver: "btrfs"},
+					{Name: "default", Driver: "dir"},
+				},
+				UsageError: tt.usageError,
+				ScrubError: tt.scrubError,
+			}
+
+			var out bytes.Buffer
+			err := maintainStoragePool(context.Background(), manager, tt.pool, tt.balance, tt.scrub, &out)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !strings.Contains(out.String(), "Scrub status:") {
+				t.Errorf("expected scrub status in output, got %s", out.String())
+			}
+			for method, count := range tt.expectedCalls {
+				if manager.GetCallCount(method) != count {
+					t.Errorf("expected %d calls to %s, got %d", count, method, manager.GetCallCount(method))
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultStorageManager(t *testing.T) {
+	// Test that DefaultStorageManager implements StorageManager interface
+	var manager StorageManager = &DefaultStorageManager{}
+	_ = manager
+}
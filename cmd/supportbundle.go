@@ -0,0 +1,185 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	supportBundleTimeout time.Duration
+	supportBundleOutput  string
+)
+
+// supportBundleCmd represents the support-bundle command
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle [container-name]",
+	Short: "Gather diagnostics into a tarball to attach to an issue",
+	Long: `Gather what is needed to look into a problem into one .tar.gz file to
+attach to an issue:
+
+  version.txt       lxc-go-cli, LXD client and server, and storage driver versions
+  host.txt          kernel, distribution, memory and disks of the host
+  lxc-info.txt      'lxc version' and 'lxc info' output
+  doctor.txt        the results of 'lxc-go-cli doctor'
+  audit.log         the last ` + strconv.Itoa(helpers.SupportLogLines) + ` actions lxc-go-cli took on its own
+
+Given a container, it also holds its expanded 'lxc config show', 'lxc info
+--show-log' and the last ` + strconv.Itoa(helpers.SupportLogLines) + ` lines of its journal. Anything that could not be
+gathered is listed in errors.txt rather than failing the command.
+
+Passwords, tokens, keys, cloud-init data, environment.* values, credentials
+in URLs and other secrets are replaced with ` + helpers.RedactedValue + `, but review the bundle
+before sharing it.
+
+Examples:
+  lxc-go-cli support-bundle
+  lxc-go-cli support-bundle mycontainer
+  lxc-go-cli support-bundle mycontainer --output /tmp/bundle.tar.gz`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), supportBundleTimeout)
+		defer cancel()
+
+		containerName := ""
+		if len(args) > 0 {
+			containerName = resolveContainerName(args[0])
+		}
+		return createSupportBundle(ctx, &DefaultSupportBundleManager{}, containerName, supportBundleOutput, time.Now(), cmd.OutOrStdout())
+	},
+}
+
+// SupportBundleManager interface for dependency injection
+type SupportBundleManager interface {
+	VersionManager
+	DoctorManager
+	CollectHostInfo(ctx context.Context) []byte
+	CollectCommandOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+	GetExpandedContainerConfig(ctx context.Context, name string) ([]byte, error)
+	ReadAuditLogTail(lines int) ([]byte, error)
+	WriteSupportBundle(path string, files []helpers.SupportFile, now time.Time) error
+}
+
+// DefaultSupportBundleManager implements SupportBundleManager using helpers
+type DefaultSupportBundleManager struct {
+	DefaultVersionManager
+	DefaultDoctorManager
+}
+
+func (d *DefaultSupportBundleManager) CollectHostInfo(ctx context.Context) []byte {
+	return helpers.CollectHostInfo(ctx)
+}
+
+func (d *DefaultSupportBundleManager) CollectCommandOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return helpers.CollectCommandOutput(ctx, name, args...)
+}
+
+func (d *DefaultSupportBundleManager) GetExpandedContainerConfig(ctx context.Context, name string) ([]byte, error) {
+	return helpers.GetExpandedContainerConfig(ctx, name)
+}
+
+func (d *DefaultSupportBundleManager) ReadAuditLogTail(lines int) ([]byte, error) {
+	return helpers.ReadAuditLogTail(lines)
+}
+
+func (d *DefaultSupportBundleManager) WriteSupportBundle(path string, files []helpers.SupportFile, now time.Time) error {
+	return helpers.WriteSupportBundle(path, files, now)
+}
+
+// createSupportBundle gathers diagnostics of the host, and of a container if
+// one is given, and writes them redacted into a tarball at path, or at a
+// timestamped name in the current directory when path is empty
+func createSupportBundle(ctx context.Context, manager SupportBundleManager, containerName, path string, now time.Time, out io.Writer) error {
+	if path == "" {
+		path = helpers.SupportBundleName(now)
+	}
+
+	var files []helpers.SupportFile
+	var failures []string
+	add := func(name string, data []byte) {
+		files = append(files, helpers.SupportFile{Name: name, Data: []byte(helpers.RedactSecrets(string(data)))})
+	}
+	// collect keeps what a command printed even when it failed, since that
+	// output is often the most useful part
+	collect := func(name string, data []byte, err error) {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+		if len(data) > 0 {
+			add(name, data)
+		}
+	}
+
+	logger.Info("Gathering diagnostics...")
+	var version bytes.Buffer
+	if err := writeVersion(ctx, manager, versionOptions{Verbose: true}, &version); err != nil {
+		failures = append(failures, fmt.Sprintf("version.txt: %v", err))
+	}
+	add("version.txt", version.Bytes())
+	add("host.txt", manager.CollectHostInfo(ctx))
+
+	var lxcInfo bytes.Buffer
+	for _, args := range [][]string{{"version"}, {"info"}} {
+		fmt.Fprintf(&lxcInfo, "$ lxc %s\n", strings.Join(args, " "))
+		output, err := manager.CollectCommandOutput(ctx, "lxc", args...)
+		lxcInfo.Write(output)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("lxc-info.txt: %v", err))
+		}
+		lxcInfo.WriteString("\n")
+	}
+	add("lxc-info.txt", lxcInfo.Bytes())
+
+	// Failed checks are what the bundle is for, not a reason to stop
+	var doctor bytes.Buffer
+	if err := runDoctor(ctx, manager, containerName, false, &doctor); err != nil {
+		fmt.Fprintf(&doctor, "\n%v\n", err)
+	}
+	add("doctor.txt", doctor.Bytes())
+
+	audit, err := manager.ReadAuditLogTail(helpers.SupportLogLines)
+	collect("audit.log", audit, err)
+
+	if containerName != "" {
+		config, err := manager.GetExpandedContainerConfig(ctx, containerName)
+		collect("container/config.yaml", config, err)
+		info, err := manager.CollectCommandOutput(ctx, "lxc", "info", containerName, "--show-log")
+		collect("container/info.txt", info, err)
+		journal := append(helpers.BuildJournalCommand(helpers.LogOptions{}), "--lines", strconv.Itoa(helpers.SupportLogLines))
+		output, err := manager.CollectCommandOutput(ctx, "lxc", append([]string{"exec", containerName, "--"}, journal...)...)
+		collect("container/journal.txt", output, err)
+	}
+
+	if len(failures) > 0 {
+		add("errors.txt", []byte(strings.Join(failures, "\n")+"\n"))
+		logger.Warn("Could not gather %d item(s); see errors.txt in the bundle", len(failures))
+	}
+
+	if err := manager.WriteSupportBundle(path, files, now); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, i18n.T("support_bundle.written", path))
+	fmt.Fprintln(out, i18n.T("support_bundle.review"))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(supportBundleCmd)
+	// A bundle is most needed when lxc is missing or broken
+	skipLXCCheck(supportBundleCmd)
+
+	supportBundleCmd.Flags().DurationVarP(&supportBundleTimeout, "timeout", "t", 2*time.Minute, "Timeout for gathering diagnostics")
+	supportBundleCmd.Flags().StringVarP(&supportBundleOutput, "output", "o", "", "Path of the tarball (default lxc-go-cli-support-<timestamp>.tar.gz)")
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockSupportBundleManager for testing support-bundle
+type MockSupportBundleManager struct {
+	MockVersionManager
+	MockDoctorManager
+	Outputs     map[string]string
+	Errors      map[string]error
+	Config      string
+	ConfigError error
+	Audit       string
+	Path        string
+	Files       map[string]string
+}
+
+func newMockSupportBundleManager() *MockSupportBundleManager {
+	return &MockSupportBundleManager{
+		MockVersionManager: MockVersionManager{Components: &helpers.ComponentVersions{Server: "lxd", ServerVersion: "5.21.1"}},
+		Outputs:            map[string]string{},
+		Errors:             map[string]error{},
+	}
+}
+
+func (m *MockSupportBundleManager) CollectHostInfo(ctx context.Context) []byte {
+	return []byte("$ uname -a\nLinux host\n")
+}
+
+func (m *MockSupportBundleManager) CollectCommandOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	return []byte(m.Outputs[key]), m.Errors[key]
+}
+
+func (m *MockSupportBundleManager) GetExpandedContainerConfig(ctx context.Context, name string) ([]byte, error) {
+	return []byte(m.Config), m.ConfigError
+}
+
+func (m *MockSupportBundleManager) ReadAuditLogTail(lines int) ([]byte, error) {
+	return []byte(m.Audit), nil
+}
+
+func (m *MockSupportBundleManager) WriteSupportBundle(path string, files []helpers.SupportFile, now time.Time) error {
+	m.Path = path
+	m.Files = map[string]string{}
+	for _, f := range files {
+		m.Files[f.Name] = string(f.Data)
+	}
+	return nil
+}
+
+func TestCreateSupportBundle(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	t.Run("host only", func(t *testing.T) {
+		manager := newMockSupportBundleManager()
+		manager.Outputs["lxc version"] = "Client version: 5.21.1\n"
+		manager.Audit = `{"action":"restart","container":"web"}` + "\n"
+
+		var buf bytes.Buffer
+		if err := createSupportBundle(context.Background(), manager, "", "", now, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if manager.Path != "lxc-go-cli-support-20250304-050607.tar.gz" {
+			t.Errorf("unexpected default path %s", manager.Path)
+		}
+		for _, name := range []string{"version.txt", "host.txt", "lxc-info.txt", "doctor.txt", "audit.log"} {
+			if _, ok := manager.Files[name]; !ok {
+				t.Errorf("expected %s in the bundle, got %v", name, manager.Files)
+			}
+		}
+		if _, ok := manager.Files["container/config.yaml"]; ok {
+			t.Error("expected no container files without a container")
+		}
+		if _, ok := manager.Files["errors.txt"]; ok {
+			t.Errorf("expected no errors.txt, got %s", manager.Files["errors.txt"])
+		}
+		if !strings.Contains(manager.Files["version.txt"], "Server:         lxd 5.21.1") {
+			t.Errorf("expected component versions, got %s", manager.Files["version.txt"])
+		}
+		if !strings.Contains(manager.Files["lxc-info.txt"], "$ lxc version\nClient version: 5.21.1") {
+			t.Errorf("unexpected lxc-info.txt: %s", manager.Files["lxc-info.txt"])
+		}
+		if !strings.Contains(buf.String(), "Support bundle written to "+manager.Path) {
+			t.Errorf("expected the path to be printed, got %s", buf.String())
+		}
+	})
+
+	t.Run("container with failures", func(t *testing.T) {
+		manager := newMockSupportBundleManager()
+		manager.LXCError = errors.New("lxc not found")
+		manager.Config = "config:\n  user.app-password: hunter2\n  image.os: Ubuntu\n"
+		manager.Outputs["lxc info web --show-log"] = "Name: web\n"
+		manager.Errors["lxc info"] = errors.New("lxc info failed")
+		journal := "lxc exec web -- journalctl --no-pager --output short-iso --lines 300"
+		manager.Outputs[journal] = "Error: Instance is not running\n"
+		manager.Errors[journal] = errors.New("exit status 1")
+
+		if err := createSupportBundle(context.Background(), manager, "web", "/tmp/out.tar.gz", now, &bytes.Buffer{}); err != nil {
+			t.Fatalf("failures should be recorded, not returned: %v", err)
+		}
+		if manager.Path != "/tmp/out.tar.gz" {
+			t.Errorf("expected the given path, got %s", manager.Path)
+		}
+		config := manager.Files["container/config.yaml"]
+		if strings.Contains(config, "hunter2") || !strings.Contains(config, "image.os: Ubuntu") {
+			t.Errorf("expected the password to be redacted, got %s", config)
+		}
+		if manager.Files["container/info.txt"] != "Name: web\n" {
+			t.Errorf("unexpected info.txt: %q", manager.Files["container/info.txt"])
+		}
+		if !strings.Contains(manager.Files["container/journal.txt"], "not running") {
+			t.Errorf("expected the failed command's output to be kept, got %q", manager.Files["container/journal.txt"])
+		}
+		if !strings.Contains(manager.Files["doctor.txt"], "check(s) failed") {
+			t.Errorf("expected failed doctor checks to be recorded, got %s", manager.Files["doctor.txt"])
+		}
+		errs := manager.Files["errors.txt"]
+		for _, expected := range []string{"lxc-info.txt: lxc info failed", "container/journal.txt: exit status 1"} {
+			if !strings.Contains(errs, expected) {
+				t.Errorf("expected errors.txt to contain %q, got %s", expected, errs)
+			}
+		}
+	})
+}
+
+func TestSupportBundleCommand(t *testing.T) {
+	if supportBundleCmd.Use != "support-bundle [container-name]" {
+		t.Errorf("unexpected Use %s", supportBundleCmd.Use)
+	}
+	if supportBundleCmd.Annotations[skipLXCCheckAnnotation] != "true" {
+		t.Error("support-bundle should run without a working lxc")
+	}
+	for _, flag := range []string{"output", "timeout"} {
+		if supportBundleCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("expected --%s flag", flag)
+		}
+	}
+}
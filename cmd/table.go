@@ -0,0 +1,33 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"io"
+
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// tableFlags holds the --columns and --no-header flags of a command that
+// prints a table
+type tableFlags struct {
+	columns  string
+	noHeader bool
+}
+
+// addTableFlags registers the table flags on cmd
+func addTableFlags(cmd *cobra.Command, flags *tableFlags) {
+	cmd.Flags().StringVar(&flags.columns, "columns", "", "Comma separated columns to show, in order, e.g. name,status")
+	cmd.Flags().BoolVar(&flags.noHeader, "no-header", false, "Leave out the header line")
+}
+
+// options returns how to render tables written to out
+func (f *tableFlags) options(out io.Writer) render.Options {
+	return render.Options{
+		Columns:  render.ParseColumns(f.columns),
+		NoHeader: f.noHeader,
+		Color:    render.ColorEnabled(out),
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// Timing formats accepted by --timings
+const (
+	TimingsText = "text"
+	TimingsJSON = "json"
+	TimingsNone = "none"
+)
+
+// StepTiming is how long one step took
+type StepTiming struct {
+	Step       string `json:"step"`
+	Container  string `json:"container,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Failed     bool   `json:"failed,omitempty"`
+
+	duration time.Duration
+}
+
+// TimingReport is the breakdown printed by --timings, stable for tracking
+// provisioning performance across versions
+type TimingReport struct {
+	Command string       `json:"command"`
+	Version string       `json:"version"`
+	TotalMS int64        `json:"total_ms"`
+	Steps   []StepTiming `json:"steps"`
+}
+
+// Timings records how long the steps of a command take. A nil recorder
+// discards them, so callers need not check whether timings were asked for.
+type Timings struct {
+	mu      sync.Mutex
+	command string
+	start   time.Time
+	steps   []StepTiming
+}
+
+// NewTimings starts timing a command
+func NewTimings(command string) *Timings {
+	return &Timings{command: command, start: time.Now()}
+}
+
+// Record adds a finished step, failed if err is not nil
+func (t *Timings) Record(container, step string, duration time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps = append(t.steps, StepTiming{
+		Step:       step,
+		Container:  container,
+		DurationMS: duration.Milliseconds(),
+		Failed:     err != nil,
+		duration:   duration,
+	})
+}
+
+// Time runs fn as a step and records how long it took
+func (t *Timings) Time(container, step string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	t.Record(container, step, time.Since(start), err)
+	return err
+}
+
+// Report returns the steps recorded so far and the time since the start
+func (t *Timings) Report() TimingReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return TimingReport{
+		Command: t.command,
+		Version: version,
+		TotalMS: time.Since(t.start).Milliseconds(),
+		Steps:   append([]StepTiming(nil), t.steps...),
+	}
+}
+
+// validateTimingsFormat checks a --timings value
+func validateTimingsFormat(format string) error {
+	switch format {
+	case TimingsText, TimingsJSON, TimingsNone:
+		return nil
+	}
+	return helpers.UsageErrorf("invalid timings format '%s': must be %s, %s or %s", format, TimingsText, TimingsJSON, TimingsNone)
+}
+
+// newTimings returns a recorder for --timings, or nil when none are wanted
+func newTimings(command, format string) *Timings {
+	if format == TimingsNone {
+		return nil
+	}
+	return NewTimings(command)
+}
+
+// writeTimings prints the breakdown of a command's steps, as a table or a
+// single JSON line
+func writeTimings(out io.Writer, t *Timings, format string) error {
+	if t == nil {
+		return nil
+	}
+	report := t.Report()
+	if len(report.Steps) == 0 {
+		return nil
+	}
+	if format == TimingsJSON {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode timings: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+	_, err := io.WriteString(out, formatTimings(report))
+	return err
+}
+
+// printTimings writes the breakdown to stderr. With --progress json it is
+// written as JSON so every line on stderr stays JSON.
+func printTimings(out io.Writer, t *Timings, format string) {
+	if progress != nil && format == TimingsText {
+		format = TimingsJSON
+	}
+	if err := writeTimings(out, t, format); err != nil {
+		logger.Warn("Could not print timings: %v", err)
+	}
+}
+
+// formatTimings renders a report as a table with each step's share of the total
+func formatTimings(report TimingReport) string {
+	containers := make(map[string]bool)
+	for _, step := range report.Steps {
+		containers[step.Container] = true
+	}
+	headers := []string{"STEP", "DURATION", "SHARE"}
+	if len(containers) > 1 {
+		headers = append([]string{"CONTAINER"}, headers...)
+	}
+
+	total := time.Duration(report.TotalMS) * time.Millisecond
+	table := render.NewTable(headers...)
+	for _, step := range report.Steps {
+		share := "-"
+		if total > 0 {
+			share = fmt.Sprintf("%d%%", step.duration*100/total)
+		}
+		name := step.Step
+		if step.Failed {
+			name += " (failed)"
+		}
+		row := []string{name, roundDuration(step.duration).String(), share}
+		if len(containers) > 1 {
+			row = append([]string{step.Container}, row...)
+		}
+		table.AddRow(row...)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Timings for %s (total %s):\n", report.Command, roundDuration(total))
+	table.Render(&sb, render.Options{})
+	return sb.String()
+}
+
+// roundDuration keeps a duration readable: milliseconds below a second,
+// tenths of a second above
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
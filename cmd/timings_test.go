@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingsReport(t *testing.T) {
+	timings := NewTimings("create")
+	timings.Record("", "image", 1500*time.Millisecond, nil)
+	if err := timings.Time("", "apt-update", func() error { return fmt.Errorf("boom") }); err == nil {
+		t.Error("expected the step error to be returned")
+	}
+
+	report := timings.Report()
+	if report.Command != "create" || report.Version != version || len(report.Steps) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Steps[0].DurationMS != 1500 || report.Steps[0].Failed || !report.Steps[1].Failed {
+		t.Errorf("unexpected steps: %+v", report.Steps)
+	}
+
+	// A nil recorder discards steps
+	var none *Timings
+	none.Record("", "image", time.Second, nil)
+	if err := none.Time("", "image", func() error { return nil }); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := writeTimings(&bytes.Buffer{}, none, TimingsText); err != nil {
+		t.Errorf("expected nothing to print, got %v", err)
+	}
+}
+
+func TestFormatTimings(t *testing.T) {
+	report := TimingReport{Command: "create", TotalMS: 4000, Steps: []StepTiming{
+		{Step: "image", duration: time.Second},
+		{Step: "docker-install", duration: 2345 * time.Millisecond, Failed: true},
+	}}
+	out := formatTimings(report)
+	for _, want := range []string{"Timings for create (total 4s):", "image", "1s", "25%", "docker-install (failed)", "2.3s", "58%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "CONTAINER") {
+		t.Errorf("expected no container column for one container:\n%s", out)
+	}
+
+	report.Steps[0].Container, report.Steps[1].Container = "web1", "web2"
+	if out := formatTimings(report); !strings.Contains(out, "CONTAINER") || !strings.Contains(out, "web2") {
+		t.Errorf("expected a container column for several containers:\n%s", out)
+	}
+}
+
+func TestWriteTimingsJSON(t *testing.T) {
+	timings := NewTimings("update")
+	timings.Record("web", "upgrade", 90*time.Second, nil)
+
+	var out bytes.Buffer
+	if err := writeTimings(&out, timings, TimingsJSON); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("expected a single JSON line, got %q", out.String())
+	}
+	var report TimingReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out.String(), err)
+	}
+	if report.Command != "update" || len(report.Steps) != 1 || report.Steps[0].DurationMS != 90000 || report.Steps[0].Container != "web" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestValidateTimingsFormat(t *testing.T) {
+	for _, format := range []string{TimingsText, TimingsJSON, TimingsNone} {
+		if err := validateTimingsFormat(format); err != nil {
+			t.Errorf("expected %s to be valid, got %v", format, err)
+		}
+	}
+	if err := validateTimingsFormat("yaml"); err == nil {
+		t.Error("expected yaml to be rejected")
+	}
+	if newTimings("create", TimingsNone) != nil {
+		t.Error("expected no recorder with --timings none")
+	}
+}
+
+func TestStepRunnerRecordsTimings(t *testing.T) {
+	runner := NewStepRunner(0)
+	runner.Timings = NewTimings("create")
+	step := func(name string, err error) Step {
+		return Step{Name: name, Timeout: time.Second, Run: func(ctx context.Context) error { return err }}
+	}
+	runner.Run(context.Background(), step("image", nil), step("launch", fmt.Errorf("boom")), step("restart", nil))
+
+	steps := runner.Timings.Report().Steps
+	if len(steps) != 2 || steps[0].Step != "image" || steps[1].Step != "launch" || !steps[1].Failed {
+		t.Errorf("expected the run and failed steps to be recorded, got %+v", steps)
+	}
+}
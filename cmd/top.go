@@ -0,0 +1,233 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval time.Duration
+	topSortBy   string
+	topPrefix   string
+	topOnce     bool
+)
+
+// topCmd represents the top command
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show live resource usage of managed containers",
+	Long: `Show a refreshing table of resource usage for all containers managed by this tool.
+
+Columns show CPU usage (percentage of one core since the previous refresh),
+memory usage, cumulative disk IO and cumulative network traffic.
+
+Sort columns: name, cpu, memory, disk, net
+
+Examples:
+  lxc-go-cli top                       # Refresh every 2 seconds
+  lxc-go-cli top --sort memory         # Largest memory users first
+  lxc-go-cli top --prefix web-         # Only containers starting with 'web-'
+  lxc-go-cli top --once                # Print a single snapshot and exit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		opts := topOptions{
+			Interval: topInterval,
+			SortBy:   strings.ToLower(topSortBy),
+			Prefix:   topPrefix,
+			Once:     topOnce,
+		}
+
+		manager := &DefaultTopManager{}
+		return runTop(ctx, manager, opts, cmd.OutOrStdout())
+	},
+}
+
+// TopManager interface for dependency injection
+type TopManager interface {
+	ListContainerStates(ctx context.Context) ([]helpers.ContainerState, error)
+}
+
+// DefaultTopManager implements TopManager using helpers
+type DefaultTopManager struct{}
+
+func (d *DefaultTopManager) ListContainerStates(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainerStates()
+}
+
+// topOptions holds the settings for the top command
+type topOptions struct {
+	Interval time.Duration
+	SortBy   string
+	Prefix   string
+	Once     bool
+}
+
+// topRow is one line of the top table
+type topRow struct {
+	Name       string
+	Status     string
+	CPUPercent float64
+	HasCPU     bool
+	Memory     int64
+	DiskIO     int64
+	NetIO      int64
+}
+
+var validTopSortColumns = []string{"name", "cpu", "memory", "disk", "net"}
+
+// validateTopOptions validates the top command options
+func validateTopOptions(opts topOptions) error {
+	if opts.Interval <= 0 {
+		return fmt.Errorf("interval must be greater than zero")
+	}
+	for _, column := range validTopSortColumns {
+		if opts.SortBy == column {
+			return nil
+		}
+	}
+	return helpers.UsageErrorf("invalid sort column '%s': must be one of %s", opts.SortBy, strings.Join(validTopSortColumns, ", "))
+}
+
+// runTop refreshes the resource table until the context is cancelled
+func runTop(ctx context.Context, manager TopManager, opts topOptions, out io.Writer) error {
+	if err := validateTopOptions(opts); err != nil {
+		return err
+	}
+
+	var previous []helpers.ContainerState
+	var previousTime time.Time
+
+	for {
+		states, err := manager.ListContainerStates(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get container states: %w", err)
+		}
+		now := time.Now()
+
+		rows := buildTopRows(previous, states, now.Sub(previousTime), opts.Prefix)
+		sortTopRows(rows, opts.SortBy)
+
+		if !opts.Once {
+			// Clear the screen and move the cursor home before redrawing
+			fmt.Fprint(out, "\033[H\033[2J")
+			fmt.Fprintf(out, "lxc-go-cli top - %s (refresh %s, sort %s)\n\n", now.Format("15:04:05"), opts.Interval, opts.SortBy)
+		}
+		fmt.Fprint(out, formatTopRows(rows))
+
+		if opts.Once {
+			return nil
+		}
+
+		previous, previousTime = states, now
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// buildTopRows converts container states into table rows, computing CPU usage from the previous sample
+func buildTopRows(previous, current []helpers.ContainerState, elapsed time.Duration, prefix string) []topRow {
+	previousCPU := make(map[string]int64, len(previous))
+	for _, state := range previous {
+		previousCPU[state.Name] = state.CPUUsageNs
+	}
+
+	var rows []topRow
+	for _, state := range helpers.WithoutTrash(current) {
+		if !helpers.IsManagedContainer(state.Config) {
+			continue
+		}
+		if prefix != "" && !strings.HasPrefix(state.Name, prefix) {
+			continue
+		}
+
+		row := topRow{
+			Name:   state.Name,
+			Status: state.Status,
+			Memory: state.MemoryUsage,
+			DiskIO: state.DiskReadBytes + state.DiskWriteBytes,
+			NetIO:  state.NetRxBytes + state.NetTxBytes,
+		}
+		if prevUsage, ok := previousCPU[state.Name]; ok && elapsed > 0 && state.CPUUsageNs >= prevUsage {
+			row.CPUPercent = float64(state.CPUUsageNs-prevUsage) / float64(elapsed.Nanoseconds()) * 100
+			row.HasCPU = true
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// sortTopRows sorts rows by the given column; resource columns sort largest first
+func sortTopRows(rows []topRow, sortBy string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch sortBy {
+		case "cpu":
+			return rows[i].CPUPercent > rows[j].CPUPercent
+		case "memory":
+			return rows[i].Memory > rows[j].Memory
+		case "disk":
+			return rows[i].DiskIO > rows[j].DiskIO
+		case "net":
+			return rows[i].NetIO > rows[j].NetIO
+		default:
+			return rows[i].Name < rows[j].Name
+		}
+	})
+}
+
+// formatTopRows formats rows for display
+func formatTopRows(rows []topRow) string {
+	if len(rows) == 0 {
+		return "No managed containers found\n"
+	}
+
+	var result strings.Builder
+
+	result.WriteString("NAME                  STATUS    CPU%    MEMORY      DISK IO     NET IO\n")
+	result.WriteString("--------------------  --------  ------  ----------  ----------  ----------\n")
+
+	for _, row := range rows {
+		cpu := "-"
+		if row.HasCPU {
+			cpu = fmt.Sprintf("%.1f", row.CPUPercent)
+		}
+		result.WriteString(fmt.Sprintf("%-20s  %-8s  %-6s  %-10s  %-10s  %s\n",
+			row.Name,
+			row.Status,
+			cpu,
+			helpers.FormatBytes(row.Memory),
+			helpers.FormatBytes(row.DiskIO),
+			helpers.FormatBytes(row.NetIO),
+		))
+	}
+
+	return result.String()
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "Refresh interval")
+	topCmd.Flags().StringVar(&topSortBy, "sort", "name", "Sort column (name, cpu, memory, disk, net)")
+	topCmd.Flags().StringVar(&topPrefix, "prefix", "", "Only show containers whose name starts with this prefix")
+	topCmd.Flags().BoolVar(&topOnce, "once", false, "Print a single snapshot and exit")
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockTopManager for testing top command
+type MockTopManager struct {
+	States    []helpers.ContainerState
+	ListError error
+	Calls     int
+}
+
+func (m *MockTopManager) ListContainerStates(ctx context.Context) ([]helpers.ContainerState, error) {
+	m.Calls++
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.States, nil
+}
+
+func managedState(name string, cpu, memory int64) helpers.ContainerState {
+	return helpers.ContainerState{
+		Name:        name,
+		Status:      "Running",
+		Config:      map[string]string{"user.app-password": "c2VjcmV0"},
+		CPUUsageNs:  cpu,
+		MemoryUsage: memory,
+	}
+}
+
+func TestTopCommand(t *testing.T) {
+	if topCmd == nil {
+		t.Fatal("topCmd should not be nil")
+	}
+
+	if topCmd.Use != "top" {
+		t.Errorf("expected Use to be 'top', got '%s'", topCmd.Use)
+	}
+
+	intervalFlag := topCmd.Flags().Lookup("interval")
+	if intervalFlag == nil {
+		t.Fatal("interval flag should exist")
+	}
+	if intervalFlag.DefValue != "2s" {
+		t.Errorf("expected interval default to be '2s', got '%s'", intervalFlag.DefValue)
+	}
+}
+
+func TestValidateTopOptions(t *testing.T) {
+	if err := validateTopOptions(topOptions{Interval: time.Second, SortBy: "cpu"}); err != nil {
+		t.Errorf("expected valid options, got %v", err)
+	}
+	if err := validateTopOptions(topOptions{Interval: 0, SortBy: "cpu"}); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if err := validateTopOptions(topOptions{Interval: time.Second, SortBy: "bogus"}); err == nil {
+		t.Error("expected error for invalid sort column")
+	}
+}
+
+func TestBuildTopRows(t *testing.T) {
+	unmanaged := helpers.ContainerState{Name: "other", Config: map[string]string{}}
+	trashed := managedState("trash-20250101-120000-web-1", 10, 300)
+	trashed.Config[helpers.TrashedKey] = "2025-01-01T12:00:00Z"
+	previous := []helpers.ContainerState{managedState("web-1", 1_000_000_000, 0)}
+	current := []helpers.ContainerState{
+		managedState("web-1", 1_500_000_000, 100),
+		managedState("db-1", 10, 200),
+		unmanaged,
+		trashed,
+	}
+
+	rows := buildTopRows(previous, current, time.Second, "")
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 managed rows without the trashed one, got %d", len(rows))
+	}
+	if !rows[0].HasCPU || rows[0].CPUPercent != 50 {
+		t.Errorf("expected web-1 at 50%% CPU, got %+v", rows[0])
+	}
+	if rows[1].HasCPU {
+		t.Error("db-1 has no previous sample and should not report CPU")
+	}
+
+	rows = buildTopRows(nil, current, time.Second, "db-")
+	if len(rows) != 1 || rows[0].Name != "db-1" {
+		t.Errorf("expected prefix filter to keep only db-1, got %+v", rows)
+	}
+}
+
+func TestSortTopRows(t *testing.T) {
+	rows := []topRow{
+		{Name: "b", Memory: 10},
+		{Name: "a", Memory: 30},
+		{Name: "c", Memory: 20},
+	}
+
+	sortTopRows(rows, "name")
+	if rows[0].Name != "a" || rows[2].Name != "c" {
+		t.Errorf("unexpected name order: %+v", rows)
+	}
+
+	sortTopRows(rows, "memory")
+	if rows[0].Name != "a" || rows[1].Name != "c" {
+		t.Errorf("unexpected memory order: %+v", rows)
+	}
+}
+
+func TestRunTopOnce(t *testing.T) {
+	manager := &MockTopManager{States: []helpers.ContainerState{managedState("web-1", 0, 2048)}}
+
+	var out bytes.Buffer
+	err := runTop(context.Background(), manager, topOptions{Interval: time.Second, SortBy: "name", Once: true}, &out)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Calls != 1 {
+		t.Errorf("expected 1 call to ListContainerStates, got %d", manager.Calls)
+	}
+	if !strings.Contains(out.String(), "web-1") || !strings.Contains(out.String(), "2.0KiB") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRunTopError(t *testing.T) {
+	manager := &MockTopManager{ListError: fmt.Errorf("lxc failed")}
+
+	err := runTop(context.Background(), manager, topOptions{Interval: time.Second, SortBy: "name", Once: true}, &bytes.Buffer{})
+	if err == nil || !contains(err.Error(), "failed to get container states") {
+		t.Errorf("expected state error, got %v", err)
+	}
+}
+
+func TestRunTopStopsOnCancel(t *testing.T) {
+	manager := &MockTopManager{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := runTop(ctx, manager, topOptions{Interval: time.Hour, SortBy: "name"}, &bytes.Buffer{})
+	if err != nil {
+		t.Errorf("expected no error on cancellation, got %v", err)
+	}
+}
+
+func TestFormatTopRowsEmpty(t *testing.T) {
+	if !strings.Contains(formatTopRows(nil), "No managed containers found") {
+		t.Error("expected empty message")
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/i18n"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashOlderThan time.Duration
+	trashEmptyAll  bool
+	trashTimeout   time.Duration
+	restoreAs      string
+	restoreTimeout time.Duration
+)
+
+// trashCmd represents the trash command
+var trashCmd = &cobra.Command{
+	Use:   "trash <list|empty>",
+	Short: "List or empty deleted containers kept in the trash",
+	Long: `'delete' moves containers to the trash: they are stopped, kept from
+starting at boot and renamed to trash-<time>-<name>. They keep their disk
+space until the trash is emptied.
+
+  list    shows the containers in the trash and when they were deleted
+  empty   destroys containers trashed longer than --older-than (a week by
+          default), or all of them with --all
+
+Bring a container back with 'restore-from-trash <name>'.
+
+Examples:
+  lxc-go-cli trash list
+  lxc-go-cli trash empty
+  lxc-go-cli trash empty --older-than 24h
+  lxc-go-cli trash empty --all --yes`,
+}
+
+// trashListCmd represents the trash list subcommand
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the containers in the trash",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), trashTimeout)
+		defer cancel()
+
+		return listTrash(ctx, &DefaultTrashManager{}, cmd.OutOrStdout())
+	},
+}
+
+// trashEmptyCmd represents the trash empty subcommand
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Destroy containers kept in the trash for too long",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), trashTimeout)
+		defer cancel()
+
+		cutoff := time.Now().Add(-trashOlderThan)
+		if trashEmptyAll {
+			cutoff = time.Now().Add(time.Minute)
+		}
+		return emptyTrash(ctx, &DefaultTrashManager{}, cutoff)
+	},
+}
+
+// restoreFromTrashCmd represents the restore-from-trash command
+var restoreFromTrashCmd = &cobra.Command{
+	Use:   "restore-from-trash <container-name>",
+	Short: "Undo the deletion of a container",
+	Long: `Bring back a container deleted with 'delete' from the trash.
+
+The name is the container's name before it was deleted, or its trash name
+as shown by 'trash list'; of several deleted containers with the same name
+the most recent is restored. It gets its old name back, or the one given
+with --as when the old name has been taken since. The container is restored
+stopped, with its previous boot.autostart setting.
+
+Examples:
+  lxc-go-cli restore-from-trash web
+  lxc-go-cli restore-from-trash web --as web-old
+  lxc-go-cli restore-from-trash trash-20250101-120000-web`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+		defer cancel()
+
+		return restoreFromTrash(ctx, &DefaultTrashManager{}, args[0], restoreAs)
+	},
+}
+
+// TrashManager interface for dependency injection
+type TrashManager interface {
+	DeleteManager
+	MoveToTrash(ctx context.Context, name, trashName string, running bool, now time.Time) error
+	RestoreFromTrash(ctx context.Context, trashName, name string, config map[string]string) error
+}
+
+// DefaultTrashManager implements TrashManager using helpers
+type DefaultTrashManager struct {
+	DefaultDeleteManager
+}
+
+func (d *DefaultTrashManager) MoveToTrash(ctx context.Context, name, trashName string, running bool, now time.Time) error {
+	return helpers.MoveToTrash(ctx, name, trashName, running, now)
+}
+
+func (d *DefaultTrashManager) RestoreFromTrash(ctx context.Context, trashName, name string, config map[string]string) error {
+	return helpers.RestoreFromTrash(ctx, trashName, name, config)
+}
+
+// trashContainer moves a container to the trash after the same checks as
+// deleteContainer. Containers that cannot be kept, because they are
+// ephemeral or already trashed, are deleted instead.
+func trashContainer(ctx context.Context, manager TrashManager, name string, force, includeUnmanaged bool, now time.Time) error {
+	state, err := deletableContainer(ctx, manager, name, force, includeUnmanaged)
+	if err != nil {
+		return err
+	}
+	switch {
+	case helpers.IsTrashed(state.Config):
+		logger.Info("%s", i18n.T("trash.already_trashed", name))
+		return deleteContainer(ctx, manager, name, force, includeUnmanaged)
+	case state.Ephemeral:
+		logger.Info("%s", i18n.T("trash.ephemeral", name))
+		return deleteContainer(ctx, manager, name, force, includeUnmanaged)
+	}
+
+	trashName := helpers.TrashName(name, now)
+	logger.Info("%s", i18n.T("trash.moving", name))
+	if err := manager.MoveToTrash(ctx, name, trashName, state.Status != "Stopped", now); err != nil {
+		return i18n.Errorf("delete.failed", name, err)
+	}
+
+	logger.Info("%s", i18n.T("trash.moved", name, trashName, name))
+	return nil
+}
+
+// restoreFromTrash renames a trashed container back to its old name, or to as
+func restoreFromTrash(ctx context.Context, manager TrashManager, name, as string) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	trashed, err := helpers.FindTrashed(helpers.ListTrash(states), name)
+	if err != nil {
+		return err
+	}
+
+	target := as
+	if target == "" {
+		target = trashed.Original
+	}
+	if err := helpers.ValidateContainerName(target); err != nil {
+		return err
+	}
+	var config map[string]string
+	for _, state := range states {
+		if state.Name == target {
+			return helpers.WithKind(i18n.Errorf("trash.target_exists", target), helpers.ErrConflict)
+		}
+		if state.Name == trashed.Name {
+			config = state.Config
+		}
+	}
+
+	logger.Info("%s", i18n.T("trash.restoring", trashed.Name, target))
+	if err := manager.RestoreFromTrash(ctx, trashed.Name, target, config); err != nil {
+		return err
+	}
+	logger.Info("%s", i18n.T("trash.restored", target, target))
+	return nil
+}
+
+// listTrash prints the containers in the trash
+func listTrash(ctx context.Context, manager ListManager, out io.Writer) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	trash := helpers.ListTrash(states)
+	if len(trash) == 0 {
+		fmt.Fprintln(out, i18n.T("trash.empty"))
+		return nil
+	}
+
+	table := render.NewTable("NAME", "TRASH NAME", "DELETED")
+	for _, container := range trash {
+		table.AddRow(container.Original, container.Name, formatImageTime(container.Trashed))
+	}
+	return table.Render(out, render.Options{})
+}
+
+// emptyTrash destroys the containers trashed before cutoff
+func emptyTrash(ctx context.Context, manager DeleteManager, cutoff time.Time) error {
+	states, err := manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	expired := helpers.TrashToEmpty(helpers.ListTrash(states), cutoff)
+	if len(expired) == 0 {
+		logger.Info("%s", i18n.T("trash.nothing_expired", formatImageTime(cutoff)))
+		return nil
+	}
+	if err := confirm("trash.empty_confirm", len(expired)); err != nil {
+		return err
+	}
+
+	for _, container := range expired {
+		logger.Info("%s", i18n.T("trash.deleting", container.Name, container.Original))
+		if err := manager.DeleteContainer(ctx, container.Name, true); err != nil {
+			return i18n.Errorf("delete.failed", container.Name, err)
+		}
+	}
+	logger.Info("%s", i18n.T("trash.emptied", len(expired)))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(restoreFromTrashCmd)
+
+	trashCmd.PersistentFlags().DurationVarP(&trashTimeout, "timeout", "t", 5*time.Minute, "Timeout for the trash operation")
+	trashEmptyCmd.Flags().DurationVar(&trashOlderThan, "older-than", helpers.DefaultTrashRetention, "Destroy containers trashed longer ago than this")
+	trashEmptyCmd.Flags().BoolVar(&trashEmptyAll, "all", false, "Destroy every container in the trash")
+	restoreFromTrashCmd.Flags().StringVar(&restoreAs, "as", "", "Name to restore the container under (default: its name before deletion)")
+	restoreFromTrashCmd.Flags().DurationVarP(&restoreTimeout, "timeout", "t", 2*time.Minute, "Timeout for the restore operation")
+}
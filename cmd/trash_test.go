@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockTrashManager for testing delete to the trash and restoring
+type MockTrashManager struct {
+	*MockListManager
+	Trashed  map[string]string
+	Running  map[string]bool
+	Restored map[string]string
+	Config   map[string]string
+}
+
+func newMockTrashManager() *MockTrashManager {
+	list := newMockListManager()
+	list.States = append(list.States,
+		helpers.ContainerState{Name: "trash-20250101-120000-db", Status: "Stopped", Config: map[string]string{
+			helpers.ManagedMarkerKey: "true",
+			helpers.TrashedKey:       "2025-01-01T12:00:00Z",
+			helpers.TrashOriginalKey: "db",
+		}},
+		helpers.ContainerState{Name: "trash-20250105-120000-web", Status: "Stopped", Config: map[string]string{
+			helpers.ManagedMarkerKey: "true",
+			helpers.TrashedKey:       "2025-01-05T12:00:00Z",
+			helpers.TrashOriginalKey: "web",
+		}},
+	)
+	return &MockTrashManager{
+		MockListManager: list,
+		Trashed:         map[string]string{},
+		Running:         map[string]bool{},
+		Restored:        map[string]string{},
+	}
+}
+
+func (m *MockTrashManager) MoveToTrash(ctx context.Context, name, trashName string, running bool, now time.Time) error {
+	m.Trashed[name] = trashName
+	m.Running[name] = running
+	return nil
+}
+
+func (m *MockTrashManager) RestoreFromTrash(ctx context.Context, trashName, name string, config map[string]string) error {
+	m.Restored[trashName] = name
+	m.Config = config
+	return nil
+}
+
+func TestTrashContainer(t *testing.T) {
+	now := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	manager := newMockTrashManager()
+	if err := trashContainer(context.Background(), manager, "web", true, false, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Trashed["web"] != "trash-20250110-080000-web" || !manager.Running["web"] || len(manager.Deleted) != 0 {
+		t.Errorf("expected web to be stopped and trashed, got %v %v %v", manager.Trashed, manager.Running, manager.Deleted)
+	}
+
+	if err := trashContainer(context.Background(), manager, "web", false, false, now); err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Errorf("expected running check, got %v", err)
+	}
+	if err := trashContainer(context.Background(), manager, "unrelated", true, false, now); err == nil || !strings.Contains(err.Error(), "not managed") {
+		t.Errorf("expected managed check, got %v", err)
+	}
+
+	manager = newMockTrashManager()
+	if err := trashContainer(context.Background(), manager, "ci", true, false, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := trashContainer(context.Background(), manager, "trash-20250101-120000-db", false, false, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Trashed) != 0 || strings.Join(manager.Deleted, ",") != "ci,trash-20250101-120000-db" {
+		t.Errorf("expected ephemeral and trashed containers to be deleted, got %v %v", manager.Trashed, manager.Deleted)
+	}
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	ctx := context.Background()
+
+	manager := newMockTrashManager()
+	if err := restoreFromTrash(ctx, manager, "db", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Restored["trash-20250101-120000-db"] != "db" || manager.Config[helpers.TrashOriginalKey] != "db" {
+		t.Errorf("expected db to be restored with its config, got %v %v", manager.Restored, manager.Config)
+	}
+
+	// 'web' has been created again since it was deleted
+	manager = newMockTrashManager()
+	if err := restoreFromTrash(ctx, manager, "web", ""); err == nil || !strings.Contains(err.Error(), "--as") || exitCode(err) != ExitConflict {
+		t.Errorf("expected a name clash conflict, got %v", err)
+	}
+	if err := restoreFromTrash(ctx, manager, "trash-20250105-120000-web", "web-old"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Restored["trash-20250105-120000-web"] != "web-old" {
+		t.Errorf("expected web to be restored as web-old, got %v", manager.Restored)
+	}
+
+	if err := restoreFromTrash(ctx, manager, "legacy", ""); err == nil || !strings.Contains(err.Error(), "in the trash") {
+		t.Errorf("expected not in trash error, got %v", err)
+	}
+	if err := restoreFromTrash(ctx, manager, "db", "bad_name"); err == nil {
+		t.Error("expected an invalid name to be rejected")
+	}
+}
+
+func TestListTrash(t *testing.T) {
+	var out bytes.Buffer
+	if err := listTrash(context.Background(), newMockTrashManager(), &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output := out.String()
+	if !strings.Contains(output, "TRASH NAME") || strings.Index(output, "trash-20250101-120000-db") > strings.Index(output, "trash-20250105-120000-web") {
+		t.Errorf("expected the trash oldest first, got:\n%s", output)
+	}
+	if strings.Contains(output, "legacy") {
+		t.Errorf("expected only trashed containers, got:\n%s", output)
+	}
+
+	out.Reset()
+	if err := listTrash(context.Background(), newMockListManager(), &out); err != nil || !strings.Contains(out.String(), "The trash is empty") {
+		t.Errorf("expected an empty trash, got %q, %v", out.String(), err)
+	}
+}
+
+func TestEmptyTrash(t *testing.T) {
+	manager := newMockTrashManager()
+	if err := emptyTrash(context.Background(), manager, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(manager.Deleted, ",") != "trash-20250101-120000-db" {
+		t.Errorf("expected only the expired container to be deleted, got %v", manager.Deleted)
+	}
+
+	useConfirmAnswer(t, "n\n")
+	manager = newMockTrashManager()
+	if err := emptyTrash(context.Background(), manager, time.Now()); !errors.Is(err, errAborted) || len(manager.Deleted) != 0 {
+		t.Errorf("expected a declined confirmation to delete nothing, got %v %v", err, manager.Deleted)
+	}
+}
+
+func TestListHidesTrash(t *testing.T) {
+	var out bytes.Buffer
+	if err := listContainers(context.Background(), newMockTrashManager(), true, nil, &out, listTable.options(&out)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(out.String(), "trash-") {
+		t.Errorf("expected trashed containers to be hidden, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var tunnelAddress string
+
+// tunnelCmd represents the tunnel command
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel <container-name> <host-port> <container-port>",
+	Short: "Temporarily forward a host port to a container until Ctrl-C",
+	Long: `Forward a TCP port from the host to a container for as long as the command runs.
+
+Unlike 'port add', no proxy device is added to the container: the forward is a
+userspace proxy in this process and disappears when it exits (Ctrl-C). The
+container must be running and reachable from this machine on its IPv4 address.
+
+By default the host port only listens on 127.0.0.1; use --address to expose it
+on other interfaces.
+
+Examples:
+  lxc-go-cli tunnel mycontainer 8080 80
+  lxc-go-cli tunnel mycontainer 5433 5432 --address 0.0.0.0`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		return runTunnel(ctx, &DefaultTunnelManager{}, args[0], args[1], args[2], tunnelAddress)
+	},
+}
+
+// TunnelManager interface for dependency injection
+type TunnelManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	Listen(address string) (net.Listener, error)
+}
+
+// DefaultTunnelManager implements TunnelManager using helpers
+type DefaultTunnelManager struct{}
+
+func (d *DefaultTunnelManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainerStates()
+}
+
+func (d *DefaultTunnelManager) Listen(address string) (net.Listener, error) {
+	return net.Listen("tcp", address)
+}
+
+// runTunnel forwards hostPort to the container's containerPort until ctx is cancelled
+func runTunnel(ctx context.Context, manager TunnelManager, name, hostPort, containerPort, address string) error {
+	if err := validatePortForwardingArgs(name, hostPort, containerPort, "tcp"); err != nil {
+		return err
+	}
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	if net.ParseIP(address) == nil {
+		return helpers.UsageErrorf("invalid listen address '%s': must be an IP address", address)
+	}
+
+	state, err := findContainer(ctx, manager, name)
+	if err != nil {
+		return err
+	}
+	if state.Status != "Running" {
+		return fmt.Errorf("container '%s' is %s; start it before opening a tunnel", name, state.Status)
+	}
+	if state.IPv4 == "" {
+		return fmt.Errorf("container '%s' has no IPv4 address", name)
+	}
+
+	listenAddr := net.JoinHostPort(address, hostPort)
+	listener, err := manager.Listen(listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+
+	target := net.JoinHostPort(state.IPv4, containerPort)
+	logger.Info("Forwarding %s -> %s (%s); press Ctrl-C to stop", listener.Addr(), name, target)
+	if err := helpers.ServeTunnel(ctx, listener, target); err != nil {
+		return err
+	}
+	logger.Info("Tunnel to '%s' closed", name)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(tunnelCmd)
+
+	tunnelCmd.Flags().StringVar(&tunnelAddress, "address", "127.0.0.1", "Host address to listen on")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockTunnelManager implements TunnelManager for testing
+type MockTunnelManager struct {
+	States     []helpers.ContainerState
+	ListenAddr string
+	// Listening receives the listener once the tunnel has opened it
+	Listening chan net.Listener
+}
+
+func (m *MockTunnelManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockTunnelManager) Listen(address string) (net.Listener, error) {
+	m.ListenAddr = address
+	// Tests listen on an ephemeral port instead of the requested one
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err == nil && m.Listening != nil {
+		m.Listening <- listener
+	}
+	return listener, err
+}
+
+func TestRunTunnelValidation(t *testing.T) {
+	manager := &MockTunnelManager{States: []helpers.ContainerState{
+		{Name: "stopped", Status: "Stopped"},
+		{Name: "no-ip", Status: "Running"},
+	}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		container     string
+		hostPort      string
+		containerPort string
+		address       string
+		expected      string
+	}{
+		{"empty name", "", "8080", "80", "", "container name is required"},
+		{"invalid host port", "web", "abc", "80", "", "invalid host port"},
+		{"invalid container port", "web", "8080", "70000", "", "invalid container port"},
+		{"invalid address", "web", "8080", "80", "localhost", "invalid listen address"},
+		{"missing container", "web", "8080", "80", "", "does not exist"},
+		{"stopped container", "stopped", "8080", "80", "", "start it before opening a tunnel"},
+		{"no address", "no-ip", "8080", "80", "", "has no IPv4 address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runTunnel(ctx, manager, tt.container, tt.hostPort, tt.containerPort, tt.address)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+	if manager.ListenAddr != "" {
+		t.Errorf("nothing should listen when validation fails, got %s", manager.ListenAddr)
+	}
+}
+
+func TestRunTunnel(t *testing.T) {
+	// The "container" is an echo server on the loopback address
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	_, containerPort, _ := net.SplitHostPort(server.Addr().String())
+
+	manager := &MockTunnelManager{
+		States:    []helpers.ContainerState{{Name: "web", Status: "Running", IPv4: "127.0.0.1"}},
+		Listening: make(chan net.Listener, 1),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runTunnel(ctx, manager, "web", "8080", containerPort, "") }()
+
+	var listener net.Listener
+	select {
+	case listener = <-manager.Listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel did not start listening")
+	}
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	if manager.ListenAddr != "127.0.0.1:8080" {
+		t.Errorf("expected to listen on loopback by default, got %s", manager.ListenAddr)
+	}
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("ping\n"))
+	if line, err := bufio.NewReader(conn).ReadString('\n'); err != nil || line != "ping\n" {
+		t.Errorf("expected echo through tunnel, got %q (%v)", line, err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected tunnel to close cleanly, got %v", err)
+	}
+}
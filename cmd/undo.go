@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// keepPartial leaves the changes of a failed multi-step command in place
+// instead of reverting them
+var keepPartial bool
+
+// undoTimeout bounds reverting a failed command; the command's own context
+// may already have run out
+const undoTimeout = 2 * time.Minute
+
+// undoAction reverts one completed step
+type undoAction struct {
+	description string
+	undo        func(ctx context.Context) error
+}
+
+// UndoStack records how to revert the steps of a multi-step command as they
+// complete, so a failure part way through can undo the ones that ran, most
+// recent first. Steps running concurrently may push to it.
+type UndoStack struct {
+	// Keep leaves everything in place on failure, for --keep-partial
+	Keep bool
+
+	mu      sync.Mutex
+	actions []undoAction
+}
+
+// NewUndoStack creates an UndoStack honoring --keep-partial
+func NewUndoStack() *UndoStack {
+	return &UndoStack{Keep: keepPartial}
+}
+
+// Push records how to revert a step that just completed, e.g. "remove
+// device 'web-80-80-tcp'"
+func (u *UndoStack) Push(description string, undo func(ctx context.Context) error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.actions = append(u.actions, undoAction{description: description, undo: undo})
+}
+
+// Rollback reverts the recorded steps after err, most recent first, unless
+// Keep is set. Failures to revert are logged rather than returned so they do
+// not hide err. It reports whether anything was reverted.
+func (u *UndoStack) Rollback(err error) bool {
+	u.mu.Lock()
+	actions := u.actions
+	u.actions = nil
+	u.mu.Unlock()
+
+	if err == nil || len(actions) == 0 {
+		return false
+	}
+	if u.Keep {
+		logger.Warn("Keeping the %d completed step(s) of the failed operation (--keep-partial)", len(actions))
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), undoTimeout)
+	defer cancel()
+	logger.Info("Reverting the %d completed step(s) of the failed operation...", len(actions))
+	for i := len(actions) - 1; i >= 0; i-- {
+		logger.Info("Undo: %s", actions[i].description)
+		if undoErr := actions[i].undo(ctx); undoErr != nil {
+			logger.Warn("Failed to %s: %v", actions[i].description, undoErr)
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUndoStackRollback(t *testing.T) {
+	var undone []string
+	undo := &UndoStack{}
+	for _, name := range []string{"first", "second", "third"} {
+		undo.Push("undo "+name, func(ctx context.Context) error {
+			undone = append(undone, name)
+			if name == "second" {
+				return errors.New("cannot undo")
+			}
+			return nil
+		})
+	}
+
+	if !undo.Rollback(errors.New("step failed")) {
+		t.Error("expected the steps to be reverted")
+	}
+	// A failing undo does not stop the rest
+	if expected := []string{"third", "second", "first"}; !reflect.DeepEqual(undone, expected) {
+		t.Errorf("expected steps undone in reverse order %v, got %v", expected, undone)
+	}
+	if undo.Rollback(errors.New("step failed")) {
+		t.Error("expected nothing left to revert")
+	}
+}
+
+func TestUndoStackKeep(t *testing.T) {
+	undone := false
+	undo := &UndoStack{Keep: true}
+	undo.Push("undo", func(ctx context.Context) error {
+		undone = true
+		return nil
+	})
+	if undo.Rollback(errors.New("step failed")) || undone {
+		t.Error("expected --keep-partial to keep the completed steps")
+	}
+
+	undo = &UndoStack{}
+	undo.Push("undo", func(ctx context.Context) error {
+		undone = true
+		return nil
+	})
+	if undo.Rollback(nil) || undone {
+		t.Error("expected nothing to be reverted without an error")
+	}
+}
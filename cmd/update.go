@@ -0,0 +1,256 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateTimeout      time.Duration
+	updateAll          bool
+	updateSecurityOnly bool
+	updateNoSnapshot   bool
+	updateSelector     string
+	updateTimings      string
+)
+
+// updateNotifyTimeout bounds the notification of an update's result, which is
+// sent even when the update ran out of time
+const updateNotifyTimeout = 30 * time.Second
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update <container-name>",
+	Short: "Upgrade packages and Docker inside containers",
+	Long: `Upgrade distribution packages and Docker inside a container.
+
+Runs the distribution package upgrade and bumps docker-ce, containerd and the
+Compose plugin to the latest versions from Docker's repository. A snapshot named
+pre-update-<timestamp> is taken first so a botched upgrade can be reverted with
+'lxc-go-cli rollback <container>'. A report of Docker package versions before
+and after the upgrade is printed for each container, followed on stderr by the
+time spent in the snapshot and the upgrade of each.
+
+Options:
+  --all            Update every running managed container
+  --selector       With --all, only update containers whose labels match, e.g. env=prod
+  --security-only  Only install updates from the security pocket (Docker packages are left alone)
+  --no-snapshot    Skip the pre-update snapshot
+  --timings        Time breakdown format: text, json (one line, for tracking
+                   performance across versions) or none
+
+Examples:
+  lxc-go-cli update mycontainer
+  lxc-go-cli update mycontainer --security-only
+  lxc-go-cli update --all
+  lxc-go-cli update --all --selector env=staging`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if updateSelector != "" && !updateAll {
+			return helpers.UsageErrorf("--selector requires --all")
+		}
+		if updateAll {
+			if len(args) > 0 {
+				return fmt.Errorf("container names cannot be combined with --all")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+		defer cancel()
+
+		selector, err := helpers.ParseLabelSelector(updateSelector)
+		if err != nil {
+			return err
+		}
+		if err := validateTimingsFormat(updateTimings); err != nil {
+			return err
+		}
+		opts := updateOptions{
+			UpdateOptions: helpers.UpdateOptions{SecurityOnly: updateSecurityOnly},
+			Snapshot:      !updateNoSnapshot,
+			Selector:      selector,
+			Timings:       newTimings("update", updateTimings),
+		}
+
+		manager := &DefaultUpdateManager{}
+		err = updateContainers(ctx, manager, args, opts, cmd.OutOrStdout())
+		printTimings(cmd.ErrOrStderr(), opts.Timings, updateTimings)
+		return err
+	},
+}
+
+// updateOptions controls an update run
+type updateOptions struct {
+	helpers.UpdateOptions
+	Snapshot bool
+	// Selector narrows an update of all containers to those whose labels match
+	Selector helpers.LabelSelector
+	// Timings records how long each container's steps take; nil records nothing
+	Timings *Timings
+}
+
+// UpdateManager interface for dependency injection
+type UpdateManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error)
+	CreateSnapshot(ctx context.Context, containerName, snapshotName string) error
+	GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error)
+	RunScript(ctx context.Context, containerName, script string) error
+	Notify(ctx context.Context, event helpers.NotificationEvent)
+}
+
+// DefaultUpdateManager implements UpdateManager using helpers
+type DefaultUpdateManager struct{}
+
+func (d *DefaultUpdateManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultUpdateManager) ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error) {
+	return helpers.ListManagedContainersMatching(selector)
+}
+
+func (d *DefaultUpdateManager) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	return helpers.CreateSnapshot(containerName, snapshotName)
+}
+
+func (d *DefaultUpdateManager) GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error) {
+	return helpers.GetPackageVersions(ctx, containerName, packages...)
+}
+
+func (d *DefaultUpdateManager) RunScript(ctx context.Context, containerName, script string) error {
+	return helpers.RunContainerScript(ctx, containerName, script)
+}
+
+func (d *DefaultUpdateManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	helpers.Notify(ctx, event)
+}
+
+// updateContainers upgrades each target container; no targets means every
+// running managed container matching opts.Selector
+func updateContainers(ctx context.Context, manager UpdateManager, targets []string, opts updateOptions, out io.Writer) error {
+	if len(targets) == 0 {
+		names, err := manager.ListManagedContainers(ctx, opts.Selector)
+		if err != nil {
+			return fmt.Errorf("failed to list managed containers: %w", err)
+		}
+		if len(names) == 0 && len(opts.Selector) > 0 {
+			return fmt.Errorf("no running managed containers match selector '%s'", opts.Selector)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no running managed containers found")
+		}
+		targets = names
+	}
+
+	// A single container reports its error directly
+	if len(targets) == 1 {
+		return updateContainer(ctx, manager, targets[0], opts, out)
+	}
+
+	failed := 0
+	for _, name := range targets {
+		if err := updateContainer(ctx, manager, name, opts, out); err != nil {
+			logger.Error("%v", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d container(s) failed to update", failed, len(targets))
+	}
+	return nil
+}
+
+// updateContainer snapshots and upgrades one container, prints a version
+// report and sends an update notification with the result
+func updateContainer(ctx context.Context, manager UpdateManager, containerName string, opts updateOptions, out io.Writer) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if !manager.ContainerExists(ctx, containerName) {
+		return containerNotFound(containerName)
+	}
+
+	// The update's context may have expired, which is what gets reported
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), updateNotifyTimeout)
+	defer cancel()
+
+	report, err := upgradeContainer(ctx, manager, containerName, opts)
+	if err != nil {
+		manager.Notify(notifyCtx, helpers.NotificationEvent{
+			Event:     helpers.EventUpdate,
+			Container: containerName,
+			Message:   fmt.Sprintf("Updating container '%s' failed", containerName),
+			Error:     err.Error(),
+		})
+		return err
+	}
+
+	fmt.Fprintf(out, "Container '%s' updated:\n", containerName)
+	fmt.Fprint(out, report)
+	manager.Notify(notifyCtx, helpers.NotificationEvent{
+		Event:     helpers.EventUpdate,
+		Container: containerName,
+		Message:   fmt.Sprintf("Container '%s' updated", containerName),
+		Details:   report,
+	})
+	return nil
+}
+
+// upgradeContainer runs the upgrade and returns the Docker package version report
+func upgradeContainer(ctx context.Context, manager UpdateManager, containerName string, opts updateOptions) (string, error) {
+	if opts.Snapshot {
+		err := opts.Timings.Time(containerName, "snapshot", func() error {
+			_, err := snapshotBeforeOperation(ctx, manager, containerName, "update")
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	before, err := manager.GetPackageVersions(ctx, containerName, helpers.DockerPackages...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read package versions in container '%s': %w", containerName, err)
+	}
+
+	if opts.SecurityOnly {
+		logger.Info("Installing security updates in container '%s'...", containerName)
+	} else {
+		logger.Info("Upgrading packages and Docker in container '%s'...", containerName)
+	}
+	err = opts.Timings.Time(containerName, "upgrade", func() error {
+		return manager.RunScript(ctx, containerName, helpers.BuildUpgradeScript(opts.UpdateOptions))
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to update container '%s': %w", containerName, err)
+	}
+
+	after, err := manager.GetPackageVersions(ctx, containerName, helpers.DockerPackages...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read package versions in container '%s': %w", containerName, err)
+	}
+	return helpers.FormatVersionReport(before, after), nil
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().DurationVarP(&updateTimeout, "timeout", "t", 30*time.Minute, "Timeout for the update operation")
+	updateCmd.Flags().BoolVar(&updateAll, "all", false, "Update every running managed container")
+	updateCmd.Flags().StringVar(&updateSelector, "selector", "", "Only update containers whose labels match, e.g. env=prod,team=core (with --all)")
+	updateCmd.Flags().BoolVar(&updateSecurityOnly, "security-only", false, "Only install security updates")
+	updateCmd.Flags().BoolVar(&updateNoSnapshot, "no-snapshot", false, "Skip the pre-update snapshot")
+	updateCmd.Flags().StringVar(&updateTimings, "timings", TimingsText, "Time spent per step, printed to stderr at the end: text, json or none")
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockUpdateManager for testing update command
+type MockUpdateManager struct {
+	ExistingContainers map[string]bool
+	ManagedContainers  []string
+	SnapshotError      error
+	ScriptError        map[string]error
+	Snapshots          []string
+	Scripts            []string
+	Notifications      []helpers.NotificationEvent
+	NotifyContextErrs  []error
+	Labels             map[string]map[string]string
+	versionCalls       int
+	Calls              map[string]int
+}
+
+func (m *MockUpdateManager) ContainerExists(ctx context.Context, name string) bool {
+	m.trackCall("ContainerExists")
+	return m.ExistingContainers[name]
+}
+
+func (m *MockUpdateManager) ListManagedContainers(ctx context.Context, selector helpers.LabelSelector) ([]string, error) {
+	m.trackCall("ListManagedContainers")
+	var names []string
+	for _, name := range m.ManagedContainers {
+		if selector.Matches(m.Labels[name]) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (m *MockUpdateManager) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	m.trackCall("CreateSnapshot")
+	if m.SnapshotError != nil {
+		return m.SnapshotError
+	}
+	m.Snapshots = append(m.Snapshots, containerName+"/"+snapshotName)
+	return nil
+}
+
+func (m *MockUpdateManager) GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error) {
+	m.trackCall("GetPackageVersions")
+	m.versionCalls++
+	// Alternate between the pre- and post-upgrade versions
+	if m.versionCalls%2 == 1 {
+		return map[string]string{"docker-ce": "5:27.0.1", "docker-compose-plugin": "2.28.1"}, nil
+	}
+	return map[string]string{"docker-ce": "5:27.1.0", "docker-compose-plugin": "2.28.1"}, nil
+}
+
+func (m *MockUpdateManager) RunScript(ctx context.Context, containerName, script string) error {
+	m.trackCall("RunScript")
+	m.Scripts = append(m.Scripts, script)
+	return m.ScriptError[containerName]
+}
+
+func (m *MockUpdateManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	m.Notifications = append(m.Notifications, event)
+	m.NotifyContextErrs = append(m.NotifyContextErrs, ctx.Err())
+}
+
+func (m *MockUpdateManager) trackCall(method string) {
+	if m.Calls == nil {
+		m.Calls = make(map[string]int)
+	}
+	m.Calls[method]++
+}
+
+func (m *MockUpdateManager) GetCallCount(method string) int {
+	if m.Calls == nil {
+		return 0
+	}
+	return m.Calls[method]
+}
+
+func TestUpdateCommand(t *testing.T) {
+	if updateCmd == nil {
+		t.Fatal("updateCmd should not be nil")
+	}
+	if updateCmd.Use != "update <container-name>" {
+		t.Errorf("expected Use to be 'update <container-name>', got '%s'", updateCmd.Use)
+	}
+	for _, name := range []string{"all", "selector", "security-only", "no-snapshot", "timeout"} {
+		if updateCmd.Flags().Lookup(name) == nil {
+			t.Errorf("%s flag should exist", name)
+		}
+	}
+
+	if err := updateCmd.Args(updateCmd, []string{}); err == nil {
+		t.Error("should fail without a container name")
+	}
+	if err := updateCmd.Args(updateCmd, []string{"web"}); err != nil {
+		t.Errorf("should pass with one container name: %v", err)
+	}
+}
+
+func TestUpdateContainers(t *testing.T) {
+	tests := []struct {
+		name              string
+		targets           []string
+		managed           []string
+		opts              updateOptions
+		snapshotError     error
+		scriptError       map[string]error
+		expectedError     string
+		expectedSnapshots int
+		expectedOutput    []string
+		expectedNotified  []string
+	}{
+		{
+			name:              "single container with snapshot",
+			targets:           []string{"web1"},
+			opts:              updateOptions{Snapshot: true},
+			expectedSnapshots: 1,
+			expectedOutput:    []string{"Container 'web1' updated", "docker-ce", "5:27.0.1", "5:27.1.0", "updated"},
+			expectedNotified:  []string{"Container 'web1' updated"},
+		},
+		{
+			name:    "skip snapshot",
+			targets: []string{"web1"},
+			opts:    updateOptions{Snapshot: false},
+		},
+		{
+			name:          "missing container",
+			targets:       []string{"missing"},
+			expectedError: "container 'missing' does not exist",
+		},
+		{
+			name:          "snapshot failure aborts update",
+			targets:       []string{"web1"},
+			opts:          updateOptions{Snapshot: true},
+			snapshotError: fmt.Errorf("quota exceeded"),
+			expectedError: "failed to snapshot container 'web1' before update",
+		},
+		{
+			name:          "single container upgrade fails",
+			targets:       []string{"web1"},
+			scriptError:   map[string]error{"web1": fmt.Errorf("dpkg lock")},
+			expectedError: "failed to update container 'web1'",
+		},
+		{
+			name:             "all containers with one failure",
+			managed:          []string{"web1", "web2"},
+			scriptError:      map[string]error{"web2": fmt.Errorf("dpkg lock")},
+			expectedError:    "1 of 2 container(s) failed to update",
+			expectedOutput:   []string{"Container 'web1' updated"},
+			expectedNotified: []string{"Container 'web1' updated", "Updating container 'web2' failed"},
+		},
+		{
+			name:             "all containers matching selector",
+			managed:          []string{"web1", "web2"},
+			opts:             updateOptions{Selector: helpers.LabelSelector{{Key: "env", Operator: helpers.SelectorEquals, Value: "prod"}}},
+			expectedOutput:   []string{"Container 'web1' updated"},
+			expectedNotified: []string{"Container 'web1' updated"},
+		},
+		{
+			name:          "no containers match selector",
+			managed:       []string{"web1", "web2"},
+			opts:          updateOptions{Selector: helpers.LabelSelector{{Key: "canary", Operator: helpers.SelectorExists}}},
+			expectedError: "no running managed containers match selector 'canary'",
+		},
+		{
+			name:          "no managed containers",
+			expectedError: "no running managed containers found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &MockUpdateManager{
+				ExistingContainers: map[string]bool{"web1": true, "web2": true},
+				ManagedContainers:  tt.managed,
+				SnapshotError:      tt.snapshotError,
+				ScriptError:        tt.scriptError,
+				Labels:             map[string]map[string]string{"web1": {"env": "prod"}, "web2": {"env": "dev"}},
+			}
+
+			var out bytes.Buffer
+			err := updateContainers(context.Background(), manager, tt.targets, tt.opts, &out)
+
+			if tt.expectedError != "" {
+				if err == nil || !contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if len(manager.Snapshots) != tt.expectedSnapshots {
+				t.Errorf("expected %d snapshots, got %v", tt.expectedSnapshots, manager.Snapshots)
+			}
+			for _, snapshot := range manager.Snapshots {
+				if !strings.Contains(snapshot, "/pre-update-") {
+					t.Errorf("unexpected snapshot name: %s", snapshot)
+				}
+			}
+			for _, expected := range tt.expectedOutput {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain '%s', got:\n%s", expected, out.String())
+				}
+			}
+			if tt.expectedNotified != nil {
+				var messages []string
+				for _, event := range manager.Notifications {
+					messages = append(messages, event.Message)
+				}
+				if strings.Join(messages, "|") != strings.Join(tt.expectedNotified, "|") {
+					t.Errorf("expected notifications %q, got %q", tt.expectedNotified, messages)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateContainerSecurityOnly(t *testing.T) {
+	manager := &MockUpdateManager{ExistingContainers: map[string]bool{"web1": true}}
+	opts := updateOptions{UpdateOptions: helpers.UpdateOptions{SecurityOnly: true}}
+
+	var out bytes.Buffer
+	if err := updateContainers(context.Background(), manager, []string{"web1"}, opts, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Scripts) != 1 || !strings.Contains(manager.Scripts[0], "-security") {
+		t.Errorf("expected a security-only upgrade script, got %v", manager.Scripts)
+	}
+}
+
+func TestUpdateContainerNotifiesAfterTimeout(t *testing.T) {
+	manager := &MockUpdateManager{
+		ExistingContainers: map[string]bool{"web1": true},
+		ScriptError:        map[string]error{"web1": context.DeadlineExceeded},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := updateContainer(ctx, manager, "web1", updateOptions{}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected the update to fail")
+	}
+	if len(manager.Notifications) != 1 || manager.NotifyContextErrs[0] != nil {
+		t.Errorf("expected the failure to be notified with a live context, got %v (%v)", manager.Notifications, manager.NotifyContextErrs)
+	}
+}
+
+func TestUpdateContainerTimings(t *testing.T) {
+	manager := &MockUpdateManager{ExistingContainers: map[string]bool{"web1": true, "web2": true}}
+	opts := updateOptions{Snapshot: true, Timings: NewTimings("update")}
+
+	var out bytes.Buffer
+	if err := updateContainers(context.Background(), manager, []string{"web1", "web2"}, opts, &out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var recorded []string
+	for _, step := range opts.Timings.Report().Steps {
+		recorded = append(recorded, step.Container+"/"+step.Step)
+	}
+	if strings.Join(recorded, " ") != "web1/snapshot web1/upgrade web2/snapshot web2/upgrade" {
+		t.Errorf("unexpected timings: %v", recorded)
+	}
+}
+
+func TestDefaultUpdateManager(t *testing.T) {
+	// Test that DefaultUpdateManager implements UpdateManager interface
+	var manager UpdateManager = &DefaultUpdateManager{}
+	_ = manager
+}
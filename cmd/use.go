@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var useClear bool
+
+// useCmd represents the use command
+var useCmd = &cobra.Command{
+	Use:   "use [container-name]",
+	Short: "Choose the container commands use when none is named",
+	Long: `Choose a current container for single-container workflows. exec, info, logs
+and port list use it when the container name is omitted.
+
+The choice is saved in this tool's config file together with the active
+context, and only applies while that context is active. The
+` + helpers.CurrentContainerEnvVar + ` environment variable overrides it for a
+single shell.
+
+Without arguments the current container is shown; --clear forgets it.
+
+Examples:
+  lxc-go-cli use web
+  lxc-go-cli exec -- docker ps
+  lxc-go-cli logs --docker app --follow
+  lxc-go-cli use --clear`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager := &DefaultUseManager{}
+		switch {
+		case useClear && len(args) > 0:
+			return helpers.UsageErrorf("--clear cannot be combined with a container name")
+		case useClear:
+			return clearCurrentContainer(manager)
+		case len(args) == 0:
+			return showCurrentContainer(manager, cmd.OutOrStdout())
+		}
+		return useContainer(context.Background(), manager, resolveContainerName(args[0]))
+	},
+}
+
+// UseManager interface for dependency injection
+type UseManager interface {
+	ContainerExists(ctx context.Context, name string) bool
+	LoadSettings() (*helpers.Settings, error)
+	SaveSettings(settings *helpers.Settings) error
+}
+
+// DefaultUseManager implements UseManager using helpers
+type DefaultUseManager struct{}
+
+func (d *DefaultUseManager) ContainerExists(ctx context.Context, name string) bool {
+	return helpers.ContainerExists(name)
+}
+
+func (d *DefaultUseManager) LoadSettings() (*helpers.Settings, error) {
+	return helpers.LoadSettings()
+}
+
+func (d *DefaultUseManager) SaveSettings(settings *helpers.Settings) error {
+	return helpers.SaveSettings(settings)
+}
+
+// useContainer saves a container as the current one for the active context
+func useContainer(ctx context.Context, manager UseManager, name string) error {
+	if !manager.ContainerExists(ctx, name) {
+		return containerNotFound(name)
+	}
+
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	remote, _ := helpers.ResolveContext(settings)
+	settings.Current = helpers.CurrentContainer{Name: name, Context: remote, Project: helpers.ActiveProject()}
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+
+	logger.Info("Current container set to '%s'", name)
+	if envName, source := helpers.ResolveCurrentContainer(nil); source == helpers.ContextSourceEnv && envName != name {
+		logger.Warn("%s=%s overrides the current container in this shell", helpers.CurrentContainerEnvVar, envName)
+	}
+	return nil
+}
+
+// showCurrentContainer prints the current container and its source
+func showCurrentContainer(manager UseManager, out io.Writer) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	name, source := helpers.ResolveCurrentContainer(settings)
+	if name == "" {
+		fmt.Fprintln(out, "No current container set")
+		return nil
+	}
+	if source == helpers.ContextSourceEnv {
+		source = helpers.CurrentContainerEnvVar
+	}
+	fmt.Fprintf(out, "%s (from %s)\n", name, source)
+	return nil
+}
+
+// clearCurrentContainer forgets the saved current container
+func clearCurrentContainer(manager UseManager) error {
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings.Current.Name == "" {
+		logger.Info("No current container is set")
+		return nil
+	}
+
+	settings.Current = helpers.CurrentContainer{}
+	if err := manager.SaveSettings(settings); err != nil {
+		return err
+	}
+	logger.Info("Current container cleared")
+	return nil
+}
+
+// containerArg returns the container named on the command line, matched
+// against existing names unless --exact is set, or the current container
+// chosen with 'use' when the name is omitted
+func containerArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return resolveContainerName(args[0]), nil
+	}
+
+	settings, err := helpers.LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	name, source := helpers.ResolveCurrentContainer(settings)
+	if name == "" {
+		return "", fmt.Errorf("no container name given and no current container set (choose one with 'lxc-go-cli use <container>')")
+	}
+	logger.Debug("Using current container '%s' (from %s)", name, source)
+	return name, nil
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+
+	useCmd.Flags().BoolVar(&useClear, "clear", false, "Forget the current container")
+}
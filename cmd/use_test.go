@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockUseManager for testing the use command
+type MockUseManager struct {
+	ExistingContainers map[string]bool
+	Settings           helpers.Settings
+	Saved              bool
+}
+
+func (m *MockUseManager) ContainerExists(ctx context.Context, name string) bool {
+	return m.ExistingContainers[name]
+}
+
+func (m *MockUseManager) LoadSettings() (*helpers.Settings, error) {
+	settings := m.Settings
+	return &settings, nil
+}
+
+func (m *MockUseManager) SaveSettings(settings *helpers.Settings) error {
+	m.Settings = *settings
+	m.Saved = true
+	return nil
+}
+
+func TestUseContainer(t *testing.T) {
+	t.Setenv(helpers.ContextEnvVar, "")
+	t.Setenv(helpers.CurrentContainerEnvVar, "")
+	ctx := context.Background()
+
+	manager := &MockUseManager{ExistingContainers: map[string]bool{"web": true}}
+	manager.Settings.Context = "mylab"
+	if err := useContainer(ctx, manager, "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Settings.Current != (helpers.CurrentContainer{Name: "web", Context: "mylab"}) {
+		t.Errorf("expected web to be saved for the mylab context, got %+v", manager.Settings.Current)
+	}
+
+	var out bytes.Buffer
+	if err := showCurrentContainer(manager, &out); err != nil || out.String() != "web (from config)\n" {
+		t.Errorf("unexpected show output %q (%v)", out.String(), err)
+	}
+
+	if err := clearCurrentContainer(manager); err != nil || manager.Settings.Current.Name != "" {
+		t.Errorf("expected the current container to be cleared, got %+v (%v)", manager.Settings.Current, err)
+	}
+	out.Reset()
+	if err := showCurrentContainer(manager, &out); err != nil || !strings.Contains(out.String(), "No current container") {
+		t.Errorf("unexpected show output %q (%v)", out.String(), err)
+	}
+
+	manager = &MockUseManager{}
+	if err := useContainer(ctx, manager, "missing"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing container error, got %v", err)
+	}
+	if manager.Saved {
+		t.Error("expected nothing to be saved for a missing container")
+	}
+}
+
+func TestContainerArg(t *testing.T) {
+	originalDir := helpers.SettingsDir
+	helpers.SettingsDir = t.TempDir()
+	t.Cleanup(func() { helpers.SettingsDir = originalDir })
+	t.Setenv(helpers.ContextEnvVar, "")
+	t.Setenv(helpers.CurrentContainerEnvVar, "")
+
+	if name, err := containerArg([]string{"db"}); err != nil || name != "db" {
+		t.Errorf("expected the named container, got %q (%v)", name, err)
+	}
+	if _, err := containerArg(nil); err == nil || !strings.Contains(err.Error(), "lxc-go-cli use <container>") {
+		t.Errorf("expected a hint to choose a current container, got %v", err)
+	}
+
+	if err := helpers.SaveSettings(&helpers.Settings{Current: helpers.CurrentContainer{Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := containerArg(nil); err != nil || name != "web" {
+		t.Errorf("expected the current container, got %q (%v)", name, err)
+	}
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"runtime"
+	"strings"
 
+	"github.com/deji/lxc-go-cli/internal/helpers"
 	"github.com/spf13/cobra"
 )
 
@@ -29,31 +34,132 @@ var versionCmd = &cobra.Command{
 - Version number (major.git-sha.timestamp format)
 - Git commit hash
 - Build timestamp
-- Go version and platform information`,
-	Run: func(cmd *cobra.Command, args []string) {
-		showVersion(cmd)
+- Go version and platform information
+
+With --verbose, the detected LXD client and server versions, instance and
+storage driver versions, and the backend used to talk to LXD are also shown.
+Use --output json for machine-readable output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showVersion(cmd)
 	},
 }
 
-func showVersion(cmd *cobra.Command) {
-	// Check if we want detailed output
+// VersionManager interface for dependency injection
+type VersionManager interface {
+	GetComponentVersions(ctx context.Context) *helpers.ComponentVersions
+}
+
+// DefaultVersionManager implements VersionManager using helpers
+type DefaultVersionManager struct{}
+
+func (d *DefaultVersionManager) GetComponentVersions(ctx context.Context) *helpers.ComponentVersions {
+	return helpers.GetComponentVersions()
+}
+
+// versionInfo is the JSON representation of the version output
+type versionInfo struct {
+	Version    string                     `json:"version"`
+	GitCommit  string                     `json:"git_commit"`
+	BuildTime  string                     `json:"build_time"`
+	GoVersion  string                     `json:"go_version"`
+	Platform   string                     `json:"platform"`
+	Components *helpers.ComponentVersions `json:"components,omitempty"`
+}
+
+// versionOptions controls the version output
+type versionOptions struct {
+	Detailed bool
+	Verbose  bool
+	Output   string
+}
+
+func showVersion(cmd *cobra.Command) error {
+	// Flags may be missing when called with a bare command
 	detailed, _ := cmd.Flags().GetBool("detailed")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	output, _ := cmd.Flags().GetString("output")
+
+	opts := versionOptions{Detailed: detailed, Verbose: verbose, Output: strings.ToLower(output)}
+	return writeVersion(context.Background(), &DefaultVersionManager{}, opts, cmd.OutOrStdout())
+}
+
+// writeVersion renders version information in the requested format
+func writeVersion(ctx context.Context, manager VersionManager, opts versionOptions, out io.Writer) error {
+	if opts.Output != "" && opts.Output != "text" && opts.Output != "json" {
+		return helpers.UsageErrorf("invalid output format '%s': must be 'text' or 'json'", opts.Output)
+	}
 
-	if detailed {
-		fmt.Fprintf(cmd.OutOrStdout(), "lxc-go-cli version information:\n")
-		fmt.Fprintf(cmd.OutOrStdout(), "  Version:    %s\n", version)
-		fmt.Fprintf(cmd.OutOrStdout(), "  Git Commit: %s\n", gitCommit)
-		fmt.Fprintf(cmd.OutOrStdout(), "  Build Time: %s\n", buildTime)
-		fmt.Fprintf(cmd.OutOrStdout(), "  Go Version: %s\n", runtime.Version())
-		fmt.Fprintf(cmd.OutOrStdout(), "  Platform:   %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	info := versionInfo{
+		Version:   version,
+		GitCommit: gitCommit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+	if opts.Verbose {
+		info.Components = manager.GetComponentVersions(ctx)
+	}
+
+	if opts.Output == "json" {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode version information: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if opts.Detailed || opts.Verbose {
+		fmt.Fprintf(out, "lxc-go-cli version information:\n")
+		fmt.Fprintf(out, "  Version:    %s\n", info.Version)
+		fmt.Fprintf(out, "  Git Commit: %s\n", info.GitCommit)
+		fmt.Fprintf(out, "  Build Time: %s\n", info.BuildTime)
+		fmt.Fprintf(out, "  Go Version: %s\n", info.GoVersion)
+		fmt.Fprintf(out, "  Platform:   %s\n", info.Platform)
 	} else {
-		fmt.Fprintf(cmd.OutOrStdout(), "lxc-go-cli %s\n", version)
+		fmt.Fprintf(out, "lxc-go-cli %s\n", info.Version)
+	}
+
+	if info.Components != nil {
+		fmt.Fprint(out, formatComponentVersions(info.Components))
+	}
+	return nil
+}
+
+// formatComponentVersions renders detected component versions
+func formatComponentVersions(c *helpers.ComponentVersions) string {
+	orUnknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nComponents:\n")
+	fmt.Fprintf(&sb, "  Backend:        %s\n", orUnknown(c.Backend))
+	fmt.Fprintf(&sb, "  Client Version: %s\n", orUnknown(c.ClientVersion))
+	fmt.Fprintf(&sb, "  Server:         %s %s\n", orUnknown(c.Server), c.ServerVersion)
+	fmt.Fprintf(&sb, "  API Version:    %s\n", orUnknown(c.APIVersion))
+	fmt.Fprintf(&sb, "  Driver:         %s %s\n", orUnknown(c.Driver), c.DriverVersion)
+	if len(c.StorageDrivers) > 0 {
+		sb.WriteString("  Storage Drivers:\n")
+		for _, driver := range c.StorageDrivers {
+			fmt.Fprintf(&sb, "    %-10s %s\n", driver.Name, driver.Version)
+		}
+	}
+	for _, err := range c.Errors {
+		fmt.Fprintf(&sb, "  Warning: %s\n", err)
 	}
+	return sb.String()
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	skipLXCCheck(versionCmd)
 
 	// Add detailed flag
 	versionCmd.Flags().BoolP("detailed", "d", false, "Show detailed version information")
+	versionCmd.Flags().Bool("verbose", false, "Also show LXD client, server and storage driver versions")
+	versionCmd.Flags().StringP("output", "o", "text", "Output format (text, json)")
 }
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
+	"github.com/deji/lxc-go-cli/internal/helpers"
 	"github.com/spf13/cobra"
 )
 
@@ -195,3 +198,76 @@ func TestVersionIsRegistered(t *testing.T) {
 		t.Error("version command should be registered with root command")
 	}
 }
+
+// MockVersionManager for testing verbose version output
+type MockVersionManager struct {
+	Components *helpers.ComponentVersions
+	Calls      int
+}
+
+func (m *MockVersionManager) GetComponentVersions(ctx context.Context) *helpers.ComponentVersions {
+	m.Calls++
+	return m.Components
+}
+
+func TestWriteVersionVerbose(t *testing.T) {
+	SetVersionInfo("1.test.20250102123456", "test-commit", "test-time")
+
+	manager := &MockVersionManager{Components: &helpers.ComponentVersions{
+		Backend:        helpers.BackendCLI,
+		ClientVersion:  "5.21.1 LTS",
+		Server:         "lxd",
+		ServerVersion:  "5.21.1",
+		APIVersion:     "1.0",
+		StorageDrivers: []helpers.StorageDriverVersion{{Name: "btrfs", Version: "6.6.3"}},
+		Errors:         []string{"something odd"},
+	}}
+
+	var buf bytes.Buffer
+	if err := writeVersion(context.Background(), manager, versionOptions{Verbose: true}, &buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, expected := range []string{"Version:    1.test.20250102123456", "Backend:        cli", "Server:         lxd 5.21.1", "btrfs", "Warning: something odd"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("output should contain '%s', got: %s", expected, buf.String())
+		}
+	}
+
+	// Components are only detected when asked for
+	manager.Calls = 0
+	buf.Reset()
+	if err := writeVersion(context.Background(), manager, versionOptions{}, &buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if manager.Calls != 0 || strings.Contains(buf.String(), "Components") {
+		t.Error("components should not be detected without --verbose")
+	}
+}
+
+func TestWriteVersionJSON(t *testing.T) {
+	SetVersionInfo("1.test.20250102123456", "test-commit", "test-time")
+	manager := &MockVersionManager{Components: &helpers.ComponentVersions{Backend: helpers.BackendCLI, ServerVersion: "5.21.1"}}
+
+	var buf bytes.Buffer
+	if err := writeVersion(context.Background(), manager, versionOptions{Verbose: true, Output: "json"}, &buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var info struct {
+		Version    string `json:"version"`
+		Components struct {
+			Backend       string `json:"backend"`
+			ServerVersion string `json:"server_version"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("expected valid JSON, got %v: %s", err, buf.String())
+	}
+	if info.Version != "1.test.20250102123456" || info.Components.Backend != "cli" || info.Components.ServerVersion != "5.21.1" {
+		t.Errorf("unexpected JSON output: %s", buf.String())
+	}
+
+	if err := writeVersion(context.Background(), manager, versionOptions{Output: "yaml"}, &buf); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitFor      []string
+	waitTimeout  time.Duration
+	waitInterval time.Duration
+)
+
+// waitCmd represents the wait command
+var waitCmd = &cobra.Command{
+	Use:   "wait <container-name>",
+	Short: "Block until a container is running, has an IP, Docker is ready or a port answers",
+	Long: `Block until every given condition holds for a container, so scripts can
+sequence actions after create or start without sleep loops.
+
+Conditions:
+  running        the container is running
+  ip             the container has an IPv4 address
+  docker-ready   the Docker daemon inside the container answers 'docker info'
+  port:<port>    a TCP connection to the container's IPv4 address on <port> succeeds
+
+The command exits non-zero if the conditions are not met within --timeout.
+
+Examples:
+  lxc-go-cli wait mycontainer --for ip
+  lxc-go-cli wait mycontainer --for docker-ready --timeout 5m
+  lxc-go-cli wait mycontainer --for running,port:8080`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		conditions, err := parseWaitConditions(waitFor)
+		if err != nil {
+			return err
+		}
+		return waitForContainer(ctx, &DefaultWaitManager{}, args[0], conditions, waitTimeout, waitInterval)
+	},
+}
+
+// WaitManager interface for dependency injection
+type WaitManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	CheckDockerReady(ctx context.Context, name string) error
+	CheckTCPPort(ctx context.Context, address string) error
+}
+
+// DefaultWaitManager implements WaitManager using helpers
+type DefaultWaitManager struct{}
+
+func (d *DefaultWaitManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainerStates()
+}
+
+func (d *DefaultWaitManager) CheckDockerReady(ctx context.Context, name string) error {
+	return helpers.CheckDockerReady(ctx, name)
+}
+
+func (d *DefaultWaitManager) CheckTCPPort(ctx context.Context, address string) error {
+	return helpers.CheckTCPPort(ctx, address)
+}
+
+// parseWaitConditions parses the --for values; none means running
+func parseWaitConditions(values []string) ([]helpers.WaitCondition, error) {
+	if len(values) == 0 {
+		values = []string{helpers.WaitRunning}
+	}
+	conditions := make([]helpers.WaitCondition, 0, len(values))
+	for _, value := range values {
+		condition, err := helpers.ParseWaitCondition(value)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// waitForContainer polls until every condition holds or the timeout expires
+func waitForContainer(ctx context.Context, manager WaitManager, name string, conditions []helpers.WaitCondition, timeout, interval time.Duration) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	labels := make([]string, len(conditions))
+	for i, condition := range conditions {
+		labels[i] = condition.String()
+	}
+	wanted := strings.Join(labels, ", ")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state, err := findContainer(ctx, manager, name)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		var pending string
+		if err == nil {
+			pending = unmetWaitCondition(ctx, manager, state, conditions)
+			if pending == "" {
+				logger.Info("Container '%s' is ready (%s)", name, wanted)
+				return nil
+			}
+			logger.Debug("Waiting for '%s': %s", name, pending)
+		}
+
+		select {
+		case <-ctx.Done():
+			if pending == "" {
+				pending = ctx.Err().Error()
+			}
+			if timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+				return helpers.TimeoutErrorf("timed out after %s waiting for '%s' (%s): %s", timeout, name, wanted, pending)
+			}
+			return fmt.Errorf("stopped waiting for '%s' (%s): %s", name, wanted, pending)
+		case <-ticker.C:
+		}
+	}
+}
+
+// unmetWaitCondition describes the first condition that does not hold yet,
+// or returns "" when all of them do
+func unmetWaitCondition(ctx context.Context, manager WaitManager, state *helpers.ContainerState, conditions []helpers.WaitCondition) string {
+	for _, condition := range conditions {
+		// Every condition needs a running container
+		if state.Status != "Running" {
+			return fmt.Sprintf("container is %s", state.Status)
+		}
+
+		switch condition.Kind {
+		case helpers.WaitIP:
+			if state.IPv4 == "" {
+				return "no IPv4 address yet"
+			}
+		case helpers.WaitDockerReady:
+			if err := manager.CheckDockerReady(ctx, state.Name); err != nil {
+				return "docker is not ready"
+			}
+		case helpers.WaitPort:
+			if state.IPv4 == "" {
+				return "no IPv4 address yet"
+			}
+			address := net.JoinHostPort(state.IPv4, strconv.Itoa(condition.Port))
+			if err := manager.CheckTCPPort(ctx, address); err != nil {
+				return fmt.Sprintf("port %d is not accepting connections", condition.Port)
+			}
+		}
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	waitCmd.Flags().StringSliceVar(&waitFor, "for", []string{helpers.WaitRunning}, "Conditions to wait for: running, ip, docker-ready, port:<port> (repeatable)")
+	waitCmd.Flags().DurationVarP(&waitTimeout, "timeout", "t", 120*time.Second, "Maximum time to wait (0 waits indefinitely)")
+	waitCmd.Flags().DurationVar(&waitInterval, "interval", 2*time.Second, "Time between checks")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockWaitManager implements WaitManager for testing; each poll returns the
+// next state in States and the last one repeats
+type MockWaitManager struct {
+	States      []helpers.ContainerState
+	Polls       int
+	DockerReady bool
+	OpenPorts   map[string]bool
+	Dialed      []string
+}
+
+func (m *MockWaitManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	index := m.Polls
+	if index >= len(m.States) {
+		index = len(m.States) - 1
+	}
+	m.Polls++
+	if index < 0 {
+		return nil, nil
+	}
+	return []helpers.ContainerState{m.States[index]}, nil
+}
+
+func (m *MockWaitManager) CheckDockerReady(ctx context.Context, name string) error {
+	if !m.DockerReady {
+		return fmt.Errorf("not ready")
+	}
+	return nil
+}
+
+func (m *MockWaitManager) CheckTCPPort(ctx context.Context, address string) error {
+	m.Dialed = append(m.Dialed, address)
+	if !m.OpenPorts[address] {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func TestParseWaitConditions(t *testing.T) {
+	conditions, err := parseWaitConditions(nil)
+	if err != nil || len(conditions) != 1 || conditions[0].Kind != helpers.WaitRunning {
+		t.Errorf("expected running by default, got %v (%v)", conditions, err)
+	}
+
+	conditions, err = parseWaitConditions([]string{"ip", "Docker-Ready", "port:8080"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(conditions) != 3 || conditions[2].Port != 8080 || conditions[1].Kind != helpers.WaitDockerReady {
+		t.Errorf("unexpected conditions: %v", conditions)
+	}
+
+	for _, value := range []string{"healthy", "port", "port:0", "port:http", "ip:1"} {
+		if _, err := parseWaitConditions([]string{value}); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestWaitForContainer(t *testing.T) {
+	ctx := context.Background()
+	starting := helpers.ContainerState{Name: "web", Status: "Stopped"}
+	noIP := helpers.ContainerState{Name: "web", Status: "Running"}
+	ready := helpers.ContainerState{Name: "web", Status: "Running", IPv4: "10.0.0.5"}
+
+	t.Run("running after a few polls", func(t *testing.T) {
+		manager := &MockWaitManager{States: []helpers.ContainerState{starting, starting, noIP}}
+		conditions, _ := parseWaitConditions([]string{"running"})
+		if err := waitForContainer(ctx, manager, "web", conditions, time.Second, time.Millisecond); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if manager.Polls != 3 {
+			t.Errorf("expected 3 polls, got %d", manager.Polls)
+		}
+	})
+
+	t.Run("ip and port", func(t *testing.T) {
+		manager := &MockWaitManager{
+			States:    []helpers.ContainerState{noIP, ready},
+			OpenPorts: map[string]bool{"10.0.0.5:8080": true},
+		}
+		conditions, _ := parseWaitConditions([]string{"ip", "port:8080"})
+		if err := waitForContainer(ctx, manager, "web", conditions, time.Second, time.Millisecond); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(manager.Dialed) != 1 || manager.Dialed[0] != "10.0.0.5:8080" {
+			t.Errorf("expected port to be checked once the IP is known, got %v", manager.Dialed)
+		}
+	})
+
+	t.Run("timeout reports the pending condition", func(t *testing.T) {
+		manager := &MockWaitManager{States: []helpers.ContainerState{ready}}
+		conditions, _ := parseWaitConditions([]string{"docker-ready"})
+		err := waitForContainer(ctx, manager, "web", conditions, 20*time.Millisecond, 5*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "docker is not ready") {
+			t.Errorf("expected timeout naming docker, got %v", err)
+		}
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		manager := &MockWaitManager{States: []helpers.ContainerState{{Name: "other", Status: "Running"}}}
+		conditions, _ := parseWaitConditions(nil)
+		err := waitForContainer(ctx, manager, "web", conditions, time.Second, time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("expected missing container error, got %v", err)
+		}
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		if err := waitForContainer(ctx, &MockWaitManager{}, "", nil, time.Second, time.Millisecond); err == nil {
+			t.Error("expected error for empty name")
+		}
+	})
+}
@@ -0,0 +1,423 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// watchMaxBackoff caps the wait between restarts of the same container
+const watchMaxBackoff = 30 * time.Minute
+
+// watchCheckTimeout bounds each health check, so one hung probe such as
+// docker info does not stall the watchdog
+const watchCheckTimeout = 30 * time.Second
+
+// Actions the watchdog records in the audit log
+const (
+	watchActionRestartContainer = "restart-container"
+	watchActionRestartCompose   = "restart-compose"
+	watchActionGiveUp           = "give-up"
+	watchActionRecovered        = "recovered"
+)
+
+var (
+	watchInterval    time.Duration
+	watchBackoff     time.Duration
+	watchMaxRestarts int
+	watchProjectDir  string
+	watchOnce        bool
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [container-name...]",
+	Short: "Restart managed containers whose Docker daemon or compose services fail",
+	Long: `Watch running managed containers and restart them when they become unhealthy.
+Without container names every running managed container is watched; stopped
+containers are left alone.
+
+Every --interval each container is checked:
+  - if 'docker info' fails or does not answer within 30s, the container is
+    restarted
+  - if a compose service in --project-dir has exited with an error or fails
+    its health check, the compose project is restarted; one-shot services
+    that exited with code 0 are fine
+
+Restarts of the same container back off, starting at --backoff and doubling
+up to 30m. After --max-restarts restarts without the container recovering, the
+watchdog gives up on it until it is healthy again. Every restart, give-up and
+recovery is appended to the audit log (audit.log in the lxc-go-cli config
+directory) as a line of JSON.
+
+The restart counts and backoff are kept in watch-state.yml in the config
+directory, so they carry over between runs. The command runs until
+interrupted; --once checks once and exits, e.g. from cron, and each run
+continues the budget and backoff of the last.
+
+Examples:
+  lxc-go-cli watch
+  lxc-go-cli watch mycontainer --interval 1m --max-restarts 3
+  lxc-go-cli watch --once`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval <= 0 {
+			return helpers.UsageErrorf("--interval must be positive")
+		}
+		if watchMaxRestarts < 1 {
+			return helpers.UsageErrorf("--max-restarts must be at least 1")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		w := newWatchdog(&DefaultWatchManager{}, watchProjectDir, watchMaxRestarts, watchBackoff)
+		if watchOnce {
+			return w.checkOnce(ctx, args)
+		}
+		return w.run(ctx, args, watchInterval)
+	},
+}
+
+// WatchManager interface for dependency injection
+type WatchManager interface {
+	ListContainers(ctx context.Context) ([]helpers.ContainerState, error)
+	CheckDockerReady(ctx context.Context, name string) error
+	ListComposeServices(ctx context.Context, containerName, projectDir string) ([]helpers.ComposeService, error)
+	RestartContainer(ctx context.Context, name string) error
+	RestartCompose(ctx context.Context, containerName, projectDir string) error
+	RecordAuditEvent(event helpers.AuditEvent) error
+	Notify(ctx context.Context, event helpers.NotificationEvent)
+	LoadWatchRecords() (map[string]helpers.WatchRecord, error)
+	SaveWatchRecords(records map[string]helpers.WatchRecord) error
+}
+
+// DefaultWatchManager implements WatchManager using helpers
+type DefaultWatchManager struct{}
+
+func (d *DefaultWatchManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return helpers.ListContainers()
+}
+
+func (d *DefaultWatchManager) CheckDockerReady(ctx context.Context, name string) error {
+	return helpers.CheckDockerReady(ctx, name)
+}
+
+func (d *DefaultWatchManager) ListComposeServices(ctx context.Context, containerName, projectDir string) ([]helpers.ComposeService, error) {
+	return helpers.ListComposeServices(ctx, containerName, projectDir)
+}
+
+func (d *DefaultWatchManager) RestartContainer(ctx context.Context, name string) error {
+	return helpers.RestartContainer(ctx, name)
+}
+
+func (d *DefaultWatchManager) RestartCompose(ctx context.Context, containerName, projectDir string) error {
+	return helpers.RestartComposeProject(ctx, containerName, projectDir)
+}
+
+func (d *DefaultWatchManager) RecordAuditEvent(event helpers.AuditEvent) error {
+	return helpers.AppendAuditEvent(event)
+}
+
+func (d *DefaultWatchManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	helpers.Notify(ctx, event)
+}
+
+func (d *DefaultWatchManager) LoadWatchRecords() (map[string]helpers.WatchRecord, error) {
+	return helpers.LoadWatchRecords()
+}
+
+func (d *DefaultWatchManager) SaveWatchRecords(records map[string]helpers.WatchRecord) error {
+	return helpers.SaveWatchRecords(records)
+}
+
+// watchdog checks containers and restarts unhealthy ones within a budget
+type watchdog struct {
+	manager     WatchManager
+	projectDir  string
+	maxRestarts int
+	backoff     time.Duration
+	// checkTimeout bounds each health check
+	checkTimeout time.Duration
+	now          func() time.Time
+	records      map[string]*helpers.WatchRecord
+	// changed is set when records differ from the saved watch state
+	changed bool
+}
+
+func newWatchdog(manager WatchManager, projectDir string, maxRestarts int, backoff time.Duration) *watchdog {
+	return &watchdog{
+		manager:      manager,
+		projectDir:   projectDir,
+		maxRestarts:  maxRestarts,
+		backoff:      backoff,
+		checkTimeout: watchCheckTimeout,
+		now:          time.Now,
+		records:      make(map[string]*helpers.WatchRecord),
+	}
+}
+
+// run checks the containers every interval until ctx is cancelled; failed
+// checks are logged so a passing problem does not stop the watchdog
+func (w *watchdog) run(ctx context.Context, names []string, interval time.Duration) error {
+	logger.Info("Watching containers every %s (Ctrl+C to stop)", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.checkOnce(ctx, names); err != nil && ctx.Err() == nil {
+			logger.Warn("Watch check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped watching containers")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkOnce checks the watched containers once and restarts unhealthy ones,
+// continuing from the restart records of earlier runs
+func (w *watchdog) checkOnce(ctx context.Context, names []string) error {
+	states, err := w.manager.ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	w.loadRecords(states)
+	defer w.saveRecords()
+
+	for _, state := range watchedContainers(states, names) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if state.Status != "Running" {
+			logger.Debug("Skipping container '%s': it is %s", state.Name, state.Status)
+			continue
+		}
+
+		action, reason := w.diagnose(ctx, state.Name)
+		if action == "" {
+			w.healthy(state.Name)
+			continue
+		}
+		w.restart(ctx, state.Name, action, reason)
+	}
+	return nil
+}
+
+// watchedContainers picks the named containers, or every managed container
+// when no names are given; named containers that do not exist are reported
+func watchedContainers(states []helpers.ContainerState, names []string) []helpers.ContainerState {
+	if len(names) == 0 {
+		return helpers.FilterManagedContainers(states, false)
+	}
+
+	byName := make(map[string]helpers.ContainerState, len(states))
+	for _, state := range states {
+		byName[state.Name] = state
+	}
+	var watched []helpers.ContainerState
+	for _, name := range names {
+		state, ok := byName[name]
+		if !ok {
+			logger.Warn("Container '%s' does not exist", name)
+			continue
+		}
+		watched = append(watched, state)
+	}
+	return watched
+}
+
+// diagnose returns the restart a container needs and why, or "" if it is healthy
+func (w *watchdog) diagnose(ctx context.Context, name string) (string, string) {
+	checkCtx, cancel := context.WithTimeout(ctx, w.checkTimeout)
+	err := w.manager.CheckDockerReady(checkCtx, name)
+	cancel()
+	if err != nil {
+		return watchActionRestartContainer, "docker is not responding"
+	}
+
+	checkCtx, cancel = context.WithTimeout(ctx, w.checkTimeout)
+	services, err := w.manager.ListComposeServices(checkCtx, name, w.projectDir)
+	cancel()
+	if err != nil {
+		logger.Debug("Could not check compose services in '%s': %v", name, err)
+		return "", ""
+	}
+	var failing []string
+	for _, service := range services {
+		if !service.IsHealthy() {
+			state := service.State
+			if service.Health == "unhealthy" {
+				state = service.Health
+			} else if service.State == "exited" {
+				state = fmt.Sprintf("exited (%d)", service.ExitCode)
+			}
+			failing = append(failing, fmt.Sprintf("%s is %s", service.Service, state))
+		}
+	}
+	if len(failing) > 0 {
+		return watchActionRestartCompose, "compose service " + strings.Join(failing, ", ")
+	}
+	return "", ""
+}
+
+// loadRecords replaces the restart records with the saved ones, leaving out
+// containers that no longer exist; unreadable state keeps the records in memory
+func (w *watchdog) loadRecords(states []helpers.ContainerState) {
+	saved, err := w.manager.LoadWatchRecords()
+	if err != nil {
+		logger.Warn("Could not read the watch state: %v", err)
+		return
+	}
+	exists := make(map[string]bool, len(states))
+	for _, state := range states {
+		exists[state.Name] = true
+	}
+	w.records = make(map[string]*helpers.WatchRecord, len(saved))
+	w.changed = false
+	for name, record := range saved {
+		if !exists[name] {
+			w.changed = true
+			continue
+		}
+		w.records[name] = &record
+	}
+}
+
+// saveRecords writes the restart records if they changed during a check
+func (w *watchdog) saveRecords() {
+	if !w.changed {
+		return
+	}
+	records := make(map[string]helpers.WatchRecord, len(w.records))
+	for name, record := range w.records {
+		records[name] = *record
+	}
+	if err := w.manager.SaveWatchRecords(records); err != nil {
+		logger.Warn("Could not save the watch state: %v", err)
+		return
+	}
+	w.changed = false
+}
+
+// healthy resets the restart budget of a container that passed its checks
+func (w *watchdog) healthy(name string) {
+	record, ok := w.records[name]
+	if !ok {
+		return
+	}
+	delete(w.records, name)
+	w.changed = true
+	logger.Info("Container '%s' is healthy again after %d restart(s)", name, record.Restarts)
+	w.audit(helpers.AuditEvent{Action: watchActionRecovered, Container: name})
+}
+
+// restart restarts a container or its compose project unless it is backing
+// off or has used up its restart budget
+func (w *watchdog) restart(ctx context.Context, name, action, reason string) {
+	record := w.records[name]
+	if record == nil {
+		record = &helpers.WatchRecord{}
+		w.records[name] = record
+	}
+
+	if record.Restarts >= w.maxRestarts {
+		if !record.GaveUp {
+			record.GaveUp = true
+			w.changed = true
+			logger.Error("Giving up on container '%s' after %d restart(s): %s", name, record.Restarts, reason)
+			w.audit(helpers.AuditEvent{Action: watchActionGiveUp, Container: name, Reason: reason})
+			w.manager.Notify(ctx, helpers.NotificationEvent{
+				Event:     helpers.EventWatchGiveUp,
+				Container: name,
+				Message:   fmt.Sprintf("Watchdog gave up on container '%s' after %d restart(s)", name, record.Restarts),
+				Error:     reason,
+			})
+		}
+		return
+	}
+	if now := w.now(); now.Before(record.NextRestart) {
+		logger.Debug("Container '%s' is unhealthy (%s); next restart in %s", name, reason, record.NextRestart.Sub(now).Round(time.Second))
+		return
+	}
+
+	record.Restarts++
+	record.NextRestart = w.now().Add(watchBackoffDelay(w.backoff, record.Restarts))
+	w.changed = true
+
+	var err error
+	if action == watchActionRestartCompose {
+		logger.Warn("Restarting compose project in container '%s': %s", name, reason)
+		err = w.manager.RestartCompose(ctx, name, w.projectDir)
+	} else {
+		logger.Warn("Restarting container '%s': %s", name, reason)
+		err = w.manager.RestartContainer(ctx, name)
+	}
+
+	event := helpers.AuditEvent{Action: action, Container: name, Reason: reason}
+	notification := helpers.NotificationEvent{
+		Event:     helpers.EventWatchRestart,
+		Container: name,
+		Message:   fmt.Sprintf("Watchdog restarted %s: %s", watchRestartTarget(action, name), reason),
+	}
+	if err != nil {
+		logger.Error("Failed to restart '%s': %v", name, err)
+		event.Error = err.Error()
+		notification.Message = fmt.Sprintf("Watchdog failed to restart %s: %s", watchRestartTarget(action, name), reason)
+		notification.Error = err.Error()
+	}
+	w.audit(event)
+	w.manager.Notify(ctx, notification)
+}
+
+// watchRestartTarget names what a restart action restarts
+func watchRestartTarget(action, name string) string {
+	if action == watchActionRestartCompose {
+		return fmt.Sprintf("the compose project in container '%s'", name)
+	}
+	return fmt.Sprintf("container '%s'", name)
+}
+
+// audit records an event; a failure to write the audit log must not stop the watchdog
+func (w *watchdog) audit(event helpers.AuditEvent) {
+	if event.Time.IsZero() {
+		event.Time = w.now()
+	}
+	if err := w.manager.RecordAuditEvent(event); err != nil {
+		logger.Warn("Failed to write audit log: %v", err)
+	}
+}
+
+// watchBackoffDelay doubles the base delay for each restart, up to watchMaxBackoff
+func watchBackoffDelay(base time.Duration, restarts int) time.Duration {
+	delay := base
+	for i := 1; i < restarts && delay < watchMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > watchMaxBackoff {
+		delay = watchMaxBackoff
+	}
+	return delay
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 30*time.Second, "Time between health checks")
+	watchCmd.Flags().DurationVar(&watchBackoff, "backoff", time.Minute, "Wait after the first restart of a container; doubles with each restart")
+	watchCmd.Flags().IntVar(&watchMaxRestarts, "max-restarts", 5, "Restarts of a container before giving up until it is healthy again")
+	watchCmd.Flags().StringVar(&watchProjectDir, "project-dir", "/home/app", "Docker Compose project directory inside the containers")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Check once and exit instead of watching")
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/helpers"
+)
+
+// MockWatchManager for testing the watchdog
+type MockWatchManager struct {
+	States           []helpers.ContainerState
+	DockerDown       map[string]bool
+	DockerHangs      map[string]bool
+	Services         map[string][]helpers.ComposeService
+	RestartError     error
+	Restarted        []string
+	ComposeRestarted []string
+	Events           []helpers.AuditEvent
+	Notifications    []helpers.NotificationEvent
+	// Records is the saved watch state
+	Records map[string]helpers.WatchRecord
+}
+
+func (m *MockWatchManager) ListContainers(ctx context.Context) ([]helpers.ContainerState, error) {
+	return m.States, nil
+}
+
+func (m *MockWatchManager) CheckDockerReady(ctx context.Context, name string) error {
+	if m.DockerHangs[name] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if m.DockerDown[name] {
+		return fmt.Errorf("docker is not ready")
+	}
+	return nil
+}
+
+func (m *MockWatchManager) ListComposeServices(ctx context.Context, containerName, projectDir string) ([]helpers.ComposeService, error) {
+	return m.Services[containerName], nil
+}
+
+func (m *MockWatchManager) RestartContainer(ctx context.Context, name string) error {
+	m.Restarted = append(m.Restarted, name)
+	return m.RestartError
+}
+
+func (m *MockWatchManager) RestartCompose(ctx context.Context, containerName, projectDir string) error {
+	m.ComposeRestarted = append(m.ComposeRestarted, containerName)
+	return nil
+}
+
+func (m *MockWatchManager) RecordAuditEvent(event helpers.AuditEvent) error {
+	m.Events = append(m.Events, event)
+	return nil
+}
+
+func (m *MockWatchManager) Notify(ctx context.Context, event helpers.NotificationEvent) {
+	m.Notifications = append(m.Notifications, event)
+}
+
+func (m *MockWatchManager) LoadWatchRecords() (map[string]helpers.WatchRecord, error) {
+	records := make(map[string]helpers.WatchRecord, len(m.Records))
+	for name, record := range m.Records {
+		records[name] = record
+	}
+	return records, nil
+}
+
+func (m *MockWatchManager) SaveWatchRecords(records map[string]helpers.WatchRecord) error {
+	m.Records = records
+	return nil
+}
+
+func watchedState(name, status string) helpers.ContainerState {
+	return helpers.ContainerState{Name: name, Status: status, Config: map[string]string{helpers.ManagedMarkerKey: "true"}}
+}
+
+// newTestWatchdog returns a watchdog with a clock the test moves forward
+func newTestWatchdog(manager *MockWatchManager, maxRestarts int) (*watchdog, *time.Time) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := newWatchdog(manager, "/home/app", maxRestarts, time.Minute)
+	w.now = func() time.Time { return clock }
+	return w, &clock
+}
+
+func TestWatchdogCheckOnce(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockWatchManager{
+		States: []helpers.ContainerState{
+			watchedState("web", "Running"),
+			watchedState("api", "Running"),
+			watchedState("old", "Stopped"),
+			{Name: "other", Status: "Running"},
+		},
+		DockerDown: map[string]bool{"web": true, "old": true, "other": true},
+		Services: map[string][]helpers.ComposeService{
+			"api": {{Service: "app", State: "running"}, {Service: "db", State: "running", Health: "unhealthy"}},
+		},
+	}
+	w, _ := newTestWatchdog(manager, 3)
+
+	if err := w.checkOnce(ctx, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Restarted) != 1 || manager.Restarted[0] != "web" {
+		t.Errorf("expected only the running managed container with docker down to restart, got %v", manager.Restarted)
+	}
+	if len(manager.ComposeRestarted) != 1 || manager.ComposeRestarted[0] != "api" {
+		t.Errorf("expected the compose project to restart, got %v", manager.ComposeRestarted)
+	}
+	if len(manager.Events) != 2 || manager.Events[1].Action != watchActionRestartCompose || manager.Events[1].Reason != "compose service db is unhealthy" {
+		t.Errorf("unexpected audit events %+v", manager.Events)
+	}
+
+	manager.Restarted = nil
+	if err := w.checkOnce(ctx, []string{"other", "missing"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manager.Restarted) != 1 || manager.Restarted[0] != "other" {
+		t.Errorf("expected named containers to be watched even if unmanaged, got %v", manager.Restarted)
+	}
+}
+
+func TestWatchdogCheckTimeout(t *testing.T) {
+	manager := &MockWatchManager{
+		States:      []helpers.ContainerState{watchedState("web", "Running"), watchedState("api", "Running")},
+		DockerHangs: map[string]bool{"web": true},
+		Services: map[string][]helpers.ComposeService{
+			"api": {{Service: "app", State: "running"}, {Service: "migrate", State: "exited", ExitCode: 0}},
+		},
+	}
+	w, _ := newTestWatchdog(manager, 3)
+	w.checkTimeout = 10 * time.Millisecond
+
+	if err := w.checkOnce(context.Background(), nil); err != nil {
+		t.Fatalf("expected the hung check not to stop the watchdog, got %v", err)
+	}
+	if len(manager.Restarted) != 1 || manager.Restarted[0] != "web" {
+		t.Errorf("expected the container with a hung docker to restart, got %v", manager.Restarted)
+	}
+	if len(manager.ComposeRestarted) != 0 {
+		t.Errorf("expected a one-shot service that exited successfully to be left alone, got %v", manager.ComposeRestarted)
+	}
+}
+
+func TestWatchdogBackoffAndBudget(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockWatchManager{
+		States:     []helpers.ContainerState{watchedState("web", "Running")},
+		DockerDown: map[string]bool{"web": true},
+	}
+	w, clock := newTestWatchdog(manager, 2)
+
+	w.checkOnce(ctx, nil)
+	w.checkOnce(ctx, nil)
+	if len(manager.Restarted) != 1 {
+		t.Fatalf("expected the second check to back off, got %d restarts", len(manager.Restarted))
+	}
+
+	*clock = clock.Add(time.Minute)
+	w.checkOnce(ctx, nil)
+	*clock = clock.Add(time.Minute)
+	w.checkOnce(ctx, nil)
+	if len(manager.Restarted) != 2 {
+		t.Fatalf("expected a restart once the backoff passed, got %d restarts", len(manager.Restarted))
+	}
+
+	*clock = clock.Add(time.Hour)
+	w.checkOnce(ctx, nil)
+	w.checkOnce(ctx, nil)
+	if len(manager.Restarted) != 2 {
+		t.Errorf("expected no restarts past the budget, got %d", len(manager.Restarted))
+	}
+	var gaveUp int
+	for _, event := range manager.Events {
+		if event.Action == watchActionGiveUp {
+			gaveUp++
+		}
+	}
+	if gaveUp != 1 {
+		t.Errorf("expected one give-up event, got %+v", manager.Events)
+	}
+	if last := manager.Notifications[len(manager.Notifications)-1]; last.Event != helpers.EventWatchGiveUp {
+		t.Errorf("expected a give-up notification, got %+v", last)
+	}
+
+	manager.DockerDown = nil
+	w.checkOnce(ctx, nil)
+	if last := manager.Events[len(manager.Events)-1]; last.Action != watchActionRecovered {
+		t.Errorf("expected a recovery event, got %+v", last)
+	}
+	manager.DockerDown = map[string]bool{"web": true}
+	w.checkOnce(ctx, nil)
+	if len(manager.Restarted) != 3 {
+		t.Errorf("expected the budget to reset after recovering, got %d restarts", len(manager.Restarted))
+	}
+}
+
+func TestWatchdogKeepsBudgetBetweenRuns(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockWatchManager{
+		States:     []helpers.ContainerState{watchedState("web", "Running")},
+		DockerDown: map[string]bool{"web": true},
+		Records:    map[string]helpers.WatchRecord{"gone": {Restarts: 1}},
+	}
+
+	// Each cron run of 'watch --once' is a new watchdog
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	runOnce := func() {
+		w := newWatchdog(manager, "/home/app", 2, time.Minute)
+		w.now = func() time.Time { return clock }
+		w.checkOnce(ctx, nil)
+	}
+
+	runOnce()
+	if _, ok := manager.Records["gone"]; ok {
+		t.Error("expected the record of a deleted container to be dropped")
+	}
+	runOnce()
+	if len(manager.Restarted) != 1 {
+		t.Fatalf("expected the second run to back off, got %d restarts", len(manager.Restarted))
+	}
+	clock = clock.Add(time.Minute)
+	runOnce()
+	clock = clock.Add(time.Hour)
+	runOnce()
+	runOnce()
+	if len(manager.Restarted) != 2 || !manager.Records["web"].GaveUp {
+		t.Errorf("expected the runs to give up after 2 restarts, got %d restarts and %+v", len(manager.Restarted), manager.Records)
+	}
+
+	manager.DockerDown = nil
+	runOnce()
+	if _, ok := manager.Records["web"]; ok {
+		t.Error("expected the record to be cleared once the container is healthy")
+	}
+}
+
+func TestWatchdogRestartFailure(t *testing.T) {
+	manager := &MockWatchManager{
+		States:       []helpers.ContainerState{watchedState("web", "Running")},
+		DockerDown:   map[string]bool{"web": true},
+		RestartError: fmt.Errorf("lxc restart failed"),
+	}
+	w, _ := newTestWatchdog(manager, 3)
+	w.checkOnce(context.Background(), nil)
+	if len(manager.Events) != 1 || manager.Events[0].Error != "lxc restart failed" {
+		t.Errorf("expected the failure in the audit log, got %+v", manager.Events)
+	}
+	if len(manager.Notifications) != 1 || manager.Notifications[0].Event != helpers.EventWatchRestart || manager.Notifications[0].Error != "lxc restart failed" {
+		t.Errorf("expected a watch-restart notification with the error, got %+v", manager.Notifications)
+	}
+}
+
+func TestWatchBackoffDelay(t *testing.T) {
+	tests := []struct {
+		restarts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{10, watchMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := watchBackoffDelay(time.Minute, tt.restarts); got != tt.want {
+			t.Errorf("restart %d: expected %s, got %s", tt.restarts, tt.want, got)
+		}
+	}
+}
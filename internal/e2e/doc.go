@@ -0,0 +1,16 @@
+// Package e2e runs the lxc-go-cli binary against a real, disposable LXD.
+//
+// The harness and tests are behind the integration build tag and skip unless
+// E2E_LXD selects where LXD runs:
+//
+//	E2E_LXD=host    use the LXD on this machine, e.g. a throwaway CI VM
+//	E2E_LXD=nested  launch a nesting container, install LXD from snap in it
+//	                and run the binary there (lxd-in-lxd)
+//
+// Run them with:
+//
+//	E2E_LXD=host go test -tags=integration -v -timeout 60m ./internal/e2e
+//
+// Every command's duration is logged; set E2E_TIMINGS to a file path to also
+// append them as JSON lines so slowdowns can be tracked between runs.
+package e2e
@@ -0,0 +1,113 @@
+//go:build integration
+
+package e2e
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// storagePoolArgs picks the pool create uses. A fresh 'lxd init --auto' only
+// has a dir pool named default, so the nested host uses that unless
+// E2E_STORAGE_POOL says otherwise.
+func storagePoolArgs(h *Harness) []string {
+	if pool := os.Getenv("E2E_STORAGE_POOL"); pool != "" {
+		return []string{"--storage-pool", pool}
+	}
+	if h.mode == ModeNested {
+		return []string{"--storage-pool", "default"}
+	}
+	return nil
+}
+
+func TestContainerLifecycle(t *testing.T) {
+	h := New(t)
+	name := h.Name("life")
+
+	// create
+	args := append([]string{"create", "--name", name, "--output", "json"}, storagePoolArgs(h)...)
+	result := h.MustRun("create", args...)
+
+	var summary struct {
+		Name        string `json:"name"`
+		IPv4        string `json:"ipv4"`
+		Provisioned bool   `json:"provisioned"`
+		User        string `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), &summary); err != nil {
+		t.Fatalf("create --output json printed invalid JSON: %v\n%s", err, result.Stdout)
+	}
+	if summary.Name != name || !summary.Provisioned || summary.User != "app" {
+		t.Errorf("unexpected create summary: %+v", summary)
+	}
+	if summary.IPv4 == "" {
+		t.Error("expected the created container to have an IPv4 address")
+	}
+
+	// Docker really is installed and usable by the app user
+	if result := h.Lxc("docker-check", "exec", name, "--", "su", "-", "app", "-c", "docker info --format '{{.ServerVersion}}'"); result.ExitCode != 0 {
+		t.Errorf("docker is not usable by app in the new container: %s", result.Stderr)
+	}
+
+	// list
+	result = h.MustRun("list", "list")
+	if !strings.Contains(result.Stdout, name) {
+		t.Errorf("expected list to show %s, got:\n%s", name, result.Stdout)
+	}
+
+	// port add, list and check
+	h.MustRun("port-add", "port", "add", name, "18080", "80")
+	result = h.MustRun("port-list", "port", "list", name)
+	if !strings.Contains(result.Stdout, "18080") {
+		t.Errorf("expected port list to show 18080, got:\n%s", result.Stdout)
+	}
+	result = h.Run("port-check", "port", "check", "18080")
+	if result.ExitCode != 1 {
+		t.Errorf("expected port check to exit 1 for a forwarded port, got %d", result.ExitCode)
+	}
+	if output := result.Stdout + result.Stderr; !strings.Contains(output, name) {
+		t.Errorf("expected port check to name %s, got:\n%s", name, output)
+	}
+	if result := h.Lxc("port-device", "config", "device", "show", name); !strings.Contains(result.Stdout, "listen: tcp:0.0.0.0:18080") {
+		t.Errorf("expected a proxy device listening on 18080, got:\n%s", result.Stdout)
+	}
+
+	// gpu
+	result = h.MustRun("gpu-status", "gpu", name, "status")
+	if !strings.Contains(result.Stdout, "Host GPUs:") {
+		t.Errorf("expected gpu status to list host GPUs, got:\n%s", result.Stdout)
+	}
+	if strings.Contains(result.Stdout, "none found") {
+		result = h.Run("gpu-enable", "gpu", name, "enable", "--no-snapshot")
+		if result.ExitCode == 0 || !strings.Contains(result.Stderr, "no GPU found") {
+			t.Errorf("expected gpu enable to refuse on a host without a GPU, got exit %d:\n%s", result.ExitCode, result.Stderr)
+		}
+	} else {
+		h.MustRun("gpu-enable", "gpu", name, "enable", "--no-snapshot")
+		h.MustRun("gpu-disable", "gpu", name, "disable")
+	}
+
+	// delete
+	h.MustRun("delete", "delete", name, "--force")
+	if result := h.Lxc("deleted-check", "info", name); result.ExitCode == 0 {
+		t.Errorf("expected %s to be gone after delete", name)
+	}
+}
+
+func TestCreateNoProvision(t *testing.T) {
+	h := New(t)
+	name := h.Name("bare")
+
+	args := append([]string{"create", "--name", name, "--no-provision", "--output", "json"}, storagePoolArgs(h)...)
+	h.MustRun("create-bare", args...)
+
+	// Only launch and security ran, so there is no docker and no app user
+	if result := h.Lxc("no-docker-check", "exec", name, "--", "sh", "-c", "command -v docker"); result.ExitCode == 0 {
+		t.Error("expected docker not to be installed with --no-provision")
+	}
+	if result := h.Lxc("no-user-check", "exec", name, "--", "id", "app"); result.ExitCode == 0 {
+		t.Error("expected no app user with --no-provision")
+	}
+}
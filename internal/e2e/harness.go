@@ -0,0 +1,245 @@
+//go:build integration
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Where the LXD under test runs, selected by E2E_LXD
+const (
+	ModeHost   = "host"
+	ModeNested = "nested"
+)
+
+// nestedImage is the image the lxd-in-lxd host container is launched from
+const nestedImage = "ubuntu:24.04"
+
+// nestedBinary is where the binary is pushed inside the nested host
+const nestedBinary = "/usr/local/bin/lxc-go-cli"
+
+// Timing records how long one command took
+type Timing struct {
+	Test     string   `json:"test"`
+	Name     string   `json:"name"`
+	Args     []string `json:"args"`
+	Seconds  float64  `json:"seconds"`
+	ExitCode int      `json:"exit_code"`
+}
+
+// Result is the outcome of one command
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Harness runs lxc-go-cli and lxc against a disposable LXD
+type Harness struct {
+	t       *testing.T
+	mode    string
+	binary  string
+	host    string
+	prefix  string
+	timings []Timing
+}
+
+// New builds the binary and prepares the LXD selected by E2E_LXD, skipping
+// the test when it is unset. Everything it creates is removed on cleanup.
+func New(t *testing.T) *Harness {
+	t.Helper()
+
+	mode := os.Getenv("E2E_LXD")
+	switch mode {
+	case "":
+		t.Skip("set E2E_LXD=host or E2E_LXD=nested to run end-to-end tests against a real LXD")
+	case ModeHost, ModeNested:
+	default:
+		t.Fatalf("invalid E2E_LXD '%s': must be '%s' or '%s'", mode, ModeHost, ModeNested)
+	}
+	if _, err := exec.LookPath("lxc"); err != nil {
+		t.Fatalf("E2E_LXD=%s needs the lxc client on PATH: %v", mode, err)
+	}
+
+	h := &Harness{t: t, mode: mode, prefix: "e2e-" + randomSuffix(t)}
+	t.Cleanup(h.writeTimings)
+
+	h.binary = buildBinary(t)
+	if mode == ModeNested {
+		h.startNestedHost()
+	}
+	return h
+}
+
+// Name returns a container name unique to this run and deletes the
+// container on cleanup, whether or not the test removed it
+func (h *Harness) Name(suffix string) string {
+	name := h.prefix + "-" + suffix
+	h.t.Cleanup(func() {
+		h.Lxc("cleanup", "delete", "--force", name)
+	})
+	return name
+}
+
+// Run runs lxc-go-cli with args and records its timing under name
+func (h *Harness) Run(name string, args ...string) Result {
+	h.t.Helper()
+	if h.mode == ModeNested {
+		return h.run(name, "lxc", append([]string{"exec", h.host, "--", nestedBinary}, args...)...)
+	}
+	return h.run(name, h.binary, args...)
+}
+
+// MustRun is Run, failing the test unless the command succeeds
+func (h *Harness) MustRun(name string, args ...string) Result {
+	h.t.Helper()
+	result := h.Run(name, args...)
+	if result.ExitCode != 0 {
+		h.t.Fatalf("%s: lxc-go-cli %s exited %d\nstdout:\n%s\nstderr:\n%s",
+			name, strings.Join(args, " "), result.ExitCode, result.Stdout, result.Stderr)
+	}
+	return result
+}
+
+// Lxc runs the lxc client against the LXD under test
+func (h *Harness) Lxc(name string, args ...string) Result {
+	h.t.Helper()
+	if h.mode == ModeNested {
+		return h.run(name, "lxc", append([]string{"exec", h.host, "--", "lxc"}, args...)...)
+	}
+	return h.run(name, "lxc", args...)
+}
+
+// run executes a command on this machine, logs it and records its timing
+func (h *Harness) run(name, command string, args ...string) Result {
+	h.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	result := Result{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		h.t.Fatalf("%s: failed to run %s: %v", name, command, err)
+	}
+
+	h.timings = append(h.timings, Timing{
+		Test:     h.t.Name(),
+		Name:     name,
+		Args:     args,
+		Seconds:  elapsed.Seconds(),
+		ExitCode: result.ExitCode,
+	})
+	h.t.Logf("%-20s %8s  exit %d", name, elapsed.Round(time.Millisecond), result.ExitCode)
+	return result
+}
+
+// startNestedHost launches a nesting container with its own LXD and copies
+// the binary into it
+func (h *Harness) startNestedHost() {
+	h.t.Helper()
+
+	h.host = h.prefix + "-lxd"
+	h.t.Cleanup(func() {
+		h.run("nested-cleanup", "lxc", "delete", "--force", h.host)
+	})
+
+	steps := []struct {
+		name string
+		args []string
+	}{
+		{"nested-launch", []string{"launch", nestedImage, h.host,
+			"-c", "security.nesting=true",
+			"-c", "security.syscalls.intercept.mknod=true",
+			"-c", "security.syscalls.intercept.setxattr=true"}},
+		{"nested-boot", []string{"exec", h.host, "--", "cloud-init", "status", "--wait"}},
+		{"nested-snap", []string{"exec", h.host, "--", "snap", "install", "lxd"}},
+		{"nested-init", []string{"exec", h.host, "--", "lxd", "init", "--auto"}},
+		{"nested-push", []string{"file", "push", "--mode", "0755", h.binary, h.host + nestedBinary}},
+	}
+	for _, step := range steps {
+		// cloud-init exits 2 for recoverable warnings; the host is still usable
+		if result := h.run(step.name, "lxc", step.args...); result.ExitCode != 0 &&
+			!(step.name == "nested-boot" && result.ExitCode == 2) {
+			h.t.Fatalf("%s: lxc %s exited %d: %s", step.name, strings.Join(step.args, " "), result.ExitCode, result.Stderr)
+		}
+	}
+}
+
+// writeTimings appends the recorded timings to E2E_TIMINGS as JSON lines
+func (h *Harness) writeTimings() {
+	path := os.Getenv("E2E_TIMINGS")
+	if path == "" || len(h.timings) == 0 {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		h.t.Errorf("failed to open timings file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, timing := range h.timings {
+		if err := encoder.Encode(timing); err != nil {
+			h.t.Errorf("failed to write timings: %v", err)
+			return
+		}
+	}
+}
+
+// buildBinary compiles lxc-go-cli from the module under test
+func buildBinary(t *testing.T) string {
+	t.Helper()
+
+	gomod, err := exec.Command("go", "env", "GOMOD").Output()
+	if err != nil {
+		t.Fatalf("failed to find the module root: %v", err)
+	}
+	root := filepath.Dir(strings.TrimSpace(string(gomod)))
+
+	binary := filepath.Join(t.TempDir(), "lxc-go-cli")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = root
+	// The nested host may not share this machine's libc
+	build.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build lxc-go-cli: %v\n%s", err, output)
+	}
+	return binary
+}
+
+// randomSuffix returns a short random hex string for unique names
+func randomSuffix(t *testing.T) string {
+	t.Helper()
+
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatalf("failed to generate a name: %v", err)
+	}
+	return hex.EncodeToString(buf)
+}
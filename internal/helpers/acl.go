@@ -0,0 +1,248 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// NetworkACLExtensions are the API extensions needed to attach ACLs to the
+// bridged NICs this tool creates (LXD 4.20 and later)
+var NetworkACLExtensions = []string{"network_acl", "network_bridge_acl"}
+
+// NetworkACLRule is a single ingress or egress rule of an LXD network ACL
+type NetworkACLRule struct {
+	Action          string `json:"action"`
+	Source          string `json:"source,omitempty"`
+	Destination     string `json:"destination,omitempty"`
+	Protocol        string `json:"protocol,omitempty"`
+	SourcePort      string `json:"source_port,omitempty"`
+	DestinationPort string `json:"destination_port,omitempty"`
+	ICMPType        string `json:"icmp_type,omitempty"`
+	ICMPCode        string `json:"icmp_code,omitempty"`
+	Description     string `json:"description,omitempty"`
+	State           string `json:"state,omitempty"`
+}
+
+// NetworkACL is an LXD network ACL
+type NetworkACL struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Ingress     []NetworkACLRule `json:"ingress"`
+	Egress      []NetworkACLRule `json:"egress"`
+	UsedBy      []string         `json:"used_by"`
+}
+
+// ParseNetworkACLRule parses a rule written as space-separated key=value pairs,
+// e.g. "action=allow protocol=tcp destination_port=80"
+func ParseNetworkACLRule(spec string) (NetworkACLRule, error) {
+	var rule NetworkACLRule
+	fields := map[string]*string{
+		"action":           &rule.Action,
+		"source":           &rule.Source,
+		"destination":      &rule.Destination,
+		"protocol":         &rule.Protocol,
+		"source_port":      &rule.SourcePort,
+		"destination_port": &rule.DestinationPort,
+		"icmp_type":        &rule.ICMPType,
+		"icmp_code":        &rule.ICMPCode,
+		"description":      &rule.Description,
+		"state":            &rule.State,
+	}
+
+	for _, pair := range strings.Fields(spec) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || value == "" {
+			return rule, UsageErrorf("invalid rule field '%s': expected key=value", pair)
+		}
+		field, known := fields[key]
+		if !known {
+			return rule, fmt.Errorf("unknown rule field '%s'", key)
+		}
+		*field = value
+	}
+
+	switch rule.Action {
+	case "allow", "allow-stateless", "reject", "drop":
+	case "":
+		return rule, fmt.Errorf("rule '%s' has no action", spec)
+	default:
+		return rule, UsageErrorf("invalid action '%s': must be allow, allow-stateless, reject or drop", rule.Action)
+	}
+	if (rule.SourcePort != "" || rule.DestinationPort != "") && rule.Protocol != "tcp" && rule.Protocol != "udp" {
+		return rule, fmt.Errorf("ports require protocol=tcp or protocol=udp")
+	}
+	return rule, nil
+}
+
+// Args returns the rule as key=value arguments for `lxc network acl rule add`
+func (r NetworkACLRule) Args() []string {
+	pairs := []struct{ key, value string }{
+		{"action", r.Action},
+		{"source", r.Source},
+		{"destination", r.Destination},
+		{"protocol", r.Protocol},
+		{"source_port", r.SourcePort},
+		{"destination_port", r.DestinationPort},
+		{"icmp_type", r.ICMPType},
+		{"icmp_code", r.ICMPCode},
+		{"description", r.Description},
+		{"state", r.State},
+	}
+
+	var args []string
+	for _, pair := range pairs {
+		if pair.value != "" {
+			args = append(args, pair.key+"="+pair.value)
+		}
+	}
+	return args
+}
+
+// CreateNetworkACL creates an ACL with the given rules. A partially created ACL
+// is deleted again if any rule is rejected.
+func CreateNetworkACL(ctx context.Context, acl NetworkACL) error {
+	args := []string{"network", "acl", "create", acl.Name}
+	if acl.Description != "" {
+		args = append(args, "description="+acl.Description)
+	}
+	if err := runLXC(ctx, args...); err != nil {
+		return fmt.Errorf("failed to create ACL '%s': %w", acl.Name, err)
+	}
+
+	add := func(direction string, rules []NetworkACLRule) error {
+		for _, rule := range rules {
+			ruleArgs := append([]string{"network", "acl", "rule", "add", acl.Name, direction}, rule.Args()...)
+			if err := runLXC(ctx, ruleArgs...); err != nil {
+				return fmt.Errorf("failed to add %s rule '%s': %w", direction, strings.Join(rule.Args(), " "), err)
+			}
+		}
+		return nil
+	}
+
+	err := add("ingress", acl.Ingress)
+	if err == nil {
+		err = add("egress", acl.Egress)
+	}
+	if err != nil {
+		if delErr := runLXC(ctx, "network", "acl", "delete", acl.Name); delErr != nil {
+			logger.Warn("Failed to clean up ACL '%s': %v", acl.Name, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListNetworkACLs returns all network ACLs sorted by name
+func ListNetworkACLs(ctx context.Context) ([]NetworkACL, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/network-acls?recursion=1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network ACLs: %w", err)
+	}
+	return parseNetworkACLs(output)
+}
+
+// parseNetworkACLs parses the GET /1.0/network-acls?recursion=1 response
+func parseNetworkACLs(jsonOutput []byte) ([]NetworkACL, error) {
+	var acls []NetworkACL
+	if err := json.Unmarshal(jsonOutput, &acls); err != nil {
+		return nil, fmt.Errorf("failed to parse network ACLs: %w", err)
+	}
+	sort.Slice(acls, func(i, j int) bool { return acls[i].Name < acls[j].Name })
+	return acls, nil
+}
+
+// lxdInstanceDevices mirrors the device sections of GET /1.0/instances/<name>
+type lxdInstanceDevices struct {
+	Devices         map[string]map[string]string `json:"devices"`
+	ExpandedDevices map[string]map[string]string `json:"expanded_devices"`
+}
+
+// AttachNetworkACL adds an ACL to a container NIC, overriding the profile
+// device when the NIC is inherited rather than defined on the container
+func AttachNetworkACL(ctx context.Context, containerName, nic, aclName string) error {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/instances/"+containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get devices of container '%s': %w", containerName, err)
+	}
+
+	verb, acls, err := planACLAttach(output, nic, aclName)
+	if err != nil {
+		return err
+	}
+	if verb == "" {
+		logger.Info("ACL '%s' is already attached to %s of container '%s'", aclName, nic, containerName)
+		return nil
+	}
+
+	return runLXC(ctx, "config", "device", verb, containerName, nic, "security.acls="+acls)
+}
+
+// planACLAttach decides how to attach an ACL to a NIC. It returns the
+// `lxc config device` verb (empty when already attached) and the new ACL list.
+func planACLAttach(instanceJSON []byte, nic, aclName string) (verb, acls string, err error) {
+	var instance lxdInstanceDevices
+	if err := json.Unmarshal(instanceJSON, &instance); err != nil {
+		return "", "", fmt.Errorf("failed to parse instance devices: %w", err)
+	}
+
+	device, ok := instance.ExpandedDevices[nic]
+	if !ok || device["type"] != "nic" {
+		return "", "", fmt.Errorf("device '%s' is not a NIC", nic)
+	}
+	if device["network"] == "" {
+		return "", "", fmt.Errorf("NIC '%s' is not connected to a managed network; ACLs require a managed bridge or OVN network", nic)
+	}
+
+	var names []string
+	for _, name := range strings.Split(device["security.acls"], ",") {
+		name = strings.TrimSpace(name)
+		if name == aclName {
+			return "", device["security.acls"], nil
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	names = append(names, aclName)
+
+	verb = "set"
+	if _, local := instance.Devices[nic]; !local {
+		verb = "override"
+	}
+	return verb, strings.Join(names, ","), nil
+}
+
+// FormatNetworkACLs renders ACLs as a table with rule counts and users
+func FormatNetworkACLs(acls []NetworkACL) string {
+	if len(acls) == 0 {
+		return "No network ACLs found\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-20s %-8s %-8s %s\n", "NAME", "INGRESS", "EGRESS", "USED BY"))
+	for _, acl := range acls {
+		usedBy := make([]string, 0, len(acl.UsedBy))
+		for _, url := range acl.UsedBy {
+			usedBy = append(usedBy, path.Base(strings.SplitN(url, "?", 2)[0]))
+		}
+		sb.WriteString(fmt.Sprintf("%-20s %-8d %-8d %s\n", acl.Name, len(acl.Ingress), len(acl.Egress), strings.Join(usedBy, ",")))
+	}
+	return sb.String()
+}
+
+func runLXC(ctx context.Context, args ...string) error {
+	logger.Debug("Running: lxc %s", strings.Join(args, " "))
+
+	output, err := Runner().RunWithOutput(ctx, "lxc", args...)
+	if err != nil {
+		logger.Debug("lxc failed with output: %s", string(output))
+		return fmt.Errorf("%w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNetworkACLRule(t *testing.T) {
+	rule, err := ParseNetworkACLRule("action=allow protocol=tcp destination_port=80,443 source=10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rule.Action != "allow" || rule.Protocol != "tcp" || rule.DestinationPort != "80,443" || rule.Source != "10.0.0.0/8" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	expected := "action=allow source=10.0.0.0/8 protocol=tcp destination_port=80,443"
+	if args := strings.Join(rule.Args(), " "); args != expected {
+		t.Errorf("expected args '%s', got '%s'", expected, args)
+	}
+
+	for _, spec := range []string{
+		"",
+		"protocol=tcp",
+		"action=accept",
+		"action=allow colour=red",
+		"action=allow destination_port",
+		"action=allow destination_port=80",
+	} {
+		if _, err := ParseNetworkACLRule(spec); err == nil {
+			t.Errorf("expected rule '%s' to be invalid", spec)
+		}
+	}
+}
+
+func TestMissingExtensions(t *testing.T) {
+	output := []byte(`{"api_version":"1.0","api_extensions":["network_acl","storage_api"]}`)
+	missing, err := missingExtensions(output, NetworkACLExtensions)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "network_bridge_acl" {
+		t.Errorf("expected network_bridge_acl to be missing, got %v", missing)
+	}
+
+	if _, err := missingExtensions([]byte("not json"), NetworkACLExtensions); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestPlanACLAttach(t *testing.T) {
+	profileNIC := `{"devices":{},"expanded_devices":{"eth0":{"type":"nic","network":"lxdbr0","name":"eth0"}}}`
+	localNIC := `{"devices":{"eth0":{"type":"nic","network":"lxdbr0","security.acls":"base"}},` +
+		`"expanded_devices":{"eth0":{"type":"nic","network":"lxdbr0","security.acls":"base"}}}`
+
+	tests := []struct {
+		name         string
+		instance     string
+		nic          string
+		acl          string
+		expectVerb   string
+		expectACLs   string
+		expectError  bool
+		errorContain string
+	}{
+		{name: "profile NIC is overridden", instance: profileNIC, nic: "eth0", acl: "web", expectVerb: "override", expectACLs: "web"},
+		{name: "local NIC keeps existing ACLs", instance: localNIC, nic: "eth0", acl: "web", expectVerb: "set", expectACLs: "base,web"},
+		{name: "already attached", instance: localNIC, nic: "eth0", acl: "base", expectVerb: "", expectACLs: "base"},
+		{name: "missing NIC", instance: profileNIC, nic: "eth1", acl: "web", expectError: true, errorContain: "not a NIC"},
+		{
+			name:         "unmanaged network",
+			instance:     `{"expanded_devices":{"eth0":{"type":"nic","nictype":"bridged","parent":"br0"}}}`,
+			nic:          "eth0",
+			acl:          "web",
+			expectError:  true,
+			errorContain: "not connected to a managed network",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verb, acls, err := planACLAttach([]byte(tt.instance), tt.nic, tt.acl)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContain) {
+					t.Fatalf("expected error containing '%s', got %v", tt.errorContain, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if verb != tt.expectVerb || acls != tt.expectACLs {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.expectVerb, tt.expectACLs, verb, acls)
+			}
+		})
+	}
+}
+
+func TestParseAndFormatNetworkACLs(t *testing.T) {
+	output := []byte(`[
+		{"name":"web","ingress":[{"action":"allow","protocol":"tcp","destination_port":"80"}],"egress":[],"used_by":["/1.0/instances/app"]},
+		{"name":"db","ingress":[],"egress":[{"action":"allow"}],"used_by":["/1.0/instances/pg?project=prod"]}
+	]`)
+	acls, err := parseNetworkACLs(output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(acls) != 2 || acls[0].Name != "db" {
+		t.Fatalf("expected ACLs sorted by name, got %+v", acls)
+	}
+
+	formatted := FormatNetworkACLs(acls)
+	for _, expected := range []string{"NAME", "web", "app", "pg"} {
+		if !strings.Contains(formatted, expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, formatted)
+		}
+	}
+	if !strings.Contains(FormatNetworkACLs(nil), "No network ACLs found") {
+		t.Error("expected empty message for no ACLs")
+	}
+}
@@ -0,0 +1,393 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// Where the ACME client runs for a certificate
+const (
+	// ACMEModeHost runs lego on the host and pushes the certificate into the container
+	ACMEModeHost = "host"
+	// ACMEModeContainer installs and runs lego inside the container
+	ACMEModeContainer = "container"
+)
+
+const (
+	// ACMEStagingServer issues untrusted certificates without the production rate limits
+	ACMEStagingServer = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	// DefaultCertInstallDir is where certificates are installed in the container
+	DefaultCertInstallDir = "/etc/ssl/lxc-go-cli"
+	// ChallengeDeviceName is the disk device that serves host-mode HTTP challenges
+	ChallengeDeviceName = "acme-challenge"
+	// containerACMEDir holds lego's account and certificates in container mode
+	containerACMEDir = "/etc/lxc-go-cli/acme"
+	// acmeRenewDays renews certificates that expire within this many days
+	acmeRenewDays = "30"
+)
+
+// CertRecord is the state of a certificate issued with 'cert issue', kept
+// under the config dir so it can be renewed and listed
+type CertRecord struct {
+	Container  string    `yaml:"container"`
+	Domain     string    `yaml:"domain"`
+	Email      string    `yaml:"email"`
+	Webroot    string    `yaml:"webroot"`
+	Mode       string    `yaml:"mode"`
+	Staging    bool      `yaml:"staging,omitempty"`
+	InstallDir string    `yaml:"install_dir"`
+	Reload     string    `yaml:"reload,omitempty"`
+	Expires    time.Time `yaml:"expires,omitempty"`
+}
+
+// CertFile returns the installed certificate path in the container
+func (r *CertRecord) CertFile() string {
+	return path.Join(r.InstallDir, r.Domain+".crt")
+}
+
+// KeyFile returns the installed key path in the container
+func (r *CertRecord) KeyFile() string {
+	return path.Join(r.InstallDir, r.Domain+".key")
+}
+
+// ACMEDir returns the directory holding the state of a domain's certificate
+func ACMEDir(domain string) string {
+	return filepath.Join(SettingsDir, "acme", domain)
+}
+
+// challengeWebroot is the host directory lego writes a container's HTTP
+// challenges to; it is shared with every domain of the container
+func challengeWebroot(containerName string) string {
+	return filepath.Join(SettingsDir, "acme", "webroot", containerName)
+}
+
+// LoadCertRecord reads the state of a domain's certificate
+func LoadCertRecord(domain string) (*CertRecord, error) {
+	data, err := os.ReadFile(filepath.Join(ACMEDir(domain), "cert.yml"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no certificate was issued for '%s' (see 'lxc-go-cli cert list')", domain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate state: %w", err)
+	}
+	record := &CertRecord{}
+	if err := yaml.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate state of '%s': %w", domain, err)
+	}
+	return record, nil
+}
+
+// SaveCertRecord writes the state of a certificate
+func SaveCertRecord(record *CertRecord) error {
+	if SettingsDir == "" {
+		return fmt.Errorf("no user config directory available")
+	}
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode certificate state: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(ACMEDir(record.Domain), "cert.yml"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write certificate state: %w", err)
+	}
+	return nil
+}
+
+// ListCertRecords returns the issued certificates, sorted by domain
+func ListCertRecords() ([]CertRecord, error) {
+	if SettingsDir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(SettingsDir, "acme", "*", "cert.yml"))
+	if err != nil {
+		return nil, err
+	}
+	var records []CertRecord
+	for _, p := range paths {
+		record, err := LoadCertRecord(filepath.Base(filepath.Dir(p)))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Domain < records[j].Domain })
+	return records, nil
+}
+
+// LegoCommand returns the lego invocation for a certificate; action is "run"
+// to issue it or "renew". Extra arguments follow the action.
+func LegoCommand(record *CertRecord, action string, extra ...string) []string {
+	statePath, webroot := ACMEDir(record.Domain), challengeWebroot(record.Container)
+	if record.Mode == ACMEModeContainer {
+		statePath, webroot = containerACMEDir, record.Webroot
+	}
+	argv := []string{"lego", "--accept-tos", "--email", record.Email, "--domains", record.Domain,
+		"--path", statePath, "--http", "--http.webroot", webroot}
+	if record.Staging {
+		argv = append(argv, "--server", ACMEStagingServer)
+	}
+	argv = append(argv, action)
+	if action == "renew" {
+		argv = append(argv, "--days", acmeRenewDays)
+	}
+	return append(argv, extra...)
+}
+
+// certHookFile is the script in the container that installs a renewed
+// certificate. lego splits --renew-hook on whitespace and runs it without a
+// shell, so the hook has to be a single executable path.
+func certHookFile(record *CertRecord) string {
+	return path.Join(containerACMEDir, "hooks", record.Domain+".sh")
+}
+
+// certHookScript copies the certificate and key lego stored under legoPath
+// to the install dir, then reloads the web server
+func certHookScript(record *CertRecord, legoPath string) string {
+	from := path.Join(legoPath, "certificates", record.Domain)
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n# Managed by lxc-go-cli: installs the certificate for " + record.Domain + "\nset -e\n")
+	fmt.Fprintf(&sb, "install -D -m 0644 %s %s\n", ShellQuote(from+".crt"), ShellQuote(record.CertFile()))
+	fmt.Fprintf(&sb, "install -D -m 0600 %s %s\n", ShellQuote(from+".key"), ShellQuote(record.KeyFile()))
+	if record.Reload != "" {
+		sb.WriteString(record.Reload + "\n")
+	}
+	return sb.String()
+}
+
+// writeCertHook writes the install hook of a container-mode certificate
+func writeCertHook(ctx context.Context, record *CertRecord) error {
+	return WriteContainerFile(ctx, record.Container, certHookFile(record), "0755", []byte(certHookScript(record, containerACMEDir)))
+}
+
+// IssueCertificate obtains a certificate with lego and installs it in the
+// container, on the host or inside the container depending on the mode
+func IssueCertificate(ctx context.Context, record *CertRecord) error {
+	if record.Mode == ACMEModeContainer {
+		return issueInContainer(ctx, record)
+	}
+
+	if err := RequireLocalServer(ctx, "Issuing certificates on the host"); err != nil {
+		return fmt.Errorf("%w; pass --in-container to run the ACME client in the container", err)
+	}
+	if _, err := lookPath("lego"); err != nil {
+		return WithKind(fmt.Errorf("lego is not installed on the host; install it (e.g. 'snap install lego' or 'apt install lego') or pass --in-container"), ErrEnvironment)
+	}
+	if err := ensureChallengeDevice(ctx, record); err != nil {
+		return err
+	}
+
+	logger.Info("Requesting a certificate for '%s' from %s", record.Domain, acmeServerName(record))
+	if err := runACMEClient(ctx, LegoCommand(record, "run")); err != nil {
+		return err
+	}
+	return InstallHostCertificate(ctx, record)
+}
+
+// RenewCertificate renews a certificate that is close to expiry and
+// installs it again. Container-mode ones are renewed where lego runs.
+func RenewCertificate(ctx context.Context, record *CertRecord) error {
+	if record.Mode == ACMEModeContainer {
+		// Rewritten so a changed install dir or reload command takes effect
+		if err := writeCertHook(ctx, record); err != nil {
+			return err
+		}
+		return runLXC(ctx, append([]string{"exec", record.Container, "--"}, LegoCommand(record, "renew", "--renew-hook", certHookFile(record))...)...)
+	}
+	if err := runACMEClient(ctx, LegoCommand(record, "renew")); err != nil {
+		return err
+	}
+	return InstallHostCertificate(ctx, record)
+}
+
+// InstallHostCertificate pushes the certificate lego stored on the host into
+// the container and reloads its web server
+func InstallHostCertificate(ctx context.Context, record *CertRecord) error {
+	certificates := filepath.Join(ACMEDir(record.Domain), "certificates")
+	certPEM, err := os.ReadFile(filepath.Join(certificates, record.Domain+".crt"))
+	if err != nil {
+		return fmt.Errorf("failed to read the certificate of '%s': %w", record.Domain, err)
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(certificates, record.Domain+".key"))
+	if err != nil {
+		return fmt.Errorf("failed to read the key of '%s': %w", record.Domain, err)
+	}
+	if expires, err := CertExpiry(certPEM); err == nil {
+		record.Expires = expires
+	}
+
+	if err := WriteContainerFile(ctx, record.Container, record.CertFile(), "0644", certPEM); err != nil {
+		return err
+	}
+	if err := WriteContainerFile(ctx, record.Container, record.KeyFile(), "0600", keyPEM); err != nil {
+		return err
+	}
+	logger.Info("Installed the certificate for '%s' in %s", record.Domain, record.InstallDir)
+
+	if record.Reload != "" {
+		if err := runLXC(ctx, "exec", record.Container, "--", "sh", "-c", record.Reload); err != nil {
+			return fmt.Errorf("certificate installed, but reloading the web server failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// issueInContainer installs lego in the container if needed, issues the
+// certificate there and installs it
+func issueInContainer(ctx context.Context, record *CertRecord) error {
+	if err := runLXC(ctx, "exec", record.Container, "--", "lego", "--version"); err != nil {
+		logger.Info("Installing lego in container '%s'", record.Container)
+		if err := runLXC(ctx, append([]string{"exec", record.Container, "--"}, PackageInstallCommand([]string{"lego"})...)...); err != nil {
+			return fmt.Errorf("failed to install lego in container '%s': %w", record.Container, err)
+		}
+	}
+
+	logger.Info("Requesting a certificate for '%s' from %s inside '%s'", record.Domain, acmeServerName(record), record.Container)
+	if err := runLXC(ctx, append([]string{"exec", record.Container, "--"}, LegoCommand(record, "run")...)...); err != nil {
+		return fmt.Errorf("lego failed in container '%s': %w", record.Container, err)
+	}
+	if err := writeCertHook(ctx, record); err != nil {
+		return err
+	}
+	if err := runLXC(ctx, "exec", record.Container, "--", certHookFile(record)); err != nil {
+		return fmt.Errorf("failed to install the certificate of '%s': %w", record.Domain, err)
+	}
+
+	output, err := runOutput(ctx, "lxc", "exec", record.Container, "--", "cat", record.CertFile())
+	if err == nil {
+		if expires, err := CertExpiry(output); err == nil {
+			record.Expires = expires
+		}
+	}
+	logger.Info("Installed the certificate for '%s' in %s", record.Domain, record.InstallDir)
+	return nil
+}
+
+// ensureChallengeDevice shares the host challenge directory read-only into
+// the container's webroot, so the web server answers lego's HTTP challenges
+func ensureChallengeDevice(ctx context.Context, record *CertRecord) error {
+	source := filepath.Join(challengeWebroot(record.Container), ".well-known", "acme-challenge")
+	target := path.Join(record.Webroot, ".well-known", "acme-challenge")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		return fmt.Errorf("failed to create challenge directory: %w", err)
+	}
+
+	current, err := runOutput(ctx, "lxc", "config", "device", "get", record.Container, ChallengeDeviceName, "path")
+	if err == nil {
+		if existing := strings.TrimSpace(string(current)); existing != target {
+			return fmt.Errorf("container '%s' already serves ACME challenges from %s; all its certificates must use --webroot %s",
+				record.Container, existing, path.Dir(path.Dir(existing)))
+		}
+		return nil
+	}
+	return runLXC(ctx, "config", "device", "add", record.Container, ChallengeDeviceName, "disk",
+		"source="+source, "path="+target, "readonly=true")
+}
+
+// WriteContainerFile writes data to a file in a container with the given
+// mode, creating its directory. The data travels on stdin.
+func WriteContainerFile(ctx context.Context, containerName, file, mode string, data []byte) error {
+	script := fmt.Sprintf("umask 077; mkdir -p %s && cat > %s && chmod %s %s",
+		ShellQuote(path.Dir(file)), ShellQuote(file), mode, ShellQuote(file))
+	var stderr bytes.Buffer
+	streams := Streams{Stdin: bytes.NewReader(data), Stderr: &stderr}
+	if err := Runner().RunStreaming(ctx, streams, "lxc", "exec", containerName, "-T", "--", "sh", "-c", script); err != nil {
+		return fmt.Errorf("failed to write %s in container '%s': %w (output: %s)", file, containerName, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// CertExpiry returns when a PEM certificate expires
+func CertExpiry(data []byte) (time.Time, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, fmt.Errorf("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert.NotAfter, nil
+}
+
+// renewalSchedule spreads renewals of different domains over the night, as
+// Let's Encrypt asks clients not to renew on the hour
+func renewalSchedule(domain string) string {
+	h := fnv.New32a()
+	h.Write([]byte(domain))
+	sum := h.Sum32()
+	return fmt.Sprintf("%d %d * * *", sum%60, 1+(sum/60)%5)
+}
+
+// certCronFile names the renewal cron entry of a domain
+func certCronFile(domain string) string {
+	return "lxc-go-cli-cert-" + strings.ReplaceAll(domain, ".", "-")
+}
+
+// HostRenewalCronEntry builds the host cron.d entry that renews a host-mode
+// certificate daily as user
+func HostRenewalCronEntry(record *CertRecord, user, executable string) string {
+	return fmt.Sprintf("# Managed by lxc-go-cli: renewal of the certificate for %s\n%s %s %s cert renew %s\n",
+		record.Domain, renewalSchedule(record.Domain), user, executable, record.Domain)
+}
+
+// ContainerRenewalCronEntry builds the cron.d entry that renews a
+// container-mode certificate inside the container
+func ContainerRenewalCronEntry(record *CertRecord) string {
+	argv := LegoCommand(record, "renew", "--renew-hook", certHookFile(record))
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = arg
+		if strings.ContainsAny(arg, " '\"&;|$*") {
+			quoted[i] = ShellQuote(arg)
+		}
+	}
+	// % is special in crontab lines and must be escaped
+	command := strings.ReplaceAll(strings.Join(quoted, " "), "%", `\%`)
+	return fmt.Sprintf("# Managed by lxc-go-cli: renewal of the certificate for %s\n%s root %s\n",
+		record.Domain, renewalSchedule(record.Domain), command)
+}
+
+// ScheduleRenewal installs the cron entry that renews a certificate: on the
+// host for host-mode certificates, in the container otherwise
+func ScheduleRenewal(ctx context.Context, record *CertRecord, entry string) error {
+	name := certCronFile(record.Domain)
+	if record.Mode == ACMEModeContainer {
+		return WriteContainerFile(ctx, record.Container, path.Join("/etc/cron.d", name), "0644", []byte(entry))
+	}
+	file := filepath.Join(CronDir, name)
+	logger.Debug("Writing cron entry %s", file)
+	if err := os.WriteFile(file, []byte(entry), 0644); err != nil {
+		return fmt.Errorf("failed to write cron entry %s: %w", file, err)
+	}
+	return nil
+}
+
+// runACMEClient runs lego on the host
+func runACMEClient(ctx context.Context, argv []string) error {
+	logger.Debug("Running: %s", strings.Join(argv, " "))
+	output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+	if err != nil {
+		return fmt.Errorf("lego failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// acmeServerName names the ACME server a certificate comes from
+func acmeServerName(record *CertRecord) string {
+	if record.Staging {
+		return "Let's Encrypt staging"
+	}
+	return "Let's Encrypt"
+}
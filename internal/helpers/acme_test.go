@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func useTempSettingsDir(t *testing.T) {
+	t.Helper()
+	previous := SettingsDir
+	t.Cleanup(func() { SettingsDir = previous })
+	SettingsDir = t.TempDir()
+}
+
+func TestLegoCommand(t *testing.T) {
+	useTempSettingsDir(t)
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Email: "ops@example.com", Webroot: "/var/www/html", Mode: ACMEModeHost}
+
+	argv := strings.Join(LegoCommand(record, "run"), " ")
+	expected := "lego --accept-tos --email ops@example.com --domains app.example.com --path " + ACMEDir("app.example.com") +
+		" --http --http.webroot " + filepath.Join(SettingsDir, "acme", "webroot", "web") + " run"
+	if argv != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, argv)
+	}
+
+	record.Mode, record.Staging = ACMEModeContainer, true
+	argv = strings.Join(LegoCommand(record, "renew"), " ")
+	for _, want := range []string{"--path /etc/lxc-go-cli/acme", "--http.webroot /var/www/html", "--server " + ACMEStagingServer, "renew --days 30"} {
+		if !strings.Contains(argv, want) {
+			t.Errorf("expected %q in %s", want, argv)
+		}
+	}
+}
+
+func TestRenewalCronEntries(t *testing.T) {
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Email: "ops@example.com", Webroot: "/var/www/html",
+		Mode: ACMEModeContainer, InstallDir: DefaultCertInstallDir, Reload: "systemctl reload nginx"}
+
+	entry := ContainerRenewalCronEntry(record)
+	if !strings.Contains(entry, " root lego ") || !strings.Contains(entry, "--renew-hook /etc/lxc-go-cli/acme/hooks/app.example.com.sh\n") {
+		t.Errorf("unexpected container cron entry:\n%s", entry)
+	}
+	if schedule := renewalSchedule(record.Domain); schedule != renewalSchedule("app.example.com") || len(strings.Fields(schedule)) != 5 {
+		t.Errorf("expected a stable five field schedule, got %q", schedule)
+	}
+
+	record.Mode = ACMEModeHost
+	entry = HostRenewalCronEntry(record, "deji", "/usr/local/bin/lxc-go-cli")
+	if !strings.HasSuffix(entry, " deji /usr/local/bin/lxc-go-cli cert renew app.example.com\n") {
+		t.Errorf("unexpected host cron entry:\n%s", entry)
+	}
+}
+
+func TestIssueCertificateOnHost(t *testing.T) {
+	useTempSettingsDir(t)
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Email: "ops@example.com", Webroot: "/var/www/html",
+		Mode: ACMEModeHost, InstallDir: DefaultCertInstallDir, Reload: "systemctl reload nginx"}
+
+	// lego is mocked, so put the certificate where it would have stored it
+	certPEM, keyPEM, err := GenerateSelfSignedCert(record.Domain, time.Now(), 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificates := filepath.Join(ACMEDir(record.Domain), "certificates")
+	if err := os.MkdirAll(certificates, 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(certificates, record.Domain+".crt"), certPEM, 0600)
+	os.WriteFile(filepath.Join(certificates, record.Domain+".key"), keyPEM, 0600)
+
+	runner := useMockRunner(t)
+	runner.Respond("", &MockExitError{Code: 1}, "lxc", "config", "device", "get", "web", ChallengeDeviceName)
+	if err := IssueCertificate(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	source := filepath.Join(SettingsDir, "acme", "webroot", "web", ".well-known", "acme-challenge")
+	if !runner.Ran("lxc", "config", "device", "add", "web", ChallengeDeviceName, "disk", "source="+source,
+		"path=/var/www/html/.well-known/acme-challenge", "readonly=true") {
+		t.Errorf("expected the challenge device to be added, ran %v", runner.Commands)
+	}
+	if !runner.Ran(LegoCommand(record, "run")...) {
+		t.Error("expected lego to run on the host")
+	}
+	if len(runner.Stdins) != 2 {
+		t.Errorf("expected the certificate and key to be pushed, got %d pushes", len(runner.Stdins))
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "sh", "-c", "systemctl reload nginx") {
+		t.Error("expected the web server to be reloaded")
+	}
+	if record.Expires.IsZero() {
+		t.Error("expected the expiry to be recorded")
+	}
+
+	// A challenge device for another webroot is not replaced
+	runner.Respond("/srv/www/.well-known/acme-challenge\n", nil, "lxc", "config", "device", "get", "web", ChallengeDeviceName)
+	if err := IssueCertificate(context.Background(), record); err == nil || !strings.Contains(err.Error(), "--webroot /srv/www") {
+		t.Errorf("expected a conflicting webroot to be rejected, got %v", err)
+	}
+}
+
+func TestIssueCertificateInContainer(t *testing.T) {
+	useTempSettingsDir(t)
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Email: "ops@example.com", Webroot: "/var/www/html",
+		Mode: ACMEModeContainer, InstallDir: DefaultCertInstallDir}
+
+	runner := useMockRunner(t)
+	runner.Respond("", &MockExitError{Code: 127}, "lxc", "exec", "web", "--", "lego", "--version")
+	if err := IssueCertificate(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+	if !runner.Ran(append([]string{"lxc", "exec", "web", "--"}, PackageInstallCommand([]string{"lego"})...)...) {
+		t.Error("expected lego to be installed in the container")
+	}
+	if !runner.Ran(append([]string{"lxc", "exec", "web", "--"}, LegoCommand(record, "run")...)...) {
+		t.Error("expected lego to run in the container")
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "/etc/lxc-go-cli/acme/hooks/app.example.com.sh") {
+		t.Error("expected the certificate to be installed by the renewal hook")
+	}
+	for _, command := range runner.Commands {
+		if command[0] == "lego" {
+			t.Errorf("expected nothing to run on the host, ran %v", command)
+		}
+	}
+}
+
+func TestCertRecords(t *testing.T) {
+	useTempSettingsDir(t)
+	if _, err := LoadCertRecord("app.example.com"); err == nil {
+		t.Error("expected an unknown domain to fail")
+	}
+
+	expires := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, domain := range []string{"b.example.com", "a.example.com"} {
+		if err := SaveCertRecord(&CertRecord{Container: "web", Domain: domain, Mode: ACMEModeHost, Expires: expires}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := ListCertRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Domain != "a.example.com" || !records[1].Expires.Equal(expires) {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestIssueCertificateWithoutLego(t *testing.T) {
+	useTempSettingsDir(t)
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	useMockRunner(t)
+
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Mode: ACMEModeHost}
+	if err := IssueCertificate(context.Background(), record); err == nil || !strings.Contains(err.Error(), "--in-container") {
+		t.Errorf("expected a missing lego to suggest --in-container, got %v", err)
+	}
+}
+
+func TestCertHookRunsAsLegoRunsIt(t *testing.T) {
+	dir := t.TempDir()
+	legoPath := filepath.Join(dir, "lego")
+	certificates := filepath.Join(legoPath, "certificates")
+	if err := os.MkdirAll(certificates, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(certificates, "app.example.com.crt"), []byte("cert"), 0600)
+	os.WriteFile(filepath.Join(certificates, "app.example.com.key"), []byte("key"), 0600)
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Mode: ACMEModeContainer,
+		InstallDir: filepath.Join(dir, "ssl dir"), Reload: "touch " + ShellQuote(filepath.Join(dir, "reloaded"))}
+
+	// The cron entry passes the hook path, which lego splits on whitespace
+	// and executes without a shell
+	entry := ContainerRenewalCronEntry(record)
+	hook := entry[strings.Index(entry, "--renew-hook ")+len("--renew-hook ") : len(entry)-1]
+	argv := strings.Fields(hook)
+	if len(argv) != 1 || argv[0] != certHookFile(record) {
+		t.Fatalf("expected the hook to be one path, got %q", argv)
+	}
+
+	hookFile := filepath.Join(dir, "hook.sh")
+	if err := os.WriteFile(hookFile, []byte(certHookScript(record, legoPath)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command(hookFile).CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v (output: %s)", err, output)
+	}
+	if data, err := os.ReadFile(record.KeyFile()); err != nil || string(data) != "key" {
+		t.Errorf("expected the key to be installed, got %q, %v", data, err)
+	}
+	if info, err := os.Stat(record.KeyFile()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the key to be private, got %v", info)
+	}
+	if data, err := os.ReadFile(record.CertFile()); err != nil || string(data) != "cert" {
+		t.Errorf("expected the certificate to be installed, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reloaded")); err != nil {
+		t.Error("expected the web server to be reloaded")
+	}
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"sort"
+)
+
+// AdoptedPortsKey lists proxy devices of adopted containers that port list
+// should report even though they predate this tool's device naming
+const AdoptedPortsKey = "user.lxc-go-cli.ports"
+
+// MissingSecurityConfig returns the Docker security settings a container lacks
+func MissingSecurityConfig(config map[string]string) map[string]string {
+	missing := make(map[string]string)
+	for key, value := range DockerSecurityConfig {
+		if config[key] != value {
+			missing[key] = value
+		}
+	}
+	return missing
+}
+
+// AdoptableProxyDevices returns the names of TCP/UDP proxy devices, sorted,
+// that are not already listed in the adopted ports key
+func AdoptableProxyDevices(config map[string]string, devices map[string]map[string]string) []string {
+	registered := make(map[string]bool)
+	for _, name := range SplitConfigList(config[AdoptedPortsKey]) {
+		registered[name] = true
+	}
+
+	var names []string
+	for name, device := range devices {
+		if device["type"] != "proxy" || registered[name] {
+			continue
+		}
+		protocol, _, ok := parseProxyAddress(device["listen"])
+		if !ok || (protocol != "tcp" && protocol != "udp") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingSecurityConfig(t *testing.T) {
+	missing := MissingSecurityConfig(map[string]string{
+		"security.nesting":                  "true",
+		"security.syscalls.intercept.mknod": "false",
+	})
+	expected := map[string]string{
+		"security.syscalls.intercept.mknod":    "true",
+		"security.syscalls.intercept.setxattr": "true",
+	}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("expected %v, got %v", expected, missing)
+	}
+
+	if missing := MissingSecurityConfig(DockerSecurityConfig); len(missing) != 0 {
+		t.Errorf("expected nothing missing, got %v", missing)
+	}
+}
+
+func TestAdoptableProxyDevices(t *testing.T) {
+	devices := map[string]map[string]string{
+		"web":    {"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:127.0.0.1:80"},
+		"dns":    {"type": "proxy", "listen": "udp:0.0.0.0:53", "connect": "udp:127.0.0.1:53"},
+		"socket": {"type": "proxy", "listen": "unix:/run/app.sock", "connect": "unix:/run/app.sock"},
+		"old":    {"type": "proxy", "listen": "tcp:0.0.0.0:2222", "connect": "tcp:127.0.0.1:22"},
+		"root":   {"type": "disk", "path": "/"},
+	}
+	config := map[string]string{AdoptedPortsKey: "old"}
+
+	names := AdoptableProxyDevices(config, devices)
+	if !reflect.DeepEqual(names, []string{"dns", "web"}) {
+		t.Errorf("expected [dns web], got %v", names)
+	}
+}
@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SSHAuthSockEnv is the variable ssh clients read the agent socket from
+const SSHAuthSockEnv = "SSH_AUTH_SOCK"
+
+// AgentDevicePrefix names the proxy devices that forward an SSH agent
+const AgentDevicePrefix = "ssh-agent-"
+
+// AgentForward is the host's SSH agent socket proxied into a container
+type AgentForward struct {
+	// Device is the proxy device to remove when the session ends
+	Device string
+	// Socket is the agent socket's path inside the container
+	Socket string
+}
+
+// HostSSHAgentSocket returns the host's SSH agent socket from SSH_AUTH_SOCK
+func HostSSHAgentSocket() (string, error) {
+	socket := os.Getenv(SSHAuthSockEnv)
+	if socket == "" {
+		return "", fmt.Errorf("no SSH agent: %s is not set (start one with 'eval $(ssh-agent)' and 'ssh-add')", SSHAuthSockEnv)
+	}
+	info, err := os.Stat(socket)
+	if err != nil {
+		return "", fmt.Errorf("SSH agent socket %s is not available: %w", socket, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return "", fmt.Errorf("%s=%s is not a socket", SSHAuthSockEnv, socket)
+	}
+	return socket, nil
+}
+
+// ForwardSSHAgent adds a proxy device that listens on a socket inside the
+// container, owned by user, and connects to the host's agent socket. The
+// device and socket are named after this process so concurrent sessions do
+// not collide.
+func ForwardSSHAgent(ctx context.Context, containerName, hostSocket, user string) (*AgentForward, error) {
+	uid, err := containerUserID(ctx, containerName, user, "-u")
+	if err != nil {
+		return nil, err
+	}
+	gid, err := containerUserID(ctx, containerName, user, "-g")
+	if err != nil {
+		return nil, err
+	}
+
+	forward := &AgentForward{
+		Device: fmt.Sprintf("%s%d", AgentDevicePrefix, os.Getpid()),
+		Socket: fmt.Sprintf("/tmp/lxc-go-cli-ssh-agent-%d.sock", os.Getpid()),
+	}
+	err = AddContainerDevice(containerName, forward.Device, "proxy", map[string]string{
+		"bind":    "container",
+		"connect": "unix:" + hostSocket,
+		"listen":  "unix:" + forward.Socket,
+		"uid":     uid,
+		"gid":     gid,
+		"mode":    "0600",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to forward the SSH agent: %w", err)
+	}
+	return forward, nil
+}
+
+// containerUserID looks up a user's uid (-u) or gid (-g) inside a container
+func containerUserID(ctx context.Context, containerName, user, flag string) (string, error) {
+	output, err := runOutput(ctx, "lxc", "exec", containerName, "--", "id", flag, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up user '%s' in container '%s': %w", user, containerName, err)
+	}
+	id := strings.TrimSpace(string(output))
+	if id == "" {
+		return "", fmt.Errorf("failed to look up user '%s' in container '%s': empty id", user, containerName)
+	}
+	return id, nil
+}
+
+// AgentCommand prefixes a command so it runs with SSH_AUTH_SOCK pointing at
+// the forwarded agent
+func AgentCommand(forward *AgentForward, command ...string) []string {
+	return append([]string{"env", SSHAuthSockEnv + "=" + forward.Socket}, command...)
+}
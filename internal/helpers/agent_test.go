@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHostSSHAgentSocket(t *testing.T) {
+	t.Setenv(SSHAuthSockEnv, "")
+	if _, err := HostSSHAgentSocket(); err == nil || !strings.Contains(err.Error(), "is not set") {
+		t.Errorf("expected an unset agent error, got %v", err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(SSHAuthSockEnv, file)
+	if _, err := HostSSHAgentSocket(); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("expected a not-a-socket error, got %v", err)
+	}
+
+	t.Setenv(SSHAuthSockEnv, filepath.Join(dir, "missing"))
+	if _, err := HostSSHAgentSocket(); err == nil {
+		t.Error("expected a missing socket to fail")
+	}
+
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("cannot create a unix socket: %v", err)
+	}
+	defer listener.Close()
+	t.Setenv(SSHAuthSockEnv, socket)
+	if got, err := HostSSHAgentSocket(); err != nil || got != socket {
+		t.Errorf("expected %s, got %s (%v)", socket, got, err)
+	}
+}
+
+func TestForwardSSHAgent(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("1000\n", nil, "lxc", "exec", "web", "--", "id", "-u", "app")
+	runner.Respond("1001\n", nil, "lxc", "exec", "web", "--", "id", "-g", "app")
+
+	forward, err := ForwardSSHAgent(context.Background(), "web", "/run/user/1000/agent.sock", "app")
+	if err != nil {
+		t.Fatalf("ForwardSSHAgent failed: %v", err)
+	}
+	pid := os.Getpid()
+	if forward.Device != fmt.Sprintf("ssh-agent-%d", pid) || forward.Socket != fmt.Sprintf("/tmp/lxc-go-cli-ssh-agent-%d.sock", pid) {
+		t.Errorf("unexpected forward: %+v", forward)
+	}
+	if !runner.Ran("lxc", "config", "device", "add", "web", forward.Device, "proxy",
+		"bind=container", "connect=unix:/run/user/1000/agent.sock", "gid=1001",
+		"listen=unix:"+forward.Socket, "mode=0600", "uid=1000") {
+		t.Errorf("expected the proxy device to be added, got %v", runner.Commands)
+	}
+
+	got := AgentCommand(forward, "git", "pull")
+	if strings.Join(got, " ") != "env SSH_AUTH_SOCK="+forward.Socket+" git pull" {
+		t.Errorf("unexpected agent command: %q", got)
+	}
+
+	runner.Reset()
+	runner.Respond("id: 'app': no such user\n", &MockExitError{Code: 1}, "lxc", "exec", "web", "--", "id")
+	if _, err := ForwardSSHAgent(context.Background(), "web", "/tmp/agent.sock", "app"); err == nil || !strings.Contains(err.Error(), "look up user 'app'") {
+		t.Errorf("expected a user lookup error, got %v", err)
+	}
+	if len(runner.Commands) != 1 {
+		t.Errorf("expected no device to be added after a failed lookup, got %v", runner.Commands)
+	}
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"logs web --docker app --follow", []string{"logs", "web", "--docker", "app", "--follow"}},
+		{`exec web -- sh -c 'docker ps -a'`, []string{"exec", "web", "--", "sh", "-c", "docker ps -a"}},
+		{`exec web -- echo "a \"b\" \$HOME" it\'s`, []string{"exec", "web", "--", "echo", `a "b" $HOME`, "it's"}},
+		{`  info   ''  `, []string{"info", ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := SplitCommandLine(tt.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %q, got %q", tt.line, tt.want, got)
+		}
+	}
+
+	for _, line := range []string{`logs 'web`, `logs "web`, `logs web\`} {
+		if _, err := SplitCommandLine(line); err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
+
+func TestValidateAliasName(t *testing.T) {
+	for _, name := range []string{"weblogs", "web-logs", "w_2"} {
+		if err := ValidateAliasName(name); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-x", "2web", "web logs", "web/logs"} {
+		if err := ValidateAliasName(name); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+}
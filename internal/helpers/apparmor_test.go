@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDockerAppArmorRules(t *testing.T) {
+	rules := GenerateDockerAppArmorRules()
+	for _, expected := range []string{"mount fstype=overlay,", "change_profile -> docker-default,", "pivot_root,"} {
+		if !strings.Contains(rules, expected) {
+			t.Errorf("generated rules should contain '%s'", expected)
+		}
+	}
+	if strings.Contains(rules, "unconfined") {
+		t.Error("generated rules must not make the container unconfined")
+	}
+}
+
+func TestValidateAppArmorProfileName(t *testing.T) {
+	for _, name := range []string{"docker-web", "lxc-container-default", "my.profile", "a/b"} {
+		if err := ValidateAppArmorProfileName(name); err != nil {
+			t.Errorf("expected '%s' to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "bad name", "x;rm", "-flag"} {
+		if err := ValidateAppArmorProfileName(name); err == nil {
+			t.Errorf("expected '%s' to be invalid", name)
+		}
+	}
+}
+
+func TestIsAppArmorProfileLoaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles")
+	content := "docker-default (enforce)\nlxd-web_</var/snap/lxd/common/lxd> (enforce)\nmy profile (complain)\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	original := appArmorProfilesPath
+	appArmorProfilesPath = path
+	defer func() { appArmorProfilesPath = original }()
+
+	for name, expected := range map[string]bool{"docker-default": true, "my profile": true, "docker": false} {
+		loaded, err := IsAppArmorProfileLoaded(name)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if loaded != expected {
+			t.Errorf("IsAppArmorProfileLoaded(%q) = %v, expected %v", name, loaded, expected)
+		}
+	}
+
+	appArmorProfilesPath = filepath.Join(t.TempDir(), "missing")
+	if _, err := IsAppArmorProfileLoaded("docker-default"); err == nil {
+		t.Error("expected error when profiles cannot be read")
+	}
+}
+
+func TestRawLXCEntries(t *testing.T) {
+	raw := "lxc.cgroup.devices.allow=a\nlxc.apparmor.profile = old\n"
+
+	updated := SetRawLXCEntry(raw, LXCAppArmorProfileKey, "new")
+	if updated != "lxc.cgroup.devices.allow=a\nlxc.apparmor.profile=new\n" {
+		t.Errorf("unexpected raw.lxc after set: %q", updated)
+	}
+
+	if removed := RemoveRawLXCEntry(raw, LXCAppArmorProfileKey); removed != "lxc.cgroup.devices.allow=a\n" {
+		t.Errorf("unexpected raw.lxc after remove: %q", removed)
+	}
+	if removed := RemoveRawLXCEntry("lxc.apparmor.profile=old", LXCAppArmorProfileKey); removed != "" {
+		t.Errorf("expected empty raw.lxc, got %q", removed)
+	}
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEvent is an action this tool took on its own, such as the watchdog
+// restarting a container
+type AuditEvent struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Container string    `json:"container"`
+	Reason    string    `json:"reason,omitempty"`
+	// Error is set when the action failed
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLogPath returns the path of the audit log, a file of JSON lines
+func AuditLogPath() string {
+	return filepath.Join(SettingsDir, "audit.log")
+}
+
+// AppendAuditEvent appends an event to the audit log, stamping it with the
+// current time if it has none
+func AppendAuditEvent(event AuditEvent) error {
+	if SettingsDir == "" {
+		return fmt.Errorf("no user config directory available for the audit log")
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	if err := os.MkdirAll(SettingsDir, 0755); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	file, err := os.OpenFile(AuditLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppendAuditEvent(t *testing.T) {
+	originalDir := SettingsDir
+	SettingsDir = t.TempDir()
+	t.Cleanup(func() { SettingsDir = originalDir })
+
+	if err := AppendAuditEvent(AuditEvent{Action: "restart-container", Container: "web", Reason: "docker is not ready"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := AppendAuditEvent(AuditEvent{Action: "restart-compose", Container: "web", Error: "exit status 1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(AuditLogPath())
+	if err != nil {
+		t.Fatalf("expected the audit log to exist, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", data)
+	}
+	var event AuditEvent
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil || event.Action != "restart-container" || event.Time.IsZero() {
+		t.Errorf("unexpected event %+v (%v)", event, err)
+	}
+	if strings.Contains(lines[0], `"error"`) || !strings.Contains(lines[1], `"error":"exit status 1"`) {
+		t.Errorf("expected the error only on the failed action, got %q", data)
+	}
+
+	SettingsDir = ""
+	if err := AppendAuditEvent(AuditEvent{Action: "restart-container"}); err == nil {
+		t.Error("expected an error without a config directory")
+	}
+}
@@ -0,0 +1,357 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// BenchTools are the packages 'bench --install' adds to a container
+var BenchTools = []string{"fio", "iperf3"}
+
+// DefaultBenchDir is where the disk benchmark writes its test file; /var/tmp
+// is on the root disk, unlike the tmpfs /tmp of some images
+const DefaultBenchDir = "/var/tmp"
+
+// benchFileName is the test file written in the benchmarked directory
+const benchFileName = ".lxc-go-cli-bench"
+
+// DiskBenchResult is the throughput of a container's disk. Random I/O is
+// only measured with fio.
+type DiskBenchResult struct {
+	Tool          string  `json:"tool"`
+	Path          string  `json:"path"`
+	SeqWriteMBps  float64 `json:"seq_write_mbps"`
+	SeqReadMBps   float64 `json:"seq_read_mbps"`
+	RandReadIOPS  float64 `json:"rand_read_iops,omitempty"`
+	RandWriteIOPS float64 `json:"rand_write_iops,omitempty"`
+}
+
+// NetworkBenchResult is the TCP throughput between a container and the host
+type NetworkBenchResult struct {
+	Server string `json:"server"`
+	// UploadMbps is container to host, DownloadMbps host to container
+	UploadMbps   float64 `json:"upload_mbps"`
+	DownloadMbps float64 `json:"download_mbps"`
+	Retransmits  int     `json:"retransmits"`
+}
+
+// containerHasCommand reports whether a tool answers --version in the container
+func containerHasCommand(ctx context.Context, containerName, tool string) bool {
+	_, err := Runner().RunWithOutput(ctx, "lxc", "exec", containerName, "--", tool, "--version")
+	return err == nil
+}
+
+// InstallBenchTools installs fio and iperf3 in a container with apt
+func InstallBenchTools(ctx context.Context, containerName string) error {
+	prefix := fmt.Sprintf("[%s] ", containerName)
+	if err := runStreamed(ctx, prefix, "lxc", "exec", containerName, "--", "apt-get", "update"); err != nil {
+		return fmt.Errorf("failed to update package index: %w", err)
+	}
+	args := append([]string{"lxc", "exec", containerName, "--"}, PackageInstallCommand(BenchTools)...)
+	if err := runStreamed(ctx, prefix, args...); err != nil {
+		return fmt.Errorf("failed to install %s: %w", strings.Join(BenchTools, " "), err)
+	}
+	return nil
+}
+
+// BenchDisk measures sequential and, with fio, random I/O on a directory of
+// a container, falling back to dd when fio is not installed. The test file
+// of sizeMB megabytes is removed afterwards.
+func BenchDisk(ctx context.Context, containerName, dir string, sizeMB int, runtime time.Duration) (*DiskBenchResult, error) {
+	if sizeMB < 1 {
+		return nil, fmt.Errorf("benchmark size must be at least 1 MB")
+	}
+	if !path.IsAbs(dir) {
+		return nil, fmt.Errorf("benchmark path '%s' must be absolute", dir)
+	}
+	file := path.Join(dir, benchFileName)
+	defer func() {
+		// The test file is the size of the benchmark; never leave it behind
+		cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if output, err := Runner().RunWithOutput(cleanup, "lxc", "exec", containerName, "--", "rm", "-f", file); err != nil {
+			logger.Warn("Could not remove %s in container '%s': %v (output: %s)", file, containerName, err, strings.TrimSpace(string(output)))
+		}
+	}()
+
+	if containerHasCommand(ctx, containerName, "fio") {
+		return benchDiskFio(ctx, containerName, file, sizeMB, runtime)
+	}
+	logger.Info("fio is not installed in '%s'; measuring sequential I/O with dd (use --install for fio)", containerName)
+	return benchDiskDD(ctx, containerName, file, sizeMB)
+}
+
+// fioJobs are the jobs of the fio benchmark; stonewall runs them one by one
+var fioJobs = []struct{ name, rw, bs string }{
+	{"seq-write", "write", "1M"},
+	{"seq-read", "read", "1M"},
+	{"rand-read", "randread", "4k"},
+	{"rand-write", "randwrite", "4k"},
+}
+
+// benchDiskFio runs the fio jobs with direct I/O, bypassing the page cache
+func benchDiskFio(ctx context.Context, containerName, file string, sizeMB int, runtime time.Duration) (*DiskBenchResult, error) {
+	seconds := int(runtime.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	args := []string{"lxc", "exec", containerName, "--", "fio", "--output-format=json",
+		"--filename=" + file, fmt.Sprintf("--size=%dM", sizeMB), "--direct=1",
+		fmt.Sprintf("--runtime=%d", seconds), "--end_fsync=1"}
+	for _, job := range fioJobs {
+		args = append(args, "--name="+job.name, "--stonewall", "--rw="+job.rw, "--bs="+job.bs)
+	}
+
+	logger.Info("Running fio on %s in container '%s'...", file, containerName)
+	output, err := runOutput(ctx, args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("fio failed in container '%s': %w", containerName, err)
+	}
+	result, err := ParseFioOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	result.Path = path.Dir(file)
+	return result, nil
+}
+
+// ParseFioOutput reads the results of the fio jobs from fio's JSON output
+func ParseFioOutput(output []byte) (*DiskBenchResult, error) {
+	type stats struct {
+		BW   float64 `json:"bw"` // KiB/s
+		IOPS float64 `json:"iops"`
+	}
+	var report struct {
+		Jobs []struct {
+			Name  string `json:"jobname"`
+			Read  stats  `json:"read"`
+			Write stats  `json:"write"`
+		} `json:"jobs"`
+	}
+	// fio may print warnings before the JSON document
+	if i := strings.IndexByte(string(output), '{'); i > 0 {
+		output = output[i:]
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse fio output: %w", err)
+	}
+
+	mbps := func(kib float64) float64 { return kib * 1024 / 1e6 }
+	result := &DiskBenchResult{Tool: "fio"}
+	found := 0
+	for _, job := range report.Jobs {
+		found++
+		switch job.Name {
+		case "seq-write":
+			result.SeqWriteMBps = mbps(job.Write.BW)
+		case "seq-read":
+			result.SeqReadMBps = mbps(job.Read.BW)
+		case "rand-read":
+			result.RandReadIOPS = job.Read.IOPS
+		case "rand-write":
+			result.RandWriteIOPS = job.Write.IOPS
+		default:
+			found--
+		}
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("fio reported no benchmark jobs")
+	}
+	return result, nil
+}
+
+// ddScript writes $2 megabytes to $1 and reads them back, bypassing the
+// page cache, printing dd's summary line for each
+const ddScript = `dd if=/dev/zero of="$1" bs=1M count="$2" conv=fdatasync 2>&1 | tail -n 1; ` +
+	`dd if="$1" of=/dev/null bs=1M iflag=direct 2>&1 | tail -n 1`
+
+// benchDiskDD measures sequential throughput with dd
+func benchDiskDD(ctx context.Context, containerName, file string, sizeMB int) (*DiskBenchResult, error) {
+	logger.Info("Running dd on %s in container '%s'...", file, containerName)
+	output, err := runOutput(ctx, "lxc", "exec", containerName, "--", "sh", "-c", ddScript, "sh", file, strconv.Itoa(sizeMB))
+	if err != nil {
+		return nil, fmt.Errorf("dd failed in container '%s': %w", containerName, err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected dd output: %s", strings.TrimSpace(string(output)))
+	}
+	write, err := ParseDDThroughput(lines[0])
+	if err != nil {
+		return nil, err
+	}
+	read, err := ParseDDThroughput(lines[1])
+	if err != nil {
+		return nil, err
+	}
+	return &DiskBenchResult{Tool: "dd", Path: path.Dir(file), SeqWriteMBps: write, SeqReadMBps: read}, nil
+}
+
+// ddSummaryPattern matches GNU dd's "N bytes (...) copied, S s, R MB/s"
+var ddSummaryPattern = regexp.MustCompile(`^(\d+) bytes .*copied, ([0-9.,]+) s`)
+
+// ParseDDThroughput returns the MB/s of a dd summary line, computed from the
+// byte count and time so the unit dd chose does not matter
+func ParseDDThroughput(line string) (float64, error) {
+	match := ddSummaryPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return 0, fmt.Errorf("unexpected dd output: %s", line)
+	}
+	bytes, _ := strconv.ParseFloat(match[1], 64)
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", "."), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("unexpected dd time in: %s", line)
+	}
+	return bytes / seconds / 1e6, nil
+}
+
+// ContainerGateway returns the IPv4 default gateway of a container, which
+// on a bridged network is the host
+func ContainerGateway(ctx context.Context, containerName string) (string, error) {
+	output, err := runOutput(ctx, "lxc", "exec", containerName, "--", "ip", "-4", "route", "show", "default")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the default route of '%s': %w", containerName, err)
+	}
+	fields := strings.Fields(string(output))
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "via" && net.ParseIP(fields[i+1]) != nil {
+			return fields[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("container '%s' has no IPv4 default gateway", containerName)
+}
+
+// freeTCPPort returns a port nothing on the host listens on
+func freeTCPPort() (int, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForListener waits until a TCP server accepts connections; a variable
+// so tests need no real server
+var waitForListener = func(ctx context.Context, address string) error {
+	for {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("iperf3 server on %s did not start: %w", address, err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// BenchNetwork measures TCP throughput both ways between a container and an
+// iperf3 server started on the host for the duration of the test
+func BenchNetwork(ctx context.Context, containerName string, duration time.Duration) (*NetworkBenchResult, error) {
+	if err := RequireLocalServer(ctx, "network benchmarks"); err != nil {
+		return nil, err
+	}
+	if _, err := lookPath("iperf3"); err != nil {
+		return nil, WithKind(fmt.Errorf("iperf3 is not installed on the host; install it (e.g. 'apt install iperf3') to benchmark the network"), ErrEnvironment)
+	}
+	if !containerHasCommand(ctx, containerName, "iperf3") {
+		return nil, fmt.Errorf("iperf3 is not installed in container '%s'; rerun with --install", containerName)
+	}
+	gateway, err := ContainerGateway(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+	port, err := freeTCPPort()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port for iperf3: %w", err)
+	}
+
+	// The server runs until the client tests are done
+	serverCtx, stopServer := context.WithCancel(ctx)
+	defer stopServer()
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+		output, err := Runner().RunWithOutput(serverCtx, "iperf3", "--server", "--bind", gateway, "--port", strconv.Itoa(port))
+		if err != nil && serverCtx.Err() == nil {
+			logger.Debug("iperf3 server exited: %v (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+	}()
+	defer func() {
+		stopServer()
+		<-serverDone
+	}()
+
+	address := net.JoinHostPort(gateway, strconv.Itoa(port))
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err = waitForListener(waitCtx, address)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	result := &NetworkBenchResult{Server: address}
+	for _, reverse := range []bool{false, true} {
+		args := []string{"exec", containerName, "--", "iperf3", "--client", gateway, "--port", strconv.Itoa(port), "--time", strconv.Itoa(seconds), "--json"}
+		direction := "container -> host"
+		if reverse {
+			args = append(args, "--reverse")
+			direction = "host -> container"
+		}
+		logger.Info("Measuring %s throughput for %ds...", direction, seconds)
+		output, err := runOutput(ctx, "lxc", args...)
+		if err != nil {
+			return nil, fmt.Errorf("iperf3 from container '%s' to %s failed (is the port blocked by the host firewall?): %w", containerName, address, err)
+		}
+		mbps, retransmits, err := ParseIperfOutput(output)
+		if err != nil {
+			return nil, err
+		}
+		if reverse {
+			result.DownloadMbps = mbps
+		} else {
+			result.UploadMbps = mbps
+		}
+		result.Retransmits += retransmits
+	}
+	return result, nil
+}
+
+// ParseIperfOutput returns the received throughput in Mbit/s and the
+// retransmits of an iperf3 --json client run
+func ParseIperfOutput(output []byte) (float64, int, error) {
+	var report struct {
+		End struct {
+			SumSent struct {
+				Retransmits int `json:"retransmits"`
+			} `json:"sum_sent"`
+			SumReceived struct {
+				BitsPerSecond float64 `json:"bits_per_second"`
+			} `json:"sum_received"`
+		} `json:"end"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse iperf3 output: %w", err)
+	}
+	if report.Error != "" {
+		return 0, 0, fmt.Errorf("iperf3: %s", report.Error)
+	}
+	return report.End.SumReceived.BitsPerSecond / 1e6, report.End.SumSent.Retransmits, nil
+}
@@ -0,0 +1,177 @@
+package helpers
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fioOutput = `note: both iodepth >= 1 and synchronous I/O engine are selected
+{
+  "fio version" : "fio-3.36",
+  "jobs" : [
+    {"jobname" : "seq-write", "read" : {"bw" : 0, "iops" : 0}, "write" : {"bw" : 512000, "iops" : 500}},
+    {"jobname" : "seq-read", "read" : {"bw" : 1024000, "iops" : 1000}, "write" : {"bw" : 0, "iops" : 0}},
+    {"jobname" : "rand-read", "read" : {"bw" : 80000, "iops" : 20000.5}, "write" : {"bw" : 0, "iops" : 0}},
+    {"jobname" : "rand-write", "read" : {"bw" : 0, "iops" : 0}, "write" : {"bw" : 40000, "iops" : 10000}}
+  ]
+}`
+
+const iperfOutput = `{"start": {}, "end": {"sum_sent": {"bits_per_second": 9.5e9, "retransmits": 3},
+  "sum_received": {"bits_per_second": 9.4e9}}}`
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestParseFioOutput(t *testing.T) {
+	result, err := ParseFioOutput([]byte(fioOutput))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Tool != "fio" || !near(result.SeqWriteMBps, 524.288) || !near(result.SeqReadMBps, 1048.576) ||
+		result.RandReadIOPS != 20000.5 || result.RandWriteIOPS != 10000 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := ParseFioOutput([]byte(`{"jobs": []}`)); err == nil {
+		t.Error("expected error without jobs")
+	}
+	if _, err := ParseFioOutput([]byte("fio: command not found")); err == nil {
+		t.Error("expected error for non-JSON output")
+	}
+}
+
+func TestParseDDThroughput(t *testing.T) {
+	tests := []struct {
+		line string
+		want float64
+	}{
+		{"268435456 bytes (268 MB, 256 MiB) copied, 0.5 s, 537 MB/s", 536.870912},
+		{"268435456 bytes (268 MB, 256 MiB) copied, 2 s, 134 MB/s", 134.217728},
+		{"268435456 Bytes (268 MB, 256 MiB) kopiert, 0,5 s, 537 MB/s", -1},
+		{"268435456 bytes (268 MB, 256 MiB) copied, 0,5 s, 537 MB/s", 536.870912},
+		{"dd: failed to open '/var/tmp/x': Permission denied", -1},
+	}
+	for _, tt := range tests {
+		got, err := ParseDDThroughput(tt.line)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf("expected error for %q", tt.line)
+			}
+			continue
+		}
+		if err != nil || !near(got, tt.want) {
+			t.Errorf("ParseDDThroughput(%q) = %v, %v; want %v", tt.line, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseIperfOutput(t *testing.T) {
+	mbps, retransmits, err := ParseIperfOutput([]byte(iperfOutput))
+	if err != nil || mbps != 9400 || retransmits != 3 {
+		t.Errorf("unexpected result %v %v %v", mbps, retransmits, err)
+	}
+	if _, _, err := ParseIperfOutput([]byte(`{"error": "unable to connect to server: Connection refused"}`)); err == nil || !strings.Contains(err.Error(), "Connection refused") {
+		t.Errorf("expected the iperf3 error, got %v", err)
+	}
+}
+
+func TestBenchDiskFio(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(fioOutput, nil, "lxc", "exec", "web", "--", "fio", "--output-format=json")
+
+	result, err := BenchDisk(context.Background(), "web", "/srv/data", 64, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Tool != "fio" || result.Path != "/srv/data" || result.RandReadIOPS == 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	var fio []string
+	for _, argv := range runner.Commands {
+		if len(argv) > 4 && argv[4] == "fio" && argv[5] != "--version" {
+			fio = argv
+		}
+	}
+	for _, want := range []string{"--filename=/srv/data/.lxc-go-cli-bench", "--size=64M", "--direct=1", "--runtime=5", "--name=rand-write"} {
+		if !strings.Contains(strings.Join(fio, " "), want) {
+			t.Errorf("expected %s in fio command %v", want, fio)
+		}
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "rm", "-f", "/srv/data/.lxc-go-cli-bench") {
+		t.Error("expected the test file to be removed")
+	}
+}
+
+func TestBenchDiskFallsBackToDD(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("", &MockExitError{Code: 127}, "lxc", "exec", "web", "--", "fio", "--version")
+	runner.Respond("268435456 bytes (268 MB, 256 MiB) copied, 1 s, 268 MB/s\n268435456 bytes (268 MB, 256 MiB) copied, 0.5 s, 537 MB/s\n",
+		nil, "lxc", "exec", "web", "--", "sh", "-c")
+
+	result, err := BenchDisk(context.Background(), "web", DefaultBenchDir, 256, time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Tool != "dd" || !near(result.SeqWriteMBps, 268.435456) || !near(result.SeqReadMBps, 536.870912) || result.RandReadIOPS != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "sh", "-c", ddScript, "sh", "/var/tmp/.lxc-go-cli-bench", "256") {
+		t.Errorf("expected dd to run on the test file, got %v", runner.Commands)
+	}
+
+	if _, err := BenchDisk(context.Background(), "web", "var/tmp", 256, time.Second); err == nil {
+		t.Error("expected error for a relative path")
+	}
+}
+
+func TestContainerGateway(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("default via 10.10.0.1 dev eth0 proto dhcp src 10.10.0.42 metric 100\n", nil, "lxc", "exec", "web", "--", "ip")
+	if gateway, err := ContainerGateway(context.Background(), "web"); err != nil || gateway != "10.10.0.1" {
+		t.Errorf("expected the gateway, got %q, %v", gateway, err)
+	}
+
+	runner.Respond("", nil, "lxc", "exec", "web", "--", "ip")
+	if _, err := ContainerGateway(context.Background(), "web"); err == nil {
+		t.Error("expected error without a default route")
+	}
+}
+
+func TestBenchNetwork(t *testing.T) {
+	runner := useMockRunner(t)
+	previousLookPath, previousWait := lookPath, waitForListener
+	t.Cleanup(func() { lookPath, waitForListener = previousLookPath, previousWait })
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	waitForListener = func(ctx context.Context, address string) error { return nil }
+
+	runner.Respond("default via 10.10.0.1 dev eth0\n", nil, "lxc", "exec", "web", "--", "ip")
+	runner.Respond(iperfOutput, nil, "lxc", "exec", "web", "--", "iperf3", "--client")
+
+	result, err := BenchNetwork(context.Background(), "web", 3*time.Second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.UploadMbps != 9400 || result.DownloadMbps != 9400 || result.Retransmits != 6 || !strings.HasPrefix(result.Server, "10.10.0.1:") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	var server, reverse bool
+	for _, argv := range runner.Commands {
+		command := strings.Join(argv, " ")
+		server = server || strings.HasPrefix(command, "iperf3 --server --bind 10.10.0.1 --port ")
+		reverse = reverse || (strings.Contains(command, "iperf3 --client 10.10.0.1") && strings.HasSuffix(command, "--time 3 --json --reverse"))
+	}
+	if !server || !reverse {
+		t.Errorf("expected a host server and a reverse client run, got %v", runner.Commands)
+	}
+
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	if _, err := BenchNetwork(context.Background(), "web", time.Second); err == nil || !strings.Contains(err.Error(), "not installed on the host") {
+		t.Errorf("expected missing host iperf3 error, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BootUnit is a systemd unit and how long it took to start
+type BootUnit struct {
+	Unit    string  `json:"unit"`
+	Seconds float64 `json:"seconds"`
+}
+
+// BootReport is how the last start of a container went: when LXD started it,
+// when systemd started in it and when systemd reached its default target
+type BootReport struct {
+	Container   string    `json:"container"`
+	Started     time.Time `json:"started"`
+	InitStarted time.Time `json:"init_started"`
+	// Finished is zero while the boot has not finished
+	Finished time.Time `json:"finished,omitempty"`
+	// Units are the units that took time to start, slowest first
+	Units []BootUnit `json:"units"`
+	// CriticalChain is the chain of units the default target waited for,
+	// as systemd-analyze critical-chain prints it
+	CriticalChain []string `json:"critical_chain,omitempty"`
+}
+
+// InitDelay is the time from LXD starting the container to systemd starting
+func (r BootReport) InitDelay() time.Duration {
+	return r.InitStarted.Sub(r.Started)
+}
+
+// SystemdDuration is the time systemd took to reach its default target
+func (r BootReport) SystemdDuration() time.Duration {
+	if r.Finished.IsZero() {
+		return 0
+	}
+	return r.Finished.Sub(r.InitStarted)
+}
+
+// Total is the time from LXD starting the container to the boot finishing
+func (r BootReport) Total() time.Duration {
+	if r.Finished.IsZero() {
+		return 0
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// systemdTimestampLayout is how systemctl show --timestamp=us+utc prints times
+const systemdTimestampLayout = "Mon 2006-01-02 15:04:05.999999 MST"
+
+// AnalyzeBoot reports how the last start of a running container went, from
+// LXD's start time and systemd-analyze in the container. Needs systemd 248
+// or later in the container, e.g. Ubuntu 22.04 or Debian 12.
+func AnalyzeBoot(ctx context.Context, containerName string) (*BootReport, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/instances/"+containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the start time of container '%s': %w", containerName, err)
+	}
+	started, err := parseInstanceLastStarted(output)
+	if err != nil {
+		return nil, err
+	}
+	report := &BootReport{Container: containerName, Started: started}
+
+	output, err = runOutput(ctx, "lxc", "exec", containerName, "--", "systemctl", "show",
+		"--property=UserspaceTimestamp", "--property=FinishTimestamp", "--timestamp=us+utc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the boot times of container '%s' (needs systemd 248 or later): %w", containerName, err)
+	}
+	if report.InitStarted, report.Finished, err = parseSystemdBootTimes(output); err != nil {
+		return nil, err
+	}
+	if report.Finished.IsZero() {
+		return report, nil
+	}
+
+	output, err = runOutput(ctx, "lxc", "exec", containerName, "--", "systemd-analyze", "blame", "--no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run systemd-analyze blame in container '%s': %w", containerName, err)
+	}
+	report.Units = parseSystemdBlame(output)
+
+	output, err = runOutput(ctx, "lxc", "exec", containerName, "--", "systemd-analyze", "critical-chain", "--no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run systemd-analyze critical-chain in container '%s': %w", containerName, err)
+	}
+	report.CriticalChain = parseCriticalChain(output)
+	return report, nil
+}
+
+// parseInstanceLastStarted reads when an instance was last started from a
+// GET /1.0/instances/<name> response
+func parseInstanceLastStarted(jsonOutput []byte) (time.Time, error) {
+	var instance struct {
+		Status     string    `json:"status"`
+		LastUsedAt time.Time `json:"last_used_at"`
+	}
+	if err := json.Unmarshal(jsonOutput, &instance); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse container state: %w", err)
+	}
+	if instance.Status != "Running" {
+		return time.Time{}, fmt.Errorf("container is %s; boot times are only available while it runs", strings.ToLower(instance.Status))
+	}
+	if instance.LastUsedAt.IsZero() || instance.LastUsedAt.Year() < 2000 {
+		return time.Time{}, fmt.Errorf("the server did not report when the container started")
+	}
+	return instance.LastUsedAt, nil
+}
+
+// parseSystemdBootTimes reads the UserspaceTimestamp and FinishTimestamp
+// properties printed by systemctl show; an empty FinishTimestamp means the
+// boot has not finished
+func parseSystemdBootTimes(output []byte) (initStarted, finished time.Time, err error) {
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || value == "" {
+			continue
+		}
+		at, parseErr := time.Parse(systemdTimestampLayout, value)
+		if parseErr != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse %s '%s': %w", key, value, parseErr)
+		}
+		switch key {
+		case "UserspaceTimestamp":
+			initStarted = at
+		case "FinishTimestamp":
+			finished = at
+		}
+	}
+	if initStarted.IsZero() {
+		return time.Time{}, time.Time{}, fmt.Errorf("systemd did not report when it started")
+	}
+	return initStarted, finished, nil
+}
+
+// parseSystemdBlame reads systemd-analyze blame output, lines such as
+// "1min 2.345s snapd.service", into units sorted slowest first
+func parseSystemdBlame(output []byte) []BootUnit {
+	var units []BootUnit
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		seconds, ok := parseSystemdTimespan(fields[:len(fields)-1])
+		if !ok {
+			continue
+		}
+		units = append(units, BootUnit{Unit: fields[len(fields)-1], Seconds: seconds})
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].Seconds > units[j].Seconds })
+	return units
+}
+
+// systemdTimespanUnits are the units systemd formats time spans with, as
+// seconds; longer suffixes come first so "ms" is not read as "s"
+var systemdTimespanUnits = []struct {
+	suffix  string
+	seconds float64
+}{
+	{"min", 60}, {"ms", 1e-3}, {"us", 1e-6}, {"µs", 1e-6}, {"h", 3600}, {"d", 86400}, {"s", 1},
+}
+
+// parseSystemdTimespan parses a time span such as "1min 2.345s" or "345ms"
+func parseSystemdTimespan(parts []string) (float64, bool) {
+	total := 0.0
+	for _, part := range parts {
+		matched := false
+		for _, unit := range systemdTimespanUnits {
+			number, found := strings.CutSuffix(part, unit.suffix)
+			if !found {
+				continue
+			}
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, false
+			}
+			total += value * unit.seconds
+			matched = true
+			break
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return total, true
+}
+
+// parseCriticalChain keeps the chain from systemd-analyze critical-chain
+// output, dropping its explanation of the notation
+func parseCriticalChain(output []byte) []string {
+	var chain []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "The time ") {
+			continue
+		}
+		chain = append(chain, strings.TrimRight(line, " "))
+	}
+	return chain
+}
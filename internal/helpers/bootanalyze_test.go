@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeBoot(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"name":"web","status":"Running","last_used_at":"2025-03-01T10:00:00.5Z"}`, nil,
+		"lxc", "query", "/1.0/instances/web")
+	runner.Respond("UserspaceTimestamp=Sat 2025-03-01 10:00:01.750000 UTC\nFinishTimestamp=Sat 2025-03-01 10:00:09.250000 UTC\n", nil,
+		"lxc", "exec", "web", "--", "systemctl", "show")
+	runner.Respond("345ms systemd-journald.service\n1min 2.5s cloud-init.service\n   4.100s snapd.service\n", nil,
+		"lxc", "exec", "web", "--", "systemd-analyze", "blame")
+	runner.Respond("The time when unit became active or started is printed after the \"@\" character.\n"+
+		"The time the unit took to start is printed after the \"+\" character.\n\n"+
+		"graphical.target @7.5s\n└─multi-user.target @7.5s\n  └─docker.service @3.2s +4.2s\n", nil,
+		"lxc", "exec", "web", "--", "systemd-analyze", "critical-chain")
+
+	report, err := AnalyzeBoot(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.InitDelay() != 1250*time.Millisecond {
+		t.Errorf("expected 1.25s from LXD start to systemd, got %s", report.InitDelay())
+	}
+	if report.SystemdDuration() != 7500*time.Millisecond || report.Total() != 8750*time.Millisecond {
+		t.Errorf("expected 7.5s in systemd and 8.75s in total, got %s and %s", report.SystemdDuration(), report.Total())
+	}
+	expected := []BootUnit{{"cloud-init.service", 62.5}, {"snapd.service", 4.1}, {"systemd-journald.service", 0.345}}
+	if len(report.Units) != len(expected) {
+		t.Fatalf("expected %d units, got %+v", len(expected), report.Units)
+	}
+	for i, unit := range expected {
+		if report.Units[i].Unit != unit.Unit || report.Units[i].Seconds-unit.Seconds > 1e-9 || unit.Seconds-report.Units[i].Seconds > 1e-9 {
+			t.Errorf("expected unit %d to be %+v, got %+v", i, unit, report.Units[i])
+		}
+	}
+	if len(report.CriticalChain) != 3 || report.CriticalChain[0] != "graphical.target @7.5s" {
+		t.Errorf("expected the chain without its header, got %q", report.CriticalChain)
+	}
+}
+
+func TestAnalyzeBootStillBooting(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"status":"Running","last_used_at":"2025-03-01T10:00:00Z"}`, nil, "lxc", "query")
+	runner.Respond("UserspaceTimestamp=Sat 2025-03-01 10:00:01 UTC\nFinishTimestamp=\n", nil, "lxc", "exec", "web")
+
+	report, err := AnalyzeBoot(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.Finished.IsZero() || report.Total() != 0 || report.Units != nil {
+		t.Errorf("expected an unfinished boot without units, got %+v", report)
+	}
+	if err := runner.ExpectCommands(
+		[]string{"lxc", "query", "/1.0/instances/web"},
+		[]string{"lxc", "exec", "web", "--", "systemctl", "show", "--property=UserspaceTimestamp", "--property=FinishTimestamp", "--timestamp=us+utc"},
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAnalyzeBootStopped(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"status":"Stopped","last_used_at":"2025-03-01T10:00:00Z"}`, nil, "lxc", "query")
+
+	_, err := AnalyzeBoot(context.Background(), "web")
+	if err == nil || !strings.Contains(err.Error(), "container is stopped") {
+		t.Errorf("expected a stopped container error, got %v", err)
+	}
+}
+
+func TestParseSystemdTimespan(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		ok       bool
+	}{
+		{"2.345s", 2.345, true},
+		{"345ms", 0.345, true},
+		{"12us", 0.000012, true},
+		{"1min 2s", 62, true},
+		{"1h 1min", 3660, true},
+		{"fast", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSystemdTimespan(strings.Fields(tt.input))
+		if ok != tt.ok || (ok && (got-tt.expected > 1e-9 || tt.expected-got > 1e-9)) {
+			t.Errorf("parseSystemdTimespan(%q) = %v, %v; expected %v, %v", tt.input, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
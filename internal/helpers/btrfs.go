@@ -0,0 +1,220 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Metadata usage above this percentage is reported as nearly exhausted when the
+// filesystem has no unallocated space left to grow the metadata chunks
+const (
+	btrfsMetadataWarnPercent   = 80.0
+	btrfsMetadataCriticalPct   = 95.0
+	btrfsLowUnallocatedBytes   = 1 << 30
+	snapLXDMountNamespace      = "/run/snapd/ns/lxd.mnt"
+	btrfsBalanceUsageThreshold = "50"
+)
+
+// storagePoolRoots lists the directories where LXD and Incus mount storage pools
+var storagePoolRoots = []string{
+	"/var/snap/lxd/common/lxd/storage-pools",
+	"/var/lib/lxd/storage-pools",
+	"/var/lib/incus/storage-pools",
+}
+
+// BtrfsUsage holds the space accounting of a Btrfs filesystem in bytes
+type BtrfsUsage struct {
+	DeviceSize        int64
+	DeviceAllocated   int64
+	DeviceUnallocated int64
+	Used              int64
+	FreeEstimated     int64
+	DataSize          int64
+	DataUsed          int64
+	MetadataSize      int64
+	MetadataUsed      int64
+}
+
+// MetadataPercent returns the percentage of allocated metadata space in use
+func (u *BtrfsUsage) MetadataPercent() float64 {
+	if u.MetadataSize == 0 {
+		return 0
+	}
+	return float64(u.MetadataUsed) / float64(u.MetadataSize) * 100
+}
+
+// MetadataNearlyExhausted reports whether metadata is close to full without room to grow.
+// Btrfs returns ENOSPC when metadata fills up even if plenty of data space is free.
+func (u *BtrfsUsage) MetadataNearlyExhausted() bool {
+	percent := u.MetadataPercent()
+	if percent >= btrfsMetadataCriticalPct {
+		return true
+	}
+	return percent >= btrfsMetadataWarnPercent && u.DeviceUnallocated < btrfsLowUnallocatedBytes
+}
+
+// StoragePoolMountPath returns the host path where a storage pool is mounted
+func StoragePoolMountPath(pool string) (string, error) {
+	if pool == "" {
+		return "", fmt.Errorf("storage pool name is required")
+	}
+	for _, root := range storagePoolRoots {
+		path := filepath.Join(root, pool)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	// Snap LXD mounts pools in its own mount namespace; the path exists there only
+	if _, err := os.Stat(snapLXDMountNamespace); err == nil {
+		return filepath.Join(storagePoolRoots[0], pool), nil
+	}
+	return "", fmt.Errorf("could not find mount path for storage pool '%s'", pool)
+}
+
+// btrfsCommandArgs builds the argv for a btrfs command, entering the snap LXD
+// mount namespace when the pool is only visible there
+func btrfsCommandArgs(args ...string) []string {
+	if _, err := os.Stat(snapLXDMountNamespace); err == nil {
+		return append([]string{"nsenter", "--mount=" + snapLXDMountNamespace, "--", "btrfs"}, args...)
+	}
+	return append([]string{"btrfs"}, args...)
+}
+
+// runBtrfsCommand runs a btrfs command against a storage pool and returns its output
+func runBtrfsCommand(pool string, args ...string) (string, error) {
+	if err := RequireLocalServer(context.Background(), "btrfs "+args[0]); err != nil {
+		return "", err
+	}
+
+	path, err := StoragePoolMountPath(pool)
+	if err != nil {
+		return "", err
+	}
+
+	argv, err := privilegedCommand(context.Background(), btrfsCommandArgs(append(args, path)...)...)
+	if err != nil {
+		return "", err
+	}
+	logger.Debug("Executing btrfs command: %v", argv)
+
+	output, err := Runner().RunWithOutput(context.Background(), argv[0], argv[1:]...)
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return "", withPrivilegeHint(fmt.Errorf("btrfs %s failed: %w (output: %s)", strings.Join(args, " "), err, string(output)), string(output))
+	}
+
+	return string(output), nil
+}
+
+// GetBtrfsUsage returns the space accounting for a Btrfs storage pool
+func GetBtrfsUsage(pool string) (*BtrfsUsage, error) {
+	output, err := runBtrfsCommand(pool, "filesystem", "usage", "-b")
+	if err != nil {
+		return nil, err
+	}
+	return parseBtrfsUsage(output)
+}
+
+// GetBtrfsScrubStatus returns the scrub status report for a Btrfs storage pool
+func GetBtrfsScrubStatus(pool string) (string, error) {
+	return runBtrfsCommand(pool, "scrub", "status")
+}
+
+// StartBtrfsScrub runs a scrub on a Btrfs storage pool and waits for it to finish
+func StartBtrfsScrub(pool string) (string, error) {
+	return runBtrfsCommand(pool, "scrub", "start", "-B")
+}
+
+// BalanceBtrfsPool compacts partially used data and metadata chunks, returning
+// the space to the unallocated pool so metadata can grow again
+func BalanceBtrfsPool(pool string) (string, error) {
+	return runBtrfsCommand(pool, "balance", "start",
+		"-dusage="+btrfsBalanceUsageThreshold, "-musage="+btrfsBalanceUsageThreshold)
+}
+
+// EnableBtrfsQuota enables qgroup accounting on a Btrfs storage pool so LXD can
+// enforce per-container size limits; enabling it again is harmless
+func EnableBtrfsQuota(pool string) error {
+	_, err := runBtrfsCommand(pool, "quota", "enable")
+	return err
+}
+
+var (
+	btrfsOverallPattern = regexp.MustCompile(`^\s*(Device size|Device allocated|Device unallocated|Used|Free \(estimated\)):\s+([0-9]+)`)
+	btrfsChunkPattern   = regexp.MustCompile(`^(Data|Metadata),[^:]*:\s+Size:([0-9]+),\s+Used:([0-9]+)`)
+)
+
+// parseBtrfsUsage parses `btrfs filesystem usage -b` output
+func parseBtrfsUsage(output string) (*BtrfsUsage, error) {
+	usage := &BtrfsUsage{}
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := btrfsOverallPattern.FindStringSubmatch(line); matches != nil {
+			value, _ := strconv.ParseInt(matches[2], 10, 64)
+			switch matches[1] {
+			case "Device size":
+				usage.DeviceSize = value
+			case "Device allocated":
+				usage.DeviceAllocated = value
+			case "Device unallocated":
+				usage.DeviceUnallocated = value
+			case "Used":
+				usage.Used = value
+			case "Free (estimated)":
+				usage.FreeEstimated = value
+			}
+			found = true
+			continue
+		}
+
+		if matches := btrfsChunkPattern.FindStringSubmatch(line); matches != nil {
+			size, _ := strconv.ParseInt(matches[2], 10, 64)
+			used, _ := strconv.ParseInt(matches[3], 10, 64)
+			if matches[1] == "Data" {
+				usage.DataSize += size
+				usage.DataUsed += used
+			} else {
+				usage.MetadataSize += size
+				usage.MetadataUsed += used
+			}
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("failed to parse btrfs usage output")
+	}
+	return usage, nil
+}
+
+// FormatBtrfsUsage returns a human-readable usage report
+func FormatBtrfsUsage(pool string, usage *BtrfsUsage) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Btrfs usage for storage pool '%s':\n", pool))
+	result.WriteString(fmt.Sprintf("  Device size:        %s\n", FormatBytes(usage.DeviceSize)))
+	result.WriteString(fmt.Sprintf("  Device unallocated: %s\n", FormatBytes(usage.DeviceUnallocated)))
+	result.WriteString(fmt.Sprintf("  Used:               %s\n", FormatBytes(usage.Used)))
+	result.WriteString(fmt.Sprintf("  Free (estimated):   %s\n", FormatBytes(usage.FreeEstimated)))
+	result.WriteString(fmt.Sprintf("  Data:               %s of %s\n", FormatBytes(usage.DataUsed), FormatBytes(usage.DataSize)))
+	result.WriteString(fmt.Sprintf("  Metadata:           %s of %s (%.1f%%)\n",
+		FormatBytes(usage.MetadataUsed), FormatBytes(usage.MetadataSize), usage.MetadataPercent()))
+
+	if usage.MetadataNearlyExhausted() {
+		result.WriteString("  WARNING: metadata space is nearly exhausted; run 'lxc-go-cli storage maintain " + pool + " --balance'\n")
+	}
+
+	return result.String()
+}
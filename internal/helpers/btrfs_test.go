@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleBtrfsUsage = `Overall:
+    Device size:                  32212254720
+    Device allocated:              3296722944
+    Device unallocated:           28915531776
+    Device missing:                         0
+    Used:                          1512271872
+    Free (estimated):             30124507136      (min: 15666741248)
+    Free (statfs, df):            30123458560
+    Data ratio:                          1.00
+    Metadata ratio:                      2.00
+    Global reserve:                   5521408      (used: 0)
+    Multiple profiles:                     no
+
+Data,single: Size:2155872256, Used:946868224 (43.92%)
+   /dev/loop5   2155872256
+
+Metadata,DUP: Size:536870912, Used:268435456 (50.00%)
+   /dev/loop5   1073741824
+
+System,DUP: Size:33554432, Used:16384 (0.05%)
+   /dev/loop5     67108864
+`
+
+func TestParseBtrfsUsage(t *testing.T) {
+	usage, err := parseBtrfsUsage(sampleBtrfsUsage)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if usage.DeviceSize != 32212254720 {
+		t.Errorf("unexpected device size: %d", usage.DeviceSize)
+	}
+	if usage.DeviceUnallocated != 28915531776 {
+		t.Errorf("unexpected unallocated: %d", usage.DeviceUnallocated)
+	}
+	if usage.FreeEstimated != 30124507136 {
+		t.Errorf("unexpected free estimate: %d", usage.FreeEstimated)
+	}
+	if usage.DataSize != 2155872256 || usage.DataUsed != 946868224 {
+		t.Errorf("unexpected data usage: %+v", usage)
+	}
+	if usage.MetadataPercent() != 50 {
+		t.Errorf("expected 50%% metadata usage, got %.2f", usage.MetadataPercent())
+	}
+	if usage.MetadataNearlyExhausted() {
+		t.Error("metadata should not be reported as exhausted")
+	}
+
+	if _, err := parseBtrfsUsage("ERROR: not a btrfs filesystem"); err == nil {
+		t.Error("expected error for unparseable output")
+	}
+}
+
+func TestMetadataNearlyExhausted(t *testing.T) {
+	tests := []struct {
+		name     string
+		usage    BtrfsUsage
+		expected bool
+	}{
+		{name: "empty", usage: BtrfsUsage{}, expected: false},
+		{name: "high usage with room to grow", usage: BtrfsUsage{MetadataSize: 100, MetadataUsed: 85, DeviceUnallocated: 10 << 30}, expected: false},
+		{name: "high usage without room to grow", usage: BtrfsUsage{MetadataSize: 100, MetadataUsed: 85, DeviceUnallocated: 1 << 20}, expected: true},
+		{name: "critical usage", usage: BtrfsUsage{MetadataSize: 100, MetadataUsed: 97, DeviceUnallocated: 10 << 30}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.MetadataNearlyExhausted(); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBtrfsCommandArgs(t *testing.T) {
+	args := btrfsCommandArgs("scrub", "status", "/pool")
+	joined := strings.Join(args, " ")
+	if !strings.HasSuffix(joined, "btrfs scrub status /pool") {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestStoragePoolMountPathValidation(t *testing.T) {
+	if _, err := StoragePoolMountPath(""); err == nil {
+		t.Error("expected error for empty pool name")
+	}
+}
+
+func TestFormatBtrfsUsage(t *testing.T) {
+	output := FormatBtrfsUsage("fast", &BtrfsUsage{MetadataSize: 100, MetadataUsed: 99})
+	if !strings.Contains(output, "Btrfs usage for storage pool 'fast'") {
+		t.Errorf("missing header: %s", output)
+	}
+	if !strings.Contains(output, "WARNING: metadata space is nearly exhausted") {
+		t.Errorf("missing warning: %s", output)
+	}
+
+	output = FormatBtrfsUsage("fast", &BtrfsUsage{MetadataSize: 100, MetadataUsed: 10, DeviceUnallocated: 10 << 30})
+	if strings.Contains(output, "WARNING") {
+		t.Errorf("unexpected warning: %s", output)
+	}
+}
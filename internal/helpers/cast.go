@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Terminal size assumed when it cannot be read, e.g. without a terminal
+const (
+	DefaultTerminalWidth  = 80
+	DefaultTerminalHeight = 24
+)
+
+// CastHeader is the first line of an asciinema v2 recording
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastRecorder writes what is written to it as output events of an
+// asciinema v2 recording, timed from when it was created. A multi-byte
+// character split across writes is held back until it is complete.
+type CastRecorder struct {
+	w     io.Writer
+	start time.Time
+	// now is the clock; tests replace it
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending []byte
+	err     error
+}
+
+// NewCastRecorder writes the header of a recording started at start to w.
+// The header's version and timestamp are filled in.
+func NewCastRecorder(w io.Writer, header CastHeader, start time.Time) (*CastRecorder, error) {
+	header.Version = 2
+	header.Timestamp = start.Unix()
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recording header: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write recording: %w", err)
+	}
+	return &CastRecorder{w: w, start: start, now: time.Now}, nil
+}
+
+// Write records p as an output event. It never fails so that a recording
+// problem does not break the session; Err reports it afterwards.
+func (r *CastRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending, p...)
+	cut := incompleteRuneStart(data)
+	r.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		r.event(data[:cut])
+	}
+	return len(p), nil
+}
+
+// Close records anything held back, even if it is not valid UTF-8
+func (r *CastRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) > 0 {
+		r.event(r.pending)
+		r.pending = nil
+	}
+	return r.err
+}
+
+// Err returns the first error writing the recording
+func (r *CastRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// event writes one output event; the caller holds mu
+func (r *CastRecorder) event(data []byte) {
+	if r.err != nil {
+		return
+	}
+	elapsed := r.now().Sub(r.start).Seconds()
+	line, err := json.Marshal([]any{json.Number(strconv.FormatFloat(elapsed, 'f', 6, 64)), "o", string(data)})
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+	}
+}
+
+// incompleteRuneStart returns where an incomplete UTF-8 character at the end
+// of data starts, or len(data) if it ends with a complete one
+func incompleteRuneStart(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// TerminalSize returns the width and height of the terminal on stdin, from
+// stty, falling back to $COLUMNS and $LINES and then to 80x24
+func TerminalSize(ctx context.Context) (width, height int) {
+	width, height = DefaultTerminalWidth, DefaultTerminalHeight
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		width = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		height = n
+	}
+
+	var out bytes.Buffer
+	if err := Runner().RunStreaming(ctx, Streams{Stdin: os.Stdin, Stdout: &out}, "stty", "size"); err != nil {
+		return width, height
+	}
+	fields := strings.Fields(out.String())
+	if len(fields) != 2 {
+		return width, height
+	}
+	rows, rowsErr := strconv.Atoi(fields[0])
+	cols, colsErr := strconv.Atoi(fields[1])
+	if rowsErr != nil || colsErr != nil || rows <= 0 || cols <= 0 {
+		return width, height
+	}
+	return cols, rows
+}
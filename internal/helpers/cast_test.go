@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCastRecorder(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	recorder, err := NewCastRecorder(&buf, CastHeader{Width: 120, Height: 40, Title: "demo"}, start)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	now := start
+	recorder.now = func() time.Time { return now }
+
+	now = start.Add(1500 * time.Millisecond)
+	recorder.Write([]byte("héllo\r\n"))
+	// "é" split across two writes is recorded once it is complete
+	now = start.Add(2 * time.Second)
+	recorder.Write([]byte("caf\xc3"))
+	now = start.Add(2500 * time.Millisecond)
+	recorder.Write([]byte("\xa9"))
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `{"version":2,"width":120,"height":40,"timestamp":1735732800,"title":"demo"}
+[1.500000,"o","héllo\r\n"]
+[2.000000,"o","caf"]
+[2.500000,"o","é"]
+`
+	if buf.String() != expected {
+		t.Errorf("expected recording:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestCastRecorderFlushesOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := NewCastRecorder(&buf, CastHeader{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Write([]byte("\xe2\x82"))
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("expected the incomplete character to be held back, got %q", buf.String())
+	}
+	recorder.Close()
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("expected the held back bytes on close, got %q", buf.String())
+	}
+}
+
+type failingWriter struct{ writes int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestCastRecorderWriteError(t *testing.T) {
+	recorder, err := NewCastRecorder(&failingWriter{}, CastHeader{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := recorder.Write([]byte("output")); n != 6 || err != nil {
+		t.Errorf("expected the session not to see recording errors, got %d, %v", n, err)
+	}
+	if err := recorder.Close(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected the write error on close, got %v", err)
+	}
+}
+
+func TestTerminalSize(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	t.Setenv("LINES", "")
+	runner := useMockRunner(t)
+	runner.Respond("40 120\n", nil, "stty", "size")
+	if width, height := TerminalSize(context.Background()); width != 120 || height != 40 {
+		t.Errorf("expected 120x40, got %dx%d", width, height)
+	}
+
+	runner.Respond("", errors.New("stty: 'standard input': Inappropriate ioctl for device"), "stty", "size")
+	if width, height := TerminalSize(context.Background()); width != DefaultTerminalWidth || height != DefaultTerminalHeight {
+		t.Errorf("expected the default size, got %dx%d", width, height)
+	}
+
+	t.Setenv("COLUMNS", "100")
+	t.Setenv("LINES", "30")
+	if width, height := TerminalSize(context.Background()); width != 100 || height != 30 {
+		t.Errorf("expected the size from the environment, got %dx%d", width, height)
+	}
+}
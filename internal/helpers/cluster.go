@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MemberStatusOnline is the status of a cluster member that can run instances
+const MemberStatusOnline = "Online"
+
+// ClusterMember is a server of an LXD cluster
+type ClusterMember struct {
+	Name         string   `json:"server_name"`
+	URL          string   `json:"url"`
+	Roles        []string `json:"roles"`
+	Architecture string   `json:"architecture"`
+	Status       string   `json:"status"`
+	Message      string   `json:"message"`
+}
+
+// MemberCapacity is the processors and memory of a cluster member
+type MemberCapacity struct {
+	CPUs        int
+	MemoryTotal int64
+	MemoryUsed  int64
+}
+
+// IsClustered reports whether the server is a member of a cluster
+func IsClustered(ctx context.Context) (bool, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0")
+	if err != nil {
+		return false, fmt.Errorf("failed to query LXD server: %w", err)
+	}
+	var info struct {
+		Environment struct {
+			ServerClustered bool `json:"server_clustered"`
+		} `json:"environment"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return false, fmt.Errorf("failed to parse server info: %w", err)
+	}
+	return info.Environment.ServerClustered, nil
+}
+
+// ListClusterMembers returns the members of the cluster, sorted by name
+func ListClusterMembers(ctx context.Context) ([]ClusterMember, error) {
+	output, err := runOutput(ctx, "lxc", "cluster", "list", "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster members: %w", err)
+	}
+	var members []ClusterMember
+	if err := json.Unmarshal(output, &members); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster member list: %w", err)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	return members, nil
+}
+
+// GetMemberCapacity returns the processors and memory of a cluster member
+func GetMemberCapacity(ctx context.Context, member string) (*MemberCapacity, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/resources?target="+url.QueryEscape(member))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources of member '%s': %w", member, err)
+	}
+	var resources struct {
+		CPU struct {
+			Total int `json:"total"`
+		} `json:"cpu"`
+		Memory struct {
+			Total int64 `json:"total"`
+			Used  int64 `json:"used"`
+		} `json:"memory"`
+	}
+	if err := json.Unmarshal(output, &resources); err != nil {
+		return nil, fmt.Errorf("failed to parse resources of member '%s': %w", member, err)
+	}
+	return &MemberCapacity{
+		CPUs:        resources.CPU.Total,
+		MemoryTotal: resources.Memory.Total,
+		MemoryUsed:  resources.Memory.Used,
+	}, nil
+}
+
+// CheckTargetMember returns an error unless member is an online member of
+// the cluster
+func CheckTargetMember(members []ClusterMember, member string) error {
+	names := make([]string, 0, len(members))
+	for _, candidate := range members {
+		if candidate.Name == member {
+			if candidate.Status != MemberStatusOnline {
+				return fmt.Errorf("cluster member '%s' is %s: %s", member, strings.ToLower(candidate.Status), candidate.Message)
+			}
+			return nil
+		}
+		names = append(names, candidate.Name)
+	}
+	return NotFoundErrorf("cluster member '%s' does not exist (members: %s)", member, strings.Join(names, ", "))
+}
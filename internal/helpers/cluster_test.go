@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIsClustered(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"environment": {"server_clustered": true}}`, nil, "lxc", "query", "/1.0")
+	if clustered, err := IsClustered(context.Background()); err != nil || !clustered {
+		t.Errorf("expected a cluster, got %v, %v", clustered, err)
+	}
+	runner.Respond(`{"environment": {}}`, nil, "lxc", "query", "/1.0")
+	if clustered, err := IsClustered(context.Background()); err != nil || clustered {
+		t.Errorf("expected a standalone server, got %v, %v", clustered, err)
+	}
+}
+
+func TestListClusterMembers(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`[{"server_name": "member2", "url": "https://10.0.0.2:8443", "roles": [], "status": "Online"},
+		{"server_name": "member1", "url": "https://10.0.0.1:8443", "roles": ["database"], "architecture": "x86_64", "status": "Online"}]`,
+		nil, "lxc", "cluster", "list", "--format", "json")
+
+	members, err := ListClusterMembers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[0].Name != "member1" || members[0].Roles[0] != "database" {
+		t.Errorf("unexpected members %+v", members)
+	}
+}
+
+func TestGetMemberCapacity(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"cpu": {"total": 16}, "memory": {"total": 34359738368, "used": 8589934592}}`, nil, "lxc", "query", "/1.0/resources?target=member1")
+
+	capacity, err := GetMemberCapacity(context.Background(), "member1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capacity.CPUs != 16 || capacity.MemoryTotal != 32<<30 || capacity.MemoryUsed != 8<<30 {
+		t.Errorf("unexpected capacity %+v", capacity)
+	}
+}
+
+func TestCheckTargetMember(t *testing.T) {
+	members := []ClusterMember{
+		{Name: "member1", Status: MemberStatusOnline},
+		{Name: "member2", Status: "Evacuated", Message: "Evacuated by an operator"},
+	}
+	if err := CheckTargetMember(members, "member1"); err != nil {
+		t.Errorf("expected member1 to be usable, got %v", err)
+	}
+	if err := CheckTargetMember(members, "member2"); err == nil || !strings.Contains(err.Error(), "evacuated") {
+		t.Errorf("expected an evacuated member to be rejected, got %v", err)
+	}
+	if err := CheckTargetMember(members, "member9"); err == nil || !strings.Contains(err.Error(), "member1, member2") {
+		t.Errorf("expected the members to be listed, got %v", err)
+	}
+}
+
+func TestLaunchInstanceOnTarget(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := LaunchInstance("web", "ubuntu", "24.04", "amd64", "pool", LaunchOptions{Ephemeral: true, Target: "member3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.ExpectCommands([]string{"lxc", "launch", "ubuntu:24.04", "web", "--storage", "pool", "--ephemeral", "--target", "member3"}); err != nil {
+		t.Error(err)
+	}
+}
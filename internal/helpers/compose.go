@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// composeFiles are the file names docker compose looks for in a project directory
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// ComposeService is the state of one service container of a compose project
+type ComposeService struct {
+	Service  string `json:"Service"`
+	State    string `json:"State"`
+	Health   string `json:"Health"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// IsHealthy reports whether the service is running and not failing its
+// health check, or is a one-shot service that exited successfully
+func (s ComposeService) IsHealthy() bool {
+	if s.State == "exited" {
+		return s.ExitCode == 0
+	}
+	return s.State == "running" && s.Health != "unhealthy"
+}
+
+// composeProjectScript runs a docker compose command in projectDir, or does
+// nothing when the directory has no compose file
+func composeProjectScript(projectDir, command string) string {
+	tests := make([]string, len(composeFiles))
+	for i, file := range composeFiles {
+		tests[i] = "[ -e " + file + " ]"
+	}
+	return "cd " + ShellQuote(projectDir) + " && { " + strings.Join(tests, " || ") + " || exit 0; } && exec docker compose " + command
+}
+
+// ListComposeServices returns the service containers of the compose project in
+// projectDir inside a container, or none if there is no project there
+func ListComposeServices(ctx context.Context, containerName, projectDir string) ([]ComposeService, error) {
+	script := composeProjectScript(projectDir, "ps --all --format json")
+	output, err := runOutput(ctx, "lxc", "exec", containerName, "--", "su", "-", "app", "-c", script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compose services in container '%s': %w", containerName, err)
+	}
+	return parseComposeServices(output)
+}
+
+// parseComposeServices parses 'docker compose ps --format json', which is a
+// JSON array in older releases and one object per line in newer ones
+func parseComposeServices(output []byte) ([]ComposeService, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, nil
+	}
+
+	var services []ComposeService
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &services); err != nil {
+			return nil, fmt.Errorf("failed to parse compose services: %w", err)
+		}
+		return services, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var service ComposeService
+		if err := json.Unmarshal(line, &service); err != nil {
+			return nil, fmt.Errorf("failed to parse compose services: %w", err)
+		}
+		services = append(services, service)
+	}
+	return services, scanner.Err()
+}
+
+// RestartComposeProject restarts every service of the compose project in
+// projectDir inside a container
+func RestartComposeProject(ctx context.Context, containerName, projectDir string) error {
+	script := composeProjectScript(projectDir, "restart")
+	if _, err := runOutput(ctx, "lxc", "exec", containerName, "--", "su", "-", "app", "-c", script); err != nil {
+		return fmt.Errorf("failed to restart compose project in container '%s': %w", containerName, err)
+	}
+	return nil
+}
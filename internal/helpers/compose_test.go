@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseComposeServices(t *testing.T) {
+	lines := `{"Service":"web","State":"running","Health":""}
+{"Service":"db","State":"running","Health":"unhealthy"}
+{"Service":"worker","State":"exited","Health":"","ExitCode":1}
+{"Service":"migrate","State":"exited","Health":"","ExitCode":0}
+{"Service":"cache","State":"restarting","Health":""}
+`
+	array := `[{"Service":"web","State":"running","Health":"healthy"}]`
+
+	services, err := parseComposeServices([]byte(lines))
+	if err != nil || len(services) != 5 {
+		t.Fatalf("expected 5 services, got %+v (%v)", services, err)
+	}
+	for _, service := range services {
+		// A one-shot service that exited successfully is healthy
+		if service.IsHealthy() != (service.Service == "web" || service.Service == "migrate") {
+			t.Errorf("%s: unexpected health %v", service.Service, service.IsHealthy())
+		}
+	}
+
+	if services, err := parseComposeServices([]byte(array)); err != nil || len(services) != 1 || !services[0].IsHealthy() {
+		t.Errorf("expected the array format to parse, got %+v (%v)", services, err)
+	}
+	if services, err := parseComposeServices([]byte("\n")); err != nil || services != nil {
+		t.Errorf("expected no services for empty output, got %+v (%v)", services, err)
+	}
+	if _, err := parseComposeServices([]byte("not json")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestListComposeServices(t *testing.T) {
+	mock := useMockRunner(t)
+	script := composeProjectScript("/home/app", "ps --all --format json")
+	mock.Respond(`{"Service":"web","State":"running"}`, nil, "lxc", "exec", "web")
+
+	services, err := ListComposeServices(context.Background(), "web", "/home/app")
+	if err != nil || len(services) != 1 || services[0].Service != "web" {
+		t.Errorf("unexpected services %+v (%v)", services, err)
+	}
+	if !mock.Ran("lxc", "exec", "web", "--", "su", "-", "app", "-c", script) {
+		t.Errorf("expected compose ps to run as app, got %v", mock.Commands)
+	}
+	if !strings.Contains(script, "|| exit 0;") || !strings.HasSuffix(script, "exec docker compose ps --all --format json") {
+		t.Errorf("unexpected script %q", script)
+	}
+}
@@ -0,0 +1,213 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// ManagedConfigKeys are the instance config keys set by this tool
+var ManagedConfigKeys = []string{
+	"security.nesting",
+	"security.privileged",
+	"security.syscalls.intercept.mknod",
+	"security.syscalls.intercept.setxattr",
+	RawIdmapKey,
+}
+
+// ManagedConfigPrefixes are config key prefixes owned by this tool
+var ManagedConfigPrefixes = []string{"user."}
+
+// ManagedDeviceTypes are the device types this tool adds to containers
+var ManagedDeviceTypes = []string{"proxy", "gpu", "disk"}
+
+// SecretConfigKeys are config keys whose values are masked when displayed
+var SecretConfigKeys = []string{"user.app-password"}
+
+// maskedValue replaces secret values in displayed configuration
+const maskedValue = "<hidden>"
+
+// ManagedConfig is the tool-scoped view of a container's configuration
+type ManagedConfig struct {
+	Name    string                       `yaml:"name"`
+	Config  map[string]string            `yaml:"config,omitempty"`
+	Devices map[string]map[string]string `yaml:"devices,omitempty"`
+}
+
+// GetContainerConfig returns the output of `lxc config show` for a container
+func GetContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+
+	output, err := Runner().RunWithOutput(ctx, "lxc", "config", "show", containerName)
+	if err != nil {
+		logger.Debug("Failed to get container config: %s", string(output))
+		return nil, fmt.Errorf("failed to get container config: %w (output: %s)", err, string(output))
+	}
+
+	return output, nil
+}
+
+// readContainerConfig returns the parsed `lxc config show` of a container
+func readContainerConfig(ctx context.Context, containerName string) (ContainerConfig, error) {
+	var config ContainerConfig
+	output, err := GetContainerConfig(ctx, containerName)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(output, &config); err != nil {
+		return config, fmt.Errorf("failed to parse container config YAML: %w", err)
+	}
+	return config, nil
+}
+
+// GetExpandedContainerConfig returns the container configuration with profile settings applied
+func GetExpandedContainerConfig(ctx context.Context, containerName string) ([]byte, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+
+	output, err := Runner().RunWithOutput(ctx, "lxc", "config", "show", containerName, "--expanded")
+	if err != nil {
+		logger.Debug("Failed to get expanded container config: %s", string(output))
+		return nil, fmt.Errorf("failed to get container config: %w (output: %s)", err, string(output))
+	}
+
+	return output, nil
+}
+
+// isManagedConfigKey reports whether a config key is set by this tool
+func isManagedConfigKey(key string) bool {
+	for _, managed := range ManagedConfigKeys {
+		if key == managed {
+			return true
+		}
+	}
+	for _, prefix := range ManagedConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretConfigKey reports whether a config value must not be displayed
+func isSecretConfigKey(key string) bool {
+	for _, secret := range SecretConfigKeys {
+		if key == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterManagedConfig reduces `lxc config show` output to the keys and devices this tool manages
+func FilterManagedConfig(containerName string, yamlData []byte) (*ManagedConfig, error) {
+	var raw struct {
+		Config  map[string]string            `yaml:"config"`
+		Devices map[string]map[string]string `yaml:"devices"`
+	}
+	if err := yaml.Unmarshal(yamlData, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+
+	result := &ManagedConfig{
+		Name:    containerName,
+		Config:  make(map[string]string),
+		Devices: make(map[string]map[string]string),
+	}
+	for key, value := range raw.Config {
+		if !isManagedConfigKey(key) {
+			continue
+		}
+		if isSecretConfigKey(key) {
+			value = maskedValue
+		}
+		result.Config[key] = value
+	}
+	for name, device := range raw.Devices {
+		for _, deviceType := range ManagedDeviceTypes {
+			if device["type"] == deviceType {
+				result.Devices[name] = device
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// MaskConfigSecrets returns the full configuration with secret values masked
+func MaskConfigSecrets(yamlData []byte) ([]byte, error) {
+	var full yaml.MapSlice
+	if err := yaml.Unmarshal(yamlData, &full); err != nil {
+		return nil, fmt.Errorf("failed to parse container configuration: %w", err)
+	}
+
+	for i, item := range full {
+		if item.Key != "config" && item.Key != "expanded_config" {
+			continue
+		}
+		config, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		for j, entry := range config {
+			if key, ok := entry.Key.(string); ok && isSecretConfigKey(key) {
+				config[j].Value = maskedValue
+			}
+		}
+		full[i].Value = config
+	}
+
+	return yaml.Marshal(full)
+}
+
+// GetConfigValue returns a single instance config value, empty if unset
+func GetConfigValue(containerName, key string) (string, error) {
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "get", containerName, key)
+	if err != nil {
+		logger.Debug("Failed to get %s: %s", key, string(output))
+		return "", fmt.Errorf("failed to get %s: %w (output: %s)", key, err, string(output))
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// SetConfigValue sets a single instance config value
+func SetConfigValue(containerName, key, value string) error {
+	logger.Debug("Setting %s for container %s", key, containerName)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "set", containerName, key, value)
+	if err != nil {
+		logger.Debug("Failed to set %s: %s", key, string(output))
+		return fmt.Errorf("failed to set %s: %w (output: %s)", key, err, string(output))
+	}
+	return nil
+}
+
+// UnsetConfigValue removes a single instance config value
+func UnsetConfigValue(containerName, key string) error {
+	logger.Debug("Unsetting %s for container %s", key, containerName)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "unset", containerName, key)
+	if err != nil {
+		logger.Debug("Failed to unset %s: %s", key, string(output))
+		return fmt.Errorf("failed to unset %s: %w (output: %s)", key, err, string(output))
+	}
+	return nil
+}
+
+// SplitConfigList parses a comma-separated list stored in a config value
+func SplitConfigList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
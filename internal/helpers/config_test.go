@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsManagedConfigKey(t *testing.T) {
+	tests := map[string]bool{
+		"security.nesting":                  true,
+		"security.privileged":               true,
+		"security.syscalls.intercept.mknod": true,
+		"user.app-password":                 true,
+		"user.anything":                     true,
+		"security.idmap.isolated":           false,
+		"volatile.eth0.hwaddr":              false,
+		"image.os":                          false,
+	}
+
+	for key, expected := range tests {
+		if got := isManagedConfigKey(key); got != expected {
+			t.Errorf("isManagedConfigKey(%q) = %v, expected %v", key, got, expected)
+		}
+	}
+}
+
+func TestFilterManagedConfig(t *testing.T) {
+	data := []byte(`config:
+  security.nesting: "true"
+  user.app-password: c2VjcmV0
+  volatile.uuid: abc
+devices:
+  gpu:
+    type: gpu
+  eth0:
+    type: nic
+`)
+
+	managed, err := FilterManagedConfig("web", data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(managed.Config) != 2 || managed.Config["user.app-password"] != "<hidden>" {
+		t.Errorf("unexpected config: %v", managed.Config)
+	}
+	if _, ok := managed.Devices["gpu"]; !ok || len(managed.Devices) != 1 {
+		t.Errorf("unexpected devices: %v", managed.Devices)
+	}
+
+	if _, err := FilterManagedConfig("web", []byte("config: [")); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestMaskConfigSecrets(t *testing.T) {
+	data := []byte(`architecture: x86_64
+config:
+  user.app-password: c2VjcmV0
+  security.nesting: "true"
+`)
+
+	masked, err := MaskConfigSecrets(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output := string(masked)
+	if strings.Contains(output, "c2VjcmV0") || !strings.Contains(output, "user.app-password: <hidden>") {
+		t.Errorf("password should be masked:\n%s", output)
+	}
+	if strings.Index(output, "architecture") > strings.Index(output, "config") {
+		t.Errorf("key order should be preserved:\n%s", output)
+	}
+}
+
+func TestSplitConfigList(t *testing.T) {
+	if names := SplitConfigList(" web, ,dns "); strings.Join(names, "|") != "web|dns" {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if names := SplitConfigList(""); names != nil {
+		t.Errorf("expected nil, got %v", names)
+	}
+}
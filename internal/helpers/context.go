@@ -0,0 +1,206 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// ContextEnvVar overrides the configured context, e.g. in CI
+const ContextEnvVar = "LXC_GO_CLI_REMOTE"
+
+// Where the active context came from
+const (
+	ContextSourceEnv    = "environment"
+	ContextSourceConfig = "config"
+)
+
+// originalConfEnvVar remembers the user's lxc config directory while LXD_CONF
+// points at a context overlay, so nested invocations overlay the original
+const originalConfEnvVar = "LXC_GO_CLI_LXD_CONF"
+
+// contextOverlayPrefix names the overlay directories created next to the lxc config
+const contextOverlayPrefix = "lxc-go-cli-context-"
+
+// ResolveContext returns the remote commands should target and where that
+// choice came from; the environment variable wins over the config file
+func ResolveContext(settings *Settings) (remote, source string) {
+	if remote := strings.TrimSpace(os.Getenv(ContextEnvVar)); remote != "" {
+		return remote, ContextSourceEnv
+	}
+	if settings != nil && settings.Context != "" {
+		return settings.Context, ContextSourceConfig
+	}
+	return "", ""
+}
+
+// confEnvVar is the variable that points the client at its configuration directory
+func confEnvVar() string {
+	if CurrentDriver() == DriverIncus {
+		return "INCUS_CONF"
+	}
+	return "LXD_CONF"
+}
+
+// LXCConfigDir returns the client configuration directory, following
+// LXD_CONF and the snap layout like lxc itself does, or INCUS_CONF and
+// ~/.config/incus for Incus
+func LXCConfigDir() string {
+	if dir := os.Getenv(originalConfEnvVar); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv(confEnvVar()); dir != "" {
+		return dir
+	}
+	if CurrentDriver() == DriverIncus {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "incus")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		snapDir := filepath.Join(home, "snap", "lxd", "common", "config")
+		if _, err := os.Stat(snapDir); err == nil {
+			return snapDir
+		}
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lxc")
+}
+
+// ActivateContext makes every lxc command run by this process target remote.
+// lxc has no per-command remote switch, so LXD_CONF is pointed at an overlay
+// of the user's lxc config that differs only in its default remote; the
+// user's own default is left untouched.
+func ActivateContext(remote string) error {
+	confDir := LXCConfigDir()
+	if confDir == "" {
+		return fmt.Errorf("cannot locate the lxc client configuration")
+	}
+
+	config, err := readLXCConfig(confDir)
+	if err != nil {
+		return err
+	}
+	if !lxcConfigHasRemote(config, remote) {
+		return fmt.Errorf("remote '%s' is not configured (see 'lxc-go-cli remote list')", remote)
+	}
+	if lxcConfigDefault(config) == remote {
+		logger.Debug("Context '%s' is already the lxc default remote", remote)
+		return nil
+	}
+
+	overlay := filepath.Join(filepath.Dir(confDir), contextOverlayPrefix+remote)
+	if err := buildContextOverlay(confDir, overlay, setLXCConfigDefault(config, remote)); err != nil {
+		return fmt.Errorf("failed to prepare context '%s': %w", remote, err)
+	}
+
+	logger.Debug("Using context '%s' via %s=%s", remote, confEnvVar(), overlay)
+	os.Setenv(originalConfEnvVar, confDir)
+	return os.Setenv(confEnvVar(), overlay)
+}
+
+// readLXCConfig reads config.yml from an lxc config directory; lxc works
+// without one, so a missing file is an empty config
+func readLXCConfig(confDir string) (yaml.MapSlice, error) {
+	data, err := os.ReadFile(filepath.Join(confDir, "config.yml"))
+	if os.IsNotExist(err) {
+		return yaml.MapSlice{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lxc config: %w", err)
+	}
+	var config yaml.MapSlice
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse lxc config: %w", err)
+	}
+	return config, nil
+}
+
+func lxcConfigDefault(config yaml.MapSlice) string {
+	for _, item := range config {
+		if item.Key == "default-remote" {
+			return fmt.Sprint(item.Value)
+		}
+	}
+	return LocalRemote
+}
+
+func lxcConfigHasRemote(config yaml.MapSlice, remote string) bool {
+	if remote == LocalRemote {
+		return true
+	}
+	for _, item := range config {
+		if item.Key != "remotes" {
+			continue
+		}
+		remotes, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return false
+		}
+		for _, entry := range remotes {
+			if entry.Key == remote {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func setLXCConfigDefault(config yaml.MapSlice, remote string) yaml.MapSlice {
+	updated := make(yaml.MapSlice, 0, len(config)+1)
+	found := false
+	for _, item := range config {
+		if item.Key == "default-remote" {
+			item.Value = remote
+			found = true
+		}
+		updated = append(updated, item)
+	}
+	if !found {
+		updated = append(yaml.MapSlice{{Key: "default-remote", Value: remote}}, updated...)
+	}
+	return updated
+}
+
+// buildContextOverlay links everything from the lxc config directory into the
+// overlay, such as the client certificate and trusted server certificates,
+// and writes its own config.yml
+func buildContextOverlay(confDir, overlay string, config yaml.MapSlice) error {
+	if err := os.MkdirAll(overlay, 0700); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(confDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == "config.yml" {
+			continue
+		}
+		link := filepath.Join(overlay, entry.Name())
+		target := filepath.Join(confDir, entry.Name())
+		if existing, err := os.Readlink(link); err == nil && existing == target {
+			continue
+		}
+		os.RemoveAll(link)
+		if err := os.Symlink(target, link); err != nil {
+			return err
+		}
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(overlay, "config.yml"), data, 0600)
+}
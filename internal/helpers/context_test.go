@@ -0,0 +1,131 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLXCConfig = `default-remote: local
+remotes:
+  mylab:
+    addr: https://lab:8443
+    protocol: lxd
+    auth_type: tls
+aliases: {}
+`
+
+func TestSettingsRoundTrip(t *testing.T) {
+	SettingsDir = t.TempDir()
+	t.Cleanup(func() { SettingsDir = defaultSettingsDir() })
+
+	settings, err := LoadSettings()
+	if err != nil || settings.Context != "" {
+		t.Fatalf("Expected empty settings without a file, got %+v, %v", settings, err)
+	}
+
+	settings.Context = "mylab"
+	if err := SaveSettings(settings); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	loaded, err := LoadSettings()
+	if err != nil || loaded.Context != "mylab" {
+		t.Errorf("Expected context mylab, got %+v, %v", loaded, err)
+	}
+
+	if err := os.WriteFile(SettingsPath(), []byte("context: [broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSettings(); err == nil {
+		t.Error("Expected error for invalid YAML")
+	}
+}
+
+func TestResolveContext(t *testing.T) {
+	t.Setenv(ContextEnvVar, "")
+	if remote, source := ResolveContext(&Settings{}); remote != "" || source != "" {
+		t.Errorf("Expected no context, got %q from %q", remote, source)
+	}
+	if remote, source := ResolveContext(&Settings{Context: "mylab"}); remote != "mylab" || source != ContextSourceConfig {
+		t.Errorf("Expected mylab from config, got %q from %q", remote, source)
+	}
+
+	t.Setenv(ContextEnvVar, "staging")
+	if remote, source := ResolveContext(&Settings{Context: "mylab"}); remote != "staging" || source != ContextSourceEnv {
+		t.Errorf("Expected environment to win, got %q from %q", remote, source)
+	}
+}
+
+func TestActivateContext(t *testing.T) {
+	confDir := filepath.Join(t.TempDir(), "lxc")
+	if err := os.MkdirAll(filepath.Join(confDir, "servercerts"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(confDir, "config.yml"), []byte(testLXCConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(confDir, "client.crt"), []byte("cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LXD_CONF", confDir)
+	t.Setenv(originalConfEnvVar, "")
+
+	if err := ActivateContext("unknown"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected error for unknown remote, got %v", err)
+	}
+
+	// The lxc default needs no overlay
+	if err := ActivateContext("local"); err != nil {
+		t.Fatalf("ActivateContext(local) failed: %v", err)
+	}
+	if os.Getenv("LXD_CONF") != confDir {
+		t.Error("LXD_CONF should be unchanged for the default remote")
+	}
+
+	if err := ActivateContext("mylab"); err != nil {
+		t.Fatalf("ActivateContext failed: %v", err)
+	}
+	overlay := os.Getenv("LXD_CONF")
+	if overlay != filepath.Join(filepath.Dir(confDir), contextOverlayPrefix+"mylab") {
+		t.Fatalf("Unexpected LXD_CONF %q", overlay)
+	}
+
+	config, err := readLXCConfig(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lxcConfigDefault(config) != "mylab" || !lxcConfigHasRemote(config, "mylab") {
+		t.Errorf("Overlay config should default to mylab: %v", config)
+	}
+	if data, err := os.ReadFile(filepath.Join(overlay, "client.crt")); err != nil || string(data) != "cert" {
+		t.Errorf("Expected client certificate in overlay, got %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(overlay, "servercerts")); err != nil || !info.IsDir() {
+		t.Error("Expected servercerts in overlay")
+	}
+
+	// The user's own config keeps its default
+	original, _ := readLXCConfig(confDir)
+	if lxcConfigDefault(original) != "local" {
+		t.Error("The user's lxc config must not be modified")
+	}
+
+	// A nested invocation overlays the original config again
+	if err := ActivateContext("mylab"); err != nil {
+		t.Fatalf("Re-activating failed: %v", err)
+	}
+	if LXCConfigDir() != confDir {
+		t.Errorf("Expected the original config dir, got %q", LXCConfigDir())
+	}
+}
+
+func TestSetLXCConfigDefault(t *testing.T) {
+	config := setLXCConfigDefault(nil, "mylab")
+	if lxcConfigDefault(config) != "mylab" {
+		t.Errorf("Expected default-remote to be added, got %v", config)
+	}
+	if lxcConfigDefault(nil) != LocalRemote {
+		t.Error("Expected local when no default is configured")
+	}
+}
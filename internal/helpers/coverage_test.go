@@ -31,8 +31,8 @@ func TestNewMockLXC(t *testing.T) {
 		t.Error("Mock should have some default pools")
 	}
 
-	if mock.ExistingContainers == nil {
-		t.Error("ExistingContainers should be initialized")
+	if mock.Containers == nil {
+		t.Error("Containers should be initialized")
 	}
 
 	if mock.Calls == nil {
@@ -484,7 +484,7 @@ func TestMockLXC_Reset(t *testing.T) {
 	_ = mock.IsBtrfsAvailable(context.Background()) // Add a call
 
 	// Verify state exists
-	if len(mock.ExistingContainers) == 0 {
+	if len(mock.Containers) == 0 {
 		t.Error("Should have containers before reset")
 	}
 	if mock.GetCallCount("IsBtrfsAvailable") == 0 {
@@ -497,7 +497,7 @@ func TestMockLXC_Reset(t *testing.T) {
 	// Reset and verify
 	mock.Reset()
 
-	if len(mock.ExistingContainers) != 0 {
+	if len(mock.Containers) != 0 {
 		t.Error("Should clear containers after reset")
 	}
 	if mock.GetCallCount("IsBtrfsAvailable") != 0 {
@@ -760,10 +760,10 @@ func TestHelperFunctionsWithMockData(t *testing.T) {
 	err = StartContainer("test-container")
 	t.Logf("StartContainer error (expected): %v", err)
 
-	err = RestartContainer("test-container")
+	err = RestartContainer(context.Background(), "test-container")
 	t.Logf("RestartContainer error (expected): %v", err)
 
-	err = RunInContainer("test-container", "echo", "test")
+	err = RunInContainer(context.Background(), "test-container", "echo", "test")
 	t.Logf("RunInContainer error (expected): %v", err)
 
 	err = ConfigureContainerSecurity("test-container")
@@ -1044,3 +1044,166 @@ func TestMockLXC_GPUAndPasswordFunctions(t *testing.T) {
 		t.Error("Expected error when SetError is configured")
 	}
 }
+
+func TestMockLXC_StateMachine(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLXC()
+
+	if err := mock.CreateContainer(ctx, "c1", "ubuntu", "24.04", "amd64", "default-btrfs"); err != nil {
+		t.Fatalf("CreateContainer failed: %v", err)
+	}
+	if err := mock.ExpectState("c1", MockStateRunning); err != nil {
+		t.Error(err)
+	}
+
+	steps := []struct {
+		name    string
+		op      func() error
+		state   string
+		wantErr bool
+	}{
+		{"freeze running", func() error { return mock.FreezeContainer(ctx, "c1") }, MockStateFrozen, false},
+		{"freeze frozen", func() error { return mock.FreezeContainer(ctx, "c1") }, MockStateFrozen, true},
+		{"run in frozen", func() error { return mock.RunInContainer(ctx, "c1", "true") }, MockStateFrozen, true},
+		{"unfreeze frozen", func() error { return mock.UnfreezeContainer(ctx, "c1") }, MockStateRunning, false},
+		{"unfreeze running", func() error { return mock.UnfreezeContainer(ctx, "c1") }, MockStateRunning, true},
+		{"stop running", func() error { return mock.StopContainer(ctx, "c1") }, MockStateStopped, false},
+		{"stop stopped", func() error { return mock.StopContainer(ctx, "c1") }, MockStateStopped, true},
+		{"restart stopped", func() error { return mock.RestartContainer(ctx, "c1") }, MockStateStopped, true},
+		{"freeze stopped", func() error { return mock.FreezeContainer(ctx, "c1") }, MockStateStopped, true},
+		{"start stopped", func() error { return mock.StartContainer(ctx, "c1") }, MockStateRunning, false},
+		{"start running", func() error { return mock.StartContainer(ctx, "c1") }, MockStateRunning, false},
+	}
+	for _, step := range steps {
+		err := step.op()
+		if (err != nil) != step.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		if err := mock.ExpectState("c1", step.state); err != nil {
+			t.Errorf("%s: %v", step.name, err)
+		}
+	}
+
+	status, err := mock.GetContainerStatus(ctx, "c1")
+	if err != nil || status != MockStateRunning {
+		t.Errorf("GetContainerStatus = %q, %v", status, err)
+	}
+	if _, err := mock.GetContainerStatus(ctx, "missing"); err == nil {
+		t.Error("Expected error for non-existent container")
+	}
+
+	mock.AddContainerWithState("c2", MockStateCreated)
+	if err := mock.RunInContainer(ctx, "c2", "true"); err == nil {
+		t.Error("Expected error running a command in a created container")
+	}
+	if err := mock.StartContainer(ctx, "c2"); err != nil {
+		t.Errorf("Start created container failed: %v", err)
+	}
+}
+
+func TestMockLXC_ConfigDevicesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLXC()
+	mock.AddContainer("c1")
+
+	if err := mock.SetConfigValue(ctx, "c1", "user.key", "value"); err != nil {
+		t.Fatalf("SetConfigValue failed: %v", err)
+	}
+	if value, _ := mock.GetConfigValue(ctx, "c1", "user.key"); value != "value" {
+		t.Errorf("GetConfigValue = %q, want %q", value, "value")
+	}
+	if value, err := mock.GetConfigValue(ctx, "c1", "user.unset"); err != nil || value != "" {
+		t.Errorf("GetConfigValue for unset key = %q, %v", value, err)
+	}
+
+	if err := mock.ConfigureContainerSecurity(ctx, "c1"); err != nil {
+		t.Fatalf("ConfigureContainerSecurity failed: %v", err)
+	}
+	if value, _ := mock.GetConfigValue(ctx, "c1", "security.nesting"); value != "true" {
+		t.Errorf("security.nesting = %q, want true", value)
+	}
+
+	props := map[string]string{"listen": "tcp:0.0.0.0:8080", "connect": "tcp:127.0.0.1:80"}
+	if err := mock.AddDevice(ctx, "c1", "port8080", "proxy", props); err != nil {
+		t.Fatalf("AddDevice failed: %v", err)
+	}
+	if err := mock.AddDevice(ctx, "c1", "port8080", "proxy", props); err == nil {
+		t.Error("Expected error adding a duplicate device")
+	}
+	device := mock.Container("c1").Devices["port8080"]
+	if device["type"] != "proxy" || device["listen"] != "tcp:0.0.0.0:8080" {
+		t.Errorf("Unexpected device: %v", device)
+	}
+	if err := mock.RemoveDevice(ctx, "c1", "port8080"); err != nil {
+		t.Errorf("RemoveDevice failed: %v", err)
+	}
+	if err := mock.RemoveDevice(ctx, "c1", "port8080"); err == nil {
+		t.Error("Expected error removing a missing device")
+	}
+
+	if err := mock.CreateSnapshot(ctx, "c1", "snap0"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if err := mock.CreateSnapshot(ctx, "c1", "snap0"); err == nil {
+		t.Error("Expected error creating a duplicate snapshot")
+	}
+	if snapshots := mock.Container("c1").Snapshots; len(snapshots) != 1 || snapshots[0] != "snap0" {
+		t.Errorf("Snapshots = %v", snapshots)
+	}
+
+	for _, err := range []error{
+		mock.SetConfigValue(ctx, "missing", "k", "v"),
+		mock.AddDevice(ctx, "missing", "d", "disk", nil),
+		mock.CreateSnapshot(ctx, "missing", "s"),
+	} {
+		if err == nil {
+			t.Error("Expected error for non-existent container")
+		}
+	}
+
+	// Container returns a copy that does not alias the mock state
+	mock.Container("c1").Config["user.key"] = "changed"
+	if value, _ := mock.GetConfigValue(ctx, "c1", "user.key"); value != "value" {
+		t.Error("Container should return a copy of the mock state")
+	}
+}
+
+func TestMockLXC_OperationAssertions(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLXC()
+	mock.AddContainer("c1")
+	mock.AddContainer("c2")
+
+	_ = mock.RunInContainer(ctx, "c1", "apt-get", "update")
+	_ = mock.RunInContainer(ctx, "c2", "true")
+	_ = mock.CreateSnapshot(ctx, "c1", "before")
+	_ = mock.StopContainer(ctx, "c1")
+
+	if got := len(mock.OperationsFor("c1")); got != 3 {
+		t.Errorf("Expected 3 operations on c1, got %d", got)
+	}
+	if err := mock.ExpectOperations("c1", "RunInContainer", "StopContainer"); err != nil {
+		t.Errorf("ExpectOperations: %v", err)
+	}
+	if err := mock.ExpectOperations("c1", "StopContainer", "CreateSnapshot"); err == nil {
+		t.Error("Expected error for operations out of order")
+	}
+	if err := mock.ExpectOperations("c2", "StopContainer"); err == nil {
+		t.Error("Expected error for an operation on another container")
+	}
+
+	if !mock.RanCommand("c1", "apt-get", "update") {
+		t.Error("Expected apt-get update in the process table")
+	}
+	if !mock.RanCommand("c1", "apt-get") {
+		t.Error("Expected prefix match in the process table")
+	}
+	if mock.RanCommand("c1", "true") {
+		t.Error("Command ran in c2 should not appear in c1's process table")
+	}
+
+	mock.Reset()
+	if len(mock.Operations) != 0 {
+		t.Error("Should clear operations after reset")
+	}
+}
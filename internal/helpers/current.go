@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"os"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// CurrentContainerEnvVar overrides the saved current container, e.g. for one shell
+const CurrentContainerEnvVar = "LXC_GO_CLI_CONTAINER"
+
+// CurrentContainer is the container chosen with 'use'
+type CurrentContainer struct {
+	Name string `yaml:"name,omitempty"`
+	// Context is the context active when it was chosen; the container is
+	// only current while that context is
+	Context string `yaml:"context,omitempty"`
+	// Project is the project active when it was chosen; likewise the
+	// container is only current in that project
+	Project string `yaml:"project,omitempty"`
+}
+
+// ResolveCurrentContainer returns the container commands use when none is
+// named and where that choice came from; the environment variable wins over
+// the config file. Empty when none is set for the active context.
+func ResolveCurrentContainer(settings *Settings) (name, source string) {
+	if name := strings.TrimSpace(os.Getenv(CurrentContainerEnvVar)); name != "" {
+		return name, ContextSourceEnv
+	}
+	if settings == nil || settings.Current.Name == "" {
+		return "", ""
+	}
+	if remote, _ := ResolveContext(settings); remote != settings.Current.Context {
+		logger.Debug("Current container '%s' was chosen in another context; ignoring it", settings.Current.Name)
+		return "", ""
+	}
+	if ActiveProject() != settings.Current.Project {
+		logger.Debug("Current container '%s' was chosen in another project; ignoring it", settings.Current.Name)
+		return "", ""
+	}
+	return settings.Current.Name, ContextSourceConfig
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import "testing"
+
+func TestResolveCurrentContainer(t *testing.T) {
+	t.Setenv(CurrentContainerEnvVar, "")
+	t.Setenv(ContextEnvVar, "")
+
+	if name, source := ResolveCurrentContainer(&Settings{}); name != "" || source != "" {
+		t.Errorf("expected no current container, got %q (%s)", name, source)
+	}
+
+	settings := &Settings{Current: CurrentContainer{Name: "web"}}
+	if name, source := ResolveCurrentContainer(settings); name != "web" || source != ContextSourceConfig {
+		t.Errorf("expected web from config, got %q (%s)", name, source)
+	}
+
+	// A container chosen without a context does not apply on a remote
+	settings.Context = "mylab"
+	if name, _ := ResolveCurrentContainer(settings); name != "" {
+		t.Errorf("expected the container to be ignored in another context, got %q", name)
+	}
+	settings.Current.Context = "mylab"
+	if name, _ := ResolveCurrentContainer(settings); name != "web" {
+		t.Errorf("expected web in its own context, got %q", name)
+	}
+
+	// Nor in another project
+	useMockRunner(t)
+	UseProject("team")
+	t.Cleanup(func() { UseProject("") })
+	if name, _ := ResolveCurrentContainer(settings); name != "" {
+		t.Errorf("expected the container to be ignored in another project, got %q", name)
+	}
+	settings.Current.Project = "team"
+	if name, _ := ResolveCurrentContainer(settings); name != "web" {
+		t.Errorf("expected web in its own project, got %q", name)
+	}
+
+	t.Setenv(CurrentContainerEnvVar, "db")
+	if name, source := ResolveCurrentContainer(settings); name != "db" || source != ContextSourceEnv {
+		t.Errorf("expected db from the environment, got %q (%s)", name, source)
+	}
+}
@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of host devices that device attach passes into containers
+const (
+	DeviceKindUSB    = "usb"
+	DeviceKindSerial = "serial"
+)
+
+// passthroughTypes are the LXD device types that device list and detach handle
+var passthroughTypes = map[string]bool{"usb": true, "unix-char": true, "unix-block": true}
+
+var (
+	usbIDPattern      = regexp.MustCompile(`^[0-9a-fA-F]{4}$`)
+	deviceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// PassthroughDevice is a host device given to a container
+type PassthroughDevice struct {
+	Name       string
+	Type       string
+	Properties map[string]string
+}
+
+// NewUSBDevice describes the USB devices matching a vendor ID and, if given,
+// a product ID. LXD hotplugs matching devices as they are plugged in.
+func NewUSBDevice(name, vendorID, productID string) (*PassthroughDevice, error) {
+	if !usbIDPattern.MatchString(vendorID) {
+		return nil, UsageErrorf("invalid vendor ID '%s': expected 4 hex digits as shown by lsusb, e.g. 1d6b", vendorID)
+	}
+	if productID != "" && !usbIDPattern.MatchString(productID) {
+		return nil, UsageErrorf("invalid product ID '%s': expected 4 hex digits as shown by lsusb, e.g. 0002", productID)
+	}
+
+	props := map[string]string{"vendorid": strings.ToLower(vendorID), "mode": "0666"}
+	if productID != "" {
+		props["productid"] = strings.ToLower(productID)
+	}
+	if name == "" {
+		name = "usb-" + props["vendorid"]
+		if productID != "" {
+			name += "-" + props["productid"]
+		}
+	}
+	return newPassthroughDevice(name, "usb", props)
+}
+
+// NewSerialDevice describes a character device such as /dev/ttyUSB0. It is
+// not required to exist, so it is hotplugged whenever it appears on the host.
+func NewSerialDevice(name, path string) (*PassthroughDevice, error) {
+	if !strings.HasPrefix(filepath.Clean(path), "/dev/") {
+		return nil, UsageErrorf("invalid device path '%s': expected a device node under /dev, e.g. /dev/ttyUSB0", path)
+	}
+	path = filepath.Clean(path)
+	if name == "" {
+		name = "serial-" + filepath.Base(path)
+	}
+	return newPassthroughDevice(name, "unix-char", map[string]string{
+		"source":   path,
+		"path":     path,
+		"mode":     "0666",
+		"required": "false",
+	})
+}
+
+// newPassthroughDevice checks the device name
+func newPassthroughDevice(name, deviceType string, props map[string]string) (*PassthroughDevice, error) {
+	if !deviceNamePattern.MatchString(name) {
+		return nil, UsageErrorf("invalid device name '%s': use letters, digits, '.', '_' and '-'", name)
+	}
+	return &PassthroughDevice{Name: name, Type: deviceType, Properties: props}, nil
+}
+
+// Describe summarizes what the device passes through
+func (d PassthroughDevice) Describe() string {
+	if d.Type == "usb" {
+		description := "vendor " + d.Properties["vendorid"]
+		if product := d.Properties["productid"]; product != "" {
+			description += ", product " + product
+		}
+		return description
+	}
+	if source := d.Properties["source"]; source != "" {
+		return source
+	}
+	return d.Properties["path"]
+}
+
+// IsPassthroughType reports whether device list and detach handle a device type
+func IsPassthroughType(deviceType string) bool {
+	return passthroughTypes[deviceType]
+}
+
+// PassthroughDevices picks the USB and unix devices from a container's
+// devices, sorted by name
+func PassthroughDevices(devices map[string]map[string]string) []PassthroughDevice {
+	var result []PassthroughDevice
+	for name, props := range devices {
+		if IsPassthroughType(props["type"]) {
+			result = append(result, PassthroughDevice{Name: name, Type: props["type"], Properties: props})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// GetContainerDevices returns the devices configured on a container
+func GetContainerDevices(containerName string) (map[string]map[string]string, error) {
+	output, err := runOutput(context.Background(), "lxc", "config", "show", containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration of container '%s': %w", containerName, err)
+	}
+
+	var config ContainerConfig
+	if err := yaml.Unmarshal(output, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration of container '%s': %w", containerName, err)
+	}
+	return config.Devices, nil
+}
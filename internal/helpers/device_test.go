@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewUSBDevice(t *testing.T) {
+	device, err := NewUSBDevice("", "1D6B", "0002")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := &PassthroughDevice{Name: "usb-1d6b-0002", Type: "usb", Properties: map[string]string{"vendorid": "1d6b", "productid": "0002", "mode": "0666"}}
+	if !reflect.DeepEqual(device, want) {
+		t.Errorf("expected %+v, got %+v", want, device)
+	}
+	if device.Describe() != "vendor 1d6b, product 0002" {
+		t.Errorf("unexpected description %q", device.Describe())
+	}
+
+	if device, err := NewUSBDevice("dongle", "1d6b", ""); err != nil || device.Name != "dongle" || device.Properties["productid"] != "" {
+		t.Errorf("expected a named device for any product, got %+v (%v)", device, err)
+	}
+
+	tests := []struct {
+		name, vendor, product, want string
+	}{
+		{"", "1d6", "", "invalid vendor ID"},
+		{"", "1d6b", "xyz1", "invalid product ID"},
+		{"my dongle", "1d6b", "", "invalid device name"},
+	}
+	for _, tt := range tests {
+		if _, err := NewUSBDevice(tt.name, tt.vendor, tt.product); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: expected %q, got %v", tt, tt.want, err)
+		}
+	}
+}
+
+func TestNewSerialDevice(t *testing.T) {
+	device, err := NewSerialDevice("", "/dev/ttyUSB0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if device.Name != "serial-ttyUSB0" || device.Type != "unix-char" || device.Properties["required"] != "false" || device.Describe() != "/dev/ttyUSB0" {
+		t.Errorf("unexpected device %+v", device)
+	}
+
+	for _, path := range []string{"ttyUSB0", "/etc/passwd", "/dev/../etc/passwd"} {
+		if _, err := NewSerialDevice("", path); err == nil {
+			t.Errorf("%s: expected an invalid path error", path)
+		}
+	}
+}
+
+func TestPassthroughDevices(t *testing.T) {
+	devices := PassthroughDevices(map[string]map[string]string{
+		"serial-ttyUSB0": {"type": "unix-char", "source": "/dev/ttyUSB0"},
+		"root":           {"type": "disk", "path": "/"},
+		"kvm":            {"type": "unix-char", "path": "/dev/kvm"},
+		"dongle":         {"type": "usb", "vendorid": "1d6b"},
+		"web-8080-80":    {"type": "proxy"},
+	})
+	var names []string
+	for _, device := range devices {
+		names = append(names, device.Name)
+	}
+	if strings.Join(names, ",") != "dongle,kvm,serial-ttyUSB0" {
+		t.Errorf("expected the usb and unix devices sorted by name, got %v", names)
+	}
+	if devices[1].Describe() != "/dev/kvm" {
+		t.Errorf("expected the path when there is no source, got %q", devices[1].Describe())
+	}
+}
+
+func TestGetContainerDevices(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond("devices:\n  dongle:\n    type: usb\n    vendorid: 1d6b\n", nil, "lxc", "config", "show", "web")
+
+	devices, err := GetContainerDevices("web")
+	if err != nil || devices["dongle"]["vendorid"] != "1d6b" {
+		t.Errorf("unexpected devices %v (%v)", devices, err)
+	}
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Sections of a container comparison, in report order
+const (
+	DiffSectionConfig       = "config"
+	DiffSectionDevices      = "devices"
+	DiffSectionDocker       = "docker"
+	DiffSectionProvisioning = "provisioning"
+)
+
+// DiffSections lists the comparison sections in report order
+var DiffSections = []string{DiffSectionConfig, DiffSectionDevices, DiffSectionDocker, DiffSectionProvisioning}
+
+// provisioningKeyPrefix marks the config keys this tool records its own state in
+const provisioningKeyPrefix = "user.lxc-go-cli."
+
+// diffIgnoredKeys always differ between containers and would drown out real
+// differences: runtime state and creation times
+var diffIgnoredKeys = []string{ManagedCreatedKey}
+
+// ContainerFacts are the comparable settings of a container by section, each
+// a flat map of key to value
+type ContainerFacts map[string]map[string]string
+
+// ContainerFactsFromConfig collects the config keys, devices and provisioning
+// metadata of a container from its `lxc config show` output. Device names
+// starting with the container name are renamed to start with "<name>", so
+// the port devices of two containers compare equal. Secrets and volatile keys
+// are left out.
+func ContainerFactsFromConfig(containerName string, yamlData []byte) (ContainerFacts, error) {
+	var config ContainerConfig
+	if err := yaml.Unmarshal(yamlData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration of container '%s': %w", containerName, err)
+	}
+
+	facts := ContainerFacts{
+		DiffSectionConfig:       {},
+		DiffSectionDevices:      {},
+		DiffSectionProvisioning: {},
+	}
+	for key, value := range config.Config {
+		switch {
+		case strings.HasPrefix(key, "volatile."), isSecretConfigKey(key), slices.Contains(diffIgnoredKeys, key):
+			continue
+		case strings.HasPrefix(key, provisioningKeyPrefix):
+			facts[DiffSectionProvisioning][key] = value
+		default:
+			facts[DiffSectionConfig][key] = value
+		}
+	}
+	for name, device := range config.Devices {
+		if strings.HasPrefix(name, containerName+"-") {
+			name = "<name>" + strings.TrimPrefix(name, containerName)
+		}
+		for key, value := range device {
+			facts[DiffSectionDevices][name+"."+key] = value
+		}
+	}
+	return facts, nil
+}
+
+// FactDiff is a key whose value differs between two containers; an empty
+// side means the key is missing there
+type FactDiff struct {
+	Section string
+	Key     string
+	A       string
+	B       string
+	// InA and InB tell a missing key from an empty value
+	InA bool
+	InB bool
+}
+
+// DiffContainerFacts returns the differing keys of two containers, by
+// section in report order and then by key
+func DiffContainerFacts(a, b ContainerFacts) []FactDiff {
+	var diffs []FactDiff
+	for _, section := range DiffSections {
+		keys := make(map[string]bool)
+		for key := range a[section] {
+			keys[key] = true
+		}
+		for key := range b[section] {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			valueA, inA := a[section][key]
+			valueB, inB := b[section][key]
+			if inA == inB && valueA == valueB {
+				continue
+			}
+			diffs = append(diffs, FactDiff{Section: section, Key: key, A: valueA, B: valueB, InA: inA, InB: inB})
+		}
+	}
+	return diffs
+}
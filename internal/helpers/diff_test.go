@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainerFactsFromConfig(t *testing.T) {
+	config := `config:
+  image.release: noble
+  limits.memory: 2GiB
+  security.nesting: "true"
+  user.app-password: secret
+  user.lxc-go-cli.created: "2025-01-01T00:00:00Z"
+  user.lxc-go-cli.provisioned-steps: launch,security
+  volatile.eth0.hwaddr: 00:16:3e:00:00:01
+devices:
+  web-8080-80-tcp:
+    type: proxy
+    listen: tcp:0.0.0.0:8080
+  root:
+    type: disk
+    path: /
+`
+	facts, err := ContainerFactsFromConfig("web", []byte(config))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := ContainerFacts{
+		DiffSectionConfig:       {"image.release": "noble", "limits.memory": "2GiB", "security.nesting": "true"},
+		DiffSectionProvisioning: {"user.lxc-go-cli.provisioned-steps": "launch,security"},
+		DiffSectionDevices: {
+			"<name>-8080-80-tcp.type":   "proxy",
+			"<name>-8080-80-tcp.listen": "tcp:0.0.0.0:8080",
+			"root.type":                 "disk",
+			"root.path":                 "/",
+		},
+	}
+	if !reflect.DeepEqual(facts, want) {
+		t.Errorf("expected %v, got %v", want, facts)
+	}
+
+	if _, err := ContainerFactsFromConfig("web", []byte("config: [")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestDiffContainerFacts(t *testing.T) {
+	a := ContainerFacts{
+		DiffSectionConfig: {"limits.memory": "2GiB", "security.nesting": "true"},
+		DiffSectionDocker: {"docker-ce": "5:27.0.1"},
+	}
+	b := ContainerFacts{
+		DiffSectionConfig:  {"limits.memory": "4GiB", "security.nesting": "true", "limits.cpu": ""},
+		DiffSectionDocker:  {"docker-ce": "5:27.0.1"},
+		DiffSectionDevices: {"gpu.type": "gpu"},
+	}
+	want := []FactDiff{
+		{Section: DiffSectionConfig, Key: "limits.cpu", InB: true},
+		{Section: DiffSectionConfig, Key: "limits.memory", A: "2GiB", B: "4GiB", InA: true, InB: true},
+		{Section: DiffSectionDevices, Key: "gpu.type", B: "gpu", InB: true},
+	}
+	if got := DiffContainerFacts(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := DiffContainerFacts(a, a); len(got) != 0 {
+		t.Errorf("expected no differences, got %+v", got)
+	}
+}
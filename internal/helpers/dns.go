@@ -0,0 +1,297 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Host resolvers 'dns setup' can forward a bridge's domain from
+const (
+	DNSResolverResolved = "systemd-resolved"
+	DNSResolverDnsmasq  = "dnsmasq"
+)
+
+// DefaultBridgeDomain is the DNS domain LXD gives a bridge without dns.domain
+const DefaultBridgeDomain = "lxd"
+
+// Where 'dns setup' writes its configuration; tests point them elsewhere
+var (
+	SystemdUnitDir = "/etc/systemd/system"
+	DnsmasqConfDir = "/etc/dnsmasq.d"
+)
+
+// BridgeDNS is the DNS server LXD runs on a bridge network
+type BridgeDNS struct {
+	Network string
+	// Address is the bridge's IPv4 address, where LXD's dnsmasq listens
+	Address string
+	Domain  string
+}
+
+// DNSCheck is the outcome of resolving a container by its bridge name
+type DNSCheck struct {
+	Network string
+	OK      bool
+	// Warning marks a check that could not be made rather than one that failed
+	Warning bool
+	Message string
+	Fix     string
+}
+
+// GetBridgeDNS reads the address and domain of a bridge's DNS server
+func GetBridgeDNS(ctx context.Context, network string) (BridgeDNS, error) {
+	get := func(key string) (string, error) {
+		output, err := runOutput(ctx, "lxc", "network", "get", network, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s of network '%s': %w", key, network, err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	mode, err := get("dns.mode")
+	if err != nil {
+		return BridgeDNS{}, err
+	}
+	if mode == "none" {
+		return BridgeDNS{}, fmt.Errorf("network '%s' has DNS turned off (dns.mode=none); turn it on with 'lxc network set %s dns.mode=managed'", network, network)
+	}
+	address, err := get("ipv4.address")
+	if err != nil {
+		return BridgeDNS{}, err
+	}
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil {
+		return BridgeDNS{}, fmt.Errorf("network '%s' has no IPv4 address for its DNS server (ipv4.address=%s)", network, valueOr(address, "unset"))
+	}
+	domain, err := get("dns.domain")
+	if err != nil {
+		return BridgeDNS{}, err
+	}
+	return BridgeDNS{Network: network, Address: ip.String(), Domain: valueOr(domain, DefaultBridgeDomain)}, nil
+}
+
+// DetectDNSResolver returns the host resolver that is running:
+// systemd-resolved, or else dnsmasq
+func DetectDNSResolver(ctx context.Context) (string, error) {
+	for _, resolver := range []string{DNSResolverResolved, DNSResolverDnsmasq} {
+		if err := Runner().Run(ctx, "systemctl", "is-active", "--quiet", resolver); err == nil {
+			return resolver, nil
+		}
+	}
+	return "", fmt.Errorf("neither systemd-resolved nor dnsmasq is running on the host")
+}
+
+// dnsUnitName is the systemd unit that points systemd-resolved at a bridge
+func dnsUnitName(network string) string {
+	return "lxc-go-cli-dns-" + network + ".service"
+}
+
+// dnsUnitPath is where the unit of a bridge is written
+func dnsUnitPath(network string) string {
+	return filepath.Join(SystemdUnitDir, dnsUnitName(network))
+}
+
+// dnsmasqConfPath is where the dnsmasq forwarding rule of a bridge is written
+func dnsmasqConfPath(network string) string {
+	return filepath.Join(DnsmasqConfDir, "lxc-go-cli-"+network+".conf")
+}
+
+// ResolvedUnit returns a unit that sends queries for the bridge's domain to
+// its DNS server while the bridge exists; resolvectl settings alone are lost
+// whenever the bridge is recreated
+func ResolvedUnit(bridge BridgeDNS) string {
+	device := "sys-subsystem-net-devices-" + bridge.Network + ".device"
+	return fmt.Sprintf(`# Written by lxc-go-cli dns setup
+[Unit]
+Description=Resolve .%[3]s names through LXD's DNS on %[1]s
+BindsTo=%[4]s
+After=%[4]s
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/resolvectl dns %[1]s %[2]s
+ExecStart=/usr/bin/resolvectl domain %[1]s ~%[3]s
+ExecStopPost=/usr/bin/resolvectl revert %[1]s
+
+[Install]
+WantedBy=%[4]s
+`, bridge.Network, bridge.Address, bridge.Domain, device)
+}
+
+// DnsmasqConfig returns a dnsmasq rule that forwards the bridge's domain to
+// its DNS server
+func DnsmasqConfig(bridge BridgeDNS) string {
+	return fmt.Sprintf("# Written by lxc-go-cli dns setup\nserver=/%s/%s\n", bridge.Domain, bridge.Address)
+}
+
+// SetupBridgeDNS makes the host resolve <container>.<domain> through the
+// bridge's DNS server with the given resolver
+func SetupBridgeDNS(ctx context.Context, bridge BridgeDNS, resolver string) error {
+	switch resolver {
+	case DNSResolverResolved:
+		unit := dnsUnitName(bridge.Network)
+		if err := writeRootFile(ctx, dnsUnitPath(bridge.Network), ResolvedUnit(bridge)); err != nil {
+			return err
+		}
+		for _, argv := range [][]string{
+			{"systemctl", "daemon-reload"},
+			{"systemctl", "enable", unit},
+			{"systemctl", "restart", unit},
+		} {
+			if err := runDNSCommand(ctx, argv...); err != nil {
+				return err
+			}
+		}
+	case DNSResolverDnsmasq:
+		if err := writeRootFile(ctx, dnsmasqConfPath(bridge.Network), DnsmasqConfig(bridge)); err != nil {
+			return err
+		}
+		if err := runDNSCommand(ctx, "systemctl", "restart", "dnsmasq"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported resolver '%s': must be %s or %s", resolver, DNSResolverResolved, DNSResolverDnsmasq)
+	}
+	logger.Info("Names under .%s now resolve through %s at %s", bridge.Domain, bridge.Network, bridge.Address)
+	return nil
+}
+
+// ConfiguredBridgeDNS returns the networks 'dns setup' has configured, each
+// with its resolver
+func ConfiguredBridgeDNS() map[string]string {
+	configured := make(map[string]string)
+	units, _ := filepath.Glob(filepath.Join(SystemdUnitDir, "lxc-go-cli-dns-*.service"))
+	for _, unit := range units {
+		network := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(unit), "lxc-go-cli-dns-"), ".service")
+		configured[network] = DNSResolverResolved
+	}
+	confs, _ := filepath.Glob(filepath.Join(DnsmasqConfDir, "lxc-go-cli-*.conf"))
+	for _, conf := range confs {
+		network := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(conf), "lxc-go-cli-"), ".conf")
+		configured[network] = DNSResolverDnsmasq
+	}
+	return configured
+}
+
+// TeardownBridgeDNS removes what 'dns setup' configured for a network.
+// It returns false when there was nothing to remove.
+func TeardownBridgeDNS(ctx context.Context, network string) (bool, error) {
+	removed := false
+	if _, err := os.Stat(dnsUnitPath(network)); err == nil {
+		unit := dnsUnitName(network)
+		// Stopping the unit reverts the bridge's resolvectl settings
+		for _, argv := range [][]string{
+			{"systemctl", "disable", "--now", unit},
+			{"rm", "-f", dnsUnitPath(network)},
+			{"systemctl", "daemon-reload"},
+		} {
+			if err := runDNSCommand(ctx, argv...); err != nil {
+				return removed, err
+			}
+		}
+		removed = true
+	}
+	if _, err := os.Stat(dnsmasqConfPath(network)); err == nil {
+		if err := runDNSCommand(ctx, "rm", "-f", dnsmasqConfPath(network)); err != nil {
+			return removed, err
+		}
+		if err := runDNSCommand(ctx, "systemctl", "restart", "dnsmasq"); err != nil {
+			return removed, err
+		}
+		removed = true
+	}
+	return removed, nil
+}
+
+// CheckBridgeDNS resolves a running container on the network by its bridge
+// name from the host, using states to pick the container
+func CheckBridgeDNS(ctx context.Context, network string, states []ContainerState) DNSCheck {
+	check := DNSCheck{Network: network}
+	bridge, err := GetBridgeDNS(ctx, network)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+	container, ok := runningContainerOn(states, network)
+	if !ok {
+		check.Warning = true
+		check.Message = fmt.Sprintf("no running container with an address on %s to resolve", network)
+		return check
+	}
+
+	host := container.Name + "." + bridge.Domain
+	output, err := runOutput(ctx, "getent", "hosts", host)
+	if err != nil {
+		check.Message = fmt.Sprintf("%s does not resolve from the host", host)
+		check.Fix = "lxc-go-cli dns setup --network " + network
+		return check
+	}
+	var resolved []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			resolved = append(resolved, fields[0])
+		}
+	}
+	if !slices.Contains(resolved, container.IPv4) && !slices.ContainsFunc(container.IPv6Addresses, func(ip string) bool { return slices.Contains(resolved, ip) }) {
+		check.Message = fmt.Sprintf("%s resolves to %s, not to the container's address %s", host, strings.Join(resolved, ", "), container.IPv4)
+		check.Fix = fmt.Sprintf("look for a stale %s entry in /etc/hosts", host)
+		return check
+	}
+	check.OK = true
+	check.Message = fmt.Sprintf("%s resolves to %s", host, strings.Join(resolved, ", "))
+	return check
+}
+
+// runningContainerOn returns a running container with an IPv4 address and a
+// NIC on the network
+func runningContainerOn(states []ContainerState, network string) (ContainerState, bool) {
+	for _, state := range states {
+		if state.Status != "Running" || state.IPv4 == "" {
+			continue
+		}
+		for _, device := range state.Devices {
+			if device["type"] == "nic" && (device["network"] == network || device["parent"] == network) {
+				return state, true
+			}
+		}
+	}
+	return ContainerState{}, false
+}
+
+// writeRootFile writes a host file that needs root, through tee so it also
+// works with --sudo
+func writeRootFile(ctx context.Context, path, content string) error {
+	argv, err := privilegedCommand(ctx, "tee", path)
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	streams := Streams{Stdin: strings.NewReader(content), Stderr: &stderr}
+	if err := Runner().RunStreaming(ctx, streams, argv[0], argv[1:]...); err != nil {
+		return withPrivilegeHint(fmt.Errorf("failed to write %s: %w (output: %s)", path, err, strings.TrimSpace(stderr.String())), stderr.String())
+	}
+	return nil
+}
+
+// runDNSCommand runs a host command that needs root
+func runDNSCommand(ctx context.Context, args ...string) error {
+	argv, err := privilegedCommand(ctx, args...)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Running: %s", strings.Join(argv, " "))
+
+	output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+	if err != nil {
+		return withPrivilegeHint(fmt.Errorf("%s failed: %w (output: %s)", strings.Join(args, " "), err, strings.TrimSpace(string(output))), string(output))
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useDNSConfDirs points the dns setup directories at a temporary directory
+func useDNSConfDirs(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	oldUnits, oldDnsmasq := SystemdUnitDir, DnsmasqConfDir
+	SystemdUnitDir, DnsmasqConfDir = filepath.Join(dir, "systemd"), filepath.Join(dir, "dnsmasq.d")
+	t.Cleanup(func() { SystemdUnitDir, DnsmasqConfDir = oldUnits, oldDnsmasq })
+	os.MkdirAll(SystemdUnitDir, 0755)
+	os.MkdirAll(DnsmasqConfDir, 0755)
+}
+
+func TestGetBridgeDNS(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("managed\n", nil, "lxc", "network", "get", "lxdbr0", "dns.mode")
+	runner.Respond("10.0.0.1/24\n", nil, "lxc", "network", "get", "lxdbr0", "ipv4.address")
+
+	bridge, err := GetBridgeDNS(context.Background(), "lxdbr0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bridge != (BridgeDNS{Network: "lxdbr0", Address: "10.0.0.1", Domain: "lxd"}) {
+		t.Errorf("unexpected bridge %+v", bridge)
+	}
+
+	runner.Respond("none\n", nil, "lxc", "network", "get", "lxdbr0", "ipv4.address")
+	if _, err := GetBridgeDNS(context.Background(), "lxdbr0"); err == nil || !strings.Contains(err.Error(), "no IPv4 address") {
+		t.Errorf("expected a bridge without IPv4 to be rejected, got %v", err)
+	}
+	runner.Respond("none\n", nil, "lxc", "network", "get", "lxdbr0", "dns.mode")
+	if _, err := GetBridgeDNS(context.Background(), "lxdbr0"); err == nil || !strings.Contains(err.Error(), "dns.mode=none") {
+		t.Errorf("expected a bridge without DNS to be rejected, got %v", err)
+	}
+}
+
+func TestDetectDNSResolver(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("", &MockExitError{Code: 3}, "systemctl", "is-active", "--quiet", DNSResolverResolved)
+	if resolver, err := DetectDNSResolver(context.Background()); err != nil || resolver != DNSResolverDnsmasq {
+		t.Errorf("expected dnsmasq, got %q (%v)", resolver, err)
+	}
+	runner.Respond("", &MockExitError{Code: 3}, "systemctl", "is-active", "--quiet", DNSResolverDnsmasq)
+	if _, err := DetectDNSResolver(context.Background()); err == nil {
+		t.Error("expected an error without a running resolver")
+	}
+}
+
+func TestSetupBridgeDNS(t *testing.T) {
+	useDNSConfDirs(t)
+	bridge := BridgeDNS{Network: "lxdbr0", Address: "10.0.0.1", Domain: "lxd"}
+
+	runner := useMockRunner(t)
+	if err := SetupBridgeDNS(context.Background(), bridge, DNSResolverResolved); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	unit := filepath.Join(SystemdUnitDir, "lxc-go-cli-dns-lxdbr0.service")
+	err := runner.ExpectCommands(
+		[]string{"tee", unit},
+		[]string{"systemctl", "daemon-reload"},
+		[]string{"systemctl", "enable", "lxc-go-cli-dns-lxdbr0.service"},
+		[]string{"systemctl", "restart", "lxc-go-cli-dns-lxdbr0.service"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	data, _ := io.ReadAll(runner.Stdins[0])
+	for _, want := range []string{"ExecStart=/usr/bin/resolvectl dns lxdbr0 10.0.0.1", "resolvectl domain lxdbr0 ~lxd", "BindsTo=sys-subsystem-net-devices-lxdbr0.device"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the unit, got:\n%s", want, data)
+		}
+	}
+
+	runner = useMockRunner(t)
+	if err := SetupBridgeDNS(context.Background(), bridge, DNSResolverDnsmasq); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !runner.Ran("systemctl", "restart", "dnsmasq") {
+		t.Errorf("expected dnsmasq to be restarted, got %v", runner.Commands)
+	}
+	if data, _ := io.ReadAll(runner.Stdins[0]); !strings.Contains(string(data), "server=/lxd/10.0.0.1\n") {
+		t.Errorf("unexpected dnsmasq rule:\n%s", data)
+	}
+
+	if err := SetupBridgeDNS(context.Background(), bridge, "bind"); err == nil {
+		t.Error("expected an unsupported resolver to be rejected")
+	}
+}
+
+func TestTeardownBridgeDNS(t *testing.T) {
+	useDNSConfDirs(t)
+	runner := useMockRunner(t)
+	if removed, err := TeardownBridgeDNS(context.Background(), "lxdbr0"); err != nil || removed || len(runner.Commands) != 0 {
+		t.Errorf("expected nothing to remove, got %v (%v, %v)", removed, err, runner.Commands)
+	}
+
+	unit := filepath.Join(SystemdUnitDir, "lxc-go-cli-dns-lxdbr0.service")
+	os.WriteFile(unit, []byte("[Unit]\n"), 0644)
+	os.WriteFile(filepath.Join(DnsmasqConfDir, "lxc-go-cli-lxdbr1.conf"), []byte("server=/lxd/10.0.1.1\n"), 0644)
+	configured := ConfiguredBridgeDNS()
+	if len(configured) != 2 || configured["lxdbr0"] != DNSResolverResolved || configured["lxdbr1"] != DNSResolverDnsmasq {
+		t.Errorf("unexpected configured networks %v", configured)
+	}
+
+	removed, err := TeardownBridgeDNS(context.Background(), "lxdbr0")
+	if err != nil || !removed {
+		t.Fatalf("expected the unit to be removed, got %v (%v)", removed, err)
+	}
+	err = runner.ExpectCommands(
+		[]string{"systemctl", "disable", "--now", "lxc-go-cli-dns-lxdbr0.service"},
+		[]string{"rm", "-f", unit},
+		[]string{"systemctl", "daemon-reload"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckBridgeDNS(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("managed\n", nil, "lxc", "network", "get", "lxdbr0", "dns.mode")
+	runner.Respond("10.0.0.1/24\n", nil, "lxc", "network", "get", "lxdbr0", "ipv4.address")
+	nic := map[string]map[string]string{"eth0": {"type": "nic", "network": "lxdbr0"}}
+	states := []ContainerState{
+		{Name: "old", Status: "Stopped", Devices: nic},
+		{Name: "web", Status: "Running", IPv4: "10.0.0.2", Devices: nic},
+	}
+
+	runner.Respond("10.0.0.2       web.lxd\n", nil, "getent", "hosts", "web.lxd")
+	if check := CheckBridgeDNS(context.Background(), "lxdbr0", states); !check.OK || !strings.Contains(check.Message, "web.lxd resolves to 10.0.0.2") {
+		t.Errorf("expected resolution to pass, got %+v", check)
+	}
+
+	runner.Respond("10.0.0.9       web.lxd\n", nil, "getent", "hosts", "web.lxd")
+	if check := CheckBridgeDNS(context.Background(), "lxdbr0", states); check.OK || !strings.Contains(check.Message, "not to the container's address") {
+		t.Errorf("expected a wrong address to fail, got %+v", check)
+	}
+
+	runner.Respond("", &MockExitError{Code: 2}, "getent", "hosts", "web.lxd")
+	if check := CheckBridgeDNS(context.Background(), "lxdbr0", states); check.OK || check.Warning || !strings.Contains(check.Fix, "dns setup") {
+		t.Errorf("expected unresolved names to fail, got %+v", check)
+	}
+
+	if check := CheckBridgeDNS(context.Background(), "lxdbr0", states[:1]); !check.Warning {
+		t.Errorf("expected a warning without a running container, got %+v", check)
+	}
+}
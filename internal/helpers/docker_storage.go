@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Docker storage drivers create can configure. Auto picks one from the
+// container's root filesystem.
+const (
+	DockerStorageAuto          = "auto"
+	DockerStorageOverlay2      = "overlay2"
+	DockerStorageFuseOverlayfs = "fuse-overlayfs"
+	DockerStorageBtrfs         = "btrfs"
+	DockerStorageVFS           = "vfs"
+)
+
+// DockerStorageDrivers lists the valid --docker-storage-driver values
+var DockerStorageDrivers = []string{DockerStorageAuto, DockerStorageOverlay2, DockerStorageFuseOverlayfs, DockerStorageBtrfs, DockerStorageVFS}
+
+const (
+	// DockerVolumeDevice is the disk device that mounts the Docker volume
+	DockerVolumeDevice = "docker"
+	// DockerDataDir is where Docker keeps images and containers
+	DockerDataDir = "/var/lib/docker"
+	// dockerDaemonConfig is Docker's daemon configuration file
+	dockerDaemonConfig = "/etc/docker/daemon.json"
+)
+
+// ValidateDockerStorageDriver checks a --docker-storage-driver value
+func ValidateDockerStorageDriver(driver string) error {
+	for _, valid := range DockerStorageDrivers {
+		if driver == valid {
+			return nil
+		}
+	}
+	return UsageErrorf("invalid Docker storage driver '%s' (valid drivers: %s)", driver, strings.Join(DockerStorageDrivers, ", "))
+}
+
+// DockerVolumeName returns the name of the custom volume holding a container's Docker data
+func DockerVolumeName(containerName string) string {
+	return containerName + "-docker"
+}
+
+// BtrfsDataDirCommand returns a command that succeeds when the directory
+// Docker stores its data in is on Btrfs
+func BtrfsDataDirCommand() []string {
+	// /var/lib/docker does not exist before Docker is installed
+	return []string{"sh", "-c", `[ "$(stat -f -c %T /var/lib/docker 2>/dev/null || stat -f -c %T /var/lib)" = btrfs ]`}
+}
+
+// DockerStorageDriverCommand returns the command that makes Docker use a
+// storage driver; it must run before Docker first starts
+func DockerStorageDriverCommand(driver string) []string {
+	script := fmt.Sprintf(`mkdir -p /etc/docker && printf '{\n  "storage-driver": "%s"\n}\n' > %s`, driver, dockerDaemonConfig)
+	return []string{"sh", "-c", script}
+}
+
+// AttachDockerVolume mounts a custom volume from pool at /var/lib/docker, so
+// Docker's layers live on that pool's filesystem instead of the root disk.
+// A container that already has the device is left alone, so it can be
+// re-run. A volume left behind by another container of the same name is
+// refused rather than reused, so its images and data are not inherited.
+func AttachDockerVolume(containerName, pool string) error {
+	ctx := context.Background()
+	volume := DockerVolumeName(containerName)
+
+	config, err := readContainerConfig(ctx, containerName)
+	if err != nil {
+		return err
+	}
+	if _, exists := config.Devices[DockerVolumeDevice]; exists {
+		logger.Debug("Container '%s' already has a '%s' device", containerName, DockerVolumeDevice)
+		return nil
+	}
+
+	if _, err := Runner().RunWithOutput(ctx, "lxc", "storage", "volume", "show", pool, volume); err == nil {
+		return ConflictErrorf("volume '%s' already exists in pool '%s'; delete it with 'lxc storage volume delete %s %s' or choose another container name", volume, pool, pool, volume)
+	}
+	if output, err := Runner().RunWithOutput(ctx, "lxc", "storage", "volume", "create", pool, volume); err != nil {
+		return fmt.Errorf("failed to create volume '%s' in pool '%s': %w (output: %s)", volume, pool, err, strings.TrimSpace(string(output)))
+	}
+
+	err = AddContainerDevice(containerName, DockerVolumeDevice, "disk", map[string]string{
+		"pool":   pool,
+		"source": volume,
+		"path":   DockerDataDir,
+	})
+	if err != nil {
+		if deleteErr := DeleteStorageVolume(ctx, pool, volume); deleteErr != nil {
+			logger.Warn("Failed to delete volume '%s' from pool '%s': %v", volume, pool, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// dockerVolumeOf returns the pool and name of the custom volume a container's
+// Docker data is on, if it has one
+func dockerVolumeOf(ctx context.Context, containerName string) (pool, volume string, ok bool) {
+	config, err := readContainerConfig(ctx, containerName)
+	if err != nil {
+		return "", "", false
+	}
+	return dockerVolumeIn(config)
+}
+
+// dockerVolumeIn returns the pool and custom volume of the docker device in
+// a container config
+func dockerVolumeIn(config ContainerConfig) (pool, volume string, ok bool) {
+	device, exists := config.Devices[DockerVolumeDevice]
+	if !exists || device["type"] != "disk" || device["pool"] == "" || device["source"] == "" {
+		return "", "", false
+	}
+	return device["pool"], device["source"], true
+}
+
+// DeleteStorageVolume deletes a custom volume from a storage pool
+func DeleteStorageVolume(ctx context.Context, pool, volume string) error {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "storage", "volume", "delete", pool, volume)
+	if err != nil {
+		return fmt.Errorf("failed to delete volume '%s' from pool '%s': %w (output: %s)", volume, pool, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
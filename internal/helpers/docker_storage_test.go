@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateDockerStorageDriver(t *testing.T) {
+	for _, driver := range DockerStorageDrivers {
+		if err := ValidateDockerStorageDriver(driver); err != nil {
+			t.Errorf("ValidateDockerStorageDriver(%q) failed: %v", driver, err)
+		}
+	}
+	if err := ValidateDockerStorageDriver("aufs"); err == nil {
+		t.Error("expected aufs to be rejected")
+	}
+}
+
+func TestDockerStorageDriverCommand(t *testing.T) {
+	command := strings.Join(DockerStorageDriverCommand(DockerStorageFuseOverlayfs), " ")
+	if !strings.Contains(command, `"storage-driver": "fuse-overlayfs"`) || !strings.Contains(command, "/etc/docker/daemon.json") {
+		t.Errorf("unexpected command %q", command)
+	}
+}
+
+func TestAttachDockerVolume(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond("", fmt.Errorf("not found"), "lxc", "storage", "volume", "show", "lvm", "web-docker")
+	mock.Respond("devices:\n  root:\n    path: /\n    type: disk\n", nil, "lxc", "config", "show", "web")
+
+	if err := AttachDockerVolume("web", "lvm"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var ran []string
+	for _, argv := range mock.Commands {
+		ran = append(ran, strings.Join(argv, " "))
+	}
+	all := strings.Join(ran, "\n")
+	for _, expected := range []string{
+		"lxc storage volume create lvm web-docker",
+		"lxc config device add web docker disk path=/var/lib/docker pool=lvm source=web-docker",
+	} {
+		if !strings.Contains(all, expected) {
+			t.Errorf("expected %q to run, got:\n%s", expected, all)
+		}
+	}
+
+	// An existing device is left alone
+	mock = useMockRunner(t)
+	mock.Respond("devices:\n  docker:\n    path: /var/lib/docker\n    type: disk\n", nil, "lxc", "config", "show", "web")
+	if err := AttachDockerVolume("web", "lvm"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, argv := range mock.Commands {
+		if command := strings.Join(argv, " "); strings.Contains(command, "create") || strings.Contains(command, "device add") {
+			t.Errorf("unexpected command %q", command)
+		}
+	}
+}
+
+func TestAttachDockerVolumeRefusesLeftover(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond("devices:\n  root:\n    path: /\n    type: disk\n", nil, "lxc", "config", "show", "web")
+	mock.Respond("name: web-docker\n", nil, "lxc", "storage", "volume", "show", "lvm", "web-docker")
+
+	err := AttachDockerVolume("web", "lvm")
+	if !errors.Is(err, ErrConflict) || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a conflict for the leftover volume, got %v", err)
+	}
+	for _, argv := range mock.Commands {
+		if strings.Contains(strings.Join(argv, " "), "device add") {
+			t.Errorf("expected the leftover volume not to be attached, ran %q", strings.Join(argv, " "))
+		}
+	}
+}
+
+func TestAttachDockerVolumeDeletesVolumeOnFailure(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond("devices: {}\n", nil, "lxc", "config", "show", "web")
+	mock.Respond("", fmt.Errorf("not found"), "lxc", "storage", "volume", "show")
+	mock.Respond("", fmt.Errorf("device add failed"), "lxc", "config", "device", "add")
+
+	if err := AttachDockerVolume("web", "lvm"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !mock.Ran("lxc", "storage", "volume", "delete", "lvm", "web-docker") {
+		t.Error("expected the new volume to be deleted")
+	}
+}
+
+func TestDeleteContainerDeletesDockerVolume(t *testing.T) {
+	useHostDirs(t)
+	mock := useMockRunner(t)
+	mock.Respond("devices:\n  docker:\n    path: /var/lib/docker\n    pool: lvm\n    source: web-docker\n    type: disk\n", nil,
+		"lxc", "config", "show", "trash-20250301-web")
+
+	if err := DeleteContainer("trash-20250301-web", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := mock.ExpectCommands(
+		[]string{"lxc", "config", "show", "trash-20250301-web"},
+		[]string{"lxc", "delete", "trash-20250301-web", "--force"},
+		[]string{"lxc", "storage", "volume", "delete", "lvm", "web-docker"},
+		[]string{"lxc", "list", "--format", "json"},
+	); err != nil {
+		t.Error(err)
+	}
+
+	// Without a Docker volume only the container is deleted
+	mock = useMockRunner(t)
+	mock.Respond("devices: {}\n", nil, "lxc", "config", "show", "db")
+	if err := DeleteContainer("db", false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, argv := range mock.Commands {
+		if strings.Contains(strings.Join(argv, " "), "volume delete") {
+			t.Errorf("expected no volume to be deleted, ran %q", strings.Join(argv, " "))
+		}
+	}
+}
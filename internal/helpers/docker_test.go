@@ -1,7 +1,9 @@
 package helpers
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 )
@@ -12,7 +14,7 @@ type MockDockerInstaller struct {
 	CallLog            [][]string // Track all calls for verification
 }
 
-func (m *MockDockerInstaller) RunInContainer(containerName string, args ...string) error {
+func (m *MockDockerInstaller) RunInContainer(ctx context.Context, containerName string, args ...string) error {
 	// Log the call
 	callArgs := append([]string{containerName}, args...)
 	m.CallLog = append(m.CallLog, callArgs)
@@ -27,7 +29,7 @@ func TestInstallDockerInContainer_Success(t *testing.T) {
 	installer := &MockDockerInstaller{}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -122,7 +124,7 @@ func TestInstallDockerInContainer_PrerequisitesFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -143,7 +145,7 @@ func TestInstallDockerInContainer_GPGKeyFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -164,7 +166,7 @@ func TestInstallDockerInContainer_RepositoryFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -190,7 +192,7 @@ func TestInstallDockerInContainer_PackageInstallFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -211,7 +213,7 @@ func TestInstallDockerInContainer_ServiceEnableFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := InstallDockerInContainer(installer, containerName)
+	err := InstallDockerInContainer(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -225,7 +227,7 @@ func TestVerifyDockerInstallation_Success(t *testing.T) {
 	installer := &MockDockerInstaller{}
 	containerName := "test-container"
 
-	err := VerifyDockerInstallation(installer, containerName)
+	err := VerifyDockerInstallation(context.Background(), installer, containerName)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -262,7 +264,7 @@ func TestVerifyDockerInstallation_DockerVersionFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := VerifyDockerInstallation(installer, containerName)
+	err := VerifyDockerInstallation(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -283,7 +285,7 @@ func TestVerifyDockerInstallation_ComposeVersionFailure(t *testing.T) {
 	}
 	containerName := "test-container"
 
-	err := VerifyDockerInstallation(installer, containerName)
+	err := VerifyDockerInstallation(context.Background(), installer, containerName)
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -292,3 +294,73 @@ func TestVerifyDockerInstallation_ComposeVersionFailure(t *testing.T) {
 		t.Errorf("expected Docker Compose V2 verification error, got '%s'", err.Error())
 	}
 }
+
+func TestUninstallDockerFromContainer(t *testing.T) {
+	installer := &MockDockerInstaller{}
+
+	if err := UninstallDockerFromContainer(context.Background(), installer, "test-container"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(installer.CallLog) != 3 {
+		t.Fatalf("expected 3 calls, got %d: %v", len(installer.CallLog), installer.CallLog)
+	}
+	purge := strings.Join(installer.CallLog[0][1:], " ")
+	if purge != "apt-get purge -y "+strings.Join(DockerPackages, " ") {
+		t.Errorf("first call should purge the Docker packages, got '%s'", purge)
+	}
+	cleanup := strings.Join(installer.CallLog[2][1:], " ")
+	if !strings.Contains(cleanup, "/var/lib/docker") || !strings.Contains(cleanup, "/etc/apt/sources.list.d/docker.list") {
+		t.Errorf("Docker data and repository should be removed, got '%s'", cleanup)
+	}
+}
+
+func TestUninstallDockerFromContainer_PurgeFailure(t *testing.T) {
+	installer := &MockDockerInstaller{
+		RunInContainerFunc: func(containerName string, args ...string) error {
+			if args[1] == "purge" {
+				return fmt.Errorf("dpkg lock held")
+			}
+			return nil
+		},
+	}
+
+	err := UninstallDockerFromContainer(context.Background(), installer, "test-container")
+	if err == nil || !strings.Contains(err.Error(), "failed to purge Docker packages") {
+		t.Errorf("expected purge failure, got %v", err)
+	}
+	if len(installer.CallLog) != 1 {
+		t.Errorf("nothing should run after a failed purge, got %v", installer.CallLog)
+	}
+}
+
+func TestDockerLogin(t *testing.T) {
+	mock := useMockRunner(t)
+
+	if err := DockerLogin(context.Background(), "web", "ghcr.io", "octocat", strings.NewReader("ghp_secret\n")); err != nil {
+		t.Fatalf("DockerLogin failed: %v", err)
+	}
+	if !mock.Ran("lxc", "exec", "web", "-T", "--", "su", "-", "app", "-c", "docker login --username 'octocat' --password-stdin 'ghcr.io'") {
+		t.Errorf("unexpected commands: %v", mock.Commands)
+	}
+	for _, argv := range mock.Commands {
+		if strings.Contains(strings.Join(argv, " "), "ghp_secret") {
+			t.Errorf("the password must not be on the command line: %v", argv)
+		}
+	}
+	if len(mock.Stdins) != 1 {
+		t.Fatalf("expected the password on stdin, got %d inputs", len(mock.Stdins))
+	}
+	if data, _ := io.ReadAll(mock.Stdins[0]); string(data) != "ghp_secret\n" {
+		t.Errorf("unexpected stdin %q", data)
+	}
+
+	mock.Respond("Error response from daemon: unauthorized", &MockExitError{Code: 1}, "lxc", "exec", "web")
+	if err := DockerLogin(context.Background(), "web", "", "octocat", strings.NewReader("x")); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+
+	if err := DockerLogin(context.Background(), "web", "ghcr.io", "me'; rm -rf ~; '", strings.NewReader("x")); err == nil {
+		t.Error("expected invalid username error")
+	}
+}
@@ -0,0 +1,364 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DockerProbeImage is the image 'doctor --deep' runs to test DNS from a
+// container started by Docker
+const DockerProbeImage = "busybox:stable"
+
+// dockerProbeHost is the name resolved by the DNS checks
+const dockerProbeHost = "docker.com"
+
+// requiredCgroupControllers are the cgroup v2 controllers Docker needs to
+// apply the limits of its containers
+var requiredCgroupControllers = []string{"cpu", "memory", "pids"}
+
+// overlayProbeScript mounts an overlay filesystem in a scratch directory, as
+// Docker's overlay2 driver does. It exits 3 when the kernel has no overlay
+// support at all.
+const overlayProbeScript = `grep -qw overlay /proc/filesystems || exit 3
+d=$(mktemp -d) || exit 1
+mkdir "$d/lower" "$d/upper" "$d/work" "$d/merged"
+mount -t overlay overlay -o "lowerdir=$d/lower,upperdir=$d/upper,workdir=$d/work" "$d/merged"
+status=$?
+umount "$d/merged" 2>/dev/null
+rm -rf "$d"
+exit $status`
+
+// cgroupProbeDir is created and removed to test that the container may
+// manage its own cgroups
+const cgroupProbeDir = "/sys/fs/cgroup/lxc-go-cli-probe"
+
+// DockerCheck is the outcome of one check of Docker in a container
+type DockerCheck struct {
+	Name    string
+	OK      bool
+	Message string
+	// Warning marks a problem that slows Docker down or breaks one feature
+	// rather than Docker as a whole
+	Warning bool
+	Fix     string
+}
+
+// passed returns a check that found no problem
+func passed(name, format string, args ...interface{}) DockerCheck {
+	return DockerCheck{Name: name, OK: true, Message: fmt.Sprintf(format, args...)}
+}
+
+// DockerFailure is a known Docker-in-LXD problem found in Docker's logs
+type DockerFailure struct {
+	Problem string
+	// Line is the first log line showing the problem
+	Line string
+	Fix  string
+}
+
+// dockerFailureSignatures map log lines of Docker and containerd to the
+// known problems behind them
+var dockerFailureSignatures = []struct {
+	pattern *regexp.Regexp
+	problem string
+	fix     string
+}{
+	{
+		regexp.MustCompile(`(?i)ip_unprivileged_port_start.*permission denied`),
+		"runc is denied a sysctl by the container's AppArmor profile",
+		"update LXD or Incus on the host; until then hold the previous containerd.io in the container (apt-mark hold containerd.io)",
+	},
+	{
+		regexp.MustCompile(`(?i)failed to mount overlay|error creating overlay mount|driver not supported: overlay`),
+		"Docker cannot mount its overlay2 layers",
+		"see the overlayfs check; recreate with --docker-storage-driver fuse-overlayfs or btrfs if the host kernel cannot be upgraded",
+	},
+	{
+		regexp.MustCompile(`(?i)iptables.*(can't initialize|table does not exist|permission denied)|failed to create nat chain`),
+		"Docker cannot set up its firewall rules",
+		"load the netfilter modules on the host: sudo modprobe ip_tables iptable_nat iptable_filter br_netfilter",
+	},
+	{
+		regexp.MustCompile(`(?i)cgroup.*(permission denied|read-only file system)|cannot enter cgroupv2`),
+		"Docker cannot create cgroups for its containers",
+		"lxc config set <container> security.nesting=true, then restart the container",
+	},
+	{
+		regexp.MustCompile(`(?i)apparmor.*(denied|could not be loaded)`),
+		"Docker cannot load its AppArmor profile",
+		"lxc config set <container> security.nesting=true, then restart the container",
+	},
+	{
+		regexp.MustCompile(`(?i)mknod.*operation not permitted`),
+		"a container image needs device nodes the container may not create",
+		"lxc config set <container> security.syscalls.intercept.mknod=true, then restart the container",
+	},
+	{
+		regexp.MustCompile(`(?i)l?setxattr.*operation not permitted`),
+		"a container image needs extended attributes the container may not set",
+		"lxc config set <container> security.syscalls.intercept.setxattr=true, then restart the container",
+	},
+	{
+		regexp.MustCompile(`(?i)no space left on device`),
+		"the disk Docker stores its data on is full",
+		"free space with 'docker system prune' in the container, or raise its quota with 'lxc-go-cli quota set'",
+	},
+}
+
+// maxFailureLine caps the log line shown with a failure
+const maxFailureLine = 200
+
+// MatchDockerFailures returns the known problems in Docker's logs, each once
+// and in the order of dockerFailureSignatures
+func MatchDockerFailures(log string) []DockerFailure {
+	lines := strings.Split(log, "\n")
+	var failures []DockerFailure
+	for _, signature := range dockerFailureSignatures {
+		for _, line := range lines {
+			if !signature.pattern.MatchString(line) {
+				continue
+			}
+			line = strings.TrimSpace(line)
+			if len(line) > maxFailureLine {
+				line = line[:maxFailureLine] + "..."
+			}
+			failures = append(failures, DockerFailure{Problem: signature.problem, Line: line, Fix: signature.fix})
+			break
+		}
+	}
+	return failures
+}
+
+// withContainer fills in the container's name in a fix
+func withContainer(fix, containerName string) string {
+	return strings.ReplaceAll(fix, "<container>", containerName)
+}
+
+// execProbe runs a command in the container and returns its output
+func execProbe(ctx context.Context, containerName string, args ...string) (string, error) {
+	argv := append([]string{"exec", containerName, "--"}, args...)
+	output, err := Runner().RunWithOutput(ctx, "lxc", argv...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// CheckDockerConfig checks the container settings Docker needs, including
+// those inherited from profiles, and that the Docker daemon answers
+func CheckDockerConfig(ctx context.Context, containerName string) []DockerCheck {
+	var checks []DockerCheck
+	output, err := runOutput(ctx, "lxc", "config", "show", containerName, "--expanded")
+	if err != nil {
+		checks = append(checks, DockerCheck{Name: "docker config", Warning: true, Message: fmt.Sprintf("could not read the configuration: %v", err)})
+	} else {
+		var config struct {
+			Config map[string]string `yaml:"config"`
+		}
+		if err := yaml.Unmarshal(output, &config); err != nil {
+			return []DockerCheck{{Name: "docker config", Warning: true, Message: fmt.Sprintf("could not parse the configuration: %v", err)}}
+		}
+		var missing, fixes []string
+		for key, value := range DockerSecurityConfig {
+			if config.Config[key] != value {
+				missing = append(missing, key)
+				fixes = append(fixes, key+"="+value)
+			}
+		}
+		sort.Strings(missing)
+		sort.Strings(fixes)
+		switch {
+		case config.Config["security.privileged"] == "true":
+			checks = append(checks, passed("docker config", "the container is privileged"))
+		case len(missing) > 0:
+			checks = append(checks, DockerCheck{
+				Name:    "docker config",
+				Message: fmt.Sprintf("%s not enabled", strings.Join(missing, ", ")),
+				Fix:     fmt.Sprintf("lxc config set %s %s, then restart the container", containerName, strings.Join(fixes, " ")),
+			})
+		default:
+			checks = append(checks, passed("docker config", "nesting and syscall interception are enabled"))
+		}
+	}
+
+	if output, err := execProbe(ctx, containerName, "docker", "info", "--format", "{{.ServerVersion}}"); err != nil {
+		checks = append(checks, DockerCheck{
+			Name:    "docker daemon",
+			Message: fmt.Sprintf("docker info failed: %s", firstLine(output, err)),
+			Fix:     fmt.Sprintf("lxc exec %s -- systemctl status docker; install it with 'lxc-go-cli provision %s' if it is missing", containerName, containerName),
+		})
+	} else {
+		checks = append(checks, passed("docker daemon", "Docker %s is running", output))
+	}
+	return checks
+}
+
+// DeepCheckDocker probes the container for the problems that commonly break
+// Docker inside LXD: cgroup delegation, overlay mounts, the firewall, DNS
+// from Docker's containers and known failures in Docker's logs
+func DeepCheckDocker(ctx context.Context, containerName string) []DockerCheck {
+	return []DockerCheck{
+		checkCgroupDelegation(ctx, containerName),
+		checkOverlayfs(ctx, containerName),
+		checkFirewall(ctx, containerName),
+		checkContainerDNS(ctx, containerName),
+		checkDockerLogs(ctx, containerName),
+	}
+}
+
+// firstLine returns the first line of a failed command's output, or its error
+func firstLine(output string, err error) string {
+	if output == "" {
+		return err.Error()
+	}
+	line, _, _ := strings.Cut(output, "\n")
+	return line
+}
+
+// checkCgroupDelegation checks that the container has a cgroup v2 hierarchy
+// with the controllers Docker needs and may create cgroups in it
+func checkCgroupDelegation(ctx context.Context, containerName string) DockerCheck {
+	const name = "cgroup delegation"
+	fsType, err := execProbe(ctx, containerName, "stat", "-fc", "%T", "/sys/fs/cgroup")
+	if err != nil {
+		return DockerCheck{Name: name, Warning: true, Message: fmt.Sprintf("could not inspect /sys/fs/cgroup: %s", firstLine(fsType, err))}
+	}
+	if fsType != "cgroup2fs" {
+		return DockerCheck{
+			Name:    name,
+			Warning: true,
+			Message: fmt.Sprintf("the container has a cgroup v1 hierarchy (%s)", fsType),
+			Fix:     "boot the host with the unified cgroup hierarchy (systemd.unified_cgroup_hierarchy=1); Docker's v1 support is deprecated",
+		}
+	}
+
+	controllers, err := execProbe(ctx, containerName, "cat", "/sys/fs/cgroup/cgroup.controllers")
+	if err != nil {
+		return DockerCheck{Name: name, Warning: true, Message: fmt.Sprintf("could not read cgroup.controllers: %s", firstLine(controllers, err))}
+	}
+	available := strings.Fields(controllers)
+	var missing []string
+	for _, required := range requiredCgroupControllers {
+		if !slices.Contains(available, required) {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return DockerCheck{
+			Name:    name,
+			Message: fmt.Sprintf("the %s controller(s) are not delegated to the container", strings.Join(missing, ", ")),
+			Fix:     "delegate the controllers to the LXD service on the host (Delegate=yes in 'sudo systemctl edit snap.lxd.daemon'), then restart LXD and the container",
+		}
+	}
+
+	if output, err := execProbe(ctx, containerName, "sh", "-c", fmt.Sprintf("mkdir %[1]s && rmdir %[1]s", cgroupProbeDir)); err != nil {
+		return DockerCheck{
+			Name:    name,
+			Message: fmt.Sprintf("the container cannot create cgroups: %s", firstLine(output, err)),
+			Fix:     fmt.Sprintf("lxc config set %s security.nesting=true, then restart the container", containerName),
+		}
+	}
+	return passed(name, "cgroup v2 with %s delegated", strings.Join(requiredCgroupControllers, ", "))
+}
+
+// checkOverlayfs checks that the container can mount overlay filesystems,
+// and warns when Docker falls back to the slow vfs driver
+func checkOverlayfs(ctx context.Context, containerName string) DockerCheck {
+	const name = "overlayfs"
+	output, err := execProbe(ctx, containerName, "sh", "-c", overlayProbeScript)
+	switch {
+	case ExitCodeFromError(err) == 3:
+		return DockerCheck{
+			Name:    name,
+			Message: "the host kernel has no overlay filesystem",
+			Fix:     "load it on the host: sudo modprobe overlay && echo overlay | sudo tee /etc/modules-load.d/overlay.conf",
+		}
+	case err != nil:
+		return DockerCheck{
+			Name:    name,
+			Message: fmt.Sprintf("overlay mounts are not allowed in the container: %s", firstLine(output, err)),
+			Fix:     "upgrade the host kernel to 5.11 or later for overlay mounts in unprivileged containers, or recreate with --docker-storage-driver fuse-overlayfs",
+		}
+	}
+
+	driver, err := execProbe(ctx, containerName, "docker", "info", "--format", "{{.Driver}}")
+	if err == nil && driver == DockerStorageVFS {
+		return DockerCheck{
+			Name:    name,
+			Warning: true,
+			Message: "overlay mounts work, but Docker uses the vfs storage driver, which copies every layer",
+			Fix:     "recreate with --docker-storage-driver overlay2",
+		}
+	}
+	return passed(name, "overlay mounts work")
+}
+
+// checkFirewall checks that iptables, and nftables when installed, can
+// read the container's rules, which Docker needs for its networks
+func checkFirewall(ctx context.Context, containerName string) DockerCheck {
+	const name = "firewall"
+	fix := fmt.Sprintf("load the netfilter modules on the host: sudo modprobe ip_tables iptable_nat iptable_filter br_netfilter, or lxc config set %s linux.kernel_modules=ip_tables,iptable_nat,br_netfilter", containerName)
+	if !containerHasCommand(ctx, containerName, "iptables") {
+		return DockerCheck{Name: name, Warning: true, Message: "iptables is not installed", Fix: fmt.Sprintf("lxc exec %s -- apt-get install -y iptables", containerName)}
+	}
+	if output, err := execProbe(ctx, containerName, "iptables", "-t", "nat", "-S"); err != nil {
+		return DockerCheck{Name: name, Message: fmt.Sprintf("iptables does not work: %s", firstLine(output, err)), Fix: fix}
+	}
+	if containerHasCommand(ctx, containerName, "nft") {
+		if output, err := execProbe(ctx, containerName, "nft", "list", "tables"); err != nil {
+			return DockerCheck{Name: name, Message: fmt.Sprintf("nftables does not work: %s", firstLine(output, err)), Fix: fix}
+		}
+	}
+	return passed(name, "iptables can read the nat table")
+}
+
+// checkContainerDNS checks that the container, then a container started by
+// Docker in it, can resolve names
+func checkContainerDNS(ctx context.Context, containerName string) DockerCheck {
+	const name = "dns"
+	if output, err := execProbe(ctx, containerName, "getent", "hosts", dockerProbeHost); err != nil {
+		return DockerCheck{
+			Name:    name,
+			Message: fmt.Sprintf("the container cannot resolve %s: %s", dockerProbeHost, firstLine(output, err)),
+			Fix:     "check the DNS settings of the container's network (lxc network show lxdbr0) and its /etc/resolv.conf",
+		}
+	}
+
+	output, err := execProbe(ctx, containerName, "docker", "run", "--rm", DockerProbeImage, "nslookup", dockerProbeHost)
+	if err != nil {
+		if strings.Contains(output, "Unable to find image") || strings.Contains(output, "pull access denied") {
+			return DockerCheck{Name: name, Warning: true, Message: fmt.Sprintf("could not pull %s to test DNS: %s", DockerProbeImage, firstLine(output, err))}
+		}
+		return DockerCheck{
+			Name:    name,
+			Message: fmt.Sprintf("containers started by Docker cannot resolve %s", dockerProbeHost),
+			Fix:     `set "dns" in /etc/docker/daemon.json to the container's gateway or a public resolver and restart Docker; the 127.0.0.53 stub of systemd-resolved does not work inside Docker's networks`,
+		}
+	}
+	return passed(name, "containers started by Docker resolve %s", dockerProbeHost)
+}
+
+// checkDockerLogs looks for known failures in the recent logs of Docker and
+// containerd
+func checkDockerLogs(ctx context.Context, containerName string) DockerCheck {
+	const name = "docker logs"
+	output, err := execProbe(ctx, containerName, "journalctl", "-u", "docker", "-u", "containerd", "--no-pager", "-n", "300", "-o", "cat")
+	if err != nil {
+		return DockerCheck{Name: name, Warning: true, Message: fmt.Sprintf("could not read Docker's logs: %s", firstLine(output, err))}
+	}
+	failures := MatchDockerFailures(output)
+	if len(failures) == 0 {
+		return passed(name, "no known failures in the recent logs")
+	}
+
+	problems := make([]string, len(failures))
+	fixes := make([]string, len(failures))
+	for i, failure := range failures {
+		problems[i] = fmt.Sprintf("%s (%q)", failure.Problem, failure.Line)
+		fixes[i] = withContainer(failure.Fix, containerName)
+	}
+	return DockerCheck{Name: name, Message: strings.Join(problems, "; "), Fix: strings.Join(fixes, "\n")}
+}
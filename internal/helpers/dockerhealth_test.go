@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMatchDockerFailures(t *testing.T) {
+	log := `level=info msg="Starting up"
+level=error msg="failed to mount overlay: operation not permitted" storage-driver=overlay2
+level=error msg="failed to start daemon: Error initializing network controller: iptables failed: iptables --wait -t nat -N DOCKER: iptables v1.8.7 (legacy): can't initialize iptables table 'nat': Table does not exist"
+level=error msg="failed to mount overlay: invalid argument"
+OCI runtime create failed: runc create failed: unable to start container process: open sysctl net.ipv4.ip_unprivileged_port_start file: reopen fd 8: permission denied`
+
+	failures := MatchDockerFailures(log)
+	if len(failures) != 3 {
+		t.Fatalf("expected 3 failures, got %+v", failures)
+	}
+	if !strings.Contains(failures[0].Problem, "AppArmor") || !strings.Contains(failures[1].Line, "operation not permitted") || !strings.Contains(failures[2].Fix, "modprobe") {
+		t.Errorf("unexpected failures: %+v", failures)
+	}
+
+	if failures := MatchDockerFailures("level=info msg=\"API listen on /run/docker.sock\""); len(failures) != 0 {
+		t.Errorf("expected no failures, got %+v", failures)
+	}
+	if failures := MatchDockerFailures("write /var/lib/docker/tmp/x: no space left on device " + strings.Repeat("x", 300)); len(failures) != 1 || len(failures[0].Line) != maxFailureLine+3 {
+		t.Errorf("expected one shortened failure, got %+v", failures)
+	}
+}
+
+func TestCheckDockerConfig(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("config:\n  security.nesting: \"true\"\n", nil, "lxc", "config", "show", "web")
+	runner.Respond("", &MockExitError{Code: 1}, "lxc", "exec", "web", "--", "docker", "info")
+
+	checks := CheckDockerConfig(context.Background(), "web")
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 checks, got %+v", checks)
+	}
+	if checks[0].OK || checks[0].Fix != "lxc config set web security.syscalls.intercept.mknod=true security.syscalls.intercept.setxattr=true, then restart the container" {
+		t.Errorf("expected the missing keys to be reported, got %+v", checks[0])
+	}
+	if checks[1].OK || !strings.Contains(checks[1].Fix, "provision web") {
+		t.Errorf("expected the daemon check to fail, got %+v", checks[1])
+	}
+
+	runner.Respond("config:\n  security.privileged: \"true\"\n", nil, "lxc", "config", "show", "web")
+	runner.Respond("27.3.1\n", nil, "lxc", "exec", "web", "--", "docker", "info")
+	checks = CheckDockerConfig(context.Background(), "web")
+	if !checks[0].OK || !checks[1].OK || checks[1].Message != "Docker 27.3.1 is running" {
+		t.Errorf("expected both checks to pass, got %+v", checks)
+	}
+}
+
+func TestDeepCheckDocker(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("cgroup2fs\n", nil, "lxc", "exec", "web", "--", "stat")
+	runner.Respond("cpuset cpu io memory pids\n", nil, "lxc", "exec", "web", "--", "cat", "/sys/fs/cgroup/cgroup.controllers")
+	runner.Respond("overlay2\n", nil, "lxc", "exec", "web", "--", "docker", "info")
+	runner.Respond("", &MockExitError{Code: 127}, "lxc", "exec", "web", "--", "nft")
+
+	checks := DeepCheckDocker(context.Background(), "web")
+	for _, check := range checks {
+		if !check.OK {
+			t.Errorf("expected %s to pass, got %+v", check.Name, check)
+		}
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "docker", "run", "--rm", DockerProbeImage, "nslookup", "docker.com") {
+		t.Errorf("expected DNS to be tested from a Docker container, got %v", runner.Commands)
+	}
+
+	runner.Respond("cpuset io memory\n", nil, "lxc", "exec", "web", "--", "cat", "/sys/fs/cgroup/cgroup.controllers")
+	runner.Respond("", &MockExitError{Code: 3}, "lxc", "exec", "web", "--", "sh", "-c")
+	runner.Respond("iptables: can't initialize iptables table 'nat'", &MockExitError{Code: 3}, "lxc", "exec", "web", "--", "iptables", "-t")
+	runner.Respond("nslookup: can't resolve 'docker.com'", &MockExitError{Code: 1}, "lxc", "exec", "web", "--", "docker", "run")
+	runner.Respond("failed to mount overlay: operation not permitted\n", nil, "lxc", "exec", "web", "--", "journalctl")
+
+	checks = DeepCheckDocker(context.Background(), "web")
+	want := map[string]string{
+		"cgroup delegation": "the cpu, pids controller(s) are not delegated",
+		"overlayfs":         "no overlay filesystem",
+		"firewall":          "can't initialize iptables table",
+		"dns":               "containers started by Docker cannot resolve",
+		"docker logs":       "cannot mount its overlay2 layers",
+	}
+	for _, check := range checks {
+		if check.OK || check.Warning || check.Fix == "" || !strings.Contains(check.Message, want[check.Name]) {
+			t.Errorf("expected %s to fail with %q and a fix, got %+v", check.Name, want[check.Name], check)
+		}
+	}
+}
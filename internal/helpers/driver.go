@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Container managers this tool can drive
+const (
+	DriverLXD   = "lxd"
+	DriverIncus = "incus"
+)
+
+// DriverEnvVar selects the driver when --driver is not given
+const DriverEnvVar = "LXC_GO_CLI_DRIVER"
+
+var (
+	driverMu sync.RWMutex
+	driver   = DriverLXD
+)
+
+// CurrentDriver returns the driver commands are run with
+func CurrentDriver() string {
+	driverMu.RLock()
+	defer driverMu.RUnlock()
+	return driver
+}
+
+// ClientBinary returns the command line client of the current driver
+func ClientBinary() string {
+	if CurrentDriver() == DriverIncus {
+		return "incus"
+	}
+	return "lxc"
+}
+
+// ValidateDriver checks a --driver value
+func ValidateDriver(name string) error {
+	if name != DriverLXD && name != DriverIncus {
+		return UsageErrorf("invalid driver '%s': must be '%s' or '%s'", name, DriverLXD, DriverIncus)
+	}
+	return nil
+}
+
+// DetectDriver picks LXD when the lxc client is installed, otherwise Incus
+// when the incus client is, and LXD when neither is found
+func DetectDriver() string {
+	if _, err := lookPath("lxc"); err == nil {
+		return DriverLXD
+	}
+	if _, err := lookPath("incus"); err == nil {
+		return DriverIncus
+	}
+	return DriverLXD
+}
+
+// ResolveDriver returns the driver to use: the flag value, then
+// LXC_GO_CLI_DRIVER, then whichever client is installed
+func ResolveDriver(flag string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(flag))
+	if name == "" {
+		name = strings.ToLower(strings.TrimSpace(os.Getenv(DriverEnvVar)))
+	}
+	if name == "" {
+		return DetectDriver(), nil
+	}
+	if err := ValidateDriver(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// UseDriver makes helpers talk to the given driver. Helpers always build lxc
+// command lines; for Incus the package runner is wrapped so they are
+// rewritten for the incus client.
+func UseDriver(name string) error {
+	if err := ValidateDriver(name); err != nil {
+		return err
+	}
+
+	driverMu.Lock()
+	driver = name
+	driverMu.Unlock()
+
+	current := Runner()
+	if wrapped, ok := current.(*IncusRunner); ok {
+		current = wrapped.next
+	}
+	if name == DriverIncus {
+		current = &IncusRunner{next: current}
+	}
+	SetRunner(current)
+	logger.Debug("Using the %s driver (%s client)", name, ClientBinary())
+	return nil
+}
+
+// IncusRunner rewrites lxc command lines for the incus client and runs them
+// through another runner; other commands pass through unchanged
+type IncusRunner struct {
+	next CommandRunner
+}
+
+// NewIncusRunner wraps next so lxc commands are run with incus
+func NewIncusRunner(next CommandRunner) *IncusRunner {
+	return &IncusRunner{next: next}
+}
+
+// Run runs a command, rewritten for incus if it is an lxc command
+func (r *IncusRunner) Run(ctx context.Context, name string, args ...string) error {
+	name, args = IncusCommand(name, args)
+	return r.next.Run(ctx, name, args...)
+}
+
+// RunWithOutput runs a command, rewritten for incus if it is an lxc command
+func (r *IncusRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	name, args = IncusCommand(name, args)
+	return r.next.RunWithOutput(ctx, name, args...)
+}
+
+// RunStreaming runs a command, rewritten for incus if it is an lxc command
+func (r *IncusRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	name, args = IncusCommand(name, args)
+	return r.next.RunStreaming(ctx, streams, name, args...)
+}
+
+// incusImageRemotes are LXD image remotes Incus does not ship; their images
+// are published on the images: remote instead
+var incusImageRemotes = []string{"ubuntu", "ubuntu-daily"}
+
+// IncusCommand translates an lxc command line to its incus equivalent. Most
+// subcommands are identical; the differences are:
+//   - ubuntu:<release> images come from images:ubuntu/<release>/cloud, the
+//     variant with cloud-init
+//   - there is no 'storage set-default'; the default profile's root disk
+//     pool is changed instead
+func IncusCommand(name string, args []string) (string, []string) {
+	if name != "lxc" {
+		return name, args
+	}
+	translated := append([]string(nil), args...)
+
+	switch {
+	case len(translated) >= 2 && (translated[0] == "launch" || translated[0] == "init"):
+		translated[1] = incusImage(translated[1])
+	case len(translated) == 3 && translated[0] == "storage" && translated[1] == "set-default":
+		translated = []string{"profile", "device", "set", "default", "root", "pool", translated[2]}
+	}
+	return "incus", translated
+}
+
+// incusImage maps an LXD image reference to the Incus image server
+func incusImage(image string) string {
+	remote, alias, ok := strings.Cut(image, ":")
+	if !ok || alias == "" {
+		return image
+	}
+	for _, lxdRemote := range incusImageRemotes {
+		if remote == lxdRemote {
+			return "images:ubuntu/" + alias + "/cloud"
+		}
+	}
+	return image
+}
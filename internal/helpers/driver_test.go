@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useDriver switches driver for the duration of a test
+func useDriver(t *testing.T, name string) {
+	t.Helper()
+	if err := UseDriver(name); err != nil {
+		t.Fatalf("UseDriver failed: %v", err)
+	}
+	t.Cleanup(func() { UseDriver(DriverLXD) })
+}
+
+func TestIncusCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantArgs []string
+	}{
+		{"lxc", []string{"launch", "ubuntu:24.04", "web", "--storage", "pool"}, "incus", []string{"launch", "images:ubuntu/24.04/cloud", "web", "--storage", "pool"}},
+		{"lxc", []string{"init", "ubuntu-daily:noble", "web"}, "incus", []string{"init", "images:ubuntu/noble/cloud", "web"}},
+		{"lxc", []string{"launch", "images:debian/12", "web"}, "incus", []string{"launch", "images:debian/12", "web"}},
+		{"lxc", []string{"storage", "set-default", "fast"}, "incus", []string{"profile", "device", "set", "default", "root", "pool", "fast"}},
+		{"lxc", []string{"config", "show", "web"}, "incus", []string{"config", "show", "web"}},
+		{"ss", []string{"-H", "-ltnp"}, "ss", []string{"-H", "-ltnp"}},
+	}
+	for _, tt := range tests {
+		name, args := IncusCommand(tt.name, tt.args)
+		if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("IncusCommand(%s %v) = %s %v, want %s %v", tt.name, tt.args, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+
+	// The caller's argv is left alone
+	args := []string{"launch", "ubuntu:24.04", "web"}
+	IncusCommand("lxc", args)
+	if args[1] != "ubuntu:24.04" {
+		t.Errorf("expected the original args to be unchanged, got %v", args)
+	}
+}
+
+func TestResolveDriver(t *testing.T) {
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	installed := map[string]bool{}
+	lookPath = func(file string) (string, error) {
+		if installed[file] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Setenv(DriverEnvVar, "")
+
+	tests := []struct {
+		name      string
+		installed []string
+		env       string
+		flag      string
+		want      string
+	}{
+		{"nothing installed", nil, "", "", DriverLXD},
+		{"lxc installed", []string{"lxc"}, "", "", DriverLXD},
+		{"incus installed", []string{"incus"}, "", "", DriverIncus},
+		{"both installed", []string{"lxc", "incus"}, "", "", DriverLXD},
+		{"environment", []string{"lxc", "incus"}, "incus", "", DriverIncus},
+		{"flag wins", []string{"lxc", "incus"}, "incus", "LXD", DriverLXD},
+	}
+	for _, tt := range tests {
+		installed = map[string]bool{}
+		for _, file := range tt.installed {
+			installed[file] = true
+		}
+		t.Setenv(DriverEnvVar, tt.env)
+		got, err := ResolveDriver(tt.flag)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %s, got %s (%v)", tt.name, tt.want, got, err)
+		}
+	}
+
+	if _, err := ResolveDriver("docker"); err == nil || !strings.Contains(err.Error(), "invalid driver") {
+		t.Errorf("expected an invalid driver error, got %v", err)
+	}
+}
+
+func TestUseDriver(t *testing.T) {
+	runner := useMockRunner(t)
+	useDriver(t, DriverIncus)
+
+	if ClientBinary() != "incus" {
+		t.Errorf("expected the incus client, got %s", ClientBinary())
+	}
+	if err := CreateContainer("web", "ubuntu", "24.04", "", "pool"); err != nil {
+		t.Fatalf("CreateContainer failed: %v", err)
+	}
+	if err := SetDefaultStoragePool("pool"); err != nil {
+		t.Fatalf("SetDefaultStoragePool failed: %v", err)
+	}
+	err := runner.ExpectCommands(
+		[]string{"incus", "launch", "images:ubuntu/24.04/cloud", "web", "--storage", "pool"},
+		[]string{"incus", "profile", "device", "set", "default", "root", "pool", "pool"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Selecting a driver again does not stack translations, and LXD unwraps
+	if err := UseDriver(DriverIncus); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped, ok := Runner().(*IncusRunner); !ok || wrapped.next != runner {
+		t.Errorf("expected a single IncusRunner around the mock, got %T", Runner())
+	}
+	if err := UseDriver(DriverLXD); err != nil {
+		t.Fatal(err)
+	}
+	if Runner() != runner || ClientBinary() != "lxc" {
+		t.Errorf("expected the mock runner and lxc client back, got %T and %s", Runner(), ClientBinary())
+	}
+
+	if err := UseDriver("docker"); err == nil {
+		t.Error("expected an invalid driver to fail")
+	}
+}
+
+func TestCheckLXCAvailableIncus(t *testing.T) {
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+	useMockRunner(t)
+	useDriver(t, DriverIncus)
+
+	var unavailable *LXCUnavailableError
+	err := CheckLXCAvailable()
+	if !errors.As(err, &unavailable) || unavailable.Code != LXCErrNotFound {
+		t.Fatalf("expected %s, got %v", LXCErrNotFound, err)
+	}
+	if !strings.Contains(err.Error(), "incus client") || !strings.Contains(unavailable.Hint, "incus admin init") {
+		t.Errorf("expected Incus instructions, got %v: %s", err, unavailable.Hint)
+	}
+
+	if hint := lxcDaemonError("connect: permission denied").Hint; !strings.Contains(hint, "incus-admin") {
+		t.Errorf("expected the incus-admin group, got %q", hint)
+	}
+}
+
+func TestLXCConfigDirIncus(t *testing.T) {
+	t.Setenv(originalConfEnvVar, "")
+	t.Setenv("LXD_CONF", "/tmp/lxd-conf")
+	t.Setenv("INCUS_CONF", "/tmp/incus-conf")
+	useDriver(t, DriverIncus)
+
+	if dir := LXCConfigDir(); dir != "/tmp/incus-conf" {
+		t.Errorf("expected INCUS_CONF to be used, got %s", dir)
+	}
+	t.Setenv("INCUS_CONF", "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if dir := LXCConfigDir(); dir != "/tmp/xdg/incus" {
+		t.Errorf("expected the incus config directory, got %s", dir)
+	}
+}
@@ -0,0 +1,173 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// envKeyPattern matches a variable name a shell can export
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvVar is one variable from an env file
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// LoadEnvFile reads variables from a .env file
+func LoadEnvFile(path string) ([]EnvVar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return ParseEnvFile(path, string(data))
+}
+
+// ParseEnvFile parses KEY=VALUE lines in the usual .env syntax: blank lines and
+// # comments are skipped, an 'export ' prefix is allowed, single-quoted values
+// are literal and double-quoted values understand \n, \t, \", \\ and \$.
+// A key given twice keeps its last value.
+func ParseEnvFile(name, data string) ([]EnvVar, error) {
+	var vars []EnvVar
+	index := make(map[string]int)
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", name, i+1)
+		}
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: invalid variable name '%s'", name, i+1, key)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+
+		if existing, ok := index[key]; ok {
+			vars[existing].Value = value
+			continue
+		}
+		index[key] = len(vars)
+		vars = append(vars, EnvVar{Key: key, Value: value})
+	}
+	return vars, nil
+}
+
+// parseEnvValue unquotes a value
+func parseEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return value[1 : end+1], nil
+	case strings.HasPrefix(value, `"`):
+		var result strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			if c == '"' {
+				return result.String(), nil
+			}
+			if c == '\\' && i+1 < len(value) {
+				i++
+				switch value[i] {
+				case 'n':
+					result.WriteByte('\n')
+				case 't':
+					result.WriteByte('\t')
+				case '"', '\\', '$':
+					result.WriteByte(value[i])
+				default:
+					result.WriteByte('\\')
+					result.WriteByte(value[i])
+				}
+				continue
+			}
+			result.WriteByte(c)
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	default:
+		// An unquoted value ends at an inline comment
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = value[:comment]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// EnvKeys returns the variable names, in file order
+func EnvKeys(vars []EnvVar) []string {
+	keys := make([]string, len(vars))
+	for i, v := range vars {
+		keys[i] = v.Key
+	}
+	return keys
+}
+
+// EnvScript renders variables as shell exports with every value single-quoted,
+// so sourcing it never expands or runs anything
+func EnvScript(vars []EnvVar) string {
+	var script strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&script, "export %s=%s\n", v.Key, ShellQuote(v.Value))
+	}
+	return script.String()
+}
+
+// ShellQuote single-quotes a string for sh
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// pushEnvScript writes stdin to a new root-only file on the container's /run
+// tmpfs and prints its path
+const pushEnvScript = `umask 077; f=$(mktemp /run/lxc-go-cli-env.XXXXXX) && cat > "$f" && echo "$f"`
+
+// PushEnv copies variables into a container for one session. They travel on
+// stdin, never on a command line, and land in a mode 0600 root-owned file on
+// tmpfs that EnvCommand deletes as soon as it has read it.
+func PushEnv(ctx context.Context, containerName string, vars []EnvVar) (string, error) {
+	var stdout, stderr bytes.Buffer
+	streams := Streams{Stdin: strings.NewReader(EnvScript(vars)), Stdout: &stdout, Stderr: &stderr}
+	if err := Runner().RunStreaming(ctx, streams, "lxc", "exec", containerName, "-T", "--", "sh", "-c", pushEnvScript); err != nil {
+		return "", fmt.Errorf("failed to copy environment into container '%s': %w (output: %s)", containerName, err, strings.TrimSpace(stderr.String()))
+	}
+	path := strings.TrimSpace(stdout.String())
+	if !strings.HasPrefix(path, "/run/lxc-go-cli-env.") {
+		return "", fmt.Errorf("failed to copy environment into container '%s': unexpected path '%s'", containerName, path)
+	}
+	logger.Debug("Copied %d variable(s) to %s in container '%s'", len(vars), path, containerName)
+	return path, nil
+}
+
+// envCommandScript exports the variables in $1, deletes the file and runs the rest
+const envCommandScript = `set -a; . "$1"; rm -f -- "$1"; set +a; shift; exec "$@"`
+
+// EnvCommand wraps a command so it runs with the variables pushed by PushEnv
+func EnvCommand(path string, command ...string) []string {
+	return append([]string{"sh", "-c", envCommandScript, "sh", path}, command...)
+}
+
+// RemoveContainerFile deletes a file in a container if it still exists
+func RemoveContainerFile(ctx context.Context, containerName, path string) error {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "exec", containerName, "--", "rm", "-f", "--", path)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from container '%s': %w (output: %s)", path, containerName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	data := strings.Join([]string{
+		"# database",
+		"",
+		"DB_HOST=db.internal",
+		"export DB_USER = app ",
+		"DB_PASS='p@ss $word'",
+		`GREETING="hello\n\"world\" \$HOME"`,
+		"PORT=5432 # default port",
+		"EMPTY=",
+		"DB_HOST=db.example.com\r",
+	}, "\n")
+
+	vars, err := ParseEnvFile(".env", data)
+	if err != nil {
+		t.Fatalf("ParseEnvFile failed: %v", err)
+	}
+	want := []EnvVar{
+		{"DB_HOST", "db.example.com"},
+		{"DB_USER", "app"},
+		{"DB_PASS", "p@ss $word"},
+		{"GREETING", "hello\n\"world\" $HOME"},
+		{"PORT", "5432"},
+		{"EMPTY", ""},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("expected %v, got %v", want, vars)
+	}
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"A=1\nNOVALUE", ".env:2: expected KEY=VALUE"},
+		{"1ABC=x", ".env:1: invalid variable name '1ABC'"},
+		{"A B=x", "invalid variable name"},
+		{"A='open", ".env:1: unterminated single quote"},
+		{`A="open`, ".env:1: unterminated double quote"},
+	}
+	for _, tt := range tests {
+		_, err := ParseEnvFile(".env", tt.data)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseEnvFile(%q): expected %q, got %v", tt.data, tt.want, err)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TOKEN=abc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := LoadEnvFile(path)
+	if err != nil || len(vars) != 1 || vars[0].Value != "abc" {
+		t.Errorf("expected TOKEN=abc, got %v (%v)", vars, err)
+	}
+
+	if _, err := LoadEnvFile(filepath.Join(t.TempDir(), "missing")); err == nil || !strings.Contains(err.Error(), "failed to read env file") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestEnvScript(t *testing.T) {
+	script := EnvScript([]EnvVar{{"A", "it's $(rm -rf /)"}, {"B", ""}})
+	want := "export A='it'\\''s $(rm -rf /)'\nexport B=''\n"
+	if script != want {
+		t.Errorf("expected %q, got %q", want, script)
+	}
+}
+
+func TestEnvScriptSourcedByShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	vars := []EnvVar{{"A", "it's \"quoted\" $HOME `id`"}, {"B", "line1\nline2"}}
+	path := filepath.Join(t.TempDir(), "env")
+	if err := os.WriteFile(path, []byte(EnvScript(vars)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	argv := EnvCommand(path, "sh", "-c", `printf '%s|%s' "$A" "$B"`)
+	output, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil {
+		t.Fatalf("sourcing the script failed: %v", err)
+	}
+	if want := vars[0].Value + "|" + vars[1].Value; string(output) != want {
+		t.Errorf("expected %q, got %q", want, output)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the env file to be deleted after sourcing, got %v", err)
+	}
+}
+
+func TestPushEnv(t *testing.T) {
+	runner := useMockRunner(t)
+	argv := []string{"lxc", "exec", "web", "-T", "--", "sh", "-c"}
+	runner.Respond("/run/lxc-go-cli-env.Xy12ab\n", nil, argv...)
+
+	path, err := PushEnv(context.Background(), "web", []EnvVar{{"TOKEN", "s3cret"}})
+	if err != nil || path != "/run/lxc-go-cli-env.Xy12ab" {
+		t.Fatalf("expected the pushed path, got %q (%v)", path, err)
+	}
+	if !runner.Ran(append(argv, pushEnvScript)...) {
+		t.Errorf("expected the push script to run, got %v", runner.Commands)
+	}
+	for _, command := range runner.Commands {
+		if strings.Contains(strings.Join(command, " "), "s3cret") {
+			t.Errorf("expected values to stay off the command line, got %v", command)
+		}
+	}
+	if len(runner.Stdins) != 1 {
+		t.Fatalf("expected the variables on stdin, got %d inputs", len(runner.Stdins))
+	}
+	if data, _ := io.ReadAll(runner.Stdins[0]); string(data) != "export TOKEN='s3cret'\n" {
+		t.Errorf("unexpected stdin %q", data)
+	}
+
+	runner.Respond("/tmp/elsewhere\n", nil, argv...)
+	if _, err := PushEnv(context.Background(), "web", nil); err == nil || !strings.Contains(err.Error(), "unexpected path") {
+		t.Errorf("expected an unexpected path error, got %v", err)
+	}
+
+	runner.Respond("", errors.New("exit status 1"), argv...)
+	if _, err := PushEnv(context.Background(), "web", nil); err == nil || !strings.Contains(err.Error(), "failed to copy environment") {
+		t.Errorf("expected a copy error, got %v", err)
+	}
+}
+
+func TestRemoveContainerFile(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := RemoveContainerFile(context.Background(), "web", "/run/lxc-go-cli-env.abc"); err != nil {
+		t.Fatalf("RemoveContainerFile failed: %v", err)
+	}
+	if !runner.Ran("lxc", "exec", "web", "--", "rm", "-f", "--", "/run/lxc-go-cli-env.abc") {
+		t.Errorf("expected rm in the container, got %v", runner.Commands)
+	}
+
+	runner.Respond("", errors.New("exit status 1"), "lxc", "exec", "web")
+	if err := RemoveContainerFile(context.Background(), "web", "/run/x"); err == nil {
+		t.Error("expected a failed removal to return an error")
+	}
+}
@@ -0,0 +1,265 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Codes identifying why lxc cannot be used, stable for scripts to match on
+const (
+	LXCErrNotFound         = "LXC_NOT_FOUND"
+	LXCErrPermissionDenied = "LXD_PERMISSION_DENIED"
+	LXCErrUnreachable      = "LXD_UNREACHABLE"
+)
+
+// lookPath finds executables; tests replace it to simulate a missing lxc
+var lookPath = exec.LookPath
+
+// lxcInfoTimeout bounds the `lxc info` that checks the daemon, so a hung
+// daemon fails the check instead of every command; a variable so tests can
+// shorten it
+var lxcInfoTimeout = 10 * time.Second
+
+// LXCUnavailableError explains why the lxc client cannot be used and how to fix it
+type LXCUnavailableError struct {
+	Code   string
+	Reason string
+	Hint   string
+}
+
+func (e *LXCUnavailableError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Reason, e.Code)
+}
+
+// Is makes the error match ErrEnvironment
+func (e *LXCUnavailableError) Is(target error) bool {
+	return target == ErrEnvironment
+}
+
+// CheckLXCAvailable verifies that the lxc client is installed and can reach the LXD daemon;
+// failures are returned as *LXCUnavailableError
+func CheckLXCAvailable() error {
+	client := ClientBinary()
+	path, err := lookPath(client)
+	if err != nil {
+		return &LXCUnavailableError{
+			Code:   LXCErrNotFound,
+			Reason: fmt.Sprintf("%s client not found in PATH", client),
+			Hint:   installHint(),
+		}
+	}
+	logger.Debug("Found %s client at %s", client, path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), lxcInfoTimeout)
+	defer cancel()
+	output, err := Runner().RunWithOutput(ctx, "lxc", "info")
+	if ctx.Err() != nil {
+		return lxcDaemonError(fmt.Sprintf("no answer within %s", lxcInfoTimeout))
+	}
+	if err != nil {
+		logger.Debug("lxc info failed with output: %s", string(output))
+		return lxcDaemonError(strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// lxcDaemonError describes a failed `lxc info`
+func lxcDaemonError(output string) *LXCUnavailableError {
+	if output == "" {
+		output = "no output"
+	}
+	incus := CurrentDriver() == DriverIncus
+	if strings.Contains(strings.ToLower(output), "permission denied") {
+		return &LXCUnavailableError{
+			Code:   LXCErrPermissionDenied,
+			Reason: fmt.Sprintf("not allowed to use the %s daemon: %s", daemonName(), output),
+			Hint:   permissionHint(),
+		}
+	}
+
+	hint := `Make sure LXD is running and initialised:
+  sudo snap start lxd
+  sudo lxd init --auto
+For a remote server, check 'lxc remote list' and the active context.`
+	if incus {
+		hint = `Make sure Incus is running and initialised:
+  sudo systemctl start incus
+  sudo incus admin init --auto
+For a remote server, check 'incus remote list' and the active context.`
+	}
+	return &LXCUnavailableError{
+		Code:   LXCErrUnreachable,
+		Reason: fmt.Sprintf("%s client cannot reach the %s daemon: %s", ClientBinary(), daemonName(), output),
+		Hint:   hint,
+	}
+}
+
+// daemonGroup returns the group whose members may use the current driver's daemon
+func daemonGroup() string {
+	if CurrentDriver() == DriverIncus {
+		return "incus-admin"
+	}
+	return "lxd"
+}
+
+// permissionHint explains how to get access to the daemon socket
+func permissionHint() string {
+	group := daemonGroup()
+	configured, active := groupMembership(group)
+	if configured && !active {
+		return fmt.Sprintf(`Your user is in the %[1]s group, but this session started before it was
+added. Log out and back in, or start a shell with the group:
+  newgrp %[1]s`, group)
+	}
+	return fmt.Sprintf(`Add your user to the %[1]s group and start a new login session:
+  sudo usermod -aG %[1]s "$USER"
+  newgrp %[1]s`, group)
+}
+
+// daemonName returns the display name of the current driver's daemon
+func daemonName() string {
+	if CurrentDriver() == DriverIncus {
+		return "Incus"
+	}
+	return "LXD"
+}
+
+// installHint explains how to install the current driver
+func installHint() string {
+	if CurrentDriver() == DriverIncus {
+		return `lxc-go-cli --driver incus drives Incus through the incus client. Install it
+from your distribution (e.g. sudo apt install incus), then:
+  sudo incus admin init --auto
+  sudo usermod -aG incus-admin "$USER"   # then log out and back in
+See https://linuxcontainers.org/incus/docs/main/installing/`
+	}
+	return `lxc-go-cli drives LXD through the lxc client. Install LXD with:
+  sudo snap install lxd
+  sudo lxd init --auto
+  sudo usermod -aG lxd "$USER"   # then log out and back in
+See https://documentation.ubuntu.com/lxd/en/latest/installing/
+On a system with Incus instead, use --driver incus.`
+}
+
+// BackendCLI identifies the lxc command line client as the backend used to talk to LXD
+const BackendCLI = "cli"
+
+// StorageDriverVersion is a storage driver supported by the server
+type StorageDriverVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Remote  bool   `json:"remote"`
+}
+
+// ComponentVersions describes the LXD client and server this tool is talking to.
+// Detection problems are recorded in Errors rather than failing outright.
+type ComponentVersions struct {
+	Backend        string                 `json:"backend"`
+	ClientVersion  string                 `json:"client_version"`
+	Server         string                 `json:"server"`
+	ServerVersion  string                 `json:"server_version"`
+	APIVersion     string                 `json:"api_version"`
+	Driver         string                 `json:"driver"`
+	DriverVersion  string                 `json:"driver_version"`
+	StorageDrivers []StorageDriverVersion `json:"storage_drivers"`
+	Errors         []string               `json:"errors,omitempty"`
+}
+
+// lxdServerInfo mirrors the parts of GET /1.0 we use
+type lxdServerInfo struct {
+	APIVersion    string   `json:"api_version"`
+	APIExtensions []string `json:"api_extensions"`
+	Environment   struct {
+		Server                  string `json:"server"`
+		ServerVersion           string `json:"server_version"`
+		Driver                  string `json:"driver"`
+		DriverVersion           string `json:"driver_version"`
+		StorageSupportedDrivers []struct {
+			Name    string `json:"Name"`
+			Version string `json:"Version"`
+			Remote  bool   `json:"Remote"`
+		} `json:"storage_supported_drivers"`
+	} `json:"environment"`
+}
+
+// GetComponentVersions detects the lxc client, server and storage driver versions
+func GetComponentVersions() *ComponentVersions {
+	versions := &ComponentVersions{Backend: BackendCLI}
+
+	output, err := runOutput(context.Background(), "lxc", "--version")
+	if err != nil {
+		versions.Errors = append(versions.Errors, fmt.Sprintf("client version: %v", err))
+	} else {
+		versions.ClientVersion = strings.TrimSpace(string(output))
+	}
+
+	output, err = runOutput(context.Background(), "lxc", "query", "/1.0")
+	if err != nil {
+		versions.Errors = append(versions.Errors, fmt.Sprintf("server info: %v", err))
+		return versions
+	}
+	if err := parseServerInfo(output, versions); err != nil {
+		versions.Errors = append(versions.Errors, err.Error())
+	}
+
+	return versions
+}
+
+// parseServerInfo fills in server details from the GET /1.0 response
+func parseServerInfo(jsonOutput []byte, versions *ComponentVersions) error {
+	var info lxdServerInfo
+	if err := json.Unmarshal(jsonOutput, &info); err != nil {
+		return fmt.Errorf("failed to parse server info: %w", err)
+	}
+
+	versions.APIVersion = info.APIVersion
+	versions.Server = info.Environment.Server
+	versions.ServerVersion = info.Environment.ServerVersion
+	versions.Driver = info.Environment.Driver
+	versions.DriverVersion = info.Environment.DriverVersion
+	for _, driver := range info.Environment.StorageSupportedDrivers {
+		versions.StorageDrivers = append(versions.StorageDrivers, StorageDriverVersion{
+			Name:    driver.Name,
+			Version: driver.Version,
+			Remote:  driver.Remote,
+		})
+	}
+	return nil
+}
+
+// MissingAPIExtensions returns the required API extensions the LXD server does not support
+func MissingAPIExtensions(ctx context.Context, required ...string) ([]string, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LXD server: %w", err)
+	}
+	return missingExtensions(output, required)
+}
+
+// missingExtensions checks a GET /1.0 response for the required API extensions
+func missingExtensions(jsonOutput []byte, required []string) ([]string, error) {
+	var info lxdServerInfo
+	if err := json.Unmarshal(jsonOutput, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse server info: %w", err)
+	}
+
+	supported := make(map[string]bool, len(info.APIExtensions))
+	for _, ext := range info.APIExtensions {
+		supported[ext] = true
+	}
+
+	var missing []string
+	for _, ext := range required {
+		if !supported[ext] {
+			missing = append(missing, ext)
+		}
+	}
+	return missing, nil
+}
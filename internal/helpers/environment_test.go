@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	output := `{
+  "api_version": "1.0",
+  "environment": {
+    "server": "lxd",
+    "server_version": "5.21.1",
+    "driver": "lxc | qemu",
+    "driver_version": "6.0.0 | 8.2.1",
+    "storage_supported_drivers": [
+      {"Name": "btrfs", "Version": "6.6.3", "Remote": false},
+      {"Name": "ceph", "Version": "17.2.7", "Remote": true}
+    ]
+  }
+}`
+
+	versions := &ComponentVersions{Backend: BackendCLI}
+	if err := parseServerInfo([]byte(output), versions); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if versions.Server != "lxd" || versions.ServerVersion != "5.21.1" || versions.APIVersion != "1.0" {
+		t.Errorf("unexpected server info: %+v", versions)
+	}
+	if len(versions.StorageDrivers) != 2 || versions.StorageDrivers[1].Name != "ceph" || !versions.StorageDrivers[1].Remote {
+		t.Errorf("unexpected storage drivers: %+v", versions.StorageDrivers)
+	}
+
+	if err := parseServerInfo([]byte("not json"), versions); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestCheckLXCAvailable(t *testing.T) {
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	runner := useMockRunner(t)
+	var unavailable *LXCUnavailableError
+	err := CheckLXCAvailable()
+	if !errors.As(err, &unavailable) || unavailable.Code != LXCErrNotFound {
+		t.Fatalf("expected %s, got %v", LXCErrNotFound, err)
+	}
+	if !strings.Contains(unavailable.Hint, "snap install lxd") {
+		t.Errorf("expected install instructions, got %q", unavailable.Hint)
+	}
+	if len(runner.Commands) != 0 {
+		t.Errorf("expected no commands without an lxc client, got %v", runner.Commands)
+	}
+
+	lookPath = func(string) (string, error) { return "/snap/bin/lxc", nil }
+	tests := []struct {
+		output string
+		code   string
+	}{
+		{"Error: Get \"http://unix.socket/1.0\": dial unix /var/snap/lxd/common/lxd/unix.socket: connect: permission denied", LXCErrPermissionDenied},
+		{"Error: LXD unix socket \"/var/snap/lxd/common/lxd/unix.socket\" not accessible: dial unix: connect: no such file or directory", LXCErrUnreachable},
+		{"", LXCErrUnreachable},
+	}
+	for _, tt := range tests {
+		runner.Reset()
+		runner.Respond(tt.output, &MockExitError{Code: 1}, "lxc", "info")
+		err := CheckLXCAvailable()
+		if !errors.As(err, &unavailable) || unavailable.Code != tt.code {
+			t.Errorf("%q: expected %s, got %v", tt.output, tt.code, err)
+		}
+	}
+
+	runner.Reset()
+	if err := CheckLXCAvailable(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// A daemon that does not answer fails the check instead of hanging
+	previousTimeout := lxcInfoTimeout
+	t.Cleanup(func() { lxcInfoTimeout = previousTimeout })
+	lxcInfoTimeout = 0
+	err = CheckLXCAvailable()
+	if !errors.As(err, &unavailable) || unavailable.Code != LXCErrUnreachable || !strings.Contains(err.Error(), "no answer within") {
+		t.Errorf("expected %s after the timeout, got %v", LXCErrUnreachable, err)
+	}
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure, matched with errors.Is, from which the command line tool
+// picks its exit code. Timeouts are matched with context.DeadlineExceeded.
+var (
+	// ErrUsage is a command invoked wrongly, e.g. an invalid flag value
+	ErrUsage = errors.New("usage error")
+	// ErrNotFound is a container, snapshot, pool or other object that does
+	// not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is an object that already exists or a resource in use
+	ErrConflict = errors.New("conflict")
+	// ErrEnvironment is a missing prerequisite on the host, such as the lxc
+	// client, root privileges or a local LXD server
+	ErrEnvironment = errors.New("environment not ready")
+)
+
+// kindError gives an error a kind without changing its message
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// WithKind marks err as a failure of the given kind, one of ErrUsage,
+// ErrNotFound, ErrConflict, ErrEnvironment or context.DeadlineExceeded; the
+// message stays the same
+func WithKind(err error, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{err: err, kind: kind}
+}
+
+// UsageErrorf returns an ErrUsage error formatted as by fmt.Errorf
+func UsageErrorf(format string, args ...any) error {
+	return WithKind(fmt.Errorf(format, args...), ErrUsage)
+}
+
+// NotFoundErrorf returns an ErrNotFound error formatted as by fmt.Errorf
+func NotFoundErrorf(format string, args ...any) error {
+	return WithKind(fmt.Errorf(format, args...), ErrNotFound)
+}
+
+// TimeoutErrorf returns an error matching context.DeadlineExceeded, formatted
+// as by fmt.Errorf, for an operation that ran out of time
+func TimeoutErrorf(format string, args ...any) error {
+	return WithKind(fmt.Errorf(format, args...), context.DeadlineExceeded)
+}
+
+// ConflictErrorf returns an ErrConflict error formatted as by fmt.Errorf
+func ConflictErrorf(format string, args ...any) error {
+	return WithKind(fmt.Errorf(format, args...), ErrConflict)
+}
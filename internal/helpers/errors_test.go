@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithKind(t *testing.T) {
+	cause := errors.New("lxc failed")
+	err := WithKind(fmt.Errorf("container 'web' does not exist: %w", cause), ErrNotFound)
+
+	if err.Error() != "container 'web' does not exist: lxc failed" {
+		t.Errorf("expected the message to stay the same, got %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, cause) {
+		t.Error("expected the error to match its kind and its cause")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("expected the error not to match other kinds")
+	}
+	if WithKind(nil, ErrUsage) != nil {
+		t.Error("expected no error for nil")
+	}
+	if !errors.Is(fmt.Errorf("create: %w", UsageErrorf("invalid size '%s'", "x")), ErrUsage) {
+		t.Error("expected the kind to survive wrapping")
+	}
+}
+
+func TestWithContextError(t *testing.T) {
+	killed := errors.New("signal: killed")
+	if err := withContextError(context.Background(), killed); err != killed {
+		t.Errorf("expected errors of live contexts unchanged, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	err := withContextError(ctx, killed)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, killed) {
+		t.Errorf("expected a deadline error wrapping the command's, got %v", err)
+	}
+	if withContextError(ctx, nil) != nil {
+		t.Error("expected no error for a command that succeeded")
+	}
+}
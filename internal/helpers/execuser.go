@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultExecUser is the user exec runs shells and commands as unless
+// configured otherwise
+const DefaultExecUser = "app"
+
+// userNamePattern follows the names useradd accepts by default
+var userNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// ExecSettings are the defaults of the exec command in the config file
+type ExecSettings struct {
+	// User is who shells and commands run as; empty means the app user
+	User string `yaml:"user,omitempty"`
+	// Login runs commands in a login shell, sourcing the user's profile
+	Login bool `yaml:"login,omitempty"`
+}
+
+// ValidateUserName checks a container user name before it ends up in a command line
+func ValidateUserName(name string) error {
+	if !userNamePattern.MatchString(name) {
+		return UsageErrorf("invalid user name '%s': use lowercase letters, digits, '_' and '-', starting with a letter or '_'", name)
+	}
+	return nil
+}
+
+// ResolveExecUser returns the user exec runs as: the given user, else the
+// configured one, else the app user
+func ResolveExecUser(user string, settings *Settings) (string, error) {
+	if user == "" && settings != nil {
+		user = settings.Exec.User
+	}
+	if user == "" {
+		user = DefaultExecUser
+	}
+	if err := ValidateUserName(user); err != nil {
+		return "", err
+	}
+	return user, nil
+}
+
+// UserShell returns the command for a login shell of user. su clears the
+// environment, so variables named in keepEnv are passed on with -w.
+func UserShell(user string, keepEnv []string) []string {
+	if len(keepEnv) == 0 {
+		return []string{"su", "-", user}
+	}
+	return []string{"su", "-w", strings.Join(keepEnv, ","), "-", user}
+}
+
+// UserCommand wraps a command so it runs as user. With login it runs in a
+// login shell that sources the user's profile, keeping the variables named in
+// keepEnv; without, root runs it directly and other users through runuser,
+// which keeps the environment. Either way it starts in the user's home, as
+// lxc exec would otherwise leave it in /root, which other users cannot read.
+func UserCommand(user string, login bool, keepEnv []string, command ...string) []string {
+	if login {
+		quoted := make([]string, len(command))
+		for i, arg := range command {
+			quoted[i] = ShellQuote(arg)
+		}
+		return append(UserShell(user, keepEnv), "-c", strings.Join(quoted, " "))
+	}
+	if user == "root" {
+		return command
+	}
+	// runuser has no option for the working directory; user is validated, so
+	// it is safe in the script, and the command is passed on as arguments
+	return append([]string{"runuser", "-u", user, "--", "sh", "-c", `cd ~` + user + ` && exec "$@"`, "sh"}, command...)
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateUserName(t *testing.T) {
+	for _, name := range []string{"app", "root", "_svc", "deploy-2"} {
+		if err := ValidateUserName(name); err != nil {
+			t.Errorf("expected '%s' to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "App", "2app", "app user", "app;id", "-app"} {
+		if err := ValidateUserName(name); err == nil {
+			t.Errorf("expected '%s' to be rejected", name)
+		}
+	}
+}
+
+func TestUserCommand(t *testing.T) {
+	got := UserCommand("app", false, nil, "ls", "-l")
+	want := []string{"runuser", "-u", "app", "--", "sh", "-c", `cd ~app && exec "$@"`, "sh", "ls", "-l"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := UserCommand("root", false, nil, "ls", "-l"); strings.Join(got, " ") != "ls -l" {
+		t.Errorf("expected root to run the command directly, got %q", got)
+	}
+	got = UserCommand("app", true, []string{"TOKEN"}, "echo", "$HOME")
+	want = []string{"su", "-w", "TOKEN", "-", "app", "-c", "'echo' '$HOME'"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
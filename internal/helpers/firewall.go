@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Host firewalls that 'expose' opens ports in
+const (
+	FirewallUFW       = "ufw"
+	FirewallFirewalld = "firewalld"
+)
+
+// ExposedPortsKey lists the host firewall ports 'expose' opened for a
+// container, e.g. "80/tcp,443/tcp", so they are closed when it is deleted
+const ExposedPortsKey = "user.lxc-go-cli.exposed-ports"
+
+// DetectFirewall returns the active host firewall, ufw or firewalld, or ""
+// when neither is installed and running
+func DetectFirewall(ctx context.Context) (string, error) {
+	if err := RequireLocalServer(ctx, "Opening the host firewall"); err != nil {
+		return "", err
+	}
+
+	if _, err := lookPath("ufw"); err == nil {
+		output, err := runFirewallCommand(ctx, "ufw", "status")
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(output, "Status: active") {
+			return FirewallUFW, nil
+		}
+	}
+	if _, err := lookPath("firewall-cmd"); err == nil {
+		// --state exits non-zero when firewalld is not running
+		if output, err := runFirewallCommand(ctx, "firewall-cmd", "--state"); err == nil && strings.TrimSpace(output) == "running" {
+			return FirewallFirewalld, nil
+		}
+	}
+	return "", nil
+}
+
+// FirewallOpenCommands returns the commands that allow a port through a
+// firewall, for running them or showing them to the user
+func FirewallOpenCommands(firewall string, port int, protocol, comment string) [][]string {
+	rule := fmt.Sprintf("%d/%s", port, protocol)
+	switch firewall {
+	case FirewallUFW:
+		return [][]string{{"ufw", "allow", rule, "comment", comment}}
+	case FirewallFirewalld:
+		// Open it now and across reloads
+		return [][]string{
+			{"firewall-cmd", "--add-port=" + rule},
+			{"firewall-cmd", "--permanent", "--add-port=" + rule},
+		}
+	}
+	return nil
+}
+
+// OpenFirewallPort allows a port through the given host firewall
+func OpenFirewallPort(ctx context.Context, firewall string, port int, protocol, comment string) error {
+	commands := FirewallOpenCommands(firewall, port, protocol, comment)
+	if commands == nil {
+		return fmt.Errorf("unsupported firewall '%s'", firewall)
+	}
+	for _, argv := range commands {
+		if _, err := runFirewallCommand(ctx, argv...); err != nil {
+			return err
+		}
+	}
+	logger.Info("Opened %d/%s in %s", port, protocol, firewall)
+	return nil
+}
+
+// FirewallCloseCommands returns the commands that remove the rule allowing a
+// port through a firewall
+func FirewallCloseCommands(firewall, rule string) [][]string {
+	switch firewall {
+	case FirewallUFW:
+		return [][]string{{"ufw", "delete", "allow", rule}}
+	case FirewallFirewalld:
+		return [][]string{
+			{"firewall-cmd", "--remove-port=" + rule},
+			{"firewall-cmd", "--permanent", "--remove-port=" + rule},
+		}
+	}
+	return nil
+}
+
+// CloseFirewallPort removes the rule allowing a port, given as e.g.
+// "8080/tcp", through the given host firewall
+func CloseFirewallPort(ctx context.Context, firewall, rule string) error {
+	commands := FirewallCloseCommands(firewall, rule)
+	if commands == nil {
+		return fmt.Errorf("unsupported firewall '%s'", firewall)
+	}
+	for _, argv := range commands {
+		if _, err := runFirewallCommand(ctx, argv...); err != nil {
+			return err
+		}
+	}
+	logger.Info("Closed %s in %s", rule, firewall)
+	return nil
+}
+
+// runFirewallCommand runs a firewall tool as root and returns its output
+func runFirewallCommand(ctx context.Context, args ...string) (string, error) {
+	argv, err := privilegedCommand(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	logger.Debug("Running: %s", strings.Join(argv, " "))
+
+	output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+	if err != nil {
+		logger.Debug("%s failed with output: %s", args[0], string(output))
+		return "", withPrivilegeHint(fmt.Errorf("%s failed: %w (output: %s)", strings.Join(args, " "), err, strings.TrimSpace(string(output))), string(output))
+	}
+	return string(output), nil
+}
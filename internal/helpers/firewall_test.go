@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestDetectFirewall(t *testing.T) {
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	installed := map[string]bool{}
+	lookPath = func(file string) (string, error) {
+		if installed[file] {
+			return "/usr/sbin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	runner := useMockRunner(t)
+	ctx := context.Background()
+	if firewall, err := DetectFirewall(ctx); err != nil || firewall != "" {
+		t.Errorf("expected no firewall, got %q, %v", firewall, err)
+	}
+
+	installed["ufw"] = true
+	runner.Respond("Status: inactive\n", nil, "ufw", "status")
+	if firewall, err := DetectFirewall(ctx); err != nil || firewall != "" {
+		t.Errorf("expected an inactive ufw to be ignored, got %q, %v", firewall, err)
+	}
+
+	installed["firewall-cmd"] = true
+	runner.Respond("running\n", nil, "firewall-cmd", "--state")
+	if firewall, err := DetectFirewall(ctx); err != nil || firewall != FirewallFirewalld {
+		t.Errorf("expected firewalld, got %q, %v", firewall, err)
+	}
+
+	runner.Respond("Status: active\n", nil, "ufw", "status")
+	if firewall, err := DetectFirewall(ctx); err != nil || firewall != FirewallUFW {
+		t.Errorf("expected ufw, got %q, %v", firewall, err)
+	}
+}
+
+func TestOpenFirewallPort(t *testing.T) {
+	runner := useMockRunner(t)
+	ctx := context.Background()
+
+	if err := OpenFirewallPort(ctx, FirewallFirewalld, 8080, "tcp", "lxc-go-cli expose web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.ExpectCommands(
+		[]string{"firewall-cmd", "--add-port=8080/tcp"},
+		[]string{"firewall-cmd", "--permanent", "--add-port=8080/tcp"},
+	); err != nil {
+		t.Error(err)
+	}
+
+	expected := [][]string{{"ufw", "allow", "443/tcp", "comment", "lxc-go-cli expose web"}}
+	if commands := FirewallOpenCommands(FirewallUFW, 443, "tcp", "lxc-go-cli expose web"); !reflect.DeepEqual(commands, expected) {
+		t.Errorf("expected %v, got %v", expected, commands)
+	}
+	if err := OpenFirewallPort(ctx, "iptables", 80, "tcp", ""); err == nil {
+		t.Error("expected an unsupported firewall to fail")
+	}
+}
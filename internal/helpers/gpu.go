@@ -1,8 +1,9 @@
 package helpers
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/deji/lxc-go-cli/internal/logger"
@@ -13,11 +14,14 @@ import (
 type GPUStatus struct {
 	HasGPUDevice   bool
 	PrivilegedMode bool
+	// VirtualMachine instances get the GPU passed through and need no privileged mode
+	VirtualMachine bool
 }
 
-// IsEnabled returns true if GPU is fully enabled (both device and privileged mode)
+// IsEnabled returns true if GPU is fully enabled (both device and privileged
+// mode, or just the device for a virtual machine)
 func (s *GPUStatus) IsEnabled() bool {
-	return s.HasGPUDevice && s.PrivilegedMode
+	return s.HasGPUDevice && (s.PrivilegedMode || s.VirtualMachine)
 }
 
 // ContainerConfig represents the relevant parts of LXC container configuration
@@ -33,10 +37,9 @@ func GetContainerGPUStatus(containerName string) (*GPUStatus, error) {
 	}
 
 	// Use standard lxc config show command (outputs YAML by default)
-	cmd := exec.Command("lxc", "config", "show", containerName)
 	logger.Debug("Getting GPU status for container: lxc config show %s", containerName)
 
-	output, err := cmd.CombinedOutput()
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "show", containerName)
 	if err != nil {
 		logger.Debug("Command failed with output: %s", string(output))
 		return nil, fmt.Errorf("failed to get container config: %w (output: %s)", err, string(output))
@@ -44,7 +47,16 @@ func GetContainerGPUStatus(containerName string) (*GPUStatus, error) {
 
 	logger.Debug("Command succeeded with output length: %d bytes", len(output))
 
-	return parseGPUStatus(string(output))
+	status, err := parseGPUStatus(string(output))
+	if err != nil {
+		return nil, err
+	}
+	instanceType, err := GetInstanceType(context.Background(), containerName)
+	if err != nil {
+		logger.Debug("Could not get the type of '%s', assuming a container: %v", containerName, err)
+	}
+	status.VirtualMachine = instanceType == InstanceTypeVM
+	return status, nil
 }
 
 // parseGPUStatus parses the YAML output from lxc config show
@@ -107,8 +119,12 @@ func EnableContainerGPU(containerName string) error {
 	// Add GPU device if not present
 	if !status.HasGPUDevice {
 		logger.Debug("Adding GPU device to container '%s'", containerName)
-		cmd := exec.Command("lxc", "config", "device", "add", containerName, "gpu", "gpu")
-		output, err := cmd.CombinedOutput()
+		args := []string{"config", "device", "add", containerName, "gpu", "gpu"}
+		if status.VirtualMachine {
+			// VMs get the whole GPU through PCI passthrough
+			args = append(args, "gputype=physical")
+		}
+		output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
 		if err != nil {
 			logger.Debug("Failed to add GPU device: %s", string(output))
 			return fmt.Errorf("failed to add GPU device: %w (output: %s)", err, string(output))
@@ -116,11 +132,10 @@ func EnableContainerGPU(containerName string) error {
 		logger.Debug("GPU device added successfully")
 	}
 
-	// Set privileged mode if not enabled
-	if !status.PrivilegedMode {
+	// Set privileged mode if not enabled; a VM's own kernel drives the GPU
+	if !status.PrivilegedMode && !status.VirtualMachine {
 		logger.Debug("Setting privileged mode for container '%s'", containerName)
-		cmd := exec.Command("lxc", "config", "set", containerName, "security.privileged", "true")
-		output, err := cmd.CombinedOutput()
+		output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "set", containerName, "security.privileged", "true")
 		if err != nil {
 			logger.Debug("Failed to set privileged mode: %s", string(output))
 			return fmt.Errorf("failed to set privileged mode: %w (output: %s)", err, string(output))
@@ -155,8 +170,7 @@ func DisableContainerGPU(containerName string) error {
 	// Remove GPU device if present
 	if status.HasGPUDevice {
 		logger.Debug("Removing GPU device from container '%s'", containerName)
-		cmd := exec.Command("lxc", "config", "device", "remove", containerName, "gpu")
-		output, err := cmd.CombinedOutput()
+		output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "device", "remove", containerName, "gpu")
 		if err != nil {
 			logger.Debug("Failed to remove GPU device: %s", string(output))
 			return fmt.Errorf("failed to remove GPU device: %w (output: %s)", err, string(output))
@@ -167,8 +181,7 @@ func DisableContainerGPU(containerName string) error {
 	// Disable privileged mode if enabled
 	if status.PrivilegedMode {
 		logger.Debug("Disabling privileged mode for container '%s'", containerName)
-		cmd := exec.Command("lxc", "config", "set", containerName, "security.privileged", "false")
-		output, err := cmd.CombinedOutput()
+		output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "set", containerName, "security.privileged", "false")
 		if err != nil {
 			logger.Debug("Failed to disable privileged mode: %s", string(output))
 			return fmt.Errorf("failed to disable privileged mode: %w (output: %s)", err, string(output))
@@ -192,7 +205,9 @@ func FormatGPUStatus(status *GPUStatus) string {
 		result.WriteString("  GPU Device: absent\n")
 	}
 
-	if status.PrivilegedMode {
+	if status.VirtualMachine {
+		result.WriteString("  Privileged Mode: not needed (virtual machine, GPU passed through)\n")
+	} else if status.PrivilegedMode {
 		result.WriteString("  Privileged Mode: enabled\n")
 	} else {
 		result.WriteString("  Privileged Mode: disabled\n")
@@ -206,3 +221,115 @@ func FormatGPUStatus(status *GPUStatus) string {
 
 	return result.String()
 }
+
+// Sources of host GPU detection
+const (
+	GPUSourceLspci     = "lspci"
+	GPUSourceNvidiaSMI = "nvidia-smi"
+	GPUSourceRender    = "render node"
+)
+
+// driDir holds the DRM device nodes that a gpu device passes to containers
+var driDir = "/dev/dri"
+
+// HostGPU is a GPU found on the host
+type HostGPU struct {
+	Source      string
+	Description string
+}
+
+// DetectHostGPUs looks for GPUs on this machine with lspci, nvidia-smi and the
+// render nodes under /dev/dri. Missing tools are skipped.
+func DetectHostGPUs(ctx context.Context) []HostGPU {
+	var gpus []HostGPU
+
+	if output, err := runOutput(ctx, "lspci"); err != nil {
+		logger.Debug("lspci failed: %v", err)
+	} else {
+		gpus = append(gpus, parseLspciGPUs(string(output))...)
+	}
+
+	if output, err := runOutput(ctx, "nvidia-smi", "-L"); err != nil {
+		logger.Debug("nvidia-smi failed: %v", err)
+	} else {
+		gpus = append(gpus, parseNvidiaSMIGPUs(string(output))...)
+	}
+
+	nodes, _ := filepath.Glob(filepath.Join(driDir, "renderD*"))
+	for _, node := range nodes {
+		gpus = append(gpus, HostGPU{Source: GPUSourceRender, Description: node})
+	}
+
+	return gpus
+}
+
+// parseLspciGPUs picks the display controllers from lspci output such as
+// "00:02.0 VGA compatible controller: Intel Corporation UHD Graphics 630"
+func parseLspciGPUs(output string) []HostGPU {
+	var gpus []HostGPU
+	for _, line := range strings.Split(output, "\n") {
+		slot, rest, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		class, name, ok := strings.Cut(rest, ": ")
+		if !ok {
+			continue
+		}
+		switch class {
+		case "VGA compatible controller", "3D controller", "Display controller":
+			gpus = append(gpus, HostGPU{Source: GPUSourceLspci, Description: fmt.Sprintf("%s [%s]", name, slot)})
+		}
+	}
+	return gpus
+}
+
+// parseNvidiaSMIGPUs parses `nvidia-smi -L` output such as
+// "GPU 0: NVIDIA GeForce RTX 3090 (UUID: GPU-...)"
+func parseNvidiaSMIGPUs(output string) []HostGPU {
+	var gpus []HostGPU
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "GPU ") {
+			continue
+		}
+		if i := strings.Index(line, " (UUID:"); i >= 0 {
+			line = line[:i]
+		}
+		gpus = append(gpus, HostGPU{Source: GPUSourceNvidiaSMI, Description: line})
+	}
+	return gpus
+}
+
+// CheckHostGPUs returns an error unless a GPU a container can use was found:
+// a gpu device passes render nodes or NVIDIA devices, so a card listed by
+// lspci without a loaded driver is not enough
+func CheckHostGPUs(gpus []HostGPU) error {
+	var found []string
+	for _, gpu := range gpus {
+		if gpu.Source != GPUSourceLspci {
+			return nil
+		}
+		found = append(found, gpu.Description)
+	}
+	if len(found) > 0 {
+		return fmt.Errorf("found %s but no render node under %s and no working nvidia-smi; is the GPU driver loaded?",
+			strings.Join(found, ", "), driDir)
+	}
+	return fmt.Errorf("no GPU found on the host (checked lspci, nvidia-smi and %s)", driDir)
+}
+
+// FormatHostGPUs returns a formatted list of the GPUs found on the host
+func FormatHostGPUs(gpus []HostGPU) string {
+	var result strings.Builder
+
+	result.WriteString("Host GPUs:\n")
+	if len(gpus) == 0 {
+		result.WriteString("  none found\n")
+	}
+	for _, gpu := range gpus {
+		result.WriteString(fmt.Sprintf("  %s (%s)\n", gpu.Description, gpu.Source))
+	}
+
+	return result.String()
+}
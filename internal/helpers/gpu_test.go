@@ -1,6 +1,11 @@
 package helpers
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -228,6 +233,18 @@ func TestFormatGPUStatus(t *testing.T) {
 				"GPU Status: enabled",
 			},
 		},
+		{
+			name: "GPU passed through to a VM",
+			status: &GPUStatus{
+				HasGPUDevice:   true,
+				VirtualMachine: true,
+			},
+			expectedOutput: []string{
+				"GPU Device: present",
+				"Privileged Mode: not needed (virtual machine",
+				"GPU Status: enabled",
+			},
+		},
 		{
 			name: "GPU fully disabled",
 			status: &GPUStatus{
@@ -401,3 +418,60 @@ func TestDisableContainerGPU(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestDetectHostGPUs(t *testing.T) {
+	driDir = t.TempDir()
+	t.Cleanup(func() { driDir = "/dev/dri" })
+	for _, node := range []string{"card0", "renderD128"} {
+		if err := os.WriteFile(filepath.Join(driDir, node), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock := useMockRunner(t)
+	mock.Respond(`00:02.0 VGA compatible controller: Intel Corporation UHD Graphics 630 (rev 02)
+00:1f.3 Audio device: Intel Corporation Cannon Lake PCH cAVS (rev 10)
+01:00.0 3D controller: NVIDIA Corporation GA102 [GeForce RTX 3090] (rev a1)
+`, nil, "lspci")
+	mock.Respond("GPU 0: NVIDIA GeForce RTX 3090 (UUID: GPU-5c3b1a2e)\n", nil, "nvidia-smi", "-L")
+
+	gpus := DetectHostGPUs(context.Background())
+	expected := []HostGPU{
+		{Source: GPUSourceLspci, Description: "Intel Corporation UHD Graphics 630 (rev 02) [00:02.0]"},
+		{Source: GPUSourceLspci, Description: "NVIDIA Corporation GA102 [GeForce RTX 3090] (rev a1) [01:00.0]"},
+		{Source: GPUSourceNvidiaSMI, Description: "GPU 0: NVIDIA GeForce RTX 3090"},
+		{Source: GPUSourceRender, Description: filepath.Join(driDir, "renderD128")},
+	}
+	if !reflect.DeepEqual(gpus, expected) {
+		t.Errorf("expected %+v, got %+v", expected, gpus)
+	}
+	if !strings.Contains(FormatHostGPUs(gpus), "GPU 0: NVIDIA GeForce RTX 3090 (nvidia-smi)") {
+		t.Errorf("unexpected format:\n%s", FormatHostGPUs(gpus))
+	}
+
+	// Missing tools are skipped
+	mock.Respond("", fmt.Errorf("executable file not found"), "lspci")
+	mock.Respond("", fmt.Errorf("executable file not found"), "nvidia-smi", "-L")
+	if gpus := DetectHostGPUs(context.Background()); len(gpus) != 1 || gpus[0].Source != GPUSourceRender {
+		t.Errorf("expected only the render node, got %+v", gpus)
+	}
+}
+
+func TestCheckHostGPUs(t *testing.T) {
+	if err := CheckHostGPUs(nil); err == nil || !strings.Contains(err.Error(), "no GPU found") {
+		t.Errorf("expected no GPU error, got %v", err)
+	}
+
+	lspciOnly := []HostGPU{{Source: GPUSourceLspci, Description: "NVIDIA Corporation GA102 [01:00.0]"}}
+	if err := CheckHostGPUs(lspciOnly); err == nil || !strings.Contains(err.Error(), "driver loaded") {
+		t.Errorf("expected missing driver error, got %v", err)
+	}
+
+	usable := append(lspciOnly, HostGPU{Source: GPUSourceNvidiaSMI, Description: "GPU 0: NVIDIA GeForce RTX 3090"})
+	if err := CheckHostGPUs(usable); err != nil {
+		t.Errorf("expected usable GPU, got %v", err)
+	}
+	if !strings.Contains(FormatHostGPUs(nil), "none found") {
+		t.Error("expected none found")
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/deji/lxc-go-cli/internal/logger"
@@ -32,8 +32,7 @@ func ParseImageString(image string) (distro, release, arch string) {
 
 // IsBtrfsAvailable checks if Btrfs is available as a storage backend
 func IsBtrfsAvailable() bool {
-	cmd := exec.Command("lxc", "storage", "list")
-	out, err := cmd.CombinedOutput()
+	out, err := Runner().RunWithOutput(context.Background(), "lxc", "storage", "list")
 	if err != nil {
 		return false
 	}
@@ -42,8 +41,7 @@ func IsBtrfsAvailable() bool {
 
 // GetDefaultStoragePoolType returns the type of the default storage pool
 func GetDefaultStoragePoolType() string {
-	cmd := exec.Command("lxc", "storage", "show", "default")
-	out, err := cmd.CombinedOutput()
+	out, err := Runner().RunWithOutput(context.Background(), "lxc", "storage", "show", "default")
 	if err != nil {
 		return ""
 	}
@@ -57,15 +55,18 @@ func GetDefaultStoragePoolType() string {
 
 // StoragePool represents a storage pool from LXC
 type StoragePool struct {
-	Name   string `json:"name"`
-	Driver string `json:"driver"`
+	Name        string            `json:"name"`
+	Driver      string            `json:"driver"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Config      map[string]string `json:"config"`
+	UsedBy      []string          `json:"used_by"`
 }
 
 // GetBtrfsStoragePools returns a list of existing Btrfs storage pools
 func GetBtrfsStoragePools() []string {
 	// Use JSON format for reliable parsing
-	cmd := exec.Command("lxc", "storage", "list", "-f", "json")
-	out, err := cmd.CombinedOutput()
+	out, err := Runner().RunWithOutput(context.Background(), "lxc", "storage", "list", "-f", "json")
 	if err != nil {
 		// Fallback to table format if JSON fails
 		return getBtrfsPoolsFromTable()
@@ -100,8 +101,7 @@ func parseBtrfsPoolsFromJSON(jsonOutput string) []string {
 
 // getBtrfsPoolsFromTable is a fallback method using table format
 func getBtrfsPoolsFromTable() []string {
-	cmd := exec.Command("lxc", "storage", "list")
-	out, err := cmd.CombinedOutput()
+	out, err := Runner().RunWithOutput(context.Background(), "lxc", "storage", "list")
 	if err != nil {
 		return nil
 	}
@@ -144,8 +144,7 @@ func parseBtrfsPoolsFromTable(tableOutput string) []string {
 
 // CreateBtrfsStoragePool creates a new Btrfs storage pool
 func CreateBtrfsStoragePool(name string) error {
-	cmd := exec.Command("lxc", "storage", "create", name, "btrfs")
-	return cmd.Run()
+	return Runner().Run(context.Background(), "lxc", "storage", "create", name, "btrfs")
 }
 
 // GetOrCreateBtrfsPool returns an existing Btrfs pool or creates a new one
@@ -172,12 +171,11 @@ func GetOrCreateBtrfsPool() (string, error) {
 	return poolName, nil
 }
 
-// ContainerExists checks if a container exists
+// ContainerExists checks if an instance exists, container or virtual
+// machine; GetInstanceType tells the two apart
 func ContainerExists(name string) bool {
-	cmd := exec.Command("lxc", "list", name, "--format", "csv")
-
 	// For debugging, capture output
-	output, err := cmd.CombinedOutput()
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "list", name, "--format", "csv")
 
 	// Debug output using structured logging
 	logger.Debug("Checking container existence for '%s'", name)
@@ -195,19 +193,79 @@ func ContainerExists(name string) bool {
 
 // CreateContainer creates a new LXC container
 func CreateContainer(name, distro, release, arch, storagePool string) error {
+	return launchContainer(name, distro, release, storagePool)
+}
+
+// CreateEphemeralContainer creates a container that LXD deletes when it stops
+func CreateEphemeralContainer(name, distro, release, arch, storagePool string) error {
+	return LaunchInstance(name, distro, release, arch, storagePool, LaunchOptions{Ephemeral: true})
+}
+
+// CreateVirtualMachine creates a virtual machine instead of a container
+func CreateVirtualMachine(name, distro, release, arch, storagePool string) error {
+	return LaunchInstance(name, distro, release, arch, storagePool, LaunchOptions{VM: true})
+}
+
+// LaunchOptions are the optional ways of launching an instance
+type LaunchOptions struct {
+	// Ephemeral instances are deleted when they stop
+	Ephemeral bool
+	// VM launches a virtual machine instead of a container
+	VM bool
+	// Target is the cluster member to place the instance on; empty lets LXD choose
+	Target string
+	// Config holds instance config keys to set at launch, e.g. limits.cpu
+	Config map[string]string
+	// RootSize limits the root disk; empty keeps the profile's size
+	RootSize string
+	// IPv6 sets the NIC's IPv6 settings; the zero value keeps the profile's
+	IPv6 IPv6Config
+}
+
+// LaunchInstance creates a container, or a VM, with the given options
+func LaunchInstance(name, distro, release, arch, storagePool string, opts LaunchOptions) error {
+	var flags []string
+	if opts.Ephemeral {
+		flags = append(flags, "--ephemeral")
+	}
+	if opts.VM {
+		flags = append(flags, "--vm")
+	}
+	if opts.Target != "" {
+		flags = append(flags, "--target", opts.Target)
+	}
+	keys := make([]string, 0, len(opts.Config))
+	for key := range opts.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		flags = append(flags, "--config", key+"="+opts.Config[key])
+	}
+	if opts.RootSize != "" {
+		flags = append(flags, "--device", "root,size="+opts.RootSize)
+	}
+	if override := opts.IPv6.DeviceOverride(); override != "" {
+		flags = append(flags, "--device", override)
+	}
+	return launchContainer(name, distro, release, storagePool, flags...)
+}
+
+// launchContainer runs lxc launch for a new instance, adding flags such as
+// --ephemeral, --vm or --target
+func launchContainer(name, distro, release, storagePool string, flags ...string) error {
 	// Create container with specific storage pool
 	// LXC expects format: lxc launch remote:image container_name
 	// For ubuntu:24.04:amd64, we need to use: ubuntu:24.04
 	imageName := fmt.Sprintf("%s:%s", distro, release)
 
-	args := []string{"launch", imageName, name, "--storage", storagePool}
-	cmd := exec.Command("lxc", args...)
+	args := append([]string{"launch", imageName, name, "--storage", storagePool}, flags...)
 
 	// Debug output
 	logger.Debug("Executing: lxc %v", args)
 
 	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
 	if err != nil {
 		logger.Debug("Command failed with output: %s", string(output))
 		return fmt.Errorf("lxc launch failed: %w", err)
@@ -219,13 +277,11 @@ func CreateContainer(name, distro, release, arch, storagePool string) error {
 
 // StartContainer starts an existing container
 func StartContainer(name string) error {
-	cmd := exec.Command("lxc", "start", name)
-
 	// Debug output
 	logger.Debug("Starting container: lxc start %s", name)
 
 	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "start", name)
 	if err != nil {
 		logger.Debug("Start failed with output: %s", string(output))
 		return fmt.Errorf("lxc start failed: %w", err)
@@ -236,14 +292,12 @@ func StartContainer(name string) error {
 }
 
 // RestartContainer restarts an existing container
-func RestartContainer(name string) error {
-	cmd := exec.Command("lxc", "restart", name)
-
+func RestartContainer(ctx context.Context, name string) error {
 	// Debug output
 	logger.Debug("Restarting container: lxc restart %s", name)
 
 	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+	output, err := Runner().RunWithOutput(ctx, "lxc", "restart", name)
 	if err != nil {
 		logger.Debug("Restart failed with output: %s", string(output))
 		return fmt.Errorf("lxc restart failed: %w", err)
@@ -253,23 +307,143 @@ func RestartContainer(name string) error {
 	return nil
 }
 
-// RunInContainer executes a command inside a container
-func RunInContainer(containerName string, args ...string) error {
-	cmdArgs := append([]string{"exec", containerName, "--"}, args...)
-	cmd := exec.Command("lxc", cmdArgs...)
+// StopContainer stops a running container
+func StopContainer(name string) error {
+	// Debug output
+	logger.Debug("Stopping container: lxc stop %s", name)
 
-	logger.Debug("Executing in container '%s': lxc exec %s -- %v", containerName, containerName, args)
+	// Capture both stdout and stderr
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "stop", name)
+	if err != nil {
+		logger.Debug("Stop failed with output: %s", string(output))
+		return fmt.Errorf("lxc stop failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
 
-	output, err := cmd.CombinedOutput()
+	logger.Debug("Stop succeeded with output: %s", string(output))
+	return nil
+}
+
+// FreezeContainer freezes all processes of a running container
+func FreezeContainer(name string) error {
+	// Debug output
+	logger.Debug("Freezing container: lxc pause %s", name)
+
+	// Capture both stdout and stderr
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "pause", name)
 	if err != nil {
-		logger.Debug("Command failed with output: %s", string(output))
-		return fmt.Errorf("command failed: %w (output: %s)", err, string(output))
+		logger.Debug("Pause failed with output: %s", string(output))
+		return fmt.Errorf("lxc pause failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
 	}
 
-	logger.Debug("Command succeeded with output: %s", string(output))
+	logger.Debug("Pause succeeded with output: %s", string(output))
+	return nil
+}
+
+// UnfreezeContainer thaws a frozen container; lxc start resumes frozen instances
+func UnfreezeContainer(name string) error {
+	// Debug output
+	logger.Debug("Unfreezing container: lxc start %s", name)
+
+	// Capture both stdout and stderr
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "start", name)
+	if err != nil {
+		logger.Debug("Unfreeze failed with output: %s", string(output))
+		return fmt.Errorf("lxc start failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	logger.Debug("Unfreeze succeeded with output: %s", string(output))
+	return nil
+}
+
+// DeleteContainer deletes a container along with the custom volume holding
+// its Docker data, if any, and what the tool set up for it on the host and in
+// the password store; force also deletes a running container
+func DeleteContainer(name string, force bool) error {
+	args := []string{"delete", name}
+	if force {
+		args = append(args, "--force")
+	}
+
+	// The Docker volume and the host resources outlive the container unless
+	// deleted with it; a trashed container's are kept under its original name
+	config, _ := readContainerConfig(context.Background(), name)
+	pool, volume, hasDockerVolume := dockerVolumeIn(config)
+	owner := name
+	if original := config.Config[TrashOriginalKey]; original != "" {
+		owner = original
+	}
+
+	// Debug output
+	logger.Debug("Deleting container: lxc %v", args)
+
+	// Capture both stdout and stderr
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
+	if err != nil {
+		logger.Debug("Delete failed with output: %s", string(output))
+		return fmt.Errorf("lxc delete failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	logger.Debug("Delete succeeded with output: %s", string(output))
+	if hasDockerVolume {
+		logger.Debug("Deleting Docker volume '%s' from pool '%s'", volume, pool)
+		if err := DeleteStorageVolume(context.Background(), pool, volume); err != nil {
+			return err
+		}
+	}
+	releaseContainerResources(context.Background(), owner, config)
+	return nil
+}
+
+// AddContainerDevice adds a device with the given properties to a container
+func AddContainerDevice(containerName, deviceName, deviceType string, properties map[string]string) error {
+	args := deviceAddArgs(containerName, deviceName, deviceType, properties)
+	logger.Debug("Adding device: lxc %v", args)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
+	if err != nil {
+		logger.Debug("Device add failed with output: %s", string(output))
+		return fmt.Errorf("failed to add device '%s' to container '%s': %w (output: %s)", deviceName, containerName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// deviceAddArgs returns the lxc arguments that add a device, with its
+// properties in a stable order
+func deviceAddArgs(containerName, deviceName, deviceType string, properties map[string]string) []string {
+	args := []string{"config", "device", "add", containerName, deviceName, deviceType}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, key+"="+properties[key])
+	}
+	return args
+}
+
+// RemoveContainerDevice removes a device from a container
+func RemoveContainerDevice(containerName, deviceName string) error {
+	logger.Debug("Removing device: lxc config device remove %s %s", containerName, deviceName)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "device", "remove", containerName, deviceName)
+	if err != nil {
+		logger.Debug("Device remove failed with output: %s", string(output))
+		return fmt.Errorf("failed to remove device '%s' from container '%s': %w (output: %s)", deviceName, containerName, err, strings.TrimSpace(string(output)))
+	}
 	return nil
 }
 
+// RunInContainer executes a command inside a container
+func RunInContainer(ctx context.Context, containerName string, args ...string) error {
+	cmdArgs := append([]string{"lxc", "exec", containerName, "--"}, args...)
+
+	logger.Debug("Executing in container '%s': lxc exec %s -- %v", containerName, containerName, args)
+
+	// Output is streamed as it arrives so long installs show progress
+	return runStreamed(ctx, fmt.Sprintf("[%s] ", containerName), cmdArgs...)
+}
+
 // EnsureBtrfsStoragePool ensures a Btrfs storage pool exists and is set as default
 // This is kept for backward compatibility but is not the preferred approach
 func EnsureBtrfsStoragePool() error {
@@ -309,8 +483,7 @@ func EnsureBtrfsStoragePool() error {
 
 // SetDefaultStoragePool sets the specified pool as the default
 func SetDefaultStoragePool(name string) error {
-	cmd := exec.Command("lxc", "storage", "set-default", name)
-	return cmd.Run()
+	return Runner().Run(context.Background(), "lxc", "storage", "set-default", name)
 }
 
 // RunHostCommand executes a command directly on the host with context support
@@ -319,37 +492,26 @@ func RunHostCommand(ctx context.Context, args ...string) error {
 		return fmt.Errorf("no command provided")
 	}
 
-	// Create command with context for timeout/cancellation support
-	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-
 	logger.Debug("Executing host command: %v", args)
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Debug("Host command failed with output: %s", string(output))
-		return fmt.Errorf("command failed: %w (output: %s)", err, string(output))
-	}
+	// Context supports timeout/cancellation; output is streamed as it arrives
+	return runStreamed(ctx, "[host] ", args...)
+}
 
-	logger.Debug("Host command succeeded with output: %s", string(output))
-	return nil
+// DockerSecurityConfig holds the security settings needed for Docker to work in LXC containers
+var DockerSecurityConfig = map[string]string{
+	"security.nesting":                     "true",
+	"security.syscalls.intercept.mknod":    "true",
+	"security.syscalls.intercept.setxattr": "true",
 }
 
 // ConfigureContainerSecurity sets up security settings needed for Docker
 func ConfigureContainerSecurity(containerName string) error {
-	// Security settings needed for Docker to work in LXC containers
-	settings := map[string]string{
-		"security.nesting":                     "true",
-		"security.syscalls.intercept.mknod":    "true",
-		"security.syscalls.intercept.setxattr": "true",
-	}
-
-	for key, value := range settings {
-		cmd := exec.Command("lxc", "config", "set", containerName, key, value)
-
+	for key, value := range DockerSecurityConfig {
 		// Debug output
 		logger.Debug("Setting %s=%s for container %s", key, value, containerName)
 
-		output, err := cmd.CombinedOutput()
+		output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "set", containerName, key, value)
 		if err != nil {
 			logger.Debug("Failed to set %s: %s", key, string(output))
 			return fmt.Errorf("failed to set %s: %w", key, err)
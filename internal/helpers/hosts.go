@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Lines around the block 'hosts sync' owns in a hosts file; everything
+// outside them is left alone
+const (
+	HostsBlockBegin = "# BEGIN lxc-go-cli managed containers"
+	HostsBlockEnd   = "# END lxc-go-cli managed containers"
+)
+
+// HostHostsFile is the host's hosts file
+var HostHostsFile = "/etc/hosts"
+
+// ContainerHostsFile is the hosts file inside containers
+const ContainerHostsFile = "/etc/hosts"
+
+// HostsEntry is one line of a hosts file: an address and the names it has
+type HostsEntry struct {
+	IP    string
+	Names []string
+}
+
+// HostsEntries returns an entry per address of the managed containers, IPv4
+// and global IPv6, sorted by name. Containers without an address, such as
+// stopped ones, have none.
+func HostsEntries(states []ContainerState) []HostsEntry {
+	var entries []HostsEntry
+	for _, state := range WithoutTrash(states) {
+		if !IsManagedContainer(state.Config) {
+			continue
+		}
+		if state.IPv4 != "" {
+			entries = append(entries, HostsEntry{IP: state.IPv4, Names: []string{state.Name}})
+		}
+		for _, ip := range state.IPv6Addresses {
+			entries = append(entries, HostsEntry{IP: ip, Names: []string{state.Name}})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Names[0] < entries[j].Names[0] })
+	return entries
+}
+
+// HostsBlock renders entries as the block 'hosts sync' writes
+func HostsBlock(entries []HostsEntry) string {
+	width := 0
+	for _, entry := range entries {
+		width = max(width, len(entry.IP))
+	}
+	var sb strings.Builder
+	sb.WriteString(HostsBlockBegin + "\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "%-*s  %s\n", width, entry.IP, strings.Join(entry.Names, " "))
+	}
+	sb.WriteString(HostsBlockEnd + "\n")
+	return sb.String()
+}
+
+// ReplaceHostsBlock puts block in place of the one already in content, or
+// appends it when there is none
+func ReplaceHostsBlock(content, block string) string {
+	begin := strings.Index(content, HostsBlockBegin+"\n")
+	if begin >= 0 {
+		if end := strings.Index(content[begin:], HostsBlockEnd); end >= 0 {
+			rest := content[begin+end+len(HostsBlockEnd):]
+			return content[:begin] + block + strings.TrimPrefix(rest, "\n")
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + block
+}
+
+// ReadContainerHosts returns the hosts file of a running container
+func ReadContainerHosts(ctx context.Context, name string) (string, error) {
+	output, err := runOutput(ctx, "lxc", "exec", name, "--", "cat", ContainerHostsFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s in container '%s': %w", ContainerHostsFile, name, err)
+	}
+	return string(output), nil
+}
+
+// WriteContainerHosts replaces the hosts file of a running container
+func WriteContainerHosts(ctx context.Context, name, content string) error {
+	return WriteContainerFile(ctx, name, ContainerHostsFile, "644", []byte(content))
+}
+
+// ReadHostHosts returns the host's hosts file
+func ReadHostHosts() (string, error) {
+	data, err := os.ReadFile(HostHostsFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", HostHostsFile, err)
+	}
+	return string(data), nil
+}
+
+// WriteHostHosts replaces the host's hosts file in place, keeping its
+// owner and mode; it needs root
+func WriteHostHosts(content string) error {
+	if err := os.WriteFile(HostHostsFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s (run with sudo): %w", HostHostsFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHostsEntries(t *testing.T) {
+	states := []ContainerState{
+		{Name: "web", IPv4: "10.0.0.2", IPv6Addresses: []string{"fd42::2"}, Config: map[string]string{ManagedMarkerKey: "true"}},
+		{Name: "db", IPv4: "10.0.0.3", Config: map[string]string{ManagedMarkerKey: "true"}},
+		{Name: "stopped", Config: map[string]string{ManagedMarkerKey: "true"}},
+		{Name: "other", IPv4: "10.0.0.9", Config: map[string]string{}},
+		{Name: "trash-20250101-120000-x", IPv4: "10.0.0.8", Config: map[string]string{ManagedMarkerKey: "true", TrashedKey: "2025-01-01T12:00:00Z"}},
+	}
+	entries := HostsEntries(states)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.IP+" "+strings.Join(entry.Names, " "))
+	}
+	want := "10.0.0.3 db,10.0.0.2 web,fd42::2 web"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestReplaceHostsBlock(t *testing.T) {
+	block := HostsBlock([]HostsEntry{{IP: "10.0.0.2", Names: []string{"web"}}})
+
+	appended := ReplaceHostsBlock("127.0.0.1 localhost", block)
+	if appended != "127.0.0.1 localhost\n"+block {
+		t.Errorf("expected the block to be appended, got:\n%s", appended)
+	}
+
+	existing := "127.0.0.1 localhost\n" + HostsBlockBegin + "\n10.0.0.7  gone\n" + HostsBlockEnd + "\n::1 ip6-localhost\n"
+	replaced := ReplaceHostsBlock(existing, block)
+	if replaced != "127.0.0.1 localhost\n"+block+"::1 ip6-localhost\n" {
+		t.Errorf("expected the block to be replaced in place, got:\n%s", replaced)
+	}
+	if ReplaceHostsBlock(replaced, block) != replaced {
+		t.Error("expected replacing the same block to change nothing")
+	}
+}
+
+func TestContainerHostsFile(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("127.0.0.1 localhost\n", nil, "lxc", "exec", "web", "--", "cat", "/etc/hosts")
+	content, err := ReadContainerHosts(context.Background(), "web")
+	if err != nil || content != "127.0.0.1 localhost\n" {
+		t.Errorf("expected the hosts file, got %q (%v)", content, err)
+	}
+
+	if err := WriteContainerHosts(context.Background(), "web", content); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(runner.Stdins) != 1 {
+		t.Errorf("expected the file to be pushed on stdin, got %d pushes", len(runner.Stdins))
+	}
+}
+
+func TestHostHostsFile(t *testing.T) {
+	old := HostHostsFile
+	HostHostsFile = filepath.Join(t.TempDir(), "hosts")
+	defer func() { HostHostsFile = old }()
+
+	if _, err := ReadHostHosts(); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if err := WriteHostHosts("127.0.0.1 localhost\n"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if data, _ := os.ReadFile(HostHostsFile); string(data) != "127.0.0.1 localhost\n" {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
@@ -0,0 +1,396 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Files the host's subordinate id ranges and users are read from
+var (
+	hostSubUIDFile = "/etc/subuid"
+	hostSubGIDFile = "/etc/subgid"
+	hostPasswdFile = "/etc/passwd"
+	// snapLXDDir only exists with the LXD snap, which allocates container
+	// ids itself rather than from /etc/subuid and /etc/subgid
+	snapLXDDir = "/var/snap/lxd/common/lxd"
+)
+
+const (
+	// ContainerIDCount is the number of ids an unprivileged container maps;
+	// Docker inside it needs the full 16-bit range for image layers
+	ContainerIDCount = 65536
+
+	// DefaultSubIDStart and DefaultSubIDCount are the range added for a daemon
+	// user without one, matching LXD's own default allocation
+	DefaultSubIDStart = 1000000
+	DefaultSubIDCount = 1000000000
+
+	// AppUserID is the uid and gid of the 'app' user inside containers, which
+	// host folder owners are mapped to
+	AppUserID = 1000
+
+	// RawIdmapKey holds extra id mappings for a container
+	RawIdmapKey = "raw.idmap"
+)
+
+// SubIDRange is one line of /etc/subuid or /etc/subgid
+type SubIDRange struct {
+	Owner string
+	Start int64
+	Count int64
+}
+
+// Contains reports whether the range covers id
+func (r SubIDRange) Contains(id int64) bool {
+	return id >= r.Start && id < r.Start+r.Count
+}
+
+// String returns the range as written in /etc/subuid
+func (r SubIDRange) String() string {
+	return fmt.Sprintf("%s:%d:%d", r.Owner, r.Start, r.Count)
+}
+
+// HostMount is a host folder shared into a container with a disk device
+type HostMount struct {
+	Container string
+	Device    string
+	Source    string
+	Path      string
+	// RawIdmap is the container's current raw.idmap, empty if unset
+	RawIdmap string
+}
+
+// IdmapFix is one change doctor --fix-idmap makes: a line appended to a
+// subordinate id file, or a raw.idmap value set on a container
+type IdmapFix struct {
+	Reason    string
+	File      string
+	Line      string
+	Container string
+	RawIdmap  string
+}
+
+// String describes the change for people reviewing it before it is applied
+func (f IdmapFix) String() string {
+	if f.Container != "" {
+		return fmt.Sprintf("set raw.idmap on '%s' to %q (%s)", f.Container, f.RawIdmap, f.Reason)
+	}
+	return fmt.Sprintf("append '%s' to %s (%s)", f.Line, f.File, f.Reason)
+}
+
+// ParseSubIDs parses /etc/subuid or /etc/subgid content; malformed lines are skipped
+func ParseSubIDs(content string) []SubIDRange {
+	var ranges []SubIDRange
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) != 3 {
+			logger.Debug("Skipping malformed subordinate id line: %q", line)
+			continue
+		}
+		start, err1 := strconv.ParseInt(fields[1], 10, 64)
+		count, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || count <= 0 {
+			logger.Debug("Skipping malformed subordinate id line: %q", line)
+			continue
+		}
+		ranges = append(ranges, SubIDRange{Owner: fields[0], Start: start, Count: count})
+	}
+	return ranges
+}
+
+// readSubIDs reads a subordinate id file; a missing file has no ranges
+func readSubIDs(path string) ([]SubIDRange, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return ParseSubIDs(string(data)), nil
+}
+
+// IdmapDaemonUsers returns the users whose ranges the container daemon maps
+// containers into: root, plus the lxd user distribution packages run it as
+func IdmapDaemonUsers() []string {
+	users := []string{"root"}
+	data, err := os.ReadFile(hostPasswdFile)
+	if err != nil {
+		return users
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, _, _ := strings.Cut(line, ":"); name == DriverLXD {
+			users = append(users, DriverLXD)
+		}
+	}
+	return users
+}
+
+// IsSnapLXD reports whether the containers are run by the LXD snap
+func IsSnapLXD() bool {
+	if CurrentDriver() != DriverLXD {
+		return false
+	}
+	_, err := os.Stat(snapLXDDir)
+	return err == nil
+}
+
+// Overlaps reports whether the range shares ids with other
+func (r SubIDRange) Overlaps(other SubIDRange) bool {
+	return r.Start < other.Start+other.Count && other.Start < r.Start+r.Count
+}
+
+// freeRange moves a new range past every range in taken it overlaps, so the
+// ids it hands out are not already another user's
+func freeRange(ranges []SubIDRange, r SubIDRange) SubIDRange {
+	for moved := true; moved; {
+		moved = false
+		for _, existing := range ranges {
+			if r.Overlaps(existing) {
+				r.Start = existing.Start + existing.Count
+				moved = true
+			}
+		}
+	}
+	return r
+}
+
+// largestRange returns the biggest range owned by owner
+func largestRange(ranges []SubIDRange, owner string) (SubIDRange, bool) {
+	var best SubIDRange
+	found := false
+	for _, r := range ranges {
+		if r.Owner == owner && (!found || r.Count > best.Count) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// ownerCovers reports whether owner has a range covering id
+func ownerCovers(ranges []SubIDRange, owner string, id int64) bool {
+	for _, r := range ranges {
+		if r.Owner == owner && r.Contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// planSubIDFile returns the lines to append to one subordinate id file so
+// every daemon user has a range big enough for a container and root may map
+// each host folder owner id. New ranges are placed where they overlap no
+// range of another user; the daemon users share theirs, as LXD's packages set
+// them up.
+func planSubIDFile(file string, ranges []SubIDRange, users []string, mappedIDs []int64) []IdmapFix {
+	var fixes []IdmapFix
+	var taken []SubIDRange
+	for _, r := range ranges {
+		if !slices.Contains(users, r.Owner) {
+			taken = append(taken, r)
+		}
+	}
+	for _, user := range users {
+		r, ok := largestRange(ranges, user)
+		var added SubIDRange
+		var reason string
+		switch {
+		case !ok:
+			added = SubIDRange{Owner: user, Start: DefaultSubIDStart, Count: DefaultSubIDCount}
+			reason = fmt.Sprintf("%s has no subordinate ids, so unprivileged containers cannot start", user)
+		case r.Count < ContainerIDCount:
+			added = SubIDRange{Owner: user, Start: r.Start + r.Count, Count: DefaultSubIDCount}
+			reason = fmt.Sprintf("%s has only %d subordinate ids; Docker needs at least %d", user, r.Count, ContainerIDCount)
+		default:
+			continue
+		}
+		added = freeRange(taken, added)
+		fixes = append(fixes, IdmapFix{File: file, Line: added.String(), Reason: reason})
+	}
+
+	seen := make(map[int64]bool, len(mappedIDs))
+	for _, id := range mappedIDs {
+		if seen[id] || ownerCovers(ranges, "root", id) {
+			continue
+		}
+		seen[id] = true
+		fixes = append(fixes, IdmapFix{
+			File:   file,
+			Line:   SubIDRange{Owner: "root", Start: id, Count: 1}.String(),
+			Reason: fmt.Sprintf("raw.idmap can only map host id %d if root may use it", id),
+		})
+	}
+	return fixes
+}
+
+// RawIdmapFor returns a raw.idmap value mapping a host uid and gid to the
+// 'app' user, so files in a shared folder keep their host owner
+func RawIdmapFor(uid, gid int64) string {
+	if uid == gid {
+		return fmt.Sprintf("both %d %d", uid, AppUserID)
+	}
+	return fmt.Sprintf("uid %d %d\ngid %d %d", uid, AppUserID, gid, AppUserID)
+}
+
+// HostFolderMounts returns the disk devices that share a host folder into
+// the given containers, skipping the root disk and storage volumes
+func HostFolderMounts(states []ContainerState) []HostMount {
+	var mounts []HostMount
+	for _, state := range states {
+		devices := make([]string, 0, len(state.Devices))
+		for name := range state.Devices {
+			devices = append(devices, name)
+		}
+		sort.Strings(devices)
+
+		for _, name := range devices {
+			device := state.Devices[name]
+			if device["type"] != "disk" || device["pool"] != "" || !strings.HasPrefix(device["source"], "/") {
+				continue
+			}
+			mounts = append(mounts, HostMount{
+				Container: state.Name,
+				Device:    name,
+				Source:    device["source"],
+				Path:      device["path"],
+				RawIdmap:  state.Config[RawIdmapKey],
+			})
+		}
+	}
+	return mounts
+}
+
+// hostPathOwner returns the uid and gid owning a host path. It shells out to
+// stat so the helpers still build for the macOS and Windows remote clients.
+func hostPathOwner(ctx context.Context, path string) (uid, gid int64, err error) {
+	output, err := runOutput(ctx, "stat", "-c", "%u:%g", path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	uidText, gidText, ok := strings.Cut(strings.TrimSpace(string(output)), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected stat output for %s: %q", path, string(output))
+	}
+	if uid, err = strconv.ParseInt(uidText, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("unexpected stat output for %s: %q", path, string(output))
+	}
+	if gid, err = strconv.ParseInt(gidText, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("unexpected stat output for %s: %q", path, string(output))
+	}
+	return uid, gid, nil
+}
+
+// PlanSubIDFixes returns the /etc/subuid and /etc/subgid lines needed for
+// unprivileged containers running Docker
+func PlanSubIDFixes() ([]IdmapFix, error) {
+	return planIdmapFixes(context.Background(), nil)
+}
+
+// PlanIdmapFixes returns every change needed for containers to map ids: the
+// subordinate id ranges of the daemon users, and a raw.idmap for each
+// container sharing a host folder without one. Containers that already set
+// raw.idmap are left alone.
+func PlanIdmapFixes(ctx context.Context) ([]IdmapFix, error) {
+	states, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	return planIdmapFixes(ctx, HostFolderMounts(states))
+}
+
+// planIdmapFixes plans subordinate id and raw.idmap changes for the given mounts
+func planIdmapFixes(ctx context.Context, mounts []HostMount) ([]IdmapFix, error) {
+	var uids, gids []int64
+	var rawFixes []IdmapFix
+	planned := make(map[string]bool)
+	for _, mount := range mounts {
+		if mount.RawIdmap != "" || planned[mount.Container] {
+			continue
+		}
+		uid, gid, err := hostPathOwner(ctx, mount.Source)
+		if err != nil {
+			logger.Debug("Skipping idmap for %s on '%s': %v", mount.Source, mount.Container, err)
+			continue
+		}
+		planned[mount.Container] = true
+		uids = append(uids, uid)
+		gids = append(gids, gid)
+		rawFixes = append(rawFixes, IdmapFix{
+			Container: mount.Container,
+			RawIdmap:  RawIdmapFor(uid, gid),
+			Reason:    fmt.Sprintf("%s is shared at %s and owned by %d:%d on the host", mount.Source, mount.Path, uid, gid),
+		})
+	}
+
+	// The snap ignores the subordinate id files, so only raw.idmap matters
+	if IsSnapLXD() {
+		logger.Debug("LXD runs from the snap; skipping %s and %s", hostSubUIDFile, hostSubGIDFile)
+		return rawFixes, nil
+	}
+
+	subuids, err := readSubIDs(hostSubUIDFile)
+	if err != nil {
+		return nil, err
+	}
+	subgids, err := readSubIDs(hostSubGIDFile)
+	if err != nil {
+		return nil, err
+	}
+
+	users := IdmapDaemonUsers()
+	fixes := planSubIDFile(hostSubUIDFile, subuids, users, uids)
+	fixes = append(fixes, planSubIDFile(hostSubGIDFile, subgids, users, gids)...)
+	return append(fixes, rawFixes...), nil
+}
+
+// ApplyIdmapFixes makes the planned changes. Subordinate id changes only
+// take effect once the container daemon is restarted, and raw.idmap changes
+// once the container is.
+func ApplyIdmapFixes(fixes []IdmapFix) error {
+	for _, fix := range fixes {
+		if fix.Container != "" {
+			if err := SetConfigValue(fix.Container, RawIdmapKey, fix.RawIdmap); err != nil {
+				return fmt.Errorf("failed to set raw.idmap on '%s': %w", fix.Container, err)
+			}
+			continue
+		}
+		if err := appendLine(fix.File, fix.Line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendLine adds a line to a file, creating it if needed
+func appendLine(path, line string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s (run as root): %w", path, err)
+	}
+	defer file.Close()
+
+	// Keep an existing last line that lacks a newline intact
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		data, err := os.ReadFile(path)
+		if err == nil && !strings.HasSuffix(string(data), "\n") {
+			line = "\n" + line
+		}
+	}
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	return nil
+}
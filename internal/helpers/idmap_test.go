@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useIdmapFiles points the subordinate id and passwd files at a temp dir
+func useIdmapFiles(t *testing.T, subuid, subgid, passwd string) {
+	dir := t.TempDir()
+	oldUID, oldGID, oldPasswd, oldSnap := hostSubUIDFile, hostSubGIDFile, hostPasswdFile, snapLXDDir
+	t.Cleanup(func() {
+		hostSubUIDFile, hostSubGIDFile, hostPasswdFile, snapLXDDir = oldUID, oldGID, oldPasswd, oldSnap
+	})
+	hostSubUIDFile = filepath.Join(dir, "subuid")
+	hostSubGIDFile = filepath.Join(dir, "subgid")
+	hostPasswdFile = filepath.Join(dir, "passwd")
+	snapLXDDir = filepath.Join(dir, "snap")
+
+	for path, content := range map[string]string{hostSubUIDFile: subuid, hostSubGIDFile: subgid, hostPasswdFile: passwd} {
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseSubIDs(t *testing.T) {
+	ranges := ParseSubIDs("# comment\nroot:1000000:1000000000\nbroken\nlxd:100000:abc\napp:100000:65536\n")
+	expected := []SubIDRange{{Owner: "root", Start: 1000000, Count: 1000000000}, {Owner: "app", Start: 100000, Count: 65536}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, ranges)
+	}
+	if !ranges[1].Contains(165535) || ranges[1].Contains(165536) {
+		t.Error("unexpected Contains result at the range boundary")
+	}
+}
+
+func TestRawIdmapFor(t *testing.T) {
+	if got := RawIdmapFor(1000, 1000); got != "both 1000 1000" {
+		t.Errorf("unexpected raw.idmap %q", got)
+	}
+	if got := RawIdmapFor(1001, 100); got != "uid 1001 1000\ngid 100 1000" {
+		t.Errorf("unexpected raw.idmap %q", got)
+	}
+}
+
+func TestHostFolderMounts(t *testing.T) {
+	states := []ContainerState{{
+		Name:   "web",
+		Config: map[string]string{RawIdmapKey: "both 1000 1000"},
+		Devices: map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": "default"},
+			"data": {"type": "disk", "path": "/data", "source": "/srv/data"},
+			"vol":  {"type": "disk", "path": "/vol", "pool": "default", "source": "vol1"},
+			"http": {"type": "proxy", "listen": "tcp:0.0.0.0:80"},
+		},
+	}}
+
+	expected := []HostMount{{Container: "web", Device: "data", Source: "/srv/data", Path: "/data", RawIdmap: "both 1000 1000"}}
+	if mounts := HostFolderMounts(states); !reflect.DeepEqual(mounts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, mounts)
+	}
+}
+
+func TestPlanIdmapFixes(t *testing.T) {
+	useIdmapFiles(t, "root:1000000:1000000000\n", "root:1000000:1000\n", "root:x:0:0::/root:/bin/bash\nlxd:x:999:999::/var/lib/lxd:/bin/false\n")
+	mock := useMockRunner(t)
+	mock.Respond("1001:1001\n", nil, "stat", "-c", "%u:%g", "/srv/data")
+
+	mounts := []HostMount{
+		{Container: "web", Device: "data", Source: "/srv/data", Path: "/data"},
+		{Container: "db", Device: "data", Source: "/srv/db", Path: "/data", RawIdmap: "both 1000 1000"},
+	}
+	fixes, err := planIdmapFixes(context.Background(), mounts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var described []string
+	for _, fix := range fixes {
+		described = append(described, fix.String())
+	}
+	expected := []string{
+		"append 'lxd:1000000:1000000000' to " + hostSubUIDFile,
+		"append 'root:1001:1' to " + hostSubUIDFile,
+		"append 'root:1001000:1000000000' to " + hostSubGIDFile,
+		"append 'lxd:1000000:1000000000' to " + hostSubGIDFile,
+		"append 'root:1001:1' to " + hostSubGIDFile,
+		`set raw.idmap on 'web' to "both 1001 1000"`,
+	}
+	if len(described) != len(expected) {
+		t.Fatalf("expected %d fixes, got:\n%s", len(expected), strings.Join(described, "\n"))
+	}
+	for i := range expected {
+		if !strings.HasPrefix(described[i], expected[i]) {
+			t.Errorf("fix %d: expected %q, got %q", i, expected[i], described[i])
+		}
+	}
+
+	// The db container already has a raw.idmap, so its folder is never inspected
+	for _, argv := range mock.Commands {
+		if strings.Contains(strings.Join(argv, " "), "/srv/db") {
+			t.Errorf("unexpected command for a mapped container: %v", argv)
+		}
+	}
+}
+
+func TestPlanSubIDFixesAvoidsOtherUsers(t *testing.T) {
+	// Regular users own ids where the default range for lxd would start
+	useIdmapFiles(t, "root:1000000:1000000000\napp:1000000:65536\nops:1065536:65536\n", "root:1000000:1000000000\n",
+		"root:x:0:0::/root:/bin/bash\nlxd:x:999:999::/var/lib/lxd:/bin/false\n")
+	fixes, err := PlanSubIDFixes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixes) != 2 || fixes[0].Line != "lxd:1131072:1000000000" || fixes[1].Line != "lxd:1000000:1000000000" {
+		t.Errorf("expected lxd to get ids no other user has, sharing root's where free, got %+v", fixes)
+	}
+}
+
+func TestPlanIdmapFixesSnap(t *testing.T) {
+	useIdmapFiles(t, "", "", "root:x:0:0::/root:/bin/bash\n")
+	if err := os.MkdirAll(snapLXDDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fixes, err := PlanSubIDFixes()
+	if err != nil || len(fixes) != 0 {
+		t.Errorf("expected the snap to need no subordinate ids, got %+v (%v)", fixes, err)
+	}
+}
+
+func TestPlanSubIDFixesConfigured(t *testing.T) {
+	useIdmapFiles(t, "root:1000000:1000000000\n", "root:1000000:1000000000\n", "root:x:0:0::/root:/bin/bash\n")
+	fixes, err := PlanSubIDFixes()
+	if err != nil || len(fixes) != 0 {
+		t.Errorf("expected no fixes, got %+v (%v)", fixes, err)
+	}
+}
+
+func TestApplyIdmapFixes(t *testing.T) {
+	useIdmapFiles(t, "root:1000000:1000000000", "", "")
+	mock := useMockRunner(t)
+
+	fixes := []IdmapFix{
+		{File: hostSubUIDFile, Line: "root:1000:1"},
+		{File: hostSubGIDFile, Line: "root:1000:1"},
+		{Container: "web", RawIdmap: "both 1000 1000"},
+	}
+	if err := ApplyIdmapFixes(fixes); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(hostSubUIDFile)
+	if string(data) != "root:1000000:1000000000\nroot:1000:1\n" {
+		t.Errorf("unexpected subuid content %q", string(data))
+	}
+	data, _ = os.ReadFile(hostSubGIDFile)
+	if string(data) != "root:1000:1\n" {
+		t.Errorf("unexpected subgid content %q", string(data))
+	}
+	if len(mock.Commands) != 1 || strings.Join(mock.Commands[0], " ") != "lxc config set web raw.idmap both 1000 1000" {
+		t.Errorf("unexpected commands %v", mock.Commands)
+	}
+}
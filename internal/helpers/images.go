@@ -0,0 +1,337 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// imagePollInterval is how often a running image download is checked; tests shorten it
+var imagePollInterval = 500 * time.Millisecond
+
+// LocalImage is an image in the server's image store
+type LocalImage struct {
+	Fingerprint  string
+	Aliases      []string
+	Description  string
+	Architecture string
+	Size         int64
+	// Cached images were downloaded by a launch and are expired by the server
+	Cached     bool
+	AutoUpdate bool
+	UploadedAt time.Time
+	LastUsedAt time.Time
+	// Source is where the image was downloaded from, if anywhere
+	Source ImageSource
+}
+
+// ImageSource identifies an image on an image server
+type ImageSource struct {
+	Server   string `json:"server"`
+	Protocol string `json:"protocol"`
+	Alias    string `json:"alias"`
+}
+
+// ImageProgress is the state of a running image download
+type ImageProgress struct {
+	// Stage is what is being downloaded, e.g. "metadata" or "rootfs"
+	Stage   string
+	Percent int
+	// Processed and Speed are in bytes and bytes per second, when the server reports them
+	Processed int64
+	Speed     int64
+}
+
+// String describes the progress for log messages
+func (p ImageProgress) String() string {
+	s := fmt.Sprintf("%d%%", p.Percent)
+	if p.Stage != "" {
+		s = p.Stage + " " + s
+	}
+	if p.Processed > 0 {
+		s += fmt.Sprintf(", %s", FormatBytes(p.Processed))
+	}
+	if p.Speed > 0 {
+		s += fmt.Sprintf(" at %s/s", FormatBytes(p.Speed))
+	}
+	return s
+}
+
+// lxdImage mirrors an entry of GET /1.0/images?recursion=1
+type lxdImage struct {
+	Fingerprint string `json:"fingerprint"`
+	Aliases     []struct {
+		Name string `json:"name"`
+	} `json:"aliases"`
+	Properties   map[string]string `json:"properties"`
+	Architecture string            `json:"architecture"`
+	Size         int64             `json:"size"`
+	Cached       bool              `json:"cached"`
+	AutoUpdate   bool              `json:"auto_update"`
+	UploadedAt   time.Time         `json:"uploaded_at"`
+	LastUsedAt   time.Time         `json:"last_used_at"`
+	UpdateSource *ImageSource      `json:"update_source"`
+}
+
+// ListImages returns the images in the server's image store, newest first
+func ListImages(ctx context.Context) ([]LocalImage, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/images?recursion=1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	return parseImages(output)
+}
+
+// parseImages parses the GET /1.0/images?recursion=1 response
+func parseImages(jsonOutput []byte) ([]LocalImage, error) {
+	var raw []lxdImage
+	if err := json.Unmarshal(jsonOutput, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse images: %w", err)
+	}
+
+	images := make([]LocalImage, 0, len(raw))
+	for _, image := range raw {
+		local := LocalImage{
+			Fingerprint:  image.Fingerprint,
+			Description:  image.Properties["description"],
+			Architecture: image.Architecture,
+			Size:         image.Size,
+			Cached:       image.Cached,
+			AutoUpdate:   image.AutoUpdate,
+			UploadedAt:   image.UploadedAt,
+			LastUsedAt:   image.LastUsedAt,
+		}
+		for _, alias := range image.Aliases {
+			local.Aliases = append(local.Aliases, alias.Name)
+		}
+		if image.UpdateSource != nil {
+			local.Source = *image.UpdateSource
+		}
+		images = append(images, local)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].UploadedAt.After(images[j].UploadedAt) })
+	return images, nil
+}
+
+// imageReference splits a launch image such as ubuntu:24.04 into its remote
+// and alias, as the current driver resolves it
+func imageReference(image string) (remote, alias string) {
+	distro, release, _ := ParseImageString(image)
+	reference := distro + ":" + release
+	if CurrentDriver() == DriverIncus {
+		reference = incusImage(reference)
+	}
+	remote, alias, _ = strings.Cut(reference, ":")
+	return remote, alias
+}
+
+// FindLocalImage returns the stored image a launch of image would use, or nil
+// when it has to be downloaded first
+func FindLocalImage(ctx context.Context, image string) (*LocalImage, error) {
+	remoteName, alias := imageReference(image)
+	images, err := ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if remoteName == LocalRemote {
+		return matchLocalImage(images, Remote{Name: LocalRemote}, alias), nil
+	}
+
+	remotes, err := ListRemotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, remote := range remotes {
+		if remote.Name == remoteName {
+			return matchLocalImage(images, remote, alias), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown image remote '%s'", remoteName)
+}
+
+// matchLocalImage finds the image with a local alias, or downloaded from the
+// remote under the alias
+func matchLocalImage(images []LocalImage, remote Remote, alias string) *LocalImage {
+	for i, image := range images {
+		if remote.Name == LocalRemote {
+			for _, name := range image.Aliases {
+				if name == alias {
+					return &images[i]
+				}
+			}
+			continue
+		}
+		if image.Source.Alias == alias && strings.TrimSuffix(image.Source.Server, "/") == strings.TrimSuffix(remote.Addr, "/") {
+			return &images[i]
+		}
+	}
+	return nil
+}
+
+// lxdOperation mirrors the parts of an LXD operation we use
+type lxdOperation struct {
+	ID       string                 `json:"id"`
+	Status   string                 `json:"status"`
+	Err      string                 `json:"err"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// DownloadImage downloads an image into the server's image store ahead of a
+// launch, through the operations API so onProgress can follow the transfer
+func DownloadImage(ctx context.Context, image string, onProgress func(ImageProgress)) (string, error) {
+	remoteName, alias := imageReference(image)
+	remotes, err := ListRemotes(ctx)
+	if err != nil {
+		return "", err
+	}
+	var source *Remote
+	for i := range remotes {
+		if remotes[i].Name == remoteName {
+			source = &remotes[i]
+		}
+	}
+	if source == nil {
+		return "", fmt.Errorf("unknown image remote '%s'", remoteName)
+	}
+	if source.IsLocal() {
+		return "", NotFoundErrorf("image '%s' is not in the image store of remote '%s'", alias, remoteName)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"source": map[string]string{
+			"type":     "image",
+			"mode":     "pull",
+			"server":   source.Addr,
+			"protocol": source.Protocol,
+			"alias":    alias,
+		},
+		"auto_update": true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Debug("Downloading image %s:%s from %s", remoteName, alias, source.Addr)
+	output, err := Runner().RunWithOutput(ctx, "lxc", "query", "-X", "POST", "/1.0/images", "--data", string(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to start download of image '%s': %w (output: %s)", image, err, strings.TrimSpace(string(output)))
+	}
+	var operation lxdOperation
+	if err := json.Unmarshal(output, &operation); err != nil || operation.ID == "" {
+		return "", fmt.Errorf("unexpected response to image download: %s", strings.TrimSpace(string(output)))
+	}
+
+	return waitForImageOperation(ctx, operation.ID, onProgress)
+}
+
+// waitForImageOperation polls a download operation until it finishes and
+// returns the fingerprint of the downloaded image
+func waitForImageOperation(ctx context.Context, id string, onProgress func(ImageProgress)) (string, error) {
+	ticker := time.NewTicker(imagePollInterval)
+	defer ticker.Stop()
+
+	for {
+		output, err := runOutput(ctx, "lxc", "query", "/1.0/operations/"+id)
+		if err != nil {
+			return "", fmt.Errorf("failed to check image download: %w", err)
+		}
+		var operation lxdOperation
+		if err := json.Unmarshal(output, &operation); err != nil {
+			return "", fmt.Errorf("failed to parse operation: %w", err)
+		}
+
+		switch operation.Status {
+		case "Success":
+			fingerprint, _ := operation.Metadata["fingerprint"].(string)
+			return fingerprint, nil
+		case "Failure", "Cancelled":
+			return "", fmt.Errorf("image download failed: %s", operation.Err)
+		}
+		if progress, ok := parseImageProgress(operation.Metadata); ok && onProgress != nil {
+			onProgress(progress)
+		}
+
+		select {
+		case <-ctx.Done():
+			// Leave no download running once we stop waiting for it
+			if err := Runner().Run(context.Background(), "lxc", "query", "-X", "DELETE", "/1.0/operations/"+id); err != nil {
+				logger.Debug("Failed to cancel image download: %v", err)
+			}
+			return "", fmt.Errorf("image download interrupted: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// downloadProgressPattern matches the download_progress text, e.g. "rootfs: 42% (12.50MB/s)"
+var downloadProgressPattern = regexp.MustCompile(`^(\w+): (\d+)%`)
+
+// parseImageProgress reads the progress of a download operation. Newer
+// servers report it as a structured map with byte counts; older ones only as
+// download_progress text.
+func parseImageProgress(metadata map[string]interface{}) (ImageProgress, bool) {
+	if values, ok := metadata["progress"].(map[string]interface{}); ok {
+		field := func(key string) int64 {
+			text, _ := values[key].(string)
+			n, _ := strconv.ParseInt(text, 10, 64)
+			return n
+		}
+		stage, _ := values["stage"].(string)
+		return ImageProgress{
+			Stage:     strings.TrimSuffix(stage, "_progress"),
+			Percent:   int(field("percent")),
+			Processed: field("processed"),
+			Speed:     field("speed"),
+		}, true
+	}
+
+	text, _ := metadata["download_progress"].(string)
+	matches := downloadProgressPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return ImageProgress{}, false
+	}
+	percent, _ := strconv.Atoi(matches[2])
+	return ImageProgress{Stage: matches[1], Percent: percent}, true
+}
+
+// ImagesToPrune returns the images not used since cutoff, leaving out images
+// with local aliases, which were imported or named on purpose
+func ImagesToPrune(images []LocalImage, cutoff time.Time) []LocalImage {
+	var prune []LocalImage
+	for _, image := range images {
+		if len(image.Aliases) > 0 {
+			continue
+		}
+		lastUsed := image.LastUsedAt
+		if lastUsed.IsZero() {
+			lastUsed = image.UploadedAt
+		}
+		if lastUsed.Before(cutoff) {
+			prune = append(prune, image)
+		}
+	}
+	return prune
+}
+
+// DeleteImage removes an image from the server's image store
+func DeleteImage(ctx context.Context, fingerprint string) error {
+	if err := runLXC(ctx, "image", "delete", fingerprint); err != nil {
+		return fmt.Errorf("failed to delete image '%s': %w", ShortFingerprint(fingerprint), err)
+	}
+	return nil
+}
+
+// ShortFingerprint abbreviates an image fingerprint as lxc image list does
+func ShortFingerprint(fingerprint string) string {
+	if len(fingerprint) > 12 {
+		return fingerprint[:12]
+	}
+	return fingerprint
+}
@@ -0,0 +1,173 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+const imagesJSON = `[
+  {
+    "fingerprint": "8f1d3a0b7c2e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a",
+    "aliases": [],
+    "properties": {"description": "ubuntu 24.04 LTS amd64 (release) (20250301)"},
+    "architecture": "x86_64",
+    "size": 262144000,
+    "cached": true,
+    "auto_update": true,
+    "uploaded_at": "2025-03-01T10:00:00Z",
+    "last_used_at": "2025-03-02T10:00:00Z",
+    "update_source": {"server": "https://cloud-images.ubuntu.com/releases/", "protocol": "simplestreams", "alias": "24.04"}
+  },
+  {
+    "fingerprint": "1234567890abcdef",
+    "aliases": [{"name": "golden"}],
+    "properties": {"description": "golden image"},
+    "architecture": "x86_64",
+    "size": 1048576,
+    "uploaded_at": "2024-01-01T10:00:00Z",
+    "last_used_at": "0001-01-01T00:00:00Z"
+  }
+]`
+
+const imageRemotesJSON = `{
+  "local": {"Addr": "unix://", "Protocol": "lxd", "Public": false, "Static": true},
+  "ubuntu": {"Addr": "https://cloud-images.ubuntu.com/releases", "Protocol": "simplestreams", "Public": true, "Static": true}
+}`
+
+func TestParseImages(t *testing.T) {
+	images, err := parseImages([]byte(imagesJSON))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(images) != 2 || images[0].Source.Alias != "24.04" || !images[0].Cached {
+		t.Fatalf("unexpected images: %+v", images)
+	}
+	if images[1].Aliases[0] != "golden" || !images[1].LastUsedAt.IsZero() {
+		t.Errorf("unexpected aliased image: %+v", images[1])
+	}
+	if _, err := parseImages([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestFindLocalImage(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(imagesJSON, nil, "lxc", "query", "/1.0/images?recursion=1")
+	runner.Respond(imageRemotesJSON, nil, "lxc", "remote", "list")
+	runner.Respond("local\n", nil, "lxc", "remote", "get-default")
+	ctx := context.Background()
+
+	image, err := FindLocalImage(ctx, "ubuntu:24.04")
+	if err != nil || image == nil || !strings.HasPrefix(image.Fingerprint, "8f1d3a0b") {
+		t.Errorf("expected the cached ubuntu image, got %+v, %v", image, err)
+	}
+	if image, err := FindLocalImage(ctx, "ubuntu:22.04"); err != nil || image != nil {
+		t.Errorf("expected no cached 22.04 image, got %+v, %v", image, err)
+	}
+	if image, err := FindLocalImage(ctx, "local:golden"); err != nil || image == nil {
+		t.Errorf("expected the aliased image, got %+v, %v", image, err)
+	}
+	if _, err := FindLocalImage(ctx, "nowhere:1"); err == nil || !strings.Contains(err.Error(), "unknown image remote") {
+		t.Errorf("expected an unknown remote error, got %v", err)
+	}
+}
+
+func TestDownloadImage(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(imageRemotesJSON, nil, "lxc", "remote", "list")
+	runner.Respond("local\n", nil, "lxc", "remote", "get-default")
+	runner.Respond(`{"id": "op-1", "status": "Running"}`, nil, "lxc", "query", "-X", "POST", "/1.0/images")
+	runner.Respond(`{"id": "op-1", "status": "Success", "metadata": {"fingerprint": "8f1d3a0b7c2e"}}`, nil, "lxc", "query", "/1.0/operations/op-1")
+
+	fingerprint, err := DownloadImage(context.Background(), "ubuntu:24.04", nil)
+	if err != nil || fingerprint != "8f1d3a0b7c2e" {
+		t.Fatalf("expected the downloaded fingerprint, got %q, %v", fingerprint, err)
+	}
+
+	var post []string
+	for _, command := range runner.Commands {
+		if len(command) > 3 && command[3] == "POST" {
+			post = command
+		}
+	}
+	var body struct {
+		Source map[string]string `json:"source"`
+	}
+	if len(post) != 7 || json.Unmarshal([]byte(post[6]), &body) != nil {
+		t.Fatalf("expected a POST with a JSON body, got %v", post)
+	}
+	if body.Source["server"] != "https://cloud-images.ubuntu.com/releases" || body.Source["alias"] != "24.04" || body.Source["mode"] != "pull" {
+		t.Errorf("unexpected download source: %v", body.Source)
+	}
+
+	runner.Respond(`{"id": "op-1", "status": "Failure", "err": "Failed getting image"}`, nil, "lxc", "query", "/1.0/operations/op-1")
+	if _, err := DownloadImage(context.Background(), "ubuntu:24.04", nil); err == nil || !strings.Contains(err.Error(), "Failed getting image") {
+		t.Errorf("expected the operation error, got %v", err)
+	}
+}
+
+func TestWaitForImageOperationCancel(t *testing.T) {
+	previous := imagePollInterval
+	imagePollInterval = time.Millisecond
+	t.Cleanup(func() { imagePollInterval = previous })
+
+	runner := useMockRunner(t)
+	runner.Respond(`{"id": "op-1", "status": "Running", "metadata": {"download_progress": "rootfs: 42% (12.50MB/s)"}}`, nil, "lxc", "query", "/1.0/operations/op-1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var seen []ImageProgress
+	_, err := waitForImageOperation(ctx, "op-1", func(p ImageProgress) {
+		seen = append(seen, p)
+		cancel()
+	})
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("expected an interrupted download, got %v", err)
+	}
+	if len(seen) == 0 || seen[0].Percent != 42 || seen[0].Stage != "rootfs" {
+		t.Errorf("expected progress to be reported, got %+v", seen)
+	}
+	if !runner.Ran("lxc", "query", "-X", "DELETE", "/1.0/operations/op-1") {
+		t.Error("expected the download to be cancelled")
+	}
+}
+
+func TestParseImageProgress(t *testing.T) {
+	progress, ok := parseImageProgress(map[string]interface{}{
+		"download_progress": "rootfs: 42% (12.50MB/s)",
+		"progress":          map[string]interface{}{"stage": "rootfs_progress", "percent": "42", "processed": "104857600", "speed": "13107200"},
+	})
+	if !ok || progress != (ImageProgress{Stage: "rootfs", Percent: 42, Processed: 104857600, Speed: 13107200}) {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+	if progress.String() != "rootfs 42%, 100.0MiB at 12.5MiB/s" {
+		t.Errorf("unexpected description: %s", progress)
+	}
+
+	progress, ok = parseImageProgress(map[string]interface{}{"download_progress": "metadata: 100% (1.20MB/s)"})
+	if !ok || progress != (ImageProgress{Stage: "metadata", Percent: 100}) {
+		t.Errorf("unexpected progress from text: %+v", progress)
+	}
+	if _, ok := parseImageProgress(map[string]interface{}{}); ok {
+		t.Error("expected no progress without progress metadata")
+	}
+}
+
+func TestImagesToPrune(t *testing.T) {
+	now := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	images := []LocalImage{
+		{Fingerprint: "recent", LastUsedAt: now.Add(-24 * time.Hour)},
+		{Fingerprint: "stale", LastUsedAt: now.Add(-60 * 24 * time.Hour)},
+		{Fingerprint: "never-used", UploadedAt: now.Add(-45 * 24 * time.Hour)},
+		{Fingerprint: "aliased", Aliases: []string{"golden"}, UploadedAt: now.Add(-365 * 24 * time.Hour)},
+	}
+	var names []string
+	for _, image := range ImagesToPrune(images, now.Add(-30*24*time.Hour)) {
+		names = append(names, image.Fingerprint)
+	}
+	if strings.Join(names, ",") != "stale,never-used" {
+		t.Errorf("expected stale and never-used, got %v", names)
+	}
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Instance types as LXD reports them
+const (
+	InstanceTypeContainer = "container"
+	InstanceTypeVM        = "virtual-machine"
+)
+
+// VMNetworkDevice is the NIC of the default profile that NAT port forwards
+// reach a virtual machine through
+const VMNetworkDevice = "eth0"
+
+// agentPollInterval is how often WaitForAgent checks a VM's agent; tests shorten it
+var agentPollInterval = 2 * time.Second
+
+// GetInstanceType returns whether an instance is a container or a virtual machine
+func GetInstanceType(ctx context.Context, name string) (string, error) {
+	output, err := runOutput(ctx, "lxc", "query", "/1.0/instances/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance '%s': %w", name, err)
+	}
+	var instance struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(output, &instance); err != nil {
+		return "", fmt.Errorf("failed to parse instance '%s': %w", name, err)
+	}
+	// Servers without VM support do not report a type
+	if instance.Type == "" {
+		return InstanceTypeContainer, nil
+	}
+	return instance.Type, nil
+}
+
+// WaitForAgent waits until commands can run in a virtual machine. lxc exec
+// goes through the agent in the guest, which starts well after the VM does.
+func WaitForAgent(ctx context.Context, name string) error {
+	ticker := time.NewTicker(agentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		output, err := Runner().RunWithOutput(ctx, "lxc", "exec", name, "--", "true")
+		if err == nil {
+			return nil
+		}
+		logger.Debug("Agent of '%s' not ready: %v (output: %s)", name, err, strings.TrimSpace(string(output)))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the agent of VM '%s' did not start: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// PinInstanceAddress makes the current IPv4 address of a virtual machine
+// static and returns it. Proxy devices of VMs only work in NAT mode, which
+// needs a fixed address to forward to.
+func PinInstanceAddress(ctx context.Context, name string) (string, error) {
+	if output, err := runOutput(ctx, "lxc", "config", "device", "get", name, VMNetworkDevice, "ipv4.address"); err == nil {
+		if address := strings.TrimSpace(string(output)); address != "" {
+			return address, nil
+		}
+	}
+
+	address, err := GetContainerIPv4(name)
+	if err != nil {
+		return "", err
+	}
+	if address == "" {
+		return "", fmt.Errorf("VM '%s' has no IPv4 address yet; start it and try again", name)
+	}
+
+	logger.Info("Pinning the address of VM '%s' to %s for NAT port forwarding", name, address)
+	// The NIC usually comes from a profile; override copies it onto the VM
+	if err := runLXC(ctx, "config", "device", "override", name, VMNetworkDevice, "ipv4.address="+address); err != nil {
+		if err := runLXC(ctx, "config", "device", "set", name, VMNetworkDevice, "ipv4.address", address); err != nil {
+			return "", fmt.Errorf("failed to pin the address of VM '%s': %w", name, err)
+		}
+	}
+	return address, nil
+}
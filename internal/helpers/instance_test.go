@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetInstanceType(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"name": "vm", "type": "virtual-machine"}`, nil, "lxc", "query", "/1.0/instances/vm")
+	runner.Respond(`{"name": "old"}`, nil, "lxc", "query", "/1.0/instances/old")
+	runner.Respond("", fmt.Errorf("not found"), "lxc", "query", "/1.0/instances/missing")
+	ctx := context.Background()
+
+	if instanceType, err := GetInstanceType(ctx, "vm"); err != nil || instanceType != InstanceTypeVM {
+		t.Errorf("expected a VM, got %q, %v", instanceType, err)
+	}
+	if instanceType, err := GetInstanceType(ctx, "old"); err != nil || instanceType != InstanceTypeContainer {
+		t.Errorf("expected an instance without a type to be a container, got %q, %v", instanceType, err)
+	}
+	if _, err := GetInstanceType(ctx, "missing"); err == nil {
+		t.Error("expected an error for a missing instance")
+	}
+}
+
+func TestWaitForAgent(t *testing.T) {
+	previous := agentPollInterval
+	agentPollInterval = time.Millisecond
+	t.Cleanup(func() { agentPollInterval = previous })
+
+	runner := useMockRunner(t)
+	if err := WaitForAgent(context.Background(), "vm"); err != nil {
+		t.Fatalf("expected a running agent, got %v", err)
+	}
+	if !runner.Ran("lxc", "exec", "vm", "--", "true") {
+		t.Error("expected the agent to be probed with lxc exec")
+	}
+
+	runner.Respond("", fmt.Errorf("VM agent isn't currently running"), "lxc", "exec", "vm")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitForAgent(ctx, "vm"); err == nil || !strings.Contains(err.Error(), "did not start") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestCreateVirtualMachine(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := CreateVirtualMachine("vm", "ubuntu", "24.04", "amd64", "pool"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := runner.ExpectCommands([]string{"lxc", "launch", "ubuntu:24.04", "vm", "--storage", "pool", "--vm"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPinInstanceAddress(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("10.0.0.9\n", nil, "lxc", "config", "device", "get", "pinned", "eth0", "ipv4.address")
+	runner.Respond(`[{"name": "vm", "status": "Running", "type": "virtual-machine", "state": {"network": {"enp5s0": {"addresses": [{"family": "inet", "address": "10.0.0.5", "scope": "global"}]}}}}]`,
+		nil, "lxc", "list", "^vm$")
+	ctx := context.Background()
+
+	if address, err := PinInstanceAddress(ctx, "pinned"); err != nil || address != "10.0.0.9" {
+		t.Errorf("expected the pinned address, got %q, %v", address, err)
+	}
+	if runner.Ran("lxc", "config", "device", "override", "pinned", "eth0", "ipv4.address=10.0.0.9") {
+		t.Error("an address that is already pinned should be left alone")
+	}
+
+	if address, err := PinInstanceAddress(ctx, "vm"); err != nil || address != "10.0.0.5" {
+		t.Errorf("expected the current address to be pinned, got %q, %v", address, err)
+	}
+	if !runner.Ran("lxc", "config", "device", "override", "vm", "eth0", "ipv4.address=10.0.0.5") {
+		t.Errorf("expected the profile NIC to be overridden, ran %v", runner.Commands)
+	}
+
+	// A NIC defined on the VM itself cannot be overridden and is set instead
+	runner.Respond("", fmt.Errorf("the device already exists"), "lxc", "config", "device", "override")
+	if _, err := PinInstanceAddress(ctx, "vm"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !runner.Ran("lxc", "config", "device", "set", "vm", "eth0", "ipv4.address", "10.0.0.5") {
+		t.Error("expected the address to be set on the VM's own NIC")
+	}
+}
@@ -0,0 +1,193 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SupportedInventoryFormats lists the output formats accepted by FormatInventory
+var SupportedInventoryFormats = []string{"json", "ansible", "terraform"}
+
+// InventoryPort is a host to container port forward
+type InventoryPort struct {
+	Protocol      string `json:"protocol"`
+	HostPort      int    `json:"host_port"`
+	ContainerPort int    `json:"container_port"`
+}
+
+// String renders the port as "8080:80/tcp"
+func (p InventoryPort) String() string {
+	return fmt.Sprintf("%d:%d/%s", p.HostPort, p.ContainerPort, p.Protocol)
+}
+
+// InventoryHost describes a managed container for external tooling. The password
+// itself is never included; PasswordCommand prints it on demand.
+type InventoryHost struct {
+	Name            string          `json:"name"`
+	Status          string          `json:"status"`
+	IPv4            string          `json:"ipv4"`
+	IPv6            string          `json:"ipv6"`
+	User            string          `json:"user"`
+	Ports           []InventoryPort `json:"ports"`
+	PasswordCommand string          `json:"password_command"`
+}
+
+// ListInventory returns inventory entries for all managed containers outside
+// the trash
+func ListInventory() ([]InventoryHost, error) {
+	states, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	return buildInventory(states), nil
+}
+
+// buildInventory converts container states into inventory entries, sorted by name
+func buildInventory(states []ContainerState) []InventoryHost {
+	hosts := make([]InventoryHost, 0, len(states))
+	for _, state := range states {
+		if !IsManagedContainer(state.Config) || IsTrashed(state.Config) {
+			continue
+		}
+		hosts = append(hosts, InventoryHost{
+			Name:            state.Name,
+			Status:          state.Status,
+			IPv4:            state.IPv4,
+			IPv6:            state.IPv6,
+			User:            "app",
+			Ports:           proxyPorts(state.Devices),
+			PasswordCommand: fmt.Sprintf("lxc-go-cli password %s", state.Name),
+		})
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts
+}
+
+// proxyPorts extracts port forwards from proxy devices, sorted by host port
+func proxyPorts(devices map[string]map[string]string) []InventoryPort {
+	ports := []InventoryPort{}
+	for _, device := range devices {
+		if device["type"] != "proxy" {
+			continue
+		}
+		protocol, hostPort, ok := parseProxyAddress(device["listen"])
+		if !ok {
+			continue
+		}
+		_, containerPort, ok := parseProxyAddress(device["connect"])
+		if !ok {
+			continue
+		}
+		ports = append(ports, InventoryPort{Protocol: protocol, HostPort: hostPort, ContainerPort: containerPort})
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].HostPort != ports[j].HostPort {
+			return ports[i].HostPort < ports[j].HostPort
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
+
+// parseProxyAddress parses a proxy device address such as "tcp:0.0.0.0:8080"
+func parseProxyAddress(address string) (protocol string, port int, ok bool) {
+	idx := strings.Index(address, ":")
+	last := strings.LastIndex(address, ":")
+	if idx < 0 || last == idx {
+		return "", 0, false
+	}
+	port, err := strconv.Atoi(address[last+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return address[:idx], port, true
+}
+
+// FormatInventory renders inventory entries in the requested format
+func FormatInventory(hosts []InventoryHost, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		if hosts == nil {
+			hosts = []InventoryHost{}
+		}
+		return json.MarshalIndent(hosts, "", "  ")
+	case "ansible":
+		return formatAnsibleInventory(hosts)
+	case "terraform":
+		return formatTerraformInventory(hosts)
+	default:
+		return nil, UsageErrorf("invalid format '%s': must be one of %s", format, strings.Join(SupportedInventoryFormats, ", "))
+	}
+}
+
+// formatAnsibleInventory renders the JSON expected from an Ansible dynamic inventory script
+func formatAnsibleInventory(hosts []InventoryHost) ([]byte, error) {
+	names := make([]string, 0, len(hosts))
+	hostvars := make(map[string]map[string]interface{}, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.Name)
+
+		ports := make([]string, 0, len(host.Ports))
+		for _, port := range host.Ports {
+			ports = append(ports, port.String())
+		}
+
+		vars := map[string]interface{}{
+			"ansible_user":   host.User,
+			"lxc_status":     host.Status,
+			"lxc_ports":      ports,
+			"lxc_ipv6":       host.IPv6,
+			"ansible_become": true,
+			// Resolved by Ansible at run time so the password never lands in the inventory
+			"ansible_password":        fmt.Sprintf("{{ lookup('pipe', '%s') }}", host.PasswordCommand),
+			"ansible_become_password": fmt.Sprintf("{{ lookup('pipe', '%s') }}", host.PasswordCommand),
+		}
+		if host.IPv4 != "" {
+			vars["ansible_host"] = host.IPv4
+		}
+		hostvars[host.Name] = vars
+	}
+
+	inventory := map[string]interface{}{
+		"all": map[string]interface{}{
+			"children": []string{"lxc_go_cli"},
+		},
+		"lxc_go_cli": map[string]interface{}{
+			"hosts": names,
+		},
+		"_meta": map[string]interface{}{
+			"hostvars": hostvars,
+		},
+	}
+	return json.MarshalIndent(inventory, "", "  ")
+}
+
+// formatTerraformInventory renders a flat string map as required by Terraform's
+// external data source, keyed "<container>.<attribute>"
+func formatTerraformInventory(hosts []InventoryHost) ([]byte, error) {
+	result := make(map[string]string)
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.Name)
+
+		ports := make([]string, 0, len(host.Ports))
+		for _, port := range host.Ports {
+			ports = append(ports, port.String())
+		}
+
+		prefix := host.Name + "."
+		result[prefix+"status"] = host.Status
+		result[prefix+"ipv4"] = host.IPv4
+		result[prefix+"ipv6"] = host.IPv6
+		result[prefix+"user"] = host.User
+		result[prefix+"ports"] = strings.Join(ports, ",")
+		result[prefix+"password_command"] = host.PasswordCommand
+	}
+	result["containers"] = strings.Join(names, ",")
+	return json.MarshalIndent(result, "", "  ")
+}
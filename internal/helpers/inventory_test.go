@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildInventory(t *testing.T) {
+	states := []ContainerState{
+		{
+			Name:   "web",
+			Status: "Running",
+			Config: map[string]string{"user.app-password": "c2VjcmV0"},
+			IPv4:   "10.0.0.5",
+			Devices: map[string]map[string]string{
+				"web-8080-80-tcp": {"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:0.0.0.0:80"},
+				"web-53-53-udp":   {"type": "proxy", "listen": "udp:0.0.0.0:53", "connect": "udp:0.0.0.0:53"},
+				"eth0":            {"type": "nic", "network": "lxdbr0"},
+				"broken":          {"type": "proxy", "listen": "tcp:nope"},
+			},
+		},
+		{Name: "unmanaged", Status: "Running", Config: map[string]string{}},
+		{Name: "trash-20250301-db", Status: "Stopped", Config: map[string]string{"user.app-password": "c2VjcmV0", TrashedKey: "2025-03-01T00:00:00Z"}},
+	}
+
+	hosts := buildInventory(states)
+	if len(hosts) != 1 {
+		t.Fatalf("expected only managed containers outside the trash, got %+v", hosts)
+	}
+
+	web := hosts[0]
+	if web.IPv4 != "10.0.0.5" || web.User != "app" || web.PasswordCommand != "lxc-go-cli password web" {
+		t.Errorf("unexpected host: %+v", web)
+	}
+	if len(web.Ports) != 2 || web.Ports[0].String() != "53:53/udp" || web.Ports[1].String() != "8080:80/tcp" {
+		t.Errorf("unexpected ports: %+v", web.Ports)
+	}
+}
+
+func TestParseContainerAddresses(t *testing.T) {
+	jsonOutput := `[{
+  "name": "web",
+  "status": "Running",
+  "state": {
+    "network": {
+      "docker0": {"addresses": [{"family": "inet", "address": "172.17.0.1", "scope": "global"}]},
+      "eth0": {"addresses": [
+        {"family": "inet6", "address": "fe80::1", "scope": "link"},
+        {"family": "inet", "address": "10.0.0.5", "scope": "global"},
+        {"family": "inet6", "address": "fd42::5", "scope": "global"}
+      ]}
+    }
+  }
+}]`
+
+	states, err := parseContainerStates([]byte(jsonOutput))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if states[0].IPv4 != "10.0.0.5" || states[0].IPv6 != "fd42::5" {
+		t.Errorf("expected eth0 global addresses, got %s / %s", states[0].IPv4, states[0].IPv6)
+	}
+	if len(states[0].IPv6Addresses) != 1 || states[0].IPv6Addresses[0] != "fd42::5" {
+		t.Errorf("expected only the global IPv6 address, got %v", states[0].IPv6Addresses)
+	}
+}
+
+func TestFormatInventory(t *testing.T) {
+	hosts := []InventoryHost{{
+		Name: "web", Status: "Running", IPv4: "10.0.0.5", User: "app", PasswordCommand: "lxc-go-cli password web",
+		Ports: []InventoryPort{{Protocol: "tcp", HostPort: 8080, ContainerPort: 80}},
+	}}
+
+	data, err := FormatInventory(hosts, "ansible")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var ansible struct {
+		Group struct {
+			Hosts []string `json:"hosts"`
+		} `json:"lxc_go_cli"`
+		Meta struct {
+			Hostvars map[string]map[string]interface{} `json:"hostvars"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &ansible); err != nil {
+		t.Fatalf("invalid ansible inventory: %v", err)
+	}
+	if len(ansible.Group.Hosts) != 1 || ansible.Meta.Hostvars["web"]["ansible_host"] != "10.0.0.5" {
+		t.Errorf("unexpected ansible inventory: %s", data)
+	}
+	if !strings.Contains(ansible.Meta.Hostvars["web"]["ansible_password"].(string), "lookup('pipe', 'lxc-go-cli password web')") {
+		t.Errorf("expected password lookup, got %v", ansible.Meta.Hostvars["web"]["ansible_password"])
+	}
+
+	data, err = FormatInventory(hosts, "terraform")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var terraform map[string]string
+	if err := json.Unmarshal(data, &terraform); err != nil {
+		t.Fatalf("terraform inventory must be a flat string map: %v", err)
+	}
+	if terraform["web.ipv4"] != "10.0.0.5" || terraform["web.ports"] != "8080:80/tcp" || terraform["containers"] != "web" {
+		t.Errorf("unexpected terraform inventory: %v", terraform)
+	}
+
+	data, err = FormatInventory(nil, "json")
+	if err != nil || strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("expected empty JSON list, got %s (%v)", data, err)
+	}
+
+	if _, err := FormatInventory(hosts, "csv"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
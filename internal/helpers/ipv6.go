@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPv6 modes for a new container's NIC
+const (
+	// IPv6Auto takes an address from the network by SLAAC or DHCPv6
+	IPv6Auto = "auto"
+	// IPv6Disabled turns IPv6 off inside the container
+	IPv6Disabled = "disabled"
+	// IPv6Static reserves a fixed address on the network's DHCPv6 server
+	IPv6Static = "static"
+)
+
+// DefaultNICDevice is the NIC that LXD's default profile gives instances
+const DefaultNICDevice = "eth0"
+
+// ipv6SysctlFile keeps IPv6 disabled in the container across restarts
+const ipv6SysctlFile = "/etc/sysctl.d/60-lxc-go-cli-ipv6.conf"
+
+// IPv6Config is how a container gets its IPv6 address; the zero value leaves
+// the network's defaults alone
+type IPv6Config struct {
+	Mode string
+	// Address is the fixed address of the static mode
+	Address string
+}
+
+// ParseIPv6Config parses auto, disabled or static:<address>
+func ParseIPv6Config(value string) (IPv6Config, error) {
+	switch {
+	case value == "":
+		return IPv6Config{}, nil
+	case value == IPv6Auto || value == IPv6Disabled:
+		return IPv6Config{Mode: value}, nil
+	case strings.HasPrefix(value, IPv6Static+":"):
+		address := strings.TrimPrefix(value, IPv6Static+":")
+		ip := net.ParseIP(address)
+		if ip == nil || ip.To4() != nil {
+			return IPv6Config{}, UsageErrorf("invalid IPv6 address '%s' in '%s'", address, value)
+		}
+		if !ip.IsGlobalUnicast() {
+			return IPv6Config{}, fmt.Errorf("IPv6 address '%s' is not a global unicast address", address)
+		}
+		return IPv6Config{Mode: IPv6Static, Address: ip.String()}, nil
+	default:
+		return IPv6Config{}, UsageErrorf("invalid IPv6 mode '%s': must be auto, disabled or static:<address>", value)
+	}
+}
+
+// String returns the config as it is written on the command line
+func (c IPv6Config) String() string {
+	if c.Mode == IPv6Static {
+		return IPv6Static + ":" + c.Address
+	}
+	return c.Mode
+}
+
+// DeviceOverride returns the launch --device value that sets the NIC's IPv6
+// settings, or "" when the profile's NIC is used as it is
+func (c IPv6Config) DeviceOverride() string {
+	if c.Mode != IPv6Static {
+		return ""
+	}
+	return DefaultNICDevice + ",ipv6.address=" + c.Address
+}
+
+// CheckIPv6Addresses reports whether a container's global IPv6 addresses
+// are what the config asks for
+func (c IPv6Config) CheckIPv6Addresses(addresses []string) error {
+	switch c.Mode {
+	case IPv6Auto:
+		if len(addresses) == 0 {
+			return fmt.Errorf("no global IPv6 address was assigned; check that the network has IPv6 (lxc network get lxdbr0 ipv6.address)")
+		}
+	case IPv6Disabled:
+		if len(addresses) > 0 {
+			return fmt.Errorf("IPv6 is still in use: %s", strings.Join(addresses, ", "))
+		}
+	case IPv6Static:
+		want := net.ParseIP(c.Address)
+		for _, address := range addresses {
+			if want.Equal(net.ParseIP(address)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("address %s was not assigned (got %s); a static address needs DHCPv6 on the network (lxc network set lxdbr0 ipv6.dhcp.stateful=true)", c.Address, valueOr(strings.Join(addresses, ", "), "none"))
+	}
+	return nil
+}
+
+// DisableContainerIPv6 turns IPv6 off inside the container, now and after
+// restarts, with a sysctl file
+func DisableContainerIPv6(ctx context.Context, name string) error {
+	script := fmt.Sprintf(`printf 'net.ipv6.conf.all.disable_ipv6 = 1\nnet.ipv6.conf.default.disable_ipv6 = 1\n' > %s && sysctl -q -p %s`, ipv6SysctlFile, ipv6SysctlFile)
+	output, err := Runner().RunWithOutput(ctx, "lxc", "exec", name, "--", "sh", "-c", script)
+	if err != nil {
+		return fmt.Errorf("failed to disable IPv6 in container '%s': %w (output: %s)", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseIPv6Config(t *testing.T) {
+	valid := map[string]IPv6Config{
+		"":                        {},
+		"auto":                    {Mode: IPv6Auto},
+		"disabled":                {Mode: IPv6Disabled},
+		"static:fd42:0:0:1::0010": {Mode: IPv6Static, Address: "fd42:0:0:1::10"},
+	}
+	for value, want := range valid {
+		got, err := ParseIPv6Config(value)
+		if err != nil || got != want {
+			t.Errorf("%q: expected %+v, got %+v (%v)", value, want, got, err)
+		}
+	}
+
+	for _, value := range []string{"on", "static:", "static:10.0.0.5", "static:fe80::1", "static:nope"} {
+		if _, err := ParseIPv6Config(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestIPv6ConfigDeviceOverride(t *testing.T) {
+	static := IPv6Config{Mode: IPv6Static, Address: "fd42::10"}
+	if got := static.DeviceOverride(); got != "eth0,ipv6.address=fd42::10" {
+		t.Errorf("unexpected override %q", got)
+	}
+	if got := (IPv6Config{Mode: IPv6Auto}).DeviceOverride(); got != "" {
+		t.Errorf("expected no override for auto, got %q", got)
+	}
+	if static.String() != "static:fd42::10" {
+		t.Errorf("unexpected string %q", static.String())
+	}
+}
+
+func TestCheckIPv6Addresses(t *testing.T) {
+	tests := []struct {
+		config    IPv6Config
+		addresses []string
+		wantErr   string
+	}{
+		{IPv6Config{Mode: IPv6Auto}, []string{"fd42::5"}, ""},
+		{IPv6Config{Mode: IPv6Auto}, nil, "no global IPv6 address"},
+		{IPv6Config{Mode: IPv6Disabled}, nil, ""},
+		{IPv6Config{Mode: IPv6Disabled}, []string{"fd42::5"}, "still in use"},
+		{IPv6Config{Mode: IPv6Static, Address: "fd42::10"}, []string{"fd42::5", "fd42:0::10"}, ""},
+		{IPv6Config{Mode: IPv6Static, Address: "fd42::10"}, []string{"fd42::5"}, "ipv6.dhcp.stateful"},
+		{IPv6Config{}, nil, ""},
+	}
+	for _, tt := range tests {
+		err := tt.config.CheckIPv6Addresses(tt.addresses)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s with %v: expected no error, got %v", tt.config, tt.addresses, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s with %v: expected error containing %q, got %v", tt.config, tt.addresses, tt.wantErr, err)
+		}
+	}
+}
+
+func TestLaunchInstanceIPv6(t *testing.T) {
+	runner := useMockRunner(t)
+	opts := LaunchOptions{IPv6: IPv6Config{Mode: IPv6Static, Address: "fd42::10"}}
+	if err := LaunchInstance("web", "ubuntu", "24.04", "amd64", "default", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !runner.Ran("lxc", "launch", "ubuntu:24.04", "web", "--storage", "default", "--device", "eth0,ipv6.address=fd42::10") {
+		t.Errorf("expected the NIC override at launch, got %v", runner.Commands)
+	}
+}
+
+func TestDisableContainerIPv6(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := DisableContainerIPv6(context.Background(), "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(runner.Commands) != 1 || !strings.Contains(strings.Join(runner.Commands[0], " "), "disable_ipv6 = 1") {
+		t.Errorf("expected a sysctl file to be written, got %v", runner.Commands)
+	}
+
+	runner.Respond("read-only file system", &MockExitError{Code: 1}, "lxc", "exec", "web")
+	if err := DisableContainerIPv6(context.Background(), "web"); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected the output in the error, got %v", err)
+	}
+}
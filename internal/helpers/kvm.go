@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// KVMDevice is a host device node passed into a container for QEMU
+type KVMDevice struct {
+	// Name is the LXD device name
+	Name string
+	// Path is the device node on the host and in the container
+	Path string
+	// Required devices must exist on the host; the others are passed when present
+	Required bool
+}
+
+// KVMDevices lists what QEMU needs: /dev/kvm for hardware acceleration and
+// the vhost devices for fast virtio networking and vsock
+var KVMDevices = []KVMDevice{
+	{Name: "kvm", Path: "/dev/kvm", Required: true},
+	{Name: "kvm-vhost-net", Path: "/dev/vhost-net"},
+	{Name: "kvm-vhost-vsock", Path: "/dev/vhost-vsock"},
+}
+
+// hostRoot prefixes the host paths checked by DetectHostKVM; tests replace it
+var hostRoot = "/"
+
+// KVMStatus is the KVM configuration of a container
+type KVMStatus struct {
+	// Devices maps the names of KVMDevices to whether the container has them
+	Devices map[string]bool
+}
+
+// IsEnabled returns true if the container has /dev/kvm
+func (s *KVMStatus) IsEnabled() bool {
+	return s.Devices["kvm"]
+}
+
+// GetContainerKVMStatus reads which KVM devices a container has
+func GetContainerKVMStatus(containerName string) (*KVMStatus, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "config", "show", containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container config: %w (output: %s)", err, string(output))
+	}
+	return parseKVMStatus(string(output))
+}
+
+// parseKVMStatus finds the KVM devices in the YAML output of lxc config show
+func parseKVMStatus(yamlOutput string) (*KVMStatus, error) {
+	var config ContainerConfig
+	if err := yaml.Unmarshal([]byte(yamlOutput), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse container config YAML: %w", err)
+	}
+
+	status := &KVMStatus{Devices: make(map[string]bool)}
+	for _, device := range KVMDevices {
+		props, exists := config.Devices[device.Name]
+		status.Devices[device.Name] = exists && props["type"] == "unix-char"
+	}
+	return status, nil
+}
+
+// EnableContainerKVM adds the given KVM devices that the container does not
+// have yet (idempotent). Unix-char devices are hotplugged, so no restart is
+// needed; mode 0666 lets the 'app' user open them without a kvm group.
+func EnableContainerKVM(containerName string, devices []KVMDevice) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+
+	status, err := GetContainerKVMStatus(containerName)
+	if err != nil {
+		return fmt.Errorf("failed to check current KVM status: %w", err)
+	}
+
+	for _, device := range devices {
+		if status.Devices[device.Name] {
+			logger.Debug("Device '%s' is already present", device.Name)
+			continue
+		}
+		logger.Debug("Adding %s to container '%s'", device.Path, containerName)
+		if err := runLXC(context.Background(), "config", "device", "add", containerName, device.Name, "unix-char",
+			"source="+device.Path, "path="+device.Path, "mode=0666"); err != nil {
+			return fmt.Errorf("failed to add %s: %w", device.Path, err)
+		}
+	}
+	return nil
+}
+
+// DisableContainerKVM removes the KVM devices the container has (idempotent)
+func DisableContainerKVM(containerName string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+
+	status, err := GetContainerKVMStatus(containerName)
+	if err != nil {
+		return fmt.Errorf("failed to check current KVM status: %w", err)
+	}
+
+	for _, device := range KVMDevices {
+		if !status.Devices[device.Name] {
+			continue
+		}
+		logger.Debug("Removing %s from container '%s'", device.Path, containerName)
+		if err := runLXC(context.Background(), "config", "device", "remove", containerName, device.Name); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", device.Path, err)
+		}
+	}
+	return nil
+}
+
+// KVMHost describes the host's support for nested virtualization
+type KVMHost struct {
+	// Devices maps the paths of KVMDevices to whether they exist on the host
+	Devices map[string]bool
+	// CPUExtension is "vmx" (Intel VT-x), "svm" (AMD-V) or empty
+	CPUExtension string
+}
+
+// DetectHostKVM looks for the KVM device nodes and the CPU's virtualization
+// extensions on this machine
+func DetectHostKVM() *KVMHost {
+	host := &KVMHost{Devices: make(map[string]bool)}
+	for _, device := range KVMDevices {
+		_, err := os.Stat(filepath.Join(hostRoot, device.Path))
+		host.Devices[device.Path] = err == nil
+	}
+
+	cpuinfo, err := os.ReadFile(filepath.Join(hostRoot, "proc/cpuinfo"))
+	if err != nil {
+		logger.Debug("Could not read /proc/cpuinfo: %v", err)
+	} else {
+		host.CPUExtension = parseCPUVirtExtension(string(cpuinfo))
+	}
+	return host
+}
+
+// parseCPUVirtExtension finds vmx or svm in the flags of /proc/cpuinfo
+func parseCPUVirtExtension(cpuinfo string) string {
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "vmx" || flag == "svm" {
+				return flag
+			}
+		}
+	}
+	return ""
+}
+
+// AvailableDevices returns the KVM devices present on the host
+func (h *KVMHost) AvailableDevices() []KVMDevice {
+	var devices []KVMDevice
+	for _, device := range KVMDevices {
+		if h.Devices[device.Path] {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// CheckHostKVM returns an error explaining why containers cannot use KVM on
+// this host, if they cannot
+func CheckHostKVM(host *KVMHost) error {
+	for _, device := range KVMDevices {
+		if !device.Required || host.Devices[device.Path] {
+			continue
+		}
+		switch host.CPUExtension {
+		case "vmx":
+			return fmt.Errorf("%s not found; load the KVM module with 'sudo modprobe kvm_intel'", device.Path)
+		case "svm":
+			return fmt.Errorf("%s not found; load the KVM module with 'sudo modprobe kvm_amd'", device.Path)
+		default:
+			return fmt.Errorf("%s not found and the CPU reports no virtualization extensions (vmx or svm); enable VT-x/AMD-V in the firmware, or nested virtualization if this host is a VM", device.Path)
+		}
+	}
+	return nil
+}
+
+// FormatKVMStatus describes a container's KVM devices and the host's support
+func FormatKVMStatus(status *KVMStatus, host *KVMHost) string {
+	var result strings.Builder
+
+	result.WriteString("KVM Configuration:\n")
+	for _, device := range KVMDevices {
+		state := "absent"
+		if status.Devices[device.Name] {
+			state = "present"
+		}
+		result.WriteString(fmt.Sprintf("  %s: %s\n", device.Path, state))
+	}
+	if status.IsEnabled() {
+		result.WriteString("  KVM Status: enabled\n")
+	} else {
+		result.WriteString("  KVM Status: disabled\n")
+	}
+
+	if host == nil {
+		return result.String()
+	}
+	result.WriteString("Host:\n")
+	for _, device := range KVMDevices {
+		state := "missing"
+		if host.Devices[device.Path] {
+			state = "available"
+		}
+		result.WriteString(fmt.Sprintf("  %s: %s\n", device.Path, state))
+	}
+	extension := host.CPUExtension
+	if extension == "" {
+		extension = "none found"
+	}
+	result.WriteString(fmt.Sprintf("  CPU virtualization: %s\n", extension))
+	return result.String()
+}
@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const kvmConfigYAML = `config:
+  security.nesting: "true"
+devices:
+  kvm:
+    mode: "0666"
+    path: /dev/kvm
+    source: /dev/kvm
+    type: unix-char
+  kvm-vhost-net:
+    path: /dev/vhost-net
+    type: disk
+`
+
+func TestParseKVMStatus(t *testing.T) {
+	status, err := parseKVMStatus(kvmConfigYAML)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !status.IsEnabled() || status.Devices["kvm-vhost-net"] || status.Devices["kvm-vhost-vsock"] {
+		t.Errorf("expected only the kvm device, got %v", status.Devices)
+	}
+
+	if _, err := parseKVMStatus("devices: ["); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestEnableAndDisableContainerKVM(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond(kvmConfigYAML, nil, "lxc", "config", "show", "web")
+
+	if err := EnableContainerKVM("web", KVMDevices[:2]); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.Ran("lxc", "config", "device", "add", "web", "kvm") {
+		t.Error("an existing device should not be added again")
+	}
+	if !mock.Ran("lxc", "config", "device", "add", "web", "kvm-vhost-net", "unix-char", "source=/dev/vhost-net", "path=/dev/vhost-net", "mode=0666") {
+		t.Error("expected vhost-net to be added")
+	}
+	if mock.Ran("lxc", "config", "device", "add", "web", "kvm-vhost-vsock") {
+		t.Error("only the given devices should be added")
+	}
+
+	mock.Reset()
+	mock.Respond(kvmConfigYAML, nil, "lxc", "config", "show", "web")
+	if err := DisableContainerKVM("web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !mock.Ran("lxc", "config", "device", "remove", "web", "kvm") || mock.Ran("lxc", "config", "device", "remove", "web", "kvm-vhost-net") {
+		t.Error("expected only the present kvm device to be removed")
+	}
+
+	if err := EnableContainerKVM("", KVMDevices); err == nil {
+		t.Error("expected error for an empty container name")
+	}
+}
+
+func TestDetectHostKVM(t *testing.T) {
+	hostRoot = t.TempDir()
+	t.Cleanup(func() { hostRoot = "/" })
+	for _, dir := range []string{"dev", "proc"} {
+		if err := os.MkdirAll(filepath.Join(hostRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(hostRoot, "dev/kvm"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cpuinfo := "processor\t: 0\nflags\t\t: fpu vme svm sse\n"
+	if err := os.WriteFile(filepath.Join(hostRoot, "proc/cpuinfo"), []byte(cpuinfo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	host := DetectHostKVM()
+	if !host.Devices["/dev/kvm"] || host.Devices["/dev/vhost-net"] || host.CPUExtension != "svm" {
+		t.Errorf("unexpected host %+v", host)
+	}
+	if devices := host.AvailableDevices(); len(devices) != 1 || devices[0].Name != "kvm" {
+		t.Errorf("expected only /dev/kvm to be available, got %+v", devices)
+	}
+	if err := CheckHostKVM(host); err != nil {
+		t.Errorf("expected the host to support KVM, got %v", err)
+	}
+}
+
+func TestCheckHostKVM(t *testing.T) {
+	tests := []struct {
+		extension string
+		want      string
+	}{
+		{"vmx", "modprobe kvm_intel"},
+		{"svm", "modprobe kvm_amd"},
+		{"", "no virtualization extensions"},
+	}
+	for _, tt := range tests {
+		host := &KVMHost{Devices: map[string]bool{"/dev/vhost-net": true}, CPUExtension: tt.extension}
+		if err := CheckHostKVM(host); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected %q, got %v", tt.extension, tt.want, err)
+		}
+	}
+}
+
+func TestFormatKVMStatus(t *testing.T) {
+	status := &KVMStatus{Devices: map[string]bool{"kvm": true}}
+	host := &KVMHost{Devices: map[string]bool{"/dev/kvm": true}, CPUExtension: "vmx"}
+
+	output := FormatKVMStatus(status, host)
+	for _, want := range []string{"/dev/kvm: present", "/dev/vhost-net: absent", "KVM Status: enabled", "/dev/kvm: available", "/dev/vhost-vsock: missing", "CPU virtualization: vmx"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(FormatKVMStatus(status, nil), "Host:") {
+		t.Error("expected no host section without host details")
+	}
+}
@@ -0,0 +1,195 @@
+package helpers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LabelConfigPrefix is the config key prefix labels are stored under, e.g.
+// user.label.env=prod
+const LabelConfigPrefix = "user.label."
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]{0,61}[a-zA-Z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9._-]{0,61}[a-zA-Z0-9])?)?$`)
+)
+
+// ValidateLabelKey checks a label key: up to 63 letters, digits, '.', '_'
+// and '-', starting and ending with a letter or digit
+func ValidateLabelKey(key string) error {
+	if !labelKeyPattern.MatchString(key) {
+		return UsageErrorf("invalid label key '%s': use up to 63 letters, digits, '.', '_' and '-', starting and ending with a letter or digit", key)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks a label value; it follows the key rules but may be empty
+func ValidateLabelValue(value string) error {
+	if !labelValuePattern.MatchString(value) {
+		return UsageErrorf("invalid label value '%s': use up to 63 letters, digits, '.', '_' and '-', starting and ending with a letter or digit", value)
+	}
+	return nil
+}
+
+// ParseLabels parses key=value arguments, e.g. env=prod team=core
+func ParseLabels(args []string) (map[string]string, error) {
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, UsageErrorf("invalid label '%s': expected key=value", arg)
+		}
+		if err := ValidateLabelKey(key); err != nil {
+			return nil, err
+		}
+		if err := ValidateLabelValue(value); err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// ContainerLabels extracts the labels from a container's config
+func ContainerLabels(config map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range config {
+		if strings.HasPrefix(key, LabelConfigPrefix) {
+			labels[strings.TrimPrefix(key, LabelConfigPrefix)] = value
+		}
+	}
+	return labels
+}
+
+// LabelKeys returns the keys of labels in sorted order
+func LabelKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FormatLabels renders labels as sorted key=value pairs separated by commas
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range LabelKeys(labels) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Operators of a label selector requirement
+const (
+	SelectorEquals    = "="
+	SelectorNotEquals = "!="
+	SelectorExists    = "exists"
+	SelectorNotExists = "!exists"
+)
+
+// LabelRequirement is one condition of a selector, e.g. env=prod or !canary
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+// Matches reports whether labels satisfy the requirement
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorEquals:
+		return ok && value == r.Value
+	case SelectorNotEquals:
+		return !ok || value != r.Value
+	case SelectorExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+func (r LabelRequirement) String() string {
+	switch r.Operator {
+	case SelectorExists:
+		return r.Key
+	case SelectorNotExists:
+		return "!" + r.Key
+	default:
+		return r.Key + r.Operator + r.Value
+	}
+}
+
+// LabelSelector selects containers whose labels meet every requirement; an
+// empty selector selects everything
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses a comma-separated selector in the style of
+// Kubernetes: env=prod (or env==prod), env!=prod, canary (label is set) and
+// !canary (label is not set)
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var result LabelSelector
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var requirement LabelRequirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorNotEquals, Value: strings.TrimSpace(value)}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			requirement = LabelRequirement{Key: strings.TrimSpace(key), Operator: SelectorEquals, Value: strings.TrimSpace(strings.TrimPrefix(value, "="))}
+		case strings.HasPrefix(part, "!"):
+			requirement = LabelRequirement{Key: strings.TrimSpace(part[1:]), Operator: SelectorNotExists}
+		default:
+			requirement = LabelRequirement{Key: part, Operator: SelectorExists}
+		}
+
+		if err := ValidateLabelKey(requirement.Key); err != nil {
+			return nil, UsageErrorf("invalid selector '%s': %w", part, err)
+		}
+		if err := ValidateLabelValue(requirement.Value); err != nil {
+			return nil, UsageErrorf("invalid selector '%s': %w", part, err)
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+// Matches reports whether labels satisfy every requirement
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s LabelSelector) String() string {
+	parts := make([]string, len(s))
+	for i, requirement := range s {
+		parts[i] = requirement.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// FilterContainersBySelector keeps the containers whose labels match the selector
+func FilterContainersBySelector(states []ContainerState, selector LabelSelector) []ContainerState {
+	if len(selector) == 0 {
+		return states
+	}
+	var matched []ContainerState
+	for _, state := range states {
+		if selector.Matches(ContainerLabels(state.Config)) {
+			matched = append(matched, state)
+		}
+	}
+	return matched
+}
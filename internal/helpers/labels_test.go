@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"env=prod", "team=core", "canary="})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := map[string]string{"env": "prod", "team": "core", "canary": ""}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected %v, got %v", want, labels)
+	}
+
+	for _, invalid := range []string{"env", "=prod", "env=pro d", "-env=prod", "env=prod-", strings.Repeat("a", 64) + "=x"} {
+		if _, err := ParseLabels([]string{invalid}); err == nil {
+			t.Errorf("expected '%s' to be rejected", invalid)
+		}
+	}
+}
+
+func TestContainerLabels(t *testing.T) {
+	config := map[string]string{
+		"user.label.env":    "prod",
+		"user.label.team":   "core",
+		"user.app-password": "c2VjcmV0",
+		"limits.memory":     "2GiB",
+	}
+	labels := ContainerLabels(config)
+	if !reflect.DeepEqual(labels, map[string]string{"env": "prod", "team": "core"}) {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+	if got := FormatLabels(labels); got != "env=prod,team=core" {
+		t.Errorf("expected sorted labels, got %q", got)
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := ParseLabelSelector("env=prod, team==core,tier!=db,canary,!legacy")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := LabelSelector{
+		{Key: "env", Operator: SelectorEquals, Value: "prod"},
+		{Key: "team", Operator: SelectorEquals, Value: "core"},
+		{Key: "tier", Operator: SelectorNotEquals, Value: "db"},
+		{Key: "canary", Operator: SelectorExists},
+		{Key: "legacy", Operator: SelectorNotExists},
+	}
+	if !reflect.DeepEqual(selector, want) {
+		t.Errorf("expected %v, got %v", want, selector)
+	}
+	if selector.String() != "env=prod,team=core,tier!=db,canary,!legacy" {
+		t.Errorf("unexpected string form: %s", selector)
+	}
+
+	if selector, err := ParseLabelSelector(""); err != nil || len(selector) != 0 {
+		t.Errorf("expected an empty selector, got %v, %v", selector, err)
+	}
+	for _, invalid := range []string{"=prod", "env=a b", "!", "env=prod,bad key"} {
+		if _, err := ParseLabelSelector(invalid); err == nil {
+			t.Errorf("expected '%s' to be rejected", invalid)
+		}
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "core"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env=prod,team=core", true},
+		{"env=prod,team=web", false},
+		{"env!=dev", true},
+		{"tier!=db", true},
+		{"env!=prod", false},
+		{"team", true},
+		{"canary", false},
+		{"!canary", true},
+		{"!env", false},
+	}
+	for _, tt := range tests {
+		selector, err := ParseLabelSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("invalid selector '%s': %v", tt.selector, err)
+		}
+		if got := selector.Matches(labels); got != tt.want {
+			t.Errorf("selector '%s': expected %v, got %v", tt.selector, tt.want, got)
+		}
+	}
+}
+
+func TestFilterContainersBySelector(t *testing.T) {
+	states := []ContainerState{
+		{Name: "web1", Config: map[string]string{"user.label.env": "prod"}},
+		{Name: "web2", Config: map[string]string{"user.label.env": "dev"}},
+		{Name: "web3", Config: map[string]string{}},
+	}
+	selector, _ := ParseLabelSelector("env!=prod")
+	var names []string
+	for _, state := range FilterContainersBySelector(states, selector) {
+		names = append(names, state.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"web2", "web3"}) {
+		t.Errorf("expected web2 and web3, got %v", names)
+	}
+	if len(FilterContainersBySelector(states, nil)) != 3 {
+		t.Error("an empty selector should keep every container")
+	}
+}
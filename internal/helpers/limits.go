@@ -0,0 +1,254 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Resource limit keys set by 'limits set'
+const (
+	LimitCPUKey           = "limits.cpu"
+	LimitMemorySwapKey    = "limits.memory.swap"
+	LimitMemoryEnforceKey = "limits.memory.enforce"
+	LimitDiskPriorityKey  = "limits.disk.priority"
+)
+
+// Rate limit keys of devices: the root disk takes read and write, the NIC
+// egress and ingress
+const (
+	LimitReadKey    = "limits.read"
+	LimitWriteKey   = "limits.write"
+	LimitEgressKey  = "limits.egress"
+	LimitIngressKey = "limits.ingress"
+)
+
+// diskRatePattern matches disk limits in bytes per second, e.g. 50MB or
+// 20MiB, or in operations per second, e.g. 1000iops
+var diskRatePattern = regexp.MustCompile(`^(\d+(\.\d+)?(B|kB|MB|GB|TB|KiB|MiB|GiB|TiB)|\d+iops)$`)
+
+// networkRatePattern matches network limits in bits per second, e.g. 100Mbit
+var networkRatePattern = regexp.MustCompile(`^\d+(\.\d+)?(bit|kbit|Mbit|Gbit|Tbit)$`)
+
+// Values of limits.memory.enforce. With soft the container may use more than
+// limits.memory while the host has memory to spare.
+const (
+	MemoryEnforceHard = "hard"
+	MemoryEnforceSoft = "soft"
+)
+
+// HostTopology is the part of the host's hardware that limits are checked against
+type HostTopology struct {
+	// CPUs are the numbers of the online processors, ascending
+	CPUs []int
+	// SwapBytes is the total size of the active swap areas
+	SwapBytes int64
+}
+
+// DetectHostTopology reads the host's processors from /proc/cpuinfo and its
+// swap areas from /proc/swaps
+func DetectHostTopology() (*HostTopology, error) {
+	cpuinfo, err := os.ReadFile(filepath.Join(hostRoot, "proc/cpuinfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc/cpuinfo: %w", err)
+	}
+	topology := &HostTopology{CPUs: parseCPUInfoProcessors(string(cpuinfo))}
+	if len(topology.CPUs) == 0 {
+		return nil, fmt.Errorf("no processors found in /proc/cpuinfo")
+	}
+
+	swaps, err := os.ReadFile(filepath.Join(hostRoot, "proc/swaps"))
+	if err != nil {
+		logger.Debug("Could not read /proc/swaps: %v", err)
+	} else {
+		topology.SwapBytes = parseSwapsTotal(string(swaps))
+	}
+	return topology, nil
+}
+
+// parseCPUInfoProcessors returns the processor numbers listed in /proc/cpuinfo
+func parseCPUInfoProcessors(cpuinfo string) []int {
+	var cpus []int
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "processor" {
+			continue
+		}
+		if cpu, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			cpus = append(cpus, cpu)
+		}
+	}
+	sort.Ints(cpus)
+	return cpus
+}
+
+// parseSwapsTotal adds up the sizes in /proc/swaps, which are in KiB
+func parseSwapsTotal(swaps string) int64 {
+	var total int64
+	for i, line := range strings.Split(swaps, "\n") {
+		fields := strings.Fields(line)
+		// The first line is the header
+		if i == 0 || len(fields) < 3 {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			total += size * 1024
+		}
+	}
+	return total
+}
+
+// ParseCPUSet parses a list of CPUs such as "0,1" or "0-3,8" into ascending
+// CPU numbers
+func ParseCPUSet(spec string) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("CPU list is empty")
+	}
+
+	seen := make(map[int]bool)
+	var cpus []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 0 {
+			return nil, UsageErrorf("invalid CPU '%s' in '%s': must be a CPU number or a range such as 0-3", part, spec)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(last)
+			if err != nil || end < start {
+				return nil, UsageErrorf("invalid CPU range '%s' in '%s'", part, spec)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			if seen[cpu] {
+				return nil, fmt.Errorf("CPU %d is listed more than once in '%s'", cpu, spec)
+			}
+			seen[cpu] = true
+			cpus = append(cpus, cpu)
+		}
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// FormatCPUPin renders CPUs in limits.cpu pinning syntax, collapsing runs into
+// ranges. A single CPU is written as a range, e.g. "2-2", because a bare
+// number in limits.cpu is a CPU count rather than a CPU to pin to.
+func FormatCPUPin(cpus []int) string {
+	if len(cpus) == 1 {
+		return fmt.Sprintf("%d-%d", cpus[0], cpus[0])
+	}
+
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		switch {
+		case j == i:
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		case j == i+1:
+			parts = append(parts, strconv.Itoa(cpus[i]), strconv.Itoa(cpus[j]))
+		default:
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// CheckCPUPin returns an error naming the CPUs that the host does not have
+func CheckCPUPin(cpus []int, host *HostTopology) error {
+	online := make(map[int]bool, len(host.CPUs))
+	for _, cpu := range host.CPUs {
+		online[cpu] = true
+	}
+	var missing []string
+	for _, cpu := range cpus {
+		if !online[cpu] {
+			missing = append(missing, strconv.Itoa(cpu))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the host has no CPU %s (available: %s)", strings.Join(missing, ", "), FormatCPUPin(host.CPUs))
+	}
+	return nil
+}
+
+// ValidateMemoryEnforce checks a limits.memory.enforce value
+func ValidateMemoryEnforce(value string) error {
+	if value != MemoryEnforceHard && value != MemoryEnforceSoft {
+		return UsageErrorf("invalid memory enforcement '%s': must be '%s' or '%s'", value, MemoryEnforceHard, MemoryEnforceSoft)
+	}
+	return nil
+}
+
+// ValidateDiskRate checks a limits.read or limits.write value
+func ValidateDiskRate(value string) error {
+	if !diskRatePattern.MatchString(value) {
+		return UsageErrorf("invalid disk rate '%s': use bytes per second such as 50MB or 20MiB, or operations such as 1000iops", value)
+	}
+	return nil
+}
+
+// ValidateNetworkRate checks a limits.egress or limits.ingress value
+func ValidateNetworkRate(value string) error {
+	if !networkRatePattern.MatchString(value) {
+		return UsageErrorf("invalid network rate '%s': use bits per second such as 100Mbit or 1Gbit", value)
+	}
+	return nil
+}
+
+// ValidateDiskPriority checks a limits.disk.priority value, 0 to 10
+func ValidateDiskPriority(value string) error {
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < 0 || priority > 10 {
+		return UsageErrorf("invalid disk priority '%s': must be between 0 and 10", value)
+	}
+	return nil
+}
+
+// PrimaryNIC returns the name of an instance's main network device,
+// preferring eth0; "" when it has none
+func PrimaryNIC(devices map[string]map[string]string) string {
+	var nics []string
+	for name, device := range devices {
+		if device["type"] == "nic" {
+			nics = append(nics, name)
+		}
+	}
+	sort.Strings(nics)
+	for _, name := range nics {
+		if name == "eth0" {
+			return name
+		}
+	}
+	if len(nics) == 0 {
+		return ""
+	}
+	return nics[0]
+}
+
+// SetDeviceLimits sets key=value limits on a device. A device inherited from
+// a profile is overridden on the instance only; local devices are set.
+func SetDeviceLimits(ctx context.Context, containerName, device string, limits []string, local bool) error {
+	action := "override"
+	if local {
+		action = "set"
+	}
+	args := append([]string{"config", "device", action, containerName, device}, limits...)
+	if err := runLXC(ctx, args...); err != nil {
+		return fmt.Errorf("failed to set limits on device '%s' of '%s': %w", device, containerName, err)
+	}
+	return nil
+}
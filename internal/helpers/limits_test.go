@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCPUSet(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []int
+		err      string
+	}{
+		{spec: "0,1", expected: []int{0, 1}},
+		{spec: "0-3,8", expected: []int{0, 1, 2, 3, 8}},
+		{spec: "5, 2", expected: []int{2, 5}},
+		{spec: "", err: "empty"},
+		{spec: "a", err: "invalid CPU"},
+		{spec: "3-1", err: "invalid CPU range"},
+		{spec: "0-2,1", err: "more than once"},
+	}
+	for _, tt := range tests {
+		cpus, err := ParseCPUSet(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseCPUSet(%q): expected error containing %q, got %v", tt.spec, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(cpus, tt.expected) {
+			t.Errorf("ParseCPUSet(%q) = %v, %v; expected %v", tt.spec, cpus, err, tt.expected)
+		}
+	}
+}
+
+func TestFormatCPUPin(t *testing.T) {
+	tests := map[string][]int{
+		"2-2":     {2},
+		"0,1":     {0, 1},
+		"0-3,8":   {0, 1, 2, 3, 8},
+		"1,3,5-7": {1, 3, 5, 6, 7},
+	}
+	for expected, cpus := range tests {
+		if got := FormatCPUPin(cpus); got != expected {
+			t.Errorf("FormatCPUPin(%v) = %q, expected %q", cpus, got, expected)
+		}
+	}
+}
+
+func TestCheckCPUPin(t *testing.T) {
+	host := &HostTopology{CPUs: []int{0, 1, 2, 3}}
+	if err := CheckCPUPin([]int{0, 3}, host); err != nil {
+		t.Errorf("expected online CPUs to pass, got %v", err)
+	}
+	if err := CheckCPUPin([]int{2, 4, 7}, host); err == nil || !strings.Contains(err.Error(), "no CPU 4, 7 (available: 0-3)") {
+		t.Errorf("expected the missing CPUs to be named, got %v", err)
+	}
+}
+
+func TestDetectHostTopology(t *testing.T) {
+	hostRoot = t.TempDir()
+	t.Cleanup(func() { hostRoot = "/" })
+	if err := os.MkdirAll(filepath.Join(hostRoot, "proc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DetectHostTopology(); err == nil {
+		t.Error("expected an error without /proc/cpuinfo")
+	}
+
+	cpuinfo := "processor\t: 0\nmodel name\t: Test CPU\n\nprocessor\t: 1\nmodel name\t: Test CPU\n"
+	swaps := "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n/swap.img                               file\t\t2097148\t\t0\t\t-2\n"
+	os.WriteFile(filepath.Join(hostRoot, "proc/cpuinfo"), []byte(cpuinfo), 0644)
+	os.WriteFile(filepath.Join(hostRoot, "proc/swaps"), []byte(swaps), 0644)
+
+	host, err := DetectHostTopology()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(host.CPUs, []int{0, 1}) || host.SwapBytes != 2097148*1024 {
+		t.Errorf("unexpected topology: %+v", host)
+	}
+}
+
+func TestValidateRates(t *testing.T) {
+	for _, value := range []string{"50MB", "20MiB", "1.5GB", "1000iops"} {
+		if err := ValidateDiskRate(value); err != nil {
+			t.Errorf("expected disk rate %q to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "50", "fast", "100Mbit"} {
+		if err := ValidateDiskRate(value); err == nil {
+			t.Errorf("expected disk rate %q to be rejected", value)
+		}
+	}
+	for _, value := range []string{"100Mbit", "1Gbit", "512kbit"} {
+		if err := ValidateNetworkRate(value); err != nil {
+			t.Errorf("expected network rate %q to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"100MB", "100", "1gbit"} {
+		if err := ValidateNetworkRate(value); err == nil {
+			t.Errorf("expected network rate %q to be rejected", value)
+		}
+	}
+}
+
+func TestPrimaryNIC(t *testing.T) {
+	devices := map[string]map[string]string{
+		"root": {"type": "disk"},
+		"eth1": {"type": "nic"},
+		"eth0": {"type": "nic"},
+	}
+	if nic := PrimaryNIC(devices); nic != "eth0" {
+		t.Errorf("expected eth0, got %q", nic)
+	}
+	delete(devices, "eth0")
+	if nic := PrimaryNIC(devices); nic != "eth1" {
+		t.Errorf("expected eth1, got %q", nic)
+	}
+	if nic := PrimaryNIC(map[string]map[string]string{}); nic != "" {
+		t.Errorf("expected no NIC, got %q", nic)
+	}
+}
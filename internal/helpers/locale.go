@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Files the host's time zone and locale are read from
+var (
+	hostTimezoneFile  = "/etc/timezone"
+	hostLocaltimeFile = "/etc/localtime"
+	hostLocaleFile    = "/etc/default/locale"
+)
+
+var (
+	timezonePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`)
+	localePattern   = regexp.MustCompile(`^[A-Za-z]+(_[A-Za-z]+)?(\.[A-Za-z0-9-]+)?(@[A-Za-z]+)?$`)
+)
+
+// ValidateTimezone checks that a time zone looks like a tz database name such as Europe/London
+func ValidateTimezone(timezone string) error {
+	if !timezonePattern.MatchString(timezone) {
+		return UsageErrorf("invalid time zone '%s': expected a name such as Europe/London or UTC", timezone)
+	}
+	return nil
+}
+
+// ValidateLocale checks that a locale looks like en_GB.UTF-8
+func ValidateLocale(locale string) error {
+	if !localePattern.MatchString(locale) {
+		return UsageErrorf("invalid locale '%s': expected a name such as en_GB.UTF-8", locale)
+	}
+	return nil
+}
+
+// HostTimezone returns the host's time zone, or "" if it cannot be determined
+func HostTimezone() string {
+	if data, err := os.ReadFile(hostTimezoneFile); err == nil {
+		if timezone := strings.TrimSpace(string(data)); ValidateTimezone(timezone) == nil {
+			return timezone
+		}
+	}
+	// Systems without /etc/timezone link /etc/localtime into the tz database
+	target, err := os.Readlink(hostLocaltimeFile)
+	if err != nil {
+		return ""
+	}
+	if _, timezone, ok := strings.Cut(target, "zoneinfo/"); ok && ValidateTimezone(timezone) == nil {
+		return timezone
+	}
+	return ""
+}
+
+// HostLocale returns the host's locale from the environment or the system
+// default, or "" for the built-in C and POSIX locales
+func HostLocale() string {
+	locale := os.Getenv("LC_ALL")
+	if locale == "" {
+		locale = os.Getenv("LANG")
+	}
+	if locale == "" {
+		locale = systemLocale()
+	}
+	if isBuiltinLocale(locale) || ValidateLocale(locale) != nil {
+		return ""
+	}
+	return locale
+}
+
+// systemLocale reads LANG from /etc/default/locale
+func systemLocale() string {
+	file, err := os.Open(hostLocaleFile)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "LANG="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+// isBuiltinLocale reports whether a locale needs no generating
+func isBuiltinLocale(locale string) bool {
+	return locale == "" || locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.")
+}
+
+// timezoneScript sets the time zone with timedatectl, or by linking
+// /etc/localtime when systemd is not up yet
+const timezoneScript = `if command -v timedatectl >/dev/null 2>&1 && timedatectl set-timezone "$1" 2>/dev/null; then exit 0; fi
+[ -e "/usr/share/zoneinfo/$1" ] || DEBIAN_FRONTEND=noninteractive apt-get install -y tzdata >/dev/null
+[ -e "/usr/share/zoneinfo/$1" ] || { echo "unknown time zone: $1" >&2; exit 1; }
+ln -sf "/usr/share/zoneinfo/$1" /etc/localtime && echo "$1" > /etc/timezone`
+
+// localeScript generates the locale and makes it the system default
+const localeScript = `command -v locale-gen >/dev/null 2>&1 || DEBIAN_FRONTEND=noninteractive apt-get install -y locales >/dev/null
+case "$1" in C|C.*|POSIX) ;; *) locale-gen "$1" ;; esac
+update-locale LANG="$1"`
+
+// TimezoneCommand returns the command that sets a container's time zone
+func TimezoneCommand(timezone string) []string {
+	return []string{"sh", "-c", timezoneScript, "sh", timezone}
+}
+
+// LocaleCommand returns the command that sets a container's locale
+func LocaleCommand(locale string) []string {
+	return []string{"sh", "-c", localeScript, "sh", locale}
+}
@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateTimezoneAndLocale(t *testing.T) {
+	for _, timezone := range []string{"UTC", "Europe/London", "America/Argentina/Buenos_Aires", "Etc/GMT+5"} {
+		if err := ValidateTimezone(timezone); err != nil {
+			t.Errorf("ValidateTimezone(%q) failed: %v", timezone, err)
+		}
+	}
+	for _, timezone := range []string{"", "/etc/passwd", "Europe/../London", "Europe/London; reboot"} {
+		if err := ValidateTimezone(timezone); err == nil {
+			t.Errorf("ValidateTimezone(%q) should fail", timezone)
+		}
+	}
+
+	for _, locale := range []string{"en_GB.UTF-8", "C.UTF-8", "POSIX", "sr_RS@latin", "de_DE"} {
+		if err := ValidateLocale(locale); err != nil {
+			t.Errorf("ValidateLocale(%q) failed: %v", locale, err)
+		}
+	}
+	for _, locale := range []string{"", "en GB", "en_GB.UTF-8\nLANG=C", "$(id)"} {
+		if err := ValidateLocale(locale); err == nil {
+			t.Errorf("ValidateLocale(%q) should fail", locale)
+		}
+	}
+}
+
+func TestHostTimezone(t *testing.T) {
+	dir := t.TempDir()
+	oldTimezone, oldLocaltime := hostTimezoneFile, hostLocaltimeFile
+	t.Cleanup(func() { hostTimezoneFile, hostLocaltimeFile = oldTimezone, oldLocaltime })
+	hostTimezoneFile = filepath.Join(dir, "timezone")
+	hostLocaltimeFile = filepath.Join(dir, "localtime")
+
+	if timezone := HostTimezone(); timezone != "" {
+		t.Errorf("expected no time zone, got %q", timezone)
+	}
+
+	if err := os.Symlink("/usr/share/zoneinfo/America/New_York", hostLocaltimeFile); err != nil {
+		t.Fatal(err)
+	}
+	if timezone := HostTimezone(); timezone != "America/New_York" {
+		t.Errorf("expected the /etc/localtime link target, got %q", timezone)
+	}
+
+	if err := os.WriteFile(hostTimezoneFile, []byte("Europe/London\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if timezone := HostTimezone(); timezone != "Europe/London" {
+		t.Errorf("expected /etc/timezone to win, got %q", timezone)
+	}
+}
+
+func TestHostLocale(t *testing.T) {
+	oldLocaleFile := hostLocaleFile
+	t.Cleanup(func() { hostLocaleFile = oldLocaleFile })
+	hostLocaleFile = filepath.Join(t.TempDir(), "locale")
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "en_GB.UTF-8")
+	if locale := HostLocale(); locale != "en_GB.UTF-8" {
+		t.Errorf("expected LANG, got %q", locale)
+	}
+
+	t.Setenv("LC_ALL", "C.UTF-8")
+	if locale := HostLocale(); locale != "" {
+		t.Errorf("built-in locales need no configuring, got %q", locale)
+	}
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "")
+	if err := os.WriteFile(hostLocaleFile, []byte("# generated\nLANG=\"fr_FR.UTF-8\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if locale := HostLocale(); locale != "fr_FR.UTF-8" {
+		t.Errorf("expected the system default, got %q", locale)
+	}
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// LogOptions controls how logs are retrieved from a container
+type LogOptions struct {
+	Follow     bool
+	Since      time.Duration
+	ProjectDir string
+}
+
+// BuildJournalCommand returns the journalctl command used to read the container journal
+func BuildJournalCommand(opts LogOptions) []string {
+	args := []string{"journalctl", "--no-pager", "--output", "short-iso"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Since > 0 {
+		// journalctl accepts relative timestamps prefixed with '-'
+		args = append(args, "--since", fmt.Sprintf("-%ds", int(opts.Since.Seconds())))
+	}
+	return args
+}
+
+// BuildDockerLogsCommand returns the docker compose command used to read logs for a service
+func BuildDockerLogsCommand(service string, opts LogOptions) []string {
+	args := []string{"docker", "compose", "logs", "--no-color"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Since > 0 {
+		args = append(args, "--since", opts.Since.String())
+	}
+	if service != "" {
+		args = append(args, service)
+	}
+	return args
+}
+
+// StreamContainerCommand runs a command inside a container, writing its output to w as it arrives
+func StreamContainerCommand(ctx context.Context, containerName, workDir string, w io.Writer, args ...string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+
+	cmdArgs := []string{"exec", containerName}
+	if workDir != "" {
+		cmdArgs = append(cmdArgs, "--cwd", workDir)
+	}
+	cmdArgs = append(cmdArgs, "--")
+	cmdArgs = append(cmdArgs, args...)
+
+	logger.Debug("Streaming from container '%s': lxc %v", containerName, cmdArgs)
+
+	if err := Runner().RunStreaming(ctx, Streams{Stdout: w, Stderr: w}, "lxc", cmdArgs...); err != nil {
+		// Cancellation (Ctrl-C while following) is a normal way to stop streaming
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command timed out: %w", err)
+		}
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	return nil
+}
+
+// exitCoder is implemented by *exec.ExitError and by MockExitError
+type exitCoder interface {
+	ExitCode() int
+}
+
+// ExitCodeFromError returns the exit code of a failed command, 0 for nil and -1 if unknown
+func ExitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr exitCoder
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// PrefixWriter writes each complete line to an underlying writer with a fixed prefix.
+// Several PrefixWriters may share one underlying writer; lines are never interleaved.
+type PrefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// NewPrefixWriter creates a PrefixWriter; mu guards out and should be shared by all
+// writers multiplexed onto the same output
+func NewPrefixWriter(out io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	if mu == nil {
+		mu = &sync.Mutex{}
+	}
+	return &PrefixWriter{prefix: prefix, out: out, mu: mu}
+}
+
+// Write buffers data and emits every complete line with the prefix
+func (p *PrefixWriter) Write(data []byte) (int, error) {
+	p.buf.Write(data)
+	for {
+		line, err := p.buf.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write
+			p.buf.Reset()
+			p.buf.WriteString(line)
+			break
+		}
+		if err := p.emit(strings.TrimRight(line, "\r\n")); err != nil {
+			return len(data), err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush emits any buffered partial line
+func (p *PrefixWriter) Flush() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	line := p.buf.String()
+	p.buf.Reset()
+	return p.emit(line)
+}
+
+func (p *PrefixWriter) emit(line string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.out, "%s%s\n", p.prefix, line)
+	return err
+}
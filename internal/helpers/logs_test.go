@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuildJournalCommand(t *testing.T) {
+	args := BuildJournalCommand(LogOptions{})
+	if strings.Join(args, " ") != "journalctl --no-pager --output short-iso" {
+		t.Errorf("unexpected default journal command: %v", args)
+	}
+
+	args = BuildJournalCommand(LogOptions{Follow: true, Since: time.Hour})
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "--follow") {
+		t.Errorf("expected --follow in %v", args)
+	}
+	if !strings.Contains(joined, "--since -3600s") {
+		t.Errorf("expected relative --since in %v", args)
+	}
+}
+
+func TestBuildDockerLogsCommand(t *testing.T) {
+	args := BuildDockerLogsCommand("web", LogOptions{Follow: true, Since: 30 * time.Minute})
+	expected := "docker compose logs --no-color --follow --since 30m0s web"
+	if strings.Join(args, " ") != expected {
+		t.Errorf("expected '%s', got '%s'", expected, strings.Join(args, " "))
+	}
+}
+
+func TestStreamContainerCommandValidation(t *testing.T) {
+	var out bytes.Buffer
+	if err := StreamContainerCommand(context.Background(), "", "", &out, "true"); err == nil {
+		t.Error("expected error for empty container name")
+	}
+	if err := StreamContainerCommand(context.Background(), "test", "", &out); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := NewPrefixWriter(&out, "[web] ", &mu)
+
+	w.Write([]byte("first line\nsecond "))
+	w.Write([]byte("line\r\npartial"))
+
+	if out.String() != "[web] first line\n[web] second line\n" {
+		t.Errorf("unexpected output before flush: %q", out.String())
+	}
+
+	w.Flush()
+	if !strings.HasSuffix(out.String(), "[web] partial\n") {
+		t.Errorf("expected flushed partial line, got %q", out.String())
+	}
+
+	// Flushing again is a no-op
+	before := out.Len()
+	w.Flush()
+	if out.Len() != before {
+		t.Error("second flush should not write anything")
+	}
+}
+
+func TestExitCodeFromError(t *testing.T) {
+	if code := ExitCodeFromError(nil); code != 0 {
+		t.Errorf("expected 0 for nil error, got %d", code)
+	}
+	if code := ExitCodeFromError(fmt.Errorf("boom")); code != -1 {
+		t.Errorf("expected -1 for unknown error, got %d", code)
+	}
+
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	if code := ExitCodeFromError(fmt.Errorf("command failed: %w", err)); code != 3 {
+		t.Errorf("expected exit code 3 through wrapping, got %d", code)
+	}
+}
@@ -21,8 +21,19 @@ type LXCInterface interface {
 	StartContainer(ctx context.Context, name string) error
 	RestartContainer(ctx context.Context, name string) error
 	RunInContainer(ctx context.Context, containerName string, args ...string) error
+	StopContainer(ctx context.Context, name string) error
+	FreezeContainer(ctx context.Context, name string) error
+	UnfreezeContainer(ctx context.Context, name string) error
+	GetContainerStatus(ctx context.Context, name string) (string, error)
 	ConfigureContainerSecurity(ctx context.Context, containerName string) error
 
+	// Config, device and snapshot operations
+	GetConfigValue(ctx context.Context, containerName, key string) (string, error)
+	SetConfigValue(ctx context.Context, containerName, key, value string) error
+	AddDevice(ctx context.Context, containerName, deviceName, deviceType string, properties map[string]string) error
+	RemoveDevice(ctx context.Context, containerName, deviceName string) error
+	CreateSnapshot(ctx context.Context, containerName, snapshotName string) error
+
 	// GPU operations
 	GetContainerGPUStatus(ctx context.Context, containerName string) (*GPUStatus, error)
 	EnableContainerGPU(ctx context.Context, containerName string) error
@@ -94,12 +105,57 @@ func (r *RealLXC) StartContainer(ctx context.Context, name string) error {
 
 // RestartContainer restarts an existing container
 func (r *RealLXC) RestartContainer(ctx context.Context, name string) error {
-	return RestartContainer(name)
+	return RestartContainer(ctx, name)
 }
 
 // RunInContainer executes a command inside a container
 func (r *RealLXC) RunInContainer(ctx context.Context, containerName string, args ...string) error {
-	return RunInContainer(containerName, args...)
+	return RunInContainer(ctx, containerName, args...)
+}
+
+// StopContainer stops a running container
+func (r *RealLXC) StopContainer(ctx context.Context, name string) error {
+	return StopContainer(name)
+}
+
+// FreezeContainer freezes a running container
+func (r *RealLXC) FreezeContainer(ctx context.Context, name string) error {
+	return FreezeContainer(name)
+}
+
+// UnfreezeContainer thaws a frozen container
+func (r *RealLXC) UnfreezeContainer(ctx context.Context, name string) error {
+	return UnfreezeContainer(name)
+}
+
+// GetContainerStatus returns the status of a container
+func (r *RealLXC) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	return GetContainerStatus(name)
+}
+
+// GetConfigValue returns a single instance config value
+func (r *RealLXC) GetConfigValue(ctx context.Context, containerName, key string) (string, error) {
+	return GetConfigValue(containerName, key)
+}
+
+// SetConfigValue sets a single instance config value
+func (r *RealLXC) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	return SetConfigValue(containerName, key, value)
+}
+
+// AddDevice adds a device to a container
+func (r *RealLXC) AddDevice(ctx context.Context, containerName, deviceName, deviceType string, properties map[string]string) error {
+	return AddContainerDevice(containerName, deviceName, deviceType, properties)
+}
+
+// RemoveDevice removes a device from a container
+func (r *RealLXC) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	return RemoveContainerDevice(containerName, deviceName)
+}
+
+// CreateSnapshot takes a snapshot of a container
+func (r *RealLXC) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	return CreateSnapshot(containerName, snapshotName)
 }
 
 // ConfigureContainerSecurity sets up security settings needed for Docker
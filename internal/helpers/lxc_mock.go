@@ -7,17 +7,42 @@ import (
 	"sync"
 )
 
+// Container states tracked by MockLXC; they match the statuses LXD reports
+const (
+	MockStateCreated = "Created"
+	MockStateRunning = "Running"
+	MockStateStopped = "Stopped"
+	MockStateFrozen  = StatusFrozen
+)
+
+// MockContainer is the state MockLXC keeps for each container
+type MockContainer struct {
+	State     string
+	Config    map[string]string
+	Devices   map[string]map[string]string
+	Snapshots []string
+	// Processes records every command run in the container, in order
+	Processes [][]string
+}
+
+// MockOperation is a recorded call that targeted a container
+type MockOperation struct {
+	Method    string
+	Container string
+	Args      []string
+}
+
 // MockLXC implements LXCInterface with configurable mock behavior
 type MockLXC struct {
 	mu sync.RWMutex
 
 	// Configuration
-	BtrfsAvailable     bool
-	DefaultPoolType    string
-	ExistingPools      []string
-	ExistingContainers map[string]bool
-	GPUStates          map[string]*GPUStatus
-	Passwords          map[string]string
+	BtrfsAvailable  bool
+	DefaultPoolType string
+	ExistingPools   []string
+	Containers      map[string]*MockContainer
+	GPUStates       map[string]*GPUStatus
+	Passwords       map[string]string
 
 	// Error injection
 	CreatePoolError       error
@@ -33,21 +58,26 @@ type MockLXC struct {
 	StorePasswordError    error
 	GetPasswordError      error
 	SetPasswordError      error
+	StopContainerError    error
+	FreezeError           error
+	SnapshotError         error
+	DeviceError           error
 
 	// Call tracking
-	Calls map[string]int
+	Calls      map[string]int
+	Operations []MockOperation
 }
 
 // NewMockLXC creates a new mock LXC implementation with sensible defaults
 func NewMockLXC() *MockLXC {
 	return &MockLXC{
-		BtrfsAvailable:     true,
-		DefaultPoolType:    "btrfs",
-		ExistingPools:      []string{"default-btrfs", "test-pool"},
-		ExistingContainers: make(map[string]bool),
-		GPUStates:          make(map[string]*GPUStatus),
-		Passwords:          make(map[string]string),
-		Calls:              make(map[string]int),
+		BtrfsAvailable:  true,
+		DefaultPoolType: "btrfs",
+		ExistingPools:   []string{"default-btrfs", "test-pool"},
+		Containers:      make(map[string]*MockContainer),
+		GPUStates:       make(map[string]*GPUStatus),
+		Passwords:       make(map[string]string),
+		Calls:           make(map[string]int),
 	}
 }
 
@@ -174,10 +204,11 @@ func (m *MockLXC) ContainerExists(ctx context.Context, name string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.ExistingContainers[name]
+	_, exists := m.Containers[name]
+	return exists
 }
 
-// CreateContainer creates a new LXC container
+// CreateContainer creates a new LXC container; like lxc launch it is started right away
 func (m *MockLXC) CreateContainer(ctx context.Context, name, distro, release, arch, storagePool string) error {
 	m.trackCall("CreateContainer")
 
@@ -189,7 +220,7 @@ func (m *MockLXC) CreateContainer(ctx context.Context, name, distro, release, ar
 	defer m.mu.Unlock()
 
 	// Check if container already exists
-	if m.ExistingContainers[name] {
+	if _, exists := m.Containers[name]; exists {
 		return fmt.Errorf("container '%s' already exists", name)
 	}
 
@@ -206,11 +237,18 @@ func (m *MockLXC) CreateContainer(ctx context.Context, name, distro, release, ar
 	}
 
 	// Add container
-	m.ExistingContainers[name] = true
+	container := newMockContainer(MockStateRunning)
+	container.Config["image.os"] = distro
+	container.Config["image.release"] = release
+	container.Config["image.architecture"] = arch
+	container.Devices["root"] = map[string]string{"type": "disk", "path": "/", "pool": storagePool}
+	m.Containers[name] = container
+	m.record("CreateContainer", name)
 	return nil
 }
 
-// StartContainer starts an existing container
+// StartContainer starts a created or stopped container and thaws a frozen one.
+// Starting a running container succeeds without a change.
 func (m *MockLXC) StartContainer(ctx context.Context, name string) error {
 	m.trackCall("StartContainer")
 
@@ -218,14 +256,22 @@ func (m *MockLXC) StartContainer(ctx context.Context, name string) error {
 		return m.StartContainerError
 	}
 
-	if !m.ContainerExists(ctx, name) {
-		return fmt.Errorf("container '%s' does not exist", name)
+	return m.transition("StartContainer", name, MockStateRunning,
+		MockStateCreated, MockStateStopped, MockStateFrozen, MockStateRunning)
+}
+
+// StopContainer stops a running or frozen container
+func (m *MockLXC) StopContainer(ctx context.Context, name string) error {
+	m.trackCall("StopContainer")
+
+	if m.StopContainerError != nil {
+		return m.StopContainerError
 	}
 
-	return nil
+	return m.transition("StopContainer", name, MockStateStopped, MockStateRunning, MockStateFrozen)
 }
 
-// RestartContainer restarts an existing container
+// RestartContainer restarts a running container
 func (m *MockLXC) RestartContainer(ctx context.Context, name string) error {
 	m.trackCall("RestartContainer")
 
@@ -233,14 +279,46 @@ func (m *MockLXC) RestartContainer(ctx context.Context, name string) error {
 		return m.RestartContainerError
 	}
 
-	if !m.ContainerExists(ctx, name) {
-		return fmt.Errorf("container '%s' does not exist", name)
+	return m.transition("RestartContainer", name, MockStateRunning, MockStateRunning)
+}
+
+// FreezeContainer freezes a running container
+func (m *MockLXC) FreezeContainer(ctx context.Context, name string) error {
+	m.trackCall("FreezeContainer")
+
+	if m.FreezeError != nil {
+		return m.FreezeError
 	}
 
-	return nil
+	return m.transition("FreezeContainer", name, MockStateFrozen, MockStateRunning)
+}
+
+// UnfreezeContainer thaws a frozen container
+func (m *MockLXC) UnfreezeContainer(ctx context.Context, name string) error {
+	m.trackCall("UnfreezeContainer")
+
+	if m.FreezeError != nil {
+		return m.FreezeError
+	}
+
+	return m.transition("UnfreezeContainer", name, MockStateRunning, MockStateFrozen)
+}
+
+// GetContainerStatus returns the state of a container
+func (m *MockLXC) GetContainerStatus(ctx context.Context, name string) (string, error) {
+	m.trackCall("GetContainerStatus")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	container, exists := m.Containers[name]
+	if !exists {
+		return "", fmt.Errorf("container '%s' does not exist", name)
+	}
+	return container.State, nil
 }
 
-// RunInContainer executes a command inside a container
+// RunInContainer executes a command inside a running container and records it
+// in the container's process table
 func (m *MockLXC) RunInContainer(ctx context.Context, containerName string, args ...string) error {
 	m.trackCall("RunInContainer")
 
@@ -248,28 +326,23 @@ func (m *MockLXC) RunInContainer(ctx context.Context, containerName string, args
 		return m.RunCommandError
 	}
 
-	if !m.ContainerExists(ctx, containerName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
 		return fmt.Errorf("container '%s' does not exist", containerName)
 	}
-
-	// Simulate command execution
-	if len(args) > 0 {
-		// Check for specific failure scenarios
-		if args[0] == "apt-get" && len(args) > 1 && args[1] == "update" {
-			// Simulate package update - always succeeds in mock
-		} else if args[0] == "apt-get" && len(args) > 1 && args[1] == "install" {
-			// Simulate package installation - always succeeds in mock
-		} else if args[0] == "useradd" {
-			// Simulate user creation - always succeeds in mock
-		} else if args[0] == "usermod" {
-			// Simulate user modification - always succeeds in mock
-		}
+	if container.State != MockStateRunning {
+		return fmt.Errorf("container '%s' is not running (state: %s)", containerName, container.State)
 	}
 
+	container.Processes = append(container.Processes, append([]string(nil), args...))
+	m.Operations = append(m.Operations, MockOperation{Method: "RunInContainer", Container: containerName, Args: append([]string(nil), args...)})
 	return nil
 }
 
-// ConfigureContainerSecurity sets up security settings needed for Docker
+// ConfigureContainerSecurity applies the Docker security settings to the container config
 func (m *MockLXC) ConfigureContainerSecurity(ctx context.Context, containerName string) error {
 	m.trackCall("ConfigureContainerSecurity")
 
@@ -277,13 +350,156 @@ func (m *MockLXC) ConfigureContainerSecurity(ctx context.Context, containerName
 		return m.SecurityConfigError
 	}
 
-	if !m.ContainerExists(ctx, containerName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
+		return fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	for key, value := range DockerSecurityConfig {
+		container.Config[key] = value
+	}
+	m.Operations = append(m.Operations, MockOperation{Method: "ConfigureContainerSecurity", Container: containerName})
+	return nil
+}
+
+// GetConfigValue returns a config value of a container, empty if unset
+func (m *MockLXC) GetConfigValue(ctx context.Context, containerName, key string) (string, error) {
+	m.trackCall("GetConfigValue")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
+		return "", fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	return container.Config[key], nil
+}
+
+// SetConfigValue sets a config value of a container
+func (m *MockLXC) SetConfigValue(ctx context.Context, containerName, key, value string) error {
+	m.trackCall("SetConfigValue")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
+		return fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	container.Config[key] = value
+	m.Operations = append(m.Operations, MockOperation{Method: "SetConfigValue", Container: containerName, Args: []string{key, value}})
+	return nil
+}
+
+// AddDevice adds a device to a container; adding an existing device name fails like lxc does
+func (m *MockLXC) AddDevice(ctx context.Context, containerName, deviceName, deviceType string, properties map[string]string) error {
+	m.trackCall("AddDevice")
+
+	if m.DeviceError != nil {
+		return m.DeviceError
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
 		return fmt.Errorf("container '%s' does not exist", containerName)
 	}
+	if _, exists := container.Devices[deviceName]; exists {
+		return fmt.Errorf("device '%s' already exists on container '%s'", deviceName, containerName)
+	}
 
+	device := map[string]string{"type": deviceType}
+	for key, value := range properties {
+		device[key] = value
+	}
+	container.Devices[deviceName] = device
+	m.Operations = append(m.Operations, MockOperation{Method: "AddDevice", Container: containerName, Args: []string{deviceName, deviceType}})
 	return nil
 }
 
+// RemoveDevice removes a device from a container
+func (m *MockLXC) RemoveDevice(ctx context.Context, containerName, deviceName string) error {
+	m.trackCall("RemoveDevice")
+
+	if m.DeviceError != nil {
+		return m.DeviceError
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
+		return fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	if _, exists := container.Devices[deviceName]; !exists {
+		return fmt.Errorf("device '%s' does not exist on container '%s'", deviceName, containerName)
+	}
+	delete(container.Devices, deviceName)
+	m.Operations = append(m.Operations, MockOperation{Method: "RemoveDevice", Container: containerName, Args: []string{deviceName}})
+	return nil
+}
+
+// CreateSnapshot records a snapshot of a container
+func (m *MockLXC) CreateSnapshot(ctx context.Context, containerName, snapshotName string) error {
+	m.trackCall("CreateSnapshot")
+
+	if m.SnapshotError != nil {
+		return m.SnapshotError
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[containerName]
+	if !exists {
+		return fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	for _, existing := range container.Snapshots {
+		if existing == snapshotName {
+			return fmt.Errorf("snapshot '%s' of container '%s' already exists", snapshotName, containerName)
+		}
+	}
+	container.Snapshots = append(container.Snapshots, snapshotName)
+	m.Operations = append(m.Operations, MockOperation{Method: "CreateSnapshot", Container: containerName, Args: []string{snapshotName}})
+	return nil
+}
+
+// transition moves a container to the target state if it is in one of the allowed states
+func (m *MockLXC) transition(method, name, target string, from ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	container, exists := m.Containers[name]
+	if !exists {
+		return fmt.Errorf("container '%s' does not exist", name)
+	}
+	for _, state := range from {
+		if container.State == state {
+			container.State = target
+			m.Operations = append(m.Operations, MockOperation{Method: method, Container: name})
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot %s container '%s' in state %s", strings.TrimSuffix(strings.ToLower(method), "container"), name, container.State)
+}
+
+// record appends an operation; the caller must hold the lock
+func (m *MockLXC) record(method, container string, args ...string) {
+	m.Operations = append(m.Operations, MockOperation{Method: method, Container: container, Args: args})
+}
+
+func newMockContainer(state string) *MockContainer {
+	return &MockContainer{
+		State:   state,
+		Config:  make(map[string]string),
+		Devices: make(map[string]map[string]string),
+	}
+}
+
 // Helper methods for testing
 
 // trackCall increments the call counter for a method
@@ -306,7 +522,8 @@ func (m *MockLXC) Reset() {
 	defer m.mu.Unlock()
 
 	m.Calls = make(map[string]int)
-	m.ExistingContainers = make(map[string]bool)
+	m.Operations = nil
+	m.Containers = make(map[string]*MockContainer)
 
 	// Reset errors
 	m.CreatePoolError = nil
@@ -316,20 +533,118 @@ func (m *MockLXC) Reset() {
 	m.RunCommandError = nil
 	m.SecurityConfigError = nil
 	m.SetDefaultPoolError = nil
+	m.StopContainerError = nil
+	m.FreezeError = nil
+	m.SnapshotError = nil
+	m.DeviceError = nil
 }
 
-// AddContainer adds a container to the mock state
+// AddContainer adds a running container to the mock state
 func (m *MockLXC) AddContainer(name string) {
+	m.AddContainerWithState(name, MockStateRunning)
+}
+
+// AddContainerWithState adds a container in the given state to the mock state
+func (m *MockLXC) AddContainerWithState(name, state string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.ExistingContainers[name] = true
+	m.Containers[name] = newMockContainer(state)
 }
 
 // RemoveContainer removes a container from the mock state
 func (m *MockLXC) RemoveContainer(name string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.ExistingContainers, name)
+	delete(m.Containers, name)
+}
+
+// Container returns a copy of a container's mock state, or nil if it does not exist
+func (m *MockLXC) Container(name string) *MockContainer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	container, exists := m.Containers[name]
+	if !exists {
+		return nil
+	}
+	clone := &MockContainer{
+		State:     container.State,
+		Config:    make(map[string]string, len(container.Config)),
+		Devices:   make(map[string]map[string]string, len(container.Devices)),
+		Snapshots: append([]string(nil), container.Snapshots...),
+		Processes: append([][]string(nil), container.Processes...),
+	}
+	for key, value := range container.Config {
+		clone.Config[key] = value
+	}
+	for name, device := range container.Devices {
+		clone.Devices[name] = make(map[string]string, len(device))
+		for key, value := range device {
+			clone.Devices[name][key] = value
+		}
+	}
+	return clone
+}
+
+// OperationsFor returns the recorded operations that targeted a container, in order
+func (m *MockLXC) OperationsFor(containerName string) []MockOperation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var operations []MockOperation
+	for _, operation := range m.Operations {
+		if operation.Container == containerName {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// ExpectOperations checks that the given methods were called on a container in
+// this order; other operations may occur in between
+func (m *MockLXC) ExpectOperations(containerName string, methods ...string) error {
+	operations := m.OperationsFor(containerName)
+	next := 0
+	for _, operation := range operations {
+		if next < len(methods) && operation.Method == methods[next] {
+			next++
+		}
+	}
+	if next < len(methods) {
+		recorded := make([]string, len(operations))
+		for i, operation := range operations {
+			recorded[i] = operation.Method
+		}
+		return fmt.Errorf("expected operation %s on container '%s' after %v, recorded: %v",
+			methods[next], containerName, methods[:next], recorded)
+	}
+	return nil
+}
+
+// ExpectState checks the current state of a container
+func (m *MockLXC) ExpectState(containerName, state string) error {
+	container := m.Container(containerName)
+	if container == nil {
+		return fmt.Errorf("container '%s' does not exist", containerName)
+	}
+	if container.State != state {
+		return fmt.Errorf("expected container '%s' to be %s, got %s", containerName, state, container.State)
+	}
+	return nil
+}
+
+// RanCommand reports whether a command starting with the given arguments ran in a container
+func (m *MockLXC) RanCommand(containerName string, prefix ...string) bool {
+	container := m.Container(containerName)
+	if container == nil {
+		return false
+	}
+	for _, process := range container.Processes {
+		if len(process) >= len(prefix) && strings.Join(process[:len(prefix)], " ") == strings.Join(prefix, " ") {
+			return true
+		}
+	}
+	return false
 }
 
 // AddPool adds a storage pool to the mock state
@@ -523,5 +838,13 @@ func (m *MockLXC) SetError(operation string, err error) {
 		m.GetPasswordError = err
 	case "setpassword":
 		m.SetPasswordError = err
+	case "stopcontainer":
+		m.StopContainerError = err
+	case "freeze":
+		m.FreezeError = err
+	case "snapshot":
+		m.SnapshotError = err
+	case "device":
+		m.DeviceError = err
 	}
 }
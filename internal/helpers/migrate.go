@@ -0,0 +1,184 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// StateChange is one change a migration makes to a container: setting a
+// config key, or renaming a device, which LXD does by removing it and adding
+// it back with the same properties
+type StateChange struct {
+	ConfigKey   string
+	ConfigValue string
+
+	Device    string
+	NewDevice string
+	// Properties of the device, with its type
+	Properties map[string]string
+}
+
+// String describes the change for dry runs
+func (c StateChange) String() string {
+	if c.Device != "" {
+		return fmt.Sprintf("rename device '%s' to '%s'", c.Device, c.NewDevice)
+	}
+	return fmt.Sprintf("set %s=%s", c.ConfigKey, c.ConfigValue)
+}
+
+// StateMigration upgrades one deprecated format of the metadata this tool
+// keeps on containers. Plan looks at a container and returns the changes it
+// needs, none once it is current, so migrations are safe to run repeatedly.
+type StateMigration struct {
+	ID          string
+	Description string
+	Plan        func(state ContainerState) []StateChange
+}
+
+// StateMigrations are all migrations in the order they run. Add new ones at
+// the end; keep old ones as long as containers from the releases that wrote
+// the deprecated format may still be around.
+var StateMigrations = []StateMigration{
+	{
+		ID:          "managed-marker",
+		Description: "containers from older releases are only recognized by user.app-password; mark them with " + ManagedMarkerKey,
+		Plan:        planManagedMarker,
+	},
+	{
+		ID:          "port-device-names",
+		Description: "port forwarding devices still named after a name the container was restored from the trash under with 'restore-from-trash --as'",
+		Plan:        planPortDeviceNames,
+	},
+}
+
+// PendingMigration is a migration a container still needs
+type PendingMigration struct {
+	Container string
+	Migration StateMigration
+	Changes   []StateChange
+}
+
+// PendingMigrations returns the migrations managed containers need, by
+// container and in migration order. Trashed containers are left alone until
+// they are restored.
+func PendingMigrations(states []ContainerState) []PendingMigration {
+	sorted := append([]ContainerState(nil), states...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var pending []PendingMigration
+	for _, state := range sorted {
+		if !IsManagedContainer(state.Config) || IsTrashed(state.Config) {
+			continue
+		}
+		for _, migration := range StateMigrations {
+			if changes := migration.Plan(state); len(changes) > 0 {
+				pending = append(pending, PendingMigration{Container: state.Name, Migration: migration, Changes: changes})
+			}
+		}
+	}
+	return pending
+}
+
+// ApplyStateChange makes one migration change to a container
+func ApplyStateChange(ctx context.Context, containerName string, change StateChange) error {
+	if change.Device == "" {
+		if err := runLXC(ctx, "config", "set", containerName, change.ConfigKey+"="+change.ConfigValue); err != nil {
+			return fmt.Errorf("failed to set %s on container '%s': %w", change.ConfigKey, containerName, err)
+		}
+		return nil
+	}
+
+	properties := make(map[string]string, len(change.Properties))
+	for key, value := range change.Properties {
+		if key != "type" {
+			properties[key] = value
+		}
+	}
+	deviceType := change.Properties["type"]
+	if err := runLXC(ctx, "config", "device", "remove", containerName, change.Device); err != nil {
+		return fmt.Errorf("failed to remove device '%s' from container '%s': %w", change.Device, containerName, err)
+	}
+	if err := runLXC(ctx, deviceAddArgs(containerName, change.NewDevice, deviceType, properties)...); err != nil {
+		// Put the device back under its old name rather than lose the forward
+		restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), undoDeviceTimeout)
+		defer cancel()
+		if restoreErr := runLXC(restoreCtx, deviceAddArgs(containerName, change.Device, deviceType, properties)...); restoreErr != nil {
+			return fmt.Errorf("device '%s' was removed but could not be added back as '%s' (%v) or under its old name: %w", change.Device, change.NewDevice, err, restoreErr)
+		}
+		return fmt.Errorf("failed to rename device '%s' to '%s': %w", change.Device, change.NewDevice, err)
+	}
+	return nil
+}
+
+// undoDeviceTimeout bounds putting a device back after a failed rename, when
+// the command's own timeout may have run out
+const undoDeviceTimeout = 30 * time.Second
+
+// planManagedMarker marks containers that are only recognized as managed by
+// their stored app password
+func planManagedMarker(state ContainerState) []StateChange {
+	if state.Config[ManagedMarkerKey] == "true" {
+		return nil
+	}
+	if _, ok := state.Config["user.app-password"]; !ok {
+		return nil
+	}
+	return []StateChange{{ConfigKey: ManagedMarkerKey, ConfigValue: "true"}}
+}
+
+// Port forwarding device names, split into the container name they start
+// with and the rest: {hostPort}-{containerPort}-{protocol} and any listen
+// address, or rev-{containerPort}-{hostPort}-{protocol} for reverse devices
+var (
+	portDeviceNamePattern        = regexp.MustCompile(`^(.+)-(\d+-\d+-(?:tcp|udp)(?:-[0-9a-f-]+)?)$`)
+	reversePortDeviceNamePattern = regexp.MustCompile(`^(.+)-(rev-\d+-\d+-(?:tcp|udp))$`)
+)
+
+// planPortDeviceNames renames proxy devices following the port forwarding
+// naming convention for a name the container had before it was restored
+// from the trash under another one. Devices named after any other container,
+// e.g. added with plain lxc, and adopted devices keep their names, and a
+// rename is skipped when the new name is taken.
+func planPortDeviceNames(state ContainerState) []StateChange {
+	previous := make(map[string]bool)
+	for _, name := range SplitConfigList(state.Config[PreviousNamesKey]) {
+		previous[name] = name != state.Name
+	}
+	if len(previous) == 0 {
+		return nil
+	}
+	adopted := make(map[string]bool)
+	for _, name := range SplitConfigList(state.Config[AdoptedPortsKey]) {
+		adopted[name] = true
+	}
+
+	names := make([]string, 0, len(state.Devices))
+	for name := range state.Devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []StateChange
+	for _, name := range names {
+		device := state.Devices[name]
+		if device["type"] != "proxy" || adopted[name] {
+			continue
+		}
+		match := reversePortDeviceNamePattern.FindStringSubmatch(name)
+		if match == nil {
+			match = portDeviceNamePattern.FindStringSubmatch(name)
+		}
+		if match == nil || !previous[match[1]] {
+			continue
+		}
+		newName := state.Name + "-" + match[2]
+		if _, taken := state.Devices[newName]; taken {
+			continue
+		}
+		changes = append(changes, StateChange{Device: name, NewDevice: newName, Properties: device})
+	}
+	return changes
+}
@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	proxy := map[string]string{"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:127.0.0.1:80"}
+	states := []ContainerState{
+		{Name: "web", Config: map[string]string{"user.app-password": "c2VjcmV0"}},
+		{Name: "api", Config: map[string]string{ManagedMarkerKey: "true", AdoptedPortsKey: "legacy-9000-90-tcp", PreviousNamesKey: "old,legacy"}, Devices: map[string]map[string]string{
+			"old-8080-80-tcp":            proxy,
+			"plain-7000-70-tcp":          proxy,
+			"old-rev-5432-15432-tcp":     {"type": "proxy", "bind": "container"},
+			"old-443-443-tcp-192-0-2-10": proxy,
+			"api-9090-90-tcp":            proxy,
+			"legacy-9000-90-tcp":         proxy,
+			"root":                       {"type": "disk", "path": "/"},
+		}},
+		{Name: "current", Config: map[string]string{ManagedMarkerKey: "true"}, Devices: map[string]map[string]string{
+			"current-8080-80-tcp": proxy,
+			"elsewhere-80-80-tcp": proxy,
+		}},
+		{Name: "trash-20250101-120000-db", Config: map[string]string{ManagedMarkerKey: "true", TrashedKey: "2025-01-01T12:00:00Z"}, Devices: map[string]map[string]string{
+			"db-5432-5432-tcp": proxy,
+		}},
+		{Name: "other", Config: map[string]string{}, Devices: map[string]map[string]string{"x-1-1-tcp": proxy}},
+	}
+
+	pending := PendingMigrations(states)
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending migrations, got %+v", pending)
+	}
+
+	if pending[0].Container != "api" || pending[0].Migration.ID != "port-device-names" {
+		t.Errorf("unexpected first migration %s/%s", pending[0].Container, pending[0].Migration.ID)
+	}
+	var renames []string
+	for _, change := range pending[0].Changes {
+		renames = append(renames, change.String())
+	}
+	expected := []string{
+		"rename device 'old-443-443-tcp-192-0-2-10' to 'api-443-443-tcp-192-0-2-10'",
+		"rename device 'old-8080-80-tcp' to 'api-8080-80-tcp'",
+		"rename device 'old-rev-5432-15432-tcp' to 'api-rev-5432-15432-tcp'",
+	}
+	if !reflect.DeepEqual(renames, expected) {
+		t.Errorf("expected renames %v, got %v", expected, renames)
+	}
+
+	if pending[1].Container != "web" || pending[1].Migration.ID != "managed-marker" {
+		t.Errorf("unexpected second migration %s/%s", pending[1].Container, pending[1].Migration.ID)
+	}
+	if change := pending[1].Changes[0]; change.ConfigKey != ManagedMarkerKey || change.ConfigValue != "true" {
+		t.Errorf("expected the marker to be set, got %s", change)
+	}
+}
+
+func TestPendingMigrationsSkipsTakenNames(t *testing.T) {
+	proxy := map[string]string{"type": "proxy"}
+	states := []ContainerState{{Name: "api", Config: map[string]string{ManagedMarkerKey: "true", PreviousNamesKey: "old"}, Devices: map[string]map[string]string{
+		"old-8080-80-tcp": proxy,
+		"api-8080-80-tcp": proxy,
+	}}}
+	if pending := PendingMigrations(states); len(pending) != 0 {
+		t.Errorf("expected no rename onto an existing device, got %+v", pending)
+	}
+}
+
+func TestPendingMigrationsPreviousNameStartsWithName(t *testing.T) {
+	proxy := map[string]string{"type": "proxy"}
+	states := []ContainerState{{Name: "web", Config: map[string]string{ManagedMarkerKey: "true", PreviousNamesKey: "web-old"}, Devices: map[string]map[string]string{
+		"web-old-8080-80-tcp": proxy,
+		"web-9090-90-tcp":     proxy,
+	}}}
+	pending := PendingMigrations(states)
+	if len(pending) != 1 || len(pending[0].Changes) != 1 || pending[0].Changes[0].NewDevice != "web-8080-80-tcp" {
+		t.Errorf("expected web-old-8080-80-tcp to be renamed, got %+v", pending)
+	}
+}
+
+func TestApplyStateChangePutsDeviceBack(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("", fmt.Errorf("exit status 1"), "lxc", "config", "device", "add", "api", "api-8080-80-tcp")
+
+	err := ApplyStateChange(context.Background(), "api", StateChange{
+		Device:     "old-8080-80-tcp",
+		NewDevice:  "api-8080-80-tcp",
+		Properties: map[string]string{"type": "proxy", "listen": "tcp:0.0.0.0:8080"},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !runner.Ran("lxc", "config", "device", "add", "api", "old-8080-80-tcp", "proxy", "listen=tcp:0.0.0.0:8080") {
+		t.Errorf("expected the device to be added back under its old name, ran %v", runner.Commands)
+	}
+}
+
+func TestApplyStateChange(t *testing.T) {
+	runner := useMockRunner(t)
+
+	err := ApplyStateChange(context.Background(), "api", StateChange{
+		Device:     "old-8080-80-tcp",
+		NewDevice:  "api-8080-80-tcp",
+		Properties: map[string]string{"type": "proxy", "listen": "tcp:0.0.0.0:8080", "connect": "tcp:127.0.0.1:80"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := ApplyStateChange(context.Background(), "web", StateChange{ConfigKey: ManagedMarkerKey, ConfigValue: "true"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = runner.ExpectCommands(
+		[]string{"lxc", "config", "device", "remove", "api", "old-8080-80-tcp"},
+		[]string{"lxc", "config", "device", "add", "api", "api-8080-80-tcp", "proxy", "connect=tcp:127.0.0.1:80", "listen=tcp:0.0.0.0:8080"},
+		[]string{"lxc", "config", "set", "web", ManagedMarkerKey + "=true"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Mount shares a host folder into a container
+type Mount struct {
+	Source string
+	Path   string
+}
+
+// ParseMount parses a host:container mount such as .:/src; the host side is
+// made absolute and must be an existing directory
+func ParseMount(spec string) (Mount, error) {
+	source, target, ok := strings.Cut(spec, ":")
+	if !ok || source == "" || target == "" {
+		return Mount{}, UsageErrorf("invalid mount '%s': expected <host-dir>:<container-path>", spec)
+	}
+	if !path.IsAbs(target) {
+		return Mount{}, UsageErrorf("invalid mount '%s': container path '%s' must be absolute", spec, target)
+	}
+
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return Mount{}, UsageErrorf("invalid mount '%s': %w", spec, err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return Mount{}, UsageErrorf("invalid mount '%s': %w", spec, err)
+	}
+	if !info.IsDir() {
+		return Mount{}, UsageErrorf("invalid mount '%s': %s is not a directory", spec, source)
+	}
+	return Mount{Source: source, Path: path.Clean(target)}, nil
+}
+
+// DeviceProperties returns the disk device properties for the mount. shift
+// makes LXD map ownership with an idmapped mount, so files keep their host
+// owner without a raw.idmap or a container restart.
+func (m Mount) DeviceProperties() map[string]string {
+	return map[string]string{"source": m.Source, "path": m.Path, "shift": "true"}
+}
+
+// RandomContainerName returns a name such as run-3f9a1c for throwaway containers
+func RandomContainerName(prefix string) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the pid
+		return fmt.Sprintf("%s-%d", prefix, os.Getpid())
+	}
+	return prefix + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseMount(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mount, err := ParseMount(dir + ":/src/")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mount.Source != dir || mount.Path != "/src" {
+		t.Errorf("unexpected mount %+v", mount)
+	}
+
+	mount, err = ParseMount(".:/src")
+	if err != nil || !filepath.IsAbs(mount.Source) {
+		t.Errorf("expected . to be made absolute, got %+v (%v)", mount, err)
+	}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{dir, "expected <host-dir>:<container-path>"},
+		{dir + ":src", "must be absolute"},
+		{file + ":/src", "is not a directory"},
+		{filepath.Join(dir, "missing") + ":/src", "no such file"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseMount(tt.spec); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.spec, tt.want, err)
+		}
+	}
+}
+
+func TestMountDeviceProperties(t *testing.T) {
+	props := Mount{Source: "/srv/app", Path: "/src"}.DeviceProperties()
+	if props["source"] != "/srv/app" || props["path"] != "/src" || props["shift"] != "true" {
+		t.Errorf("unexpected device properties %v", props)
+	}
+}
+
+func TestRandomContainerName(t *testing.T) {
+	name := RandomContainerName("run")
+	if !regexp.MustCompile(`^run-[0-9a-f]{6}$`).MatchString(name) {
+		t.Errorf("unexpected name %q", name)
+	}
+	if name == RandomContainerName("run") {
+		t.Error("expected names to differ")
+	}
+}
@@ -0,0 +1,221 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AutoContainerName asks create to generate a name
+const AutoContainerName = "auto"
+
+// maxContainerNameLength is LXD's limit, as names double as hostnames
+const maxContainerNameLength = 63
+
+// petNameAttempts bounds how many generated names are tried before giving up
+const petNameAttempts = 20
+
+// Words for generated names such as brave-otter
+var (
+	petAdjectives = []string{
+		"agile", "amber", "bold", "brave", "brisk", "calm", "clever", "cosy", "crisp", "eager",
+		"fancy", "fluffy", "gentle", "glad", "golden", "happy", "hardy", "humble", "jolly", "keen",
+		"kind", "lively", "loyal", "lucky", "mellow", "merry", "mighty", "misty", "nimble", "noble",
+		"patient", "plucky", "polite", "proud", "quick", "quiet", "rapid", "rustic", "shiny", "silent",
+		"sleepy", "smart", "snappy", "steady", "sunny", "swift", "tidy", "witty", "young", "zesty",
+	}
+	petAnimals = []string{
+		"badger", "beaver", "bison", "camel", "cheetah", "crane", "dingo", "dolphin", "eagle", "falcon",
+		"ferret", "finch", "gecko", "gibbon", "heron", "ibis", "jackal", "koala", "lemur", "lynx",
+		"magpie", "marmot", "meerkat", "moose", "newt", "ocelot", "orca", "osprey", "otter", "owl",
+		"panda", "pelican", "puffin", "quail", "rabbit", "raven", "salmon", "seal", "sparrow", "stork",
+		"swan", "tapir", "tiger", "toucan", "turtle", "walrus", "weasel", "wombat", "yak", "zebra",
+	}
+)
+
+// ValidateContainerName checks a name against LXD's rules before anything is
+// created: 1 to 63 ASCII letters, digits and hyphens, not starting with a
+// digit or hyphen and not ending with a hyphen
+func ValidateContainerName(name string) error {
+	if name == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if len(name) > maxContainerNameLength {
+		return fmt.Errorf("container name '%s' is %d characters long; the maximum is %d", name, len(name), maxContainerNameLength)
+	}
+	for _, r := range name {
+		if !isNameRune(r) {
+			return fmt.Errorf("container name '%s' contains %q; use only letters, digits and '-'%s", name, r, suggestion(name))
+		}
+	}
+	switch {
+	case name[0] >= '0' && name[0] <= '9':
+		return fmt.Errorf("container name '%s' must not start with a digit%s", name, suggestion(name))
+	case name[0] == '-':
+		return fmt.Errorf("container name '%s' must not start with '-'%s", name, suggestion(name))
+	case name[len(name)-1] == '-':
+		return fmt.Errorf("container name '%s' must not end with '-'%s", name, suggestion(name))
+	}
+	return nil
+}
+
+// isNameRune reports whether r may appear in a container name
+func isNameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-'
+}
+
+// SuggestContainerName turns an invalid name into a valid one, e.g. my_app.v2
+// into my-app-v2; empty when nothing usable is left
+func SuggestContainerName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if isNameRune(r) {
+			b.WriteRune(r)
+		} else if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	suggested := strings.Trim(b.String(), "-")
+	if suggested != "" && suggested[0] >= '0' && suggested[0] <= '9' {
+		suggested = "c-" + suggested
+	}
+	if len(suggested) > maxContainerNameLength {
+		suggested = strings.TrimRight(suggested[:maxContainerNameLength], "-")
+	}
+	return suggested
+}
+
+// suggestion returns a hint with a valid alternative name, if there is one
+func suggestion(name string) string {
+	if suggested := SuggestContainerName(name); suggested != "" && suggested != name {
+		return fmt.Sprintf(" (try '%s')", suggested)
+	}
+	return ""
+}
+
+// maxSuggestions is how many close names a "did you mean" hint lists
+const maxSuggestions = 3
+
+// foldName reduces a name to what a person typing it is likely to get right:
+// lower case without hyphens or underscores, so WebAPI, web_api and web-api
+// all fold to webapi
+func foldName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r != '-' && r != '_' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// MatchContainerName finds the one existing container a mistyped name means,
+// ignoring case, hyphens and underscores. An exact match wins; nothing is
+// returned when the name is ambiguous or matches no container.
+func MatchContainerName(name string, names []string) (string, bool) {
+	folded := foldName(name)
+	match := ""
+	for _, candidate := range names {
+		if candidate == name {
+			return candidate, true
+		}
+		if foldName(candidate) == folded {
+			if match != "" {
+				return "", false
+			}
+			match = candidate
+		}
+	}
+	return match, match != ""
+}
+
+// SimilarContainerNames returns up to three existing names close to name,
+// closest first, for "did you mean" hints. Names within an edit distance of
+// a third of the name's length (at least one), or containing it, count as
+// close.
+func SimilarContainerNames(name string, names []string) []string {
+	folded := foldName(name)
+	if folded == "" {
+		return nil
+	}
+	limit := max(len(folded)/3, 1)
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var similar []scored
+	for _, candidate := range names {
+		if candidate == name {
+			continue
+		}
+		candidateFolded := foldName(candidate)
+		distance := editDistance(folded, candidateFolded)
+		if distance > limit && (len(folded) < 3 || !strings.Contains(candidateFolded, folded)) {
+			continue
+		}
+		similar = append(similar, scored{candidate, distance})
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].distance != similar[j].distance {
+			return similar[i].distance < similar[j].distance
+		}
+		return similar[i].name < similar[j].name
+	})
+
+	var result []string
+	for i := 0; i < len(similar) && i < maxSuggestions; i++ {
+		result = append(result, similar[i].name)
+	}
+	return result
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// GeneratePetName returns a memorable name such as brave-otter that exists
+// reports as unused. After repeated collisions a number is appended.
+func GeneratePetName(exists func(name string) bool) (string, error) {
+	for attempt := 0; attempt < petNameAttempts; attempt++ {
+		adjective, err := randomInt(len(petAdjectives))
+		if err != nil {
+			return "", err
+		}
+		animal, err := randomInt(len(petAnimals))
+		if err != nil {
+			return "", err
+		}
+		name := petAdjectives[adjective] + "-" + petAnimals[animal]
+		// Half the attempts use the bare name; busy hosts get a number
+		if attempt >= petNameAttempts/2 {
+			number, err := randomInt(100)
+			if err != nil {
+				return "", err
+			}
+			name = fmt.Sprintf("%s-%d", name, number)
+		}
+		if !exists(name) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate an unused container name after %d attempts; pass --name", petNameAttempts)
+}
@@ -0,0 +1,131 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidateContainerName(t *testing.T) {
+	for _, name := range []string{"web", "Web-01", "a", strings.Repeat("a", 63)} {
+		if err := ValidateContainerName(name); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "container name is required"},
+		{strings.Repeat("a", 64), "is 64 characters long; the maximum is 63"},
+		{"my_app", `contains '_'; use only letters, digits and '-' (try 'my-app')`},
+		{"web.example", `contains '.'`},
+		{"2web", "must not start with a digit (try 'c-2web')"},
+		{"-web", "must not start with '-' (try 'web')"},
+		{"web-", "must not end with '-' (try 'web')"},
+	}
+	for _, tt := range tests {
+		if err := ValidateContainerName(tt.name); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestSuggestContainerName(t *testing.T) {
+	for name, want := range map[string]string{
+		"my_app.v2": "my-app-v2",
+		"__":        "",
+		"web":       "web",
+		"9lives":    "c-9lives",
+	} {
+		if got := SuggestContainerName(name); got != want {
+			t.Errorf("%q: expected %q, got %q", name, want, got)
+		}
+		if want != "" && ValidateContainerName(want) != nil {
+			t.Errorf("%q: suggestion %q is not valid", name, want)
+		}
+	}
+}
+
+func TestGeneratePetName(t *testing.T) {
+	name, err := GeneratePetName(func(string) bool { return false })
+	if err != nil || !regexp.MustCompile(`^[a-z]+-[a-z]+$`).MatchString(name) {
+		t.Errorf("unexpected name %q (%v)", name, err)
+	}
+	if err := ValidateContainerName(name); err != nil {
+		t.Errorf("generated name is invalid: %v", err)
+	}
+
+	// Bare names are all taken, so a number is appended
+	name, err = GeneratePetName(func(name string) bool { return !regexp.MustCompile(`-\d+$`).MatchString(name) })
+	if err != nil || !regexp.MustCompile(`^[a-z]+-[a-z]+-\d+$`).MatchString(name) {
+		t.Errorf("expected a numbered name, got %q (%v)", name, err)
+	}
+
+	checks := 0
+	if _, err := GeneratePetName(func(string) bool { checks++; return true }); err == nil || checks != petNameAttempts {
+		t.Errorf("expected an error after %d attempts, got %v after %d", petNameAttempts, err, checks)
+	}
+}
+
+func TestMatchContainerName(t *testing.T) {
+	names := []string{"web-api", "db", "Cache", "worker_1", "worker-1"}
+	tests := []struct {
+		name  string
+		want  string
+		match bool
+	}{
+		{"db", "db", true},
+		{"webapi", "web-api", true},
+		{"WEB_API", "web-api", true},
+		{"cache", "Cache", true},
+		{"worker-1", "worker-1", true},
+		{"worker1", "", false},
+		{"web", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := MatchContainerName(tt.name, names)
+		if got != tt.want || ok != tt.match {
+			t.Errorf("MatchContainerName(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.match)
+		}
+	}
+}
+
+func TestSimilarContainerNames(t *testing.T) {
+	names := []string{"web-api", "web-app", "db", "postgres", "worker", "web-frontend"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"webapi", []string{"web-api", "web-app"}},
+		{"postgers", []string{"postgres"}},
+		{"dv", []string{"db"}},
+		{"web", []string{"web-api", "web-app", "web-frontend"}},
+		{"mysql", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := SimilarContainerNames(tt.name, names)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SimilarContainerNames(%q) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"web", "", 3},
+		{"kitten", "sitting", 3},
+		{"webapi", "webapp", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Container-to-container policy lives in its own nftables table so it never
+// touches rules managed by LXD or the host firewall
+const (
+	PolicyTableFamily = "bridge"
+	PolicyTableName   = "lxc_go_cli"
+	PolicyChainName   = "forward"
+
+	// PolicyAnyContainer matches traffic from or to any container
+	PolicyAnyContainer = "any"
+
+	policyCommentPrefix = "lxc-go-cli"
+)
+
+// NetPolicyRule allows or denies traffic between two containers
+type NetPolicyRule struct {
+	Action      string
+	Source      string
+	Destination string
+	Port        int
+	Protocol    string
+	Handle      int
+}
+
+// ValidateNetPolicyRule checks a rule before it is applied
+func ValidateNetPolicyRule(rule NetPolicyRule) error {
+	if rule.Action != "allow" && rule.Action != "deny" {
+		return UsageErrorf("invalid action '%s': must be 'allow' or 'deny'", rule.Action)
+	}
+	if rule.Source == "" || rule.Destination == "" {
+		return fmt.Errorf("source and destination containers are required")
+	}
+	if rule.Source == PolicyAnyContainer && rule.Destination == PolicyAnyContainer {
+		return fmt.Errorf("source and destination cannot both be '%s'", PolicyAnyContainer)
+	}
+	if rule.Source == rule.Destination {
+		return fmt.Errorf("source and destination must differ")
+	}
+	if rule.Port < 0 || rule.Port > 65535 {
+		return UsageErrorf("invalid port %d: must be between 1 and 65535", rule.Port)
+	}
+	if rule.Port > 0 && rule.Protocol != "tcp" && rule.Protocol != "udp" {
+		return UsageErrorf("invalid protocol '%s': must be 'tcp' or 'udp'", rule.Protocol)
+	}
+	return nil
+}
+
+// Comment returns the nftables comment identifying the rule
+func (r NetPolicyRule) Comment() string {
+	port := "all"
+	if r.Port > 0 {
+		port = fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%s", policyCommentPrefix, r.Action, r.Source, r.Destination, port)
+}
+
+// Matches reports whether two rules describe the same traffic and action
+func (r NetPolicyRule) Matches(other NetPolicyRule) bool {
+	return r.Comment() == other.Comment()
+}
+
+// String renders the rule for display
+func (r NetPolicyRule) String() string {
+	port := "all ports"
+	if r.Port > 0 {
+		port = fmt.Sprintf("%s/%d", r.Protocol, r.Port)
+	}
+	return fmt.Sprintf("%s %s -> %s (%s)", r.Action, r.Source, r.Destination, port)
+}
+
+// parsePolicyComment turns a rule comment back into a rule
+func parsePolicyComment(comment string) (NetPolicyRule, bool) {
+	parts := strings.Split(comment, ":")
+	if len(parts) != 5 || parts[0] != policyCommentPrefix {
+		return NetPolicyRule{}, false
+	}
+
+	rule := NetPolicyRule{Action: parts[1], Source: parts[2], Destination: parts[3]}
+	if parts[4] != "all" {
+		portProto := strings.SplitN(parts[4], "/", 2)
+		if len(portProto) != 2 {
+			return NetPolicyRule{}, false
+		}
+		port, err := strconv.Atoi(portProto[0])
+		if err != nil {
+			return NetPolicyRule{}, false
+		}
+		rule.Port = port
+		rule.Protocol = portProto[1]
+	}
+	return rule, true
+}
+
+// BuildPolicyRuleExpression returns the nftables rule expression for a rule.
+// Containers are matched by MAC address, which survives DHCP address changes.
+func BuildPolicyRuleExpression(rule NetPolicyRule, sourceMAC, destinationMAC string) []string {
+	var expr []string
+	if sourceMAC != "" {
+		expr = append(expr, "ether", "saddr", sourceMAC)
+	}
+	if destinationMAC != "" {
+		expr = append(expr, "ether", "daddr", destinationMAC)
+	}
+	if rule.Port > 0 {
+		expr = append(expr, rule.Protocol, "dport", strconv.Itoa(rule.Port))
+	}
+
+	verdict := "accept"
+	if rule.Action == "deny" {
+		verdict = "drop"
+	}
+	expr = append(expr, verdict, "comment", fmt.Sprintf("%q", rule.Comment()))
+	return expr
+}
+
+// EnsurePolicyTable creates the policy table and chain if they do not exist
+func EnsurePolicyTable(ctx context.Context) error {
+	if err := runNft(ctx, "add", "table", PolicyTableFamily, PolicyTableName); err != nil {
+		return err
+	}
+	return runNft(ctx, "add", "chain", PolicyTableFamily, PolicyTableName, PolicyChainName,
+		"{ type filter hook forward priority 0 ; policy accept ; }")
+}
+
+// AddPolicyRule installs a rule. Allow rules are inserted at the top of the chain
+// and deny rules appended, so an allow always takes precedence over a deny.
+func AddPolicyRule(ctx context.Context, rule NetPolicyRule, sourceMAC, destinationMAC string) error {
+	verb := "add"
+	if rule.Action == "allow" {
+		verb = "insert"
+	}
+	args := append([]string{verb, "rule", PolicyTableFamily, PolicyTableName, PolicyChainName},
+		BuildPolicyRuleExpression(rule, sourceMAC, destinationMAC)...)
+	return runNft(ctx, args...)
+}
+
+// ListPolicyRules returns the rules installed by this tool
+func ListPolicyRules(ctx context.Context) ([]NetPolicyRule, error) {
+	if err := RequireLocalServer(ctx, "Network policy"); err != nil {
+		return nil, err
+	}
+	argv, err := privilegedCommand(ctx, "nft", "-a", "list", "chain", PolicyTableFamily, PolicyTableName, PolicyChainName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+	if err != nil {
+		// No table yet simply means no rules
+		if strings.Contains(string(output), "No such file or directory") {
+			return nil, nil
+		}
+		logger.Debug("nft list failed with output: %s", string(output))
+		return nil, withPrivilegeHint(fmt.Errorf("failed to list network policy rules: %w (output: %s)", err, strings.TrimSpace(string(output))), string(output))
+	}
+	return parsePolicyRules(string(output)), nil
+}
+
+// DeletePolicyRule removes a rule by its nftables handle
+func DeletePolicyRule(ctx context.Context, handle int) error {
+	return runNft(ctx, "delete", "rule", PolicyTableFamily, PolicyTableName, PolicyChainName, "handle", strconv.Itoa(handle))
+}
+
+var policyRulePattern = regexp.MustCompile(`comment "([^"]+)".*# handle (\d+)`)
+
+// parsePolicyRules extracts tool-managed rules from `nft -a list chain` output
+func parsePolicyRules(output string) []NetPolicyRule {
+	var rules []NetPolicyRule
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		matches := policyRulePattern.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+		rule, ok := parsePolicyComment(matches[1])
+		if !ok {
+			continue
+		}
+		rule.Handle, _ = strconv.Atoi(matches[2])
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GetContainerMAC returns the MAC address of a container's eth0 interface
+func GetContainerMAC(containerName string) (string, error) {
+	mac, err := GetConfigValue(containerName, "volatile.eth0.hwaddr")
+	if err != nil {
+		return "", err
+	}
+	mac = strings.TrimSpace(mac)
+	if mac == "" {
+		return "", fmt.Errorf("container '%s' has no eth0 MAC address", containerName)
+	}
+	return mac, nil
+}
+
+// FormatPolicyRules renders policy rules in evaluation order
+func FormatPolicyRules(rules []NetPolicyRule) string {
+	if len(rules) == 0 {
+		return "No network policy rules found\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("ACTION  SOURCE               DESTINATION          PORT\n")
+	for _, rule := range rules {
+		port := "all"
+		if rule.Port > 0 {
+			port = fmt.Sprintf("%d/%s", rule.Port, rule.Protocol)
+		}
+		sb.WriteString(fmt.Sprintf("%-7s %-20s %-20s %s\n", rule.Action, rule.Source, rule.Destination, port))
+	}
+	return sb.String()
+}
+
+func runNft(ctx context.Context, args ...string) error {
+	if err := RequireLocalServer(ctx, "Network policy"); err != nil {
+		return err
+	}
+	logger.Debug("Running: nft %s", strings.Join(args, " "))
+
+	argv, err := privilegedCommand(ctx, append([]string{"nft"}, args...)...)
+	if err != nil {
+		return err
+	}
+	output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+	if err != nil {
+		logger.Debug("nft failed with output: %s", string(output))
+		return withPrivilegeHint(fmt.Errorf("nft %s failed: %w (output: %s)", args[0], err, strings.TrimSpace(string(output))), string(output))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNetPolicyRule(t *testing.T) {
+	valid := []NetPolicyRule{
+		{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp"},
+		{Action: "deny", Source: "any", Destination: "db"},
+		{Action: "allow", Source: "dns", Destination: "any", Port: 53, Protocol: "udp"},
+	}
+	for _, rule := range valid {
+		if err := ValidateNetPolicyRule(rule); err != nil {
+			t.Errorf("expected %v to be valid: %v", rule, err)
+		}
+	}
+
+	invalid := []NetPolicyRule{
+		{Action: "reject", Source: "app", Destination: "db"},
+		{Action: "allow", Source: "", Destination: "db"},
+		{Action: "deny", Source: "any", Destination: "any"},
+		{Action: "deny", Source: "db", Destination: "db"},
+		{Action: "allow", Source: "app", Destination: "db", Port: 70000, Protocol: "tcp"},
+		{Action: "allow", Source: "app", Destination: "db", Port: 80, Protocol: "icmp"},
+	}
+	for _, rule := range invalid {
+		if err := ValidateNetPolicyRule(rule); err == nil {
+			t.Errorf("expected %v to be invalid", rule)
+		}
+	}
+}
+
+func TestPolicyCommentRoundTrip(t *testing.T) {
+	rules := []NetPolicyRule{
+		{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp"},
+		{Action: "deny", Source: "any", Destination: "db"},
+	}
+	for _, rule := range rules {
+		parsed, ok := parsePolicyComment(rule.Comment())
+		if !ok {
+			t.Fatalf("failed to parse comment '%s'", rule.Comment())
+		}
+		if parsed != rule {
+			t.Errorf("expected %+v, got %+v", rule, parsed)
+		}
+	}
+
+	for _, comment := range []string{"other:allow:a:b:all", "lxc-go-cli:allow:a:b", "lxc-go-cli:allow:a:b:x/tcp"} {
+		if _, ok := parsePolicyComment(comment); ok {
+			t.Errorf("expected comment '%s' to be rejected", comment)
+		}
+	}
+}
+
+func TestBuildPolicyRuleExpression(t *testing.T) {
+	rule := NetPolicyRule{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp"}
+	expr := strings.Join(BuildPolicyRuleExpression(rule, "00:16:3e:00:00:01", "00:16:3e:00:00:02"), " ")
+	expected := `ether saddr 00:16:3e:00:00:01 ether daddr 00:16:3e:00:00:02 tcp dport 5432 accept comment "lxc-go-cli:allow:app:db:5432/tcp"`
+	if expr != expected {
+		t.Errorf("expected '%s', got '%s'", expected, expr)
+	}
+
+	deny := NetPolicyRule{Action: "deny", Source: "any", Destination: "db"}
+	expr = strings.Join(BuildPolicyRuleExpression(deny, "", "00:16:3e:00:00:02"), " ")
+	expected = `ether daddr 00:16:3e:00:00:02 drop comment "lxc-go-cli:deny:any:db:all"`
+	if expr != expected {
+		t.Errorf("expected '%s', got '%s'", expected, expr)
+	}
+}
+
+func TestParsePolicyRules(t *testing.T) {
+	output := `table bridge lxc_go_cli {
+	chain forward { # handle 1
+		type filter hook forward priority filter; policy accept;
+		ether saddr 00:16:3e:00:00:01 ether daddr 00:16:3e:00:00:02 tcp dport 5432 accept comment "lxc-go-cli:allow:app:db:5432/tcp" # handle 4
+		ether daddr 00:16:3e:00:00:02 drop comment "lxc-go-cli:deny:any:db:all" # handle 3
+		ether daddr 00:16:3e:00:00:09 drop comment "added by hand" # handle 5
+	}
+}
+`
+	rules := parsePolicyRules(output)
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d: %+v", len(rules), rules)
+	}
+	if rules[0].Action != "allow" || rules[0].Port != 5432 || rules[0].Handle != 4 {
+		t.Errorf("unexpected first rule: %+v", rules[0])
+	}
+	if rules[1].Action != "deny" || rules[1].Source != "any" || rules[1].Handle != 3 {
+		t.Errorf("unexpected second rule: %+v", rules[1])
+	}
+}
+
+func TestFormatPolicyRules(t *testing.T) {
+	if output := FormatPolicyRules(nil); !strings.Contains(output, "No network policy rules") {
+		t.Errorf("unexpected output for no rules: %s", output)
+	}
+
+	output := FormatPolicyRules([]NetPolicyRule{
+		{Action: "allow", Source: "app", Destination: "db", Port: 5432, Protocol: "tcp"},
+		{Action: "deny", Source: "any", Destination: "db"},
+	})
+	for _, expected := range []string{"ACTION", "5432/tcp", "deny", "all"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, output)
+		}
+	}
+}
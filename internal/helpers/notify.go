@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Lifecycle events that can trigger notifications
+const (
+	EventCreateComplete  = "create-complete"
+	EventProvisionFailed = "provision-failed"
+	EventWatchRestart    = "watch-restart"
+	EventWatchGiveUp     = "watch-give-up"
+	EventUpdate          = "update"
+)
+
+// NotificationEvents lists every event a notification can subscribe to
+var NotificationEvents = []string{EventCreateComplete, EventProvisionFailed, EventWatchRestart, EventWatchGiveUp, EventUpdate}
+
+// Notification channel types
+const (
+	NotifyWebhook = "webhook"
+	NotifySlack   = "slack"
+	NotifyEmail   = "email"
+)
+
+// DefaultNotificationTemplate is the message used when a notification has no template
+const DefaultNotificationTemplate = "[{{.Host}}] {{.Message}}{{if .Error}}: {{.Error}}{{end}}"
+
+// notifyTimeout bounds each notification so a slow endpoint cannot hold up a command
+const notifyTimeout = 10 * time.Second
+
+// httpClient sends webhook and Slack notifications
+var httpClient = &http.Client{Timeout: notifyTimeout}
+
+// Notification is a channel in the config file that lifecycle events are sent to
+type Notification struct {
+	// Type is webhook, slack or email
+	Type string `yaml:"type"`
+	// URL receives a POST for webhook and slack notifications
+	URL string `yaml:"url,omitempty"`
+	// To is the recipient of email notifications, sent with sendmail
+	To string `yaml:"to,omitempty"`
+	// Events limits the notification to these events; empty means all of them
+	Events []string `yaml:"events,omitempty"`
+	// Template is a Go text/template for the message, rendered with a NotificationEvent
+	Template string `yaml:"template,omitempty"`
+}
+
+// NotificationEvent is what happened; its fields can be used in templates
+type NotificationEvent struct {
+	Event     string    `json:"event"`
+	Container string    `json:"container"`
+	Message   string    `json:"message"`
+	Error     string    `json:"error,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	Host      string    `json:"host"`
+	Time      time.Time `json:"time"`
+}
+
+// Validate checks a notification's type, destination, events and template
+func (n Notification) Validate() error {
+	switch n.Type {
+	case NotifyWebhook, NotifySlack:
+		if !strings.HasPrefix(n.URL, "http://") && !strings.HasPrefix(n.URL, "https://") {
+			return fmt.Errorf("%s notification needs an http(s) url", n.Type)
+		}
+	case NotifyEmail:
+		if n.To == "" || strings.ContainsAny(n.To, "\r\n") {
+			return fmt.Errorf("email notification needs a 'to' address")
+		}
+	default:
+		return UsageErrorf("invalid notification type '%s': must be webhook, slack or email", n.Type)
+	}
+	for _, event := range n.Events {
+		if !slices.Contains(NotificationEvents, event) {
+			return fmt.Errorf("unknown notification event '%s': must be one of %s", event, strings.Join(NotificationEvents, ", "))
+		}
+	}
+	if _, err := n.template(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Wants reports whether the notification subscribes to an event
+func (n Notification) Wants(event string) bool {
+	return len(n.Events) == 0 || slices.Contains(n.Events, event)
+}
+
+// Render fills in the notification's template for an event
+func (n Notification) Render(event NotificationEvent) (string, error) {
+	tmpl, err := n.template()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("failed to render notification template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (n Notification) template() (*template.Template, error) {
+	text := n.Template
+	if text == "" {
+		text = DefaultNotificationTemplate
+	}
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Notify sends an event to every configured notification that wants it.
+// Failures are logged rather than returned: a notification must never fail
+// the command that triggered it.
+func Notify(ctx context.Context, event NotificationEvent) {
+	settings, err := LoadSettings()
+	if err != nil {
+		logger.Warn("Notifications skipped: %v", err)
+		return
+	}
+	if len(settings.Notifications) == 0 {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Host == "" {
+		event.Host, _ = os.Hostname()
+	}
+	for i, notification := range settings.Notifications {
+		if !notification.Wants(event.Event) {
+			continue
+		}
+		if err := SendNotification(ctx, notification, event); err != nil {
+			logger.Warn("Notification %d (%s) failed: %v", i+1, notification.Type, err)
+		}
+	}
+}
+
+// SendNotification delivers one event to one notification channel
+func SendNotification(ctx context.Context, n Notification, event NotificationEvent) error {
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	message, err := n.Render(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	switch n.Type {
+	case NotifySlack:
+		return postJSON(ctx, n.URL, map[string]string{"text": message})
+	case NotifyWebhook:
+		return postJSON(ctx, n.URL, struct {
+			NotificationEvent
+			Text string `json:"text"`
+		}{event, message})
+	default:
+		return sendMail(ctx, n.To, event, message)
+	}
+}
+
+// postJSON posts a JSON body and treats any non-2xx response as a failure
+func postJSON(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendMail pipes a message to 'sendmail -t', which reads the recipient from
+// the headers
+func sendMail(ctx context.Context, to string, event NotificationEvent, message string) error {
+	subject := fmt.Sprintf("lxc-go-cli: %s", event.Event)
+	if event.Container != "" {
+		subject += " " + event.Container
+	}
+	mail := fmt.Sprintf("To: %s\nSubject: %s\nContent-Type: text/plain; charset=utf-8\n\n%s\n", to, subject, message)
+
+	var stderr bytes.Buffer
+	if err := Runner().RunStreaming(ctx, Streams{Stdin: strings.NewReader(mail), Stderr: &stderr}, "sendmail", "-t"); err != nil {
+		return fmt.Errorf("sendmail failed: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestNotificationValidate(t *testing.T) {
+	valid := []Notification{
+		{Type: NotifySlack, URL: "https://hooks.slack.com/services/x"},
+		{Type: NotifyWebhook, URL: "http://ops.example.com/hook", Events: []string{EventUpdate}},
+		{Type: NotifyEmail, To: "ops@example.com", Template: "{{.Container}}: {{.Message}}"},
+	}
+	for _, n := range valid {
+		if err := n.Validate(); err != nil {
+			t.Errorf("%+v: expected no error, got %v", n, err)
+		}
+	}
+
+	tests := []struct {
+		notification Notification
+		want         string
+	}{
+		{Notification{Type: "pager"}, "invalid notification type 'pager'"},
+		{Notification{Type: NotifySlack, URL: "hooks.slack.com"}, "needs an http(s) url"},
+		{Notification{Type: NotifyEmail, To: "ops@example.com\nBcc: x@example.com"}, "needs a 'to' address"},
+		{Notification{Type: NotifyWebhook, URL: "https://x", Events: []string{"deleted"}}, "unknown notification event 'deleted'"},
+		{Notification{Type: NotifyWebhook, URL: "https://x", Template: "{{.Message"}, "invalid notification template"},
+	}
+	for _, tt := range tests {
+		if err := tt.notification.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: expected %q, got %v", tt.notification, tt.want, err)
+		}
+	}
+}
+
+func TestNotificationRender(t *testing.T) {
+	event := NotificationEvent{Event: EventProvisionFailed, Container: "web", Message: "Provisioning container 'web' failed", Error: "step docker-install timed out", Host: "lab"}
+
+	message, err := Notification{}.Render(event)
+	if err != nil || message != "[lab] Provisioning container 'web' failed: step docker-install timed out" {
+		t.Errorf("unexpected default message %q (%v)", message, err)
+	}
+	message, err = Notification{Template: "{{.Event}} on {{.Container}}"}.Render(event)
+	if err != nil || message != "provision-failed on web" {
+		t.Errorf("unexpected custom message %q (%v)", message, err)
+	}
+	if _, err := (Notification{Template: "{{.Missing}}"}).Render(event); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestSendNotificationHTTP(t *testing.T) {
+	var bodies []map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	event := NotificationEvent{Event: EventUpdate, Container: "web", Message: "Container 'web' updated", Host: "lab", Time: time.Now()}
+	ctx := context.Background()
+
+	if err := SendNotification(ctx, Notification{Type: NotifySlack, URL: server.URL}, event); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(bodies[0]) != 1 || bodies[0]["text"] != "[lab] Container 'web' updated" {
+		t.Errorf("expected a Slack text payload, got %v", bodies[0])
+	}
+
+	if err := SendNotification(ctx, Notification{Type: NotifyWebhook, URL: server.URL}, event); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bodies[1]["event"] != EventUpdate || bodies[1]["container"] != "web" || bodies[1]["text"] != "[lab] Container 'web' updated" {
+		t.Errorf("expected the event and text in the webhook payload, got %v", bodies[1])
+	}
+
+	status = http.StatusInternalServerError
+	if err := SendNotification(ctx, Notification{Type: NotifyWebhook, URL: server.URL}, event); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected the status in the error, got %v", err)
+	}
+}
+
+func TestSendNotificationEmail(t *testing.T) {
+	mock := useMockRunner(t)
+	event := NotificationEvent{Event: EventWatchRestart, Container: "web", Message: "Watchdog restarted container 'web'", Host: "lab"}
+
+	if err := SendNotification(context.Background(), Notification{Type: NotifyEmail, To: "ops@example.com"}, event); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !mock.Ran("sendmail", "-t") || len(mock.Stdins) != 1 {
+		t.Fatalf("expected the mail to be piped to sendmail, got %v", mock.Commands)
+	}
+	data, _ := io.ReadAll(mock.Stdins[0])
+	if !strings.HasPrefix(string(data), "To: ops@example.com\nSubject: lxc-go-cli: watch-restart web\n") || !strings.Contains(string(data), "\n\n[lab] Watchdog restarted container 'web'\n") {
+		t.Errorf("unexpected mail:\n%s", data)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	originalDir := SettingsDir
+	SettingsDir = t.TempDir()
+	t.Cleanup(func() { SettingsDir = originalDir })
+
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body["text"])
+	}))
+	defer server.Close()
+
+	settings := Settings{Notifications: []Notification{
+		{Type: NotifySlack, URL: server.URL, Events: []string{EventProvisionFailed}, Template: "failed: {{.Container}}"},
+		{Type: NotifySlack, URL: server.URL, Template: "any: {{.Container}}"},
+	}}
+	data, _ := yaml.Marshal(settings)
+	if err := os.WriteFile(SettingsPath(), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Notify(context.Background(), NotificationEvent{Event: EventCreateComplete, Container: "web"})
+	Notify(context.Background(), NotificationEvent{Event: EventProvisionFailed, Container: "db"})
+	if strings.Join(texts, ",") != "any: web,failed: db,any: db" {
+		t.Errorf("expected each notification to get the events it wants, got %v", texts)
+	}
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+const (
+	// outputTailLines is how many lines of command output are kept for error messages
+	outputTailLines = 20
+	// maxOutputLineLength bounds a single buffered line, e.g. progress bars without newlines
+	maxOutputLineLength = 4096
+)
+
+// quietOutput sends command output to the debug log instead of the info log
+var quietOutput bool
+
+// SetQuietOutput controls whether output of commands run by this tool is shown.
+// Quiet output is still logged at debug level and included in error messages.
+func SetQuietOutput(quiet bool) {
+	quietOutput = quiet
+}
+
+// OutputLogger is an io.Writer that logs command output line by line as it
+// arrives, keeping only the last few lines in memory for error messages.
+// It is not safe for concurrent use; exec.Cmd serialises writes when the same
+// OutputLogger is used for Stdout and Stderr.
+type OutputLogger struct {
+	prefix  string
+	partial bytes.Buffer
+	tail    []string
+}
+
+// NewOutputLogger creates an OutputLogger that prefixes each line, e.g. "[mycontainer] "
+func NewOutputLogger(prefix string) *OutputLogger {
+	return &OutputLogger{prefix: prefix}
+}
+
+// Write logs every complete line and buffers the rest
+func (o *OutputLogger) Write(data []byte) (int, error) {
+	for _, b := range data {
+		if b == '\n' {
+			o.emit()
+			continue
+		}
+		o.partial.WriteByte(b)
+		if o.partial.Len() >= maxOutputLineLength {
+			o.emit()
+		}
+	}
+	return len(data), nil
+}
+
+// Flush logs any buffered partial line
+func (o *OutputLogger) Flush() {
+	if o.partial.Len() > 0 {
+		o.emit()
+	}
+}
+
+// Tail returns the last lines of output
+func (o *OutputLogger) Tail() string {
+	return strings.Join(o.tail, "\n")
+}
+
+func (o *OutputLogger) emit() {
+	line := strings.TrimRight(o.partial.String(), "\r")
+	o.partial.Reset()
+
+	// apt and docker redraw progress lines with carriage returns; keep the last state
+	if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+		line = line[idx+1:]
+	}
+
+	if quietOutput {
+		logger.Debug("%s%s", o.prefix, line)
+	} else {
+		logger.Info("%s%s", o.prefix, line)
+	}
+
+	o.tail = append(o.tail, line)
+	if len(o.tail) > outputTailLines {
+		o.tail = o.tail[len(o.tail)-outputTailLines:]
+	}
+}
+
+// runStreamed runs a command, streaming its output through an OutputLogger
+func runStreamed(ctx context.Context, prefix string, args ...string) error {
+	out := NewOutputLogger(prefix)
+	err := Runner().RunStreaming(ctx, Streams{Stdout: out, Stderr: out}, args[0], args[1:]...)
+	out.Flush()
+	if err != nil {
+		return fmt.Errorf("command failed: %w (output: %s)", err, out.Tail())
+	}
+	return nil
+}
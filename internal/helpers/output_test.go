@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOutputLogger(t *testing.T) {
+	out := NewOutputLogger("[test] ")
+	fmt.Fprint(out, "first\nsec")
+	fmt.Fprint(out, "ond\r\nprogress 10%\rprogress 100%\npartial")
+	out.Flush()
+
+	expected := "first\nsecond\nprogress 100%\npartial"
+	if out.Tail() != expected {
+		t.Errorf("expected tail %q, got %q", expected, out.Tail())
+	}
+}
+
+func TestOutputLoggerBoundedMemory(t *testing.T) {
+	out := NewOutputLogger("")
+	for i := 0; i < 3*outputTailLines; i++ {
+		fmt.Fprintf(out, "line %d\n", i)
+	}
+	lines := strings.Split(out.Tail(), "\n")
+	if len(lines) != outputTailLines || lines[len(lines)-1] != fmt.Sprintf("line %d", 3*outputTailLines-1) {
+		t.Errorf("expected the last %d lines, got %v", outputTailLines, lines)
+	}
+
+	out = NewOutputLogger("")
+	fmt.Fprint(out, strings.Repeat("x", maxOutputLineLength+10))
+	if out.partial.Len() > maxOutputLineLength {
+		t.Errorf("partial line should be bounded, got %d bytes", out.partial.Len())
+	}
+}
+
+func TestRunStreamedIncludesOutputTail(t *testing.T) {
+	SetQuietOutput(true)
+	defer SetQuietOutput(false)
+
+	err := runStreamed(context.Background(), "[host] ", "sh", "-c", "echo building; echo 'E: broken' >&2; exit 2")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "building\nE: broken") {
+		t.Errorf("expected error to include the output tail, got %v", err)
+	}
+
+	if err := runStreamed(context.Background(), "[host] ", "true"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
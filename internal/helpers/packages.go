@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// packagePattern matches a Debian package name with an optional
+// architecture and version, e.g. git, libssl3:amd64 or htop=3.3.0-4
+var packagePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+(:[a-z0-9-]+)?(=[A-Za-z0-9.+:~-]+)?$`)
+
+// ValidatePackages checks that every entry is a valid package name
+func ValidatePackages(packages []string) error {
+	for _, name := range packages {
+		if !packagePattern.MatchString(name) {
+			return UsageErrorf("invalid package name '%s'", name)
+		}
+	}
+	return nil
+}
+
+// LoadPackagesFile reads package names from a file, one or more per line;
+// blank lines and text after # are ignored
+func LoadPackagesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages file: %w", err)
+	}
+
+	var packages []string
+	for i, line := range strings.Split(string(data), "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		for _, name := range strings.Fields(line) {
+			if err := ValidatePackages([]string{name}); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+			}
+			packages = append(packages, name)
+		}
+	}
+	return packages, nil
+}
+
+// PackageInstallCommand returns the command that installs the packages in
+// one apt transaction
+func PackageInstallCommand(packages []string) []string {
+	return append([]string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y"}, packages...)
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePackages(t *testing.T) {
+	if err := ValidatePackages([]string{"git", "g++", "libssl3:amd64", "htop=3.3.0-4", "python3.12"}); err != nil {
+		t.Errorf("expected valid packages, got %v", err)
+	}
+	for _, name := range []string{"", "Git", "-y", "git;reboot", "a"} {
+		if err := ValidatePackages([]string{name}); err == nil {
+			t.Errorf("ValidatePackages(%q) should fail", name)
+		}
+	}
+}
+
+func TestLoadPackagesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packages.txt")
+	if err := os.WriteFile(path, []byte("git htop\n# editors\nvim  # the only one\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	packages, err := LoadPackagesFile(path)
+	if err != nil || strings.Join(packages, " ") != "git htop vim" {
+		t.Errorf("unexpected packages %v, %v", packages, err)
+	}
+
+	if err := os.WriteFile(path, []byte("git\n--allow-unauthenticated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPackagesFile(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("expected error with line number, got %v", err)
+	}
+}
@@ -1,41 +1,43 @@
 package helpers
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/deji/lxc-go-cli/internal/logger"
 )
 
-// GenerateSecurePassword creates a 16-character password with guaranteed character distribution
-func GenerateSecurePassword() string {
-	// Guarantee at least 2 of each character type for better security
-	uppercase := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	lowercase := "abcdefghijklmnopqrstuvwxyz"
-	numbers := "0123456789"
-	allChars := uppercase + lowercase + numbers
-
-	var result []byte
-
-	// Add 2 guaranteed uppercase letters
-	result = append(result, getRandomChar(uppercase), getRandomChar(uppercase))
-	// Add 2 guaranteed lowercase letters
-	result = append(result, getRandomChar(lowercase), getRandomChar(lowercase))
-	// Add 2 guaranteed numbers
-	result = append(result, getRandomChar(numbers), getRandomChar(numbers))
+// AppPasswordEnvVar supplies the 'app' user password to create instead of
+// generating one, e.g. from a central vault
+const AppPasswordEnvVar = "LXC_GO_CLI_APP_PASSWORD"
 
-	// Fill remaining 10 positions randomly from all characters
-	for i := 0; i < 10; i++ {
-		result = append(result, getRandomChar(allChars))
+// ValidateSuppliedPassword checks a password given by the user rather than
+// generated, which chpasswd takes on a single line
+func ValidateSuppliedPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password is empty")
 	}
+	if strings.ContainsAny(password, "\r\n") {
+		return fmt.Errorf("password must not contain a newline")
+	}
+	return nil
+}
 
-	// Shuffle the entire result to avoid predictable patterns
-	shuffleBytes(result)
-
-	return string(result)
+// GenerateSecurePassword creates a password following the active policy; by
+// default 16 letters and digits with at least two of each character class
+func GenerateSecurePassword() string {
+	password, bits, err := GeneratePassword(activePasswordPolicy)
+	if err != nil {
+		// A configured wordlist may have become unreadable; fall back to the default
+		logger.Warn("Failed to apply password policy (%v); using the default policy", err)
+		password, bits, _ = GeneratePassword(PasswordPolicy{})
+	}
+	warnIfWeak(bits)
+	return password
 }
 
 // generateRandomString creates a random string of specified length using given character set
@@ -73,7 +75,8 @@ func generateRandomString(length int, charset string) string {
 	return string(result)
 }
 
-// StoreContainerPassword stores password in LXC metadata with base64 encoding
+// StoreContainerPassword stores a container's password in the active
+// password store, the container metadata unless Vault is configured
 func StoreContainerPassword(containerName, password string) error {
 	if containerName == "" {
 		return fmt.Errorf("container name is required")
@@ -82,53 +85,33 @@ func StoreContainerPassword(containerName, password string) error {
 		return fmt.Errorf("password is required")
 	}
 
-	logger.Debug("Storing password for container '%s'", containerName)
-
-	// Encode password with base64 for basic obfuscation
-	encoded := base64.StdEncoding.EncodeToString([]byte(password))
-
-	// Store in LXC metadata using user.app-password key
-	cmd := exec.Command("lxc", "config", "set", containerName, "user.app-password", encoded)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Debug("Failed to store password: %s", string(output))
-		return fmt.Errorf("failed to store password in container metadata: %w (output: %s)", err, string(output))
-	}
-
-	logger.Debug("Password stored successfully in container metadata")
-	return nil
+	logger.Debug("Storing password for container '%s' in the %s store", containerName, activePasswordStore.Name())
+	return activePasswordStore.Store(context.Background(), containerName, password)
 }
 
-// GetContainerPassword retrieves password from LXC metadata
+// GetContainerPassword retrieves a container's password from the active password store
 func GetContainerPassword(containerName string) (string, error) {
 	if containerName == "" {
 		return "", fmt.Errorf("container name is required")
 	}
 
-	logger.Debug("Retrieving password for container '%s'", containerName)
-
-	// Get password from LXC metadata
-	cmd := exec.Command("lxc", "config", "get", containerName, "user.app-password")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Debug("Failed to retrieve password: %s", string(output))
-		return "", fmt.Errorf("failed to retrieve password from container metadata: %w (output: %s)", err, string(output))
-	}
-
-	encoded := strings.TrimSpace(string(output))
-	if encoded == "" {
-		return "", fmt.Errorf("no password found for container '%s' (container may not have been created with this tool)", containerName)
-	}
-
-	// Decode from base64
-	decoded, err := base64.StdEncoding.DecodeString(encoded)
-	if err != nil {
-		logger.Debug("Failed to decode password: %v", err)
-		return "", fmt.Errorf("failed to decode stored password: %w", err)
+	logger.Debug("Retrieving password for container '%s' from the %s store", containerName, activePasswordStore.Name())
+	password, err := activePasswordStore.Get(context.Background(), containerName)
+	if errors.Is(err, ErrNotFound) && activePasswordStore.Name() != PasswordStoreMetadata {
+		// Containers created before the backend was switched keep theirs in metadata
+		if fallback, metadataErr := (MetadataPasswordStore{}).Get(context.Background(), containerName); metadataErr == nil {
+			logger.Debug("Password of '%s' found in container metadata instead of the %s store", containerName, activePasswordStore.Name())
+			return fallback, nil
+		}
 	}
+	return password, err
+}
 
-	logger.Debug("Password retrieved successfully")
-	return string(decoded), nil
+// DeleteContainerPassword forgets a deleted container's password in the
+// active password store
+func DeleteContainerPassword(ctx context.Context, containerName string) error {
+	logger.Debug("Deleting password for container '%s' from the %s store", containerName, activePasswordStore.Name())
+	return activePasswordStore.Delete(ctx, containerName)
 }
 
 // SetUserPassword sets the password for a user inside a container using chpasswd
@@ -143,54 +126,26 @@ func SetUserPassword(containerName, username, password string) error {
 		return fmt.Errorf("password is required")
 	}
 
-	logger.Debug("Setting password for user '%s' in container '%s'", username, containerName)
+	// chpasswd reads one "username:password" line per user
+	if strings.ContainsAny(password, "\r\n") {
+		return fmt.Errorf("password must not contain a newline")
+	}
 
-	// Use chpasswd to set the password securely
-	// Format: "username:password" | chpasswd
-	passwordInput := fmt.Sprintf("%s:%s", username, password)
-	cmd := exec.Command("lxc", "exec", containerName, "--", "bash", "-c", fmt.Sprintf("echo '%s' | chpasswd", passwordInput))
+	logger.Debug("Setting password for user '%s' in container '%s'", username, containerName)
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		logger.Debug("Failed to set user password: %s", string(output))
-		return fmt.Errorf("failed to set password for user '%s': %w (output: %s)", username, err, string(output))
+	// The password goes to chpasswd on stdin so that it never appears on a
+	// command line and needs no shell quoting
+	var output bytes.Buffer
+	streams := Streams{Stdin: strings.NewReader(username + ":" + password + "\n"), Stdout: &output, Stderr: &output}
+	if err := Runner().RunStreaming(context.Background(), streams, "lxc", "exec", containerName, "-T", "--", "chpasswd"); err != nil {
+		logger.Debug("Failed to set user password: %s", output.String())
+		return fmt.Errorf("failed to set password for user '%s': %w (output: %s)", username, err, strings.TrimSpace(output.String()))
 	}
 
 	logger.Debug("Password set successfully for user '%s'", username)
 	return nil
 }
 
-// getRandomChar securely selects a random character from the given charset
-func getRandomChar(charset string) byte {
-	if len(charset) == 0 {
-		return 'A' // Fallback
-	}
-
-	randomBytes := make([]byte, 1)
-	_, err := rand.Read(randomBytes)
-	if err != nil {
-		// Fallback to first character if crypto/rand fails
-		return charset[0]
-	}
-
-	return charset[int(randomBytes[0])%len(charset)]
-}
-
-// shuffleBytes performs Fisher-Yates shuffle on byte slice using crypto/rand
-func shuffleBytes(slice []byte) {
-	for i := len(slice) - 1; i > 0; i-- {
-		randomBytes := make([]byte, 1)
-		_, err := rand.Read(randomBytes)
-		if err != nil {
-			// Skip shuffle if crypto/rand fails (not critical)
-			return
-		}
-
-		j := int(randomBytes[0]) % (i + 1)
-		slice[i], slice[j] = slice[j], slice[i]
-	}
-}
-
 // ContainerHasPassword checks if a container has a stored password
 func ContainerHasPassword(containerName string) bool {
 	_, err := GetContainerPassword(containerName)
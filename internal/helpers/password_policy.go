@@ -0,0 +1,262 @@
+package helpers
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Character classes used for generated passwords. Symbols leave out quotes
+// and backslashes, which would need escaping in shells and chpasswd input.
+const (
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "!@#$%^&*-_=+?.,:;~"
+	// ambiguousChars are easily confused when read or typed from a screen
+	ambiguousChars = "0O1lI"
+)
+
+// Password policy bounds
+const (
+	DefaultPasswordLength = 16
+	minPasswordLength     = 8
+	maxPasswordLength     = 128
+	minPassphraseWords    = 4
+	maxPassphraseWords    = 32
+	// weakPasswordBits is the entropy below which a warning is logged
+	weakPasswordBits = 64
+)
+
+//go:embed wordlist.txt
+var builtinWordlist string
+
+// PasswordPolicy controls how 'app' user passwords are generated. It can be
+// set in the config file under "password" and overridden by flags.
+type PasswordPolicy struct {
+	// Length of character passwords; 0 means DefaultPasswordLength
+	Length int `yaml:"length,omitempty"`
+	// Symbols adds punctuation to character passwords
+	Symbols bool `yaml:"symbols,omitempty"`
+	// ExcludeAmbiguous leaves out characters such as 0/O and 1/l/I
+	ExcludeAmbiguous bool `yaml:"exclude_ambiguous,omitempty"`
+	// Words selects a diceware-style passphrase of this many words
+	Words int `yaml:"words,omitempty"`
+	// Separator joins passphrase words; empty means "-"
+	Separator string `yaml:"separator,omitempty"`
+	// Wordlist is a file with one word per line (diceware "11111 word" lines
+	// are accepted); empty means the built-in list
+	Wordlist string `yaml:"wordlist,omitempty"`
+}
+
+// activePasswordPolicy is used by GenerateSecurePassword
+var activePasswordPolicy PasswordPolicy
+
+// SetPasswordPolicy changes the policy used by GenerateSecurePassword
+func SetPasswordPolicy(policy PasswordPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	activePasswordPolicy = policy
+	return nil
+}
+
+// ActivePasswordPolicy returns the policy used by GenerateSecurePassword
+func ActivePasswordPolicy() PasswordPolicy {
+	return activePasswordPolicy
+}
+
+// Validate checks the policy bounds without reading the wordlist
+func (p PasswordPolicy) Validate() error {
+	if p.Words != 0 {
+		if p.Words < minPassphraseWords || p.Words > maxPassphraseWords {
+			return fmt.Errorf("passphrase word count must be between %d and %d, got %d", minPassphraseWords, maxPassphraseWords, p.Words)
+		}
+		if strings.ContainsAny(p.Separator, "'\"\\\n") {
+			return fmt.Errorf("passphrase separator must not contain quotes, backslashes or newlines")
+		}
+		return nil
+	}
+	if p.Length != 0 && (p.Length < minPasswordLength || p.Length > maxPasswordLength) {
+		return fmt.Errorf("password length must be between %d and %d, got %d", minPasswordLength, maxPasswordLength, p.Length)
+	}
+	return nil
+}
+
+// Describe summarises the policy, e.g. "16 characters, symbols"
+func (p PasswordPolicy) Describe() string {
+	if p.Words != 0 {
+		source := "built-in wordlist"
+		if p.Wordlist != "" {
+			source = p.Wordlist
+		}
+		return fmt.Sprintf("%d-word passphrase from %s", p.Words, source)
+	}
+	length := p.Length
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+	parts := []string{fmt.Sprintf("%d characters", length)}
+	if p.Symbols {
+		parts = append(parts, "symbols")
+	}
+	if p.ExcludeAmbiguous {
+		parts = append(parts, "no ambiguous characters")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GeneratePassword creates a password or passphrase following policy and
+// returns it with its estimated entropy in bits
+func GeneratePassword(policy PasswordPolicy) (string, float64, error) {
+	if err := policy.Validate(); err != nil {
+		return "", 0, err
+	}
+	if policy.Words != 0 {
+		return generatePassphrase(policy)
+	}
+	return generateCharacterPassword(policy)
+}
+
+// generateCharacterPassword draws from every enabled character class, with
+// at least two characters of each class, then shuffles the result
+func generateCharacterPassword(policy PasswordPolicy) (string, float64, error) {
+	length := policy.Length
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+
+	classes := []string{passwordUpper, passwordLower, passwordDigits}
+	if policy.Symbols {
+		classes = append(classes, passwordSymbols)
+	}
+	var all string
+	for i, class := range classes {
+		if policy.ExcludeAmbiguous {
+			class = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, class)
+			classes[i] = class
+		}
+		all += class
+	}
+
+	result := make([]byte, 0, length)
+	for _, class := range classes {
+		for i := 0; i < 2 && len(result) < length; i++ {
+			c, err := randomElement(class)
+			if err != nil {
+				return "", 0, err
+			}
+			result = append(result, c)
+		}
+	}
+	for len(result) < length {
+		c, err := randomElement(all)
+		if err != nil {
+			return "", 0, err
+		}
+		result = append(result, c)
+	}
+
+	// Shuffle so the guaranteed characters are not at fixed positions
+	for i := len(result) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", 0, err
+		}
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return string(result), float64(length) * math.Log2(float64(len(all))), nil
+}
+
+// generatePassphrase joins random words from the policy's wordlist
+func generatePassphrase(policy PasswordPolicy) (string, float64, error) {
+	words, err := loadWordlist(policy.Wordlist)
+	if err != nil {
+		return "", 0, err
+	}
+	separator := policy.Separator
+	if separator == "" {
+		separator = "-"
+	}
+
+	chosen := make([]string, policy.Words)
+	for i := range chosen {
+		index, err := randomInt(len(words))
+		if err != nil {
+			return "", 0, err
+		}
+		chosen[i] = words[index]
+	}
+	return strings.Join(chosen, separator), float64(policy.Words) * math.Log2(float64(len(words))), nil
+}
+
+// loadWordlist reads a wordlist file, or the built-in list when path is empty.
+// Duplicate words are dropped so they do not inflate the entropy estimate.
+func loadWordlist(path string) ([]string, error) {
+	content := builtinWordlist
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wordlist: %w", err)
+		}
+		content = string(data)
+	}
+
+	seen := make(map[string]bool)
+	var words []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// Diceware lists prefix each word with its dice roll
+		word := fields[len(fields)-1]
+		if strings.ContainsAny(word, "'\"\\") || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	if len(words) < 2 {
+		return nil, fmt.Errorf("wordlist %s has too few usable words", path)
+	}
+	return words, nil
+}
+
+// randomInt returns a uniformly distributed integer in [0, n)
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random number: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+func randomElement(charset string) (byte, error) {
+	i, err := randomInt(len(charset))
+	if err != nil {
+		return 0, err
+	}
+	return charset[i], nil
+}
+
+// warnIfWeak logs a warning for passwords below weakPasswordBits of entropy
+func warnIfWeak(bits float64) {
+	if bits < weakPasswordBits {
+		logger.Warn("Password policy yields only about %.0f bits of entropy; consider a longer password or more words", bits)
+	}
+}
@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePasswordCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		length   int
+		allowed  string
+		required []string
+	}{
+		{"default", PasswordPolicy{}, 16, passwordUpper + passwordLower + passwordDigits,
+			[]string{passwordUpper, passwordLower, passwordDigits}},
+		{"long with symbols", PasswordPolicy{Length: 40, Symbols: true}, 40, passwordUpper + passwordLower + passwordDigits + passwordSymbols,
+			[]string{passwordUpper, passwordLower, passwordDigits, passwordSymbols}},
+		{"minimum length", PasswordPolicy{Length: 8, Symbols: true}, 8, passwordUpper + passwordLower + passwordDigits + passwordSymbols,
+			[]string{passwordUpper, passwordLower, passwordDigits, passwordSymbols}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				password, bits, err := GeneratePassword(tt.policy)
+				if err != nil {
+					t.Fatalf("GeneratePassword failed: %v", err)
+				}
+				if len(password) != tt.length {
+					t.Fatalf("expected length %d, got %q", tt.length, password)
+				}
+				if bits <= 0 {
+					t.Errorf("expected positive entropy, got %f", bits)
+				}
+				for _, c := range password {
+					if !strings.ContainsRune(tt.allowed, c) {
+						t.Fatalf("unexpected character %q in %q", c, password)
+					}
+				}
+				for _, class := range tt.required {
+					if !strings.ContainsAny(password, class) {
+						t.Fatalf("expected a character from %q in %q", class, password)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePasswordExcludeAmbiguous(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		password, _, err := GeneratePassword(PasswordPolicy{Length: 64, ExcludeAmbiguous: true})
+		if err != nil {
+			t.Fatalf("GeneratePassword failed: %v", err)
+		}
+		if strings.ContainsAny(password, ambiguousChars) {
+			t.Fatalf("password contains ambiguous characters: %q", password)
+		}
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	password, bits, err := GeneratePassword(PasswordPolicy{Words: 6})
+	if err != nil {
+		t.Fatalf("GeneratePassword failed: %v", err)
+	}
+	if words := strings.Split(password, "-"); len(words) != 6 {
+		t.Errorf("expected 6 words, got %q", password)
+	}
+	if bits < 48 {
+		t.Errorf("expected at least 48 bits from the built-in list, got %.1f", bits)
+	}
+
+	// Diceware-style lists with dice rolls, blank lines and duplicates
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	os.WriteFile(wordlist, []byte("11111 alpha\n11112 beta\n\n11113 alpha\n"), 0644)
+	password, bits, err = GeneratePassword(PasswordPolicy{Words: 4, Separator: " ", Wordlist: wordlist})
+	if err != nil {
+		t.Fatalf("GeneratePassword failed: %v", err)
+	}
+	for _, word := range strings.Split(password, " ") {
+		if word != "alpha" && word != "beta" {
+			t.Errorf("unexpected word %q in %q", word, password)
+		}
+	}
+	if bits != 4 {
+		t.Errorf("expected 4 bits from a two-word list, got %.1f", bits)
+	}
+
+	if _, _, err := GeneratePassword(PasswordPolicy{Words: 4, Wordlist: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected error for a missing wordlist")
+	}
+}
+
+func TestBuiltinWordlist(t *testing.T) {
+	words, err := loadWordlist("")
+	if err != nil {
+		t.Fatalf("loadWordlist failed: %v", err)
+	}
+	if len(words) < 256 {
+		t.Errorf("expected at least 256 built-in words, got %d", len(words))
+	}
+	for _, word := range words {
+		if word != strings.ToLower(word) || strings.ContainsAny(word, "-' ") {
+			t.Errorf("built-in word %q should be plain lowercase", word)
+		}
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	invalid := []PasswordPolicy{
+		{Length: 4},
+		{Length: 500},
+		{Words: 2},
+		{Words: 100},
+		{Words: 5, Separator: "'"},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", policy)
+		}
+		if err := SetPasswordPolicy(policy); err == nil {
+			t.Errorf("SetPasswordPolicy should reject %+v", policy)
+		}
+	}
+	if err := (PasswordPolicy{Length: 24, Symbols: true}).Validate(); err != nil {
+		t.Errorf("expected valid policy, got %v", err)
+	}
+}
+
+func TestSetPasswordPolicy(t *testing.T) {
+	t.Cleanup(func() { activePasswordPolicy = PasswordPolicy{} })
+
+	if err := SetPasswordPolicy(PasswordPolicy{Length: 24}); err != nil {
+		t.Fatalf("SetPasswordPolicy failed: %v", err)
+	}
+	if password := GenerateSecurePassword(); len(password) != 24 {
+		t.Errorf("expected the active policy to apply, got %q", password)
+	}
+
+	// An unreadable wordlist falls back to the default policy
+	activePasswordPolicy = PasswordPolicy{Words: 5, Wordlist: filepath.Join(t.TempDir(), "missing")}
+	if password := GenerateSecurePassword(); len(password) != DefaultPasswordLength {
+		t.Errorf("expected fallback to the default policy, got %q", password)
+	}
+}
+
+func TestPasswordPolicyDescribe(t *testing.T) {
+	policy := PasswordPolicy{Length: 32, Symbols: true, ExcludeAmbiguous: true}
+	if got := policy.Describe(); got != "32 characters, symbols, no ambiguous characters" {
+		t.Errorf("unexpected description %q", got)
+	}
+	if got := (PasswordPolicy{Words: 6}).Describe(); got != "6-word passphrase from built-in wordlist" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestSettingsPasswordPolicy(t *testing.T) {
+	SettingsDir = t.TempDir()
+	t.Cleanup(func() { SettingsDir = defaultSettingsDir() })
+
+	os.WriteFile(SettingsPath(), []byte("password:\n  length: 24\n  symbols: true\n"), 0644)
+	settings, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if settings.Password.Length != 24 || !settings.Password.Symbols {
+		t.Errorf("expected password policy from config, got %+v", settings.Password)
+	}
+
+	// An empty policy is not written out
+	if err := SaveSettings(&Settings{Context: "mylab"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	data, _ := os.ReadFile(SettingsPath())
+	if strings.Contains(string(data), "password") {
+		t.Errorf("expected no password section, got:\n%s", data)
+	}
+}
@@ -0,0 +1,357 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Password store backends
+const (
+	PasswordStoreMetadata = "metadata"
+	PasswordStoreVault    = "vault"
+)
+
+// Vault defaults and the environment variables the vault CLI also reads
+const (
+	DefaultVaultMount = "secret"
+	DefaultVaultPath  = "lxc-go-cli"
+	VaultAddrEnvVar   = "VAULT_ADDR"
+	VaultTokenEnvVar  = "VAULT_TOKEN"
+	VaultNSEnvVar     = "VAULT_NAMESPACE"
+)
+
+// vaultTimeout bounds each request to Vault
+const vaultTimeout = 10 * time.Second
+
+// vaultClient talks to the Vault HTTP API
+var vaultClient = &http.Client{Timeout: vaultTimeout}
+
+// PasswordStoreSettings chooses where 'app' passwords are kept
+type PasswordStoreSettings struct {
+	// Backend is metadata (the default) or vault
+	Backend string `yaml:"backend,omitempty"`
+	// Vault configures the vault backend
+	Vault VaultSettings `yaml:"vault,omitempty"`
+}
+
+// VaultSettings locates the kv-v2 secrets engine passwords are kept in. The
+// token is only read from VAULT_TOKEN or ~/.vault-token, never the config.
+type VaultSettings struct {
+	// Address is the Vault server; empty means $VAULT_ADDR
+	Address string `yaml:"address,omitempty"`
+	// Mount is the kv-v2 mount; empty means secret
+	Mount string `yaml:"mount,omitempty"`
+	// Path is the prefix under the mount; a container's password is kept at
+	// <path>/<container>. Empty means lxc-go-cli.
+	Path string `yaml:"path,omitempty"`
+	// Namespace is the Vault Enterprise namespace; empty means $VAULT_NAMESPACE
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// Validate checks the backend name and the Vault locations
+func (s PasswordStoreSettings) Validate() error {
+	switch s.Backend {
+	case "", PasswordStoreMetadata:
+		return nil
+	case PasswordStoreVault:
+	default:
+		return UsageErrorf("invalid password store backend '%s': must be %s or %s", s.Backend, PasswordStoreMetadata, PasswordStoreVault)
+	}
+	if s.Vault.Address != "" {
+		if u, err := url.Parse(s.Vault.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return UsageErrorf("invalid vault address '%s': must be an http(s) URL", s.Vault.Address)
+		}
+	}
+	for _, p := range []string{s.Vault.Mount, s.Vault.Path} {
+		if strings.Contains(p, "..") || strings.ContainsAny(p, "?#") {
+			return UsageErrorf("invalid vault path '%s'", p)
+		}
+	}
+	return nil
+}
+
+// PasswordStore keeps the 'app' password of each container
+type PasswordStore interface {
+	// Name is the backend name, as used in the config file
+	Name() string
+	Store(ctx context.Context, containerName, password string) error
+	Get(ctx context.Context, containerName string) (string, error)
+	// Delete forgets the password of a deleted container
+	Delete(ctx context.Context, containerName string) error
+	// Check reports whether the backend is reachable and usable
+	Check(ctx context.Context) error
+}
+
+// activePasswordStore is used by StoreContainerPassword and GetContainerPassword
+var activePasswordStore PasswordStore = MetadataPasswordStore{}
+
+// SetPasswordStore selects the backend used for 'app' passwords
+func SetPasswordStore(settings PasswordStoreSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if settings.Backend == PasswordStoreVault {
+		activePasswordStore = NewVaultPasswordStore(settings.Vault)
+	} else {
+		activePasswordStore = MetadataPasswordStore{}
+	}
+	return nil
+}
+
+// ActivePasswordStore returns the backend used for 'app' passwords
+func ActivePasswordStore() PasswordStore {
+	return activePasswordStore
+}
+
+// MetadataPasswordStore keeps passwords base64-encoded in the container's
+// user.app-password config key
+type MetadataPasswordStore struct{}
+
+func (MetadataPasswordStore) Name() string { return PasswordStoreMetadata }
+
+// Store writes the password to the container metadata
+func (MetadataPasswordStore) Store(ctx context.Context, containerName, password string) error {
+	// Encode password with base64 for basic obfuscation
+	encoded := base64.StdEncoding.EncodeToString([]byte(password))
+
+	// Store in LXC metadata using user.app-password key
+	output, err := Runner().RunWithOutput(ctx, "lxc", "config", "set", containerName, "user.app-password", encoded)
+	if err != nil {
+		logger.Debug("Failed to store password: %s", string(output))
+		return fmt.Errorf("failed to store password in container metadata: %w (output: %s)", err, string(output))
+	}
+
+	logger.Debug("Password stored successfully in container metadata")
+	return nil
+}
+
+// Get reads the password from the container metadata
+func (MetadataPasswordStore) Get(ctx context.Context, containerName string) (string, error) {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "config", "get", containerName, "user.app-password")
+	if err != nil {
+		logger.Debug("Failed to retrieve password: %s", string(output))
+		return "", fmt.Errorf("failed to retrieve password from container metadata: %w (output: %s)", err, string(output))
+	}
+
+	encoded := strings.TrimSpace(string(output))
+	if encoded == "" {
+		return "", fmt.Errorf("no password found for container '%s' (container may not have been created with this tool)", containerName)
+	}
+
+	// Decode from base64
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		logger.Debug("Failed to decode password: %v", err)
+		return "", fmt.Errorf("failed to decode stored password: %w", err)
+	}
+
+	logger.Debug("Password retrieved successfully")
+	return string(decoded), nil
+}
+
+// Delete does nothing; the metadata is deleted with the container
+func (MetadataPasswordStore) Delete(ctx context.Context, containerName string) error { return nil }
+
+// Check always succeeds; the metadata lives with the container
+func (MetadataPasswordStore) Check(ctx context.Context) error { return nil }
+
+// VaultPasswordStore keeps passwords in a HashiCorp Vault kv-v2 secrets
+// engine, one secret with a 'password' key per container
+type VaultPasswordStore struct {
+	Address   string
+	Mount     string
+	Path      string
+	Namespace string
+}
+
+// NewVaultPasswordStore fills in the defaults of a Vault backend
+func NewVaultPasswordStore(settings VaultSettings) *VaultPasswordStore {
+	store := &VaultPasswordStore{
+		Address:   settings.Address,
+		Mount:     strings.Trim(settings.Mount, "/"),
+		Path:      strings.Trim(settings.Path, "/"),
+		Namespace: settings.Namespace,
+	}
+	if store.Address == "" {
+		store.Address = os.Getenv(VaultAddrEnvVar)
+	}
+	if store.Mount == "" {
+		store.Mount = DefaultVaultMount
+	}
+	if store.Path == "" {
+		store.Path = DefaultVaultPath
+	}
+	if store.Namespace == "" {
+		store.Namespace = os.Getenv(VaultNSEnvVar)
+	}
+	return store
+}
+
+func (v *VaultPasswordStore) Name() string { return PasswordStoreVault }
+
+// SecretPath returns where a container's password is kept, as the vault
+// CLI names it
+func (v *VaultPasswordStore) SecretPath(containerName string) string {
+	return path.Join(v.Mount, v.Path, containerName)
+}
+
+// vaultToken returns the token from VAULT_TOKEN or the vault CLI's token file
+func vaultToken() (string, error) {
+	if token := os.Getenv(VaultTokenEnvVar); token != "" {
+		return token, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			if token := strings.TrimSpace(string(data)); token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no Vault token: set %s or log in with 'vault login'", VaultTokenEnvVar)
+}
+
+// request sends a Vault API request and decodes a JSON response into out.
+// It returns the HTTP status so callers can tell a missing secret apart.
+func (v *VaultPasswordStore) request(ctx context.Context, method, apiPath string, body, out any) (int, error) {
+	if v.Address == "" {
+		return 0, fmt.Errorf("no Vault address: set %s or password_store.vault.address in %s", VaultAddrEnvVar, SettingsPath())
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return 0, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(v.Address, "/")+"/v1/"+apiPath, reader)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach Vault at %s: %w", v.Address, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("Vault returned %s%s", resp.Status, vaultErrors(data))
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse Vault response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// vaultErrors formats the errors list of a Vault error response
+func vaultErrors(data []byte) string {
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(data, &body) != nil || len(body.Errors) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(body.Errors, "; ")
+}
+
+// dataPath is the kv-v2 API path of a container's secret
+func (v *VaultPasswordStore) dataPath(containerName string) string {
+	return path.Join(v.Mount, "data", v.Path, url.PathEscape(containerName))
+}
+
+// Store writes the password as a new version of the container's secret
+func (v *VaultPasswordStore) Store(ctx context.Context, containerName, password string) error {
+	body := map[string]any{"data": map[string]string{"password": password}}
+	if _, err := v.request(ctx, http.MethodPost, v.dataPath(containerName), body, nil); err != nil {
+		return fmt.Errorf("failed to store password in Vault at %s: %w", v.SecretPath(containerName), err)
+	}
+	logger.Debug("Password stored in Vault at %s", v.SecretPath(containerName))
+	return nil
+}
+
+// Get reads the latest version of the container's secret
+func (v *VaultPasswordStore) Get(ctx context.Context, containerName string) (string, error) {
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	status, err := v.request(ctx, http.MethodGet, v.dataPath(containerName), nil, &secret)
+	if status == http.StatusNotFound {
+		return "", NotFoundErrorf("no password found for container '%s' in Vault at %s", containerName, v.SecretPath(containerName))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve password from Vault: %w", err)
+	}
+	password, ok := secret.Data.Data["password"].(string)
+	if !ok || password == "" {
+		return "", fmt.Errorf("the Vault secret %s has no 'password' key", v.SecretPath(containerName))
+	}
+	return password, nil
+}
+
+// Delete removes every version of the container's secret; a secret that is
+// already gone is not an error
+func (v *VaultPasswordStore) Delete(ctx context.Context, containerName string) error {
+	metadataPath := path.Join(v.Mount, "metadata", v.Path, url.PathEscape(containerName))
+	status, err := v.request(ctx, http.MethodDelete, metadataPath, nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete the password in Vault at %s: %w", v.SecretPath(containerName), err)
+	}
+	logger.Debug("Password deleted from Vault at %s", v.SecretPath(containerName))
+	return nil
+}
+
+// Check verifies that Vault is initialized and unsealed and the token is valid
+func (v *VaultPasswordStore) Check(ctx context.Context) error {
+	// Standby nodes answer 429, which still serves reads through the active node
+	var health struct {
+		Initialized bool `json:"initialized"`
+		Sealed      bool `json:"sealed"`
+	}
+	status, err := v.request(ctx, http.MethodGet, "sys/health?standbyok=true", nil, &health)
+	switch {
+	case status == http.StatusNotImplemented:
+		return fmt.Errorf("Vault at %s is not initialized", v.Address)
+	case status == http.StatusServiceUnavailable:
+		return fmt.Errorf("Vault at %s is sealed", v.Address)
+	case err != nil:
+		return err
+	case health.Sealed:
+		return fmt.Errorf("Vault at %s is sealed", v.Address)
+	}
+
+	if _, err := v.request(ctx, http.MethodGet, "auth/token/lookup-self", nil, nil); err != nil {
+		return fmt.Errorf("the Vault token was rejected: %w", err)
+	}
+	return nil
+}
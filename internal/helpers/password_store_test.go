@@ -0,0 +1,236 @@
+package helpers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVault serves the kv-v2, health and token endpoints used by the Vault store
+type fakeVault struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	sealed  bool
+	token   string
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	switch {
+	case r.URL.Path == "/v1/sys/health":
+		if f.sealed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"initialized": true, "sealed": f.sealed})
+	case r.URL.Path == "/v1/auth/token/lookup-self":
+		w.Write([]byte(`{"data":{}}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/metadata/") && r.Method == http.MethodDelete:
+		delete(f.secrets, strings.TrimPrefix(r.URL.Path, "/v1/kv/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/data/"):
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/data/")
+		if r.Method == http.MethodPost {
+			var body struct {
+				Data map[string]string `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			f.secrets[key] = body.Data["password"]
+			w.Write([]byte(`{"data":{"version":1}}`))
+			return
+		}
+		password, ok := f.secrets[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]string{"password": password}}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestVaultPasswordStore(t *testing.T) {
+	vault := &fakeVault{secrets: map[string]string{}, token: "s.test"}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+	t.Setenv(VaultTokenEnvVar, "s.test")
+
+	store := NewVaultPasswordStore(VaultSettings{Address: server.URL, Mount: "kv", Path: "/team/"})
+	if store.SecretPath("web") != "kv/team/web" {
+		t.Errorf("unexpected secret path %s", store.SecretPath("web"))
+	}
+	ctx := context.Background()
+
+	if err := store.Check(ctx); err != nil {
+		t.Errorf("expected healthy vault, got %v", err)
+	}
+	if err := store.Store(ctx, "web", "s3cret"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if vault.secrets["team/web"] != "s3cret" {
+		t.Errorf("expected the password at team/web, got %v", vault.secrets)
+	}
+	if password, err := store.Get(ctx, "web"); err != nil || password != "s3cret" {
+		t.Errorf("expected the stored password, got %q, %v", password, err)
+	}
+	if _, err := store.Get(ctx, "db"); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "no password found for container 'db'") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if err := store.Delete(ctx, "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := vault.secrets["team/web"]; ok {
+		t.Error("expected the secret to be deleted")
+	}
+	if err := store.Delete(ctx, "web"); err != nil {
+		t.Errorf("expected deleting a missing secret to succeed, got %v", err)
+	}
+
+	vault.sealed = true
+	if err := store.Check(ctx); err == nil || !strings.Contains(err.Error(), "sealed") {
+		t.Errorf("expected sealed error, got %v", err)
+	}
+
+	t.Setenv(VaultTokenEnvVar, "s.wrong")
+	if err := store.Store(ctx, "web", "x"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+}
+
+func TestGetContainerPasswordFallsBackToMetadata(t *testing.T) {
+	vault := &fakeVault{secrets: map[string]string{"lxc-go-cli/new": "fromvault"}, token: "s.test"}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+	t.Setenv(VaultTokenEnvVar, "s.test")
+	t.Cleanup(func() { SetPasswordStore(PasswordStoreSettings{}) })
+	if err := SetPasswordStore(PasswordStoreSettings{Backend: PasswordStoreVault, Vault: VaultSettings{Address: server.URL, Mount: "kv"}}); err != nil {
+		t.Fatal(err)
+	}
+	mock := useMockRunner(t)
+	mock.Respond(base64.StdEncoding.EncodeToString([]byte("frommetadata"))+"\n", nil, "lxc", "config", "get", "old", "user.app-password")
+	mock.Respond("\n", nil, "lxc", "config", "get", "none", "user.app-password")
+
+	if password, err := GetContainerPassword("new"); err != nil || password != "fromvault" {
+		t.Errorf("expected the Vault password, got %q, %v", password, err)
+	}
+	if password, err := GetContainerPassword("old"); err != nil || password != "frommetadata" {
+		t.Errorf("expected the metadata password of an older container, got %q, %v", password, err)
+	}
+	if _, err := GetContainerPassword("none"); err == nil || !strings.Contains(err.Error(), "in Vault") {
+		t.Errorf("expected the Vault not found error, got %v", err)
+	}
+}
+
+func TestDeleteContainerDeletesVaultPassword(t *testing.T) {
+	useHostDirs(t)
+	vault := &fakeVault{secrets: map[string]string{"lxc-go-cli/web": "s3cret"}, token: "s.test"}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+	t.Setenv(VaultTokenEnvVar, "s.test")
+	t.Cleanup(func() { SetPasswordStore(PasswordStoreSettings{}) })
+	if err := SetPasswordStore(PasswordStoreSettings{Backend: PasswordStoreVault, Vault: VaultSettings{Address: server.URL, Mount: "kv"}}); err != nil {
+		t.Fatal(err)
+	}
+	mock := useMockRunner(t)
+	mock.Respond("config:\n  "+TrashOriginalKey+": web\ndevices: {}\n", nil, "lxc", "config", "show", "trash-20250301-web")
+	mock.Respond("[]", nil, "lxc", "list", "--format", "json")
+
+	if err := DeleteContainer("trash-20250301-web", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vault.secrets["lxc-go-cli/web"]; ok {
+		t.Error("expected the password of the trashed container to be deleted under its original name")
+	}
+
+	// Once a new container is named web, the secret is its password
+	vault.secrets["lxc-go-cli/web"] = "n3w"
+	mock.Respond(`[{"name":"web","status":"Running","config":{}}]`, nil, "lxc", "list", "--format", "json")
+	if err := DeleteContainer("trash-20250301-web", true); err != nil {
+		t.Fatal(err)
+	}
+	if vault.secrets["lxc-go-cli/web"] != "n3w" {
+		t.Error("expected the password of the new 'web' to be kept")
+	}
+}
+
+func TestVaultPasswordStoreNeedsAddressAndToken(t *testing.T) {
+	t.Setenv(VaultAddrEnvVar, "")
+	t.Setenv(VaultTokenEnvVar, "s.test")
+	if err := NewVaultPasswordStore(VaultSettings{}).Check(context.Background()); err == nil || !strings.Contains(err.Error(), VaultAddrEnvVar) {
+		t.Errorf("expected missing address error, got %v", err)
+	}
+
+	t.Setenv(VaultAddrEnvVar, "http://127.0.0.1:8200")
+	t.Setenv(VaultTokenEnvVar, "")
+	t.Setenv("HOME", t.TempDir())
+	store := NewVaultPasswordStore(VaultSettings{})
+	if store.Address != "http://127.0.0.1:8200" || store.SecretPath("web") != "secret/lxc-go-cli/web" {
+		t.Errorf("expected defaults from the environment, got %+v", store)
+	}
+	if err := store.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "no Vault token") {
+		t.Errorf("expected missing token error, got %v", err)
+	}
+}
+
+func TestPasswordStoreSettingsValidate(t *testing.T) {
+	valid := []PasswordStoreSettings{
+		{},
+		{Backend: PasswordStoreMetadata},
+		{Backend: PasswordStoreVault, Vault: VaultSettings{Address: "https://vault.example.com:8200", Mount: "kv", Path: "apps/lxc"}},
+	}
+	for _, settings := range valid {
+		if err := settings.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", settings, err)
+		}
+	}
+	invalid := []PasswordStoreSettings{
+		{Backend: "keychain"},
+		{Backend: PasswordStoreVault, Vault: VaultSettings{Address: "vault:8200"}},
+		{Backend: PasswordStoreVault, Vault: VaultSettings{Path: "../sys"}},
+	}
+	for _, settings := range invalid {
+		if err := settings.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", settings)
+		}
+	}
+}
+
+func TestStoreContainerPasswordUsesActiveStore(t *testing.T) {
+	runner := useMockRunner(t)
+	t.Cleanup(func() { SetPasswordStore(PasswordStoreSettings{}) })
+
+	if ActivePasswordStore().Name() != PasswordStoreMetadata {
+		t.Fatalf("expected the metadata store by default, got %s", ActivePasswordStore().Name())
+	}
+	if err := StoreContainerPassword("web", "s3cret"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !runner.Ran("lxc", "config", "set", "web", "user.app-password", "czNjcmV0") {
+		t.Errorf("expected the password in the metadata, got %v", runner.Commands)
+	}
+
+	vault := &fakeVault{secrets: map[string]string{"lxc-go-cli/web": "from-vault"}, token: "s.test"}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+	t.Setenv(VaultTokenEnvVar, "s.test")
+	if err := SetPasswordStore(PasswordStoreSettings{Backend: PasswordStoreVault, Vault: VaultSettings{Address: server.URL, Mount: "kv"}}); err != nil {
+		t.Fatal(err)
+	}
+	if password, err := GetContainerPassword("web"); err != nil || password != "from-vault" {
+		t.Errorf("expected the Vault password, got %q, %v", password, err)
+	}
+}
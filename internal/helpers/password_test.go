@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"io"
 	"regexp"
 	"strings"
 	"testing"
@@ -387,3 +388,37 @@ func TestSetUserPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestSetUserPasswordUsesStdin(t *testing.T) {
+	runner := useMockRunner(t)
+
+	if err := SetUserPassword("web", "app", "it's:s3cret"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := runner.ExpectCommands([]string{"lxc", "exec", "web", "-T", "--", "chpasswd"}); err != nil {
+		t.Error(err)
+	}
+	if len(runner.Stdins) != 1 {
+		t.Fatalf("expected the password on stdin, got %d inputs", len(runner.Stdins))
+	}
+	if data, _ := io.ReadAll(runner.Stdins[0]); string(data) != "app:it's:s3cret\n" {
+		t.Errorf("unexpected stdin %q", data)
+	}
+
+	if err := SetUserPassword("web", "app", "two\nlines"); err == nil {
+		t.Error("expected error for a password with a newline")
+	}
+}
+
+func TestValidateSuppliedPassword(t *testing.T) {
+	for _, password := range []string{"s3cret", "with space", "it's:ok"} {
+		if err := ValidateSuppliedPassword(password); err != nil {
+			t.Errorf("expected %q to be valid, got %v", password, err)
+		}
+	}
+	for _, password := range []string{"", "two\nlines", "cr\r"} {
+		if err := ValidateSuppliedPassword(password); err == nil {
+			t.Errorf("expected %q to be rejected", password)
+		}
+	}
+}
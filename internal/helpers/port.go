@@ -1,8 +1,11 @@
 package helpers
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,31 +14,37 @@ import (
 
 // IsPortAvailable checks if a port is available for use on the host
 func IsPortAvailable(port int, protocol string) bool {
+	return IsPortAvailableOn("", port, protocol)
+}
+
+// IsPortAvailableOn checks if a port is available on one host address, or
+// on all of them when ip is empty
+func IsPortAvailableOn(ip string, port int, protocol string) bool {
 	if port < 1 || port > 65535 {
 		return false
 	}
 
-	address := fmt.Sprintf(":%d", port)
+	address := net.JoinHostPort(ip, strconv.Itoa(port))
 	protocol = strings.ToLower(protocol)
 
 	switch protocol {
 	case "tcp":
 		listener, err := net.Listen("tcp", address)
 		if err != nil {
-			logger.Debug("Port %d (TCP) appears to be in use: %v", port, err)
+			logger.Debug("Port %s (TCP) appears to be in use: %v", address, err)
 			return false
 		}
 		listener.Close()
-		logger.Debug("Port %d (TCP) is available", port)
+		logger.Debug("Port %s (TCP) is available", address)
 		return true
 	case "udp":
 		conn, err := net.ListenPacket("udp", address)
 		if err != nil {
-			logger.Debug("Port %d (UDP) appears to be in use: %v", port, err)
+			logger.Debug("Port %s (UDP) appears to be in use: %v", address, err)
 			return false
 		}
 		conn.Close()
-		logger.Debug("Port %d (UDP) is available", port)
+		logger.Debug("Port %s (UDP) is available", address)
 		return true
 	default:
 		logger.Debug("Unknown protocol '%s' for port availability check", protocol)
@@ -43,6 +52,33 @@ func IsPortAvailable(port int, protocol string) bool {
 	}
 }
 
+// InterfaceAddresses returns the IP addresses of a host network interface.
+// IPv6 link-local addresses are left out: a proxy cannot listen on them
+// without a zone.
+func InterfaceAddresses(name string) ([]string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown network interface '%s': %w", name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the addresses of '%s': %w", name, err)
+	}
+
+	var ips []string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ips = append(ips, ipNet.IP.String())
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("network interface '%s' has no usable address", name)
+	}
+	return ips, nil
+}
+
 // ValidatePortMapping attempts to validate that a port mapping is actually working
 // This is a best-effort check and may have false positives/negatives
 func ValidatePortMapping(hostPort int, protocol string, timeout time.Duration) error {
@@ -81,7 +117,7 @@ func ValidatePortMapping(hostPort int, protocol string, timeout time.Duration) e
 
 // FormatPortConflictError creates a helpful error message when a port is in use
 func FormatPortConflictError(hostPort, protocol string) error {
-	return fmt.Errorf(`host port %s (%s) is already in use
+	return ConflictErrorf(`host port %s (%s) is already in use
 
 Suggestions:
   • Use a different host port: lxc-go-cli port add <container> <other-port> <container-port> %s
@@ -100,3 +136,68 @@ func GetPortUsageInfo(port int) string {
 	return fmt.Sprintf("Port %d may be in use by another service. Use 'ss -tuln | grep :%d' or 'netstat -tuln | grep :%d' to investigate.",
 		port, port, port)
 }
+
+// PortHolder is a process listening on a host port, as reported by ss
+type PortHolder struct {
+	Process string
+	PID     int
+	Local   string
+}
+
+func (h PortHolder) String() string {
+	if h.Process == "" {
+		return fmt.Sprintf("unknown process on %s", h.Local)
+	}
+	return fmt.Sprintf("%s (pid %d) on %s", h.Process, h.PID, h.Local)
+}
+
+// ssUserPattern matches one process in the users:(...) column of ss output
+var ssUserPattern = regexp.MustCompile(`\("([^"]*)",pid=(\d+)`)
+
+// GetPortHolders lists the sockets listening on a host port. Processes of
+// other users are only named when run as root; their sockets are still listed.
+func GetPortHolders(ctx context.Context, port int, protocol string) ([]PortHolder, error) {
+	flag := "-ltnp"
+	if strings.ToLower(protocol) == "udp" {
+		flag = "-lunp"
+	}
+	output, err := runOutput(ctx, "ss", "-H", flag, fmt.Sprintf("sport = :%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ss: %w", err)
+	}
+	return parseSSHolders(string(output)), nil
+}
+
+// parseSSHolders parses `ss -H -l[tu]np` output into one holder per process
+// and local address
+func parseSSHolders(output string) []PortHolder {
+	var holders []PortHolder
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// State Recv-Q Send-Q Local Peer [Process]
+		if len(fields) < 5 {
+			continue
+		}
+		local := fields[3]
+
+		matches := ssUserPattern.FindAllStringSubmatch(line, -1)
+		if len(matches) == 0 {
+			if !seen[local] {
+				seen[local] = true
+				holders = append(holders, PortHolder{Local: local})
+			}
+			continue
+		}
+		for _, match := range matches {
+			key := match[2] + "@" + local
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pid, _ := strconv.Atoi(match[2])
+			holders = append(holders, PortHolder{Process: match[1], PID: pid, Local: local})
+		}
+	}
+	return holders
+}
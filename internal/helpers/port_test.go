@@ -1,6 +1,8 @@
 package helpers
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"strings"
 	"testing"
@@ -281,3 +283,81 @@ func TestProtocolCaseInsensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPortHolders(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond(`LISTEN 0 511 0.0.0.0:8080 0.0.0.0:* users:(("nginx",pid=812,fd=6),("nginx",pid=813,fd=6))
+LISTEN 0 511 [::]:8080 [::]:* users:(("nginx",pid=812,fd=7))
+`, nil, "ss")
+
+	holders, err := GetPortHolders(context.Background(), 8080, "tcp")
+	if err != nil {
+		t.Fatalf("GetPortHolders failed: %v", err)
+	}
+	if !mock.Ran("ss", "-H", "-ltnp", "sport = :8080") {
+		t.Errorf("expected ss to query tcp listeners, got %v", mock.Commands)
+	}
+	if len(holders) != 3 {
+		t.Fatalf("expected 3 holders, got %+v", holders)
+	}
+	if holders[0].String() != "nginx (pid 812) on 0.0.0.0:8080" {
+		t.Errorf("unexpected holder: %s", holders[0])
+	}
+
+	if _, err := GetPortHolders(context.Background(), 53, "UDP"); err != nil || !mock.Ran("ss", "-H", "-lunp", "sport = :53") {
+		t.Errorf("expected ss to query udp listeners, got %v, %v", mock.Commands, err)
+	}
+
+	mock.Respond("", fmt.Errorf("executable file not found"), "ss")
+	if _, err := GetPortHolders(context.Background(), 8080, "tcp"); err == nil {
+		t.Error("expected error when ss fails")
+	}
+}
+
+func TestParseSSHolders(t *testing.T) {
+	// Without root, ss lists the socket but not the process
+	holders := parseSSHolders("UNCONN 0 0 127.0.0.53%lo:53 0.0.0.0:*\n\n")
+	if len(holders) != 1 || holders[0].Process != "" || holders[0].Local != "127.0.0.53%lo:53" {
+		t.Errorf("unexpected holders: %+v", holders)
+	}
+	if !strings.HasPrefix(holders[0].String(), "unknown process") {
+		t.Errorf("unexpected description: %s", holders[0])
+	}
+	if holders := parseSSHolders(""); len(holders) != 0 {
+		t.Errorf("expected no holders, got %+v", holders)
+	}
+}
+
+func TestIsPortAvailableOn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on 127.0.0.1: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if IsPortAvailableOn("127.0.0.1", port, "tcp") {
+		t.Errorf("expected port %d to be in use on 127.0.0.1", port)
+	}
+	if IsPortAvailable(port, "tcp") {
+		t.Errorf("expected port %d to be in use on all addresses", port)
+	}
+}
+
+func TestInterfaceAddresses(t *testing.T) {
+	addresses, err := InterfaceAddresses("lo")
+	if err != nil {
+		t.Skipf("no loopback interface: %v", err)
+	}
+	found := false
+	for _, address := range addresses {
+		found = found || address == "127.0.0.1"
+	}
+	if !found {
+		t.Errorf("expected 127.0.0.1 among the addresses of lo, got %v", addresses)
+	}
+
+	if _, err := InterfaceAddresses("no-such-if0"); err == nil || !strings.Contains(err.Error(), "unknown network interface") {
+		t.Errorf("expected an unknown interface error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PortTraffic is the traffic through a forwarded host port. It covers the
+// connections the host tracks now, not everything since the port was added.
+type PortTraffic struct {
+	// Connections are established TCP connections, or UDP flows
+	Connections int
+	// BytesIn is received from clients, BytesOut sent to them
+	BytesIn  int64
+	BytesOut int64
+	// Bytes is false when the byte counters are unknown, e.g. because
+	// conntrack accounting is turned off
+	Bytes bool
+	// Source is the tool the numbers come from: ss or conntrack
+	Source string
+}
+
+// ConntrackAccountingHint tells how to make conntrack count bytes
+const ConntrackAccountingHint = "sudo sysctl -w net.netfilter.nf_conntrack_acct=1"
+
+var (
+	ssBytesSentPattern     = regexp.MustCompile(`\bbytes_sent:(\d+)`)
+	ssBytesReceivedPattern = regexp.MustCompile(`\bbytes_received:(\d+)`)
+	conntrackBytesPattern  = regexp.MustCompile(`\bbytes=(\d+)`)
+)
+
+// GetPortTraffic reads the traffic through a host port. TCP ports served by
+// LXD's proxy process are read from its sockets with ss; UDP ports and NAT
+// proxy devices, whose packets never reach a host socket, from conntrack,
+// which needs root.
+func GetPortTraffic(ctx context.Context, port int, protocol string, nat bool) (PortTraffic, error) {
+	protocol = strings.ToLower(protocol)
+	if protocol == "tcp" && !nat {
+		output, err := runOutput(ctx, "ss", "-H", "-tni", fmt.Sprintf("sport = :%d", port))
+		if err != nil {
+			return PortTraffic{}, fmt.Errorf("failed to run ss: %w", err)
+		}
+		return parseSSTraffic(string(output)), nil
+	}
+
+	output, err := runOutput(ctx, "conntrack", "-L", "-p", protocol, "--dport", strconv.Itoa(port))
+	if err != nil {
+		return PortTraffic{}, fmt.Errorf("failed to run conntrack (it needs root and the conntrack package): %w", err)
+	}
+	return parseConntrackTraffic(string(output)), nil
+}
+
+// parseSSTraffic sums `ss -H -tni` output: a line per socket, followed by an
+// indented line of TCP info with the byte counters
+func parseSSTraffic(output string) PortTraffic {
+	traffic := PortTraffic{Bytes: true, Source: "ss"}
+	established := false
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			established = strings.HasPrefix(line, "ESTAB")
+			if established {
+				traffic.Connections++
+			}
+			continue
+		}
+		if !established {
+			continue
+		}
+		if match := ssBytesReceivedPattern.FindStringSubmatch(line); match != nil {
+			n, _ := strconv.ParseInt(match[1], 10, 64)
+			traffic.BytesIn += n
+		}
+		if match := ssBytesSentPattern.FindStringSubmatch(line); match != nil {
+			n, _ := strconv.ParseInt(match[1], 10, 64)
+			traffic.BytesOut += n
+		}
+	}
+	return traffic
+}
+
+// parseConntrackTraffic sums `conntrack -L` output, a line per flow. With
+// accounting on, the first bytes= is the original direction and the second
+// the reply.
+func parseConntrackTraffic(output string) PortTraffic {
+	traffic := PortTraffic{Bytes: true, Source: "conntrack"}
+	flows := 0
+	counted := 0
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// TCP flows carry a state; only count the open ones
+		if fields[0] == "tcp" && !strings.Contains(line, " ESTABLISHED ") {
+			continue
+		}
+		flows++
+		matches := conntrackBytesPattern.FindAllStringSubmatch(line, 2)
+		if len(matches) < 2 {
+			continue
+		}
+		counted++
+		in, _ := strconv.ParseInt(matches[0][1], 10, 64)
+		out, _ := strconv.ParseInt(matches[1][1], 10, 64)
+		traffic.BytesIn += in
+		traffic.BytesOut += out
+	}
+	traffic.Connections = flows
+	traffic.Bytes = counted > 0 || flows == 0
+	return traffic
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseSSTraffic(t *testing.T) {
+	output := "ESTAB 0 0 [::ffff:10.0.0.1]:8080 [::ffff:192.0.2.7]:51234\n" +
+		"\t cubic wscale:7,7 rto:204 bytes_sent:2048 bytes_acked:2049 bytes_received:512 segs_out:10\n" +
+		"ESTAB 0 0 [::ffff:10.0.0.1]:8080 [::ffff:192.0.2.8]:40000\n" +
+		"\t cubic bytes_sent:100 bytes_received:50\n" +
+		"TIME-WAIT 0 0 [::ffff:10.0.0.1]:8080 [::ffff:192.0.2.9]:40001\n" +
+		"\t bytes_sent:999999 bytes_received:999999\n"
+
+	traffic := parseSSTraffic(output)
+	want := PortTraffic{Connections: 2, BytesIn: 562, BytesOut: 2148, Bytes: true, Source: "ss"}
+	if traffic != want {
+		t.Errorf("expected %+v, got %+v", want, traffic)
+	}
+	if traffic := parseSSTraffic(""); traffic.Connections != 0 || !traffic.Bytes {
+		t.Errorf("expected an idle port, got %+v", traffic)
+	}
+}
+
+func TestParseConntrackTraffic(t *testing.T) {
+	output := "udp      17 29 src=192.0.2.7 dst=10.0.0.1 sport=40000 dport=5353 packets=3 bytes=200 src=10.0.0.5 dst=192.0.2.7 sport=53 dport=40000 packets=3 bytes=600 mark=0 use=1\n" +
+		"tcp      6 431999 ESTABLISHED src=192.0.2.8 dst=10.0.0.1 sport=40001 dport=8443 packets=10 bytes=1000 src=10.0.0.5 dst=192.0.2.8 sport=443 dport=40001 packets=12 bytes=9000 [ASSURED] mark=0 use=1\n" +
+		"tcp      6 100 TIME_WAIT src=192.0.2.9 dst=10.0.0.1 sport=40002 dport=8443 packets=1 bytes=60 src=10.0.0.5 dst=192.0.2.9 sport=443 dport=40002 packets=1 bytes=60 mark=0 use=1\n"
+
+	traffic := parseConntrackTraffic(output)
+	want := PortTraffic{Connections: 2, BytesIn: 1200, BytesOut: 9600, Bytes: true, Source: "conntrack"}
+	if traffic != want {
+		t.Errorf("expected %+v, got %+v", want, traffic)
+	}
+
+	// Without accounting there are no byte counters
+	traffic = parseConntrackTraffic("udp      17 29 src=192.0.2.7 dst=10.0.0.1 sport=40000 dport=5353 src=10.0.0.5 dst=192.0.2.7 sport=53 dport=40000 mark=0 use=1\n")
+	if traffic.Connections != 1 || traffic.Bytes {
+		t.Errorf("expected a flow without byte counts, got %+v", traffic)
+	}
+}
+
+func TestGetPortTraffic(t *testing.T) {
+	runner := useMockRunner(t)
+	if _, err := GetPortTraffic(context.Background(), 8080, "TCP", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPortTraffic(context.Background(), 8443, "TCP", true); err != nil {
+		t.Fatal(err)
+	}
+	err := runner.ExpectCommands(
+		[]string{"ss", "-H", "-tni", "sport = :8080"},
+		[]string{"conntrack", "-L", "-p", "tcp", "--dport", "8443"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	runner.Respond("", &MockExitError{Code: 1}, "conntrack")
+	if _, err := GetPortTraffic(context.Background(), 5353, "udp", false); err == nil {
+		t.Error("expected error when conntrack fails")
+	}
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LimitMemoryKey is the instance's memory limit, set by size presets
+const LimitMemoryKey = "limits.memory"
+
+// memoryLimitPattern matches limits.memory values: a size such as 1GiB or
+// 512MB, or a share of the host's memory such as 50%
+var memoryLimitPattern = regexp.MustCompile(`^(\d+(\.\d+)?(B|kB|MB|GB|TB|KiB|MiB|GiB|TiB)|\d+%)$`)
+
+// SizePreset is a named container size: a CPU count, a memory limit and a
+// root disk size. Empty fields are left as they are.
+type SizePreset struct {
+	CPU    string `yaml:"cpu,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+	Disk   string `yaml:"disk,omitempty"`
+}
+
+// DefaultSizePresets are available without configuration; presets of the
+// same name in the config file replace them
+var DefaultSizePresets = map[string]SizePreset{
+	"small":  {CPU: "1", Memory: "1GiB", Disk: "10GiB"},
+	"medium": {CPU: "2", Memory: "4GiB", Disk: "20GiB"},
+	"large":  {CPU: "4", Memory: "8GiB", Disk: "50GiB"},
+}
+
+// Validate checks the preset's values before anything is changed
+func (p SizePreset) Validate() error {
+	if p == (SizePreset{}) {
+		return fmt.Errorf("preset sets nothing: give cpu, memory or disk")
+	}
+	if p.CPU != "" {
+		if count, err := strconv.Atoi(p.CPU); err != nil || count < 1 {
+			return UsageErrorf("invalid cpu '%s': must be a number of CPUs of at least 1", p.CPU)
+		}
+	}
+	if p.Memory != "" && !memoryLimitPattern.MatchString(p.Memory) {
+		return UsageErrorf("invalid memory '%s': expected a size such as 1GiB or 512MB, or a percentage such as 50%%", p.Memory)
+	}
+	if p.Disk != "" {
+		if err := ValidateStorageSize(p.Disk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Config returns the instance config keys the preset sets
+func (p SizePreset) Config() map[string]string {
+	config := make(map[string]string)
+	if p.CPU != "" {
+		config[LimitCPUKey] = p.CPU
+	}
+	if p.Memory != "" {
+		config[LimitMemoryKey] = p.Memory
+	}
+	return config
+}
+
+// String describes the preset, e.g. "2 CPU, 4GiB memory, 20GiB disk"
+func (p SizePreset) String() string {
+	return fmt.Sprintf("%s CPU, %s memory, %s disk", valueOr(p.CPU, "any"), valueOr(p.Memory, "any"), valueOr(p.Disk, "default"))
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// SizePresets returns the built-in presets merged with those in the config
+func SizePresets(settings *Settings) map[string]SizePreset {
+	presets := make(map[string]SizePreset, len(DefaultSizePresets))
+	for name, preset := range DefaultSizePresets {
+		presets[name] = preset
+	}
+	if settings != nil {
+		for name, preset := range settings.Presets {
+			presets[name] = preset
+		}
+	}
+	return presets
+}
+
+// SizePresetNames returns the names of presets, sorted
+func SizePresetNames(presets map[string]SizePreset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveSizePreset looks up and validates a preset by name
+func ResolveSizePreset(settings *Settings, name string) (SizePreset, error) {
+	presets := SizePresets(settings)
+	preset, ok := presets[name]
+	if !ok {
+		return SizePreset{}, fmt.Errorf("unknown preset '%s': available presets are %s", name, strings.Join(SizePresetNames(presets), ", "))
+	}
+	if err := preset.Validate(); err != nil {
+		return SizePreset{}, fmt.Errorf("invalid preset '%s' in %s: %w", name, SettingsPath(), err)
+	}
+	return preset, nil
+}
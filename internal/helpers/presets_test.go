@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSizePresetValidate(t *testing.T) {
+	valid := []SizePreset{
+		{CPU: "1", Memory: "1GiB", Disk: "10GiB"},
+		{Memory: "50%"},
+		{Disk: "20G"},
+	}
+	for _, preset := range valid {
+		if err := preset.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", preset, err)
+		}
+	}
+	invalid := map[string]SizePreset{
+		"sets nothing":  {},
+		"invalid cpu":   {CPU: "0"},
+		"invalid mem":   {Memory: "lots"},
+		"invalid size":  {Disk: "ten"},
+		"pinned cpu":    {CPU: "0-3"},
+		"memory 1.5GiB": {Memory: "1.5 GiB"},
+	}
+	for name, preset := range invalid {
+		if err := preset.Validate(); err == nil {
+			t.Errorf("%s: expected %+v to be rejected", name, preset)
+		}
+	}
+	for name, preset := range DefaultSizePresets {
+		if err := preset.Validate(); err != nil {
+			t.Errorf("built-in preset %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestResolveSizePreset(t *testing.T) {
+	var settings Settings
+	config := "presets:\n  large:\n    cpu: 8\n    memory: 16GiB\n  ci:\n    cpu: 2\n  broken:\n    memory: lots\n"
+	if err := yaml.Unmarshal([]byte(config), &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if preset, err := ResolveSizePreset(&settings, "large"); err != nil || preset != (SizePreset{CPU: "8", Memory: "16GiB"}) {
+		t.Errorf("expected the configured large preset, got %+v, %v", preset, err)
+	}
+	if preset, err := ResolveSizePreset(&settings, "small"); err != nil || preset != DefaultSizePresets["small"] {
+		t.Errorf("expected the built-in small preset, got %+v, %v", preset, err)
+	}
+	if preset, err := ResolveSizePreset(nil, "medium"); err != nil || preset.CPU != "2" {
+		t.Errorf("expected the built-in medium preset without a config, got %+v, %v", preset, err)
+	}
+	if _, err := ResolveSizePreset(&settings, "huge"); err == nil || !strings.Contains(err.Error(), "available presets are broken, ci, large, medium, small") {
+		t.Errorf("expected unknown preset error, got %v", err)
+	}
+	if _, err := ResolveSizePreset(&settings, "broken"); err == nil || !strings.Contains(err.Error(), "invalid preset 'broken'") {
+		t.Errorf("expected invalid preset error, got %v", err)
+	}
+}
+
+func TestSizePresetConfig(t *testing.T) {
+	config := SizePreset{CPU: "2", Disk: "20GiB"}.Config()
+	if len(config) != 1 || config[LimitCPUKey] != "2" {
+		t.Errorf("unexpected config %v", config)
+	}
+	if s := (SizePreset{CPU: "2", Memory: "4GiB"}).String(); s != "2 CPU, 4GiB memory, default disk" {
+		t.Errorf("unexpected description %q", s)
+	}
+}
+
+func TestLaunchInstanceWithLimits(t *testing.T) {
+	runner := useMockRunner(t)
+	opts := LaunchOptions{Config: map[string]string{LimitMemoryKey: "4GiB", LimitCPUKey: "2"}, RootSize: "20GiB"}
+	if err := LaunchInstance("web", "ubuntu", "24.04", "amd64", "pool", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := runner.ExpectCommands([]string{"lxc", "launch", "ubuntu:24.04", "web", "--storage", "pool",
+		"--config", "limits.cpu=2", "--config", "limits.memory=4GiB", "--device", "root,size=20GiB"})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Host commands such as nft and btrfs need root. With sudo mode they are
+// prefixed with sudo; sudo asks for the password once, before the first of
+// them runs, so the password prompt is never hidden in captured output.
+var (
+	sudoMu     sync.Mutex
+	sudoMode   bool
+	sudoPrimed bool
+)
+
+// Hooks for the current process' identity; tests replace them
+var (
+	geteuid          = os.Geteuid
+	sessionGroupIDs  = os.Getgroups
+	configuredGroups = func() ([]string, error) {
+		current, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		return current.GroupIds()
+	}
+	lookupGroupID = func(name string) (string, error) {
+		group, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return group.Gid, nil
+	}
+)
+
+// sudoStreams connect sudo's password prompt to the terminal
+var sudoStreams = Streams{Stdin: os.Stdin, Stdout: os.Stderr, Stderr: os.Stderr}
+
+// SetSudo controls whether privileged host commands are run through sudo
+func SetSudo(enabled bool) {
+	sudoMu.Lock()
+	defer sudoMu.Unlock()
+	sudoMode = enabled
+	sudoPrimed = false
+}
+
+// privilegedCommand returns the argv for a host command that needs root,
+// prefixed with sudo in sudo mode unless this process already is root
+func privilegedCommand(ctx context.Context, argv ...string) ([]string, error) {
+	sudoMu.Lock()
+	defer sudoMu.Unlock()
+	if !sudoMode || geteuid() == 0 {
+		return argv, nil
+	}
+
+	if !sudoPrimed {
+		logger.Info("%s needs root privileges, running it with sudo", argv[0])
+		if err := Runner().RunStreaming(ctx, sudoStreams, "sudo", "-v"); err != nil {
+			return nil, WithKind(fmt.Errorf("sudo authentication failed: %w", err), ErrEnvironment)
+		}
+		sudoPrimed = true
+	}
+	// -n fails instead of prompting again where the prompt could not be seen
+	return append([]string{"sudo", "-n", "--"}, argv...), nil
+}
+
+// isPermissionOutput reports whether command output shows it lacked privileges
+func isPermissionOutput(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "permission denied") || strings.Contains(output, "operation not permitted") ||
+		strings.Contains(output, "need to be root")
+}
+
+// withPrivilegeHint explains how to get root for a host command that failed
+// because it ran without it
+func withPrivilegeHint(err error, output string) error {
+	sudoMu.Lock()
+	enabled := sudoMode
+	sudoMu.Unlock()
+	if enabled || geteuid() == 0 || !isPermissionOutput(output) {
+		return err
+	}
+	return WithKind(fmt.Errorf("%w\nThis needs root privileges: run it as root or add --sudo", err), ErrEnvironment)
+}
+
+// groupMembership reports whether the current user is a member of group in
+// the user database, and whether this session already has the group. Users
+// added to a group keep running without it until they log in again.
+func groupMembership(group string) (configured, active bool) {
+	gid, err := lookupGroupID(group)
+	if err != nil {
+		return false, false
+	}
+
+	if ids, err := configuredGroups(); err == nil {
+		for _, id := range ids {
+			if id == gid {
+				configured = true
+			}
+		}
+	}
+	if ids, err := sessionGroupIDs(); err == nil {
+		for _, id := range ids {
+			if fmt.Sprint(id) == gid {
+				active = true
+			}
+		}
+	}
+	return configured, active
+}
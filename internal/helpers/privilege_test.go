@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// useIdentity fakes the effective user id and group memberships of the process
+func useIdentity(t *testing.T, euid int, configured []string, session []int) {
+	t.Helper()
+	previousEuid, previousConfigured, previousSession, previousLookup := geteuid, configuredGroups, sessionGroupIDs, lookupGroupID
+	t.Cleanup(func() {
+		geteuid, configuredGroups, sessionGroupIDs, lookupGroupID = previousEuid, previousConfigured, previousSession, previousLookup
+		SetSudo(false)
+	})
+	geteuid = func() int { return euid }
+	configuredGroups = func() ([]string, error) { return configured, nil }
+	sessionGroupIDs = func() ([]int, error) { return session, nil }
+	lookupGroupID = func(name string) (string, error) {
+		if name == "lxd" {
+			return "133", nil
+		}
+		return "", errors.New("unknown group")
+	}
+}
+
+func TestPrivilegedCommand(t *testing.T) {
+	runner := useMockRunner(t)
+	useIdentity(t, 1000, nil, nil)
+	ctx := context.Background()
+
+	argv, err := privilegedCommand(ctx, "nft", "list", "ruleset")
+	if err != nil || strings.Join(argv, " ") != "nft list ruleset" {
+		t.Errorf("expected the command unchanged without sudo mode, got %v, %v", argv, err)
+	}
+
+	SetSudo(true)
+	for i := 0; i < 2; i++ {
+		argv, err = privilegedCommand(ctx, "nft", "list", "ruleset")
+		if err != nil || strings.Join(argv, " ") != "sudo -n -- nft list ruleset" {
+			t.Errorf("expected a sudo prefix, got %v, %v", argv, err)
+		}
+	}
+	if err := runner.ExpectCommands([]string{"sudo", "-v"}); err != nil {
+		t.Errorf("sudo should ask for the password once: %v", err)
+	}
+
+	SetSudo(true)
+	runner.Reset()
+	runner.Respond("", &MockExitError{Code: 1}, "sudo", "-v")
+	if _, err := privilegedCommand(ctx, "nft", "list", "ruleset"); err == nil || !strings.Contains(err.Error(), "sudo authentication failed") {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+
+	useIdentity(t, 0, nil, nil)
+	SetSudo(true)
+	runner.Reset()
+	argv, _ = privilegedCommand(ctx, "nft", "list", "ruleset")
+	if argv[0] != "nft" || len(runner.Commands) != 0 {
+		t.Errorf("root should not go through sudo, got %v and ran %v", argv, runner.Commands)
+	}
+}
+
+func TestRunNftWithSudo(t *testing.T) {
+	runner := useMockRunner(t)
+	useIdentity(t, 1000, nil, nil)
+
+	runner.Respond("Error: Could not process rule: Operation not permitted", &MockExitError{Code: 1}, "nft")
+	err := DeletePolicyRule(context.Background(), 7)
+	if err == nil || !strings.Contains(err.Error(), "--sudo") {
+		t.Errorf("expected a hint to use --sudo, got %v", err)
+	}
+
+	SetSudo(true)
+	runner.Reset()
+	if err := DeletePolicyRule(context.Background(), 7); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !runner.Ran("sudo", "-n", "--", "nft", "delete", "rule", PolicyTableFamily, PolicyTableName, PolicyChainName, "handle", "7") {
+		t.Errorf("expected nft to run through sudo, ran %v", runner.Commands)
+	}
+}
+
+func TestWithPrivilegeHint(t *testing.T) {
+	useIdentity(t, 1000, nil, nil)
+	base := errors.New("btrfs scrub status failed")
+
+	if err := withPrivilegeHint(base, "ERROR: getting dev info for scrub failed: Operation not permitted"); !errors.Is(err, base) || !strings.Contains(err.Error(), "root privileges") {
+		t.Errorf("expected a root privileges hint, got %v", err)
+	}
+	if err := withPrivilegeHint(base, "ERROR: not a btrfs filesystem"); err != base {
+		t.Errorf("unrelated failures should be returned unchanged, got %v", err)
+	}
+	SetSudo(true)
+	if err := withPrivilegeHint(base, "permission denied"); err != base {
+		t.Errorf("no hint expected in sudo mode, got %v", err)
+	}
+}
+
+func TestPermissionHint(t *testing.T) {
+	useIdentity(t, 1000, []string{"1000"}, []int{1000})
+	if hint := permissionHint(); !strings.Contains(hint, "usermod -aG lxd") {
+		t.Errorf("expected the group addition to be suggested, got %q", hint)
+	}
+
+	useIdentity(t, 1000, []string{"1000", "133"}, []int{1000})
+	if hint := permissionHint(); !strings.Contains(hint, "started before") || strings.Contains(hint, "usermod") {
+		t.Errorf("expected a new login session to be suggested, got %q", hint)
+	}
+
+	useIdentity(t, 1000, []string{"1000", "133"}, []int{1000, 133})
+	if configured, active := groupMembership("lxd"); !configured || !active {
+		t.Errorf("expected an active lxd membership, got %v, %v", configured, active)
+	}
+}
@@ -0,0 +1,235 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// ProjectEnvVar overrides the saved project, e.g. in CI
+const ProjectEnvVar = "LXC_GO_CLI_PROJECT"
+
+// DefaultProject is the project LXD puts instances in when none is chosen
+const DefaultProject = "default"
+
+// ProjectSourceFlag marks a project chosen with --project
+const ProjectSourceFlag = "flag"
+
+// projectNamePattern matches names LXD accepts for projects
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// projectFreeCommands are lxc subcommands that are not scoped to a project
+var projectFreeCommands = map[string]bool{
+	"project": true,
+	"remote":  true,
+	"version": true,
+}
+
+var (
+	projectMu     sync.RWMutex
+	activeProject string
+)
+
+// Project is an LXD project
+type Project struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	UsedBy      []string `json:"used_by"`
+}
+
+// Instances returns how many instances belong to the project
+func (p Project) Instances() int {
+	count := 0
+	for _, url := range p.UsedBy {
+		if strings.HasPrefix(url, "/1.0/instances/") {
+			count++
+		}
+	}
+	return count
+}
+
+// ValidateProjectName checks a project name
+func ValidateProjectName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name is required")
+	}
+	if !projectNamePattern.MatchString(name) {
+		return UsageErrorf("invalid project name '%s': use letters, digits, '.', '_' and '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// ResolveProject returns the project commands should target and where that
+// choice came from: --project, then LXC_GO_CLI_PROJECT, then the config file.
+// Empty means the project of the lxc client's remote, usually default.
+func ResolveProject(flag string, settings *Settings) (project, source string) {
+	if project := strings.TrimSpace(flag); project != "" {
+		return project, ProjectSourceFlag
+	}
+	if project := strings.TrimSpace(os.Getenv(ProjectEnvVar)); project != "" {
+		return project, ContextSourceEnv
+	}
+	if settings != nil && settings.Project != "" {
+		return settings.Project, ContextSourceConfig
+	}
+	return "", ""
+}
+
+// ActiveProject returns the project commands are run in; empty when none was chosen
+func ActiveProject() string {
+	projectMu.RLock()
+	defer projectMu.RUnlock()
+	return activeProject
+}
+
+// UseProject makes every lxc command run by this process target a project.
+// Like UseDriver it wraps the package runner; the project runner sits below
+// the Incus one so it sees the command lines incus will run. An empty name
+// removes the wrapper.
+func UseProject(name string) error {
+	if name != "" {
+		if err := ValidateProjectName(name); err != nil {
+			return err
+		}
+	}
+
+	projectMu.Lock()
+	activeProject = name
+	projectMu.Unlock()
+
+	current := Runner()
+	incus, isIncus := current.(*IncusRunner)
+	if isIncus {
+		current = incus.next
+	}
+	if wrapped, ok := current.(*ProjectRunner); ok {
+		current = wrapped.next
+	}
+	if name != "" {
+		current = &ProjectRunner{next: current, project: name}
+	}
+	if isIncus {
+		incus.next = current
+	} else {
+		SetRunner(current)
+	}
+	if name != "" {
+		logger.Debug("Using project '%s'", name)
+	}
+	return nil
+}
+
+// ProjectRunner adds a project to lxc and incus command lines and runs them
+// through another runner; other commands pass through unchanged
+type ProjectRunner struct {
+	next    CommandRunner
+	project string
+}
+
+// NewProjectRunner wraps next so lxc commands target project
+func NewProjectRunner(next CommandRunner, project string) *ProjectRunner {
+	return &ProjectRunner{next: next, project: project}
+}
+
+// Run runs a command in the runner's project
+func (r *ProjectRunner) Run(ctx context.Context, name string, args ...string) error {
+	return r.next.Run(ctx, name, ProjectCommand(name, args, r.project)...)
+}
+
+// RunWithOutput runs a command in the runner's project
+func (r *ProjectRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.next.RunWithOutput(ctx, name, ProjectCommand(name, args, r.project)...)
+}
+
+// RunStreaming runs a command in the runner's project
+func (r *ProjectRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	return r.next.RunStreaming(ctx, streams, name, ProjectCommand(name, args, r.project)...)
+}
+
+// ProjectCommand returns the arguments of an lxc or incus command line with
+// the project added:
+//   - 'query' is a raw API call, so the project goes into the URL
+//   - other subcommands get --project, placed before any '--' so it is not
+//     passed to a command run inside an instance
+func ProjectCommand(name string, args []string, project string) []string {
+	if (name != "lxc" && name != "incus") || project == "" || len(args) == 0 || projectFreeCommands[args[0]] {
+		return args
+	}
+
+	if args[0] == "query" {
+		translated := append([]string(nil), args...)
+		for i, arg := range translated {
+			if strings.HasPrefix(arg, "/1.0") {
+				translated[i] = projectURL(arg, project)
+				break
+			}
+		}
+		return translated
+	}
+
+	for _, arg := range args {
+		if arg == "--project" || strings.HasPrefix(arg, "--project=") {
+			return args
+		}
+	}
+	translated := make([]string, 0, len(args)+2)
+	for i, arg := range args {
+		if arg == "--" {
+			translated = append(translated, "--project", project)
+			return append(translated, args[i:]...)
+		}
+		translated = append(translated, arg)
+	}
+	return append(translated, "--project", project)
+}
+
+// projectURL adds the project parameter to an API path; projects themselves
+// are not scoped to one
+func projectURL(path, project string) string {
+	if strings.HasPrefix(path, "/1.0/projects") || strings.Contains(path, "project=") {
+		return path
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "project=" + project
+}
+
+// ListProjects returns the projects on the server, sorted by name
+func ListProjects(ctx context.Context) ([]Project, error) {
+	output, err := runOutput(ctx, "lxc", "project", "list", "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	var projects []Project
+	if err := json.Unmarshal(output, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse project list: %w", err)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+// CreateProject creates a project. Its instances, networks and storage
+// volumes are separate from other projects, but it shares images and profiles
+// with the default project so containers are created from the same images
+// and with the same root disk and network as everywhere else.
+func CreateProject(ctx context.Context, name, description string) error {
+	args := []string{"project", "create", name, "-c", "features.images=false", "-c", "features.profiles=false"}
+	if err := runLXC(ctx, args...); err != nil {
+		return fmt.Errorf("failed to create project '%s': %w", name, err)
+	}
+	if description != "" {
+		if err := runLXC(ctx, "project", "set", name, "--property", "description="+description); err != nil {
+			return fmt.Errorf("failed to set the description of project '%s': %w", name, err)
+		}
+	}
+	return nil
+}
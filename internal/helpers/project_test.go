@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestProjectCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"lxc", []string{"list", "--format", "json"}, []string{"list", "--format", "json", "--project", "team"}},
+		{"lxc", []string{"exec", "web", "--", "ls", "-l"}, []string{"exec", "web", "--project", "team", "--", "ls", "-l"}},
+		{"incus", []string{"launch", "images:ubuntu/24.04/cloud", "web"}, []string{"launch", "images:ubuntu/24.04/cloud", "web", "--project", "team"}},
+		{"lxc", []string{"query", "/1.0/instances/web"}, []string{"query", "/1.0/instances/web?project=team"}},
+		{"lxc", []string{"query", "/1.0/images?recursion=1"}, []string{"query", "/1.0/images?recursion=1&project=team"}},
+		{"lxc", []string{"query", "-X", "POST", "/1.0/images", "--data", "{}"}, []string{"query", "-X", "POST", "/1.0/images?project=team", "--data", "{}"}},
+		{"lxc", []string{"query", "/1.0/projects"}, []string{"query", "/1.0/projects"}},
+		{"lxc", []string{"list", "--project", "other"}, []string{"list", "--project", "other"}},
+		{"lxc", []string{"project", "list"}, []string{"project", "list"}},
+		{"lxc", []string{"remote", "list"}, []string{"remote", "list"}},
+		{"ss", []string{"-H", "-ltnp"}, []string{"-H", "-ltnp"}},
+	}
+	for _, tt := range tests {
+		if got := ProjectCommand(tt.name, tt.args, "team"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ProjectCommand(%s %v) = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+
+	// The caller's argv is left alone
+	args := []string{"query", "/1.0/instances/web"}
+	ProjectCommand("lxc", args, "team")
+	if args[1] != "/1.0/instances/web" {
+		t.Errorf("expected the original args to be unchanged, got %v", args)
+	}
+}
+
+func TestResolveProject(t *testing.T) {
+	t.Setenv(ProjectEnvVar, "")
+	settings := &Settings{Project: "saved"}
+
+	if project, source := ResolveProject("", nil); project != "" || source != "" {
+		t.Errorf("expected no project, got %q from %q", project, source)
+	}
+	if project, source := ResolveProject("", settings); project != "saved" || source != ContextSourceConfig {
+		t.Errorf("expected the saved project, got %q from %q", project, source)
+	}
+	t.Setenv(ProjectEnvVar, "ci")
+	if project, source := ResolveProject("", settings); project != "ci" || source != ContextSourceEnv {
+		t.Errorf("expected the environment to win over the config, got %q from %q", project, source)
+	}
+	if project, source := ResolveProject("team", settings); project != "team" || source != ProjectSourceFlag {
+		t.Errorf("expected the flag to win, got %q from %q", project, source)
+	}
+}
+
+func TestUseProject(t *testing.T) {
+	runner := useMockRunner(t)
+	t.Cleanup(func() { UseProject("") })
+
+	if err := UseProject("bad name"); err == nil {
+		t.Error("expected an invalid project name to be rejected")
+	}
+	if err := UseProject("team"); err != nil {
+		t.Fatalf("UseProject failed: %v", err)
+	}
+	// Switching again replaces the wrapper rather than stacking another one
+	if err := UseProject("team"); err != nil {
+		t.Fatalf("UseProject failed: %v", err)
+	}
+	if ActiveProject() != "team" {
+		t.Errorf("expected team to be active, got %q", ActiveProject())
+	}
+	StartContainer("web")
+	if err := runner.ExpectCommands([]string{"lxc", "start", "web", "--project", "team"}); err != nil {
+		t.Error(err)
+	}
+
+	// Incus command lines get the project too
+	useDriver(t, DriverIncus)
+	runner.Commands = nil
+	StartContainer("web")
+	if err := runner.ExpectCommands([]string{"incus", "start", "web", "--project", "team"}); err != nil {
+		t.Error(err)
+	}
+
+	if err := UseProject(""); err != nil {
+		t.Fatal(err)
+	}
+	runner.Commands = nil
+	StartContainer("web")
+	if err := runner.ExpectCommands([]string{"incus", "start", "web"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`[{"name": "team", "description": "Team A", "used_by": ["/1.0/instances/web?project=team", "/1.0/profiles/default"]}, {"name": "default", "used_by": []}]`,
+		nil, "lxc", "project", "list", "--format", "json")
+
+	projects, err := ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 2 || projects[0].Name != "default" || projects[1].Instances() != 1 {
+		t.Errorf("unexpected projects %+v", projects)
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := CreateProject(context.Background(), "team", "Team A"); err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if err := runner.ExpectCommands(
+		[]string{"lxc", "project", "create", "team", "-c", "features.images=false", "-c", "features.profiles=false"},
+		[]string{"lxc", "project", "set", "team", "--property", "description=Team A"},
+	); err != nil {
+		t.Error(err)
+	}
+}
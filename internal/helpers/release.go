@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// releaseContainerResources removes what the tool set up for a container
+// outside of LXD once the container is deleted: its password in the password
+// store, stored secrets, reverse proxy sites, certificates, cron entries and firewall ports.
+// owner is the name these were set up under, the original name of a trashed
+// container. Resources found by name are kept when a container of that name
+// exists again, as they belong to it now. The container is gone either way,
+// so failures only warn.
+func releaseContainerResources(ctx context.Context, owner string, config ContainerConfig) {
+	states, err := ListContainers()
+	if err != nil {
+		logger.Warn("Could not list containers; keeping the password, secrets, reverse proxy sites, certificates, snapshot schedule and firewall ports of '%s': %v", owner, err)
+		return
+	}
+	if rules := SplitConfigList(config.Config[ExposedPortsKey]); len(rules) > 0 {
+		if err := closeExposedPorts(ctx, states, rules); err != nil {
+			logger.Warn("Could not close the firewall ports of '%s': %v", owner, err)
+		}
+	}
+	if containerNamed(states, owner) {
+		logger.Warn("Keeping the password, secrets, reverse proxy sites, certificates and snapshot schedule set up under '%s'; they belong to the container now named so", owner)
+		return
+	}
+
+	// Deleting a Vault secret destroys all of its versions
+	if err := DeleteContainerPassword(ctx, owner); err != nil {
+		logger.Warn("Could not delete the password of '%s': %v", owner, err)
+	}
+	if err := DeleteContainerSecrets(owner); err != nil {
+		logger.Warn("Could not delete the secrets of '%s': %v", owner, err)
+	}
+	if err := removeProxySites(owner); err != nil {
+		logger.Warn("Could not remove the reverse proxy sites of '%s': %v", owner, err)
+	}
+	if err := removeCertRecords(owner); err != nil {
+		logger.Warn("Could not remove the certificates of '%s': %v", owner, err)
+	}
+	if err := RemoveSnapshotCronEntry(owner); err != nil {
+		logger.Warn("Could not remove the snapshot schedule of '%s': %v", owner, err)
+	}
+}
+
+// containerNamed reports whether one of states is named name
+func containerNamed(states []ContainerState, name string) bool {
+	for _, state := range states {
+		if state.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// proxySiteContainer returns the container a generated Caddy site was
+// written for, from its header line
+func proxySiteContainer(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	name, ok := strings.CutPrefix(strings.TrimSpace(line), "# Generated by lxc-go-cli expose for container '")
+	if !ok {
+		return "", nil
+	}
+	return strings.TrimSuffix(name, "'"), nil
+}
+
+// removeProxySites removes the Caddy sites of a container and the
+// self-signed certificates of their domains
+func removeProxySites(containerName string) error {
+	sites, err := filepath.Glob(filepath.Join(ProxyConfigDir, "*.caddy"))
+	if err != nil {
+		return err
+	}
+	for _, site := range sites {
+		owner, err := proxySiteContainer(site)
+		if err != nil {
+			return err
+		}
+		if owner != containerName {
+			continue
+		}
+		domain := strings.TrimSuffix(filepath.Base(site), ".caddy")
+		for _, file := range []string{
+			site,
+			filepath.Join(ProxyConfigDir, "certs", domain+".crt"),
+			filepath.Join(ProxyConfigDir, "certs", domain+".key"),
+		} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return withProxyDirHint(err)
+			}
+		}
+		logger.Info("Removed the reverse proxy site of '%s'; reload Caddy to stop serving it", domain)
+	}
+	return nil
+}
+
+// removeCertRecords forgets the certificates issued for a container, with
+// their lego state, host renewal cron entries and challenge webroot
+func removeCertRecords(containerName string) error {
+	records, err := ListCertRecords()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Container != containerName {
+			continue
+		}
+		// Container-mode entries were inside the container
+		if record.Mode != ACMEModeContainer {
+			cron := filepath.Join(CronDir, certCronFile(record.Domain))
+			if err := os.Remove(cron); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove cron entry %s: %w", cron, err)
+			}
+		}
+		if err := os.RemoveAll(ACMEDir(record.Domain)); err != nil {
+			return fmt.Errorf("failed to remove the certificate state of '%s': %w", record.Domain, err)
+		}
+		logger.Info("Removed the certificate of '%s'", record.Domain)
+	}
+	if SettingsDir == "" {
+		return nil
+	}
+	return os.RemoveAll(challengeWebroot(containerName))
+}
+
+// closeExposedPorts closes the firewall ports 'expose' opened for a deleted
+// container, except those a remaining container still exposes, such as
+// 80/tcp shared by the reverse proxy sites of several containers
+func closeExposedPorts(ctx context.Context, states []ContainerState, rules []string) error {
+	inUse := make(map[string]bool)
+	for _, state := range states {
+		for _, rule := range SplitConfigList(state.Config[ExposedPortsKey]) {
+			inUse[rule] = true
+		}
+	}
+
+	firewall, err := DetectFirewall(ctx)
+	if err != nil || firewall == "" {
+		return err
+	}
+	for _, rule := range rules {
+		if inUse[rule] {
+			logger.Debug("Keeping %s open; another container still exposes it", rule)
+			continue
+		}
+		if err := CloseFirewallPort(ctx, firewall, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useHostDirs points the host files the tool writes at temporary directories
+func useHostDirs(t *testing.T) {
+	t.Helper()
+	previousSettings, previousProxy, previousCron := SettingsDir, ProxyConfigDir, CronDir
+	t.Cleanup(func() { SettingsDir, ProxyConfigDir, CronDir = previousSettings, previousProxy, previousCron })
+	SettingsDir, ProxyConfigDir, CronDir = t.TempDir(), t.TempDir(), t.TempDir()
+}
+
+func TestReleaseContainerResources(t *testing.T) {
+	useHostDirs(t)
+	previousLookPath := lookPath
+	t.Cleanup(func() { lookPath = previousLookPath })
+	lookPath = func(file string) (string, error) {
+		if file == "ufw" {
+			return "/usr/sbin/ufw", nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	for _, site := range []ProxySite{
+		{Container: "web", Domain: "app.local", Upstream: 8080, TLS: TLSSelfSigned},
+		{Container: "api", Domain: "api.local", Upstream: 8081, TLS: TLSNone},
+	} {
+		if _, err := WriteProxySite(site); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keyFile := filepath.Join(ProxyConfigDir, "certs", "app.local.key")
+	if err := writeFileAtomic(keyFile, []byte("key"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []*CertRecord{
+		{Container: "web", Domain: "app.example.com", Mode: ACMEModeHost},
+		{Container: "api", Domain: "api.example.com", Mode: ACMEModeHost},
+	} {
+		if err := SaveCertRecord(record); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(CronDir, certCronFile(record.Domain)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshotCron := cronEntryPath("web")
+	if err := os.WriteFile(snapshotCron, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{secretFile("web", "DB_PASSWORD"), secretFile("api", "DB_PASSWORD")} {
+		if err := writeFileAtomic(file, []byte("ciphertext"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock := useMockRunner(t)
+	mock.Respond(`[{"name":"api","status":"Running","config":{"`+ExposedPortsKey+`":"80/tcp"}}]`, nil, "lxc", "list", "--format", "json")
+	mock.Respond("Status: active\n", nil, "ufw", "status")
+
+	config := ContainerConfig{Config: map[string]string{ExposedPortsKey: "80/tcp,443/tcp"}}
+	releaseContainerResources(context.Background(), "web", config)
+
+	for _, gone := range []string{
+		filepath.Join(ProxyConfigDir, "app.local.caddy"),
+		keyFile,
+		ACMEDir("app.example.com"),
+		filepath.Join(CronDir, certCronFile("app.example.com")),
+		snapshotCron,
+		filepath.Join(secretsDir(), "web"),
+	} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", gone, err)
+		}
+	}
+	for _, kept := range []string{
+		filepath.Join(ProxyConfigDir, "api.local.caddy"),
+		ACMEDir("api.example.com"),
+		filepath.Join(CronDir, certCronFile("api.example.com")),
+		secretFile("api", "DB_PASSWORD"),
+	} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("expected %s of another container to be kept, got %v", kept, err)
+		}
+	}
+
+	// 80/tcp is still exposed by api
+	if !mock.Ran("ufw", "delete", "allow", "443/tcp") {
+		t.Errorf("expected 443/tcp to be closed, ran %v", mock.Commands)
+	}
+	for _, argv := range mock.Commands {
+		if strings.Join(argv, " ") == "ufw delete allow 80/tcp" {
+			t.Error("expected 80/tcp to stay open for api")
+		}
+	}
+}
+
+func TestDeleteTrashedContainerKeepsResourcesOfNewOwner(t *testing.T) {
+	useHostDirs(t)
+	previousLookPath := lookPath
+	t.Cleanup(func() { lookPath = previousLookPath })
+	lookPath = func(file string) (string, error) {
+		if file == "ufw" {
+			return "/usr/sbin/ufw", nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	// web was trashed, then created again and exposed on 443/tcp
+	if _, err := WriteProxySite(ProxySite{Container: "web", Domain: "app.local", Upstream: 8080, TLS: TLSNone}); err != nil {
+		t.Fatal(err)
+	}
+	record := &CertRecord{Container: "web", Domain: "app.example.com", Mode: ACMEModeHost}
+	if err := SaveCertRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	snapshotCron := cronEntryPath("web")
+	if err := os.WriteFile(snapshotCron, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(secretFile("web", "DB_PASSWORD"), []byte("ciphertext"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := useMockRunner(t)
+	mock.Respond("config:\n  "+TrashOriginalKey+": web\n  "+ExposedPortsKey+": 443/tcp,8443/tcp\ndevices: {}\n", nil,
+		"lxc", "config", "show", "trash-20250301-web")
+	mock.Respond(`[{"name":"web","status":"Running","config":{"`+ExposedPortsKey+`":"443/tcp"}}]`, nil, "lxc", "list", "--format", "json")
+	mock.Respond("Status: active\n", nil, "ufw", "status")
+
+	if err := DeleteContainer("trash-20250301-web", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, kept := range []string{
+		filepath.Join(ProxyConfigDir, "app.local.caddy"),
+		ACMEDir("app.example.com"),
+		snapshotCron,
+		secretFile("web", "DB_PASSWORD"),
+	} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("expected %s of the new 'web' to be kept, got %v", kept, err)
+		}
+	}
+	if !mock.Ran("ufw", "delete", "allow", "8443/tcp") {
+		t.Errorf("expected 8443/tcp to be closed, ran %v", mock.Commands)
+	}
+	for _, argv := range mock.Commands {
+		if strings.Join(argv, " ") == "ufw delete allow 443/tcp" {
+			t.Error("expected 443/tcp to stay open for the new 'web'")
+		}
+	}
+}
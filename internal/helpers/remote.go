@@ -0,0 +1,217 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// LocalRemote is the name of the lxc remote for the LXD daemon on this machine
+const LocalRemote = "local"
+
+// Remote is an LXD server known to the lxc client
+type Remote struct {
+	Name     string `json:"name"`
+	Addr     string `json:"addr"`
+	Protocol string `json:"protocol"`
+	AuthType string `json:"auth_type"`
+	Public   bool   `json:"public"`
+	Static   bool   `json:"static"`
+	Default  bool   `json:"default"`
+}
+
+// IsLocal reports whether the remote talks to a daemon on this machine over its unix socket
+func (r Remote) IsLocal() bool {
+	return r.Addr == "" || strings.HasPrefix(r.Addr, "unix:")
+}
+
+// RemoteAddOptions controls how a remote is added and trusted
+type RemoteAddOptions struct {
+	// AcceptCertificate trusts the server certificate without prompting
+	AcceptCertificate bool
+	// Token is a trust token created on the server with 'lxc config trust add'
+	Token string
+}
+
+// ValidateRemoteName checks that a remote name can be used as a "remote:" prefix
+func ValidateRemoteName(name string) error {
+	if name == "" {
+		return fmt.Errorf("remote name is required")
+	}
+	if strings.ContainsAny(name, ":/ ") {
+		return UsageErrorf("invalid remote name '%s': must not contain ':', '/' or spaces", name)
+	}
+	return nil
+}
+
+// ValidateRemoteURL checks a remote address; a bare host is accepted and an
+// explicit scheme must be https
+func ValidateRemoteURL(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("remote URL is required")
+	}
+	if !strings.Contains(addr, "://") {
+		return nil
+	}
+	parsed, err := url.Parse(addr)
+	if err != nil {
+		return UsageErrorf("invalid remote URL '%s': %w", addr, err)
+	}
+	if parsed.Scheme != "https" {
+		return UsageErrorf("invalid remote URL '%s': remote LXD servers are reached over https", addr)
+	}
+	if parsed.Host == "" {
+		return UsageErrorf("invalid remote URL '%s': missing host", addr)
+	}
+	return nil
+}
+
+// RemoteAddArgs builds the lxc arguments that add a remote
+func RemoteAddArgs(name, addr string, opts RemoteAddOptions) []string {
+	args := []string{"remote", "add", name, addr}
+	if opts.AcceptCertificate {
+		args = append(args, "--accept-certificate")
+	}
+	if opts.Token != "" {
+		args = append(args, "--token", opts.Token)
+	}
+	return args
+}
+
+// AddRemote adds an LXD server as a remote. Without a token lxc prompts for
+// one, so the command is connected to the given streams.
+func AddRemote(ctx context.Context, name, addr string, opts RemoteAddOptions, streams Streams) error {
+	args := RemoteAddArgs(name, addr, opts)
+	// Do not log the token
+	logger.Debug("Adding remote: lxc remote add %s %s", name, addr)
+
+	if err := Runner().RunStreaming(ctx, streams, "lxc", args...); err != nil {
+		return fmt.Errorf("failed to add remote '%s': %w", name, err)
+	}
+	return nil
+}
+
+// RemoveRemote removes a remote from the lxc client configuration
+func RemoveRemote(ctx context.Context, name string) error {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "remote", "remove", name)
+	if err != nil {
+		return fmt.Errorf("failed to remove remote '%s': %w (output: %s)", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SwitchRemote makes a remote the default, so every lxc command and therefore
+// every command of this tool operates against it
+func SwitchRemote(ctx context.Context, name string) error {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "remote", "switch", name)
+	if err != nil {
+		return fmt.Errorf("failed to switch to remote '%s': %w (output: %s)", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetDefaultRemote returns the name of the remote lxc commands operate against
+func GetDefaultRemote(ctx context.Context) (string, error) {
+	output, err := runOutput(ctx, "lxc", "remote", "get-default")
+	if err != nil {
+		return "", fmt.Errorf("failed to get default remote: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ListRemotes returns the remotes known to the lxc client, sorted by name
+func ListRemotes(ctx context.Context) ([]Remote, error) {
+	output, err := runOutput(ctx, "lxc", "remote", "list", "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	defaultRemote, err := GetDefaultRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseRemotes(output, defaultRemote)
+}
+
+// lxcRemote mirrors an entry of `lxc remote list --format json`, which uses Go field names as keys
+type lxcRemote struct {
+	Addr     string
+	AuthType string
+	Protocol string
+	Public   bool
+	Static   bool
+}
+
+// parseRemotes parses `lxc remote list --format json`, a map keyed by remote name
+func parseRemotes(data []byte, defaultRemote string) ([]Remote, error) {
+	var byName map[string]lxcRemote
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("failed to parse remote list: %w", err)
+	}
+
+	remotes := make([]Remote, 0, len(byName))
+	for name, remote := range byName {
+		remotes = append(remotes, Remote{
+			Name:     name,
+			Addr:     remote.Addr,
+			Protocol: remote.Protocol,
+			AuthType: remote.AuthType,
+			Public:   remote.Public,
+			Static:   remote.Static,
+			Default:  name == defaultRemote,
+		})
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// CreateTrustToken creates a token on the current server that lets a client
+// with the given name add it as a remote
+func CreateTrustToken(ctx context.Context, clientName string) (string, error) {
+	output, err := Runner().RunWithOutput(ctx, "lxc", "config", "trust", "add", "--name", clientName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create trust token: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return parseTrustToken(string(output))
+}
+
+// parseTrustToken extracts the token from `lxc config trust add` output,
+// which prints a short message followed by the token on its own line
+func parseTrustToken(output string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	token := strings.TrimSpace(lines[len(lines)-1])
+	if token == "" || strings.Contains(token, " ") {
+		return "", fmt.Errorf("no trust token in output: %s", strings.TrimSpace(output))
+	}
+	return token, nil
+}
+
+// RequireLocalServer fails when the default remote is a remote server. Host
+// tools such as btrfs and nft only affect this machine, so running them while
+// lxc points elsewhere would change the wrong host.
+func RequireLocalServer(ctx context.Context, operation string) error {
+	name, err := GetDefaultRemote(ctx)
+	if err != nil {
+		// Without a working lxc client there is no remote to point at
+		logger.Debug("Could not determine default remote, assuming local: %v", err)
+		return nil
+	}
+	if name == LocalRemote {
+		return nil
+	}
+
+	remotes, err := ListRemotes(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, remote := range remotes {
+		if remote.Name == name && !remote.IsLocal() {
+			return WithKind(fmt.Errorf("%s needs to run on the LXD host, but the default remote is '%s' (%s); run it on that host or switch back with 'lxc-go-cli remote use local'", operation, name, remote.Addr), ErrEnvironment)
+		}
+	}
+	return nil
+}
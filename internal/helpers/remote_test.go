@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const remoteListJSON = `{
+  "images": {"Addr": "https://images.linuxcontainers.org", "AuthType": "", "Protocol": "simplestreams", "Public": true, "Static": false},
+  "local": {"Addr": "unix://", "AuthType": "file access", "Protocol": "lxd", "Public": false, "Static": true},
+  "mylab": {"Addr": "https://lab:8443", "AuthType": "tls", "Protocol": "lxd", "Public": false, "Static": false}
+}`
+
+func TestParseRemotes(t *testing.T) {
+	remotes, err := parseRemotes([]byte(remoteListJSON), "mylab")
+	if err != nil {
+		t.Fatalf("parseRemotes failed: %v", err)
+	}
+	if len(remotes) != 3 {
+		t.Fatalf("Expected 3 remotes, got %d", len(remotes))
+	}
+	if remotes[0].Name != "images" || remotes[1].Name != "local" || remotes[2].Name != "mylab" {
+		t.Errorf("Expected remotes sorted by name, got %v", remotes)
+	}
+	if !remotes[2].Default || remotes[1].Default {
+		t.Error("Expected only mylab to be the default")
+	}
+	if !remotes[1].IsLocal() || remotes[2].IsLocal() {
+		t.Error("Expected only local to talk to the unix socket")
+	}
+	if remotes[2].AuthType != "tls" || !remotes[1].Static {
+		t.Errorf("Unexpected fields: %+v", remotes)
+	}
+
+	if _, err := parseRemotes([]byte("not json"), "local"); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestValidateRemote(t *testing.T) {
+	for _, name := range []string{"mylab", "lab-2"} {
+		if err := ValidateRemoteName(name); err != nil {
+			t.Errorf("ValidateRemoteName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "my:lab", "a/b", "my lab"} {
+		if err := ValidateRemoteName(name); err == nil {
+			t.Errorf("ValidateRemoteName(%q) should fail", name)
+		}
+	}
+
+	for _, addr := range []string{"https://host:8443", "host", "10.0.0.5:8443"} {
+		if err := ValidateRemoteURL(addr); err != nil {
+			t.Errorf("ValidateRemoteURL(%q) failed: %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"", "http://host:8443", "unix:///var/snap/lxd/common/lxd/unix.socket", "https://"} {
+		if err := ValidateRemoteURL(addr); err == nil {
+			t.Errorf("ValidateRemoteURL(%q) should fail", addr)
+		}
+	}
+}
+
+func TestRemoteAddArgs(t *testing.T) {
+	got := RemoteAddArgs("mylab", "https://lab:8443", RemoteAddOptions{AcceptCertificate: true, Token: "abc"})
+	want := "remote add mylab https://lab:8443 --accept-certificate --token abc"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected %q, got %q", want, strings.Join(got, " "))
+	}
+
+	got = RemoteAddArgs("mylab", "lab", RemoteAddOptions{})
+	if strings.Join(got, " ") != "remote add mylab lab" {
+		t.Errorf("Unexpected args without options: %v", got)
+	}
+}
+
+func TestParseTrustToken(t *testing.T) {
+	output := "Client my-laptop certificate add token:\neyJjbGllbnRfbmFtZSI6Im15LWxhcHRvcCJ9\n"
+	token, err := parseTrustToken(output)
+	if err != nil || token != "eyJjbGllbnRfbmFtZSI6Im15LWxhcHRvcCJ9" {
+		t.Errorf("parseTrustToken = %q, %v", token, err)
+	}
+	if _, err := parseTrustToken("Error: not authorized"); err == nil {
+		t.Error("Expected error when no token is printed")
+	}
+}
+
+func TestRequireLocalServer(t *testing.T) {
+	ctx := context.Background()
+	runner := useMockRunner(t)
+	runner.Respond(remoteListJSON, nil, "lxc", "remote", "list")
+
+	runner.Respond("local\n", nil, "lxc", "remote", "get-default")
+	if err := RequireLocalServer(ctx, "btrfs quota"); err != nil {
+		t.Errorf("Expected local remote to pass, got %v", err)
+	}
+
+	runner.Respond("mylab\n", nil, "lxc", "remote", "get-default")
+	err := RequireLocalServer(ctx, "btrfs quota")
+	if err == nil || !strings.Contains(err.Error(), "mylab") {
+		t.Errorf("Expected remote server error, got %v", err)
+	}
+
+	// Host tools refuse to run against a remote
+	if _, err := runBtrfsCommand("default", "quota", "enable"); err == nil {
+		t.Error("Expected btrfs to refuse to run while a remote is the default")
+	}
+	if runner.Ran("btrfs", "quota", "enable") {
+		t.Error("btrfs should not have run")
+	}
+
+	runner.Respond("", &MockExitError{Code: 1}, "lxc", "remote", "get-default")
+	if err := RequireLocalServer(ctx, "btrfs quota"); err != nil {
+		t.Errorf("Expected a missing lxc client to be treated as local, got %v", err)
+	}
+}
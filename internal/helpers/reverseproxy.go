@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// TLS modes of an exposed site
+const (
+	TLSNone       = "none"
+	TLSSelfSigned = "self-signed"
+	TLSACME       = "acme"
+)
+
+// selfSignedValidity is how long generated certificates are valid for
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// domainPattern matches host names such as app.local or app.example.com
+var domainPattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// ProxySite is a domain served by the host's reverse proxy and forwarded to
+// a port on the host
+type ProxySite struct {
+	Container string
+	Domain    string
+	// Upstream is the host port the site is forwarded to
+	Upstream int
+	TLS      string
+	// ACMEEmail is the contact for ACME accounts; optional
+	ACMEEmail string
+	// CertFile and KeyFile hold the self-signed certificate
+	CertFile string
+	KeyFile  string
+}
+
+// ValidateDomain checks a domain name for a proxy site
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return UsageErrorf("domain is required")
+	}
+	if len(domain) > 253 || !domainPattern.MatchString(domain) {
+		return UsageErrorf("invalid domain '%s'", domain)
+	}
+	return nil
+}
+
+// ValidateTLSMode checks a --tls value
+func ValidateTLSMode(mode string) error {
+	switch mode {
+	case TLSNone, TLSSelfSigned, TLSACME:
+		return nil
+	}
+	return UsageErrorf("invalid TLS mode '%s': must be '%s', '%s' or '%s'", mode, TLSNone, TLSSelfSigned, TLSACME)
+}
+
+// ProxyConfigDir holds generated reverse proxy sites and certificates, where
+// the caddy service can read them; a variable so tests can redirect it
+var ProxyConfigDir = "/etc/caddy/lxc-go-cli"
+
+// ProxyGroup is the group the caddy service runs as; private keys are made
+// readable by it
+const ProxyGroup = "caddy"
+
+// RenderCaddySite renders a Caddy site block for a proxy site. Caddy obtains
+// ACME certificates itself; self-signed ones are generated beforehand.
+func RenderCaddySite(site ProxySite) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by lxc-go-cli expose for container '%s'\n", site.Container)
+	address := site.Domain
+	if site.TLS == TLSNone {
+		address = "http://" + site.Domain
+	}
+	fmt.Fprintf(&sb, "%s {\n", address)
+	switch site.TLS {
+	case TLSSelfSigned:
+		fmt.Fprintf(&sb, "\ttls %s %s\n", site.CertFile, site.KeyFile)
+	case TLSACME:
+		if site.ACMEEmail != "" {
+			fmt.Fprintf(&sb, "\ttls %s\n", site.ACMEEmail)
+		}
+	}
+	fmt.Fprintf(&sb, "\treverse_proxy 127.0.0.1:%d\n", site.Upstream)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// WriteProxySite writes the Caddy site of a domain into ProxyConfigDir and
+// returns its path
+func WriteProxySite(site ProxySite) (string, error) {
+	path := filepath.Join(ProxyConfigDir, site.Domain+".caddy")
+	if err := writeFileAtomic(path, []byte(RenderCaddySite(site)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write reverse proxy config: %w", withProxyDirHint(err))
+	}
+	return path, nil
+}
+
+// withProxyDirHint explains a permission error writing under ProxyConfigDir
+func withProxyDirHint(err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%w (writing to %s needs root; run expose with sudo)", err, ProxyConfigDir)
+	}
+	return err
+}
+
+// shareWithProxyGroup gives the ProxyGroup group ownership of a file, so the
+// caddy service can read it; without the group it warns and leaves the file
+func shareWithProxyGroup(path string) error {
+	gid, err := lookupGroupID(ProxyGroup)
+	if err != nil {
+		logger.Warn("No '%s' group found; install Caddy, then run expose again so it can read %s", ProxyGroup, path)
+		return nil
+	}
+	id, err := strconv.Atoi(gid)
+	if err != nil {
+		return fmt.Errorf("invalid group id '%s' of '%s'", gid, ProxyGroup)
+	}
+	return os.Chown(path, -1, id)
+}
+
+// EnsureSelfSignedCert returns the certificate and key files for a domain,
+// generating a self-signed pair when there is none or it has expired
+func EnsureSelfSignedCert(domain string) (certFile, keyFile string, err error) {
+	dir := filepath.Join(ProxyConfigDir, "certs")
+	certFile = filepath.Join(dir, domain+".crt")
+	keyFile = filepath.Join(dir, domain+".key")
+
+	if data, err := os.ReadFile(certFile); err == nil && certValidFor(data, domain, time.Now()) {
+		if _, err := os.Stat(keyFile); err == nil {
+			logger.Debug("Reusing the certificate for '%s' in %s", domain, certFile)
+			// Caddy may have been installed since the key was written
+			if err := shareWithProxyGroup(keyFile); err != nil {
+				return "", "", fmt.Errorf("failed to share the key for '%s' with Caddy: %w", domain, withProxyDirHint(err))
+			}
+			return certFile, keyFile, nil
+		}
+	}
+
+	certPEM, keyPEM, err := GenerateSelfSignedCert(domain, time.Now(), selfSignedValidity)
+	if err != nil {
+		return "", "", err
+	}
+	// The key is readable by the caddy group only
+	if err := writeFileAtomic(keyFile, keyPEM, 0640); err != nil {
+		return "", "", fmt.Errorf("failed to write the key for '%s': %w", domain, withProxyDirHint(err))
+	}
+	if err := shareWithProxyGroup(keyFile); err != nil {
+		return "", "", fmt.Errorf("failed to share the key for '%s' with Caddy: %w", domain, withProxyDirHint(err))
+	}
+	if err := writeFileAtomic(certFile, certPEM, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write the certificate for '%s': %w", domain, withProxyDirHint(err))
+	}
+	logger.Info("Generated a self-signed certificate for '%s', valid until %s", domain, time.Now().Add(selfSignedValidity).Format("2006-01-02"))
+	return certFile, keyFile, nil
+}
+
+// GenerateSelfSignedCert creates a PEM encoded certificate and ECDSA key for
+// a domain, valid from notBefore for the given duration
+func GenerateSelfSignedCert(domain string, notBefore time.Time, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: domain, Organization: []string{"lxc-go-cli"}},
+		DNSNames:              []string{domain},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// certValidFor reports whether a PEM certificate covers domain at now
+func certValidFor(data []byte, domain string, now time.Time) bool {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	return now.After(cert.NotBefore) && now.Before(cert.NotAfter) && cert.VerifyHostname(domain) == nil
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateDomain(t *testing.T) {
+	for _, domain := range []string{"app.local", "app.example.com", "localhost"} {
+		if err := ValidateDomain(domain); err != nil {
+			t.Errorf("expected %q to be valid, got %v", domain, err)
+		}
+	}
+	for _, domain := range []string{"", "-app.local", "app..local", "app local", "https://app.local"} {
+		if err := ValidateDomain(domain); err == nil {
+			t.Errorf("expected %q to be rejected", domain)
+		}
+	}
+}
+
+func TestRenderCaddySite(t *testing.T) {
+	site := ProxySite{Container: "web", Domain: "app.local", Upstream: 8080, TLS: TLSNone}
+	if config := RenderCaddySite(site); !strings.Contains(config, "http://app.local {") || !strings.Contains(config, "reverse_proxy 127.0.0.1:8080") {
+		t.Errorf("unexpected plain HTTP site:\n%s", config)
+	}
+
+	site.TLS, site.CertFile, site.KeyFile = TLSSelfSigned, "/etc/app.crt", "/etc/app.key"
+	if config := RenderCaddySite(site); !strings.Contains(config, "app.local {") || strings.Contains(config, "http://") ||
+		!strings.Contains(config, "tls /etc/app.crt /etc/app.key") {
+		t.Errorf("unexpected self-signed site:\n%s", config)
+	}
+
+	site = ProxySite{Container: "web", Domain: "app.example.com", Upstream: 8080, TLS: TLSACME, ACMEEmail: "ops@example.com"}
+	if config := RenderCaddySite(site); !strings.Contains(config, "tls ops@example.com") {
+		t.Errorf("unexpected ACME site:\n%s", config)
+	}
+}
+
+func TestEnsureSelfSignedCert(t *testing.T) {
+	previousDir, previousLookup := ProxyConfigDir, lookupGroupID
+	t.Cleanup(func() { ProxyConfigDir, lookupGroupID = previousDir, previousLookup })
+	ProxyConfigDir = t.TempDir()
+	var looked []string
+	lookupGroupID = func(name string) (string, error) {
+		looked = append(looked, name)
+		return strconv.Itoa(os.Getgid()), nil
+	}
+
+	certFile, keyFile, err := EnsureSelfSignedCert("app.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(certFile) != filepath.Join(ProxyConfigDir, "certs") {
+		t.Errorf("unexpected certificate path %s", certFile)
+	}
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !certValidFor(data, "app.local", time.Now()) {
+		t.Error("expected the certificate to be valid for app.local")
+	}
+	if certValidFor(data, "other.local", time.Now()) {
+		t.Error("expected the certificate not to cover other.local")
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("expected a key file readable by its group, got %v, %v", info, err)
+	}
+	if len(looked) == 0 || looked[0] != ProxyGroup {
+		t.Errorf("expected the key to be shared with the %s group, looked up %v", ProxyGroup, looked)
+	}
+
+	// A valid certificate is kept
+	if _, _, err := EnsureSelfSignedCert("app.local"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(certFile); string(again) != string(data) {
+		t.Error("expected the existing certificate to be reused")
+	}
+
+	// An expired one is replaced
+	expired, _, err := GenerateSelfSignedCert("app.local", time.Now().Add(-48*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, expired, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := EnsureSelfSignedCert("app.local"); err != nil {
+		t.Fatal(err)
+	}
+	if renewed, _ := os.ReadFile(certFile); string(renewed) == string(expired) {
+		t.Error("expected the expired certificate to be replaced")
+	}
+}
@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// CommandRunner runs host commands. Every helper that shells out goes through
+// the package runner, so tests can intercept each command and check its argv.
+type CommandRunner interface {
+	// Run runs a command and discards its output
+	Run(ctx context.Context, name string, args ...string) error
+	// RunWithOutput runs a command and returns its combined stdout and stderr
+	RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+	// RunStreaming runs a command connected to the given streams; nil streams are discarded
+	RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error
+}
+
+// Streams connects a command to input and output
+type Streams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Sensitive keeps the command's output out of transcripts, for commands
+	// that print secrets such as age --decrypt
+	Sensitive bool
+}
+
+// ExecRunner implements CommandRunner with os/exec
+type ExecRunner struct{}
+
+// Run runs a command and discards its output
+func (ExecRunner) Run(ctx context.Context, name string, args ...string) error {
+	return withContextError(ctx, exec.CommandContext(ctx, name, args...).Run())
+}
+
+// RunWithOutput runs a command and returns its combined stdout and stderr
+func (ExecRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return output, withContextError(ctx, err)
+}
+
+// RunStreaming runs a command connected to the given streams
+func (ExecRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = streams.Stdin
+	cmd.Stdout = streams.Stdout
+	cmd.Stderr = streams.Stderr
+	return withContextError(ctx, cmd.Run())
+}
+
+// withContextError makes a command killed because its context ended, e.g. by
+// a --timeout, report that rather than just "signal: killed"
+func withContextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ctx.Err(), err)
+}
+
+var (
+	runnerMu sync.RWMutex
+	runner   CommandRunner = ExecRunner{}
+)
+
+// Runner returns the command runner used by the helpers
+func Runner() CommandRunner {
+	runnerMu.RLock()
+	defer runnerMu.RUnlock()
+	return runner
+}
+
+// SetRunner replaces the command runner used by the helpers and returns the
+// previous one, so tests can restore it when they are done
+func SetRunner(r CommandRunner) CommandRunner {
+	runnerMu.Lock()
+	defer runnerMu.Unlock()
+	previous := runner
+	runner = r
+	return previous
+}
+
+// runOutput runs a command and returns its stdout only, like exec.Cmd.Output;
+// use it where stderr noise would corrupt parsed output such as JSON
+func runOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	err := Runner().RunStreaming(ctx, Streams{Stdout: &stdout}, name, args...)
+	return stdout.Bytes(), err
+}
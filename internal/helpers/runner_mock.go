@@ -0,0 +1,157 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// MockResponse is the canned result of a command run through MockRunner
+type MockResponse struct {
+	Output string
+	Err    error
+}
+
+// MockExitError is a command failure with an exit code, like *exec.ExitError
+type MockExitError struct {
+	Code int
+}
+
+func (e *MockExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the failed command
+func (e *MockExitError) ExitCode() int {
+	return e.Code
+}
+
+// MockRunner implements CommandRunner by recording every command and replying
+// with canned responses instead of running anything
+type MockRunner struct {
+	mu sync.Mutex
+
+	// Responses are keyed by the space-joined argv; the longest key that is a
+	// prefix of a command's argv wins. Commands without a response succeed silently.
+	Responses map[string]MockResponse
+
+	// Commands records the argv of every command run, in order
+	Commands [][]string
+
+	// Stdins records the stdin of every streaming command that was given one
+	Stdins []io.Reader
+}
+
+// NewMockRunner creates a MockRunner with no canned responses
+func NewMockRunner() *MockRunner {
+	return &MockRunner{Responses: make(map[string]MockResponse)}
+}
+
+// Respond sets the output and error returned for commands starting with argv
+func (m *MockRunner) Respond(output string, err error, argv ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Responses[strings.Join(argv, " ")] = MockResponse{Output: output, Err: err}
+}
+
+// Run records a command and returns its canned error
+func (m *MockRunner) Run(ctx context.Context, name string, args ...string) error {
+	_, err := m.RunWithOutput(ctx, name, args...)
+	return err
+}
+
+// RunWithOutput records a command and returns its canned output and error
+func (m *MockRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	argv := append([]string{name}, args...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Commands = append(m.Commands, argv)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	response := m.lookup(argv)
+	return []byte(response.Output), response.Err
+}
+
+// RunStreaming records a command and writes its canned output to stdout
+func (m *MockRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	if streams.Stdin != nil {
+		m.mu.Lock()
+		m.Stdins = append(m.Stdins, streams.Stdin)
+		m.mu.Unlock()
+	}
+	output, err := m.RunWithOutput(ctx, name, args...)
+	if streams.Stdout != nil && len(output) > 0 {
+		if _, writeErr := streams.Stdout.Write(output); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
+
+// lookup finds the response with the longest key matching the start of argv
+func (m *MockRunner) lookup(argv []string) MockResponse {
+	var best MockResponse
+	bestLen := -1
+	for key, response := range m.Responses {
+		keyArgv := strings.Fields(key)
+		if len(keyArgv) > len(argv) || len(keyArgv) <= bestLen {
+			continue
+		}
+		if strings.Join(argv[:len(keyArgv)], " ") == key {
+			best = response
+			bestLen = len(keyArgv)
+		}
+	}
+	return best
+}
+
+// Ran reports whether a command with exactly this argv was run
+func (m *MockRunner) Ran(argv ...string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	want := strings.Join(argv, "\x00")
+	for _, command := range m.Commands {
+		if strings.Join(command, "\x00") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectCommands checks that exactly these commands ran, in this order
+func (m *MockRunner) ExpectCommands(expected ...[]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.Commands) != len(expected) {
+		return fmt.Errorf("expected %d commands, got %d: %s", len(expected), len(m.Commands), formatCommands(m.Commands))
+	}
+	for i, argv := range expected {
+		if strings.Join(m.Commands[i], "\x00") != strings.Join(argv, "\x00") {
+			return fmt.Errorf("command %d: expected %q, got %q", i, argv, m.Commands[i])
+		}
+	}
+	return nil
+}
+
+// Reset clears recorded commands and canned responses
+func (m *MockRunner) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Commands = nil
+	m.Responses = make(map[string]MockResponse)
+}
+
+func formatCommands(commands [][]string) string {
+	lines := make([]string, len(commands))
+	for i, command := range commands {
+		lines[i] = strings.Join(command, " ")
+	}
+	return "[" + strings.Join(lines, "; ") + "]"
+}
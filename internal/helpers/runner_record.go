@@ -0,0 +1,305 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordEnvVar names a directory to record every host command into, e.g.
+// LXC_GO_CLI_RECORD=testdata/transcripts
+const RecordEnvVar = "LXC_GO_CLI_RECORD"
+
+// Interaction is one recorded command. Output holds the combined output of a
+// RunWithOutput call; Stdout and Stderr hold the streams of a RunStreaming call.
+// Stdin is never recorded, and the output of sensitive streams is replaced
+// by RedactedOutput, but argv and other output are: review a transcript for
+// secrets before committing it.
+type Interaction struct {
+	Argv     []string `json:"argv"`
+	Output   string   `json:"output,omitempty"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exit_code"`
+	// Error is set when the command failed without an exit code, e.g. it was not found
+	Error string `json:"error,omitempty"`
+}
+
+// RedactedOutput stands in for the output of sensitive streams in transcripts
+const RedactedOutput = "[redacted]"
+
+// RecordingRunner runs commands through another runner and appends each one
+// to a transcript file of JSON lines
+type RecordingRunner struct {
+	next CommandRunner
+	path string
+
+	mu sync.Mutex
+}
+
+// NewRecordingRunner records commands run through next into a new transcript
+// in dir, named after the time and process so concurrent runs do not collide
+func NewRecordingRunner(next CommandRunner, dir string) *RecordingRunner {
+	name := fmt.Sprintf("%s-%d.jsonl", time.Now().Format("20060102-150405"), os.Getpid())
+	return &RecordingRunner{next: next, path: filepath.Join(dir, name)}
+}
+
+// RecordFromEnv wraps the package runner in a RecordingRunner when
+// LXC_GO_CLI_RECORD is set
+func RecordFromEnv() {
+	dir := os.Getenv(RecordEnvVar)
+	if dir == "" {
+		return
+	}
+	SetRunner(NewRecordingRunner(Runner(), dir))
+}
+
+// Path returns the transcript file being written
+func (r *RecordingRunner) Path() string {
+	return r.path
+}
+
+// Run runs a command and records it
+func (r *RecordingRunner) Run(ctx context.Context, name string, args ...string) error {
+	_, err := r.RunWithOutput(ctx, name, args...)
+	return err
+}
+
+// RunWithOutput runs a command and records its combined output
+func (r *RecordingRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := r.next.RunWithOutput(ctx, name, args...)
+	r.record(newInteraction(name, args, err, func(i *Interaction) { i.Output = string(output) }))
+	return output, err
+}
+
+// RunStreaming runs a command and records its stdout and stderr as they pass through
+func (r *RecordingRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	var stdout, stderr bytes.Buffer
+	recorded := streams
+	recorded.Stdout = teeWriter(streams.Stdout, &stdout)
+	recorded.Stderr = teeWriter(streams.Stderr, &stderr)
+
+	err := r.next.RunStreaming(ctx, recorded, name, args...)
+	r.record(newInteraction(name, args, err, func(i *Interaction) {
+		i.Stdout = stdout.String()
+		i.Stderr = stderr.String()
+		if streams.Sensitive {
+			i.Stdout, i.Stderr = redact(i.Stdout), redact(i.Stderr)
+		}
+	}))
+	return err
+}
+
+// record appends an interaction to the transcript; a recording failure never
+// fails the command itself
+func (r *RecordingRunner) record(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	json.NewEncoder(file).Encode(interaction)
+}
+
+func newInteraction(name string, args []string, err error, fill func(*Interaction)) Interaction {
+	interaction := Interaction{Argv: append([]string{name}, args...)}
+	fill(&interaction)
+	if err != nil {
+		interaction.ExitCode = ExitCodeFromError(err)
+		if interaction.ExitCode < 0 {
+			interaction.Error = err.Error()
+		}
+	}
+	return interaction
+}
+
+// redact replaces recorded output that may hold a secret
+func redact(output string) string {
+	if output == "" {
+		return ""
+	}
+	return RedactedOutput
+}
+
+// teeWriter copies writes to capture as well as to out, which may be nil
+func teeWriter(out io.Writer, capture io.Writer) io.Writer {
+	if out == nil {
+		return capture
+	}
+	return io.MultiWriter(out, capture)
+}
+
+// LoadTranscript reads a transcript written by RecordingRunner
+func LoadTranscript(path string) ([]Interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid transcript entry: %w", path, line, err)
+		}
+		if len(interaction.Argv) == 0 {
+			return nil, fmt.Errorf("%s:%d: transcript entry has no argv", path, line)
+		}
+		interactions = append(interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return interactions, nil
+}
+
+// ReplayRunner implements CommandRunner by answering commands from a
+// transcript. A command gets the first unused interaction with the same argv;
+// once those are used up the last one is repeated, so polling loops settle on
+// the final recorded state. Commands missing from the transcript fail.
+type ReplayRunner struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+
+	// Commands records the argv of every command run, in order
+	Commands [][]string
+}
+
+// NewReplayRunner creates a ReplayRunner for the given interactions
+func NewReplayRunner(interactions []Interaction) *ReplayRunner {
+	return &ReplayRunner{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// Run replays a command and returns its recorded error
+func (r *ReplayRunner) Run(ctx context.Context, name string, args ...string) error {
+	_, err := r.RunWithOutput(ctx, name, args...)
+	return err
+}
+
+// RunWithOutput replays a command and returns its recorded output and error
+func (r *ReplayRunner) RunWithOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	interaction, err := r.next(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	output := interaction.Output
+	if output == "" {
+		output = interaction.Stdout + interaction.Stderr
+	}
+	return []byte(output), interaction.err()
+}
+
+// RunStreaming replays a command, writing its recorded output to the streams
+func (r *ReplayRunner) RunStreaming(ctx context.Context, streams Streams, name string, args ...string) error {
+	interaction, err := r.next(ctx, name, args)
+	if err != nil {
+		return err
+	}
+	stdout := interaction.Stdout
+	if stdout == "" && interaction.Stderr == "" {
+		stdout = interaction.Output
+	}
+	for _, stream := range []struct {
+		out  io.Writer
+		data string
+	}{{streams.Stdout, stdout}, {streams.Stderr, interaction.Stderr}} {
+		if stream.out == nil || stream.data == "" {
+			continue
+		}
+		if _, err := io.WriteString(stream.out, stream.data); err != nil {
+			return err
+		}
+	}
+	return interaction.err()
+}
+
+// Ran reports whether a command with exactly this argv was run
+func (r *ReplayRunner) Ran(argv ...string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	want := strings.Join(argv, "\x00")
+	for _, command := range r.Commands {
+		if strings.Join(command, "\x00") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Unused returns the recorded commands that were never replayed
+func (r *ReplayRunner) Unused() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused [][]string
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction.Argv)
+		}
+	}
+	return unused
+}
+
+// next records a command and finds the interaction that answers it
+func (r *ReplayRunner) next(ctx context.Context, name string, args []string) (Interaction, error) {
+	argv := append([]string{name}, args...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Commands = append(r.Commands, argv)
+
+	if err := ctx.Err(); err != nil {
+		return Interaction{}, err
+	}
+
+	want := strings.Join(argv, "\x00")
+	last := -1
+	for i, interaction := range r.interactions {
+		if strings.Join(interaction.Argv, "\x00") != want {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return Interaction{}, fmt.Errorf("no recorded output for %q", strings.Join(argv, " "))
+	}
+	return r.interactions[last], nil
+}
+
+// err rebuilds the error the recorded command returned
+func (i Interaction) err() error {
+	if i.Error != "" {
+		return errors.New(i.Error)
+	}
+	if i.ExitCode != 0 {
+		return &MockExitError{Code: i.ExitCode}
+	}
+	return nil
+}
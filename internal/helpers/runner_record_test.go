@@ -0,0 +1,216 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useTranscript replays testdata/transcripts/<name>.jsonl for the duration of a test
+func useTranscript(t *testing.T, name string) *ReplayRunner {
+	t.Helper()
+	interactions, err := LoadTranscript(filepath.Join("testdata", "transcripts", name+".jsonl"))
+	if err != nil {
+		t.Fatalf("failed to load transcript: %v", err)
+	}
+	replay := NewReplayRunner(interactions)
+	previous := SetRunner(replay)
+	t.Cleanup(func() { SetRunner(previous) })
+	return replay
+}
+
+func TestRecordingRunnerRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecordingRunner(ExecRunner{}, dir)
+	ctx := context.Background()
+
+	output, err := recorder.RunWithOutput(ctx, "sh", "-c", "echo combined; exit 3")
+	if ExitCodeFromError(err) != 3 || string(output) != "combined\n" {
+		t.Fatalf("unexpected result: %q, %v", output, err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := recorder.RunStreaming(ctx, Streams{Stdout: &stdout, Stderr: &stderr}, "sh", "-c", "echo out; echo err >&2"); err != nil {
+		t.Fatalf("RunStreaming failed: %v", err)
+	}
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("expected output to pass through, got %q and %q", stdout.String(), stderr.String())
+	}
+	recorder.Run(ctx, "lxc-go-cli-no-such-command")
+
+	interactions, err := LoadTranscript(recorder.Path())
+	if err != nil {
+		t.Fatalf("LoadTranscript failed: %v", err)
+	}
+	if len(interactions) != 3 {
+		t.Fatalf("expected 3 interactions, got %d", len(interactions))
+	}
+	if interactions[2].ExitCode != -1 || interactions[2].Error == "" {
+		t.Errorf("expected a missing command to record its error, got %+v", interactions[2])
+	}
+
+	replay := NewReplayRunner(interactions)
+	output, err = replay.RunWithOutput(ctx, "sh", "-c", "echo combined; exit 3")
+	if ExitCodeFromError(err) != 3 || string(output) != "combined\n" {
+		t.Errorf("expected the recorded result to replay, got %q, %v", output, err)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	if err := replay.RunStreaming(ctx, Streams{Stdout: &stdout, Stderr: &stderr}, "sh", "-c", "echo out; echo err >&2"); err != nil {
+		t.Errorf("expected the streaming command to replay, got %v", err)
+	}
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("expected streams to replay separately, got %q and %q", stdout.String(), stderr.String())
+	}
+	if err := replay.Run(ctx, "lxc-go-cli-no-such-command"); err == nil || ExitCodeFromError(err) != -1 {
+		t.Errorf("expected the recorded start failure to replay, got %v", err)
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("expected every interaction to be replayed, got %v", unused)
+	}
+}
+
+func TestRecordFromEnv(t *testing.T) {
+	previous := SetRunner(ExecRunner{})
+	t.Cleanup(func() { SetRunner(previous) })
+
+	t.Setenv(RecordEnvVar, "")
+	RecordFromEnv()
+	if _, ok := Runner().(ExecRunner); !ok {
+		t.Fatalf("expected the runner to be unchanged without %s", RecordEnvVar)
+	}
+
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	t.Setenv(RecordEnvVar, dir)
+	RecordFromEnv()
+	recorder, ok := Runner().(*RecordingRunner)
+	if !ok {
+		t.Fatalf("expected a RecordingRunner, got %T", Runner())
+	}
+	if filepath.Dir(recorder.Path()) != dir {
+		t.Errorf("expected the transcript in %s, got %s", dir, recorder.Path())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written before a command runs")
+	}
+}
+
+func TestReplayRunnerOrder(t *testing.T) {
+	replay := NewReplayRunner([]Interaction{
+		{Argv: []string{"lxc", "list", "web", "--format", "csv"}, Output: "web,STOPPED\n"},
+		{Argv: []string{"lxc", "list", "web", "--format", "csv"}, Output: "web,RUNNING\n"},
+	})
+	ctx := context.Background()
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		output, err := replay.RunWithOutput(ctx, "lxc", "list", "web", "--format", "csv")
+		if err != nil {
+			t.Fatalf("RunWithOutput failed: %v", err)
+		}
+		got = append(got, string(output))
+	}
+	want := []string{"web,STOPPED\n", "web,RUNNING\n", "web,RUNNING\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	_, err := replay.RunWithOutput(ctx, "lxc", "delete", "web")
+	if err == nil || !strings.Contains(err.Error(), "no recorded output") {
+		t.Errorf("expected an unrecorded command to fail, got %v", err)
+	}
+	if len(replay.Commands) != 4 {
+		t.Errorf("expected 4 commands recorded, got %d", len(replay.Commands))
+	}
+
+	ctxCancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := replay.RunWithOutput(ctxCancelled, "lxc", "list", "web", "--format", "csv"); err == nil {
+		t.Error("expected a cancelled context to fail")
+	}
+}
+
+func TestLoadTranscriptErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadTranscript(filepath.Join(dir, "missing.jsonl")); err == nil {
+		t.Error("expected a missing transcript to fail")
+	}
+
+	tests := map[string]string{
+		"invalid.jsonl": "{\"argv\":[\"lxc\"]}\nnot json\n",
+		"no-argv.jsonl": "{\"output\":\"x\"}\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTranscript(path); err == nil || !strings.Contains(err.Error(), path+":") {
+			t.Errorf("%s: expected a line-numbered error, got %v", name, err)
+		}
+	}
+}
+
+func TestTranscriptStoragePools(t *testing.T) {
+	useTranscript(t, "storage")
+
+	if pools := GetBtrfsStoragePools(); !reflect.DeepEqual(pools, []string{"docker-pool"}) {
+		t.Errorf("expected [docker-pool], got %v", pools)
+	}
+	if !IsBtrfsAvailable() {
+		t.Error("expected btrfs to be available")
+	}
+	if driver := GetDefaultStoragePoolType(); driver != "zfs" {
+		t.Errorf("expected the default pool to be zfs, got %q", driver)
+	}
+}
+
+func TestTranscriptStorageTableFallback(t *testing.T) {
+	replay := useTranscript(t, "storage-old-client")
+
+	if pools := GetBtrfsStoragePools(); !reflect.DeepEqual(pools, []string{"docker-pool"}) {
+		t.Errorf("expected [docker-pool] from the table, got %v", pools)
+	}
+	if !replay.Ran("lxc", "storage", "list") {
+		t.Error("expected the table format to be used after JSON failed")
+	}
+}
+
+func TestTranscriptConfigShow(t *testing.T) {
+	useTranscript(t, "config-show")
+
+	status, err := GetContainerGPUStatus("web")
+	if err != nil {
+		t.Fatalf("GetContainerGPUStatus failed: %v", err)
+	}
+	if !status.IsEnabled() {
+		t.Errorf("expected GPU access to be enabled, got %+v", status)
+	}
+
+	output, err := GetContainerConfig(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("GetContainerConfig failed: %v", err)
+	}
+	managed, err := FilterManagedConfig("web", output)
+	if err != nil {
+		t.Fatalf("FilterManagedConfig failed: %v", err)
+	}
+	if managed.Config["user.app-password"] != maskedValue {
+		t.Errorf("expected the password to be masked, got %q", managed.Config["user.app-password"])
+	}
+	if _, ok := managed.Config["volatile.uuid"]; ok {
+		t.Error("expected volatile keys to be filtered out")
+	}
+	for _, device := range []string{"gpu", "root", "web-8080-80-tcp"} {
+		if _, ok := managed.Devices[device]; !ok {
+			t.Errorf("expected device %s, got %v", device, managed.Devices)
+		}
+	}
+
+	if _, err := GetContainerGPUStatus("missing"); err == nil || !strings.Contains(err.Error(), "Instance not found") {
+		t.Errorf("expected the recorded lxc error, got %v", err)
+	}
+}
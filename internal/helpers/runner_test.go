@@ -0,0 +1,168 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// useMockRunner installs a MockRunner for the duration of a test
+func useMockRunner(t *testing.T) *MockRunner {
+	t.Helper()
+	mock := NewMockRunner()
+	previous := SetRunner(mock)
+	t.Cleanup(func() { SetRunner(previous) })
+	return mock
+}
+
+func TestHelpersUseRunner(t *testing.T) {
+	runner := useMockRunner(t)
+
+	if err := StartContainer("web"); err != nil {
+		t.Fatalf("StartContainer failed: %v", err)
+	}
+	if err := AddContainerDevice("web", "port80", "proxy", map[string]string{
+		"listen":  "tcp:0.0.0.0:80",
+		"connect": "tcp:127.0.0.1:8080",
+	}); err != nil {
+		t.Fatalf("AddContainerDevice failed: %v", err)
+	}
+	if err := DeleteSnapshot("web", "snap0"); err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+	if err := CreateEphemeralContainer("ci", "ubuntu", "24.04", "amd64", "pool"); err != nil {
+		t.Fatalf("CreateEphemeralContainer failed: %v", err)
+	}
+
+	err := runner.ExpectCommands(
+		[]string{"lxc", "start", "web"},
+		[]string{"lxc", "config", "device", "add", "web", "port80", "proxy", "connect=tcp:127.0.0.1:8080", "listen=tcp:0.0.0.0:80"},
+		[]string{"lxc", "delete", "web/snap0"},
+		[]string{"lxc", "launch", "ubuntu:24.04", "ci", "--storage", "pool", "--ephemeral"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHelpersRunnerFaultInjection(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("Error: Instance is busy", &MockExitError{Code: 1}, "lxc", "stop")
+
+	err := StopContainer("web")
+	if err == nil {
+		t.Fatal("Expected StopContainer to fail")
+	}
+	if !strings.Contains(err.Error(), "Instance is busy") {
+		t.Errorf("Expected command output in error, got: %v", err)
+	}
+	if ExitCodeFromError(err) != 1 {
+		t.Errorf("Expected exit code 1, got %d", ExitCodeFromError(err))
+	}
+}
+
+func TestHelpersRunnerContext(t *testing.T) {
+	useMockRunner(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := RunInContainer(ctx, "web", "apt-get", "update"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected RunInContainer to stop with the context, got %v", err)
+	}
+	if err := RestartContainer(ctx, "web"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected RestartContainer to stop with the context, got %v", err)
+	}
+}
+
+func TestHelpersRunnerOutput(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond(`{"name":"web","status":"Frozen"}`, nil, "lxc", "query", "/1.0/instances/web")
+
+	status, err := GetContainerStatus("web")
+	if err != nil {
+		t.Fatalf("GetContainerStatus failed: %v", err)
+	}
+	if status != StatusFrozen {
+		t.Errorf("Expected status %s, got %s", StatusFrozen, status)
+	}
+	if !runner.Ran("lxc", "query", "/1.0/instances/web") {
+		t.Error("Expected lxc query to be run")
+	}
+}
+
+func TestMockRunner(t *testing.T) {
+	runner := NewMockRunner()
+	ctx := context.Background()
+
+	runner.Respond("generic", nil, "lxc")
+	runner.Respond("specific", nil, "lxc", "list")
+	runner.Respond("", errors.New("boom"), "nft")
+
+	if out, _ := runner.RunWithOutput(ctx, "lxc", "list", "--format", "json"); string(out) != "specific" {
+		t.Errorf("Expected longest prefix match, got %q", out)
+	}
+	if out, _ := runner.RunWithOutput(ctx, "lxc", "info"); string(out) != "generic" {
+		t.Errorf("Expected shorter prefix match, got %q", out)
+	}
+	if out, err := runner.RunWithOutput(ctx, "btrfs", "version"); err != nil || len(out) != 0 {
+		t.Errorf("Expected silent success without a response, got %q, %v", out, err)
+	}
+	if err := runner.Run(ctx, "nft", "list", "ruleset"); err == nil {
+		t.Error("Expected canned error")
+	}
+
+	var stdout bytes.Buffer
+	if err := runner.RunStreaming(ctx, Streams{Stdout: &stdout}, "lxc", "list"); err != nil {
+		t.Fatalf("RunStreaming failed: %v", err)
+	}
+	if stdout.String() != "specific" {
+		t.Errorf("Expected output on stdout, got %q", stdout.String())
+	}
+
+	if len(runner.Commands) != 5 {
+		t.Errorf("Expected 5 recorded commands, got %d", len(runner.Commands))
+	}
+	if err := runner.ExpectCommands([]string{"lxc", "list"}); err == nil {
+		t.Error("Expected ExpectCommands to fail on a count mismatch")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := runner.Run(cancelled, "lxc", "list"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	runner.Reset()
+	if len(runner.Commands) != 0 || len(runner.Responses) != 0 {
+		t.Error("Reset should clear commands and responses")
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	runner := ExecRunner{}
+	ctx := context.Background()
+
+	out, err := runner.RunWithOutput(ctx, "sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatalf("RunWithOutput failed: %v", err)
+	}
+	if !strings.Contains(string(out), "out") || !strings.Contains(string(out), "err") {
+		t.Errorf("Expected combined output, got %q", out)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = runner.RunStreaming(ctx, Streams{Stdin: strings.NewReader("hello"), Stdout: &stdout, Stderr: &stderr}, "sh", "-c", "cat; echo err >&2")
+	if err != nil {
+		t.Fatalf("RunStreaming failed: %v", err)
+	}
+	if stdout.String() != "hello" || strings.TrimSpace(stderr.String()) != "err" {
+		t.Errorf("Unexpected streams: stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	err = runner.Run(ctx, "sh", "-c", "exit 3")
+	if ExitCodeFromError(err) != 3 {
+		t.Errorf("Expected exit code 3, got %d (%v)", ExitCodeFromError(err), err)
+	}
+}
@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON schema version generated schemas declare
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generates a JSON schema for the YAML form of v, a struct, from
+// its yaml tags. Fields without omitempty are required and unknown keys are
+// rejected, as yaml.UnmarshalStrict does. Fields can refine their schema with
+// tags: desc for a description, enum for comma-separated allowed values and
+// min and max for integer bounds.
+func JSONSchema(v interface{}, title string) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot generate a schema for %s: not a struct", t)
+	}
+
+	schema := schemaForType(t)
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = title
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func schemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, omitempty, ok := yamlFieldName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaForField(field)
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaForType(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaForField applies a field's desc, enum, min and max tags to the schema of its type
+func schemaForField(field reflect.StructField) map[string]interface{} {
+	schema := schemaForType(field.Type)
+	if desc := field.Tag.Get("desc"); desc != "" {
+		schema["description"] = desc
+	}
+	if enum := field.Tag.Get("enum"); enum != "" {
+		schema["enum"] = strings.Split(enum, ",")
+	}
+	for tag, keyword := range map[string]string{"min": "minimum", "max": "maximum"} {
+		if value, err := strconv.Atoi(field.Tag.Get(tag)); err == nil {
+			schema[keyword] = value
+		}
+	}
+	return schema
+}
+
+// yamlFieldName returns the key a struct field has in YAML and whether it is omitempty
+func yamlFieldName(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	omitempty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, true
+}
@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type schemaTestEntry struct {
+	Port  int      `yaml:"port" desc:"A port" min:"1" max:"65535"`
+	Mode  string   `yaml:"mode,omitempty" enum:"a,b"`
+	Tags  []string `yaml:"tags,omitempty"`
+	Skip  string   `yaml:"-"`
+	local string
+}
+
+type schemaTestSpec struct {
+	Entries []schemaTestEntry `yaml:"entries"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema(&schemaTestSpec{}, "test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v\n%s", err, data)
+	}
+	if schema["$schema"] != jsonSchemaDialect || schema["title"] != "test" || schema["additionalProperties"] != false {
+		t.Errorf("unexpected top level: %v", schema)
+	}
+	if !reflect.DeepEqual(schema["required"], []interface{}{"entries"}) {
+		t.Errorf("expected entries to be required, got %v", schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	labels := properties["labels"].(map[string]interface{})
+	if labels["type"] != "object" || labels["additionalProperties"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("unexpected map schema: %v", labels)
+	}
+
+	entry := properties["entries"].(map[string]interface{})["items"].(map[string]interface{})
+	entryProperties := entry["properties"].(map[string]interface{})
+	want := map[string]interface{}{"type": "integer", "description": "A port", "minimum": 1.0, "maximum": 65535.0}
+	if !reflect.DeepEqual(entryProperties["port"], want) {
+		t.Errorf("expected %v, got %v", want, entryProperties["port"])
+	}
+	if !reflect.DeepEqual(entryProperties["mode"], map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b"}}) {
+		t.Errorf("unexpected enum schema: %v", entryProperties["mode"])
+	}
+	if _, ok := entryProperties["Skip"]; ok || len(entryProperties) != 3 {
+		t.Errorf("ignored and unexported fields should be left out, got %v", entryProperties)
+	}
+
+	if _, err := JSONSchema("text", "test"); err == nil {
+		t.Error("expected an error for a non-struct")
+	}
+}
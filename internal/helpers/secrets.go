@@ -0,0 +1,246 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// SecretsRunDir is the tmpfs directory in containers that injected secrets
+// are written to, so they vanish on restart and never reach instance config
+const SecretsRunDir = "/run/lxc-go-cli/secrets"
+
+// SecretsEnvFile is the name of the env file written by 'secret inject --as-env'
+const SecretsEnvFile = "secrets.env"
+
+// SecretInfo describes a stored secret without its value
+type SecretInfo struct {
+	Name    string
+	Updated time.Time
+}
+
+// secretsDir holds the age identity and every container's encrypted secrets
+func secretsDir() string {
+	return filepath.Join(SettingsDir, "secrets")
+}
+
+// secretIdentityFile is the age private key secrets are encrypted to
+func secretIdentityFile() string {
+	return filepath.Join(secretsDir(), "identity.txt")
+}
+
+// secretFile is the encrypted file of one secret of a container
+func secretFile(containerName, name string) string {
+	return filepath.Join(secretsDir(), containerName, name+".age")
+}
+
+// ValidateSecretName checks that a secret can be used as an environment
+// variable and a file name
+func ValidateSecretName(name string) error {
+	if !envKeyPattern.MatchString(name) {
+		return UsageErrorf("invalid secret name '%s': use letters, digits and underscores, e.g. DB_PASSWORD", name)
+	}
+	return nil
+}
+
+// validateSecretPath checks the names that make up a secret's file path, so
+// neither can reach outside the secrets directory
+func validateSecretPath(containerName, name string) error {
+	if err := ValidateContainerName(containerName); err != nil {
+		return WithKind(err, ErrUsage)
+	}
+	return ValidateSecretName(name)
+}
+
+// ensureSecretIdentity creates the age identity on first use and returns
+// its public key
+func ensureSecretIdentity(ctx context.Context) (string, error) {
+	if SettingsDir == "" {
+		return "", fmt.Errorf("no user config directory available")
+	}
+	if _, err := lookPath("age"); err != nil {
+		return "", WithKind(fmt.Errorf("age is not installed; install it (e.g. 'apt install age') to store secrets"), ErrEnvironment)
+	}
+
+	identity := secretIdentityFile()
+	if _, err := os.Stat(identity); os.IsNotExist(err) {
+		if err := os.MkdirAll(secretsDir(), 0700); err != nil {
+			return "", fmt.Errorf("failed to create secrets directory: %w", err)
+		}
+		if output, err := Runner().RunWithOutput(ctx, "age-keygen", "-o", identity); err != nil {
+			return "", fmt.Errorf("failed to create the secrets key: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+		logger.Info("Created the secrets key %s; back it up, secrets cannot be read without it", identity)
+	}
+
+	output, err := runOutput(ctx, "age-keygen", "-y", identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the secrets key %s: %w", identity, err)
+	}
+	recipient := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(recipient, "age1") {
+		return "", fmt.Errorf("unexpected public key '%s' in %s", recipient, identity)
+	}
+	return recipient, nil
+}
+
+// SetSecret encrypts a secret of a container with age and stores it; the
+// plaintext only ever travels on age's stdin
+func SetSecret(ctx context.Context, containerName, name string, value []byte) error {
+	if err := validateSecretPath(containerName, name); err != nil {
+		return err
+	}
+	recipient, err := ensureSecretIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var ciphertext, stderr bytes.Buffer
+	streams := Streams{Stdin: bytes.NewReader(value), Stdout: &ciphertext, Stderr: &stderr}
+	if err := Runner().RunStreaming(ctx, streams, "age", "--encrypt", "--armor", "-r", recipient); err != nil {
+		return fmt.Errorf("failed to encrypt secret '%s': %w (output: %s)", name, err, strings.TrimSpace(stderr.String()))
+	}
+	if err := writeFileAtomic(secretFile(containerName, name), ciphertext.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to store secret '%s': %w", name, err)
+	}
+	return nil
+}
+
+// GetSecret decrypts a stored secret of a container
+func GetSecret(ctx context.Context, containerName, name string) ([]byte, error) {
+	if err := validateSecretPath(containerName, name); err != nil {
+		return nil, err
+	}
+	file := secretFile(containerName, name)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, NotFoundErrorf("container '%s' has no secret '%s'", containerName, name)
+	}
+
+	var plaintext, stderr bytes.Buffer
+	streams := Streams{Stdout: &plaintext, Stderr: &stderr, Sensitive: true}
+	if err := Runner().RunStreaming(ctx, streams, "age", "--decrypt", "-i", secretIdentityFile(), file); err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret '%s': %w (output: %s)", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return plaintext.Bytes(), nil
+}
+
+// ListSecrets returns the secrets stored for a container, sorted by name
+func ListSecrets(containerName string) ([]SecretInfo, error) {
+	if err := ValidateContainerName(containerName); err != nil {
+		return nil, WithKind(err, ErrUsage)
+	}
+	if SettingsDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(secretsDir(), containerName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var secrets []SecretInfo
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".age")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, SecretInfo{Name: name, Updated: info.ModTime()})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// DeleteSecret removes a stored secret of a container
+func DeleteSecret(containerName, name string) error {
+	if err := validateSecretPath(containerName, name); err != nil {
+		return err
+	}
+	if err := os.Remove(secretFile(containerName, name)); err != nil {
+		if os.IsNotExist(err) {
+			return NotFoundErrorf("container '%s' has no secret '%s'", containerName, name)
+		}
+		return fmt.Errorf("failed to delete secret '%s': %w", name, err)
+	}
+	return nil
+}
+
+// DeleteContainerSecrets removes every stored secret of a container
+func DeleteContainerSecrets(containerName string) error {
+	if SettingsDir == "" {
+		return nil
+	}
+	if err := ValidateContainerName(containerName); err != nil {
+		return WithKind(err, ErrUsage)
+	}
+	if err := os.RemoveAll(filepath.Join(secretsDir(), containerName)); err != nil {
+		return fmt.Errorf("failed to delete the secrets of '%s': %w", containerName, err)
+	}
+	return nil
+}
+
+// LoadSecrets decrypts every secret of a container as variables
+func LoadSecrets(ctx context.Context, containerName string) ([]EnvVar, error) {
+	secrets, err := ListSecrets(containerName)
+	if err != nil {
+		return nil, err
+	}
+	vars := make([]EnvVar, 0, len(secrets))
+	for _, secret := range secrets {
+		value, err := GetSecret(ctx, containerName, secret.Name)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, EnvVar{Key: secret.Name, Value: string(value)})
+	}
+	return vars, nil
+}
+
+// SecretsEnvData renders secrets as a compose env_file. Values are single
+// quoted, which compose reads literally, so they cannot contain a quote or
+// a newline; inject those as files instead.
+func SecretsEnvData(vars []EnvVar) ([]byte, error) {
+	var data strings.Builder
+	for _, v := range vars {
+		if strings.ContainsAny(v.Value, "'\n\r") {
+			return nil, fmt.Errorf("secret '%s' contains a quote or newline and cannot go in an env file; inject it without --as-env", v.Key)
+		}
+		fmt.Fprintf(&data, "%s='%s'\n", v.Key, v.Value)
+	}
+	return []byte(data.String()), nil
+}
+
+// SecretsServiceDir returns the directory a service's secrets are injected into
+func SecretsServiceDir(service string) string {
+	return path.Join(SecretsRunDir, service)
+}
+
+// pushSecretScript writes stdin to $1 in a directory only the owner $2 can
+// enter; the parent stays traversable for other services' owners
+const pushSecretScript = `set -e; dir=$(dirname "$1"); mkdir -p -m 0711 "$(dirname "$dir")"; ` +
+	`install -d -m 0700 -o "$2" "$dir"; umask 077; cat > "$1"; chown "$2" "$1"`
+
+// PushSecretFile writes a secret into a container's tmpfs, readable only by
+// owner. The value travels on stdin and never appears on a command line.
+func PushSecretFile(ctx context.Context, containerName, file, owner string, data []byte) error {
+	var stderr bytes.Buffer
+	streams := Streams{Stdin: bytes.NewReader(data), Stderr: &stderr}
+	if err := Runner().RunStreaming(ctx, streams, "lxc", "exec", containerName, "-T", "--", "sh", "-c", pushSecretScript, "sh", file, owner); err != nil {
+		return fmt.Errorf("failed to write %s in container '%s': %w (output: %s)", file, containerName, err, strings.TrimSpace(stderr.String()))
+	}
+	logger.Debug("Wrote %s in container '%s'", file, containerName)
+	return nil
+}
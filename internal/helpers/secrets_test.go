@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetAndGetSecret(t *testing.T) {
+	useTempSettingsDir(t)
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	runner := useMockRunner(t)
+	identity := filepath.Join(SettingsDir, "secrets", "identity.txt")
+	runner.Respond("age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs3290gq\n", nil, "age-keygen", "-y", identity)
+	runner.Respond("-----BEGIN AGE ENCRYPTED FILE-----\n", nil, "age", "--encrypt")
+
+	ctx := context.Background()
+	if err := SetSecret(ctx, "web", "DB_PASSWORD", []byte("s3cret")); err != nil {
+		t.Fatal(err)
+	}
+	if !runner.Ran("age-keygen", "-o", identity) {
+		t.Error("expected the identity to be created on first use")
+	}
+	if !runner.Ran("age", "--encrypt", "--armor", "-r", "age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs3290gq") {
+		t.Errorf("expected age to encrypt to the identity, ran %v", runner.Commands)
+	}
+	for _, command := range runner.Commands {
+		if strings.Contains(strings.Join(command, " "), "s3cret") {
+			t.Errorf("the secret must not appear on a command line: %v", command)
+		}
+	}
+	stored, err := os.ReadFile(filepath.Join(SettingsDir, "secrets", "web", "DB_PASSWORD.age"))
+	if err != nil || !strings.Contains(string(stored), "AGE ENCRYPTED") {
+		t.Errorf("expected the ciphertext to be stored, got %q, %v", stored, err)
+	}
+
+	secrets, err := ListSecrets("web")
+	if err != nil || len(secrets) != 1 || secrets[0].Name != "DB_PASSWORD" {
+		t.Errorf("unexpected secrets %v, %v", secrets, err)
+	}
+
+	runner.Respond("s3cret", nil, "age", "--decrypt")
+	vars, err := LoadSecrets(ctx, "web")
+	if err != nil || len(vars) != 1 || vars[0].Value != "s3cret" {
+		t.Errorf("unexpected variables %v, %v", vars, err)
+	}
+
+	if err := DeleteSecret("web", "DB_PASSWORD"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSecret(ctx, "web", "DB_PASSWORD"); err == nil {
+		t.Error("expected a deleted secret to be gone")
+	}
+}
+
+func TestGetSecretIsNotRecorded(t *testing.T) {
+	useTempSettingsDir(t)
+	file := filepath.Join(SettingsDir, "secrets", "web", "DB_PASSWORD.age")
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(file, []byte("-----BEGIN AGE ENCRYPTED FILE-----\n"), 0600)
+
+	mock := NewMockRunner()
+	mock.Respond("s3cret", nil, "age", "--decrypt")
+	recorder := NewRecordingRunner(mock, t.TempDir())
+	previous := SetRunner(recorder)
+	t.Cleanup(func() { SetRunner(previous) })
+
+	value, err := GetSecret(context.Background(), "web", "DB_PASSWORD")
+	if err != nil || string(value) != "s3cret" {
+		t.Fatalf("expected the secret, got %q, %v", value, err)
+	}
+	transcript, err := os.ReadFile(recorder.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(transcript), "s3cret") || !strings.Contains(string(transcript), RedactedOutput) {
+		t.Errorf("expected the plaintext to be redacted, got %s", transcript)
+	}
+}
+
+func TestSetSecretWithoutAge(t *testing.T) {
+	useTempSettingsDir(t)
+	previous := lookPath
+	t.Cleanup(func() { lookPath = previous })
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	useMockRunner(t)
+
+	if err := SetSecret(context.Background(), "web", "DB_PASSWORD", []byte("x")); err == nil || !strings.Contains(err.Error(), "age is not installed") {
+		t.Errorf("expected a missing age to be reported, got %v", err)
+	}
+	if err := SetSecret(context.Background(), "web", "db password", []byte("x")); err == nil {
+		t.Error("expected an invalid name to be rejected")
+	}
+}
+
+func TestSecretNamesCannotLeaveSecretsDir(t *testing.T) {
+	useTempSettingsDir(t)
+	outside := filepath.Join(SettingsDir, "x.age")
+	if err := os.WriteFile(outside, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteSecret("web", "../../x"); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error for a secret name with a path, got %v", err)
+	}
+	if err := DeleteSecret("../web", "DB_PASSWORD"); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error for a container name with a path, got %v", err)
+	}
+	if _, err := GetSecret(context.Background(), "web", "../../x"); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error from GetSecret, got %v", err)
+	}
+	if _, err := ListSecrets(".."); !errors.Is(err, ErrUsage) {
+		t.Errorf("expected a usage error from ListSecrets, got %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("expected %s to be kept, got %v", outside, err)
+	}
+}
+
+func TestPushSecretFile(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := PushSecretFile(context.Background(), "web", "/run/lxc-go-cli/secrets/app/DB_PASSWORD", "app", []byte("s3cret")); err != nil {
+		t.Fatal(err)
+	}
+	command := runner.Commands[0]
+	if strings.Join(command[:6], " ") != "lxc exec web -T -- sh" || command[len(command)-1] != "app" || strings.Contains(strings.Join(command, " "), "s3cret") {
+		t.Errorf("unexpected command %q", command)
+	}
+	if len(runner.Stdins) != 1 {
+		t.Error("expected the secret to be passed on stdin")
+	}
+}
@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SettingsDir holds this tool's own configuration; a variable so tests can redirect it
+var SettingsDir = defaultSettingsDir()
+
+func defaultSettingsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lxc-go-cli")
+}
+
+// Settings is this tool's persisted configuration
+type Settings struct {
+	// Context is the remote commands operate against; empty means the lxc default
+	Context string `yaml:"context,omitempty"`
+	// Project is the LXD project commands operate in; empty means the remote's own
+	Project string `yaml:"project,omitempty"`
+	// Password is the policy for generated 'app' user passwords
+	Password PasswordPolicy `yaml:"password,omitempty"`
+	// PasswordStore is where 'app' user passwords are kept
+	PasswordStore PasswordStoreSettings `yaml:"password_store,omitempty"`
+	// Current is the container commands use when none is named
+	Current CurrentContainer `yaml:"current,omitempty"`
+	// Aliases maps alias names to the command line they run
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+	// Notifications are sent on lifecycle events such as a failed provision
+	Notifications []Notification `yaml:"notifications,omitempty"`
+	// Exec holds the user exec runs as and whether commands get a login shell
+	Exec ExecSettings `yaml:"exec,omitempty"`
+	// Presets are named container sizes for create --preset and limits apply,
+	// added to or replacing the built-in small, medium and large
+	Presets map[string]SizePreset `yaml:"presets,omitempty"`
+	// AssumeYes skips confirmation prompts, as if --yes were always given
+	AssumeYes bool `yaml:"assume_yes,omitempty"`
+	// Language of messages, such as es; empty means the one of the locale
+	Language string `yaml:"language,omitempty"`
+	// Create holds defaults for create
+	Create CreateSettings `yaml:"create,omitempty"`
+}
+
+// CreateSettings are defaults for create that its flags override
+type CreateSettings struct {
+	// OnFailure is what happens to a container whose create fails: keep,
+	// destroy or snapshot; empty means destroy
+	OnFailure string `yaml:"on_failure,omitempty"`
+}
+
+// SettingsPath returns the path of the configuration file
+func SettingsPath() string {
+	return filepath.Join(SettingsDir, "config.yaml")
+}
+
+// LoadSettings reads the configuration file; a missing file yields empty settings
+func LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+	if SettingsDir == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(SettingsPath())
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", SettingsPath(), err)
+	}
+	return settings, nil
+}
+
+// SaveSettings writes the configuration file, replacing it atomically
+func SaveSettings(settings *Settings) error {
+	if SettingsDir == "" {
+		return fmt.Errorf("no user config directory available")
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := writeFileAtomic(SettingsPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file and a rename, so
+// concurrent readers never see a partial file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// SnapshotName builds a timestamped snapshot name such as "pre-update-20250101-120000"
+func SnapshotName(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s-%s", prefix, t.Format("20060102-150405"))
+}
+
+// CreateSnapshot takes a snapshot of a container
+func CreateSnapshot(containerName, snapshotName string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if snapshotName == "" {
+		return fmt.Errorf("snapshot name is required")
+	}
+
+	logger.Debug("Creating snapshot: lxc snapshot %s %s", containerName, snapshotName)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "snapshot", containerName, snapshotName)
+	if err != nil {
+		logger.Debug("Snapshot failed with output: %s", string(output))
+		return fmt.Errorf("failed to create snapshot '%s' of container '%s': %w (output: %s)", snapshotName, containerName, err, string(output))
+	}
+
+	return nil
+}
+
+// PublishSnapshot turns a container snapshot into an image with the given
+// alias, which outlives the container and can be launched to inspect it
+func PublishSnapshot(ctx context.Context, containerName, snapshotName, alias string) error {
+	logger.Debug("Publishing snapshot: lxc publish %s/%s --alias %s", containerName, snapshotName, alias)
+
+	output, err := Runner().RunWithOutput(ctx, "lxc", "publish", containerName+"/"+snapshotName, "--alias", alias)
+	if err != nil {
+		logger.Debug("Publish failed with output: %s", string(output))
+		return fmt.Errorf("failed to publish snapshot '%s' of container '%s' as image '%s': %w (output: %s)", snapshotName, containerName, alias, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AutoSnapshotPrefix marks snapshots taken automatically before risky operations
+const AutoSnapshotPrefix = "pre-"
+
+// Snapshot describes a container snapshot
+type Snapshot struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Stateful  bool      `json:"stateful"`
+}
+
+// IsAutomatic reports whether the snapshot was taken before a risky operation
+func (s Snapshot) IsAutomatic() bool {
+	return strings.HasPrefix(s.Name, AutoSnapshotPrefix)
+}
+
+// ListSnapshots returns a container's snapshots, oldest first
+func ListSnapshots(containerName string) ([]Snapshot, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+
+	path := fmt.Sprintf("/1.0/instances/%s/snapshots?recursion=1", containerName)
+	logger.Debug("Listing snapshots: lxc query %s", path)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "query", path)
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return nil, fmt.Errorf("failed to list snapshots of container '%s': %w (output: %s)", containerName, err, string(output))
+	}
+
+	return parseSnapshots(output)
+}
+
+// parseSnapshots parses the snapshot list returned by the LXD API
+func parseSnapshots(jsonOutput []byte) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	if err := json.Unmarshal(jsonOutput, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot list: %w", err)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// LatestAutoSnapshot returns the most recent automatic snapshot, or nil if there is none
+func LatestAutoSnapshot(snapshots []Snapshot) *Snapshot {
+	var latest *Snapshot
+	for i := range snapshots {
+		if !snapshots[i].IsAutomatic() {
+			continue
+		}
+		if latest == nil || snapshots[i].CreatedAt.After(latest.CreatedAt) {
+			latest = &snapshots[i]
+		}
+	}
+	return latest
+}
+
+// RestoreSnapshot restores a container to a snapshot
+func RestoreSnapshot(containerName, snapshotName string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if snapshotName == "" {
+		return fmt.Errorf("snapshot name is required")
+	}
+
+	logger.Debug("Restoring snapshot: lxc restore %s %s", containerName, snapshotName)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "restore", containerName, snapshotName)
+	if err != nil {
+		logger.Debug("Restore failed with output: %s", string(output))
+		return fmt.Errorf("failed to restore snapshot '%s' of container '%s': %w (output: %s)", snapshotName, containerName, err, string(output))
+	}
+
+	return nil
+}
+
+// FormatSnapshots renders a snapshot table
+func FormatSnapshots(snapshots []Snapshot) string {
+	if len(snapshots) == 0 {
+		return "No snapshots found\n"
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCREATED\tAUTOMATIC")
+	for _, snapshot := range snapshots {
+		automatic := "no"
+		if snapshot.IsAutomatic() {
+			automatic = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", snapshot.Name, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"), automatic)
+	}
+	w.Flush()
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// ScheduledSnapshotPrefix marks snapshots taken on a schedule; only these are pruned
+const ScheduledSnapshotPrefix = "scheduled-"
+
+// Instance config keys used for scheduled snapshots
+const (
+	SnapshotScheduleKey = "snapshots.schedule"
+	SnapshotPatternKey  = "snapshots.pattern"
+	SnapshotExpiryKey   = "snapshots.expiry"
+
+	// SnapshotKeepKey records the retention count enforced by snapshot prune
+	SnapshotKeepKey = "user.lxc-go-cli.snapshots.keep"
+)
+
+// ScheduledSnapshotPattern names LXD scheduled snapshots like SnapshotName does
+const ScheduledSnapshotPattern = ScheduledSnapshotPrefix + "{{ creation_date|date:'20060102-150405' }}"
+
+// SnapshotScheduleExtensions are the API extensions needed for LXD to take and expire snapshots itself
+var SnapshotScheduleExtensions = []string{"snapshot_scheduling", "snapshot_expiry"}
+
+// CronDir holds the fallback cron entries; a variable so tests can redirect it
+var CronDir = "/etc/cron.d"
+
+var cronAliases = map[string]time.Duration{
+	"@hourly":   time.Hour,
+	"@daily":    24 * time.Hour,
+	"@midnight": 24 * time.Hour,
+	"@weekly":   7 * 24 * time.Hour,
+	"@monthly":  0,
+	"@yearly":   0,
+	"@annually": 0,
+}
+
+// ValidateCronExpression checks for five cron fields or a supported @ alias
+func ValidateCronExpression(expr string) error {
+	expr = strings.TrimSpace(expr)
+	if _, ok := cronAliases[expr]; ok {
+		return nil
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return UsageErrorf("invalid cron expression '%s': expected 5 fields (minute hour day month weekday)", expr)
+	}
+	for _, field := range fields {
+		if strings.Trim(field, "0123456789*/,-") != "" {
+			return UsageErrorf("invalid cron expression '%s': unsupported field '%s'", expr, field)
+		}
+	}
+	return nil
+}
+
+// CronInterval estimates how often a simple hourly, daily or weekly schedule
+// fires; it returns 0 when the schedule is irregular
+func CronInterval(expr string) time.Duration {
+	expr = strings.TrimSpace(expr)
+	if interval, ok := cronAliases[expr]; ok {
+		return interval
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 || !isNumber(fields[0]) || fields[2] != "*" || fields[3] != "*" {
+		return 0
+	}
+	switch {
+	case fields[1] == "*" && fields[4] == "*":
+		return time.Hour
+	case isNumber(fields[1]) && fields[4] == "*":
+		return 24 * time.Hour
+	case isNumber(fields[1]) && isNumber(fields[4]):
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// SnapshotExpiry renders a duration in LXD's snapshots.expiry format, e.g. "7d" or "12H"
+func SnapshotExpiry(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dH", (d+time.Hour-1)/time.Hour)
+}
+
+// SnapshotsToPrune returns the scheduled snapshots beyond the newest keep;
+// manual and automatic pre-operation snapshots are never pruned
+func SnapshotsToPrune(snapshots []Snapshot, keep int) []Snapshot {
+	var scheduled []Snapshot
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.Name, ScheduledSnapshotPrefix) {
+			scheduled = append(scheduled, snapshot)
+		}
+	}
+	// Snapshots are listed oldest first
+	if len(scheduled) <= keep {
+		return nil
+	}
+	return scheduled[:len(scheduled)-keep]
+}
+
+// DeleteSnapshot deletes a container snapshot
+func DeleteSnapshot(containerName, snapshotName string) error {
+	target := containerName + "/" + snapshotName
+	logger.Debug("Deleting snapshot: lxc delete %s", target)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "delete", target)
+	if err != nil {
+		logger.Debug("Delete failed with output: %s", string(output))
+		return fmt.Errorf("failed to delete snapshot '%s' of container '%s': %w (output: %s)", snapshotName, containerName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// cronEntryPath returns the fallback cron file for a container
+func cronEntryPath(containerName string) string {
+	return filepath.Join(CronDir, "lxc-go-cli-snapshot-"+containerName)
+}
+
+// SnapshotCronEntry builds a cron.d entry that snapshots the container and then prunes it
+func SnapshotCronEntry(schedule, containerName, executable string, keep int) string {
+	// % is special in crontab lines and must be escaped
+	snapshot := fmt.Sprintf(`lxc snapshot %s %s$(date +\%%Y\%%m\%%d-\%%H\%%M\%%S)`, containerName, ScheduledSnapshotPrefix)
+	command := snapshot
+	if keep > 0 {
+		command += fmt.Sprintf(" && %s snapshot prune %s --keep %d", executable, containerName, keep)
+	}
+	return fmt.Sprintf("# Managed by lxc-go-cli: scheduled snapshots of %s\n%s root %s\n", containerName, schedule, command)
+}
+
+// WriteSnapshotCronEntry installs the fallback cron entry for a container
+func WriteSnapshotCronEntry(containerName, entry string) error {
+	path := cronEntryPath(containerName)
+	logger.Debug("Writing cron entry %s", path)
+	if err := os.WriteFile(path, []byte(entry), 0644); err != nil {
+		return fmt.Errorf("failed to write cron entry: %w", err)
+	}
+	return nil
+}
+
+// RemoveSnapshotCronEntry removes the fallback cron entry for a container, if any
+func RemoveSnapshotCronEntry(containerName string) error {
+	path := cronEntryPath(containerName)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cron entry: %w", err)
+	}
+	return nil
+}
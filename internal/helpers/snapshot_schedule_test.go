@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCronExpression(t *testing.T) {
+	for _, expr := range []string{"0 3 * * *", "*/15 * * * 1-5", "@daily"} {
+		if err := ValidateCronExpression(expr); err != nil {
+			t.Errorf("expected '%s' to be valid, got %v", expr, err)
+		}
+	}
+	for _, expr := range []string{"", "0 3 * *", "0 3 * * MON", "@sometimes"} {
+		if err := ValidateCronExpression(expr); err == nil {
+			t.Errorf("expected '%s' to be invalid", expr)
+		}
+	}
+}
+
+func TestCronInterval(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected time.Duration
+	}{
+		{"15 * * * *", time.Hour},
+		{"0 3 * * *", 24 * time.Hour},
+		{"0 3 * * 0", 7 * 24 * time.Hour},
+		{"@weekly", 7 * 24 * time.Hour},
+		{"0 */6 * * *", 0},
+		{"0 3 1 * *", 0},
+		{"@monthly", 0},
+	}
+	for _, tt := range tests {
+		if got := CronInterval(tt.expr); got != tt.expected {
+			t.Errorf("CronInterval(%q) = %v, expected %v", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestSnapshotExpiry(t *testing.T) {
+	if got := SnapshotExpiry(7 * 24 * time.Hour); got != "7d" {
+		t.Errorf("expected 7d, got %s", got)
+	}
+	if got := SnapshotExpiry(12 * time.Hour); got != "12H" {
+		t.Errorf("expected 12H, got %s", got)
+	}
+}
+
+func TestSnapshotsToPrune(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []Snapshot{
+		{Name: "scheduled-1", CreatedAt: base},
+		{Name: "pre-update-1", CreatedAt: base.Add(time.Hour)},
+		{Name: "scheduled-2", CreatedAt: base.Add(2 * time.Hour)},
+		{Name: "manual", CreatedAt: base.Add(3 * time.Hour)},
+		{Name: "scheduled-3", CreatedAt: base.Add(4 * time.Hour)},
+	}
+
+	prune := SnapshotsToPrune(snapshots, 2)
+	if len(prune) != 1 || prune[0].Name != "scheduled-1" {
+		t.Errorf("expected only the oldest scheduled snapshot to be pruned, got %v", prune)
+	}
+	if prune := SnapshotsToPrune(snapshots, 3); len(prune) != 0 {
+		t.Errorf("expected nothing to prune, got %v", prune)
+	}
+}
+
+func TestSnapshotCronEntry(t *testing.T) {
+	entry := SnapshotCronEntry("0 3 * * *", "web", "/usr/local/bin/lxc-go-cli", 7)
+	if !strings.Contains(entry, "0 3 * * * root lxc snapshot web scheduled-$(date +\\%Y\\%m\\%d-\\%H\\%M\\%S)") {
+		t.Errorf("unexpected snapshot command in entry:\n%s", entry)
+	}
+	if !strings.Contains(entry, "&& /usr/local/bin/lxc-go-cli snapshot prune web --keep 7") {
+		t.Errorf("expected prune command in entry:\n%s", entry)
+	}
+	if strings.Contains(SnapshotCronEntry("@daily", "web", "lxc-go-cli", 0), "prune") {
+		t.Error("no prune command expected without a retention count")
+	}
+}
+
+func TestSnapshotCronEntryFile(t *testing.T) {
+	original := CronDir
+	CronDir = t.TempDir()
+	defer func() { CronDir = original }()
+
+	if err := WriteSnapshotCronEntry("web", "entry\n"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(CronDir, "lxc-go-cli-snapshot-web"))
+	if err != nil || string(data) != "entry\n" {
+		t.Fatalf("expected cron entry to be written, got %q (%v)", data, err)
+	}
+
+	if err := RemoveSnapshotCronEntry("web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Removing a missing entry is not an error
+	if err := RemoveSnapshotCronEntry("web"); err != nil {
+		t.Errorf("expected no error for missing entry, got %v", err)
+	}
+}
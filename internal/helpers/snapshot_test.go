@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotName(t *testing.T) {
+	name := SnapshotName("pre-update", time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC))
+	if name != "pre-update-20250304-050607" {
+		t.Errorf("unexpected snapshot name: %s", name)
+	}
+}
+
+func TestCreateSnapshotValidation(t *testing.T) {
+	if err := CreateSnapshot("", "snap"); err == nil {
+		t.Error("expected error for empty container name")
+	}
+	if err := CreateSnapshot("web", ""); err == nil {
+		t.Error("expected error for empty snapshot name")
+	}
+	if err := RestoreSnapshot("", "snap"); err == nil {
+		t.Error("expected error for empty container name")
+	}
+}
+
+func TestPublishSnapshot(t *testing.T) {
+	runner := useMockRunner(t)
+	if err := PublishSnapshot(context.Background(), "web", "failed-20250101-120000", "failed-web-20250101-120000"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := runner.ExpectCommands([]string{"lxc", "publish", "web/failed-20250101-120000", "--alias", "failed-web-20250101-120000"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseSnapshots(t *testing.T) {
+	output := `[
+		{"name": "manual", "created_at": "2025-01-03T12:00:00Z", "stateful": false},
+		{"name": "pre-update-20250101-120000", "created_at": "2025-01-01T12:00:00Z", "stateful": false},
+		{"name": "pre-gpu-enable-20250102-120000", "created_at": "2025-01-02T12:00:00Z", "stateful": false}
+	]`
+
+	snapshots, err := parseSnapshots([]byte(output))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].Name != "pre-update-20250101-120000" {
+		t.Errorf("expected snapshots sorted oldest first, got %+v", snapshots)
+	}
+
+	latest := LatestAutoSnapshot(snapshots)
+	if latest == nil || latest.Name != "pre-gpu-enable-20250102-120000" {
+		t.Errorf("unexpected latest automatic snapshot: %+v", latest)
+	}
+
+	if LatestAutoSnapshot([]Snapshot{{Name: "manual"}}) != nil {
+		t.Error("expected no automatic snapshot")
+	}
+
+	if _, err := parseSnapshots([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestFormatSnapshots(t *testing.T) {
+	if FormatSnapshots(nil) != "No snapshots found\n" {
+		t.Error("expected placeholder for no snapshots")
+	}
+
+	output := FormatSnapshots([]Snapshot{{Name: "pre-update-1"}, {Name: "manual"}})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], "yes") || !strings.HasSuffix(lines[2], "no") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
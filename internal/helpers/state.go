@@ -0,0 +1,392 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// ContainerState is a snapshot of a container's status and resource counters
+type ContainerState struct {
+	Name   string
+	Status string
+	// Type is InstanceTypeContainer or InstanceTypeVM
+	Type      string
+	Ephemeral bool
+	// Location is the cluster member the instance runs on; empty when the
+	// server is not clustered
+	Location       string
+	Config         map[string]string
+	CPUUsageNs     int64
+	MemoryUsage    int64
+	DiskReadBytes  int64
+	DiskWriteBytes int64
+	NetRxBytes     int64
+	NetTxBytes     int64
+	DiskUsage      int64
+	IPv4           string
+	IPv6           string
+	IPv6Addresses  []string
+	Devices        map[string]map[string]string
+}
+
+// lxcListEntry mirrors the parts of `lxc list --format json` output we use
+type lxcListEntry struct {
+	Name            string                       `json:"name"`
+	Status          string                       `json:"status"`
+	Type            string                       `json:"type"`
+	Ephemeral       bool                         `json:"ephemeral"`
+	Location        string                       `json:"location"`
+	Config          map[string]string            `json:"config"`
+	ExpandedDevices map[string]map[string]string `json:"expanded_devices"`
+	State           *struct {
+		CPU struct {
+			Usage int64 `json:"usage"`
+		} `json:"cpu"`
+		Memory struct {
+			Usage int64 `json:"usage"`
+		} `json:"memory"`
+		Disk map[string]struct {
+			Usage int64 `json:"usage"`
+		} `json:"disk"`
+		Network map[string]struct {
+			Addresses []struct {
+				Family  string `json:"family"`
+				Address string `json:"address"`
+				Scope   string `json:"scope"`
+			} `json:"addresses"`
+			Counters struct {
+				BytesReceived int64 `json:"bytes_received"`
+				BytesSent     int64 `json:"bytes_sent"`
+			} `json:"counters"`
+		} `json:"network"`
+	} `json:"state"`
+}
+
+// Marker keys tag containers created or adopted by this tool, recording the
+// tool version and, for created containers, when they were created
+const (
+	ManagedMarkerKey  = "user.lxc-go-cli.managed"
+	ManagedVersionKey = "user.lxc-go-cli.version"
+	ManagedCreatedKey = "user.lxc-go-cli.created"
+
+	// ProvisionStepsKey lists the create steps completed so far, so an
+	// interrupted create can be resumed
+	ProvisionStepsKey = "user.lxc-go-cli.provisioned-steps"
+)
+
+// ManagedMarkerConfig returns the marker config for a container; a zero
+// created time leaves out the creation timestamp
+func ManagedMarkerConfig(version string, created time.Time) map[string]string {
+	marker := map[string]string{
+		ManagedMarkerKey:  "true",
+		ManagedVersionKey: version,
+	}
+	if !created.IsZero() {
+		marker[ManagedCreatedKey] = created.UTC().Format(time.RFC3339)
+	}
+	return marker
+}
+
+// StatusFrozen is the status LXD reports for a paused container
+const StatusFrozen = "Frozen"
+
+// GetContainerStatus returns the status of a single container, e.g. "Running" or "Frozen"
+func GetContainerStatus(name string) (string, error) {
+	output, err := runOutput(context.Background(), "lxc", "query", "/1.0/instances/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of container '%s': %w", name, err)
+	}
+	return parseInstanceStatus(output)
+}
+
+// parseInstanceStatus extracts the status from a GET /1.0/instances/<name> response
+func parseInstanceStatus(jsonOutput []byte) (string, error) {
+	var instance struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(jsonOutput, &instance); err != nil {
+		return "", fmt.Errorf("failed to parse container status: %w", err)
+	}
+	return instance.Status, nil
+}
+
+// IsManagedContainer reports whether a container was set up or adopted by this tool
+func IsManagedContainer(config map[string]string) bool {
+	if config[ManagedMarkerKey] == "true" {
+		return true
+	}
+	_, ok := config["user.app-password"]
+	return ok
+}
+
+// ListContainers returns the status and configuration of all containers
+func ListContainers() ([]ContainerState, error) {
+	logger.Debug("Listing containers: lxc list --format json")
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "list", "--format", "json")
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return nil, fmt.Errorf("failed to list containers: %w (output: %s)", err, string(output))
+	}
+
+	return parseContainerStates(output)
+}
+
+// GetContainerIPv4 returns the container's primary IPv4 address, or "" if it has none yet
+func GetContainerIPv4(name string) (string, error) {
+	state, err := containerAddresses(name)
+	if err != nil {
+		return "", err
+	}
+	return state.IPv4, nil
+}
+
+// GetContainerIPv6Addresses returns the container's global IPv6 addresses
+func GetContainerIPv6Addresses(name string) ([]string, error) {
+	state, err := containerAddresses(name)
+	if err != nil {
+		return nil, err
+	}
+	return state.IPv6Addresses, nil
+}
+
+// containerAddresses reads the state, with its addresses, of one container
+func containerAddresses(name string) (ContainerState, error) {
+	output, err := runOutput(context.Background(), "lxc", "list", "^"+regexp.QuoteMeta(name)+"$", "--format", "json")
+	if err != nil {
+		return ContainerState{}, fmt.Errorf("failed to get addresses of container '%s': %w", name, err)
+	}
+	states, err := parseContainerStates(output)
+	if err != nil {
+		return ContainerState{}, err
+	}
+	for _, state := range states {
+		if state.Name == name {
+			return state, nil
+		}
+	}
+	return ContainerState{}, NotFoundErrorf("container '%s' does not exist", name)
+}
+
+// ListManagedContainers returns the names of running containers managed by this tool
+func ListManagedContainers() ([]string, error) {
+	return ListManagedContainersMatching(nil)
+}
+
+// ListManagedContainersMatching returns the names of running managed
+// containers whose labels match the selector
+func ListManagedContainersMatching(selector LabelSelector) ([]string, error) {
+	states, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	return managedRunningNames(FilterContainersBySelector(states, selector)), nil
+}
+
+// FilterManagedContainers keeps only managed containers unless includeUnmanaged is set
+func FilterManagedContainers(states []ContainerState, includeUnmanaged bool) []ContainerState {
+	if includeUnmanaged {
+		return states
+	}
+	var managed []ContainerState
+	for _, state := range states {
+		if IsManagedContainer(state.Config) {
+			managed = append(managed, state)
+		}
+	}
+	return managed
+}
+
+// managedRunningNames filters states down to the names of running managed containers
+func managedRunningNames(states []ContainerState) []string {
+	var names []string
+	for _, state := range states {
+		if IsManagedContainer(state.Config) && state.Status == "Running" {
+			names = append(names, state.Name)
+		}
+	}
+	return names
+}
+
+// ListContainerStates returns the status and resource counters of all containers
+func ListContainerStates() ([]ContainerState, error) {
+	states, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Disk IO counters are only exposed through the metrics endpoint
+	metrics, err := runOutput(context.Background(), "lxc", "query", "/1.0/metrics")
+	if err != nil {
+		logger.Debug("Metrics endpoint unavailable, disk IO will not be reported: %v", err)
+		return states, nil
+	}
+
+	diskIO := parseDiskMetrics(string(metrics))
+	for i := range states {
+		if counters, ok := diskIO[states[i].Name]; ok {
+			states[i].DiskReadBytes = counters[0]
+			states[i].DiskWriteBytes = counters[1]
+		}
+	}
+
+	return states, nil
+}
+
+// parseContainerStates parses `lxc list --format json` output
+func parseContainerStates(jsonOutput []byte) ([]ContainerState, error) {
+	var entries []lxcListEntry
+	if err := json.Unmarshal(jsonOutput, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse container list: %w", err)
+	}
+
+	states := make([]ContainerState, 0, len(entries))
+	for _, entry := range entries {
+		state := ContainerState{
+			Name:      entry.Name,
+			Status:    entry.Status,
+			Type:      entry.Type,
+			Ephemeral: entry.Ephemeral,
+			Config:    entry.Config,
+			Devices:   entry.ExpandedDevices,
+		}
+		if state.Config == nil {
+			state.Config = map[string]string{}
+		}
+		if state.Type == "" {
+			state.Type = InstanceTypeContainer
+		}
+		// Standalone servers report their instances as located on "none"
+		if entry.Location != "none" {
+			state.Location = entry.Location
+		}
+		if entry.State != nil {
+			state.CPUUsageNs = entry.State.CPU.Usage
+			state.MemoryUsage = entry.State.Memory.Usage
+			state.DiskUsage = entry.State.Disk["root"].Usage
+			for iface, nic := range entry.State.Network {
+				if iface == "lo" {
+					continue
+				}
+				state.NetRxBytes += nic.Counters.BytesReceived
+				state.NetTxBytes += nic.Counters.BytesSent
+			}
+			state.IPv4, state.IPv6 = primaryAddresses(entry)
+			state.IPv6Addresses = globalIPv6Addresses(entry)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// primaryAddresses returns the global IPv4 and IPv6 addresses of the container,
+// preferring eth0 when several interfaces are up
+func primaryAddresses(entry lxcListEntry) (ipv4, ipv6 string) {
+	ifaces := make([]string, 0, len(entry.State.Network))
+	for iface := range entry.State.Network {
+		if iface != "lo" {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	sort.Slice(ifaces, func(i, j int) bool {
+		if (ifaces[i] == "eth0") != (ifaces[j] == "eth0") {
+			return ifaces[i] == "eth0"
+		}
+		return ifaces[i] < ifaces[j]
+	})
+
+	for _, iface := range ifaces {
+		for _, addr := range entry.State.Network[iface].Addresses {
+			if addr.Scope != "global" {
+				continue
+			}
+			if addr.Family == "inet" && ipv4 == "" {
+				ipv4 = addr.Address
+			}
+			if addr.Family == "inet6" && ipv6 == "" {
+				ipv6 = addr.Address
+			}
+		}
+	}
+	return ipv4, ipv6
+}
+
+// globalIPv6Addresses returns every global IPv6 address of the container,
+// in interface order
+func globalIPv6Addresses(entry lxcListEntry) []string {
+	ifaces := make([]string, 0, len(entry.State.Network))
+	for iface := range entry.State.Network {
+		if iface != "lo" {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	sort.Strings(ifaces)
+
+	var addresses []string
+	for _, iface := range ifaces {
+		for _, addr := range entry.State.Network[iface].Addresses {
+			if addr.Family == "inet6" && addr.Scope == "global" {
+				addresses = append(addresses, addr.Address)
+			}
+		}
+	}
+	return addresses
+}
+
+var diskMetricPattern = regexp.MustCompile(`^lxd_disk_(read|written)_bytes_total\{([^}]*)\}\s+([0-9.eE+]+)`)
+var metricNamePattern = regexp.MustCompile(`(?:^|,)name="([^"]*)"`)
+
+// parseDiskMetrics extracts per-instance disk read/write byte totals from OpenMetrics text
+func parseDiskMetrics(metrics string) map[string][2]int64 {
+	result := make(map[string][2]int64)
+
+	scanner := bufio.NewScanner(strings.NewReader(metrics))
+	for scanner.Scan() {
+		matches := diskMetricPattern.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+		nameMatch := metricNamePattern.FindStringSubmatch(matches[2])
+		if nameMatch == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(matches[3], 64)
+		if err != nil {
+			continue
+		}
+
+		counters := result[nameMatch[1]]
+		if matches[1] == "read" {
+			counters[0] += int64(value)
+		} else {
+			counters[1] += int64(value)
+		}
+		result[nameMatch[1]] = counters
+	}
+
+	return result
+}
+
+// FormatBytes renders a byte count using binary units
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,179 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseContainerStates(t *testing.T) {
+	jsonOutput := `[
+  {
+    "name": "web",
+    "status": "Running",
+    "location": "none",
+    "config": {"user.app-password": "c2VjcmV0"},
+    "state": {
+      "cpu": {"usage": 123456},
+      "memory": {"usage": 4096},
+      "disk": {"root": {"usage": 2048}},
+      "network": {
+        "eth0": {"counters": {"bytes_received": 100, "bytes_sent": 50}},
+        "lo": {"counters": {"bytes_received": 999, "bytes_sent": 999}}
+      }
+    }
+  },
+  {"name": "stopped", "status": "Stopped", "type": "virtual-machine", "ephemeral": true, "location": "member2", "state": null}
+]`
+
+	states, err := parseContainerStates([]byte(jsonOutput))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("expected 2 states, got %d", len(states))
+	}
+
+	web := states[0]
+	if web.CPUUsageNs != 123456 || web.MemoryUsage != 4096 {
+		t.Errorf("unexpected counters: %+v", web)
+	}
+	if web.DiskUsage != 2048 {
+		t.Errorf("expected root disk usage 2048, got %d", web.DiskUsage)
+	}
+	if web.NetRxBytes != 100 || web.NetTxBytes != 50 {
+		t.Errorf("loopback traffic should be ignored: %+v", web)
+	}
+	if !IsManagedContainer(web.Config) {
+		t.Error("web should be managed")
+	}
+	if states[1].Config == nil || IsManagedContainer(states[1].Config) {
+		t.Error("stopped should have an empty, unmanaged config")
+	}
+	if web.Ephemeral || !states[1].Ephemeral {
+		t.Error("only stopped should be ephemeral")
+	}
+	if web.Type != InstanceTypeContainer || states[1].Type != InstanceTypeVM {
+		t.Errorf("expected a container and a VM, got %q and %q", web.Type, states[1].Type)
+	}
+	if web.Location != "" || states[1].Location != "member2" {
+		t.Errorf("expected only the clustered location, got %q and %q", web.Location, states[1].Location)
+	}
+
+	if _, err := parseContainerStates([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseDiskMetrics(t *testing.T) {
+	metrics := `# HELP lxd_disk_read_bytes_total The total number of bytes read.
+# TYPE lxd_disk_read_bytes_total counter
+lxd_disk_read_bytes_total{device="sda",name="web",project="default",type="container"} 1024
+lxd_disk_read_bytes_total{device="sdb",name="web",project="default",type="container"} 1024
+lxd_disk_written_bytes_total{device="sda",name="web",project="default",type="container"} 512
+lxd_cpu_seconds_total{cpu="0",mode="user",name="web",project="default",type="container"} 10
+`
+
+	result := parseDiskMetrics(metrics)
+	counters, ok := result["web"]
+	if !ok {
+		t.Fatal("expected counters for 'web'")
+	}
+	if counters[0] != 2048 || counters[1] != 512 {
+		t.Errorf("unexpected counters: %v", counters)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                      "0B",
+		512:                    "512B",
+		1024:                   "1.0KiB",
+		1536:                   "1.5KiB",
+		5 * 1024 * 1024:        "5.0MiB",
+		3 * 1024 * 1024 * 1024: "3.0GiB",
+	}
+	for input, expected := range tests {
+		if got := FormatBytes(input); got != expected {
+			t.Errorf("FormatBytes(%d) = %s, want %s", input, got, expected)
+		}
+	}
+}
+
+func TestManagedRunningNames(t *testing.T) {
+	states := []ContainerState{
+		{Name: "web1", Status: "Running", Config: map[string]string{"user.app-password": "x"}},
+		{Name: "web2", Status: "Stopped", Config: map[string]string{"user.app-password": "x"}},
+		{Name: "other", Status: "Running", Config: map[string]string{}},
+	}
+
+	names := managedRunningNames(states)
+	if len(names) != 1 || names[0] != "web1" {
+		t.Errorf("expected only web1, got %v", names)
+	}
+}
+
+func TestIsManagedContainerMarker(t *testing.T) {
+	if !IsManagedContainer(map[string]string{ManagedMarkerKey: "true"}) {
+		t.Error("container with marker should be managed")
+	}
+	if IsManagedContainer(map[string]string{ManagedMarkerKey: "false"}) {
+		t.Error("container with false marker should not be managed")
+	}
+}
+
+func TestManagedMarkerConfig(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	marker := ManagedMarkerConfig("1.abc", created)
+	if marker[ManagedMarkerKey] != "true" || marker[ManagedVersionKey] != "1.abc" || marker[ManagedCreatedKey] != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected marker: %v", marker)
+	}
+
+	if _, ok := ManagedMarkerConfig("1.abc", time.Time{})[ManagedCreatedKey]; ok {
+		t.Error("zero creation time should be left out")
+	}
+}
+
+func TestFilterManagedContainers(t *testing.T) {
+	states := []ContainerState{
+		{Name: "web", Config: map[string]string{ManagedMarkerKey: "true"}},
+		{Name: "other", Config: map[string]string{}},
+	}
+	if managed := FilterManagedContainers(states, false); len(managed) != 1 || managed[0].Name != "web" {
+		t.Errorf("expected only web, got %v", managed)
+	}
+	if all := FilterManagedContainers(states, true); len(all) != 2 {
+		t.Errorf("expected all containers, got %v", all)
+	}
+}
+
+func TestParseInstanceStatus(t *testing.T) {
+	status, err := parseInstanceStatus([]byte(`{"name":"web","status":"Frozen","status_code":110}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status != StatusFrozen {
+		t.Errorf("expected %s, got %s", StatusFrozen, status)
+	}
+
+	if _, err := parseInstanceStatus([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestGetContainerIPv4(t *testing.T) {
+	mock := useMockRunner(t)
+	mock.Respond(`[{"name":"web","status":"Running","state":{"network":{"eth0":{"addresses":[{"family":"inet","address":"10.0.0.5","scope":"global"}]}}}}]`,
+		nil, "lxc", "list")
+
+	ip, err := GetContainerIPv4("web")
+	if err != nil || ip != "10.0.0.5" {
+		t.Errorf("expected 10.0.0.5, got %q (%v)", ip, err)
+	}
+	if !mock.Ran("lxc", "list", "^web$", "--format", "json") {
+		t.Errorf("expected an anchored list filter, got %v", mock.Commands)
+	}
+
+	if _, err := GetContainerIPv4("other"); err == nil {
+		t.Error("expected error for a missing container")
+	}
+}
@@ -0,0 +1,179 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+	"github.com/deji/lxc-go-cli/internal/render"
+)
+
+// SupportedStorageDrivers lists the LXD storage drivers accepted by storage create
+var SupportedStorageDrivers = []string{"btrfs", "zfs", "lvm", "dir"}
+
+var storageSizePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(B|[KMGTPE]i?B?)$`)
+
+// StoragePoolOptions holds the settings for creating a storage pool
+type StoragePoolOptions struct {
+	Name   string
+	Driver string
+	Size   string
+	Source string
+}
+
+// ValidateStorageSize checks that a size string is in a format LXD understands (e.g. 50G, 512MiB)
+func ValidateStorageSize(size string) error {
+	if !storageSizePattern.MatchString(size) {
+		return UsageErrorf("invalid size '%s': expected a number followed by a unit (e.g. 10G, 50GiB, 512MB)", size)
+	}
+	return nil
+}
+
+// ValidateStoragePoolOptions validates the options for creating a storage pool
+func ValidateStoragePoolOptions(opts StoragePoolOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("storage pool name is required")
+	}
+
+	validDriver := false
+	for _, driver := range SupportedStorageDrivers {
+		if opts.Driver == driver {
+			validDriver = true
+			break
+		}
+	}
+	if !validDriver {
+		return UsageErrorf("invalid driver '%s': must be one of %s", opts.Driver, strings.Join(SupportedStorageDrivers, ", "))
+	}
+
+	if opts.Size != "" {
+		if opts.Source != "" && strings.HasPrefix(opts.Source, "/dev/") {
+			return fmt.Errorf("--size cannot be used with a block device source; the pool uses the whole device")
+		}
+		if err := ValidateStorageSize(opts.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BuildStorageCreateArgs returns the lxc arguments for creating a storage pool
+func BuildStorageCreateArgs(opts StoragePoolOptions) []string {
+	args := []string{"storage", "create", opts.Name, opts.Driver}
+	if opts.Size != "" {
+		args = append(args, fmt.Sprintf("size=%s", opts.Size))
+	}
+	if opts.Source != "" {
+		args = append(args, fmt.Sprintf("source=%s", opts.Source))
+	}
+	return args
+}
+
+// CreateStoragePool creates a storage pool with an explicit driver, size and source
+func CreateStoragePool(opts StoragePoolOptions) error {
+	if err := ValidateStoragePoolOptions(opts); err != nil {
+		return err
+	}
+
+	args := BuildStorageCreateArgs(opts)
+
+	logger.Debug("Executing: lxc %v", args)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return fmt.Errorf("failed to create storage pool '%s': %w (output: %s)", opts.Name, err, string(output))
+	}
+
+	logger.Debug("Command succeeded with output: %s", string(output))
+	return nil
+}
+
+// ListStoragePools returns all storage pools known to LXD
+func ListStoragePools() ([]StoragePool, error) {
+	logger.Debug("Listing storage pools: lxc storage list -f json")
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", "storage", "list", "-f", "json")
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return nil, fmt.Errorf("failed to list storage pools: %w (output: %s)", err, string(output))
+	}
+
+	return parseStoragePools(output)
+}
+
+// parseStoragePools parses `lxc storage list -f json` output
+func parseStoragePools(jsonOutput []byte) ([]StoragePool, error) {
+	var pools []StoragePool
+	if err := json.Unmarshal(jsonOutput, &pools); err != nil {
+		return nil, fmt.Errorf("failed to parse storage pool list: %w", err)
+	}
+	return pools, nil
+}
+
+// GetStoragePool returns the named storage pool
+func GetStoragePool(name string) (*StoragePool, error) {
+	pools, err := ListStoragePools()
+	if err != nil {
+		return nil, err
+	}
+	return findStoragePool(pools, name)
+}
+
+// findStoragePool looks up a pool by name
+func findStoragePool(pools []StoragePool, name string) (*StoragePool, error) {
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i], nil
+		}
+	}
+	return nil, NotFoundErrorf("storage pool '%s' does not exist", name)
+}
+
+// FormatStoragePools formats storage pools for display
+func FormatStoragePools(pools []StoragePool) string {
+	if len(pools) == 0 {
+		return "No storage pools found\n"
+	}
+	return StoragePoolTable(pools).String()
+}
+
+// StoragePoolTable lays out storage pools as a table
+func StoragePoolTable(pools []StoragePool) *render.Table {
+	table := render.NewTable("NAME", "DRIVER", "SIZE", "SOURCE", "USED BY")
+	for _, pool := range pools {
+		size := pool.Config["size"]
+		if size == "" {
+			size = "-"
+		}
+		source := pool.Config["source"]
+		if source == "" {
+			source = "-"
+		}
+		table.AddRow(pool.Name, pool.Driver, size, source, strconv.Itoa(len(pool.UsedBy)))
+	}
+	return table
+}
+
+// SetRootDiskSize limits a container's root disk. A root disk inherited from a
+// profile is overridden on the container rather than changing the profile.
+func SetRootDiskSize(containerName, size string, local bool) error {
+	action := "override"
+	if local {
+		action = "set"
+	}
+	args := []string{"config", "device", action, containerName, "root", "size=" + size}
+	logger.Debug("Setting root disk size: lxc %v", args)
+
+	output, err := Runner().RunWithOutput(context.Background(), "lxc", args...)
+	if err != nil {
+		logger.Debug("Command failed with output: %s", string(output))
+		return fmt.Errorf("failed to set root disk size of container '%s': %w (output: %s)", containerName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
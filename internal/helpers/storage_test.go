@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateStorageSize(t *testing.T) {
+	valid := []string{"10G", "50GiB", "512MB", "1.5T", "100B"}
+	for _, size := range valid {
+		if err := ValidateStorageSize(size); err != nil {
+			t.Errorf("expected '%s' to be valid, got %v", size, err)
+		}
+	}
+
+	invalid := []string{"", "G", "10", "ten gigs", "-5G", "10X"}
+	for _, size := range invalid {
+		if err := ValidateStorageSize(size); err == nil {
+			t.Errorf("expected '%s' to be invalid", size)
+		}
+	}
+}
+
+func TestValidateStoragePoolOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    StoragePoolOptions
+		wantErr string
+	}{
+		{name: "valid loop pool", opts: StoragePoolOptions{Name: "p", Driver: "btrfs", Size: "50G"}},
+		{name: "valid device pool", opts: StoragePoolOptions{Name: "p", Driver: "btrfs", Source: "/dev/sdb"}},
+		{name: "missing name", opts: StoragePoolOptions{Driver: "btrfs"}, wantErr: "name is required"},
+		{name: "bad driver", opts: StoragePoolOptions{Name: "p", Driver: "ext4"}, wantErr: "invalid driver"},
+		{name: "size with device", opts: StoragePoolOptions{Name: "p", Driver: "btrfs", Size: "5G", Source: "/dev/sdb"}, wantErr: "cannot be used with a block device"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStoragePoolOptions(tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing '%s', got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildStorageCreateArgs(t *testing.T) {
+	args := BuildStorageCreateArgs(StoragePoolOptions{Name: "fast", Driver: "btrfs", Size: "50G"})
+	if strings.Join(args, " ") != "storage create fast btrfs size=50G" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	args = BuildStorageCreateArgs(StoragePoolOptions{Name: "disk", Driver: "btrfs", Source: "/dev/sdb"})
+	if strings.Join(args, " ") != "storage create disk btrfs source=/dev/sdb" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestParseStoragePools(t *testing.T) {
+	jsonOutput := `[{"name":"default","driver":"dir","config":{"source":"/var/lib/lxd"},"used_by":["/1.0/profiles/default"]},{"name":"fast","driver":"btrfs","config":{"size":"50GiB"}}]`
+
+	pools, err := parseStoragePools([]byte(jsonOutput))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(pools) != 2 {
+		t.Fatalf("expected 2 pools, got %d", len(pools))
+	}
+
+	pool, err := findStoragePool(pools, "fast")
+	if err != nil || pool.Config["size"] != "50GiB" {
+		t.Errorf("expected to find 'fast' with size, got %+v, %v", pool, err)
+	}
+	if _, err := findStoragePool(pools, "missing"); err == nil {
+		t.Error("expected error for missing pool")
+	}
+
+	if _, err := parseStoragePools([]byte("oops")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestFormatStoragePools(t *testing.T) {
+	if !strings.Contains(FormatStoragePools(nil), "No storage pools found") {
+		t.Error("expected empty message")
+	}
+
+	output := FormatStoragePools([]StoragePool{{Name: "fast", Driver: "btrfs"}})
+	if !strings.Contains(output, "fast") || !strings.Contains(output, "btrfs") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
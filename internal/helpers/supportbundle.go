@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SupportLogLines is how many recent lines of each log a support bundle keeps
+const SupportLogLines = 300
+
+// RedactedValue replaces secrets in a support bundle
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeyPattern matches config keys and variables whose values must not
+// leave the machine, such as user.app-password. Every environment.* key is
+// included, as variables like DATABASE_URL carry credentials under any name.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(^environment\.|password|passwd|secret|token|credential|private|api[-_.]?key|user-data|vendor-data|network-config)`)
+
+// yamlKeyLinePattern matches a "key: value" line, capturing its indent, key
+// and value. Keys have no spaces, which keeps prose such as doctor results
+// from matching.
+var yamlKeyLinePattern = regexp.MustCompile(`^(\s*-?\s*)([^\s:#][^\s:]*):(?:\s+(.*))?$`)
+
+// envAssignmentPattern matches KEY=value secrets in command lines and logs
+var envAssignmentPattern = regexp.MustCompile(`(?i)\b([A-Z0-9_.-]*(?:password|passwd|secret|token|api[-_]?key)[A-Z0-9_.-]*)=(\S+)`)
+
+// urlUserinfoPattern matches the user and password of URLs such as
+// postgres://user:pass@db/app
+var urlUserinfoPattern = regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9+.-]*://)[^\s/@]+@`)
+
+// SupportFile is one file in a support bundle
+type SupportFile struct {
+	Name string
+	Data []byte
+}
+
+// RedactSecrets hides the values of sensitive keys in YAML such as 'lxc
+// config show' output, including multi-line values, and of KEY=value
+// assignments and URL credentials anywhere in text
+func RedactSecrets(text string) string {
+	lines := strings.Split(text, "\n")
+	redacted := make([]string, 0, len(lines))
+	// While skipping a redacted block value, lines indented deeper than
+	// blockIndent belong to it
+	blockIndent := -1
+	for _, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if strings.TrimSpace(line) == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+
+		if match := yamlKeyLinePattern.FindStringSubmatch(line); match != nil && sensitiveKeyPattern.MatchString(match[2]) {
+			value := strings.TrimSpace(match[3])
+			if value == "" || value == `""` || value == "''" {
+				redacted = append(redacted, line)
+				continue
+			}
+			if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+				blockIndent = indent
+			}
+			redacted = append(redacted, match[1]+match[2]+": "+RedactedValue)
+			continue
+		}
+		line = envAssignmentPattern.ReplaceAllString(line, "$1="+RedactedValue)
+		redacted = append(redacted, urlUserinfoPattern.ReplaceAllString(line, "${1}"+RedactedValue+"@"))
+	}
+	return strings.Join(redacted, "\n")
+}
+
+// TailLines returns the last n lines of data
+func TailLines(data []byte, n int) []byte {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// ReadAuditLogTail returns the last n lines of the audit log, or nothing when
+// there is no audit log yet
+func ReadAuditLogTail(n int) ([]byte, error) {
+	data, err := os.ReadFile(AuditLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return TailLines(data, n), nil
+}
+
+// CollectHostInfo describes the host: kernel, distribution, memory and disk
+func CollectHostInfo(ctx context.Context) []byte {
+	var sb strings.Builder
+	for _, argv := range [][]string{
+		{"uname", "-a"},
+		{"cat", "/etc/os-release"},
+		{"uptime"},
+		{"free", "-h"},
+		{"df", "-h"},
+	} {
+		fmt.Fprintf(&sb, "$ %s\n", strings.Join(argv, " "))
+		output, err := Runner().RunWithOutput(ctx, argv[0], argv[1:]...)
+		sb.Write(output)
+		if err != nil {
+			fmt.Fprintf(&sb, "(failed: %v)\n", err)
+		}
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+// CollectCommandOutput runs a command for a support bundle, keeping its
+// output also when it fails
+func CollectCommandOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := Runner().RunWithOutput(ctx, name, args...)
+	if err != nil {
+		return output, fmt.Errorf("%s %s failed: %w", name, strings.Join(args, " "), err)
+	}
+	return output, nil
+}
+
+// SupportBundleName returns the default file name of a bundle made at now
+func SupportBundleName(now time.Time) string {
+	return "lxc-go-cli-support-" + now.UTC().Format("20060102-150405") + ".tar.gz"
+}
+
+// WriteSupportBundle writes files into a gzipped tarball at file, under a
+// directory named after it
+func WriteSupportBundle(file string, files []SupportFile, now time.Time) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	dir := strings.TrimSuffix(path.Base(file), ".tar.gz")
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    path.Join(dir, f.Name),
+			Mode:    0600,
+			Size:    int64(len(f.Data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s to the support bundle: %w", f.Name, err)
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return fmt.Errorf("failed to add %s to the support bundle: %w", f.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write the support bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write the support bundle: %w", err)
+	}
+	// The bundle may still hold details of the host; keep it private
+	if err := os.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write the support bundle: %w", err)
+	}
+	return nil
+}
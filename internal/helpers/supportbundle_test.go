@@ -0,0 +1,154 @@
+package helpers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	input := `architecture: x86_64
+config:
+  environment.API_TOKEN: abc123
+  environment.TZ: UTC
+  environment.DATABASE_URL: postgres://app:dbpass@db:5432/app
+  user.app-password: hunter2
+  user.empty-secret: ""
+  cloud-init.user-data: |
+    #cloud-config
+    password: inline
+    chpasswd: { expire: false }
+  image.os: Ubuntu
+devices:
+  root:
+    path: /
+ephemeral: false
+[PASS] password store: Vault is reachable
+Jan 01 host app[1]: starting with DB_PASSWORD=s3cret PORT=80
+Jan 01 host app[1]: connecting to redis://:redispass@cache:6379/0 and https://example.com/a@b`
+
+	got := RedactSecrets(input)
+	for _, secret := range []string{"abc123", "UTC", "dbpass", "hunter2", "#cloud-config", "inline", "s3cret", "redispass"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected '%s' to be redacted, got:\n%s", secret, got)
+		}
+	}
+	for _, kept := range []string{
+		"environment.API_TOKEN: " + RedactedValue,
+		"  cloud-init.user-data: " + RedactedValue + "\n  image.os: Ubuntu",
+		"environment.TZ: " + RedactedValue,
+		"environment.DATABASE_URL: " + RedactedValue,
+		`user.empty-secret: ""`,
+		"path: /",
+		"[PASS] password store: Vault is reachable",
+		"DB_PASSWORD=" + RedactedValue + " PORT=80",
+		"redis://" + RedactedValue + "@cache:6379/0",
+		"https://example.com/a@b",
+	} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("expected output to contain '%s', got:\n%s", kept, got)
+		}
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := string(TailLines([]byte("a\nb\nc\n"), 2)); got != "b\nc\n" {
+		t.Errorf("expected the last 2 lines, got %q", got)
+	}
+	if got := string(TailLines([]byte("a\nb"), 5)); got != "a\nb\n" {
+		t.Errorf("expected all lines, got %q", got)
+	}
+}
+
+func TestReadAuditLogTail(t *testing.T) {
+	originalDir := SettingsDir
+	SettingsDir = t.TempDir()
+	t.Cleanup(func() { SettingsDir = originalDir })
+
+	data, err := ReadAuditLogTail(2)
+	if err != nil || data != nil {
+		t.Fatalf("expected nothing without an audit log, got %q, %v", data, err)
+	}
+
+	if err := os.WriteFile(AuditLogPath(), []byte("1\n2\n3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ReadAuditLogTail(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "2\n3\n" {
+		t.Errorf("expected the last 2 lines, got %q", data)
+	}
+}
+
+func TestCollectHostInfo(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("Linux host 6.8.0\n", nil, "uname", "-a")
+	runner.Respond("", &MockExitError{Code: 127}, "free", "-h")
+
+	info := string(CollectHostInfo(context.Background()))
+	for _, expected := range []string{"$ uname -a\nLinux host 6.8.0", "$ cat /etc/os-release", "$ free -h\n(failed:"} {
+		if !strings.Contains(info, expected) {
+			t.Errorf("expected host info to contain %q, got:\n%s", expected, info)
+		}
+	}
+}
+
+func TestSupportBundleName(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	if got := SupportBundleName(now); got != "lxc-go-cli-support-20250304-050607.tar.gz" {
+		t.Errorf("unexpected name %s", got)
+	}
+}
+
+func TestWriteSupportBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	files := []SupportFile{
+		{Name: "version.txt", Data: []byte("lxc-go-cli dev\n")},
+		{Name: "container/config.yaml", Data: []byte("config: {}\n")},
+	}
+	if err := WriteSupportBundle(path, files, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	contents := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		contents[header.Name] = string(data)
+	}
+	if contents["bundle/version.txt"] != "lxc-go-cli dev\n" || contents["bundle/container/config.yaml"] != "config: {}\n" {
+		t.Errorf("unexpected bundle contents: %v", contents)
+	}
+}
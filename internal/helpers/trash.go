@@ -0,0 +1,194 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// Keys recording why a container is in the trash. A trashed container is
+// stopped and renamed to a trash name, so its own name can be reused until
+// it is restored.
+const (
+	// TrashedKey is when the container was moved to the trash (RFC 3339)
+	TrashedKey = "user.lxc-go-cli.trashed"
+	// TrashOriginalKey is the name the container had before
+	TrashOriginalKey = "user.lxc-go-cli.trash-name"
+	// TrashAutostartKey is the container's boot.autostart before it was
+	// turned off for the trash; unset when it had none
+	TrashAutostartKey = "user.lxc-go-cli.trash-autostart"
+	// PreviousNamesKey lists the names a container was restored from the
+	// trash under before, so migrate-state can rename devices named after them
+	PreviousNamesKey = "user.lxc-go-cli.previous-names"
+)
+
+// TrashPrefix starts the names of trashed containers
+const TrashPrefix = "trash-"
+
+// DefaultTrashRetention is how long 'trash empty' keeps containers by default
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// TrashedContainer is a container in the trash
+type TrashedContainer struct {
+	// Name is the container's name while in the trash
+	Name string
+	// Original is the name it is restored to
+	Original string
+	Trashed  time.Time
+}
+
+// IsTrashed reports whether a container's config marks it as trashed
+func IsTrashed(config map[string]string) bool {
+	return config[TrashedKey] != ""
+}
+
+// TrashName returns the name a container gets in the trash, e.g.
+// trash-20250101-120000-web, shortened to LXD's name length limit
+func TrashName(name string, at time.Time) string {
+	trashName := TrashPrefix + at.UTC().Format("20060102-150405") + "-" + name
+	if len(trashName) > maxContainerNameLength {
+		trashName = strings.TrimRight(trashName[:maxContainerNameLength], "-")
+	}
+	return trashName
+}
+
+// ListTrash returns the trashed containers among states, oldest first
+func ListTrash(states []ContainerState) []TrashedContainer {
+	var trash []TrashedContainer
+	for _, state := range states {
+		if !IsTrashed(state.Config) {
+			continue
+		}
+		trashed, err := time.Parse(time.RFC3339, state.Config[TrashedKey])
+		if err != nil {
+			logger.Debug("Container '%s' has an invalid %s: %v", state.Name, TrashedKey, err)
+		}
+		original := state.Config[TrashOriginalKey]
+		if original == "" {
+			original = state.Name
+		}
+		trash = append(trash, TrashedContainer{Name: state.Name, Original: original, Trashed: trashed})
+	}
+	sort.SliceStable(trash, func(i, j int) bool { return trash[i].Trashed.Before(trash[j].Trashed) })
+	return trash
+}
+
+// WithoutTrash leaves out trashed containers
+func WithoutTrash(states []ContainerState) []ContainerState {
+	var kept []ContainerState
+	for _, state := range states {
+		if !IsTrashed(state.Config) {
+			kept = append(kept, state)
+		}
+	}
+	return kept
+}
+
+// FindTrashed picks the trashed container to restore for name, which is
+// either its trash name or its original name; of several containers once
+// called name the most recently trashed is picked
+func FindTrashed(trash []TrashedContainer, name string) (TrashedContainer, error) {
+	var found *TrashedContainer
+	for i := range trash {
+		if trash[i].Name == name {
+			return trash[i], nil
+		}
+		if trash[i].Original == name && (found == nil || !trash[i].Trashed.Before(found.Trashed)) {
+			found = &trash[i]
+		}
+	}
+	if found == nil {
+		return TrashedContainer{}, NotFoundErrorf("no container '%s' in the trash", name)
+	}
+	return *found, nil
+}
+
+// TrashToEmpty returns the trashed containers trashed before cutoff
+func TrashToEmpty(trash []TrashedContainer, cutoff time.Time) []TrashedContainer {
+	var expired []TrashedContainer
+	for _, container := range trash {
+		if container.Trashed.Before(cutoff) {
+			expired = append(expired, container)
+		}
+	}
+	return expired
+}
+
+// MoveToTrash stops a container, keeps it from starting at boot and renames
+// it to trashName, recording its name and the time so it can be restored
+func MoveToTrash(ctx context.Context, name, trashName string, running bool, now time.Time) error {
+	if running {
+		logger.Debug("Stopping container '%s' before moving it to the trash", name)
+		if err := runLXC(ctx, "stop", name); err != nil {
+			return fmt.Errorf("failed to stop container '%s': %w", name, err)
+		}
+	}
+
+	output, err := runOutput(ctx, "lxc", "config", "get", name, "boot.autostart")
+	if err != nil {
+		return fmt.Errorf("failed to get boot.autostart of container '%s': %w", name, err)
+	}
+	autostart := strings.TrimSpace(string(output))
+	settings := []string{
+		TrashedKey + "=" + now.UTC().Format(time.RFC3339),
+		TrashOriginalKey + "=" + name,
+		"boot.autostart=false",
+	}
+	if autostart != "" {
+		settings = append(settings, TrashAutostartKey+"="+autostart)
+	}
+	if err := runLXC(ctx, append([]string{"config", "set", name}, settings...)...); err != nil {
+		return fmt.Errorf("failed to mark container '%s' as trashed: %w", name, err)
+	}
+
+	if err := runLXC(ctx, "move", name, trashName); err != nil {
+		// Leave the container as it was rather than half trashed
+		undoErr := runLXC(ctx, "config", "set", name, "boot.autostart="+autostart, TrashedKey+"=", TrashOriginalKey+"=", TrashAutostartKey+"=")
+		if undoErr != nil {
+			logger.Warn("Failed to clear the trash marker of container '%s': %v", name, undoErr)
+		}
+		return fmt.Errorf("failed to rename container '%s' to '%s': %w", name, trashName, err)
+	}
+	return nil
+}
+
+// recordPreviousName returns the previous names of a container restored as
+// name with its original name added, or "" when the name did not change
+func recordPreviousName(config map[string]string, name string) string {
+	original := config[TrashOriginalKey]
+	if original == "" || original == name {
+		return ""
+	}
+	names := SplitConfigList(config[PreviousNamesKey])
+	for _, previous := range names {
+		if previous == original {
+			return strings.Join(names, ",")
+		}
+	}
+	return strings.Join(append(names, original), ",")
+}
+
+// RestoreFromTrash renames a trashed container to name and undoes the
+// changes made when it was trashed, given its config. The container is left
+// stopped.
+func RestoreFromTrash(ctx context.Context, trashName, name string, config map[string]string) error {
+	if err := runLXC(ctx, "move", trashName, name); err != nil {
+		return fmt.Errorf("failed to rename container '%s' to '%s': %w", trashName, name, err)
+	}
+
+	// An empty value unsets a key, so keys that were never set are no error
+	settings := []string{"config", "set", name,
+		"boot.autostart=" + config[TrashAutostartKey], TrashedKey + "=", TrashOriginalKey + "=", TrashAutostartKey + "="}
+	if previous := recordPreviousName(config, name); previous != "" {
+		settings = append(settings, PreviousNamesKey+"="+previous)
+	}
+	err := runLXC(ctx, settings...)
+	if err != nil {
+		return fmt.Errorf("failed to clear the trash marker of container '%s': %w", name, err)
+	}
+	return nil
+}
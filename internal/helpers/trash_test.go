@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrashName(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if name := TrashName("web", at); name != "trash-20250101-120000-web" {
+		t.Errorf("unexpected trash name %s", name)
+	}
+	long := TrashName(strings.Repeat("a", 40)+"-"+strings.Repeat("b", 22), at)
+	if len(long) > maxContainerNameLength || strings.HasSuffix(long, "-") {
+		t.Errorf("expected a valid shortened name, got %s", long)
+	}
+	if err := ValidateContainerName(long); err != nil {
+		t.Errorf("expected a valid container name, got %v", err)
+	}
+}
+
+func TestListTrash(t *testing.T) {
+	states := []ContainerState{
+		{Name: "web", Config: map[string]string{}},
+		{Name: "trash-20250102-000000-db", Config: map[string]string{TrashedKey: "2025-01-02T00:00:00Z", TrashOriginalKey: "db"}},
+		{Name: "trash-20250101-000000-db", Config: map[string]string{TrashedKey: "2025-01-01T00:00:00Z", TrashOriginalKey: "db"}},
+		{Name: "trash-20250103-000000-api", Config: map[string]string{TrashedKey: "2025-01-03T00:00:00Z", TrashOriginalKey: "api"}},
+	}
+
+	trash := ListTrash(states)
+	if len(trash) != 3 || trash[0].Name != "trash-20250101-000000-db" || trash[2].Original != "api" {
+		t.Fatalf("expected the trash oldest first, got %+v", trash)
+	}
+	if kept := WithoutTrash(states); len(kept) != 1 || kept[0].Name != "web" {
+		t.Errorf("expected only web outside the trash, got %+v", kept)
+	}
+
+	if found, err := FindTrashed(trash, "db"); err != nil || found.Name != "trash-20250102-000000-db" {
+		t.Errorf("expected the most recent db, got %+v, %v", found, err)
+	}
+	if found, err := FindTrashed(trash, "trash-20250101-000000-db"); err != nil || found.Original != "db" {
+		t.Errorf("expected the named trash entry, got %+v, %v", found, err)
+	}
+	if _, err := FindTrashed(trash, "web"); err == nil || !strings.Contains(err.Error(), "no container 'web' in the trash") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	expired := TrashToEmpty(trash, time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC))
+	if len(expired) != 2 || expired[1].Name != "trash-20250102-000000-db" {
+		t.Errorf("expected the two db containers to expire, got %+v", expired)
+	}
+}
+
+func TestMoveToTrash(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("true\n", nil, "lxc", "config", "get", "web", "boot.autostart")
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := MoveToTrash(context.Background(), "web", "trash-20250101-120000-web", true, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := runner.ExpectCommands(
+		[]string{"lxc", "stop", "web"},
+		[]string{"lxc", "config", "get", "web", "boot.autostart"},
+		[]string{"lxc", "config", "set", "web", TrashedKey + "=2025-01-01T12:00:00Z", TrashOriginalKey + "=web", "boot.autostart=false", TrashAutostartKey + "=true"},
+		[]string{"lxc", "move", "web", "trash-20250101-120000-web"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoveToTrashUndoesMarkerWhenRenameFails(t *testing.T) {
+	runner := useMockRunner(t)
+	runner.Respond("", errors.New("exit status 1"), "lxc", "move")
+
+	if err := MoveToTrash(context.Background(), "web", "trash-x-web", false, time.Now()); err == nil {
+		t.Fatal("expected the rename error")
+	}
+	if runner.Ran("lxc", "stop", "web") {
+		t.Error("expected a stopped container not to be stopped again")
+	}
+	if !runner.Ran("lxc", "config", "set", "web", "boot.autostart=", TrashedKey+"=", TrashOriginalKey+"=", TrashAutostartKey+"=") {
+		t.Errorf("expected the trash marker to be cleared, got %v", runner.Commands)
+	}
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	runner := useMockRunner(t)
+	config := map[string]string{TrashedKey: "2025-01-01T12:00:00Z", TrashOriginalKey: "web", TrashAutostartKey: "true"}
+
+	if err := RestoreFromTrash(context.Background(), "trash-20250101-120000-web", "web", config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := runner.ExpectCommands(
+		[]string{"lxc", "move", "trash-20250101-120000-web", "web"},
+		[]string{"lxc", "config", "set", "web", "boot.autostart=true", TrashedKey + "=", TrashOriginalKey + "=", TrashAutostartKey + "="},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestoreFromTrashAs(t *testing.T) {
+	runner := useMockRunner(t)
+	config := map[string]string{TrashedKey: "2025-01-01T12:00:00Z", TrashOriginalKey: "web", PreviousNamesKey: "www"}
+
+	if err := RestoreFromTrash(context.Background(), "trash-20250101-120000-web", "web2", config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// The old name is recorded so migrate-state renames its port devices
+	err := runner.ExpectCommands(
+		[]string{"lxc", "move", "trash-20250101-120000-web", "web2"},
+		[]string{"lxc", "config", "set", "web2", "boot.autostart=", TrashedKey + "=", TrashOriginalKey + "=", TrashAutostartKey + "=", PreviousNamesKey + "=www,web"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
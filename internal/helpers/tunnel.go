@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// tunnelDialTimeout bounds connecting to the container for each client
+const tunnelDialTimeout = 10 * time.Second
+
+// ServeTunnel forwards every TCP connection accepted on listener to target
+// until ctx is cancelled. The listener and open connections are closed on
+// return; cancellation is not an error.
+func ServeTunnel(ctx context.Context, listener net.Listener, target string) error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		closed bool
+		conns  = make(map[net.Conn]struct{})
+	)
+	// track registers a connection to close on shutdown; after shutdown it
+	// closes the connection straight away so no copy can outlive the tunnel
+	track := func(conn net.Conn) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			conn.Close()
+			return
+		}
+		conns[conn] = struct{}{}
+	}
+	untrack := func(conn net.Conn) {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(conns, conn)
+	}
+	shutdown := func() {
+		listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		for conn := range conns {
+			conn.Close()
+		}
+	}
+	stop := context.AfterFunc(ctx, shutdown)
+	defer stop()
+
+	var serveErr error
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				serveErr = fmt.Errorf("tunnel stopped accepting connections: %w", err)
+			}
+			break
+		}
+		track(client)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer untrack(client)
+			defer client.Close()
+
+			dialer := net.Dialer{Timeout: tunnelDialTimeout}
+			upstream, err := dialer.DialContext(ctx, "tcp", target)
+			if err != nil {
+				logger.Warn("Cannot reach %s for %s: %v", target, client.RemoteAddr(), err)
+				return
+			}
+			track(upstream)
+			defer untrack(upstream)
+			defer upstream.Close()
+
+			logger.Debug("Tunnel connection from %s to %s", client.RemoteAddr(), target)
+			pipeConns(client, upstream)
+		}()
+	}
+
+	shutdown()
+	wg.Wait()
+	return serveErr
+}
+
+// pipeConns copies data both ways until each side has finished sending,
+// passing half-closes through so request/response protocols work
+func pipeConns(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	copyHalf := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			cw.CloseWrite()
+		} else {
+			dst.Close()
+		}
+		done <- struct{}{}
+	}
+	go copyHalf(a, b)
+	go copyHalf(b, a)
+	<-done
+	<-done
+}
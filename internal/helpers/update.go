@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/deji/lxc-go-cli/internal/logger"
+)
+
+// DockerPackages are the packages installed from Docker's repository
+var DockerPackages = []string{"docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin"}
+
+// UpdateOptions controls how packages are upgraded inside a container
+type UpdateOptions struct {
+	SecurityOnly bool
+}
+
+// BuildUpgradeScript returns the shell script that upgrades packages inside a container.
+// A security-only upgrade installs just the packages offered by a -security pocket and
+// leaves Docker packages alone, since Docker's repository has no security pocket.
+func BuildUpgradeScript(opts UpdateOptions) string {
+	lines := []string{
+		"set -e",
+		"export DEBIAN_FRONTEND=noninteractive",
+		"apt-get update",
+	}
+
+	// Keep locally modified config files rather than prompting
+	install := "apt-get install -y -o Dpkg::Options::=--force-confold --only-upgrade"
+
+	if opts.SecurityOnly {
+		lines = append(lines,
+			"pkgs=$(apt list --upgradable 2>/dev/null | grep -e '-security' | cut -d/ -f1)",
+			fmt.Sprintf("if [ -n \"$pkgs\" ]; then %s $pkgs; fi", install),
+		)
+	} else {
+		lines = append(lines,
+			"apt-get upgrade -y -o Dpkg::Options::=--force-confold",
+			fmt.Sprintf("%s %s", install, strings.Join(DockerPackages, " ")),
+		)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RunContainerScript runs a shell script inside a container as root
+func RunContainerScript(ctx context.Context, containerName, script string) error {
+	if containerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	return runStreamed(ctx, fmt.Sprintf("[%s] ", containerName), "lxc", "exec", containerName, "--", "sh", "-c", script)
+}
+
+// GetPackageVersions returns the installed versions of the given packages in a container.
+// Packages that are not installed are omitted.
+func GetPackageVersions(ctx context.Context, containerName string, packages ...string) (map[string]string, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+
+	args := []string{"exec", containerName, "--", "dpkg-query", "-W", "-f", "${Package} ${Version}\\n"}
+	args = append(args, packages...)
+	logger.Debug("Querying package versions in '%s': %v", containerName, packages)
+
+	output, err := runOutput(ctx, "lxc", args...)
+	if err != nil {
+		// dpkg-query exits 1 when some packages are not installed but still lists the rest
+		if ExitCodeFromError(err) <= 0 || len(output) == 0 {
+			return nil, fmt.Errorf("failed to query package versions: %w", err)
+		}
+	}
+
+	return parsePackageVersions(string(output)), nil
+}
+
+// parsePackageVersions parses "<package> <version>" lines from dpkg-query
+func parsePackageVersions(output string) map[string]string {
+	versions := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		versions[fields[0]] = fields[1]
+	}
+	return versions
+}
+
+// FormatVersionReport renders a before/after package version table
+func FormatVersionReport(before, after map[string]string) string {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	if len(names) == 0 {
+		return "No tracked packages installed\n"
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tBEFORE\tAFTER\t")
+	for _, name := range sorted {
+		from, to := before[name], after[name]
+		if from == "" {
+			from = "-"
+		}
+		if to == "" {
+			to = "-"
+		}
+		marker := ""
+		if from != to {
+			marker = "updated"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, from, to, marker)
+	}
+	w.Flush()
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildUpgradeScript(t *testing.T) {
+	script := BuildUpgradeScript(UpdateOptions{})
+	if !strings.Contains(script, "apt-get upgrade -y") {
+		t.Errorf("full upgrade should run apt-get upgrade: %s", script)
+	}
+	if !strings.Contains(script, "--only-upgrade docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin") {
+		t.Errorf("full upgrade should bump Docker packages: %s", script)
+	}
+
+	script = BuildUpgradeScript(UpdateOptions{SecurityOnly: true})
+	if strings.Contains(script, "apt-get upgrade") || strings.Contains(script, "docker-ce") {
+		t.Errorf("security-only upgrade should not run a full upgrade: %s", script)
+	}
+	if !strings.Contains(script, "-security") {
+		t.Errorf("security-only upgrade should filter on the security pocket: %s", script)
+	}
+}
+
+func TestParsePackageVersions(t *testing.T) {
+	versions := parsePackageVersions("docker-ce 5:27.1.0-1~ubuntu.24.04~noble\ncontainerd.io 1.7.19-1\n\nbogus\n")
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 packages, got %v", versions)
+	}
+	if versions["docker-ce"] != "5:27.1.0-1~ubuntu.24.04~noble" {
+		t.Errorf("unexpected docker-ce version: %s", versions["docker-ce"])
+	}
+}
+
+func TestFormatVersionReport(t *testing.T) {
+	report := FormatVersionReport(
+		map[string]string{"docker-ce": "1.0", "containerd.io": "2.0"},
+		map[string]string{"docker-ce": "1.1", "containerd.io": "2.0", "docker-compose-plugin": "3.0"},
+	)
+
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 3 rows, got:\n%s", report)
+	}
+	if !strings.Contains(lines[2], "docker-ce") || !strings.Contains(lines[2], "updated") {
+		t.Errorf("docker-ce should be marked updated: %s", lines[2])
+	}
+	if strings.Contains(lines[1], "updated") {
+		t.Errorf("containerd.io should not be marked updated: %s", lines[1])
+	}
+	if !strings.Contains(lines[3], "-") {
+		t.Errorf("newly installed package should show '-' before: %s", lines[3])
+	}
+
+	if FormatVersionReport(nil, nil) != "No tracked packages installed\n" {
+		t.Error("expected placeholder for empty report")
+	}
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conditions the wait command can block on
+const (
+	WaitRunning     = "running"
+	WaitIP          = "ip"
+	WaitDockerReady = "docker-ready"
+	WaitPort        = "port"
+)
+
+// WaitCondition is a parsed --for value such as "running" or "port:8080"
+type WaitCondition struct {
+	Kind string
+	Port int
+}
+
+func (c WaitCondition) String() string {
+	if c.Kind == WaitPort {
+		return fmt.Sprintf("%s:%d", WaitPort, c.Port)
+	}
+	return c.Kind
+}
+
+// ParseWaitCondition parses a condition name, e.g. "docker-ready" or "port:5432"
+func ParseWaitCondition(value string) (WaitCondition, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	kind, arg, hasArg := strings.Cut(value, ":")
+
+	switch kind {
+	case WaitRunning, WaitIP, WaitDockerReady:
+		if hasArg {
+			return WaitCondition{}, fmt.Errorf("condition '%s' takes no argument", kind)
+		}
+		return WaitCondition{Kind: kind}, nil
+	case WaitPort:
+		port, err := strconv.Atoi(arg)
+		if err != nil || port < 1 || port > 65535 {
+			return WaitCondition{}, UsageErrorf("invalid condition '%s': expected port:<1-65535>", value)
+		}
+		return WaitCondition{Kind: WaitPort, Port: port}, nil
+	default:
+		return WaitCondition{}, fmt.Errorf("unknown condition '%s': must be running, ip, docker-ready or port:<port>", value)
+	}
+}
+
+// CheckDockerReady returns nil once the Docker daemon in the container answers
+func CheckDockerReady(ctx context.Context, containerName string) error {
+	if _, err := Runner().RunWithOutput(ctx, "lxc", "exec", containerName, "--", "docker", "info"); err != nil {
+		return fmt.Errorf("docker is not ready in '%s': %w", containerName, err)
+	}
+	return nil
+}
+
+// CheckTCPPort returns nil if a TCP connection to address succeeds
+func CheckTCPPort(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"running", "running", false},
+		{" IP ", "ip", false},
+		{"docker-ready", "docker-ready", false},
+		{"port:5432", "port:5432", false},
+		{"port:65536", "", true},
+		{"running:1", "", true},
+		{"healthy", "", true},
+	}
+	for _, tt := range tests {
+		condition, err := ParseWaitCondition(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWaitCondition(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && condition.String() != tt.expected {
+			t.Errorf("ParseWaitCondition(%q) = %s, expected %s", tt.input, condition, tt.expected)
+		}
+	}
+}
+
+func TestCheckDockerReady(t *testing.T) {
+	mock := useMockRunner(t)
+	if err := CheckDockerReady(context.Background(), "web"); err != nil {
+		t.Errorf("expected docker to be ready, got %v", err)
+	}
+	if !mock.Ran("lxc", "exec", "web", "--", "docker", "info") {
+		t.Errorf("expected docker info to run, got %v", mock.Commands)
+	}
+
+	mock.Respond("", fmt.Errorf("exit status 1"), "lxc", "exec", "web")
+	if err := CheckDockerReady(context.Background(), "web"); err == nil {
+		t.Error("expected error when docker info fails")
+	}
+}
+
+func TestCheckTCPPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	if err := CheckTCPPort(context.Background(), address); err != nil {
+		t.Errorf("expected open port, got %v", err)
+	}
+
+	listener.Close()
+	if err := CheckTCPPort(context.Background(), address); err == nil {
+		t.Error("expected error for closed port")
+	}
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WatchRecord tracks the restarts of one container by the watchdog since it
+// was last healthy
+type WatchRecord struct {
+	Restarts    int       `yaml:"restarts"`
+	NextRestart time.Time `yaml:"next_restart"`
+	GaveUp      bool      `yaml:"gave_up,omitempty"`
+}
+
+// WatchStatePath returns the file the watchdog keeps its restart records in,
+// so separate runs such as 'watch --once' from cron share one budget
+func WatchStatePath() string {
+	return filepath.Join(SettingsDir, "watch-state.yml")
+}
+
+// LoadWatchRecords reads the watchdog's restart records by container name;
+// there are none before the first restart
+func LoadWatchRecords() (map[string]WatchRecord, error) {
+	records := make(map[string]WatchRecord)
+	if SettingsDir == "" {
+		return records, nil
+	}
+	data, err := os.ReadFile(WatchStatePath())
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state %s: %w", WatchStatePath(), err)
+	}
+	return records, nil
+}
+
+// SaveWatchRecords writes the watchdog's restart records
+func SaveWatchRecords(records map[string]WatchRecord) error {
+	if SettingsDir == "" {
+		return fmt.Errorf("no user config directory available")
+	}
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch state: %w", err)
+	}
+	if err := writeFileAtomic(WatchStatePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}
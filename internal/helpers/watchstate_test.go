@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchRecords(t *testing.T) {
+	useTempSettingsDir(t)
+
+	records, err := LoadWatchRecords()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected no records before the first save, got %v (%v)", records, err)
+	}
+
+	next := time.Date(2025, 1, 1, 12, 4, 0, 0, time.UTC)
+	saved := map[string]WatchRecord{
+		"web": {Restarts: 3, NextRestart: next},
+		"api": {Restarts: 5, NextRestart: next, GaveUp: true},
+	}
+	if err := SaveWatchRecords(saved); err != nil {
+		t.Fatal(err)
+	}
+	records, err = LoadWatchRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records["web"].Restarts != 3 || !records["web"].NextRestart.Equal(next) || !records["api"].GaveUp {
+		t.Errorf("expected the records to round-trip, got %+v", records)
+	}
+}
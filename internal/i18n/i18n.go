@@ -0,0 +1,173 @@
+// Package i18n translates user-facing messages. Messages are looked up by a
+// stable ID, such as container.not_found, in a catalog per language; the ID
+// doubles as the error code scripts can rely on whatever the language.
+package i18n
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultLanguage is used when no other language is chosen or available, and
+// for messages missing from a catalog
+const DefaultLanguage = "en"
+
+//go:embed catalogs/*.yaml
+var catalogFiles embed.FS
+
+// catalogs maps a language to its messages by ID
+var catalogs = loadCatalogs()
+
+// language is the language messages are shown in
+var language = DefaultLanguage
+
+// loadCatalogs reads the embedded catalogs, one file per language
+func loadCatalogs() map[string]map[string]string {
+	entries, err := catalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+	loaded := make(map[string]map[string]string)
+	for _, entry := range entries {
+		data, err := catalogFiles.ReadFile(path.Join("catalogs", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read catalog %s: %v", entry.Name(), err))
+		}
+		messages := make(map[string]string)
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".yaml")] = messages
+	}
+	return loaded
+}
+
+// Languages returns the languages with a catalog, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Language returns the language messages are shown in
+func Language() string {
+	return language
+}
+
+// SetLanguage shows messages in lang, one of Languages
+func SetLanguage(lang string) error {
+	if _, ok := catalogs[lang]; !ok {
+		return fmt.Errorf("unsupported language '%s': must be one of %s", lang, strings.Join(Languages(), ", "))
+	}
+	language = lang
+	return nil
+}
+
+// ParseLanguage returns the language of a locale such as es_ES.UTF-8, or ""
+// for the C and POSIX locales and for empty values
+func ParseLanguage(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "c" || lang == "posix" {
+		return ""
+	}
+	return lang
+}
+
+// DetectLanguage picks the language from the configured one, then the
+// LC_ALL, LC_MESSAGES and LANG environment variables, falling back to
+// DefaultLanguage when none has a catalog
+func DetectLanguage(configured string) string {
+	candidates := []string{configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, candidate := range candidates {
+		lang := ParseLanguage(candidate)
+		if lang == "" {
+			continue
+		}
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		// The first locale set is the one asked for; one without a catalog
+		// gets the default rather than a lower-priority variable's language
+		return DefaultLanguage
+	}
+	return DefaultLanguage
+}
+
+// Lookup returns the message with the given ID in the current language only
+func Lookup(id string) (string, bool) {
+	message, ok := catalogs[language][id]
+	return message, ok
+}
+
+// message returns the format of a message in the current language, falling
+// back to the default language and then to the ID itself
+func message(lang, id string) string {
+	if format, ok := catalogs[lang][id]; ok {
+		return format
+	}
+	if format, ok := catalogs[DefaultLanguage][id]; ok {
+		return format
+	}
+	return id
+}
+
+// T returns the message with the given ID in the current language, formatted
+// with args as by fmt.Sprintf
+func T(id string, args ...any) string {
+	format := message(language, id)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error is an error whose message is translated when it is shown. Code is
+// the message ID, which stays the same in every language.
+type Error struct {
+	Code string
+	Args []any
+}
+
+// Errorf returns an Error for the message with the given ID; like
+// fmt.Errorf, a %w verb in the message wraps its argument
+func Errorf(id string, args ...any) error {
+	return &Error{Code: id, Args: args}
+}
+
+func (e *Error) Error() string {
+	return fmt.Errorf(message(language, e.Code), e.Args...).Error()
+}
+
+// Unwrap returns the errors wrapped with %w, so errors.Is and errors.As see
+// through an Error
+func (e *Error) Unwrap() []error {
+	switch wrapped := fmt.Errorf(message(DefaultLanguage, e.Code), e.Args...).(type) {
+	case interface{ Unwrap() error }:
+		return []error{wrapped.Unwrap()}
+	case interface{ Unwrap() []error }:
+		return wrapped.Unwrap()
+	}
+	return nil
+}
+
+// CodeOf returns the code of the outermost Error in err's chain, or ""
+func CodeOf(err error) string {
+	var translated *Error
+	if errors.As(err, &translated) {
+		return translated.Code
+	}
+	return ""
+}
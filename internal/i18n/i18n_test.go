@@ -0,0 +1,162 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useLanguage switches the language for one test
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	original := language
+	if err := SetLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { language = original })
+}
+
+// verbPattern matches fmt verbs, ignoring escaped percent signs
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+func verbs(format string) []string {
+	var found []string
+	for _, verb := range verbPattern.FindAllString(format, -1) {
+		if verb != "%%" {
+			found = append(found, verb)
+		}
+	}
+	return found
+}
+
+func TestCatalogs(t *testing.T) {
+	if !slices.Equal(Languages(), []string{"en", "es"}) {
+		t.Fatalf("expected English and Spanish catalogs, got %v", Languages())
+	}
+	english := catalogs[DefaultLanguage]
+	for _, lang := range Languages() {
+		for id, format := range catalogs[lang] {
+			if strings.HasPrefix(id, "cmd.") {
+				// Command help is translated from the commands themselves
+				continue
+			}
+			source, ok := english[id]
+			if !ok {
+				t.Errorf("%s: message %s is not in the English catalog", lang, id)
+				continue
+			}
+			if !slices.Equal(verbs(format), verbs(source)) {
+				t.Errorf("%s: message %s has verbs %v, expected %v", lang, id, verbs(format), verbs(source))
+			}
+		}
+		for id := range english {
+			if _, ok := catalogs[lang][id]; !ok {
+				t.Errorf("%s: message %s is not translated", lang, id)
+			}
+		}
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := map[string]string{
+		"es_ES.UTF-8": "es",
+		"en_GB":       "en",
+		"de":          "de",
+		"ES-mx":       "es",
+		"C":           "",
+		"C.UTF-8":     "",
+		"POSIX":       "",
+		"":            "",
+	}
+	for locale, expected := range tests {
+		if got := ParseLanguage(locale); got != expected {
+			t.Errorf("ParseLanguage(%q) = %q, expected %q", locale, got, expected)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		env        map[string]string
+		expected   string
+	}{
+		{"nothing set", "", nil, "en"},
+		{"LANG", "", map[string]string{"LANG": "es_ES.UTF-8"}, "es"},
+		{"LC_ALL over LANG", "", map[string]string{"LC_ALL": "en_US.UTF-8", "LANG": "es_ES.UTF-8"}, "en"},
+		{"LC_MESSAGES over LANG", "", map[string]string{"LC_MESSAGES": "es_AR", "LANG": "en_US"}, "es"},
+		{"config over the environment", "es", map[string]string{"LANG": "en_US.UTF-8"}, "es"},
+		{"C locale is skipped", "", map[string]string{"LC_ALL": "C", "LANG": "es_ES.UTF-8"}, "es"},
+		{"language without a catalog", "", map[string]string{"LANG": "de_DE.UTF-8"}, "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := DetectLanguage(tt.configured); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	original := language
+	t.Cleanup(func() { language = original })
+
+	if err := SetLanguage("xx"); err == nil || !strings.Contains(err.Error(), "en, es") {
+		t.Errorf("expected an unsupported language error, got %v", err)
+	}
+	if Language() != original {
+		t.Errorf("a failed SetLanguage should keep %s, got %s", original, Language())
+	}
+}
+
+func TestT(t *testing.T) {
+	useLanguage(t, "es")
+
+	if got := T("container.not_found", "web"); got != "el contenedor 'web' no existe" {
+		t.Errorf("unexpected translation %q", got)
+	}
+	// Unknown IDs are shown as they are, formatted
+	if got := T("Delete '%s'?", "web"); got != "Delete 'web'?" {
+		t.Errorf("unexpected fallback %q", got)
+	}
+	if _, ok := Lookup("cmd.list.short"); !ok {
+		t.Error("expected a Spanish short help for list")
+	}
+
+	useLanguage(t, "en")
+	if _, ok := Lookup("cmd.list.short"); ok {
+		t.Error("English short help comes from the commands, not the catalog")
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	useLanguage(t, "en")
+	cause := errors.New("device busy")
+	err := Errorf("delete.failed", "web", cause)
+
+	if err.Error() != "failed to delete container 'web': device busy" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the error to wrap its cause")
+	}
+	if CodeOf(err) != "delete.failed" {
+		t.Errorf("unexpected code %q", CodeOf(err))
+	}
+
+	// The message follows the language; the code does not
+	useLanguage(t, "es")
+	if err.Error() != "no se pudo eliminar el contenedor 'web': device busy" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if CodeOf(err) != "delete.failed" || CodeOf(cause) != "" {
+		t.Errorf("unexpected codes %q and %q", CodeOf(err), CodeOf(cause))
+	}
+}
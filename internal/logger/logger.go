@@ -51,8 +51,9 @@ func ParseLogLevel(level string) LogLevel {
 
 // Logger represents our application logger
 type Logger struct {
-	level  LogLevel
-	logger *log.Logger
+	level   LogLevel
+	logger  *log.Logger
+	handler func(level LogLevel, message string)
 }
 
 // Global logger instance
@@ -76,6 +77,12 @@ func SetLevelFromString(level string) {
 	SetLevel(ParseLogLevel(level))
 }
 
+// SetHandler passes log messages to handler instead of writing them to
+// stderr, e.g. to emit them as structured events; nil restores stderr
+func SetHandler(handler func(level LogLevel, message string)) {
+	globalLogger.handler = handler
+}
+
 // GetLevel returns the current logging level
 func GetLevel() LogLevel {
 	return globalLogger.level
@@ -94,6 +101,10 @@ func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
 	
 	prefix := fmt.Sprintf("[%s] ", level.String())
 	message := fmt.Sprintf(format, args...)
+	if l.handler != nil {
+		l.handler(level, message)
+		return
+	}
 	l.logger.Printf("%s%s", prefix, message)
 }
 
@@ -0,0 +1,62 @@
+package render
+
+import (
+	"io"
+	"os"
+)
+
+// plain turns off color and symbols everywhere; set by --plain
+var plain bool
+
+// SetPlain makes all output plain text, e.g. for logs and scripts
+func SetPlain(p bool) {
+	plain = p
+}
+
+// Symbols for check results
+const (
+	SymbolPass = "✔"
+	SymbolWarn = "⚠"
+	SymbolFail = "✖"
+)
+
+// Style says how output to a writer may be decorated
+type Style struct {
+	// Symbols allows check marks and crosses in place of words
+	Symbols bool
+	// Color allows ANSI colors
+	Color bool
+}
+
+// StyleFor returns the style for output to w: terminals get symbols, and
+// color unless NO_COLOR is set (https://no-color.org); everything else, and
+// everything after --plain, gets plain text
+func StyleFor(w io.Writer) Style {
+	if plain || !isTerminal(w) {
+		return Style{}
+	}
+	return Style{Symbols: true, Color: os.Getenv("NO_COLOR") == ""}
+}
+
+// Paint colors s when the style allows color
+func (s Style) Paint(c Color, text string) string {
+	if !s.Color {
+		return text
+	}
+	return c.Paint(text)
+}
+
+// ColorEnabled reports whether output to w should be colored
+func ColorEnabled(w io.Writer) bool {
+	return StyleFor(w).Color
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStyleFor(t *testing.T) {
+	if StyleFor(&bytes.Buffer{}) != (Style{}) {
+		t.Error("expected plain output for a buffer")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if StyleFor(f) != (Style{}) || ColorEnabled(f) {
+		t.Error("expected plain output for a regular file")
+	}
+}
+
+func TestStylePaint(t *testing.T) {
+	if got := (Style{Color: true}).Paint(Green, "ok"); got != "\x1b[32mok\x1b[0m" {
+		t.Errorf("unexpected colored text %q", got)
+	}
+	if got := (Style{Symbols: true}).Paint(Green, "ok"); got != "ok" {
+		t.Errorf("expected no color, got %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package render decorates human output: tables whose columns are sized to
+// their contents and can be selected by name, and colors and symbols that are
+// only used on terminals.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// columnGap separates columns
+const columnGap = "  "
+
+// Color is an ANSI SGR code
+type Color string
+
+// Colors used for cell values
+const (
+	NoColor Color = ""
+	Red     Color = "31"
+	Green   Color = "32"
+	Yellow  Color = "33"
+	Dim     Color = "2"
+)
+
+// Paint wraps s in the escape codes for c
+func (c Color) Paint(s string) string {
+	if c == NoColor || s == "" {
+		return s
+	}
+	return "\x1b[" + string(c) + "m" + s + "\x1b[0m"
+}
+
+// Options control how a table is printed
+type Options struct {
+	// Columns selects and orders columns by name; empty prints all of them
+	Columns []string
+	// NoHeader leaves out the header line, e.g. for scripts
+	NoHeader bool
+	// Color paints cells styled with Table.Style
+	Color bool
+}
+
+// Table holds rows of text cells under named columns
+type Table struct {
+	headers []string
+	rows    [][]string
+	styles  map[int]func(value string) Color
+}
+
+// NewTable returns an empty table with the given column headers
+func NewTable(headers ...string) *Table {
+	return &Table{headers: headers, styles: make(map[int]func(string) Color)}
+}
+
+// AddRow appends a row; missing cells are left empty
+func (t *Table) AddRow(values ...string) {
+	row := make([]string, len(t.headers))
+	copy(row, values)
+	t.rows = append(t.rows, row)
+}
+
+// Style colors the cells of a column by their value when color is enabled
+func (t *Table) Style(column string, style func(value string) Color) {
+	if i := t.columnIndex(column); i >= 0 {
+		t.styles[i] = style
+	}
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// ColumnName turns a header into the name used to select it, e.g. "HOST PORT"
+// into "host-port"
+func ColumnName(header string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(header, "_", " ")), "-"))
+}
+
+// ColumnNames returns the names of the table's columns
+func (t *Table) ColumnNames() []string {
+	names := make([]string, len(t.headers))
+	for i, header := range t.headers {
+		names[i] = ColumnName(header)
+	}
+	return names
+}
+
+// columnIndex finds a column by header or name, or returns -1
+func (t *Table) columnIndex(name string) int {
+	name = ColumnName(name)
+	for i, header := range t.headers {
+		if ColumnName(header) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectColumns resolves the selected column names to indexes
+func (t *Table) selectColumns(columns []string) ([]int, error) {
+	if len(columns) == 0 {
+		indexes := make([]int, len(t.headers))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	indexes := make([]int, 0, len(columns))
+	for _, column := range columns {
+		i := t.columnIndex(column)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column '%s' (available: %s)", column, strings.Join(t.ColumnNames(), ", "))
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes, nil
+}
+
+// Render writes the table with columns as wide as their widest cell
+func (t *Table) Render(w io.Writer, opts Options) error {
+	indexes, err := t.selectColumns(opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	lines := t.rows
+	if !opts.NoHeader {
+		lines = append([][]string{t.headers}, t.rows...)
+	}
+
+	widths := make([]int, len(indexes))
+	for _, line := range lines {
+		for col, i := range indexes {
+			if n := utf8.RuneCountInString(line[i]); n > widths[col] {
+				widths[col] = n
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for n, line := range lines {
+		isHeader := n == 0 && !opts.NoHeader
+		var cells strings.Builder
+		for col, i := range indexes {
+			value := line[i]
+			padding := ""
+			// The last column is not padded, so lines carry no trailing spaces
+			if col < len(indexes)-1 {
+				padding = strings.Repeat(" ", widths[col]-utf8.RuneCountInString(value)) + columnGap
+			}
+			if style := t.styles[i]; opts.Color && !isHeader && style != nil {
+				value = style(value).Paint(value)
+			}
+			cells.WriteString(value + padding)
+		}
+		sb.WriteString(strings.TrimRight(cells.String(), " ") + "\n")
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// String renders the table with all columns, a header and no color
+func (t *Table) String() string {
+	var sb strings.Builder
+	t.Render(&sb, Options{})
+	return sb.String()
+}
+
+// ParseColumns splits a comma separated --columns value
+func ParseColumns(spec string) []string {
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newTestTable() *Table {
+	table := NewTable("NAME", "STATUS", "HOST PORT")
+	table.AddRow("web", "Running", "8080")
+	table.AddRow("database", "Stopped")
+	table.Style("status", func(value string) Color {
+		if value == "Running" {
+			return Green
+		}
+		return Red
+	})
+	return table
+}
+
+func TestRender(t *testing.T) {
+	want := "NAME      STATUS   HOST PORT\n" +
+		"web       Running  8080\n" +
+		"database  Stopped\n"
+	if got := newTestTable().String(); got != want {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, got)
+	}
+}
+
+func TestRenderOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "columns",
+			opts: Options{Columns: []string{"host-port", "NAME"}},
+			want: "HOST PORT  NAME\n8080       web\n           database\n",
+		},
+		{
+			name: "no header",
+			opts: Options{Columns: []string{"name"}, NoHeader: true},
+			want: "web\ndatabase\n",
+		},
+		{
+			name: "color",
+			opts: Options{Columns: []string{"status", "name"}, Color: true},
+			want: "STATUS   NAME\n\x1b[32mRunning\x1b[0m  web\n\x1b[31mStopped\x1b[0m  database\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := newTestTable().Render(&out, tt.opts); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.want, out.String())
+			}
+		})
+	}
+
+	err := newTestTable().Render(&bytes.Buffer{}, Options{Columns: []string{"name", "ip"}})
+	if err == nil || !strings.Contains(err.Error(), "unknown column 'ip' (available: name, status, host-port)") {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	if got := strings.Join(ParseColumns(" name, ,status "), "|"); got != "name|status" {
+		t.Errorf("unexpected columns %q", got)
+	}
+	if ParseColumns("") != nil {
+		t.Error("expected no columns for an empty value")
+	}
+}